@@ -0,0 +1,498 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// markerPrefix 写入每个请求的用户消息，内置 mock 上游据此记录请求到达时间，
+// 用于计算网关侧耗时（排队等并发槽位 + 调度 + 转发）。
+const markerPrefix = "loadtest-id:"
+
+type endpoint struct {
+	name string
+	path string
+}
+
+var endpoints = map[string]endpoint{
+	"messages":  {name: "messages", path: "/v1/messages"},
+	"chat":      {name: "chat", path: "/v1/chat/completions"},
+	"responses": {name: "responses", path: "/v1/responses"},
+}
+
+type weightedEndpoint struct {
+	endpoint endpoint
+	weight   int
+}
+
+type options struct {
+	target      string
+	keys        []string
+	mix         []weightedEndpoint
+	model       string
+	concurrency int
+	requests    int64
+	streamRatio float64
+	bodySizes   []int
+	timeout     time.Duration
+	seed        int64
+}
+
+type result struct {
+	endpoint string
+	stream   bool
+	status   int
+	errKind  string
+	ttfb     time.Duration
+	total    time.Duration
+	// overhead 发出请求到 mock 上游收到请求的间隔；未使用内置 mock 或请求未到达上游时为 -1
+	overhead time.Duration
+}
+
+func main() {
+	target := flag.String("target", "", "required base URL of the instance under test, e.g. http://staging:8080")
+	keysRaw := flag.String("keys", "", "comma-separated API keys; requests rotate across them")
+	keysFile := flag.String("keys-file", "", "file with one API key per line (merged with --keys)")
+	mixRaw := flag.String("mix", "messages=6,chat=2,responses=2", "endpoint weights: messages, chat, responses")
+	model := flag.String("model", "claude-sonnet-4-5", "model requested by every call")
+	concurrency := flag.Int("concurrency", 16, "concurrent clients")
+	duration := flag.Duration("duration", time.Minute, "test duration (ignored when --requests > 0)")
+	requests := flag.Int64("requests", 0, "total requests to send (0 = run for --duration)")
+	streamRatio := flag.Float64("stream-ratio", 0.7, "fraction of streaming requests (0-1)")
+	bodySizesRaw := flag.String("body-sizes", "1k,8k,64k", "prompt sizes picked uniformly per request (k/m suffix)")
+	timeout := flag.Duration("timeout", 5*time.Minute, "per-request client timeout")
+	seed := flag.Int64("seed", 1, "random seed for the traffic mix")
+	mockListen := flag.String("mock-listen", "", "start the built-in mock upstream on this address, e.g. :18081")
+	mockLatency := flag.Duration("mock-latency", 300*time.Millisecond, "mock upstream time to first token")
+	mockChunks := flag.Int("mock-chunks", 10, "mock upstream streamed chunks per response")
+	mockChunkInterval := flag.Duration("mock-chunk-interval", 20*time.Millisecond, "mock upstream delay between streamed chunks")
+	flag.Parse()
+
+	mix, err := parseMix(*mixRaw)
+	if err != nil {
+		log.Fatalf("invalid --mix: %v", err)
+	}
+	bodySizes, err := parseSizes(*bodySizesRaw)
+	if err != nil {
+		log.Fatalf("invalid --body-sizes: %v", err)
+	}
+	keys, err := loadKeys(*keysRaw, *keysFile)
+	if err != nil {
+		log.Fatalf("load keys: %v", err)
+	}
+	if *target == "" || len(keys) == 0 {
+		log.Fatal("--target and at least one key (--keys or --keys-file) are required")
+	}
+	if *concurrency < 1 {
+		log.Fatal("--concurrency must be at least 1")
+	}
+	if *streamRatio < 0 || *streamRatio > 1 {
+		log.Fatal("--stream-ratio must be between 0 and 1")
+	}
+
+	var mock *mockUpstream
+	if *mockListen != "" {
+		mock = newMockUpstream(*mockLatency, *mockChunks, *mockChunkInterval)
+		listener, err := net.Listen("tcp", *mockListen)
+		if err != nil {
+			log.Fatalf("mock upstream: %v", err)
+		}
+		server := &http.Server{Handler: mock, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("mock upstream: %v", err)
+			}
+		}()
+		defer func() { _ = server.Close() }()
+		log.Printf("mock upstream listening on %s (point the test accounts' base_url here)", *mockListen)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *requests <= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	opts := options{
+		target:      strings.TrimRight(*target, "/"),
+		keys:        keys,
+		mix:         mix,
+		model:       *model,
+		concurrency: *concurrency,
+		requests:    *requests,
+		streamRatio: *streamRatio,
+		bodySizes:   bodySizes,
+		timeout:     *timeout,
+		seed:        *seed,
+	}
+	started := time.Now()
+	results := run(ctx, opts, mock)
+	printReport(os.Stdout, results, time.Since(started), mock != nil)
+}
+
+func run(ctx context.Context, opts options, mock *mockUpstream) []result {
+	client := &http.Client{
+		Timeout: opts.timeout,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: opts.concurrency,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+	var (
+		mu      sync.Mutex
+		results []result
+		sent    atomic.Int64
+		wg      sync.WaitGroup
+	)
+	for worker := 0; worker < opts.concurrency; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(opts.seed + int64(worker)))
+			for ctx.Err() == nil {
+				n := sent.Add(1)
+				if opts.requests > 0 && n > opts.requests {
+					return
+				}
+				r := doRequest(ctx, client, opts, rng, n, mock)
+				if r.errKind == "canceled" {
+					return
+				}
+				mu.Lock()
+				results = append(results, r)
+				mu.Unlock()
+			}
+		}(worker)
+	}
+	wg.Wait()
+	return results
+}
+
+func doRequest(ctx context.Context, client *http.Client, opts options, rng *rand.Rand, n int64, mock *mockUpstream) result {
+	ep := pickEndpoint(opts.mix, rng)
+	stream := rng.Float64() < opts.streamRatio
+	key := opts.keys[int(n)%len(opts.keys)]
+	marker := fmt.Sprintf("%s%d-%d", markerPrefix, opts.seed, n)
+	prompt := marker + " " + filler(opts.bodySizes[rng.Intn(len(opts.bodySizes))])
+	res := result{endpoint: ep.name, stream: stream, overhead: -1}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.target+ep.path, bytes.NewReader(buildBody(ep.name, opts.model, stream, prompt)))
+	if err != nil {
+		res.errKind = "build_request"
+		return res
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ep.name == "messages" {
+		req.Header.Set("x-api-key", key)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	} else {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	start := time.Now()
+	var firstByte time.Time
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}))
+	resp, err := client.Do(req)
+	if err != nil {
+		res.total = time.Since(start)
+		res.errKind = classifyError(ctx, err)
+		return res
+	}
+	_, readErr := io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	res.total = time.Since(start)
+	res.status = resp.StatusCode
+	if !firstByte.IsZero() {
+		res.ttfb = firstByte.Sub(start)
+	}
+	if readErr != nil {
+		res.errKind = classifyError(ctx, readErr)
+	} else if resp.StatusCode >= 400 {
+		res.errKind = "http_" + strconv.Itoa(resp.StatusCode)
+	}
+	if mock != nil {
+		if arrived, ok := mock.arrival(marker); ok {
+			res.overhead = arrived.Sub(start)
+		}
+	}
+	return res
+}
+
+func classifyError(ctx context.Context, err error) string {
+	switch {
+	case ctx.Err() != nil:
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "Client.Timeout"):
+		return "timeout"
+	default:
+		return "transport"
+	}
+}
+
+func buildBody(endpointName, model string, stream bool, prompt string) []byte {
+	var body map[string]any
+	switch endpointName {
+	case "messages":
+		body = map[string]any{
+			"model":      model,
+			"max_tokens": 256,
+			"stream":     stream,
+			"messages":   []map[string]any{{"role": "user", "content": prompt}},
+		}
+	case "chat":
+		body = map[string]any{
+			"model":    model,
+			"stream":   stream,
+			"messages": []map[string]any{{"role": "user", "content": prompt}},
+		}
+	default:
+		body = map[string]any{
+			"model":  model,
+			"stream": stream,
+			"input":  []map[string]any{{"role": "user", "content": prompt}},
+		}
+	}
+	data, _ := json.Marshal(body)
+	return data
+}
+
+const fillerText = "The quick brown fox jumps over the lazy dog while the load test measures latency. "
+
+func filler(size int) string {
+	if size <= 0 {
+		return ""
+	}
+	return strings.Repeat(fillerText, size/len(fillerText)+1)[:size]
+}
+
+func pickEndpoint(mix []weightedEndpoint, rng *rand.Rand) endpoint {
+	total := 0
+	for _, w := range mix {
+		total += w.weight
+	}
+	n := rng.Intn(total)
+	for _, w := range mix {
+		if n < w.weight {
+			return w.endpoint
+		}
+		n -= w.weight
+	}
+	return mix[len(mix)-1].endpoint
+}
+
+func parseMix(raw string) ([]weightedEndpoint, error) {
+	var mix []weightedEndpoint
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightRaw, ok := strings.Cut(part, "=")
+		if !ok {
+			weightRaw = "1"
+		}
+		ep, known := endpoints[strings.TrimSpace(name)]
+		if !known {
+			return nil, fmt.Errorf("unknown endpoint %q (expected messages, chat or responses)", name)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(weightRaw))
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight for %s: %q", name, weightRaw)
+		}
+		if weight > 0 {
+			mix = append(mix, weightedEndpoint{endpoint: ep, weight: weight})
+		}
+	}
+	if len(mix) == 0 {
+		return nil, errors.New("no endpoint with a positive weight")
+	}
+	return mix, nil
+}
+
+func parseSizes(raw string) ([]int, error) {
+	var sizes []int
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		multiplier := 1
+		switch {
+		case strings.HasSuffix(part, "k"):
+			multiplier, part = 1<<10, strings.TrimSuffix(part, "k")
+		case strings.HasSuffix(part, "m"):
+			multiplier, part = 1<<20, strings.TrimSuffix(part, "m")
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid size %q", part)
+		}
+		sizes = append(sizes, n*multiplier)
+	}
+	if len(sizes) == 0 {
+		return nil, errors.New("at least one size is required")
+	}
+	return sizes, nil
+}
+
+func loadKeys(raw, file string) ([]string, error) {
+	var keys []string
+	for _, key := range strings.Split(raw, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				keys = append(keys, line)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// percentile 按最近秩法取分位数，输入须已排序。
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.999999) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func sortedDurations(values []time.Duration) []time.Duration {
+	out := append([]time.Duration(nil), values...)
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func formatPercentiles(values []time.Duration) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	sorted := sortedDurations(values)
+	return fmt.Sprintf("%s / %s / %s", roundDuration(percentile(sorted, 50)), roundDuration(percentile(sorted, 90)), roundDuration(percentile(sorted, 99)))
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
+
+func printReport(out io.Writer, results []result, elapsed time.Duration, withMock bool) {
+	type group struct {
+		count  int
+		errors int
+		ttfb   []time.Duration
+		total  []time.Duration
+	}
+	groups := map[string]*group{}
+	errorKinds := map[string]int{}
+	var overhead []time.Duration
+	failed := 0
+	for _, r := range results {
+		mode := "non-stream"
+		if r.stream {
+			mode = "stream"
+		}
+		key := r.endpoint + "\t" + mode
+		g := groups[key]
+		if g == nil {
+			g = &group{}
+			groups[key] = g
+		}
+		g.count++
+		if r.errKind != "" {
+			g.errors++
+			failed++
+			errorKinds[r.errKind]++
+			continue
+		}
+		g.ttfb = append(g.ttfb, r.ttfb)
+		g.total = append(g.total, r.total)
+		if r.overhead >= 0 {
+			overhead = append(overhead, r.overhead)
+		}
+	}
+
+	rps := 0.0
+	if elapsed > 0 {
+		rps = float64(len(results)) / elapsed.Seconds()
+	}
+	errorRate := 0.0
+	if len(results) > 0 {
+		errorRate = float64(failed) / float64(len(results)) * 100
+	}
+	_, _ = fmt.Fprintf(out, "requests=%d elapsed=%s rps=%.1f errors=%d error_rate=%.2f%%\n\n",
+		len(results), roundDuration(elapsed), rps, failed, errorRate)
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "endpoint\tmode\tcount\terrors\tttfb p50 / p90 / p99\ttotal p50 / p90 / p99")
+	for _, key := range keys {
+		g := groups[key]
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", key, g.count, g.errors, formatPercentiles(g.ttfb), formatPercentiles(g.total))
+	}
+	_ = tw.Flush()
+
+	if len(errorKinds) > 0 {
+		kinds := make([]string, 0, len(errorKinds))
+		for kind := range errorKinds {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		_, _ = fmt.Fprintln(out, "\nerrors by kind:")
+		for _, kind := range kinds {
+			_, _ = fmt.Fprintf(out, "  %s=%d\n", kind, errorKinds[kind])
+		}
+	}
+
+	if withMock {
+		_, _ = fmt.Fprintln(out, "\ngateway overhead before upstream (includes concurrency slot wait):")
+		if len(overhead) == 0 {
+			_, _ = fmt.Fprintln(out, "  no request reached the mock upstream; check the test accounts' base_url")
+			return
+		}
+		sorted := sortedDurations(overhead)
+		_, _ = fmt.Fprintf(out, "  p50 / p90 / p99 / max = %s / %s / %s / %s (%d of %d successful requests matched)\n",
+			roundDuration(percentile(sorted, 50)), roundDuration(percentile(sorted, 90)), roundDuration(percentile(sorted, 99)),
+			roundDuration(sorted[len(sorted)-1]), len(overhead), len(results)-failed)
+	}
+}
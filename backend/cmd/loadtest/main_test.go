@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := parseMix("messages=3, chat=0,responses")
+	if err != nil {
+		t.Fatalf("parseMix: %v", err)
+	}
+	if len(mix) != 2 || mix[0].endpoint.name != "messages" || mix[0].weight != 3 || mix[1].endpoint.name != "responses" || mix[1].weight != 1 {
+		t.Fatalf("unexpected mix: %+v", mix)
+	}
+	for _, raw := range []string{"", "chat=0", "embeddings=1", "chat=-1", "chat=x"} {
+		if _, err := parseMix(raw); err == nil {
+			t.Fatalf("parseMix(%q) should fail", raw)
+		}
+	}
+}
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := parseSizes("512, 8K,1m")
+	if err != nil {
+		t.Fatalf("parseSizes: %v", err)
+	}
+	want := []int{512, 8 << 10, 1 << 20}
+	for i := range want {
+		if sizes[i] != want[i] {
+			t.Fatalf("sizes = %v, want %v", sizes, want)
+		}
+	}
+	for _, raw := range []string{"", "0", "-1k", "big"} {
+		if _, err := parseSizes(raw); err == nil {
+			t.Fatalf("parseSizes(%q) should fail", raw)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var values []time.Duration
+	for i := 100; i >= 1; i-- {
+		values = append(values, time.Duration(i)*time.Millisecond)
+	}
+	sorted := sortedDurations(values)
+	cases := map[float64]time.Duration{50: 50 * time.Millisecond, 90: 90 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond, 0: time.Millisecond}
+	for p, want := range cases {
+		if got := percentile(sorted, p); got != want {
+			t.Fatalf("p%v = %s, want %s", p, got, want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Fatalf("empty percentile = %s", got)
+	}
+}
+
+func TestMockUpstreamRecordsFirstArrival(t *testing.T) {
+	mock := newMockUpstream(0, 2, 0)
+	body := buildBody("chat", "gpt-test", true, markerPrefix+"1-7 hello")
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		mock.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "data: [DONE]") {
+			t.Fatalf("unexpected stream response %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if _, ok := mock.arrival(markerPrefix + "1-7"); !ok {
+		t.Fatal("arrival not recorded")
+	}
+	if _, ok := mock.arrival(markerPrefix + "1-7"); ok {
+		t.Fatal("arrival should be consumed after lookup")
+	}
+
+	rec := httptest.NewRecorder()
+	mock.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader(buildBody("messages", "claude-test", false, "hi"))))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"type":"message"`) {
+		t.Fatalf("unexpected messages response %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var markerPattern = regexp.MustCompile(regexp.QuoteMeta(markerPrefix) + `[0-9]+-[0-9]+`)
+
+// mockUpstream 模拟 Anthropic Messages / OpenAI Chat Completions / Responses 上游，
+// 按固定首 token 延迟与分块间隔返回流式或非流式结果，并记录每个压测请求到达上游的时间。
+type mockUpstream struct {
+	latency       time.Duration
+	chunks        int
+	chunkInterval time.Duration
+	arrivals      sync.Map // marker -> time.Time
+}
+
+func newMockUpstream(latency time.Duration, chunks int, chunkInterval time.Duration) *mockUpstream {
+	if chunks < 1 {
+		chunks = 1
+	}
+	return &mockUpstream{latency: latency, chunks: chunks, chunkInterval: chunkInterval}
+}
+
+// arrival 返回并清除指定请求到达上游的时间；failover 重试时保留第一次到达。
+func (m *mockUpstream) arrival(marker string) (time.Time, bool) {
+	value, ok := m.arrivals.LoadAndDelete(marker)
+	if !ok {
+		return time.Time{}, false
+	}
+	return value.(time.Time), true
+}
+
+func (m *mockUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	arrived := time.Now()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+	if marker := markerPattern.Find(body); marker != nil {
+		m.arrivals.LoadOrStore(string(marker), arrived)
+	}
+	var req struct {
+		Model  string `json:"model"`
+		Stream bool   `json:"stream"`
+	}
+	_ = json.Unmarshal(body, &req)
+
+	var kind string
+	switch r.URL.Path {
+	case "/v1/messages":
+		kind = "messages"
+	case "/v1/chat/completions", "/chat/completions":
+		kind = "chat"
+	case "/v1/responses", "/responses":
+		kind = "responses"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	select {
+	case <-time.After(m.latency):
+	case <-r.Context().Done():
+		return
+	}
+	if !req.Stream {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(mockResponseBody(kind, req.Model, m.chunks))
+		return
+	}
+	m.stream(w, r, kind, req.Model)
+}
+
+func (m *mockUpstream) stream(w http.ResponseWriter, r *http.Request, kind, model string) {
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	write := func(event, data string) {
+		if event != "" {
+			_, _ = fmt.Fprintf(w, "event: %s\n", event)
+		}
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	pause := func() bool {
+		select {
+		case <-time.After(m.chunkInterval):
+			return true
+		case <-r.Context().Done():
+			return false
+		}
+	}
+
+	switch kind {
+	case "messages":
+		write("message_start", fmt.Sprintf(`{"type":"message_start","message":{"id":"msg_loadtest","type":"message","role":"assistant","model":%q,"content":[],"stop_reason":null,"usage":{"input_tokens":100,"output_tokens":1}}}`, model))
+		write("content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`)
+		for i := 0; i < m.chunks; i++ {
+			write("content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"chunk "}}`)
+			if !pause() {
+				return
+			}
+		}
+		write("content_block_stop", `{"type":"content_block_stop","index":0}`)
+		write("message_delta", fmt.Sprintf(`{"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":%d}}`, m.chunks))
+		write("message_stop", `{"type":"message_stop"}`)
+	case "chat":
+		for i := 0; i < m.chunks; i++ {
+			write("", fmt.Sprintf(`{"id":"chatcmpl-loadtest","object":"chat.completion.chunk","created":%d,"model":%q,"choices":[{"index":0,"delta":{"content":"chunk "},"finish_reason":null}]}`, time.Now().Unix(), model))
+			if !pause() {
+				return
+			}
+		}
+		write("", fmt.Sprintf(`{"id":"chatcmpl-loadtest","object":"chat.completion.chunk","created":%d,"model":%q,"choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":100,"completion_tokens":%d,"total_tokens":%d}}`, time.Now().Unix(), model, m.chunks, 100+m.chunks))
+		write("", "[DONE]")
+	default:
+		write("response.created", fmt.Sprintf(`{"type":"response.created","response":{"id":"resp_loadtest","object":"response","status":"in_progress","model":%q,"output":[]}}`, model))
+		for i := 0; i < m.chunks; i++ {
+			write("response.output_text.delta", `{"type":"response.output_text.delta","item_id":"msg_loadtest","output_index":0,"content_index":0,"delta":"chunk "}`)
+			if !pause() {
+				return
+			}
+		}
+		write("response.completed", fmt.Sprintf(`{"type":"response.completed","response":%s}`, mockResponseBody("responses", model, m.chunks)))
+	}
+}
+
+func mockResponseBody(kind, model string, outputTokens int) []byte {
+	var body any
+	switch kind {
+	case "messages":
+		body = map[string]any{
+			"id": "msg_loadtest", "type": "message", "role": "assistant", "model": model,
+			"content":     []map[string]any{{"type": "text", "text": "loadtest response"}},
+			"stop_reason": "end_turn",
+			"usage":       map[string]any{"input_tokens": 100, "output_tokens": outputTokens},
+		}
+	case "chat":
+		body = map[string]any{
+			"id": "chatcmpl-loadtest", "object": "chat.completion", "created": time.Now().Unix(), "model": model,
+			"choices": []map[string]any{{"index": 0, "message": map[string]any{"role": "assistant", "content": "loadtest response"}, "finish_reason": "stop"}},
+			"usage":   map[string]any{"prompt_tokens": 100, "completion_tokens": outputTokens, "total_tokens": 100 + outputTokens},
+		}
+	default:
+		body = map[string]any{
+			"id": "resp_loadtest", "object": "response", "status": "completed", "model": model,
+			"output": []map[string]any{{
+				"type": "message", "id": "msg_loadtest", "role": "assistant", "status": "completed",
+				"content": []map[string]any{{"type": "output_text", "text": "loadtest response"}},
+			}},
+			"usage": map[string]any{"input_tokens": 100, "output_tokens": outputTokens, "total_tokens": 100 + outputTokens},
+		}
+	}
+	data, _ := json.Marshal(body)
+	return data
+}
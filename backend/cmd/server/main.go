@@ -18,6 +18,7 @@ import (
 	_ "github.com/Wei-Shaw/sub2api/ent/runtime"
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/accesslog"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/setup"
@@ -139,6 +140,12 @@ func runMainServer() {
 	if err := logger.Init(logger.OptionsFromConfig(cfg.Log)); err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	if cfg.Log.Access.Enabled {
+		if err := accesslog.Init(accesslog.OptionsFromConfig(cfg.Log.Access)); err != nil {
+			log.Fatalf("Failed to initialize access log: %v", err)
+		}
+		defer accesslog.Close()
+	}
 	if cfg.RunMode == config.RunModeSimple {
 		log.Println("⚠️  WARNING: Running in SIMPLE mode - billing and quota checks are DISABLED")
 	}
@@ -163,6 +170,15 @@ func runMainServer() {
 		}
 	}
 
+	if err := app.Diagnostics.RunAtStartup(context.Background()); err != nil {
+		// log.Fatalf 不会执行 defer，先显式释放资源。
+		app.Cleanup()
+		log.Fatalf("%v (see startup diagnostics report above; set startup_diagnostics.fail_fast=false to start anyway)", err)
+	}
+
+	// 开始接收流量前预热热点数据，失败不影响启动
+	app.WarmStart.Run(context.Background())
+
 	// 启动服务器
 	go func() {
 		if err := app.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
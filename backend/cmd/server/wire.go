@@ -14,6 +14,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
 	"github.com/Wei-Shaw/sub2api/internal/repository"
 	"github.com/Wei-Shaw/sub2api/internal/securityaudit"
 	"github.com/Wei-Shaw/sub2api/internal/server"
@@ -27,6 +28,8 @@ import (
 type Application struct {
 	Server      *http.Server
 	PromptAudit *securityaudit.PromptService
+	Diagnostics *service.StartupDiagnosticsService
+	WarmStart   *service.WarmStartService
 	Cleanup     func()
 }
 
@@ -39,6 +42,7 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 		repository.ProviderSet,
 		service.ProviderSet,
 		securityaudit.ProviderSet,
+		policyplugin.ProviderSet,
 		payment.ProviderSet,
 		middleware.ProviderSet,
 		handler.ProviderSet,
@@ -56,7 +60,7 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 		provideCleanup,
 
 		// Application struct
-		wire.Struct(new(Application), "Server", "PromptAudit", "Cleanup"),
+		wire.Struct(new(Application), "Server", "PromptAudit", "Diagnostics", "WarmStart", "Cleanup"),
 	)
 	return nil, nil
 }
@@ -74,7 +78,9 @@ func provideServiceBuildInfo(buildInfo handler.BuildInfo) service.BuildInfo {
 
 func provideCleanup(
 	entClient *ent.Client,
-	rdb *redis.Client,
+	dbReplica *repository.DBReadReplica,
+	rdb redis.UniversalClient,
+	redisReplica *repository.RedisReadReplica,
 	opsMetricsCollector *service.OpsMetricsCollector,
 	opsAggregation *service.OpsAggregationService,
 	opsAlertEvaluator *service.OpsAlertEvaluatorService,
@@ -88,7 +94,16 @@ func provideCleanup(
 	schedulerSnapshot *service.SchedulerSnapshotService,
 	tokenRefresh *service.TokenRefreshService,
 	accountExpiry *service.AccountExpiryService,
+	accountRenewalReminder *service.AccountRenewalReminderService,
+	accountSnapshot *service.AccountSnapshotService,
+	credentialSync *service.AccountCredentialSyncService,
+	userNotification *service.UserNotificationService,
+	modelAliasLearning *service.ModelAliasLearningService,
+	costAnomaly *service.AccountCostAnomalyService,
+	smokeTest *service.AccountSmokeTestService,
+	groupBudget *service.GroupBudgetService,
 	proxyExpiry *service.ProxyExpiryService,
+	proxyLatencyRouter *service.ProxyLatencyRouter,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
@@ -98,6 +113,10 @@ func provideCleanup(
 	emailQueue *service.EmailQueueService,
 	billingCache *service.BillingCacheService,
 	usageRecordWorkerPool *service.UsageRecordWorkerPool,
+	usageRecordDeadLetter *service.UsageRecordDeadLetterService,
+	dbHealthMonitor *service.DatabaseHealthMonitor,
+	redisHealthMonitor *service.RedisHealthMonitor,
+	requestMirror *service.RequestMirrorService,
 	subscriptionService *service.SubscriptionService,
 	oauth *service.OAuthService,
 	openaiOAuth *service.OpenAIOAuthService,
@@ -112,6 +131,9 @@ func provideCleanup(
 	quotaFlusher *service.UserPlatformQuotaUsageFlusher,
 	upstreamBillingProbe *service.UpstreamBillingProbeService,
 	auditLog *service.AuditLogService,
+	transcript *service.ConversationTranscriptService,
+	streamCapture *service.UpstreamStreamCaptureService,
+	usageArchive *service.UsageArchiveService,
 	promptAudit *securityaudit.PromptService,
 ) func() {
 	return func() {
@@ -179,6 +201,24 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"ConversationTranscriptService", func() error {
+				if transcript != nil {
+					transcript.Stop()
+				}
+				return nil
+			}},
+			{"UpstreamStreamCaptureService", func() error {
+				if streamCapture != nil {
+					streamCapture.Stop()
+				}
+				return nil
+			}},
+			{"UsageArchiveService", func() error {
+				if usageArchive != nil {
+					usageArchive.Stop()
+				}
+				return nil
+			}},
 			{"OpsAlertEvaluatorService", func() error {
 				if opsAlertEvaluator != nil {
 					opsAlertEvaluator.Stop()
@@ -235,10 +275,46 @@ func provideCleanup(
 				accountExpiry.Stop()
 				return nil
 			}},
+			{"AccountRenewalReminderService", func() error {
+				accountRenewalReminder.Stop()
+				return nil
+			}},
+			{"AccountSnapshotService", func() error {
+				accountSnapshot.Stop()
+				return nil
+			}},
+			{"AccountCredentialSyncService", func() error {
+				credentialSync.Stop()
+				return nil
+			}},
+			{"UserNotificationService", func() error {
+				userNotification.Stop()
+				return nil
+			}},
+			{"ModelAliasLearningService", func() error {
+				modelAliasLearning.Stop()
+				return nil
+			}},
+			{"AccountCostAnomalyService", func() error {
+				costAnomaly.Stop()
+				return nil
+			}},
+			{"AccountSmokeTestService", func() error {
+				smokeTest.Stop()
+				return nil
+			}},
+			{"GroupBudgetService", func() error {
+				groupBudget.Stop()
+				return nil
+			}},
 			{"ProxyExpiryService", func() error {
 				proxyExpiry.Stop()
 				return nil
 			}},
+			{"ProxyLatencyRouter", func() error {
+				proxyLatencyRouter.Stop()
+				return nil
+			}},
 			{"SubscriptionExpiryService", func() error {
 				subscriptionExpiry.Stop()
 				return nil
@@ -267,6 +343,24 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"UsageRecordDeadLetterService", func() error {
+				if usageRecordDeadLetter != nil {
+					usageRecordDeadLetter.Stop()
+				}
+				return nil
+			}},
+			{"DatabaseHealthMonitor", func() error {
+				dbHealthMonitor.Stop()
+				return nil
+			}},
+			{"RedisHealthMonitor", func() error {
+				redisHealthMonitor.Stop()
+				return nil
+			}},
+			{"RequestMirrorService", func() error {
+				requestMirror.Stop()
+				return nil
+			}},
 			{"OAuthService", func() error {
 				oauth.Stop()
 				return nil
@@ -334,6 +428,12 @@ func provideCleanup(
 		}
 
 		infraSteps := []cleanupStep{
+			{"DBReadReplica", func() error {
+				return dbReplica.Close()
+			}},
+			{"RedisReadReplica", func() error {
+				return redisReplica.Close()
+			}},
 			{"Redis", func() error {
 				if rdb == nil {
 					return nil
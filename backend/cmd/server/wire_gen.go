@@ -13,6 +13,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/handler/admin"
 	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
 	"github.com/Wei-Shaw/sub2api/internal/repository"
 	"github.com/Wei-Shaw/sub2api/internal/securityaudit"
 	"github.com/Wei-Shaw/sub2api/internal/server"
@@ -47,29 +48,30 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	}
 	userRepository := repository.NewUserRepository(client, db)
 	redeemCodeRepository := repository.NewRedeemCodeRepository(client)
-	redisClient := repository.ProvideRedis(configConfig)
-	refreshTokenCache := repository.NewRefreshTokenCache(redisClient)
-	settingRepository := repository.NewSettingRepository(client)
+	universalClient := repository.ProvideRedis(configConfig)
+	refreshTokenCache := repository.NewRefreshTokenCache(universalClient)
+	settingRepository := repository.ProvideSettingRepository(client, configConfig)
 	groupRepository := repository.NewGroupRepository(client, db)
 	proxyRepository := repository.NewProxyRepository(client, db)
 	settingService := service.ProvideSettingService(settingRepository, groupRepository, proxyRepository, configConfig)
-	emailCache := repository.NewEmailCache(redisClient)
+	featureFlagService := service.NewFeatureFlagService(settingRepository)
+	emailCache := repository.NewEmailCache(universalClient)
 	emailService := service.NewEmailService(settingRepository, emailCache)
 	turnstileVerifier := repository.NewTurnstileVerifier()
 	turnstileService := service.NewTurnstileService(settingService, turnstileVerifier)
 	emailQueueService := service.ProvideEmailQueueService(emailService)
 	promoCodeRepository := repository.NewPromoCodeRepository(client)
-	billingCache := repository.NewBillingCache(redisClient)
+	billingCache := repository.NewBillingCache(universalClient)
 	userSubscriptionRepository := repository.NewUserSubscriptionRepository(client)
 	apiKeyRepository := repository.NewAPIKeyRepository(client, db)
-	userRPMCache := repository.NewUserRPMCache(redisClient)
+	userRPMCache := repository.NewUserRPMCache(universalClient)
 	userGroupRateRepository := repository.NewUserGroupRateRepository(db)
 	userPlatformQuotaRepository := repository.NewUserPlatformQuotaRepository(client)
 	serviceUserPlatformQuotaRepository := repository.NewUserPlatformQuotaServiceAdapter(userPlatformQuotaRepository)
 	billingCacheService := service.ProvideBillingCacheService(billingCache, userRepository, userSubscriptionRepository, apiKeyRepository, userRPMCache, userGroupRateRepository, configConfig, serviceUserPlatformQuotaRepository)
-	apiKeyCache := repository.NewAPIKeyCache(redisClient)
-	concurrencyCache := repository.ProvideConcurrencyCache(redisClient, configConfig)
-	schedulerCache := repository.ProvideSchedulerCache(redisClient, configConfig)
+	apiKeyCache := repository.NewAPIKeyCache(universalClient)
+	concurrencyCache := repository.ProvideConcurrencyCache(universalClient, configConfig)
+	schedulerCache := repository.ProvideSchedulerCache(universalClient, configConfig)
 	accountRepository := repository.NewAccountRepository(client, db, schedulerCache)
 	concurrencyService := service.ProvideConcurrencyService(concurrencyCache, accountRepository, configConfig)
 	apiKeyService := service.ProvideAPIKeyService(apiKeyRepository, userRepository, groupRepository, userSubscriptionRepository, userGroupRateRepository, apiKeyCache, configConfig, billingCacheService, concurrencyService)
@@ -80,13 +82,13 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	affiliateService := service.NewAffiliateService(affiliateRepository, settingService, apiKeyAuthCacheInvalidator, billingCacheService)
 	authService := service.NewAuthService(client, userRepository, redeemCodeRepository, refreshTokenCache, configConfig, settingService, emailService, turnstileService, emailQueueService, promoService, subscriptionService, affiliateService, serviceUserPlatformQuotaRepository)
 	userService := service.NewUserService(userRepository, settingRepository, apiKeyAuthCacheInvalidator, billingCache)
-	redeemCache := repository.NewRedeemCache(redisClient)
+	redeemCache := repository.NewRedeemCache(universalClient)
 	redeemService := service.NewRedeemService(redeemCodeRepository, userRepository, subscriptionService, redeemCache, billingCacheService, client, apiKeyAuthCacheInvalidator, affiliateService)
 	secretEncryptor, err := repository.NewAESEncryptor(configConfig)
 	if err != nil {
 		return nil, err
 	}
-	totpCache := repository.NewTotpCache(redisClient)
+	totpCache := repository.NewTotpCache(universalClient)
 	totpService := service.NewTotpService(userRepository, secretEncryptor, totpCache, settingService, emailService, emailQueueService)
 	userAttributeDefinitionRepository := repository.NewUserAttributeDefinitionRepository(client)
 	userAttributeValueRepository := repository.NewUserAttributeValueRepository(client)
@@ -94,11 +96,12 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	authHandler := handler.NewAuthHandler(configConfig, authService, userService, settingService, promoService, redeemService, totpService, userAttributeService)
 	userHandler := handler.NewUserHandler(userService, authService, emailService, emailCache, affiliateService, serviceUserPlatformQuotaRepository)
 	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
-	usageLogRepository := repository.NewUsageLogRepository(client, db)
+	dbReadReplica := repository.ProvideDBReadReplica(configConfig)
+	usageLogRepository := repository.NewUsageLogRepository(client, db, dbReadReplica)
 	usageService := service.NewUsageService(usageLogRepository, userRepository, client, apiKeyAuthCacheInvalidator)
 	opsRepository := repository.NewOpsRepository(db)
 	usageBillingRepository := repository.NewUsageBillingRepository(client, db)
-	gatewayCache := repository.NewGatewayCache(redisClient)
+	gatewayCache := repository.NewGatewayCache(universalClient)
 	schedulerOutboxRepository := repository.NewSchedulerOutboxRepository(db)
 	schedulerSnapshotService := service.ProvideSchedulerSnapshotService(schedulerCache, schedulerOutboxRepository, accountRepository, groupRepository, configConfig)
 	pricingRemoteClient := repository.ProvidePricingRemoteClient(configConfig)
@@ -108,15 +111,26 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	}
 	billingService := service.NewBillingService(configConfig, pricingService)
 	geminiQuotaService := service.NewGeminiQuotaService(configConfig, settingRepository)
-	tempUnschedCache := repository.NewTempUnschedCache(redisClient)
-	timeoutCounterCache := repository.NewTimeoutCounterCache(redisClient)
-	openAI403CounterCache := repository.NewOpenAI403CounterCache(redisClient)
-	geminiTokenCache := repository.NewGeminiTokenCache(redisClient)
+	tempUnschedCache := repository.NewTempUnschedCache(universalClient)
+	timeoutCounterCache := repository.NewTimeoutCounterCache(universalClient)
+	openAI403CounterCache := repository.NewOpenAI403CounterCache(universalClient)
+	redisReadReplica := repository.ProvideRedisReadReplica(configConfig)
+	geminiTokenCache := repository.ProvideGeminiTokenCache(universalClient, redisReadReplica, configConfig, secretEncryptor)
 	compositeTokenCacheInvalidator := service.NewCompositeTokenCacheInvalidator(geminiTokenCache)
 	rateLimitService := service.ProvideRateLimitService(accountRepository, usageLogRepository, configConfig, geminiQuotaService, tempUnschedCache, timeoutCounterCache, openAI403CounterCache, settingService, compositeTokenCacheInvalidator)
-	identityCache := repository.NewIdentityCache(redisClient)
+	identityCache := repository.NewIdentityCache(universalClient)
 	identityService := service.NewIdentityService(identityCache)
-	httpUpstream := repository.NewHTTPUpstream(configConfig)
+	proxyHostLatencyProber := repository.NewProxyHostLatencyProber(configConfig)
+	proxyLatencyRouter := service.ProvideProxyLatencyRouter(accountRepository, proxyRepository, proxyHostLatencyProber, configConfig)
+	manager, err := policyplugin.ProvideManager(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	upstreamMTLSService, err := service.NewUpstreamMTLSService(configConfig, accountRepository)
+	if err != nil {
+		return nil, err
+	}
+	httpUpstream := repository.ProvideHTTPUpstream(configConfig, proxyLatencyRouter, manager, upstreamMTLSService, settingService)
 	timingWheelService, err := service.ProvideTimingWheelService()
 	if err != nil {
 		return nil, err
@@ -126,17 +140,20 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	oAuthService := service.NewOAuthService(proxyRepository, claudeOAuthClient)
 	oAuthRefreshAPI := service.ProvideOAuthRefreshAPI(accountRepository, geminiTokenCache)
 	claudeTokenProvider := service.ProvideClaudeTokenProvider(accountRepository, geminiTokenCache, oAuthService, oAuthRefreshAPI)
-	sessionLimitCache := repository.ProvideSessionLimitCache(redisClient, configConfig)
-	rpmCache := repository.NewRPMCache(redisClient)
+	sessionLimitCache := repository.ProvideSessionLimitCache(universalClient, configConfig)
+	rpmCache := repository.NewRPMCache(universalClient)
 	digestSessionStore := service.NewDigestSessionStore()
 	tlsFingerprintProfileRepository := repository.NewTLSFingerprintProfileRepository(client)
-	tlsFingerprintProfileCache := repository.NewTLSFingerprintProfileCache(redisClient)
+	tlsFingerprintProfileCache := repository.NewTLSFingerprintProfileCache(universalClient)
 	tlsFingerprintProfileService := service.NewTLSFingerprintProfileService(tlsFingerprintProfileRepository, tlsFingerprintProfileCache)
 	channelRepository := repository.NewChannelRepository(db)
 	channelService := service.NewChannelService(channelRepository, groupRepository, apiKeyAuthCacheInvalidator, pricingService)
 	modelPricingResolver := service.NewModelPricingResolver(channelService, billingService)
 	notificationEmailService := service.NewNotificationEmailService(settingRepository, emailService)
-	balanceNotifyService := service.ProvideBalanceNotifyService(emailService, settingRepository, accountRepository, notificationEmailService)
+	userNotificationRepository := repository.NewUserNotificationRepository(db)
+	leaderLockCache := repository.NewLeaderLockCache(universalClient)
+	userNotificationService := service.ProvideUserNotificationService(userNotificationRepository, leaderLockCache, db, configConfig)
+	balanceNotifyService := service.ProvideBalanceNotifyService(emailService, settingRepository, accountRepository, notificationEmailService, userNotificationService)
 	gatewayService := service.NewGatewayService(accountRepository, groupRepository, usageLogRepository, usageBillingRepository, userRepository, userSubscriptionRepository, userGroupRateRepository, gatewayCache, configConfig, schedulerSnapshotService, concurrencyService, billingService, rateLimitService, billingCacheService, identityService, httpUpstream, deferredService, claudeTokenProvider, sessionLimitCache, rpmCache, digestSessionStore, settingService, tlsFingerprintProfileService, channelService, modelPricingResolver, balanceNotifyService, serviceUserPlatformQuotaRepository)
 	openAIOAuthClient := repository.NewOpenAIOAuthClient()
 	privacyClientFactory := providePrivacyClientFactory()
@@ -150,16 +167,18 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	geminiCliCodeAssistClient := repository.NewGeminiCliCodeAssistClient()
 	driveClient := repository.NewGeminiDriveClient()
 	geminiOAuthService := service.NewGeminiOAuthService(proxyRepository, geminiOAuthClient, geminiCliCodeAssistClient, driveClient, configConfig)
-	geminiTokenProvider := service.ProvideGeminiTokenProvider(accountRepository, geminiTokenCache, geminiOAuthService, oAuthRefreshAPI)
+	geminiTokenProvider := service.ProvideGeminiTokenProvider(accountRepository, geminiTokenCache, geminiOAuthService, oAuthRefreshAPI, configConfig)
 	antigravityOAuthService := service.NewAntigravityOAuthService(proxyRepository)
 	antigravityTokenProvider := service.ProvideAntigravityTokenProvider(accountRepository, geminiTokenCache, antigravityOAuthService, oAuthRefreshAPI, tempUnschedCache)
-	internal500CounterCache := repository.NewInternal500CounterCache(redisClient)
+	internal500CounterCache := repository.NewInternal500CounterCache(universalClient)
 	antigravityGatewayService := service.NewAntigravityGatewayService(accountRepository, gatewayCache, schedulerSnapshotService, antigravityTokenProvider, rateLimitService, httpUpstream, settingService, internal500CounterCache)
 	geminiMessagesCompatService := service.NewGeminiMessagesCompatService(accountRepository, groupRepository, gatewayCache, schedulerSnapshotService, geminiTokenProvider, rateLimitService, httpUpstream, antigravityGatewayService, configConfig)
 	opsSystemLogSink := service.ProvideOpsSystemLogSink(opsRepository)
 	authCacheInvalidationOutboxRepository := repository.NewAuthCacheInvalidationOutboxRepository(db)
 	authCacheInvalidationWorker := service.ProvideAuthCacheInvalidationWorker(authCacheInvalidationOutboxRepository, apiKeyCache, apiKeyService)
-	opsService := service.ProvideOpsService(opsRepository, settingRepository, configConfig, accountRepository, userRepository, concurrencyService, gatewayService, openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, opsSystemLogSink, settingService, authCacheInvalidationWorker, apiKeyService)
+	usageRecordDeadLetterCache := repository.NewUsageRecordDeadLetterCache(universalClient)
+	usageRecordDeadLetterService := service.ProvideUsageRecordDeadLetterService(usageRecordDeadLetterCache, usageBillingRepository, usageLogRepository, billingCacheService, db, configConfig)
+	opsService := service.ProvideOpsService(opsRepository, settingRepository, configConfig, accountRepository, userRepository, concurrencyService, gatewayService, openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, opsSystemLogSink, settingService, authCacheInvalidationWorker, apiKeyService, usageRecordDeadLetterService)
 	usageHandler := handler.NewUsageHandler(usageService, apiKeyService, opsService, settingService)
 	redeemHandler := handler.NewRedeemHandler(redeemService)
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
@@ -171,15 +190,14 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	channelMonitorService := service.ProvideChannelMonitorService(channelMonitorRepository, secretEncryptor)
 	channelMonitorUserHandler := handler.NewChannelMonitorUserHandler(channelMonitorService, settingService)
 	dashboardAggregationRepository := repository.NewDashboardAggregationRepository(db)
-	dashboardStatsCache := repository.NewDashboardCache(redisClient, configConfig)
+	dashboardStatsCache := repository.NewDashboardCache(universalClient, configConfig)
 	dashboardService := service.NewDashboardService(usageLogRepository, dashboardAggregationRepository, dashboardStatsCache, configConfig)
-	leaderLockCache := repository.NewLeaderLockCache(redisClient)
 	dashboardAggregationService := service.ProvideDashboardAggregationService(dashboardAggregationRepository, timingWheelService, leaderLockCache, db, configConfig)
 	dashboardHandler := admin.NewDashboardHandler(dashboardService, dashboardAggregationService)
 	adminGroupRepository := repository.NewAdminGroupRepository(client, db)
 	adminAccountRepository := repository.NewAdminAccountRepository(client, db, schedulerCache)
 	proxyExitInfoProber := repository.NewProxyExitInfoProber(configConfig)
-	proxyLatencyCache := repository.NewProxyLatencyCache(redisClient)
+	proxyLatencyCache := repository.NewProxyLatencyCache(universalClient)
 	adminService := service.NewAdminService(userRepository, adminGroupRepository, adminAccountRepository, proxyRepository, apiKeyRepository, redeemCodeRepository, userGroupRateRepository, userRPMCache, billingCacheService, proxyExitInfoProber, proxyLatencyCache, apiKeyAuthCacheInvalidator, client, settingService, subscriptionService, userSubscriptionRepository, privacyClientFactory, openAIGatewayService, affiliateService)
 	adminUserHandler := admin.NewUserHandler(adminService, concurrencyService, serviceUserPlatformQuotaRepository, billingCache, totpService, userService, settingService)
 	groupCapacityService := service.NewGroupCapacityService(accountRepository, groupRepository, concurrencyService, sessionLimitCache, rpmCache)
@@ -207,7 +225,7 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	openAIOAuthHandler := admin.NewOpenAIOAuthHandler(openAIOAuthService, adminService, openAIQuotaService)
 	geminiOAuthHandler := admin.NewGeminiOAuthHandler(geminiOAuthService)
 	antigravityOAuthHandler := admin.NewAntigravityOAuthHandler(antigravityOAuthService)
-	tokenRefreshService := service.ProvideTokenRefreshService(accountRepository, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, grokOAuthService, compositeTokenCacheInvalidator, schedulerCache, configConfig, tempUnschedCache, privacyClientFactory, proxyRepository, oAuthRefreshAPI, openAIGatewayService)
+	tokenRefreshService := service.ProvideTokenRefreshService(accountRepository, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, grokOAuthService, compositeTokenCacheInvalidator, schedulerCache, configConfig, tempUnschedCache, privacyClientFactory, proxyRepository, oAuthRefreshAPI, openAIGatewayService, featureFlagService)
 	grokOAuthHandler := admin.NewGrokOAuthHandler(grokOAuthService, adminService, grokQuotaService, tokenRefreshService)
 	proxyHandler := admin.NewProxyHandler(adminService)
 	adminRedeemHandler := admin.NewRedeemHandler(adminService, redeemService)
@@ -220,9 +238,11 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	registry := payment.ProvideRegistry()
 	defaultLoadBalancer := payment.ProvideDefaultLoadBalancer(client, encryptionKey)
 	paymentService := service.ProvidePaymentService(client, registry, defaultLoadBalancer, redeemService, subscriptionService, paymentConfigService, userRepository, groupRepository, affiliateService, notificationEmailService)
-	settingHandler := handler.ProvideAdminSettingHandler(settingService, emailService, turnstileService, opsService, paymentConfigService, paymentService, userAttributeService, notificationEmailService, totpService, userService)
+	settingChangeRequestRepository := repository.NewSettingChangeRequestRepository(db)
+	settingGovernanceService := service.NewSettingGovernanceService(settingRepository, settingChangeRequestRepository)
+	settingHandler := handler.ProvideAdminSettingHandler(settingService, emailService, turnstileService, opsService, paymentConfigService, paymentService, userAttributeService, notificationEmailService, totpService, userService, settingGovernanceService)
 	opsHandler := admin.NewOpsHandler(opsService)
-	updateCache := repository.NewUpdateCache(redisClient)
+	updateCache := repository.NewUpdateCache(universalClient)
 	gitHubReleaseClient := repository.ProvideGitHubReleaseClient(configConfig)
 	serviceBuildInfo := provideServiceBuildInfo(buildInfo)
 	updateService := service.ProvideUpdateService(updateCache, gitHubReleaseClient, serviceBuildInfo)
@@ -235,11 +255,12 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	adminUsageHandler := admin.NewUsageHandler(usageService, apiKeyService, adminService, usageCleanupService)
 	userAttributeHandler := admin.NewUserAttributeHandler(userAttributeService)
 	errorPassthroughRepository := repository.NewErrorPassthroughRepository(client)
-	errorPassthroughCache := repository.NewErrorPassthroughCache(redisClient)
+	errorPassthroughCache := repository.NewErrorPassthroughCache(universalClient)
 	errorPassthroughService := service.NewErrorPassthroughService(errorPassthroughRepository, errorPassthroughCache)
 	errorPassthroughHandler := admin.NewErrorPassthroughHandler(errorPassthroughService)
 	tlsFingerprintProfileHandler := admin.NewTLSFingerprintProfileHandler(tlsFingerprintProfileService)
-	adminAPIKeyHandler := admin.NewAdminAPIKeyHandler(adminService)
+	apiKeyImportService := service.NewAPIKeyImportService(apiKeyService, userRepository, notificationEmailService)
+	adminAPIKeyHandler := admin.NewAdminAPIKeyHandler(adminService, apiKeyImportService)
 	scheduledTestPlanRepository := repository.NewScheduledTestPlanRepository(db)
 	scheduledTestResultRepository := repository.NewScheduledTestResultRepository(db)
 	scheduledTestService := service.ProvideScheduledTestService(scheduledTestPlanRepository, scheduledTestResultRepository)
@@ -250,12 +271,12 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	channelMonitorRequestTemplateService := service.NewChannelMonitorRequestTemplateService(channelMonitorRequestTemplateRepository)
 	channelMonitorRequestTemplateHandler := admin.NewChannelMonitorRequestTemplateHandler(channelMonitorRequestTemplateService)
 	contentModerationRepository := repository.NewContentModerationRepository(db)
-	contentModerationHashCache := repository.NewContentModerationHashCache(redisClient)
+	contentModerationHashCache := repository.NewContentModerationHashCache(universalClient)
 	contentModerationService := service.NewContentModerationService(settingRepository, contentModerationRepository, contentModerationHashCache, groupRepository, userRepository, apiKeyAuthCacheInvalidator, emailService)
 	contentModerationHandler := admin.NewContentModerationHandler(contentModerationService)
-	configManager := securityaudit.NewConfigManager(db, settingRepository, redisClient, secretEncryptor)
+	configManager := securityaudit.NewConfigManager(db, settingRepository, universalClient, secretEncryptor)
 	postgreSQLRepository := securityaudit.NewPostgreSQLRepository(db)
-	redisPayloadStore := securityaudit.NewRedisPayloadStore(redisClient)
+	redisPayloadStore := securityaudit.NewRedisPayloadStore(universalClient)
 	openAICompatibleScanner := securityaudit.NewOpenAICompatibleScanner()
 	atomicMetrics := securityaudit.NewAtomicMetrics()
 	promptService := securityaudit.NewPromptService(configManager, postgreSQLRepository, redisPayloadStore, openAICompatibleScanner, atomicMetrics)
@@ -266,59 +287,128 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	auditLogRepository := repository.NewAuditLogRepository(db)
 	auditLogService := service.ProvideAuditLogService(auditLogRepository, settingService)
 	auditLogHandler := admin.NewAuditLogHandler(auditLogService, totpService)
-	upstreamBillingProbeService := service.ProvideUpstreamBillingProbeService(accountRepository, accountTestService, settingService, leaderLockCache, db)
-	adminHandlers := handler.ProvideAdminHandlers(dashboardHandler, adminUserHandler, groupHandler, accountHandler, adminAnnouncementHandler, dataManagementHandler, backupHandler, oAuthHandler, openAIOAuthHandler, geminiOAuthHandler, antigravityOAuthHandler, grokOAuthHandler, proxyHandler, adminRedeemHandler, promoHandler, settingHandler, opsHandler, systemHandler, adminSubscriptionHandler, adminUsageHandler, userAttributeHandler, errorPassthroughHandler, tlsFingerprintProfileHandler, adminAPIKeyHandler, scheduledTestHandler, channelHandler, channelMonitorHandler, channelMonitorRequestTemplateHandler, contentModerationHandler, promptAdminHandler, paymentHandler, affiliateHandler, complianceHandler, auditLogHandler, upstreamBillingProbeService)
+	startupDiagnosticsRepository := repository.NewStartupDiagnosticsRepository(db, universalClient)
+	startupDiagnosticsService := service.NewStartupDiagnosticsService(startupDiagnosticsRepository, settingService, proxyRepository, proxyExitInfoProber, configConfig)
+	diagnosticsHandler := admin.NewDiagnosticsHandler(startupDiagnosticsService)
+	billingAdjustmentRepository := repository.NewBillingAdjustmentRepository(db)
+	billingAdjustmentService := service.NewBillingAdjustmentService(billingAdjustmentRepository, apiKeyAuthCacheInvalidator, billingCacheService)
+	billingAdjustmentHandler := admin.NewBillingAdjustmentHandler(billingAdjustmentService)
+	accountMetadataRepository := repository.NewAccountMetadataRepository(db)
+	accountMetadataService := service.NewAccountMetadataService(accountMetadataRepository)
+	accountMetadataHandler := admin.NewAccountMetadataHandler(accountMetadataService)
+	accountCredentialSourceRepository := repository.NewAccountCredentialSourceRepository(db)
+	credentialSecretFetcher := repository.NewCredentialSecretFetcher(configConfig)
+	accountCredentialSyncService := service.ProvideAccountCredentialSyncService(accountCredentialSourceRepository, accountRepository, credentialSecretFetcher, compositeTokenCacheInvalidator, leaderLockCache, db, configConfig)
+	accountCredentialSourceHandler := admin.NewAccountCredentialSourceHandler(accountCredentialSyncService)
+	accountSnapshotRepository := repository.NewAccountSnapshotRepository(db)
+	accountSnapshotService := service.ProvideAccountSnapshotService(accountSnapshotRepository, accountRepository, leaderLockCache, db, configConfig)
+	accountSnapshotHandler := admin.NewAccountSnapshotHandler(accountSnapshotService)
+	groupBudgetRepository := repository.NewGroupBudgetRepository(db)
+	groupBudgetService := service.ProvideGroupBudgetService(groupBudgetRepository, groupRepository, opsRepository, gatewayService, openAIGatewayService)
+	groupBudgetHandler := admin.NewGroupBudgetHandler(groupBudgetService)
+	activeRequestRegistry := service.ProvideActiveRequestRegistry(configConfig)
+	activeRequestHandler := admin.NewActiveRequestHandler(activeRequestRegistry)
+	featureFlagHandler := admin.NewFeatureFlagHandler(featureFlagService)
+	tokenCacheHandler := admin.NewTokenCacheHandler(compositeTokenCacheInvalidator)
+	upstreamStreamCaptureRepository := repository.NewUpstreamStreamCaptureRepository(db)
+	upstreamStreamCaptureService := service.ProvideUpstreamStreamCaptureService(upstreamStreamCaptureRepository, backupService, configConfig)
+	streamCaptureHandler := admin.NewStreamCaptureHandler(upstreamStreamCaptureService, usageService)
+	usageArchiveRepository := repository.NewUsageArchiveRepository(db)
+	usageArchiveService := service.ProvideUsageArchiveService(usageArchiveRepository, backupService, leaderLockCache, db, configConfig)
+	usageArchiveHandler := admin.NewUsageArchiveHandler(usageArchiveService)
+	modelAliasRepository := repository.NewModelAliasRepository(db)
+	modelAliasLearningService := service.ProvideModelAliasLearningService(modelAliasRepository, rateLimitService, channelService, configConfig)
+	modelAliasHandler := admin.NewModelAliasHandler(modelAliasLearningService)
+	accountCostAnomalyRepository := repository.NewAccountCostAnomalyRepository(db)
+	accountCostAnomalyService := service.ProvideAccountCostAnomalyService(accountCostAnomalyRepository, accountRepository, opsRepository, leaderLockCache, db, configConfig)
+	costAnomalyHandler := admin.NewCostAnomalyHandler(accountCostAnomalyService)
+	accountSmokeTestRepository := repository.NewAccountSmokeTestRepository(db)
+	accountSmokeTestService := service.ProvideAccountSmokeTestService(accountSmokeTestRepository, accountRepository, accountTestService, opsRepository, leaderLockCache, db, configConfig)
+	accountSmokeTestHandler := admin.NewAccountSmokeTestHandler(accountSmokeTestService)
 	usageRecordWorkerPool := service.NewUsageRecordWorkerPool(configConfig)
-	userMsgQueueCache := repository.NewUserMsgQueueCache(redisClient)
+	supportBundleService := service.NewSupportBundleService(configConfig, serviceBuildInfo, opsService, usageRecordWorkerPool, startupDiagnosticsService)
+	supportBundleHandler := admin.NewSupportBundleHandler(supportBundleService)
+	upstreamBillingProbeService := service.ProvideUpstreamBillingProbeService(accountRepository, accountTestService, settingService, leaderLockCache, db)
+	adminListVersionRepository := repository.NewAdminListVersionRepository(db)
+	adminListVersionService := service.NewAdminListVersionService(adminListVersionRepository, accountRepository)
+	accountAttributionRepository := repository.NewAccountAttributionRepository(db)
+	accountAttributionService := service.NewAccountAttributionService(accountAttributionRepository, accountRepository)
+	usageRecostService := service.NewUsageRecostService(configConfig, pricingService, billingService, usageService)
+	accountWarmupPolicy := service.NewAccountWarmupPolicy(configConfig)
+	contentPolicyCooldownCache := repository.NewContentPolicyCooldownCache(universalClient)
+	contentPolicyCooldownService := service.ProvideContentPolicyCooldownService(configConfig, contentPolicyCooldownCache, rateLimitService, gatewayService, openAIGatewayService, geminiMessagesCompatService)
+	adminHandlers := handler.ProvideAdminHandlers(dashboardHandler, adminUserHandler, groupHandler, accountHandler, adminAnnouncementHandler, dataManagementHandler, backupHandler, oAuthHandler, openAIOAuthHandler, geminiOAuthHandler, antigravityOAuthHandler, grokOAuthHandler, proxyHandler, adminRedeemHandler, promoHandler, settingHandler, opsHandler, systemHandler, adminSubscriptionHandler, adminUsageHandler, userAttributeHandler, errorPassthroughHandler, tlsFingerprintProfileHandler, adminAPIKeyHandler, scheduledTestHandler, channelHandler, channelMonitorHandler, channelMonitorRequestTemplateHandler, contentModerationHandler, promptAdminHandler, paymentHandler, affiliateHandler, complianceHandler, auditLogHandler, diagnosticsHandler, billingAdjustmentHandler, accountMetadataHandler, accountCredentialSourceHandler, accountSnapshotHandler, groupBudgetHandler, activeRequestHandler, featureFlagHandler, tokenCacheHandler, streamCaptureHandler, usageArchiveHandler, modelAliasHandler, costAnomalyHandler, accountSmokeTestHandler, supportBundleHandler, upstreamBillingProbeService, adminListVersionService, accountAttributionService, usageRecostService, accountWarmupPolicy, contentPolicyCooldownService)
+	userMsgQueueCache := repository.NewUserMsgQueueCache(universalClient)
 	userMessageQueueService := service.ProvideUserMessageQueueService(userMsgQueueCache, rpmCache, configConfig)
 	legacyEngine := securityaudit.NewLegacyModerationAdapter(contentModerationService)
 	coordinator := securityaudit.NewCoordinator(legacyEngine, promptService)
-	gatewayHandler := handler.ProvideGatewayHandler(gatewayService, openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, userService, concurrencyService, billingCacheService, usageService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, contentModerationService, userMessageQueueService, configConfig, settingService, coordinator)
-	openAIGatewayHandler := handler.ProvideOpenAIGatewayHandler(openAIGatewayService, concurrencyService, billingCacheService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, contentModerationService, opsService, grokQuotaService, configConfig, coordinator)
+	conversationTranscriptRepository := repository.NewConversationTranscriptRepository(db)
+	conversationTranscriptService := service.ProvideConversationTranscriptService(conversationTranscriptRepository, secretEncryptor, configConfig)
+	gatewayHandler := handler.ProvideGatewayHandler(gatewayService, openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, userService, concurrencyService, billingCacheService, usageService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, contentModerationService, userMessageQueueService, configConfig, settingService, coordinator, conversationTranscriptService)
+	openAIGatewayHandler := handler.ProvideOpenAIGatewayHandler(openAIGatewayService, concurrencyService, billingCacheService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, contentModerationService, opsService, grokQuotaService, configConfig, coordinator, conversationTranscriptService)
 	handlerSettingHandler := handler.ProvideSettingHandler(settingService, buildInfo, notificationEmailService)
 	totpHandler := handler.NewTotpHandler(totpService)
 	handlerPaymentHandler := handler.NewPaymentHandler(paymentService, paymentConfigService)
 	paymentWebhookHandler := handler.NewPaymentWebhookHandler(paymentService, registry)
 	availableChannelHandler := handler.NewAvailableChannelHandler(channelService, apiKeyService, settingService)
-	imageTaskStore := repository.NewImageTaskStore(redisClient)
-	imageTaskService := service.ProvideImageTaskService(imageTaskStore, imageStorageSettingService)
+	imageTaskStore := repository.NewImageTaskStore(universalClient)
+	imageTaskService := service.ProvideImageTaskService(imageTaskStore, imageStorageSettingService, userNotificationService)
 	asyncImageHandler := handler.NewAsyncImageHandler(imageTaskService, openAIGatewayHandler)
 	batchImageRepository := repository.NewBatchImageRepository(db)
-	batchImageQueue := repository.NewBatchImageQueue(redisClient, configConfig)
+	batchImageQueue := repository.NewBatchImageQueue(universalClient, configConfig)
 	batchImageModelPricingResolver := service.ProvideBatchImageModelPricingResolver(modelPricingResolver)
 	batchImagePublicService := service.NewBatchImagePublicService(batchImageRepository, accountRepository, groupRepository, userGroupRateRepository, batchImageQueue, batchImageModelPricingResolver, usageBillingRepository, apiKeyAuthCacheInvalidator, configConfig)
-	batchImageDownloadLimiter := repository.NewBatchImageDownloadLimiter(redisClient, configConfig)
+	batchImageDownloadLimiter := repository.NewBatchImageDownloadLimiter(universalClient, configConfig)
 	batchImageDownloadService := service.NewBatchImageDownloadService(batchImageRepository, accountRepository, batchImageDownloadLimiter, configConfig)
 	batchImageCleanupService := service.ProvideBatchImageCleanupService(batchImageRepository, accountRepository, configConfig)
 	batchImageHandler := handler.ProvideBatchImageHandler(batchImagePublicService, batchImageDownloadService, batchImageCleanupService, openAIGatewayHandler)
+	conversationTranscriptHandler := handler.NewConversationTranscriptHandler(conversationTranscriptService)
+	taskHistoryRepository := repository.NewTaskHistoryRepository(db)
+	taskHistoryService := service.NewTaskHistoryService(taskHistoryRepository)
+	taskHistoryHandler := handler.NewTaskHistoryHandler(taskHistoryService)
+	handlerBillingAdjustmentHandler := handler.NewBillingAdjustmentHandler(billingAdjustmentService)
+	autoscalingSignalsService := service.NewAutoscalingSignalsService(configConfig, opsService, usageRecordWorkerPool, activeRequestRegistry)
+	autoscalingMetricsHandler := handler.NewAutoscalingMetricsHandler(autoscalingSignalsService, configConfig)
 	idempotencyCoordinator := service.ProvideIdempotencyCoordinator(idempotencyRepository, configConfig)
 	idempotencyCleanupService := service.ProvideIdempotencyCleanupService(idempotencyRepository, configConfig)
-	handlers := handler.ProvideHandlers(authHandler, userHandler, apiKeyHandler, usageHandler, redeemHandler, subscriptionHandler, announcementHandler, channelMonitorUserHandler, adminHandlers, gatewayHandler, openAIGatewayHandler, handlerSettingHandler, totpHandler, handlerPaymentHandler, paymentWebhookHandler, availableChannelHandler, asyncImageHandler, batchImageHandler, idempotencyCoordinator, idempotencyCleanupService)
+	userNotificationHandler := handler.NewUserNotificationHandler(userNotificationService)
+	handlers := handler.ProvideHandlers(authHandler, userHandler, apiKeyHandler, usageHandler, redeemHandler, subscriptionHandler, announcementHandler, userNotificationHandler, channelMonitorUserHandler, adminHandlers, gatewayHandler, openAIGatewayHandler, handlerSettingHandler, totpHandler, handlerPaymentHandler, paymentWebhookHandler, availableChannelHandler, asyncImageHandler, batchImageHandler, conversationTranscriptHandler, taskHistoryHandler, handlerBillingAdjustmentHandler, autoscalingMetricsHandler, idempotencyCoordinator, idempotencyCleanupService)
 	jwtAuthMiddleware := middleware.NewJWTAuthMiddleware(authService, userService, settingService, auditLogService)
 	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(authService, userService, settingService, auditLogService)
 	apiKeyAuthMiddleware := middleware.NewAPIKeyAuthMiddleware(apiKeyService, subscriptionService, configConfig)
 	auditLogMiddleware := middleware.NewAuditLogMiddleware(auditLogService)
 	stepUpAuthMiddleware := middleware.NewStepUpAuthMiddleware(totpService, userService, settingService)
-	engine := server.ProvideRouter(configConfig, handlers, jwtAuthMiddleware, adminAuthMiddleware, apiKeyAuthMiddleware, auditLogMiddleware, stepUpAuthMiddleware, apiKeyService, subscriptionService, opsService, settingService, redisClient)
+	requestMirrorService := service.NewRequestMirrorService(configConfig)
+	sseResumeCache := repository.NewSSEResumeCache(universalClient)
+	sseResumeService := service.NewSSEResumeService(sseResumeCache, configConfig)
+	engine := server.ProvideRouter(configConfig, handlers, jwtAuthMiddleware, adminAuthMiddleware, apiKeyAuthMiddleware, auditLogMiddleware, stepUpAuthMiddleware, apiKeyService, subscriptionService, opsService, requestMirrorService, sseResumeService, activeRequestRegistry, upstreamStreamCaptureService, settingService, featureFlagService, manager, universalClient)
 	httpServer := server.ProvideHTTPServer(configConfig, engine)
-	opsMetricsCollector := service.ProvideOpsMetricsCollector(opsRepository, settingRepository, accountRepository, concurrencyService, db, redisClient, configConfig)
-	opsAggregationService := service.ProvideOpsAggregationService(opsRepository, settingRepository, db, redisClient, configConfig)
-	opsAlertEvaluatorService := service.ProvideOpsAlertEvaluatorService(opsService, opsRepository, emailService, redisClient, configConfig, proxyRepository)
-	opsCleanupService := service.ProvideOpsCleanupService(opsRepository, db, redisClient, configConfig, channelMonitorService, settingRepository, opsService)
-	opsScheduledReportService := service.ProvideOpsScheduledReportService(opsService, userService, emailService, redisClient, configConfig)
+	opsMetricsCollector := service.ProvideOpsMetricsCollector(opsRepository, settingRepository, accountRepository, concurrencyService, db, universalClient, configConfig)
+	opsAggregationService := service.ProvideOpsAggregationService(opsRepository, settingRepository, db, universalClient, configConfig)
+	opsAlertEvaluatorService := service.ProvideOpsAlertEvaluatorService(opsService, opsRepository, emailService, universalClient, configConfig, proxyRepository)
+	opsCleanupService := service.ProvideOpsCleanupService(opsRepository, db, universalClient, configConfig, channelMonitorService, settingRepository, opsService)
+	opsScheduledReportService := service.ProvideOpsScheduledReportService(opsService, userService, emailService, universalClient, configConfig)
 	opsIngressRejectAggregator := service.ProvideOpsIngressRejectAggregator(opsRepository, opsService)
 	accountExpiryService := service.ProvideAccountExpiryService(accountRepository)
+	accountRenewalReminderService := service.ProvideAccountRenewalReminderService(accountMetadataRepository, opsRepository, leaderLockCache, db, configConfig)
 	proxyExpiryService := service.ProvideProxyExpiryService(proxyRepository)
-	subscriptionExpiryService := service.ProvideSubscriptionExpiryService(userSubscriptionRepository, settingRepository, notificationEmailService, leaderLockCache, db)
-	batchImageWorkerRuntime := service.ProvideBatchImageWorkerRuntime(batchImageRepository, accountRepository, batchImageQueue, usageBillingRepository, usageLogRepository, batchImageModelPricingResolver, apiKeyAuthCacheInvalidator, configConfig)
+	subscriptionExpiryService := service.ProvideSubscriptionExpiryService(userSubscriptionRepository, settingRepository, notificationEmailService, userNotificationService, leaderLockCache, db)
+	batchImageWorkerRuntime := service.ProvideBatchImageWorkerRuntime(batchImageRepository, accountRepository, batchImageQueue, usageBillingRepository, usageLogRepository, batchImageModelPricingResolver, apiKeyAuthCacheInvalidator, userNotificationService, configConfig)
+	databaseHealthMonitor := service.ProvideDatabaseHealthMonitor(startupDiagnosticsRepository, configConfig)
+	redisProbe := repository.NewRedisHealthProbe(universalClient, redisReadReplica, configConfig)
+	redisHealthMonitor := service.ProvideRedisHealthMonitor(redisProbe, configConfig)
 	scheduledTestRunnerService := service.ProvideScheduledTestRunnerService(scheduledTestPlanRepository, scheduledTestService, accountTestService, rateLimitService, configConfig)
-	paymentOrderExpiryService := service.ProvidePaymentOrderExpiryService(paymentService, leaderLockCache, db)
+	paymentOrderExpiryService := service.ProvidePaymentOrderExpiryService(paymentService, leaderLockCache, db, featureFlagService)
 	channelMonitorRunner := service.ProvideChannelMonitorRunner(channelMonitorService, settingService)
 	userPlatformQuotaUsageFlusher := service.ProvideUserPlatformQuotaUsageFlusher(configConfig, billingCache, serviceUserPlatformQuotaRepository, timingWheelService)
-	v := provideCleanup(client, redisClient, opsMetricsCollector, opsAggregationService, opsAlertEvaluatorService, opsCleanupService, opsScheduledReportService, opsSystemLogSink, opsService, opsIngressRejectAggregator, apiKeyService, authCacheInvalidationWorker, schedulerSnapshotService, tokenRefreshService, accountExpiryService, proxyExpiryService, subscriptionExpiryService, usageCleanupService, idempotencyCleanupService, batchImageCleanupService, batchImageWorkerRuntime, pricingService, emailQueueService, billingCacheService, usageRecordWorkerPool, subscriptionService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, grokOAuthService, openAIGatewayService, scheduledTestRunnerService, backupService, paymentOrderExpiryService, channelMonitorRunner, userPlatformQuotaUsageFlusher, upstreamBillingProbeService, auditLogService, promptService)
+	warmStartRepository := repository.NewWarmStartRepository(db)
+	warmStartService := service.NewWarmStartService(warmStartRepository, apiKeyService, settingService, schedulerSnapshotService, configConfig)
+	v := provideCleanup(client, dbReadReplica, universalClient, redisReadReplica, opsMetricsCollector, opsAggregationService, opsAlertEvaluatorService, opsCleanupService, opsScheduledReportService, opsSystemLogSink, opsService, opsIngressRejectAggregator, apiKeyService, authCacheInvalidationWorker, schedulerSnapshotService, tokenRefreshService, accountExpiryService, accountRenewalReminderService, accountSnapshotService, accountCredentialSyncService, userNotificationService, modelAliasLearningService, accountCostAnomalyService, accountSmokeTestService, groupBudgetService, proxyExpiryService, proxyLatencyRouter, subscriptionExpiryService, usageCleanupService, idempotencyCleanupService, batchImageCleanupService, batchImageWorkerRuntime, pricingService, emailQueueService, billingCacheService, usageRecordWorkerPool, usageRecordDeadLetterService, databaseHealthMonitor, redisHealthMonitor, requestMirrorService, subscriptionService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, grokOAuthService, openAIGatewayService, scheduledTestRunnerService, backupService, paymentOrderExpiryService, channelMonitorRunner, userPlatformQuotaUsageFlusher, upstreamBillingProbeService, auditLogService, conversationTranscriptService, upstreamStreamCaptureService, usageArchiveService, promptService)
 	application := &Application{
 		Server:      httpServer,
 		PromptAudit: promptService,
+		Diagnostics: startupDiagnosticsService,
+		WarmStart:   warmStartService,
 		Cleanup:     v,
 	}
 	return application, nil
@@ -329,6 +419,8 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 type Application struct {
 	Server      *http.Server
 	PromptAudit *securityaudit.PromptService
+	Diagnostics *service.StartupDiagnosticsService
+	WarmStart   *service.WarmStartService
 	Cleanup     func()
 }
 
@@ -345,7 +437,9 @@ func provideServiceBuildInfo(buildInfo handler.BuildInfo) service.BuildInfo {
 
 func provideCleanup(
 	entClient *ent.Client,
-	rdb *redis.Client,
+	dbReplica *repository.DBReadReplica,
+	rdb redis.UniversalClient,
+	redisReplica *repository.RedisReadReplica,
 	opsMetricsCollector *service.OpsMetricsCollector,
 	opsAggregation *service.OpsAggregationService,
 	opsAlertEvaluator *service.OpsAlertEvaluatorService,
@@ -359,7 +453,16 @@ func provideCleanup(
 	schedulerSnapshot *service.SchedulerSnapshotService,
 	tokenRefresh *service.TokenRefreshService,
 	accountExpiry *service.AccountExpiryService,
+	accountRenewalReminder *service.AccountRenewalReminderService,
+	accountSnapshot *service.AccountSnapshotService,
+	credentialSync *service.AccountCredentialSyncService,
+	userNotification *service.UserNotificationService,
+	modelAliasLearning *service.ModelAliasLearningService,
+	costAnomaly *service.AccountCostAnomalyService,
+	smokeTest *service.AccountSmokeTestService,
+	groupBudget *service.GroupBudgetService,
 	proxyExpiry *service.ProxyExpiryService,
+	proxyLatencyRouter *service.ProxyLatencyRouter,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
@@ -369,6 +472,10 @@ func provideCleanup(
 	emailQueue *service.EmailQueueService,
 	billingCache *service.BillingCacheService,
 	usageRecordWorkerPool *service.UsageRecordWorkerPool,
+	usageRecordDeadLetter *service.UsageRecordDeadLetterService,
+	dbHealthMonitor *service.DatabaseHealthMonitor,
+	redisHealthMonitor *service.RedisHealthMonitor,
+	requestMirror *service.RequestMirrorService,
 	subscriptionService *service.SubscriptionService,
 	oauth *service.OAuthService,
 	openaiOAuth *service.OpenAIOAuthService,
@@ -383,6 +490,9 @@ func provideCleanup(
 	quotaFlusher *service.UserPlatformQuotaUsageFlusher,
 	upstreamBillingProbe *service.UpstreamBillingProbeService,
 	auditLog *service.AuditLogService,
+	transcript *service.ConversationTranscriptService,
+	streamCapture *service.UpstreamStreamCaptureService,
+	usageArchive *service.UsageArchiveService,
 	promptAudit *securityaudit.PromptService,
 ) func() {
 	return func() {
@@ -449,6 +559,24 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"ConversationTranscriptService", func() error {
+				if transcript != nil {
+					transcript.Stop()
+				}
+				return nil
+			}},
+			{"UpstreamStreamCaptureService", func() error {
+				if streamCapture != nil {
+					streamCapture.Stop()
+				}
+				return nil
+			}},
+			{"UsageArchiveService", func() error {
+				if usageArchive != nil {
+					usageArchive.Stop()
+				}
+				return nil
+			}},
 			{"OpsAlertEvaluatorService", func() error {
 				if opsAlertEvaluator != nil {
 					opsAlertEvaluator.Stop()
@@ -505,10 +633,46 @@ func provideCleanup(
 				accountExpiry.Stop()
 				return nil
 			}},
+			{"AccountRenewalReminderService", func() error {
+				accountRenewalReminder.Stop()
+				return nil
+			}},
+			{"AccountSnapshotService", func() error {
+				accountSnapshot.Stop()
+				return nil
+			}},
+			{"AccountCredentialSyncService", func() error {
+				credentialSync.Stop()
+				return nil
+			}},
+			{"UserNotificationService", func() error {
+				userNotification.Stop()
+				return nil
+			}},
+			{"ModelAliasLearningService", func() error {
+				modelAliasLearning.Stop()
+				return nil
+			}},
+			{"AccountCostAnomalyService", func() error {
+				costAnomaly.Stop()
+				return nil
+			}},
+			{"AccountSmokeTestService", func() error {
+				smokeTest.Stop()
+				return nil
+			}},
+			{"GroupBudgetService", func() error {
+				groupBudget.Stop()
+				return nil
+			}},
 			{"ProxyExpiryService", func() error {
 				proxyExpiry.Stop()
 				return nil
 			}},
+			{"ProxyLatencyRouter", func() error {
+				proxyLatencyRouter.Stop()
+				return nil
+			}},
 			{"SubscriptionExpiryService", func() error {
 				subscriptionExpiry.Stop()
 				return nil
@@ -537,6 +701,24 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"UsageRecordDeadLetterService", func() error {
+				if usageRecordDeadLetter != nil {
+					usageRecordDeadLetter.Stop()
+				}
+				return nil
+			}},
+			{"DatabaseHealthMonitor", func() error {
+				dbHealthMonitor.Stop()
+				return nil
+			}},
+			{"RedisHealthMonitor", func() error {
+				redisHealthMonitor.Stop()
+				return nil
+			}},
+			{"RequestMirrorService", func() error {
+				requestMirror.Stop()
+				return nil
+			}},
 			{"OAuthService", func() error {
 				oauth.Stop()
 				return nil
@@ -604,6 +786,12 @@ func provideCleanup(
 		}
 
 		infraSteps := []cleanupStep{
+			{"DBReadReplica", func() error {
+				return dbReplica.Close()
+			}},
+			{"RedisReadReplica", func() error {
+				return redisReplica.Close()
+			}},
 			{"Redis", func() error {
 				if rdb == nil {
 					return nil
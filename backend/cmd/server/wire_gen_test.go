@@ -40,6 +40,8 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		nil,
 	)
 	accountExpirySvc := service.NewAccountExpiryService(nil, time.Second)
+	accountRenewalReminderSvc := service.NewAccountRenewalReminderService(nil, nil, 7, time.Second)
+	accountSnapshotSvc := service.NewAccountSnapshotService(nil, nil, time.Second, time.Hour)
 	proxyExpirySvc := service.NewProxyExpiryService(nil, time.Second)
 	subscriptionExpirySvc := service.NewSubscriptionExpiryService(nil, time.Second)
 	pricingSvc := service.NewPricingService(cfg, nil)
@@ -51,7 +53,9 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 
 	cleanup := provideCleanup(
 		nil, // entClient
+		nil, // dbReplica
 		nil, // redis
+		nil, // redisReplica
 		&service.OpsMetricsCollector{},
 		&service.OpsAggregationService{},
 		&service.OpsAlertEvaluatorService{},
@@ -65,7 +69,16 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		schedulerSnapshotSvc,
 		tokenRefreshSvc,
 		accountExpirySvc,
+		accountRenewalReminderSvc,
+		accountSnapshotSvc,
+		nil, // credentialSync
+		nil, // userNotification
+		nil, // modelAliasLearning
+		nil, // costAnomaly
+		nil, // smokeTest
+		service.NewGroupBudgetService(nil, nil, nil, time.Second),
 		proxyExpirySvc,
+		nil, // proxyLatencyRouter
 		subscriptionExpirySvc,
 		&service.UsageCleanupService{},
 		idempotencyCleanupSvc,
@@ -75,6 +88,10 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		emailQueueSvc,
 		billingCacheSvc,
 		&service.UsageRecordWorkerPool{},
+		nil, // usageRecordDeadLetter
+		nil, // dbHealthMonitor
+		nil, // redisHealthMonitor
+		nil, // requestMirror
 		&service.SubscriptionService{},
 		oauthSvc,
 		openAIOAuthSvc,
@@ -89,6 +106,9 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		nil, // quotaFlusher
 		nil, // upstreamBillingProbe
 		nil, // auditLog
+		nil, // transcript
+		nil, // streamCapture
+		nil, // usageArchive
 		nil, // promptAudit
 	)
 
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	_ "github.com/Wei-Shaw/sub2api/ent/runtime"
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/repository"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// costEpsilon 与 usage_logs 费用列 DECIMAL(20,10) 的精度对齐，低于该差值视为未变化
+const costEpsilon = 1e-10
+
+type usageRow struct {
+	id             int64
+	model          string
+	serviceTier    string
+	billingMode    string
+	hasChannel     bool
+	tokens         service.UsageTokens
+	rateMultiplier float64
+
+	old costColumns
+}
+
+type costColumns struct {
+	inputCost         float64
+	imageInputCost    float64
+	outputCost        float64
+	reasoningCost     float64
+	imageOutputCost   float64
+	cacheCreationCost float64
+	cacheReadCost     float64
+	totalCost         float64
+	actualCost        float64
+	longContext       bool
+}
+
+type modelSummary struct {
+	rows      int64
+	changed   int64
+	oldTotal  float64
+	newTotal  float64
+	oldActual float64
+	newActual float64
+}
+
+type report struct {
+	scanned  int64
+	repriced int64
+	changed  int64
+	updated  int64
+	skipped  map[string]int64
+	unpriced map[string]int64
+	models   map[string]*modelSummary
+}
+
+func newReport() *report {
+	return &report{
+		skipped:  make(map[string]int64),
+		unpriced: make(map[string]int64),
+		models:   make(map[string]*modelSummary),
+	}
+}
+
+func main() {
+	startRaw := flag.String("start", "", "required RFC3339 range start (inclusive)")
+	endRaw := flag.String("end", "", "required RFC3339 range end (exclusive)")
+	pricingFile := flag.String("pricing-file", "", "pricing snapshot JSON (default: deployed file in pricing.data_dir)")
+	execute := flag.Bool("execute", false, "write recomputed costs (default is dry-run)")
+	batchSize := flag.Int("batch-size", 1000, "scan/update batch size (1-5000)")
+	flag.Parse()
+
+	if *startRaw == "" || *endRaw == "" {
+		log.Fatal("--start and --end are required")
+	}
+	start, err := time.Parse(time.RFC3339, *startRaw)
+	if err != nil {
+		log.Fatalf("invalid --start: %v", err)
+	}
+	end, err := time.Parse(time.RFC3339, *endRaw)
+	if err != nil {
+		log.Fatalf("invalid --end: %v", err)
+	}
+	if !start.Before(end) {
+		log.Fatal("--start must be before --end")
+	}
+	if *batchSize < 1 || *batchSize > 5000 {
+		log.Fatal("--batch-size must be between 1 and 5000")
+	}
+
+	cfg, err := config.LoadForBootstrap()
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	snapshotPath := *pricingFile
+	if snapshotPath == "" {
+		snapshotPath = filepath.Join(cfg.Pricing.DataDir, "model_pricing.json")
+	}
+	snapshot, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		log.Fatalf("read pricing snapshot: %v", err)
+	}
+	pricingService := service.NewPricingService(cfg, nil)
+	if err := pricingService.LoadSnapshot(snapshotPath); err != nil {
+		log.Fatalf("load pricing snapshot: %v", err)
+	}
+	billingService := service.NewBillingService(cfg, pricingService)
+
+	client, db, err := repository.InitEnt(cfg)
+	if err != nil {
+		log.Fatalf("initialize database: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx := context.Background()
+	rep, err := backfill(ctx, db, billingService, start, end, *batchSize, *execute)
+	if err != nil {
+		log.Fatalf("backfill failed: %v", err)
+	}
+
+	digest := sha256.Sum256(snapshot)
+	mode := "dry-run"
+	if *execute {
+		mode = "execute"
+	}
+	fmt.Printf("mode=%s start=%s end=%s pricing_file=%s pricing_sha256=%s\n",
+		mode, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339), snapshotPath, hex.EncodeToString(digest[:]))
+	printReport(rep)
+	if *execute && rep.updated > 0 {
+		fmt.Println("backfill complete; user balances were NOT adjusted. Re-run dashboard aggregation backfill for the same range.")
+	}
+}
+
+func backfill(ctx context.Context, db *sql.DB, billing *service.BillingService, start, end time.Time, batchSize int, execute bool) (*report, error) {
+	rep := newReport()
+	var cursor int64
+	for {
+		batch, err := loadBatch(ctx, db, cursor, start, end, batchSize)
+		if err != nil {
+			return rep, err
+		}
+		if len(batch) == 0 {
+			return rep, nil
+		}
+		cursor = batch[len(batch)-1].id
+
+		updates := make(map[int64]costColumns)
+		for _, row := range batch {
+			rep.scanned++
+			if reason, skip := skipReason(row); skip {
+				rep.skipped[reason]++
+				continue
+			}
+			cost, err := billing.CalculateCostWithServiceTier(row.model, row.tokens, row.rateMultiplier, row.serviceTier)
+			if err != nil {
+				rep.unpriced[row.model]++
+				continue
+			}
+			next := costColumnsFromBreakdown(cost)
+			rep.repriced++
+			changed := costChanged(row.old, next)
+			rep.addModel(row.model, row.old, next, changed)
+			if changed {
+				rep.changed++
+				updates[row.id] = next
+			}
+		}
+		if execute && len(updates) > 0 {
+			n, err := applyUpdates(ctx, db, updates, start, end)
+			if err != nil {
+				return rep, err
+			}
+			rep.updated += n
+		}
+	}
+}
+
+func loadBatch(ctx context.Context, db *sql.DB, cursor int64, start, end time.Time, batchSize int) ([]usageRow, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, model, COALESCE(service_tier, ''), COALESCE(billing_mode, ''), channel_id IS NOT NULL,
+		       input_tokens, image_input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens,
+		       cache_creation_5m_tokens, cache_creation_1h_tokens, image_output_tokens, reasoning_tokens, rate_multiplier,
+		       input_cost, image_input_cost, output_cost, reasoning_cost, image_output_cost, cache_creation_cost, cache_read_cost,
+		       total_cost, actual_cost, long_context_billing_applied
+		FROM usage_logs
+		WHERE id > $1
+		  AND created_at >= $2
+		  AND created_at < $3
+		ORDER BY id ASC
+		LIMIT $4`, cursor, start, end, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	batch := make([]usageRow, 0, batchSize)
+	for rows.Next() {
+		var r usageRow
+		if err := rows.Scan(
+			&r.id, &r.model, &r.serviceTier, &r.billingMode, &r.hasChannel,
+			&r.tokens.InputTokens, &r.tokens.ImageInputTokens, &r.tokens.OutputTokens,
+			&r.tokens.CacheCreationTokens, &r.tokens.CacheReadTokens,
+			&r.tokens.CacheCreation5mTokens, &r.tokens.CacheCreation1hTokens, &r.tokens.ImageOutputTokens,
+			&r.tokens.ReasoningTokens, &r.rateMultiplier,
+			&r.old.inputCost, &r.old.imageInputCost, &r.old.outputCost, &r.old.reasoningCost, &r.old.imageOutputCost,
+			&r.old.cacheCreationCost, &r.old.cacheReadCost, &r.old.totalCost, &r.old.actualCost, &r.old.longContext,
+		); err != nil {
+			return nil, err
+		}
+		batch = append(batch, r)
+	}
+	return batch, rows.Err()
+}
+
+func applyUpdates(ctx context.Context, db *sql.DB, updates map[int64]costColumns, start, end time.Time) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var updated int64
+	for id, c := range updates {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE usage_logs
+			SET input_cost = $2, image_input_cost = $3, output_cost = $4, image_output_cost = $5,
+			    cache_creation_cost = $6, cache_read_cost = $7, total_cost = $8, actual_cost = $9,
+			    long_context_billing_applied = $10, reasoning_cost = $11
+			WHERE id = $1 AND created_at >= $12 AND created_at < $13`,
+			id, c.inputCost, c.imageInputCost, c.outputCost, c.imageOutputCost,
+			c.cacheCreationCost, c.cacheReadCost, c.totalCost, c.actualCost, c.longContext, c.reasoningCost, start, end)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		updated += n
+	}
+	if updated > 0 {
+		// 原地改写不改变 usage_logs 的 id 范围，需递增修订号使管理端用量列表的 ETag 失效
+		if _, err := tx.ExecContext(ctx, `UPDATE usage_log_revision SET revision = revision + 1, updated_at = NOW() WHERE id = 1`); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// skipReason 仅按 token 计费且未走渠道定价的记录可由 token 数重算，其余记录原样保留并计入报告。
+func skipReason(row usageRow) (string, bool) {
+	if row.hasChannel {
+		return "channel_pricing", true
+	}
+	switch mode := strings.TrimSpace(row.billingMode); mode {
+	case "", string(service.BillingModeToken):
+		return "", false
+	default:
+		return "billing_mode_" + mode, true
+	}
+}
+
+func costColumnsFromBreakdown(cost *service.CostBreakdown) costColumns {
+	return costColumns{
+		inputCost:         cost.InputCost,
+		imageInputCost:    cost.ImageInputCost,
+		outputCost:        cost.OutputCost,
+		reasoningCost:     cost.ReasoningCost,
+		imageOutputCost:   cost.ImageOutputCost,
+		cacheCreationCost: cost.CacheCreationCost,
+		cacheReadCost:     cost.CacheReadCost,
+		totalCost:         cost.TotalCost,
+		actualCost:        cost.ActualCost,
+		longContext:       cost.LongContextBillingApplied,
+	}
+}
+
+func costChanged(old, next costColumns) bool {
+	pairs := [][2]float64{
+		{old.inputCost, next.inputCost},
+		{old.imageInputCost, next.imageInputCost},
+		{old.outputCost, next.outputCost},
+		{old.reasoningCost, next.reasoningCost},
+		{old.imageOutputCost, next.imageOutputCost},
+		{old.cacheCreationCost, next.cacheCreationCost},
+		{old.cacheReadCost, next.cacheReadCost},
+		{old.totalCost, next.totalCost},
+		{old.actualCost, next.actualCost},
+	}
+	for _, p := range pairs {
+		if math.Abs(p[0]-p[1]) >= costEpsilon {
+			return true
+		}
+	}
+	return old.longContext != next.longContext
+}
+
+func (r *report) addModel(model string, old, next costColumns, changed bool) {
+	summary := r.models[model]
+	if summary == nil {
+		summary = &modelSummary{}
+		r.models[model] = summary
+	}
+	summary.rows++
+	if changed {
+		summary.changed++
+	}
+	summary.oldTotal += old.totalCost
+	summary.newTotal += next.totalCost
+	summary.oldActual += old.actualCost
+	summary.newActual += next.actualCost
+}
+
+func printReport(rep *report) {
+	var unpricedRows int64
+	for _, n := range rep.unpriced {
+		unpricedRows += n
+	}
+	var skippedRows int64
+	for _, n := range rep.skipped {
+		skippedRows += n
+	}
+	var oldActual, newActual float64
+	for _, s := range rep.models {
+		oldActual += s.oldActual
+		newActual += s.newActual
+	}
+	fmt.Printf("scanned=%d repriced=%d changed=%d updated=%d skipped=%d unpriced=%d old_actual_cost=%.10f new_actual_cost=%.10f delta_actual_cost=%.10f\n",
+		rep.scanned, rep.repriced, rep.changed, rep.updated, skippedRows, unpricedRows, oldActual, newActual, newActual-oldActual)
+
+	for _, model := range sortedKeys(rep.models) {
+		s := rep.models[model]
+		fmt.Printf("model=%s rows=%d changed=%d old_total_cost=%.10f new_total_cost=%.10f old_actual_cost=%.10f new_actual_cost=%.10f delta_actual_cost=%.10f\n",
+			model, s.rows, s.changed, s.oldTotal, s.newTotal, s.oldActual, s.newActual, s.newActual-s.oldActual)
+	}
+	for _, reason := range sortedKeys(rep.skipped) {
+		fmt.Printf("skipped reason=%s count=%d\n", reason, rep.skipped[reason])
+	}
+	for _, model := range sortedKeys(rep.unpriced) {
+		fmt.Printf("unpriced model=%s count=%d\n", model, rep.unpriced[model])
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
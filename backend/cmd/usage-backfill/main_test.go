@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+func TestSkipReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		row    usageRow
+		reason string
+		skip   bool
+	}{
+		{name: "legacy token row", row: usageRow{}, skip: false},
+		{name: "token row", row: usageRow{billingMode: string(service.BillingModeToken)}, skip: false},
+		{name: "channel pricing", row: usageRow{hasChannel: true}, reason: "channel_pricing", skip: true},
+		{name: "per request", row: usageRow{billingMode: "per_request"}, reason: "billing_mode_per_request", skip: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, skip := skipReason(tt.row)
+			if skip != tt.skip || reason != tt.reason {
+				t.Fatalf("got (%q, %v), want (%q, %v)", reason, skip, tt.reason, tt.skip)
+			}
+		})
+	}
+}
+
+func TestCostChanged(t *testing.T) {
+	base := costColumns{inputCost: 0.001, outputCost: 0.002, totalCost: 0.003, actualCost: 0.003}
+
+	same := base
+	same.totalCost += 1e-12
+	if costChanged(base, same) {
+		t.Fatal("sub-precision difference must not count as a change")
+	}
+
+	repriced := base
+	repriced.outputCost = 0.004
+	if !costChanged(base, repriced) {
+		t.Fatal("output cost difference must count as a change")
+	}
+
+	flagged := base
+	flagged.longContext = true
+	if !costChanged(base, flagged) {
+		t.Fatal("long context flag difference must count as a change")
+	}
+}
+
+func TestReportAddModel(t *testing.T) {
+	rep := newReport()
+	rep.addModel("claude-sonnet-4-5", costColumns{totalCost: 1, actualCost: 1}, costColumns{totalCost: 2, actualCost: 1.5}, true)
+	rep.addModel("claude-sonnet-4-5", costColumns{totalCost: 1, actualCost: 1}, costColumns{totalCost: 1, actualCost: 1}, false)
+
+	s := rep.models["claude-sonnet-4-5"]
+	if s.rows != 2 || s.changed != 1 {
+		t.Fatalf("unexpected counts: rows=%d changed=%d", s.rows, s.changed)
+	}
+	if s.oldActual != 2 || s.newActual != 2.5 {
+		t.Fatalf("unexpected actual totals: old=%v new=%v", s.oldActual, s.newActual)
+	}
+}
@@ -23,6 +23,12 @@ type AccountGroup struct {
 	GroupID int64 `json:"group_id,omitempty"`
 	// Priority holds the value of the "priority" field.
 	Priority int `json:"priority,omitempty"`
+	// PriorityOverride holds the value of the "priority_override" field.
+	PriorityOverride *int `json:"priority_override,omitempty"`
+	// Weight holds the value of the "weight" field.
+	Weight int `json:"weight,omitempty"`
+	// ConcurrencyCap holds the value of the "concurrency_cap" field.
+	ConcurrencyCap *int `json:"concurrency_cap,omitempty"`
 	// CreatedAt holds the value of the "created_at" field.
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
@@ -69,7 +75,7 @@ func (*AccountGroup) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case accountgroup.FieldAccountID, accountgroup.FieldGroupID, accountgroup.FieldPriority:
+		case accountgroup.FieldAccountID, accountgroup.FieldGroupID, accountgroup.FieldPriority, accountgroup.FieldPriorityOverride, accountgroup.FieldWeight, accountgroup.FieldConcurrencyCap:
 			values[i] = new(sql.NullInt64)
 		case accountgroup.FieldCreatedAt:
 			values[i] = new(sql.NullTime)
@@ -106,6 +112,26 @@ func (_m *AccountGroup) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.Priority = int(value.Int64)
 			}
+		case accountgroup.FieldPriorityOverride:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field priority_override", values[i])
+			} else if value.Valid {
+				_m.PriorityOverride = new(int)
+				*_m.PriorityOverride = int(value.Int64)
+			}
+		case accountgroup.FieldWeight:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field weight", values[i])
+			} else if value.Valid {
+				_m.Weight = int(value.Int64)
+			}
+		case accountgroup.FieldConcurrencyCap:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field concurrency_cap", values[i])
+			} else if value.Valid {
+				_m.ConcurrencyCap = new(int)
+				*_m.ConcurrencyCap = int(value.Int64)
+			}
 		case accountgroup.FieldCreatedAt:
 			if value, ok := values[i].(*sql.NullTime); !ok {
 				return fmt.Errorf("unexpected type %T for field created_at", values[i])
@@ -166,6 +192,19 @@ func (_m *AccountGroup) String() string {
 	builder.WriteString("priority=")
 	builder.WriteString(fmt.Sprintf("%v", _m.Priority))
 	builder.WriteString(", ")
+	if v := _m.PriorityOverride; v != nil {
+		builder.WriteString("priority_override=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("weight=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Weight))
+	builder.WriteString(", ")
+	if v := _m.ConcurrencyCap; v != nil {
+		builder.WriteString("concurrency_cap=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
 	builder.WriteString("created_at=")
 	builder.WriteString(_m.CreatedAt.Format(time.ANSIC))
 	builder.WriteByte(')')
@@ -18,6 +18,12 @@ const (
 	FieldGroupID = "group_id"
 	// FieldPriority holds the string denoting the priority field in the database.
 	FieldPriority = "priority"
+	// FieldPriorityOverride holds the string denoting the priority_override field in the database.
+	FieldPriorityOverride = "priority_override"
+	// FieldWeight holds the string denoting the weight field in the database.
+	FieldWeight = "weight"
+	// FieldConcurrencyCap holds the string denoting the concurrency_cap field in the database.
+	FieldConcurrencyCap = "concurrency_cap"
 	// FieldCreatedAt holds the string denoting the created_at field in the database.
 	FieldCreatedAt = "created_at"
 	// EdgeAccount holds the string denoting the account edge name in mutations.
@@ -51,6 +57,9 @@ var Columns = []string{
 	FieldAccountID,
 	FieldGroupID,
 	FieldPriority,
+	FieldPriorityOverride,
+	FieldWeight,
+	FieldConcurrencyCap,
 	FieldCreatedAt,
 }
 
@@ -67,6 +76,8 @@ func ValidColumn(column string) bool {
 var (
 	// DefaultPriority holds the default value on creation for the "priority" field.
 	DefaultPriority int
+	// DefaultWeight holds the default value on creation for the "weight" field.
+	DefaultWeight int
 	// DefaultCreatedAt holds the default value on creation for the "created_at" field.
 	DefaultCreatedAt func() time.Time
 )
@@ -89,6 +100,21 @@ func ByPriority(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldPriority, opts...).ToFunc()
 }
 
+// ByPriorityOverride orders the results by the priority_override field.
+func ByPriorityOverride(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPriorityOverride, opts...).ToFunc()
+}
+
+// ByWeight orders the results by the weight field.
+func ByWeight(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldWeight, opts...).ToFunc()
+}
+
+// ByConcurrencyCap orders the results by the concurrency_cap field.
+func ByConcurrencyCap(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldConcurrencyCap, opts...).ToFunc()
+}
+
 // ByCreatedAt orders the results by the created_at field.
 func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldCreatedAt, opts...).ToFunc()
@@ -25,6 +25,21 @@ func Priority(v int) predicate.AccountGroup {
 	return predicate.AccountGroup(sql.FieldEQ(FieldPriority, v))
 }
 
+// PriorityOverride applies equality check predicate on the "priority_override" field. It's identical to PriorityOverrideEQ.
+func PriorityOverride(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldPriorityOverride, v))
+}
+
+// Weight applies equality check predicate on the "weight" field. It's identical to WeightEQ.
+func Weight(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldWeight, v))
+}
+
+// ConcurrencyCap applies equality check predicate on the "concurrency_cap" field. It's identical to ConcurrencyCapEQ.
+func ConcurrencyCap(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldConcurrencyCap, v))
+}
+
 // CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
 func CreatedAt(v time.Time) predicate.AccountGroup {
 	return predicate.AccountGroup(sql.FieldEQ(FieldCreatedAt, v))
@@ -110,6 +125,146 @@ func PriorityLTE(v int) predicate.AccountGroup {
 	return predicate.AccountGroup(sql.FieldLTE(FieldPriority, v))
 }
 
+// PriorityOverrideEQ applies the EQ predicate on the "priority_override" field.
+func PriorityOverrideEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldPriorityOverride, v))
+}
+
+// PriorityOverrideNEQ applies the NEQ predicate on the "priority_override" field.
+func PriorityOverrideNEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNEQ(FieldPriorityOverride, v))
+}
+
+// PriorityOverrideIn applies the In predicate on the "priority_override" field.
+func PriorityOverrideIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldIn(FieldPriorityOverride, vs...))
+}
+
+// PriorityOverrideNotIn applies the NotIn predicate on the "priority_override" field.
+func PriorityOverrideNotIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNotIn(FieldPriorityOverride, vs...))
+}
+
+// PriorityOverrideGT applies the GT predicate on the "priority_override" field.
+func PriorityOverrideGT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGT(FieldPriorityOverride, v))
+}
+
+// PriorityOverrideGTE applies the GTE predicate on the "priority_override" field.
+func PriorityOverrideGTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGTE(FieldPriorityOverride, v))
+}
+
+// PriorityOverrideLT applies the LT predicate on the "priority_override" field.
+func PriorityOverrideLT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLT(FieldPriorityOverride, v))
+}
+
+// PriorityOverrideLTE applies the LTE predicate on the "priority_override" field.
+func PriorityOverrideLTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLTE(FieldPriorityOverride, v))
+}
+
+// PriorityOverrideIsNil applies the IsNil predicate on the "priority_override" field.
+func PriorityOverrideIsNil() predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldIsNull(FieldPriorityOverride))
+}
+
+// PriorityOverrideNotNil applies the NotNil predicate on the "priority_override" field.
+func PriorityOverrideNotNil() predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNotNull(FieldPriorityOverride))
+}
+
+// WeightEQ applies the EQ predicate on the "weight" field.
+func WeightEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldWeight, v))
+}
+
+// WeightNEQ applies the NEQ predicate on the "weight" field.
+func WeightNEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNEQ(FieldWeight, v))
+}
+
+// WeightIn applies the In predicate on the "weight" field.
+func WeightIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldIn(FieldWeight, vs...))
+}
+
+// WeightNotIn applies the NotIn predicate on the "weight" field.
+func WeightNotIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNotIn(FieldWeight, vs...))
+}
+
+// WeightGT applies the GT predicate on the "weight" field.
+func WeightGT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGT(FieldWeight, v))
+}
+
+// WeightGTE applies the GTE predicate on the "weight" field.
+func WeightGTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGTE(FieldWeight, v))
+}
+
+// WeightLT applies the LT predicate on the "weight" field.
+func WeightLT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLT(FieldWeight, v))
+}
+
+// WeightLTE applies the LTE predicate on the "weight" field.
+func WeightLTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLTE(FieldWeight, v))
+}
+
+// ConcurrencyCapEQ applies the EQ predicate on the "concurrency_cap" field.
+func ConcurrencyCapEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldEQ(FieldConcurrencyCap, v))
+}
+
+// ConcurrencyCapNEQ applies the NEQ predicate on the "concurrency_cap" field.
+func ConcurrencyCapNEQ(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNEQ(FieldConcurrencyCap, v))
+}
+
+// ConcurrencyCapIn applies the In predicate on the "concurrency_cap" field.
+func ConcurrencyCapIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldIn(FieldConcurrencyCap, vs...))
+}
+
+// ConcurrencyCapNotIn applies the NotIn predicate on the "concurrency_cap" field.
+func ConcurrencyCapNotIn(vs ...int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNotIn(FieldConcurrencyCap, vs...))
+}
+
+// ConcurrencyCapGT applies the GT predicate on the "concurrency_cap" field.
+func ConcurrencyCapGT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGT(FieldConcurrencyCap, v))
+}
+
+// ConcurrencyCapGTE applies the GTE predicate on the "concurrency_cap" field.
+func ConcurrencyCapGTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldGTE(FieldConcurrencyCap, v))
+}
+
+// ConcurrencyCapLT applies the LT predicate on the "concurrency_cap" field.
+func ConcurrencyCapLT(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLT(FieldConcurrencyCap, v))
+}
+
+// ConcurrencyCapLTE applies the LTE predicate on the "concurrency_cap" field.
+func ConcurrencyCapLTE(v int) predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldLTE(FieldConcurrencyCap, v))
+}
+
+// ConcurrencyCapIsNil applies the IsNil predicate on the "concurrency_cap" field.
+func ConcurrencyCapIsNil() predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldIsNull(FieldConcurrencyCap))
+}
+
+// ConcurrencyCapNotNil applies the NotNil predicate on the "concurrency_cap" field.
+func ConcurrencyCapNotNil() predicate.AccountGroup {
+	return predicate.AccountGroup(sql.FieldNotNull(FieldConcurrencyCap))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.AccountGroup {
 	return predicate.AccountGroup(sql.FieldEQ(FieldCreatedAt, v))
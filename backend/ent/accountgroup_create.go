@@ -50,6 +50,48 @@ func (_c *AccountGroupCreate) SetNillablePriority(v *int) *AccountGroupCreate {
 	return _c
 }
 
+// SetPriorityOverride sets the "priority_override" field.
+func (_c *AccountGroupCreate) SetPriorityOverride(v int) *AccountGroupCreate {
+	_c.mutation.SetPriorityOverride(v)
+	return _c
+}
+
+// SetNillablePriorityOverride sets the "priority_override" field if the given value is not nil.
+func (_c *AccountGroupCreate) SetNillablePriorityOverride(v *int) *AccountGroupCreate {
+	if v != nil {
+		_c.SetPriorityOverride(*v)
+	}
+	return _c
+}
+
+// SetWeight sets the "weight" field.
+func (_c *AccountGroupCreate) SetWeight(v int) *AccountGroupCreate {
+	_c.mutation.SetWeight(v)
+	return _c
+}
+
+// SetNillableWeight sets the "weight" field if the given value is not nil.
+func (_c *AccountGroupCreate) SetNillableWeight(v *int) *AccountGroupCreate {
+	if v != nil {
+		_c.SetWeight(*v)
+	}
+	return _c
+}
+
+// SetConcurrencyCap sets the "concurrency_cap" field.
+func (_c *AccountGroupCreate) SetConcurrencyCap(v int) *AccountGroupCreate {
+	_c.mutation.SetConcurrencyCap(v)
+	return _c
+}
+
+// SetNillableConcurrencyCap sets the "concurrency_cap" field if the given value is not nil.
+func (_c *AccountGroupCreate) SetNillableConcurrencyCap(v *int) *AccountGroupCreate {
+	if v != nil {
+		_c.SetConcurrencyCap(*v)
+	}
+	return _c
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (_c *AccountGroupCreate) SetCreatedAt(v time.Time) *AccountGroupCreate {
 	_c.mutation.SetCreatedAt(v)
@@ -113,6 +155,10 @@ func (_c *AccountGroupCreate) defaults() {
 		v := accountgroup.DefaultPriority
 		_c.mutation.SetPriority(v)
 	}
+	if _, ok := _c.mutation.Weight(); !ok {
+		v := accountgroup.DefaultWeight
+		_c.mutation.SetWeight(v)
+	}
 	if _, ok := _c.mutation.CreatedAt(); !ok {
 		v := accountgroup.DefaultCreatedAt()
 		_c.mutation.SetCreatedAt(v)
@@ -130,6 +176,9 @@ func (_c *AccountGroupCreate) check() error {
 	if _, ok := _c.mutation.Priority(); !ok {
 		return &ValidationError{Name: "priority", err: errors.New(`ent: missing required field "AccountGroup.priority"`)}
 	}
+	if _, ok := _c.mutation.Weight(); !ok {
+		return &ValidationError{Name: "weight", err: errors.New(`ent: missing required field "AccountGroup.weight"`)}
+	}
 	if _, ok := _c.mutation.CreatedAt(); !ok {
 		return &ValidationError{Name: "created_at", err: errors.New(`ent: missing required field "AccountGroup.created_at"`)}
 	}
@@ -166,6 +215,18 @@ func (_c *AccountGroupCreate) createSpec() (*AccountGroup, *sqlgraph.CreateSpec)
 		_spec.SetField(accountgroup.FieldPriority, field.TypeInt, value)
 		_node.Priority = value
 	}
+	if value, ok := _c.mutation.PriorityOverride(); ok {
+		_spec.SetField(accountgroup.FieldPriorityOverride, field.TypeInt, value)
+		_node.PriorityOverride = &value
+	}
+	if value, ok := _c.mutation.Weight(); ok {
+		_spec.SetField(accountgroup.FieldWeight, field.TypeInt, value)
+		_node.Weight = value
+	}
+	if value, ok := _c.mutation.ConcurrencyCap(); ok {
+		_spec.SetField(accountgroup.FieldConcurrencyCap, field.TypeInt, value)
+		_node.ConcurrencyCap = &value
+	}
 	if value, ok := _c.mutation.CreatedAt(); ok {
 		_spec.SetField(accountgroup.FieldCreatedAt, field.TypeTime, value)
 		_node.CreatedAt = value
@@ -298,6 +359,72 @@ func (u *AccountGroupUpsert) AddPriority(v int) *AccountGroupUpsert {
 	return u
 }
 
+// SetPriorityOverride sets the "priority_override" field.
+func (u *AccountGroupUpsert) SetPriorityOverride(v int) *AccountGroupUpsert {
+	u.Set(accountgroup.FieldPriorityOverride, v)
+	return u
+}
+
+// UpdatePriorityOverride sets the "priority_override" field to the value that was provided on create.
+func (u *AccountGroupUpsert) UpdatePriorityOverride() *AccountGroupUpsert {
+	u.SetExcluded(accountgroup.FieldPriorityOverride)
+	return u
+}
+
+// AddPriorityOverride adds v to the "priority_override" field.
+func (u *AccountGroupUpsert) AddPriorityOverride(v int) *AccountGroupUpsert {
+	u.Add(accountgroup.FieldPriorityOverride, v)
+	return u
+}
+
+// ClearPriorityOverride clears the value of the "priority_override" field.
+func (u *AccountGroupUpsert) ClearPriorityOverride() *AccountGroupUpsert {
+	u.SetNull(accountgroup.FieldPriorityOverride)
+	return u
+}
+
+// SetWeight sets the "weight" field.
+func (u *AccountGroupUpsert) SetWeight(v int) *AccountGroupUpsert {
+	u.Set(accountgroup.FieldWeight, v)
+	return u
+}
+
+// UpdateWeight sets the "weight" field to the value that was provided on create.
+func (u *AccountGroupUpsert) UpdateWeight() *AccountGroupUpsert {
+	u.SetExcluded(accountgroup.FieldWeight)
+	return u
+}
+
+// AddWeight adds v to the "weight" field.
+func (u *AccountGroupUpsert) AddWeight(v int) *AccountGroupUpsert {
+	u.Add(accountgroup.FieldWeight, v)
+	return u
+}
+
+// SetConcurrencyCap sets the "concurrency_cap" field.
+func (u *AccountGroupUpsert) SetConcurrencyCap(v int) *AccountGroupUpsert {
+	u.Set(accountgroup.FieldConcurrencyCap, v)
+	return u
+}
+
+// UpdateConcurrencyCap sets the "concurrency_cap" field to the value that was provided on create.
+func (u *AccountGroupUpsert) UpdateConcurrencyCap() *AccountGroupUpsert {
+	u.SetExcluded(accountgroup.FieldConcurrencyCap)
+	return u
+}
+
+// AddConcurrencyCap adds v to the "concurrency_cap" field.
+func (u *AccountGroupUpsert) AddConcurrencyCap(v int) *AccountGroupUpsert {
+	u.Add(accountgroup.FieldConcurrencyCap, v)
+	return u
+}
+
+// ClearConcurrencyCap clears the value of the "concurrency_cap" field.
+func (u *AccountGroupUpsert) ClearConcurrencyCap() *AccountGroupUpsert {
+	u.SetNull(accountgroup.FieldConcurrencyCap)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -392,6 +519,83 @@ func (u *AccountGroupUpsertOne) UpdatePriority() *AccountGroupUpsertOne {
 	})
 }
 
+// SetPriorityOverride sets the "priority_override" field.
+func (u *AccountGroupUpsertOne) SetPriorityOverride(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetPriorityOverride(v)
+	})
+}
+
+// AddPriorityOverride adds v to the "priority_override" field.
+func (u *AccountGroupUpsertOne) AddPriorityOverride(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddPriorityOverride(v)
+	})
+}
+
+// UpdatePriorityOverride sets the "priority_override" field to the value that was provided on create.
+func (u *AccountGroupUpsertOne) UpdatePriorityOverride() *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdatePriorityOverride()
+	})
+}
+
+// ClearPriorityOverride clears the value of the "priority_override" field.
+func (u *AccountGroupUpsertOne) ClearPriorityOverride() *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.ClearPriorityOverride()
+	})
+}
+
+// SetWeight sets the "weight" field.
+func (u *AccountGroupUpsertOne) SetWeight(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetWeight(v)
+	})
+}
+
+// AddWeight adds v to the "weight" field.
+func (u *AccountGroupUpsertOne) AddWeight(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddWeight(v)
+	})
+}
+
+// UpdateWeight sets the "weight" field to the value that was provided on create.
+func (u *AccountGroupUpsertOne) UpdateWeight() *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdateWeight()
+	})
+}
+
+// SetConcurrencyCap sets the "concurrency_cap" field.
+func (u *AccountGroupUpsertOne) SetConcurrencyCap(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetConcurrencyCap(v)
+	})
+}
+
+// AddConcurrencyCap adds v to the "concurrency_cap" field.
+func (u *AccountGroupUpsertOne) AddConcurrencyCap(v int) *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddConcurrencyCap(v)
+	})
+}
+
+// UpdateConcurrencyCap sets the "concurrency_cap" field to the value that was provided on create.
+func (u *AccountGroupUpsertOne) UpdateConcurrencyCap() *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdateConcurrencyCap()
+	})
+}
+
+// ClearConcurrencyCap clears the value of the "concurrency_cap" field.
+func (u *AccountGroupUpsertOne) ClearConcurrencyCap() *AccountGroupUpsertOne {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.ClearConcurrencyCap()
+	})
+}
+
 // Exec executes the query.
 func (u *AccountGroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -629,6 +833,83 @@ func (u *AccountGroupUpsertBulk) UpdatePriority() *AccountGroupUpsertBulk {
 	})
 }
 
+// SetPriorityOverride sets the "priority_override" field.
+func (u *AccountGroupUpsertBulk) SetPriorityOverride(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetPriorityOverride(v)
+	})
+}
+
+// AddPriorityOverride adds v to the "priority_override" field.
+func (u *AccountGroupUpsertBulk) AddPriorityOverride(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddPriorityOverride(v)
+	})
+}
+
+// UpdatePriorityOverride sets the "priority_override" field to the value that was provided on create.
+func (u *AccountGroupUpsertBulk) UpdatePriorityOverride() *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdatePriorityOverride()
+	})
+}
+
+// ClearPriorityOverride clears the value of the "priority_override" field.
+func (u *AccountGroupUpsertBulk) ClearPriorityOverride() *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.ClearPriorityOverride()
+	})
+}
+
+// SetWeight sets the "weight" field.
+func (u *AccountGroupUpsertBulk) SetWeight(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetWeight(v)
+	})
+}
+
+// AddWeight adds v to the "weight" field.
+func (u *AccountGroupUpsertBulk) AddWeight(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddWeight(v)
+	})
+}
+
+// UpdateWeight sets the "weight" field to the value that was provided on create.
+func (u *AccountGroupUpsertBulk) UpdateWeight() *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdateWeight()
+	})
+}
+
+// SetConcurrencyCap sets the "concurrency_cap" field.
+func (u *AccountGroupUpsertBulk) SetConcurrencyCap(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.SetConcurrencyCap(v)
+	})
+}
+
+// AddConcurrencyCap adds v to the "concurrency_cap" field.
+func (u *AccountGroupUpsertBulk) AddConcurrencyCap(v int) *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.AddConcurrencyCap(v)
+	})
+}
+
+// UpdateConcurrencyCap sets the "concurrency_cap" field to the value that was provided on create.
+func (u *AccountGroupUpsertBulk) UpdateConcurrencyCap() *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.UpdateConcurrencyCap()
+	})
+}
+
+// ClearConcurrencyCap clears the value of the "concurrency_cap" field.
+func (u *AccountGroupUpsertBulk) ClearConcurrencyCap() *AccountGroupUpsertBulk {
+	return u.Update(func(s *AccountGroupUpsert) {
+		s.ClearConcurrencyCap()
+	})
+}
+
 // Exec executes the query.
 func (u *AccountGroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -78,6 +78,81 @@ func (_u *AccountGroupUpdate) AddPriority(v int) *AccountGroupUpdate {
 	return _u
 }
 
+// SetPriorityOverride sets the "priority_override" field.
+func (_u *AccountGroupUpdate) SetPriorityOverride(v int) *AccountGroupUpdate {
+	_u.mutation.ResetPriorityOverride()
+	_u.mutation.SetPriorityOverride(v)
+	return _u
+}
+
+// SetNillablePriorityOverride sets the "priority_override" field if the given value is not nil.
+func (_u *AccountGroupUpdate) SetNillablePriorityOverride(v *int) *AccountGroupUpdate {
+	if v != nil {
+		_u.SetPriorityOverride(*v)
+	}
+	return _u
+}
+
+// AddPriorityOverride adds value to the "priority_override" field.
+func (_u *AccountGroupUpdate) AddPriorityOverride(v int) *AccountGroupUpdate {
+	_u.mutation.AddPriorityOverride(v)
+	return _u
+}
+
+// ClearPriorityOverride clears the value of the "priority_override" field.
+func (_u *AccountGroupUpdate) ClearPriorityOverride() *AccountGroupUpdate {
+	_u.mutation.ClearPriorityOverride()
+	return _u
+}
+
+// SetWeight sets the "weight" field.
+func (_u *AccountGroupUpdate) SetWeight(v int) *AccountGroupUpdate {
+	_u.mutation.ResetWeight()
+	_u.mutation.SetWeight(v)
+	return _u
+}
+
+// SetNillableWeight sets the "weight" field if the given value is not nil.
+func (_u *AccountGroupUpdate) SetNillableWeight(v *int) *AccountGroupUpdate {
+	if v != nil {
+		_u.SetWeight(*v)
+	}
+	return _u
+}
+
+// AddWeight adds value to the "weight" field.
+func (_u *AccountGroupUpdate) AddWeight(v int) *AccountGroupUpdate {
+	_u.mutation.AddWeight(v)
+	return _u
+}
+
+// SetConcurrencyCap sets the "concurrency_cap" field.
+func (_u *AccountGroupUpdate) SetConcurrencyCap(v int) *AccountGroupUpdate {
+	_u.mutation.ResetConcurrencyCap()
+	_u.mutation.SetConcurrencyCap(v)
+	return _u
+}
+
+// SetNillableConcurrencyCap sets the "concurrency_cap" field if the given value is not nil.
+func (_u *AccountGroupUpdate) SetNillableConcurrencyCap(v *int) *AccountGroupUpdate {
+	if v != nil {
+		_u.SetConcurrencyCap(*v)
+	}
+	return _u
+}
+
+// AddConcurrencyCap adds value to the "concurrency_cap" field.
+func (_u *AccountGroupUpdate) AddConcurrencyCap(v int) *AccountGroupUpdate {
+	_u.mutation.AddConcurrencyCap(v)
+	return _u
+}
+
+// ClearConcurrencyCap clears the value of the "concurrency_cap" field.
+func (_u *AccountGroupUpdate) ClearConcurrencyCap() *AccountGroupUpdate {
+	_u.mutation.ClearConcurrencyCap()
+	return _u
+}
+
 // SetAccount sets the "account" edge to the Account entity.
 func (_u *AccountGroupUpdate) SetAccount(v *Account) *AccountGroupUpdate {
 	return _u.SetAccountID(v.ID)
@@ -161,6 +236,30 @@ func (_u *AccountGroupUpdate) sqlSave(ctx context.Context) (_node int, err error
 	if value, ok := _u.mutation.AddedPriority(); ok {
 		_spec.AddField(accountgroup.FieldPriority, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.PriorityOverride(); ok {
+		_spec.SetField(accountgroup.FieldPriorityOverride, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedPriorityOverride(); ok {
+		_spec.AddField(accountgroup.FieldPriorityOverride, field.TypeInt, value)
+	}
+	if _u.mutation.PriorityOverrideCleared() {
+		_spec.ClearField(accountgroup.FieldPriorityOverride, field.TypeInt)
+	}
+	if value, ok := _u.mutation.Weight(); ok {
+		_spec.SetField(accountgroup.FieldWeight, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedWeight(); ok {
+		_spec.AddField(accountgroup.FieldWeight, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ConcurrencyCap(); ok {
+		_spec.SetField(accountgroup.FieldConcurrencyCap, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedConcurrencyCap(); ok {
+		_spec.AddField(accountgroup.FieldConcurrencyCap, field.TypeInt, value)
+	}
+	if _u.mutation.ConcurrencyCapCleared() {
+		_spec.ClearField(accountgroup.FieldConcurrencyCap, field.TypeInt)
+	}
 	if _u.mutation.AccountCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -288,6 +387,81 @@ func (_u *AccountGroupUpdateOne) AddPriority(v int) *AccountGroupUpdateOne {
 	return _u
 }
 
+// SetPriorityOverride sets the "priority_override" field.
+func (_u *AccountGroupUpdateOne) SetPriorityOverride(v int) *AccountGroupUpdateOne {
+	_u.mutation.ResetPriorityOverride()
+	_u.mutation.SetPriorityOverride(v)
+	return _u
+}
+
+// SetNillablePriorityOverride sets the "priority_override" field if the given value is not nil.
+func (_u *AccountGroupUpdateOne) SetNillablePriorityOverride(v *int) *AccountGroupUpdateOne {
+	if v != nil {
+		_u.SetPriorityOverride(*v)
+	}
+	return _u
+}
+
+// AddPriorityOverride adds value to the "priority_override" field.
+func (_u *AccountGroupUpdateOne) AddPriorityOverride(v int) *AccountGroupUpdateOne {
+	_u.mutation.AddPriorityOverride(v)
+	return _u
+}
+
+// ClearPriorityOverride clears the value of the "priority_override" field.
+func (_u *AccountGroupUpdateOne) ClearPriorityOverride() *AccountGroupUpdateOne {
+	_u.mutation.ClearPriorityOverride()
+	return _u
+}
+
+// SetWeight sets the "weight" field.
+func (_u *AccountGroupUpdateOne) SetWeight(v int) *AccountGroupUpdateOne {
+	_u.mutation.ResetWeight()
+	_u.mutation.SetWeight(v)
+	return _u
+}
+
+// SetNillableWeight sets the "weight" field if the given value is not nil.
+func (_u *AccountGroupUpdateOne) SetNillableWeight(v *int) *AccountGroupUpdateOne {
+	if v != nil {
+		_u.SetWeight(*v)
+	}
+	return _u
+}
+
+// AddWeight adds value to the "weight" field.
+func (_u *AccountGroupUpdateOne) AddWeight(v int) *AccountGroupUpdateOne {
+	_u.mutation.AddWeight(v)
+	return _u
+}
+
+// SetConcurrencyCap sets the "concurrency_cap" field.
+func (_u *AccountGroupUpdateOne) SetConcurrencyCap(v int) *AccountGroupUpdateOne {
+	_u.mutation.ResetConcurrencyCap()
+	_u.mutation.SetConcurrencyCap(v)
+	return _u
+}
+
+// SetNillableConcurrencyCap sets the "concurrency_cap" field if the given value is not nil.
+func (_u *AccountGroupUpdateOne) SetNillableConcurrencyCap(v *int) *AccountGroupUpdateOne {
+	if v != nil {
+		_u.SetConcurrencyCap(*v)
+	}
+	return _u
+}
+
+// AddConcurrencyCap adds value to the "concurrency_cap" field.
+func (_u *AccountGroupUpdateOne) AddConcurrencyCap(v int) *AccountGroupUpdateOne {
+	_u.mutation.AddConcurrencyCap(v)
+	return _u
+}
+
+// ClearConcurrencyCap clears the value of the "concurrency_cap" field.
+func (_u *AccountGroupUpdateOne) ClearConcurrencyCap() *AccountGroupUpdateOne {
+	_u.mutation.ClearConcurrencyCap()
+	return _u
+}
+
 // SetAccount sets the "account" edge to the Account entity.
 func (_u *AccountGroupUpdateOne) SetAccount(v *Account) *AccountGroupUpdateOne {
 	return _u.SetAccountID(v.ID)
@@ -403,6 +577,30 @@ func (_u *AccountGroupUpdateOne) sqlSave(ctx context.Context) (_node *AccountGro
 	if value, ok := _u.mutation.AddedPriority(); ok {
 		_spec.AddField(accountgroup.FieldPriority, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.PriorityOverride(); ok {
+		_spec.SetField(accountgroup.FieldPriorityOverride, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedPriorityOverride(); ok {
+		_spec.AddField(accountgroup.FieldPriorityOverride, field.TypeInt, value)
+	}
+	if _u.mutation.PriorityOverrideCleared() {
+		_spec.ClearField(accountgroup.FieldPriorityOverride, field.TypeInt)
+	}
+	if value, ok := _u.mutation.Weight(); ok {
+		_spec.SetField(accountgroup.FieldWeight, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedWeight(); ok {
+		_spec.AddField(accountgroup.FieldWeight, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ConcurrencyCap(); ok {
+		_spec.SetField(accountgroup.FieldConcurrencyCap, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedConcurrencyCap(); ok {
+		_spec.AddField(accountgroup.FieldConcurrencyCap, field.TypeInt, value)
+	}
+	if _u.mutation.ConcurrencyCapCleared() {
+		_spec.ClearField(accountgroup.FieldConcurrencyCap, field.TypeInt)
+	}
 	if _u.mutation.AccountCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
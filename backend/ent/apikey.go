@@ -66,6 +66,28 @@ type APIKey struct {
 	Window1dStart *time.Time `json:"window_1d_start,omitempty"`
 	// Start time of the current 7d rate limit window
 	Window7dStart *time.Time `json:"window_7d_start,omitempty"`
+	// Persist full conversation transcripts for this API key (opt-in)
+	TranscriptEnabled bool `json:"transcript_enabled,omitempty"`
+	// Expose gateway annotation headers in responses for this API key (admin-managed)
+	AnnotationsEnabled bool `json:"annotations_enabled,omitempty"`
+	// Capture every upstream response of this API key for dispute resolution (admin-managed)
+	StreamCaptureEnabled bool `json:"stream_capture_enabled,omitempty"`
+	// Enforced response language code; empty inherits the group, 'off' disables group enforcement
+	ResponseLanguage string `json:"response_language,omitempty"`
+	// Parent API key of a delegated sub-key; usage is also billed against the parent's quota
+	ParentKeyID *int64 `json:"parent_key_id,omitempty"`
+	// Model patterns a delegated sub-key may request (trailing * wildcard); empty allows all
+	AllowedModels []string `json:"allowed_models,omitempty"`
+	// Highest X-Priority this key may request (low/normal/high); empty ignores the header (admin-managed)
+	MaxPriority string `json:"max_priority,omitempty"`
+	// Validate request bodies against the endpoint schema before forwarding and reject malformed requests with field-level errors
+	StrictValidation bool `json:"strict_validation,omitempty"`
+	// Maximum estimated cost in USD of a single request (0 = unlimited)
+	MaxRequestCost float64 `json:"max_request_cost,omitempty"`
+	// Experimental gateway features this key may opt into per request via X-Sub2API-Beta (admin-managed)
+	BetaFeatures []string `json:"beta_features,omitempty"`
+	// Region (eu/us/asia) the serving account and its proxy must be tagged with; empty means unrestricted (admin-managed)
+	DataResidency string `json:"data_residency,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the APIKeyQuery when eager-loading is set.
 	Edges        APIKeyEdges `json:"edges"`
@@ -121,13 +143,15 @@ func (*APIKey) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case apikey.FieldIPWhitelist, apikey.FieldIPBlacklist:
+		case apikey.FieldIPWhitelist, apikey.FieldIPBlacklist, apikey.FieldAllowedModels, apikey.FieldBetaFeatures:
 			values[i] = new([]byte)
-		case apikey.FieldQuota, apikey.FieldQuotaUsed, apikey.FieldRateLimit5h, apikey.FieldRateLimit1d, apikey.FieldRateLimit7d, apikey.FieldUsage5h, apikey.FieldUsage1d, apikey.FieldUsage7d:
+		case apikey.FieldTranscriptEnabled, apikey.FieldAnnotationsEnabled, apikey.FieldStreamCaptureEnabled, apikey.FieldStrictValidation:
+			values[i] = new(sql.NullBool)
+		case apikey.FieldQuota, apikey.FieldQuotaUsed, apikey.FieldRateLimit5h, apikey.FieldRateLimit1d, apikey.FieldRateLimit7d, apikey.FieldUsage5h, apikey.FieldUsage1d, apikey.FieldUsage7d, apikey.FieldMaxRequestCost:
 			values[i] = new(sql.NullFloat64)
-		case apikey.FieldID, apikey.FieldUserID, apikey.FieldGroupID:
+		case apikey.FieldID, apikey.FieldUserID, apikey.FieldGroupID, apikey.FieldParentKeyID:
 			values[i] = new(sql.NullInt64)
-		case apikey.FieldKey, apikey.FieldName, apikey.FieldStatus:
+		case apikey.FieldKey, apikey.FieldName, apikey.FieldStatus, apikey.FieldResponseLanguage, apikey.FieldMaxPriority, apikey.FieldDataResidency:
 			values[i] = new(sql.NullString)
 		case apikey.FieldCreatedAt, apikey.FieldUpdatedAt, apikey.FieldDeletedAt, apikey.FieldLastUsedAt, apikey.FieldExpiresAt, apikey.FieldWindow5hStart, apikey.FieldWindow1dStart, apikey.FieldWindow7dStart:
 			values[i] = new(sql.NullTime)
@@ -301,6 +325,77 @@ func (_m *APIKey) assignValues(columns []string, values []any) error {
 				_m.Window7dStart = new(time.Time)
 				*_m.Window7dStart = value.Time
 			}
+		case apikey.FieldTranscriptEnabled:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field transcript_enabled", values[i])
+			} else if value.Valid {
+				_m.TranscriptEnabled = value.Bool
+			}
+		case apikey.FieldAnnotationsEnabled:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field annotations_enabled", values[i])
+			} else if value.Valid {
+				_m.AnnotationsEnabled = value.Bool
+			}
+		case apikey.FieldStreamCaptureEnabled:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field stream_capture_enabled", values[i])
+			} else if value.Valid {
+				_m.StreamCaptureEnabled = value.Bool
+			}
+		case apikey.FieldResponseLanguage:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field response_language", values[i])
+			} else if value.Valid {
+				_m.ResponseLanguage = value.String
+			}
+		case apikey.FieldParentKeyID:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field parent_key_id", values[i])
+			} else if value.Valid {
+				_m.ParentKeyID = new(int64)
+				*_m.ParentKeyID = value.Int64
+			}
+		case apikey.FieldAllowedModels:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_models", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AllowedModels); err != nil {
+					return fmt.Errorf("unmarshal field allowed_models: %w", err)
+				}
+			}
+		case apikey.FieldMaxPriority:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field max_priority", values[i])
+			} else if value.Valid {
+				_m.MaxPriority = value.String
+			}
+		case apikey.FieldStrictValidation:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field strict_validation", values[i])
+			} else if value.Valid {
+				_m.StrictValidation = value.Bool
+			}
+		case apikey.FieldMaxRequestCost:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field max_request_cost", values[i])
+			} else if value.Valid {
+				_m.MaxRequestCost = value.Float64
+			}
+		case apikey.FieldBetaFeatures:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field beta_features", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.BetaFeatures); err != nil {
+					return fmt.Errorf("unmarshal field beta_features: %w", err)
+				}
+			}
+		case apikey.FieldDataResidency:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field data_residency", values[i])
+			} else if value.Valid {
+				_m.DataResidency = value.String
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -434,6 +529,41 @@ func (_m *APIKey) String() string {
 		builder.WriteString("window_7d_start=")
 		builder.WriteString(v.Format(time.ANSIC))
 	}
+	builder.WriteString(", ")
+	builder.WriteString("transcript_enabled=")
+	builder.WriteString(fmt.Sprintf("%v", _m.TranscriptEnabled))
+	builder.WriteString(", ")
+	builder.WriteString("annotations_enabled=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AnnotationsEnabled))
+	builder.WriteString(", ")
+	builder.WriteString("stream_capture_enabled=")
+	builder.WriteString(fmt.Sprintf("%v", _m.StreamCaptureEnabled))
+	builder.WriteString(", ")
+	builder.WriteString("response_language=")
+	builder.WriteString(_m.ResponseLanguage)
+	builder.WriteString(", ")
+	if v := _m.ParentKeyID; v != nil {
+		builder.WriteString("parent_key_id=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("allowed_models=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowedModels))
+	builder.WriteString(", ")
+	builder.WriteString("max_priority=")
+	builder.WriteString(_m.MaxPriority)
+	builder.WriteString(", ")
+	builder.WriteString("strict_validation=")
+	builder.WriteString(fmt.Sprintf("%v", _m.StrictValidation))
+	builder.WriteString(", ")
+	builder.WriteString("max_request_cost=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MaxRequestCost))
+	builder.WriteString(", ")
+	builder.WriteString("beta_features=")
+	builder.WriteString(fmt.Sprintf("%v", _m.BetaFeatures))
+	builder.WriteString(", ")
+	builder.WriteString("data_residency=")
+	builder.WriteString(_m.DataResidency)
 	builder.WriteByte(')')
 	return builder.String()
 }
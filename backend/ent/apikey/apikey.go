@@ -61,6 +61,28 @@ const (
 	FieldWindow1dStart = "window_1d_start"
 	// FieldWindow7dStart holds the string denoting the window_7d_start field in the database.
 	FieldWindow7dStart = "window_7d_start"
+	// FieldTranscriptEnabled holds the string denoting the transcript_enabled field in the database.
+	FieldTranscriptEnabled = "transcript_enabled"
+	// FieldAnnotationsEnabled holds the string denoting the annotations_enabled field in the database.
+	FieldAnnotationsEnabled = "annotations_enabled"
+	// FieldStreamCaptureEnabled holds the string denoting the stream_capture_enabled field in the database.
+	FieldStreamCaptureEnabled = "stream_capture_enabled"
+	// FieldResponseLanguage holds the string denoting the response_language field in the database.
+	FieldResponseLanguage = "response_language"
+	// FieldParentKeyID holds the string denoting the parent_key_id field in the database.
+	FieldParentKeyID = "parent_key_id"
+	// FieldAllowedModels holds the string denoting the allowed_models field in the database.
+	FieldAllowedModels = "allowed_models"
+	// FieldMaxPriority holds the string denoting the max_priority field in the database.
+	FieldMaxPriority = "max_priority"
+	// FieldStrictValidation holds the string denoting the strict_validation field in the database.
+	FieldStrictValidation = "strict_validation"
+	// FieldMaxRequestCost holds the string denoting the max_request_cost field in the database.
+	FieldMaxRequestCost = "max_request_cost"
+	// FieldBetaFeatures holds the string denoting the beta_features field in the database.
+	FieldBetaFeatures = "beta_features"
+	// FieldDataResidency holds the string denoting the data_residency field in the database.
+	FieldDataResidency = "data_residency"
 	// EdgeUser holds the string denoting the user edge name in mutations.
 	EdgeUser = "user"
 	// EdgeGroup holds the string denoting the group edge name in mutations.
@@ -118,6 +140,17 @@ var Columns = []string{
 	FieldWindow5hStart,
 	FieldWindow1dStart,
 	FieldWindow7dStart,
+	FieldTranscriptEnabled,
+	FieldAnnotationsEnabled,
+	FieldStreamCaptureEnabled,
+	FieldResponseLanguage,
+	FieldParentKeyID,
+	FieldAllowedModels,
+	FieldMaxPriority,
+	FieldStrictValidation,
+	FieldMaxRequestCost,
+	FieldBetaFeatures,
+	FieldDataResidency,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -168,6 +201,28 @@ var (
 	DefaultUsage1d float64
 	// DefaultUsage7d holds the default value on creation for the "usage_7d" field.
 	DefaultUsage7d float64
+	// DefaultTranscriptEnabled holds the default value on creation for the "transcript_enabled" field.
+	DefaultTranscriptEnabled bool
+	// DefaultAnnotationsEnabled holds the default value on creation for the "annotations_enabled" field.
+	DefaultAnnotationsEnabled bool
+	// DefaultStreamCaptureEnabled holds the default value on creation for the "stream_capture_enabled" field.
+	DefaultStreamCaptureEnabled bool
+	// DefaultResponseLanguage holds the default value on creation for the "response_language" field.
+	DefaultResponseLanguage string
+	// ResponseLanguageValidator is a validator for the "response_language" field. It is called by the builders before save.
+	ResponseLanguageValidator func(string) error
+	// DefaultMaxPriority holds the default value on creation for the "max_priority" field.
+	DefaultMaxPriority string
+	// MaxPriorityValidator is a validator for the "max_priority" field. It is called by the builders before save.
+	MaxPriorityValidator func(string) error
+	// DefaultStrictValidation holds the default value on creation for the "strict_validation" field.
+	DefaultStrictValidation bool
+	// DefaultMaxRequestCost holds the default value on creation for the "max_request_cost" field.
+	DefaultMaxRequestCost float64
+	// DefaultDataResidency holds the default value on creation for the "data_residency" field.
+	DefaultDataResidency string
+	// DataResidencyValidator is a validator for the "data_residency" field. It is called by the builders before save.
+	DataResidencyValidator func(string) error
 )
 
 // OrderOption defines the ordering options for the APIKey queries.
@@ -283,6 +338,51 @@ func ByWindow7dStart(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldWindow7dStart, opts...).ToFunc()
 }
 
+// ByTranscriptEnabled orders the results by the transcript_enabled field.
+func ByTranscriptEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTranscriptEnabled, opts...).ToFunc()
+}
+
+// ByAnnotationsEnabled orders the results by the annotations_enabled field.
+func ByAnnotationsEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAnnotationsEnabled, opts...).ToFunc()
+}
+
+// ByStreamCaptureEnabled orders the results by the stream_capture_enabled field.
+func ByStreamCaptureEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldStreamCaptureEnabled, opts...).ToFunc()
+}
+
+// ByResponseLanguage orders the results by the response_language field.
+func ByResponseLanguage(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseLanguage, opts...).ToFunc()
+}
+
+// ByParentKeyID orders the results by the parent_key_id field.
+func ByParentKeyID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldParentKeyID, opts...).ToFunc()
+}
+
+// ByMaxPriority orders the results by the max_priority field.
+func ByMaxPriority(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMaxPriority, opts...).ToFunc()
+}
+
+// ByStrictValidation orders the results by the strict_validation field.
+func ByStrictValidation(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldStrictValidation, opts...).ToFunc()
+}
+
+// ByMaxRequestCost orders the results by the max_request_cost field.
+func ByMaxRequestCost(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMaxRequestCost, opts...).ToFunc()
+}
+
+// ByDataResidency orders the results by the data_residency field.
+func ByDataResidency(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDataResidency, opts...).ToFunc()
+}
+
 // ByUserField orders the results by user field.
 func ByUserField(field string, opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
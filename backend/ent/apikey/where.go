@@ -160,6 +160,51 @@ func Window7dStart(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldWindow7dStart, v))
 }
 
+// TranscriptEnabled applies equality check predicate on the "transcript_enabled" field. It's identical to TranscriptEnabledEQ.
+func TranscriptEnabled(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTranscriptEnabled, v))
+}
+
+// AnnotationsEnabled applies equality check predicate on the "annotations_enabled" field. It's identical to AnnotationsEnabledEQ.
+func AnnotationsEnabled(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldAnnotationsEnabled, v))
+}
+
+// StreamCaptureEnabled applies equality check predicate on the "stream_capture_enabled" field. It's identical to StreamCaptureEnabledEQ.
+func StreamCaptureEnabled(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldStreamCaptureEnabled, v))
+}
+
+// ResponseLanguage applies equality check predicate on the "response_language" field. It's identical to ResponseLanguageEQ.
+func ResponseLanguage(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldResponseLanguage, v))
+}
+
+// ParentKeyID applies equality check predicate on the "parent_key_id" field. It's identical to ParentKeyIDEQ.
+func ParentKeyID(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldParentKeyID, v))
+}
+
+// MaxPriority applies equality check predicate on the "max_priority" field. It's identical to MaxPriorityEQ.
+func MaxPriority(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMaxPriority, v))
+}
+
+// StrictValidation applies equality check predicate on the "strict_validation" field. It's identical to StrictValidationEQ.
+func StrictValidation(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldStrictValidation, v))
+}
+
+// MaxRequestCost applies equality check predicate on the "max_request_cost" field. It's identical to MaxRequestCostEQ.
+func MaxRequestCost(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMaxRequestCost, v))
+}
+
+// DataResidency applies equality check predicate on the "data_residency" field. It's identical to DataResidencyEQ.
+func DataResidency(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDataResidency, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldCreatedAt, v))
@@ -1125,6 +1170,351 @@ func Window7dStartNotNil() predicate.APIKey {
 	return predicate.APIKey(sql.FieldNotNull(FieldWindow7dStart))
 }
 
+// TranscriptEnabledEQ applies the EQ predicate on the "transcript_enabled" field.
+func TranscriptEnabledEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTranscriptEnabled, v))
+}
+
+// TranscriptEnabledNEQ applies the NEQ predicate on the "transcript_enabled" field.
+func TranscriptEnabledNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldTranscriptEnabled, v))
+}
+
+// AnnotationsEnabledEQ applies the EQ predicate on the "annotations_enabled" field.
+func AnnotationsEnabledEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldAnnotationsEnabled, v))
+}
+
+// AnnotationsEnabledNEQ applies the NEQ predicate on the "annotations_enabled" field.
+func AnnotationsEnabledNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldAnnotationsEnabled, v))
+}
+
+// StreamCaptureEnabledEQ applies the EQ predicate on the "stream_capture_enabled" field.
+func StreamCaptureEnabledEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldStreamCaptureEnabled, v))
+}
+
+// StreamCaptureEnabledNEQ applies the NEQ predicate on the "stream_capture_enabled" field.
+func StreamCaptureEnabledNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldStreamCaptureEnabled, v))
+}
+
+// ResponseLanguageEQ applies the EQ predicate on the "response_language" field.
+func ResponseLanguageEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageNEQ applies the NEQ predicate on the "response_language" field.
+func ResponseLanguageNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageIn applies the In predicate on the "response_language" field.
+func ResponseLanguageIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldResponseLanguage, vs...))
+}
+
+// ResponseLanguageNotIn applies the NotIn predicate on the "response_language" field.
+func ResponseLanguageNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldResponseLanguage, vs...))
+}
+
+// ResponseLanguageGT applies the GT predicate on the "response_language" field.
+func ResponseLanguageGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageGTE applies the GTE predicate on the "response_language" field.
+func ResponseLanguageGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageLT applies the LT predicate on the "response_language" field.
+func ResponseLanguageLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageLTE applies the LTE predicate on the "response_language" field.
+func ResponseLanguageLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageContains applies the Contains predicate on the "response_language" field.
+func ResponseLanguageContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageHasPrefix applies the HasPrefix predicate on the "response_language" field.
+func ResponseLanguageHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageHasSuffix applies the HasSuffix predicate on the "response_language" field.
+func ResponseLanguageHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageEqualFold applies the EqualFold predicate on the "response_language" field.
+func ResponseLanguageEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageContainsFold applies the ContainsFold predicate on the "response_language" field.
+func ResponseLanguageContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldResponseLanguage, v))
+}
+
+// ParentKeyIDEQ applies the EQ predicate on the "parent_key_id" field.
+func ParentKeyIDEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldParentKeyID, v))
+}
+
+// ParentKeyIDNEQ applies the NEQ predicate on the "parent_key_id" field.
+func ParentKeyIDNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldParentKeyID, v))
+}
+
+// ParentKeyIDIn applies the In predicate on the "parent_key_id" field.
+func ParentKeyIDIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldParentKeyID, vs...))
+}
+
+// ParentKeyIDNotIn applies the NotIn predicate on the "parent_key_id" field.
+func ParentKeyIDNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldParentKeyID, vs...))
+}
+
+// ParentKeyIDGT applies the GT predicate on the "parent_key_id" field.
+func ParentKeyIDGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldParentKeyID, v))
+}
+
+// ParentKeyIDGTE applies the GTE predicate on the "parent_key_id" field.
+func ParentKeyIDGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldParentKeyID, v))
+}
+
+// ParentKeyIDLT applies the LT predicate on the "parent_key_id" field.
+func ParentKeyIDLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldParentKeyID, v))
+}
+
+// ParentKeyIDLTE applies the LTE predicate on the "parent_key_id" field.
+func ParentKeyIDLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldParentKeyID, v))
+}
+
+// ParentKeyIDIsNil applies the IsNil predicate on the "parent_key_id" field.
+func ParentKeyIDIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldParentKeyID))
+}
+
+// ParentKeyIDNotNil applies the NotNil predicate on the "parent_key_id" field.
+func ParentKeyIDNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldParentKeyID))
+}
+
+// AllowedModelsIsNil applies the IsNil predicate on the "allowed_models" field.
+func AllowedModelsIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldAllowedModels))
+}
+
+// AllowedModelsNotNil applies the NotNil predicate on the "allowed_models" field.
+func AllowedModelsNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldAllowedModels))
+}
+
+// MaxPriorityEQ applies the EQ predicate on the "max_priority" field.
+func MaxPriorityEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMaxPriority, v))
+}
+
+// MaxPriorityNEQ applies the NEQ predicate on the "max_priority" field.
+func MaxPriorityNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldMaxPriority, v))
+}
+
+// MaxPriorityIn applies the In predicate on the "max_priority" field.
+func MaxPriorityIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldMaxPriority, vs...))
+}
+
+// MaxPriorityNotIn applies the NotIn predicate on the "max_priority" field.
+func MaxPriorityNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldMaxPriority, vs...))
+}
+
+// MaxPriorityGT applies the GT predicate on the "max_priority" field.
+func MaxPriorityGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldMaxPriority, v))
+}
+
+// MaxPriorityGTE applies the GTE predicate on the "max_priority" field.
+func MaxPriorityGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldMaxPriority, v))
+}
+
+// MaxPriorityLT applies the LT predicate on the "max_priority" field.
+func MaxPriorityLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldMaxPriority, v))
+}
+
+// MaxPriorityLTE applies the LTE predicate on the "max_priority" field.
+func MaxPriorityLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldMaxPriority, v))
+}
+
+// MaxPriorityContains applies the Contains predicate on the "max_priority" field.
+func MaxPriorityContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldMaxPriority, v))
+}
+
+// MaxPriorityHasPrefix applies the HasPrefix predicate on the "max_priority" field.
+func MaxPriorityHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldMaxPriority, v))
+}
+
+// MaxPriorityHasSuffix applies the HasSuffix predicate on the "max_priority" field.
+func MaxPriorityHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldMaxPriority, v))
+}
+
+// MaxPriorityEqualFold applies the EqualFold predicate on the "max_priority" field.
+func MaxPriorityEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldMaxPriority, v))
+}
+
+// MaxPriorityContainsFold applies the ContainsFold predicate on the "max_priority" field.
+func MaxPriorityContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldMaxPriority, v))
+}
+
+// StrictValidationEQ applies the EQ predicate on the "strict_validation" field.
+func StrictValidationEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldStrictValidation, v))
+}
+
+// StrictValidationNEQ applies the NEQ predicate on the "strict_validation" field.
+func StrictValidationNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldStrictValidation, v))
+}
+
+// MaxRequestCostEQ applies the EQ predicate on the "max_request_cost" field.
+func MaxRequestCostEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostNEQ applies the NEQ predicate on the "max_request_cost" field.
+func MaxRequestCostNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostIn applies the In predicate on the "max_request_cost" field.
+func MaxRequestCostIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldMaxRequestCost, vs...))
+}
+
+// MaxRequestCostNotIn applies the NotIn predicate on the "max_request_cost" field.
+func MaxRequestCostNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldMaxRequestCost, vs...))
+}
+
+// MaxRequestCostGT applies the GT predicate on the "max_request_cost" field.
+func MaxRequestCostGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostGTE applies the GTE predicate on the "max_request_cost" field.
+func MaxRequestCostGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostLT applies the LT predicate on the "max_request_cost" field.
+func MaxRequestCostLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostLTE applies the LTE predicate on the "max_request_cost" field.
+func MaxRequestCostLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldMaxRequestCost, v))
+}
+
+// BetaFeaturesIsNil applies the IsNil predicate on the "beta_features" field.
+func BetaFeaturesIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldBetaFeatures))
+}
+
+// BetaFeaturesNotNil applies the NotNil predicate on the "beta_features" field.
+func BetaFeaturesNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldBetaFeatures))
+}
+
+// DataResidencyEQ applies the EQ predicate on the "data_residency" field.
+func DataResidencyEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDataResidency, v))
+}
+
+// DataResidencyNEQ applies the NEQ predicate on the "data_residency" field.
+func DataResidencyNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldDataResidency, v))
+}
+
+// DataResidencyIn applies the In predicate on the "data_residency" field.
+func DataResidencyIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldDataResidency, vs...))
+}
+
+// DataResidencyNotIn applies the NotIn predicate on the "data_residency" field.
+func DataResidencyNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldDataResidency, vs...))
+}
+
+// DataResidencyGT applies the GT predicate on the "data_residency" field.
+func DataResidencyGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldDataResidency, v))
+}
+
+// DataResidencyGTE applies the GTE predicate on the "data_residency" field.
+func DataResidencyGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldDataResidency, v))
+}
+
+// DataResidencyLT applies the LT predicate on the "data_residency" field.
+func DataResidencyLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldDataResidency, v))
+}
+
+// DataResidencyLTE applies the LTE predicate on the "data_residency" field.
+func DataResidencyLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldDataResidency, v))
+}
+
+// DataResidencyContains applies the Contains predicate on the "data_residency" field.
+func DataResidencyContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldDataResidency, v))
+}
+
+// DataResidencyHasPrefix applies the HasPrefix predicate on the "data_residency" field.
+func DataResidencyHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldDataResidency, v))
+}
+
+// DataResidencyHasSuffix applies the HasSuffix predicate on the "data_residency" field.
+func DataResidencyHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldDataResidency, v))
+}
+
+// DataResidencyEqualFold applies the EqualFold predicate on the "data_residency" field.
+func DataResidencyEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldDataResidency, v))
+}
+
+// DataResidencyContainsFold applies the ContainsFold predicate on the "data_residency" field.
+func DataResidencyContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldDataResidency, v))
+}
+
 // HasUser applies the HasEdge predicate on the "user" edge.
 func HasUser() predicate.APIKey {
 	return predicate.APIKey(func(s *sql.Selector) {
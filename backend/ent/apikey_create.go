@@ -307,6 +307,144 @@ func (_c *APIKeyCreate) SetNillableWindow7dStart(v *time.Time) *APIKeyCreate {
 	return _c
 }
 
+// SetTranscriptEnabled sets the "transcript_enabled" field.
+func (_c *APIKeyCreate) SetTranscriptEnabled(v bool) *APIKeyCreate {
+	_c.mutation.SetTranscriptEnabled(v)
+	return _c
+}
+
+// SetNillableTranscriptEnabled sets the "transcript_enabled" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableTranscriptEnabled(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetTranscriptEnabled(*v)
+	}
+	return _c
+}
+
+// SetAnnotationsEnabled sets the "annotations_enabled" field.
+func (_c *APIKeyCreate) SetAnnotationsEnabled(v bool) *APIKeyCreate {
+	_c.mutation.SetAnnotationsEnabled(v)
+	return _c
+}
+
+// SetNillableAnnotationsEnabled sets the "annotations_enabled" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableAnnotationsEnabled(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetAnnotationsEnabled(*v)
+	}
+	return _c
+}
+
+// SetStreamCaptureEnabled sets the "stream_capture_enabled" field.
+func (_c *APIKeyCreate) SetStreamCaptureEnabled(v bool) *APIKeyCreate {
+	_c.mutation.SetStreamCaptureEnabled(v)
+	return _c
+}
+
+// SetNillableStreamCaptureEnabled sets the "stream_capture_enabled" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableStreamCaptureEnabled(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetStreamCaptureEnabled(*v)
+	}
+	return _c
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (_c *APIKeyCreate) SetResponseLanguage(v string) *APIKeyCreate {
+	_c.mutation.SetResponseLanguage(v)
+	return _c
+}
+
+// SetNillableResponseLanguage sets the "response_language" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableResponseLanguage(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetResponseLanguage(*v)
+	}
+	return _c
+}
+
+// SetParentKeyID sets the "parent_key_id" field.
+func (_c *APIKeyCreate) SetParentKeyID(v int64) *APIKeyCreate {
+	_c.mutation.SetParentKeyID(v)
+	return _c
+}
+
+// SetNillableParentKeyID sets the "parent_key_id" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableParentKeyID(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetParentKeyID(*v)
+	}
+	return _c
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_c *APIKeyCreate) SetAllowedModels(v []string) *APIKeyCreate {
+	_c.mutation.SetAllowedModels(v)
+	return _c
+}
+
+// SetMaxPriority sets the "max_priority" field.
+func (_c *APIKeyCreate) SetMaxPriority(v string) *APIKeyCreate {
+	_c.mutation.SetMaxPriority(v)
+	return _c
+}
+
+// SetNillableMaxPriority sets the "max_priority" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableMaxPriority(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetMaxPriority(*v)
+	}
+	return _c
+}
+
+// SetStrictValidation sets the "strict_validation" field.
+func (_c *APIKeyCreate) SetStrictValidation(v bool) *APIKeyCreate {
+	_c.mutation.SetStrictValidation(v)
+	return _c
+}
+
+// SetNillableStrictValidation sets the "strict_validation" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableStrictValidation(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetStrictValidation(*v)
+	}
+	return _c
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (_c *APIKeyCreate) SetMaxRequestCost(v float64) *APIKeyCreate {
+	_c.mutation.SetMaxRequestCost(v)
+	return _c
+}
+
+// SetNillableMaxRequestCost sets the "max_request_cost" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableMaxRequestCost(v *float64) *APIKeyCreate {
+	if v != nil {
+		_c.SetMaxRequestCost(*v)
+	}
+	return _c
+}
+
+// SetBetaFeatures sets the "beta_features" field.
+func (_c *APIKeyCreate) SetBetaFeatures(v []string) *APIKeyCreate {
+	_c.mutation.SetBetaFeatures(v)
+	return _c
+}
+
+// SetDataResidency sets the "data_residency" field.
+func (_c *APIKeyCreate) SetDataResidency(v string) *APIKeyCreate {
+	_c.mutation.SetDataResidency(v)
+	return _c
+}
+
+// SetNillableDataResidency sets the "data_residency" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableDataResidency(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetDataResidency(*v)
+	}
+	return _c
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_c *APIKeyCreate) SetUser(v *User) *APIKeyCreate {
 	return _c.SetUserID(v.ID)
@@ -419,6 +557,38 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultUsage7d
 		_c.mutation.SetUsage7d(v)
 	}
+	if _, ok := _c.mutation.TranscriptEnabled(); !ok {
+		v := apikey.DefaultTranscriptEnabled
+		_c.mutation.SetTranscriptEnabled(v)
+	}
+	if _, ok := _c.mutation.AnnotationsEnabled(); !ok {
+		v := apikey.DefaultAnnotationsEnabled
+		_c.mutation.SetAnnotationsEnabled(v)
+	}
+	if _, ok := _c.mutation.StreamCaptureEnabled(); !ok {
+		v := apikey.DefaultStreamCaptureEnabled
+		_c.mutation.SetStreamCaptureEnabled(v)
+	}
+	if _, ok := _c.mutation.ResponseLanguage(); !ok {
+		v := apikey.DefaultResponseLanguage
+		_c.mutation.SetResponseLanguage(v)
+	}
+	if _, ok := _c.mutation.MaxPriority(); !ok {
+		v := apikey.DefaultMaxPriority
+		_c.mutation.SetMaxPriority(v)
+	}
+	if _, ok := _c.mutation.StrictValidation(); !ok {
+		v := apikey.DefaultStrictValidation
+		_c.mutation.SetStrictValidation(v)
+	}
+	if _, ok := _c.mutation.MaxRequestCost(); !ok {
+		v := apikey.DefaultMaxRequestCost
+		_c.mutation.SetMaxRequestCost(v)
+	}
+	if _, ok := _c.mutation.DataResidency(); !ok {
+		v := apikey.DefaultDataResidency
+		_c.mutation.SetDataResidency(v)
+	}
 	return nil
 }
 
@@ -481,6 +651,45 @@ func (_c *APIKeyCreate) check() error {
 	if _, ok := _c.mutation.Usage7d(); !ok {
 		return &ValidationError{Name: "usage_7d", err: errors.New(`ent: missing required field "APIKey.usage_7d"`)}
 	}
+	if _, ok := _c.mutation.TranscriptEnabled(); !ok {
+		return &ValidationError{Name: "transcript_enabled", err: errors.New(`ent: missing required field "APIKey.transcript_enabled"`)}
+	}
+	if _, ok := _c.mutation.AnnotationsEnabled(); !ok {
+		return &ValidationError{Name: "annotations_enabled", err: errors.New(`ent: missing required field "APIKey.annotations_enabled"`)}
+	}
+	if _, ok := _c.mutation.StreamCaptureEnabled(); !ok {
+		return &ValidationError{Name: "stream_capture_enabled", err: errors.New(`ent: missing required field "APIKey.stream_capture_enabled"`)}
+	}
+	if _, ok := _c.mutation.ResponseLanguage(); !ok {
+		return &ValidationError{Name: "response_language", err: errors.New(`ent: missing required field "APIKey.response_language"`)}
+	}
+	if v, ok := _c.mutation.ResponseLanguage(); ok {
+		if err := apikey.ResponseLanguageValidator(v); err != nil {
+			return &ValidationError{Name: "response_language", err: fmt.Errorf(`ent: validator failed for field "APIKey.response_language": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.MaxPriority(); !ok {
+		return &ValidationError{Name: "max_priority", err: errors.New(`ent: missing required field "APIKey.max_priority"`)}
+	}
+	if v, ok := _c.mutation.MaxPriority(); ok {
+		if err := apikey.MaxPriorityValidator(v); err != nil {
+			return &ValidationError{Name: "max_priority", err: fmt.Errorf(`ent: validator failed for field "APIKey.max_priority": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.StrictValidation(); !ok {
+		return &ValidationError{Name: "strict_validation", err: errors.New(`ent: missing required field "APIKey.strict_validation"`)}
+	}
+	if _, ok := _c.mutation.MaxRequestCost(); !ok {
+		return &ValidationError{Name: "max_request_cost", err: errors.New(`ent: missing required field "APIKey.max_request_cost"`)}
+	}
+	if _, ok := _c.mutation.DataResidency(); !ok {
+		return &ValidationError{Name: "data_residency", err: errors.New(`ent: missing required field "APIKey.data_residency"`)}
+	}
+	if v, ok := _c.mutation.DataResidency(); ok {
+		if err := apikey.DataResidencyValidator(v); err != nil {
+			return &ValidationError{Name: "data_residency", err: fmt.Errorf(`ent: validator failed for field "APIKey.data_residency": %w`, err)}
+		}
+	}
 	if len(_c.mutation.UserIDs()) == 0 {
 		return &ValidationError{Name: "user", err: errors.New(`ent: missing required edge "APIKey.user"`)}
 	}
@@ -595,6 +804,50 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldWindow7dStart, field.TypeTime, value)
 		_node.Window7dStart = &value
 	}
+	if value, ok := _c.mutation.TranscriptEnabled(); ok {
+		_spec.SetField(apikey.FieldTranscriptEnabled, field.TypeBool, value)
+		_node.TranscriptEnabled = value
+	}
+	if value, ok := _c.mutation.AnnotationsEnabled(); ok {
+		_spec.SetField(apikey.FieldAnnotationsEnabled, field.TypeBool, value)
+		_node.AnnotationsEnabled = value
+	}
+	if value, ok := _c.mutation.StreamCaptureEnabled(); ok {
+		_spec.SetField(apikey.FieldStreamCaptureEnabled, field.TypeBool, value)
+		_node.StreamCaptureEnabled = value
+	}
+	if value, ok := _c.mutation.ResponseLanguage(); ok {
+		_spec.SetField(apikey.FieldResponseLanguage, field.TypeString, value)
+		_node.ResponseLanguage = value
+	}
+	if value, ok := _c.mutation.ParentKeyID(); ok {
+		_spec.SetField(apikey.FieldParentKeyID, field.TypeInt64, value)
+		_node.ParentKeyID = &value
+	}
+	if value, ok := _c.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+		_node.AllowedModels = value
+	}
+	if value, ok := _c.mutation.MaxPriority(); ok {
+		_spec.SetField(apikey.FieldMaxPriority, field.TypeString, value)
+		_node.MaxPriority = value
+	}
+	if value, ok := _c.mutation.StrictValidation(); ok {
+		_spec.SetField(apikey.FieldStrictValidation, field.TypeBool, value)
+		_node.StrictValidation = value
+	}
+	if value, ok := _c.mutation.MaxRequestCost(); ok {
+		_spec.SetField(apikey.FieldMaxRequestCost, field.TypeFloat64, value)
+		_node.MaxRequestCost = value
+	}
+	if value, ok := _c.mutation.BetaFeatures(); ok {
+		_spec.SetField(apikey.FieldBetaFeatures, field.TypeJSON, value)
+		_node.BetaFeatures = value
+	}
+	if value, ok := _c.mutation.DataResidency(); ok {
+		_spec.SetField(apikey.FieldDataResidency, field.TypeString, value)
+		_node.DataResidency = value
+	}
 	if nodes := _c.mutation.UserIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -1063,6 +1316,168 @@ func (u *APIKeyUpsert) ClearWindow7dStart() *APIKeyUpsert {
 	return u
 }
 
+// SetTranscriptEnabled sets the "transcript_enabled" field.
+func (u *APIKeyUpsert) SetTranscriptEnabled(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldTranscriptEnabled, v)
+	return u
+}
+
+// UpdateTranscriptEnabled sets the "transcript_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateTranscriptEnabled() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldTranscriptEnabled)
+	return u
+}
+
+// SetAnnotationsEnabled sets the "annotations_enabled" field.
+func (u *APIKeyUpsert) SetAnnotationsEnabled(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldAnnotationsEnabled, v)
+	return u
+}
+
+// UpdateAnnotationsEnabled sets the "annotations_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAnnotationsEnabled() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAnnotationsEnabled)
+	return u
+}
+
+// SetStreamCaptureEnabled sets the "stream_capture_enabled" field.
+func (u *APIKeyUpsert) SetStreamCaptureEnabled(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldStreamCaptureEnabled, v)
+	return u
+}
+
+// UpdateStreamCaptureEnabled sets the "stream_capture_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateStreamCaptureEnabled() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldStreamCaptureEnabled)
+	return u
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (u *APIKeyUpsert) SetResponseLanguage(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldResponseLanguage, v)
+	return u
+}
+
+// UpdateResponseLanguage sets the "response_language" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateResponseLanguage() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldResponseLanguage)
+	return u
+}
+
+// SetParentKeyID sets the "parent_key_id" field.
+func (u *APIKeyUpsert) SetParentKeyID(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldParentKeyID, v)
+	return u
+}
+
+// UpdateParentKeyID sets the "parent_key_id" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateParentKeyID() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldParentKeyID)
+	return u
+}
+
+// AddParentKeyID adds v to the "parent_key_id" field.
+func (u *APIKeyUpsert) AddParentKeyID(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldParentKeyID, v)
+	return u
+}
+
+// ClearParentKeyID clears the value of the "parent_key_id" field.
+func (u *APIKeyUpsert) ClearParentKeyID() *APIKeyUpsert {
+	u.SetNull(apikey.FieldParentKeyID)
+	return u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsert) SetAllowedModels(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldAllowedModels, v)
+	return u
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAllowedModels() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAllowedModels)
+	return u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsert) ClearAllowedModels() *APIKeyUpsert {
+	u.SetNull(apikey.FieldAllowedModels)
+	return u
+}
+
+// SetMaxPriority sets the "max_priority" field.
+func (u *APIKeyUpsert) SetMaxPriority(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldMaxPriority, v)
+	return u
+}
+
+// UpdateMaxPriority sets the "max_priority" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateMaxPriority() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldMaxPriority)
+	return u
+}
+
+// SetStrictValidation sets the "strict_validation" field.
+func (u *APIKeyUpsert) SetStrictValidation(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldStrictValidation, v)
+	return u
+}
+
+// UpdateStrictValidation sets the "strict_validation" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateStrictValidation() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldStrictValidation)
+	return u
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (u *APIKeyUpsert) SetMaxRequestCost(v float64) *APIKeyUpsert {
+	u.Set(apikey.FieldMaxRequestCost, v)
+	return u
+}
+
+// UpdateMaxRequestCost sets the "max_request_cost" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateMaxRequestCost() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldMaxRequestCost)
+	return u
+}
+
+// AddMaxRequestCost adds v to the "max_request_cost" field.
+func (u *APIKeyUpsert) AddMaxRequestCost(v float64) *APIKeyUpsert {
+	u.Add(apikey.FieldMaxRequestCost, v)
+	return u
+}
+
+// SetBetaFeatures sets the "beta_features" field.
+func (u *APIKeyUpsert) SetBetaFeatures(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldBetaFeatures, v)
+	return u
+}
+
+// UpdateBetaFeatures sets the "beta_features" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateBetaFeatures() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldBetaFeatures)
+	return u
+}
+
+// ClearBetaFeatures clears the value of the "beta_features" field.
+func (u *APIKeyUpsert) ClearBetaFeatures() *APIKeyUpsert {
+	u.SetNull(apikey.FieldBetaFeatures)
+	return u
+}
+
+// SetDataResidency sets the "data_residency" field.
+func (u *APIKeyUpsert) SetDataResidency(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldDataResidency, v)
+	return u
+}
+
+// UpdateDataResidency sets the "data_residency" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateDataResidency() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldDataResidency)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1535,6 +1950,195 @@ func (u *APIKeyUpsertOne) ClearWindow7dStart() *APIKeyUpsertOne {
 	})
 }
 
+// SetTranscriptEnabled sets the "transcript_enabled" field.
+func (u *APIKeyUpsertOne) SetTranscriptEnabled(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTranscriptEnabled(v)
+	})
+}
+
+// UpdateTranscriptEnabled sets the "transcript_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateTranscriptEnabled() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTranscriptEnabled()
+	})
+}
+
+// SetAnnotationsEnabled sets the "annotations_enabled" field.
+func (u *APIKeyUpsertOne) SetAnnotationsEnabled(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAnnotationsEnabled(v)
+	})
+}
+
+// UpdateAnnotationsEnabled sets the "annotations_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAnnotationsEnabled() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAnnotationsEnabled()
+	})
+}
+
+// SetStreamCaptureEnabled sets the "stream_capture_enabled" field.
+func (u *APIKeyUpsertOne) SetStreamCaptureEnabled(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetStreamCaptureEnabled(v)
+	})
+}
+
+// UpdateStreamCaptureEnabled sets the "stream_capture_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateStreamCaptureEnabled() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateStreamCaptureEnabled()
+	})
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (u *APIKeyUpsertOne) SetResponseLanguage(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetResponseLanguage(v)
+	})
+}
+
+// UpdateResponseLanguage sets the "response_language" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateResponseLanguage() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateResponseLanguage()
+	})
+}
+
+// SetParentKeyID sets the "parent_key_id" field.
+func (u *APIKeyUpsertOne) SetParentKeyID(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetParentKeyID(v)
+	})
+}
+
+// AddParentKeyID adds v to the "parent_key_id" field.
+func (u *APIKeyUpsertOne) AddParentKeyID(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddParentKeyID(v)
+	})
+}
+
+// UpdateParentKeyID sets the "parent_key_id" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateParentKeyID() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateParentKeyID()
+	})
+}
+
+// ClearParentKeyID clears the value of the "parent_key_id" field.
+func (u *APIKeyUpsertOne) ClearParentKeyID() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearParentKeyID()
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertOne) SetAllowedModels(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsertOne) ClearAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAllowedModels()
+	})
+}
+
+// SetMaxPriority sets the "max_priority" field.
+func (u *APIKeyUpsertOne) SetMaxPriority(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMaxPriority(v)
+	})
+}
+
+// UpdateMaxPriority sets the "max_priority" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateMaxPriority() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMaxPriority()
+	})
+}
+
+// SetStrictValidation sets the "strict_validation" field.
+func (u *APIKeyUpsertOne) SetStrictValidation(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetStrictValidation(v)
+	})
+}
+
+// UpdateStrictValidation sets the "strict_validation" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateStrictValidation() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateStrictValidation()
+	})
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (u *APIKeyUpsertOne) SetMaxRequestCost(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMaxRequestCost(v)
+	})
+}
+
+// AddMaxRequestCost adds v to the "max_request_cost" field.
+func (u *APIKeyUpsertOne) AddMaxRequestCost(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddMaxRequestCost(v)
+	})
+}
+
+// UpdateMaxRequestCost sets the "max_request_cost" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateMaxRequestCost() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMaxRequestCost()
+	})
+}
+
+// SetBetaFeatures sets the "beta_features" field.
+func (u *APIKeyUpsertOne) SetBetaFeatures(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBetaFeatures(v)
+	})
+}
+
+// UpdateBetaFeatures sets the "beta_features" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateBetaFeatures() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBetaFeatures()
+	})
+}
+
+// ClearBetaFeatures clears the value of the "beta_features" field.
+func (u *APIKeyUpsertOne) ClearBetaFeatures() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearBetaFeatures()
+	})
+}
+
+// SetDataResidency sets the "data_residency" field.
+func (u *APIKeyUpsertOne) SetDataResidency(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDataResidency(v)
+	})
+}
+
+// UpdateDataResidency sets the "data_residency" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateDataResidency() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateDataResidency()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -2173,6 +2777,195 @@ func (u *APIKeyUpsertBulk) ClearWindow7dStart() *APIKeyUpsertBulk {
 	})
 }
 
+// SetTranscriptEnabled sets the "transcript_enabled" field.
+func (u *APIKeyUpsertBulk) SetTranscriptEnabled(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTranscriptEnabled(v)
+	})
+}
+
+// UpdateTranscriptEnabled sets the "transcript_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateTranscriptEnabled() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTranscriptEnabled()
+	})
+}
+
+// SetAnnotationsEnabled sets the "annotations_enabled" field.
+func (u *APIKeyUpsertBulk) SetAnnotationsEnabled(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAnnotationsEnabled(v)
+	})
+}
+
+// UpdateAnnotationsEnabled sets the "annotations_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAnnotationsEnabled() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAnnotationsEnabled()
+	})
+}
+
+// SetStreamCaptureEnabled sets the "stream_capture_enabled" field.
+func (u *APIKeyUpsertBulk) SetStreamCaptureEnabled(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetStreamCaptureEnabled(v)
+	})
+}
+
+// UpdateStreamCaptureEnabled sets the "stream_capture_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateStreamCaptureEnabled() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateStreamCaptureEnabled()
+	})
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (u *APIKeyUpsertBulk) SetResponseLanguage(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetResponseLanguage(v)
+	})
+}
+
+// UpdateResponseLanguage sets the "response_language" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateResponseLanguage() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateResponseLanguage()
+	})
+}
+
+// SetParentKeyID sets the "parent_key_id" field.
+func (u *APIKeyUpsertBulk) SetParentKeyID(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetParentKeyID(v)
+	})
+}
+
+// AddParentKeyID adds v to the "parent_key_id" field.
+func (u *APIKeyUpsertBulk) AddParentKeyID(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddParentKeyID(v)
+	})
+}
+
+// UpdateParentKeyID sets the "parent_key_id" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateParentKeyID() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateParentKeyID()
+	})
+}
+
+// ClearParentKeyID clears the value of the "parent_key_id" field.
+func (u *APIKeyUpsertBulk) ClearParentKeyID() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearParentKeyID()
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertBulk) SetAllowedModels(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAllowedModels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsertBulk) ClearAllowedModels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAllowedModels()
+	})
+}
+
+// SetMaxPriority sets the "max_priority" field.
+func (u *APIKeyUpsertBulk) SetMaxPriority(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMaxPriority(v)
+	})
+}
+
+// UpdateMaxPriority sets the "max_priority" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateMaxPriority() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMaxPriority()
+	})
+}
+
+// SetStrictValidation sets the "strict_validation" field.
+func (u *APIKeyUpsertBulk) SetStrictValidation(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetStrictValidation(v)
+	})
+}
+
+// UpdateStrictValidation sets the "strict_validation" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateStrictValidation() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateStrictValidation()
+	})
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (u *APIKeyUpsertBulk) SetMaxRequestCost(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMaxRequestCost(v)
+	})
+}
+
+// AddMaxRequestCost adds v to the "max_request_cost" field.
+func (u *APIKeyUpsertBulk) AddMaxRequestCost(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddMaxRequestCost(v)
+	})
+}
+
+// UpdateMaxRequestCost sets the "max_request_cost" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateMaxRequestCost() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMaxRequestCost()
+	})
+}
+
+// SetBetaFeatures sets the "beta_features" field.
+func (u *APIKeyUpsertBulk) SetBetaFeatures(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBetaFeatures(v)
+	})
+}
+
+// UpdateBetaFeatures sets the "beta_features" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateBetaFeatures() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBetaFeatures()
+	})
+}
+
+// ClearBetaFeatures clears the value of the "beta_features" field.
+func (u *APIKeyUpsertBulk) ClearBetaFeatures() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearBetaFeatures()
+	})
+}
+
+// SetDataResidency sets the "data_residency" field.
+func (u *APIKeyUpsertBulk) SetDataResidency(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDataResidency(v)
+	})
+}
+
+// UpdateDataResidency sets the "data_residency" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateDataResidency() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateDataResidency()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -438,6 +438,188 @@ func (_u *APIKeyUpdate) ClearWindow7dStart() *APIKeyUpdate {
 	return _u
 }
 
+// SetTranscriptEnabled sets the "transcript_enabled" field.
+func (_u *APIKeyUpdate) SetTranscriptEnabled(v bool) *APIKeyUpdate {
+	_u.mutation.SetTranscriptEnabled(v)
+	return _u
+}
+
+// SetNillableTranscriptEnabled sets the "transcript_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableTranscriptEnabled(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetTranscriptEnabled(*v)
+	}
+	return _u
+}
+
+// SetAnnotationsEnabled sets the "annotations_enabled" field.
+func (_u *APIKeyUpdate) SetAnnotationsEnabled(v bool) *APIKeyUpdate {
+	_u.mutation.SetAnnotationsEnabled(v)
+	return _u
+}
+
+// SetNillableAnnotationsEnabled sets the "annotations_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableAnnotationsEnabled(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetAnnotationsEnabled(*v)
+	}
+	return _u
+}
+
+// SetStreamCaptureEnabled sets the "stream_capture_enabled" field.
+func (_u *APIKeyUpdate) SetStreamCaptureEnabled(v bool) *APIKeyUpdate {
+	_u.mutation.SetStreamCaptureEnabled(v)
+	return _u
+}
+
+// SetNillableStreamCaptureEnabled sets the "stream_capture_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableStreamCaptureEnabled(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetStreamCaptureEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (_u *APIKeyUpdate) SetResponseLanguage(v string) *APIKeyUpdate {
+	_u.mutation.SetResponseLanguage(v)
+	return _u
+}
+
+// SetNillableResponseLanguage sets the "response_language" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableResponseLanguage(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetResponseLanguage(*v)
+	}
+	return _u
+}
+
+// SetParentKeyID sets the "parent_key_id" field.
+func (_u *APIKeyUpdate) SetParentKeyID(v int64) *APIKeyUpdate {
+	_u.mutation.ResetParentKeyID()
+	_u.mutation.SetParentKeyID(v)
+	return _u
+}
+
+// SetNillableParentKeyID sets the "parent_key_id" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableParentKeyID(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetParentKeyID(*v)
+	}
+	return _u
+}
+
+// AddParentKeyID adds value to the "parent_key_id" field.
+func (_u *APIKeyUpdate) AddParentKeyID(v int64) *APIKeyUpdate {
+	_u.mutation.AddParentKeyID(v)
+	return _u
+}
+
+// ClearParentKeyID clears the value of the "parent_key_id" field.
+func (_u *APIKeyUpdate) ClearParentKeyID() *APIKeyUpdate {
+	_u.mutation.ClearParentKeyID()
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdate) SetAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdate) AppendAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (_u *APIKeyUpdate) ClearAllowedModels() *APIKeyUpdate {
+	_u.mutation.ClearAllowedModels()
+	return _u
+}
+
+// SetMaxPriority sets the "max_priority" field.
+func (_u *APIKeyUpdate) SetMaxPriority(v string) *APIKeyUpdate {
+	_u.mutation.SetMaxPriority(v)
+	return _u
+}
+
+// SetNillableMaxPriority sets the "max_priority" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableMaxPriority(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetMaxPriority(*v)
+	}
+	return _u
+}
+
+// SetStrictValidation sets the "strict_validation" field.
+func (_u *APIKeyUpdate) SetStrictValidation(v bool) *APIKeyUpdate {
+	_u.mutation.SetStrictValidation(v)
+	return _u
+}
+
+// SetNillableStrictValidation sets the "strict_validation" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableStrictValidation(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetStrictValidation(*v)
+	}
+	return _u
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (_u *APIKeyUpdate) SetMaxRequestCost(v float64) *APIKeyUpdate {
+	_u.mutation.ResetMaxRequestCost()
+	_u.mutation.SetMaxRequestCost(v)
+	return _u
+}
+
+// SetNillableMaxRequestCost sets the "max_request_cost" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableMaxRequestCost(v *float64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetMaxRequestCost(*v)
+	}
+	return _u
+}
+
+// AddMaxRequestCost adds value to the "max_request_cost" field.
+func (_u *APIKeyUpdate) AddMaxRequestCost(v float64) *APIKeyUpdate {
+	_u.mutation.AddMaxRequestCost(v)
+	return _u
+}
+
+// SetBetaFeatures sets the "beta_features" field.
+func (_u *APIKeyUpdate) SetBetaFeatures(v []string) *APIKeyUpdate {
+	_u.mutation.SetBetaFeatures(v)
+	return _u
+}
+
+// AppendBetaFeatures appends value to the "beta_features" field.
+func (_u *APIKeyUpdate) AppendBetaFeatures(v []string) *APIKeyUpdate {
+	_u.mutation.AppendBetaFeatures(v)
+	return _u
+}
+
+// ClearBetaFeatures clears the value of the "beta_features" field.
+func (_u *APIKeyUpdate) ClearBetaFeatures() *APIKeyUpdate {
+	_u.mutation.ClearBetaFeatures()
+	return _u
+}
+
+// SetDataResidency sets the "data_residency" field.
+func (_u *APIKeyUpdate) SetDataResidency(v string) *APIKeyUpdate {
+	_u.mutation.SetDataResidency(v)
+	return _u
+}
+
+// SetNillableDataResidency sets the "data_residency" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableDataResidency(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetDataResidency(*v)
+	}
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdate) SetUser(v *User) *APIKeyUpdate {
 	return _u.SetUserID(v.ID)
@@ -560,6 +742,21 @@ func (_u *APIKeyUpdate) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ResponseLanguage(); ok {
+		if err := apikey.ResponseLanguageValidator(v); err != nil {
+			return &ValidationError{Name: "response_language", err: fmt.Errorf(`ent: validator failed for field "APIKey.response_language": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.MaxPriority(); ok {
+		if err := apikey.MaxPriorityValidator(v); err != nil {
+			return &ValidationError{Name: "max_priority", err: fmt.Errorf(`ent: validator failed for field "APIKey.max_priority": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.DataResidency(); ok {
+		if err := apikey.DataResidencyValidator(v); err != nil {
+			return &ValidationError{Name: "data_residency", err: fmt.Errorf(`ent: validator failed for field "APIKey.data_residency": %w`, err)}
+		}
+	}
 	if _u.mutation.UserCleared() && len(_u.mutation.UserIDs()) > 0 {
 		return errors.New(`ent: clearing a required unique edge "APIKey.user"`)
 	}
@@ -696,6 +893,64 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.TranscriptEnabled(); ok {
+		_spec.SetField(apikey.FieldTranscriptEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.AnnotationsEnabled(); ok {
+		_spec.SetField(apikey.FieldAnnotationsEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.StreamCaptureEnabled(); ok {
+		_spec.SetField(apikey.FieldStreamCaptureEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseLanguage(); ok {
+		_spec.SetField(apikey.FieldResponseLanguage, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ParentKeyID(); ok {
+		_spec.SetField(apikey.FieldParentKeyID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedParentKeyID(); ok {
+		_spec.AddField(apikey.FieldParentKeyID, field.TypeInt64, value)
+	}
+	if _u.mutation.ParentKeyIDCleared() {
+		_spec.ClearField(apikey.FieldParentKeyID, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
+	if _u.mutation.AllowedModelsCleared() {
+		_spec.ClearField(apikey.FieldAllowedModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.MaxPriority(); ok {
+		_spec.SetField(apikey.FieldMaxPriority, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.StrictValidation(); ok {
+		_spec.SetField(apikey.FieldStrictValidation, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.MaxRequestCost(); ok {
+		_spec.SetField(apikey.FieldMaxRequestCost, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxRequestCost(); ok {
+		_spec.AddField(apikey.FieldMaxRequestCost, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BetaFeatures(); ok {
+		_spec.SetField(apikey.FieldBetaFeatures, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedBetaFeatures(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldBetaFeatures, value)
+		})
+	}
+	if _u.mutation.BetaFeaturesCleared() {
+		_spec.ClearField(apikey.FieldBetaFeatures, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.DataResidency(); ok {
+		_spec.SetField(apikey.FieldDataResidency, field.TypeString, value)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -1225,6 +1480,188 @@ func (_u *APIKeyUpdateOne) ClearWindow7dStart() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetTranscriptEnabled sets the "transcript_enabled" field.
+func (_u *APIKeyUpdateOne) SetTranscriptEnabled(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetTranscriptEnabled(v)
+	return _u
+}
+
+// SetNillableTranscriptEnabled sets the "transcript_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableTranscriptEnabled(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetTranscriptEnabled(*v)
+	}
+	return _u
+}
+
+// SetAnnotationsEnabled sets the "annotations_enabled" field.
+func (_u *APIKeyUpdateOne) SetAnnotationsEnabled(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetAnnotationsEnabled(v)
+	return _u
+}
+
+// SetNillableAnnotationsEnabled sets the "annotations_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableAnnotationsEnabled(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetAnnotationsEnabled(*v)
+	}
+	return _u
+}
+
+// SetStreamCaptureEnabled sets the "stream_capture_enabled" field.
+func (_u *APIKeyUpdateOne) SetStreamCaptureEnabled(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetStreamCaptureEnabled(v)
+	return _u
+}
+
+// SetNillableStreamCaptureEnabled sets the "stream_capture_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableStreamCaptureEnabled(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetStreamCaptureEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (_u *APIKeyUpdateOne) SetResponseLanguage(v string) *APIKeyUpdateOne {
+	_u.mutation.SetResponseLanguage(v)
+	return _u
+}
+
+// SetNillableResponseLanguage sets the "response_language" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableResponseLanguage(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetResponseLanguage(*v)
+	}
+	return _u
+}
+
+// SetParentKeyID sets the "parent_key_id" field.
+func (_u *APIKeyUpdateOne) SetParentKeyID(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetParentKeyID()
+	_u.mutation.SetParentKeyID(v)
+	return _u
+}
+
+// SetNillableParentKeyID sets the "parent_key_id" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableParentKeyID(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetParentKeyID(*v)
+	}
+	return _u
+}
+
+// AddParentKeyID adds value to the "parent_key_id" field.
+func (_u *APIKeyUpdateOne) AddParentKeyID(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddParentKeyID(v)
+	return _u
+}
+
+// ClearParentKeyID clears the value of the "parent_key_id" field.
+func (_u *APIKeyUpdateOne) ClearParentKeyID() *APIKeyUpdateOne {
+	_u.mutation.ClearParentKeyID()
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdateOne) SetAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdateOne) AppendAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (_u *APIKeyUpdateOne) ClearAllowedModels() *APIKeyUpdateOne {
+	_u.mutation.ClearAllowedModels()
+	return _u
+}
+
+// SetMaxPriority sets the "max_priority" field.
+func (_u *APIKeyUpdateOne) SetMaxPriority(v string) *APIKeyUpdateOne {
+	_u.mutation.SetMaxPriority(v)
+	return _u
+}
+
+// SetNillableMaxPriority sets the "max_priority" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableMaxPriority(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetMaxPriority(*v)
+	}
+	return _u
+}
+
+// SetStrictValidation sets the "strict_validation" field.
+func (_u *APIKeyUpdateOne) SetStrictValidation(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetStrictValidation(v)
+	return _u
+}
+
+// SetNillableStrictValidation sets the "strict_validation" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableStrictValidation(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetStrictValidation(*v)
+	}
+	return _u
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (_u *APIKeyUpdateOne) SetMaxRequestCost(v float64) *APIKeyUpdateOne {
+	_u.mutation.ResetMaxRequestCost()
+	_u.mutation.SetMaxRequestCost(v)
+	return _u
+}
+
+// SetNillableMaxRequestCost sets the "max_request_cost" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableMaxRequestCost(v *float64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetMaxRequestCost(*v)
+	}
+	return _u
+}
+
+// AddMaxRequestCost adds value to the "max_request_cost" field.
+func (_u *APIKeyUpdateOne) AddMaxRequestCost(v float64) *APIKeyUpdateOne {
+	_u.mutation.AddMaxRequestCost(v)
+	return _u
+}
+
+// SetBetaFeatures sets the "beta_features" field.
+func (_u *APIKeyUpdateOne) SetBetaFeatures(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetBetaFeatures(v)
+	return _u
+}
+
+// AppendBetaFeatures appends value to the "beta_features" field.
+func (_u *APIKeyUpdateOne) AppendBetaFeatures(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendBetaFeatures(v)
+	return _u
+}
+
+// ClearBetaFeatures clears the value of the "beta_features" field.
+func (_u *APIKeyUpdateOne) ClearBetaFeatures() *APIKeyUpdateOne {
+	_u.mutation.ClearBetaFeatures()
+	return _u
+}
+
+// SetDataResidency sets the "data_residency" field.
+func (_u *APIKeyUpdateOne) SetDataResidency(v string) *APIKeyUpdateOne {
+	_u.mutation.SetDataResidency(v)
+	return _u
+}
+
+// SetNillableDataResidency sets the "data_residency" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableDataResidency(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetDataResidency(*v)
+	}
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdateOne) SetUser(v *User) *APIKeyUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -1360,6 +1797,21 @@ func (_u *APIKeyUpdateOne) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ResponseLanguage(); ok {
+		if err := apikey.ResponseLanguageValidator(v); err != nil {
+			return &ValidationError{Name: "response_language", err: fmt.Errorf(`ent: validator failed for field "APIKey.response_language": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.MaxPriority(); ok {
+		if err := apikey.MaxPriorityValidator(v); err != nil {
+			return &ValidationError{Name: "max_priority", err: fmt.Errorf(`ent: validator failed for field "APIKey.max_priority": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.DataResidency(); ok {
+		if err := apikey.DataResidencyValidator(v); err != nil {
+			return &ValidationError{Name: "data_residency", err: fmt.Errorf(`ent: validator failed for field "APIKey.data_residency": %w`, err)}
+		}
+	}
 	if _u.mutation.UserCleared() && len(_u.mutation.UserIDs()) > 0 {
 		return errors.New(`ent: clearing a required unique edge "APIKey.user"`)
 	}
@@ -1513,6 +1965,64 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.TranscriptEnabled(); ok {
+		_spec.SetField(apikey.FieldTranscriptEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.AnnotationsEnabled(); ok {
+		_spec.SetField(apikey.FieldAnnotationsEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.StreamCaptureEnabled(); ok {
+		_spec.SetField(apikey.FieldStreamCaptureEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseLanguage(); ok {
+		_spec.SetField(apikey.FieldResponseLanguage, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ParentKeyID(); ok {
+		_spec.SetField(apikey.FieldParentKeyID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedParentKeyID(); ok {
+		_spec.AddField(apikey.FieldParentKeyID, field.TypeInt64, value)
+	}
+	if _u.mutation.ParentKeyIDCleared() {
+		_spec.ClearField(apikey.FieldParentKeyID, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
+	if _u.mutation.AllowedModelsCleared() {
+		_spec.ClearField(apikey.FieldAllowedModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.MaxPriority(); ok {
+		_spec.SetField(apikey.FieldMaxPriority, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.StrictValidation(); ok {
+		_spec.SetField(apikey.FieldStrictValidation, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.MaxRequestCost(); ok {
+		_spec.SetField(apikey.FieldMaxRequestCost, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxRequestCost(); ok {
+		_spec.AddField(apikey.FieldMaxRequestCost, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BetaFeatures(); ok {
+		_spec.SetField(apikey.FieldBetaFeatures, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedBetaFeatures(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldBetaFeatures, value)
+		})
+	}
+	if _u.mutation.BetaFeaturesCleared() {
+		_spec.ClearField(apikey.FieldBetaFeatures, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.DataResidency(); ok {
+		_spec.SetField(apikey.FieldDataResidency, field.TypeString, value)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -117,6 +117,22 @@ type Group struct {
 	ModelsListConfig domain.GroupModelsListConfig `json:"models_list_config,omitempty"`
 	// 分组 RPM 上限，0 表示不限制；设置后接管该分组用户的限流
 	RpmLimit int `json:"rpm_limit,omitempty"`
+	// 是否允许透传 computer use 工具（computer_*）
+	AllowComputerUseTool bool `json:"allow_computer_use_tool,omitempty"`
+	// 是否允许透传 code execution 工具（code_execution_*）
+	AllowCodeExecutionTool bool `json:"allow_code_execution_tool,omitempty"`
+	// 是否允许透传服务端 web search 工具（web_search_*）
+	AllowWebSearchTool bool `json:"allow_web_search_tool,omitempty"`
+	// system prompt 注入档位：claude-code / api-default / no-injection，空表示沿用全局设置
+	SystemPromptProfile string `json:"system_prompt_profile,omitempty"`
+	// 强制回复语言代码（如 zh / en），请求未指定语言时追加语言指令，空表示不强制
+	ResponseLanguage string `json:"response_language,omitempty"`
+	// 文本响应归属水印：footer（可见页脚）/ invisible（零宽字符），空表示不添加
+	AttributionMode string `json:"attribution_mode,omitempty"`
+	// footer 模式的页脚模板，支持 {request_id} {model} {group} {timestamp} 占位符
+	AttributionText string `json:"attribution_text,omitempty"`
+	// 单次请求预估费用上限（美元，按倍率折算后），0 表示不限制
+	MaxRequestCost float64 `json:"max_request_cost,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the GroupQuery when eager-loading is set.
 	Edges        GroupEdges `json:"edges"`
@@ -225,13 +241,13 @@ func (*Group) scanValues(columns []string) ([]any, error) {
 		switch columns[i] {
 		case group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldModelsListConfig:
 			values[i] = new([]byte)
-		case group.FieldPeakRateEnabled, group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldAllowBatchImageGeneration, group.FieldImageRateIndependent, group.FieldVideoRateIndependent, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet:
+		case group.FieldPeakRateEnabled, group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldAllowBatchImageGeneration, group.FieldImageRateIndependent, group.FieldVideoRateIndependent, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldAllowComputerUseTool, group.FieldAllowCodeExecutionTool, group.FieldAllowWebSearchTool:
 			values[i] = new(sql.NullBool)
-		case group.FieldRateMultiplier, group.FieldPeakRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldBatchImageDiscountMultiplier, group.FieldBatchImageHoldMultiplier, group.FieldVideoRateMultiplier, group.FieldVideoPrice480p, group.FieldVideoPrice720p, group.FieldVideoPrice1080p, group.FieldWebSearchPricePerCall:
+		case group.FieldRateMultiplier, group.FieldPeakRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldBatchImageDiscountMultiplier, group.FieldBatchImageHoldMultiplier, group.FieldVideoRateMultiplier, group.FieldVideoPrice480p, group.FieldVideoPrice720p, group.FieldVideoPrice1080p, group.FieldWebSearchPricePerCall, group.FieldMaxRequestCost:
 			values[i] = new(sql.NullFloat64)
 		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit:
 			values[i] = new(sql.NullInt64)
-		case group.FieldName, group.FieldDescription, group.FieldPeakStart, group.FieldPeakEnd, group.FieldStatus, group.FieldDuplicateOperationID, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel:
+		case group.FieldName, group.FieldDescription, group.FieldPeakStart, group.FieldPeakEnd, group.FieldStatus, group.FieldDuplicateOperationID, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel, group.FieldSystemPromptProfile, group.FieldResponseLanguage, group.FieldAttributionMode, group.FieldAttributionText:
 			values[i] = new(sql.NullString)
 		case group.FieldCreatedAt, group.FieldUpdatedAt, group.FieldDeletedAt:
 			values[i] = new(sql.NullTime)
@@ -573,6 +589,54 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.RpmLimit = int(value.Int64)
 			}
+		case group.FieldAllowComputerUseTool:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field allow_computer_use_tool", values[i])
+			} else if value.Valid {
+				_m.AllowComputerUseTool = value.Bool
+			}
+		case group.FieldAllowCodeExecutionTool:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field allow_code_execution_tool", values[i])
+			} else if value.Valid {
+				_m.AllowCodeExecutionTool = value.Bool
+			}
+		case group.FieldAllowWebSearchTool:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field allow_web_search_tool", values[i])
+			} else if value.Valid {
+				_m.AllowWebSearchTool = value.Bool
+			}
+		case group.FieldSystemPromptProfile:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field system_prompt_profile", values[i])
+			} else if value.Valid {
+				_m.SystemPromptProfile = value.String
+			}
+		case group.FieldResponseLanguage:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field response_language", values[i])
+			} else if value.Valid {
+				_m.ResponseLanguage = value.String
+			}
+		case group.FieldAttributionMode:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field attribution_mode", values[i])
+			} else if value.Valid {
+				_m.AttributionMode = value.String
+			}
+		case group.FieldAttributionText:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field attribution_text", values[i])
+			} else if value.Valid {
+				_m.AttributionText = value.String
+			}
+		case group.FieldMaxRequestCost:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field max_request_cost", values[i])
+			} else if value.Valid {
+				_m.MaxRequestCost = value.Float64
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -825,6 +889,30 @@ func (_m *Group) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("rpm_limit=")
 	builder.WriteString(fmt.Sprintf("%v", _m.RpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("allow_computer_use_tool=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowComputerUseTool))
+	builder.WriteString(", ")
+	builder.WriteString("allow_code_execution_tool=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowCodeExecutionTool))
+	builder.WriteString(", ")
+	builder.WriteString("allow_web_search_tool=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowWebSearchTool))
+	builder.WriteString(", ")
+	builder.WriteString("system_prompt_profile=")
+	builder.WriteString(_m.SystemPromptProfile)
+	builder.WriteString(", ")
+	builder.WriteString("response_language=")
+	builder.WriteString(_m.ResponseLanguage)
+	builder.WriteString(", ")
+	builder.WriteString("attribution_mode=")
+	builder.WriteString(_m.AttributionMode)
+	builder.WriteString(", ")
+	builder.WriteString("attribution_text=")
+	builder.WriteString(_m.AttributionText)
+	builder.WriteString(", ")
+	builder.WriteString("max_request_cost=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MaxRequestCost))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -114,6 +114,22 @@ const (
 	FieldModelsListConfig = "models_list_config"
 	// FieldRpmLimit holds the string denoting the rpm_limit field in the database.
 	FieldRpmLimit = "rpm_limit"
+	// FieldAllowComputerUseTool holds the string denoting the allow_computer_use_tool field in the database.
+	FieldAllowComputerUseTool = "allow_computer_use_tool"
+	// FieldAllowCodeExecutionTool holds the string denoting the allow_code_execution_tool field in the database.
+	FieldAllowCodeExecutionTool = "allow_code_execution_tool"
+	// FieldAllowWebSearchTool holds the string denoting the allow_web_search_tool field in the database.
+	FieldAllowWebSearchTool = "allow_web_search_tool"
+	// FieldSystemPromptProfile holds the string denoting the system_prompt_profile field in the database.
+	FieldSystemPromptProfile = "system_prompt_profile"
+	// FieldResponseLanguage holds the string denoting the response_language field in the database.
+	FieldResponseLanguage = "response_language"
+	// FieldAttributionMode holds the string denoting the attribution_mode field in the database.
+	FieldAttributionMode = "attribution_mode"
+	// FieldAttributionText holds the string denoting the attribution_text field in the database.
+	FieldAttributionText = "attribution_text"
+	// FieldMaxRequestCost holds the string denoting the max_request_cost field in the database.
+	FieldMaxRequestCost = "max_request_cost"
 	// EdgeAPIKeys holds the string denoting the api_keys edge name in mutations.
 	EdgeAPIKeys = "api_keys"
 	// EdgeRedeemCodes holds the string denoting the redeem_codes edge name in mutations.
@@ -238,6 +254,14 @@ var Columns = []string{
 	FieldMessagesDispatchModelConfig,
 	FieldModelsListConfig,
 	FieldRpmLimit,
+	FieldAllowComputerUseTool,
+	FieldAllowCodeExecutionTool,
+	FieldAllowWebSearchTool,
+	FieldSystemPromptProfile,
+	FieldResponseLanguage,
+	FieldAttributionMode,
+	FieldAttributionText,
+	FieldMaxRequestCost,
 }
 
 var (
@@ -349,6 +373,30 @@ var (
 	DefaultModelsListConfig domain.GroupModelsListConfig
 	// DefaultRpmLimit holds the default value on creation for the "rpm_limit" field.
 	DefaultRpmLimit int
+	// DefaultAllowComputerUseTool holds the default value on creation for the "allow_computer_use_tool" field.
+	DefaultAllowComputerUseTool bool
+	// DefaultAllowCodeExecutionTool holds the default value on creation for the "allow_code_execution_tool" field.
+	DefaultAllowCodeExecutionTool bool
+	// DefaultAllowWebSearchTool holds the default value on creation for the "allow_web_search_tool" field.
+	DefaultAllowWebSearchTool bool
+	// DefaultSystemPromptProfile holds the default value on creation for the "system_prompt_profile" field.
+	DefaultSystemPromptProfile string
+	// SystemPromptProfileValidator is a validator for the "system_prompt_profile" field. It is called by the builders before save.
+	SystemPromptProfileValidator func(string) error
+	// DefaultResponseLanguage holds the default value on creation for the "response_language" field.
+	DefaultResponseLanguage string
+	// ResponseLanguageValidator is a validator for the "response_language" field. It is called by the builders before save.
+	ResponseLanguageValidator func(string) error
+	// DefaultAttributionMode holds the default value on creation for the "attribution_mode" field.
+	DefaultAttributionMode string
+	// AttributionModeValidator is a validator for the "attribution_mode" field. It is called by the builders before save.
+	AttributionModeValidator func(string) error
+	// DefaultAttributionText holds the default value on creation for the "attribution_text" field.
+	DefaultAttributionText string
+	// AttributionTextValidator is a validator for the "attribution_text" field. It is called by the builders before save.
+	AttributionTextValidator func(string) error
+	// DefaultMaxRequestCost holds the default value on creation for the "max_request_cost" field.
+	DefaultMaxRequestCost float64
 )
 
 // OrderOption defines the ordering options for the Group queries.
@@ -584,6 +632,46 @@ func ByRpmLimit(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldRpmLimit, opts...).ToFunc()
 }
 
+// ByAllowComputerUseTool orders the results by the allow_computer_use_tool field.
+func ByAllowComputerUseTool(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAllowComputerUseTool, opts...).ToFunc()
+}
+
+// ByAllowCodeExecutionTool orders the results by the allow_code_execution_tool field.
+func ByAllowCodeExecutionTool(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAllowCodeExecutionTool, opts...).ToFunc()
+}
+
+// ByAllowWebSearchTool orders the results by the allow_web_search_tool field.
+func ByAllowWebSearchTool(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAllowWebSearchTool, opts...).ToFunc()
+}
+
+// BySystemPromptProfile orders the results by the system_prompt_profile field.
+func BySystemPromptProfile(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSystemPromptProfile, opts...).ToFunc()
+}
+
+// ByResponseLanguage orders the results by the response_language field.
+func ByResponseLanguage(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseLanguage, opts...).ToFunc()
+}
+
+// ByAttributionMode orders the results by the attribution_mode field.
+func ByAttributionMode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAttributionMode, opts...).ToFunc()
+}
+
+// ByAttributionText orders the results by the attribution_text field.
+func ByAttributionText(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAttributionText, opts...).ToFunc()
+}
+
+// ByMaxRequestCost orders the results by the max_request_cost field.
+func ByMaxRequestCost(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMaxRequestCost, opts...).ToFunc()
+}
+
 // ByAPIKeysCount orders the results by api_keys count.
 func ByAPIKeysCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
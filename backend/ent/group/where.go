@@ -280,6 +280,46 @@ func RpmLimit(v int) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldRpmLimit, v))
 }
 
+// AllowComputerUseTool applies equality check predicate on the "allow_computer_use_tool" field. It's identical to AllowComputerUseToolEQ.
+func AllowComputerUseTool(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowComputerUseTool, v))
+}
+
+// AllowCodeExecutionTool applies equality check predicate on the "allow_code_execution_tool" field. It's identical to AllowCodeExecutionToolEQ.
+func AllowCodeExecutionTool(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowCodeExecutionTool, v))
+}
+
+// AllowWebSearchTool applies equality check predicate on the "allow_web_search_tool" field. It's identical to AllowWebSearchToolEQ.
+func AllowWebSearchTool(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowWebSearchTool, v))
+}
+
+// SystemPromptProfile applies equality check predicate on the "system_prompt_profile" field. It's identical to SystemPromptProfileEQ.
+func SystemPromptProfile(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSystemPromptProfile, v))
+}
+
+// ResponseLanguage applies equality check predicate on the "response_language" field. It's identical to ResponseLanguageEQ.
+func ResponseLanguage(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseLanguage, v))
+}
+
+// AttributionMode applies equality check predicate on the "attribution_mode" field. It's identical to AttributionModeEQ.
+func AttributionMode(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAttributionMode, v))
+}
+
+// AttributionText applies equality check predicate on the "attribution_text" field. It's identical to AttributionTextEQ.
+func AttributionText(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAttributionText, v))
+}
+
+// MaxRequestCost applies equality check predicate on the "max_request_cost" field. It's identical to MaxRequestCostEQ.
+func MaxRequestCost(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldMaxRequestCost, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldCreatedAt, v))
@@ -2105,6 +2145,336 @@ func RpmLimitLTE(v int) predicate.Group {
 	return predicate.Group(sql.FieldLTE(FieldRpmLimit, v))
 }
 
+// AllowComputerUseToolEQ applies the EQ predicate on the "allow_computer_use_tool" field.
+func AllowComputerUseToolEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowComputerUseTool, v))
+}
+
+// AllowComputerUseToolNEQ applies the NEQ predicate on the "allow_computer_use_tool" field.
+func AllowComputerUseToolNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAllowComputerUseTool, v))
+}
+
+// AllowCodeExecutionToolEQ applies the EQ predicate on the "allow_code_execution_tool" field.
+func AllowCodeExecutionToolEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowCodeExecutionTool, v))
+}
+
+// AllowCodeExecutionToolNEQ applies the NEQ predicate on the "allow_code_execution_tool" field.
+func AllowCodeExecutionToolNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAllowCodeExecutionTool, v))
+}
+
+// AllowWebSearchToolEQ applies the EQ predicate on the "allow_web_search_tool" field.
+func AllowWebSearchToolEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowWebSearchTool, v))
+}
+
+// AllowWebSearchToolNEQ applies the NEQ predicate on the "allow_web_search_tool" field.
+func AllowWebSearchToolNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAllowWebSearchTool, v))
+}
+
+// SystemPromptProfileEQ applies the EQ predicate on the "system_prompt_profile" field.
+func SystemPromptProfileEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileNEQ applies the NEQ predicate on the "system_prompt_profile" field.
+func SystemPromptProfileNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileIn applies the In predicate on the "system_prompt_profile" field.
+func SystemPromptProfileIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldSystemPromptProfile, vs...))
+}
+
+// SystemPromptProfileNotIn applies the NotIn predicate on the "system_prompt_profile" field.
+func SystemPromptProfileNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldSystemPromptProfile, vs...))
+}
+
+// SystemPromptProfileGT applies the GT predicate on the "system_prompt_profile" field.
+func SystemPromptProfileGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileGTE applies the GTE predicate on the "system_prompt_profile" field.
+func SystemPromptProfileGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileLT applies the LT predicate on the "system_prompt_profile" field.
+func SystemPromptProfileLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileLTE applies the LTE predicate on the "system_prompt_profile" field.
+func SystemPromptProfileLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileContains applies the Contains predicate on the "system_prompt_profile" field.
+func SystemPromptProfileContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileHasPrefix applies the HasPrefix predicate on the "system_prompt_profile" field.
+func SystemPromptProfileHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileHasSuffix applies the HasSuffix predicate on the "system_prompt_profile" field.
+func SystemPromptProfileHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileEqualFold applies the EqualFold predicate on the "system_prompt_profile" field.
+func SystemPromptProfileEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldSystemPromptProfile, v))
+}
+
+// SystemPromptProfileContainsFold applies the ContainsFold predicate on the "system_prompt_profile" field.
+func SystemPromptProfileContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldSystemPromptProfile, v))
+}
+
+// ResponseLanguageEQ applies the EQ predicate on the "response_language" field.
+func ResponseLanguageEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageNEQ applies the NEQ predicate on the "response_language" field.
+func ResponseLanguageNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageIn applies the In predicate on the "response_language" field.
+func ResponseLanguageIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldResponseLanguage, vs...))
+}
+
+// ResponseLanguageNotIn applies the NotIn predicate on the "response_language" field.
+func ResponseLanguageNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldResponseLanguage, vs...))
+}
+
+// ResponseLanguageGT applies the GT predicate on the "response_language" field.
+func ResponseLanguageGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageGTE applies the GTE predicate on the "response_language" field.
+func ResponseLanguageGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageLT applies the LT predicate on the "response_language" field.
+func ResponseLanguageLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageLTE applies the LTE predicate on the "response_language" field.
+func ResponseLanguageLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageContains applies the Contains predicate on the "response_language" field.
+func ResponseLanguageContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageHasPrefix applies the HasPrefix predicate on the "response_language" field.
+func ResponseLanguageHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageHasSuffix applies the HasSuffix predicate on the "response_language" field.
+func ResponseLanguageHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageEqualFold applies the EqualFold predicate on the "response_language" field.
+func ResponseLanguageEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldResponseLanguage, v))
+}
+
+// ResponseLanguageContainsFold applies the ContainsFold predicate on the "response_language" field.
+func ResponseLanguageContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldResponseLanguage, v))
+}
+
+// AttributionModeEQ applies the EQ predicate on the "attribution_mode" field.
+func AttributionModeEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAttributionMode, v))
+}
+
+// AttributionModeNEQ applies the NEQ predicate on the "attribution_mode" field.
+func AttributionModeNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAttributionMode, v))
+}
+
+// AttributionModeIn applies the In predicate on the "attribution_mode" field.
+func AttributionModeIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldAttributionMode, vs...))
+}
+
+// AttributionModeNotIn applies the NotIn predicate on the "attribution_mode" field.
+func AttributionModeNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldAttributionMode, vs...))
+}
+
+// AttributionModeGT applies the GT predicate on the "attribution_mode" field.
+func AttributionModeGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldAttributionMode, v))
+}
+
+// AttributionModeGTE applies the GTE predicate on the "attribution_mode" field.
+func AttributionModeGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldAttributionMode, v))
+}
+
+// AttributionModeLT applies the LT predicate on the "attribution_mode" field.
+func AttributionModeLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldAttributionMode, v))
+}
+
+// AttributionModeLTE applies the LTE predicate on the "attribution_mode" field.
+func AttributionModeLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldAttributionMode, v))
+}
+
+// AttributionModeContains applies the Contains predicate on the "attribution_mode" field.
+func AttributionModeContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldAttributionMode, v))
+}
+
+// AttributionModeHasPrefix applies the HasPrefix predicate on the "attribution_mode" field.
+func AttributionModeHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldAttributionMode, v))
+}
+
+// AttributionModeHasSuffix applies the HasSuffix predicate on the "attribution_mode" field.
+func AttributionModeHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldAttributionMode, v))
+}
+
+// AttributionModeEqualFold applies the EqualFold predicate on the "attribution_mode" field.
+func AttributionModeEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldAttributionMode, v))
+}
+
+// AttributionModeContainsFold applies the ContainsFold predicate on the "attribution_mode" field.
+func AttributionModeContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldAttributionMode, v))
+}
+
+// AttributionTextEQ applies the EQ predicate on the "attribution_text" field.
+func AttributionTextEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAttributionText, v))
+}
+
+// AttributionTextNEQ applies the NEQ predicate on the "attribution_text" field.
+func AttributionTextNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAttributionText, v))
+}
+
+// AttributionTextIn applies the In predicate on the "attribution_text" field.
+func AttributionTextIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldAttributionText, vs...))
+}
+
+// AttributionTextNotIn applies the NotIn predicate on the "attribution_text" field.
+func AttributionTextNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldAttributionText, vs...))
+}
+
+// AttributionTextGT applies the GT predicate on the "attribution_text" field.
+func AttributionTextGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldAttributionText, v))
+}
+
+// AttributionTextGTE applies the GTE predicate on the "attribution_text" field.
+func AttributionTextGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldAttributionText, v))
+}
+
+// AttributionTextLT applies the LT predicate on the "attribution_text" field.
+func AttributionTextLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldAttributionText, v))
+}
+
+// AttributionTextLTE applies the LTE predicate on the "attribution_text" field.
+func AttributionTextLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldAttributionText, v))
+}
+
+// AttributionTextContains applies the Contains predicate on the "attribution_text" field.
+func AttributionTextContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldAttributionText, v))
+}
+
+// AttributionTextHasPrefix applies the HasPrefix predicate on the "attribution_text" field.
+func AttributionTextHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldAttributionText, v))
+}
+
+// AttributionTextHasSuffix applies the HasSuffix predicate on the "attribution_text" field.
+func AttributionTextHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldAttributionText, v))
+}
+
+// AttributionTextEqualFold applies the EqualFold predicate on the "attribution_text" field.
+func AttributionTextEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldAttributionText, v))
+}
+
+// AttributionTextContainsFold applies the ContainsFold predicate on the "attribution_text" field.
+func AttributionTextContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldAttributionText, v))
+}
+
+// MaxRequestCostEQ applies the EQ predicate on the "max_request_cost" field.
+func MaxRequestCostEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostNEQ applies the NEQ predicate on the "max_request_cost" field.
+func MaxRequestCostNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostIn applies the In predicate on the "max_request_cost" field.
+func MaxRequestCostIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldMaxRequestCost, vs...))
+}
+
+// MaxRequestCostNotIn applies the NotIn predicate on the "max_request_cost" field.
+func MaxRequestCostNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldMaxRequestCost, vs...))
+}
+
+// MaxRequestCostGT applies the GT predicate on the "max_request_cost" field.
+func MaxRequestCostGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostGTE applies the GTE predicate on the "max_request_cost" field.
+func MaxRequestCostGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostLT applies the LT predicate on the "max_request_cost" field.
+func MaxRequestCostLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldMaxRequestCost, v))
+}
+
+// MaxRequestCostLTE applies the LTE predicate on the "max_request_cost" field.
+func MaxRequestCostLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldMaxRequestCost, v))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.Group {
 	return predicate.Group(func(s *sql.Selector) {
@@ -691,6 +691,118 @@ func (_c *GroupCreate) SetNillableRpmLimit(v *int) *GroupCreate {
 	return _c
 }
 
+// SetAllowComputerUseTool sets the "allow_computer_use_tool" field.
+func (_c *GroupCreate) SetAllowComputerUseTool(v bool) *GroupCreate {
+	_c.mutation.SetAllowComputerUseTool(v)
+	return _c
+}
+
+// SetNillableAllowComputerUseTool sets the "allow_computer_use_tool" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAllowComputerUseTool(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetAllowComputerUseTool(*v)
+	}
+	return _c
+}
+
+// SetAllowCodeExecutionTool sets the "allow_code_execution_tool" field.
+func (_c *GroupCreate) SetAllowCodeExecutionTool(v bool) *GroupCreate {
+	_c.mutation.SetAllowCodeExecutionTool(v)
+	return _c
+}
+
+// SetNillableAllowCodeExecutionTool sets the "allow_code_execution_tool" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAllowCodeExecutionTool(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetAllowCodeExecutionTool(*v)
+	}
+	return _c
+}
+
+// SetAllowWebSearchTool sets the "allow_web_search_tool" field.
+func (_c *GroupCreate) SetAllowWebSearchTool(v bool) *GroupCreate {
+	_c.mutation.SetAllowWebSearchTool(v)
+	return _c
+}
+
+// SetNillableAllowWebSearchTool sets the "allow_web_search_tool" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAllowWebSearchTool(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetAllowWebSearchTool(*v)
+	}
+	return _c
+}
+
+// SetSystemPromptProfile sets the "system_prompt_profile" field.
+func (_c *GroupCreate) SetSystemPromptProfile(v string) *GroupCreate {
+	_c.mutation.SetSystemPromptProfile(v)
+	return _c
+}
+
+// SetNillableSystemPromptProfile sets the "system_prompt_profile" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSystemPromptProfile(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetSystemPromptProfile(*v)
+	}
+	return _c
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (_c *GroupCreate) SetResponseLanguage(v string) *GroupCreate {
+	_c.mutation.SetResponseLanguage(v)
+	return _c
+}
+
+// SetNillableResponseLanguage sets the "response_language" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseLanguage(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetResponseLanguage(*v)
+	}
+	return _c
+}
+
+// SetAttributionMode sets the "attribution_mode" field.
+func (_c *GroupCreate) SetAttributionMode(v string) *GroupCreate {
+	_c.mutation.SetAttributionMode(v)
+	return _c
+}
+
+// SetNillableAttributionMode sets the "attribution_mode" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAttributionMode(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetAttributionMode(*v)
+	}
+	return _c
+}
+
+// SetAttributionText sets the "attribution_text" field.
+func (_c *GroupCreate) SetAttributionText(v string) *GroupCreate {
+	_c.mutation.SetAttributionText(v)
+	return _c
+}
+
+// SetNillableAttributionText sets the "attribution_text" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAttributionText(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetAttributionText(*v)
+	}
+	return _c
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (_c *GroupCreate) SetMaxRequestCost(v float64) *GroupCreate {
+	_c.mutation.SetMaxRequestCost(v)
+	return _c
+}
+
+// SetNillableMaxRequestCost sets the "max_request_cost" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableMaxRequestCost(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetMaxRequestCost(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *GroupCreate) AddAPIKeyIDs(ids ...int64) *GroupCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -952,6 +1064,38 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultRpmLimit
 		_c.mutation.SetRpmLimit(v)
 	}
+	if _, ok := _c.mutation.AllowComputerUseTool(); !ok {
+		v := group.DefaultAllowComputerUseTool
+		_c.mutation.SetAllowComputerUseTool(v)
+	}
+	if _, ok := _c.mutation.AllowCodeExecutionTool(); !ok {
+		v := group.DefaultAllowCodeExecutionTool
+		_c.mutation.SetAllowCodeExecutionTool(v)
+	}
+	if _, ok := _c.mutation.AllowWebSearchTool(); !ok {
+		v := group.DefaultAllowWebSearchTool
+		_c.mutation.SetAllowWebSearchTool(v)
+	}
+	if _, ok := _c.mutation.SystemPromptProfile(); !ok {
+		v := group.DefaultSystemPromptProfile
+		_c.mutation.SetSystemPromptProfile(v)
+	}
+	if _, ok := _c.mutation.ResponseLanguage(); !ok {
+		v := group.DefaultResponseLanguage
+		_c.mutation.SetResponseLanguage(v)
+	}
+	if _, ok := _c.mutation.AttributionMode(); !ok {
+		v := group.DefaultAttributionMode
+		_c.mutation.SetAttributionMode(v)
+	}
+	if _, ok := _c.mutation.AttributionText(); !ok {
+		v := group.DefaultAttributionText
+		_c.mutation.SetAttributionText(v)
+	}
+	if _, ok := _c.mutation.MaxRequestCost(); !ok {
+		v := group.DefaultMaxRequestCost
+		_c.mutation.SetMaxRequestCost(v)
+	}
 	return nil
 }
 
@@ -1096,6 +1240,50 @@ func (_c *GroupCreate) check() error {
 	if _, ok := _c.mutation.RpmLimit(); !ok {
 		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "Group.rpm_limit"`)}
 	}
+	if _, ok := _c.mutation.AllowComputerUseTool(); !ok {
+		return &ValidationError{Name: "allow_computer_use_tool", err: errors.New(`ent: missing required field "Group.allow_computer_use_tool"`)}
+	}
+	if _, ok := _c.mutation.AllowCodeExecutionTool(); !ok {
+		return &ValidationError{Name: "allow_code_execution_tool", err: errors.New(`ent: missing required field "Group.allow_code_execution_tool"`)}
+	}
+	if _, ok := _c.mutation.AllowWebSearchTool(); !ok {
+		return &ValidationError{Name: "allow_web_search_tool", err: errors.New(`ent: missing required field "Group.allow_web_search_tool"`)}
+	}
+	if _, ok := _c.mutation.SystemPromptProfile(); !ok {
+		return &ValidationError{Name: "system_prompt_profile", err: errors.New(`ent: missing required field "Group.system_prompt_profile"`)}
+	}
+	if v, ok := _c.mutation.SystemPromptProfile(); ok {
+		if err := group.SystemPromptProfileValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_profile", err: fmt.Errorf(`ent: validator failed for field "Group.system_prompt_profile": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.ResponseLanguage(); !ok {
+		return &ValidationError{Name: "response_language", err: errors.New(`ent: missing required field "Group.response_language"`)}
+	}
+	if v, ok := _c.mutation.ResponseLanguage(); ok {
+		if err := group.ResponseLanguageValidator(v); err != nil {
+			return &ValidationError{Name: "response_language", err: fmt.Errorf(`ent: validator failed for field "Group.response_language": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.AttributionMode(); !ok {
+		return &ValidationError{Name: "attribution_mode", err: errors.New(`ent: missing required field "Group.attribution_mode"`)}
+	}
+	if v, ok := _c.mutation.AttributionMode(); ok {
+		if err := group.AttributionModeValidator(v); err != nil {
+			return &ValidationError{Name: "attribution_mode", err: fmt.Errorf(`ent: validator failed for field "Group.attribution_mode": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.AttributionText(); !ok {
+		return &ValidationError{Name: "attribution_text", err: errors.New(`ent: missing required field "Group.attribution_text"`)}
+	}
+	if v, ok := _c.mutation.AttributionText(); ok {
+		if err := group.AttributionTextValidator(v); err != nil {
+			return &ValidationError{Name: "attribution_text", err: fmt.Errorf(`ent: validator failed for field "Group.attribution_text": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.MaxRequestCost(); !ok {
+		return &ValidationError{Name: "max_request_cost", err: errors.New(`ent: missing required field "Group.max_request_cost"`)}
+	}
 	return nil
 }
 
@@ -1319,6 +1507,38 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldRpmLimit, field.TypeInt, value)
 		_node.RpmLimit = value
 	}
+	if value, ok := _c.mutation.AllowComputerUseTool(); ok {
+		_spec.SetField(group.FieldAllowComputerUseTool, field.TypeBool, value)
+		_node.AllowComputerUseTool = value
+	}
+	if value, ok := _c.mutation.AllowCodeExecutionTool(); ok {
+		_spec.SetField(group.FieldAllowCodeExecutionTool, field.TypeBool, value)
+		_node.AllowCodeExecutionTool = value
+	}
+	if value, ok := _c.mutation.AllowWebSearchTool(); ok {
+		_spec.SetField(group.FieldAllowWebSearchTool, field.TypeBool, value)
+		_node.AllowWebSearchTool = value
+	}
+	if value, ok := _c.mutation.SystemPromptProfile(); ok {
+		_spec.SetField(group.FieldSystemPromptProfile, field.TypeString, value)
+		_node.SystemPromptProfile = value
+	}
+	if value, ok := _c.mutation.ResponseLanguage(); ok {
+		_spec.SetField(group.FieldResponseLanguage, field.TypeString, value)
+		_node.ResponseLanguage = value
+	}
+	if value, ok := _c.mutation.AttributionMode(); ok {
+		_spec.SetField(group.FieldAttributionMode, field.TypeString, value)
+		_node.AttributionMode = value
+	}
+	if value, ok := _c.mutation.AttributionText(); ok {
+		_spec.SetField(group.FieldAttributionText, field.TypeString, value)
+		_node.AttributionText = value
+	}
+	if value, ok := _c.mutation.MaxRequestCost(); ok {
+		_spec.SetField(group.FieldMaxRequestCost, field.TypeFloat64, value)
+		_node.MaxRequestCost = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -2255,6 +2475,108 @@ func (u *GroupUpsert) AddRpmLimit(v int) *GroupUpsert {
 	return u
 }
 
+// SetAllowComputerUseTool sets the "allow_computer_use_tool" field.
+func (u *GroupUpsert) SetAllowComputerUseTool(v bool) *GroupUpsert {
+	u.Set(group.FieldAllowComputerUseTool, v)
+	return u
+}
+
+// UpdateAllowComputerUseTool sets the "allow_computer_use_tool" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAllowComputerUseTool() *GroupUpsert {
+	u.SetExcluded(group.FieldAllowComputerUseTool)
+	return u
+}
+
+// SetAllowCodeExecutionTool sets the "allow_code_execution_tool" field.
+func (u *GroupUpsert) SetAllowCodeExecutionTool(v bool) *GroupUpsert {
+	u.Set(group.FieldAllowCodeExecutionTool, v)
+	return u
+}
+
+// UpdateAllowCodeExecutionTool sets the "allow_code_execution_tool" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAllowCodeExecutionTool() *GroupUpsert {
+	u.SetExcluded(group.FieldAllowCodeExecutionTool)
+	return u
+}
+
+// SetAllowWebSearchTool sets the "allow_web_search_tool" field.
+func (u *GroupUpsert) SetAllowWebSearchTool(v bool) *GroupUpsert {
+	u.Set(group.FieldAllowWebSearchTool, v)
+	return u
+}
+
+// UpdateAllowWebSearchTool sets the "allow_web_search_tool" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAllowWebSearchTool() *GroupUpsert {
+	u.SetExcluded(group.FieldAllowWebSearchTool)
+	return u
+}
+
+// SetSystemPromptProfile sets the "system_prompt_profile" field.
+func (u *GroupUpsert) SetSystemPromptProfile(v string) *GroupUpsert {
+	u.Set(group.FieldSystemPromptProfile, v)
+	return u
+}
+
+// UpdateSystemPromptProfile sets the "system_prompt_profile" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSystemPromptProfile() *GroupUpsert {
+	u.SetExcluded(group.FieldSystemPromptProfile)
+	return u
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (u *GroupUpsert) SetResponseLanguage(v string) *GroupUpsert {
+	u.Set(group.FieldResponseLanguage, v)
+	return u
+}
+
+// UpdateResponseLanguage sets the "response_language" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseLanguage() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseLanguage)
+	return u
+}
+
+// SetAttributionMode sets the "attribution_mode" field.
+func (u *GroupUpsert) SetAttributionMode(v string) *GroupUpsert {
+	u.Set(group.FieldAttributionMode, v)
+	return u
+}
+
+// UpdateAttributionMode sets the "attribution_mode" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAttributionMode() *GroupUpsert {
+	u.SetExcluded(group.FieldAttributionMode)
+	return u
+}
+
+// SetAttributionText sets the "attribution_text" field.
+func (u *GroupUpsert) SetAttributionText(v string) *GroupUpsert {
+	u.Set(group.FieldAttributionText, v)
+	return u
+}
+
+// UpdateAttributionText sets the "attribution_text" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAttributionText() *GroupUpsert {
+	u.SetExcluded(group.FieldAttributionText)
+	return u
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (u *GroupUpsert) SetMaxRequestCost(v float64) *GroupUpsert {
+	u.Set(group.FieldMaxRequestCost, v)
+	return u
+}
+
+// UpdateMaxRequestCost sets the "max_request_cost" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateMaxRequestCost() *GroupUpsert {
+	u.SetExcluded(group.FieldMaxRequestCost)
+	return u
+}
+
+// AddMaxRequestCost adds v to the "max_request_cost" field.
+func (u *GroupUpsert) AddMaxRequestCost(v float64) *GroupUpsert {
+	u.Add(group.FieldMaxRequestCost, v)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -3213,6 +3535,125 @@ func (u *GroupUpsertOne) UpdateRpmLimit() *GroupUpsertOne {
 	})
 }
 
+// SetAllowComputerUseTool sets the "allow_computer_use_tool" field.
+func (u *GroupUpsertOne) SetAllowComputerUseTool(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowComputerUseTool(v)
+	})
+}
+
+// UpdateAllowComputerUseTool sets the "allow_computer_use_tool" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAllowComputerUseTool() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowComputerUseTool()
+	})
+}
+
+// SetAllowCodeExecutionTool sets the "allow_code_execution_tool" field.
+func (u *GroupUpsertOne) SetAllowCodeExecutionTool(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowCodeExecutionTool(v)
+	})
+}
+
+// UpdateAllowCodeExecutionTool sets the "allow_code_execution_tool" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAllowCodeExecutionTool() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowCodeExecutionTool()
+	})
+}
+
+// SetAllowWebSearchTool sets the "allow_web_search_tool" field.
+func (u *GroupUpsertOne) SetAllowWebSearchTool(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowWebSearchTool(v)
+	})
+}
+
+// UpdateAllowWebSearchTool sets the "allow_web_search_tool" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAllowWebSearchTool() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowWebSearchTool()
+	})
+}
+
+// SetSystemPromptProfile sets the "system_prompt_profile" field.
+func (u *GroupUpsertOne) SetSystemPromptProfile(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSystemPromptProfile(v)
+	})
+}
+
+// UpdateSystemPromptProfile sets the "system_prompt_profile" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSystemPromptProfile() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSystemPromptProfile()
+	})
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (u *GroupUpsertOne) SetResponseLanguage(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseLanguage(v)
+	})
+}
+
+// UpdateResponseLanguage sets the "response_language" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseLanguage() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseLanguage()
+	})
+}
+
+// SetAttributionMode sets the "attribution_mode" field.
+func (u *GroupUpsertOne) SetAttributionMode(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAttributionMode(v)
+	})
+}
+
+// UpdateAttributionMode sets the "attribution_mode" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAttributionMode() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAttributionMode()
+	})
+}
+
+// SetAttributionText sets the "attribution_text" field.
+func (u *GroupUpsertOne) SetAttributionText(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAttributionText(v)
+	})
+}
+
+// UpdateAttributionText sets the "attribution_text" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAttributionText() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAttributionText()
+	})
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (u *GroupUpsertOne) SetMaxRequestCost(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetMaxRequestCost(v)
+	})
+}
+
+// AddMaxRequestCost adds v to the "max_request_cost" field.
+func (u *GroupUpsertOne) AddMaxRequestCost(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddMaxRequestCost(v)
+	})
+}
+
+// UpdateMaxRequestCost sets the "max_request_cost" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateMaxRequestCost() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateMaxRequestCost()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -4337,6 +4778,125 @@ func (u *GroupUpsertBulk) UpdateRpmLimit() *GroupUpsertBulk {
 	})
 }
 
+// SetAllowComputerUseTool sets the "allow_computer_use_tool" field.
+func (u *GroupUpsertBulk) SetAllowComputerUseTool(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowComputerUseTool(v)
+	})
+}
+
+// UpdateAllowComputerUseTool sets the "allow_computer_use_tool" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAllowComputerUseTool() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowComputerUseTool()
+	})
+}
+
+// SetAllowCodeExecutionTool sets the "allow_code_execution_tool" field.
+func (u *GroupUpsertBulk) SetAllowCodeExecutionTool(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowCodeExecutionTool(v)
+	})
+}
+
+// UpdateAllowCodeExecutionTool sets the "allow_code_execution_tool" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAllowCodeExecutionTool() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowCodeExecutionTool()
+	})
+}
+
+// SetAllowWebSearchTool sets the "allow_web_search_tool" field.
+func (u *GroupUpsertBulk) SetAllowWebSearchTool(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowWebSearchTool(v)
+	})
+}
+
+// UpdateAllowWebSearchTool sets the "allow_web_search_tool" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAllowWebSearchTool() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowWebSearchTool()
+	})
+}
+
+// SetSystemPromptProfile sets the "system_prompt_profile" field.
+func (u *GroupUpsertBulk) SetSystemPromptProfile(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSystemPromptProfile(v)
+	})
+}
+
+// UpdateSystemPromptProfile sets the "system_prompt_profile" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSystemPromptProfile() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSystemPromptProfile()
+	})
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (u *GroupUpsertBulk) SetResponseLanguage(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseLanguage(v)
+	})
+}
+
+// UpdateResponseLanguage sets the "response_language" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseLanguage() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseLanguage()
+	})
+}
+
+// SetAttributionMode sets the "attribution_mode" field.
+func (u *GroupUpsertBulk) SetAttributionMode(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAttributionMode(v)
+	})
+}
+
+// UpdateAttributionMode sets the "attribution_mode" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAttributionMode() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAttributionMode()
+	})
+}
+
+// SetAttributionText sets the "attribution_text" field.
+func (u *GroupUpsertBulk) SetAttributionText(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAttributionText(v)
+	})
+}
+
+// UpdateAttributionText sets the "attribution_text" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAttributionText() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAttributionText()
+	})
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (u *GroupUpsertBulk) SetMaxRequestCost(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetMaxRequestCost(v)
+	})
+}
+
+// AddMaxRequestCost adds v to the "max_request_cost" field.
+func (u *GroupUpsertBulk) AddMaxRequestCost(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddMaxRequestCost(v)
+	})
+}
+
+// UpdateMaxRequestCost sets the "max_request_cost" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateMaxRequestCost() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateMaxRequestCost()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
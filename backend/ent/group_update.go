@@ -913,6 +913,125 @@ func (_u *GroupUpdate) AddRpmLimit(v int) *GroupUpdate {
 	return _u
 }
 
+// SetAllowComputerUseTool sets the "allow_computer_use_tool" field.
+func (_u *GroupUpdate) SetAllowComputerUseTool(v bool) *GroupUpdate {
+	_u.mutation.SetAllowComputerUseTool(v)
+	return _u
+}
+
+// SetNillableAllowComputerUseTool sets the "allow_computer_use_tool" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAllowComputerUseTool(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetAllowComputerUseTool(*v)
+	}
+	return _u
+}
+
+// SetAllowCodeExecutionTool sets the "allow_code_execution_tool" field.
+func (_u *GroupUpdate) SetAllowCodeExecutionTool(v bool) *GroupUpdate {
+	_u.mutation.SetAllowCodeExecutionTool(v)
+	return _u
+}
+
+// SetNillableAllowCodeExecutionTool sets the "allow_code_execution_tool" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAllowCodeExecutionTool(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetAllowCodeExecutionTool(*v)
+	}
+	return _u
+}
+
+// SetAllowWebSearchTool sets the "allow_web_search_tool" field.
+func (_u *GroupUpdate) SetAllowWebSearchTool(v bool) *GroupUpdate {
+	_u.mutation.SetAllowWebSearchTool(v)
+	return _u
+}
+
+// SetNillableAllowWebSearchTool sets the "allow_web_search_tool" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAllowWebSearchTool(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetAllowWebSearchTool(*v)
+	}
+	return _u
+}
+
+// SetSystemPromptProfile sets the "system_prompt_profile" field.
+func (_u *GroupUpdate) SetSystemPromptProfile(v string) *GroupUpdate {
+	_u.mutation.SetSystemPromptProfile(v)
+	return _u
+}
+
+// SetNillableSystemPromptProfile sets the "system_prompt_profile" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSystemPromptProfile(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetSystemPromptProfile(*v)
+	}
+	return _u
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (_u *GroupUpdate) SetResponseLanguage(v string) *GroupUpdate {
+	_u.mutation.SetResponseLanguage(v)
+	return _u
+}
+
+// SetNillableResponseLanguage sets the "response_language" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseLanguage(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseLanguage(*v)
+	}
+	return _u
+}
+
+// SetAttributionMode sets the "attribution_mode" field.
+func (_u *GroupUpdate) SetAttributionMode(v string) *GroupUpdate {
+	_u.mutation.SetAttributionMode(v)
+	return _u
+}
+
+// SetNillableAttributionMode sets the "attribution_mode" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAttributionMode(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetAttributionMode(*v)
+	}
+	return _u
+}
+
+// SetAttributionText sets the "attribution_text" field.
+func (_u *GroupUpdate) SetAttributionText(v string) *GroupUpdate {
+	_u.mutation.SetAttributionText(v)
+	return _u
+}
+
+// SetNillableAttributionText sets the "attribution_text" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAttributionText(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetAttributionText(*v)
+	}
+	return _u
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (_u *GroupUpdate) SetMaxRequestCost(v float64) *GroupUpdate {
+	_u.mutation.ResetMaxRequestCost()
+	_u.mutation.SetMaxRequestCost(v)
+	return _u
+}
+
+// SetNillableMaxRequestCost sets the "max_request_cost" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableMaxRequestCost(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetMaxRequestCost(*v)
+	}
+	return _u
+}
+
+// AddMaxRequestCost adds value to the "max_request_cost" field.
+func (_u *GroupUpdate) AddMaxRequestCost(v float64) *GroupUpdate {
+	_u.mutation.AddMaxRequestCost(v)
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdate) AddAPIKeyIDs(ids ...int64) *GroupUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -1213,6 +1332,26 @@ func (_u *GroupUpdate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SystemPromptProfile(); ok {
+		if err := group.SystemPromptProfileValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_profile", err: fmt.Errorf(`ent: validator failed for field "Group.system_prompt_profile": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.ResponseLanguage(); ok {
+		if err := group.ResponseLanguageValidator(v); err != nil {
+			return &ValidationError{Name: "response_language", err: fmt.Errorf(`ent: validator failed for field "Group.response_language": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.AttributionMode(); ok {
+		if err := group.AttributionModeValidator(v); err != nil {
+			return &ValidationError{Name: "attribution_mode", err: fmt.Errorf(`ent: validator failed for field "Group.attribution_mode": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.AttributionText(); ok {
+		if err := group.AttributionTextValidator(v); err != nil {
+			return &ValidationError{Name: "attribution_text", err: fmt.Errorf(`ent: validator failed for field "Group.attribution_text": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1485,6 +1624,33 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.AllowComputerUseTool(); ok {
+		_spec.SetField(group.FieldAllowComputerUseTool, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.AllowCodeExecutionTool(); ok {
+		_spec.SetField(group.FieldAllowCodeExecutionTool, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.AllowWebSearchTool(); ok {
+		_spec.SetField(group.FieldAllowWebSearchTool, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.SystemPromptProfile(); ok {
+		_spec.SetField(group.FieldSystemPromptProfile, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ResponseLanguage(); ok {
+		_spec.SetField(group.FieldResponseLanguage, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.AttributionMode(); ok {
+		_spec.SetField(group.FieldAttributionMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.AttributionText(); ok {
+		_spec.SetField(group.FieldAttributionText, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.MaxRequestCost(); ok {
+		_spec.SetField(group.FieldMaxRequestCost, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxRequestCost(); ok {
+		_spec.AddField(group.FieldMaxRequestCost, field.TypeFloat64, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -2676,6 +2842,125 @@ func (_u *GroupUpdateOne) AddRpmLimit(v int) *GroupUpdateOne {
 	return _u
 }
 
+// SetAllowComputerUseTool sets the "allow_computer_use_tool" field.
+func (_u *GroupUpdateOne) SetAllowComputerUseTool(v bool) *GroupUpdateOne {
+	_u.mutation.SetAllowComputerUseTool(v)
+	return _u
+}
+
+// SetNillableAllowComputerUseTool sets the "allow_computer_use_tool" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAllowComputerUseTool(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAllowComputerUseTool(*v)
+	}
+	return _u
+}
+
+// SetAllowCodeExecutionTool sets the "allow_code_execution_tool" field.
+func (_u *GroupUpdateOne) SetAllowCodeExecutionTool(v bool) *GroupUpdateOne {
+	_u.mutation.SetAllowCodeExecutionTool(v)
+	return _u
+}
+
+// SetNillableAllowCodeExecutionTool sets the "allow_code_execution_tool" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAllowCodeExecutionTool(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAllowCodeExecutionTool(*v)
+	}
+	return _u
+}
+
+// SetAllowWebSearchTool sets the "allow_web_search_tool" field.
+func (_u *GroupUpdateOne) SetAllowWebSearchTool(v bool) *GroupUpdateOne {
+	_u.mutation.SetAllowWebSearchTool(v)
+	return _u
+}
+
+// SetNillableAllowWebSearchTool sets the "allow_web_search_tool" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAllowWebSearchTool(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAllowWebSearchTool(*v)
+	}
+	return _u
+}
+
+// SetSystemPromptProfile sets the "system_prompt_profile" field.
+func (_u *GroupUpdateOne) SetSystemPromptProfile(v string) *GroupUpdateOne {
+	_u.mutation.SetSystemPromptProfile(v)
+	return _u
+}
+
+// SetNillableSystemPromptProfile sets the "system_prompt_profile" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSystemPromptProfile(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSystemPromptProfile(*v)
+	}
+	return _u
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (_u *GroupUpdateOne) SetResponseLanguage(v string) *GroupUpdateOne {
+	_u.mutation.SetResponseLanguage(v)
+	return _u
+}
+
+// SetNillableResponseLanguage sets the "response_language" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseLanguage(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseLanguage(*v)
+	}
+	return _u
+}
+
+// SetAttributionMode sets the "attribution_mode" field.
+func (_u *GroupUpdateOne) SetAttributionMode(v string) *GroupUpdateOne {
+	_u.mutation.SetAttributionMode(v)
+	return _u
+}
+
+// SetNillableAttributionMode sets the "attribution_mode" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAttributionMode(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAttributionMode(*v)
+	}
+	return _u
+}
+
+// SetAttributionText sets the "attribution_text" field.
+func (_u *GroupUpdateOne) SetAttributionText(v string) *GroupUpdateOne {
+	_u.mutation.SetAttributionText(v)
+	return _u
+}
+
+// SetNillableAttributionText sets the "attribution_text" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAttributionText(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAttributionText(*v)
+	}
+	return _u
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (_u *GroupUpdateOne) SetMaxRequestCost(v float64) *GroupUpdateOne {
+	_u.mutation.ResetMaxRequestCost()
+	_u.mutation.SetMaxRequestCost(v)
+	return _u
+}
+
+// SetNillableMaxRequestCost sets the "max_request_cost" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableMaxRequestCost(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetMaxRequestCost(*v)
+	}
+	return _u
+}
+
+// AddMaxRequestCost adds value to the "max_request_cost" field.
+func (_u *GroupUpdateOne) AddMaxRequestCost(v float64) *GroupUpdateOne {
+	_u.mutation.AddMaxRequestCost(v)
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdateOne) AddAPIKeyIDs(ids ...int64) *GroupUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -2989,6 +3274,26 @@ func (_u *GroupUpdateOne) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SystemPromptProfile(); ok {
+		if err := group.SystemPromptProfileValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_profile", err: fmt.Errorf(`ent: validator failed for field "Group.system_prompt_profile": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.ResponseLanguage(); ok {
+		if err := group.ResponseLanguageValidator(v); err != nil {
+			return &ValidationError{Name: "response_language", err: fmt.Errorf(`ent: validator failed for field "Group.response_language": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.AttributionMode(); ok {
+		if err := group.AttributionModeValidator(v); err != nil {
+			return &ValidationError{Name: "attribution_mode", err: fmt.Errorf(`ent: validator failed for field "Group.attribution_mode": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.AttributionText(); ok {
+		if err := group.AttributionTextValidator(v); err != nil {
+			return &ValidationError{Name: "attribution_text", err: fmt.Errorf(`ent: validator failed for field "Group.attribution_text": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -3278,6 +3583,33 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.AllowComputerUseTool(); ok {
+		_spec.SetField(group.FieldAllowComputerUseTool, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.AllowCodeExecutionTool(); ok {
+		_spec.SetField(group.FieldAllowCodeExecutionTool, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.AllowWebSearchTool(); ok {
+		_spec.SetField(group.FieldAllowWebSearchTool, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.SystemPromptProfile(); ok {
+		_spec.SetField(group.FieldSystemPromptProfile, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ResponseLanguage(); ok {
+		_spec.SetField(group.FieldResponseLanguage, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.AttributionMode(); ok {
+		_spec.SetField(group.FieldAttributionMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.AttributionText(); ok {
+		_spec.SetField(group.FieldAttributionText, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.MaxRequestCost(); ok {
+		_spec.SetField(group.FieldMaxRequestCost, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxRequestCost(); ok {
+		_spec.AddField(group.FieldMaxRequestCost, field.TypeFloat64, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -4,7 +4,7 @@ package ent
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/json/jsontext"
 	"errors"
 	"fmt"
 	"sync"
@@ -106,51 +106,66 @@ const (
 // APIKeyMutation represents an operation that mutates the APIKey nodes in the graph.
 type APIKeyMutation struct {
 	config
-	op                 Op
-	typ                string
-	id                 *int64
-	created_at         *time.Time
-	updated_at         *time.Time
-	deleted_at         *time.Time
-	key                *string
-	name               *string
-	status             *string
-	last_used_at       *time.Time
-	ip_whitelist       *[]string
-	appendip_whitelist []string
-	ip_blacklist       *[]string
-	appendip_blacklist []string
-	quota              *float64
-	addquota           *float64
-	quota_used         *float64
-	addquota_used      *float64
-	expires_at         *time.Time
-	rate_limit_5h      *float64
-	addrate_limit_5h   *float64
-	rate_limit_1d      *float64
-	addrate_limit_1d   *float64
-	rate_limit_7d      *float64
-	addrate_limit_7d   *float64
-	usage_5h           *float64
-	addusage_5h        *float64
-	usage_1d           *float64
-	addusage_1d        *float64
-	usage_7d           *float64
-	addusage_7d        *float64
-	window_5h_start    *time.Time
-	window_1d_start    *time.Time
-	window_7d_start    *time.Time
-	clearedFields      map[string]struct{}
-	user               *int64
-	cleareduser        bool
-	group              *int64
-	clearedgroup       bool
-	usage_logs         map[int64]struct{}
-	removedusage_logs  map[int64]struct{}
-	clearedusage_logs  bool
-	done               bool
-	oldValue           func(context.Context) (*APIKey, error)
-	predicates         []predicate.APIKey
+	op                     Op
+	typ                    string
+	id                     *int64
+	created_at             *time.Time
+	updated_at             *time.Time
+	deleted_at             *time.Time
+	key                    *string
+	name                   *string
+	status                 *string
+	last_used_at           *time.Time
+	ip_whitelist           *[]string
+	appendip_whitelist     []string
+	ip_blacklist           *[]string
+	appendip_blacklist     []string
+	quota                  *float64
+	addquota               *float64
+	quota_used             *float64
+	addquota_used          *float64
+	expires_at             *time.Time
+	rate_limit_5h          *float64
+	addrate_limit_5h       *float64
+	rate_limit_1d          *float64
+	addrate_limit_1d       *float64
+	rate_limit_7d          *float64
+	addrate_limit_7d       *float64
+	usage_5h               *float64
+	addusage_5h            *float64
+	usage_1d               *float64
+	addusage_1d            *float64
+	usage_7d               *float64
+	addusage_7d            *float64
+	window_5h_start        *time.Time
+	window_1d_start        *time.Time
+	window_7d_start        *time.Time
+	transcript_enabled     *bool
+	annotations_enabled    *bool
+	stream_capture_enabled *bool
+	response_language      *string
+	parent_key_id          *int64
+	addparent_key_id       *int64
+	allowed_models         *[]string
+	appendallowed_models   []string
+	max_priority           *string
+	strict_validation      *bool
+	max_request_cost       *float64
+	addmax_request_cost    *float64
+	beta_features          *[]string
+	appendbeta_features    []string
+	data_residency         *string
+	clearedFields          map[string]struct{}
+	user                   *int64
+	cleareduser            bool
+	group                  *int64
+	clearedgroup           bool
+	usage_logs             map[int64]struct{}
+	removedusage_logs      map[int64]struct{}
+	clearedusage_logs      bool
+	done                   bool
+	oldValue               func(context.Context) (*APIKey, error)
+	predicates             []predicate.APIKey
 }
 
 var _ ent.Mutation = (*APIKeyMutation)(nil)
@@ -1388,6 +1403,514 @@ func (m *APIKeyMutation) ResetWindow7dStart() {
 	delete(m.clearedFields, apikey.FieldWindow7dStart)
 }
 
+// SetTranscriptEnabled sets the "transcript_enabled" field.
+func (m *APIKeyMutation) SetTranscriptEnabled(b bool) {
+	m.transcript_enabled = &b
+}
+
+// TranscriptEnabled returns the value of the "transcript_enabled" field in the mutation.
+func (m *APIKeyMutation) TranscriptEnabled() (r bool, exists bool) {
+	v := m.transcript_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTranscriptEnabled returns the old "transcript_enabled" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldTranscriptEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTranscriptEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTranscriptEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTranscriptEnabled: %w", err)
+	}
+	return oldValue.TranscriptEnabled, nil
+}
+
+// ResetTranscriptEnabled resets all changes to the "transcript_enabled" field.
+func (m *APIKeyMutation) ResetTranscriptEnabled() {
+	m.transcript_enabled = nil
+}
+
+// SetAnnotationsEnabled sets the "annotations_enabled" field.
+func (m *APIKeyMutation) SetAnnotationsEnabled(b bool) {
+	m.annotations_enabled = &b
+}
+
+// AnnotationsEnabled returns the value of the "annotations_enabled" field in the mutation.
+func (m *APIKeyMutation) AnnotationsEnabled() (r bool, exists bool) {
+	v := m.annotations_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAnnotationsEnabled returns the old "annotations_enabled" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldAnnotationsEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAnnotationsEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAnnotationsEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAnnotationsEnabled: %w", err)
+	}
+	return oldValue.AnnotationsEnabled, nil
+}
+
+// ResetAnnotationsEnabled resets all changes to the "annotations_enabled" field.
+func (m *APIKeyMutation) ResetAnnotationsEnabled() {
+	m.annotations_enabled = nil
+}
+
+// SetStreamCaptureEnabled sets the "stream_capture_enabled" field.
+func (m *APIKeyMutation) SetStreamCaptureEnabled(b bool) {
+	m.stream_capture_enabled = &b
+}
+
+// StreamCaptureEnabled returns the value of the "stream_capture_enabled" field in the mutation.
+func (m *APIKeyMutation) StreamCaptureEnabled() (r bool, exists bool) {
+	v := m.stream_capture_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStreamCaptureEnabled returns the old "stream_capture_enabled" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldStreamCaptureEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStreamCaptureEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStreamCaptureEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStreamCaptureEnabled: %w", err)
+	}
+	return oldValue.StreamCaptureEnabled, nil
+}
+
+// ResetStreamCaptureEnabled resets all changes to the "stream_capture_enabled" field.
+func (m *APIKeyMutation) ResetStreamCaptureEnabled() {
+	m.stream_capture_enabled = nil
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (m *APIKeyMutation) SetResponseLanguage(s string) {
+	m.response_language = &s
+}
+
+// ResponseLanguage returns the value of the "response_language" field in the mutation.
+func (m *APIKeyMutation) ResponseLanguage() (r string, exists bool) {
+	v := m.response_language
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseLanguage returns the old "response_language" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldResponseLanguage(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseLanguage is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseLanguage requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseLanguage: %w", err)
+	}
+	return oldValue.ResponseLanguage, nil
+}
+
+// ResetResponseLanguage resets all changes to the "response_language" field.
+func (m *APIKeyMutation) ResetResponseLanguage() {
+	m.response_language = nil
+}
+
+// SetParentKeyID sets the "parent_key_id" field.
+func (m *APIKeyMutation) SetParentKeyID(i int64) {
+	m.parent_key_id = &i
+	m.addparent_key_id = nil
+}
+
+// ParentKeyID returns the value of the "parent_key_id" field in the mutation.
+func (m *APIKeyMutation) ParentKeyID() (r int64, exists bool) {
+	v := m.parent_key_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldParentKeyID returns the old "parent_key_id" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldParentKeyID(ctx context.Context) (v *int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldParentKeyID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldParentKeyID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldParentKeyID: %w", err)
+	}
+	return oldValue.ParentKeyID, nil
+}
+
+// AddParentKeyID adds i to the "parent_key_id" field.
+func (m *APIKeyMutation) AddParentKeyID(i int64) {
+	if m.addparent_key_id != nil {
+		*m.addparent_key_id += i
+	} else {
+		m.addparent_key_id = &i
+	}
+}
+
+// AddedParentKeyID returns the value that was added to the "parent_key_id" field in this mutation.
+func (m *APIKeyMutation) AddedParentKeyID() (r int64, exists bool) {
+	v := m.addparent_key_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearParentKeyID clears the value of the "parent_key_id" field.
+func (m *APIKeyMutation) ClearParentKeyID() {
+	m.parent_key_id = nil
+	m.addparent_key_id = nil
+	m.clearedFields[apikey.FieldParentKeyID] = struct{}{}
+}
+
+// ParentKeyIDCleared returns if the "parent_key_id" field was cleared in this mutation.
+func (m *APIKeyMutation) ParentKeyIDCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldParentKeyID]
+	return ok
+}
+
+// ResetParentKeyID resets all changes to the "parent_key_id" field.
+func (m *APIKeyMutation) ResetParentKeyID() {
+	m.parent_key_id = nil
+	m.addparent_key_id = nil
+	delete(m.clearedFields, apikey.FieldParentKeyID)
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (m *APIKeyMutation) SetAllowedModels(s []string) {
+	m.allowed_models = &s
+	m.appendallowed_models = nil
+}
+
+// AllowedModels returns the value of the "allowed_models" field in the mutation.
+func (m *APIKeyMutation) AllowedModels() (r []string, exists bool) {
+	v := m.allowed_models
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowedModels returns the old "allowed_models" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldAllowedModels(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedModels is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedModels requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedModels: %w", err)
+	}
+	return oldValue.AllowedModels, nil
+}
+
+// AppendAllowedModels adds s to the "allowed_models" field.
+func (m *APIKeyMutation) AppendAllowedModels(s []string) {
+	m.appendallowed_models = append(m.appendallowed_models, s...)
+}
+
+// AppendedAllowedModels returns the list of values that were appended to the "allowed_models" field in this mutation.
+func (m *APIKeyMutation) AppendedAllowedModels() ([]string, bool) {
+	if len(m.appendallowed_models) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_models, true
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (m *APIKeyMutation) ClearAllowedModels() {
+	m.allowed_models = nil
+	m.appendallowed_models = nil
+	m.clearedFields[apikey.FieldAllowedModels] = struct{}{}
+}
+
+// AllowedModelsCleared returns if the "allowed_models" field was cleared in this mutation.
+func (m *APIKeyMutation) AllowedModelsCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldAllowedModels]
+	return ok
+}
+
+// ResetAllowedModels resets all changes to the "allowed_models" field.
+func (m *APIKeyMutation) ResetAllowedModels() {
+	m.allowed_models = nil
+	m.appendallowed_models = nil
+	delete(m.clearedFields, apikey.FieldAllowedModels)
+}
+
+// SetMaxPriority sets the "max_priority" field.
+func (m *APIKeyMutation) SetMaxPriority(s string) {
+	m.max_priority = &s
+}
+
+// MaxPriority returns the value of the "max_priority" field in the mutation.
+func (m *APIKeyMutation) MaxPriority() (r string, exists bool) {
+	v := m.max_priority
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxPriority returns the old "max_priority" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldMaxPriority(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxPriority is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxPriority requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxPriority: %w", err)
+	}
+	return oldValue.MaxPriority, nil
+}
+
+// ResetMaxPriority resets all changes to the "max_priority" field.
+func (m *APIKeyMutation) ResetMaxPriority() {
+	m.max_priority = nil
+}
+
+// SetStrictValidation sets the "strict_validation" field.
+func (m *APIKeyMutation) SetStrictValidation(b bool) {
+	m.strict_validation = &b
+}
+
+// StrictValidation returns the value of the "strict_validation" field in the mutation.
+func (m *APIKeyMutation) StrictValidation() (r bool, exists bool) {
+	v := m.strict_validation
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStrictValidation returns the old "strict_validation" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldStrictValidation(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStrictValidation is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStrictValidation requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStrictValidation: %w", err)
+	}
+	return oldValue.StrictValidation, nil
+}
+
+// ResetStrictValidation resets all changes to the "strict_validation" field.
+func (m *APIKeyMutation) ResetStrictValidation() {
+	m.strict_validation = nil
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (m *APIKeyMutation) SetMaxRequestCost(f float64) {
+	m.max_request_cost = &f
+	m.addmax_request_cost = nil
+}
+
+// MaxRequestCost returns the value of the "max_request_cost" field in the mutation.
+func (m *APIKeyMutation) MaxRequestCost() (r float64, exists bool) {
+	v := m.max_request_cost
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxRequestCost returns the old "max_request_cost" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldMaxRequestCost(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxRequestCost is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxRequestCost requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxRequestCost: %w", err)
+	}
+	return oldValue.MaxRequestCost, nil
+}
+
+// AddMaxRequestCost adds f to the "max_request_cost" field.
+func (m *APIKeyMutation) AddMaxRequestCost(f float64) {
+	if m.addmax_request_cost != nil {
+		*m.addmax_request_cost += f
+	} else {
+		m.addmax_request_cost = &f
+	}
+}
+
+// AddedMaxRequestCost returns the value that was added to the "max_request_cost" field in this mutation.
+func (m *APIKeyMutation) AddedMaxRequestCost() (r float64, exists bool) {
+	v := m.addmax_request_cost
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMaxRequestCost resets all changes to the "max_request_cost" field.
+func (m *APIKeyMutation) ResetMaxRequestCost() {
+	m.max_request_cost = nil
+	m.addmax_request_cost = nil
+}
+
+// SetBetaFeatures sets the "beta_features" field.
+func (m *APIKeyMutation) SetBetaFeatures(s []string) {
+	m.beta_features = &s
+	m.appendbeta_features = nil
+}
+
+// BetaFeatures returns the value of the "beta_features" field in the mutation.
+func (m *APIKeyMutation) BetaFeatures() (r []string, exists bool) {
+	v := m.beta_features
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBetaFeatures returns the old "beta_features" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldBetaFeatures(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBetaFeatures is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBetaFeatures requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBetaFeatures: %w", err)
+	}
+	return oldValue.BetaFeatures, nil
+}
+
+// AppendBetaFeatures adds s to the "beta_features" field.
+func (m *APIKeyMutation) AppendBetaFeatures(s []string) {
+	m.appendbeta_features = append(m.appendbeta_features, s...)
+}
+
+// AppendedBetaFeatures returns the list of values that were appended to the "beta_features" field in this mutation.
+func (m *APIKeyMutation) AppendedBetaFeatures() ([]string, bool) {
+	if len(m.appendbeta_features) == 0 {
+		return nil, false
+	}
+	return m.appendbeta_features, true
+}
+
+// ClearBetaFeatures clears the value of the "beta_features" field.
+func (m *APIKeyMutation) ClearBetaFeatures() {
+	m.beta_features = nil
+	m.appendbeta_features = nil
+	m.clearedFields[apikey.FieldBetaFeatures] = struct{}{}
+}
+
+// BetaFeaturesCleared returns if the "beta_features" field was cleared in this mutation.
+func (m *APIKeyMutation) BetaFeaturesCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldBetaFeatures]
+	return ok
+}
+
+// ResetBetaFeatures resets all changes to the "beta_features" field.
+func (m *APIKeyMutation) ResetBetaFeatures() {
+	m.beta_features = nil
+	m.appendbeta_features = nil
+	delete(m.clearedFields, apikey.FieldBetaFeatures)
+}
+
+// SetDataResidency sets the "data_residency" field.
+func (m *APIKeyMutation) SetDataResidency(s string) {
+	m.data_residency = &s
+}
+
+// DataResidency returns the value of the "data_residency" field in the mutation.
+func (m *APIKeyMutation) DataResidency() (r string, exists bool) {
+	v := m.data_residency
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDataResidency returns the old "data_residency" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldDataResidency(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDataResidency is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDataResidency requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDataResidency: %w", err)
+	}
+	return oldValue.DataResidency, nil
+}
+
+// ResetDataResidency resets all changes to the "data_residency" field.
+func (m *APIKeyMutation) ResetDataResidency() {
+	m.data_residency = nil
+}
+
 // ClearUser clears the "user" edge to the User entity.
 func (m *APIKeyMutation) ClearUser() {
 	m.cleareduser = true
@@ -1530,7 +2053,7 @@ func (m *APIKeyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *APIKeyMutation) Fields() []string {
-	fields := make([]string, 0, 23)
+	fields := make([]string, 0, 34)
 	if m.created_at != nil {
 		fields = append(fields, apikey.FieldCreatedAt)
 	}
@@ -1600,6 +2123,39 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.window_7d_start != nil {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.transcript_enabled != nil {
+		fields = append(fields, apikey.FieldTranscriptEnabled)
+	}
+	if m.annotations_enabled != nil {
+		fields = append(fields, apikey.FieldAnnotationsEnabled)
+	}
+	if m.stream_capture_enabled != nil {
+		fields = append(fields, apikey.FieldStreamCaptureEnabled)
+	}
+	if m.response_language != nil {
+		fields = append(fields, apikey.FieldResponseLanguage)
+	}
+	if m.parent_key_id != nil {
+		fields = append(fields, apikey.FieldParentKeyID)
+	}
+	if m.allowed_models != nil {
+		fields = append(fields, apikey.FieldAllowedModels)
+	}
+	if m.max_priority != nil {
+		fields = append(fields, apikey.FieldMaxPriority)
+	}
+	if m.strict_validation != nil {
+		fields = append(fields, apikey.FieldStrictValidation)
+	}
+	if m.max_request_cost != nil {
+		fields = append(fields, apikey.FieldMaxRequestCost)
+	}
+	if m.beta_features != nil {
+		fields = append(fields, apikey.FieldBetaFeatures)
+	}
+	if m.data_residency != nil {
+		fields = append(fields, apikey.FieldDataResidency)
+	}
 	return fields
 }
 
@@ -1654,6 +2210,28 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.Window1dStart()
 	case apikey.FieldWindow7dStart:
 		return m.Window7dStart()
+	case apikey.FieldTranscriptEnabled:
+		return m.TranscriptEnabled()
+	case apikey.FieldAnnotationsEnabled:
+		return m.AnnotationsEnabled()
+	case apikey.FieldStreamCaptureEnabled:
+		return m.StreamCaptureEnabled()
+	case apikey.FieldResponseLanguage:
+		return m.ResponseLanguage()
+	case apikey.FieldParentKeyID:
+		return m.ParentKeyID()
+	case apikey.FieldAllowedModels:
+		return m.AllowedModels()
+	case apikey.FieldMaxPriority:
+		return m.MaxPriority()
+	case apikey.FieldStrictValidation:
+		return m.StrictValidation()
+	case apikey.FieldMaxRequestCost:
+		return m.MaxRequestCost()
+	case apikey.FieldBetaFeatures:
+		return m.BetaFeatures()
+	case apikey.FieldDataResidency:
+		return m.DataResidency()
 	}
 	return nil, false
 }
@@ -1709,6 +2287,28 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldWindow1dStart(ctx)
 	case apikey.FieldWindow7dStart:
 		return m.OldWindow7dStart(ctx)
+	case apikey.FieldTranscriptEnabled:
+		return m.OldTranscriptEnabled(ctx)
+	case apikey.FieldAnnotationsEnabled:
+		return m.OldAnnotationsEnabled(ctx)
+	case apikey.FieldStreamCaptureEnabled:
+		return m.OldStreamCaptureEnabled(ctx)
+	case apikey.FieldResponseLanguage:
+		return m.OldResponseLanguage(ctx)
+	case apikey.FieldParentKeyID:
+		return m.OldParentKeyID(ctx)
+	case apikey.FieldAllowedModels:
+		return m.OldAllowedModels(ctx)
+	case apikey.FieldMaxPriority:
+		return m.OldMaxPriority(ctx)
+	case apikey.FieldStrictValidation:
+		return m.OldStrictValidation(ctx)
+	case apikey.FieldMaxRequestCost:
+		return m.OldMaxRequestCost(ctx)
+	case apikey.FieldBetaFeatures:
+		return m.OldBetaFeatures(ctx)
+	case apikey.FieldDataResidency:
+		return m.OldDataResidency(ctx)
 	}
 	return nil, fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1879,6 +2479,83 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetWindow7dStart(v)
 		return nil
+	case apikey.FieldTranscriptEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTranscriptEnabled(v)
+		return nil
+	case apikey.FieldAnnotationsEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAnnotationsEnabled(v)
+		return nil
+	case apikey.FieldStreamCaptureEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStreamCaptureEnabled(v)
+		return nil
+	case apikey.FieldResponseLanguage:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseLanguage(v)
+		return nil
+	case apikey.FieldParentKeyID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetParentKeyID(v)
+		return nil
+	case apikey.FieldAllowedModels:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedModels(v)
+		return nil
+	case apikey.FieldMaxPriority:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxPriority(v)
+		return nil
+	case apikey.FieldStrictValidation:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStrictValidation(v)
+		return nil
+	case apikey.FieldMaxRequestCost:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxRequestCost(v)
+		return nil
+	case apikey.FieldBetaFeatures:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBetaFeatures(v)
+		return nil
+	case apikey.FieldDataResidency:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDataResidency(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1911,6 +2588,12 @@ func (m *APIKeyMutation) AddedFields() []string {
 	if m.addusage_7d != nil {
 		fields = append(fields, apikey.FieldUsage7d)
 	}
+	if m.addparent_key_id != nil {
+		fields = append(fields, apikey.FieldParentKeyID)
+	}
+	if m.addmax_request_cost != nil {
+		fields = append(fields, apikey.FieldMaxRequestCost)
+	}
 	return fields
 }
 
@@ -1935,6 +2618,10 @@ func (m *APIKeyMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedUsage1d()
 	case apikey.FieldUsage7d:
 		return m.AddedUsage7d()
+	case apikey.FieldParentKeyID:
+		return m.AddedParentKeyID()
+	case apikey.FieldMaxRequestCost:
+		return m.AddedMaxRequestCost()
 	}
 	return nil, false
 }
@@ -2000,6 +2687,20 @@ func (m *APIKeyMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddUsage7d(v)
 		return nil
+	case apikey.FieldParentKeyID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddParentKeyID(v)
+		return nil
+	case apikey.FieldMaxRequestCost:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMaxRequestCost(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey numeric field %s", name)
 }
@@ -2035,6 +2736,15 @@ func (m *APIKeyMutation) ClearedFields() []string {
 	if m.FieldCleared(apikey.FieldWindow7dStart) {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.FieldCleared(apikey.FieldParentKeyID) {
+		fields = append(fields, apikey.FieldParentKeyID)
+	}
+	if m.FieldCleared(apikey.FieldAllowedModels) {
+		fields = append(fields, apikey.FieldAllowedModels)
+	}
+	if m.FieldCleared(apikey.FieldBetaFeatures) {
+		fields = append(fields, apikey.FieldBetaFeatures)
+	}
 	return fields
 }
 
@@ -2076,6 +2786,15 @@ func (m *APIKeyMutation) ClearField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ClearWindow7dStart()
 		return nil
+	case apikey.FieldParentKeyID:
+		m.ClearParentKeyID()
+		return nil
+	case apikey.FieldAllowedModels:
+		m.ClearAllowedModels()
+		return nil
+	case apikey.FieldBetaFeatures:
+		m.ClearBetaFeatures()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey nullable field %s", name)
 }
@@ -2153,6 +2872,39 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ResetWindow7dStart()
 		return nil
+	case apikey.FieldTranscriptEnabled:
+		m.ResetTranscriptEnabled()
+		return nil
+	case apikey.FieldAnnotationsEnabled:
+		m.ResetAnnotationsEnabled()
+		return nil
+	case apikey.FieldStreamCaptureEnabled:
+		m.ResetStreamCaptureEnabled()
+		return nil
+	case apikey.FieldResponseLanguage:
+		m.ResetResponseLanguage()
+		return nil
+	case apikey.FieldParentKeyID:
+		m.ResetParentKeyID()
+		return nil
+	case apikey.FieldAllowedModels:
+		m.ResetAllowedModels()
+		return nil
+	case apikey.FieldMaxPriority:
+		m.ResetMaxPriority()
+		return nil
+	case apikey.FieldStrictValidation:
+		m.ResetStrictValidation()
+		return nil
+	case apikey.FieldMaxRequestCost:
+		m.ResetMaxRequestCost()
+		return nil
+	case apikey.FieldBetaFeatures:
+		m.ResetBetaFeatures()
+		return nil
+	case apikey.FieldDataResidency:
+		m.ResetDataResidency()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -5086,19 +5838,25 @@ func (m *AccountMutation) ResetEdge(name string) error {
 // AccountGroupMutation represents an operation that mutates the AccountGroup nodes in the graph.
 type AccountGroupMutation struct {
 	config
-	op             Op
-	typ            string
-	priority       *int
-	addpriority    *int
-	created_at     *time.Time
-	clearedFields  map[string]struct{}
-	account        *int64
-	clearedaccount bool
-	group          *int64
-	clearedgroup   bool
-	done           bool
-	oldValue       func(context.Context) (*AccountGroup, error)
-	predicates     []predicate.AccountGroup
+	op                   Op
+	typ                  string
+	priority             *int
+	addpriority          *int
+	priority_override    *int
+	addpriority_override *int
+	weight               *int
+	addweight            *int
+	concurrency_cap      *int
+	addconcurrency_cap   *int
+	created_at           *time.Time
+	clearedFields        map[string]struct{}
+	account              *int64
+	clearedaccount       bool
+	group                *int64
+	clearedgroup         bool
+	done                 bool
+	oldValue             func(context.Context) (*AccountGroup, error)
+	predicates           []predicate.AccountGroup
 }
 
 var _ ent.Mutation = (*AccountGroupMutation)(nil)
@@ -5216,6 +5974,151 @@ func (m *AccountGroupMutation) ResetPriority() {
 	m.addpriority = nil
 }
 
+// SetPriorityOverride sets the "priority_override" field.
+func (m *AccountGroupMutation) SetPriorityOverride(i int) {
+	m.priority_override = &i
+	m.addpriority_override = nil
+}
+
+// PriorityOverride returns the value of the "priority_override" field in the mutation.
+func (m *AccountGroupMutation) PriorityOverride() (r int, exists bool) {
+	v := m.priority_override
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// AddPriorityOverride adds i to the "priority_override" field.
+func (m *AccountGroupMutation) AddPriorityOverride(i int) {
+	if m.addpriority_override != nil {
+		*m.addpriority_override += i
+	} else {
+		m.addpriority_override = &i
+	}
+}
+
+// AddedPriorityOverride returns the value that was added to the "priority_override" field in this mutation.
+func (m *AccountGroupMutation) AddedPriorityOverride() (r int, exists bool) {
+	v := m.addpriority_override
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearPriorityOverride clears the value of the "priority_override" field.
+func (m *AccountGroupMutation) ClearPriorityOverride() {
+	m.priority_override = nil
+	m.addpriority_override = nil
+	m.clearedFields[accountgroup.FieldPriorityOverride] = struct{}{}
+}
+
+// PriorityOverrideCleared returns if the "priority_override" field was cleared in this mutation.
+func (m *AccountGroupMutation) PriorityOverrideCleared() bool {
+	_, ok := m.clearedFields[accountgroup.FieldPriorityOverride]
+	return ok
+}
+
+// ResetPriorityOverride resets all changes to the "priority_override" field.
+func (m *AccountGroupMutation) ResetPriorityOverride() {
+	m.priority_override = nil
+	m.addpriority_override = nil
+	delete(m.clearedFields, accountgroup.FieldPriorityOverride)
+}
+
+// SetWeight sets the "weight" field.
+func (m *AccountGroupMutation) SetWeight(i int) {
+	m.weight = &i
+	m.addweight = nil
+}
+
+// Weight returns the value of the "weight" field in the mutation.
+func (m *AccountGroupMutation) Weight() (r int, exists bool) {
+	v := m.weight
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// AddWeight adds i to the "weight" field.
+func (m *AccountGroupMutation) AddWeight(i int) {
+	if m.addweight != nil {
+		*m.addweight += i
+	} else {
+		m.addweight = &i
+	}
+}
+
+// AddedWeight returns the value that was added to the "weight" field in this mutation.
+func (m *AccountGroupMutation) AddedWeight() (r int, exists bool) {
+	v := m.addweight
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetWeight resets all changes to the "weight" field.
+func (m *AccountGroupMutation) ResetWeight() {
+	m.weight = nil
+	m.addweight = nil
+}
+
+// SetConcurrencyCap sets the "concurrency_cap" field.
+func (m *AccountGroupMutation) SetConcurrencyCap(i int) {
+	m.concurrency_cap = &i
+	m.addconcurrency_cap = nil
+}
+
+// ConcurrencyCap returns the value of the "concurrency_cap" field in the mutation.
+func (m *AccountGroupMutation) ConcurrencyCap() (r int, exists bool) {
+	v := m.concurrency_cap
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// AddConcurrencyCap adds i to the "concurrency_cap" field.
+func (m *AccountGroupMutation) AddConcurrencyCap(i int) {
+	if m.addconcurrency_cap != nil {
+		*m.addconcurrency_cap += i
+	} else {
+		m.addconcurrency_cap = &i
+	}
+}
+
+// AddedConcurrencyCap returns the value that was added to the "concurrency_cap" field in this mutation.
+func (m *AccountGroupMutation) AddedConcurrencyCap() (r int, exists bool) {
+	v := m.addconcurrency_cap
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearConcurrencyCap clears the value of the "concurrency_cap" field.
+func (m *AccountGroupMutation) ClearConcurrencyCap() {
+	m.concurrency_cap = nil
+	m.addconcurrency_cap = nil
+	m.clearedFields[accountgroup.FieldConcurrencyCap] = struct{}{}
+}
+
+// ConcurrencyCapCleared returns if the "concurrency_cap" field was cleared in this mutation.
+func (m *AccountGroupMutation) ConcurrencyCapCleared() bool {
+	_, ok := m.clearedFields[accountgroup.FieldConcurrencyCap]
+	return ok
+}
+
+// ResetConcurrencyCap resets all changes to the "concurrency_cap" field.
+func (m *AccountGroupMutation) ResetConcurrencyCap() {
+	m.concurrency_cap = nil
+	m.addconcurrency_cap = nil
+	delete(m.clearedFields, accountgroup.FieldConcurrencyCap)
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (m *AccountGroupMutation) SetCreatedAt(t time.Time) {
 	m.created_at = &t
@@ -5323,7 +6226,7 @@ func (m *AccountGroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *AccountGroupMutation) Fields() []string {
-	fields := make([]string, 0, 4)
+	fields := make([]string, 0, 7)
 	if m.account != nil {
 		fields = append(fields, accountgroup.FieldAccountID)
 	}
@@ -5333,6 +6236,15 @@ func (m *AccountGroupMutation) Fields() []string {
 	if m.priority != nil {
 		fields = append(fields, accountgroup.FieldPriority)
 	}
+	if m.priority_override != nil {
+		fields = append(fields, accountgroup.FieldPriorityOverride)
+	}
+	if m.weight != nil {
+		fields = append(fields, accountgroup.FieldWeight)
+	}
+	if m.concurrency_cap != nil {
+		fields = append(fields, accountgroup.FieldConcurrencyCap)
+	}
 	if m.created_at != nil {
 		fields = append(fields, accountgroup.FieldCreatedAt)
 	}
@@ -5350,6 +6262,12 @@ func (m *AccountGroupMutation) Field(name string) (ent.Value, bool) {
 		return m.GroupID()
 	case accountgroup.FieldPriority:
 		return m.Priority()
+	case accountgroup.FieldPriorityOverride:
+		return m.PriorityOverride()
+	case accountgroup.FieldWeight:
+		return m.Weight()
+	case accountgroup.FieldConcurrencyCap:
+		return m.ConcurrencyCap()
 	case accountgroup.FieldCreatedAt:
 		return m.CreatedAt()
 	}
@@ -5389,6 +6307,27 @@ func (m *AccountGroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetPriority(v)
 		return nil
+	case accountgroup.FieldPriorityOverride:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPriorityOverride(v)
+		return nil
+	case accountgroup.FieldWeight:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetWeight(v)
+		return nil
+	case accountgroup.FieldConcurrencyCap:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConcurrencyCap(v)
+		return nil
 	case accountgroup.FieldCreatedAt:
 		v, ok := value.(time.Time)
 		if !ok {
@@ -5407,6 +6346,15 @@ func (m *AccountGroupMutation) AddedFields() []string {
 	if m.addpriority != nil {
 		fields = append(fields, accountgroup.FieldPriority)
 	}
+	if m.addpriority_override != nil {
+		fields = append(fields, accountgroup.FieldPriorityOverride)
+	}
+	if m.addweight != nil {
+		fields = append(fields, accountgroup.FieldWeight)
+	}
+	if m.addconcurrency_cap != nil {
+		fields = append(fields, accountgroup.FieldConcurrencyCap)
+	}
 	return fields
 }
 
@@ -5417,6 +6365,12 @@ func (m *AccountGroupMutation) AddedField(name string) (ent.Value, bool) {
 	switch name {
 	case accountgroup.FieldPriority:
 		return m.AddedPriority()
+	case accountgroup.FieldPriorityOverride:
+		return m.AddedPriorityOverride()
+	case accountgroup.FieldWeight:
+		return m.AddedWeight()
+	case accountgroup.FieldConcurrencyCap:
+		return m.AddedConcurrencyCap()
 	}
 	return nil, false
 }
@@ -5433,6 +6387,27 @@ func (m *AccountGroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddPriority(v)
 		return nil
+	case accountgroup.FieldPriorityOverride:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddPriorityOverride(v)
+		return nil
+	case accountgroup.FieldWeight:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddWeight(v)
+		return nil
+	case accountgroup.FieldConcurrencyCap:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddConcurrencyCap(v)
+		return nil
 	}
 	return fmt.Errorf("unknown AccountGroup numeric field %s", name)
 }
@@ -5440,7 +6415,14 @@ func (m *AccountGroupMutation) AddField(name string, value ent.Value) error {
 // ClearedFields returns all nullable fields that were cleared during this
 // mutation.
 func (m *AccountGroupMutation) ClearedFields() []string {
-	return nil
+	var fields []string
+	if m.FieldCleared(accountgroup.FieldPriorityOverride) {
+		fields = append(fields, accountgroup.FieldPriorityOverride)
+	}
+	if m.FieldCleared(accountgroup.FieldConcurrencyCap) {
+		fields = append(fields, accountgroup.FieldConcurrencyCap)
+	}
+	return fields
 }
 
 // FieldCleared returns a boolean indicating if a field with the given name was
@@ -5453,6 +6435,14 @@ func (m *AccountGroupMutation) FieldCleared(name string) bool {
 // ClearField clears the value of the field with the given name. It returns an
 // error if the field is not defined in the schema.
 func (m *AccountGroupMutation) ClearField(name string) error {
+	switch name {
+	case accountgroup.FieldPriorityOverride:
+		m.ClearPriorityOverride()
+		return nil
+	case accountgroup.FieldConcurrencyCap:
+		m.ClearConcurrencyCap()
+		return nil
+	}
 	return fmt.Errorf("unknown AccountGroup nullable field %s", name)
 }
 
@@ -5469,6 +6459,15 @@ func (m *AccountGroupMutation) ResetField(name string) error {
 	case accountgroup.FieldPriority:
 		m.ResetPriority()
 		return nil
+	case accountgroup.FieldPriorityOverride:
+		m.ResetPriorityOverride()
+		return nil
+	case accountgroup.FieldWeight:
+		m.ResetWeight()
+		return nil
+	case accountgroup.FieldConcurrencyCap:
+		m.ResetConcurrencyCap()
+		return nil
 	case accountgroup.FieldCreatedAt:
 		m.ResetCreatedAt()
 		return nil
@@ -20865,6 +21864,15 @@ type GroupMutation struct {
 	models_list_config                      *domain.GroupModelsListConfig
 	rpm_limit                               *int
 	addrpm_limit                            *int
+	allow_computer_use_tool                 *bool
+	allow_code_execution_tool               *bool
+	allow_web_search_tool                   *bool
+	system_prompt_profile                   *string
+	response_language                       *string
+	attribution_mode                        *string
+	attribution_text                        *string
+	max_request_cost                        *float64
+	addmax_request_cost                     *float64
 	clearedFields                           map[string]struct{}
 	api_keys                                map[int64]struct{}
 	removedapi_keys                         map[int64]struct{}
@@ -23406,6 +24414,314 @@ func (m *GroupMutation) ResetRpmLimit() {
 	m.addrpm_limit = nil
 }
 
+// SetAllowComputerUseTool sets the "allow_computer_use_tool" field.
+func (m *GroupMutation) SetAllowComputerUseTool(b bool) {
+	m.allow_computer_use_tool = &b
+}
+
+// AllowComputerUseTool returns the value of the "allow_computer_use_tool" field in the mutation.
+func (m *GroupMutation) AllowComputerUseTool() (r bool, exists bool) {
+	v := m.allow_computer_use_tool
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowComputerUseTool returns the old "allow_computer_use_tool" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAllowComputerUseTool(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowComputerUseTool is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowComputerUseTool requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowComputerUseTool: %w", err)
+	}
+	return oldValue.AllowComputerUseTool, nil
+}
+
+// ResetAllowComputerUseTool resets all changes to the "allow_computer_use_tool" field.
+func (m *GroupMutation) ResetAllowComputerUseTool() {
+	m.allow_computer_use_tool = nil
+}
+
+// SetAllowCodeExecutionTool sets the "allow_code_execution_tool" field.
+func (m *GroupMutation) SetAllowCodeExecutionTool(b bool) {
+	m.allow_code_execution_tool = &b
+}
+
+// AllowCodeExecutionTool returns the value of the "allow_code_execution_tool" field in the mutation.
+func (m *GroupMutation) AllowCodeExecutionTool() (r bool, exists bool) {
+	v := m.allow_code_execution_tool
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowCodeExecutionTool returns the old "allow_code_execution_tool" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAllowCodeExecutionTool(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowCodeExecutionTool is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowCodeExecutionTool requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowCodeExecutionTool: %w", err)
+	}
+	return oldValue.AllowCodeExecutionTool, nil
+}
+
+// ResetAllowCodeExecutionTool resets all changes to the "allow_code_execution_tool" field.
+func (m *GroupMutation) ResetAllowCodeExecutionTool() {
+	m.allow_code_execution_tool = nil
+}
+
+// SetAllowWebSearchTool sets the "allow_web_search_tool" field.
+func (m *GroupMutation) SetAllowWebSearchTool(b bool) {
+	m.allow_web_search_tool = &b
+}
+
+// AllowWebSearchTool returns the value of the "allow_web_search_tool" field in the mutation.
+func (m *GroupMutation) AllowWebSearchTool() (r bool, exists bool) {
+	v := m.allow_web_search_tool
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowWebSearchTool returns the old "allow_web_search_tool" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAllowWebSearchTool(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowWebSearchTool is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowWebSearchTool requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowWebSearchTool: %w", err)
+	}
+	return oldValue.AllowWebSearchTool, nil
+}
+
+// ResetAllowWebSearchTool resets all changes to the "allow_web_search_tool" field.
+func (m *GroupMutation) ResetAllowWebSearchTool() {
+	m.allow_web_search_tool = nil
+}
+
+// SetSystemPromptProfile sets the "system_prompt_profile" field.
+func (m *GroupMutation) SetSystemPromptProfile(s string) {
+	m.system_prompt_profile = &s
+}
+
+// SystemPromptProfile returns the value of the "system_prompt_profile" field in the mutation.
+func (m *GroupMutation) SystemPromptProfile() (r string, exists bool) {
+	v := m.system_prompt_profile
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSystemPromptProfile returns the old "system_prompt_profile" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldSystemPromptProfile(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSystemPromptProfile is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSystemPromptProfile requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSystemPromptProfile: %w", err)
+	}
+	return oldValue.SystemPromptProfile, nil
+}
+
+// ResetSystemPromptProfile resets all changes to the "system_prompt_profile" field.
+func (m *GroupMutation) ResetSystemPromptProfile() {
+	m.system_prompt_profile = nil
+}
+
+// SetResponseLanguage sets the "response_language" field.
+func (m *GroupMutation) SetResponseLanguage(s string) {
+	m.response_language = &s
+}
+
+// ResponseLanguage returns the value of the "response_language" field in the mutation.
+func (m *GroupMutation) ResponseLanguage() (r string, exists bool) {
+	v := m.response_language
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseLanguage returns the old "response_language" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseLanguage(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseLanguage is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseLanguage requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseLanguage: %w", err)
+	}
+	return oldValue.ResponseLanguage, nil
+}
+
+// ResetResponseLanguage resets all changes to the "response_language" field.
+func (m *GroupMutation) ResetResponseLanguage() {
+	m.response_language = nil
+}
+
+// SetAttributionMode sets the "attribution_mode" field.
+func (m *GroupMutation) SetAttributionMode(s string) {
+	m.attribution_mode = &s
+}
+
+// AttributionMode returns the value of the "attribution_mode" field in the mutation.
+func (m *GroupMutation) AttributionMode() (r string, exists bool) {
+	v := m.attribution_mode
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAttributionMode returns the old "attribution_mode" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAttributionMode(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAttributionMode is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAttributionMode requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAttributionMode: %w", err)
+	}
+	return oldValue.AttributionMode, nil
+}
+
+// ResetAttributionMode resets all changes to the "attribution_mode" field.
+func (m *GroupMutation) ResetAttributionMode() {
+	m.attribution_mode = nil
+}
+
+// SetAttributionText sets the "attribution_text" field.
+func (m *GroupMutation) SetAttributionText(s string) {
+	m.attribution_text = &s
+}
+
+// AttributionText returns the value of the "attribution_text" field in the mutation.
+func (m *GroupMutation) AttributionText() (r string, exists bool) {
+	v := m.attribution_text
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAttributionText returns the old "attribution_text" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAttributionText(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAttributionText is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAttributionText requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAttributionText: %w", err)
+	}
+	return oldValue.AttributionText, nil
+}
+
+// ResetAttributionText resets all changes to the "attribution_text" field.
+func (m *GroupMutation) ResetAttributionText() {
+	m.attribution_text = nil
+}
+
+// SetMaxRequestCost sets the "max_request_cost" field.
+func (m *GroupMutation) SetMaxRequestCost(f float64) {
+	m.max_request_cost = &f
+	m.addmax_request_cost = nil
+}
+
+// MaxRequestCost returns the value of the "max_request_cost" field in the mutation.
+func (m *GroupMutation) MaxRequestCost() (r float64, exists bool) {
+	v := m.max_request_cost
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxRequestCost returns the old "max_request_cost" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldMaxRequestCost(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxRequestCost is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxRequestCost requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxRequestCost: %w", err)
+	}
+	return oldValue.MaxRequestCost, nil
+}
+
+// AddMaxRequestCost adds f to the "max_request_cost" field.
+func (m *GroupMutation) AddMaxRequestCost(f float64) {
+	if m.addmax_request_cost != nil {
+		*m.addmax_request_cost += f
+	} else {
+		m.addmax_request_cost = &f
+	}
+}
+
+// AddedMaxRequestCost returns the value that was added to the "max_request_cost" field in this mutation.
+func (m *GroupMutation) AddedMaxRequestCost() (r float64, exists bool) {
+	v := m.addmax_request_cost
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMaxRequestCost resets all changes to the "max_request_cost" field.
+func (m *GroupMutation) ResetMaxRequestCost() {
+	m.max_request_cost = nil
+	m.addmax_request_cost = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *GroupMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -23764,7 +25080,7 @@ func (m *GroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *GroupMutation) Fields() []string {
-	fields := make([]string, 0, 49)
+	fields := make([]string, 0, 57)
 	if m.created_at != nil {
 		fields = append(fields, group.FieldCreatedAt)
 	}
@@ -23912,6 +25228,30 @@ func (m *GroupMutation) Fields() []string {
 	if m.rpm_limit != nil {
 		fields = append(fields, group.FieldRpmLimit)
 	}
+	if m.allow_computer_use_tool != nil {
+		fields = append(fields, group.FieldAllowComputerUseTool)
+	}
+	if m.allow_code_execution_tool != nil {
+		fields = append(fields, group.FieldAllowCodeExecutionTool)
+	}
+	if m.allow_web_search_tool != nil {
+		fields = append(fields, group.FieldAllowWebSearchTool)
+	}
+	if m.system_prompt_profile != nil {
+		fields = append(fields, group.FieldSystemPromptProfile)
+	}
+	if m.response_language != nil {
+		fields = append(fields, group.FieldResponseLanguage)
+	}
+	if m.attribution_mode != nil {
+		fields = append(fields, group.FieldAttributionMode)
+	}
+	if m.attribution_text != nil {
+		fields = append(fields, group.FieldAttributionText)
+	}
+	if m.max_request_cost != nil {
+		fields = append(fields, group.FieldMaxRequestCost)
+	}
 	return fields
 }
 
@@ -24018,6 +25358,22 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.ModelsListConfig()
 	case group.FieldRpmLimit:
 		return m.RpmLimit()
+	case group.FieldAllowComputerUseTool:
+		return m.AllowComputerUseTool()
+	case group.FieldAllowCodeExecutionTool:
+		return m.AllowCodeExecutionTool()
+	case group.FieldAllowWebSearchTool:
+		return m.AllowWebSearchTool()
+	case group.FieldSystemPromptProfile:
+		return m.SystemPromptProfile()
+	case group.FieldResponseLanguage:
+		return m.ResponseLanguage()
+	case group.FieldAttributionMode:
+		return m.AttributionMode()
+	case group.FieldAttributionText:
+		return m.AttributionText()
+	case group.FieldMaxRequestCost:
+		return m.MaxRequestCost()
 	}
 	return nil, false
 }
@@ -24125,6 +25481,22 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldModelsListConfig(ctx)
 	case group.FieldRpmLimit:
 		return m.OldRpmLimit(ctx)
+	case group.FieldAllowComputerUseTool:
+		return m.OldAllowComputerUseTool(ctx)
+	case group.FieldAllowCodeExecutionTool:
+		return m.OldAllowCodeExecutionTool(ctx)
+	case group.FieldAllowWebSearchTool:
+		return m.OldAllowWebSearchTool(ctx)
+	case group.FieldSystemPromptProfile:
+		return m.OldSystemPromptProfile(ctx)
+	case group.FieldResponseLanguage:
+		return m.OldResponseLanguage(ctx)
+	case group.FieldAttributionMode:
+		return m.OldAttributionMode(ctx)
+	case group.FieldAttributionText:
+		return m.OldAttributionText(ctx)
+	case group.FieldMaxRequestCost:
+		return m.OldMaxRequestCost(ctx)
 	}
 	return nil, fmt.Errorf("unknown Group field %s", name)
 }
@@ -24477,6 +25849,62 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetRpmLimit(v)
 		return nil
+	case group.FieldAllowComputerUseTool:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowComputerUseTool(v)
+		return nil
+	case group.FieldAllowCodeExecutionTool:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowCodeExecutionTool(v)
+		return nil
+	case group.FieldAllowWebSearchTool:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowWebSearchTool(v)
+		return nil
+	case group.FieldSystemPromptProfile:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSystemPromptProfile(v)
+		return nil
+	case group.FieldResponseLanguage:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseLanguage(v)
+		return nil
+	case group.FieldAttributionMode:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAttributionMode(v)
+		return nil
+	case group.FieldAttributionText:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAttributionText(v)
+		return nil
+	case group.FieldMaxRequestCost:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxRequestCost(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -24548,6 +25976,9 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addrpm_limit != nil {
 		fields = append(fields, group.FieldRpmLimit)
 	}
+	if m.addmax_request_cost != nil {
+		fields = append(fields, group.FieldMaxRequestCost)
+	}
 	return fields
 }
 
@@ -24598,6 +26029,8 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedSortOrder()
 	case group.FieldRpmLimit:
 		return m.AddedRpmLimit()
+	case group.FieldMaxRequestCost:
+		return m.AddedMaxRequestCost()
 	}
 	return nil, false
 }
@@ -24754,6 +26187,13 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddRpmLimit(v)
 		return nil
+	case group.FieldMaxRequestCost:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMaxRequestCost(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group numeric field %s", name)
 }
@@ -25027,6 +26467,30 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldRpmLimit:
 		m.ResetRpmLimit()
 		return nil
+	case group.FieldAllowComputerUseTool:
+		m.ResetAllowComputerUseTool()
+		return nil
+	case group.FieldAllowCodeExecutionTool:
+		m.ResetAllowCodeExecutionTool()
+		return nil
+	case group.FieldAllowWebSearchTool:
+		m.ResetAllowWebSearchTool()
+		return nil
+	case group.FieldSystemPromptProfile:
+		m.ResetSystemPromptProfile()
+		return nil
+	case group.FieldResponseLanguage:
+		m.ResetResponseLanguage()
+		return nil
+	case group.FieldAttributionMode:
+		m.ResetAttributionMode()
+		return nil
+	case group.FieldAttributionText:
+		m.ResetAttributionText()
+		return nil
+	case group.FieldMaxRequestCost:
+		m.ResetMaxRequestCost()
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -34830,6 +36294,7 @@ type ProxyMutation struct {
 	fallback_mode       *string
 	expiry_warn_days    *int
 	addexpiry_warn_days *int
+	data_residency      *string
 	clearedFields       map[string]struct{}
 	accounts            map[int64]struct{}
 	removedaccounts     map[int64]struct{}
@@ -35548,6 +37013,42 @@ func (m *ProxyMutation) ResetExpiryWarnDays() {
 	m.addexpiry_warn_days = nil
 }
 
+// SetDataResidency sets the "data_residency" field.
+func (m *ProxyMutation) SetDataResidency(s string) {
+	m.data_residency = &s
+}
+
+// DataResidency returns the value of the "data_residency" field in the mutation.
+func (m *ProxyMutation) DataResidency() (r string, exists bool) {
+	v := m.data_residency
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDataResidency returns the old "data_residency" field's value of the Proxy entity.
+// If the Proxy object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ProxyMutation) OldDataResidency(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDataResidency is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDataResidency requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDataResidency: %w", err)
+	}
+	return oldValue.DataResidency, nil
+}
+
+// ResetDataResidency resets all changes to the "data_residency" field.
+func (m *ProxyMutation) ResetDataResidency() {
+	m.data_residency = nil
+}
+
 // AddAccountIDs adds the "accounts" edge to the Account entity by ids.
 func (m *ProxyMutation) AddAccountIDs(ids ...int64) {
 	if m.accounts == nil {
@@ -35663,7 +37164,7 @@ func (m *ProxyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *ProxyMutation) Fields() []string {
-	fields := make([]string, 0, 14)
+	fields := make([]string, 0, 15)
 	if m.created_at != nil {
 		fields = append(fields, proxy.FieldCreatedAt)
 	}
@@ -35706,6 +37207,9 @@ func (m *ProxyMutation) Fields() []string {
 	if m.expiry_warn_days != nil {
 		fields = append(fields, proxy.FieldExpiryWarnDays)
 	}
+	if m.data_residency != nil {
+		fields = append(fields, proxy.FieldDataResidency)
+	}
 	return fields
 }
 
@@ -35742,6 +37246,8 @@ func (m *ProxyMutation) Field(name string) (ent.Value, bool) {
 		return m.BackupProxyID()
 	case proxy.FieldExpiryWarnDays:
 		return m.ExpiryWarnDays()
+	case proxy.FieldDataResidency:
+		return m.DataResidency()
 	}
 	return nil, false
 }
@@ -35779,6 +37285,8 @@ func (m *ProxyMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldBackupProxyID(ctx)
 	case proxy.FieldExpiryWarnDays:
 		return m.OldExpiryWarnDays(ctx)
+	case proxy.FieldDataResidency:
+		return m.OldDataResidency(ctx)
 	}
 	return nil, fmt.Errorf("unknown Proxy field %s", name)
 }
@@ -35886,6 +37394,13 @@ func (m *ProxyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetExpiryWarnDays(v)
 		return nil
+	case proxy.FieldDataResidency:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDataResidency(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Proxy field %s", name)
 }
@@ -36037,6 +37552,9 @@ func (m *ProxyMutation) ResetField(name string) error {
 	case proxy.FieldExpiryWarnDays:
 		m.ResetExpiryWarnDays()
 		return nil
+	case proxy.FieldDataResidency:
+		m.ResetDataResidency()
+		return nil
 	}
 	return fmt.Errorf("unknown Proxy field %s", name)
 }
@@ -40813,8 +42331,8 @@ type UsageCleanupTaskMutation struct {
 	created_at      *time.Time
 	updated_at      *time.Time
 	status          *string
-	filters         *json.RawMessage
-	appendfilters   json.RawMessage
+	filters         *jsontext.Value
+	appendfilters   jsontext.Value
 	created_by      *int64
 	addcreated_by   *int64
 	deleted_rows    *int64
@@ -41038,13 +42556,13 @@ func (m *UsageCleanupTaskMutation) ResetStatus() {
 }
 
 // SetFilters sets the "filters" field.
-func (m *UsageCleanupTaskMutation) SetFilters(jm json.RawMessage) {
-	m.filters = &jm
+func (m *UsageCleanupTaskMutation) SetFilters(j jsontext.Value) {
+	m.filters = &j
 	m.appendfilters = nil
 }
 
 // Filters returns the value of the "filters" field in the mutation.
-func (m *UsageCleanupTaskMutation) Filters() (r json.RawMessage, exists bool) {
+func (m *UsageCleanupTaskMutation) Filters() (r jsontext.Value, exists bool) {
 	v := m.filters
 	if v == nil {
 		return
@@ -41055,7 +42573,7 @@ func (m *UsageCleanupTaskMutation) Filters() (r json.RawMessage, exists bool) {
 // OldFilters returns the old "filters" field's value of the UsageCleanupTask entity.
 // If the UsageCleanupTask object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMessage, err error) {
+func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v jsontext.Value, err error) {
 	if !m.op.Is(OpUpdateOne) {
 		return v, errors.New("OldFilters is only allowed on UpdateOne operations")
 	}
@@ -41069,13 +42587,13 @@ func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMe
 	return oldValue.Filters, nil
 }
 
-// AppendFilters adds jm to the "filters" field.
-func (m *UsageCleanupTaskMutation) AppendFilters(jm json.RawMessage) {
-	m.appendfilters = append(m.appendfilters, jm...)
+// AppendFilters adds j to the "filters" field.
+func (m *UsageCleanupTaskMutation) AppendFilters(j jsontext.Value) {
+	m.appendfilters = append(m.appendfilters, j...)
 }
 
 // AppendedFilters returns the list of values that were appended to the "filters" field in this mutation.
-func (m *UsageCleanupTaskMutation) AppendedFilters() (json.RawMessage, bool) {
+func (m *UsageCleanupTaskMutation) AppendedFilters() (jsontext.Value, bool) {
 	if len(m.appendfilters) == 0 {
 		return nil, false
 	}
@@ -41626,7 +43144,7 @@ func (m *UsageCleanupTaskMutation) SetField(name string, value ent.Value) error
 		m.SetStatus(v)
 		return nil
 	case usagecleanuptask.FieldFilters:
-		v, ok := value.(json.RawMessage)
+		v, ok := value.(jsontext.Value)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
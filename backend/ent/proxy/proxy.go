@@ -43,6 +43,8 @@ const (
 	FieldBackupProxyID = "backup_proxy_id"
 	// FieldExpiryWarnDays holds the string denoting the expiry_warn_days field in the database.
 	FieldExpiryWarnDays = "expiry_warn_days"
+	// FieldDataResidency holds the string denoting the data_residency field in the database.
+	FieldDataResidency = "data_residency"
 	// EdgeAccounts holds the string denoting the accounts edge name in mutations.
 	EdgeAccounts = "accounts"
 	// EdgeBackupProxy holds the string denoting the backup_proxy edge name in mutations.
@@ -79,6 +81,7 @@ var Columns = []string{
 	FieldFallbackMode,
 	FieldBackupProxyID,
 	FieldExpiryWarnDays,
+	FieldDataResidency,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -125,6 +128,10 @@ var (
 	FallbackModeValidator func(string) error
 	// DefaultExpiryWarnDays holds the default value on creation for the "expiry_warn_days" field.
 	DefaultExpiryWarnDays int
+	// DefaultDataResidency holds the default value on creation for the "data_residency" field.
+	DefaultDataResidency string
+	// DataResidencyValidator is a validator for the "data_residency" field. It is called by the builders before save.
+	DataResidencyValidator func(string) error
 )
 
 // OrderOption defines the ordering options for the Proxy queries.
@@ -205,6 +212,11 @@ func ByExpiryWarnDays(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldExpiryWarnDays, opts...).ToFunc()
 }
 
+// ByDataResidency orders the results by the data_residency field.
+func ByDataResidency(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDataResidency, opts...).ToFunc()
+}
+
 // ByAccountsCount orders the results by accounts count.
 func ByAccountsCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
@@ -125,6 +125,11 @@ func ExpiryWarnDays(v int) predicate.Proxy {
 	return predicate.Proxy(sql.FieldEQ(FieldExpiryWarnDays, v))
 }
 
+// DataResidency applies equality check predicate on the "data_residency" field. It's identical to DataResidencyEQ.
+func DataResidency(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldEQ(FieldDataResidency, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Proxy {
 	return predicate.Proxy(sql.FieldEQ(FieldCreatedAt, v))
@@ -890,6 +895,71 @@ func ExpiryWarnDaysLTE(v int) predicate.Proxy {
 	return predicate.Proxy(sql.FieldLTE(FieldExpiryWarnDays, v))
 }
 
+// DataResidencyEQ applies the EQ predicate on the "data_residency" field.
+func DataResidencyEQ(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldEQ(FieldDataResidency, v))
+}
+
+// DataResidencyNEQ applies the NEQ predicate on the "data_residency" field.
+func DataResidencyNEQ(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldNEQ(FieldDataResidency, v))
+}
+
+// DataResidencyIn applies the In predicate on the "data_residency" field.
+func DataResidencyIn(vs ...string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldIn(FieldDataResidency, vs...))
+}
+
+// DataResidencyNotIn applies the NotIn predicate on the "data_residency" field.
+func DataResidencyNotIn(vs ...string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldNotIn(FieldDataResidency, vs...))
+}
+
+// DataResidencyGT applies the GT predicate on the "data_residency" field.
+func DataResidencyGT(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldGT(FieldDataResidency, v))
+}
+
+// DataResidencyGTE applies the GTE predicate on the "data_residency" field.
+func DataResidencyGTE(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldGTE(FieldDataResidency, v))
+}
+
+// DataResidencyLT applies the LT predicate on the "data_residency" field.
+func DataResidencyLT(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldLT(FieldDataResidency, v))
+}
+
+// DataResidencyLTE applies the LTE predicate on the "data_residency" field.
+func DataResidencyLTE(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldLTE(FieldDataResidency, v))
+}
+
+// DataResidencyContains applies the Contains predicate on the "data_residency" field.
+func DataResidencyContains(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldContains(FieldDataResidency, v))
+}
+
+// DataResidencyHasPrefix applies the HasPrefix predicate on the "data_residency" field.
+func DataResidencyHasPrefix(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldHasPrefix(FieldDataResidency, v))
+}
+
+// DataResidencyHasSuffix applies the HasSuffix predicate on the "data_residency" field.
+func DataResidencyHasSuffix(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldHasSuffix(FieldDataResidency, v))
+}
+
+// DataResidencyEqualFold applies the EqualFold predicate on the "data_residency" field.
+func DataResidencyEqualFold(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldEqualFold(FieldDataResidency, v))
+}
+
+// DataResidencyContainsFold applies the ContainsFold predicate on the "data_residency" field.
+func DataResidencyContainsFold(v string) predicate.Proxy {
+	return predicate.Proxy(sql.FieldContainsFold(FieldDataResidency, v))
+}
+
 // HasAccounts applies the HasEdge predicate on the "accounts" edge.
 func HasAccounts() predicate.Proxy {
 	return predicate.Proxy(func(s *sql.Selector) {
@@ -187,6 +187,20 @@ func (_c *ProxyCreate) SetNillableExpiryWarnDays(v *int) *ProxyCreate {
 	return _c
 }
 
+// SetDataResidency sets the "data_residency" field.
+func (_c *ProxyCreate) SetDataResidency(v string) *ProxyCreate {
+	_c.mutation.SetDataResidency(v)
+	return _c
+}
+
+// SetNillableDataResidency sets the "data_residency" field if the given value is not nil.
+func (_c *ProxyCreate) SetNillableDataResidency(v *string) *ProxyCreate {
+	if v != nil {
+		_c.SetDataResidency(*v)
+	}
+	return _c
+}
+
 // AddAccountIDs adds the "accounts" edge to the Account entity by IDs.
 func (_c *ProxyCreate) AddAccountIDs(ids ...int64) *ProxyCreate {
 	_c.mutation.AddAccountIDs(ids...)
@@ -270,6 +284,10 @@ func (_c *ProxyCreate) defaults() error {
 		v := proxy.DefaultExpiryWarnDays
 		_c.mutation.SetExpiryWarnDays(v)
 	}
+	if _, ok := _c.mutation.DataResidency(); !ok {
+		v := proxy.DefaultDataResidency
+		_c.mutation.SetDataResidency(v)
+	}
 	return nil
 }
 
@@ -337,6 +355,14 @@ func (_c *ProxyCreate) check() error {
 	if _, ok := _c.mutation.ExpiryWarnDays(); !ok {
 		return &ValidationError{Name: "expiry_warn_days", err: errors.New(`ent: missing required field "Proxy.expiry_warn_days"`)}
 	}
+	if _, ok := _c.mutation.DataResidency(); !ok {
+		return &ValidationError{Name: "data_residency", err: errors.New(`ent: missing required field "Proxy.data_residency"`)}
+	}
+	if v, ok := _c.mutation.DataResidency(); ok {
+		if err := proxy.DataResidencyValidator(v); err != nil {
+			return &ValidationError{Name: "data_residency", err: fmt.Errorf(`ent: validator failed for field "Proxy.data_residency": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -416,6 +442,10 @@ func (_c *ProxyCreate) createSpec() (*Proxy, *sqlgraph.CreateSpec) {
 		_spec.SetField(proxy.FieldExpiryWarnDays, field.TypeInt, value)
 		_node.ExpiryWarnDays = value
 	}
+	if value, ok := _c.mutation.DataResidency(); ok {
+		_spec.SetField(proxy.FieldDataResidency, field.TypeString, value)
+		_node.DataResidency = value
+	}
 	if nodes := _c.mutation.AccountsIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -699,6 +729,18 @@ func (u *ProxyUpsert) AddExpiryWarnDays(v int) *ProxyUpsert {
 	return u
 }
 
+// SetDataResidency sets the "data_residency" field.
+func (u *ProxyUpsert) SetDataResidency(v string) *ProxyUpsert {
+	u.Set(proxy.FieldDataResidency, v)
+	return u
+}
+
+// UpdateDataResidency sets the "data_residency" field to the value that was provided on create.
+func (u *ProxyUpsert) UpdateDataResidency() *ProxyUpsert {
+	u.SetExcluded(proxy.FieldDataResidency)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -975,6 +1017,20 @@ func (u *ProxyUpsertOne) UpdateExpiryWarnDays() *ProxyUpsertOne {
 	})
 }
 
+// SetDataResidency sets the "data_residency" field.
+func (u *ProxyUpsertOne) SetDataResidency(v string) *ProxyUpsertOne {
+	return u.Update(func(s *ProxyUpsert) {
+		s.SetDataResidency(v)
+	})
+}
+
+// UpdateDataResidency sets the "data_residency" field to the value that was provided on create.
+func (u *ProxyUpsertOne) UpdateDataResidency() *ProxyUpsertOne {
+	return u.Update(func(s *ProxyUpsert) {
+		s.UpdateDataResidency()
+	})
+}
+
 // Exec executes the query.
 func (u *ProxyUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1417,6 +1473,20 @@ func (u *ProxyUpsertBulk) UpdateExpiryWarnDays() *ProxyUpsertBulk {
 	})
 }
 
+// SetDataResidency sets the "data_residency" field.
+func (u *ProxyUpsertBulk) SetDataResidency(v string) *ProxyUpsertBulk {
+	return u.Update(func(s *ProxyUpsert) {
+		s.SetDataResidency(v)
+	})
+}
+
+// UpdateDataResidency sets the "data_residency" field to the value that was provided on create.
+func (u *ProxyUpsertBulk) UpdateDataResidency() *ProxyUpsertBulk {
+	return u.Update(func(s *ProxyUpsert) {
+		s.UpdateDataResidency()
+	})
+}
+
 // Exec executes the query.
 func (u *ProxyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
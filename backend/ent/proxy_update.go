@@ -247,6 +247,20 @@ func (_u *ProxyUpdate) AddExpiryWarnDays(v int) *ProxyUpdate {
 	return _u
 }
 
+// SetDataResidency sets the "data_residency" field.
+func (_u *ProxyUpdate) SetDataResidency(v string) *ProxyUpdate {
+	_u.mutation.SetDataResidency(v)
+	return _u
+}
+
+// SetNillableDataResidency sets the "data_residency" field if the given value is not nil.
+func (_u *ProxyUpdate) SetNillableDataResidency(v *string) *ProxyUpdate {
+	if v != nil {
+		_u.SetDataResidency(*v)
+	}
+	return _u
+}
+
 // AddAccountIDs adds the "accounts" edge to the Account entity by IDs.
 func (_u *ProxyUpdate) AddAccountIDs(ids ...int64) *ProxyUpdate {
 	_u.mutation.AddAccountIDs(ids...)
@@ -378,6 +392,11 @@ func (_u *ProxyUpdate) check() error {
 			return &ValidationError{Name: "fallback_mode", err: fmt.Errorf(`ent: validator failed for field "Proxy.fallback_mode": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.DataResidency(); ok {
+		if err := proxy.DataResidencyValidator(v); err != nil {
+			return &ValidationError{Name: "data_residency", err: fmt.Errorf(`ent: validator failed for field "Proxy.data_residency": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -447,6 +466,9 @@ func (_u *ProxyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedExpiryWarnDays(); ok {
 		_spec.AddField(proxy.FieldExpiryWarnDays, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.DataResidency(); ok {
+		_spec.SetField(proxy.FieldDataResidency, field.TypeString, value)
+	}
 	if _u.mutation.AccountsCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -759,6 +781,20 @@ func (_u *ProxyUpdateOne) AddExpiryWarnDays(v int) *ProxyUpdateOne {
 	return _u
 }
 
+// SetDataResidency sets the "data_residency" field.
+func (_u *ProxyUpdateOne) SetDataResidency(v string) *ProxyUpdateOne {
+	_u.mutation.SetDataResidency(v)
+	return _u
+}
+
+// SetNillableDataResidency sets the "data_residency" field if the given value is not nil.
+func (_u *ProxyUpdateOne) SetNillableDataResidency(v *string) *ProxyUpdateOne {
+	if v != nil {
+		_u.SetDataResidency(*v)
+	}
+	return _u
+}
+
 // AddAccountIDs adds the "accounts" edge to the Account entity by IDs.
 func (_u *ProxyUpdateOne) AddAccountIDs(ids ...int64) *ProxyUpdateOne {
 	_u.mutation.AddAccountIDs(ids...)
@@ -903,6 +939,11 @@ func (_u *ProxyUpdateOne) check() error {
 			return &ValidationError{Name: "fallback_mode", err: fmt.Errorf(`ent: validator failed for field "Proxy.fallback_mode": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.DataResidency(); ok {
+		if err := proxy.DataResidencyValidator(v); err != nil {
+			return &ValidationError{Name: "data_residency", err: fmt.Errorf(`ent: validator failed for field "Proxy.data_residency": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -989,6 +1030,9 @@ func (_u *ProxyUpdateOne) sqlSave(ctx context.Context) (_node *Proxy, err error)
 	if value, ok := _u.mutation.AddedExpiryWarnDays(); ok {
 		_spec.AddField(proxy.FieldExpiryWarnDays, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.DataResidency(); ok {
+		_spec.SetField(proxy.FieldDataResidency, field.TypeString, value)
+	}
 	if _u.mutation.AccountsCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -144,6 +144,44 @@ func init() {
 	apikeyDescUsage7d := apikeyFields[16].Descriptor()
 	// apikey.DefaultUsage7d holds the default value on creation for the usage_7d field.
 	apikey.DefaultUsage7d = apikeyDescUsage7d.Default.(float64)
+	// apikeyDescTranscriptEnabled is the schema descriptor for transcript_enabled field.
+	apikeyDescTranscriptEnabled := apikeyFields[20].Descriptor()
+	// apikey.DefaultTranscriptEnabled holds the default value on creation for the transcript_enabled field.
+	apikey.DefaultTranscriptEnabled = apikeyDescTranscriptEnabled.Default.(bool)
+	// apikeyDescAnnotationsEnabled is the schema descriptor for annotations_enabled field.
+	apikeyDescAnnotationsEnabled := apikeyFields[21].Descriptor()
+	// apikey.DefaultAnnotationsEnabled holds the default value on creation for the annotations_enabled field.
+	apikey.DefaultAnnotationsEnabled = apikeyDescAnnotationsEnabled.Default.(bool)
+	// apikeyDescStreamCaptureEnabled is the schema descriptor for stream_capture_enabled field.
+	apikeyDescStreamCaptureEnabled := apikeyFields[22].Descriptor()
+	// apikey.DefaultStreamCaptureEnabled holds the default value on creation for the stream_capture_enabled field.
+	apikey.DefaultStreamCaptureEnabled = apikeyDescStreamCaptureEnabled.Default.(bool)
+	// apikeyDescResponseLanguage is the schema descriptor for response_language field.
+	apikeyDescResponseLanguage := apikeyFields[23].Descriptor()
+	// apikey.DefaultResponseLanguage holds the default value on creation for the response_language field.
+	apikey.DefaultResponseLanguage = apikeyDescResponseLanguage.Default.(string)
+	// apikey.ResponseLanguageValidator is a validator for the "response_language" field. It is called by the builders before save.
+	apikey.ResponseLanguageValidator = apikeyDescResponseLanguage.Validators[0].(func(string) error)
+	// apikeyDescMaxPriority is the schema descriptor for max_priority field.
+	apikeyDescMaxPriority := apikeyFields[26].Descriptor()
+	// apikey.DefaultMaxPriority holds the default value on creation for the max_priority field.
+	apikey.DefaultMaxPriority = apikeyDescMaxPriority.Default.(string)
+	// apikey.MaxPriorityValidator is a validator for the "max_priority" field. It is called by the builders before save.
+	apikey.MaxPriorityValidator = apikeyDescMaxPriority.Validators[0].(func(string) error)
+	// apikeyDescStrictValidation is the schema descriptor for strict_validation field.
+	apikeyDescStrictValidation := apikeyFields[27].Descriptor()
+	// apikey.DefaultStrictValidation holds the default value on creation for the strict_validation field.
+	apikey.DefaultStrictValidation = apikeyDescStrictValidation.Default.(bool)
+	// apikeyDescMaxRequestCost is the schema descriptor for max_request_cost field.
+	apikeyDescMaxRequestCost := apikeyFields[28].Descriptor()
+	// apikey.DefaultMaxRequestCost holds the default value on creation for the max_request_cost field.
+	apikey.DefaultMaxRequestCost = apikeyDescMaxRequestCost.Default.(float64)
+	// apikeyDescDataResidency is the schema descriptor for data_residency field.
+	apikeyDescDataResidency := apikeyFields[30].Descriptor()
+	// apikey.DefaultDataResidency holds the default value on creation for the data_residency field.
+	apikey.DefaultDataResidency = apikeyDescDataResidency.Default.(string)
+	// apikey.DataResidencyValidator is a validator for the "data_residency" field. It is called by the builders before save.
+	apikey.DataResidencyValidator = apikeyDescDataResidency.Validators[0].(func(string) error)
 	accountMixin := schema.Account{}.Mixin()
 	accountMixinHooks1 := accountMixin[1].Hooks()
 	account.Hooks[0] = accountMixinHooks1[0]
@@ -261,8 +299,12 @@ func init() {
 	accountgroupDescPriority := accountgroupFields[2].Descriptor()
 	// accountgroup.DefaultPriority holds the default value on creation for the priority field.
 	accountgroup.DefaultPriority = accountgroupDescPriority.Default.(int)
+	// accountgroupDescWeight is the schema descriptor for weight field.
+	accountgroupDescWeight := accountgroupFields[4].Descriptor()
+	// accountgroup.DefaultWeight holds the default value on creation for the weight field.
+	accountgroup.DefaultWeight = accountgroupDescWeight.Default.(int)
 	// accountgroupDescCreatedAt is the schema descriptor for created_at field.
-	accountgroupDescCreatedAt := accountgroupFields[3].Descriptor()
+	accountgroupDescCreatedAt := accountgroupFields[6].Descriptor()
 	// accountgroup.DefaultCreatedAt holds the default value on creation for the created_at field.
 	accountgroup.DefaultCreatedAt = accountgroupDescCreatedAt.Default.(func() time.Time)
 	announcementFields := schema.Announcement{}.Fields()
@@ -1097,6 +1139,46 @@ func init() {
 	groupDescRpmLimit := groupFields[45].Descriptor()
 	// group.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
 	group.DefaultRpmLimit = groupDescRpmLimit.Default.(int)
+	// groupDescAllowComputerUseTool is the schema descriptor for allow_computer_use_tool field.
+	groupDescAllowComputerUseTool := groupFields[46].Descriptor()
+	// group.DefaultAllowComputerUseTool holds the default value on creation for the allow_computer_use_tool field.
+	group.DefaultAllowComputerUseTool = groupDescAllowComputerUseTool.Default.(bool)
+	// groupDescAllowCodeExecutionTool is the schema descriptor for allow_code_execution_tool field.
+	groupDescAllowCodeExecutionTool := groupFields[47].Descriptor()
+	// group.DefaultAllowCodeExecutionTool holds the default value on creation for the allow_code_execution_tool field.
+	group.DefaultAllowCodeExecutionTool = groupDescAllowCodeExecutionTool.Default.(bool)
+	// groupDescAllowWebSearchTool is the schema descriptor for allow_web_search_tool field.
+	groupDescAllowWebSearchTool := groupFields[48].Descriptor()
+	// group.DefaultAllowWebSearchTool holds the default value on creation for the allow_web_search_tool field.
+	group.DefaultAllowWebSearchTool = groupDescAllowWebSearchTool.Default.(bool)
+	// groupDescSystemPromptProfile is the schema descriptor for system_prompt_profile field.
+	groupDescSystemPromptProfile := groupFields[49].Descriptor()
+	// group.DefaultSystemPromptProfile holds the default value on creation for the system_prompt_profile field.
+	group.DefaultSystemPromptProfile = groupDescSystemPromptProfile.Default.(string)
+	// group.SystemPromptProfileValidator is a validator for the "system_prompt_profile" field. It is called by the builders before save.
+	group.SystemPromptProfileValidator = groupDescSystemPromptProfile.Validators[0].(func(string) error)
+	// groupDescResponseLanguage is the schema descriptor for response_language field.
+	groupDescResponseLanguage := groupFields[50].Descriptor()
+	// group.DefaultResponseLanguage holds the default value on creation for the response_language field.
+	group.DefaultResponseLanguage = groupDescResponseLanguage.Default.(string)
+	// group.ResponseLanguageValidator is a validator for the "response_language" field. It is called by the builders before save.
+	group.ResponseLanguageValidator = groupDescResponseLanguage.Validators[0].(func(string) error)
+	// groupDescAttributionMode is the schema descriptor for attribution_mode field.
+	groupDescAttributionMode := groupFields[51].Descriptor()
+	// group.DefaultAttributionMode holds the default value on creation for the attribution_mode field.
+	group.DefaultAttributionMode = groupDescAttributionMode.Default.(string)
+	// group.AttributionModeValidator is a validator for the "attribution_mode" field. It is called by the builders before save.
+	group.AttributionModeValidator = groupDescAttributionMode.Validators[0].(func(string) error)
+	// groupDescAttributionText is the schema descriptor for attribution_text field.
+	groupDescAttributionText := groupFields[52].Descriptor()
+	// group.DefaultAttributionText holds the default value on creation for the attribution_text field.
+	group.DefaultAttributionText = groupDescAttributionText.Default.(string)
+	// group.AttributionTextValidator is a validator for the "attribution_text" field. It is called by the builders before save.
+	group.AttributionTextValidator = groupDescAttributionText.Validators[0].(func(string) error)
+	// groupDescMaxRequestCost is the schema descriptor for max_request_cost field.
+	groupDescMaxRequestCost := groupFields[53].Descriptor()
+	// group.DefaultMaxRequestCost holds the default value on creation for the max_request_cost field.
+	group.DefaultMaxRequestCost = groupDescMaxRequestCost.Default.(float64)
 	idempotencyrecordMixin := schema.IdempotencyRecord{}.Mixin()
 	idempotencyrecordMixinFields0 := idempotencyrecordMixin[0].Fields()
 	_ = idempotencyrecordMixinFields0
@@ -1591,6 +1673,12 @@ func init() {
 	proxyDescExpiryWarnDays := proxyFields[10].Descriptor()
 	// proxy.DefaultExpiryWarnDays holds the default value on creation for the expiry_warn_days field.
 	proxy.DefaultExpiryWarnDays = proxyDescExpiryWarnDays.Default.(int)
+	// proxyDescDataResidency is the schema descriptor for data_residency field.
+	proxyDescDataResidency := proxyFields[11].Descriptor()
+	// proxy.DefaultDataResidency holds the default value on creation for the data_residency field.
+	proxy.DefaultDataResidency = proxyDescDataResidency.Default.(string)
+	// proxy.DataResidencyValidator is a validator for the "data_residency" field. It is called by the builders before save.
+	proxy.DataResidencyValidator = proxyDescDataResidency.Validators[0].(func(string) error)
 	redeemcodeFields := schema.RedeemCode{}.Fields()
 	_ = redeemcodeFields
 	// redeemcodeDescCode is the schema descriptor for code field.
@@ -13,7 +13,8 @@ import (
 )
 
 // AccountGroup holds the edge schema definition for the account_groups relationship.
-// It stores extra fields (priority, created_at) and uses a composite primary key.
+// It stores extra fields (priority, per-membership scheduling overrides, created_at)
+// and uses a composite primary key.
 type AccountGroup struct {
 	ent.Schema
 }
@@ -32,6 +33,17 @@ func (AccountGroup) Fields() []ent.Field {
 		field.Int64("group_id"),
 		field.Int("priority").
 			Default(50),
+		// priority_override: 账号在该分组内的调度优先级，NULL 表示沿用账号优先级
+		field.Int("priority_override").
+			Optional().
+			Nillable(),
+		// weight: 账号在该分组内的负载均衡权重（百分比），100 表示按账号自身负载因子
+		field.Int("weight").
+			Default(100),
+		// concurrency_cap: 该分组的准入上限，账号在途请求（计入所有分组）达到该值后不再为该分组调度，NULL 表示不限制
+		field.Int("concurrency_cap").
+			Optional().
+			Nillable(),
 		field.Time("created_at").
 			Immutable().
 			Default(time.Now).
@@ -115,6 +115,64 @@ func (APIKey) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			Comment("Start time of the current 7d rate limit window"),
+
+		// ========== Transcript fields ==========
+		field.Bool("transcript_enabled").
+			Default(false).
+			Comment("Persist full conversation transcripts for this API key (opt-in)"),
+
+		// ========== Response annotation fields ==========
+		field.Bool("annotations_enabled").
+			Default(false).
+			Comment("Expose gateway annotation headers in responses for this API key (admin-managed)"),
+
+		// ========== Upstream stream capture fields ==========
+		field.Bool("stream_capture_enabled").
+			Default(false).
+			Comment("Capture every upstream response of this API key for dispute resolution (admin-managed)"),
+
+		// ========== Response language fields ==========
+		field.String("response_language").
+			MaxLen(16).
+			Default("").
+			Comment("Enforced response language code; empty inherits the group, 'off' disables group enforcement"),
+
+		// ========== Delegated sub-key fields ==========
+		field.Int64("parent_key_id").
+			Optional().
+			Nillable().
+			Comment("Parent API key of a delegated sub-key; usage is also billed against the parent's quota"),
+		field.JSON("allowed_models", []string{}).
+			Optional().
+			Comment("Model patterns a delegated sub-key may request (trailing * wildcard); empty allows all"),
+
+		// ========== Request priority fields ==========
+		field.String("max_priority").
+			MaxLen(8).
+			Default("").
+			Comment("Highest X-Priority this key may request (low/normal/high); empty ignores the header (admin-managed)"),
+
+		// ========== Strict request validation fields ==========
+		field.Bool("strict_validation").
+			Default(false).
+			Comment("Validate request bodies against the endpoint schema before forwarding and reject malformed requests with field-level errors"),
+
+		// ========== Per-request cost cap ==========
+		field.Float("max_request_cost").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Default(0).
+			Comment("Maximum estimated cost in USD of a single request (0 = unlimited)"),
+
+		// ========== Beta feature entitlements ==========
+		field.JSON("beta_features", []string{}).
+			Optional().
+			Comment("Experimental gateway features this key may opt into per request via X-Sub2API-Beta (admin-managed)"),
+
+		// ========== Data residency ==========
+		field.String("data_residency").
+			MaxLen(8).
+			Default("").
+			Comment("Region (eu/us/asia) the serving account and its proxy must be tagged with; empty means unrestricted (admin-managed)"),
 	}
 }
 
@@ -144,5 +202,6 @@ func (APIKey) Indexes() []ent.Index {
 		// Index for quota queries
 		index.Fields("quota", "quota_used"),
 		index.Fields("expires_at"),
+		index.Fields("parent_key_id"),
 	}
 }
@@ -221,6 +221,45 @@ func (Group) Fields() []ent.Field {
 		field.Int("rpm_limit").
 			Default(0).
 			Comment("分组 RPM 上限，0 表示不限制；设置后接管该分组用户的限流"),
+
+		// Anthropic beta 工具透传开关 (added by migration 186)，默认关闭需运营显式开启
+		field.Bool("allow_computer_use_tool").
+			Default(false).
+			Comment("是否允许透传 computer use 工具（computer_*）"),
+		field.Bool("allow_code_execution_tool").
+			Default(false).
+			Comment("是否允许透传 code execution 工具（code_execution_*）"),
+		field.Bool("allow_web_search_tool").
+			Default(false).
+			Comment("是否允许透传服务端 web search 工具（web_search_*）"),
+
+		// Claude OAuth system prompt 注入档位 (added by migration 193)，空表示沿用全局设置
+		field.String("system_prompt_profile").
+			MaxLen(32).
+			Default("").
+			Comment("system prompt 注入档位：claude-code / api-default / no-injection，空表示沿用全局设置"),
+
+		// 强制回复语言 (added by migration 199)，空表示不强制
+		field.String("response_language").
+			MaxLen(16).
+			Default("").
+			Comment("强制回复语言代码（如 zh / en），请求未指定语言时追加语言指令，空表示不强制"),
+
+		// 生成内容归属水印 (added by migration 204)，空表示不添加
+		field.String("attribution_mode").
+			MaxLen(16).
+			Default("").
+			Comment("文本响应归属水印：footer（可见页脚）/ invisible（零宽字符），空表示不添加"),
+		field.String("attribution_text").
+			MaxLen(500).
+			Default("").
+			Comment("footer 模式的页脚模板，支持 {request_id} {model} {group} {timestamp} 占位符"),
+
+		// 单请求费用上限 (added by migration 206)
+		field.Float("max_request_cost").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Default(0).
+			Comment("单次请求预估费用上限（美元，按倍率折算后），0 表示不限制"),
 	}
 }
 
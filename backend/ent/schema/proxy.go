@@ -64,6 +64,10 @@ func (Proxy) Fields() []ent.Field {
 		field.Int("expiry_warn_days").
 			Default(7).
 			Comment("Days before expiry to flag as expiring-soon (per proxy)."),
+		field.String("data_residency").
+			MaxLen(8).
+			Default("").
+			Comment("Region the egress IP is located in (eu/us/asia); empty means untagged."),
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"net"
 	"net/textproto"
 	"net/url"
 	"os"
@@ -56,6 +57,22 @@ const (
 	ConnectionPoolIsolationAccountProxy = "account_proxy"
 )
 
+// SOCKS 代理目标域名解析模式常量
+const (
+	// DNSResolutionRemote: 目标域名交由代理端解析（socks5h 语义，防止 DNS 泄漏）
+	DNSResolutionRemote = "remote"
+	// DNSResolutionLocal: 本地解析后以 IP 连接代理（可配合静态映射 / DoH 固定出口节点）
+	DNSResolutionLocal = "local"
+)
+
+func isValidDNSResolutionMode(mode string) bool {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case DNSResolutionRemote, DNSResolutionLocal:
+		return true
+	}
+	return false
+}
+
 // DefaultUpstreamResponseReadMaxBytes 上游非流式响应体的默认读取上限。
 // 128 MB 可容纳 2-3 张 4K PNG（base64 膨胀 33%，单张 4K PNG 最坏约 67MB base64）。
 // 可通过 gateway.upstream_response_read_max_bytes 配置项覆盖。
@@ -89,8 +106,20 @@ type Config struct {
 	Dashboard               DashboardCacheConfig          `mapstructure:"dashboard_cache"`
 	DashboardAgg            DashboardAggregationConfig    `mapstructure:"dashboard_aggregation"`
 	UsageCleanup            UsageCleanupConfig            `mapstructure:"usage_cleanup"`
+	UsageArchive            UsageArchiveConfig            `mapstructure:"usage_archive"`
+	StartupDiagnostics      StartupDiagnosticsConfig      `mapstructure:"startup_diagnostics"`
+	WarmStart               WarmStartConfig               `mapstructure:"warm_start"`
+	AccountRenewal          AccountRenewalConfig          `mapstructure:"account_renewal"`
+	AccountSnapshot         AccountSnapshotConfig         `mapstructure:"account_snapshot"`
+	CredentialSync          CredentialSyncConfig          `mapstructure:"credential_sync"`
+	UserNotification        UserNotificationConfig        `mapstructure:"user_notification"`
+	ModelAliasLearning      ModelAliasLearningConfig      `mapstructure:"model_alias_learning"`
+	CostAnomaly             CostAnomalyConfig             `mapstructure:"cost_anomaly"`
+	AccountSmokeTest        AccountSmokeTestConfig        `mapstructure:"account_smoke_test"`
+	AutoscalingMetrics      AutoscalingMetricsConfig      `mapstructure:"autoscaling_metrics"`
 	Concurrency             ConcurrencyConfig             `mapstructure:"concurrency"`
 	TokenRefresh            TokenRefreshConfig            `mapstructure:"token_refresh"`
+	TokenCache              TokenCacheConfig              `mapstructure:"token_cache"`
 	RunMode                 string                        `mapstructure:"run_mode" yaml:"run_mode"`
 	Timezone                string                        `mapstructure:"timezone"` // e.g. "Asia/Shanghai", "UTC"
 	Gemini                  GeminiConfig                  `mapstructure:"gemini"`
@@ -110,6 +139,7 @@ type LogConfig struct {
 	Output          LogOutputConfig   `mapstructure:"output"`
 	Rotation        LogRotationConfig `mapstructure:"rotation"`
 	Sampling        LogSamplingConfig `mapstructure:"sampling"`
+	Access          AccessLogConfig   `mapstructure:"access"`
 }
 
 type LogOutputConfig struct {
@@ -126,6 +156,18 @@ type LogRotationConfig struct {
 	LocalTime  bool `mapstructure:"local_time"`
 }
 
+// AccessLogConfig 独立的 HTTP 访问日志（Common / Combined Log Format），与 zap 结构化日志分开写入。
+type AccessLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Format: common / combined
+	Format string `mapstructure:"format"`
+	// FilePath 为空时使用 ${DATA_DIR}/logs/access.log（未设置 DATA_DIR 时为 /app/data/logs/access.log）
+	FilePath string            `mapstructure:"file_path"`
+	Rotation LogRotationConfig `mapstructure:"rotation"`
+	// RotateInterval 按时间轮转周期：none / hourly / daily（与按大小轮转同时生效）
+	RotateInterval string `mapstructure:"rotate_interval"`
+}
+
 type LogSamplingConfig struct {
 	Enabled    bool `mapstructure:"enabled"`
 	Initial    int  `mapstructure:"initial"`
@@ -146,6 +188,8 @@ type GeminiOAuthConfig struct {
 type GeminiQuotaConfig struct {
 	Tiers  map[string]GeminiTierQuotaConfig `mapstructure:"tiers"`
 	Policy string                           `mapstructure:"policy"`
+	// ProbeIntervalMinutes: Code Assist 账号剩余配额探测间隔（分钟，0 表示关闭）；配额耗尽的账号/模型限流至重置时间
+	ProbeIntervalMinutes int `mapstructure:"probe_interval_minutes"`
 }
 
 type GeminiTierQuotaConfig struct {
@@ -641,6 +685,17 @@ type TokenRefreshConfig struct {
 	CycleTimeoutSeconds int `mapstructure:"cycle_timeout_seconds"`
 }
 
+// TokenCacheConfig 上游 OAuth access token 的 Redis 缓存配置
+type TokenCacheConfig struct {
+	// Encrypt: 使用 AES-256-GCM（密钥为 totp.encryption_key）加密缓存的 token 值；
+	// 多实例部署必须配置固定密钥，否则各实例互相读不到对方写入的 token（按未命中处理）
+	Encrypt bool `mapstructure:"encrypt"`
+	// KeyPrefix: Redis key 命名空间前缀，多个部署/环境共用同一 Redis 时用于隔离
+	KeyPrefix string `mapstructure:"key_prefix"`
+	// TTLJitterPercent: 写入时将 TTL 随机缩短 0~N%，避免同批刷新的 token 同时过期
+	TTLJitterPercent int `mapstructure:"ttl_jitter_percent"`
+}
+
 type PricingConfig struct {
 	// 价格数据远程URL（默认使用LiteLLM镜像）
 	RemoteURL string `mapstructure:"remote_url"`
@@ -669,6 +724,19 @@ type ServerConfig struct {
 	TrustedProxiesConfigured bool      `mapstructure:"-" json:"-" yaml:"-"`   // 是否显式配置了可信代理列表
 	MaxRequestBodySize       int64     `mapstructure:"max_request_body_size"` // 全局最大请求体限制
 	H2C                      H2CConfig `mapstructure:"h2c"`                   // HTTP/2 Cleartext 配置
+	// Compression 非流式响应压缩配置
+	Compression ServerCompressionConfig `mapstructure:"compression"`
+}
+
+// ServerCompressionConfig 非流式响应的 gzip/br 协商压缩配置。
+// 仅压缩 Content-Type 命中白名单且响应体达到阈值的响应；SSE、WebSocket 升级、
+// 已带 Content-Encoding 或处理过程中主动 Flush 的响应保持原样直出，不做缓冲。
+type ServerCompressionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinSizeBytes: 响应体小于该值时不压缩
+	MinSizeBytes int `mapstructure:"min_size_bytes"`
+	// ContentTypes: 允许压缩的媒体类型（不含参数，如 application/json）
+	ContentTypes []string `mapstructure:"content_types"`
 }
 
 // H2CConfig HTTP/2 Cleartext 配置
@@ -887,6 +955,12 @@ type GatewayConfig struct {
 	TextMaxBodySize int64 `mapstructure:"text_max_body_size"`
 	// 非流式上游响应体读取上限（字节），用于防止无界读取导致内存放大
 	UpstreamResponseReadMaxBytes int64 `mapstructure:"upstream_response_read_max_bytes"`
+	// 按入站路由覆盖非流式响应体读取上限（key 为路由路径，如 /v1/messages）
+	UpstreamResponseEndpointMaxBytes map[string]int64 `mapstructure:"upstream_response_endpoint_max_bytes"`
+	// 流式（SSE）上游响应累计字节上限，超出即中止并记录错误；0 表示不限制
+	UpstreamStreamMaxBytes int64 `mapstructure:"upstream_stream_max_bytes"`
+	// 按入站路由覆盖流式累计字节上限（key 为路由路径）
+	UpstreamStreamEndpointMaxBytes map[string]int64 `mapstructure:"upstream_stream_endpoint_max_bytes"`
 	// 代理探测响应体读取上限（字节）
 	ProxyProbeResponseReadMaxBytes int64 `mapstructure:"proxy_probe_response_read_max_bytes"`
 	// Gemini 上游响应头调试日志开关（默认关闭，避免高频日志开销）
@@ -950,6 +1024,12 @@ type GatewayConfig struct {
 	StreamDataIntervalTimeout int `mapstructure:"stream_data_interval_timeout"`
 	// StreamKeepaliveInterval: 流式 keepalive 间隔（秒），0表示禁用
 	StreamKeepaliveInterval int `mapstructure:"stream_keepalive_interval"`
+	// ClientDisconnectUpstreamGraceSeconds: 客户端断开后继续读取上游（补全 usage 计费）的最长时间（秒），0表示立即取消上游
+	ClientDisconnectUpstreamGraceSeconds int `mapstructure:"client_disconnect_upstream_grace_seconds"`
+	// StreamClientWriteTimeout: 流式响应单次写入客户端的超时（秒），0表示禁用；超时视为慢客户端并断开
+	StreamClientWriteTimeout int `mapstructure:"stream_client_write_timeout"`
+	// StreamClientMaxLagBytes: 客户端写入阻塞期间允许积压的上游字节数，0表示不限制；超出后断开客户端
+	StreamClientMaxLagBytes int64 `mapstructure:"stream_client_max_lag_bytes"`
 	// ImageStreamDataIntervalTimeout: 图片流数据间隔超时（秒），0表示禁用
 	ImageStreamDataIntervalTimeout int `mapstructure:"image_stream_data_interval_timeout"`
 	// ImageStreamKeepaliveInterval: 图片流式 keepalive 间隔（秒），0表示禁用
@@ -958,6 +1038,8 @@ type GatewayConfig struct {
 	ImageNonstreamKeepaliveInterval int `mapstructure:"image_nonstream_keepalive_interval"`
 	// MaxLineSize: 上游 SSE 单行最大字节数（0使用默认值）
 	MaxLineSize int `mapstructure:"max_line_size"`
+	// SSEOversizeLine: 上游 SSE 单行超过 MaxLineSize 时的处理方式
+	SSEOversizeLine GatewaySSEOversizeLineConfig `mapstructure:"sse_oversize_line"`
 
 	// 是否记录上游错误响应体摘要（避免输出请求内容）
 	LogUpstreamErrorBody bool `mapstructure:"log_upstream_error_body"`
@@ -995,6 +1077,422 @@ type GatewayConfig struct {
 	// UserMessageQueue: 用户消息串行队列配置
 	// 对 role:"user" 的真实用户消息实施账号级串行化 + RPM 自适应延迟
 	UserMessageQueue UserMessageQueueConfig `mapstructure:"user_message_queue"`
+
+	// ConversationTruncation: 超长对话服务端截断配置（默认关闭）
+	ConversationTruncation GatewayConversationTruncationConfig `mapstructure:"conversation_truncation"`
+	// MaxTokens: 按模型注入默认 max_tokens 并钳制超出上限的值（默认关闭）
+	MaxTokens GatewayMaxTokensConfig `mapstructure:"max_tokens"`
+	// ToolLimits: 按分组平台限制客户端 tools 定义的数量与大小（默认关闭）
+	ToolLimits GatewayToolLimitsConfig `mapstructure:"tool_limits"`
+	// ConversationTranscript: 会话全文留存（需按 API Key 单独开启）
+	ConversationTranscript GatewayConversationTranscriptConfig `mapstructure:"conversation_transcript"`
+	// UpstreamStreamCapture: 上游原始响应留存到对象存储，供争议核查（默认关闭）
+	UpstreamStreamCapture GatewayUpstreamStreamCaptureConfig `mapstructure:"upstream_stream_capture"`
+
+	// DNS: 上游连接的自定义解析（静态映射 / DoH / SOCKS 解析位置）
+	DNS GatewayDNSConfig `mapstructure:"dns"`
+
+	// RequestMirror: 按比例将请求元数据镜像到外部分析端点（默认关闭）
+	RequestMirror GatewayRequestMirrorConfig `mapstructure:"request_mirror"`
+
+	// ProxyLatencyRouting: 多候选代理账号按上游主机延迟自动选路（默认关闭）
+	ProxyLatencyRouting GatewayProxyLatencyRoutingConfig `mapstructure:"proxy_latency_routing"`
+
+	// UpstreamMTLS: 上游 mTLS 客户端证书（按平台或账号选择证书配置）
+	UpstreamMTLS GatewayUpstreamMTLSConfig `mapstructure:"upstream_mtls"`
+
+	// AdmissionControl: 并发槽位等待队列的准入控制与过载卸载（默认关闭）
+	AdmissionControl GatewayAdmissionControlConfig `mapstructure:"admission_control"`
+
+	// UpstreamDecompression: 上游响应 Content-Encoding 解码策略（默认解码全部内置编码）
+	UpstreamDecompression GatewayUpstreamDecompressionConfig `mapstructure:"upstream_decompression"`
+
+	// SlotLease: 并发槽位心跳租约（默认关闭，沿用 concurrency_slot_ttl_minutes 作为槽位过期时间）
+	SlotLease GatewaySlotLeaseConfig `mapstructure:"slot_lease"`
+
+	// SSEResume: 流式响应断线续传（Last-Event-ID，默认关闭）
+	SSEResume GatewaySSEResumeConfig `mapstructure:"sse_resume"`
+
+	// SSEWebSocketBridge: 通过 /ws 前缀以 WebSocket 承载流式响应（默认关闭）
+	SSEWebSocketBridge GatewaySSEWebSocketBridgeConfig `mapstructure:"sse_websocket_bridge"`
+
+	// ErrorTranslation: 上游错误消息标准化与按客户端语言翻译（默认关闭）
+	ErrorTranslation GatewayErrorTranslationConfig `mapstructure:"error_translation"`
+
+	// RequestParseShadow: 请求体快速解析的影子校验（默认关闭）
+	RequestParseShadow GatewayRequestParseShadowConfig `mapstructure:"request_parse_shadow"`
+
+	// PolicyPlugins: 自定义请求策略插件（默认关闭）
+	PolicyPlugins GatewayPolicyPluginsConfig `mapstructure:"policy_plugins"`
+
+	// AccountWarmup: 新启用账号的流量预热爬坡（默认关闭）
+	AccountWarmup GatewayAccountWarmupConfig `mapstructure:"account_warmup"`
+
+	// ContentPolicyCooldown: 上游内容策略违规后的账号冷却（默认关闭）
+	ContentPolicyCooldown GatewayContentPolicyCooldownConfig `mapstructure:"content_policy_cooldown"`
+
+	// AgentSessionAffinity: 工具调用型 Agent 会话的账号亲和（默认关闭）
+	AgentSessionAffinity GatewayAgentSessionAffinityConfig `mapstructure:"agent_session_affinity"`
+}
+
+// GatewayAgentSessionAffinityConfig 工具调用型 Agent 会话的账号亲和配置。
+// 请求携带 tools 且 metadata.user_id 中带有会话 ID 时视为 Agent 会话，其粘性绑定使用更长的 TTL，
+// 让多轮 tool_use / tool_result 尽量落在同一账号上（复用 prompt cache、平滑限流）；
+// 绑定账号进入冷却（限流、过载、临时不可调度，或开启 ReleaseOnWindowWarning 时 5h 窗口告警）后自动释放。
+type GatewayAgentSessionAffinityConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// TTLSeconds: Agent 会话粘性绑定的 TTL（秒），应不小于普通粘性会话的 1 小时
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// ReleaseOnWindowWarning: 账号 5h 窗口进入 allowed_warning / rejected 时提前释放绑定
+	ReleaseOnWindowWarning bool `mapstructure:"release_on_window_warning"`
+}
+
+// GatewayContentPolicyCooldownConfig 上游内容策略（guardrail）违规的账号冷却配置。
+// 上游以 400/403 返回内容策略类错误时记录一次违规：scope=user 时该用户在冷却期内不再调度到该账号，
+// scope=account 时该账号对所有流量冷却。近期有违规的用户被视为高风险，会避开窗口内违规次数已达阈值的账号，
+// 把风险流量分散到不同账号，降低单个账号被上游封禁的概率。统计保存在 Redis 中（按 TTL 过期），多实例共享。
+type GatewayContentPolicyCooldownConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// Scope: 冷却范围，user（仅违规用户的流量）或 account（账号全部流量）
+	Scope string `mapstructure:"scope"`
+	// CooldownSeconds: 每次违规后的冷却时长（秒）
+	CooldownSeconds int `mapstructure:"cooldown_seconds"`
+	// WindowSeconds: 违规率统计窗口（秒），同时决定用户被视为高风险的时长
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// AccountThreshold: 窗口内违规次数达到该值的账号不再承接高风险用户的流量（0 表示不分散）
+	AccountThreshold int `mapstructure:"account_threshold"`
+}
+
+const (
+	ContentPolicyCooldownScopeUser    = "user"
+	ContentPolicyCooldownScopeAccount = "account"
+)
+
+// GatewayAccountWarmupConfig 新启用账号的流量预热配置。
+// 账号创建或由停用恢复为启用后，在预热窗口内只承接部分非粘性流量，份额从 InitialShare 线性增长到 100%，
+// 避免新账号一上线就被打满触发上游风控。粘性会话不受影响；候选中只剩预热账号时不做限制。
+type GatewayAccountWarmupConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// WindowHours: 预热窗口（小时），窗口结束后按正常份额调度
+	WindowHours int `mapstructure:"window_hours"`
+	// InitialShare: 预热开始时的流量份额（0-1]
+	InitialShare float64 `mapstructure:"initial_share"`
+}
+
+// GatewayPolicyPluginsConfig 自定义请求策略插件配置。
+// 策略在 pre_parse（解析请求体前）、pre_forward（每次发往上游前）、post_response（响应结束后）三个钩子点被调用，
+// 可改写请求体、拒绝请求或附加注解（注解随请求结束写入日志）。策略拿不到凭证类请求头。
+// 注意：pre_forward 阶段的拒绝会以上游请求失败的形式结束本次请求（客户端收到 502），需要返回明确错误时请在 pre_parse 拒绝。
+type GatewayPolicyPluginsConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// Builtins: 按顺序启用的内置策略名（编译进二进制并通过 policyplugin.Register 注册）
+	Builtins []string `mapstructure:"builtins"`
+	// Paths: 按顺序加载的 Go 插件（-buildmode=plugin）路径，排在内置策略之后执行
+	Paths []string `mapstructure:"paths"`
+	// TimeoutMs: 单个策略单次调用的超时（毫秒）
+	TimeoutMs int `mapstructure:"timeout_ms"`
+	// FailOpen: 策略出错或超时时放行（true）还是拒绝请求（false，返回 503）
+	FailOpen bool `mapstructure:"fail_open"`
+}
+
+// GatewayRequestParseShadowConfig 请求体快速解析（gjson）的影子校验配置。
+// 启用后按采样率对请求额外使用 encoding/json 的 map 解析器重新解析一次，
+// 两者结果不一致时记录结构化差异日志（只记录字段名与取值摘要，不记录请求内容），用于发现罕见请求体上的解析回归。
+// 影子解析只用于比对，不影响实际转发。
+type GatewayRequestParseShadowConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// SampleRate: 采样比例（0-1）
+	SampleRate float64 `mapstructure:"sample_rate"`
+}
+
+// GatewayErrorTranslationConfig 上游错误消息标准化配置。
+// 启用后，请求命中上游错误时，将 JSON 错误响应中已知的上游错误（限流、过载、上下文超长等）
+// 改写为统一文案，并按 Accept-Language 返回中文或英文；未识别的错误保持原样。
+// 上下文超长等客户端依赖原文识别的错误保留 error.message，统一文案写入 error.localized_message。
+// 原始错误响应体保存在运维错误日志的 upstream_error_detail 中（仅管理员可见）。
+// 注意：仅处理非流式 JSON 错误响应，已开始输出的 SSE 流内错误不做改写。
+type GatewayErrorTranslationConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// DefaultLocale: Accept-Language 未命中受支持语言时使用的语言（en/zh）
+	DefaultLocale string `mapstructure:"default_locale"`
+}
+
+// GatewaySSEResumeConfig 流式响应断线续传配置。
+// 启用后对话类流式接口为每个 SSE 事件分配 "<stream_id>-<seq>" 形式的 ID，并在 Redis 中短期缓存最近的事件；
+// 客户端携带 Last-Event-ID 重新发起同一请求时，直接回放其后的事件而不是重新生成。
+// 客户端断开后生成继续写入缓存，最多再保留 DisconnectGraceSeconds 供客户端续传，之后按客户端断开处理（取消上游）。
+type GatewaySSEResumeConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// BufferEvents: 每个流最多缓存的最近事件数
+	BufferEvents int `mapstructure:"buffer_events"`
+	// TTLSeconds: 缓存保留时间（秒），自最后一次写入起计算
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// TailIdleTimeoutSeconds: 续传时原流仍在生成，等待新事件的最长空闲时间（秒）
+	TailIdleTimeoutSeconds int `mapstructure:"tail_idle_timeout_seconds"`
+	// DisconnectGraceSeconds: 客户端断开后继续生成（供续传）的最长时间（秒），0表示按普通请求立即处理断开
+	DisconnectGraceSeconds int `mapstructure:"disconnect_grace_seconds"`
+}
+
+// SSE 超长行处理模式
+const (
+	SSEOversizeLineModeError    = "error"
+	SSEOversizeLineModeTruncate = "truncate"
+)
+
+// GatewaySSEOversizeLineConfig 上游 SSE 单行超过 max_line_size 时的处理配置。
+// error 模式下中止流并下发 response_too_large 错误事件（历史行为）；
+// truncate 模式下以流式 JSON 扫描该行，仅截断超长的字符串字段并在截断处追加提示，其余结构原样保留，
+// 避免长输出在接近结束时因单个超大事件整体失败。
+type GatewaySSEOversizeLineConfig struct {
+	// Mode: error | truncate
+	Mode string `mapstructure:"mode"`
+	// KeepBytes: truncate 模式下每个被截断字符串字段保留的最大字节数
+	KeepBytes int `mapstructure:"keep_bytes"`
+}
+
+// GatewaySSEWebSocketBridgeConfig 流式响应的 WebSocket 桥接配置。
+// 启用后 /ws/v1/messages、/ws/v1/chat/completions、/ws/v1/responses 接受 WebSocket 升级：
+// 客户端连接后发送一条消息作为请求体，网关按对应 POST 接口处理（共用认证、并发与计费链路），
+// 每个 SSE 事件的 data 作为一条 WebSocket 消息下发，供无法可靠消费 SSE 的客户端（如严格代理之后）使用。
+// 请求体消息大小受 gateway.text_max_body_size 限制。
+type GatewaySSEWebSocketBridgeConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// FirstMessageTimeoutSeconds: 连接建立后等待客户端发送请求体的最长时间（秒）
+	FirstMessageTimeoutSeconds int `mapstructure:"first_message_timeout_seconds"`
+	// WriteTimeoutSeconds: 单条消息写入客户端的超时（秒）
+	WriteTimeoutSeconds int `mapstructure:"write_timeout_seconds"`
+}
+
+// GatewayProxyLatencyRoutingConfig 基于延迟的代理选路配置。
+// 账号在 extra.proxy_candidate_ids 中配置候选代理后，按上游主机周期性探测各候选代理延迟，
+// 选择延迟最低的健康代理；切换需同时满足相对/绝对改善阈值与最短驻留时间，避免来回抖动。
+type GatewayProxyLatencyRoutingConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// ProbeIntervalSeconds: 探测与重新评估周期（秒）
+	ProbeIntervalSeconds int `mapstructure:"probe_interval_seconds"`
+	// ProbeTimeoutSeconds: 单次探测超时（秒）
+	ProbeTimeoutSeconds int `mapstructure:"probe_timeout_seconds"`
+	// RefreshIntervalSeconds: 重新加载账号候选代理列表的周期（秒）
+	RefreshIntervalSeconds int `mapstructure:"refresh_interval_seconds"`
+	// SwitchThresholdPercent: 新代理延迟需比当前低出的百分比才切换
+	SwitchThresholdPercent int `mapstructure:"switch_threshold_percent"`
+	// MinImprovementMs: 新代理延迟需比当前低出的最小毫秒数才切换
+	MinImprovementMs int `mapstructure:"min_improvement_ms"`
+	// MinHoldSeconds: 选定后至少保持的时间（秒），当前代理不健康时不受此限制
+	MinHoldSeconds int `mapstructure:"min_hold_seconds"`
+}
+
+// GatewayRequestMirrorConfig 请求镜像配置。
+// 镜像在请求结束后经独立的有界 worker 池异步投递，队列满时直接丢弃，不影响网关主路径。
+type GatewayRequestMirrorConfig struct {
+	// Enabled: 是否启用镜像
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint: 接收镜像的外部 HTTP(S) 地址（POST JSON）
+	Endpoint string `mapstructure:"endpoint"`
+	// AuthToken: 可选，作为 Authorization: Bearer 发送给外部端点
+	AuthToken string `mapstructure:"auth_token"`
+	// SigningSecret: 可选，设置后为每次投递附加 HMAC 签名头（X-Sub2API-Timestamp / X-Sub2API-Signature），
+	// 接收端可用 requestsign.Verify 校验来源并拒绝重放
+	SigningSecret string `mapstructure:"signing_secret"`
+	// SampleRate: 采样比例 (0, 1]
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// IncludeBodies: 是否附带请求/响应正文（默认仅元数据）
+	IncludeBodies bool `mapstructure:"include_bodies"`
+	// MaxBodyBytes: 附带正文时单个正文的上限（字节），超出截断
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// TimeoutSeconds: 单次投递超时（秒）
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// WorkerCount / QueueSize: 镜像投递 worker 数与队列容量
+	WorkerCount int `mapstructure:"worker_count"`
+	QueueSize   int `mapstructure:"queue_size"`
+}
+
+// GatewayConversationTruncationConfig 超长对话服务端截断配置。
+// 启用后，当 /v1/messages 请求的估算 token 超过模型上下文（或配置上限）时，
+// 网关会从最早的消息开始丢弃，保留 system 与最近 N 轮对话，且不会拆开 tool_use/tool_result 对。
+type GatewayConversationTruncationConfig struct {
+	// Enabled: 是否启用服务端截断
+	Enabled bool `mapstructure:"enabled"`
+	// MaxInputTokens: 输入 token 估算上限，0 表示仅按模型上下文窗口判断
+	MaxInputTokens int `mapstructure:"max_input_tokens"`
+	// ModelMaxInputTokens: 按模型覆盖的输入上限（精确匹配，或以 * 结尾的前缀匹配）
+	ModelMaxInputTokens map[string]int `mapstructure:"model_max_input_tokens"`
+	// PreserveRecentTurns: 始终保留的最近消息轮数（tool_use/tool_result 对计为一轮）
+	PreserveRecentTurns int `mapstructure:"preserve_recent_turns"`
+}
+
+// GatewayMaxTokensConfig /v1/messages 的 max_tokens 默认值与上限配置。
+// 模型名支持精确匹配或以 * 结尾的前缀匹配；未配置上限的模型可回退到价格目录中的 max_output_tokens。
+type GatewayMaxTokensConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// ModelDefaults: 请求未携带 max_tokens 时注入的默认值
+	ModelDefaults map[string]int `mapstructure:"model_defaults"`
+	// ModelMaxima: max_tokens 上限，超出时钳制为该值
+	ModelMaxima map[string]int `mapstructure:"model_maxima"`
+	// CatalogMaximumFallback: 未配置上限时使用价格目录中的 max_output_tokens 作为上限
+	CatalogMaximumFallback bool `mapstructure:"catalog_maximum_fallback"`
+}
+
+// GatewayToolLimitsConfig 客户端 tools 定义的上限配置。
+// 启用后按 API Key 所属分组的平台取上限，在转发前检查工具数量、单个工具定义大小与工具描述长度，
+// 超限时返回指明具体工具的 400，避免上游返回含糊的错误；描述超长可按策略自动截断后放行。
+type GatewayToolLimitsConfig struct {
+	// Enabled: 是否启用
+	Enabled bool `mapstructure:"enabled"`
+	// DescriptionPolicy: 工具描述超长时的处理策略：reject（拒绝请求）/ truncate（截断后放行）
+	DescriptionPolicy string `mapstructure:"description_policy"`
+	// Platforms: 各平台上限，键为平台（anthropic / openai / gemini / antigravity / grok）；未配置的平台不检查
+	Platforms map[string]GatewayToolLimit `mapstructure:"platforms"`
+}
+
+// GatewayToolLimit 单个平台的 tools 上限，0 表示不限制该项。
+type GatewayToolLimit struct {
+	// MaxTools: 工具数量上限（Gemini 按 functionDeclarations 逐个计数）
+	MaxTools int `mapstructure:"max_tools"`
+	// MaxToolBytes: 单个工具定义序列化后的字节上限
+	MaxToolBytes int `mapstructure:"max_tool_bytes"`
+	// MaxTotalBytes: 全部工具定义合计字节上限
+	MaxTotalBytes int `mapstructure:"max_total_bytes"`
+	// MaxDescriptionChars: 工具描述的字符数上限
+	MaxDescriptionChars int `mapstructure:"max_description_chars"`
+}
+
+// 工具描述超长的处理策略
+const (
+	ToolDescriptionPolicyReject   = "reject"
+	ToolDescriptionPolicyTruncate = "truncate"
+)
+
+// GatewayAdmissionControlConfig 等待槽位请求的准入控制。
+// 超出全局/分组等待上限，或近期槽位等待 p95 超过目标时按比例卸载，直接返回 503 + Retry-After，
+// 避免极端负载下等待队列无限增长。计数为本实例内存统计。
+type GatewayAdmissionControlConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxWaitingGlobal: 本实例同时等待槽位的请求上限，0 表示不限
+	MaxWaitingGlobal int `mapstructure:"max_waiting_global"`
+	// MaxWaitingPerGroup: 每个分组同时等待槽位的请求上限，0 表示不限
+	MaxWaitingPerGroup int `mapstructure:"max_waiting_per_group"`
+	// ShedWaitP95Ms: 自适应卸载的目标等待 p95（毫秒），超过后按超出比例拒绝新的等待请求，0 表示关闭
+	ShedWaitP95Ms int `mapstructure:"shed_wait_p95_ms"`
+	// ShedWindowSeconds: 计算等待 p95 的时间窗口（秒）
+	ShedWindowSeconds int `mapstructure:"shed_window_seconds"`
+	// ShedMinSamples: 窗口内样本少于该值时不做自适应卸载
+	ShedMinSamples int `mapstructure:"shed_min_samples"`
+	// RetryAfterSeconds: 卸载响应的 Retry-After（秒）
+	RetryAfterSeconds int `mapstructure:"retry_after_seconds"`
+}
+
+// GatewaySlotLeaseConfig 并发槽位心跳租约。
+// 启用后槽位过期时间改为 ttl_seconds，持有槽位的实例每 heartbeat_interval_seconds 续约一次：
+// 超长请求不会因槽位过期被挤占，实例宕机后其槽位也会在一个 TTL 内自动回收。
+type GatewaySlotLeaseConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TTLSeconds: 租约有效期（秒），未续约超过该时间的槽位会被回收
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// HeartbeatIntervalSeconds: 续约间隔（秒），不得超过 ttl_seconds 的一半
+	HeartbeatIntervalSeconds int `mapstructure:"heartbeat_interval_seconds"`
+}
+
+// UpstreamContentEncodings 上游响应可解码的 Content-Encoding
+var UpstreamContentEncodings = []string{"gzip", "br", "deflate", "zstd"}
+
+// GatewayUpstreamDecompressionConfig 上游响应解码策略。
+// 默认对 gzip/br/deflate/zstd 响应解码后再交给业务层（计费解析、流式转换）；
+// 列入 skip 的编码不解码、连同 Content-Encoding 原样交给下游。
+type GatewayUpstreamDecompressionConfig struct {
+	// SkipEncodings: 不解码的编码，"*" 表示全部
+	SkipEncodings []string `mapstructure:"skip_encodings"`
+	// PlatformSkipEncodings: 按平台覆盖 SkipEncodings（平台 → 不解码的编码）
+	PlatformSkipEncodings map[string][]string `mapstructure:"platform_skip_encodings"`
+}
+
+// GatewayUpstreamMTLSConfig 上游 mTLS 客户端证书配置。
+// 证书与私钥只从文件加载（不入库、不经管理接口回显）；账号可通过 extra.upstream_mtls_profile
+// 指定证书配置名覆盖平台默认值，设为 "none" 表示不使用客户端证书。配置名不区分大小写。
+type GatewayUpstreamMTLSConfig struct {
+	// Profiles: 证书配置名 → 证书文件
+	Profiles map[string]GatewayUpstreamMTLSProfile `mapstructure:"profiles"`
+	// PlatformProfiles: 平台 → 默认证书配置名
+	PlatformProfiles map[string]string `mapstructure:"platform_profiles"`
+}
+
+// GatewayUpstreamMTLSProfile 一组客户端证书（PEM）。
+type GatewayUpstreamMTLSProfile struct {
+	// CertFile: 客户端证书链
+	CertFile string `mapstructure:"cert_file"`
+	// KeyFile: 客户端私钥（建议权限 0600）
+	KeyFile string `mapstructure:"key_file"`
+	// CAFile: 可选，校验上游服务端证书的 CA 包；为空使用系统根证书
+	CAFile string `mapstructure:"ca_file"`
+}
+
+// GatewayConversationTranscriptConfig 会话全文留存配置。
+// 全局开关关闭时，即使 API Key 开启了留存也不会写入；内容使用 TOTP 加密密钥 AES-GCM 加密后落库。
+type GatewayConversationTranscriptConfig struct {
+	// Enabled: 全局开关
+	Enabled bool `mapstructure:"enabled"`
+	// RetentionDays: 保留天数，0 表示永久保留
+	RetentionDays int `mapstructure:"retention_days"`
+	// MaxRequestBytes: 单条留存的请求体上限（字节），超出截断
+	MaxRequestBytes int `mapstructure:"max_request_bytes"`
+	// MaxResponseBytes: 单条留存的响应体上限（字节），超出截断
+	MaxResponseBytes int `mapstructure:"max_response_bytes"`
+}
+
+// GatewayUpstreamStreamCaptureConfig 上游原始响应（SSE 全文）留存配置。
+// 对管理员标记的 API Key 全量留存；CaptureOnError 开启时其余请求仅在失败时留存。
+// 内容上传到备份所用的 S3 兼容存储，索引落库，按保留期清理。
+type GatewayUpstreamStreamCaptureConfig struct {
+	// Enabled: 全局开关
+	Enabled bool `mapstructure:"enabled"`
+	// CaptureOnError: 未标记的 Key 在请求失败（客户端状态码 >= 400、上游报错或读流中断）时也留存
+	CaptureOnError bool `mapstructure:"capture_on_error"`
+	// MaxBytes: 单个请求留存的上游响应上限（字节，含所有 failover 尝试），超出截断
+	MaxBytes int `mapstructure:"max_bytes"`
+	// RetentionDays: 保留天数，0 表示永久保留
+	RetentionDays int `mapstructure:"retention_days"`
+	// Prefix: 对象存储 key 前缀
+	Prefix string `mapstructure:"prefix"`
+}
+
+// GatewayDNSConfig 上游 DNS 解析控制。
+// 静态映射与 DoH 作用于直连与连接 HTTP 代理本身；SOCKS 代理按解析模式决定目标域名在本地还是代理端解析。
+// 注意：主机名含 "."，与 viper 的键分隔符冲突，因此静态映射与代理模式使用列表而非 map。
+type GatewayDNSConfig struct {
+	// StaticHosts: 静态 host → IP 映射（优先于 DoH 与系统解析器）
+	StaticHosts []GatewayDNSStaticHost `mapstructure:"static_hosts"`
+	// DoHURL: DoH JSON 端点（如 https://cloudflare-dns.com/dns-query），为空使用系统解析器
+	DoHURL string `mapstructure:"doh_url"`
+	// DoHTimeoutSeconds: 单次 DoH 查询超时（秒）
+	DoHTimeoutSeconds int `mapstructure:"doh_timeout_seconds"`
+	// SOCKSResolution: SOCKS 代理默认解析模式 remote（代理端解析，默认）/ local（本地解析后以 IP 连接）
+	SOCKSResolution string `mapstructure:"socks_resolution"`
+	// ProxyResolution: 按代理覆盖解析模式
+	ProxyResolution []GatewayDNSProxyResolution `mapstructure:"proxy_resolution"`
+}
+
+// GatewayDNSStaticHost 单条静态解析映射。
+type GatewayDNSStaticHost struct {
+	Host string   `mapstructure:"host"`
+	IPs  []string `mapstructure:"ips"`
+}
+
+// GatewayDNSProxyResolution 单个代理的解析模式覆盖。
+type GatewayDNSProxyResolution struct {
+	// Proxy: 代理地址 host 或 host:port
+	Proxy string `mapstructure:"proxy"`
+	// Mode: remote / local
+	Mode string `mapstructure:"mode"`
 }
 
 // GatewayOpenAIHTTP2Config OpenAI HTTP 上游协议配置。
@@ -1240,6 +1738,24 @@ type GatewayUsageRecordConfig struct {
 	AutoScaleCheckIntervalSeconds int `mapstructure:"auto_scale_check_interval_seconds"`
 	// AutoScaleCooldownSeconds: 自动扩缩容冷却时间（秒）
 	AutoScaleCooldownSeconds int `mapstructure:"auto_scale_cooldown_seconds"`
+
+	// DeadLetter: 写库失败的使用量记录死信队列
+	DeadLetter GatewayUsageRecordDeadLetterConfig `mapstructure:"dead_letter"`
+}
+
+// GatewayUsageRecordDeadLetterConfig 使用量记录死信队列配置。
+// 计费/使用日志写库失败（如数据库不可用）时，载荷写入 Redis Stream，由恢复 worker 在数据库恢复后回放。
+type GatewayUsageRecordDeadLetterConfig struct {
+	// Enabled: 是否启用死信队列
+	Enabled bool `mapstructure:"enabled"`
+	// MaxEntries: 队列最大条目数（超出后淘汰最旧条目）
+	MaxEntries int `mapstructure:"max_entries"`
+	// ReplayIntervalSeconds: 回放检查间隔（秒）
+	ReplayIntervalSeconds int `mapstructure:"replay_interval_seconds"`
+	// ReplayBatchSize: 每轮最多回放条目数
+	ReplayBatchSize int `mapstructure:"replay_batch_size"`
+	// MaxAttempts: 数据库健康时单条目最多回放次数，超出后丢弃并记录告警日志
+	MaxAttempts int `mapstructure:"max_attempts"`
 }
 
 // TLSFingerprintConfig TLS指纹伪装配置
@@ -1364,6 +1880,79 @@ type DatabaseConfig struct {
 	// UserPlatformQuotaFlushBatchSize: flusher 单批最大条数
 	// 建议 ≤ 6000（单条 UPSERT 原子上限）
 	UserPlatformQuotaFlushBatchSize int `mapstructure:"user_platform_quota_flush_batch_size"`
+	// Degraded: 数据库短暂不可用时的降级运行配置
+	Degraded DatabaseDegradedConfig `mapstructure:"degraded"`
+	// ReadReplica: 只读副本配置，重型只读查询（使用记录列表、统计聚合）路由到副本
+	ReadReplica DatabaseReadReplicaConfig `mapstructure:"read_replica"`
+}
+
+// DatabaseReadReplicaConfig PostgreSQL 只读副本配置
+//
+// 启用后，管理后台仪表盘、使用记录列表与统计聚合等重型只读查询走副本；写入与一致性敏感的读取
+// （配额窗口统计、按 ID 读取等）始终走主库。副本复制延迟超过 MaxLagSeconds 或探测失败时自动回退主库。
+// User/Password/DBName/SSLMode/Port 留空时沿用主库配置。
+type DatabaseReadReplicaConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	DBName   string `mapstructure:"dbname"`
+	SSLMode  string `mapstructure:"sslmode"`
+	// MaxOpenConns / MaxIdleConns: 副本连接池上限
+	MaxOpenConns int `mapstructure:"max_open_conns"`
+	MaxIdleConns int `mapstructure:"max_idle_conns"`
+	// MaxLagSeconds: 允许的最大复制延迟（秒），超过后回退主库
+	MaxLagSeconds int `mapstructure:"max_lag_seconds"`
+	// LagCheckIntervalSeconds: 复制延迟探测间隔（秒）
+	LagCheckIntervalSeconds int `mapstructure:"lag_check_interval_seconds"`
+}
+
+// Connection 返回副本的连接配置，未设置的字段沿用主库。
+func (r *DatabaseReadReplicaConfig) Connection(primary DatabaseConfig) DatabaseConfig {
+	conn := DatabaseConfig{
+		Host:     r.Host,
+		Port:     r.Port,
+		User:     r.User,
+		Password: r.Password,
+		DBName:   r.DBName,
+		SSLMode:  r.SSLMode,
+	}
+	if conn.Port == 0 {
+		conn.Port = primary.Port
+	}
+	if conn.User == "" {
+		conn.User = primary.User
+		if conn.Password == "" {
+			conn.Password = primary.Password
+		}
+	}
+	if conn.DBName == "" {
+		conn.DBName = primary.DBName
+	}
+	if conn.SSLMode == "" {
+		conn.SSLMode = primary.SSLMode
+	}
+	return conn
+}
+
+// DatabaseDegradedConfig 数据库降级模式配置
+//
+// 数据库连续探测失败后进入降级模式：网关继续使用缓存的 API Key 认证快照与系统设置提供服务，
+// 使用量写入直接进入死信队列，/healthz 报告 degraded 状态。
+type DatabaseDegradedConfig struct {
+	// Enabled: 是否启用降级模式
+	Enabled bool `mapstructure:"enabled"`
+	// ProbeIntervalSeconds: 数据库健康探测间隔（秒）
+	ProbeIntervalSeconds int `mapstructure:"probe_interval_seconds"`
+	// ProbeTimeoutSeconds: 单次探测超时（秒）
+	ProbeTimeoutSeconds int `mapstructure:"probe_timeout_seconds"`
+	// FailureThreshold: 连续失败多少次进入降级模式
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// RecoveryThreshold: 连续成功多少次退出降级模式
+	RecoveryThreshold int `mapstructure:"recovery_threshold"`
+	// StaleTTLSeconds: 过期缓存（认证快照、系统设置）在数据库不可用时最长可继续使用的时间（秒）
+	StaleTTLSeconds int `mapstructure:"stale_ttl_seconds"`
 }
 
 func (d *DatabaseConfig) DSN() string {
@@ -1418,6 +2007,36 @@ type RedisConfig struct {
 	MinIdleConns int `mapstructure:"min_idle_conns"`
 	// EnableTLS: 是否启用 TLS/SSL 连接
 	EnableTLS bool `mapstructure:"enable_tls"`
+
+	// Mode: 部署拓扑，standalone（默认，使用 host/port）、sentinel 或 cluster
+	Mode string `mapstructure:"mode"`
+	// Addrs: sentinel 模式为哨兵地址列表，cluster 模式为集群种子节点列表（host:port）
+	Addrs []string `mapstructure:"addrs"`
+	// MasterName: sentinel 模式下的主节点名称
+	MasterName string `mapstructure:"master_name"`
+	// SentinelPassword: 哨兵自身的认证密码（与数据节点密码不同时设置）
+	SentinelPassword string `mapstructure:"sentinel_password"`
+	// ReadFromReplicas: 热点只读路径（如 OAuth access token 缓存读取）路由到从节点；
+	// 仅 sentinel / cluster 模式生效，主从复制延迟期间可能读到刚被替换的旧值
+	ReadFromReplicas bool `mapstructure:"read_from_replicas"`
+	// HealthCheckIntervalSeconds: 周期性 PING 探测间隔（秒），0 表示关闭；
+	// 探测结果记录拓扑切换日志并通过 /healthz 暴露
+	HealthCheckIntervalSeconds int `mapstructure:"health_check_interval_seconds"`
+}
+
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+// NormalizedMode 返回规范化后的部署模式，空值视为 standalone。
+func (r *RedisConfig) NormalizedMode() string {
+	mode := strings.ToLower(strings.TrimSpace(r.Mode))
+	if mode == "" {
+		return RedisModeStandalone
+	}
+	return mode
 }
 
 func (r *RedisConfig) Address() string {
@@ -1594,6 +2213,181 @@ type UsageCleanupConfig struct {
 	TaskTimeoutSeconds int `mapstructure:"task_timeout_seconds"`
 }
 
+// UsageArchiveConfig 使用记录分层归档配置。
+// 原始 usage_logs 保留 ArchiveAfterDays 天，之后按自然月导出为 gzip 压缩的 JSONL 写入对象存储（复用备份的 S3 配置）
+// 并删除原始行；记录创建超过 ArchiveRetentionDays 天后删除归档对象。汇总口径由仪表盘预聚合表保留。
+type UsageArchiveConfig struct {
+	// Enabled: 是否启用归档
+	Enabled bool `mapstructure:"enabled"`
+	// ArchiveAfterDays: 原始记录保留天数，超过后归档并删除
+	ArchiveAfterDays int `mapstructure:"archive_after_days"`
+	// ArchiveRetentionDays: 归档对象保留天数（按记录创建时间计算，须大于 ArchiveAfterDays），0 表示永久保留
+	ArchiveRetentionDays int `mapstructure:"archive_retention_days"`
+	// BatchSize: 单个归档对象的最大记录数
+	BatchSize int `mapstructure:"batch_size"`
+	// MaxBatchesPerRun: 每轮最多写入的归档对象数，剩余记录留到下一轮
+	MaxBatchesPerRun int `mapstructure:"max_batches_per_run"`
+	// Prefix: 对象存储 key 前缀
+	Prefix string `mapstructure:"prefix"`
+}
+
+// StartupDiagnosticsConfig 启动自检配置
+type StartupDiagnosticsConfig struct {
+	// Enabled: 是否在启动时执行自检
+	Enabled bool `mapstructure:"enabled"`
+	// FailFast: 关键检查项失败时是否终止启动
+	FailFast bool `mapstructure:"fail_fast"`
+	// ProxySampleSize: 抽样探测的代理数量（0 表示不探测代理）
+	ProxySampleSize int `mapstructure:"proxy_sample_size"`
+	// TimeoutSeconds: 单次自检总超时（秒）
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+}
+
+// WarmStartConfig 启动预热配置
+type WarmStartConfig struct {
+	// Enabled: 是否在开始接收流量前预热热点数据
+	Enabled bool `mapstructure:"enabled"`
+	// TimeoutSeconds: 预热总超时（秒），超时后直接开始服务
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// APIKeyLimit: 预热认证缓存的 API Key 数量上限（0 表示不预热 API Key）
+	APIKeyLimit int `mapstructure:"api_key_limit"`
+	// APIKeyLookbackHours: 仅预热最近多少小时内使用过的 API Key
+	APIKeyLookbackHours int `mapstructure:"api_key_lookback_hours"`
+}
+
+// AccountRenewalConfig 账号续费提醒配置
+type AccountRenewalConfig struct {
+	// ReminderDays: 续费日期前多少天写入运维告警（0 表示关闭提醒）
+	ReminderDays int `mapstructure:"reminder_days"`
+	// CheckIntervalMinutes: 扫描间隔（分钟）
+	CheckIntervalMinutes int `mapstructure:"check_interval_minutes"`
+}
+
+// AccountSnapshotConfig 账号状态快照配置
+type AccountSnapshotConfig struct {
+	// IntervalMinutes: 快照采集间隔（分钟，0 表示关闭）；状态未变化的账号不会写入新快照
+	IntervalMinutes int `mapstructure:"interval_minutes"`
+	// RetentionDays: 快照保留天数
+	RetentionDays int `mapstructure:"retention_days"`
+}
+
+// CredentialSyncConfig 账号凭据外部密钥库同步配置
+type CredentialSyncConfig struct {
+	// Enabled: 是否启用外部密钥库同步（关闭时管理接口仍可维护映射，但不会拉取）
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalMinutes: 定时同步间隔（分钟，0 表示仅手动触发）
+	IntervalMinutes int `mapstructure:"interval_minutes"`
+	// TimeoutSeconds: 单次读取密钥的超时（秒）
+	TimeoutSeconds int                       `mapstructure:"timeout_seconds"`
+	Vault          CredentialSyncVaultConfig `mapstructure:"vault"`
+	AWS            CredentialSyncAWSConfig   `mapstructure:"aws"`
+}
+
+// CredentialSyncVaultConfig HashiCorp Vault KV v2 连接配置
+type CredentialSyncVaultConfig struct {
+	// Address: Vault 地址（如 https://vault.example.com:8200），为空表示不启用 Vault
+	Address   string `mapstructure:"address"`
+	Token     string `mapstructure:"token"`
+	Namespace string `mapstructure:"namespace"`
+	// Mount: KV v2 引擎挂载路径
+	Mount string `mapstructure:"mount"`
+}
+
+// CredentialSyncAWSConfig AWS Secrets Manager 连接配置
+type CredentialSyncAWSConfig struct {
+	// Region: 区域，为空表示不启用 AWS Secrets Manager
+	Region string `mapstructure:"region"`
+	// AccessKeyID/SecretAccessKey: 留空时使用默认凭据链（环境变量、实例角色等）
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	// Endpoint: 自定义端点（兼容 LocalStack 等），为空使用官方端点
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// UserNotificationConfig 终端用户站内通知配置
+type UserNotificationConfig struct {
+	// Enabled: 是否生成站内通知（关闭后不再写入新通知，已有通知仍可查询）
+	Enabled bool `mapstructure:"enabled"`
+	// RetentionDays: 通知保留天数，过期通知由后台任务清理
+	RetentionDays int `mapstructure:"retention_days"`
+	// APIKeyExpiryNoticeHours: API Key 到期前多少小时提醒（0 表示不提醒）
+	APIKeyExpiryNoticeHours int `mapstructure:"api_key_expiry_notice_hours"`
+	// ScanIntervalMinutes: 到期扫描与过期清理的执行间隔（分钟）
+	ScanIntervalMinutes int `mapstructure:"scan_interval_minutes"`
+}
+
+// ModelAliasLearningConfig 模型别名学习配置：
+// 上游返回 model not found 时记录请求的模型名与相近的已知模型（别名建议），
+// 管理员批准后可自动将请求改写为建议的模型。
+type ModelAliasLearningConfig struct {
+	// Enabled: 是否记录上游拒绝的模型名与别名建议
+	Enabled bool `mapstructure:"enabled"`
+	// AutoApply: 是否对请求自动应用管理员已批准的别名改写
+	AutoApply bool `mapstructure:"auto_apply"`
+	// MinConfidence: 生成别名建议所需的最低置信度（0-1），低于该值仅记为未知模型
+	MinConfidence float64 `mapstructure:"min_confidence"`
+	// FlushIntervalSeconds: 观测数据落库与已批准别名刷新的间隔（秒）
+	FlushIntervalSeconds int `mapstructure:"flush_interval_seconds"`
+}
+
+// CostAnomalyConfig 账号上游成本异常检测配置：
+// 按账号 + 模型对比近期与基线的单请求成本，偏离过大时写入运维告警，可选暂停调度等待审核。
+type CostAnomalyConfig struct {
+	// Enabled: 是否启用成本异常检测
+	Enabled bool `mapstructure:"enabled"`
+	// CheckIntervalMinutes: 检测间隔（分钟）
+	CheckIntervalMinutes int `mapstructure:"check_interval_minutes"`
+	// RecentWindowMinutes: 近期窗口（分钟）
+	RecentWindowMinutes int `mapstructure:"recent_window_minutes"`
+	// BaselineDays: 基线窗口（天，不含近期窗口）
+	BaselineDays int `mapstructure:"baseline_days"`
+	// MinRecentRequests: 近期窗口内参与检测的最少请求数
+	MinRecentRequests int `mapstructure:"min_recent_requests"`
+	// MinBaselineRequests: 基线窗口内参与检测的最少请求数（新账号 / 新模型没有可比基线）
+	MinBaselineRequests int `mapstructure:"min_baseline_requests"`
+	// DeviationRatio: 近期平均单请求成本达到基线的多少倍视为异常（> 1）
+	DeviationRatio float64 `mapstructure:"deviation_ratio"`
+	// ThrottleEnabled: 检测到异常时是否暂停账号调度，直到管理员审核或超过 ThrottleMinutes
+	ThrottleEnabled bool `mapstructure:"throttle_enabled"`
+	// ThrottleMinutes: 暂停调度的最长时长（分钟）
+	ThrottleMinutes int `mapstructure:"throttle_minutes"`
+}
+
+// AccountSmokeTestConfig 账号夜间冒烟测试配置：
+// 按计划对每个启用账号发起一次最小成本测试请求，记录成功率与延迟趋势，成功率明显下滑的账号被标记。
+type AccountSmokeTestConfig struct {
+	// Enabled: 是否启用夜间冒烟测试
+	Enabled bool `mapstructure:"enabled"`
+	// Schedule: 执行计划（5 段 cron，按 timezone 解释）
+	Schedule string `mapstructure:"schedule"`
+	// Concurrency: 同时测试的账号数
+	Concurrency int `mapstructure:"concurrency"`
+	// TimeoutSeconds: 单个账号测试的超时（秒）
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// RetentionDays: 测试结果保留天数
+	RetentionDays int `mapstructure:"retention_days"`
+	// RecentDays: 判断下滑的近期窗口（天）
+	RecentDays int `mapstructure:"recent_days"`
+	// BaselineDays: 对比用的基线窗口（天，不含近期窗口）
+	BaselineDays int `mapstructure:"baseline_days"`
+	// MinRuns: 近期与基线窗口各自参与判断的最少测试次数
+	MinRuns int `mapstructure:"min_runs"`
+	// DegradationThreshold: 近期成功率比基线低多少（0-1 的绝对值）视为下滑
+	DegradationThreshold float64 `mapstructure:"degradation_threshold"`
+}
+
+// AutoscalingMetricsConfig 弹性伸缩信号导出配置（GET /metrics/autoscaling，Prometheus 文本格式，供 HPA/KEDA 采集）
+type AutoscalingMetricsConfig struct {
+	// Enabled: 是否注册导出端点
+	Enabled bool `mapstructure:"enabled"`
+	// Token: 采集方需携带的 Bearer Token（为空表示不校验，建议仅在内网暴露时留空）
+	Token string `mapstructure:"token"`
+	// QueueWaitWindowSeconds: 计算槽位排队等待 p95 的滑动窗口（秒）
+	QueueWaitWindowSeconds int `mapstructure:"queue_wait_window_seconds"`
+	// SlotStatsCacheSeconds: 账号槽位占用统计的缓存时间（秒），避免每次采集都查询全部账号
+	SlotStatsCacheSeconds int `mapstructure:"slot_stats_cache_seconds"`
+}
+
 func NormalizeRunMode(value string) string {
 	normalized := strings.ToLower(strings.TrimSpace(value))
 	switch normalized {
@@ -1720,6 +2514,7 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.OIDC.UsePKCEExplicit = hasExplicitConfigOrEnv("oidc_connect.use_pkce", "OIDC_CONNECT_USE_PKCE")
 	cfg.OIDC.ValidateIDTokenExplicit = hasExplicitConfigOrEnv("oidc_connect.validate_id_token", "OIDC_CONNECT_VALIDATE_ID_TOKEN")
 	cfg.Dashboard.KeyPrefix = strings.TrimSpace(cfg.Dashboard.KeyPrefix)
+	cfg.TokenCache.KeyPrefix = strings.TrimSpace(cfg.TokenCache.KeyPrefix)
 	cfg.CORS.AllowedOrigins = normalizeStringSlice(cfg.CORS.AllowedOrigins)
 	cfg.Security.ResponseHeaders.AdditionalAllowed = normalizeStringSlice(cfg.Security.ResponseHeaders.AdditionalAllowed)
 	cfg.Security.ResponseHeaders.ForceRemove = normalizeStringSlice(cfg.Security.ResponseHeaders.ForceRemove)
@@ -1736,6 +2531,9 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.Log.Environment = strings.TrimSpace(cfg.Log.Environment)
 	cfg.Log.StacktraceLevel = strings.ToLower(strings.TrimSpace(cfg.Log.StacktraceLevel))
 	cfg.Log.Output.FilePath = strings.TrimSpace(cfg.Log.Output.FilePath)
+	cfg.Log.Access.Format = strings.ToLower(strings.TrimSpace(cfg.Log.Access.Format))
+	cfg.Log.Access.FilePath = strings.TrimSpace(cfg.Log.Access.FilePath)
+	cfg.Log.Access.RotateInterval = strings.ToLower(strings.TrimSpace(cfg.Log.Access.RotateInterval))
 	cfg.Gateway.ForcedCodexInstructionsTemplateFile = strings.TrimSpace(cfg.Gateway.ForcedCodexInstructionsTemplateFile)
 	if cfg.Gateway.ForcedCodexInstructionsTemplateFile != "" {
 		content, err := os.ReadFile(cfg.Gateway.ForcedCodexInstructionsTemplateFile)
@@ -1772,6 +2570,9 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	} else {
 		cfg.Totp.EncryptionKeyConfigured = true
 	}
+	if cfg.TokenCache.Encrypt && !cfg.Totp.EncryptionKeyConfigured {
+		slog.Warn("token_cache.encrypt uses an auto-generated key; cached tokens will not survive restarts or be shared across instances. Set totp.encryption_key.")
+	}
 
 	originalJWTSecret := cfg.JWT.Secret
 	if allowMissingJWTSecret && originalJWTSecret == "" {
@@ -1820,6 +2621,18 @@ func setDefaults() {
 	viper.SetDefault("server.max_header_bytes", 64*1024)
 	viper.SetDefault("server.idle_timeout", 120) // 120秒空闲超时
 	viper.SetDefault("server.max_request_body_size", int64(256*1024*1024))
+	viper.SetDefault("server.compression.enabled", false)
+	viper.SetDefault("server.compression.min_size_bytes", 1024)
+	viper.SetDefault("server.compression.content_types", []string{
+		"application/json",
+		"application/javascript",
+		"text/javascript",
+		"text/plain",
+		"text/csv",
+		"text/html",
+		"text/css",
+		"image/svg+xml",
+	})
 	// H2C 默认配置
 	viper.SetDefault("server.h2c.enabled", false)
 	viper.SetDefault("server.h2c.max_concurrent_streams", uint32(50))      // 50 个并发流
@@ -1846,6 +2659,15 @@ func setDefaults() {
 	viper.SetDefault("log.sampling.enabled", false)
 	viper.SetDefault("log.sampling.initial", 100)
 	viper.SetDefault("log.sampling.thereafter", 100)
+	viper.SetDefault("log.access.enabled", false)
+	viper.SetDefault("log.access.format", "combined")
+	viper.SetDefault("log.access.file_path", "")
+	viper.SetDefault("log.access.rotation.max_size_mb", 100)
+	viper.SetDefault("log.access.rotation.max_backups", 14)
+	viper.SetDefault("log.access.rotation.max_age_days", 14)
+	viper.SetDefault("log.access.rotation.compress", true)
+	viper.SetDefault("log.access.rotation.local_time", true)
+	viper.SetDefault("log.access.rotate_interval", "daily")
 
 	// CORS
 	viper.SetDefault("cors.allowed_origins", []string{})
@@ -1977,6 +2799,23 @@ func setDefaults() {
 	viper.SetDefault("database.user_platform_quota_flusher_enabled", false)
 	viper.SetDefault("database.user_platform_quota_flush_interval_ms", 2000)
 	viper.SetDefault("database.user_platform_quota_flush_batch_size", 1000)
+	viper.SetDefault("database.degraded.enabled", true)
+	viper.SetDefault("database.degraded.probe_interval_seconds", 5)
+	viper.SetDefault("database.degraded.probe_timeout_seconds", 2)
+	viper.SetDefault("database.degraded.failure_threshold", 3)
+	viper.SetDefault("database.degraded.recovery_threshold", 2)
+	viper.SetDefault("database.degraded.stale_ttl_seconds", 3600)
+	viper.SetDefault("database.read_replica.enabled", false)
+	viper.SetDefault("database.read_replica.host", "")
+	viper.SetDefault("database.read_replica.port", 0)
+	viper.SetDefault("database.read_replica.user", "")
+	viper.SetDefault("database.read_replica.password", "")
+	viper.SetDefault("database.read_replica.dbname", "")
+	viper.SetDefault("database.read_replica.sslmode", "")
+	viper.SetDefault("database.read_replica.max_open_conns", 64)
+	viper.SetDefault("database.read_replica.max_idle_conns", 16)
+	viper.SetDefault("database.read_replica.max_lag_seconds", 10)
+	viper.SetDefault("database.read_replica.lag_check_interval_seconds", 5)
 
 	// Redis
 	viper.SetDefault("redis.host", "localhost")
@@ -1989,6 +2828,12 @@ func setDefaults() {
 	viper.SetDefault("redis.pool_size", 1024)
 	viper.SetDefault("redis.min_idle_conns", 128)
 	viper.SetDefault("redis.enable_tls", false)
+	viper.SetDefault("redis.mode", RedisModeStandalone)
+	viper.SetDefault("redis.addrs", []string{})
+	viper.SetDefault("redis.master_name", "")
+	viper.SetDefault("redis.sentinel_password", "")
+	viper.SetDefault("redis.read_from_replicas", false)
+	viper.SetDefault("redis.health_check_interval_seconds", 10)
 
 	// Batch Image queue
 	viper.SetDefault("batch_image.enabled", false)
@@ -2148,6 +2993,87 @@ func setDefaults() {
 	viper.SetDefault("usage_cleanup.worker_interval_seconds", 10)
 	viper.SetDefault("usage_cleanup.task_timeout_seconds", 1800)
 
+	// Usage archive
+	viper.SetDefault("usage_archive.enabled", false)
+	viper.SetDefault("usage_archive.archive_after_days", 60)
+	viper.SetDefault("usage_archive.archive_retention_days", 730)
+	viper.SetDefault("usage_archive.batch_size", 50000)
+	viper.SetDefault("usage_archive.max_batches_per_run", 20)
+	viper.SetDefault("usage_archive.prefix", "usage-archives")
+
+	// Startup diagnostics
+	viper.SetDefault("startup_diagnostics.enabled", true)
+	viper.SetDefault("startup_diagnostics.fail_fast", false)
+	viper.SetDefault("startup_diagnostics.proxy_sample_size", 3)
+	viper.SetDefault("startup_diagnostics.timeout_seconds", 30)
+
+	// Warm start
+	viper.SetDefault("warm_start.enabled", true)
+	viper.SetDefault("warm_start.timeout_seconds", 20)
+	viper.SetDefault("warm_start.api_key_limit", 2000)
+	viper.SetDefault("warm_start.api_key_lookback_hours", 24)
+
+	// Account renewal reminders
+	viper.SetDefault("account_renewal.reminder_days", 7)
+	viper.SetDefault("account_renewal.check_interval_minutes", 60)
+
+	// Account state snapshots
+	viper.SetDefault("account_snapshot.interval_minutes", 15)
+	viper.SetDefault("account_snapshot.retention_days", 90)
+
+	// Account credential sync from external secret stores
+	viper.SetDefault("credential_sync.enabled", false)
+	viper.SetDefault("credential_sync.interval_minutes", 30)
+	viper.SetDefault("credential_sync.timeout_seconds", 15)
+	viper.SetDefault("credential_sync.vault.address", "")
+	viper.SetDefault("credential_sync.vault.token", "")
+	viper.SetDefault("credential_sync.vault.namespace", "")
+	viper.SetDefault("credential_sync.vault.mount", "secret")
+	viper.SetDefault("credential_sync.aws.region", "")
+	viper.SetDefault("credential_sync.aws.access_key_id", "")
+	viper.SetDefault("credential_sync.aws.secret_access_key", "")
+	viper.SetDefault("credential_sync.aws.endpoint", "")
+
+	// User notification
+	viper.SetDefault("user_notification.enabled", true)
+	viper.SetDefault("user_notification.retention_days", 90)
+	viper.SetDefault("user_notification.api_key_expiry_notice_hours", 72)
+	viper.SetDefault("user_notification.scan_interval_minutes", 60)
+
+	// Model alias learning
+	viper.SetDefault("model_alias_learning.enabled", true)
+	viper.SetDefault("model_alias_learning.auto_apply", false)
+	viper.SetDefault("model_alias_learning.min_confidence", 0.8)
+	viper.SetDefault("model_alias_learning.flush_interval_seconds", 60)
+
+	// Cost anomaly detection
+	viper.SetDefault("cost_anomaly.enabled", false)
+	viper.SetDefault("cost_anomaly.check_interval_minutes", 10)
+	viper.SetDefault("cost_anomaly.recent_window_minutes", 60)
+	viper.SetDefault("cost_anomaly.baseline_days", 7)
+	viper.SetDefault("cost_anomaly.min_recent_requests", 20)
+	viper.SetDefault("cost_anomaly.min_baseline_requests", 100)
+	viper.SetDefault("cost_anomaly.deviation_ratio", 3.0)
+	viper.SetDefault("cost_anomaly.throttle_enabled", false)
+	viper.SetDefault("cost_anomaly.throttle_minutes", 240)
+
+	// Account smoke tests
+	viper.SetDefault("account_smoke_test.enabled", false)
+	viper.SetDefault("account_smoke_test.schedule", "0 3 * * *")
+	viper.SetDefault("account_smoke_test.concurrency", 4)
+	viper.SetDefault("account_smoke_test.timeout_seconds", 60)
+	viper.SetDefault("account_smoke_test.retention_days", 90)
+	viper.SetDefault("account_smoke_test.recent_days", 3)
+	viper.SetDefault("account_smoke_test.baseline_days", 14)
+	viper.SetDefault("account_smoke_test.min_runs", 3)
+	viper.SetDefault("account_smoke_test.degradation_threshold", 0.3)
+
+	// Autoscaling metrics
+	viper.SetDefault("autoscaling_metrics.enabled", false)
+	viper.SetDefault("autoscaling_metrics.token", "")
+	viper.SetDefault("autoscaling_metrics.queue_wait_window_seconds", 300)
+	viper.SetDefault("autoscaling_metrics.slot_stats_cache_seconds", 10)
+
 	// Idempotency
 	viper.SetDefault("idempotency.observe_only", true)
 	viper.SetDefault("idempotency.default_ttl_seconds", 86400)
@@ -2246,6 +3172,7 @@ func setDefaults() {
 	viper.SetDefault("gateway.max_body_size", int64(256*1024*1024))
 	viper.SetDefault("gateway.text_max_body_size", int64(32*1024*1024))
 	viper.SetDefault("gateway.upstream_response_read_max_bytes", DefaultUpstreamResponseReadMaxBytes)
+	viper.SetDefault("gateway.upstream_stream_max_bytes", int64(0))
 	viper.SetDefault("gateway.proxy_probe_response_read_max_bytes", int64(1024*1024))
 	viper.SetDefault("gateway.gemini_debug_response_headers", false)
 	viper.SetDefault("gateway.connection_pool_isolation", ConnectionPoolIsolationAccountProxy)
@@ -2259,10 +3186,15 @@ func setDefaults() {
 	viper.SetDefault("gateway.concurrency_slot_ttl_minutes", 30) // 并发槽位过期时间（支持超长请求）
 	viper.SetDefault("gateway.stream_data_interval_timeout", 180)
 	viper.SetDefault("gateway.stream_keepalive_interval", 10)
+	viper.SetDefault("gateway.client_disconnect_upstream_grace_seconds", 60)
+	viper.SetDefault("gateway.stream_client_write_timeout", 30)
+	viper.SetDefault("gateway.stream_client_max_lag_bytes", 8*1024*1024)
 	viper.SetDefault("gateway.image_stream_data_interval_timeout", 900)
 	viper.SetDefault("gateway.image_stream_keepalive_interval", 10)
 	viper.SetDefault("gateway.image_nonstream_keepalive_interval", 0)
 	viper.SetDefault("gateway.max_line_size", 500*1024*1024)
+	viper.SetDefault("gateway.sse_oversize_line.mode", SSEOversizeLineModeError)
+	viper.SetDefault("gateway.sse_oversize_line.keep_bytes", 64*1024)
 	viper.SetDefault("gateway.scheduling.sticky_session_max_waiting", 3)
 	viper.SetDefault("gateway.scheduling.sticky_session_wait_timeout", 120*time.Second)
 	viper.SetDefault("gateway.scheduling.fallback_wait_timeout", 30*time.Second)
@@ -2300,6 +3232,11 @@ func setDefaults() {
 	viper.SetDefault("gateway.usage_record.auto_scale_down_step", 16)
 	viper.SetDefault("gateway.usage_record.auto_scale_check_interval_seconds", 3)
 	viper.SetDefault("gateway.usage_record.auto_scale_cooldown_seconds", 10)
+	viper.SetDefault("gateway.usage_record.dead_letter.enabled", true)
+	viper.SetDefault("gateway.usage_record.dead_letter.max_entries", 100000)
+	viper.SetDefault("gateway.usage_record.dead_letter.replay_interval_seconds", 30)
+	viper.SetDefault("gateway.usage_record.dead_letter.replay_batch_size", 100)
+	viper.SetDefault("gateway.usage_record.dead_letter.max_attempts", 10)
 	viper.SetDefault("gateway.user_group_rate_cache_ttl_seconds", 30)
 	viper.SetDefault("gateway.models_list_cache_ttl_seconds", 15)
 	// TLS指纹伪装配置（默认关闭，需要账号级别单独启用）
@@ -2310,6 +3247,84 @@ func setDefaults() {
 	viper.SetDefault("gateway.user_message_queue.min_delay_ms", 200)
 	viper.SetDefault("gateway.user_message_queue.max_delay_ms", 2000)
 	viper.SetDefault("gateway.user_message_queue.cleanup_interval_seconds", 60)
+	// 超长对话截断默认关闭
+	viper.SetDefault("gateway.conversation_truncation.enabled", false)
+	viper.SetDefault("gateway.conversation_truncation.max_input_tokens", 0)
+	viper.SetDefault("gateway.conversation_truncation.preserve_recent_turns", 4)
+	viper.SetDefault("gateway.max_tokens.enabled", false)
+	viper.SetDefault("gateway.max_tokens.catalog_maximum_fallback", false)
+	viper.SetDefault("gateway.tool_limits.enabled", false)
+	viper.SetDefault("gateway.tool_limits.description_policy", ToolDescriptionPolicyReject)
+	viper.SetDefault("gateway.conversation_transcript.enabled", false)
+	viper.SetDefault("gateway.conversation_transcript.retention_days", 30)
+	viper.SetDefault("gateway.conversation_transcript.max_request_bytes", 4*1024*1024)
+	viper.SetDefault("gateway.conversation_transcript.max_response_bytes", 2*1024*1024)
+	viper.SetDefault("gateway.upstream_stream_capture.enabled", false)
+	viper.SetDefault("gateway.upstream_stream_capture.capture_on_error", true)
+	viper.SetDefault("gateway.upstream_stream_capture.max_bytes", 2*1024*1024)
+	viper.SetDefault("gateway.upstream_stream_capture.retention_days", 30)
+	viper.SetDefault("gateway.upstream_stream_capture.prefix", "stream-captures")
+	viper.SetDefault("gateway.dns.static_hosts", []GatewayDNSStaticHost{})
+	viper.SetDefault("gateway.dns.doh_url", "")
+	viper.SetDefault("gateway.dns.doh_timeout_seconds", 5)
+	viper.SetDefault("gateway.dns.socks_resolution", DNSResolutionRemote)
+	viper.SetDefault("gateway.dns.proxy_resolution", []GatewayDNSProxyResolution{})
+	viper.SetDefault("gateway.request_mirror.enabled", false)
+	viper.SetDefault("gateway.request_mirror.endpoint", "")
+	viper.SetDefault("gateway.request_mirror.auth_token", "")
+	viper.SetDefault("gateway.request_mirror.signing_secret", "")
+	viper.SetDefault("gateway.request_mirror.sample_rate", 0.01)
+	viper.SetDefault("gateway.request_mirror.include_bodies", false)
+	viper.SetDefault("gateway.request_mirror.max_body_bytes", 256*1024)
+	viper.SetDefault("gateway.request_mirror.timeout_seconds", 5)
+	viper.SetDefault("gateway.request_mirror.worker_count", 8)
+	viper.SetDefault("gateway.request_mirror.queue_size", 1024)
+	viper.SetDefault("gateway.proxy_latency_routing.enabled", false)
+	viper.SetDefault("gateway.proxy_latency_routing.probe_interval_seconds", 60)
+	viper.SetDefault("gateway.proxy_latency_routing.probe_timeout_seconds", 5)
+	viper.SetDefault("gateway.proxy_latency_routing.refresh_interval_seconds", 300)
+	viper.SetDefault("gateway.proxy_latency_routing.switch_threshold_percent", 20)
+	viper.SetDefault("gateway.proxy_latency_routing.min_improvement_ms", 30)
+	viper.SetDefault("gateway.proxy_latency_routing.min_hold_seconds", 300)
+	viper.SetDefault("gateway.upstream_decompression.skip_encodings", []string{})
+	viper.SetDefault("gateway.admission_control.enabled", false)
+	viper.SetDefault("gateway.admission_control.max_waiting_global", 2000)
+	viper.SetDefault("gateway.admission_control.max_waiting_per_group", 500)
+	viper.SetDefault("gateway.admission_control.shed_wait_p95_ms", 15000)
+	viper.SetDefault("gateway.admission_control.shed_window_seconds", 60)
+	viper.SetDefault("gateway.admission_control.shed_min_samples", 20)
+	viper.SetDefault("gateway.admission_control.retry_after_seconds", 5)
+	viper.SetDefault("gateway.slot_lease.enabled", false)
+	viper.SetDefault("gateway.slot_lease.ttl_seconds", 90)
+	viper.SetDefault("gateway.slot_lease.heartbeat_interval_seconds", 30)
+	viper.SetDefault("gateway.sse_resume.enabled", false)
+	viper.SetDefault("gateway.sse_resume.buffer_events", 2000)
+	viper.SetDefault("gateway.sse_resume.ttl_seconds", 300)
+	viper.SetDefault("gateway.sse_resume.tail_idle_timeout_seconds", 60)
+	viper.SetDefault("gateway.sse_resume.disconnect_grace_seconds", 60)
+	viper.SetDefault("gateway.sse_websocket_bridge.enabled", false)
+	viper.SetDefault("gateway.sse_websocket_bridge.first_message_timeout_seconds", 30)
+	viper.SetDefault("gateway.sse_websocket_bridge.write_timeout_seconds", 30)
+	viper.SetDefault("gateway.error_translation.enabled", false)
+	viper.SetDefault("gateway.error_translation.default_locale", "en")
+	viper.SetDefault("gateway.request_parse_shadow.enabled", false)
+	viper.SetDefault("gateway.request_parse_shadow.sample_rate", 0.01)
+	viper.SetDefault("gateway.policy_plugins.enabled", false)
+	viper.SetDefault("gateway.policy_plugins.builtins", []string{})
+	viper.SetDefault("gateway.policy_plugins.paths", []string{})
+	viper.SetDefault("gateway.policy_plugins.timeout_ms", 200)
+	viper.SetDefault("gateway.policy_plugins.fail_open", true)
+	viper.SetDefault("gateway.account_warmup.enabled", false)
+	viper.SetDefault("gateway.account_warmup.window_hours", 48)
+	viper.SetDefault("gateway.account_warmup.initial_share", 0.1)
+	viper.SetDefault("gateway.content_policy_cooldown.enabled", false)
+	viper.SetDefault("gateway.content_policy_cooldown.scope", ContentPolicyCooldownScopeUser)
+	viper.SetDefault("gateway.content_policy_cooldown.cooldown_seconds", 300)
+	viper.SetDefault("gateway.content_policy_cooldown.window_seconds", 3600)
+	viper.SetDefault("gateway.content_policy_cooldown.account_threshold", 3)
+	viper.SetDefault("gateway.agent_session_affinity.enabled", false)
+	viper.SetDefault("gateway.agent_session_affinity.ttl_seconds", 14400)
+	viper.SetDefault("gateway.agent_session_affinity.release_on_window_warning", true)
 
 	viper.SetDefault("gateway.tls_fingerprint.enabled", true)
 	viper.SetDefault("concurrency.ping_interval", 10)
@@ -2327,6 +3342,11 @@ func setDefaults() {
 	viper.SetDefault("token_refresh.attempt_timeout_seconds", 15)
 	viper.SetDefault("token_refresh.cycle_timeout_seconds", 240)
 
+	// Token cache
+	viper.SetDefault("token_cache.encrypt", false)
+	viper.SetDefault("token_cache.key_prefix", "")
+	viper.SetDefault("token_cache.ttl_jitter_percent", 10)
+
 	// Gemini OAuth - configure via environment variables or config file
 	// GEMINI_OAUTH_CLIENT_ID and GEMINI_OAUTH_CLIENT_SECRET
 	// Default: uses Gemini CLI public credentials (set via environment)
@@ -2334,6 +3354,7 @@ func setDefaults() {
 	viper.SetDefault("gemini.oauth.client_secret", "")
 	viper.SetDefault("gemini.oauth.scopes", "")
 	viper.SetDefault("gemini.quota.policy", "")
+	viper.SetDefault("gemini.quota.probe_interval_minutes", 10)
 
 	// Subscription Maintenance (bounded queue + worker pool)
 	viper.SetDefault("subscription_maintenance.worker_count", 2)
@@ -2442,6 +3463,9 @@ func (c *Config) Validate() error {
 	if c.Server.MaxRequestBodySize < 0 {
 		return fmt.Errorf("server.max_request_body_size must be non-negative")
 	}
+	if c.Server.Compression.MinSizeBytes < 0 {
+		return fmt.Errorf("server.compression.min_size_bytes must be non-negative")
+	}
 	if c.Server.H2C.Enabled {
 		if c.Server.H2C.MaxConcurrentStreams == 0 {
 			return fmt.Errorf("server.h2c.max_concurrent_streams must be positive")
@@ -2530,6 +3554,27 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("log.sampling.thereafter must be non-negative")
 		}
 	}
+	if c.Log.Access.Enabled {
+		switch c.Log.Access.Format {
+		case "common", "combined":
+		default:
+			return fmt.Errorf("log.access.format must be one of: common/combined")
+		}
+		switch c.Log.Access.RotateInterval {
+		case "", "none", "hourly", "daily":
+		default:
+			return fmt.Errorf("log.access.rotate_interval must be one of: none/hourly/daily")
+		}
+		if c.Log.Access.Rotation.MaxSizeMB <= 0 {
+			return fmt.Errorf("log.access.rotation.max_size_mb must be positive")
+		}
+		if c.Log.Access.Rotation.MaxBackups < 0 {
+			return fmt.Errorf("log.access.rotation.max_backups must be non-negative")
+		}
+		if c.Log.Access.Rotation.MaxAgeDays < 0 {
+			return fmt.Errorf("log.access.rotation.max_age_days must be non-negative")
+		}
+	}
 
 	if c.SubscriptionMaintenance.WorkerCount < 0 {
 		return fmt.Errorf("subscription_maintenance.worker_count must be non-negative")
@@ -2545,6 +3590,9 @@ func (c *Config) Validate() error {
 	if (geminiClientID == "") != (geminiClientSecret == "") {
 		return fmt.Errorf("gemini.oauth.client_id and gemini.oauth.client_secret must be both set or both empty")
 	}
+	if c.Gemini.Quota.ProbeIntervalMinutes < 0 {
+		return fmt.Errorf("gemini.quota.probe_interval_minutes must be non-negative")
+	}
 
 	if strings.TrimSpace(c.Server.FrontendURL) != "" {
 		if err := ValidateAbsoluteHTTPURL(c.Server.FrontendURL); err != nil {
@@ -2788,6 +3836,41 @@ func (c *Config) Validate() error {
 	if c.Database.ConnMaxIdleTimeMinutes < 0 {
 		return fmt.Errorf("database.conn_max_idle_time_minutes must be non-negative")
 	}
+	if c.Database.Degraded.Enabled {
+		if c.Database.Degraded.ProbeIntervalSeconds <= 0 {
+			return fmt.Errorf("database.degraded.probe_interval_seconds must be positive")
+		}
+		if c.Database.Degraded.ProbeTimeoutSeconds <= 0 {
+			return fmt.Errorf("database.degraded.probe_timeout_seconds must be positive")
+		}
+		if c.Database.Degraded.FailureThreshold <= 0 {
+			return fmt.Errorf("database.degraded.failure_threshold must be positive")
+		}
+		if c.Database.Degraded.RecoveryThreshold <= 0 {
+			return fmt.Errorf("database.degraded.recovery_threshold must be positive")
+		}
+		if c.Database.Degraded.StaleTTLSeconds < 0 {
+			return fmt.Errorf("database.degraded.stale_ttl_seconds must be non-negative")
+		}
+	}
+	if c.Database.ReadReplica.Enabled {
+		replica := c.Database.ReadReplica
+		if strings.TrimSpace(replica.Host) == "" {
+			return fmt.Errorf("database.read_replica.host is required when database.read_replica.enabled=true")
+		}
+		if replica.MaxOpenConns <= 0 {
+			return fmt.Errorf("database.read_replica.max_open_conns must be positive")
+		}
+		if replica.MaxIdleConns < 0 || replica.MaxIdleConns > replica.MaxOpenConns {
+			return fmt.Errorf("database.read_replica.max_idle_conns must be between 0 and database.read_replica.max_open_conns")
+		}
+		if replica.MaxLagSeconds <= 0 {
+			return fmt.Errorf("database.read_replica.max_lag_seconds must be positive")
+		}
+		if replica.LagCheckIntervalSeconds <= 0 {
+			return fmt.Errorf("database.read_replica.lag_check_interval_seconds must be positive")
+		}
+	}
 	if c.Redis.DialTimeoutSeconds <= 0 {
 		return fmt.Errorf("redis.dial_timeout_seconds must be positive")
 	}
@@ -2806,6 +3889,33 @@ func (c *Config) Validate() error {
 	if c.Redis.MinIdleConns > c.Redis.PoolSize {
 		return fmt.Errorf("redis.min_idle_conns cannot exceed redis.pool_size")
 	}
+	switch c.Redis.NormalizedMode() {
+	case RedisModeStandalone:
+	case RedisModeSentinel:
+		if len(c.Redis.Addrs) == 0 {
+			return fmt.Errorf("redis.addrs must list sentinel addresses when redis.mode=sentinel")
+		}
+		if strings.TrimSpace(c.Redis.MasterName) == "" {
+			return fmt.Errorf("redis.master_name is required when redis.mode=sentinel")
+		}
+	case RedisModeCluster:
+		if len(c.Redis.Addrs) == 0 {
+			return fmt.Errorf("redis.addrs must list cluster seed nodes when redis.mode=cluster")
+		}
+		if c.Redis.DB != 0 {
+			return fmt.Errorf("redis.db must be 0 when redis.mode=cluster")
+		}
+	default:
+		return fmt.Errorf("redis.mode must be one of standalone/sentinel/cluster")
+	}
+	for _, addr := range c.Redis.Addrs {
+		if strings.TrimSpace(addr) == "" {
+			return fmt.Errorf("redis.addrs must not contain empty entries")
+		}
+	}
+	if c.Redis.HealthCheckIntervalSeconds < 0 {
+		return fmt.Errorf("redis.health_check_interval_seconds must be non-negative")
+	}
 	if c.BatchImage.QueueEnabled {
 		if strings.TrimSpace(c.BatchImage.QueueReadyKey) == "" {
 			return fmt.Errorf("batch_image.queue_ready_key must not be empty")
@@ -2861,6 +3971,9 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("batch_image.vertex_output_retention_hours must be positive")
 		}
 	}
+	if c.TokenCache.TTLJitterPercent < 0 || c.TokenCache.TTLJitterPercent > 50 {
+		return fmt.Errorf("token_cache.ttl_jitter_percent must be between 0 and 50")
+	}
 	if c.Dashboard.Enabled {
 		if c.Dashboard.StatsFreshTTLSeconds <= 0 {
 			return fmt.Errorf("dashboard_cache.stats_fresh_ttl_seconds must be positive")
@@ -2947,6 +4060,100 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("dashboard_aggregation.recompute_days must be non-negative")
 		}
 	}
+	if c.StartupDiagnostics.ProxySampleSize < 0 {
+		return fmt.Errorf("startup_diagnostics.proxy_sample_size must be non-negative")
+	}
+	if c.StartupDiagnostics.Enabled && c.StartupDiagnostics.TimeoutSeconds <= 0 {
+		return fmt.Errorf("startup_diagnostics.timeout_seconds must be positive")
+	}
+	if c.WarmStart.Enabled && c.WarmStart.TimeoutSeconds <= 0 {
+		return fmt.Errorf("warm_start.timeout_seconds must be positive")
+	}
+	if c.WarmStart.APIKeyLimit < 0 {
+		return fmt.Errorf("warm_start.api_key_limit must be non-negative")
+	}
+	if c.WarmStart.APIKeyLookbackHours < 0 {
+		return fmt.Errorf("warm_start.api_key_lookback_hours must be non-negative")
+	}
+	if c.AccountRenewal.ReminderDays < 0 {
+		return fmt.Errorf("account_renewal.reminder_days must be non-negative")
+	}
+	if c.AccountRenewal.ReminderDays > 0 && c.AccountRenewal.CheckIntervalMinutes <= 0 {
+		return fmt.Errorf("account_renewal.check_interval_minutes must be positive")
+	}
+	if c.AccountSnapshot.IntervalMinutes < 0 {
+		return fmt.Errorf("account_snapshot.interval_minutes must be non-negative")
+	}
+	if c.AccountSnapshot.IntervalMinutes > 0 && c.AccountSnapshot.RetentionDays <= 0 {
+		return fmt.Errorf("account_snapshot.retention_days must be positive")
+	}
+	if c.CredentialSync.IntervalMinutes < 0 {
+		return fmt.Errorf("credential_sync.interval_minutes must be non-negative")
+	}
+	if c.CredentialSync.Enabled && c.CredentialSync.TimeoutSeconds <= 0 {
+		return fmt.Errorf("credential_sync.timeout_seconds must be positive")
+	}
+	if c.CredentialSync.Enabled && strings.TrimSpace(c.CredentialSync.Vault.Address) != "" && strings.TrimSpace(c.CredentialSync.Vault.Token) == "" {
+		return fmt.Errorf("credential_sync.vault.token is required when credential_sync.vault.address is set")
+	}
+	if c.UserNotification.Enabled && c.UserNotification.RetentionDays <= 0 {
+		return fmt.Errorf("user_notification.retention_days must be positive")
+	}
+	if c.UserNotification.APIKeyExpiryNoticeHours < 0 {
+		return fmt.Errorf("user_notification.api_key_expiry_notice_hours must be non-negative")
+	}
+	if c.UserNotification.Enabled && c.UserNotification.ScanIntervalMinutes <= 0 {
+		return fmt.Errorf("user_notification.scan_interval_minutes must be positive")
+	}
+	if c.ModelAliasLearning.MinConfidence < 0 || c.ModelAliasLearning.MinConfidence > 1 {
+		return fmt.Errorf("model_alias_learning.min_confidence must be between 0 and 1")
+	}
+	if c.ModelAliasLearning.Enabled && c.ModelAliasLearning.FlushIntervalSeconds <= 0 {
+		return fmt.Errorf("model_alias_learning.flush_interval_seconds must be positive")
+	}
+	if c.CostAnomaly.Enabled {
+		if c.CostAnomaly.CheckIntervalMinutes <= 0 {
+			return fmt.Errorf("cost_anomaly.check_interval_minutes must be positive")
+		}
+		if c.CostAnomaly.RecentWindowMinutes <= 0 {
+			return fmt.Errorf("cost_anomaly.recent_window_minutes must be positive")
+		}
+		if c.CostAnomaly.BaselineDays <= 0 {
+			return fmt.Errorf("cost_anomaly.baseline_days must be positive")
+		}
+		if c.CostAnomaly.DeviationRatio <= 1 {
+			return fmt.Errorf("cost_anomaly.deviation_ratio must be greater than 1")
+		}
+		if c.CostAnomaly.ThrottleEnabled && c.CostAnomaly.ThrottleMinutes <= 0 {
+			return fmt.Errorf("cost_anomaly.throttle_minutes must be positive")
+		}
+	}
+	if c.AccountSmokeTest.Enabled {
+		if strings.TrimSpace(c.AccountSmokeTest.Schedule) == "" {
+			return fmt.Errorf("account_smoke_test.schedule is required")
+		}
+		if c.AccountSmokeTest.Concurrency <= 0 {
+			return fmt.Errorf("account_smoke_test.concurrency must be positive")
+		}
+		if c.AccountSmokeTest.TimeoutSeconds <= 0 {
+			return fmt.Errorf("account_smoke_test.timeout_seconds must be positive")
+		}
+		if c.AccountSmokeTest.RecentDays <= 0 || c.AccountSmokeTest.BaselineDays <= 0 {
+			return fmt.Errorf("account_smoke_test.recent_days and baseline_days must be positive")
+		}
+		if c.AccountSmokeTest.RetentionDays < c.AccountSmokeTest.RecentDays+c.AccountSmokeTest.BaselineDays {
+			return fmt.Errorf("account_smoke_test.retention_days must cover recent_days + baseline_days")
+		}
+		if c.AccountSmokeTest.DegradationThreshold <= 0 || c.AccountSmokeTest.DegradationThreshold >= 1 {
+			return fmt.Errorf("account_smoke_test.degradation_threshold must be between 0 and 1")
+		}
+	}
+	if c.AutoscalingMetrics.QueueWaitWindowSeconds <= 0 {
+		return fmt.Errorf("autoscaling_metrics.queue_wait_window_seconds must be positive")
+	}
+	if c.AutoscalingMetrics.SlotStatsCacheSeconds < 0 {
+		return fmt.Errorf("autoscaling_metrics.slot_stats_cache_seconds must be non-negative")
+	}
 	if c.UsageCleanup.Enabled {
 		if c.UsageCleanup.MaxRangeDays <= 0 {
 			return fmt.Errorf("usage_cleanup.max_range_days must be positive")
@@ -2974,6 +4181,28 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("usage_cleanup.task_timeout_seconds must be non-negative")
 		}
 	}
+	if c.UsageArchive.Enabled {
+		if c.UsageArchive.ArchiveAfterDays <= 0 {
+			return fmt.Errorf("usage_archive.archive_after_days must be positive")
+		}
+		if c.UsageArchive.ArchiveRetentionDays < 0 {
+			return fmt.Errorf("usage_archive.archive_retention_days must be non-negative")
+		}
+		if c.UsageArchive.ArchiveRetentionDays > 0 && c.UsageArchive.ArchiveRetentionDays <= c.UsageArchive.ArchiveAfterDays {
+			return fmt.Errorf("usage_archive.archive_retention_days must be greater than archive_after_days")
+		}
+		if c.UsageArchive.BatchSize <= 0 {
+			return fmt.Errorf("usage_archive.batch_size must be positive")
+		}
+		if c.UsageArchive.MaxBatchesPerRun <= 0 {
+			return fmt.Errorf("usage_archive.max_batches_per_run must be positive")
+		}
+		// 仪表盘保留期清理会直接删除（或整月 drop）过期的原始记录，必须晚于归档
+		if c.DashboardAgg.Enabled && c.DashboardAgg.Retention.UsageLogsDays > 0 &&
+			c.UsageArchive.ArchiveAfterDays >= c.DashboardAgg.Retention.UsageLogsDays {
+			return fmt.Errorf("usage_archive.archive_after_days must be less than dashboard_aggregation.retention.usage_logs_days")
+		}
+	}
 	if c.Idempotency.DefaultTTLSeconds <= 0 {
 		return fmt.Errorf("idempotency.default_ttl_seconds must be positive")
 	}
@@ -3004,6 +4233,19 @@ func (c *Config) Validate() error {
 	if c.Gateway.UpstreamResponseReadMaxBytes <= 0 {
 		return fmt.Errorf("gateway.upstream_response_read_max_bytes must be positive")
 	}
+	for endpoint, limit := range c.Gateway.UpstreamResponseEndpointMaxBytes {
+		if limit <= 0 {
+			return fmt.Errorf("gateway.upstream_response_endpoint_max_bytes[%s] must be positive", endpoint)
+		}
+	}
+	if c.Gateway.UpstreamStreamMaxBytes < 0 {
+		return fmt.Errorf("gateway.upstream_stream_max_bytes must be non-negative")
+	}
+	for endpoint, limit := range c.Gateway.UpstreamStreamEndpointMaxBytes {
+		if limit < 0 {
+			return fmt.Errorf("gateway.upstream_stream_endpoint_max_bytes[%s] must be non-negative", endpoint)
+		}
+	}
 	if c.Gateway.ProxyProbeResponseReadMaxBytes <= 0 {
 		return fmt.Errorf("gateway.proxy_probe_response_read_max_bytes must be positive")
 	}
@@ -3044,6 +4286,243 @@ func (c *Config) Validate() error {
 	if c.Gateway.ImageConcurrency.MaxWaitingRequests < 0 {
 		return fmt.Errorf("gateway.image_concurrency.max_waiting_requests must be non-negative")
 	}
+	if c.Gateway.ConversationTruncation.MaxInputTokens < 0 {
+		return fmt.Errorf("gateway.conversation_truncation.max_input_tokens must be non-negative")
+	}
+	if c.Gateway.ConversationTruncation.PreserveRecentTurns < 1 {
+		return fmt.Errorf("gateway.conversation_truncation.preserve_recent_turns must be at least 1")
+	}
+	for model, limit := range c.Gateway.ConversationTruncation.ModelMaxInputTokens {
+		if limit < 0 {
+			return fmt.Errorf("gateway.conversation_truncation.model_max_input_tokens[%s] must be non-negative", model)
+		}
+	}
+	for model, value := range c.Gateway.MaxTokens.ModelDefaults {
+		if value <= 0 {
+			return fmt.Errorf("gateway.max_tokens.model_defaults[%s] must be positive", model)
+		}
+	}
+	for model, value := range c.Gateway.MaxTokens.ModelMaxima {
+		if value <= 0 {
+			return fmt.Errorf("gateway.max_tokens.model_maxima[%s] must be positive", model)
+		}
+	}
+	switch c.Gateway.ToolLimits.DescriptionPolicy {
+	case "", ToolDescriptionPolicyReject, ToolDescriptionPolicyTruncate:
+	default:
+		return fmt.Errorf("gateway.tool_limits.description_policy must be one of: reject/truncate")
+	}
+	for platform, limit := range c.Gateway.ToolLimits.Platforms {
+		if limit.MaxTools < 0 || limit.MaxToolBytes < 0 || limit.MaxTotalBytes < 0 || limit.MaxDescriptionChars < 0 {
+			return fmt.Errorf("gateway.tool_limits.platforms[%s] limits must be non-negative", platform)
+		}
+	}
+	for name, profile := range c.Gateway.UpstreamMTLS.Profiles {
+		if strings.TrimSpace(profile.CertFile) == "" || strings.TrimSpace(profile.KeyFile) == "" {
+			return fmt.Errorf("gateway.upstream_mtls.profiles[%s] requires cert_file and key_file", name)
+		}
+	}
+	for platform, name := range c.Gateway.UpstreamMTLS.PlatformProfiles {
+		if _, ok := c.Gateway.UpstreamMTLS.Profiles[strings.ToLower(strings.TrimSpace(name))]; !ok {
+			return fmt.Errorf("gateway.upstream_mtls.platform_profiles[%s] references unknown profile %q", platform, name)
+		}
+	}
+	if c.Gateway.ConversationTranscript.RetentionDays < 0 {
+		return fmt.Errorf("gateway.conversation_transcript.retention_days must be non-negative")
+	}
+	if c.Gateway.ConversationTranscript.MaxRequestBytes <= 0 {
+		return fmt.Errorf("gateway.conversation_transcript.max_request_bytes must be positive")
+	}
+	if c.Gateway.ConversationTranscript.MaxResponseBytes <= 0 {
+		return fmt.Errorf("gateway.conversation_transcript.max_response_bytes must be positive")
+	}
+	if c.Gateway.UpstreamStreamCapture.MaxBytes <= 0 {
+		return fmt.Errorf("gateway.upstream_stream_capture.max_bytes must be positive")
+	}
+	if c.Gateway.UpstreamStreamCapture.RetentionDays < 0 {
+		return fmt.Errorf("gateway.upstream_stream_capture.retention_days must be non-negative")
+	}
+	for i, entry := range c.Gateway.DNS.StaticHosts {
+		if strings.TrimSpace(entry.Host) == "" {
+			return fmt.Errorf("gateway.dns.static_hosts[%d].host is required", i)
+		}
+		if len(entry.IPs) == 0 {
+			return fmt.Errorf("gateway.dns.static_hosts[%d].ips is required", i)
+		}
+		for _, ip := range entry.IPs {
+			if net.ParseIP(strings.TrimSpace(ip)) == nil {
+				return fmt.Errorf("gateway.dns.static_hosts[%d].ips contains invalid IP %q", i, ip)
+			}
+		}
+	}
+	if dohURL := strings.TrimSpace(c.Gateway.DNS.DoHURL); dohURL != "" {
+		if u, err := url.Parse(dohURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("gateway.dns.doh_url must be an https URL")
+		}
+	}
+	if c.Gateway.DNS.DoHTimeoutSeconds <= 0 {
+		return fmt.Errorf("gateway.dns.doh_timeout_seconds must be positive")
+	}
+	if !isValidDNSResolutionMode(c.Gateway.DNS.SOCKSResolution) {
+		return fmt.Errorf("gateway.dns.socks_resolution must be one of: %s, %s", DNSResolutionRemote, DNSResolutionLocal)
+	}
+	for i, entry := range c.Gateway.DNS.ProxyResolution {
+		if strings.TrimSpace(entry.Proxy) == "" {
+			return fmt.Errorf("gateway.dns.proxy_resolution[%d].proxy is required", i)
+		}
+		if !isValidDNSResolutionMode(entry.Mode) {
+			return fmt.Errorf("gateway.dns.proxy_resolution[%d].mode must be one of: %s, %s", i, DNSResolutionRemote, DNSResolutionLocal)
+		}
+	}
+	if c.Gateway.RequestMirror.Enabled {
+		if u, err := url.Parse(strings.TrimSpace(c.Gateway.RequestMirror.Endpoint)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("gateway.request_mirror.endpoint must be an http(s) URL when enabled")
+		}
+	}
+	if c.Gateway.RequestMirror.SampleRate <= 0 || c.Gateway.RequestMirror.SampleRate > 1 {
+		return fmt.Errorf("gateway.request_mirror.sample_rate must be within (0, 1]")
+	}
+	if c.Gateway.RequestMirror.MaxBodyBytes <= 0 {
+		return fmt.Errorf("gateway.request_mirror.max_body_bytes must be positive")
+	}
+	if c.Gateway.RequestMirror.TimeoutSeconds <= 0 {
+		return fmt.Errorf("gateway.request_mirror.timeout_seconds must be positive")
+	}
+	if c.Gateway.RequestMirror.WorkerCount <= 0 {
+		return fmt.Errorf("gateway.request_mirror.worker_count must be positive")
+	}
+	if c.Gateway.RequestMirror.QueueSize <= 0 {
+		return fmt.Errorf("gateway.request_mirror.queue_size must be positive")
+	}
+	if c.Gateway.ProxyLatencyRouting.ProbeIntervalSeconds <= 0 {
+		return fmt.Errorf("gateway.proxy_latency_routing.probe_interval_seconds must be positive")
+	}
+	if c.Gateway.ProxyLatencyRouting.ProbeTimeoutSeconds <= 0 {
+		return fmt.Errorf("gateway.proxy_latency_routing.probe_timeout_seconds must be positive")
+	}
+	if c.Gateway.ProxyLatencyRouting.RefreshIntervalSeconds <= 0 {
+		return fmt.Errorf("gateway.proxy_latency_routing.refresh_interval_seconds must be positive")
+	}
+	if c.Gateway.ProxyLatencyRouting.SwitchThresholdPercent < 0 || c.Gateway.ProxyLatencyRouting.SwitchThresholdPercent >= 100 {
+		return fmt.Errorf("gateway.proxy_latency_routing.switch_threshold_percent must be between 0 and 99")
+	}
+	if c.Gateway.ProxyLatencyRouting.MinImprovementMs < 0 {
+		return fmt.Errorf("gateway.proxy_latency_routing.min_improvement_ms must be non-negative")
+	}
+	if c.Gateway.ProxyLatencyRouting.MinHoldSeconds < 0 {
+		return fmt.Errorf("gateway.proxy_latency_routing.min_hold_seconds must be non-negative")
+	}
+	if err := validateUpstreamSkipEncodings("gateway.upstream_decompression.skip_encodings", c.Gateway.UpstreamDecompression.SkipEncodings); err != nil {
+		return err
+	}
+	for platform, encodings := range c.Gateway.UpstreamDecompression.PlatformSkipEncodings {
+		if err := validateUpstreamSkipEncodings(fmt.Sprintf("gateway.upstream_decompression.platform_skip_encodings[%s]", platform), encodings); err != nil {
+			return err
+		}
+	}
+	if c.Gateway.AdmissionControl.MaxWaitingGlobal < 0 {
+		return fmt.Errorf("gateway.admission_control.max_waiting_global must be non-negative")
+	}
+	if c.Gateway.AdmissionControl.MaxWaitingPerGroup < 0 {
+		return fmt.Errorf("gateway.admission_control.max_waiting_per_group must be non-negative")
+	}
+	if c.Gateway.AdmissionControl.ShedWaitP95Ms < 0 {
+		return fmt.Errorf("gateway.admission_control.shed_wait_p95_ms must be non-negative")
+	}
+	if c.Gateway.AdmissionControl.ShedMinSamples < 0 {
+		return fmt.Errorf("gateway.admission_control.shed_min_samples must be non-negative")
+	}
+	if c.Gateway.AdmissionControl.Enabled {
+		if c.Gateway.AdmissionControl.ShedWindowSeconds <= 0 {
+			return fmt.Errorf("gateway.admission_control.shed_window_seconds must be positive")
+		}
+		if c.Gateway.AdmissionControl.RetryAfterSeconds <= 0 {
+			return fmt.Errorf("gateway.admission_control.retry_after_seconds must be positive")
+		}
+	}
+	if c.Gateway.SlotLease.Enabled {
+		if c.Gateway.SlotLease.TTLSeconds <= 0 {
+			return fmt.Errorf("gateway.slot_lease.ttl_seconds must be positive")
+		}
+		if c.Gateway.SlotLease.HeartbeatIntervalSeconds <= 0 {
+			return fmt.Errorf("gateway.slot_lease.heartbeat_interval_seconds must be positive")
+		}
+		if c.Gateway.SlotLease.HeartbeatIntervalSeconds*2 > c.Gateway.SlotLease.TTLSeconds {
+			return fmt.Errorf("gateway.slot_lease.heartbeat_interval_seconds must be at most half of ttl_seconds")
+		}
+	}
+	if c.Gateway.SSEResume.Enabled {
+		if c.Gateway.SSEResume.BufferEvents <= 0 {
+			return fmt.Errorf("gateway.sse_resume.buffer_events must be positive")
+		}
+		if c.Gateway.SSEResume.TTLSeconds <= 0 {
+			return fmt.Errorf("gateway.sse_resume.ttl_seconds must be positive")
+		}
+		if c.Gateway.SSEResume.TailIdleTimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.sse_resume.tail_idle_timeout_seconds must be positive")
+		}
+		if c.Gateway.SSEResume.DisconnectGraceSeconds < 0 {
+			return fmt.Errorf("gateway.sse_resume.disconnect_grace_seconds must be non-negative")
+		}
+	}
+	if c.Gateway.SSEWebSocketBridge.Enabled {
+		if c.Gateway.SSEWebSocketBridge.FirstMessageTimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.sse_websocket_bridge.first_message_timeout_seconds must be positive")
+		}
+		if c.Gateway.SSEWebSocketBridge.WriteTimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.sse_websocket_bridge.write_timeout_seconds must be positive")
+		}
+	}
+	if c.Gateway.RequestParseShadow.Enabled {
+		rate := c.Gateway.RequestParseShadow.SampleRate
+		if rate <= 0 || rate > 1 {
+			return fmt.Errorf("gateway.request_parse_shadow.sample_rate must be in (0, 1]")
+		}
+	}
+	if c.Gateway.PolicyPlugins.Enabled {
+		if c.Gateway.PolicyPlugins.TimeoutMs <= 0 {
+			return fmt.Errorf("gateway.policy_plugins.timeout_ms must be positive")
+		}
+		if len(c.Gateway.PolicyPlugins.Builtins) == 0 && len(c.Gateway.PolicyPlugins.Paths) == 0 {
+			return fmt.Errorf("gateway.policy_plugins requires at least one of builtins or paths when enabled")
+		}
+	}
+	if c.Gateway.AccountWarmup.Enabled {
+		if c.Gateway.AccountWarmup.WindowHours <= 0 {
+			return fmt.Errorf("gateway.account_warmup.window_hours must be positive")
+		}
+		share := c.Gateway.AccountWarmup.InitialShare
+		if share <= 0 || share > 1 {
+			return fmt.Errorf("gateway.account_warmup.initial_share must be in (0, 1]")
+		}
+	}
+	if c.Gateway.ContentPolicyCooldown.Enabled {
+		cooldown := c.Gateway.ContentPolicyCooldown
+		switch cooldown.Scope {
+		case ContentPolicyCooldownScopeUser, ContentPolicyCooldownScopeAccount:
+		default:
+			return fmt.Errorf("gateway.content_policy_cooldown.scope must be one of: user, account")
+		}
+		if cooldown.CooldownSeconds <= 0 {
+			return fmt.Errorf("gateway.content_policy_cooldown.cooldown_seconds must be positive")
+		}
+		if cooldown.WindowSeconds <= 0 {
+			return fmt.Errorf("gateway.content_policy_cooldown.window_seconds must be positive")
+		}
+		if cooldown.AccountThreshold < 0 {
+			return fmt.Errorf("gateway.content_policy_cooldown.account_threshold must be non-negative")
+		}
+	}
+	if c.Gateway.AgentSessionAffinity.Enabled && c.Gateway.AgentSessionAffinity.TTLSeconds < 3600 {
+		return fmt.Errorf("gateway.agent_session_affinity.ttl_seconds must be at least 3600")
+	}
+	if c.Gateway.ErrorTranslation.Enabled {
+		switch c.Gateway.ErrorTranslation.DefaultLocale {
+		case "en", "zh":
+		default:
+			return fmt.Errorf("gateway.error_translation.default_locale must be one of: en, zh")
+		}
+	}
 	if c.Gateway.MaxIdleConns <= 0 {
 		return fmt.Errorf("gateway.max_idle_conns must be positive")
 	}
@@ -3082,6 +4561,15 @@ func (c *Config) Validate() error {
 		(c.Gateway.StreamKeepaliveInterval < 5 || c.Gateway.StreamKeepaliveInterval > 30) {
 		return fmt.Errorf("gateway.stream_keepalive_interval must be 0 or between 5-30 seconds")
 	}
+	if c.Gateway.ClientDisconnectUpstreamGraceSeconds < 0 {
+		return fmt.Errorf("gateway.client_disconnect_upstream_grace_seconds must be non-negative")
+	}
+	if c.Gateway.StreamClientWriteTimeout < 0 {
+		return fmt.Errorf("gateway.stream_client_write_timeout must be non-negative")
+	}
+	if c.Gateway.StreamClientMaxLagBytes < 0 {
+		return fmt.Errorf("gateway.stream_client_max_lag_bytes must be non-negative")
+	}
 	if c.Gateway.ImageStreamDataIntervalTimeout < 0 {
 		return fmt.Errorf("gateway.image_stream_data_interval_timeout must be non-negative")
 	}
@@ -3261,6 +4749,14 @@ func (c *Config) Validate() error {
 	if c.Gateway.MaxLineSize != 0 && c.Gateway.MaxLineSize < 1024*1024 {
 		return fmt.Errorf("gateway.max_line_size must be at least 1MB")
 	}
+	switch strings.TrimSpace(c.Gateway.SSEOversizeLine.Mode) {
+	case "", SSEOversizeLineModeError, SSEOversizeLineModeTruncate:
+	default:
+		return fmt.Errorf("gateway.sse_oversize_line.mode must be one of: %s/%s", SSEOversizeLineModeError, SSEOversizeLineModeTruncate)
+	}
+	if strings.TrimSpace(c.Gateway.SSEOversizeLine.Mode) == SSEOversizeLineModeTruncate && c.Gateway.SSEOversizeLine.KeepBytes <= 0 {
+		return fmt.Errorf("gateway.sse_oversize_line.keep_bytes must be positive")
+	}
 	if c.Gateway.UsageRecord.WorkerCount <= 0 {
 		return fmt.Errorf("gateway.usage_record.worker_count must be positive")
 	}
@@ -3319,6 +4815,20 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("gateway.usage_record.auto_scale_cooldown_seconds must be non-negative")
 		}
 	}
+	if c.Gateway.UsageRecord.DeadLetter.Enabled {
+		if c.Gateway.UsageRecord.DeadLetter.MaxEntries <= 0 {
+			return fmt.Errorf("gateway.usage_record.dead_letter.max_entries must be positive")
+		}
+		if c.Gateway.UsageRecord.DeadLetter.ReplayIntervalSeconds <= 0 {
+			return fmt.Errorf("gateway.usage_record.dead_letter.replay_interval_seconds must be positive")
+		}
+		if c.Gateway.UsageRecord.DeadLetter.ReplayBatchSize <= 0 || c.Gateway.UsageRecord.DeadLetter.ReplayBatchSize > 1000 {
+			return fmt.Errorf("gateway.usage_record.dead_letter.replay_batch_size must be between 1-1000")
+		}
+		if c.Gateway.UsageRecord.DeadLetter.MaxAttempts <= 0 {
+			return fmt.Errorf("gateway.usage_record.dead_letter.max_attempts must be positive")
+		}
+	}
 	if c.Gateway.UserGroupRateCacheTTLSeconds <= 0 {
 		return fmt.Errorf("gateway.user_group_rate_cache_ttl_seconds must be positive")
 	}
@@ -3555,3 +5065,23 @@ func warnIfInsecureURL(field, raw string) {
 		slog.Warn("url uses http scheme; use https in production to avoid token leakage", "field", field)
 	}
 }
+
+func validateUpstreamSkipEncodings(field string, encodings []string) error {
+	for _, encoding := range encodings {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding == "*" {
+			continue
+		}
+		supported := false
+		for _, known := range UpstreamContentEncodings {
+			if encoding == known {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("%s contains unsupported encoding %q (supported: %s, *)", field, encoding, strings.Join(UpstreamContentEncodings, ", "))
+		}
+	}
+	return nil
+}
@@ -37,6 +37,16 @@ func TestLoadServerTimingConfig(t *testing.T) {
 	})
 }
 
+func TestLoadServerCompressionDefaults(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Server.Compression.Enabled)
+	require.Equal(t, 1024, cfg.Server.Compression.MinSizeBytes)
+	require.Contains(t, cfg.Server.Compression.ContentTypes, "application/json")
+	require.NotContains(t, cfg.Server.Compression.ContentTypes, "text/event-stream")
+}
+
 func TestLoadHTTPIngressSafetyDefaults(t *testing.T) {
 	resetViperWithJWTSecret(t)
 	cfg, err := Load()
@@ -559,6 +569,301 @@ func TestLoadDefaultIdempotencyConfig(t *testing.T) {
 	}
 }
 
+func TestLoadDefaultDatabaseDegradedConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	d := cfg.Database.Degraded
+	if !d.Enabled {
+		t.Fatalf("Database.Degraded.Enabled = false, want true")
+	}
+	if d.ProbeIntervalSeconds != 5 || d.ProbeTimeoutSeconds != 2 {
+		t.Fatalf("Database.Degraded probe = %d/%d, want 5/2", d.ProbeIntervalSeconds, d.ProbeTimeoutSeconds)
+	}
+	if d.FailureThreshold != 3 || d.RecoveryThreshold != 2 {
+		t.Fatalf("Database.Degraded thresholds = %d/%d, want 3/2", d.FailureThreshold, d.RecoveryThreshold)
+	}
+	if d.StaleTTLSeconds != 3600 {
+		t.Fatalf("Database.Degraded.StaleTTLSeconds = %d, want 3600", d.StaleTTLSeconds)
+	}
+}
+
+func TestLoadDefaultDatabaseReadReplicaConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	r := cfg.Database.ReadReplica
+	if r.Enabled {
+		t.Fatalf("Database.ReadReplica.Enabled = true, want false")
+	}
+	if r.MaxOpenConns != 64 || r.MaxIdleConns != 16 {
+		t.Fatalf("Database.ReadReplica pool = %d/%d, want 64/16", r.MaxOpenConns, r.MaxIdleConns)
+	}
+	if r.MaxLagSeconds != 10 || r.LagCheckIntervalSeconds != 5 {
+		t.Fatalf("Database.ReadReplica lag = %d/%d, want 10/5", r.MaxLagSeconds, r.LagCheckIntervalSeconds)
+	}
+
+	r.Host = "replica.internal"
+	conn := r.Connection(cfg.Database)
+	if conn.Host != "replica.internal" || conn.Port != cfg.Database.Port || conn.User != cfg.Database.User ||
+		conn.Password != cfg.Database.Password || conn.DBName != cfg.Database.DBName {
+		t.Fatalf("Connection() = %+v, want primary fields inherited", conn)
+	}
+	r.User = "readonly"
+	if conn := r.Connection(cfg.Database); conn.Password != "" {
+		t.Fatalf("Connection() inherited primary password for a different user")
+	}
+}
+
+func TestLoadDefaultStartupDiagnosticsConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if !cfg.StartupDiagnostics.Enabled {
+		t.Fatalf("StartupDiagnostics.Enabled = false, want true")
+	}
+	if cfg.StartupDiagnostics.FailFast {
+		t.Fatalf("StartupDiagnostics.FailFast = true, want false")
+	}
+	if cfg.StartupDiagnostics.ProxySampleSize != 3 {
+		t.Fatalf("StartupDiagnostics.ProxySampleSize = %d, want 3", cfg.StartupDiagnostics.ProxySampleSize)
+	}
+	if cfg.StartupDiagnostics.TimeoutSeconds != 30 {
+		t.Fatalf("StartupDiagnostics.TimeoutSeconds = %d, want 30", cfg.StartupDiagnostics.TimeoutSeconds)
+	}
+}
+
+func TestLoadDefaultAccountRenewalConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.AccountRenewal.ReminderDays != 7 {
+		t.Fatalf("AccountRenewal.ReminderDays = %d, want 7", cfg.AccountRenewal.ReminderDays)
+	}
+	if cfg.AccountRenewal.CheckIntervalMinutes != 60 {
+		t.Fatalf("AccountRenewal.CheckIntervalMinutes = %d, want 60", cfg.AccountRenewal.CheckIntervalMinutes)
+	}
+}
+
+func TestLoadDefaultAccountSnapshotConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.AccountSnapshot.IntervalMinutes != 15 {
+		t.Fatalf("AccountSnapshot.IntervalMinutes = %d, want 15", cfg.AccountSnapshot.IntervalMinutes)
+	}
+	if cfg.AccountSnapshot.RetentionDays != 90 {
+		t.Fatalf("AccountSnapshot.RetentionDays = %d, want 90", cfg.AccountSnapshot.RetentionDays)
+	}
+}
+
+func TestLoadDefaultCredentialSyncConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.CredentialSync.Enabled {
+		t.Fatalf("CredentialSync.Enabled = true, want false")
+	}
+	if cfg.CredentialSync.IntervalMinutes != 30 {
+		t.Fatalf("CredentialSync.IntervalMinutes = %d, want 30", cfg.CredentialSync.IntervalMinutes)
+	}
+	if cfg.CredentialSync.TimeoutSeconds != 15 {
+		t.Fatalf("CredentialSync.TimeoutSeconds = %d, want 15", cfg.CredentialSync.TimeoutSeconds)
+	}
+	if cfg.CredentialSync.Vault.Mount != "secret" {
+		t.Fatalf("CredentialSync.Vault.Mount = %q, want secret", cfg.CredentialSync.Vault.Mount)
+	}
+}
+
+func TestLoadDefaultUserNotificationConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if !cfg.UserNotification.Enabled {
+		t.Fatalf("UserNotification.Enabled = false, want true")
+	}
+	if cfg.UserNotification.RetentionDays != 90 {
+		t.Fatalf("UserNotification.RetentionDays = %d, want 90", cfg.UserNotification.RetentionDays)
+	}
+	if cfg.UserNotification.APIKeyExpiryNoticeHours != 72 {
+		t.Fatalf("UserNotification.APIKeyExpiryNoticeHours = %d, want 72", cfg.UserNotification.APIKeyExpiryNoticeHours)
+	}
+	if cfg.UserNotification.ScanIntervalMinutes != 60 {
+		t.Fatalf("UserNotification.ScanIntervalMinutes = %d, want 60", cfg.UserNotification.ScanIntervalMinutes)
+	}
+}
+
+func TestLoadDefaultModelAliasLearningConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if !cfg.ModelAliasLearning.Enabled {
+		t.Fatalf("ModelAliasLearning.Enabled = false, want true")
+	}
+	if cfg.ModelAliasLearning.AutoApply {
+		t.Fatalf("ModelAliasLearning.AutoApply = true, want false")
+	}
+	if cfg.ModelAliasLearning.MinConfidence != 0.8 {
+		t.Fatalf("ModelAliasLearning.MinConfidence = %v, want 0.8", cfg.ModelAliasLearning.MinConfidence)
+	}
+	if cfg.ModelAliasLearning.FlushIntervalSeconds != 60 {
+		t.Fatalf("ModelAliasLearning.FlushIntervalSeconds = %d, want 60", cfg.ModelAliasLearning.FlushIntervalSeconds)
+	}
+}
+
+func TestLoadDefaultCostAnomalyConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.CostAnomaly.Enabled {
+		t.Fatalf("CostAnomaly.Enabled = true, want false")
+	}
+	if cfg.CostAnomaly.CheckIntervalMinutes != 10 {
+		t.Fatalf("CostAnomaly.CheckIntervalMinutes = %d, want 10", cfg.CostAnomaly.CheckIntervalMinutes)
+	}
+	if cfg.CostAnomaly.RecentWindowMinutes != 60 {
+		t.Fatalf("CostAnomaly.RecentWindowMinutes = %d, want 60", cfg.CostAnomaly.RecentWindowMinutes)
+	}
+	if cfg.CostAnomaly.BaselineDays != 7 {
+		t.Fatalf("CostAnomaly.BaselineDays = %d, want 7", cfg.CostAnomaly.BaselineDays)
+	}
+	if cfg.CostAnomaly.DeviationRatio != 3 {
+		t.Fatalf("CostAnomaly.DeviationRatio = %v, want 3", cfg.CostAnomaly.DeviationRatio)
+	}
+	if cfg.CostAnomaly.ThrottleEnabled {
+		t.Fatalf("CostAnomaly.ThrottleEnabled = true, want false")
+	}
+	if cfg.CostAnomaly.ThrottleMinutes != 240 {
+		t.Fatalf("CostAnomaly.ThrottleMinutes = %d, want 240", cfg.CostAnomaly.ThrottleMinutes)
+	}
+}
+
+func TestLoadDefaultAccountSmokeTestConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.AccountSmokeTest.Enabled {
+		t.Fatalf("AccountSmokeTest.Enabled = true, want false")
+	}
+	if cfg.AccountSmokeTest.Schedule != "0 3 * * *" {
+		t.Fatalf("AccountSmokeTest.Schedule = %q, want %q", cfg.AccountSmokeTest.Schedule, "0 3 * * *")
+	}
+	if cfg.AccountSmokeTest.Concurrency != 4 {
+		t.Fatalf("AccountSmokeTest.Concurrency = %d, want 4", cfg.AccountSmokeTest.Concurrency)
+	}
+	if cfg.AccountSmokeTest.RecentDays != 3 || cfg.AccountSmokeTest.BaselineDays != 14 {
+		t.Fatalf("AccountSmokeTest windows = %d/%d, want 3/14", cfg.AccountSmokeTest.RecentDays, cfg.AccountSmokeTest.BaselineDays)
+	}
+	if cfg.AccountSmokeTest.DegradationThreshold != 0.3 {
+		t.Fatalf("AccountSmokeTest.DegradationThreshold = %v, want 0.3", cfg.AccountSmokeTest.DegradationThreshold)
+	}
+}
+
+func TestLoadDefaultAutoscalingMetricsConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.AutoscalingMetrics.Enabled {
+		t.Fatalf("AutoscalingMetrics.Enabled = true, want false")
+	}
+	if cfg.AutoscalingMetrics.QueueWaitWindowSeconds != 300 {
+		t.Fatalf("AutoscalingMetrics.QueueWaitWindowSeconds = %d, want 300", cfg.AutoscalingMetrics.QueueWaitWindowSeconds)
+	}
+	if cfg.AutoscalingMetrics.SlotStatsCacheSeconds != 10 {
+		t.Fatalf("AutoscalingMetrics.SlotStatsCacheSeconds = %d, want 10", cfg.AutoscalingMetrics.SlotStatsCacheSeconds)
+	}
+}
+
+func TestLoadDefaultGeminiQuotaProbeInterval(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Gemini.Quota.ProbeIntervalMinutes != 10 {
+		t.Fatalf("Gemini.Quota.ProbeIntervalMinutes = %d, want 10", cfg.Gemini.Quota.ProbeIntervalMinutes)
+	}
+}
+
+func TestLoadDefaultSlotLeaseDisabled(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Gateway.SlotLease.Enabled)
+	require.Equal(t, 90, cfg.Gateway.SlotLease.TTLSeconds)
+	require.Equal(t, 30, cfg.Gateway.SlotLease.HeartbeatIntervalSeconds)
+}
+
+func TestLoadDefaultUpstreamDecompressionDecodesAll(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Empty(t, cfg.Gateway.UpstreamDecompression.SkipEncodings)
+	require.Empty(t, cfg.Gateway.UpstreamDecompression.PlatformSkipEncodings)
+}
+
+func TestLoadDefaultGatewayAdmissionControl(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	ac := cfg.Gateway.AdmissionControl
+	require.False(t, ac.Enabled)
+	require.Equal(t, 2000, ac.MaxWaitingGlobal)
+	require.Equal(t, 500, ac.MaxWaitingPerGroup)
+	require.Equal(t, 15000, ac.ShedWaitP95Ms)
+	require.Equal(t, 60, ac.ShedWindowSeconds)
+	require.Equal(t, 20, ac.ShedMinSamples)
+	require.Equal(t, 5, ac.RetryAfterSeconds)
+}
+
 func TestLoadDefaultBatchImageQueueDisabled(t *testing.T) {
 	resetViperWithJWTSecret(t)
 
@@ -936,6 +1241,27 @@ func TestValidateDashboardCacheConfigDisabled(t *testing.T) {
 	}
 }
 
+func TestValidateTokenCacheTTLJitterPercent(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.TokenCache.Encrypt || cfg.TokenCache.KeyPrefix != "" || cfg.TokenCache.TTLJitterPercent != 10 {
+		t.Fatalf("unexpected token_cache defaults: %+v", cfg.TokenCache)
+	}
+
+	cfg.TokenCache.TTLJitterPercent = 60
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatalf("Validate() expected error for ttl_jitter_percent > 50, got nil")
+	}
+	if !strings.Contains(err.Error(), "token_cache.ttl_jitter_percent") {
+		t.Fatalf("Validate() expected ttl_jitter_percent error, got: %v", err)
+	}
+}
+
 func TestLoadDefaultDashboardAggregationConfig(t *testing.T) {
 	resetViperWithJWTSecret(t)
 
@@ -1117,6 +1443,13 @@ func TestConfigAddressHelpers(t *testing.T) {
 	if redis.Address() != "redis:6379" {
 		t.Fatalf("RedisConfig.Address() = %q", redis.Address())
 	}
+	if redis.NormalizedMode() != RedisModeStandalone {
+		t.Fatalf("RedisConfig.NormalizedMode() = %q", redis.NormalizedMode())
+	}
+	redis.Mode = " Sentinel "
+	if redis.NormalizedMode() != RedisModeSentinel {
+		t.Fatalf("RedisConfig.NormalizedMode() = %q", redis.NormalizedMode())
+	}
 }
 
 func TestNormalizeStringSlice(t *testing.T) {
@@ -1409,6 +1742,11 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Server.MaxRequestBodySize = -1 },
 			wantErr: "server.max_request_body_size",
 		},
+		{
+			name:    "server compression min size",
+			mutate:  func(c *Config) { c.Server.Compression.MinSizeBytes = -1 },
+			wantErr: "server.compression.min_size_bytes",
+		},
 		{
 			name: "h2c zero concurrent streams",
 			mutate: func(c *Config) {
@@ -1564,6 +1902,35 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Redis.MinIdleConns = c.Redis.PoolSize + 1 },
 			wantErr: "redis.min_idle_conns cannot exceed",
 		},
+		{
+			name:    "redis unknown mode",
+			mutate:  func(c *Config) { c.Redis.Mode = "replicated" },
+			wantErr: "redis.mode",
+		},
+		{
+			name:    "redis sentinel without master",
+			mutate:  func(c *Config) { c.Redis.Mode = "sentinel"; c.Redis.Addrs = []string{"s1:26379"} },
+			wantErr: "redis.master_name",
+		},
+		{
+			name:    "redis cluster without seeds",
+			mutate:  func(c *Config) { c.Redis.Mode = "cluster" },
+			wantErr: "redis.addrs",
+		},
+		{
+			name: "redis cluster non-zero db",
+			mutate: func(c *Config) {
+				c.Redis.Mode = "cluster"
+				c.Redis.Addrs = []string{"n1:6379"}
+				c.Redis.DB = 1
+			},
+			wantErr: "redis.db must be 0",
+		},
+		{
+			name:    "redis health check negative",
+			mutate:  func(c *Config) { c.Redis.HealthCheckIntervalSeconds = -1 },
+			wantErr: "redis.health_check_interval_seconds",
+		},
 		{
 			name:    "dashboard cache disabled negative",
 			mutate:  func(c *Config) { c.Dashboard.Enabled = false; c.Dashboard.StatsTTLSeconds = -1 },
@@ -1615,6 +1982,207 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.DashboardAgg.Enabled = false; c.DashboardAgg.IntervalSeconds = -1 },
 			wantErr: "dashboard_aggregation.interval_seconds",
 		},
+		{
+			name:    "database degraded probe interval",
+			mutate:  func(c *Config) { c.Database.Degraded.Enabled = true; c.Database.Degraded.ProbeIntervalSeconds = 0 },
+			wantErr: "database.degraded.probe_interval_seconds",
+		},
+		{
+			name:    "database degraded failure threshold",
+			mutate:  func(c *Config) { c.Database.Degraded.Enabled = true; c.Database.Degraded.FailureThreshold = 0 },
+			wantErr: "database.degraded.failure_threshold",
+		},
+		{
+			name:    "database degraded stale ttl",
+			mutate:  func(c *Config) { c.Database.Degraded.Enabled = true; c.Database.Degraded.StaleTTLSeconds = -1 },
+			wantErr: "database.degraded.stale_ttl_seconds",
+		},
+		{
+			name:    "database read replica host",
+			mutate:  func(c *Config) { c.Database.ReadReplica.Enabled = true; c.Database.ReadReplica.Host = " " },
+			wantErr: "database.read_replica.host",
+		},
+		{
+			name: "database read replica max lag",
+			mutate: func(c *Config) {
+				c.Database.ReadReplica.Enabled = true
+				c.Database.ReadReplica.Host = "replica.internal"
+				c.Database.ReadReplica.MaxLagSeconds = 0
+			},
+			wantErr: "database.read_replica.max_lag_seconds",
+		},
+		{
+			name:    "startup diagnostics proxy sample size",
+			mutate:  func(c *Config) { c.StartupDiagnostics.ProxySampleSize = -1 },
+			wantErr: "startup_diagnostics.proxy_sample_size",
+		},
+		{
+			name:    "startup diagnostics timeout",
+			mutate:  func(c *Config) { c.StartupDiagnostics.Enabled = true; c.StartupDiagnostics.TimeoutSeconds = 0 },
+			wantErr: "startup_diagnostics.timeout_seconds",
+		},
+		{
+			name:    "account renewal reminder days",
+			mutate:  func(c *Config) { c.AccountRenewal.ReminderDays = -1 },
+			wantErr: "account_renewal.reminder_days",
+		},
+		{
+			name:    "account renewal check interval",
+			mutate:  func(c *Config) { c.AccountRenewal.ReminderDays = 7; c.AccountRenewal.CheckIntervalMinutes = 0 },
+			wantErr: "account_renewal.check_interval_minutes",
+		},
+		{
+			name:    "account snapshot interval",
+			mutate:  func(c *Config) { c.AccountSnapshot.IntervalMinutes = -1 },
+			wantErr: "account_snapshot.interval_minutes",
+		},
+		{
+			name:    "account snapshot retention",
+			mutate:  func(c *Config) { c.AccountSnapshot.IntervalMinutes = 15; c.AccountSnapshot.RetentionDays = 0 },
+			wantErr: "account_snapshot.retention_days",
+		},
+		{
+			name:    "credential sync interval",
+			mutate:  func(c *Config) { c.CredentialSync.IntervalMinutes = -1 },
+			wantErr: "credential_sync.interval_minutes",
+		},
+		{
+			name: "credential sync vault token",
+			mutate: func(c *Config) {
+				c.CredentialSync.Enabled = true
+				c.CredentialSync.Vault.Address = "https://vault.example.com"
+			},
+			wantErr: "credential_sync.vault.token",
+		},
+		{
+			name:    "user notification retention",
+			mutate:  func(c *Config) { c.UserNotification.Enabled = true; c.UserNotification.RetentionDays = 0 },
+			wantErr: "user_notification.retention_days",
+		},
+		{
+			name:    "user notification api key expiry notice",
+			mutate:  func(c *Config) { c.UserNotification.APIKeyExpiryNoticeHours = -1 },
+			wantErr: "user_notification.api_key_expiry_notice_hours",
+		},
+		{
+			name:    "model alias learning min confidence",
+			mutate:  func(c *Config) { c.ModelAliasLearning.MinConfidence = 1.5 },
+			wantErr: "model_alias_learning.min_confidence",
+		},
+		{
+			name:    "model alias learning flush interval",
+			mutate:  func(c *Config) { c.ModelAliasLearning.Enabled = true; c.ModelAliasLearning.FlushIntervalSeconds = 0 },
+			wantErr: "model_alias_learning.flush_interval_seconds",
+		},
+		{
+			name:    "cost anomaly deviation ratio",
+			mutate:  func(c *Config) { c.CostAnomaly.Enabled = true; c.CostAnomaly.DeviationRatio = 1 },
+			wantErr: "cost_anomaly.deviation_ratio",
+		},
+		{
+			name: "cost anomaly throttle minutes",
+			mutate: func(c *Config) {
+				c.CostAnomaly.Enabled = true
+				c.CostAnomaly.ThrottleEnabled = true
+				c.CostAnomaly.ThrottleMinutes = 0
+			},
+			wantErr: "cost_anomaly.throttle_minutes",
+		},
+		{
+			name: "account smoke test retention",
+			mutate: func(c *Config) {
+				c.AccountSmokeTest.Enabled = true
+				c.AccountSmokeTest.RetentionDays = 7
+			},
+			wantErr: "account_smoke_test.retention_days",
+		},
+		{
+			name: "account smoke test degradation threshold",
+			mutate: func(c *Config) {
+				c.AccountSmokeTest.Enabled = true
+				c.AccountSmokeTest.DegradationThreshold = 1
+			},
+			wantErr: "account_smoke_test.degradation_threshold",
+		},
+		{
+			name:    "autoscaling queue wait window",
+			mutate:  func(c *Config) { c.AutoscalingMetrics.QueueWaitWindowSeconds = 0 },
+			wantErr: "autoscaling_metrics.queue_wait_window_seconds",
+		},
+		{
+			name:    "autoscaling slot stats cache",
+			mutate:  func(c *Config) { c.AutoscalingMetrics.SlotStatsCacheSeconds = -1 },
+			wantErr: "autoscaling_metrics.slot_stats_cache_seconds",
+		},
+		{
+			name:    "gemini quota probe interval",
+			mutate:  func(c *Config) { c.Gemini.Quota.ProbeIntervalMinutes = -1 },
+			wantErr: "gemini.quota.probe_interval_minutes",
+		},
+		{
+			name: "upstream mtls profile missing key",
+			mutate: func(c *Config) {
+				c.Gateway.UpstreamMTLS.Profiles = map[string]GatewayUpstreamMTLSProfile{"corp": {CertFile: "/tmp/c.pem"}}
+			},
+			wantErr: "gateway.upstream_mtls.profiles[corp] requires cert_file and key_file",
+		},
+		{
+			name: "upstream mtls unknown platform profile",
+			mutate: func(c *Config) {
+				c.Gateway.UpstreamMTLS.PlatformProfiles = map[string]string{"anthropic": "corp"}
+			},
+			wantErr: "gateway.upstream_mtls.platform_profiles[anthropic] references unknown profile",
+		},
+		{
+			name:    "upstream decompression unknown encoding",
+			mutate:  func(c *Config) { c.Gateway.UpstreamDecompression.SkipEncodings = []string{"lz4"} },
+			wantErr: "gateway.upstream_decompression.skip_encodings contains unsupported encoding",
+		},
+		{
+			name: "upstream decompression unknown platform encoding",
+			mutate: func(c *Config) {
+				c.Gateway.UpstreamDecompression.PlatformSkipEncodings = map[string][]string{"openai": {"*", "compress"}}
+			},
+			wantErr: "gateway.upstream_decompression.platform_skip_encodings[openai]",
+		},
+		{
+			name:    "admission control negative global limit",
+			mutate:  func(c *Config) { c.Gateway.AdmissionControl.MaxWaitingGlobal = -1 },
+			wantErr: "gateway.admission_control.max_waiting_global",
+		},
+		{
+			name: "admission control zero shed window",
+			mutate: func(c *Config) {
+				c.Gateway.AdmissionControl.Enabled = true
+				c.Gateway.AdmissionControl.ShedWindowSeconds = 0
+			},
+			wantErr: "gateway.admission_control.shed_window_seconds",
+		},
+		{
+			name: "admission control zero retry after",
+			mutate: func(c *Config) {
+				c.Gateway.AdmissionControl.Enabled = true
+				c.Gateway.AdmissionControl.RetryAfterSeconds = 0
+			},
+			wantErr: "gateway.admission_control.retry_after_seconds",
+		},
+		{
+			name: "slot lease ttl",
+			mutate: func(c *Config) {
+				c.Gateway.SlotLease.Enabled = true
+				c.Gateway.SlotLease.TTLSeconds = 0
+			},
+			wantErr: "gateway.slot_lease.ttl_seconds",
+		},
+		{
+			name: "slot lease heartbeat too slow",
+			mutate: func(c *Config) {
+				c.Gateway.SlotLease.Enabled = true
+				c.Gateway.SlotLease.TTLSeconds = 60
+				c.Gateway.SlotLease.HeartbeatIntervalSeconds = 45
+			},
+			wantErr: "gateway.slot_lease.heartbeat_interval_seconds",
+		},
 		{
 			name:    "usage cleanup max range",
 			mutate:  func(c *Config) { c.UsageCleanup.Enabled = true; c.UsageCleanup.MaxRangeDays = 0 },
@@ -1635,6 +2203,173 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.UsageCleanup.Enabled = false; c.UsageCleanup.BatchSize = -1 },
 			wantErr: "usage_cleanup.batch_size",
 		},
+		{
+			name:    "gateway upstream response endpoint max bytes",
+			mutate:  func(c *Config) { c.Gateway.UpstreamResponseEndpointMaxBytes = map[string]int64{"/v1/messages": 0} },
+			wantErr: "gateway.upstream_response_endpoint_max_bytes[/v1/messages] must be positive",
+		},
+		{
+			name:    "gateway upstream stream max bytes",
+			mutate:  func(c *Config) { c.Gateway.UpstreamStreamMaxBytes = -1 },
+			wantErr: "gateway.upstream_stream_max_bytes must be non-negative",
+		},
+		{
+			name:    "gateway upstream stream endpoint max bytes",
+			mutate:  func(c *Config) { c.Gateway.UpstreamStreamEndpointMaxBytes = map[string]int64{"/v1/responses": -1} },
+			wantErr: "gateway.upstream_stream_endpoint_max_bytes[/v1/responses] must be non-negative",
+		},
+		{
+			name: "gateway dns static host invalid ip",
+			mutate: func(c *Config) {
+				c.Gateway.DNS.StaticHosts = []GatewayDNSStaticHost{{Host: "api.anthropic.com", IPs: []string{"not-an-ip"}}}
+			},
+			wantErr: "gateway.dns.static_hosts[0].ips contains invalid IP",
+		},
+		{
+			name:    "gateway dns doh url must be https",
+			mutate:  func(c *Config) { c.Gateway.DNS.DoHURL = "http://1.1.1.1/dns-query" },
+			wantErr: "gateway.dns.doh_url must be an https URL",
+		},
+		{
+			name:    "gateway dns socks resolution",
+			mutate:  func(c *Config) { c.Gateway.DNS.SOCKSResolution = "proxy" },
+			wantErr: "gateway.dns.socks_resolution must be one of",
+		},
+		{
+			name: "gateway dns proxy resolution mode",
+			mutate: func(c *Config) {
+				c.Gateway.DNS.ProxyResolution = []GatewayDNSProxyResolution{{Proxy: "socks.example:1080", Mode: "both"}}
+			},
+			wantErr: "gateway.dns.proxy_resolution[0].mode must be one of",
+		},
+		{
+			name: "gateway request mirror endpoint required",
+			mutate: func(c *Config) {
+				c.Gateway.RequestMirror.Enabled = true
+				c.Gateway.RequestMirror.Endpoint = ""
+			},
+			wantErr: "gateway.request_mirror.endpoint must be an http(s) URL when enabled",
+		},
+		{
+			name:    "gateway request mirror sample rate",
+			mutate:  func(c *Config) { c.Gateway.RequestMirror.SampleRate = 1.5 },
+			wantErr: "gateway.request_mirror.sample_rate must be within (0, 1]",
+		},
+		{
+			name:    "gateway request mirror queue size",
+			mutate:  func(c *Config) { c.Gateway.RequestMirror.QueueSize = 0 },
+			wantErr: "gateway.request_mirror.queue_size must be positive",
+		},
+		{
+			name:    "gateway proxy latency routing probe interval",
+			mutate:  func(c *Config) { c.Gateway.ProxyLatencyRouting.ProbeIntervalSeconds = 0 },
+			wantErr: "gateway.proxy_latency_routing.probe_interval_seconds must be positive",
+		},
+		{
+			name:    "gateway proxy latency routing switch threshold",
+			mutate:  func(c *Config) { c.Gateway.ProxyLatencyRouting.SwitchThresholdPercent = 100 },
+			wantErr: "gateway.proxy_latency_routing.switch_threshold_percent must be between 0 and 99",
+		},
+		{
+			name: "gateway sse resume buffer events",
+			mutate: func(c *Config) {
+				c.Gateway.SSEResume.Enabled = true
+				c.Gateway.SSEResume.BufferEvents = 0
+			},
+			wantErr: "gateway.sse_resume.buffer_events must be positive",
+		},
+		{
+			name: "gateway sse resume disconnect grace",
+			mutate: func(c *Config) {
+				c.Gateway.SSEResume.Enabled = true
+				c.Gateway.SSEResume.DisconnectGraceSeconds = -1
+			},
+			wantErr: "gateway.sse_resume.disconnect_grace_seconds must be non-negative",
+		},
+		{
+			name:    "gateway sse oversize line mode",
+			mutate:  func(c *Config) { c.Gateway.SSEOversizeLine.Mode = "chunk" },
+			wantErr: "gateway.sse_oversize_line.mode must be one of",
+		},
+		{
+			name: "gateway sse oversize line keep bytes",
+			mutate: func(c *Config) {
+				c.Gateway.SSEOversizeLine.Mode = SSEOversizeLineModeTruncate
+				c.Gateway.SSEOversizeLine.KeepBytes = 0
+			},
+			wantErr: "gateway.sse_oversize_line.keep_bytes must be positive",
+		},
+		{
+			name: "gateway sse websocket bridge first message timeout",
+			mutate: func(c *Config) {
+				c.Gateway.SSEWebSocketBridge.Enabled = true
+				c.Gateway.SSEWebSocketBridge.FirstMessageTimeoutSeconds = 0
+			},
+			wantErr: "gateway.sse_websocket_bridge.first_message_timeout_seconds must be positive",
+		},
+		{
+			name: "gateway request parse shadow sample rate",
+			mutate: func(c *Config) {
+				c.Gateway.RequestParseShadow.Enabled = true
+				c.Gateway.RequestParseShadow.SampleRate = 1.5
+			},
+			wantErr: "gateway.request_parse_shadow.sample_rate must be in (0, 1]",
+		},
+		{
+			name: "gateway policy plugins without policies",
+			mutate: func(c *Config) {
+				c.Gateway.PolicyPlugins.Enabled = true
+			},
+			wantErr: "gateway.policy_plugins requires at least one of builtins or paths when enabled",
+		},
+		{
+			name: "gateway account warmup window hours",
+			mutate: func(c *Config) {
+				c.Gateway.AccountWarmup.Enabled = true
+				c.Gateway.AccountWarmup.WindowHours = 0
+			},
+			wantErr: "gateway.account_warmup.window_hours must be positive",
+		},
+		{
+			name: "gateway account warmup initial share",
+			mutate: func(c *Config) {
+				c.Gateway.AccountWarmup.Enabled = true
+				c.Gateway.AccountWarmup.InitialShare = 0
+			},
+			wantErr: "gateway.account_warmup.initial_share must be in (0, 1]",
+		},
+		{
+			name: "gateway content policy cooldown scope",
+			mutate: func(c *Config) {
+				c.Gateway.ContentPolicyCooldown.Enabled = true
+				c.Gateway.ContentPolicyCooldown.Scope = "group"
+			},
+			wantErr: "gateway.content_policy_cooldown.scope must be one of: user, account",
+		},
+		{
+			name: "gateway content policy cooldown seconds",
+			mutate: func(c *Config) {
+				c.Gateway.ContentPolicyCooldown.Enabled = true
+				c.Gateway.ContentPolicyCooldown.CooldownSeconds = 0
+			},
+			wantErr: "gateway.content_policy_cooldown.cooldown_seconds must be positive",
+		},
+		{
+			name: "gateway agent session affinity ttl",
+			mutate: func(c *Config) {
+				c.Gateway.AgentSessionAffinity.Enabled = true
+				c.Gateway.AgentSessionAffinity.TTLSeconds = 600
+			},
+			wantErr: "gateway.agent_session_affinity.ttl_seconds must be at least 3600",
+		},
+		{
+			name: "gateway error translation default locale",
+			mutate: func(c *Config) {
+				c.Gateway.ErrorTranslation.Enabled = true
+				c.Gateway.ErrorTranslation.DefaultLocale = "fr"
+			},
+			wantErr: "gateway.error_translation.default_locale must be one of: en, zh",
+		},
 		{
 			name:    "gateway max body size",
 			mutate:  func(c *Config) { c.Gateway.MaxBodySize = 0 },
@@ -1710,6 +2445,21 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Gateway.StreamKeepaliveInterval = 4 },
 			wantErr: "gateway.stream_keepalive_interval",
 		},
+		{
+			name:    "gateway client disconnect upstream grace",
+			mutate:  func(c *Config) { c.Gateway.ClientDisconnectUpstreamGraceSeconds = -1 },
+			wantErr: "gateway.client_disconnect_upstream_grace_seconds must be non-negative",
+		},
+		{
+			name:    "gateway stream client write timeout",
+			mutate:  func(c *Config) { c.Gateway.StreamClientWriteTimeout = -1 },
+			wantErr: "gateway.stream_client_write_timeout must be non-negative",
+		},
+		{
+			name:    "gateway stream client max lag",
+			mutate:  func(c *Config) { c.Gateway.StreamClientMaxLagBytes = -1 },
+			wantErr: "gateway.stream_client_max_lag_bytes must be non-negative",
+		},
 		{
 			name:    "gateway openai ws oauth max conns factor",
 			mutate:  func(c *Config) { c.Gateway.OpenAIWS.OAuthMaxConnsFactor = 0 },
@@ -1795,6 +2545,48 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Gateway.ImageConcurrency.MaxWaitingRequests = -1 },
 			wantErr: "gateway.image_concurrency.max_waiting_requests must be non-negative",
 		},
+		{
+			name:    "gateway conversation truncation max input tokens negative",
+			mutate:  func(c *Config) { c.Gateway.ConversationTruncation.MaxInputTokens = -1 },
+			wantErr: "gateway.conversation_truncation.max_input_tokens must be non-negative",
+		},
+		{
+			name:    "gateway conversation truncation preserve turns zero",
+			mutate:  func(c *Config) { c.Gateway.ConversationTruncation.PreserveRecentTurns = 0 },
+			wantErr: "gateway.conversation_truncation.preserve_recent_turns must be at least 1",
+		},
+		{
+			name: "gateway conversation truncation model limit negative",
+			mutate: func(c *Config) {
+				c.Gateway.ConversationTruncation.ModelMaxInputTokens = map[string]int{"claude-*": -1}
+			},
+			wantErr: "gateway.conversation_truncation.model_max_input_tokens",
+		},
+		{
+			name:    "gateway max tokens default non-positive",
+			mutate:  func(c *Config) { c.Gateway.MaxTokens.ModelDefaults = map[string]int{"claude-*": 0} },
+			wantErr: "gateway.max_tokens.model_defaults",
+		},
+		{
+			name:    "gateway max tokens maximum non-positive",
+			mutate:  func(c *Config) { c.Gateway.MaxTokens.ModelMaxima = map[string]int{"claude-*": -1} },
+			wantErr: "gateway.max_tokens.model_maxima",
+		},
+		{
+			name:    "gateway conversation transcript retention negative",
+			mutate:  func(c *Config) { c.Gateway.ConversationTranscript.RetentionDays = -1 },
+			wantErr: "gateway.conversation_transcript.retention_days must be non-negative",
+		},
+		{
+			name:    "gateway conversation transcript max response bytes zero",
+			mutate:  func(c *Config) { c.Gateway.ConversationTranscript.MaxResponseBytes = 0 },
+			wantErr: "gateway.conversation_transcript.max_response_bytes must be positive",
+		},
+		{
+			name:    "gateway upstream stream capture max bytes zero",
+			mutate:  func(c *Config) { c.Gateway.UpstreamStreamCapture.MaxBytes = 0 },
+			wantErr: "gateway.upstream_stream_capture.max_bytes must be positive",
+		},
 		{
 			name:    "gateway max line size",
 			mutate:  func(c *Config) { c.Gateway.MaxLineSize = 1024 },
@@ -1873,6 +2665,16 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Gateway.UsageRecord.AutoScaleCheckIntervalSeconds = 0 },
 			wantErr: "gateway.usage_record.auto_scale_check_interval_seconds",
 		},
+		{
+			name:    "gateway usage record dead letter max entries",
+			mutate:  func(c *Config) { c.Gateway.UsageRecord.DeadLetter.MaxEntries = 0 },
+			wantErr: "gateway.usage_record.dead_letter.max_entries",
+		},
+		{
+			name:    "gateway usage record dead letter batch size",
+			mutate:  func(c *Config) { c.Gateway.UsageRecord.DeadLetter.ReplayBatchSize = 1001 },
+			wantErr: "gateway.usage_record.dead_letter.replay_batch_size",
+		},
 		{
 			name:    "gateway user group rate cache ttl",
 			mutate:  func(c *Config) { c.Gateway.UserGroupRateCacheTTLSeconds = 0 },
@@ -1942,6 +2744,22 @@ func TestValidateConfigErrors(t *testing.T) {
 			},
 			wantErr: "log.sampling.initial",
 		},
+		{
+			name: "log access format",
+			mutate: func(c *Config) {
+				c.Log.Access.Enabled = true
+				c.Log.Access.Format = "json"
+			},
+			wantErr: "log.access.format",
+		},
+		{
+			name: "log access rotate interval",
+			mutate: func(c *Config) {
+				c.Log.Access.Enabled = true
+				c.Log.Access.RotateInterval = "weekly"
+			},
+			wantErr: "log.access.rotate_interval",
+		},
 		{
 			name:    "ops metrics collector ttl",
 			mutate:  func(c *Config) { c.Ops.MetricsCollectorCache.TTL = -1 },
@@ -2383,6 +3201,15 @@ func TestLoad_DefaultGatewayUsageRecordConfig(t *testing.T) {
 	if cfg.Gateway.UsageRecord.AutoScaleCooldownSeconds != 10 {
 		t.Fatalf("auto_scale_cooldown_seconds = %d, want 10", cfg.Gateway.UsageRecord.AutoScaleCooldownSeconds)
 	}
+	if !cfg.Gateway.UsageRecord.DeadLetter.Enabled {
+		t.Fatalf("dead_letter.enabled = false, want true")
+	}
+	if cfg.Gateway.UsageRecord.DeadLetter.MaxEntries != 100000 {
+		t.Fatalf("dead_letter.max_entries = %d, want 100000", cfg.Gateway.UsageRecord.DeadLetter.MaxEntries)
+	}
+	if cfg.Gateway.UsageRecord.DeadLetter.ReplayIntervalSeconds != 30 {
+		t.Fatalf("dead_letter.replay_interval_seconds = %d, want 30", cfg.Gateway.UsageRecord.DeadLetter.ReplayIntervalSeconds)
+	}
 }
 
 func TestLoad_DefaultGatewayImageStreamConfig(t *testing.T) {
@@ -2397,6 +3224,12 @@ func TestLoad_DefaultGatewayImageStreamConfig(t *testing.T) {
 	if cfg.Gateway.StreamKeepaliveInterval != 10 {
 		t.Fatalf("stream_keepalive_interval = %d, want 10", cfg.Gateway.StreamKeepaliveInterval)
 	}
+	if cfg.Gateway.ClientDisconnectUpstreamGraceSeconds != 60 {
+		t.Fatalf("client_disconnect_upstream_grace_seconds = %d, want 60", cfg.Gateway.ClientDisconnectUpstreamGraceSeconds)
+	}
+	if cfg.Gateway.StreamClientWriteTimeout != 30 || cfg.Gateway.StreamClientMaxLagBytes != 8*1024*1024 {
+		t.Fatalf("stream client backpressure = %ds/%d bytes, want 30s/8MiB", cfg.Gateway.StreamClientWriteTimeout, cfg.Gateway.StreamClientMaxLagBytes)
+	}
 	if cfg.Gateway.ImageStreamDataIntervalTimeout != 900 {
 		t.Fatalf("image_stream_data_interval_timeout = %d, want 900", cfg.Gateway.ImageStreamDataIntervalTimeout)
 	}
@@ -2421,6 +3254,87 @@ func TestLoad_DefaultGatewayImageStreamConfig(t *testing.T) {
 	if cfg.Gateway.ImageConcurrency.MaxWaitingRequests != 100 {
 		t.Fatalf("image_concurrency.max_waiting_requests = %d, want 100", cfg.Gateway.ImageConcurrency.MaxWaitingRequests)
 	}
+	if cfg.Gateway.ConversationTruncation.Enabled {
+		t.Fatalf("conversation_truncation.enabled = true, want false")
+	}
+	if cfg.Gateway.ConversationTruncation.PreserveRecentTurns != 4 {
+		t.Fatalf("conversation_truncation.preserve_recent_turns = %d, want 4", cfg.Gateway.ConversationTruncation.PreserveRecentTurns)
+	}
+	if cfg.Gateway.MaxTokens.Enabled || cfg.Gateway.MaxTokens.CatalogMaximumFallback {
+		t.Fatalf("max_tokens = %+v, want disabled", cfg.Gateway.MaxTokens)
+	}
+	if cfg.Gateway.DNS.SOCKSResolution != DNSResolutionRemote {
+		t.Fatalf("dns.socks_resolution = %q, want %q", cfg.Gateway.DNS.SOCKSResolution, DNSResolutionRemote)
+	}
+	if cfg.Gateway.DNS.DoHTimeoutSeconds != 5 {
+		t.Fatalf("dns.doh_timeout_seconds = %d, want 5", cfg.Gateway.DNS.DoHTimeoutSeconds)
+	}
+	if cfg.Gateway.RequestMirror.Enabled {
+		t.Fatalf("request_mirror.enabled = true, want false")
+	}
+	if cfg.Gateway.RequestMirror.SampleRate != 0.01 {
+		t.Fatalf("request_mirror.sample_rate = %v, want 0.01", cfg.Gateway.RequestMirror.SampleRate)
+	}
+	if cfg.Gateway.ProxyLatencyRouting.Enabled {
+		t.Fatalf("proxy_latency_routing.enabled = true, want false")
+	}
+	if cfg.Gateway.ProxyLatencyRouting.MinHoldSeconds != 300 {
+		t.Fatalf("proxy_latency_routing.min_hold_seconds = %d, want 300", cfg.Gateway.ProxyLatencyRouting.MinHoldSeconds)
+	}
+	if cfg.Gateway.SSEResume.Enabled {
+		t.Fatalf("sse_resume.enabled = true, want false")
+	}
+	if cfg.Gateway.SSEResume.BufferEvents != 2000 {
+		t.Fatalf("sse_resume.buffer_events = %d, want 2000", cfg.Gateway.SSEResume.BufferEvents)
+	}
+	if cfg.Gateway.SSEResume.DisconnectGraceSeconds != 60 {
+		t.Fatalf("sse_resume.disconnect_grace_seconds = %d, want 60", cfg.Gateway.SSEResume.DisconnectGraceSeconds)
+	}
+	if cfg.Gateway.SSEOversizeLine.Mode != SSEOversizeLineModeError {
+		t.Fatalf("sse_oversize_line.mode = %q, want error", cfg.Gateway.SSEOversizeLine.Mode)
+	}
+	if cfg.Gateway.SSEOversizeLine.KeepBytes != 64*1024 {
+		t.Fatalf("sse_oversize_line.keep_bytes = %d, want 65536", cfg.Gateway.SSEOversizeLine.KeepBytes)
+	}
+	if cfg.Gateway.SSEWebSocketBridge.Enabled {
+		t.Fatalf("sse_websocket_bridge.enabled = true, want false")
+	}
+	if cfg.Gateway.SSEWebSocketBridge.FirstMessageTimeoutSeconds != 30 {
+		t.Fatalf("sse_websocket_bridge.first_message_timeout_seconds = %d, want 30", cfg.Gateway.SSEWebSocketBridge.FirstMessageTimeoutSeconds)
+	}
+	if cfg.Gateway.ErrorTranslation.Enabled {
+		t.Fatalf("error_translation.enabled = true, want false")
+	}
+	if cfg.Gateway.ErrorTranslation.DefaultLocale != "en" {
+		t.Fatalf("error_translation.default_locale = %q, want en", cfg.Gateway.ErrorTranslation.DefaultLocale)
+	}
+	if cfg.Gateway.RequestParseShadow.Enabled {
+		t.Fatalf("request_parse_shadow.enabled = true, want false")
+	}
+	if cfg.Gateway.RequestParseShadow.SampleRate != 0.01 {
+		t.Fatalf("request_parse_shadow.sample_rate = %v, want 0.01", cfg.Gateway.RequestParseShadow.SampleRate)
+	}
+	if cfg.Gateway.PolicyPlugins.Enabled || cfg.Gateway.PolicyPlugins.TimeoutMs != 200 || !cfg.Gateway.PolicyPlugins.FailOpen {
+		t.Fatalf("policy_plugins defaults = %+v, want disabled/200ms/fail_open", cfg.Gateway.PolicyPlugins)
+	}
+	if cfg.Gateway.AccountWarmup.Enabled || cfg.Gateway.AccountWarmup.WindowHours != 48 || cfg.Gateway.AccountWarmup.InitialShare != 0.1 {
+		t.Fatalf("account_warmup defaults = %+v, want disabled/48h/0.1", cfg.Gateway.AccountWarmup)
+	}
+	if cooldown := cfg.Gateway.ContentPolicyCooldown; cooldown.Enabled || cooldown.Scope != "user" || cooldown.CooldownSeconds != 300 || cooldown.WindowSeconds != 3600 || cooldown.AccountThreshold != 3 {
+		t.Fatalf("content_policy_cooldown defaults = %+v, want disabled/user/300s/3600s/3", cooldown)
+	}
+	if affinity := cfg.Gateway.AgentSessionAffinity; affinity.Enabled || affinity.TTLSeconds != 14400 || !affinity.ReleaseOnWindowWarning {
+		t.Fatalf("agent_session_affinity defaults = %+v, want disabled/14400s/release_on_window_warning", affinity)
+	}
+	if cfg.Gateway.ConversationTranscript.Enabled {
+		t.Fatalf("conversation_transcript.enabled = true, want false")
+	}
+	if cfg.Gateway.ConversationTranscript.RetentionDays != 30 {
+		t.Fatalf("conversation_transcript.retention_days = %d, want 30", cfg.Gateway.ConversationTranscript.RetentionDays)
+	}
+	if capture := cfg.Gateway.UpstreamStreamCapture; capture.Enabled || !capture.CaptureOnError || capture.MaxBytes != 2*1024*1024 || capture.Prefix != "stream-captures" {
+		t.Fatalf("upstream_stream_capture defaults = %+v, want disabled/capture_on_error/2MiB/stream-captures", capture)
+	}
 	if cfg.Gateway.ImageStreamDataIntervalTimeout <= cfg.Gateway.StreamDataIntervalTimeout {
 		t.Fatalf("image stream timeout = %d, want greater than ordinary stream timeout %d", cfg.Gateway.ImageStreamDataIntervalTimeout, cfg.Gateway.StreamDataIntervalTimeout)
 	}
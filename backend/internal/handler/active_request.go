@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestAnnotationUsageWait 响应结束后等待异步使用量记录算出费用的上限；超时则不下发缓存/费用 trailer。
+const requestAnnotationUsageWait = 500 * time.Millisecond
+
+// activeRequestWriter 统计写给客户端的字节数，并在首次写出上游内容时把请求标记为流式阶段。
+// annotations 非 nil 时（Key 开启了响应注解），在响应头写出前补上网关注解头并声明 trailer。
+type activeRequestWriter struct {
+	gin.ResponseWriter
+	req         *service.ActiveRequest
+	annotations *service.RequestAnnotations
+	annotated   bool
+}
+
+func (w *activeRequestWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *activeRequestWriter) Write(b []byte) (int, error) {
+	w.writeAnnotationHeaders()
+	n, err := w.ResponseWriter.Write(b)
+	w.req.AddBytes(n)
+	return n, err
+}
+
+func (w *activeRequestWriter) WriteString(s string) (int, error) {
+	w.writeAnnotationHeaders()
+	n, err := w.ResponseWriter.WriteString(s)
+	w.req.AddBytes(n)
+	return n, err
+}
+
+func (w *activeRequestWriter) WriteHeaderNow() {
+	w.writeAnnotationHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *activeRequestWriter) Flush() {
+	w.writeAnnotationHeaders()
+	w.ResponseWriter.Flush()
+}
+
+func (w *activeRequestWriter) writeAnnotationHeaders() {
+	if w.annotations == nil || w.annotated || w.ResponseWriter.Written() {
+		return
+	}
+	w.annotated = true
+	platform, retries, queueWait := w.req.Routing()
+	h := w.ResponseWriter.Header()
+	if platform != "" {
+		h.Set(service.RequestAnnotationHeaderPlatform, platform)
+	}
+	h.Set(service.RequestAnnotationHeaderRetryCount, strconv.Itoa(retries))
+	h.Set(service.RequestAnnotationHeaderPriority, w.req.Priority())
+	h.Set(service.RequestAnnotationHeaderQueueWaitMs, strconv.FormatInt(queueWait.Milliseconds(), 10))
+	// 声明 trailer 会强制 chunked 编码，响应体写完后才能补上费用
+	h.Set("Trailer", strings.Join([]string{service.RequestAnnotationTrailerCacheHit, service.RequestAnnotationTrailerCostEstimate}, ", "))
+}
+
+// writeAnnotationTrailers 在成功响应结束后等待使用量记录，填充缓存命中与费用估算 trailer。
+func (w *activeRequestWriter) writeAnnotationTrailers() {
+	if !w.annotated || w.ResponseWriter.Status() >= http.StatusBadRequest {
+		return
+	}
+	if platform, _, _ := w.req.Routing(); platform == "" {
+		return
+	}
+	cacheHit, cost, ok := w.annotations.WaitUsage(requestAnnotationUsageWait)
+	if !ok {
+		return
+	}
+	h := w.ResponseWriter.Header()
+	h.Set(service.RequestAnnotationTrailerCacheHit, strconv.FormatBool(cacheHit))
+	h.Set(service.RequestAnnotationTrailerCostEstimate, strconv.FormatFloat(cost, 'f', 6, 64))
+}
+
+// ActiveRequestMiddleware 把网关请求登记到在途请求表，供管理端查看与强制取消。需挂在 API Key 认证之后。
+// Key 开启 annotations_enabled 时同时输出网关注解（见 service.RequestAnnotationHeaderPlatform 等）。
+func ActiveRequestMiddleware(registry *service.ActiveRequestRegistry) gin.HandlerFunc {
+	if registry == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return func(c *gin.Context) {
+		info := service.ActiveRequestInfo{Method: c.Request.Method, Path: c.Request.URL.Path}
+		info.ClientRequestID, _ = c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+		var annotations *service.RequestAnnotations
+		if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil {
+			info.APIKeyID = apiKey.ID
+			info.APIKeyName = apiKey.Name
+			info.UserID = apiKey.UserID
+			info.GroupID = apiKey.GroupID
+			info.Priority = service.ResolveRequestPriority(c.GetHeader(service.RequestPriorityHeader), apiKey)
+			if apiKey.User != nil {
+				info.UserEmail = apiKey.User.Email
+			}
+			if apiKey.AnnotationsEnabled || service.BetaFeatureEnabled(c.Request.Context(), service.BetaFeatureAnnotations) {
+				annotations = service.NewRequestAnnotations()
+			}
+		}
+
+		req, ctx := registry.Begin(c.Request.Context(), info)
+		defer registry.End(req)
+		c.Request = c.Request.WithContext(service.WithRequestAnnotations(ctx, annotations))
+
+		writer := &activeRequestWriter{ResponseWriter: c.Writer, req: req, annotations: annotations}
+		c.Writer = writer
+		c.Next()
+		service.RecordGatewayCanaryOutcome(req, c.Writer.Status())
+		writer.writeAnnotationTrailers()
+		if c.Writer == writer {
+			c.Writer = writer.ResponseWriter
+		}
+	}
+}
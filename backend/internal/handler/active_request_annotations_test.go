@@ -0,0 +1,77 @@
+//go:build unit
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newActiveRequestAnnotationsRouter(apiKey *service.APIKey) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware.ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.Use(ActiveRequestMiddleware(service.NewActiveRequestRegistry(0)))
+	r.POST("/v1/messages", func(c *gin.Context) {
+		setOpsSelectedAccount(c, 1, service.PlatformAnthropic)
+		setOpsSelectedAccount(c, 2, service.PlatformAnthropic)
+		ctx := c.Request.Context()
+		go service.RequestAnnotationsFromContext(ctx).SetUsage(true, 0.0125)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return r
+}
+
+func TestActiveRequestMiddleware_Annotations(t *testing.T) {
+	r := newActiveRequestAnnotationsRouter(&service.APIKey{ID: 1, AnnotationsEnabled: true})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	resp := w.Result()
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, service.PlatformAnthropic, resp.Header.Get(service.RequestAnnotationHeaderPlatform))
+	require.Equal(t, "1", resp.Header.Get(service.RequestAnnotationHeaderRetryCount))
+	require.Equal(t, "0", resp.Header.Get(service.RequestAnnotationHeaderQueueWaitMs))
+	require.Equal(t, service.RequestPriorityNormal, resp.Header.Get(service.RequestAnnotationHeaderPriority))
+	require.Equal(t, "true", resp.Trailer.Get(service.RequestAnnotationTrailerCacheHit))
+	require.Equal(t, "0.012500", resp.Trailer.Get(service.RequestAnnotationTrailerCostEstimate))
+}
+
+func TestActiveRequestMiddleware_AnnotationsDisabledByDefault(t *testing.T) {
+	r := newActiveRequestAnnotationsRouter(&service.APIKey{ID: 1})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get(service.RequestAnnotationHeaderPlatform))
+	require.Empty(t, w.Header().Get(service.RequestAnnotationHeaderRetryCount))
+	require.Empty(t, w.Header().Get("Trailer"))
+}
+
+func TestActiveRequestMiddleware_PriorityHeaderClampedByKey(t *testing.T) {
+	r := newActiveRequestAnnotationsRouter(&service.APIKey{ID: 1, AnnotationsEnabled: true, MaxPriority: service.RequestPriorityNormal})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	req.Header.Set(service.RequestPriorityHeader, "high")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, service.RequestPriorityNormal, w.Header().Get(service.RequestAnnotationHeaderPriority))
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	req.Header.Set(service.RequestPriorityHeader, "low")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, service.RequestPriorityLow, w.Header().Get(service.RequestAnnotationHeaderPriority))
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SetAttributionService attaches the optional account attribution report service.
+func (h *AccountHandler) SetAttributionService(svc *service.AccountAttributionService) {
+	h.attribution = svc
+}
+
+// GetAttributionReport 账号（共享订阅）消耗归因报告：请求量、tokens、等价成本、Top 用户/Key 与峰值并发。
+// 时间范围优先使用 start_date/end_date（YYYY-MM-DD，按 timezone 解析，end_date 含当天），否则取最近 days 天（默认 30）。
+// GET /api/v1/admin/accounts/:id/attribution
+func (h *AccountHandler) GetAttributionReport(c *gin.Context) {
+	if h.attribution == nil {
+		response.InternalError(c, "Account attribution service is not configured")
+		return
+	}
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	var startTime, endTime time.Time
+	userTZ := c.Query("timezone")
+	if startStr, endStr := c.Query("start_date"), c.Query("end_date"); startStr != "" || endStr != "" {
+		startTime, err = timezone.ParseInUserLocation("2006-01-02", startStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		endTime, err = timezone.ParseInUserLocation("2006-01-02", endStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		endTime = endTime.AddDate(0, 0, 1)
+	} else {
+		days := 30
+		if daysStr := c.Query("days"); daysStr != "" {
+			if d, err := strconv.Atoi(daysStr); err == nil && d > 0 && d <= 90 {
+				days = d
+			}
+		}
+		now := timezone.Now()
+		endTime = timezone.StartOfDay(now.AddDate(0, 0, 1))
+		startTime = timezone.StartOfDay(now.AddDate(0, 0, -days+1))
+	}
+
+	topN := 0
+	if topStr := c.Query("top"); topStr != "" {
+		topN, err = strconv.Atoi(topStr)
+		if err != nil || topN <= 0 {
+			response.BadRequest(c, "Invalid top")
+			return
+		}
+	}
+
+	report, err := h.attribution.GetReport(c.Request.Context(), accountID, startTime, endTime, topN)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
@@ -0,0 +1,23 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SetContentPolicyCooldownService attaches the content-policy cooldown service used for violation stats.
+func (h *AccountHandler) SetContentPolicyCooldownService(svc *service.ContentPolicyCooldownService) {
+	h.contentPolicy = svc
+}
+
+// GetContentPolicyStats 各账号窗口内的上游内容策略违规次数与冷却状态（Redis 共享统计）。
+// GET /api/v1/admin/accounts/content-policy
+func (h *AccountHandler) GetContentPolicyStats(c *gin.Context) {
+	stats, err := h.contentPolicy.Stats(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, stats)
+}
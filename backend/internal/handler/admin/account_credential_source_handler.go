@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountCredentialSourceHandler 账号凭据外部密钥库映射与同步接口。
+type AccountCredentialSourceHandler struct {
+	syncService *service.AccountCredentialSyncService
+}
+
+// NewAccountCredentialSourceHandler 创建账号凭据来源处理器。
+func NewAccountCredentialSourceHandler(syncService *service.AccountCredentialSyncService) *AccountCredentialSourceHandler {
+	return &AccountCredentialSourceHandler{syncService: syncService}
+}
+
+// UpsertAccountCredentialSourceRequest 写入账号凭据来源请求。
+type UpsertAccountCredentialSourceRequest struct {
+	Provider   string `json:"provider" binding:"required"`
+	SecretPath string `json:"secret_path" binding:"required,max=512"`
+	// FieldMap 凭据键 -> 密钥字段；省略时把密钥的全部顶层字段写入凭据
+	FieldMap map[string]string `json:"field_map"`
+}
+
+// List 列出全部账号凭据来源及最近同步状态。
+// GET /api/v1/admin/credential-sources
+func (h *AccountCredentialSourceHandler) List(c *gin.Context) {
+	items, err := h.syncService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if items == nil {
+		items = []*service.AccountCredentialSource{}
+	}
+	response.Success(c, gin.H{
+		"enabled": h.syncService.Enabled(),
+		"items":   items,
+	})
+}
+
+// SyncAll 立即同步全部账号凭据来源。
+// POST /api/v1/admin/credential-sources/sync
+func (h *AccountCredentialSourceHandler) SyncAll(c *gin.Context) {
+	results, err := h.syncService.SyncAll(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if results == nil {
+		results = []*service.AccountCredentialSyncResult{}
+	}
+	response.Success(c, gin.H{"items": results})
+}
+
+// Get 查询账号的凭据来源。
+// GET /api/v1/admin/accounts/:id/credential-source
+func (h *AccountCredentialSourceHandler) Get(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	source, err := h.syncService.Get(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, source)
+}
+
+// Upsert 创建或覆盖账号的凭据来源（不会立即同步）。
+// PUT /api/v1/admin/accounts/:id/credential-source
+func (h *AccountCredentialSourceHandler) Upsert(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	var req UpsertAccountCredentialSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	source, err := h.syncService.Upsert(c.Request.Context(), &service.UpsertAccountCredentialSourceInput{
+		AccountID:  accountID,
+		Provider:   req.Provider,
+		SecretPath: req.SecretPath,
+		FieldMap:   req.FieldMap,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, source)
+}
+
+// Delete 删除账号的凭据来源；账号现有凭据保持不变。
+// DELETE /api/v1/admin/accounts/:id/credential-source
+func (h *AccountCredentialSourceHandler) Delete(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	if err := h.syncService.Delete(c.Request.Context(), accountID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Account credential source deleted successfully"})
+}
+
+// Sync 立即从外部密钥库同步该账号的凭据。
+// POST /api/v1/admin/accounts/:id/credential-source/sync
+func (h *AccountCredentialSourceHandler) Sync(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	result, err := h.syncService.SyncAccount(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
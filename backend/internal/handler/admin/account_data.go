@@ -48,6 +48,7 @@ type DataProxy struct {
 	FallbackMode    string `json:"fallback_mode,omitempty"`     // none/direct/proxy
 	BackupProxyName string `json:"backup_proxy_name,omitempty"` // 备用代理 name（跨实例按 name 反查）
 	ExpiryWarnDays  int    `json:"expiry_warn_days,omitempty"`
+	DataResidency   string `json:"data_residency,omitempty"` // eu/us/asia
 }
 
 // DataAccount 是管理员显式备份导出使用的账号结构，故意不走 dto.Account 的脱敏路径，
@@ -182,6 +183,7 @@ func (h *AccountHandler) ExportData(c *gin.Context) {
 			FallbackMode:    p.FallbackMode,
 			BackupProxyName: backupProxyName,
 			ExpiryWarnDays:  p.ExpiryWarnDays,
+			DataResidency:   p.DataResidency,
 		})
 	}
 
@@ -360,6 +362,7 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 			FallbackMode:   fallbackMode,
 			BackupProxyID:  backupProxyID,
 			ExpiryWarnDays: item.ExpiryWarnDays,
+			DataResidency:  item.DataResidency,
 		})
 		if createErr != nil {
 			result.ProxyFailed++
@@ -0,0 +1,680 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// 支持导入的外部工具导出格式
+const (
+	// ExternalImportFormatClaudeCredentials Claude Code 的 ~/.claude/.credentials.json
+	ExternalImportFormatClaudeCredentials = "claude_credentials"
+	// ExternalImportFormatGeminiCLI Gemini CLI 的 ~/.gemini/oauth_creds.json
+	ExternalImportFormatGeminiCLI = "gemini_cli"
+	// ExternalImportFormatClaudeRelayService claude-relay-service 同步接口的导出 JSON
+	// （/admin/sync/export-accounts?include_secrets=true 的响应），复用 CRS 同步的映射规则
+	ExternalImportFormatClaudeRelayService = "claude_relay_service"
+	// ExternalImportFormatNewAPIChannels new-api / one-api 的渠道导出
+	ExternalImportFormatNewAPIChannels = "new_api_channels"
+)
+
+// new-api / one-api 渠道类型编号
+const (
+	newAPIChannelTypeOpenAI    = 1
+	newAPIChannelTypeAnthropic = 14
+	newAPIChannelTypeGemini    = 24
+)
+
+const maxExternalImportEntries = 1000
+
+// ExternalAccountImportRequest 从其他中转工具的导出文件批量导入账号。
+type ExternalAccountImportRequest struct {
+	Format string `json:"format" binding:"required"`
+	// Content 导出文件内容：JSON 对象/数组，或包含 JSON 文本的字符串
+	Content              json.RawMessage `json:"content" binding:"required"`
+	NamePrefix           string          `json:"name_prefix"`
+	GroupIDs             []int64         `json:"group_ids"`
+	ProxyID              *int64          `json:"proxy_id"`
+	Concurrency          *int            `json:"concurrency"`
+	Priority             *int            `json:"priority"`
+	SkipDefaultGroupBind *bool           `json:"skip_default_group_bind"`
+	// DryRun 只返回映射报告，不创建账号
+	DryRun bool `json:"dry_run"`
+}
+
+// ExternalAccountImportResult 导入结果与逐条映射报告。
+type ExternalAccountImportResult struct {
+	Format  string                      `json:"format"`
+	DryRun  bool                        `json:"dry_run"`
+	Total   int                         `json:"total"`
+	Created int                         `json:"created"`
+	Updated int                         `json:"updated"`
+	Valid   int                         `json:"valid"`
+	Skipped int                         `json:"skipped"`
+	Failed  int                         `json:"failed"`
+	Items   []ExternalAccountImportItem `json:"items"`
+}
+
+// ExternalAccountImportItem 单条源记录的映射报告。
+type ExternalAccountImportItem struct {
+	// Source 源记录位置，如 "claudeAccounts[2]"
+	Source    string `json:"source"`
+	Name      string `json:"name,omitempty"`
+	Platform  string `json:"platform,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Action    string `json:"action"` // created / updated / valid（dry run）/ skipped / failed
+	AccountID int64  `json:"account_id,omitempty"`
+	// Mapped 源字段到 sub2api 字段的映射，如 "claudeAiOauth.accessToken -> credentials.access_token"
+	Mapped []string `json:"mapped,omitempty"`
+	// Ignored 未导入的源字段
+	Ignored  []string `json:"ignored,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Message  string   `json:"message,omitempty"`
+}
+
+// externalImportCandidate 转换后的待导入账号；skip 非空表示该记录不适用。
+type externalImportCandidate struct {
+	report  ExternalAccountImportItem
+	account DataAccount
+	skip    string
+	err     error
+}
+
+func (c *externalImportCandidate) mapField(source, target string) {
+	c.report.Mapped = append(c.report.Mapped, source+" -> "+target)
+}
+
+func (c *externalImportCandidate) warn(format string, args ...any) {
+	c.report.Warnings = append(c.report.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ImportExternalAccounts 从其他中转工具的导出格式导入账号
+// POST /api/v1/admin/accounts/import/external
+func (h *AccountHandler) ImportExternalAccounts(c *gin.Context) {
+	var req ExternalAccountImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if req.Concurrency != nil && *req.Concurrency < 0 {
+		response.BadRequest(c, "concurrency must be >= 0")
+		return
+	}
+	if req.Priority != nil && *req.Priority < 0 {
+		response.BadRequest(c, "priority must be >= 0")
+		return
+	}
+
+	req.Format = strings.TrimSpace(req.Format)
+
+	content, err := decodeExternalImportContent(req.Content)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Format == ExternalImportFormatClaudeRelayService {
+		h.importCRSExport(c, req, content)
+		return
+	}
+	candidates, err := convertExternalImportContent(req.Format, content, time.Now())
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if len(candidates) == 0 {
+		response.BadRequest(c, "no accounts found in content")
+		return
+	}
+	if len(candidates) > maxExternalImportEntries {
+		response.BadRequest(c, fmt.Sprintf("too many accounts in one import (max %d)", maxExternalImportEntries))
+		return
+	}
+
+	if req.DryRun {
+		response.Success(c, buildExternalImportReport(req, candidates))
+		return
+	}
+	executeAdminIdempotentJSON(c, "admin.accounts.import_external", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		return h.importExternalAccounts(ctx, req, candidates)
+	})
+}
+
+// buildExternalImportReport 只做映射与校验（dry run）。
+func buildExternalImportReport(req ExternalAccountImportRequest, candidates []*externalImportCandidate) ExternalAccountImportResult {
+	result := ExternalAccountImportResult{Format: req.Format, DryRun: true, Total: len(candidates)}
+	for _, candidate := range candidates {
+		applyExternalImportDefaults(req, candidate)
+		if !settleExternalImportCandidate(&result, candidate) {
+			continue
+		}
+		candidate.report.Action = "valid"
+		result.Valid++
+		result.Items = append(result.Items, candidate.report)
+	}
+	return result
+}
+
+func (h *AccountHandler) importExternalAccounts(ctx context.Context, req ExternalAccountImportRequest, candidates []*externalImportCandidate) (ExternalAccountImportResult, error) {
+	result := ExternalAccountImportResult{Format: req.Format, Total: len(candidates)}
+	skipDefaultGroupBind := false
+	if req.SkipDefaultGroupBind != nil {
+		skipDefaultGroupBind = *req.SkipDefaultGroupBind
+	}
+
+	for _, candidate := range candidates {
+		applyExternalImportDefaults(req, candidate)
+		if !settleExternalImportCandidate(&result, candidate) {
+			continue
+		}
+
+		item := candidate.account
+		created, err := h.adminService.CreateAccount(ctx, &service.CreateAccountInput{
+			Name:                 item.Name,
+			Notes:                item.Notes,
+			Platform:             item.Platform,
+			Type:                 item.Type,
+			Credentials:          item.Credentials,
+			Extra:                item.Extra,
+			ProxyID:              req.ProxyID,
+			Concurrency:          item.Concurrency,
+			Priority:             item.Priority,
+			GroupIDs:             req.GroupIDs,
+			ExpiresAt:            item.ExpiresAt,
+			AutoPauseOnExpired:   item.AutoPauseOnExpired,
+			SkipDefaultGroupBind: skipDefaultGroupBind,
+		})
+		if err != nil {
+			result.Failed++
+			candidate.report.Action = "failed"
+			candidate.report.Message = err.Error()
+			result.Items = append(result.Items, candidate.report)
+			continue
+		}
+		h.scheduleGrokImportProbe(created)
+		result.Created++
+		candidate.report.Action = "created"
+		candidate.report.AccountID = created.ID
+		result.Items = append(result.Items, candidate.report)
+	}
+	return result, nil
+}
+
+// importCRSExport 导入 CRS 导出文件：与在线 CRS 同步共用映射与去重（按 crs_account_id 更新已导入账号），
+// 因此只支持 CRS 同步本身的选项。
+func (h *AccountHandler) importCRSExport(c *gin.Context, req ExternalAccountImportRequest, content any) {
+	if h.crsSyncService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "CRS import is not available")
+		return
+	}
+	if req.NamePrefix != "" || len(req.GroupIDs) > 0 || req.ProxyID != nil || req.Concurrency != nil || req.Priority != nil || req.SkipDefaultGroupBind != nil {
+		response.BadRequest(c, "claude_relay_service import does not support name_prefix, group_ids, proxy_id, concurrency, priority or skip_default_group_bind")
+		return
+	}
+	raw, err := json.Marshal(content)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.DryRun {
+		preview, err := h.crsSyncService.PreviewCRSExport(c.Request.Context(), raw)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		result := ExternalAccountImportResult{Format: req.Format, DryRun: true}
+		for _, account := range preview.NewAccounts {
+			result.Items = append(result.Items, crsPreviewImportItem(account, ""))
+		}
+		for _, account := range preview.ExistingAccounts {
+			result.Items = append(result.Items, crsPreviewImportItem(account, "already imported; will be updated"))
+		}
+		result.Total = len(result.Items)
+		result.Valid = result.Total
+		response.Success(c, result)
+		return
+	}
+
+	executeAdminIdempotentJSON(c, "admin.accounts.import_external", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		synced, err := h.crsSyncService.ImportCRSExport(ctx, raw, service.SyncFromCRSInput{SyncProxies: true})
+		if err != nil {
+			return nil, infraerrors.BadRequest("CRS_IMPORT_INVALID", err.Error())
+		}
+		result := ExternalAccountImportResult{
+			Format:  req.Format,
+			Total:   len(synced.Items),
+			Created: synced.Created,
+			Updated: synced.Updated,
+			Skipped: synced.Skipped,
+			Failed:  synced.Failed,
+		}
+		for _, item := range synced.Items {
+			result.Items = append(result.Items, ExternalAccountImportItem{
+				Source:  crsImportSource(item.Kind, item.CRSAccountID),
+				Name:    item.Name,
+				Action:  item.Action,
+				Message: item.Error,
+			})
+		}
+		return result, nil
+	})
+}
+
+func crsPreviewImportItem(account service.CRSPreviewAccount, message string) ExternalAccountImportItem {
+	return ExternalAccountImportItem{
+		Source:   crsImportSource(account.Kind, account.CRSAccountID),
+		Name:     account.Name,
+		Platform: account.Platform,
+		Type:     account.Type,
+		Action:   "valid",
+		Message:  message,
+	}
+}
+
+func crsImportSource(kind, crsAccountID string) string {
+	return kind + "[" + crsAccountID + "]"
+}
+
+// settleExternalImportCandidate 记录跳过/失败的候选并返回 false；可导入时返回 true。
+func settleExternalImportCandidate(result *ExternalAccountImportResult, candidate *externalImportCandidate) bool {
+	switch {
+	case candidate.skip != "":
+		result.Skipped++
+		candidate.report.Action = "skipped"
+		candidate.report.Message = candidate.skip
+	case candidate.err != nil:
+		result.Failed++
+		candidate.report.Action = "failed"
+		candidate.report.Message = candidate.err.Error()
+	default:
+		if err := validateDataAccount(candidate.account); err != nil {
+			result.Failed++
+			candidate.report.Action = "failed"
+			candidate.report.Message = err.Error()
+			break
+		}
+		return true
+	}
+	result.Items = append(result.Items, candidate.report)
+	return false
+}
+
+func applyExternalImportDefaults(req ExternalAccountImportRequest, candidate *externalImportCandidate) {
+	if candidate.skip != "" || candidate.err != nil {
+		return
+	}
+	account := &candidate.account
+	if prefix := strings.TrimSpace(req.NamePrefix); prefix != "" {
+		account.Name = prefix + account.Name
+	}
+	candidate.report.Name = account.Name
+	candidate.report.Platform = account.Platform
+	candidate.report.Type = account.Type
+
+	account.Concurrency = 3
+	if req.Concurrency != nil {
+		account.Concurrency = *req.Concurrency
+	}
+	account.Priority = 50
+	if req.Priority != nil {
+		account.Priority = *req.Priority
+	}
+}
+
+// decodeExternalImportContent 接受 JSON 值本身，或粘贴文件内容得到的 JSON 字符串。
+func decodeExternalImportContent(raw json.RawMessage) (any, error) {
+	var value any
+	if err := unmarshalExternalImportJSON(raw, &value); err != nil {
+		return nil, fmt.Errorf("content is not valid JSON: %w", err)
+	}
+	if text, ok := value.(string); ok {
+		text = strings.TrimSpace(text)
+		if !looksLikeJSON(text) {
+			return nil, errors.New("content must be a JSON object or array")
+		}
+		if err := unmarshalExternalImportJSON([]byte(text), &value); err != nil {
+			return nil, fmt.Errorf("content is not valid JSON: %w", err)
+		}
+	}
+	switch value.(type) {
+	case map[string]any, []any:
+		return value, nil
+	default:
+		return nil, errors.New("content must be a JSON object or array")
+	}
+}
+
+func unmarshalExternalImportJSON(data []byte, value *any) error {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+func convertExternalImportContent(format string, content any, now time.Time) ([]*externalImportCandidate, error) {
+	switch format {
+	case ExternalImportFormatClaudeCredentials:
+		return convertClaudeCredentialsImport(content, now), nil
+	case ExternalImportFormatGeminiCLI:
+		return convertGeminiCLIImport(content, now), nil
+	case ExternalImportFormatNewAPIChannels:
+		return convertNewAPIChannelsImport(content)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+}
+
+// externalImportObjects 把顶层对象或数组展开为对象列表；非对象元素以 nil 占位，由调用方报告。
+func externalImportObjects(content any) []map[string]any {
+	switch v := content.(type) {
+	case map[string]any:
+		return []map[string]any{v}
+	case []any:
+		out := make([]map[string]any, 0, len(v))
+		for _, item := range v {
+			obj, _ := item.(map[string]any)
+			out = append(out, obj)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// externalImportNestedObject 读取对象字段；部分工具把嵌套对象序列化成 JSON 字符串保存。
+func externalImportNestedObject(obj map[string]any, key string) (map[string]any, bool, error) {
+	value, ok := obj[key]
+	if !ok || value == nil {
+		return nil, false, nil
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true, nil
+	case string:
+		text := strings.TrimSpace(v)
+		if text == "" {
+			return nil, false, nil
+		}
+		if !looksLikeJSON(text) {
+			return nil, true, fmt.Errorf("%s is not plain JSON (encrypted export?); export with decrypted credentials", key)
+		}
+		var decoded any
+		if err := unmarshalExternalImportJSON([]byte(text), &decoded); err != nil {
+			return nil, true, fmt.Errorf("%s is not valid JSON: %w", key, err)
+		}
+		mapped, ok := decoded.(map[string]any)
+		if !ok {
+			return nil, true, fmt.Errorf("%s must be a JSON object", key)
+		}
+		return mapped, true, nil
+	default:
+		return nil, true, fmt.Errorf("%s must be a JSON object", key)
+	}
+}
+
+// ignoredExternalFields 返回未被映射的源字段（排序后便于阅读）。
+func ignoredExternalFields(obj map[string]any, prefix string, used ...string) []string {
+	usedSet := make(map[string]struct{}, len(used))
+	for _, key := range used {
+		usedSet[key] = struct{}{}
+	}
+	var ignored []string
+	for key := range obj {
+		if _, ok := usedSet[key]; ok {
+			continue
+		}
+		ignored = append(ignored, prefix+key)
+	}
+	sort.Strings(ignored)
+	return ignored
+}
+
+// applyExternalOAuthExpiry 写入 expires_at；没有 refresh token 的过期凭据无法使用，直接判为失败。
+func applyExternalOAuthExpiry(candidate *externalImportCandidate, source string, expiresAt time.Time, ok bool, now time.Time) {
+	credentials := candidate.account.Credentials
+	if ok {
+		credentials["expires_at"] = strconv.FormatInt(expiresAt.Unix(), 10)
+		candidate.mapField(source, "credentials.expires_at")
+	}
+	if _, hasRefresh := credentials["refresh_token"]; hasRefresh {
+		return
+	}
+	if ok && !expiresAt.After(now) {
+		candidate.err = errors.New("access token is expired and no refresh token is available")
+		return
+	}
+	candidate.warn("no refresh token; the account stops working when the access token expires")
+}
+
+// claudeOAuthToCredentials 映射 Claude Code 风格的 claudeAiOauth 对象（驼峰字段、毫秒时间戳）。
+func claudeOAuthToCredentials(candidate *externalImportCandidate, oauth map[string]any, prefix string, now time.Time) {
+	credentials := map[string]any{}
+	candidate.account.Credentials = credentials
+
+	accessToken := firstCodexString(oauth, []string{"accessToken"}, []string{"access_token"})
+	if accessToken == "" {
+		candidate.err = errors.New("access token is missing")
+		return
+	}
+	credentials["access_token"] = accessToken
+	candidate.mapField(prefix+"accessToken", "credentials.access_token")
+	if refreshToken := firstCodexString(oauth, []string{"refreshToken"}, []string{"refresh_token"}); refreshToken != "" {
+		credentials["refresh_token"] = refreshToken
+		candidate.mapField(prefix+"refreshToken", "credentials.refresh_token")
+	}
+	if scopes, ok := oauth["scopes"].([]any); ok && len(scopes) > 0 {
+		parts := make([]string, 0, len(scopes))
+		for _, scope := range scopes {
+			if s := codexStringValue(scope); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		credentials["scope"] = strings.Join(parts, " ")
+		candidate.mapField(prefix+"scopes", "credentials.scope")
+	} else if scope := firstCodexString(oauth, []string{"scope"}); scope != "" {
+		credentials["scope"] = scope
+		candidate.mapField(prefix+"scope", "credentials.scope")
+	}
+	expiresAt, ok := firstCodexTime(oauth, []string{"expiresAt"}, []string{"expires_at"})
+	applyExternalOAuthExpiry(candidate, prefix+"expiresAt", expiresAt, ok, now)
+	candidate.report.Ignored = append(candidate.report.Ignored,
+		ignoredExternalFields(oauth, prefix, "accessToken", "access_token", "refreshToken", "refresh_token", "scopes", "scope", "expiresAt", "expires_at")...)
+}
+
+// convertClaudeCredentialsImport 转换 Claude Code 凭据文件：{"claudeAiOauth": {...}}，可为数组。
+func convertClaudeCredentialsImport(content any, now time.Time) []*externalImportCandidate {
+	objects := externalImportObjects(content)
+	candidates := make([]*externalImportCandidate, 0, len(objects))
+	for i, obj := range objects {
+		candidate := &externalImportCandidate{
+			report:  ExternalAccountImportItem{Source: fmt.Sprintf("[%d]", i)},
+			account: DataAccount{Name: fmt.Sprintf("claude-code-%d", i+1), Platform: service.PlatformAnthropic, Type: service.AccountTypeOAuth},
+		}
+		candidates = append(candidates, candidate)
+		if obj == nil {
+			candidate.err = errors.New("entry must be a JSON object")
+			continue
+		}
+		oauth, found, err := externalImportNestedObject(obj, "claudeAiOauth")
+		if err != nil {
+			candidate.err = err
+			continue
+		}
+		prefix := "claudeAiOauth."
+		if !found {
+			// 也接受直接粘贴 claudeAiOauth 内层对象
+			oauth, prefix = obj, ""
+		} else {
+			candidate.report.Ignored = ignoredExternalFields(obj, "", "claudeAiOauth")
+		}
+		claudeOAuthToCredentials(candidate, oauth, prefix, now)
+	}
+	return candidates
+}
+
+// convertGeminiCLIImport 转换 Gemini CLI 凭据文件（Google OAuth token JSON），按 Code Assist 账号导入。
+func convertGeminiCLIImport(content any, now time.Time) []*externalImportCandidate {
+	objects := externalImportObjects(content)
+	candidates := make([]*externalImportCandidate, 0, len(objects))
+	for i, obj := range objects {
+		candidate := &externalImportCandidate{
+			report:  ExternalAccountImportItem{Source: fmt.Sprintf("[%d]", i)},
+			account: DataAccount{Name: fmt.Sprintf("gemini-cli-%d", i+1), Platform: service.PlatformGemini, Type: service.AccountTypeOAuth},
+		}
+		candidates = append(candidates, candidate)
+		if obj == nil {
+			candidate.err = errors.New("entry must be a JSON object")
+			continue
+		}
+		geminiOAuthToCredentials(candidate, obj, "", "", now)
+	}
+	return candidates
+}
+
+// geminiOAuthToCredentials 映射 Google OAuth token JSON（snake_case，expiry_date 为毫秒）。
+// 未提供 projectID 时开启 auto_detect_project_id，首次使用时探测 Code Assist 项目。
+func geminiOAuthToCredentials(candidate *externalImportCandidate, oauth map[string]any, prefix, projectID string, now time.Time) {
+	credentials := map[string]any{"oauth_type": "code_assist"}
+	candidate.account.Credentials = credentials
+
+	accessToken := firstCodexString(oauth, []string{"access_token"}, []string{"accessToken"})
+	if accessToken == "" {
+		candidate.err = errors.New("access token is missing")
+		return
+	}
+	credentials["access_token"] = accessToken
+	candidate.mapField(prefix+"access_token", "credentials.access_token")
+	if refreshToken := firstCodexString(oauth, []string{"refresh_token"}, []string{"refreshToken"}); refreshToken != "" {
+		credentials["refresh_token"] = refreshToken
+		candidate.mapField(prefix+"refresh_token", "credentials.refresh_token")
+	}
+	for _, key := range []string{"token_type", "scope"} {
+		if value := firstCodexString(oauth, []string{key}); value != "" {
+			credentials[key] = value
+			candidate.mapField(prefix+key, "credentials."+key)
+		}
+	}
+	if idToken := firstCodexString(oauth, []string{"id_token"}); idToken != "" {
+		if claims, err := decodeCodexJWTClaims(idToken); err == nil && claims.Email != "" {
+			candidate.account.Name = claims.Email
+			candidate.mapField(prefix+"id_token.email", "name")
+		}
+	}
+	if projectID != "" {
+		credentials["project_id"] = projectID
+		candidate.mapField("projectId", "credentials.project_id")
+	} else {
+		credentials["auto_detect_project_id"] = "true"
+		candidate.warn("project_id will be detected on first use")
+	}
+	expiresAt, ok := firstCodexTime(oauth, []string{"expiry_date"}, []string{"expiry"}, []string{"expires_at"})
+	applyExternalOAuthExpiry(candidate, prefix+"expiry_date", expiresAt, ok, now)
+	candidate.report.Ignored = append(candidate.report.Ignored,
+		ignoredExternalFields(oauth, prefix, "access_token", "accessToken", "refresh_token", "refreshToken", "token_type", "scope", "id_token", "expiry_date", "expiry", "expires_at")...)
+}
+
+// convertNewAPIChannelsImport 转换 new-api / one-api 渠道导出为 API Key 账号。
+// 多 Key 渠道（按换行分隔）逐个 Key 拆成独立账号；不支持的渠道类型报告为跳过。
+func convertNewAPIChannelsImport(content any) ([]*externalImportCandidate, error) {
+	if root, ok := content.(map[string]any); ok {
+		for _, key := range []string{"data", "channels", "items"} {
+			if items, ok := root[key].([]any); ok {
+				content = items
+				break
+			}
+		}
+	}
+	channels, ok := content.([]any)
+	if !ok {
+		return nil, errors.New("new_api_channels content must be a channel array")
+	}
+
+	var candidates []*externalImportCandidate
+	for i, raw := range channels {
+		source := fmt.Sprintf("[%d]", i)
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			candidates = append(candidates, &externalImportCandidate{
+				report: ExternalAccountImportItem{Source: source},
+				err:    errors.New("entry must be a JSON object"),
+			})
+			continue
+		}
+		name := firstCodexString(obj, []string{"name"})
+		if name == "" {
+			name = "channel-" + firstCodexString(obj, []string{"id"})
+		}
+		channelType, _ := strconv.Atoi(firstCodexString(obj, []string{"type"}))
+		platform := newAPIChannelPlatform(channelType)
+		if platform == "" {
+			candidates = append(candidates, &externalImportCandidate{
+				report: ExternalAccountImportItem{Source: source, Name: name},
+				skip:   fmt.Sprintf("unsupported channel type %d", channelType),
+			})
+			continue
+		}
+
+		var keys []string
+		for _, line := range strings.Split(firstCodexString(obj, []string{"key"}), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				keys = append(keys, line)
+			}
+		}
+		if len(keys) == 0 {
+			candidates = append(candidates, &externalImportCandidate{
+				report: ExternalAccountImportItem{Source: source, Name: name},
+				err:    errors.New("channel key is empty"),
+			})
+			continue
+		}
+		baseURL := firstCodexString(obj, []string{"base_url"})
+		ignored := ignoredExternalFields(obj, "", "id", "name", "type", "key", "base_url", "status")
+		for k, apiKey := range keys {
+			candidate := &externalImportCandidate{
+				report:  ExternalAccountImportItem{Source: source, Ignored: ignored},
+				account: DataAccount{Name: name, Platform: platform, Type: service.AccountTypeAPIKey},
+			}
+			if len(keys) > 1 {
+				candidate.report.Source = fmt.Sprintf("%s.key[%d]", source, k)
+				candidate.account.Name = fmt.Sprintf("%s #%d", name, k+1)
+			}
+			candidate.mapField("name", "name")
+			candidate.mapField("type", "platform")
+			candidate.account.Credentials = map[string]any{"api_key": apiKey}
+			candidate.mapField("key", "credentials.api_key")
+			if baseURL != "" {
+				candidate.account.Credentials["base_url"] = baseURL
+				candidate.mapField("base_url", "credentials.base_url")
+			}
+			if status, _ := strconv.Atoi(firstCodexString(obj, []string{"status"})); status > 1 {
+				candidate.warn("channel is disabled in the source (status %d); imported as active", status)
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+func newAPIChannelPlatform(channelType int) string {
+	switch channelType {
+	case newAPIChannelTypeOpenAI:
+		return service.PlatformOpenAI
+	case newAPIChannelTypeAnthropic:
+		return service.PlatformAnthropic
+	case newAPIChannelTypeGemini:
+		return service.PlatformGemini
+	default:
+		return ""
+	}
+}
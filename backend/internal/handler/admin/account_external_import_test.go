@@ -0,0 +1,175 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeExternalImportTestContent(t *testing.T, raw string) any {
+	t.Helper()
+	content, err := decodeExternalImportContent(json.RawMessage(raw))
+	require.NoError(t, err)
+	return content
+}
+
+func TestConvertClaudeCredentialsImportMapsOAuthFields(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	content := decodeExternalImportTestContent(t, `{
+		"claudeAiOauth": {
+			"accessToken": "sk-ant-oat01-access",
+			"refreshToken": "sk-ant-ort01-refresh",
+			"expiresAt": 1700003600000,
+			"scopes": ["user:inference", "user:profile"],
+			"subscriptionType": "max"
+		}
+	}`)
+
+	candidates, err := convertExternalImportContent(ExternalImportFormatClaudeCredentials, content, now)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	candidate := candidates[0]
+	require.NoError(t, candidate.err)
+	require.Equal(t, service.PlatformAnthropic, candidate.account.Platform)
+	require.Equal(t, service.AccountTypeOAuth, candidate.account.Type)
+	require.Equal(t, "sk-ant-oat01-access", candidate.account.Credentials["access_token"])
+	require.Equal(t, "sk-ant-ort01-refresh", candidate.account.Credentials["refresh_token"])
+	require.Equal(t, "1700003600", candidate.account.Credentials["expires_at"])
+	require.Equal(t, "user:inference user:profile", candidate.account.Credentials["scope"])
+	require.Contains(t, candidate.report.Mapped, "claudeAiOauth.accessToken -> credentials.access_token")
+	require.Equal(t, []string{"claudeAiOauth.subscriptionType"}, candidate.report.Ignored)
+}
+
+func TestConvertClaudeCredentialsImportRejectsExpiredTokenWithoutRefresh(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	content := decodeExternalImportTestContent(t, `{"claudeAiOauth": {"accessToken": "expired", "expiresAt": 1600000000000}}`)
+
+	candidates, err := convertExternalImportContent(ExternalImportFormatClaudeCredentials, content, now)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	require.ErrorContains(t, candidates[0].err, "expired")
+}
+
+func TestConvertGeminiCLIImportUsesIDTokenEmailAndDetectsProject(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"email":"dev@example.com"}`))
+	raw, err := json.Marshal(map[string]any{
+		"access_token":  "ya29.access",
+		"refresh_token": "1//refresh",
+		"token_type":    "Bearer",
+		"id_token":      "e30." + payload + ".sig",
+		"expiry_date":   1700003600000,
+	})
+	require.NoError(t, err)
+	// 也接受把文件内容作为 JSON 字符串粘贴
+	quoted, err := json.Marshal(string(raw))
+	require.NoError(t, err)
+
+	content, err := decodeExternalImportContent(quoted)
+	require.NoError(t, err)
+	candidates, err := convertExternalImportContent(ExternalImportFormatGeminiCLI, content, time.Unix(1_700_000_000, 0))
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	candidate := candidates[0]
+	require.NoError(t, candidate.err)
+	require.Equal(t, "dev@example.com", candidate.account.Name)
+	require.Equal(t, service.PlatformGemini, candidate.account.Platform)
+	require.Equal(t, "code_assist", candidate.account.Credentials["oauth_type"])
+	require.Equal(t, "true", candidate.account.Credentials["auto_detect_project_id"])
+	require.Equal(t, "1//refresh", candidate.account.Credentials["refresh_token"])
+}
+
+func TestConvertNewAPIChannelsImportSplitsKeysAndSkipsUnsupportedTypes(t *testing.T) {
+	content := decodeExternalImportTestContent(t, `{"data": [
+		{"id": 1, "name": "openai-pool", "type": 1, "key": "sk-a\nsk-b\n", "base_url": "https://api.example.com", "models": "gpt-4o"},
+		{"id": 2, "name": "midjourney", "type": 2, "key": "mj"}
+	]}`)
+
+	candidates, err := convertExternalImportContent(ExternalImportFormatNewAPIChannels, content, time.Now())
+	require.NoError(t, err)
+	require.Len(t, candidates, 3)
+
+	require.Equal(t, "openai-pool #1", candidates[0].account.Name)
+	require.Equal(t, "[0].key[0]", candidates[0].report.Source)
+	require.Equal(t, "sk-a", candidates[0].account.Credentials["api_key"])
+	require.Equal(t, "https://api.example.com", candidates[0].account.Credentials["base_url"])
+	require.Equal(t, []string{"models"}, candidates[0].report.Ignored)
+	require.Equal(t, "sk-b", candidates[1].account.Credentials["api_key"])
+	require.Equal(t, "unsupported channel type 2", candidates[2].skip)
+}
+
+func TestConvertExternalImportContentRejectsUnknownFormat(t *testing.T) {
+	_, err := convertExternalImportContent("unknown", map[string]any{}, time.Now())
+	require.ErrorContains(t, err, "unsupported import format")
+}
+
+func TestImportExternalAccountsDryRunDoesNotCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newCodexImportMemoryAdminService(nil)
+	handler := NewAccountHandler(svc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	router := gin.New()
+	router.POST("/api/v1/admin/accounts/import/external", handler.ImportExternalAccounts)
+
+	body, err := json.Marshal(map[string]any{
+		"format":  ExternalImportFormatNewAPIChannels,
+		"content": []any{map[string]any{"name": "claude", "type": 14, "key": "sk-ant-api"}},
+		"dry_run": true,
+	})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/accounts/import/external", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Data ExternalAccountImportResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.True(t, resp.Data.DryRun)
+	require.Equal(t, 1, resp.Data.Valid)
+	require.Equal(t, "valid", resp.Data.Items[0].Action)
+	require.Equal(t, service.PlatformAnthropic, resp.Data.Items[0].Platform)
+	require.Empty(t, svc.createdAccounts)
+}
+
+func TestImportExternalAccountsCreatesValidCandidates(t *testing.T) {
+	svc := newCodexImportMemoryAdminService(nil)
+	handler := NewAccountHandler(svc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	content := decodeExternalImportTestContent(t, `[
+		{"name": "gemini", "type": 24, "key": "AIza-key"},
+		{"name": "broken", "type": 1, "key": ""}
+	]`)
+	candidates, err := convertExternalImportContent(ExternalImportFormatNewAPIChannels, content, time.Now())
+	require.NoError(t, err)
+	priority := 10
+
+	result, err := handler.importExternalAccounts(context.Background(), ExternalAccountImportRequest{
+		Format:               ExternalImportFormatNewAPIChannels,
+		NamePrefix:           "migrated-",
+		Priority:             &priority,
+		SkipDefaultGroupBind: boolPtr(true),
+	}, candidates)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Created)
+	require.Equal(t, 1, result.Failed)
+	require.Len(t, svc.createdAccounts, 1)
+
+	created := svc.createdAccounts[0]
+	require.Equal(t, "migrated-gemini", created.Name)
+	require.Equal(t, service.PlatformGemini, created.Platform)
+	require.Equal(t, service.AccountTypeAPIKey, created.Type)
+	require.Equal(t, 10, created.Priority)
+	require.Equal(t, 3, created.Concurrency)
+	require.True(t, created.SkipDefaultGroupBind)
+	require.Equal(t, "created", result.Items[0].Action)
+	require.Equal(t, "channel key is empty", result.Items[1].Message)
+}
@@ -63,6 +63,10 @@ type AccountHandler struct {
 	tokenCacheInvalidator   service.TokenCacheInvalidator
 	grokImportProber        grokImportProber
 	upstreamBillingProbe    *service.UpstreamBillingProbeService
+	listVersion             *service.AdminListVersionService
+	attribution             *service.AccountAttributionService
+	warmup                  *service.AccountWarmupPolicy
+	contentPolicy           *service.ContentPolicyCooldownService
 }
 
 // SetUpstreamBillingProbeService attaches the optional remote billing probe service.
@@ -70,6 +74,20 @@ func (h *AccountHandler) SetUpstreamBillingProbeService(probe *service.UpstreamB
 	h.upstreamBillingProbe = probe
 }
 
+// SetAccountWarmupPolicy attaches the warmup policy used to report per-account ramp status.
+func (h *AccountHandler) SetAccountWarmupPolicy(policy *service.AccountWarmupPolicy) {
+	h.warmup = policy
+}
+
+// accountFromService maps an account to its DTO, including the warmup status under the configured policy.
+func (h *AccountHandler) accountFromService(account *service.Account) *dto.Account {
+	out := dto.AccountFromService(account)
+	if out != nil {
+		out.Warmup = dto.AccountWarmupFromService(h.warmup.Status(account, time.Now()))
+	}
+	return out
+}
+
 // NewAccountHandler creates a new admin account handler
 func NewAccountHandler(
 	adminService service.AdminService,
@@ -210,7 +228,7 @@ const accountListGroupUngroupedQueryValue = "ungrouped"
 
 func (h *AccountHandler) buildAccountResponseWithRuntime(ctx context.Context, account *service.Account) AccountWithConcurrency {
 	item := AccountWithConcurrency{
-		Account:            dto.AccountFromService(account),
+		Account:            h.accountFromService(account),
 		CurrentConcurrency: 0,
 	}
 	if account == nil {
@@ -626,7 +644,7 @@ func (h *AccountHandler) List(c *gin.Context) {
 	for i := range accounts {
 		acc := &accounts[i]
 		item := AccountWithConcurrency{
-			Account:            dto.AccountFromService(acc),
+			Account:            h.accountFromService(acc),
 			CurrentConcurrency: concurrencyCounts[acc.ID],
 			SchedulerScore:     schedulerScores[acc.ID],
 			SchedulerScores:    schedulerGroupScores[acc.ID],
@@ -2188,6 +2206,24 @@ func (h *AccountHandler) ResetQuota(c *gin.Context) {
 	response.Success(c, h.buildAccountResponseWithRuntime(c.Request.Context(), account))
 }
 
+// InspectToken 解码账号存储的令牌，列出 scope、套餐与过期时间，并对照所属分组的平台要求给出不能调度的原因。
+// POST /api/v1/admin/accounts/:id/inspect-token
+func (h *AccountHandler) InspectToken(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	account, err := h.adminService.GetAccount(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, service.InspectAccountToken(account, time.Now()))
+}
+
 // GetTempUnschedulable handles getting temporary unschedulable status
 // GET /api/v1/admin/accounts/:id/temp-unschedulable
 func (h *AccountHandler) GetTempUnschedulable(c *gin.Context) {
@@ -2329,6 +2365,35 @@ func (h *AccountHandler) SetSchedulable(c *gin.Context) {
 	response.Success(c, h.buildAccountResponseWithRuntime(c.Request.Context(), account))
 }
 
+// UpdateGroupMembership handles updating an account's scheduling overrides within one group
+// PUT /api/v1/admin/accounts/:id/groups/:group_id
+func (h *AccountHandler) UpdateGroupMembership(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+	groupID, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	var req service.AccountGroupMembershipUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	membership, err := h.adminService.UpdateAccountGroupMembership(c.Request.Context(), accountID, groupID, &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.AccountGroupFromService(membership))
+}
+
 // GetAvailableModels handles getting available models for an account
 // GET /api/v1/admin/accounts/:id/models
 func (h *AccountHandler) GetAvailableModels(c *gin.Context) {
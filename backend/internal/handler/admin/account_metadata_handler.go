@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountMetadataHandler 账号运维元数据（负责人、续费、成本、备注）管理接口。
+type AccountMetadataHandler struct {
+	metadataService *service.AccountMetadataService
+}
+
+// NewAccountMetadataHandler 创建账号元数据处理器。
+func NewAccountMetadataHandler(metadataService *service.AccountMetadataService) *AccountMetadataHandler {
+	return &AccountMetadataHandler{metadataService: metadataService}
+}
+
+// UpsertAccountMetadataRequest 写入账号元数据请求；日期格式 YYYY-MM-DD，留空表示清空。
+type UpsertAccountMetadataRequest struct {
+	OwnerName    string   `json:"owner_name" binding:"max=100"`
+	OwnerContact string   `json:"owner_contact" binding:"max=255"`
+	PurchaseDate string   `json:"purchase_date"`
+	RenewalDate  string   `json:"renewal_date"`
+	MonthlyCost  *float64 `json:"monthly_cost"`
+	CostCurrency string   `json:"cost_currency" binding:"max=8"`
+	Notes        string   `json:"notes"`
+}
+
+// List 分页查询账号元数据。
+// GET /api/v1/admin/account-metadata
+func (h *AccountMetadataHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.AccountMetadataFilter{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   strings.TrimSpace(c.Query("search")),
+	}
+
+	// renewal_within_days=N：只看 N 天内（含已过期）需要续费的账号
+	if v := strings.TrimSpace(c.Query("renewal_within_days")); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 0 {
+			response.BadRequest(c, "Invalid renewal_within_days")
+			return
+		}
+		before := timezone.Today().AddDate(0, 0, days+1)
+		filter.RenewalBefore = &before
+	}
+
+	result, err := h.metadataService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Items, int64(result.Total), result.Page, result.PageSize)
+}
+
+// Get 查询单个账号的元数据。
+// GET /api/v1/admin/accounts/:id/metadata
+func (h *AccountMetadataHandler) Get(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	metadata, err := h.metadataService.Get(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, metadata)
+}
+
+// Upsert 创建或覆盖账号元数据。
+// PUT /api/v1/admin/accounts/:id/metadata
+func (h *AccountMetadataHandler) Upsert(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	var req UpsertAccountMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	metadata, err := h.metadataService.Upsert(c.Request.Context(), &service.UpsertAccountMetadataInput{
+		AccountID:    accountID,
+		OwnerName:    req.OwnerName,
+		OwnerContact: req.OwnerContact,
+		PurchaseDate: req.PurchaseDate,
+		RenewalDate:  req.RenewalDate,
+		MonthlyCost:  req.MonthlyCost,
+		CostCurrency: req.CostCurrency,
+		Notes:        req.Notes,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, metadata)
+}
+
+// Delete 删除账号元数据。
+// DELETE /api/v1/admin/accounts/:id/metadata
+func (h *AccountMetadataHandler) Delete(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	if err := h.metadataService.Delete(c.Request.Context(), accountID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Account metadata deleted successfully"})
+}
@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountSmokeTestHandler 账号夜间冒烟测试：每日趋势与下滑标记。
+type AccountSmokeTestHandler struct {
+	smokeTestService *service.AccountSmokeTestService
+}
+
+// NewAccountSmokeTestHandler 创建冒烟测试处理器。
+func NewAccountSmokeTestHandler(smokeTestService *service.AccountSmokeTestService) *AccountSmokeTestHandler {
+	return &AccountSmokeTestHandler{smokeTestService: smokeTestService}
+}
+
+// Trend 返回账号最近 days 天（默认 30）的每日成功率与延迟。
+// GET /api/v1/admin/accounts/:id/smoke-tests/trend
+func (h *AccountSmokeTestHandler) Trend(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || accountID <= 0 {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+	days := 30
+	if v := strings.TrimSpace(c.Query("days")); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days <= 0 || days > service.AccountSmokeTestMaxTrendDays {
+			response.BadRequest(c, "Invalid days")
+			return
+		}
+	}
+
+	points, err := h.smokeTestService.Trend(c.Request.Context(), accountID, days)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"account_id": accountID,
+		"days":       days,
+		"points":     points,
+	})
+}
+
+// ListFlagged 返回成功率明显下滑而被标记的账号。
+// GET /api/v1/admin/smoke-tests/flagged
+func (h *AccountSmokeTestHandler) ListFlagged(c *gin.Context) {
+	flags, err := h.smokeTestService.ListFlagged(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, flags)
+}
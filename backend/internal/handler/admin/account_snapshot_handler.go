@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountSnapshotHandler 账号状态快照时间线接口。
+type AccountSnapshotHandler struct {
+	snapshotService *service.AccountSnapshotService
+}
+
+// NewAccountSnapshotHandler 创建账号快照处理器。
+func NewAccountSnapshotHandler(snapshotService *service.AccountSnapshotService) *AccountSnapshotHandler {
+	return &AccountSnapshotHandler{snapshotService: snapshotService}
+}
+
+// Timeline 账号状态变化时间线，每项包含与前一份快照的字段差异。
+// GET /api/v1/admin/accounts/:id/snapshots?since=&until=&limit=
+func (h *AccountSnapshotHandler) Timeline(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	since, ok := parseOptionalRFC3339(c, "since")
+	if !ok {
+		return
+	}
+	until, ok := parseOptionalRFC3339(c, "until")
+	if !ok {
+		return
+	}
+	limit := 0
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+	}
+
+	timeline, err := h.snapshotService.Timeline(c.Request.Context(), accountID, since, until, limit)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, timeline)
+}
+
+func parseOptionalRFC3339(c *gin.Context, key string) (*time.Time, bool) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		response.BadRequest(c, "Invalid "+key+", use RFC3339 format")
+		return nil, false
+	}
+	return &t, true
+}
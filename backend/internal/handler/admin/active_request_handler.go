@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActiveRequestHandler 在途网关请求查看与强制取消接口（本实例内存数据）。
+type ActiveRequestHandler struct {
+	registry *service.ActiveRequestRegistry
+}
+
+// NewActiveRequestHandler 创建在途请求处理器。
+func NewActiveRequestHandler(registry *service.ActiveRequestRegistry) *ActiveRequestHandler {
+	return &ActiveRequestHandler{registry: registry}
+}
+
+// List 列出本实例当前在途的网关请求，按开始时间升序（耗时最长的在前）。
+// GET /api/v1/admin/requests/active
+func (h *ActiveRequestHandler) List(c *gin.Context) {
+	items := h.registry.List()
+	response.Success(c, gin.H{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// Cancel 强制取消指定在途请求：取消其 context，上游调用与流式转发随之结束。
+// POST /api/v1/admin/requests/active/:id/cancel
+func (h *ActiveRequestHandler) Cancel(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		response.BadRequest(c, "Invalid request ID")
+		return
+	}
+	item, err := h.registry.Cancel(id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, item)
+}
@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SetListVersionService attaches the optional list version service used by the delta endpoint.
+func (h *AccountHandler) SetListVersionService(svc *service.AdminListVersionService) {
+	h.listVersion = svc
+}
+
+// Changes 返回 since 之后变更或删除的账号，供管理端轮询增量合并。
+// 返回的 server_time 作为下一次请求的 since；has_more 为 true 时客户端应回退到全量刷新。
+// GET /api/v1/admin/accounts/changes?since=RFC3339&limit=
+func (h *AccountHandler) Changes(c *gin.Context) {
+	since, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(c.Query("since")))
+	if err != nil {
+		response.BadRequest(c, "Invalid since, use RFC3339 format")
+		return
+	}
+	limit := service.AdminAccountChangesMaxLimit
+	if limitStr := strings.TrimSpace(c.Query("limit")); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	changes, err := h.listVersion.AccountChanges(c.Request.Context(), since, limit)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	items := make([]*dto.Account, 0, len(changes.Accounts))
+	for _, acc := range changes.Accounts {
+		items = append(items, h.accountFromService(acc))
+	}
+	response.Success(c, gin.H{
+		"items":       items,
+		"deleted_ids": changes.DeletedIDs,
+		"has_more":    changes.HasMore,
+		"server_time": changes.ServerTime.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// writeAdminListETag 写出 ETag 响应头；If-None-Match 命中时写出 304 并返回 true。
+func writeAdminListETag(c *gin.Context, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	c.Header("ETag", etag)
+	c.Header("Vary", "If-None-Match")
+	if ifNoneMatchMatched(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
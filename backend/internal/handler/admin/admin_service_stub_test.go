@@ -478,6 +478,10 @@ func (s *stubAdminService) SetAccountSchedulable(ctx context.Context, id int64,
 	return &account, nil
 }
 
+func (s *stubAdminService) UpdateAccountGroupMembership(ctx context.Context, accountID, groupID int64, update *service.AccountGroupMembershipUpdate) (*service.AccountGroup, error) {
+	return &service.AccountGroup{AccountID: accountID, GroupID: groupID, Weight: service.DefaultAccountGroupWeight}, nil
+}
+
 func (s *stubAdminService) BulkUpdateAccounts(ctx context.Context, input *service.BulkUpdateAccountsInput) (*service.BulkUpdateAccountsResult, error) {
 	s.lastBulkUpdateAccountInput = input
 	if s.bulkUpdateAccountErr != nil {
@@ -696,6 +700,61 @@ func (s *stubAdminService) AdminResetAPIKeyRateLimitUsage(ctx context.Context, k
 	return nil, service.ErrAPIKeyNotFound
 }
 
+func (s *stubAdminService) AdminSetAPIKeyAnnotationsEnabled(ctx context.Context, keyID int64, enabled bool) (*service.APIKey, error) {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == keyID {
+			s.apiKeys[i].AnnotationsEnabled = enabled
+			k := s.apiKeys[i]
+			return &k, nil
+		}
+	}
+	return nil, service.ErrAPIKeyNotFound
+}
+
+func (s *stubAdminService) AdminSetAPIKeyStreamCaptureEnabled(ctx context.Context, keyID int64, enabled bool) (*service.APIKey, error) {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == keyID {
+			s.apiKeys[i].StreamCaptureEnabled = enabled
+			k := s.apiKeys[i]
+			return &k, nil
+		}
+	}
+	return nil, service.ErrAPIKeyNotFound
+}
+
+func (s *stubAdminService) AdminSetAPIKeyMaxPriority(ctx context.Context, keyID int64, maxPriority string) (*service.APIKey, error) {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == keyID {
+			s.apiKeys[i].MaxPriority = maxPriority
+			k := s.apiKeys[i]
+			return &k, nil
+		}
+	}
+	return nil, service.ErrAPIKeyNotFound
+}
+
+func (s *stubAdminService) AdminSetAPIKeyBetaFeatures(ctx context.Context, keyID int64, features []string) (*service.APIKey, error) {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == keyID {
+			s.apiKeys[i].BetaFeatures = features
+			k := s.apiKeys[i]
+			return &k, nil
+		}
+	}
+	return nil, service.ErrAPIKeyNotFound
+}
+
+func (s *stubAdminService) AdminSetAPIKeyDataResidency(ctx context.Context, keyID int64, region string) (*service.APIKey, error) {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == keyID {
+			s.apiKeys[i].DataResidency = region
+			k := s.apiKeys[i]
+			return &k, nil
+		}
+	}
+	return nil, service.ErrAPIKeyNotFound
+}
+
 func (s *stubAdminService) ResetAccountQuota(ctx context.Context, id int64) error {
 	return nil
 }
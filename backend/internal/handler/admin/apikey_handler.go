@@ -2,6 +2,7 @@ package admin
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
@@ -12,20 +13,27 @@ import (
 
 // AdminAPIKeyHandler handles admin API key management
 type AdminAPIKeyHandler struct {
-	adminService service.AdminService
+	adminService  service.AdminService
+	importService *service.APIKeyImportService
 }
 
 // NewAdminAPIKeyHandler creates a new admin API key handler
-func NewAdminAPIKeyHandler(adminService service.AdminService) *AdminAPIKeyHandler {
+func NewAdminAPIKeyHandler(adminService service.AdminService, importService *service.APIKeyImportService) *AdminAPIKeyHandler {
 	return &AdminAPIKeyHandler{
-		adminService: adminService,
+		adminService:  adminService,
+		importService: importService,
 	}
 }
 
 // AdminUpdateAPIKeyGroupRequest represents the request to update an API key.
 type AdminUpdateAPIKeyGroupRequest struct {
-	GroupID             *int64 `json:"group_id"`               // nil=不修改, 0=解绑, >0=绑定到目标分组
-	ResetRateLimitUsage *bool  `json:"reset_rate_limit_usage"` // true=重置 5h/1d/7d 限速用量
+	GroupID              *int64    `json:"group_id"`               // nil=不修改, 0=解绑, >0=绑定到目标分组
+	ResetRateLimitUsage  *bool     `json:"reset_rate_limit_usage"` // true=重置 5h/1d/7d 限速用量
+	AnnotationsEnabled   *bool     `json:"annotations_enabled"`    // 响应注解头开关（nil 不修改）
+	StreamCaptureEnabled *bool     `json:"stream_capture_enabled"` // 上游原始响应全量留存开关（nil 不修改）
+	MaxPriority          *string   `json:"max_priority"`           // X-Priority 允许的最高优先级（nil 不修改，"" 忽略请求头）
+	BetaFeatures         *[]string `json:"beta_features"`          // 可按请求启用的实验性功能（nil 不修改，[] 清空）
+	DataResidency        *string   `json:"data_residency"`         // 要求的数据驻留区域 eu/us/asia（nil 不修改，"" 不限制）
 }
 
 // UpdateGroup handles updating an API key's admin-managed fields.
@@ -43,9 +51,44 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
-	var resetKey *service.APIKey
+	var updatedKey *service.APIKey
 	if req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage {
-		resetKey, err = h.adminService.AdminResetAPIKeyRateLimitUsage(c.Request.Context(), keyID)
+		updatedKey, err = h.adminService.AdminResetAPIKeyRateLimitUsage(c.Request.Context(), keyID)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.AnnotationsEnabled != nil {
+		updatedKey, err = h.adminService.AdminSetAPIKeyAnnotationsEnabled(c.Request.Context(), keyID, *req.AnnotationsEnabled)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.StreamCaptureEnabled != nil {
+		updatedKey, err = h.adminService.AdminSetAPIKeyStreamCaptureEnabled(c.Request.Context(), keyID, *req.StreamCaptureEnabled)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.MaxPriority != nil {
+		updatedKey, err = h.adminService.AdminSetAPIKeyMaxPriority(c.Request.Context(), keyID, *req.MaxPriority)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.BetaFeatures != nil {
+		updatedKey, err = h.adminService.AdminSetAPIKeyBetaFeatures(c.Request.Context(), keyID, *req.BetaFeatures)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.DataResidency != nil {
+		updatedKey, err = h.adminService.AdminSetAPIKeyDataResidency(c.Request.Context(), keyID, *req.DataResidency)
 		if err != nil {
 			response.ErrorFrom(c, err)
 			return
@@ -57,8 +100,8 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
-	if resetKey != nil && req.GroupID == nil {
-		result.APIKey = resetKey
+	if updatedKey != nil && req.GroupID == nil {
+		result.APIKey = updatedKey
 	}
 
 	resp := struct {
@@ -74,3 +117,48 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 	}
 	response.Success(c, resp)
 }
+
+// AdminImportAPIKeysRequest represents a bulk API key import.
+// Exactly one of Rows (JSON spec) or CSV (CSV text with a header line) must be provided.
+type AdminImportAPIKeysRequest struct {
+	Rows      []service.APIKeyImportRow `json:"rows"`
+	CSV       string                    `json:"csv"`
+	SendEmail bool                      `json:"send_email"` // 通过通知邮件把新 Key 发给对应用户
+}
+
+// Import handles bulk API key creation from a JSON or CSV spec.
+// The response carries the plaintext keys and is the only place they are returned.
+// POST /api/v1/admin/api-keys/import
+func (h *AdminAPIKeyHandler) Import(c *gin.Context) {
+	var req AdminImportAPIKeysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	hasCSV := strings.TrimSpace(req.CSV) != ""
+	if hasCSV == (len(req.Rows) > 0) {
+		response.BadRequest(c, "Provide either rows or csv")
+		return
+	}
+
+	rows := req.Rows
+	if hasCSV {
+		parsed, err := service.ParseAPIKeyImportCSV(strings.NewReader(req.CSV))
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		rows = parsed
+	}
+
+	result, err := h.importService.Import(c.Request.Context(), service.APIKeyImportInput{
+		Rows:      rows,
+		SendEmail: req.SendEmail,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, result)
+}
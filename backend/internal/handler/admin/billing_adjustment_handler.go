@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BillingAdjustmentHandler 计费调整（补偿/退款）台账管理接口。
+type BillingAdjustmentHandler struct {
+	adjustmentService *service.BillingAdjustmentService
+}
+
+// NewBillingAdjustmentHandler 创建计费调整处理器。
+func NewBillingAdjustmentHandler(adjustmentService *service.BillingAdjustmentService) *BillingAdjustmentHandler {
+	return &BillingAdjustmentHandler{adjustmentService: adjustmentService}
+}
+
+// CreateBillingAdjustmentRequest 发放补偿/退款请求
+type CreateBillingAdjustmentRequest struct {
+	UserID         int64   `json:"user_id" binding:"required,gt=0"`
+	APIKeyID       *int64  `json:"api_key_id"`
+	Kind           string  `json:"kind" binding:"required,oneof=credit refund"`
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	Reason         string  `json:"reason" binding:"max=255"`
+	Notes          string  `json:"notes"`
+	UsageRequestID string  `json:"usage_request_id" binding:"max=64"`
+}
+
+// List 分页查询计费调整台账。
+// GET /api/v1/admin/billing-adjustments
+func (h *BillingAdjustmentHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.BillingAdjustmentFilter{
+		Page:     page,
+		PageSize: pageSize,
+		Kind:     strings.TrimSpace(c.Query("kind")),
+	}
+
+	if v := strings.TrimSpace(c.Query("user_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		filter.UserID = &id
+	}
+	if v := strings.TrimSpace(c.Query("api_key_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid api_key_id")
+			return
+		}
+		filter.APIKeyID = &id
+	}
+	if v := strings.TrimSpace(c.Query("start_time")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_time, expect RFC3339")
+			return
+		}
+		filter.StartTime = &t
+	}
+	if v := strings.TrimSpace(c.Query("end_time")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_time, expect RFC3339")
+			return
+		}
+		filter.EndTime = &t
+	}
+
+	result, err := h.adjustmentService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Items, int64(result.Total), result.Page, result.PageSize)
+}
+
+// Create 发放一笔补偿或退款（写入台账并同步调整余额与 Key 额度）。
+// POST /api/v1/admin/billing-adjustments
+func (h *BillingAdjustmentHandler) Create(c *gin.Context) {
+	var req CreateBillingAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	var createdBy *int64
+	if subject, ok := middleware.GetAuthSubjectFromContext(c); ok && subject.UserID > 0 {
+		id := subject.UserID
+		createdBy = &id
+	}
+
+	executeAdminIdempotentJSON(c, "admin.billing_adjustments.create", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		return h.adjustmentService.Create(ctx, &service.CreateBillingAdjustmentInput{
+			UserID:         req.UserID,
+			APIKeyID:       req.APIKeyID,
+			Kind:           req.Kind,
+			Amount:         req.Amount,
+			Reason:         req.Reason,
+			Notes:          req.Notes,
+			UsageRequestID: req.UsageRequestID,
+			CreatedBy:      createdBy,
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CostAnomalyHandler 账号上游成本异常：异常列表与审核。
+type CostAnomalyHandler struct {
+	anomalyService *service.AccountCostAnomalyService
+}
+
+// NewCostAnomalyHandler 创建成本异常处理器。
+func NewCostAnomalyHandler(anomalyService *service.AccountCostAnomalyService) *CostAnomalyHandler {
+	return &CostAnomalyHandler{anomalyService: anomalyService}
+}
+
+// ReviewCostAnomalyRequest 审核请求体。
+type ReviewCostAnomalyRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// List 分页查询成本异常，可按 status（open / reviewed）与 account_id 过滤。
+// GET /api/v1/admin/cost-anomalies
+func (h *CostAnomalyHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.AccountCostAnomalyFilter{
+		Status:   strings.TrimSpace(c.Query("status")),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	switch filter.Status {
+	case "", service.AccountCostAnomalyStatusOpen, service.AccountCostAnomalyStatusReviewed:
+	default:
+		response.BadRequest(c, "Invalid status")
+		return
+	}
+	if v := strings.TrimSpace(c.Query("account_id")); v != "" {
+		accountID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || accountID <= 0 {
+			response.BadRequest(c, "Invalid account_id")
+			return
+		}
+		filter.AccountID = accountID
+	}
+
+	result, err := h.anomalyService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Items, int64(result.Total), result.Page, result.PageSize)
+}
+
+// Review 标记异常已审核；检测时暂停了调度的账号在没有其他未审核异常时恢复调度。
+// POST /api/v1/admin/cost-anomalies/:id/review
+func (h *CostAnomalyHandler) Review(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid cost anomaly ID")
+		return
+	}
+	var req ReviewCostAnomalyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request: "+err.Error())
+			return
+		}
+	}
+	var reviewerID int64
+	if subject, ok := middleware.GetAuthSubjectFromContext(c); ok {
+		reviewerID = subject.UserID
+	}
+	item, err := h.anomalyService.Review(c.Request.Context(), id, reviewerID, req.Note)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, item)
+}
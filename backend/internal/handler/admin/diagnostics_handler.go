@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiagnosticsHandler 启动自检报告接口。
+type DiagnosticsHandler struct {
+	diagnosticsService *service.StartupDiagnosticsService
+}
+
+// NewDiagnosticsHandler 创建自检报告处理器。
+func NewDiagnosticsHandler(diagnosticsService *service.StartupDiagnosticsService) *DiagnosticsHandler {
+	return &DiagnosticsHandler{diagnosticsService: diagnosticsService}
+}
+
+// Get 返回最近一次自检报告；尚未执行过（例如启动自检被禁用）时立即执行一次。
+// GET /api/v1/admin/diagnostics
+func (h *DiagnosticsHandler) Get(c *gin.Context) {
+	if h.diagnosticsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Diagnostics service not available")
+		return
+	}
+	report := h.diagnosticsService.LastReport()
+	if report == nil {
+		report = h.diagnosticsService.Run(c.Request.Context(), service.DiagnosticsTriggerManual)
+	}
+	response.Success(c, report)
+}
+
+// Run 重新执行一次自检并返回报告。
+// POST /api/v1/admin/diagnostics/run
+func (h *DiagnosticsHandler) Run(c *gin.Context) {
+	if h.diagnosticsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Diagnostics service not available")
+		return
+	}
+	response.Success(c, h.diagnosticsService.Run(c.Request.Context(), service.DiagnosticsTriggerManual))
+}
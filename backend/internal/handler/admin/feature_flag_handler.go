@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagHandler 部署级功能开关接口。
+type FeatureFlagHandler struct {
+	flags *service.FeatureFlagService
+}
+
+// NewFeatureFlagHandler 创建功能开关处理器。
+func NewFeatureFlagHandler(flags *service.FeatureFlagService) *FeatureFlagHandler {
+	return &FeatureFlagHandler{flags: flags}
+}
+
+// UpdateFeatureFlagsRequest 以 key -> enabled 修改开关，未列出的功能保持不变。
+type UpdateFeatureFlagsRequest struct {
+	Flags map[string]bool `json:"flags" binding:"required"`
+}
+
+// List 返回全部功能开关。
+// GET /api/v1/admin/feature-flags
+func (h *FeatureFlagHandler) List(c *gin.Context) {
+	response.Success(c, gin.H{"items": h.flags.List(c.Request.Context())})
+}
+
+// Update 修改功能开关；关闭的功能路由返回 404，后台任务在下个周期跳过。
+// PUT /api/v1/admin/feature-flags
+func (h *FeatureFlagHandler) Update(c *gin.Context) {
+	var req UpdateFeatureFlagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	items, err := h.flags.Update(c.Request.Context(), req.Flags)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"items": items})
+}
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GroupBudgetHandler 分组月度上游成本预算管理接口。
+type GroupBudgetHandler struct {
+	budgetService *service.GroupBudgetService
+}
+
+// NewGroupBudgetHandler 创建分组预算处理器。
+func NewGroupBudgetHandler(budgetService *service.GroupBudgetService) *GroupBudgetHandler {
+	return &GroupBudgetHandler{budgetService: budgetService}
+}
+
+// UpsertGroupBudgetRequest 写入分组预算请求。
+type UpsertGroupBudgetRequest struct {
+	Enabled          *bool   `json:"enabled"`
+	MonthlyBudgetUSD float64 `json:"monthly_budget_usd" binding:"required"`
+	FallbackGroupID  int64   `json:"fallback_group_id" binding:"required"`
+	// ShiftPercent 为空时默认 100（全部切流）
+	ShiftPercent *int `json:"shift_percent"`
+}
+
+// List 查询所有分组预算及本月成本、预测与切流状态。
+// GET /api/v1/admin/group-budgets
+func (h *GroupBudgetHandler) List(c *gin.Context) {
+	items, err := h.budgetService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, items)
+}
+
+// Upsert 创建或覆盖分组预算。
+// PUT /api/v1/admin/groups/:id/budget
+func (h *GroupBudgetHandler) Upsert(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	var req UpsertGroupBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	budget := &service.GroupBudget{
+		GroupID:          groupID,
+		Enabled:          true,
+		MonthlyBudgetUSD: req.MonthlyBudgetUSD,
+		FallbackGroupID:  req.FallbackGroupID,
+		ShiftPercent:     100,
+	}
+	if req.Enabled != nil {
+		budget.Enabled = *req.Enabled
+	}
+	if req.ShiftPercent != nil {
+		budget.ShiftPercent = *req.ShiftPercent
+	}
+
+	saved, err := h.budgetService.Upsert(c.Request.Context(), budget)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, saved)
+}
+
+// Delete 删除分组预算。
+// DELETE /api/v1/admin/groups/:id/budget
+func (h *GroupBudgetHandler) Delete(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	if err := h.budgetService.Delete(c.Request.Context(), groupID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Group budget deleted successfully"})
+}
@@ -132,6 +132,19 @@ type CreateGroupRequest struct {
 	ModelsListConfig            service.GroupModelsListConfig             `json:"models_list_config"`
 	// 分组 RPM 上限（0 = 不限制）
 	RPMLimit int `json:"rpm_limit"`
+	// Anthropic beta 工具透传开关（默认关闭）
+	AllowComputerUseTool   bool `json:"allow_computer_use_tool"`
+	AllowCodeExecutionTool bool `json:"allow_code_execution_tool"`
+	AllowWebSearchTool     bool `json:"allow_web_search_tool"`
+	// system prompt 注入档位：claude-code / api-default / no-injection，空表示沿用全局设置
+	SystemPromptProfile string `json:"system_prompt_profile"`
+	// 强制回复语言（如 zh / en），空表示不强制
+	ResponseLanguage string `json:"response_language"`
+	// 归属水印：footer / invisible，空表示不添加；attribution_text 为 footer 页脚模板
+	AttributionMode string `json:"attribution_mode"`
+	AttributionText string `json:"attribution_text"`
+	// 单请求预估费用上限（美元，0 = 不限制）
+	MaxRequestCost float64 `json:"max_request_cost"`
 	// 从指定分组复制账号（创建后自动绑定）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -186,6 +199,19 @@ type UpdateGroupRequest struct {
 	ModelsListConfig            *service.GroupModelsListConfig             `json:"models_list_config"`
 	// 分组 RPM 上限（0 = 不限制）；nil 表示未提供不改动
 	RPMLimit *int `json:"rpm_limit"`
+	// Anthropic beta 工具透传开关；nil 表示未提供不改动
+	AllowComputerUseTool   *bool `json:"allow_computer_use_tool"`
+	AllowCodeExecutionTool *bool `json:"allow_code_execution_tool"`
+	AllowWebSearchTool     *bool `json:"allow_web_search_tool"`
+	// system prompt 注入档位；nil 表示未提供不改动，空字符串表示恢复沿用全局设置
+	SystemPromptProfile *string `json:"system_prompt_profile"`
+	// 强制回复语言；nil 表示未提供不改动，空字符串表示取消强制
+	ResponseLanguage *string `json:"response_language"`
+	// 归属水印模式与页脚模板；nil 表示未提供不改动
+	AttributionMode *string `json:"attribution_mode"`
+	AttributionText *string `json:"attribution_text"`
+	// 单请求预估费用上限；nil 表示未提供不改动，0 表示取消
+	MaxRequestCost *float64 `json:"max_request_cost"`
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -354,6 +380,14 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		MessagesDispatchModelConfig:     req.MessagesDispatchModelConfig,
 		ModelsListConfig:                req.ModelsListConfig,
 		RPMLimit:                        req.RPMLimit,
+		AllowComputerUseTool:            req.AllowComputerUseTool,
+		AllowCodeExecutionTool:          req.AllowCodeExecutionTool,
+		AllowWebSearchTool:              req.AllowWebSearchTool,
+		SystemPromptProfile:             req.SystemPromptProfile,
+		ResponseLanguage:                req.ResponseLanguage,
+		AttributionMode:                 req.AttributionMode,
+		AttributionText:                 req.AttributionText,
+		MaxRequestCost:                  req.MaxRequestCost,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -470,6 +504,14 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		MessagesDispatchModelConfig:     req.MessagesDispatchModelConfig,
 		ModelsListConfig:                req.ModelsListConfig,
 		RPMLimit:                        req.RPMLimit,
+		AllowComputerUseTool:            req.AllowComputerUseTool,
+		AllowCodeExecutionTool:          req.AllowCodeExecutionTool,
+		AllowWebSearchTool:              req.AllowWebSearchTool,
+		SystemPromptProfile:             req.SystemPromptProfile,
+		ResponseLanguage:                req.ResponseLanguage,
+		AttributionMode:                 req.AttributionMode,
+		AttributionText:                 req.AttributionText,
+		MaxRequestCost:                  req.MaxRequestCost,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
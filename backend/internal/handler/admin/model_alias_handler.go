@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ModelAliasHandler 模型别名学习：别名建议审核与未知模型报告。
+type ModelAliasHandler struct {
+	aliasService *service.ModelAliasLearningService
+}
+
+// NewModelAliasHandler 创建模型别名处理器。
+func NewModelAliasHandler(aliasService *service.ModelAliasLearningService) *ModelAliasHandler {
+	return &ModelAliasHandler{aliasService: aliasService}
+}
+
+// ApproveModelAliasRequest 批准请求体；TargetModel 为空时使用自动建议的模型。
+type ApproveModelAliasRequest struct {
+	TargetModel string `json:"target_model" binding:"max=255"`
+}
+
+// List 分页查询别名建议；unknown=true 仅返回没有相近模型的未知模型名。
+// GET /api/v1/admin/model-aliases
+func (h *ModelAliasHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.ModelAliasSuggestionFilter{
+		Status:   strings.TrimSpace(c.Query("status")),
+		Platform: strings.TrimSpace(c.Query("platform")),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	switch filter.Status {
+	case "", service.ModelAliasStatusPending, service.ModelAliasStatusApproved, service.ModelAliasStatusRejected:
+	default:
+		response.BadRequest(c, "Invalid status")
+		return
+	}
+	if v := strings.TrimSpace(c.Query("unknown")); v != "" {
+		unknownOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			response.BadRequest(c, "Invalid unknown")
+			return
+		}
+		filter.UnknownOnly = unknownOnly
+	}
+
+	result, err := h.aliasService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Items, int64(result.Total), result.Page, result.PageSize)
+}
+
+// Approve 批准别名建议；开启 model_alias_learning.auto_apply 后请求会被改写为目标模型。
+// POST /api/v1/admin/model-aliases/:id/approve
+func (h *ModelAliasHandler) Approve(c *gin.Context) {
+	id, ok := parseModelAliasID(c)
+	if !ok {
+		return
+	}
+	var req ApproveModelAliasRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request: "+err.Error())
+			return
+		}
+	}
+	item, err := h.aliasService.Approve(c.Request.Context(), id, req.TargetModel, modelAliasReviewerID(c))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, item)
+}
+
+// Reject 拒绝别名建议，或撤销已批准的改写。
+// POST /api/v1/admin/model-aliases/:id/reject
+func (h *ModelAliasHandler) Reject(c *gin.Context) {
+	id, ok := parseModelAliasID(c)
+	if !ok {
+		return
+	}
+	item, err := h.aliasService.Reject(c.Request.Context(), id, modelAliasReviewerID(c))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, item)
+}
+
+func parseModelAliasID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid model alias ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// modelAliasReviewerID 审核人；admin API key 调用时为 0。
+func modelAliasReviewerID(c *gin.Context) int64 {
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		return 0
+	}
+	return subject.UserID
+}
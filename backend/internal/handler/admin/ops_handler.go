@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
@@ -80,6 +81,25 @@ func applyOpsErrorSortParams(c *gin.Context, filter *service.OpsErrorLogFilter)
 	filter.SetSort(c.Query("sort_by"), c.Query("sort_order"))
 }
 
+// applyOpsErrorCursorParam 携带 cursor 参数时切换为游标分页；返回 false 表示已写入 400 响应。
+func applyOpsErrorCursorParam(c *gin.Context, filter *service.OpsErrorLogFilter) bool {
+	cursor, ok := response.ParseCursor(c)
+	filter.Cursor = cursor
+	return ok
+}
+
+func writeOpsErrorLogList(c *gin.Context, filter *service.OpsErrorLogFilter, result *service.OpsErrorLogList) {
+	if filter.Cursor != nil {
+		response.CursorPaginated(c, result.Errors, &pagination.PaginationResult{
+			PageSize:   result.PageSize,
+			NextCursor: result.NextCursor,
+			HasMore:    result.HasMore,
+		})
+		return
+	}
+	response.Paginated(c, result.Errors, int64(result.Total), result.Page, result.PageSize)
+}
+
 // GET /api/v1/admin/ops/errors
 func (h *OpsHandler) GetErrorLogs(c *gin.Context) {
 	if h.opsService == nil {
@@ -202,13 +222,16 @@ func (h *OpsHandler) GetErrorLogs(c *gin.Context) {
 	}
 
 	applyOpsErrorSortParams(c, filter)
+	if !applyOpsErrorCursorParam(c, filter) {
+		return
+	}
 
 	result, err := h.opsService.GetErrorLogs(c.Request.Context(), filter)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
-	response.Paginated(c, result.Errors, int64(result.Total), result.Page, result.PageSize)
+	writeOpsErrorLogList(c, filter, result)
 }
 
 // ListRequestErrors lists client-visible request errors.
@@ -315,13 +338,16 @@ func (h *OpsHandler) ListRequestErrors(c *gin.Context) {
 	}
 
 	applyOpsErrorSortParams(c, filter)
+	if !applyOpsErrorCursorParam(c, filter) {
+		return
+	}
 
 	result, err := h.opsService.GetErrorLogs(c.Request.Context(), filter)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
-	response.Paginated(c, result.Errors, int64(result.Total), result.Page, result.PageSize)
+	writeOpsErrorLogList(c, filter, result)
 }
 
 // GetRequestError returns request error detail.
@@ -529,13 +555,16 @@ func (h *OpsHandler) ListUpstreamErrors(c *gin.Context) {
 	}
 
 	applyOpsErrorSortParams(c, filter)
+	if !applyOpsErrorCursorParam(c, filter) {
+		return
+	}
 
 	result, err := h.opsService.GetErrorLogs(c.Request.Context(), filter)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
-	response.Paginated(c, result.Errors, int64(result.Total), result.Page, result.PageSize)
+	writeOpsErrorLogList(c, filter, result)
 }
 
 // GetUpstreamError returns upstream error detail.
@@ -79,6 +79,7 @@ func (h *ProxyHandler) ExportData(c *gin.Context) {
 			FallbackMode:    p.FallbackMode,
 			BackupProxyName: backupProxyName,
 			ExpiryWarnDays:  p.ExpiryWarnDays,
+			DataResidency:   p.DataResidency,
 		})
 	}
 
@@ -232,6 +233,7 @@ func (h *ProxyHandler) ImportData(c *gin.Context) {
 			FallbackMode:   fallbackMode,
 			BackupProxyID:  backupProxyID,
 			ExpiryWarnDays: item.ExpiryWarnDays,
+			DataResidency:  item.DataResidency,
 		})
 		if err != nil {
 			result.ProxyFailed++
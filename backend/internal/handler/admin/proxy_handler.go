@@ -37,21 +37,23 @@ type CreateProxyRequest struct {
 	FallbackMode   string `json:"fallback_mode" binding:"omitempty,oneof=none proxy direct"`
 	BackupProxyID  *int64 `json:"backup_proxy_id"`
 	ExpiryWarnDays int    `json:"expiry_warn_days" binding:"omitempty,min=0"`
+	DataResidency  string `json:"data_residency" binding:"omitempty,oneof=eu us asia"`
 }
 
 // UpdateProxyRequest represents update proxy request
 type UpdateProxyRequest struct {
-	Name           string `json:"name"`
-	Protocol       string `json:"protocol" binding:"omitempty,oneof=http https socks5 socks5h"`
-	Host           string `json:"host"`
-	Port           int    `json:"port" binding:"omitempty,min=1,max=65535"`
-	Username       string `json:"username"`
-	Password       string `json:"password"`
-	Status         string `json:"status" binding:"omitempty,oneof=active inactive"`
-	ExpiresAt      *int64 `json:"expires_at"`
-	FallbackMode   string `json:"fallback_mode" binding:"omitempty,oneof=none proxy direct"`
-	BackupProxyID  *int64 `json:"backup_proxy_id"`
-	ExpiryWarnDays int    `json:"expiry_warn_days" binding:"omitempty,min=0"`
+	Name           string  `json:"name"`
+	Protocol       string  `json:"protocol" binding:"omitempty,oneof=http https socks5 socks5h"`
+	Host           string  `json:"host"`
+	Port           int     `json:"port" binding:"omitempty,min=1,max=65535"`
+	Username       string  `json:"username"`
+	Password       string  `json:"password"`
+	Status         string  `json:"status" binding:"omitempty,oneof=active inactive"`
+	ExpiresAt      *int64  `json:"expires_at"`
+	FallbackMode   string  `json:"fallback_mode" binding:"omitempty,oneof=none proxy direct"`
+	BackupProxyID  *int64  `json:"backup_proxy_id"`
+	ExpiryWarnDays int     `json:"expiry_warn_days" binding:"omitempty,min=0"`
+	DataResidency  *string `json:"data_residency" binding:"omitempty,oneof=eu us asia"` // nil 不修改，"" 清除标签
 }
 
 // List handles listing all proxies with pagination
@@ -159,6 +161,7 @@ func (h *ProxyHandler) Create(c *gin.Context) {
 			FallbackMode:   strings.TrimSpace(req.FallbackMode),
 			BackupProxyID:  req.BackupProxyID,
 			ExpiryWarnDays: req.ExpiryWarnDays,
+			DataResidency:  req.DataResidency,
 		})
 		if err != nil {
 			return nil, err
@@ -199,6 +202,7 @@ func (h *ProxyHandler) Update(c *gin.Context) {
 		FallbackMode:   strings.TrimSpace(req.FallbackMode),
 		BackupProxyID:  req.BackupProxyID,
 		ExpiryWarnDays: req.ExpiryWarnDays,
+		DataResidency:  req.DataResidency,
 	})
 	if err != nil {
 		response.ErrorFrom(c, err)
@@ -61,6 +61,7 @@ type SettingHandler struct {
 	notificationEmailService *service.NotificationEmailService
 	totpService              *service.TotpService
 	userService              *service.UserService
+	governanceService        *service.SettingGovernanceService
 }
 
 // NewSettingHandler 创建系统设置处理器
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetGatewayCanary 获取网关灰度配置与稳定/灰度两组对比指标
+// GET /api/v1/admin/settings/canary
+func (h *SettingHandler) GetGatewayCanary(c *gin.Context) {
+	status, err := h.settingService.GetGatewayCanaryStatus(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// UpdateGatewayCanary 更新网关灰度配置（分流规则与候选设置），保存后对比指标清零
+// PUT /api/v1/admin/settings/canary
+func (h *SettingHandler) UpdateGatewayCanary(c *gin.Context) {
+	var req service.GatewayCanarySettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	status, err := h.settingService.SetGatewayCanarySettings(c.Request.Context(), &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// PromoteGatewayCanary 把候选设置写回稳定设置并关闭灰度
+// POST /api/v1/admin/settings/canary/promote
+func (h *SettingHandler) PromoteGatewayCanary(c *gin.Context) {
+	status, err := h.settingService.PromoteGatewayCanary(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// RollbackGatewayCanary 丢弃候选设置并关闭灰度
+// POST /api/v1/admin/settings/canary/rollback
+func (h *SettingHandler) RollbackGatewayCanary(c *gin.Context) {
+	status, err := h.settingService.RollbackGatewayCanary(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
@@ -0,0 +1,457 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// settingsChangeApprovalContextKey 批准待审批变更时，重放 UpdateSettings 所携带的审批上下文
+const settingsChangeApprovalContextKey = "settings_change_approval"
+
+// settingsChangeApproval 重放中的审批信息
+type settingsChangeApproval struct {
+	request *service.SettingChangeRequest
+	note    string
+}
+
+// SetGovernanceService attaches the settings category permission / approval service
+// without changing the constructor signature used by existing unit tests.
+// 未设置时不做分类权限与审批校验。
+func (h *SettingHandler) SetGovernanceService(governanceService *service.SettingGovernanceService) {
+	h.governanceService = governanceService
+}
+
+// settingsActorID 返回用于分类权限判断的操作者 ID。
+// admin API key 是机器凭证，不代表具体管理员：返回 0，只能编辑未限定编辑者的分类。
+func settingsActorID(c *gin.Context) int64 {
+	if c.GetString("auth_method") == service.AuditAuthMethodAdminAPIKey {
+		return 0
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		return 0
+	}
+	return subject.UserID
+}
+
+// settingsChangedKeys 汇总本次保存实际变化的设置键（含独立存储的支付配置与 OpenAI fast policy）。
+func (h *SettingHandler) settingsChangedKeys(ctx context.Context, before *service.SystemSettings, after *service.SystemSettings, beforeAuthSourceDefaults *service.AuthSourceDefaultSettings, afterAuthSourceDefaults *service.AuthSourceDefaultSettings, req UpdateSettingsRequest) []string {
+	changed := diffSettings(before, after, beforeAuthSourceDefaults, afterAuthSourceDefaults, req)
+	if req.OpenAIFastPolicySettings != nil {
+		current, err := h.settingService.GetOpenAIFastPolicySettings(ctx)
+		if err != nil || !jsonEqual(openaiFastPolicySettingsToDTO(current), req.OpenAIFastPolicySettings) {
+			changed = append(changed, service.SettingKeyOpenAIFastPolicySettings)
+		}
+	}
+	if h.paymentConfigService != nil && hasPaymentFields(req) {
+		current, err := h.paymentConfigService.GetPaymentConfig(ctx)
+		if err != nil || paymentConfigChanged(current, buildPaymentConfigUpdate(req)) {
+			changed = append(changed, "payment_config")
+		}
+	}
+	return changed
+}
+
+// enforceSettingsGovernance 在设置落库前执行分类权限与审批校验。
+// 返回 false 时已写入响应（拒绝、冲突，或变更已进入待审批队列）。
+func (h *SettingHandler) enforceSettingsGovernance(c *gin.Context, original UpdateSettingsRequest, changed []string) bool {
+	if h.governanceService == nil {
+		return true
+	}
+	ctx := c.Request.Context()
+
+	// 批准重放：只允许应用申请时登记的变更，期间其它设置被改动则要求重新提交，
+	// 避免旧的完整请求体把他人后续的修改回滚。
+	if value, ok := c.Get(settingsChangeApprovalContextKey); ok {
+		approval := value.(*settingsChangeApproval)
+		if !stringSubset(changed, approval.request.ChangedKeys) {
+			response.ErrorFrom(c, service.ErrSettingChangeRequestStale)
+			return false
+		}
+		// 先占用审批状态再落库：并发批准时只有一方会真正写入
+		if _, err := h.governanceService.Review(ctx, approval.request.ID, true, settingsActorID(c), approval.note); err != nil {
+			response.ErrorFrom(c, err)
+			return false
+		}
+		return true
+	}
+
+	if len(changed) == 0 {
+		return true
+	}
+	policy, err := h.governanceService.GetPolicy(ctx)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return false
+	}
+	actorID := settingsActorID(c)
+	categories := service.SettingCategoriesForKeys(changed)
+	if denied := policy.DeniedCategories(actorID, categories); len(denied) > 0 {
+		response.ErrorFrom(c, service.ErrSettingCategoryForbidden.WithMetadata(map[string]string{
+			"categories": strings.Join(denied, ","),
+		}))
+		return false
+	}
+	if !policy.RequiresApproval(categories) {
+		return true
+	}
+
+	payload, err := json.Marshal(original)
+	if err != nil {
+		response.InternalError(c, "Failed to encode settings change")
+		return false
+	}
+	changeRequest, err := h.governanceService.Submit(ctx, service.SettingChangeKindSettings, actorID, changed, payload)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return false
+	}
+	response.Accepted(c, gin.H{
+		"pending_approval": true,
+		"change_request":   redactSettingChangeRequest(changeRequest),
+	})
+	return false
+}
+
+// RequireSettingCategory 独立设置接口的分类编辑权限校验。
+// 这些接口不经过审批队列（如 admin API key 轮换需要立即返回新密钥），只校验编辑权限。
+func (h *SettingHandler) RequireSettingCategory(category string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.governanceService == nil {
+			c.Next()
+			return
+		}
+		policy, err := h.governanceService.GetPolicy(c.Request.Context())
+		if err != nil {
+			response.ErrorFrom(c, err)
+			c.Abort()
+			return
+		}
+		if !policy.CanEdit(settingsActorID(c), category) {
+			response.ErrorFrom(c, service.ErrSettingCategoryForbidden.WithMetadata(map[string]string{"categories": category}))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetSettingsGovernance 获取设置分类权限与审批策略
+// GET /api/v1/admin/settings/governance
+func (h *SettingHandler) GetSettingsGovernance(c *gin.Context) {
+	if h.governanceService == nil {
+		response.InternalError(c, "Settings governance unavailable")
+		return
+	}
+	policy, err := h.governanceService.GetPolicy(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"categories": service.SettingCategories,
+		"policy":     policy,
+	})
+}
+
+// UpdateSettingsGovernance 更新设置分类权限与审批策略
+// PUT /api/v1/admin/settings/governance
+//
+// 策略本身属于 security 分类：需要 security 编辑权限；已开启审批时同样进入待审批队列，
+// 防止单个管理员先关闭审批再修改安全设置。
+func (h *SettingHandler) UpdateSettingsGovernance(c *gin.Context) {
+	if h.governanceService == nil {
+		response.InternalError(c, "Settings governance unavailable")
+		return
+	}
+	var req service.SettingsGovernancePolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	ctx := c.Request.Context()
+	current, err := h.governanceService.GetPolicy(ctx)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	actorID := settingsActorID(c)
+	if !current.CanEdit(actorID, service.SettingCategorySecurity) {
+		response.ErrorFrom(c, service.ErrSettingCategoryForbidden.WithMetadata(map[string]string{"categories": service.SettingCategorySecurity}))
+		return
+	}
+	policy, err := h.governanceService.NormalizePolicy(&req, actorID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	if current.RequireSecurityApproval {
+		payload, err := json.Marshal(policy)
+		if err != nil {
+			response.InternalError(c, "Failed to encode settings governance policy")
+			return
+		}
+		changeRequest, err := h.governanceService.Submit(ctx, service.SettingChangeKindGovernance, actorID, []string{service.SettingKeySettingsGovernance}, payload)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		response.Accepted(c, gin.H{
+			"pending_approval": true,
+			"change_request":   redactSettingChangeRequest(changeRequest),
+		})
+		return
+	}
+
+	if err := h.governanceService.SavePolicy(ctx, policy); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"categories": service.SettingCategories,
+		"policy":     policy,
+	})
+}
+
+// ListSettingChangeRequests 分页列出设置变更申请
+// GET /api/v1/admin/settings/change-requests?status=pending
+func (h *SettingHandler) ListSettingChangeRequests(c *gin.Context) {
+	if h.governanceService == nil {
+		response.InternalError(c, "Settings governance unavailable")
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	result, err := h.governanceService.List(c.Request.Context(), c.Query("status"), page, pageSize)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	items := make([]*service.SettingChangeRequest, 0, len(result.Items))
+	for _, item := range result.Items {
+		items = append(items, redactSettingChangeRequest(item))
+	}
+	response.Paginated(c, items, int64(result.Total), result.Page, result.PageSize)
+}
+
+// GetSettingChangeRequest 获取单个设置变更申请
+// GET /api/v1/admin/settings/change-requests/:id
+func (h *SettingHandler) GetSettingChangeRequest(c *gin.Context) {
+	if h.governanceService == nil {
+		response.InternalError(c, "Settings governance unavailable")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid change request ID")
+		return
+	}
+	item, err := h.governanceService.Get(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, redactSettingChangeRequest(item))
+}
+
+// ReviewSettingChangeRequest 审批请求体
+type ReviewSettingChangeRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// ApproveSettingChangeRequest 批准设置变更申请并立即生效
+// POST /api/v1/admin/settings/change-requests/:id/approve
+//
+// 审批人必须是与申请人不同的管理员会话（admin API key 不能审批），且可编辑申请涉及的全部分类。
+// settings 类型的申请按原请求体重放 UpdateSettings，step-up 等原有校验对审批人同样生效。
+func (h *SettingHandler) ApproveSettingChangeRequest(c *gin.Context) {
+	changeRequest, note, ok := h.loadReviewableChangeRequest(c, true)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	switch changeRequest.Kind {
+	case service.SettingChangeKindGovernance:
+		var policy service.SettingsGovernancePolicy
+		if err := json.Unmarshal(changeRequest.Payload, &policy); err != nil {
+			response.InternalError(c, "Invalid settings governance payload")
+			return
+		}
+		normalized, err := h.governanceService.NormalizePolicy(&policy, changeRequest.RequestedBy)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		reviewed, err := h.governanceService.Review(ctx, changeRequest.ID, true, settingsActorID(c), note)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		if err := h.governanceService.SavePolicy(ctx, normalized); err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		response.Success(c, redactSettingChangeRequest(reviewed))
+	case service.SettingChangeKindSettings:
+		c.Set(settingsChangeApprovalContextKey, &settingsChangeApproval{request: changeRequest, note: note})
+		c.Request.Body = io.NopCloser(bytes.NewReader(changeRequest.Payload))
+		c.Request.ContentLength = int64(len(changeRequest.Payload))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.UpdateSettings(c)
+	default:
+		response.InternalError(c, "Unknown setting change request kind")
+	}
+}
+
+// RejectSettingChangeRequest 拒绝设置变更申请（申请人可用于撤回）
+// POST /api/v1/admin/settings/change-requests/:id/reject
+func (h *SettingHandler) RejectSettingChangeRequest(c *gin.Context) {
+	changeRequest, note, ok := h.loadReviewableChangeRequest(c, false)
+	if !ok {
+		return
+	}
+	reviewed, err := h.governanceService.Review(c.Request.Context(), changeRequest.ID, false, settingsActorID(c), note)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, redactSettingChangeRequest(reviewed))
+}
+
+func (h *SettingHandler) loadReviewableChangeRequest(c *gin.Context, approve bool) (*service.SettingChangeRequest, string, bool) {
+	if h.governanceService == nil {
+		response.InternalError(c, "Settings governance unavailable")
+		return nil, "", false
+	}
+	if c.GetString("auth_method") == service.AuditAuthMethodAdminAPIKey {
+		response.ErrorWithDetails(c, http.StatusForbidden,
+			"Admin API key cannot review setting change requests; use an admin session",
+			"SETTING_CHANGE_REQUEST_ADMIN_API_KEY_FORBIDDEN", nil)
+		return nil, "", false
+	}
+	reviewerID := settingsActorID(c)
+	if reviewerID <= 0 {
+		response.Unauthorized(c, "Unauthorized")
+		return nil, "", false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid change request ID")
+		return nil, "", false
+	}
+	var req ReviewSettingChangeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request: "+err.Error())
+			return nil, "", false
+		}
+	}
+
+	ctx := c.Request.Context()
+	changeRequest, err := h.governanceService.Get(ctx, id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return nil, "", false
+	}
+	if err := h.governanceService.CheckReviewer(ctx, changeRequest, reviewerID, approve); err != nil {
+		response.ErrorFrom(c, err)
+		return nil, "", false
+	}
+	return changeRequest, req.Note, true
+}
+
+// redactSettingChangeRequest 返回脱敏副本：请求体中的密钥、密码字段以掩码替代。
+func redactSettingChangeRequest(item *service.SettingChangeRequest) *service.SettingChangeRequest {
+	if item == nil {
+		return nil
+	}
+	out := *item
+	var payload map[string]any
+	if err := json.Unmarshal(item.Payload, &payload); err != nil {
+		out.Payload = json.RawMessage("{}")
+		return &out
+	}
+	for key, value := range payload {
+		if !isSecretSettingKey(key) {
+			continue
+		}
+		if s, ok := value.(string); ok && s != "" {
+			payload[key] = "******"
+		}
+	}
+	if raw, err := json.Marshal(payload); err == nil {
+		out.Payload = raw
+	}
+	return &out
+}
+
+func isSecretSettingKey(key string) bool {
+	return strings.HasSuffix(key, "_secret") || strings.HasSuffix(key, "_password") || strings.HasSuffix(key, "_secret_key")
+}
+
+// paymentConfigChanged 判断支付配置更新是否会改变现值（仅比较请求中提供的字段）。
+func paymentConfigChanged(current *service.PaymentConfig, update service.UpdatePaymentConfigRequest) bool {
+	if current == nil {
+		return true
+	}
+	var currentFields, updateFields map[string]any
+	if !jsonRoundTrip(current, &currentFields) || !jsonRoundTrip(update, &updateFields) {
+		return true
+	}
+	for key, value := range updateFields {
+		if value == nil {
+			continue
+		}
+		existing, ok := currentFields[key]
+		if !ok {
+			// 可见支付方式等字段由 diffSettings 单独比较
+			continue
+		}
+		if !reflect.DeepEqual(existing, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonEqual(a, b any) bool {
+	var left, right any
+	if !jsonRoundTrip(a, &left) || !jsonRoundTrip(b, &right) {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func jsonRoundTrip(value any, out any) bool {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, out) == nil
+}
+
+func stringSubset(items, set []string) bool {
+	allowed := make(map[string]bool, len(set))
+	for _, item := range set {
+		allowed[item] = true
+	}
+	for _, item := range items {
+		if !allowed[item] {
+			return false
+		}
+	}
+	return true
+}
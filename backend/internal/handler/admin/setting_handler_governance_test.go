@@ -0,0 +1,187 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type settingChangeRequestRepoStub struct {
+	items []*service.SettingChangeRequest
+}
+
+func (r *settingChangeRequestRepoStub) Create(ctx context.Context, req *service.SettingChangeRequest) (*service.SettingChangeRequest, error) {
+	item := *req
+	item.ID = int64(len(r.items) + 1)
+	item.CreatedAt = time.Now()
+	r.items = append(r.items, &item)
+	return &item, nil
+}
+
+func (r *settingChangeRequestRepoStub) GetByID(ctx context.Context, id int64) (*service.SettingChangeRequest, error) {
+	for _, item := range r.items {
+		if item.ID == id {
+			copied := *item
+			return &copied, nil
+		}
+	}
+	return nil, service.ErrSettingChangeRequestNotFound
+}
+
+func (r *settingChangeRequestRepoStub) List(ctx context.Context, status string, page, pageSize int) (*service.SettingChangeRequestList, error) {
+	return &service.SettingChangeRequestList{Items: r.items, Total: len(r.items), Page: page, PageSize: pageSize}, nil
+}
+
+func (r *settingChangeRequestRepoStub) Review(ctx context.Context, id int64, status string, reviewerID int64, note string) (*service.SettingChangeRequest, error) {
+	for _, item := range r.items {
+		if item.ID != id {
+			continue
+		}
+		if item.Status != service.SettingChangeStatusPending {
+			return nil, service.ErrSettingChangeRequestNotPending
+		}
+		now := time.Now()
+		item.Status = status
+		item.ReviewedBy = &reviewerID
+		item.ReviewNote = note
+		item.ReviewedAt = &now
+		copied := *item
+		return &copied, nil
+	}
+	return nil, service.ErrSettingChangeRequestNotFound
+}
+
+// 审批策略：security 分类仅 1、2 号管理员可编辑，且需另一位管理员批准。
+const governanceTestPolicy = `{"category_editors":{"security":[1,2]},"require_security_approval":true}`
+
+func newGovernanceTestHandler(t *testing.T, stored map[string]string) (*SettingHandler, *settingHandlerRepoStub, *settingChangeRequestRepoStub) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	repo := &settingHandlerRepoStub{values: stored}
+	svc := service.NewSettingService(repo, &config.Config{Default: config.DefaultConfig{UserConcurrency: 5}})
+	changeRepo := &settingChangeRequestRepoStub{}
+	h := NewSettingHandler(svc, nil, nil, nil, nil, nil, nil)
+	h.SetGovernanceService(service.NewSettingGovernanceService(repo, changeRepo))
+	return h, repo, changeRepo
+}
+
+func asAdmin(userID int64) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.Set(string(middleware.ContextKeyUser), middleware.AuthSubject{UserID: userID})
+	}
+}
+
+func doApproveChangeRequest(h *SettingHandler, id int64, userID int64) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/settings/change-requests/"+strconv.FormatInt(id, 10)+"/approve", bytes.NewReader(nil))
+	c.Params = gin.Params{{Key: "id", Value: strconv.FormatInt(id, 10)}}
+	asAdmin(userID)(c)
+	h.ApproveSettingChangeRequest(c)
+	return rec
+}
+
+func TestUpdateSettingsSecurityChangeQueuedForApproval(t *testing.T) {
+	h, repo, changeRepo := newGovernanceTestHandler(t, map[string]string{
+		service.SettingKeySettingsGovernance: governanceTestPolicy,
+	})
+
+	rec := doUpdateSettings(t, h, map[string]any{"session_binding_enabled": true}, asAdmin(1))
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Contains(t, rec.Body.String(), `"pending_approval":true`)
+	require.NotEqual(t, "true", repo.values[service.SettingKeySessionBindingEnabled], "queued change must not be applied")
+	require.Len(t, changeRepo.items, 1)
+	require.Equal(t, service.SettingChangeKindSettings, changeRepo.items[0].Kind)
+	require.Contains(t, changeRepo.items[0].ChangedKeys, "session_binding_enabled")
+	require.Contains(t, changeRepo.items[0].Categories, service.SettingCategorySecurity)
+}
+
+func TestUpdateSettingsRejectsAdminOutsideCategoryEditors(t *testing.T) {
+	h, repo, changeRepo := newGovernanceTestHandler(t, map[string]string{
+		service.SettingKeySettingsGovernance: governanceTestPolicy,
+	})
+
+	rec := doUpdateSettings(t, h, map[string]any{"session_binding_enabled": true}, asAdmin(3))
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "SETTINGS_CATEGORY_FORBIDDEN")
+	require.Empty(t, changeRepo.items)
+	require.NotEqual(t, "true", repo.values[service.SettingKeySessionBindingEnabled])
+}
+
+func TestApproveSettingChangeRequestRequiresSecondAdmin(t *testing.T) {
+	h, repo, changeRepo := newGovernanceTestHandler(t, map[string]string{
+		service.SettingKeySettingsGovernance: governanceTestPolicy,
+	})
+	rec := doUpdateSettings(t, h, map[string]any{"session_binding_enabled": true}, asAdmin(1))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	id := changeRepo.items[0].ID
+
+	rec = doApproveChangeRequest(h, id, 1)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "SETTING_CHANGE_REQUEST_SELF_APPROVAL")
+
+	rec = doApproveChangeRequest(h, id, 3)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "SETTINGS_CATEGORY_FORBIDDEN")
+	require.NotEqual(t, "true", repo.values[service.SettingKeySessionBindingEnabled])
+
+	rec = doApproveChangeRequest(h, id, 2)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "true", repo.values[service.SettingKeySessionBindingEnabled])
+	require.Equal(t, service.SettingChangeStatusApproved, changeRepo.items[0].Status)
+	require.Equal(t, int64(2), *changeRepo.items[0].ReviewedBy)
+
+	rec = doApproveChangeRequest(h, id, 2)
+	require.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestApproveSettingChangeRequestRejectsStalePayload(t *testing.T) {
+	h, repo, changeRepo := newGovernanceTestHandler(t, map[string]string{
+		service.SettingKeySettingsGovernance: governanceTestPolicy,
+	})
+	rec := doUpdateSettings(t, h, map[string]any{"session_binding_enabled": true}, asAdmin(1))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	// 申请后其它设置被修改：重放旧请求体会回滚该修改，必须拒绝
+	repo.values[service.SettingKeySiteLogo] = "https://example.com/logo.png"
+
+	rec = doApproveChangeRequest(h, changeRepo.items[0].ID, 2)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Contains(t, rec.Body.String(), "SETTING_CHANGE_REQUEST_STALE")
+	require.Equal(t, service.SettingChangeStatusPending, changeRepo.items[0].Status)
+	require.Equal(t, "https://example.com/logo.png", repo.values[service.SettingKeySiteLogo])
+}
+
+func TestUpdateSettingsWithoutApprovalAppliesPermittedChange(t *testing.T) {
+	h, repo, changeRepo := newGovernanceTestHandler(t, map[string]string{
+		service.SettingKeySettingsGovernance: `{"category_editors":{"security":[1]}}`,
+	})
+
+	rec := doUpdateSettings(t, h, map[string]any{"session_binding_enabled": true}, asAdmin(1))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "true", repo.values[service.SettingKeySessionBindingEnabled])
+	require.Empty(t, changeRepo.items)
+}
+
+func TestRedactSettingChangeRequestMasksSecrets(t *testing.T) {
+	item := &service.SettingChangeRequest{Payload: []byte(`{"smtp_password":"p","oidc_connect_client_secret":"s","site_name":"x","turnstile_secret_key":""}`)}
+
+	redacted := redactSettingChangeRequest(item)
+
+	require.JSONEq(t, `{"smtp_password":"******","oidc_connect_client_secret":"******","site_name":"x","turnstile_secret_key":""}`, string(redacted.Payload))
+	require.Contains(t, string(item.Payload), `"smtp_password":"p"`, "original must not be mutated")
+}
@@ -367,6 +367,38 @@ func (h *SettingHandler) UpdateStreamTimeoutSettings(c *gin.Context) {
 	})
 }
 
+// GetUpstreamTimeoutProfiles 获取各平台上游超时档位
+// GET /api/v1/admin/settings/upstream-timeouts
+func (h *SettingHandler) GetUpstreamTimeoutProfiles(c *gin.Context) {
+	settings, err := h.settingService.GetUpstreamTimeoutProfileSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, settings)
+}
+
+// UpdateUpstreamTimeoutProfiles 更新各平台上游超时档位（流式 / 非流式 / utility，单位秒，0 表示沿用默认）
+// PUT /api/v1/admin/settings/upstream-timeouts
+func (h *SettingHandler) UpdateUpstreamTimeoutProfiles(c *gin.Context) {
+	var req service.UpstreamTimeoutProfileSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if err := h.settingService.SetUpstreamTimeoutProfileSettings(c.Request.Context(), &req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	updated, err := h.settingService.GetUpstreamTimeoutProfileSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
 // GetWebSearchEmulationConfig 获取 Web Search 模拟配置
 // GET /api/v1/admin/settings/web-search-emulation
 func (h *SettingHandler) GetWebSearchEmulationConfig(c *gin.Context) {
@@ -379,6 +379,8 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		response.BadRequest(c, "Invalid request: "+err.Error())
 		return
 	}
+	// 规范化前的原始请求：进入审批队列时按此重放，避免把补全的现有密钥写入申请
+	original := req
 
 	previousSettings, err := h.settingService.GetAllSettings(c.Request.Context())
 	if err != nil {
@@ -1690,6 +1692,13 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		},
 		ForceEmailOnThirdPartySignup: boolValueOrDefault(req.ForceEmailOnThirdPartySignup, previousAuthSourceDefaults.ForceEmailOnThirdPartySignup),
 	}
+	// 分类编辑权限与 security 审批：需要审批时本次请求只登记申请，不落库
+	if h.governanceService != nil {
+		changed := h.settingsChangedKeys(c.Request.Context(), previousSettings, settings, previousAuthSourceDefaults, authSourceDefaults, req)
+		if !h.enforceSettingsGovernance(c, original, changed) {
+			return
+		}
+	}
 	if err := h.settingService.UpdateSettingsWithAuthSourceDefaults(c.Request.Context(), settings, authSourceDefaults); err != nil {
 		response.ErrorFrom(c, err)
 		return
@@ -1709,30 +1718,7 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 	// Update payment configuration (integrated into system settings).
 	// Skip if no payment fields were provided (prevents accidental wipe).
 	if h.paymentConfigService != nil && hasPaymentFields(req) {
-		paymentReq := service.UpdatePaymentConfigRequest{
-			Enabled:                   req.PaymentEnabled,
-			MinAmount:                 req.PaymentMinAmount,
-			MaxAmount:                 req.PaymentMaxAmount,
-			DailyLimit:                req.PaymentDailyLimit,
-			OrderTimeoutMin:           req.PaymentOrderTimeoutMin,
-			MaxPendingOrders:          req.PaymentMaxPendingOrders,
-			EnabledTypes:              req.PaymentEnabledTypes,
-			BalanceDisabled:           req.PaymentBalanceDisabled,
-			BalanceRechargeMultiplier: req.PaymentBalanceRechargeMultiplier,
-			SubscriptionUSDToCNYRate:  req.PaymentSubscriptionUSDToCNYRate,
-			RechargeFeeRate:           req.PaymentRechargeFeeRate,
-			LoadBalanceStrategy:       req.PaymentLoadBalanceStrat,
-			ProductNamePrefix:         req.PaymentProductNamePrefix,
-			ProductNameSuffix:         req.PaymentProductNameSuffix,
-			HelpImageURL:              req.PaymentHelpImageURL,
-			HelpText:                  req.PaymentHelpText,
-			CancelRateLimitEnabled:    req.PaymentCancelRateLimitEnabled,
-			CancelRateLimitMax:        req.PaymentCancelRateLimitMax,
-			CancelRateLimitWindow:     req.PaymentCancelRateLimitWindow,
-			CancelRateLimitUnit:       req.PaymentCancelRateLimitUnit,
-			CancelRateLimitMode:       req.PaymentCancelRateLimitMode,
-			AlipayForceQRCode:         req.PaymentAlipayForceQRCode,
-		}
+		paymentReq := buildPaymentConfigUpdate(req)
 		if err := h.paymentConfigService.UpdatePaymentConfig(c.Request.Context(), paymentReq); err != nil {
 			response.ErrorFrom(c, err)
 			return
@@ -2026,6 +2012,34 @@ func mapDingTalkValidateError(err error) string {
 	}
 }
 
+// buildPaymentConfigUpdate 从设置请求中提取支付配置更新。
+func buildPaymentConfigUpdate(req UpdateSettingsRequest) service.UpdatePaymentConfigRequest {
+	return service.UpdatePaymentConfigRequest{
+		Enabled:                   req.PaymentEnabled,
+		MinAmount:                 req.PaymentMinAmount,
+		MaxAmount:                 req.PaymentMaxAmount,
+		DailyLimit:                req.PaymentDailyLimit,
+		OrderTimeoutMin:           req.PaymentOrderTimeoutMin,
+		MaxPendingOrders:          req.PaymentMaxPendingOrders,
+		EnabledTypes:              req.PaymentEnabledTypes,
+		BalanceDisabled:           req.PaymentBalanceDisabled,
+		BalanceRechargeMultiplier: req.PaymentBalanceRechargeMultiplier,
+		SubscriptionUSDToCNYRate:  req.PaymentSubscriptionUSDToCNYRate,
+		RechargeFeeRate:           req.PaymentRechargeFeeRate,
+		LoadBalanceStrategy:       req.PaymentLoadBalanceStrat,
+		ProductNamePrefix:         req.PaymentProductNamePrefix,
+		ProductNameSuffix:         req.PaymentProductNameSuffix,
+		HelpImageURL:              req.PaymentHelpImageURL,
+		HelpText:                  req.PaymentHelpText,
+		CancelRateLimitEnabled:    req.PaymentCancelRateLimitEnabled,
+		CancelRateLimitMax:        req.PaymentCancelRateLimitMax,
+		CancelRateLimitWindow:     req.PaymentCancelRateLimitWindow,
+		CancelRateLimitUnit:       req.PaymentCancelRateLimitUnit,
+		CancelRateLimitMode:       req.PaymentCancelRateLimitMode,
+		AlipayForceQRCode:         req.PaymentAlipayForceQRCode,
+	}
+}
+
 func hasPaymentFields(req UpdateSettingsRequest) bool {
 	return req.PaymentEnabled != nil || req.PaymentMinAmount != nil ||
 		req.PaymentMaxAmount != nil || req.PaymentDailyLimit != nil ||
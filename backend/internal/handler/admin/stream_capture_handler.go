@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamCaptureHandler 上游原始响应留存查询与下载接口（争议核查用）。
+type StreamCaptureHandler struct {
+	captureService *service.UpstreamStreamCaptureService
+	usageService   *service.UsageService
+}
+
+// NewStreamCaptureHandler 创建上游原始响应留存处理器。
+func NewStreamCaptureHandler(captureService *service.UpstreamStreamCaptureService, usageService *service.UsageService) *StreamCaptureHandler {
+	return &StreamCaptureHandler{captureService: captureService, usageService: usageService}
+}
+
+// List 分页查询留存索引。
+// GET /api/v1/admin/stream-captures
+func (h *StreamCaptureHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.UpstreamStreamCaptureFilter{
+		Page:      page,
+		PageSize:  pageSize,
+		RequestID: strings.TrimSpace(c.Query("request_id")),
+		Reason:    strings.TrimSpace(c.Query("reason")),
+	}
+	if v := strings.TrimSpace(c.Query("api_key_id")); v != "" {
+		apiKeyID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || apiKeyID <= 0 {
+			response.BadRequest(c, "Invalid api_key_id")
+			return
+		}
+		filter.APIKeyID = &apiKeyID
+	}
+
+	result, err := h.captureService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Items, int64(result.Total), result.Page, result.PageSize)
+}
+
+// Download 下载指定留存的原始内容。
+// GET /api/v1/admin/stream-captures/:id
+func (h *StreamCaptureHandler) Download(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid capture ID")
+		return
+	}
+	record, body, err := h.captureService.Open(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	writeStreamCapture(c, record, body)
+}
+
+// DownloadByUsage 按使用记录下载对应请求的原始上游内容（经 request_id 关联）。
+// GET /api/v1/admin/usage/:id/stream-capture
+func (h *StreamCaptureHandler) DownloadByUsage(c *gin.Context) {
+	usageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || usageID <= 0 {
+		response.BadRequest(c, "Invalid usage log ID")
+		return
+	}
+	usageLog, err := h.usageService.GetByID(c.Request.Context(), usageID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	record, body, err := h.captureService.OpenByRequestID(c.Request.Context(), usageLog.RequestID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	writeStreamCapture(c, record, body)
+}
+
+func writeStreamCapture(c *gin.Context, record *service.UpstreamStreamCaptureRecord, body io.ReadCloser) {
+	defer func() { _ = body.Close() }()
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=stream-capture-%d.sse", record.ID))
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("X-Sub2API-Capture-Truncated", strconv.FormatBool(record.Truncated))
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, body)
+}
@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupportBundleHandler 支持包下载接口。
+type SupportBundleHandler struct {
+	supportBundleService *service.SupportBundleService
+}
+
+// NewSupportBundleHandler 创建支持包处理器。
+func NewSupportBundleHandler(supportBundleService *service.SupportBundleService) *SupportBundleHandler {
+	return &SupportBundleHandler{supportBundleService: supportBundleService}
+}
+
+// Download 生成并下载支持包（zip）：脱敏配置、最近错误摘要、版本信息、worker 池状态与自检报告。
+// 先在内存中完整生成再写出，生成失败时仍能返回 JSON 错误而不是半截文件。
+// GET /api/v1/admin/support-bundle
+func (h *SupportBundleHandler) Download(c *gin.Context) {
+	if h.supportBundleService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Support bundle service not available")
+		return
+	}
+	var buf bytes.Buffer
+	if err := h.supportBundleService.Write(c.Request.Context(), &buf); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", h.supportBundleService.FileName()))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
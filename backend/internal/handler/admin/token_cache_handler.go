@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenCacheHandler 上游 access token 缓存运维接口。
+type TokenCacheHandler struct {
+	invalidator *service.CompositeTokenCacheInvalidator
+}
+
+// NewTokenCacheHandler 创建 token 缓存处理器。
+func NewTokenCacheHandler(invalidator *service.CompositeTokenCacheInvalidator) *TokenCacheHandler {
+	return &TokenCacheHandler{invalidator: invalidator}
+}
+
+// Wipe 清空全部缓存的上游 access token（事故响应用）。
+// POST /api/v1/admin/token-cache/wipe
+func (h *TokenCacheHandler) Wipe(c *gin.Context) {
+	deleted, err := h.invalidator.InvalidateAll(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"deleted": deleted})
+}
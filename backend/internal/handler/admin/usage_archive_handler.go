@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageArchiveHandler 使用记录归档查询、下载与审计恢复接口。
+type UsageArchiveHandler struct {
+	archiveService *service.UsageArchiveService
+}
+
+// NewUsageArchiveHandler 创建使用记录归档处理器。
+func NewUsageArchiveHandler(archiveService *service.UsageArchiveService) *UsageArchiveHandler {
+	return &UsageArchiveHandler{archiveService: archiveService}
+}
+
+// List 分页查询归档索引，可按月份（YYYY-MM）过滤。
+// GET /api/v1/admin/usage/archives
+func (h *UsageArchiveHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.UsageLogArchiveFilter{Page: page, PageSize: pageSize}
+	if v := strings.TrimSpace(c.Query("month")); v != "" {
+		month, err := time.Parse("2006-01", v)
+		if err != nil {
+			response.BadRequest(c, "Invalid month, expected YYYY-MM")
+			return
+		}
+		filter.Month = month
+	}
+
+	result, err := h.archiveService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Items, int64(result.Total), result.Page, result.PageSize)
+}
+
+// Download 下载归档对象（gzip 压缩的 JSONL，每行一条 usage_logs 记录）。
+// GET /api/v1/admin/usage/archives/:id/download
+func (h *UsageArchiveHandler) Download(c *gin.Context) {
+	id, ok := parseUsageArchiveID(c)
+	if !ok {
+		return
+	}
+	archive, body, err := h.archiveService.Open(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	defer func() { _ = body.Close() }()
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=usage-archive-%s-%d.jsonl.gz", archive.Month.Format("2006-01"), archive.ID))
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, body)
+}
+
+// Restore 将归档恢复到审计恢复区，重复调用幂等。
+// POST /api/v1/admin/usage/archives/:id/restore
+func (h *UsageArchiveHandler) Restore(c *gin.Context) {
+	id, ok := parseUsageArchiveID(c)
+	if !ok {
+		return
+	}
+	archive, restored, err := h.archiveService.Restore(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"archive": archive, "restored_rows": restored})
+}
+
+// ListRestoredRows 分页读取已恢复的归档记录（原始 usage_logs 列）。
+// GET /api/v1/admin/usage/archives/:id/rows
+func (h *UsageArchiveHandler) ListRestoredRows(c *gin.Context) {
+	id, ok := parseUsageArchiveID(c)
+	if !ok {
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	items, total, err := h.archiveService.ListRestoredRows(c.Request.Context(), id, page, pageSize)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, items, int64(total), page, pageSize)
+}
+
+// ReleaseRestore 审计结束后清空恢复区中该归档的记录。
+// DELETE /api/v1/admin/usage/archives/:id/restore
+func (h *UsageArchiveHandler) ReleaseRestore(c *gin.Context) {
+	id, ok := parseUsageArchiveID(c)
+	if !ok {
+		return
+	}
+	deleted, err := h.archiveService.ReleaseRestore(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"deleted_rows": deleted})
+}
+
+func parseUsageArchiveID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid archive ID")
+		return 0, false
+	}
+	return id, true
+}
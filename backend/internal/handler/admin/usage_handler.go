@@ -25,8 +25,15 @@ type UsageHandler struct {
 	apiKeyService  *service.APIKeyService
 	adminService   service.AdminService
 	cleanupService *service.UsageCleanupService
+	listVersion    *service.AdminListVersionService
+	recost         *service.UsageRecostService
 }
 
+const (
+	adminUsageChangesDefaultLimit = 100
+	adminUsageChangesMaxLimit     = 500
+)
+
 // NewUsageHandler creates a new admin usage handler
 func NewUsageHandler(
 	usageService *service.UsageService,
@@ -42,6 +49,34 @@ func NewUsageHandler(
 	}
 }
 
+// SetListVersionService attaches the optional list version service used for ETag generation.
+func (h *UsageHandler) SetListVersionService(svc *service.AdminListVersionService) {
+	h.listVersion = svc
+}
+
+// SetUsageRecostService attaches the optional service used to re-cost usage under a pricing snapshot.
+func (h *UsageHandler) SetUsageRecostService(svc *service.UsageRecostService) {
+	h.recost = svc
+}
+
+// resolveUsageRecoster 解析 pricing_snapshot 查询参数；未携带时返回 nil。参数非法时已写出错误并返回 false。
+func (h *UsageHandler) resolveUsageRecoster(c *gin.Context) (*service.UsageRecoster, bool) {
+	spec := strings.TrimSpace(c.Query("pricing_snapshot"))
+	if spec == "" {
+		return nil, true
+	}
+	if h.recost == nil {
+		response.BadRequest(c, "pricing_snapshot is not supported")
+		return nil, false
+	}
+	recoster, err := h.recost.Resolve(spec)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return nil, false
+	}
+	return recoster, true
+}
+
 // CreateUsageCleanupTaskRequest represents cleanup task creation request
 type CreateUsageCleanupTaskRequest struct {
 	StartDate   string  `json:"start_date"`
@@ -61,12 +96,130 @@ type CreateUsageCleanupTaskRequest struct {
 // GET /api/v1/admin/usage
 func (h *UsageHandler) List(c *gin.Context) {
 	page, pageSize := response.ParsePagination(c)
+	filters, ok := parseAdminUsageLogFilters(c)
+	if !ok {
+		return
+	}
+	recoster, ok := h.resolveUsageRecoster(c)
+	if !ok {
+		return
+	}
+	// 用量日志只追加：数据指纹未变化时直接返回 304，跳过列表与关联查询。
+	// 重算结果还取决于价格表，带 pricing_snapshot 时不走 ETag。
+	if recoster == nil && h.writeUsageListNotModified(c) {
+		return
+	}
+
+	cursor, ok := response.ParseCursor(c)
+	if !ok {
+		return
+	}
+
+	params := pagination.PaginationParams{
+		Page:      page,
+		PageSize:  pageSize,
+		SortBy:    c.DefaultQuery("sort_by", "created_at"),
+		SortOrder: c.DefaultQuery("sort_order", "desc"),
+		Cursor:    cursor,
+	}
+	records, result, err := h.usageService.ListWithFilters(c.Request.Context(), params, filters)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.AdminUsageLog, 0, len(records))
+	for i := range records {
+		item := dto.UsageLogFromServiceAdmin(&records[i])
+		if recoster != nil {
+			item.Recost = dto.UsageRecostFromService(recoster.Recost(&records[i]))
+		}
+		out = append(out, *item)
+	}
+	response.PaginatedByMode(c, out, params, result)
+}
+
+// PricingSnapshots 列出可供 pricing_snapshot 参数使用的历史价格快照与命名覆盖集
+// GET /api/v1/admin/usage/pricing-snapshots
+func (h *UsageHandler) PricingSnapshots(c *gin.Context) {
+	if h.recost == nil {
+		response.Success(c, service.PricingSnapshotCatalog{})
+		return
+	}
+	catalog, err := h.recost.Catalog()
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, catalog)
+}
+
+// Changes 增量拉取 after_id 之后的新用量记录（支持与 List 相同的过滤参数），按 id 倒序。
+// has_more 为 true 表示新记录超过 limit，客户端应回退到全量刷新。
+// GET /api/v1/admin/usage/changes
+func (h *UsageHandler) Changes(c *gin.Context) {
+	afterID, err := strconv.ParseInt(strings.TrimSpace(c.Query("after_id")), 10, 64)
+	if err != nil || afterID < 0 {
+		response.BadRequest(c, "Invalid after_id")
+		return
+	}
+	limit := adminUsageChangesDefaultLimit
+	if limitStr := strings.TrimSpace(c.Query("limit")); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		limit = min(parsed, adminUsageChangesMaxLimit)
+	}
+	filters, ok := parseAdminUsageLogFilters(c)
+	if !ok {
+		return
+	}
+	filters.AfterID = afterID
+	filters.ExactTotal = false
+
+	params := pagination.PaginationParams{Page: 1, PageSize: limit, SortBy: "id", SortOrder: "desc"}
+	records, result, err := h.usageService.ListWithFilters(c.Request.Context(), params, filters)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	latestID := afterID
+	out := make([]dto.AdminUsageLog, 0, len(records))
+	for i := range records {
+		latestID = max(latestID, records[i].ID)
+		out = append(out, *dto.UsageLogFromServiceAdmin(&records[i]))
+	}
+	response.Success(c, gin.H{
+		"items":     out,
+		"latest_id": latestID,
+		"has_more":  result != nil && result.Total > int64(len(records)),
+	})
+}
+
+// writeUsageListNotModified 设置 ETag，命中 If-None-Match 时写出 304 并返回 true。
+func (h *UsageHandler) writeUsageListNotModified(c *gin.Context) bool {
+	if h.listVersion == nil {
+		return false
+	}
+	etag, err := h.listVersion.ListETag(c.Request.Context(), service.AdminListUsageLogs, c.Request.URL.Query())
+	if err != nil {
+		logger.LegacyPrintf("handler.admin.usage", "[AdminUsage] build list etag failed: %v", err)
+		return false
+	}
+	return writeAdminListETag(c, etag)
+}
+
+// parseAdminUsageLogFilters 解析管理端用量列表的过滤参数；参数非法时已写出 400 并返回 false。
+func parseAdminUsageLogFilters(c *gin.Context) (usagestats.UsageLogFilters, bool) {
 	exactTotal := false
 	if exactTotalRaw := strings.TrimSpace(c.Query("exact_total")); exactTotalRaw != "" {
 		parsed, err := strconv.ParseBool(exactTotalRaw)
 		if err != nil {
 			response.BadRequest(c, "Invalid exact_total value, use true or false")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		exactTotal = parsed
 	}
@@ -77,7 +230,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		id, err := strconv.ParseInt(userIDStr, 10, 64)
 		if err != nil {
 			response.BadRequest(c, "Invalid user_id")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		userID = id
 	}
@@ -86,7 +239,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		id, err := strconv.ParseInt(apiKeyIDStr, 10, 64)
 		if err != nil {
 			response.BadRequest(c, "Invalid api_key_id")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		apiKeyID = id
 	}
@@ -95,7 +248,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		id, err := strconv.ParseInt(accountIDStr, 10, 64)
 		if err != nil {
 			response.BadRequest(c, "Invalid account_id")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		accountID = id
 	}
@@ -104,7 +257,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		id, err := strconv.ParseInt(groupIDStr, 10, 64)
 		if err != nil {
 			response.BadRequest(c, "Invalid group_id")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		groupID = id
 	}
@@ -118,7 +271,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		parsed, err := service.ParseUsageRequestType(requestTypeStr)
 		if err != nil {
 			response.BadRequest(c, err.Error())
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		value := int16(parsed)
 		requestType = &value
@@ -126,7 +279,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		val, err := strconv.ParseBool(streamStr)
 		if err != nil {
 			response.BadRequest(c, "Invalid stream value, use true or false")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		stream = &val
 	}
@@ -136,7 +289,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		val, err := strconv.ParseInt(billingTypeStr, 10, 8)
 		if err != nil {
 			response.BadRequest(c, "Invalid billing_type")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		bt := int8(val)
 		billingType = &bt
@@ -149,7 +302,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
 		if err != nil {
 			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		startTime = &t
 	}
@@ -158,20 +311,14 @@ func (h *UsageHandler) List(c *gin.Context) {
 		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
 		if err != nil {
 			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
-			return
+			return usagestats.UsageLogFilters{}, false
 		}
 		// Use half-open range [start, end), move to next calendar day start (DST-safe).
 		t = t.AddDate(0, 0, 1)
 		endTime = &t
 	}
 
-	params := pagination.PaginationParams{
-		Page:      page,
-		PageSize:  pageSize,
-		SortBy:    c.DefaultQuery("sort_by", "created_at"),
-		SortOrder: c.DefaultQuery("sort_order", "desc"),
-	}
-	filters := usagestats.UsageLogFilters{
+	return usagestats.UsageLogFilters{
 		UserID:      userID,
 		APIKeyID:    apiKeyID,
 		AccountID:   accountID,
@@ -184,19 +331,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		StartTime:   startTime,
 		EndTime:     endTime,
 		ExactTotal:  exactTotal,
-	}
-
-	records, result, err := h.usageService.ListWithFilters(c.Request.Context(), params, filters)
-	if err != nil {
-		response.ErrorFrom(c, err)
-		return
-	}
-
-	out := make([]dto.AdminUsageLog, 0, len(records))
-	for i := range records {
-		out = append(out, *dto.UsageLogFromServiceAdmin(&records[i]))
-	}
-	response.Paginated(c, out, result.Total, page, pageSize)
+	}, true
 }
 
 // Stats handles getting usage statistics with filters
@@ -310,6 +445,12 @@ func (h *UsageHandler) Stats(c *gin.Context) {
 		endTime = now
 	}
 
+	spec := strings.TrimSpace(c.Query("pricing_snapshot"))
+	if spec != "" && h.recost == nil {
+		response.BadRequest(c, "pricing_snapshot is not supported")
+		return
+	}
+
 	// Build filters and call GetStatsWithFilters
 	filters := usagestats.UsageLogFilters{
 		UserID:      userID,
@@ -345,7 +486,23 @@ func (h *UsageHandler) Stats(c *gin.Context) {
 		c.Header("X-Usage-Stats-Cache", cacheStatusValue(hit))
 	}
 
-	response.Success(c, stats)
+	if spec == "" {
+		response.Success(c, stats)
+		return
+	}
+	// 在原统计之外附带按价格快照重算的原费用 / 重算费用汇总
+	summary, err := h.recost.Summarize(c.Request.Context(), spec, filters)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, usageStatsWithRecost{UsageStats: stats, Recost: summary})
+}
+
+// usageStatsWithRecost 统计结果附带重算汇总（UsageStats 字段平铺在顶层，保持原响应结构兼容）
+type usageStatsWithRecost struct {
+	*usagestats.UsageStats
+	Recost *service.UsageRecostSummary `json:"recost"`
 }
 
 // SearchUsers handles searching users by email keyword
@@ -436,8 +593,12 @@ func (h *UsageHandler) ListCleanupTasks(c *gin.Context) {
 		operator = subject.UserID
 	}
 	page, pageSize := response.ParsePagination(c)
-	logger.LegacyPrintf("handler.admin.usage", "[UsageCleanup] 请求清理任务列表: operator=%d page=%d page_size=%d", operator, page, pageSize)
-	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	cursor, ok := response.ParseCursor(c)
+	if !ok {
+		return
+	}
+	logger.LegacyPrintf("handler.admin.usage", "[UsageCleanup] 请求清理任务列表: operator=%d page=%d page_size=%d cursor=%t", operator, page, pageSize, cursor != nil)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize, Cursor: cursor}
 	tasks, result, err := h.cleanupService.ListTasks(c.Request.Context(), params)
 	if err != nil {
 		logger.LegacyPrintf("handler.admin.usage", "[UsageCleanup] 查询清理任务列表失败: operator=%d page=%d page_size=%d err=%v", operator, page, pageSize, err)
@@ -449,7 +610,7 @@ func (h *UsageHandler) ListCleanupTasks(c *gin.Context) {
 		out = append(out, *dto.UsageCleanupTaskFromService(&tasks[i]))
 	}
 	logger.LegacyPrintf("handler.admin.usage", "[UsageCleanup] 返回清理任务列表: operator=%d total=%d items=%d page=%d page_size=%d", operator, result.Total, len(out), page, pageSize)
-	response.Paginated(c, out, result.Total, page, pageSize)
+	response.PaginatedByMode(c, out, params, result)
 }
 
 // CreateCleanupTask handles creating a usage cleanup task
@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type usageETagVersionRepoStub struct {
+	fingerprint string
+}
+
+func (s *usageETagVersionRepoStub) AccountsFingerprint(context.Context) (string, error) {
+	return "", nil
+}
+
+func (s *usageETagVersionRepoStub) UsageLogsFingerprint(context.Context) (string, error) {
+	return s.fingerprint, nil
+}
+
+func (s *usageETagVersionRepoStub) AccountChangesSince(context.Context, time.Time, int) ([]int64, []int64, error) {
+	return nil, nil, nil
+}
+
+type usageChangesRepoCapture struct {
+	service.UsageLogRepository
+	calls       int
+	listParams  pagination.PaginationParams
+	listFilters usagestats.UsageLogFilters
+	records     []service.UsageLog
+}
+
+func (s *usageChangesRepoCapture) ListWithFilters(_ context.Context, params pagination.PaginationParams, filters usagestats.UsageLogFilters) ([]service.UsageLog, *pagination.PaginationResult, error) {
+	s.calls++
+	s.listParams = params
+	s.listFilters = filters
+	// 模拟快速分页：还有更多时 total = limit + 1
+	total := int64(len(s.records))
+	if len(s.records) >= params.PageSize {
+		total = int64(params.PageSize) + 1
+	}
+	return s.records, &pagination.PaginationResult{Total: total, Page: params.Page, PageSize: params.PageSize}, nil
+}
+
+func newUsageETagTestRouter(repo *usageChangesRepoCapture, versionRepo *usageETagVersionRepoStub) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewUsageHandler(service.NewUsageService(repo, nil, nil, nil), nil, nil, nil)
+	handler.SetListVersionService(service.NewAdminListVersionService(versionRepo, nil))
+	router := gin.New()
+	router.GET("/admin/usage", handler.List)
+	router.GET("/admin/usage/changes", handler.Changes)
+	return router
+}
+
+func TestAdminUsageList_ETagNotModifiedSkipsQuery(t *testing.T) {
+	repo := &usageChangesRepoCapture{}
+	versionRepo := &usageETagVersionRepoStub{fingerprint: "1:10:0"}
+	router := newUsageETagTestRouter(repo, versionRepo)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/usage?page=1&model=claude", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.Equal(t, 1, repo.calls)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/usage?model=claude&page=1", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Equal(t, 1, repo.calls)
+
+	versionRepo.fingerprint = "1:11:0"
+	req = httptest.NewRequest(http.MethodGet, "/admin/usage?model=claude&page=1", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotEqual(t, etag, rec.Header().Get("ETag"))
+	require.Equal(t, 2, repo.calls)
+}
+
+func TestAdminUsageChanges(t *testing.T) {
+	repo := &usageChangesRepoCapture{records: []service.UsageLog{{ID: 42}, {ID: 41}}}
+	router := newUsageETagTestRouter(repo, &usageETagVersionRepoStub{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/usage/changes?after_id=40&limit=2&account_id=7&exact_total=true", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, int64(40), repo.listFilters.AfterID)
+	require.Equal(t, int64(7), repo.listFilters.AccountID)
+	require.False(t, repo.listFilters.ExactTotal)
+	require.Equal(t, "id", repo.listParams.SortBy)
+	require.Equal(t, 2, repo.listParams.PageSize)
+
+	var resp struct {
+		Data struct {
+			Items    []json.RawMessage `json:"items"`
+			LatestID int64             `json:"latest_id"`
+			HasMore  bool              `json:"has_more"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Items, 2)
+	require.Equal(t, int64(42), resp.Data.LatestID)
+	require.True(t, resp.Data.HasMore)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/usage/changes?after_id=bad", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
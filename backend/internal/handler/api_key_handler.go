@@ -42,6 +42,15 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h *float64 `json:"rate_limit_5h"`
 	RateLimit1d *float64 `json:"rate_limit_1d"`
 	RateLimit7d *float64 `json:"rate_limit_7d"`
+
+	// 会话全文留存（需管理员开启全局开关后生效）
+	TranscriptEnabled bool `json:"transcript_enabled"`
+	// 强制回复语言（空 = 沿用分组，off = 关闭分组的强制设置）
+	ResponseLanguage string `json:"response_language"`
+	// 严格请求校验：转发前校验请求体并返回字段级错误
+	StrictValidation bool `json:"strict_validation"`
+	// 单请求预估费用上限（美元，0 = 不限制）
+	MaxRequestCost float64 `json:"max_request_cost"`
 }
 
 // UpdateAPIKeyRequest represents the update API key request payload
@@ -60,6 +69,11 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // 重置限速用量
+
+	TranscriptEnabled *bool    `json:"transcript_enabled"` // 会话全文留存开关（nil 不修改）
+	ResponseLanguage  *string  `json:"response_language"`  // 强制回复语言（nil 不修改）
+	StrictValidation  *bool    `json:"strict_validation"`  // 严格请求校验开关（nil 不修改）
+	MaxRequestCost    *float64 `json:"max_request_cost"`   // 单请求费用上限（nil 不修改，0 取消）
 }
 
 // List handles listing user's API keys with pagination
@@ -160,6 +174,11 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 		IPWhitelist:   req.IPWhitelist,
 		IPBlacklist:   req.IPBlacklist,
 		ExpiresInDays: req.ExpiresInDays,
+
+		TranscriptEnabled: req.TranscriptEnabled,
+		ResponseLanguage:  req.ResponseLanguage,
+		StrictValidation:  req.StrictValidation,
+		MaxRequestCost:    req.MaxRequestCost,
 	}
 	if req.Quota != nil {
 		svcReq.Quota = *req.Quota
@@ -213,6 +232,10 @@ func (h *APIKeyHandler) Update(c *gin.Context) {
 		RateLimit1d:         req.RateLimit1d,
 		RateLimit7d:         req.RateLimit7d,
 		ResetRateLimitUsage: req.ResetRateLimitUsage,
+		TranscriptEnabled:   req.TranscriptEnabled,
+		ResponseLanguage:    req.ResponseLanguage,
+		StrictValidation:    req.StrictValidation,
+		MaxRequestCost:      req.MaxRequestCost,
 	}
 	if req.Name != "" {
 		svcReq.Name = &req.Name
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attributionWatermarkMaxBodyBytes 参与改写的非流式响应体上限，超出时原样输出
+const attributionWatermarkMaxBodyBytes = 8 << 20
+
+// attributionWatermarkWriter 在成功响应的文本末尾追加分组归属水印。
+// 首次写入时按状态码与 Content-Type 决定处理方式：200 的 JSON 响应缓冲后整体改写，
+// 200 的 SSE 响应交给 AttributionStreamRewriter 逐事件改写；其余（含压缩过的响应体）原样直通。
+type attributionWatermarkWriter struct {
+	gin.ResponseWriter
+	format    string
+	watermark *service.AttributionWatermark
+
+	decided   bool
+	buffering bool
+	buf       bytes.Buffer
+	stream    *service.AttributionStreamRewriter
+}
+
+func (w *attributionWatermarkWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *attributionWatermarkWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	if w.Status() != http.StatusOK {
+		return
+	}
+	if encoding := w.Header().Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return
+	}
+	contentType := strings.ToLower(w.Header().Get("Content-Type"))
+	switch {
+	case strings.Contains(contentType, "text/event-stream"):
+		w.stream = service.NewAttributionStreamRewriter(w.format, w.watermark)
+	case strings.Contains(contentType, "json"):
+		w.buffering = true
+	}
+}
+
+func (w *attributionWatermarkWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if w.stream != nil {
+		if out := w.stream.Write(b); len(out) > 0 {
+			if _, err := w.ResponseWriter.Write(out); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	}
+	if !w.buffering {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) > attributionWatermarkMaxBodyBytes {
+		// 超出上限：放弃改写，先输出已缓冲内容再直通
+		w.buffering = false
+		if w.buf.Len() > 0 {
+			if _, err := w.ResponseWriter.Write(w.buf.Bytes()); err != nil {
+				return 0, err
+			}
+			w.buf.Reset()
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+func (w *attributionWatermarkWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *attributionWatermarkWriter) Flush() {
+	if w.buffering {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish 输出缓冲的响应体（追加水印后）或流末尾未成帧的残留字节。
+func (w *attributionWatermarkWriter) finish() {
+	if w.stream != nil {
+		if rest := w.stream.Close(); len(rest) > 0 {
+			_, _ = w.ResponseWriter.Write(rest)
+		}
+		return
+	}
+	if !w.buffering {
+		return
+	}
+	w.buffering = false
+	body := w.buf.Bytes()
+	if out, ok := service.ApplyAttributionWatermark(body, w.format, w.watermark); ok {
+		body = out
+	}
+	w.Header().Del("Content-Length")
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// AttributionWatermarkMiddleware 按分组的归属水印设置，在 messages、chat/completions、responses 的
+// 成功响应末尾追加可见页脚或零宽字符水印（responses 仅处理非流式）。需挂在 API Key 认证之后。
+func AttributionWatermarkMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || c.IsWebsocket() {
+			c.Next()
+			return
+		}
+		format := service.ResponseLanguageFormatForPath(c.Request.URL.Path)
+		if format == "" {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		requestID, _ := c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+		watermark := service.ResolveAttributionWatermark(apiKey, requestID, time.Now())
+		if watermark == nil {
+			c.Next()
+			return
+		}
+
+		writer := &attributionWatermarkWriter{ResponseWriter: c.Writer, format: format, watermark: watermark}
+		c.Writer = writer
+		c.Next()
+		writer.finish()
+		if c.Writer == writer {
+			c.Writer = writer.ResponseWriter
+		}
+	}
+}
@@ -0,0 +1,84 @@
+//go:build unit
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newAttributionWatermarkRouter(apiKey *service.APIKey, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware.ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.Use(AttributionWatermarkMiddleware())
+	r.POST("/v1/messages", handler)
+	r.POST("/v1/chat/completions", handler)
+	return r
+}
+
+func TestAttributionWatermarkMiddleware_NonStreaming(t *testing.T) {
+	apiKey := &service.APIKey{Group: &service.Group{ID: 1, Name: "g", AttributionMode: service.AttributionModeFooter, AttributionText: "via {group}"}}
+	r := newAttributionWatermarkRouter(apiKey, func(c *gin.Context) {
+		c.Header("Content-Length", "999")
+		c.Data(http.StatusOK, "application/json", []byte(`{"model":"claude","content":[{"type":"text","text":"hi"}]}`))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Content-Length"))
+	require.Equal(t, "hi\n\nvia g", gjson.Get(w.Body.String(), "content.0.text").String())
+}
+
+func TestAttributionWatermarkMiddleware_SkipsErrorsAndDisabledGroups(t *testing.T) {
+	errorBody := `{"type":"error","error":{"message":"bad"}}`
+	apiKey := &service.APIKey{Group: &service.Group{ID: 1, AttributionMode: service.AttributionModeFooter}}
+	r := newAttributionWatermarkRouter(apiKey, func(c *gin.Context) {
+		c.Data(http.StatusBadRequest, "application/json", []byte(errorBody))
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+	require.Equal(t, errorBody, w.Body.String())
+
+	okBody := `{"model":"claude","content":[{"type":"text","text":"hi"}]}`
+	r = newAttributionWatermarkRouter(&service.APIKey{Group: &service.Group{ID: 1}}, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(okBody))
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+	require.Equal(t, okBody, w.Body.String())
+}
+
+func TestAttributionWatermarkMiddleware_Streaming(t *testing.T) {
+	apiKey := &service.APIKey{Group: &service.Group{ID: 1, Name: "g", AttributionMode: service.AttributionModeFooter, AttributionText: "via {group}"}}
+	r := newAttributionWatermarkRouter(apiKey, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		for _, chunk := range []string{
+			"data: {\"id\":\"c\",\"model\":\"gpt\",\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n",
+			"\ndata: {\"id\":\"c\",\"model\":\"gpt\",\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n",
+			"data: [DONE]\n\n",
+		} {
+			_, _ = c.Writer.WriteString(chunk)
+			c.Writer.Flush()
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{}`)))
+	events := strings.Split(strings.TrimSuffix(w.Body.String(), "\n\n"), "\n\n")
+	require.Len(t, events, 4)
+	require.Equal(t, "\n\nvia g", gjson.Get(strings.TrimPrefix(events[1], "data: "), "choices.0.delta.content").String())
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AutoscalingMetricsHandler 以 Prometheus 文本格式导出 HPA/KEDA 伸缩信号
+type AutoscalingMetricsHandler struct {
+	signals *service.AutoscalingSignalsService
+	token   string
+}
+
+// NewAutoscalingMetricsHandler creates a new AutoscalingMetricsHandler
+func NewAutoscalingMetricsHandler(signals *service.AutoscalingSignalsService, cfg *config.Config) *AutoscalingMetricsHandler {
+	h := &AutoscalingMetricsHandler{signals: signals}
+	if cfg != nil {
+		h.token = strings.TrimSpace(cfg.AutoscalingMetrics.Token)
+	}
+	return h
+}
+
+// Metrics handles exporting autoscaling signals
+// GET /metrics/autoscaling
+func (h *AutoscalingMetricsHandler) Metrics(c *gin.Context) {
+	if h.token != "" {
+		token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			c.String(http.StatusUnauthorized, "unauthorized\n")
+			return
+		}
+	}
+
+	s := h.signals.Collect(c.Request.Context())
+	var b strings.Builder
+
+	if s.SlotStatsAvailable {
+		writeGaugeHeader(&b, "sub2api_slot_utilization_ratio", "Account concurrency slots in use divided by capacity, across all platforms.")
+		writeSample(&b, "sub2api_slot_utilization_ratio", "", s.SlotUtilization())
+		writeGaugeHeader(&b, "sub2api_platform_slot_utilization_ratio", "Account concurrency slots in use divided by capacity, per platform.")
+		for _, p := range s.SlotsByPlatform {
+			writeSample(&b, "sub2api_platform_slot_utilization_ratio", p.Platform, p.Utilization())
+		}
+		writeGaugeHeader(&b, "sub2api_platform_slots_in_use", "Account concurrency slots in use, per platform.")
+		for _, p := range s.SlotsByPlatform {
+			writeSample(&b, "sub2api_platform_slots_in_use", p.Platform, float64(p.InUse))
+		}
+		writeGaugeHeader(&b, "sub2api_platform_slots_capacity", "Account concurrency slot capacity, per platform.")
+		for _, p := range s.SlotsByPlatform {
+			writeSample(&b, "sub2api_platform_slots_capacity", p.Platform, float64(p.Capacity))
+		}
+	}
+
+	writeGaugeHeader(&b, "sub2api_slot_queue_wait_p95_seconds", "p95 time requests on this instance spent queued for a concurrency slot, over the configured window.")
+	writeSample(&b, "sub2api_slot_queue_wait_p95_seconds", "", s.QueueWaitP95.Seconds())
+	writeGaugeHeader(&b, "sub2api_slot_queue_wait_samples", "Number of queued slot waits on this instance within the window.")
+	writeSample(&b, "sub2api_slot_queue_wait_samples", "", float64(s.QueueWaitSamples))
+	writeGaugeHeader(&b, "sub2api_priority_slot_queue_wait_p95_seconds", "p95 time requests on this instance spent queued for a concurrency slot over the configured window, per X-Priority.")
+	for _, p := range s.QueueWaitByPriority {
+		writeLabeledSample(&b, "sub2api_priority_slot_queue_wait_p95_seconds", "priority", p.Priority, p.QueueWaitP95.Seconds())
+	}
+	writeGaugeHeader(&b, "sub2api_priority_slot_queue_wait_samples", "Number of queued slot waits on this instance within the window, per X-Priority.")
+	for _, p := range s.QueueWaitByPriority {
+		writeLabeledSample(&b, "sub2api_priority_slot_queue_wait_samples", "priority", p.Priority, float64(p.Samples))
+	}
+	writeCounterHeader(&b, "sub2api_priority_requests_total", "Gateway requests received by this instance, per effective X-Priority.")
+	for _, p := range s.QueueWaitByPriority {
+		writeLabeledSample(&b, "sub2api_priority_requests_total", "priority", p.Priority, float64(p.Requests))
+	}
+
+	writeGaugeHeader(&b, "sub2api_usage_worker_pool_saturation_ratio", "Running usage-record workers divided by the pool size on this instance.")
+	writeSample(&b, "sub2api_usage_worker_pool_saturation_ratio", "", s.WorkerPoolSaturation())
+	writeGaugeHeader(&b, "sub2api_usage_worker_pool_queued_tasks", "Usage-record tasks waiting for a worker on this instance.")
+	writeSample(&b, "sub2api_usage_worker_pool_queued_tasks", "", float64(s.WorkerPoolQueued))
+
+	writeGaugeHeader(&b, "sub2api_pending_requests", "In-flight gateway requests on this instance, per platform.")
+	for _, p := range s.PendingByPlatform {
+		writeSample(&b, "sub2api_pending_requests", p.Platform, float64(p.Pending))
+	}
+	writeGaugeHeader(&b, "sub2api_waiting_slot_requests", "In-flight gateway requests on this instance queued for a concurrency slot, per platform.")
+	for _, p := range s.PendingByPlatform {
+		writeSample(&b, "sub2api_waiting_slot_requests", p.Platform, float64(p.WaitingSlot))
+	}
+
+	if s.Admission.Enabled {
+		writeGaugeHeader(&b, "sub2api_admission_waiting_requests", "Requests on this instance admitted to wait for a concurrency slot.")
+		writeSample(&b, "sub2api_admission_waiting_requests", "", float64(s.Admission.WaitingTotal))
+		writeGaugeHeader(&b, "sub2api_admission_shed_probability", "Fraction of new slot waits currently shed because the queue wait p95 exceeds its target.")
+		writeSample(&b, "sub2api_admission_shed_probability", "", s.Admission.ShedProbability)
+		writeCounterHeader(&b, "sub2api_admission_shed_total", "Requests rejected with 503 before queueing for a concurrency slot on this instance, by reason.")
+		for _, reason := range []string{service.AdmissionShedReasonGlobal, service.AdmissionShedReasonGroup, service.AdmissionShedReasonAdaptive} {
+			writeLabeledSample(&b, "sub2api_admission_shed_total", "reason", reason, float64(s.Admission.Shed[reason]))
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+func writeGaugeHeader(b *strings.Builder, name, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+func writeCounterHeader(b *strings.Builder, name, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+}
+
+func writeSample(b *strings.Builder, name, platform string, value float64) {
+	writeLabeledSample(b, name, "platform", platform, value)
+}
+
+func writeLabeledSample(b *strings.Builder, name, label, labelValue string, value float64) {
+	b.WriteString(name)
+	if labelValue != "" {
+		b.WriteString(`{` + label + `=`)
+		b.WriteString(strconv.Quote(labelValue))
+		b.WriteString(`}`)
+	}
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteByte('\n')
+}
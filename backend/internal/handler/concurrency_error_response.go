@@ -5,11 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
 )
 
 const statusClientClosedRequest = 499
 
 func concurrencyErrorResponse(err error, slotType string) (int, string, string) {
+	var shedErr *service.AdmissionShedError
+	if errors.As(err, &shedErr) {
+		return http.StatusServiceUnavailable, "api_error", "Server is overloaded, please retry later"
+	}
+
 	var waitQueueFullErr *WaitQueueFullError
 	if errors.As(err, &waitQueueFullErr) {
 		return http.StatusTooManyRequests, "rate_limit_error",
@@ -31,3 +40,15 @@ func concurrencyErrorResponse(err error, slotType string) (int, string, string)
 
 	return http.StatusServiceUnavailable, "api_error", "Service temporarily unavailable, please retry later"
 }
+
+// setAdmissionShedRetryAfter 请求被准入控制卸载时写入 Retry-After，返回是否为卸载错误。
+func setAdmissionShedRetryAfter(c *gin.Context, err error) bool {
+	var shedErr *service.AdmissionShedError
+	if !errors.As(err, &shedErr) {
+		return false
+	}
+	if seconds := int(shedErr.RetryAfter.Seconds()); seconds > 0 {
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	return true
+}
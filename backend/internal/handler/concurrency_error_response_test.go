@@ -3,9 +3,14 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,6 +55,14 @@ func TestConcurrencyErrorResponse(t *testing.T) {
 			wantType:    "api_error",
 			wantMessage: "Service temporarily unavailable, please retry later",
 		},
+		{
+			name:        "admission shed is overloaded",
+			err:         fmt.Errorf("acquire: %w", &service.AdmissionShedError{Reason: service.AdmissionShedReasonGlobal}),
+			slotType:    "account",
+			wantStatus:  http.StatusServiceUnavailable,
+			wantType:    "api_error",
+			wantMessage: "Server is overloaded, please retry later",
+		},
 	}
 
 	for _, tt := range tests {
@@ -61,3 +74,15 @@ func TestConcurrencyErrorResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestSetAdmissionShedRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	require.False(t, setAdmissionShedRetryAfter(c, errors.New("other")))
+	require.Empty(t, w.Header().Get("Retry-After"))
+
+	require.True(t, setAdmissionShedRetryAfter(c, &service.AdmissionShedError{Reason: service.AdmissionShedReasonAdaptive, RetryAfter: 5 * time.Second}))
+	require.Equal(t, "5", w.Header().Get("Retry-After"))
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cappedCaptureWriter 在写给客户端的同时保留一份响应副本（超过上限后只计标记不再复制）。
+// 流式请求捕获的是原始 SSE 文本，非流式请求为完整 JSON。会话留存与请求镜像共用。
+type cappedCaptureWriter struct {
+	gin.ResponseWriter
+	limit     int
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (w *cappedCaptureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *cappedCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cappedCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cappedCaptureWriter) capture(b []byte) {
+	if w.truncated || len(b) == 0 {
+		return
+	}
+	remaining := w.limit - w.buf.Len()
+	if w.limit > 0 && len(b) > remaining {
+		_, _ = w.buf.Write(b[:remaining])
+		w.truncated = true
+		return
+	}
+	_, _ = w.buf.Write(b)
+}
+
+// beginConversationTranscriptCapture 替换 c.Writer 以捕获响应，返回的函数需在请求结束时调用：
+// 恢复原 writer 并将请求体与捕获的响应异步提交给留存服务。
+func beginConversationTranscriptCapture(c *gin.Context, svc *service.ConversationTranscriptService, apiKey *service.APIKey, model string, stream bool, body []byte) func() {
+	startedAt := time.Now()
+	requestPayload := string(body)
+	writer := &cappedCaptureWriter{ResponseWriter: c.Writer, limit: svc.MaxResponseBytes()}
+	c.Writer = writer
+
+	return func() {
+		c.Writer = writer.ResponseWriter
+		requestID, _ := c.Request.Context().Value(ctxkey.RequestID).(string)
+		svc.Record(&service.ConversationTranscript{
+			UserID:            apiKey.UserID,
+			APIKeyID:          apiKey.ID,
+			GroupID:           apiKey.GroupID,
+			RequestID:         strings.TrimSpace(requestID),
+			Endpoint:          c.FullPath(),
+			Model:             model,
+			Stream:            stream,
+			StatusCode:        writer.Status(),
+			DurationMs:        time.Since(startedAt).Milliseconds(),
+			RequestPayload:    requestPayload,
+			ResponsePayload:   writer.buf.String(),
+			ResponseTruncated: writer.truncated,
+		})
+	}
+}
@@ -80,33 +80,44 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		return nil
 	}
 	out := &APIKey{
-		ID:                 k.ID,
-		UserID:             k.UserID,
-		Key:                k.Key,
-		Name:               k.Name,
-		GroupID:            k.GroupID,
-		Status:             k.Status,
-		IPWhitelist:        k.IPWhitelist,
-		IPBlacklist:        k.IPBlacklist,
-		LastUsedAt:         k.LastUsedAt,
-		LastUsedIP:         k.LastUsedIP,
-		Quota:              k.Quota,
-		QuotaUsed:          k.QuotaUsed,
-		ExpiresAt:          k.ExpiresAt,
-		CreatedAt:          k.CreatedAt,
-		UpdatedAt:          k.UpdatedAt,
-		CurrentConcurrency: k.CurrentConcurrency,
-		RateLimit5h:        k.RateLimit5h,
-		RateLimit1d:        k.RateLimit1d,
-		RateLimit7d:        k.RateLimit7d,
-		Usage5h:            k.EffectiveUsage5h(),
-		Usage1d:            k.EffectiveUsage1d(),
-		Usage7d:            k.EffectiveUsage7d(),
-		Window5hStart:      k.Window5hStart,
-		Window1dStart:      k.Window1dStart,
-		Window7dStart:      k.Window7dStart,
-		User:               UserFromServiceShallow(k.User),
-		Group:              GroupFromServiceShallow(k.Group),
+		ID:                   k.ID,
+		UserID:               k.UserID,
+		Key:                  k.Key,
+		Name:                 k.Name,
+		GroupID:              k.GroupID,
+		Status:               k.Status,
+		IPWhitelist:          k.IPWhitelist,
+		IPBlacklist:          k.IPBlacklist,
+		LastUsedAt:           k.LastUsedAt,
+		LastUsedIP:           k.LastUsedIP,
+		Quota:                k.Quota,
+		QuotaUsed:            k.QuotaUsed,
+		ExpiresAt:            k.ExpiresAt,
+		CreatedAt:            k.CreatedAt,
+		UpdatedAt:            k.UpdatedAt,
+		CurrentConcurrency:   k.CurrentConcurrency,
+		RateLimit5h:          k.RateLimit5h,
+		RateLimit1d:          k.RateLimit1d,
+		RateLimit7d:          k.RateLimit7d,
+		Usage5h:              k.EffectiveUsage5h(),
+		Usage1d:              k.EffectiveUsage1d(),
+		Usage7d:              k.EffectiveUsage7d(),
+		Window5hStart:        k.Window5hStart,
+		Window1dStart:        k.Window1dStart,
+		Window7dStart:        k.Window7dStart,
+		User:                 UserFromServiceShallow(k.User),
+		Group:                GroupFromServiceShallow(k.Group),
+		TranscriptEnabled:    k.TranscriptEnabled,
+		AnnotationsEnabled:   k.AnnotationsEnabled,
+		StreamCaptureEnabled: k.StreamCaptureEnabled,
+		ResponseLanguage:     k.ResponseLanguage,
+		MaxPriority:          k.MaxPriority,
+		StrictValidation:     k.StrictValidation,
+		MaxRequestCost:       k.MaxRequestCost,
+		BetaFeatures:         k.BetaFeatures,
+		DataResidency:        k.DataResidency,
+		ParentKeyID:          k.ParentKeyID,
+		AllowedModels:        k.AllowedModels,
 	}
 	if k.Window5hStart != nil && !service.IsWindowExpired(k.Window5hStart, service.RateLimitWindow5h) {
 		t := k.Window5hStart.Add(service.RateLimitWindow5h)
@@ -207,6 +218,14 @@ func groupFromServiceBase(g *service.Group) Group {
 		RequireOAuthOnly:                g.RequireOAuthOnly,
 		RequirePrivacySet:               g.RequirePrivacySet,
 		RPMLimit:                        g.RPMLimit,
+		AllowComputerUseTool:            g.AllowComputerUseTool,
+		AllowCodeExecutionTool:          g.AllowCodeExecutionTool,
+		AllowWebSearchTool:              g.AllowWebSearchTool,
+		SystemPromptProfile:             g.SystemPromptProfile,
+		ResponseLanguage:                g.ResponseLanguage,
+		AttributionMode:                 g.AttributionMode,
+		AttributionText:                 g.AttributionText,
+		MaxRequestCost:                  g.MaxRequestCost,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
@@ -383,6 +402,18 @@ func AccountFromServiceShallow(a *service.Account) *Account {
 	return out
 }
 
+// AccountWarmupFromService 转换账号预热状态；nil 表示未处于预热期。
+func AccountWarmupFromService(w *service.AccountWarmupStatus) *AccountWarmup {
+	if w == nil {
+		return nil
+	}
+	return &AccountWarmup{
+		Share:     w.Share,
+		StartedAt: w.StartedAt,
+		EndsAt:    w.EndsAt,
+	}
+}
+
 func AccountFromService(a *service.Account) *Account {
 	if a == nil {
 		return nil
@@ -417,13 +448,20 @@ func AccountGroupFromService(ag *service.AccountGroup) *AccountGroup {
 	if ag == nil {
 		return nil
 	}
+	weight := ag.Weight
+	if weight <= 0 {
+		weight = service.DefaultAccountGroupWeight
+	}
 	return &AccountGroup{
-		AccountID: ag.AccountID,
-		GroupID:   ag.GroupID,
-		Priority:  ag.Priority,
-		CreatedAt: ag.CreatedAt,
-		Account:   AccountFromServiceShallow(ag.Account),
-		Group:     GroupFromServiceShallow(ag.Group),
+		AccountID:        ag.AccountID,
+		GroupID:          ag.GroupID,
+		Priority:         ag.Priority,
+		PriorityOverride: ag.PriorityOverride,
+		Weight:           weight,
+		ConcurrencyCap:   ag.ConcurrencyCap,
+		CreatedAt:        ag.CreatedAt,
+		Account:          AccountFromServiceShallow(ag.Account),
+		Group:            GroupFromServiceShallow(ag.Group),
 	}
 }
 
@@ -445,6 +483,7 @@ func ProxyFromService(p *service.Proxy) *Proxy {
 		FallbackMode:   p.FallbackMode,
 		BackupProxyID:  p.BackupProxyID,
 		ExpiryWarnDays: p.ExpiryWarnDays,
+		DataResidency:  p.DataResidency,
 	}
 }
 
@@ -608,6 +647,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		ServiceTier:               l.ServiceTier,
 		ReasoningEffort:           l.ReasoningEffort,
 		InboundEndpoint:           l.InboundEndpoint,
+		BetaFeatures:              l.BetaFeatures,
 		GroupID:                   l.GroupID,
 		SubscriptionID:            l.SubscriptionID,
 		InputTokens:               l.InputTokens,
@@ -616,8 +656,10 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		CacheReadTokens:           l.CacheReadTokens,
 		CacheCreation5mTokens:     l.CacheCreation5mTokens,
 		CacheCreation1hTokens:     l.CacheCreation1hTokens,
+		ReasoningTokens:           l.ReasoningTokens,
 		InputCost:                 l.InputCost,
 		OutputCost:                l.OutputCost,
+		ReasoningCost:             l.ReasoningCost,
 		CacheCreationCost:         l.CacheCreationCost,
 		CacheReadCost:             l.CacheReadCost,
 		TotalCost:                 l.TotalCost,
@@ -684,6 +726,16 @@ func UsageLogFromServiceAdmin(l *service.UsageLog) *AdminUsageLog {
 	}
 }
 
+func UsageRecostFromService(r service.UsageRecost) *UsageRecost {
+	return &UsageRecost{
+		Repriced:        r.Repriced,
+		SkipReason:      r.SkipReason,
+		TotalCost:       r.TotalCost,
+		ActualCost:      r.ActualCost,
+		ActualCostDelta: r.ActualCostDelta,
+	}
+}
+
 func UsageCleanupTaskFromService(task *service.UsageCleanupTask) *UsageCleanupTask {
 	if task == nil {
 		return nil
@@ -82,6 +82,23 @@ type APIKey struct {
 	Reset1dAt     *time.Time `json:"reset_1d_at,omitempty"`
 	Reset7dAt     *time.Time `json:"reset_7d_at,omitempty"`
 
+	TranscriptEnabled    bool    `json:"transcript_enabled"`
+	AnnotationsEnabled   bool    `json:"annotations_enabled"`
+	StreamCaptureEnabled bool    `json:"stream_capture_enabled"`
+	ResponseLanguage     string  `json:"response_language"`
+	MaxPriority          string  `json:"max_priority"`
+	StrictValidation     bool    `json:"strict_validation"`
+	MaxRequestCost       float64 `json:"max_request_cost"`
+
+	// BetaFeatures 可通过 X-Sub2API-Beta 按请求启用的实验性功能（未授权时省略）
+	BetaFeatures []string `json:"beta_features,omitempty"`
+	// DataResidency 要求的数据驻留区域（eu / us / asia），空表示不限制
+	DataResidency string `json:"data_residency"`
+
+	// ParentKeyID / AllowedModels 仅委托子 Key 返回
+	ParentKeyID   *int64   `json:"parent_key_id,omitempty"`
+	AllowedModels []string `json:"allowed_models,omitempty"`
+
 	User  *User  `json:"user,omitempty"`
 	Group *Group `json:"group,omitempty"`
 }
@@ -139,6 +156,24 @@ type Group struct {
 	// RPMLimit 分组级每分钟请求数上限（0 = 不限制），设置后覆盖用户级 rpm_limit。
 	RPMLimit int `json:"rpm_limit"`
 
+	// Anthropic beta 工具透传开关（默认关闭）
+	AllowComputerUseTool   bool `json:"allow_computer_use_tool"`
+	AllowCodeExecutionTool bool `json:"allow_code_execution_tool"`
+	AllowWebSearchTool     bool `json:"allow_web_search_tool"`
+
+	// SystemPromptProfile Claude OAuth system prompt 注入档位（空 = 沿用全局设置）
+	SystemPromptProfile string `json:"system_prompt_profile"`
+
+	// ResponseLanguage 强制回复语言（空 = 不强制）
+	ResponseLanguage string `json:"response_language"`
+
+	// AttributionMode 文本响应归属水印（footer / invisible，空 = 不添加）；AttributionText 为页脚模板
+	AttributionMode string `json:"attribution_mode"`
+	AttributionText string `json:"attribution_text"`
+
+	// MaxRequestCost 单请求预估费用上限（美元，0 = 不限制）
+	MaxRequestCost float64 `json:"max_request_cost"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -272,6 +307,9 @@ type Account struct {
 	QuotaNotifyTotalEnabled    *bool    `json:"quota_notify_total_enabled,omitempty"`
 	QuotaNotifyTotalThreshold  *float64 `json:"quota_notify_total_threshold,omitempty"`
 
+	// 流量预热状态（仅预热窗口内非空）
+	Warmup *AccountWarmup `json:"warmup,omitempty"`
+
 	// 影子账号关系（spark 维度影子）
 	ParentAccountID *int64 `json:"parent_account_id,omitempty"`
 	QuotaDimension  string `json:"quota_dimension,omitempty"`
@@ -290,11 +328,22 @@ type Account struct {
 	Groups   []*Group `json:"groups,omitempty"`
 }
 
+// AccountWarmup 新启用账号的流量预热状态
+type AccountWarmup struct {
+	// Share 当前可承接的非粘性流量份额（0-1]
+	Share     float64   `json:"share"`
+	StartedAt time.Time `json:"started_at"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
 type AccountGroup struct {
-	AccountID int64     `json:"account_id"`
-	GroupID   int64     `json:"group_id"`
-	Priority  int       `json:"priority"`
-	CreatedAt time.Time `json:"created_at"`
+	AccountID        int64     `json:"account_id"`
+	GroupID          int64     `json:"group_id"`
+	Priority         int       `json:"priority"`
+	PriorityOverride *int      `json:"priority_override"`
+	Weight           int       `json:"weight"`
+	ConcurrencyCap   *int      `json:"concurrency_cap"`
+	CreatedAt        time.Time `json:"created_at"`
 
 	Account *Account `json:"account,omitempty"`
 	Group   *Group   `json:"group,omitempty"`
@@ -316,6 +365,7 @@ type Proxy struct {
 	FallbackMode   string     `json:"fallback_mode"`
 	BackupProxyID  *int64     `json:"backup_proxy_id"`
 	ExpiryWarnDays int        `json:"expiry_warn_days"`
+	DataResidency  string     `json:"data_residency"`
 }
 
 type ProxyWithAccountCount struct {
@@ -470,6 +520,8 @@ type UsageLog struct {
 	InboundEndpoint *string `json:"inbound_endpoint,omitempty"`
 	// UpstreamEndpoint is the normalized upstream endpoint path, e.g. /v1/responses.
 	UpstreamEndpoint *string `json:"upstream_endpoint,omitempty"`
+	// BetaFeatures lists the experimental features enabled via X-Sub2API-Beta, comma separated.
+	BetaFeatures *string `json:"beta_features,omitempty"`
 
 	GroupID        *int64 `json:"group_id"`
 	SubscriptionID *int64 `json:"subscription_id"`
@@ -482,8 +534,12 @@ type UsageLog struct {
 	CacheCreation5mTokens int `json:"cache_creation_5m_tokens"`
 	CacheCreation1hTokens int `json:"cache_creation_1h_tokens"`
 
+	// ReasoningTokens 为 output_tokens 中的推理（thinking）部分，reasoning_cost 从 output_cost 中拆出
+	ReasoningTokens int `json:"reasoning_tokens"`
+
 	InputCost                 float64 `json:"input_cost"`
 	OutputCost                float64 `json:"output_cost"`
+	ReasoningCost             float64 `json:"reasoning_cost"`
 	CacheCreationCost         float64 `json:"cache_creation_cost"`
 	CacheReadCost             float64 `json:"cache_read_cost"`
 	TotalCost                 float64 `json:"total_cost"`
@@ -555,6 +611,18 @@ type AdminUsageLog struct {
 
 	// Account 最小账号信息（避免泄露敏感字段）
 	Account *AccountSummary `json:"account,omitempty"`
+
+	// Recost 按 pricing_snapshot 重算的费用（仅在请求携带该参数时返回）
+	Recost *UsageRecost `json:"recost,omitempty"`
+}
+
+// UsageRecost 单条用量在指定价格快照下的重算费用；未重算时 skip_reason 非空且费用沿用原值
+type UsageRecost struct {
+	Repriced        bool    `json:"repriced"`
+	SkipReason      string  `json:"skip_reason,omitempty"`
+	TotalCost       float64 `json:"total_cost"`
+	ActualCost      float64 `json:"actual_cost"`
+	ActualCostDelta float64 `json:"actual_cost_delta"`
 }
 
 type UsageCleanupFilters struct {
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorTranslationMaxBodyBytes 参与翻译的错误响应体上限，超出时原样输出
+const errorTranslationMaxBodyBytes = 64 << 10
+
+// errorTranslationWriter 缓冲上游错误的 JSON 响应体，待处理结束后统一改写再输出。
+// 仅在首次写入时状态码 >= 400、Content-Type 为 JSON 且本次请求记录过上游错误时进入缓冲模式。
+type errorTranslationWriter struct {
+	gin.ResponseWriter
+	ctx *gin.Context
+
+	decided   bool
+	buffering bool
+	buf       bytes.Buffer
+}
+
+func (w *errorTranslationWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *errorTranslationWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	if w.Status() < 400 {
+		return
+	}
+	if !strings.Contains(strings.ToLower(w.Header().Get("Content-Type")), "json") {
+		return
+	}
+	w.buffering = service.HasOpsUpstreamError(w.ctx)
+}
+
+func (w *errorTranslationWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if !w.buffering {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) > errorTranslationMaxBodyBytes {
+		// 超出上限：放弃改写，先输出已缓冲内容再直通
+		w.buffering = false
+		if w.buf.Len() > 0 {
+			if _, err := w.ResponseWriter.Write(w.buf.Bytes()); err != nil {
+				return 0, err
+			}
+			w.buf.Reset()
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+func (w *errorTranslationWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *errorTranslationWriter) Flush() {
+	if w.buffering {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish 翻译并输出缓冲的错误响应体；原始响应体保留到运维错误日志详情中。
+func (w *errorTranslationWriter) finish(translator *service.ErrorTranslator) {
+	if !w.buffering {
+		return
+	}
+	w.buffering = false
+	body := w.buf.Bytes()
+	locale := translator.ResolveLocale(w.ctx.GetHeader("Accept-Language"))
+	if out, _, ok := translator.Translate(w.Status(), body, locale); ok {
+		service.PreserveOpsUpstreamErrorDetail(w.ctx, string(body))
+		body = out
+	}
+	w.Header().Del("Content-Length")
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// ErrorTranslationMiddleware 将上游错误消息标准化并按 Accept-Language 本地化。
+// 需挂在 OpsErrorLoggerMiddleware 之后：错误日志记录客户端实际收到的内容，原始上游错误保存在 upstream_error_detail。
+func ErrorTranslationMiddleware(translator *service.ErrorTranslator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !translator.Enabled() {
+			c.Next()
+			return
+		}
+		writer := &errorTranslationWriter{ResponseWriter: c.Writer, ctx: c}
+		c.Writer = writer
+		c.Next()
+		writer.finish(translator)
+		if c.Writer == writer {
+			c.Writer = writer.ResponseWriter
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// newErrorTranslationTestRouter 构造挂载翻译中间件的路由；detail 在请求结束后接收 ops 上游错误详情。
+func newErrorTranslationTestRouter(handler gin.HandlerFunc, detail *any) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.ErrorTranslation = config.GatewayErrorTranslationConfig{Enabled: true, DefaultLocale: "en"}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		*detail, _ = c.Get(service.OpsUpstreamErrorDetailKey)
+	})
+	router.Use(ErrorTranslationMiddleware(service.NewErrorTranslator(cfg)))
+	router.POST("/v1/messages", handler)
+	return router
+}
+
+func TestErrorTranslationMiddleware_TranslatesUpstreamErrorByLocale(t *testing.T) {
+	original := `{"type":"error","error":{"type":"rate_limit_error","message":"Number of request tokens has exceeded your per-minute rate limit"}}`
+	var detail any
+	router := newErrorTranslationTestRouter(func(c *gin.Context) {
+		service.SetOpsUpstreamError(c, http.StatusTooManyRequests, "rate limited", "")
+		c.Header("Content-Length", "999")
+		c.Data(http.StatusTooManyRequests, "application/json", []byte(original))
+	}, &detail)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "上游请求频率超限，请稍后重试", gjson.Get(w.Body.String(), "error.message").String())
+	require.Equal(t, "rate_limit_error", gjson.Get(w.Body.String(), "error.type").String())
+	require.Empty(t, w.Header().Get("Content-Length"))
+	require.Equal(t, original, detail, "original body must be kept for admins")
+}
+
+func TestErrorTranslationMiddleware_KeepsExistingUpstreamDetail(t *testing.T) {
+	var detail any
+	router := newErrorTranslationTestRouter(func(c *gin.Context) {
+		service.SetOpsUpstreamError(c, http.StatusInternalServerError, "", "raw upstream body")
+		c.Data(http.StatusBadGateway, "application/json", []byte(`{"error":{"type":"server_error","message":"internal failure on node a1b2"}}`))
+	}, &detail)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	require.Equal(t, "Upstream service temporarily unavailable", gjson.Get(w.Body.String(), "error.message").String())
+	require.Equal(t, "raw upstream body", detail)
+}
+
+func TestErrorTranslationMiddleware_SkipsLocalErrors(t *testing.T) {
+	body := `{"error":{"type":"rate_limit_error","message":"Concurrency limit exceeded for user"}}`
+	var detail any
+	router := newErrorTranslationTestRouter(func(c *gin.Context) {
+		c.Data(http.StatusTooManyRequests, "application/json", []byte(body))
+	}, &detail)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, body, w.Body.String())
+	require.Nil(t, detail)
+}
@@ -49,8 +49,63 @@ type FailoverState struct {
 	FailedAccountIDs      map[int64]struct{}
 	SameAccountRetryCount map[int64]int
 	LastFailoverErr       *service.UpstreamFailoverError
-	ForceCacheBilling     bool
-	hasBoundSession       bool
+	// Attempts 每次账号尝试的失败摘要，切换耗尽时随错误响应返回并写入 ops 事件
+	Attempts          []service.FailoverAttempt
+	ForceCacheBilling bool
+	hasBoundSession   bool
+}
+
+// failoverStateContextKey 请求级 FailoverState 在 gin context 中的 key，供错误响应附带尝试摘要。
+const failoverStateContextKey = "failover_state"
+
+// newRequestFailoverState 创建 failover 状态并绑定到当前请求，
+// 使错误响应（errorResponse 等）可附带各账号尝试的失败摘要。
+func newRequestFailoverState(c *gin.Context, maxSwitches int, hasBoundSession bool) *FailoverState {
+	fs := NewFailoverState(maxSwitches, hasBoundSession)
+	c.Set(failoverStateContextKey, fs)
+	return fs
+}
+
+// failoverAttemptsForResponse 返回当前请求已记录的账号尝试失败摘要，并写入一次汇总 ops 事件。
+// 未发生账号尝试失败时返回 nil。
+func failoverAttemptsForResponse(c *gin.Context) []service.FailoverAttempt {
+	if c == nil {
+		return nil
+	}
+	v, ok := c.Get(failoverStateContextKey)
+	if !ok {
+		return nil
+	}
+	fs, ok := v.(*FailoverState)
+	if !ok || fs == nil || len(fs.Attempts) == 0 {
+		return nil
+	}
+	service.RecordFailoverAttemptsOpsEvent(c, fs.Attempts)
+	return fs.Attempts
+}
+
+// recordFailoverAttempt 为未使用 FailoverState 循环的处理器（OpenAI 系列）记录一次账号尝试失败。
+func recordFailoverAttempt(c *gin.Context, accountID int64, platform string, failoverErr *service.UpstreamFailoverError) {
+	if c == nil {
+		return
+	}
+	var fs *FailoverState
+	if v, ok := c.Get(failoverStateContextKey); ok {
+		fs, _ = v.(*FailoverState)
+	}
+	if fs == nil {
+		fs = &FailoverState{}
+		c.Set(failoverStateContextKey, fs)
+	}
+	fs.Attempts = append(fs.Attempts, service.NewFailoverAttempt(accountID, platform, failoverErr))
+}
+
+// withFailoverAttempts 在错误响应体中附加 failover_attempts 字段（存在失败尝试时）。
+func withFailoverAttempts(c *gin.Context, body gin.H) gin.H {
+	if attempts := failoverAttemptsForResponse(c); len(attempts) > 0 {
+		body["failover_attempts"] = attempts
+	}
+	return body
 }
 
 // NewFailoverState 创建 failover 状态
@@ -63,6 +118,11 @@ func NewFailoverState(maxSwitches int, hasBoundSession bool) *FailoverState {
 	}
 }
 
+// priorityAccountSwitches 按请求优先级（X-Priority）调整账号切换预算，见 service.RequestPriorityRetryBudget。
+func priorityAccountSwitches(c *gin.Context, base int) int {
+	return service.RequestPriorityRetryBudget(base, service.ActiveRequestFromContext(c.Request.Context()).Priority())
+}
+
 // HandleFailoverError 处理 UpstreamFailoverError，返回下一步动作。
 // 包含：缓存计费判断、同账号重试、临时封禁、切换计数、Antigravity 延时。
 func (s *FailoverState) HandleFailoverError(
@@ -79,6 +139,7 @@ func (s *FailoverState) HandleFailoverError(
 		return FailoverCanceled
 	}
 	s.LastFailoverErr = failoverErr
+	s.Attempts = append(s.Attempts, service.NewFailoverAttempt(accountID, platform, failoverErr))
 	if failoverErr == nil || !failoverErr.ShouldRetryNextAccount() {
 		return FailoverExhausted
 	}
@@ -171,6 +232,7 @@ func (s *FailoverState) HandleSelectionExhausted(ctx context.Context) FailoverAc
 		s.FailedAccountIDs = make(map[int64]struct{})
 		return FailoverContinue
 	}
+	s.Attempts = append(s.Attempts, service.FailoverAttempt{ErrorClass: service.FailoverErrorClassNoEligibleAccount})
 	return FailoverExhausted
 }
 
@@ -656,6 +656,52 @@ func TestHandleFailoverError_LastFailoverErr(t *testing.T) {
 	})
 }
 
+// ---------------------------------------------------------------------------
+// Attempts — 各账号尝试失败摘要
+// ---------------------------------------------------------------------------
+
+func TestHandleFailoverError_RecordsAttempts(t *testing.T) {
+	mock := &mockTempUnscheduler{}
+	fs := NewFailoverState(1, false)
+
+	fs.HandleFailoverError(context.Background(), mock, 100, "anthropic", maxSameAccountRetries, newTestFailoverErr(429, false, false))
+	action := fs.HandleFailoverError(context.Background(), mock, 200, "anthropic", maxSameAccountRetries, newTestFailoverErr(529, false, false))
+	require.Equal(t, FailoverExhausted, action)
+	require.Equal(t, FailoverExhausted, fs.HandleSelectionExhausted(context.Background()))
+
+	require.Equal(t, []service.FailoverAttempt{
+		{AccountID: 100, Platform: "anthropic", ErrorClass: service.FailoverErrorClassRateLimited, UpstreamStatus: 429},
+		{AccountID: 200, Platform: "anthropic", ErrorClass: service.FailoverErrorClassOverloaded, UpstreamStatus: 529},
+		{ErrorClass: service.FailoverErrorClassNoEligibleAccount},
+	}, fs.Attempts)
+}
+
+func TestWithFailoverAttempts(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	body := withFailoverAttempts(c, gin.H{"error": "x"})
+	require.NotContains(t, body, "failover_attempts", "未发生失败尝试时不附加")
+
+	fs := newRequestFailoverState(c, 3, false)
+	fs.HandleFailoverError(context.Background(), &mockTempUnscheduler{}, 100, "openai", maxSameAccountRetries, newTestFailoverErr(502, false, false))
+	body = withFailoverAttempts(c, gin.H{"error": "x"})
+	require.Equal(t, fs.Attempts, body["failover_attempts"])
+
+	// 汇总 ops 事件只写一次，且判断最后一次上游错误时被跳过
+	withFailoverAttempts(c, gin.H{})
+	raw, ok := c.Get(service.OpsUpstreamErrorsKey)
+	require.True(t, ok)
+	events := raw.([]*service.OpsUpstreamErrorEvent)
+	require.Len(t, events, 1)
+	require.Equal(t, service.OpsUpstreamKindFailoverExhausted, events[0].Kind)
+	require.Nil(t, service.LastOpsUpstreamAttemptEvent(events))
+
+	recordFailoverAttempt(c, 300, "openai", nil)
+	require.Len(t, fs.Attempts, 2)
+	require.Equal(t, service.FailoverErrorClassUpstreamError, fs.Attempts[1].ErrorClass)
+}
+
 // ---------------------------------------------------------------------------
 // HandleFailoverError — 综合集成场景
 // ---------------------------------------------------------------------------
@@ -51,6 +51,7 @@ type GatewayHandler struct {
 	errorPassthroughService   *service.ErrorPassthroughService
 	contentModerationService  *service.ContentModerationService
 	securityAuditCoordinator  *securityaudit.Coordinator
+	transcriptService         *service.ConversationTranscriptService
 	concurrencyHelper         *ConcurrencyHelper
 	userMsgQueueHelper        *UserMsgQueueHelper
 	maxAccountSwitches        int
@@ -160,7 +161,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	setOpsRequestContext(c, "", false)
 
 	bodyRef := service.NewRequestBodyRef(body)
-	parsedReq, err := service.ParseGatewayRequest(bodyRef, domain.PlatformAnthropic)
+	parsedReq, err := h.gatewayService.ParseGatewayRequest(bodyRef, domain.PlatformAnthropic)
 	if err != nil {
 		logRequestBodyParseFailure(reqLog, body, err)
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
@@ -207,6 +208,46 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	// 按模型注入缺省 max_tokens / 钳制超限值（默认关闭），通过响应头告知客户端
+	if adjustment, err := h.gatewayService.ApplyMaxTokensPolicy(parsedReq); err != nil {
+		reqLog.Warn("gateway.max_tokens_policy_failed", zap.Error(err))
+	} else if adjustment != nil {
+		body = parsedReq.Body.Bytes()
+		if adjustment.Injected {
+			c.Header(service.MaxTokensInjectedHeader, strconv.Itoa(adjustment.Applied))
+		}
+		if adjustment.Clamped {
+			c.Header(service.MaxTokensClampedHeader, fmt.Sprintf("%d->%d", adjustment.Original, adjustment.Applied))
+		}
+		reqLog.Info("gateway.max_tokens_adjusted",
+			zap.Bool("injected", adjustment.Injected),
+			zap.Bool("clamped", adjustment.Clamped),
+			zap.Int("original", adjustment.Original),
+			zap.Int("applied", adjustment.Applied),
+			zap.Int("maximum", adjustment.Maximum),
+		)
+	}
+
+	// 超长对话服务端截断（默认关闭）：丢弃最早的消息，并通过响应头告知客户端
+	if truncation, err := h.gatewayService.TruncateConversationIfNeeded(parsedReq); err != nil {
+		reqLog.Warn("gateway.conversation_truncation_failed", zap.Error(err))
+	} else if truncation != nil {
+		body = parsedReq.Body.Bytes()
+		c.Header(service.ConversationTruncatedMessagesHeader, strconv.Itoa(truncation.DroppedMessages))
+		reqLog.Info("gateway.conversation_truncated",
+			zap.Int("dropped_messages", truncation.DroppedMessages),
+			zap.Int("estimated_tokens_before", truncation.TokensBefore),
+			zap.Int("estimated_tokens_after", truncation.TokensAfter),
+			zap.Int("limit", truncation.Limit),
+		)
+	}
+
+	// 会话全文留存（按 Key 开启）：捕获返回给客户端的最终响应，请求结束后异步加密落库
+	if h.transcriptService.ShouldCapture(apiKey) {
+		finishTranscript := beginConversationTranscriptCapture(c, h.transcriptService, apiKey, reqModel, reqStream, body)
+		defer finishTranscript()
+	}
+
 	// Track if we've started streaming (for error handling)
 	streamStarted := false
 
@@ -252,11 +293,17 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		APIKeyID:  apiKey.ID,
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
+	agentSession := h.gatewayService.IsAgentSessionRequest(parsedReq)
+	if agentSession {
+		// Agent 会话使用更长的粘性亲和 TTL
+		c.Request = c.Request.WithContext(service.WithAgentSession(c.Request.Context(), true))
+	}
 
 	// [DEBUG-STICKY] 打印会话 hash 生成结果
 	reqLog.Info("sticky.session_hash_generated",
 		zap.String("session_hash", sessionHash),
 		zap.String("metadata_user_id_raw", parsedReq.MetadataUserID),
+		zap.Bool("agent_session", agentSession),
 	)
 
 	// 获取平台：优先使用强制平台（/antigravity 路由，中间件已设置 request.Context），否则使用分组平台
@@ -295,7 +342,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	hasBoundSession := sessionKey != "" && sessionBoundAccountID > 0
 
 	if platform == service.PlatformGemini {
-		fs := NewFailoverState(h.maxAccountSwitchesGemini, hasBoundSession)
+		fs := newRequestFailoverState(c, priorityAccountSwitches(c, h.maxAccountSwitchesGemini), hasBoundSession)
 
 		// 单账号分组提前设置 SingleAccountRetry 标记，让 Service 层首次 503 就不设模型限流标记。
 		// 避免单账号分组收到 503 (MODEL_CAPACITY_EXHAUSTED) 时设 29s 限流，导致后续请求连续快速失败。
@@ -575,7 +622,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	}
 
 	for {
-		fs := NewFailoverState(h.maxAccountSwitches, hasBoundSession)
+		fs := newRequestFailoverState(c, priorityAccountSwitches(c, h.maxAccountSwitches), hasBoundSession)
 		retryWithFallback := false
 
 		for {
@@ -998,7 +1045,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 // Models handles listing available models
 // GET /v1/models
 // Returns models based on account configurations (model_mapping whitelist)
-// Falls back to default models if no whitelist is configured
+// Falls back to default models if no whitelist is configured.
+// The list is narrowed to what the calling key may actually request
+// (sub-key allowlist, group image generation permission).
 func (h *GatewayHandler) Models(c *gin.Context) {
 	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
 
@@ -1018,60 +1067,123 @@ func (h *GatewayHandler) Models(c *gin.Context) {
 	if apiKey != nil && apiKey.Group != nil && apiKey.Group.CustomModelsListEnabled() {
 		fallbackModels := defaultModelIDsForPlatform(platform)
 		availableModels = filterModelsByCustomList(customModelsListSource(platform, availableModels, fallbackModels), fallbackModels, apiKey.Group.ModelsListConfig.Models)
-		writeCustomModelsList(c, platform, availableModels)
+		writeCustomModelsList(c, platform, filterEntitledModelIDs(apiKey, platform, availableModels))
 		return
 	}
 
+	// 账号白名单已确定可用范围时，即使过滤后为空也不回退到默认列表，避免暴露不可用模型
 	if len(availableModels) > 0 {
-		writeModelsList(c, platform, availableModels)
+		writeModelsList(c, platform, filterEntitledModelIDs(apiKey, platform, availableModels))
 		return
 	}
 
 	// Fallback to default models
 	if platform == service.PlatformOpenAI {
+		models := make([]openai.Model, 0, len(openai.DefaultModels))
+		for _, model := range openai.DefaultModels {
+			if modelEntitledForKey(apiKey, platform, model.ID) {
+				models = append(models, model)
+			}
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"object": "list",
-			"data":   openai.DefaultModels,
+			"data":   models,
 		})
 		return
 	}
 
 	if platform == service.PlatformGemini {
+		models := make([]geminicli.Model, 0, len(geminicli.DefaultModels))
+		for _, model := range geminicli.DefaultModels {
+			if modelEntitledForKey(apiKey, platform, model.ID) {
+				models = append(models, model)
+			}
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"object": "list",
-			"data":   geminicli.DefaultModels,
+			"data":   models,
 		})
 		return
 	}
 	if platform == service.PlatformGrok {
-		writeGrokModelsList(c, xai.DefaultModelIDs())
+		writeGrokModelsList(c, filterEntitledModelIDs(apiKey, platform, xai.DefaultModelIDs()))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"object": "list",
-		"data":   claude.DefaultModels,
-	})
+	writeModelsList(c, platform, filterEntitledModelIDs(apiKey, platform, claude.DefaultModelIDs()))
+}
+
+// modelEntitledForKey 判断调用方 Key 能否请求该模型：子 Key 允许列表 + 分组生图权限。
+// 分组的账号白名单 / 自定义模型列表由调用方在此之前处理。
+func modelEntitledForKey(apiKey *service.APIKey, platform, modelID string) bool {
+	if apiKey == nil {
+		return true
+	}
+	if !apiKey.IsModelAllowed(modelID) {
+		return false
+	}
+	if !service.GroupAllowsImageGeneration(apiKey.Group) &&
+		service.IsImageGenerationIntentForPlatform("", modelID, nil, platform) {
+		return false
+	}
+	return true
 }
 
+func filterEntitledModelIDs(apiKey *service.APIKey, platform string, modelIDs []string) []string {
+	filtered := make([]string, 0, len(modelIDs))
+	for _, modelID := range modelIDs {
+		if modelEntitledForKey(apiKey, platform, modelID) {
+			filtered = append(filtered, modelID)
+		}
+	}
+	return filtered
+}
+
+// writeModelsList 输出 Anthropic 格式的模型列表；已知模型使用内置的显示名与发布时间。
 func writeModelsList(c *gin.Context, platform string, modelIDs []string) {
 	if platform == service.PlatformGrok {
 		writeGrokModelsList(c, modelIDs)
 		return
 	}
+	known := knownClaudeFormatModels()
 	models := make([]claude.Model, 0, len(modelIDs))
 	for _, modelID := range modelIDs {
-		models = append(models, claude.Model{
-			ID:          modelID,
-			Type:        "model",
-			DisplayName: modelID,
-			CreatedAt:   "2024-01-01T00:00:00Z",
-		})
+		model, ok := known[modelID]
+		if !ok {
+			model = claude.Model{
+				ID:          modelID,
+				Type:        "model",
+				DisplayName: modelID,
+				CreatedAt:   "2024-01-01T00:00:00Z",
+			}
+		}
+		models = append(models, model)
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"object": "list",
-		"data":   models,
-	})
+	resp := gin.H{
+		"object":   "list",
+		"data":     models,
+		"has_more": false,
+		"first_id": nil,
+		"last_id":  nil,
+	}
+	if len(models) > 0 {
+		resp["first_id"] = models[0].ID
+		resp["last_id"] = models[len(models)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// knownClaudeFormatModels 汇总内置的 Claude 格式模型元数据（Claude 默认列表优先于 Antigravity 列表）。
+func knownClaudeFormatModels() map[string]claude.Model {
+	antigravityModels := antigravity.DefaultModels()
+	known := make(map[string]claude.Model, len(claude.DefaultModels)+len(antigravityModels))
+	for _, model := range antigravityModels {
+		known[model.ID] = claude.Model{ID: model.ID, Type: "model", DisplayName: model.DisplayName, CreatedAt: model.CreatedAt}
+	}
+	for _, model := range claude.DefaultModels {
+		known[model.ID] = model
+	}
+	return known
 }
 
 func writeCustomModelsList(c *gin.Context, platform string, modelIDs []string) {
@@ -1634,6 +1746,9 @@ func (h *GatewayHandler) calculateSubscriptionRemaining(group *service.Group, su
 // handleConcurrencyError handles concurrency-related acquire errors.
 func (h *GatewayHandler) handleConcurrencyError(c *gin.Context, err error, slotType string, streamStarted bool) {
 	status, errType, message := concurrencyErrorResponse(err, slotType)
+	if !streamStarted {
+		setAdmissionShedRetryAfter(c, err)
+	}
 	h.handleStreamingAwareError(c, status, errType, message, streamStarted)
 }
 
@@ -1825,13 +1940,13 @@ func (h *GatewayHandler) checkClaudeCodeVersion(c *gin.Context) bool {
 
 // errorResponse 返回Claude API格式的错误响应
 func (h *GatewayHandler) errorResponse(c *gin.Context, status int, errType, message string) {
-	c.JSON(status, gin.H{
+	c.JSON(status, withFailoverAttempts(c, gin.H{
 		"type": "error",
 		"error": gin.H{
 			"type":    errType,
 			"message": message,
 		},
-	})
+	}))
 }
 
 // CountTokens handles token counting endpoint
@@ -1877,7 +1992,7 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 	setOpsRequestContext(c, "", false)
 
 	bodyRef := service.NewRequestBodyRef(body)
-	parsedReq, err := service.ParseGatewayRequest(bodyRef, domain.PlatformAnthropic)
+	parsedReq, err := h.gatewayService.ParseGatewayRequest(bodyRef, domain.PlatformAnthropic)
 	if err != nil {
 		logRequestBodyParseFailure(reqLog, body, err)
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
@@ -1897,6 +2012,7 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 	}
 
 	setOpsRequestContext(c, parsedReq.Model, parsedReq.Stream)
+	setUpstreamUtilityRequestContext(c)
 	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(parsedReq.Stream, false)))
 
 	// 获取订阅信息（可能为nil）
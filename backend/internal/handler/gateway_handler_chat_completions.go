@@ -104,6 +104,12 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	// 会话全文留存（按 Key 开启）：捕获返回给客户端的最终响应，请求结束后异步加密落库
+	if h.transcriptService.ShouldCapture(apiKey) {
+		finishTranscript := beginConversationTranscriptCapture(c, h.transcriptService, apiKey, reqModel, reqStream, body)
+		defer finishTranscript()
+	}
+
 	// Error passthrough binding
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
@@ -137,7 +143,7 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 
 	// Parse request for session hash
 	bodyRef := service.NewRequestBodyRef(body)
-	parsedReq, _ := service.ParseGatewayRequest(bodyRef, "chat_completions")
+	parsedReq, _ := h.gatewayService.ParseGatewayRequest(bodyRef, "chat_completions")
 	if parsedReq == nil {
 		parsedReq = &service.ParsedRequest{Model: reqModel, Stream: reqStream, Body: bodyRef}
 	}
@@ -157,9 +163,9 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 	}
 
 	// 3. Account selection + failover loop
-	fs := NewFailoverState(h.maxAccountSwitches, false)
+	fs := newRequestFailoverState(c, priorityAccountSwitches(c, h.maxAccountSwitches), false)
 	if groupPlatform == service.PlatformGemini {
-		fs = NewFailoverState(h.maxAccountSwitchesGemini, false)
+		fs = newRequestFailoverState(c, priorityAccountSwitches(c, h.maxAccountSwitchesGemini), false)
 	}
 
 	for {
@@ -324,12 +330,12 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 
 // chatCompletionsErrorResponse writes an error in OpenAI Chat Completions format.
 func (h *GatewayHandler) chatCompletionsErrorResponse(c *gin.Context, status int, errType, message string) {
-	c.JSON(status, gin.H{
+	c.JSON(status, withFailoverAttempts(c, gin.H{
 		"error": gin.H{
 			"type":    errType,
 			"message": message,
 		},
-	})
+	}))
 }
 
 // handleCCFailoverExhausted writes a failover-exhausted error in CC format.
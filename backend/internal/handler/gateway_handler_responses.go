@@ -109,6 +109,12 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 
+	// 会话全文留存（按 Key 开启）：捕获返回给客户端的最终响应，请求结束后异步加密落库
+	if h.transcriptService.ShouldCapture(apiKey) {
+		finishTranscript := beginConversationTranscriptCapture(c, h.transcriptService, apiKey, reqModel, reqStream, body)
+		defer finishTranscript()
+	}
+
 	// Error passthrough binding
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
@@ -142,7 +148,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 	// Parse request for session hash
 	bodyRef := service.NewRequestBodyRef(body)
-	parsedReq, _ := service.ParseGatewayRequest(bodyRef, "responses")
+	parsedReq, _ := h.gatewayService.ParseGatewayRequest(bodyRef, "responses")
 	if parsedReq == nil {
 		parsedReq = &service.ParsedRequest{Model: reqModel, Stream: reqStream, Body: bodyRef}
 	}
@@ -154,7 +160,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 
 	// 3. Account selection + failover loop
-	fs := NewFailoverState(h.maxAccountSwitches, false)
+	fs := newRequestFailoverState(c, priorityAccountSwitches(c, h.maxAccountSwitches), false)
 
 	for {
 		if requestCtx.Err() != nil {
@@ -299,12 +305,12 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 // responsesErrorResponse writes an error in OpenAI Responses API format.
 func (h *GatewayHandler) responsesErrorResponse(c *gin.Context, status int, code, message string) {
-	c.JSON(status, gin.H{
+	c.JSON(status, withFailoverAttempts(c, gin.H{
 		"error": gin.H{
 			"code":    code,
 			"message": message,
 		},
-	})
+	}))
 }
 
 // handleResponsesFailoverExhausted writes a failover-exhausted error in Responses format.
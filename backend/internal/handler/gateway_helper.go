@@ -101,6 +101,10 @@ const (
 	backoffMultiplier = 1.5
 	// maxBackoff 最大退避时间
 	maxBackoff = 2 * time.Second
+	// highPriorityMinBackoff high 优先级请求的最小轮询间隔
+	highPriorityMinBackoff = 50 * time.Millisecond
+	// lowPriorityMaxBackoff low 优先级请求的最大轮询间隔
+	lowPriorityMaxBackoff = 4 * time.Second
 )
 
 // SSEPingFormat defines the format of SSE ping events for different platforms
@@ -331,6 +335,7 @@ func (h *ConcurrencyHelper) waitForSlotWithPing(c *gin.Context, slotType string,
 }
 
 // waitForSlotWithPingTimeout waits for a concurrency slot with a custom timeout.
+// 等待期间的 ping 携带排队位置与预估等待时间，拿到槽位后在响应头中返回排队位置与等待耗时。
 func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType string, id int64, maxConcurrency int, timeout time.Duration, isStream bool, streamStarted *bool, tryImmediate bool) (func(), error) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 	defer cancel()
@@ -352,6 +357,14 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 		}
 	}
 
+	// 进入排队前经过准入控制：过载时直接卸载，避免等待队列无限增长
+	admitted, err := service.ActiveRequestFromContext(ctx).AdmitWait()
+	if err != nil {
+		return nil, err
+	}
+	defer admitted()
+	defer service.ActiveRequestFromContext(ctx).BeginWait()()
+
 	// Determine if ping is needed (streaming + ping format defined)
 	needPing := isStream && h.pingFormat != ""
 
@@ -372,8 +385,12 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 		pingCh = pingTicker.C
 	}
 
+	// 排队位置：入队时取一次等待数，之后随 ping 刷新
+	queue := newSlotQueueStatus(slotType, h.queueWaitingCount(ctx, slotType, id), time.Now())
+
+	priority := service.ActiveRequestFromContext(ctx).Priority()
 	backoff := initialBackoff
-	timer := time.NewTimer(backoff)
+	timer := time.NewTimer(priorityBackoff(backoff, priority))
 	defer timer.Stop()
 
 	for {
@@ -396,7 +413,8 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 				c.Header("X-Accel-Buffering", "no")
 				*streamStarted = true
 			}
-			if _, err := fmt.Fprint(c.Writer, string(h.pingFormat)); err != nil {
+			queue.observe(h.queueWaitingCount(ctx, slotType, id))
+			if _, err := fmt.Fprint(c.Writer, queue.pingEvent(h.pingFormat, time.Now())); err != nil {
 				return nil, err
 			}
 			flusher.Flush()
@@ -409,14 +427,27 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 			}
 
 			if result.Acquired {
+				setQueueFeedbackHeaders(c, queue, time.Now())
 				return result.ReleaseFunc, nil
 			}
 			backoff = nextBackoff(backoff)
-			timer.Reset(backoff)
+			timer.Reset(priorityBackoff(backoff, priority))
 		}
 	}
 }
 
+// priorityBackoff 按请求优先级调整槽位轮询间隔：high 轮询更频繁，槽位释放时更容易抢到；
+// low 轮询更慢，把释放的槽位让给其它请求。
+func priorityBackoff(backoff time.Duration, priority string) time.Duration {
+	switch priority {
+	case service.RequestPriorityHigh:
+		return max(backoff/2, highPriorityMinBackoff)
+	case service.RequestPriorityLow:
+		return min(backoff*2, lowPriorityMaxBackoff)
+	}
+	return backoff
+}
+
 // AcquireAccountSlotWithWaitTimeout acquires an account slot with a custom timeout (keeps SSE ping).
 func (h *ConcurrencyHelper) AcquireAccountSlotWithWaitTimeout(c *gin.Context, accountID int64, maxConcurrency int, timeout time.Duration, isStream bool, streamStarted *bool) (func(), error) {
 	return h.waitForSlotWithPingTimeout(c, "account", accountID, maxConcurrency, timeout, isStream, streamStarted, true)
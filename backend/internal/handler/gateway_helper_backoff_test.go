@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -104,3 +105,11 @@ func BenchmarkNextBackoff(b *testing.B) {
 		}
 	}
 }
+
+func TestPriorityBackoff(t *testing.T) {
+	require.Equal(t, 400*time.Millisecond, priorityBackoff(400*time.Millisecond, service.RequestPriorityNormal))
+	require.Equal(t, 200*time.Millisecond, priorityBackoff(400*time.Millisecond, service.RequestPriorityHigh))
+	require.Equal(t, highPriorityMinBackoff, priorityBackoff(initialBackoff, service.RequestPriorityHigh))
+	require.Equal(t, 800*time.Millisecond, priorityBackoff(400*time.Millisecond, service.RequestPriorityLow))
+	require.Equal(t, lowPriorityMaxBackoff, priorityBackoff(maxBackoff*3, service.RequestPriorityLow))
+}
@@ -588,6 +588,109 @@ func TestGatewayModels_OpenAICustomModelsListKeepsOpenAIResponseShapeForDefaultF
 	require.Empty(t, got.Data[0].CreatedAt)
 }
 
+func TestGatewayModels_AnthropicDefaultsNarrowedBySubKeyAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	groupID := int64(31)
+	h := newGatewayModelsHandlerForTest(&gatewayModelsAccountRepoStub{})
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{
+		AllowedModels: []string{"claude-sonnet-*"},
+		Group:         &service.Group{ID: groupID, Platform: service.PlatformAnthropic},
+	})
+
+	h.Models(c)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got struct {
+		Data []struct {
+			ID          string `json:"id"`
+			DisplayName string `json:"display_name"`
+			CreatedAt   string `json:"created_at"`
+		} `json:"data"`
+		HasMore bool   `json:"has_more"`
+		FirstID string `json:"first_id"`
+		LastID  string `json:"last_id"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.NotEmpty(t, got.Data)
+	for _, model := range got.Data {
+		require.Contains(t, model.ID, "claude-sonnet-")
+		require.NotEqual(t, model.ID, model.DisplayName, "known models should carry their display name")
+		require.NotEqual(t, "2024-01-01T00:00:00Z", model.CreatedAt)
+	}
+	require.False(t, got.HasMore)
+	require.Equal(t, got.Data[0].ID, got.FirstID)
+	require.Equal(t, got.Data[len(got.Data)-1].ID, got.LastID)
+}
+
+func TestGatewayModels_MappedModelsFilteredToEmptyDoNotFallBackToDefaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	groupID := int64(32)
+	h := newGatewayModelsHandlerForTest(
+		&gatewayModelsAccountRepoStub{
+			byGroup: map[int64][]service.Account{
+				groupID: {
+					{
+						ID:       1,
+						Platform: service.PlatformAnthropic,
+						Type:     service.AccountTypeAPIKey,
+						Credentials: map[string]any{
+							"model_mapping": map[string]any{"deepseek-v4-pro": "deepseek-v4-pro"},
+						},
+					},
+				},
+			},
+		},
+	)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{
+		AllowedModels: []string{"claude-opus-4-8"},
+		Group:         &service.Group{ID: groupID, Platform: service.PlatformAnthropic},
+	})
+
+	h.Models(c)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got gatewayModelsResponseForTest
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Empty(t, got.Data)
+}
+
+func TestGatewayModels_OpenAIDefaultsHideImageModelsWithoutImagePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, allowImages := range []bool{false, true} {
+		h := newGatewayModelsHandlerForTest(&gatewayModelsAccountRepoStub{})
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{
+			Group: &service.Group{ID: 33, Platform: service.PlatformOpenAI, AllowImageGeneration: allowImages},
+		})
+
+		h.Models(c)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var got gatewayModelsResponseForTest
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		ids := modelIDsForTest(got.Data)
+		require.Contains(t, ids, "gpt-5.5")
+		if allowImages {
+			require.Contains(t, ids, "gpt-image-2")
+		} else {
+			require.NotContains(t, ids, "gpt-image-2")
+		}
+	}
+}
+
 func modelIDsForTest(models []gatewayModelItemForTest) []string {
 	ids := make([]string, 0, len(models))
 	for _, model := range models {
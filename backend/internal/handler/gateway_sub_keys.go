@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type createSubKeyRequest struct {
+	Name          string     `json:"name" binding:"required,max=100"`
+	Quota         float64    `json:"quota"`
+	AllowedModels []string   `json:"allowed_models"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+}
+
+type subKeyResponse struct {
+	Object        string     `json:"object"`
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Key           string     `json:"key,omitempty"`
+	Status        string     `json:"status"`
+	Quota         float64    `json:"quota"`
+	QuotaUsed     float64    `json:"quota_used"`
+	AllowedModels []string   `json:"allowed_models"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type subKeyListResponse struct {
+	Object string           `json:"object"`
+	Data   []subKeyResponse `json:"data"`
+}
+
+// CreateSubKey mints a delegated sub-key billed against the authenticated API key.
+// POST /v1/sub2api/keys
+func (h *GatewayHandler) CreateSubKey(c *gin.Context) {
+	parent, ok := h.subKeyParent(c)
+	if !ok {
+		return
+	}
+	var req createSubKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Invalid request: "+err.Error())
+		return
+	}
+	subKey, err := h.apiKeyService.CreateSubKey(c.Request.Context(), parent, service.CreateSubKeyRequest{
+		Name:          req.Name,
+		Quota:         req.Quota,
+		AllowedModels: req.AllowedModels,
+		ExpiresAt:     req.ExpiresAt,
+	})
+	if err != nil {
+		h.subKeyErrorResponse(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	// 完整 Key 仅在创建时返回一次
+	c.JSON(http.StatusCreated, buildSubKeyResponse(subKey, true))
+}
+
+// ListSubKeys lists delegated sub-keys minted by the authenticated API key.
+// GET /v1/sub2api/keys
+func (h *GatewayHandler) ListSubKeys(c *gin.Context) {
+	parent, ok := h.subKeyParent(c)
+	if !ok {
+		return
+	}
+	keys, err := h.apiKeyService.ListSubKeys(c.Request.Context(), parent)
+	if err != nil {
+		h.subKeyErrorResponse(c, err)
+		return
+	}
+	out := subKeyListResponse{Object: "list", Data: make([]subKeyResponse, 0, len(keys))}
+	for i := range keys {
+		out.Data = append(out.Data, buildSubKeyResponse(&keys[i], false))
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, out)
+}
+
+// RevokeSubKey deletes a delegated sub-key minted by the authenticated API key.
+// DELETE /v1/sub2api/keys/:id
+func (h *GatewayHandler) RevokeSubKey(c *gin.Context) {
+	parent, ok := h.subKeyParent(c)
+	if !ok {
+		return
+	}
+	subKeyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || subKeyID <= 0 {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Invalid sub-key ID")
+		return
+	}
+	if err := h.apiKeyService.RevokeSubKey(c.Request.Context(), parent, subKeyID); err != nil {
+		h.subKeyErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"object": "sub2api.sub_key", "id": subKeyID, "deleted": true})
+}
+
+func (h *GatewayHandler) subKeyParent(c *gin.Context) (*service.APIKey, bool) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return nil, false
+	}
+	if h.cfg != nil && h.cfg.RunMode == config.RunModeSimple {
+		h.errorResponse(c, http.StatusNotFound, "not_found_error", "Sub-keys are not supported in simple mode")
+		return nil, false
+	}
+	if h.apiKeyService == nil {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "Sub-keys are unavailable")
+		return nil, false
+	}
+	return apiKey, true
+}
+
+func (h *GatewayHandler) subKeyErrorResponse(c *gin.Context, err error) {
+	status := infraerrors.Code(err)
+	errType := "api_error"
+	message := infraerrors.Message(err)
+	switch status {
+	case http.StatusBadRequest:
+		errType = "invalid_request_error"
+	case http.StatusForbidden:
+		errType = "permission_error"
+	case http.StatusNotFound:
+		errType = "not_found_error"
+	default:
+		status = http.StatusInternalServerError
+		message = "Failed to manage sub-keys"
+	}
+	h.errorResponse(c, status, errType, message)
+}
+
+func buildSubKeyResponse(k *service.APIKey, includeKey bool) subKeyResponse {
+	out := subKeyResponse{
+		Object:        "sub2api.sub_key",
+		ID:            k.ID,
+		Name:          k.Name,
+		Status:        k.Status,
+		Quota:         k.Quota,
+		QuotaUsed:     k.QuotaUsed,
+		AllowedModels: k.AllowedModels,
+		ExpiresAt:     k.ExpiresAt,
+		LastUsedAt:    k.LastUsedAt,
+		CreatedAt:     k.CreatedAt,
+	}
+	if out.AllowedModels == nil {
+		out.AllowedModels = []string{}
+	}
+	if includeKey {
+		out.Key = k.Key
+	}
+	return out
+}
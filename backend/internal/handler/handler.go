@@ -41,6 +41,21 @@ type AdminHandlers struct {
 	Affiliate              *admin.AffiliateHandler
 	Compliance             *admin.ComplianceHandler
 	AuditLog               *admin.AuditLogHandler
+	Diagnostics            *admin.DiagnosticsHandler
+	BillingAdjustment      *admin.BillingAdjustmentHandler
+	AccountMetadata        *admin.AccountMetadataHandler
+	CredentialSource       *admin.AccountCredentialSourceHandler
+	AccountSnapshot        *admin.AccountSnapshotHandler
+	GroupBudget            *admin.GroupBudgetHandler
+	ActiveRequest          *admin.ActiveRequestHandler
+	FeatureFlag            *admin.FeatureFlagHandler
+	TokenCache             *admin.TokenCacheHandler
+	StreamCapture          *admin.StreamCaptureHandler
+	UsageArchive           *admin.UsageArchiveHandler
+	ModelAlias             *admin.ModelAliasHandler
+	CostAnomaly            *admin.CostAnomalyHandler
+	AccountSmokeTest       *admin.AccountSmokeTestHandler
+	SupportBundle          *admin.SupportBundleHandler
 }
 
 // Handlers contains all HTTP handlers
@@ -52,6 +67,7 @@ type Handlers struct {
 	Redeem           *RedeemHandler
 	Subscription     *SubscriptionHandler
 	Announcement     *AnnouncementHandler
+	Notification     *UserNotificationHandler
 	ChannelMonitor   *ChannelMonitorUserHandler
 	Admin            *AdminHandlers
 	Gateway          *GatewayHandler
@@ -63,6 +79,10 @@ type Handlers struct {
 	AvailableChannel *AvailableChannelHandler
 	AsyncImage       *AsyncImageHandler
 	BatchImage       *BatchImageHandler
+	Transcript       *ConversationTranscriptHandler
+	TaskHistory      *TaskHistoryHandler
+	Adjustment       *BillingAdjustmentHandler
+	Autoscaling      *AutoscalingMetricsHandler
 }
 
 // BuildInfo contains build-time information
@@ -69,6 +69,11 @@ func classifyNoAccountError(
 		ErrType: "api_error",
 		Message: "Service temporarily unavailable",
 	}
+	// Keys restricted to a data residency region only see accounts in that
+	// region; say so explicitly instead of a generic outage message.
+	if apiKey != nil && apiKey.DataResidency != "" {
+		fallback.Message = fmt.Sprintf("No account satisfying data residency %q is currently available", apiKey.DataResidency)
+	}
 
 	routingModel = strings.TrimSpace(routingModel)
 	displayModel = strings.TrimSpace(displayModel)
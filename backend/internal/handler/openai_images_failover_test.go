@@ -182,7 +182,13 @@ func TestOpenAIGatewayHandlerImages_ServerErrorFailsOverAndReturnsClearErrorWhen
 	require.True(t, ok)
 	events, ok := rawEvents.([]*service.OpsUpstreamErrorEvent)
 	require.True(t, ok)
-	require.Len(t, events, 2)
+	require.Len(t, events, 3)
 	require.Equal(t, "failover", events[0].Kind)
 	require.Equal(t, "failover", events[1].Kind)
+	require.Equal(t, service.OpsUpstreamKindFailoverExhausted, events[2].Kind)
+
+	attempts := gjson.GetBytes(rec.Body.Bytes(), "failover_attempts").Array()
+	require.Len(t, attempts, 2)
+	require.Equal(t, int64(1), attempts[0].Get("account_id").Int())
+	require.Equal(t, int64(2), attempts[1].Get("account_id").Int())
 }
@@ -445,12 +445,30 @@ func setOpsRequestContext(c *gin.Context, model string, stream bool) {
 	model = strings.TrimSpace(model)
 	c.Set(opsModelKey, model)
 	c.Set(opsStreamKey, stream)
-	if c.Request != nil && model != "" {
-		ctx := context.WithValue(c.Request.Context(), ctxkey.Model, model)
-		c.Request = c.Request.WithContext(ctx)
+	if c.Request != nil {
+		service.ActiveRequestFromContext(c.Request.Context()).SetModel(model, stream)
+	}
+	if c.Request != nil {
+		ctx := c.Request.Context()
+		if model != "" {
+			ctx = context.WithValue(ctx, ctxkey.Model, model)
+		}
+		kind := service.UpstreamRequestKindNonStreaming
+		if stream {
+			kind = service.UpstreamRequestKindStreaming
+		}
+		c.Request = c.Request.WithContext(service.WithUpstreamRequestKind(ctx, kind))
 	}
 }
 
+// setUpstreamUtilityRequestContext 把请求标记为非生成类调用（count_tokens 等），按 utility 档位计算上游超时。
+func setUpstreamUtilityRequestContext(c *gin.Context) {
+	if c == nil || c.Request == nil {
+		return
+	}
+	c.Request = c.Request.WithContext(service.WithUpstreamRequestKind(c.Request.Context(), service.UpstreamRequestKindUtility))
+}
+
 // setOpsEndpointContext stores upstream model and request type for ops error logging.
 // Called by handlers after model mapping and request type determination.
 func setOpsEndpointContext(c *gin.Context, upstreamModel string, requestType int16) {
@@ -470,13 +488,15 @@ func setOpsSelectedAccount(c *gin.Context, accountID int64, platform ...string)
 	c.Set(opsAccountIDKey, accountID)
 	if c.Request != nil {
 		ctx := context.WithValue(c.Request.Context(), ctxkey.AccountID, accountID)
+		selectedPlatform := ""
 		if len(platform) > 0 {
-			p := strings.TrimSpace(platform[0])
-			if p != "" {
-				ctx = context.WithValue(ctx, ctxkey.Platform, p)
+			selectedPlatform = strings.TrimSpace(platform[0])
+			if selectedPlatform != "" {
+				ctx = context.WithValue(ctx, ctxkey.Platform, selectedPlatform)
 			}
 		}
 		c.Request = c.Request.WithContext(ctx)
+		service.ActiveRequestFromContext(ctx).SetAccount(accountID, selectedPlatform)
 	}
 }
 
@@ -523,6 +543,10 @@ type opsCaptureWriter struct {
 	ctx   *gin.Context
 }
 
+func (w *opsCaptureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 const opsCaptureWriterLimit = service.OpsErrorLogQueueBodyMaxBytes
 
 const opsCaptureWriterPoolMaxRetainedCapacity = service.OpsErrorLogQueueBodyMaxBytes
@@ -1301,7 +1325,7 @@ func applyOpsUpstreamFieldsFromContext(c *gin.Context, entry *service.OpsInsertE
 	if v, ok := c.Get(service.OpsUpstreamErrorsKey); ok {
 		if events, ok := v.([]*service.OpsUpstreamErrorEvent); ok && len(events) > 0 {
 			entry.UpstreamErrors = events
-			last := events[len(events)-1]
+			last := service.LastOpsUpstreamAttemptEvent(events)
 			if last == nil {
 				return
 			}
@@ -1678,10 +1702,8 @@ func hasOpsAccountAuthFailure(c *gin.Context) bool {
 	}
 	if v, ok := c.Get(service.OpsUpstreamErrorsKey); ok {
 		if events, ok := v.([]*service.OpsUpstreamErrorEvent); ok {
-			for i := len(events) - 1; i >= 0; i-- {
-				if events[i] != nil {
-					return events[i].Stage == string(service.GatewayFailureStageAccountAuth)
-				}
+			if last := service.LastOpsUpstreamAttemptEvent(events); last != nil {
+				return last.Stage == string(service.GatewayFailureStageAccountAuth)
 			}
 		}
 	}
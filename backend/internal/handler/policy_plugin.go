@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PolicyPluginMiddleware 在网关请求上执行自定义策略插件的 pre_parse / post_response 钩子，
+// 并把请求级策略状态放入 context，供上游调用层执行 pre_forward。需挂在 API Key 认证之后。
+func PolicyPluginMiddleware(m *policyplugin.Manager, writeError middleware2.GatewayErrorWriter) gin.HandlerFunc {
+	if m == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return func(c *gin.Context) {
+		state := policyplugin.NewState(policyPluginBaseRequest(c))
+		ctx := policyplugin.WithState(c.Request.Context(), state)
+		c.Request = c.Request.WithContext(ctx)
+		defer logPolicyPluginAnnotations(c, state)
+
+		if m.Enabled(policyplugin.HookPreParse) && !c.IsWebsocket() && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body, err := io.ReadAll(c.Request.Body)
+			_ = c.Request.Body.Close()
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					writeError(c, http.StatusRequestEntityTooLarge, "Request body too large")
+				} else {
+					writeError(c, http.StatusBadRequest, "Failed to read request body")
+				}
+				c.Abort()
+				return
+			}
+
+			req := state.Request(policyplugin.HookPreParse)
+			req.Header = policyplugin.SanitizedHeader(c.Request.Header)
+			req.Body = body
+			out := m.Run(ctx, req)
+			state.Annotate(out.Annotations)
+			policyplugin.SetRequestBody(c.Request, out.Body)
+			if out.Reject != nil {
+				writeError(c, out.Reject.StatusCode, out.Reject.Message)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+
+		if m.Enabled(policyplugin.HookPostResponse) {
+			req := state.Request(policyplugin.HookPostResponse)
+			req.Header = policyplugin.SanitizedHeader(c.Request.Header)
+			req.StatusCode = c.Writer.Status()
+			state.Annotate(m.Run(ctx, req).Annotations)
+		}
+	}
+}
+
+func policyPluginBaseRequest(c *gin.Context) policyplugin.Request {
+	req := policyplugin.Request{
+		Method: c.Request.Method,
+		Path:   c.Request.URL.Path,
+	}
+	ctx := c.Request.Context()
+	if requestID, _ := ctx.Value(ctxkey.RequestID).(string); requestID != "" {
+		req.RequestID = strings.TrimSpace(requestID)
+	}
+	if platform, _ := ctx.Value(ctxkey.Platform).(string); platform != "" {
+		req.Platform = platform
+	}
+	if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil {
+		req.APIKeyID = apiKey.ID
+		req.UserID = apiKey.UserID
+		if apiKey.GroupID != nil {
+			req.GroupID = *apiKey.GroupID
+		}
+		if req.Platform == "" && apiKey.Group != nil {
+			req.Platform = apiKey.Group.Platform
+		}
+	}
+	return req
+}
+
+func logPolicyPluginAnnotations(c *gin.Context, state *policyplugin.State) {
+	annotations := state.Annotations()
+	if len(annotations) == 0 {
+		return
+	}
+	requestID, _ := c.Request.Context().Value(ctxkey.RequestID).(string)
+	logger.L().Info("gateway.policy_plugin_annotations",
+		zap.String("request_id", requestID),
+		zap.String("path", c.Request.URL.Path),
+		zap.Int("status", c.Writer.Status()),
+		zap.Any("annotations", annotations),
+	)
+}
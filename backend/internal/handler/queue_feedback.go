@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// queuePositionHeader 进入排队时的位置（1 表示排在最前）
+	queuePositionHeader = "X-Sub2API-Queue-Position"
+
+	// queueWaitContextKey gin.Context 中累计的排队耗时（用户槽位 + 账号槽位）
+	queueWaitContextKey = "queue_wait_total"
+)
+
+// slotQueueStatus 等待并发槽位期间的排队位置估算。
+// 等待计数只是计数器而非有序队列，位置按"当前等待数"近似：入队时等待数即自身位置，
+// 之后等待数下降时位置随之前移（不会因后来者入队而后退）。
+type slotQueueStatus struct {
+	slotType        string
+	enqueuedAt      time.Time
+	initialPosition int
+	position        int
+}
+
+func newSlotQueueStatus(slotType string, waiting int, now time.Time) *slotQueueStatus {
+	position := max(waiting, 1)
+	return &slotQueueStatus{
+		slotType:        slotType,
+		enqueuedAt:      now,
+		initialPosition: position,
+		position:        position,
+	}
+}
+
+// observe 用最新的等待数更新位置。
+func (q *slotQueueStatus) observe(waiting int) {
+	if waiting <= 0 {
+		return
+	}
+	q.position = min(q.position, waiting)
+}
+
+// estimatedWait 按入队以来的出队速率估算剩余等待时间；尚未观察到出队时无法估算。
+func (q *slotQueueStatus) estimatedWait(now time.Time) (time.Duration, bool) {
+	drained := q.initialPosition - q.position
+	elapsed := now.Sub(q.enqueuedAt)
+	if drained <= 0 || elapsed <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(elapsed) * float64(q.position) / float64(drained)), true
+}
+
+// pingEvent 返回携带排队信息的 ping：Claude 格式在 ping 事件中附加 queue 字段，
+// 注释格式在 ping 前追加一行 ": queue ..." 注释；客户端会忽略两者中不认识的内容。
+func (q *slotQueueStatus) pingEvent(format SSEPingFormat, now time.Time) string {
+	estimate, hasEstimate := q.estimatedWait(now)
+	switch format {
+	case SSEPingFormatClaude:
+		queue := map[string]any{
+			"slot":     q.slotType,
+			"position": q.position,
+		}
+		if hasEstimate {
+			queue["estimated_wait_seconds"] = int(math.Ceil(estimate.Seconds()))
+		}
+		data, err := json.Marshal(map[string]any{"type": "ping", "queue": queue})
+		if err != nil {
+			return string(format)
+		}
+		return "data: " + string(data) + "\n\n"
+	case SSEPingFormatComment:
+		comment := fmt.Sprintf(": queue slot=%s position=%d", q.slotType, q.position)
+		if hasEstimate {
+			comment += fmt.Sprintf(" estimated_wait_seconds=%d", int(math.Ceil(estimate.Seconds())))
+		}
+		return comment + "\n" + string(format)
+	default:
+		return string(format)
+	}
+}
+
+// queueWaitingCount 查询当前等待数；查询失败时返回 0（保持上一次的位置）。
+func (h *ConcurrencyHelper) queueWaitingCount(ctx context.Context, slotType string, id int64) int {
+	var (
+		waiting int
+		err     error
+	)
+	if slotType == "user" {
+		waiting, err = h.concurrencyService.GetUserWaitingCount(ctx, id)
+	} else {
+		waiting, err = h.concurrencyService.GetAccountWaitingCount(ctx, id)
+	}
+	if err != nil {
+		return 0
+	}
+	return waiting
+}
+
+// setQueueFeedbackHeaders 排队结束后在最终响应上附带排队位置与累计等待耗时（响应头尚未写出时）。
+// 等待耗时与响应注解头共用 X-Sub2API-Queue-Wait-Ms，未开启注解的 Key 也能拿到。
+func setQueueFeedbackHeaders(c *gin.Context, queue *slotQueueStatus, now time.Time) {
+	total := now.Sub(queue.enqueuedAt)
+	if prev, ok := c.Get(queueWaitContextKey); ok {
+		if d, ok := prev.(time.Duration); ok {
+			total += d
+		}
+	}
+	c.Set(queueWaitContextKey, total)
+	if c.Writer.Written() {
+		return
+	}
+	c.Header(queuePositionHeader, strconv.Itoa(queue.initialPosition))
+	c.Header(service.RequestAnnotationHeaderQueueWaitMs, strconv.FormatInt(total.Milliseconds(), 10))
+}
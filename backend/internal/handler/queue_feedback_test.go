@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlotQueueStatus_PositionAndEstimate(t *testing.T) {
+	start := time.Unix(1000, 0)
+	queue := newSlotQueueStatus("user", 4, start)
+	_, ok := queue.estimatedWait(start.Add(time.Second))
+	require.False(t, ok, "尚未观察到出队时不估算")
+
+	queue.observe(6) // 后来者入队不会让位置后退
+	require.Equal(t, 4, queue.position)
+	queue.observe(2)
+	require.Equal(t, 2, queue.position)
+	queue.observe(0) // 查询失败保持原位置
+	require.Equal(t, 2, queue.position)
+
+	// 10 秒内前移 2 位，剩余 2 位约需 10 秒
+	estimate, ok := queue.estimatedWait(start.Add(10 * time.Second))
+	require.True(t, ok)
+	require.Equal(t, 10*time.Second, estimate)
+
+	require.Equal(t, 1, newSlotQueueStatus("account", 0, start).position)
+}
+
+func TestSlotQueueStatus_PingEvent(t *testing.T) {
+	start := time.Unix(1000, 0)
+	queue := newSlotQueueStatus("account", 3, start)
+	queue.observe(1)
+
+	claude := queue.pingEvent(SSEPingFormatClaude, start.Add(4*time.Second))
+	require.True(t, strings.HasPrefix(claude, "data: "))
+	require.True(t, strings.HasSuffix(claude, "\n\n"))
+	var payload struct {
+		Type  string `json:"type"`
+		Queue struct {
+			Slot                 string `json:"slot"`
+			Position             int    `json:"position"`
+			EstimatedWaitSeconds int    `json:"estimated_wait_seconds"`
+		} `json:"queue"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(claude, "data: "))), &payload))
+	require.Equal(t, "ping", payload.Type)
+	require.Equal(t, "account", payload.Queue.Slot)
+	require.Equal(t, 1, payload.Queue.Position)
+	require.Equal(t, 2, payload.Queue.EstimatedWaitSeconds)
+
+	comment := queue.pingEvent(SSEPingFormatComment, start.Add(4*time.Second))
+	require.Equal(t, ": queue slot=account position=1 estimated_wait_seconds=2\n:\n\n", comment)
+
+	require.Empty(t, queue.pingEvent(SSEPingFormatNone, start))
+}
+
+func TestWaitForSlotWithPingTimeout_SetsQueueHeaders(t *testing.T) {
+	cache := &helperConcurrencyCacheStub{
+		accountSeq: []bool{false, true},
+	}
+	helper := NewConcurrencyHelper(service.NewConcurrencyService(cache), SSEPingFormatNone, time.Second)
+	c, rec := newHelperTestContext(http.MethodPost, "/v1/messages")
+	streamStarted := false
+
+	release, err := helper.waitForSlotWithPingTimeout(c, "account", 101, 1, time.Second, false, &streamStarted, true)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	release()
+
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	require.Equal(t, "1", rec.Header().Get(queuePositionHeader))
+	require.NotEmpty(t, rec.Header().Get(service.RequestAnnotationHeaderQueueWaitMs))
+}
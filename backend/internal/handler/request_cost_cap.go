@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestCostCapMiddleware 对设置了单请求费用上限（Key / 分组 max_request_cost）的请求，
+// 在转发前按 输入估算 + 输出上限 × 输出单价（含倍率与高峰加价）估算费用上界，超过上限时直接拒绝，
+// 防止单个失控的长上下文 / 高价模型请求耗尽小额预算。需挂在 API Key 认证与策略插件之后。
+func (h *GatewayHandler) RequestCostCapMiddleware(writeError middleware2.GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || c.IsWebsocket() || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		format := service.RequestValidationFormatForPath(c.Request.URL.Path)
+		if format == "" {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		limit := service.EffectiveMaxRequestCost(apiKey)
+		if limit <= 0 || h == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(c, http.StatusRequestEntityTooLarge, "Request body too large")
+			} else {
+				writeError(c, http.StatusBadRequest, "Failed to read request body")
+			}
+			c.Abort()
+			return
+		}
+		policyplugin.SetRequestBody(c.Request, body)
+
+		rate := 1.0
+		if apiKey.Group != nil && apiKey.GroupID != nil {
+			if resolved, ok := h.resolveKeyBillingRate(c, apiKey); ok {
+				rate = resolved * apiKey.Group.PeakMultiplierAt(time.Now())
+			}
+		}
+		estimate, err := h.gatewayService.EstimateRequestCostCeiling(body, format, geminiModelFromPath(c.Request.URL.Path), rate)
+		switch {
+		case errors.Is(err, service.ErrRequestCostUnbounded):
+			writeRequestCostCapError(c, format, fmt.Sprintf(
+				"This API key has a per-request cost cap of $%.4f; set an output token limit (max_tokens) so the request cost can be bounded", limit))
+			c.Abort()
+			return
+		case estimate != nil && estimate.Cost > limit:
+			writeRequestCostCapError(c, format, fmt.Sprintf(
+				"Estimated maximum cost $%.4f exceeds the per-request cost cap of $%.4f (model %s, ~%d input tokens, up to %d output tokens); lower max_tokens or shorten the input",
+				estimate.Cost, limit, estimate.Model, estimate.InputTokens, estimate.MaxOutputTokens))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// geminiModelFromPath 从 Gemini 原生路径（.../models/{model}:{action}）中取出模型名；非 Gemini 路径返回空字符串。
+func geminiModelFromPath(path string) string {
+	idx := strings.LastIndex(path, "/models/")
+	if idx < 0 {
+		return ""
+	}
+	model, _, err := parseGeminiModelAction(path[idx+len("/models/"):])
+	if err != nil {
+		return ""
+	}
+	return model
+}
+
+// writeRequestCostCapError 按端点协议的错误格式输出费用上限拒绝。
+func writeRequestCostCapError(c *gin.Context, format, message string) {
+	switch format {
+	case service.RequestValidationFormatGemini:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    http.StatusBadRequest,
+				"message": message,
+				"status":  "INVALID_ARGUMENT",
+			},
+		})
+	case service.ResponseLanguageFormatAnthropic:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"type":  "error",
+			"error": gin.H{"type": "invalid_request_error", "message": message},
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"type":    "invalid_request_error",
+				"code":    "request_cost_cap_exceeded",
+				"message": message,
+			},
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestCostCapHandler() *GatewayHandler {
+	billing := service.NewBillingService(&config.Config{}, nil)
+	return &GatewayHandler{
+		gatewayService: service.NewGatewayService(
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, billing, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		),
+	}
+}
+
+func runRequestCostCap(t *testing.T, h *GatewayHandler, apiKey *service.APIKey, path, body string) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	router.Use(h.RequestCostCapMiddleware(middleware2.AnthropicErrorWriter))
+	reached := false
+	router.POST("/*path", func(c *gin.Context) {
+		reached = true
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return w, reached
+}
+
+func TestRequestCostCapMiddleware_RejectsRequestAboveCap(t *testing.T) {
+	apiKey := &service.APIKey{MaxRequestCost: 0.01}
+	body := `{"model":"claude-sonnet-4","max_tokens":64000,"messages":[{"role":"user","content":"hi"}]}`
+
+	w, reached := runRequestCostCap(t, newRequestCostCapHandler(), apiKey, "/v1/messages", body)
+	require.False(t, reached)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	errObj := resp["error"].(map[string]any)
+	require.Equal(t, "invalid_request_error", errObj["type"])
+	require.Contains(t, errObj["message"], "per-request cost cap of $0.0100")
+}
+
+func TestRequestCostCapMiddleware_AllowsRequestWithinCap(t *testing.T) {
+	apiKey := &service.APIKey{MaxRequestCost: 0.01}
+	body := `{"model":"claude-sonnet-4","max_tokens":100,"messages":[{"role":"user","content":"hi"}]}`
+
+	w, reached := runRequestCostCap(t, newRequestCostCapHandler(), apiKey, "/v1/messages", body)
+	require.True(t, reached)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequestCostCapMiddleware_GroupCapAndUnboundedRequest(t *testing.T) {
+	groupID := int64(3)
+	apiKey := &service.APIKey{GroupID: &groupID, Group: &service.Group{ID: groupID, MaxRequestCost: 0.5}}
+	// 未给出 max_tokens 且回退价格中没有最大输出：无法估算上界，直接拒绝
+	body := `{"model":"claude-sonnet-4","messages":[{"role":"user","content":"hi"}]}`
+
+	w, reached := runRequestCostCap(t, newRequestCostCapHandler(), apiKey, "/v1/chat/completions", body)
+	require.False(t, reached)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "request_cost_cap_exceeded")
+	require.Contains(t, w.Body.String(), "max_tokens")
+}
+
+func TestRequestCostCapMiddleware_SkipsKeysWithoutCap(t *testing.T) {
+	body := `{"model":"claude-sonnet-4","max_tokens":64000,"messages":[{"role":"user","content":"hi"}]}`
+	_, reached := runRequestCostCap(t, nil, &service.APIKey{}, "/v1/messages", body)
+	require.True(t, reached)
+}
+
+func TestGeminiModelFromPath(t *testing.T) {
+	require.Equal(t, "gemini-2.5-pro", geminiModelFromPath("/v1beta/models/gemini-2.5-pro:generateContent"))
+	require.Equal(t, "gemini-2.5-flash", geminiModelFromPath("/antigravity/v1beta/models/gemini-2.5-flash:streamGenerateContent"))
+	require.Empty(t, geminiModelFromPath("/v1/messages"))
+}
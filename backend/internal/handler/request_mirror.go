@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cappedTeeReadCloser 读取请求体的同时保留前 limit 字节副本，不改变下游读取行为。
+type cappedTeeReadCloser struct {
+	io.ReadCloser
+	limit     int
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (r *cappedTeeReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 && !r.truncated {
+		remaining := r.limit - r.buf.Len()
+		if n > remaining {
+			_, _ = r.buf.Write(p[:remaining])
+			r.truncated = true
+		} else {
+			_, _ = r.buf.Write(p[:n])
+		}
+	}
+	return n, err
+}
+
+// RequestMirrorMiddleware 对采样命中的网关请求，在请求结束后将元数据（可选正文）提交给镜像服务。
+// 需挂在 API Key 认证之后：认证失败的请求不进入镜像，避免把未授权流量（含其请求头与正文）外发。
+func RequestMirrorMiddleware(svc *service.RequestMirrorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !svc.ShouldSample() || c.IsWebsocket() {
+			c.Next()
+			return
+		}
+
+		startedAt := time.Now()
+		var bodyTee *cappedTeeReadCloser
+		var writer *cappedCaptureWriter
+		if svc.IncludeBodies() {
+			limit := svc.MaxBodyBytes()
+			if c.Request.Body != nil {
+				bodyTee = &cappedTeeReadCloser{ReadCloser: c.Request.Body, limit: limit}
+				c.Request.Body = bodyTee
+			}
+			writer = &cappedCaptureWriter{ResponseWriter: c.Writer, limit: limit}
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		if writer != nil && c.Writer == writer {
+			c.Writer = writer.ResponseWriter
+		}
+		svc.Submit(buildRequestMirrorRecord(c, startedAt, bodyTee, writer))
+	}
+}
+
+func buildRequestMirrorRecord(c *gin.Context, startedAt time.Time, bodyTee *cappedTeeReadCloser, writer *cappedCaptureWriter) *service.RequestMirrorRecord {
+	record := &service.RequestMirrorRecord{
+		Timestamp:  startedAt.UTC(),
+		Method:     c.Request.Method,
+		Endpoint:   GetInboundEndpoint(c),
+		StatusCode: c.Writer.Status(),
+		DurationMs: time.Since(startedAt).Milliseconds(),
+	}
+	ctx := c.Request.Context()
+	if requestID, _ := ctx.Value(ctxkey.RequestID).(string); requestID != "" {
+		record.RequestID = strings.TrimSpace(requestID)
+	}
+	if platform, _ := ctx.Value(ctxkey.Platform).(string); platform != "" {
+		record.Platform = platform
+	}
+	if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil {
+		record.UserID = apiKey.UserID
+		record.APIKeyID = apiKey.ID
+		record.GroupID = apiKey.GroupID
+		if record.Platform == "" && apiKey.Group != nil {
+			record.Platform = apiKey.Group.Platform
+		}
+	}
+	if v, ok := c.Get(opsModelKey); ok {
+		record.Model, _ = v.(string)
+	}
+	if v, ok := c.Get(opsStreamKey); ok {
+		record.Stream, _ = v.(bool)
+	}
+	if v, ok := c.Get(opsAccountIDKey); ok {
+		record.AccountID, _ = v.(int64)
+	}
+	if bodyTee != nil {
+		record.RequestBody = bodyTee.buf.String()
+		record.RequestTruncated = bodyTee.truncated
+	}
+	if writer != nil {
+		record.ResponseBody = writer.buf.String()
+		record.ResponseTruncated = writer.truncated
+	}
+	return record
+}
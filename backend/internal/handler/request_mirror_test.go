@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestMirrorMiddleware_CapturesMetadataAndBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	received := make(chan service.RequestMirrorRecord, 1)
+	mirrorSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record service.RequestMirrorRecord
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+		received <- record
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer mirrorSrv.Close()
+
+	cfg := &config.Config{}
+	cfg.Gateway.RequestMirror = config.GatewayRequestMirrorConfig{
+		Enabled:        true,
+		Endpoint:       mirrorSrv.URL,
+		SampleRate:     1,
+		IncludeBodies:  true,
+		MaxBodyBytes:   10,
+		TimeoutSeconds: 2,
+		WorkerCount:    1,
+		QueueSize:      4,
+	}
+	svc := service.NewRequestMirrorService(cfg)
+	defer svc.Stop()
+
+	groupID := int64(3)
+	router := gin.New()
+	router.Use(RequestMirrorMiddleware(svc))
+	router.POST("/v1/messages", func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{
+			ID:      9,
+			UserID:  7,
+			Key:     "sk-secret",
+			GroupID: &groupID,
+			Group:   &service.Group{Platform: service.PlatformAnthropic},
+		})
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"model":"claude-sonnet-4-5"}`, string(body), "downstream must still see the full body")
+		setOpsRequestContext(c, "claude-sonnet-4-5", false)
+		setOpsSelectedAccount(c, 42)
+		c.String(http.StatusOK, "hello mirror response")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`))
+	router.ServeHTTP(w, req)
+	require.Equal(t, "hello mirror response", w.Body.String())
+
+	select {
+	case record := <-received:
+		require.Equal(t, int64(7), record.UserID)
+		require.Equal(t, int64(9), record.APIKeyID)
+		require.Equal(t, int64(42), record.AccountID)
+		require.Equal(t, "claude-sonnet-4-5", record.Model)
+		require.Equal(t, service.PlatformAnthropic, record.Platform)
+		require.Equal(t, http.StatusOK, record.StatusCode)
+		require.Equal(t, `{"model":"`, record.RequestBody)
+		require.True(t, record.RequestTruncated)
+		require.Equal(t, "hello mirr", record.ResponseBody)
+		require.True(t, record.ResponseTruncated)
+	case <-time.After(2 * time.Second):
+		t.Fatal("mirror record was not delivered")
+	}
+}
+
+func TestRequestMirrorMiddleware_DisabledPassesThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestMirrorMiddleware(service.NewRequestMirrorService(&config.Config{})))
+	router.GET("/v1/models", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	require.Equal(t, "ok", w.Body.String())
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestValidationMiddleware 对开启严格校验的 API Key（或通过 X-Sub2API-Beta 按请求启用 strict-validation），在转发前按端点 schema 校验请求体，
+// 不合法时直接返回 400 与字段级错误（path / expected / message），避免上游只给出含糊的 400。
+// 覆盖 messages、chat/completions、responses 与 Gemini generateContent；需挂在 API Key 认证与策略插件之后、
+// 其它改写请求体的中间件之前，使错误指向客户端原始请求体。
+func RequestValidationMiddleware(writeError middleware2.GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || c.IsWebsocket() || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		format := service.RequestValidationFormatForPath(c.Request.URL.Path)
+		if format == "" {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || (!apiKey.StrictValidation && !service.BetaFeatureEnabled(c.Request.Context(), service.BetaFeatureStrictValidation)) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(c, http.StatusRequestEntityTooLarge, "Request body too large")
+			} else {
+				writeError(c, http.StatusBadRequest, "Failed to read request body")
+			}
+			c.Abort()
+			return
+		}
+		if fieldErrors := service.ValidateRequestBody(body, format); len(fieldErrors) > 0 {
+			writeRequestValidationError(c, format, fieldErrors)
+			c.Abort()
+			return
+		}
+		policyplugin.SetRequestBody(c.Request, body)
+		c.Next()
+	}
+}
+
+// writeRequestValidationError 按端点协议的错误格式输出字段级错误。
+func writeRequestValidationError(c *gin.Context, format string, fieldErrors []service.RequestFieldError) {
+	first := fieldErrors[0]
+	message := "Request validation failed: " + first.Message
+	if first.Path != "" {
+		message = "Request validation failed: " + first.Path + ": " + first.Message
+	}
+	switch format {
+	case service.RequestValidationFormatGemini:
+		violations := make([]gin.H, 0, len(fieldErrors))
+		for _, fe := range fieldErrors {
+			violations = append(violations, gin.H{"field": fe.Path, "description": fe.Message})
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    http.StatusBadRequest,
+				"message": message,
+				"status":  "INVALID_ARGUMENT",
+				"details": []gin.H{{
+					"@type":           "type.googleapis.com/google.rpc.BadRequest",
+					"fieldViolations": violations,
+				}},
+			},
+		})
+	case service.ResponseLanguageFormatAnthropic:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"type": "error",
+			"error": gin.H{
+				"type":    "invalid_request_error",
+				"message": message,
+				"details": fieldErrors,
+			},
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"type":    "invalid_request_error",
+				"code":    "request_validation_failed",
+				"param":   first.Path,
+				"message": message,
+				"details": fieldErrors,
+			},
+		})
+	}
+}
@@ -0,0 +1,76 @@
+//go:build unit
+
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newRequestValidationRouter(apiKey *service.APIKey, received *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware.ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.Use(RequestValidationMiddleware(middleware.AnthropicErrorWriter))
+	capture := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		*received = string(body)
+		c.Status(http.StatusOK)
+	}
+	r.POST("/v1/messages", capture)
+	r.POST("/v1/chat/completions", capture)
+	r.POST("/v1beta/models/*modelAction", capture)
+	return r
+}
+
+func TestRequestValidationMiddleware_RejectsWithFieldErrors(t *testing.T) {
+	var received string
+	r := newRequestValidationRouter(&service.APIKey{ID: 1, StrictValidation: true}, &received)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude","messages":[]}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Empty(t, received)
+	require.Equal(t, "invalid_request_error", gjson.Get(w.Body.String(), "error.type").String())
+	require.Equal(t, "max_tokens", gjson.Get(w.Body.String(), "error.details.0.path").String())
+	require.Equal(t, "messages", gjson.Get(w.Body.String(), "error.details.1.path").String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt","messages":[{"role":1}]}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "messages.0.role", gjson.Get(w.Body.String(), "error.param").String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "INVALID_ARGUMENT", gjson.Get(w.Body.String(), "error.status").String())
+	require.Equal(t, "contents", gjson.Get(w.Body.String(), "error.details.0.fieldViolations.0.field").String())
+}
+
+func TestRequestValidationMiddleware_PassesValidAndNonStrict(t *testing.T) {
+	body := `{"model":"claude","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`
+	var received string
+	r := newRequestValidationRouter(&service.APIKey{ID: 1, StrictValidation: true}, &received)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, body, received)
+
+	r = newRequestValidationRouter(&service.APIKey{ID: 1}, &received)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{}`, received)
+}
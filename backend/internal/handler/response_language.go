@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseLanguageMiddleware 按 API Key / 分组的强制回复语言改写请求体：客户端未指定回复语言时，
+// 在 system / instructions 末尾追加语言指令。仅处理 messages、chat/completions、responses 三类端点，
+// 需挂在 API Key 认证与策略插件 pre_parse 之后（与 pre_parse 共用请求体替换方式）。
+func ResponseLanguageMiddleware(writeError middleware2.GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || c.IsWebsocket() || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		format := service.ResponseLanguageFormatForPath(c.Request.URL.Path)
+		if format == "" {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		language := service.ResolveResponseLanguage(apiKey)
+		if language == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(c, http.StatusRequestEntityTooLarge, "Request body too large")
+			} else {
+				writeError(c, http.StatusBadRequest, "Failed to read request body")
+			}
+			c.Abort()
+			return
+		}
+		if rewritten, applied := service.ApplyResponseLanguage(body, format, language); applied {
+			body = rewritten
+		}
+		policyplugin.SetRequestBody(c.Request, body)
+		c.Next()
+	}
+}
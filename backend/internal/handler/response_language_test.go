@@ -0,0 +1,70 @@
+//go:build unit
+
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newResponseLanguageRouter(apiKey *service.APIKey, received *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware.ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.Use(ResponseLanguageMiddleware(middleware.AnthropicErrorWriter))
+	capture := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		*received = string(body)
+		c.Status(http.StatusOK)
+	}
+	r.POST("/v1/messages", capture)
+	r.POST("/v1/embeddings", capture)
+	return r
+}
+
+func TestResponseLanguageMiddleware_InjectsFromGroup(t *testing.T) {
+	var received string
+	apiKey := &service.APIKey{ID: 1, Group: &service.Group{ID: 2, ResponseLanguage: "zh"}}
+	r := newResponseLanguageRouter(apiKey, &received)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`)))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, gjson.Get(received, "system").String(), "Simplified Chinese")
+}
+
+func TestResponseLanguageMiddleware_KeyOffOverridesGroup(t *testing.T) {
+	var received string
+	body := `{"model":"m","messages":[{"role":"user","content":"hi"}]}`
+	apiKey := &service.APIKey{ID: 1, ResponseLanguage: service.ResponseLanguageOff, Group: &service.Group{ID: 2, ResponseLanguage: "zh"}}
+	r := newResponseLanguageRouter(apiKey, &received)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)))
+
+	require.Equal(t, body, received)
+}
+
+func TestResponseLanguageMiddleware_SkipsUnsupportedEndpoint(t *testing.T) {
+	var received string
+	body := `{"model":"m","input":"hi"}`
+	r := newResponseLanguageRouter(&service.APIKey{ID: 1, ResponseLanguage: "en"}, &received)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(body)))
+
+	require.Equal(t, body, received)
+}
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseResumeTailPollInterval 续传仍在生成的流时轮询新事件的间隔
+const sseResumeTailPollInterval = 250 * time.Millisecond
+
+// sseResumeWriter 为 SSE 响应的每个事件注入 "id: <stream_id>-<seq>" 并异步写入续传缓存。
+// 仅当响应 Content-Type 为 text/event-stream 时生效；客户端断开后继续"写入"（只进缓存），
+// 使上游生成在断开宽限期内继续进行，供客户端稍后续传。
+type sseResumeWriter struct {
+	gin.ResponseWriter
+	svc      *service.SSEResumeService
+	streamID string
+	ownerID  int64
+
+	decided    bool
+	active     bool
+	recorder   *service.SSEResumeRecorder
+	pending    []byte
+	seq        int64
+	clientGone bool
+}
+
+func (w *sseResumeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *sseResumeWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	if !strings.HasPrefix(strings.ToLower(w.Header().Get("Content-Type")), "text/event-stream") {
+		return
+	}
+	w.active = true
+	w.recorder = w.svc.StartRecording(w.streamID, w.ownerID)
+}
+
+func (w *sseResumeWriter) WriteHeader(code int) {
+	w.decide()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sseResumeWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if !w.active {
+		return w.ResponseWriter.Write(b)
+	}
+	w.pending = append(w.pending, b...)
+	for {
+		idx := bytes.Index(w.pending, []byte("\n\n"))
+		if idx < 0 {
+			break
+		}
+		event := w.pending[:idx+2]
+		w.emit(event)
+		w.pending = w.pending[idx+2:]
+	}
+	return len(b), nil
+}
+
+func (w *sseResumeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *sseResumeWriter) Flush() {
+	if w.clientGone {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+// emit 为完整事件分配 ID（去掉事件自带的 id 行），写给客户端并记录到缓存。
+func (w *sseResumeWriter) emit(event []byte) {
+	w.seq++
+	out := make([]byte, 0, len(event)+40)
+	out = append(out, "id: "...)
+	out = append(out, service.FormatSSEResumeEventID(w.streamID, w.seq)...)
+	out = append(out, '\n')
+	for _, line := range bytes.SplitAfter(event, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("id:")) {
+			continue
+		}
+		out = append(out, line...)
+	}
+	w.recorder.Record(w.seq, out)
+	w.writeClient(out)
+}
+
+func (w *sseResumeWriter) writeClient(b []byte) {
+	if w.clientGone {
+		return
+	}
+	if _, err := w.ResponseWriter.Write(b); err != nil {
+		w.clientGone = true
+	}
+}
+
+// finish 输出残留的不完整事件并结束记录
+func (w *sseResumeWriter) finish() {
+	if !w.active {
+		return
+	}
+	if len(w.pending) > 0 {
+		w.writeClient(w.pending)
+		w.pending = nil
+		w.Flush()
+	}
+	w.recorder.Close()
+}
+
+// SSEResumeMiddleware 为对话类流式接口提供 Last-Event-ID 断线续传。
+// 携带本网关签发的 Last-Event-ID 且缓存可无缝续传时直接回放剩余事件，否则按新请求处理并为 SSE 事件分配 ID。
+// 需挂在 API Key 认证之后（续传只允许同一 Key 读取自己的流）。
+func SSEResumeMiddleware(svc *service.SSEResumeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !svc.Enabled() {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil {
+			c.Next()
+			return
+		}
+
+		if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+			if streamID, seq, valid := service.ParseSSEResumeEventID(lastEventID); valid {
+				if events, done, resumable := svc.LoadResumable(c.Request.Context(), streamID, apiKey.ID, seq); resumable {
+					replaySSEResume(c, svc, streamID, apiKey.ID, seq, events, done)
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		// 客户端断开后生成继续写入缓存供续传，但最多再保留 DisconnectGrace，之后按客户端断开取消请求 context；
+		// 下游脱钩的上游调用随之进入 watchClientDisconnect 的断开处理。
+		clientCtx := c.Request.Context()
+		ctx, cancel := context.WithCancelCause(context.WithoutCancel(clientCtx))
+		defer cancel(nil)
+		stopWatch := cancelAfterDisconnect(clientCtx, svc.DisconnectGrace(), cancel)
+		defer stopWatch()
+		// 在途请求被管理员强制取消时立即结束生成
+		service.ActiveRequestFromContext(ctx).AttachCancel(func() { cancel(service.ErrClientDisconnected) })
+		c.Request = c.Request.WithContext(ctx)
+
+		writer := &sseResumeWriter{
+			ResponseWriter: c.Writer,
+			svc:            svc,
+			streamID:       service.NewSSEResumeStreamID(),
+			ownerID:        apiKey.ID,
+		}
+		c.Writer = writer
+		c.Next()
+		writer.finish()
+		if c.Writer == writer {
+			c.Writer = writer.ResponseWriter
+		}
+	}
+}
+
+// cancelAfterDisconnect 在 clientCtx 结束 grace 之后以 ErrClientDisconnected 调用 cancel（grace<=0 时立即调用）。
+// 返回的 stop 用于请求正常结束时撤销监视与计时器。
+func cancelAfterDisconnect(clientCtx context.Context, grace time.Duration, cancel context.CancelCauseFunc) (stop func()) {
+	var (
+		mu      sync.Mutex
+		timer   *time.Timer
+		stopped bool
+	)
+	stopAfter := context.AfterFunc(clientCtx, func() {
+		if grace <= 0 {
+			cancel(service.ErrClientDisconnected)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			timer = time.AfterFunc(grace, func() { cancel(service.ErrClientDisconnected) })
+		}
+	})
+	return func() {
+		stopAfter()
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// replaySSEResume 回放 afterSeq 之后的缓存事件；原流仍在生成时持续跟随，直到结束、空闲超时或客户端断开。
+func replaySSEResume(c *gin.Context, svc *service.SSEResumeService, streamID string, apiKeyID int64, afterSeq int64, events []service.SSEResumeEvent, done bool) {
+	h := c.Writer.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	ctx := c.Request.Context()
+	lastSeq := afterSeq
+	lastProgress := time.Now()
+	idleTimeout := svc.TailIdleTimeout()
+	for {
+		for _, ev := range events {
+			if ev.Seq <= lastSeq {
+				continue
+			}
+			if _, err := c.Writer.Write(ev.Data); err != nil {
+				return
+			}
+			lastSeq = ev.Seq
+			lastProgress = time.Now()
+		}
+		c.Writer.Flush()
+		if done || time.Since(lastProgress) >= idleTimeout {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sseResumeTailPollInterval):
+		}
+		var ok bool
+		events, done, ok = svc.LoadResumable(ctx, streamID, apiKeyID, lastSeq)
+		if !ok {
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type sseResumeHandlerTestCache struct {
+	mu      sync.Mutex
+	streams map[string]*service.SSEResumeSnapshot
+}
+
+func (m *sseResumeHandlerTestCache) InitStream(_ context.Context, id string, owner int64, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams[id] = &service.SSEResumeSnapshot{OwnerAPIKeyID: owner}
+	return nil
+}
+
+func (m *sseResumeHandlerTestCache) AppendEvents(_ context.Context, id string, events []service.SSEResumeEvent, _ int, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams[id].Events = append(m.streams[id].Events, events...)
+	return nil
+}
+
+func (m *sseResumeHandlerTestCache) FinishStream(_ context.Context, id string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams[id].Done = true
+	return nil
+}
+
+func (m *sseResumeHandlerTestCache) DeleteStream(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.streams, id)
+	return nil
+}
+
+func (m *sseResumeHandlerTestCache) LoadStream(_ context.Context, id string) (*service.SSEResumeSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap, ok := m.streams[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *snap
+	cp.Events = append([]service.SSEResumeEvent(nil), snap.Events...)
+	return &cp, nil
+}
+
+func TestSSEResumeMiddleware_AssignsIDsAndReplays(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.SSEResume = config.GatewaySSEResumeConfig{Enabled: true, BufferEvents: 100, TTLSeconds: 60, TailIdleTimeoutSeconds: 1}
+	svc := service.NewSSEResumeService(&sseResumeHandlerTestCache{streams: map[string]*service.SSEResumeSnapshot{}}, cfg)
+
+	generations := 0
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 5})
+		c.Next()
+	})
+	router.POST("/v1/messages", SSEResumeMiddleware(svc), func(c *gin.Context) {
+		generations++
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = io.WriteString(c.Writer, "event: message_start\ndata: {\"n\":1}\n")
+		_, _ = io.WriteString(c.Writer, "\n")
+		_, _ = io.WriteString(c.Writer, "id: upstream-1\ndata: {\"n\":2}\n\ndata: {\"n\":3}\n\n")
+		c.Writer.Flush()
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	ids := regexp.MustCompile(`(?m)^id: (\S+)$`).FindAllStringSubmatch(w.Body.String(), -1)
+	require.Len(t, ids, 3)
+	require.NotContains(t, w.Body.String(), "upstream-1", "upstream ids must be replaced")
+	streamID, seq, ok := service.ParseSSEResumeEventID(ids[0][1])
+	require.True(t, ok)
+	require.Equal(t, int64(1), seq)
+
+	// 等待后台写入完成
+	require.Eventually(t, func() bool {
+		_, done, ok := svc.LoadResumable(context.Background(), streamID, 5, 0)
+		return ok && done
+	}, 2*time.Second, 10*time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	req.Header.Set("Last-Event-ID", ids[0][1])
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, 1, generations, "resume must not start a new generation")
+	require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	require.Equal(t, "id: "+ids[1][1]+"\ndata: {\"n\":2}\n\nid: "+ids[2][1]+"\ndata: {\"n\":3}\n\n", w.Body.String())
+
+	// 其它 Key 或未知流按新请求处理
+	req = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	req.Header.Set("Last-Event-ID", service.FormatSSEResumeEventID(service.NewSSEResumeStreamID(), 1))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, 2, generations)
+	require.Equal(t, streamID, ids[2][1][:len(streamID)])
+}
+
+func TestSSEResumeMiddleware_NonStreamPassesThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.SSEResume = config.GatewaySSEResumeConfig{Enabled: true, BufferEvents: 100, TTLSeconds: 60, TailIdleTimeoutSeconds: 1}
+	svc := service.NewSSEResumeService(&sseResumeHandlerTestCache{streams: map[string]*service.SSEResumeSnapshot{}}, cfg)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 5})
+		c.Next()
+	})
+	router.POST("/v1/messages", SSEResumeMiddleware(svc), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.JSONEq(t, `{"ok":true}`, w.Body.String())
+}
+
+func TestSSEResumeMiddleware_CancelsGenerationAfterDisconnectGrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.SSEResume = config.GatewaySSEResumeConfig{Enabled: true, BufferEvents: 100, TTLSeconds: 60, TailIdleTimeoutSeconds: 1, DisconnectGraceSeconds: 1}
+	svc := service.NewSSEResumeService(&sseResumeHandlerTestCache{streams: map[string]*service.SSEResumeSnapshot{}}, cfg)
+
+	started := make(chan struct{})
+	causeCh := make(chan error, 1)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 5})
+		c.Next()
+	})
+	router.POST("/v1beta/models/*modelAction", SSEResumeMiddleware(svc), func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		close(started)
+		ctx := c.Request.Context()
+		<-ctx.Done()
+		causeCh <- context.Cause(ctx)
+	})
+
+	clientCtx, disconnect := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", nil).WithContext(clientCtx)
+	go router.ServeHTTP(httptest.NewRecorder(), req)
+	<-started
+
+	disconnectedAt := time.Now()
+	disconnect()
+	select {
+	case <-causeCh:
+		t.Fatal("generation must keep running during the disconnect grace period")
+	case <-time.After(300 * time.Millisecond):
+	}
+	select {
+	case cause := <-causeCh:
+		require.ErrorIs(t, cause, service.ErrClientDisconnected)
+		require.GreaterOrEqual(t, time.Since(disconnectedAt), time.Second)
+	case <-time.After(3 * time.Second):
+		t.Fatal("generation must be cancelled once the disconnect grace period ends")
+	}
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+
+	coderws "github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+)
+
+// SSEWebSocketBridgePrefix 桥接路由前缀：/ws/v1/messages 对应 /v1/messages
+const SSEWebSocketBridgePrefix = "/ws"
+
+var errSSEWSBridgeHijack = errors.New("sse websocket bridge: connection already upgraded")
+
+// sseWSBridgeWriter 把处理器写出的响应转成 WebSocket 消息。
+// text/event-stream 响应按事件拆分，每个事件的 data 作为一条文本消息（注释/心跳行丢弃）；
+// 其他响应（非流式结果、错误）在处理结束后整体作为一条消息发送。
+type sseWSBridgeWriter struct {
+	gin.ResponseWriter
+	conn         *coderws.Conn
+	writeTimeout time.Duration
+
+	header  http.Header
+	status  int
+	size    int
+	decided bool
+	stream  bool
+	pending []byte
+	body    []byte
+	err     error
+}
+
+func newSSEWSBridgeWriter(rw gin.ResponseWriter, conn *coderws.Conn, writeTimeout time.Duration) *sseWSBridgeWriter {
+	return &sseWSBridgeWriter{
+		ResponseWriter: rw,
+		conn:           conn,
+		writeTimeout:   writeTimeout,
+		header:         make(http.Header),
+		size:           -1,
+	}
+}
+
+func (w *sseWSBridgeWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *sseWSBridgeWriter) WriteHeader(code int) {
+	if code <= 0 || w.Written() {
+		return
+	}
+	w.status = code
+}
+
+func (w *sseWSBridgeWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+	}
+}
+
+func (w *sseWSBridgeWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *sseWSBridgeWriter) Size() int {
+	return w.size
+}
+
+func (w *sseWSBridgeWriter) Written() bool {
+	return w.size != -1
+}
+
+func (w *sseWSBridgeWriter) Flush() {}
+
+func (w *sseWSBridgeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errSSEWSBridgeHijack
+}
+
+func (w *sseWSBridgeWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.WriteHeaderNow()
+	if !w.decided {
+		w.decided = true
+		w.stream = strings.HasPrefix(strings.ToLower(w.header.Get("Content-Type")), "text/event-stream")
+	}
+	w.size += len(b)
+	if !w.stream {
+		w.body = append(w.body, b...)
+		return len(b), nil
+	}
+	w.pending = append(w.pending, b...)
+	for {
+		idx := bytes.Index(w.pending, []byte("\n\n"))
+		if idx < 0 {
+			break
+		}
+		data, ok := sseEventData(w.pending[:idx])
+		w.pending = w.pending[idx+2:]
+		if !ok {
+			continue
+		}
+		if err := w.send(data); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *sseWSBridgeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *sseWSBridgeWriter) send(payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.writeTimeout)
+	defer cancel()
+	if err := w.conn.Write(ctx, coderws.MessageText, payload); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// finish 发送残留内容并按响应状态关闭连接：成功为 1000，HTTP 错误为 4000+状态码（如 4429）。
+func (w *sseWSBridgeWriter) finish() {
+	if w.err != nil {
+		return
+	}
+	if w.stream && len(bytes.TrimSpace(w.pending)) > 0 {
+		if data, ok := sseEventData(w.pending); ok {
+			_ = w.send(data)
+		}
+	}
+	if !w.stream && len(w.body) > 0 {
+		_ = w.send(w.body)
+	}
+	if w.err != nil {
+		return
+	}
+	status := w.Status()
+	if status >= http.StatusBadRequest {
+		_ = w.conn.Close(coderws.StatusCode(4000+status), http.StatusText(status))
+		return
+	}
+	_ = w.conn.Close(coderws.StatusNormalClosure, "")
+}
+
+// sseEventData 提取单个 SSE 事件的 data（多行 data 以换行拼接）；无 data 的事件（注释、心跳）返回 false。
+func sseEventData(event []byte) ([]byte, bool) {
+	var (
+		data  []byte
+		found bool
+	)
+	for _, line := range bytes.Split(event, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue
+		}
+		value := bytes.TrimPrefix(line[len("data:"):], []byte(" "))
+		if found {
+			data = append(data, '\n')
+		}
+		data = append(data, value...)
+		found = true
+	}
+	return data, found
+}
+
+// SSEWebSocketBridgeMiddleware 将 /ws 前缀下的 WebSocket 连接桥接为对应的流式 POST 请求。
+// 首条客户端消息作为请求体，请求路径去掉 /ws 前缀后交给后续中间件与处理器，
+// 因此认证、并发控制、调度与用量计费与普通请求完全一致。
+// 需挂在网关中间件链之前（认证失败等错误作为一条消息返回后关闭连接）；maxMessageSize 限制首条消息大小。
+func SSEWebSocketBridgeMiddleware(cfg config.GatewaySSEWebSocketBridgeConfig, maxMessageSize int64) gin.HandlerFunc {
+	firstMessageTimeout := time.Duration(cfg.FirstMessageTimeoutSeconds) * time.Second
+	writeTimeout := time.Duration(cfg.WriteTimeoutSeconds) * time.Second
+	return func(c *gin.Context) {
+		if !strings.EqualFold(strings.TrimSpace(c.GetHeader("Upgrade")), "websocket") {
+			c.AbortWithStatusJSON(http.StatusUpgradeRequired, gin.H{
+				"error": gin.H{
+					"type":    "invalid_request_error",
+					"message": "WebSocket upgrade required",
+				},
+			})
+			return
+		}
+
+		conn, err := coderws.Accept(c.Writer, c.Request, &coderws.AcceptOptions{
+			CompressionMode: coderws.CompressionContextTakeover,
+		})
+		if err != nil {
+			c.Abort()
+			return
+		}
+		defer func() {
+			_ = conn.CloseNow()
+		}()
+		if maxMessageSize > 0 {
+			conn.SetReadLimit(maxMessageSize)
+		}
+
+		readCtx, cancel := context.WithTimeout(c.Request.Context(), firstMessageTimeout)
+		_, body, err := conn.Read(readCtx)
+		cancel()
+		if err != nil {
+			_ = conn.Close(coderws.StatusPolicyViolation, "request body message required")
+			c.Abort()
+			return
+		}
+
+		// 首条消息之后不再接收客户端数据；连接关闭时 ctx 被取消，处理器据此感知客户端断开。
+		ctx := conn.CloseRead(c.Request.Context())
+		c.Request = buildSSEWSBridgeRequest(c.Request.WithContext(ctx), body)
+
+		originalWriter := c.Writer
+		writer := newSSEWSBridgeWriter(originalWriter, conn, writeTimeout)
+		c.Writer = writer
+		c.Next()
+		writer.finish()
+		if c.Writer == writer {
+			c.Writer = originalWriter
+		}
+	}
+}
+
+// buildSSEWSBridgeRequest 以首条消息为请求体构造等价的 POST 请求。
+func buildSSEWSBridgeRequest(r *http.Request, body []byte) *http.Request {
+	req := r.Clone(r.Context())
+	req.Method = http.MethodPost
+	req.URL.Path = strings.TrimPrefix(req.URL.Path, SSEWebSocketBridgePrefix)
+	req.URL.RawPath = ""
+	req.RequestURI = req.URL.RequestURI()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", "application/json")
+	for _, key := range []string{"Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions", "Sec-Websocket-Protocol"} {
+		req.Header.Del(key)
+	}
+	return req
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	coderws "github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newSSEWSBridgeTestServer(t *testing.T, handle gin.HandlerFunc) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	bridge := SSEWebSocketBridgeMiddleware(config.GatewaySSEWebSocketBridgeConfig{
+		Enabled:                    true,
+		FirstMessageTimeoutSeconds: 5,
+		WriteTimeoutSeconds:        5,
+	}, 1<<20)
+	router.GET("/ws/v1/messages", bridge, handle)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/v1/messages"
+}
+
+func TestSSEWebSocketBridge_StreamsEventDataAsMessages(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+	url := newSSEWSBridgeTestServer(t, func(c *gin.Context) {
+		gotMethod = c.Request.Method
+		gotPath = c.Request.URL.Path
+		body, _ := io.ReadAll(c.Request.Body)
+		gotBody = string(body)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("event: message_start\ndata: {\"type\":\"message_start\"}\n\n: ping\n\n")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("event: content_block_delta\ndata: {\"type\":\"content_")
+		_, _ = c.Writer.WriteString("block_delta\"}\n\ndata: [DONE]\n\n")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := coderws.Dial(ctx, url, nil)
+	require.NoError(t, err)
+	defer func() { _ = conn.CloseNow() }()
+
+	require.NoError(t, conn.Write(ctx, coderws.MessageText, []byte(`{"model":"claude-sonnet-4-5","stream":true}`)))
+
+	var messages []string
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			require.Equal(t, coderws.StatusNormalClosure, coderws.CloseStatus(err))
+			break
+		}
+		messages = append(messages, string(data))
+	}
+
+	require.Equal(t, http.MethodPost, gotMethod)
+	require.Equal(t, "/v1/messages", gotPath)
+	require.Equal(t, `{"model":"claude-sonnet-4-5","stream":true}`, gotBody)
+	require.Equal(t, []string{
+		`{"type":"message_start"}`,
+		`{"type":"content_block_delta"}`,
+		`[DONE]`,
+	}, messages)
+}
+
+func TestSSEWebSocketBridge_ErrorResponseClosesWithStatusCode(t *testing.T) {
+	url := newSSEWSBridgeTestServer(t, func(c *gin.Context) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": gin.H{"type": "rate_limit_error"}})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := coderws.Dial(ctx, url, nil)
+	require.NoError(t, err)
+	defer func() { _ = conn.CloseNow() }()
+
+	require.NoError(t, conn.Write(ctx, coderws.MessageText, []byte(`{}`)))
+	_, data, err := conn.Read(ctx)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"error":{"type":"rate_limit_error"}}`, string(data))
+
+	_, _, err = conn.Read(ctx)
+	require.Equal(t, coderws.StatusCode(4429), coderws.CloseStatus(err))
+}
+
+func TestSSEWebSocketBridge_RequiresUpgrade(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	called := false
+	router.GET("/ws/v1/messages", SSEWebSocketBridgeMiddleware(config.GatewaySSEWebSocketBridgeConfig{
+		FirstMessageTimeoutSeconds: 1,
+		WriteTimeoutSeconds:        1,
+	}, 0), func(c *gin.Context) { called = true })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws/v1/messages", nil))
+	require.Equal(t, http.StatusUpgradeRequired, w.Code)
+	require.False(t, called)
+}
+
+func TestSSEEventData(t *testing.T) {
+	data, ok := sseEventData([]byte("event: x\r\ndata: line1\r\ndata:line2"))
+	require.True(t, ok)
+	require.Equal(t, "line1\nline2", string(data))
+
+	_, ok = sseEventData([]byte(": keepalive"))
+	require.False(t, ok)
+}
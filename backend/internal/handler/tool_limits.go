@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ToolLimitsMiddleware 按 API Key 所属分组平台的上限（gateway.tool_limits）检查客户端 tools 定义的数量、单个与合计大小、
+// 描述长度，超限时返回指明具体工具（序号 / 名称 / 路径）的 400；描述超长且策略为 truncate 时截断后放行，
+// 并通过 X-Sub2API-Truncated-Tools 响应头告知被截断的工具数。需挂在 API Key 认证与严格请求校验之后。
+func ToolLimitsMiddleware(cfg *config.Config, writeError middleware2.GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || c.IsWebsocket() || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		format := service.RequestValidationFormatForPath(c.Request.URL.Path)
+		if format == "" {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey.Group == nil {
+			c.Next()
+			return
+		}
+		limit, ok := service.ToolLimitFor(cfg, apiKey.Group.Platform)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(c, http.StatusRequestEntityTooLarge, "Request body too large")
+			} else {
+				writeError(c, http.StatusBadRequest, "Failed to read request body")
+			}
+			c.Abort()
+			return
+		}
+		result, err := service.ApplyToolLimits(body, format, limit, cfg.Gateway.ToolLimits.DescriptionPolicy)
+		if err != nil {
+			logger.L().Warn("tool_limits.apply_failed", zap.Error(err))
+			policyplugin.SetRequestBody(c.Request, body)
+			c.Next()
+			return
+		}
+		if result.Violation != nil {
+			writeToolLimitError(c, format, result.Violation)
+			c.Abort()
+			return
+		}
+		if result.TruncatedTools > 0 {
+			c.Header(service.ToolsTruncatedHeader, strconv.Itoa(result.TruncatedTools))
+		}
+		policyplugin.SetRequestBody(c.Request, result.Body)
+		c.Next()
+	}
+}
+
+// writeToolLimitError 按端点协议的错误格式输出工具上限拒绝，附带超限工具的结构化信息。
+func writeToolLimitError(c *gin.Context, format string, violation *service.ToolLimitViolation) {
+	message := violation.Message()
+	switch format {
+	case service.RequestValidationFormatGemini:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    http.StatusBadRequest,
+				"message": message,
+				"status":  "INVALID_ARGUMENT",
+				"details": []gin.H{{
+					"@type":           "type.googleapis.com/google.rpc.BadRequest",
+					"fieldViolations": []gin.H{{"field": violation.Path, "description": message}},
+				}},
+			},
+		})
+	case service.ResponseLanguageFormatAnthropic:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"type": "error",
+			"error": gin.H{
+				"type":    "invalid_request_error",
+				"message": message,
+				"tool":    violation,
+			},
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"type":    "invalid_request_error",
+				"code":    "tool_limit_exceeded",
+				"param":   violation.Path,
+				"message": message,
+				"tool":    violation,
+			},
+		})
+	}
+}
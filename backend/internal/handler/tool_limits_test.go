@@ -0,0 +1,92 @@
+//go:build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func runToolLimits(t *testing.T, cfg *config.Config, platform, path, body string) (*httptest.ResponseRecorder, string, bool) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(middleware.ContextKeyAPIKey), &service.APIKey{Group: &service.Group{Platform: platform}})
+		c.Next()
+	})
+	router.Use(ToolLimitsMiddleware(cfg, middleware.AnthropicErrorWriter))
+	reached := false
+	var forwarded string
+	router.POST("/*path", func(c *gin.Context) {
+		reached = true
+		data, _ := io.ReadAll(c.Request.Body)
+		forwarded = string(data)
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return w, forwarded, reached
+}
+
+func newToolLimitsConfig(policy string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Gateway.ToolLimits = config.GatewayToolLimitsConfig{
+		Enabled:           true,
+		DescriptionPolicy: policy,
+		Platforms: map[string]config.GatewayToolLimit{
+			service.PlatformOpenAI: {MaxTools: 2, MaxDescriptionChars: 8},
+		},
+	}
+	return cfg
+}
+
+func TestToolLimitsMiddleware_RejectsWithOffendingTool(t *testing.T) {
+	body := `{"model":"gpt-5","messages":[],"tools":[{"type":"function","function":{"name":"lookup","description":"a very long description"}}]}`
+
+	w, _, reached := runToolLimits(t, newToolLimitsConfig(config.ToolDescriptionPolicyReject), service.PlatformOpenAI, "/v1/chat/completions", body)
+	require.False(t, reached)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp struct {
+		Error struct {
+			Code  string                     `json:"code"`
+			Param string                     `json:"param"`
+			Tool  service.ToolLimitViolation `json:"tool"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "tool_limit_exceeded", resp.Error.Code)
+	require.Equal(t, "tools.0.function.description", resp.Error.Param)
+	require.Equal(t, "lookup", resp.Error.Tool.Name)
+	require.Equal(t, service.ToolLimitReasonDescriptionTooLong, resp.Error.Tool.Reason)
+}
+
+func TestToolLimitsMiddleware_TruncatesDescriptionAndSetsHeader(t *testing.T) {
+	body := `{"model":"claude-sonnet-4","max_tokens":10,"messages":[],"tools":[{"name":"lookup","description":"a very long description"}]}`
+
+	w, forwarded, reached := runToolLimits(t, newToolLimitsConfig(config.ToolDescriptionPolicyTruncate), service.PlatformOpenAI, "/v1/messages", body)
+	require.True(t, reached)
+	require.Equal(t, "1", w.Header().Get(service.ToolsTruncatedHeader))
+	require.Equal(t, "a very l", gjson.Get(forwarded, "tools.0.description").String())
+}
+
+func TestToolLimitsMiddleware_SkipsUnconfiguredPlatform(t *testing.T) {
+	body := `{"model":"claude-sonnet-4","messages":[],"tools":[{"name":"a"},{"name":"b"},{"name":"c"}]}`
+
+	w, forwarded, reached := runToolLimits(t, newToolLimitsConfig(config.ToolDescriptionPolicyReject), service.PlatformAnthropic, "/v1/messages", body)
+	require.True(t, reached)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, body, forwarded)
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpstreamStreamCaptureMiddleware 为命中留存条件的网关请求挂载上游原始响应缓冲，请求结束后提交留存服务。
+// 需挂在 API Key 认证之后；WebSocket 请求不留存。
+func UpstreamStreamCaptureMiddleware(svc *service.UpstreamStreamCaptureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !svc.Enabled() || c.IsWebsocket() {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		capture := svc.Begin(apiKey)
+		if !ok || capture == nil {
+			c.Next()
+			return
+		}
+
+		c.Request = c.Request.WithContext(service.WithUpstreamStreamCapture(c.Request.Context(), capture))
+		c.Next()
+
+		ctx := c.Request.Context()
+		record := &service.UpstreamStreamCaptureRecord{
+			RequestID:  service.UsageRequestIDFromContext(ctx),
+			Endpoint:   GetInboundEndpoint(c),
+			StatusCode: c.Writer.Status(),
+		}
+		if accountID, ok := ctx.Value(ctxkey.AccountID).(int64); ok && accountID > 0 {
+			record.AccountID = &accountID
+		}
+		record.Platform, _ = ctx.Value(ctxkey.Platform).(string)
+		svc.Submit(capture, apiKey, record)
+	}
+}
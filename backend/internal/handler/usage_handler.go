@@ -227,12 +227,17 @@ func (h *UsageHandler) List(c *gin.Context) {
 	if !ok {
 		return
 	}
+	cursor, ok := response.ParseCursor(c)
+	if !ok {
+		return
+	}
 
 	params := pagination.PaginationParams{
 		Page:      page,
 		PageSize:  pageSize,
 		SortBy:    c.DefaultQuery("sort_by", "created_at"),
 		SortOrder: c.DefaultQuery("sort_order", "desc"),
+		Cursor:    cursor,
 	}
 
 	records, result, err := h.usageService.ListWithFilters(c.Request.Context(), params, parsed.Filters)
@@ -245,7 +250,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 	for i := range records {
 		out = append(out, *dto.UsageLogFromService(&records[i]))
 	}
-	response.Paginated(c, out, result.Total, page, pageSize)
+	response.PaginatedByMode(c, out, params, result)
 }
 
 // ListErrors handles listing the current user's failed requests (redacted).
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserNotificationHandler handles the end-user notification center
+type UserNotificationHandler struct {
+	notificationService *service.UserNotificationService
+}
+
+// NewUserNotificationHandler creates a new user notification handler
+func NewUserNotificationHandler(notificationService *service.UserNotificationService) *UserNotificationHandler {
+	return &UserNotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// List handles listing notifications of current user
+// GET /api/v1/notifications
+func (h *UserNotificationHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	unreadOnly := parseBoolQuery(c.Query("unread_only"))
+
+	result, err := h.notificationService.List(c.Request.Context(), subject.UserID, unreadOnly, page, pageSize)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	pages := 1
+	if result.PageSize > 0 && result.Total > result.PageSize {
+		pages = (result.Total + result.PageSize - 1) / result.PageSize
+	}
+	response.Success(c, gin.H{
+		"items":        result.Items,
+		"total":        result.Total,
+		"page":         result.Page,
+		"page_size":    result.PageSize,
+		"pages":        pages,
+		"unread_count": result.UnreadCount,
+	})
+}
+
+// UnreadCount returns the number of unread notifications of current user
+// GET /api/v1/notifications/unread-count
+func (h *UserNotificationHandler) UnreadCount(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	count, err := h.notificationService.UnreadCount(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"unread_count": count})
+}
+
+// MarkRead marks a notification as read for current user
+// POST /api/v1/notifications/:id/read
+func (h *UserNotificationHandler) MarkRead(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || notificationID <= 0 {
+		response.BadRequest(c, "Invalid notification ID")
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), subject.UserID, notificationID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "ok"})
+}
+
+// MarkAllRead marks all notifications of current user as read
+// POST /api/v1/notifications/read-all
+func (h *UserNotificationHandler) MarkAllRead(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"marked": marked})
+}
@@ -45,9 +45,35 @@ func ProvideAdminHandlers(
 	affiliateHandler *admin.AffiliateHandler,
 	complianceHandler *admin.ComplianceHandler,
 	auditLogHandler *admin.AuditLogHandler,
+	diagnosticsHandler *admin.DiagnosticsHandler,
+	billingAdjustmentHandler *admin.BillingAdjustmentHandler,
+	accountMetadataHandler *admin.AccountMetadataHandler,
+	credentialSourceHandler *admin.AccountCredentialSourceHandler,
+	accountSnapshotHandler *admin.AccountSnapshotHandler,
+	groupBudgetHandler *admin.GroupBudgetHandler,
+	activeRequestHandler *admin.ActiveRequestHandler,
+	featureFlagHandler *admin.FeatureFlagHandler,
+	tokenCacheHandler *admin.TokenCacheHandler,
+	streamCaptureHandler *admin.StreamCaptureHandler,
+	usageArchiveHandler *admin.UsageArchiveHandler,
+	modelAliasHandler *admin.ModelAliasHandler,
+	costAnomalyHandler *admin.CostAnomalyHandler,
+	accountSmokeTestHandler *admin.AccountSmokeTestHandler,
+	supportBundleHandler *admin.SupportBundleHandler,
 	upstreamBillingProbe *service.UpstreamBillingProbeService,
+	listVersion *service.AdminListVersionService,
+	attribution *service.AccountAttributionService,
+	usageRecost *service.UsageRecostService,
+	accountWarmup *service.AccountWarmupPolicy,
+	contentPolicy *service.ContentPolicyCooldownService,
 ) *AdminHandlers {
 	accountHandler.SetUpstreamBillingProbeService(upstreamBillingProbe)
+	accountHandler.SetListVersionService(listVersion)
+	accountHandler.SetAttributionService(attribution)
+	accountHandler.SetAccountWarmupPolicy(accountWarmup)
+	accountHandler.SetContentPolicyCooldownService(contentPolicy)
+	usageHandler.SetListVersionService(listVersion)
+	usageHandler.SetUsageRecostService(usageRecost)
 	return &AdminHandlers{
 		Dashboard:              dashboardHandler,
 		User:                   userHandler,
@@ -83,6 +109,21 @@ func ProvideAdminHandlers(
 		Affiliate:              affiliateHandler,
 		Compliance:             complianceHandler,
 		AuditLog:               auditLogHandler,
+		Diagnostics:            diagnosticsHandler,
+		BillingAdjustment:      billingAdjustmentHandler,
+		AccountMetadata:        accountMetadataHandler,
+		CredentialSource:       credentialSourceHandler,
+		AccountSnapshot:        accountSnapshotHandler,
+		GroupBudget:            groupBudgetHandler,
+		ActiveRequest:          activeRequestHandler,
+		FeatureFlag:            featureFlagHandler,
+		TokenCache:             tokenCacheHandler,
+		StreamCapture:          streamCaptureHandler,
+		UsageArchive:           usageArchiveHandler,
+		ModelAlias:             modelAliasHandler,
+		CostAnomaly:            costAnomalyHandler,
+		AccountSmokeTest:       accountSmokeTestHandler,
+		SupportBundle:          supportBundleHandler,
 	}
 }
 
@@ -103,11 +144,13 @@ func ProvideGatewayHandler(
 	cfg *config.Config,
 	settingService *service.SettingService,
 	coordinator *securityaudit.Coordinator,
+	transcriptService *service.ConversationTranscriptService,
 ) *GatewayHandler {
 	h := NewGatewayHandler(gatewayService, openAIGatewayService, geminiCompatService, antigravityGatewayService,
 		userService, concurrencyService, billingCacheService, usageService, apiKeyService, usageRecordWorkerPool,
 		errorPassthroughService, contentModerationService, userMsgQueueService, cfg, settingService)
 	h.securityAuditCoordinator = coordinator
+	h.transcriptService = transcriptService
 	return h
 }
 
@@ -123,11 +166,13 @@ func ProvideOpenAIGatewayHandler(
 	grokQuotaService *service.GrokQuotaService,
 	cfg *config.Config,
 	coordinator *securityaudit.Coordinator,
+	transcriptService *service.ConversationTranscriptService,
 ) *OpenAIGatewayHandler {
 	h := NewOpenAIGatewayHandler(gatewayService, concurrencyService, billingCacheService, apiKeyService,
 		usageRecordWorkerPool, errorPassthroughService, contentModerationService, opsService, cfg)
 	h.securityAuditCoordinator = coordinator
 	h.grokMediaEligibilityProber = grokQuotaService
+	h.transcriptService = transcriptService
 	return h
 }
 
@@ -155,10 +200,11 @@ func ProvideSettingHandler(settingService *service.SettingService, buildInfo Bui
 }
 
 // ProvideAdminSettingHandler creates admin.SettingHandler with notification template APIs.
-func ProvideAdminSettingHandler(settingService *service.SettingService, emailService *service.EmailService, turnstileService *service.TurnstileService, opsService *service.OpsService, paymentConfigService *service.PaymentConfigService, paymentService *service.PaymentService, userAttributeService *service.UserAttributeService, notificationEmailService *service.NotificationEmailService, totpService *service.TotpService, userService *service.UserService) *admin.SettingHandler {
+func ProvideAdminSettingHandler(settingService *service.SettingService, emailService *service.EmailService, turnstileService *service.TurnstileService, opsService *service.OpsService, paymentConfigService *service.PaymentConfigService, paymentService *service.PaymentService, userAttributeService *service.UserAttributeService, notificationEmailService *service.NotificationEmailService, totpService *service.TotpService, userService *service.UserService, governanceService *service.SettingGovernanceService) *admin.SettingHandler {
 	h := admin.NewSettingHandler(settingService, emailService, turnstileService, opsService, paymentConfigService, paymentService, userAttributeService)
 	h.SetNotificationEmailService(notificationEmailService)
 	h.SetStepUpDeps(totpService, userService)
+	h.SetGovernanceService(governanceService)
 	return h
 }
 
@@ -171,6 +217,7 @@ func ProvideHandlers(
 	redeemHandler *RedeemHandler,
 	subscriptionHandler *SubscriptionHandler,
 	announcementHandler *AnnouncementHandler,
+	notificationHandler *UserNotificationHandler,
 	channelMonitorUserHandler *ChannelMonitorUserHandler,
 	adminHandlers *AdminHandlers,
 	gatewayHandler *GatewayHandler,
@@ -182,6 +229,10 @@ func ProvideHandlers(
 	availableChannelHandler *AvailableChannelHandler,
 	asyncImageHandler *AsyncImageHandler,
 	batchImageHandler *BatchImageHandler,
+	transcriptHandler *ConversationTranscriptHandler,
+	taskHistoryHandler *TaskHistoryHandler,
+	adjustmentHandler *BillingAdjustmentHandler,
+	autoscalingHandler *AutoscalingMetricsHandler,
 	_ *service.IdempotencyCoordinator,
 	_ *service.IdempotencyCleanupService,
 ) *Handlers {
@@ -193,6 +244,7 @@ func ProvideHandlers(
 		Redeem:           redeemHandler,
 		Subscription:     subscriptionHandler,
 		Announcement:     announcementHandler,
+		Notification:     notificationHandler,
 		ChannelMonitor:   channelMonitorUserHandler,
 		Admin:            adminHandlers,
 		Gateway:          gatewayHandler,
@@ -204,6 +256,10 @@ func ProvideHandlers(
 		AvailableChannel: availableChannelHandler,
 		AsyncImage:       asyncImageHandler,
 		BatchImage:       batchImageHandler,
+		Transcript:       transcriptHandler,
+		TaskHistory:      taskHistoryHandler,
+		Adjustment:       adjustmentHandler,
+		Autoscaling:      autoscalingHandler,
 	}
 }
 
@@ -217,6 +273,11 @@ var ProviderSet = wire.NewSet(
 	NewRedeemHandler,
 	NewSubscriptionHandler,
 	NewAnnouncementHandler,
+	NewUserNotificationHandler,
+	NewConversationTranscriptHandler,
+	NewTaskHistoryHandler,
+	NewBillingAdjustmentHandler,
+	NewAutoscalingMetricsHandler,
 	NewChannelMonitorUserHandler,
 	ProvideGatewayHandler,
 	ProvideOpenAIGatewayHandler,
@@ -262,6 +323,21 @@ var ProviderSet = wire.NewSet(
 	admin.NewAffiliateHandler,
 	admin.NewComplianceHandler,
 	admin.NewAuditLogHandler,
+	admin.NewDiagnosticsHandler,
+	admin.NewBillingAdjustmentHandler,
+	admin.NewAccountMetadataHandler,
+	admin.NewAccountCredentialSourceHandler,
+	admin.NewAccountSnapshotHandler,
+	admin.NewGroupBudgetHandler,
+	admin.NewActiveRequestHandler,
+	admin.NewStreamCaptureHandler,
+	admin.NewUsageArchiveHandler,
+	admin.NewModelAliasHandler,
+	admin.NewCostAnomalyHandler,
+	admin.NewAccountSmokeTestHandler,
+	admin.NewSupportBundleHandler,
+	admin.NewFeatureFlagHandler,
+	admin.NewTokenCacheHandler,
 
 	// AdminHandlers and Handlers constructors
 	ProvideAdminHandlers,
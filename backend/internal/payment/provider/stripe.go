@@ -16,6 +16,21 @@ import (
 const (
 	stripeEventPaymentSuccess = "payment_intent.succeeded"
 	stripeEventPaymentFailed  = "payment_intent.payment_failed"
+
+	stripeEventCheckoutCompleted      = "checkout.session.completed"
+	stripeEventCheckoutAsyncSucceeded = "checkout.session.async_payment_succeeded"
+	stripeEventCheckoutAsyncFailed    = "checkout.session.async_payment_failed"
+)
+
+// Stripe checkout modes (instance config key "checkoutMode").
+//
+// payment_intent（默认）由前端 Payment Element 完成支付；checkout_session 创建
+// Stripe 托管收银台会话并返回跳转链接，无需在站内嵌入 Stripe.js。
+const (
+	stripeCheckoutModePaymentIntent = "payment_intent"
+	stripeCheckoutModeSession       = "checkout_session"
+
+	stripeCheckoutSessionIDPrefix = "cs_"
 )
 
 // Stripe implements the payment.CancelableProvider interface for Stripe payments.
@@ -39,6 +54,12 @@ func NewStripe(instanceID string, config map[string]string) (*Stripe, error) {
 		return nil, fmt.Errorf("stripe config currency: %w", err)
 	}
 	cfg["currency"] = currency
+	switch mode := strings.TrimSpace(cfg["checkoutMode"]); mode {
+	case "", stripeCheckoutModePaymentIntent, stripeCheckoutModeSession:
+		cfg["checkoutMode"] = mode
+	default:
+		return nil, fmt.Errorf("stripe config checkoutMode: unsupported value %q", mode)
+	}
 	return &Stripe{
 		instanceID: instanceID,
 		config:     cfg,
@@ -83,6 +104,14 @@ func (s *Stripe) currency() string {
 	return currency
 }
 
+func (s *Stripe) useCheckoutSession() bool {
+	return s.config["checkoutMode"] == stripeCheckoutModeSession
+}
+
+func isStripeCheckoutSessionID(tradeNo string) bool {
+	return strings.HasPrefix(strings.TrimSpace(tradeNo), stripeCheckoutSessionIDPrefix)
+}
+
 // stripePaymentMethodTypes maps our PaymentType to Stripe payment_method_types.
 var stripePaymentMethodTypes = map[string][]string{
 	payment.TypeCard:   {"card"},
@@ -103,6 +132,9 @@ func (s *Stripe) CreatePayment(ctx context.Context, req payment.CreatePaymentReq
 
 	// Collect all Stripe payment_method_types from the instance's configured sub-methods
 	methods := resolveStripeMethodTypes(req.InstanceSubMethods)
+	if s.useCheckoutSession() {
+		return s.createCheckoutSession(ctx, req, currency, amountInMinorUnit, methods)
+	}
 
 	pmTypes := make([]*string, len(methods))
 	for i, m := range methods {
@@ -141,10 +173,74 @@ func (s *Stripe) CreatePayment(ctx context.Context, req payment.CreatePaymentReq
 	}, nil
 }
 
-// QueryOrder retrieves a PaymentIntent by ID.
+// createCheckoutSession creates a hosted Stripe Checkout Session and returns its URL.
+// The underlying PaymentIntent carries the same orderId metadata, so both
+// payment_intent.* and checkout.session.* webhooks resolve to the same order.
+func (s *Stripe) createCheckoutSession(ctx context.Context, req payment.CreatePaymentRequest, currency string, amountInMinorUnit int64, methods []string) (*payment.CreatePaymentResponse, error) {
+	if strings.TrimSpace(req.ReturnURL) == "" {
+		return nil, fmt.Errorf("stripe create checkout session: return url is required")
+	}
+	productName := strings.TrimSpace(req.Subject)
+	if productName == "" {
+		productName = req.OrderID
+	}
+
+	pmTypes := make([]*string, len(methods))
+	for i, m := range methods {
+		pmTypes[i] = stripe.String(m)
+	}
+
+	params := &stripe.CheckoutSessionCreateParams{
+		Mode: stripe.String(string(stripe.CheckoutSessionModePayment)),
+		LineItems: []*stripe.CheckoutSessionCreateLineItemParams{{
+			PriceData: &stripe.CheckoutSessionCreateLineItemPriceDataParams{
+				Currency:    stripe.String(strings.ToLower(currency)),
+				UnitAmount:  stripe.Int64(amountInMinorUnit),
+				ProductData: &stripe.CheckoutSessionCreateLineItemPriceDataProductDataParams{Name: stripe.String(productName)},
+			},
+			Quantity: stripe.Int64(1),
+		}},
+		PaymentMethodTypes: pmTypes,
+		SuccessURL:         stripe.String(req.ReturnURL),
+		CancelURL:          stripe.String(req.ReturnURL),
+		ClientReferenceID:  stripe.String(req.OrderID),
+		Metadata:           map[string]string{"orderId": req.OrderID},
+		PaymentIntentData: &stripe.CheckoutSessionCreatePaymentIntentDataParams{
+			Description: stripe.String(req.Subject),
+			Metadata:    map[string]string{"orderId": req.OrderID},
+		},
+	}
+	if hasStripeMethod(methods, "wechat_pay") {
+		params.PaymentMethodOptions = &stripe.CheckoutSessionCreatePaymentMethodOptionsParams{
+			WeChatPay: &stripe.CheckoutSessionCreatePaymentMethodOptionsWeChatPayParams{
+				Client: stripe.String("web"),
+			},
+		}
+	}
+
+	params.SetIdempotencyKey(fmt.Sprintf("cs-%s", req.OrderID))
+	params.Context = ctx
+
+	cs, err := s.sc.V1CheckoutSessions.Create(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("stripe create checkout session: %w", err)
+	}
+
+	return &payment.CreatePaymentResponse{
+		TradeNo:  cs.ID,
+		PayURL:   cs.URL,
+		Currency: currency,
+	}, nil
+}
+
+// QueryOrder retrieves a PaymentIntent (or Checkout Session) by ID.
 func (s *Stripe) QueryOrder(ctx context.Context, tradeNo string) (*payment.QueryOrderResponse, error) {
 	s.ensureInit()
 
+	if isStripeCheckoutSessionID(tradeNo) {
+		return s.queryCheckoutSession(ctx, tradeNo)
+	}
+
 	pi, err := s.sc.V1PaymentIntents.Retrieve(ctx, tradeNo, nil)
 	if err != nil {
 		return nil, fmt.Errorf("stripe query order: %w", err)
@@ -169,6 +265,40 @@ func (s *Stripe) QueryOrder(ctx context.Context, tradeNo string) (*payment.Query
 	}, nil
 }
 
+func (s *Stripe) queryCheckoutSession(ctx context.Context, sessionID string) (*payment.QueryOrderResponse, error) {
+	cs, err := s.sc.V1CheckoutSessions.Retrieve(ctx, sessionID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("stripe query checkout session: %w", err)
+	}
+
+	status := payment.ProviderStatusPending
+	switch {
+	case cs.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid:
+		status = payment.ProviderStatusPaid
+	case cs.Status == stripe.CheckoutSessionStatusExpired:
+		status = payment.ProviderStatusFailed
+	}
+
+	currency := stripeIntentCurrency(cs.Currency, s.currency())
+	return &payment.QueryOrderResponse{
+		TradeNo: stripeCheckoutSessionTradeNo(cs),
+		Status:  status,
+		Amount:  payment.MinorUnitToAmount(cs.AmountTotal, currency),
+		Metadata: map[string]string{
+			"currency": currency,
+		},
+	}, nil
+}
+
+// stripeCheckoutSessionTradeNo prefers the PaymentIntent ID so that refunds
+// keep working against the stored trade number after the order is paid.
+func stripeCheckoutSessionTradeNo(cs *stripe.CheckoutSession) string {
+	if cs.PaymentIntent != nil && cs.PaymentIntent.ID != "" {
+		return cs.PaymentIntent.ID
+	}
+	return cs.ID
+}
+
 // VerifyNotification verifies a Stripe webhook event.
 func (s *Stripe) VerifyNotification(_ context.Context, rawBody string, headers map[string]string) (*payment.PaymentNotification, error) {
 	s.ensureInit()
@@ -193,6 +323,10 @@ func (s *Stripe) VerifyNotification(_ context.Context, rawBody string, headers m
 		return parseStripePaymentIntent(&event, payment.ProviderStatusSuccess, rawBody)
 	case stripeEventPaymentFailed:
 		return parseStripePaymentIntent(&event, payment.ProviderStatusFailed, rawBody)
+	case stripeEventCheckoutCompleted, stripeEventCheckoutAsyncSucceeded:
+		return parseStripeCheckoutSession(&event, payment.ProviderStatusSuccess, rawBody)
+	case stripeEventCheckoutAsyncFailed:
+		return parseStripeCheckoutSession(&event, payment.ProviderStatusFailed, rawBody)
 	}
 
 	return nil, nil
@@ -216,6 +350,49 @@ func parseStripePaymentIntent(event *stripe.Event, status string, rawBody string
 	}, nil
 }
 
+func parseStripeCheckoutSession(event *stripe.Event, status string, rawBody string) (*payment.PaymentNotification, error) {
+	var cs stripe.CheckoutSession
+	if err := json.Unmarshal(event.Data.Raw, &cs); err != nil {
+		return nil, fmt.Errorf("stripe parse checkout_session: %w", err)
+	}
+	// 延迟到账的支付方式在 completed 时仍为 unpaid，等待 async_payment_succeeded。
+	if status == payment.ProviderStatusSuccess && cs.PaymentStatus != stripe.CheckoutSessionPaymentStatusPaid {
+		return nil, nil
+	}
+	orderID := cs.Metadata["orderId"]
+	if orderID == "" {
+		orderID = cs.ClientReferenceID
+	}
+	currency := stripeIntentCurrency(cs.Currency, payment.DefaultPaymentCurrency)
+	return &payment.PaymentNotification{
+		TradeNo: stripeCheckoutSessionTradeNo(&cs),
+		OrderID: orderID,
+		Amount:  payment.MinorUnitToAmount(cs.AmountTotal, currency),
+		Status:  status,
+		RawData: rawBody,
+		Metadata: map[string]string{
+			"currency": currency,
+		},
+	}, nil
+}
+
+// resolveStripePaymentIntentID maps a Checkout Session ID to its PaymentIntent;
+// other trade numbers are returned unchanged.
+func (s *Stripe) resolveStripePaymentIntentID(ctx context.Context, tradeNo string) (string, error) {
+	tradeNo = strings.TrimSpace(tradeNo)
+	if !isStripeCheckoutSessionID(tradeNo) {
+		return tradeNo, nil
+	}
+	cs, err := s.sc.V1CheckoutSessions.Retrieve(ctx, tradeNo, nil)
+	if err != nil {
+		return "", err
+	}
+	if cs.PaymentIntent == nil || cs.PaymentIntent.ID == "" {
+		return "", fmt.Errorf("checkout session %s has no payment intent", tradeNo)
+	}
+	return cs.PaymentIntent.ID, nil
+}
+
 // Refund creates a Stripe refund.
 func (s *Stripe) Refund(ctx context.Context, req payment.RefundRequest) (*payment.RefundResponse, error) {
 	s.ensureInit()
@@ -225,8 +402,13 @@ func (s *Stripe) Refund(ctx context.Context, req payment.RefundRequest) (*paymen
 		return nil, fmt.Errorf("stripe refund: %w", err)
 	}
 
+	paymentIntentID, err := s.resolveStripePaymentIntentID(ctx, req.TradeNo)
+	if err != nil {
+		return nil, fmt.Errorf("stripe refund: %w", err)
+	}
+
 	params := &stripe.RefundCreateParams{
-		PaymentIntent: stripe.String(req.TradeNo),
+		PaymentIntent: stripe.String(paymentIntentID),
 		Amount:        stripe.Int64(amountInMinorUnit),
 		Reason:        stripe.String(string(stripe.RefundReasonRequestedByCustomer)),
 	}
@@ -265,6 +447,9 @@ func (s *Stripe) QueryRefund(ctx context.Context, req payment.RefundQueryRequest
 		if tradeNo == "" {
 			return nil, fmt.Errorf("stripe query refund: missing payment intent id")
 		}
+		if tradeNo, err = s.resolveStripePaymentIntentID(ctx, tradeNo); err != nil {
+			return nil, fmt.Errorf("stripe query refund: %w", err)
+		}
 		params := &stripe.RefundListParams{PaymentIntent: stripe.String(tradeNo)}
 		params.Limit = stripe.Int64(1)
 		list := s.sc.V1Refunds.List(ctx, params)
@@ -333,10 +518,17 @@ func hasStripeMethod(methods []string, target string) bool {
 	return false
 }
 
-// CancelPayment cancels a pending PaymentIntent.
+// CancelPayment cancels a pending PaymentIntent, or expires a pending Checkout Session.
 func (s *Stripe) CancelPayment(ctx context.Context, tradeNo string) error {
 	s.ensureInit()
 
+	if isStripeCheckoutSessionID(tradeNo) {
+		if _, err := s.sc.V1CheckoutSessions.Expire(ctx, tradeNo, nil); err != nil {
+			return fmt.Errorf("stripe expire checkout session: %w", err)
+		}
+		return nil
+	}
+
 	_, err := s.sc.V1PaymentIntents.Cancel(ctx, tradeNo, nil)
 	if err != nil {
 		return fmt.Errorf("stripe cancel payment: %w", err)
@@ -0,0 +1,160 @@
+//go:build unit
+
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/stretchr/testify/require"
+	stripe "github.com/stripe/stripe-go/v85"
+	"github.com/stripe/stripe-go/v85/webhook"
+)
+
+const stripeTestWebhookSecret = "whsec_test"
+
+func newStripeTestProvider(t *testing.T, extra map[string]string) *Stripe {
+	t.Helper()
+	cfg := map[string]string{
+		"secretKey":     "sk_test_123",
+		"webhookSecret": stripeTestWebhookSecret,
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	s, err := NewStripe("1", cfg)
+	require.NoError(t, err)
+	return s
+}
+
+func signedStripeEvent(t *testing.T, eventType string, object map[string]any) (string, map[string]string) {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{
+		"id":          "evt_test",
+		"object":      "event",
+		"type":        eventType,
+		"api_version": stripe.APIVersion,
+		"data":        map[string]any{"object": object},
+	})
+	require.NoError(t, err)
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload:   body,
+		Secret:    stripeTestWebhookSecret,
+		Timestamp: time.Now(),
+	})
+	return string(body), map[string]string{"stripe-signature": signed.Header}
+}
+
+func TestNewStripeValidatesCheckoutMode(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStripe("1", map[string]string{"secretKey": "sk_test_123", "checkoutMode": "hosted"})
+	require.ErrorContains(t, err, "checkoutMode")
+
+	s := newStripeTestProvider(t, map[string]string{"checkoutMode": " checkout_session "})
+	require.True(t, s.useCheckoutSession())
+
+	s = newStripeTestProvider(t, nil)
+	require.False(t, s.useCheckoutSession())
+}
+
+func TestStripeVerifyNotificationRejectsInvalidSignature(t *testing.T) {
+	t.Parallel()
+
+	s := newStripeTestProvider(t, nil)
+	body, _ := signedStripeEvent(t, stripeEventCheckoutCompleted, map[string]any{"id": "cs_test_1"})
+
+	_, err := s.VerifyNotification(t.Context(), body, map[string]string{"stripe-signature": "t=1,v1=deadbeef"})
+	require.Error(t, err)
+
+	_, err = s.VerifyNotification(t.Context(), body, map[string]string{})
+	require.ErrorContains(t, err, "stripe-signature")
+}
+
+func TestStripeVerifyNotificationCheckoutSessionCompleted(t *testing.T) {
+	t.Parallel()
+
+	s := newStripeTestProvider(t, map[string]string{"checkoutMode": stripeCheckoutModeSession})
+	body, headers := signedStripeEvent(t, stripeEventCheckoutCompleted, map[string]any{
+		"id":                  "cs_test_1",
+		"object":              "checkout.session",
+		"amount_total":        1234,
+		"currency":            "usd",
+		"client_reference_id": "sub2_ref",
+		"metadata":            map[string]string{"orderId": "sub2_order"},
+		"payment_intent":      "pi_test_1",
+		"payment_status":      "paid",
+		"status":              "complete",
+	})
+
+	n, err := s.VerifyNotification(t.Context(), body, headers)
+	require.NoError(t, err)
+	require.NotNil(t, n)
+	require.Equal(t, "pi_test_1", n.TradeNo)
+	require.Equal(t, "sub2_order", n.OrderID)
+	require.Equal(t, payment.ProviderStatusSuccess, n.Status)
+	require.InDelta(t, 12.34, n.Amount, 1e-9)
+	require.Equal(t, "USD", n.Metadata["currency"])
+}
+
+func TestStripeVerifyNotificationCheckoutSessionUnpaidIgnored(t *testing.T) {
+	t.Parallel()
+
+	s := newStripeTestProvider(t, nil)
+	body, headers := signedStripeEvent(t, stripeEventCheckoutCompleted, map[string]any{
+		"id":                  "cs_test_2",
+		"object":              "checkout.session",
+		"amount_total":        500,
+		"currency":            "usd",
+		"client_reference_id": "sub2_order",
+		"payment_status":      "unpaid",
+		"status":              "complete",
+	})
+
+	n, err := s.VerifyNotification(t.Context(), body, headers)
+	require.NoError(t, err)
+	require.Nil(t, n)
+}
+
+func TestStripeVerifyNotificationCheckoutSessionAsyncFailed(t *testing.T) {
+	t.Parallel()
+
+	s := newStripeTestProvider(t, nil)
+	body, headers := signedStripeEvent(t, stripeEventCheckoutAsyncFailed, map[string]any{
+		"id":                  "cs_test_3",
+		"object":              "checkout.session",
+		"amount_total":        500,
+		"currency":            "usd",
+		"client_reference_id": "sub2_order",
+		"payment_status":      "unpaid",
+	})
+
+	n, err := s.VerifyNotification(t.Context(), body, headers)
+	require.NoError(t, err)
+	require.NotNil(t, n)
+	require.Equal(t, "cs_test_3", n.TradeNo)
+	require.Equal(t, "sub2_order", n.OrderID)
+	require.Equal(t, payment.ProviderStatusFailed, n.Status)
+}
+
+func TestStripeVerifyNotificationPaymentIntentSucceeded(t *testing.T) {
+	t.Parallel()
+
+	s := newStripeTestProvider(t, nil)
+	body, headers := signedStripeEvent(t, stripeEventPaymentSuccess, map[string]any{
+		"id":       "pi_test_4",
+		"object":   "payment_intent",
+		"amount":   2000,
+		"currency": "usd",
+		"metadata": map[string]string{"orderId": "sub2_order"},
+	})
+
+	n, err := s.VerifyNotification(t.Context(), body, headers)
+	require.NoError(t, err)
+	require.NotNil(t, n)
+	require.Equal(t, "pi_test_4", n.TradeNo)
+	require.Equal(t, "sub2_order", n.OrderID)
+	require.Equal(t, payment.ProviderStatusSuccess, n.Status)
+}
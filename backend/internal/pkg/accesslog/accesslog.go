@@ -0,0 +1,332 @@
+// Package accesslog 提供独立于 zap 结构化日志的 HTTP 访问日志，
+// 以 Common / Combined Log Format 输出，便于直接接入 GoAccess、AWStats 等现有分析工具。
+//
+// 每行在标准格式之后追加 5 个扩展字段（缺失时为 "-"）：
+//
+//	"<request_id>" <api_key_id> <account_id> <upstream_status> <latency_ms>
+package accesslog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	FormatCommon   = "common"
+	FormatCombined = "combined"
+
+	RotateIntervalNone   = "none"
+	RotateIntervalHourly = "hourly"
+	RotateIntervalDaily  = "daily"
+
+	// DefaultContainerFilePath 为容器内默认访问日志路径（与结构化日志同目录）。
+	DefaultContainerFilePath = "/app/data/logs/access.log"
+	defaultFilename          = "access.log"
+
+	clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+)
+
+// Options 访问日志写入配置。
+type Options struct {
+	Format         string
+	FilePath       string
+	MaxSizeMB      int
+	MaxBackups     int
+	MaxAgeDays     int
+	Compress       bool
+	LocalTime      bool
+	RotateInterval string
+}
+
+// Entry 单条访问记录。
+type Entry struct {
+	RemoteAddr     string
+	User           string
+	Time           time.Time
+	Method         string
+	Path           string
+	RawQuery       string
+	Proto          string
+	Status         int
+	Bytes          int
+	Referer        string
+	UserAgent      string
+	RequestID      string
+	APIKeyID       int64
+	AccountID      int64
+	UpstreamStatus int
+	Latency        time.Duration
+}
+
+// Writer 访问日志写入器：按大小（lumberjack）与时间周期（hourly/daily）轮转，旧文件可 gzip 压缩。
+type Writer struct {
+	format    string
+	localTime bool
+	out       *lumberjack.Logger
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// New 创建访问日志写入器，RotateInterval 非 none 时启动按时间轮转的后台协程。
+func New(opts Options) (*Writer, error) {
+	opts = opts.normalized()
+	if err := os.MkdirAll(filepath.Dir(opts.FilePath), 0o755); err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		format:    opts.Format,
+		localTime: opts.LocalTime,
+		out: &lumberjack.Logger{
+			Filename:   opts.FilePath,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+			Compress:   opts.Compress,
+			LocalTime:  opts.LocalTime,
+		},
+		stopCh: make(chan struct{}),
+	}
+	if opts.RotateInterval != RotateIntervalNone {
+		w.wg.Add(1)
+		go w.rotateLoop(opts.RotateInterval)
+	}
+	return w, nil
+}
+
+// Write 追加一条访问记录；整行一次写入，可并发调用。
+func (w *Writer) Write(e *Entry) {
+	if w == nil || e == nil {
+		return
+	}
+	_, _ = w.out.Write(FormatLine(w.format, e, w.localTime))
+}
+
+// Close 停止时间轮转协程并关闭文件。
+func (w *Writer) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.stopOnce.Do(func() { close(w.stopCh) })
+	w.wg.Wait()
+	return w.out.Close()
+}
+
+func (w *Writer) rotateLoop(interval string) {
+	defer w.wg.Done()
+	for {
+		now := time.Now()
+		timer := time.NewTimer(nextRotation(now, interval, w.localTime).Sub(now))
+		select {
+		case <-w.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+			_ = w.out.Rotate()
+		}
+	}
+}
+
+// nextRotation 返回下一个整点 / 零点（按 localTime 选择本地时区或 UTC）。
+func nextRotation(now time.Time, interval string, localTime bool) time.Time {
+	loc := time.UTC
+	if localTime {
+		loc = time.Local
+	}
+	now = now.In(loc)
+	if interval == RotateIntervalHourly {
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, loc).Add(time.Hour)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+}
+
+// FormatLine 按 common / combined 格式渲染一行（含换行符），查询串中的敏感参数会被脱敏。
+func FormatLine(format string, e *Entry, localTime bool) []byte {
+	ts := e.Time
+	if localTime {
+		ts = ts.Local()
+	} else {
+		ts = ts.UTC()
+	}
+	uri := e.Path
+	if e.RawQuery != "" {
+		uri += "?" + redactQuery(e.RawQuery)
+	}
+
+	var b strings.Builder
+	b.Grow(256)
+	b.WriteString(dash(e.RemoteAddr))
+	b.WriteString(" - ")
+	b.WriteString(dash(strings.ReplaceAll(e.User, " ", "_")))
+	b.WriteString(" [")
+	b.WriteString(ts.Format(clfTimeLayout))
+	b.WriteString(`] "`)
+	b.WriteString(escape(e.Method + " " + uri + " " + e.Proto))
+	b.WriteString(`" `)
+	b.WriteString(strconv.Itoa(e.Status))
+	b.WriteByte(' ')
+	b.WriteString(positiveOrDash(int64(e.Bytes)))
+	if format != FormatCommon {
+		b.WriteString(` "`)
+		b.WriteString(escape(dash(e.Referer)))
+		b.WriteString(`" "`)
+		b.WriteString(escape(dash(e.UserAgent)))
+		b.WriteByte('"')
+	}
+	b.WriteString(` "`)
+	b.WriteString(escape(dash(e.RequestID)))
+	b.WriteString(`" `)
+	b.WriteString(positiveOrDash(e.APIKeyID))
+	b.WriteByte(' ')
+	b.WriteString(positiveOrDash(e.AccountID))
+	b.WriteByte(' ')
+	b.WriteString(positiveOrDash(int64(e.UpstreamStatus)))
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(e.Latency.Milliseconds(), 10))
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+var sensitiveQueryKeys = map[string]struct{}{
+	"key":           {},
+	"api_key":       {},
+	"access_token":  {},
+	"refresh_token": {},
+	"id_token":      {},
+	"token":         {},
+	"code":          {},
+	"code_verifier": {},
+	"client_secret": {},
+	"password":      {},
+}
+
+// redactQuery 保持参数顺序，仅替换敏感参数的值（如 Gemini 的 ?key=）。
+func redactQuery(raw string) string {
+	parts := strings.Split(raw, "&")
+	for i, part := range parts {
+		name, _, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		if _, ok := sensitiveQueryKeys[strings.ToLower(name)]; ok {
+			parts[i] = name + "=***"
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escape 按 nginx 的方式转义引号、反斜杠与控制字符，避免破坏行结构。
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			b.WriteString(`\x`)
+			b.WriteByte("0123456789ABCDEF"[c>>4])
+			b.WriteByte("0123456789ABCDEF"[c&0x0f])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func dash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+func positiveOrDash(v int64) string {
+	if v <= 0 {
+		return "-"
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+func (o Options) normalized() Options {
+	out := o
+	out.Format = strings.ToLower(strings.TrimSpace(out.Format))
+	if out.Format != FormatCommon {
+		out.Format = FormatCombined
+	}
+	out.FilePath = strings.TrimSpace(out.FilePath)
+	if out.FilePath == "" {
+		if dataDir := strings.TrimSpace(os.Getenv("DATA_DIR")); dataDir != "" {
+			out.FilePath = filepath.Join(dataDir, "logs", defaultFilename)
+		} else {
+			out.FilePath = DefaultContainerFilePath
+		}
+	}
+	if out.MaxSizeMB <= 0 {
+		out.MaxSizeMB = 100
+	}
+	if out.MaxBackups < 0 {
+		out.MaxBackups = 10
+	}
+	if out.MaxAgeDays < 0 {
+		out.MaxAgeDays = 7
+	}
+	out.RotateInterval = strings.ToLower(strings.TrimSpace(out.RotateInterval))
+	if out.RotateInterval != RotateIntervalHourly && out.RotateInterval != RotateIntervalDaily {
+		out.RotateInterval = RotateIntervalNone
+	}
+	return out
+}
+
+var (
+	globalMu     sync.RWMutex
+	globalWriter *Writer
+)
+
+// Init 安装全局访问日志写入器（替换并关闭已有写入器）。
+func Init(opts Options) error {
+	w, err := New(opts)
+	if err != nil {
+		return err
+	}
+	globalMu.Lock()
+	prev := globalWriter
+	globalWriter = w
+	globalMu.Unlock()
+	if prev != nil {
+		_ = prev.Close()
+	}
+	return nil
+}
+
+// Enabled 报告是否已安装全局写入器。
+func Enabled() bool {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalWriter != nil
+}
+
+// Log 通过全局写入器追加一条记录；未启用时为空操作。
+func Log(e *Entry) {
+	globalMu.RLock()
+	w := globalWriter
+	globalMu.RUnlock()
+	w.Write(e)
+}
+
+// Close 关闭并卸载全局写入器。
+func Close() {
+	globalMu.Lock()
+	w := globalWriter
+	globalWriter = nil
+	globalMu.Unlock()
+	_ = w.Close()
+}
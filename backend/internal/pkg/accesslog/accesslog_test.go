@@ -0,0 +1,96 @@
+package accesslog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatLine_Combined(t *testing.T) {
+	e := &Entry{
+		RemoteAddr:     "203.0.113.7",
+		User:           "42",
+		Time:           time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		Method:         "POST",
+		Path:           "/v1beta/models/gemini-2.5-pro:generateContent",
+		RawQuery:       "alt=sse&key=AIzaSecret&foo=bar",
+		Proto:          "HTTP/1.1",
+		Status:         200,
+		Bytes:          1234,
+		Referer:        "",
+		UserAgent:      `curl/8.0 "quoted"`,
+		RequestID:      "req-1",
+		APIKeyID:       9,
+		AccountID:      101,
+		UpstreamStatus: 200,
+		Latency:        1500 * time.Millisecond,
+	}
+	got := string(FormatLine(FormatCombined, e, false))
+	want := `203.0.113.7 - 42 [04/Mar/2026:05:06:07 +0000] "POST /v1beta/models/gemini-2.5-pro:generateContent?alt=sse&key=***&foo=bar HTTP/1.1" 200 1234 "-" "curl/8.0 \"quoted\"" "req-1" 9 101 200 1500` + "\n"
+	if got != want {
+		t.Fatalf("FormatLine() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestFormatLine_CommonWithMissingFields(t *testing.T) {
+	e := &Entry{
+		Time:   time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		Method: "GET",
+		Path:   "/api/v1/auth/me\n",
+		Proto:  "HTTP/2.0",
+		Status: 401,
+		Bytes:  -1,
+	}
+	got := string(FormatLine(FormatCommon, e, false))
+	want := `- - - [04/Mar/2026:05:06:07 +0000] "GET /api/v1/auth/me\x0A HTTP/2.0" 401 - "-" - - - 0` + "\n"
+	if got != want {
+		t.Fatalf("FormatLine() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestNextRotation(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	if got := nextRotation(now, RotateIntervalHourly, false); !got.Equal(time.Date(2026, 3, 4, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("hourly next = %v", got)
+	}
+	if got := nextRotation(now, RotateIntervalDaily, false); !got.Equal(time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("daily next = %v", got)
+	}
+}
+
+func TestOptionsNormalized_DefaultPathUsesDataDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	got := Options{RotateInterval: "weekly"}.normalized()
+	if got.FilePath != filepath.Join(dir, "logs", "access.log") {
+		t.Fatalf("FilePath = %q", got.FilePath)
+	}
+	if got.Format != FormatCombined || got.RotateInterval != RotateIntervalNone || got.MaxSizeMB != 100 {
+		t.Fatalf("unexpected normalized options: %+v", got)
+	}
+}
+
+func TestInitWritesToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "access.log")
+	if err := Init(Options{FilePath: path, RotateInterval: RotateIntervalDaily}); err != nil {
+		t.Fatalf("Init() error: %v", err)
+	}
+	if !Enabled() {
+		t.Fatalf("expected access log enabled")
+	}
+	Log(&Entry{Time: time.Now(), Method: "GET", Path: "/v1/models", Proto: "HTTP/1.1", Status: 200})
+	Close()
+	if Enabled() {
+		t.Fatalf("expected access log disabled after Close")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read access log: %v", err)
+	}
+	if !strings.Contains(string(data), `"GET /v1/models HTTP/1.1" 200`) {
+		t.Fatalf("unexpected access log content: %q", string(data))
+	}
+}
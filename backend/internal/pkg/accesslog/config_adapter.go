@@ -0,0 +1,16 @@
+package accesslog
+
+import "github.com/Wei-Shaw/sub2api/internal/config"
+
+func OptionsFromConfig(cfg config.AccessLogConfig) Options {
+	return Options{
+		Format:         cfg.Format,
+		FilePath:       cfg.FilePath,
+		MaxSizeMB:      cfg.Rotation.MaxSizeMB,
+		MaxBackups:     cfg.Rotation.MaxBackups,
+		MaxAgeDays:     cfg.Rotation.MaxAgeDays,
+		Compress:       cfg.Rotation.Compress,
+		LocalTime:      cfg.Rotation.LocalTime,
+		RotateInterval: cfg.RotateInterval,
+	}
+}
@@ -61,6 +61,12 @@ const (
 	// Service 层仅在分组匹配时复用 PrefetchedStickyAccountID，避免分组切换重试误用旧 sticky。
 	PrefetchedStickyGroupID Key = "ctx_prefetched_sticky_group_id"
 
+	// PinnedAccountID 管理员 Key 通过 X-Sub2API-Account-ID 指定的调试账号 ID，存在时跳过调度直接使用该账号。
+	PinnedAccountID Key = "ctx_pinned_account_id"
+
+	// PrimaryDBRead 标识当前调用链的数据库读取必须走主库（跳过只读副本），用于写后立即读等一致性敏感场景。
+	PrimaryDBRead Key = "ctx_primary_db_read"
+
 	// ClaudeCodeVersion stores the extracted Claude Code version from User-Agent (e.g. "2.1.22")
 	ClaudeCodeVersion Key = "ctx_claude_code_version"
 )
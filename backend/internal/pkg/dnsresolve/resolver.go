@@ -0,0 +1,321 @@
+// Package dnsresolve 提供上游连接的自定义 DNS 解析能力
+//
+// 解析优先级：
+//   - IP 字面量：原样返回
+//   - 静态映射（static hosts）：用于固定 CDN 节点或绕过被污染的解析器
+//   - DoH（DNS over HTTPS，JSON 格式，兼容 Cloudflare/Google 等公共服务）
+//   - 系统解析器
+//
+// 解析失败统一返回 *ResolveError，便于上层在运维事件中识别 DNS 类故障。
+package dnsresolve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDoHTimeout = 5 * time.Second
+	// DoH 应答 TTL 的上下限，避免 TTL=0 时每次建连都查询，也避免过期记录长期滞留
+	minCacheTTL = 30 * time.Second
+	maxCacheTTL = 10 * time.Minute
+	// DoH 应答体上限（JSON 应答通常远小于此值）
+	maxDoHResponseBytes = 64 << 10
+
+	dnsTypeA    = 1
+	dnsTypeAAAA = 28
+)
+
+// errorMarker 是 ResolveError 文本的固定前缀，供只能拿到错误字符串的调用方识别
+const errorMarker = "dns resolve "
+
+// ResolveError 表示主机名解析失败
+type ResolveError struct {
+	Host string
+	Err  error
+}
+
+func (e *ResolveError) Error() string {
+	return errorMarker + e.Host + ": " + e.Err.Error()
+}
+
+func (e *ResolveError) Unwrap() error { return e.Err }
+
+// IsResolveErrorMessage 判断错误文本是否为 DNS 解析失败（包括系统解析器的 lookup 错误）
+func IsResolveErrorMessage(msg string) bool {
+	if strings.Contains(msg, errorMarker) {
+		return true
+	}
+	return strings.Contains(msg, "lookup ") &&
+		(strings.Contains(msg, "no such host") || strings.Contains(msg, "server misbehaving"))
+}
+
+// Options 解析器配置
+type Options struct {
+	// StaticHosts 主机名 → IP 列表（主机名大小写不敏感）
+	StaticHosts map[string][]string
+	// DoHURL DoH JSON 端点（如 https://cloudflare-dns.com/dns-query），为空时使用系统解析器
+	DoHURL string
+	// DoHTimeout 单次 DoH 查询超时
+	DoHTimeout time.Duration
+}
+
+type cacheEntry struct {
+	ips       []string
+	expiresAt time.Time
+}
+
+// Resolver 带静态映射与 DoH 支持的解析器，可安全并发使用
+type Resolver struct {
+	static     map[string][]string
+	dohURL     string
+	dohClient  *http.Client
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	mu    sync.RWMutex
+	cache map[string]cacheEntry
+	now   func() time.Time
+}
+
+// New 创建解析器；未配置任何静态映射或 DoH 时返回 nil，调用方应保持默认行为
+func New(opts Options) *Resolver {
+	static := make(map[string][]string, len(opts.StaticHosts))
+	for host, ips := range opts.StaticHosts {
+		host = normalizeHost(host)
+		if host == "" || len(ips) == 0 {
+			continue
+		}
+		static[host] = append([]string(nil), ips...)
+	}
+	dohURL := strings.TrimSpace(opts.DoHURL)
+	if len(static) == 0 && dohURL == "" {
+		return nil
+	}
+	timeout := opts.DoHTimeout
+	if timeout <= 0 {
+		timeout = defaultDoHTimeout
+	}
+	return &Resolver{
+		static:     static,
+		dohURL:     dohURL,
+		dohClient:  &http.Client{Timeout: timeout},
+		lookupHost: net.DefaultResolver.LookupHost,
+		cache:      make(map[string]cacheEntry),
+		now:        time.Now,
+	}
+}
+
+// StaticIPs 返回主机名的静态映射（未命中返回 nil）
+func (r *Resolver) StaticIPs(host string) []string {
+	if r == nil {
+		return nil
+	}
+	return r.static[normalizeHost(host)]
+}
+
+// Resolve 解析主机名为 IP 列表
+func (r *Resolver) Resolve(ctx context.Context, host string) ([]string, error) {
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return []string{ip.String()}, nil
+	}
+	if ips := r.StaticIPs(host); len(ips) > 0 {
+		return ips, nil
+	}
+	key := normalizeHost(host)
+	if r != nil && r.dohURL != "" {
+		if ips, ok := r.cached(key); ok {
+			return ips, nil
+		}
+		ips, ttl, err := r.queryDoH(ctx, key)
+		if err != nil {
+			return nil, &ResolveError{Host: host, Err: err}
+		}
+		r.store(key, ips, ttl)
+		return ips, nil
+	}
+	lookup := net.DefaultResolver.LookupHost
+	if r != nil && r.lookupHost != nil {
+		lookup = r.lookupHost
+	}
+	ips, err := lookup(ctx, host)
+	if err != nil {
+		return nil, &ResolveError{Host: host, Err: err}
+	}
+	if len(ips) == 0 {
+		return nil, &ResolveError{Host: host, Err: errors.New("no addresses")}
+	}
+	return ips, nil
+}
+
+// DialFunc 与 http.Transport.DialContext 签名一致
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WrapDial 返回先经本解析器解析、再按顺序尝试各 IP 的 DialFunc。
+// 仅改写拨号地址，TLS SNI 与 Host 头仍由调用方基于原始主机名设置。
+func (r *Resolver) WrapDial(base DialFunc) DialFunc {
+	if base == nil {
+		base = (&net.Dialer{}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return base(ctx, network, addr)
+		}
+		ips, err := r.Resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range ips {
+			conn, dialErr := base(ctx, network, net.JoinHostPort(ip, port))
+			if dialErr == nil {
+				return conn, nil
+			}
+			lastErr = dialErr
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, lastErr
+	}
+}
+
+// RewriteStatic 仅按静态映射改写拨号地址（未命中原样返回），
+// 用于 SOCKS 远程解析模式下仍尊重显式固定的地址。
+func (r *Resolver) RewriteStatic(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ips := r.StaticIPs(host); len(ips) > 0 {
+		return net.JoinHostPort(ips[0], port)
+	}
+	return addr
+}
+
+func (r *Resolver) cached(host string) ([]string, bool) {
+	r.mu.RLock()
+	entry, ok := r.cache[host]
+	r.mu.RUnlock()
+	if !ok || r.now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.ips, true
+}
+
+func (r *Resolver) store(host string, ips []string, ttl time.Duration) {
+	if ttl < minCacheTTL {
+		ttl = minCacheTTL
+	}
+	if ttl > maxCacheTTL {
+		ttl = maxCacheTTL
+	}
+	r.mu.Lock()
+	r.cache[host] = cacheEntry{ips: ips, expiresAt: r.now().Add(ttl)}
+	r.mu.Unlock()
+}
+
+type dohAnswer struct {
+	Type int    `json:"type"`
+	TTL  int    `json:"TTL"`
+	Data string `json:"data"`
+}
+
+type dohResponse struct {
+	Status int         `json:"Status"`
+	Answer []dohAnswer `json:"Answer"`
+}
+
+// queryDoH 依次查询 A 与 AAAA 记录，优先返回 IPv4 地址
+func (r *Resolver) queryDoH(ctx context.Context, host string) ([]string, time.Duration, error) {
+	var ips []string
+	var ttl time.Duration
+	var firstErr error
+	for _, qtype := range []int{dnsTypeA, dnsTypeAAAA} {
+		found, recordTTL, err := r.queryDoHType(ctx, host, qtype)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if len(found) > 0 && (ttl == 0 || recordTTL < ttl) {
+			ttl = recordTTL
+		}
+		ips = append(ips, found...)
+	}
+	if len(ips) == 0 {
+		if firstErr != nil {
+			return nil, 0, firstErr
+		}
+		return nil, 0, errors.New("doh: no addresses")
+	}
+	return ips, ttl, nil
+}
+
+func (r *Resolver) queryDoHType(ctx context.Context, host string, qtype int) ([]string, time.Duration, error) {
+	u, err := url.Parse(r.dohURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("doh: invalid url: %w", err)
+	}
+	q := u.Query()
+	q.Set("name", host)
+	q.Set("type", fmt.Sprintf("%d", qtype))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("doh: %w", err)
+	}
+	req.Header.Set("Accept", "application/dns-json")
+	resp, err := r.dohClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("doh: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("doh: unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDoHResponseBytes))
+	if err != nil {
+		return nil, 0, fmt.Errorf("doh: read response: %w", err)
+	}
+	var parsed dohResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, 0, fmt.Errorf("doh: decode response: %w", err)
+	}
+	if parsed.Status != 0 {
+		return nil, 0, fmt.Errorf("doh: rcode %d", parsed.Status)
+	}
+
+	var ips []string
+	var ttl time.Duration
+	for _, ans := range parsed.Answer {
+		if ans.Type != qtype {
+			continue // 跳过 CNAME 等中间记录
+		}
+		ip := net.ParseIP(strings.TrimSpace(ans.Data))
+		if ip == nil {
+			continue
+		}
+		ips = append(ips, ip.String())
+		recordTTL := time.Duration(ans.TTL) * time.Second
+		if ttl == 0 || recordTTL < ttl {
+			ttl = recordTTL
+		}
+	}
+	return ips, ttl, nil
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,122 @@
+package dnsresolve
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_NilWhenNothingConfigured(t *testing.T) {
+	require.Nil(t, New(Options{}))
+	require.Nil(t, New(Options{StaticHosts: map[string][]string{"example.com": nil}}))
+}
+
+func TestResolve_StaticAndLiteral(t *testing.T) {
+	r := New(Options{StaticHosts: map[string][]string{"API.Example.com.": {"10.0.0.1", "10.0.0.2"}}})
+	require.NotNil(t, r)
+
+	ips, err := r.Resolve(context.Background(), "api.example.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, ips)
+
+	ips, err = r.Resolve(context.Background(), "[::1]")
+	require.NoError(t, err)
+	require.Equal(t, []string{"::1"}, ips)
+
+	require.Equal(t, "10.0.0.1:443", r.RewriteStatic("api.example.com:443"))
+	require.Equal(t, "other.example.com:443", r.RewriteStatic("other.example.com:443"))
+}
+
+func TestResolve_SystemLookupErrorIsWrapped(t *testing.T) {
+	r := New(Options{StaticHosts: map[string][]string{"pinned.example.com": {"10.0.0.1"}}})
+	r.lookupHost = func(context.Context, string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+
+	_, err := r.Resolve(context.Background(), "missing.example.com")
+	var resolveErr *ResolveError
+	require.ErrorAs(t, err, &resolveErr)
+	require.Equal(t, "missing.example.com", resolveErr.Host)
+	require.True(t, IsResolveErrorMessage("Post \"https://x\": "+err.Error()))
+}
+
+func TestResolve_DoHWithCache(t *testing.T) {
+	var queries atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		queries.Add(1)
+		require.Equal(t, "application/dns-json", req.Header.Get("Accept"))
+		require.Equal(t, "api.example.com", req.URL.Query().Get("name"))
+		w.Header().Set("Content-Type", "application/dns-json")
+		if req.URL.Query().Get("type") == "1" {
+			_, _ = w.Write([]byte(`{"Status":0,"Answer":[{"type":5,"TTL":60,"data":"cdn.example.net."},{"type":1,"TTL":120,"data":"203.0.113.7"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Status":0}`))
+	}))
+	defer srv.Close()
+
+	r := New(Options{DoHURL: srv.URL + "/dns-query"})
+	r.dohClient = srv.Client()
+	now := time.Unix(1_700_000_000, 0)
+	r.now = func() time.Time { return now }
+
+	ips, err := r.Resolve(context.Background(), "api.example.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.7"}, ips)
+	require.Equal(t, int32(2), queries.Load())
+
+	_, err = r.Resolve(context.Background(), "API.example.com")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), queries.Load(), "second lookup should hit the cache")
+
+	now = now.Add(3 * time.Minute)
+	_, err = r.Resolve(context.Background(), "api.example.com")
+	require.NoError(t, err)
+	require.Equal(t, int32(4), queries.Load(), "expired entry should be refreshed")
+}
+
+func TestResolve_DoHFailure(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"Status":3}`))
+	}))
+	defer srv.Close()
+
+	r := New(Options{DoHURL: srv.URL})
+	r.dohClient = srv.Client()
+
+	_, err := r.Resolve(context.Background(), "nxdomain.example.com")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "dns resolve nxdomain.example.com: doh: rcode 3")
+}
+
+func TestWrapDial_TriesEachAddress(t *testing.T) {
+	r := New(Options{StaticHosts: map[string][]string{"api.example.com": {"10.0.0.1", "10.0.0.2"}}})
+
+	var dialed []string
+	dial := r.WrapDial(func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if addr == "10.0.0.2:443" {
+			client, server := net.Pipe()
+			_ = server.Close()
+			return client, nil
+		}
+		return nil, errors.New("connection refused")
+	})
+
+	conn, err := dial(context.Background(), "tcp", "api.example.com:443")
+	require.NoError(t, err)
+	_ = conn.Close()
+	require.Equal(t, []string{"10.0.0.1:443", "10.0.0.2:443"}, dialed)
+}
+
+func TestIsResolveErrorMessage(t *testing.T) {
+	require.True(t, IsResolveErrorMessage("dial tcp: lookup api.example.com on 127.0.0.53:53: no such host"))
+	require.False(t, IsResolveErrorMessage("dial tcp 1.2.3.4:443: connect: connection refused"))
+}
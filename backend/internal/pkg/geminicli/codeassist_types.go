@@ -80,3 +80,21 @@ type OnboardUserResponse struct {
 type OnboardUserResultData struct {
 	CloudAICompanionProject any `json:"cloudaicompanionProject,omitempty"`
 }
+
+// RetrieveUserQuotaRequest 查询 Code Assist 项目的剩余配额（v1internal:retrieveUserQuota）。
+type RetrieveUserQuotaRequest struct {
+	Project string `json:"project"`
+}
+
+type RetrieveUserQuotaResponse struct {
+	Buckets []UserQuotaBucket `json:"buckets,omitempty"`
+}
+
+// UserQuotaBucket 单个配额桶；ModelID 为空表示项目级共享配额。
+type UserQuotaBucket struct {
+	RemainingAmount   string   `json:"remainingAmount,omitempty"`
+	RemainingFraction *float64 `json:"remainingFraction,omitempty"`
+	ResetTime         string   `json:"resetTime,omitempty"`
+	TokenType         string   `json:"tokenType,omitempty"`
+	ModelID           string   `json:"modelId,omitempty"`
+}
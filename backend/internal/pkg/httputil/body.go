@@ -22,10 +22,30 @@ const (
 	maxDecompressedBodySize = 64 << 20
 )
 
+// UnsupportedContentEncodingError reports a request body compressed with an
+// encoding the gateway cannot decode. Handlers map it to 415.
+type UnsupportedContentEncodingError struct {
+	Encoding string
+}
+
+func (e *UnsupportedContentEncodingError) Error() string {
+	return fmt.Sprintf("unsupported Content-Encoding %q", e.Encoding)
+}
+
 // ReadRequestBodyWithPrealloc reads request body with preallocated buffer based
 // on content length, transparently decoding any Content-Encoding the upstream
 // client used to compress the body (zstd, gzip, deflate).
 func ReadRequestBodyWithPrealloc(req *http.Request) ([]byte, error) {
+	return ReadRequestBodyWithLimit(req, maxDecompressedBodySize)
+}
+
+// ReadRequestBodyWithLimit reads and decodes the request body. A decoded body
+// larger than maxDecodedBytes fails with *http.MaxBytesError instead of being
+// truncated, so compressed payloads obey the same limit as plain ones.
+func ReadRequestBodyWithLimit(req *http.Request, maxDecodedBytes int64) ([]byte, error) {
+	if maxDecodedBytes <= 0 {
+		maxDecodedBytes = maxDecompressedBodySize
+	}
 	if req == nil || req.Body == nil {
 		return nil, nil
 	}
@@ -53,8 +73,13 @@ func ReadRequestBodyWithPrealloc(req *http.Request) ([]byte, error) {
 		return raw, nil
 	}
 
-	decoded, err := decompressRequestBody(enc, raw)
+	decoded, err := decompressRequestBody(enc, raw, maxDecodedBytes)
 	if err != nil {
+		var unsupported *UnsupportedContentEncodingError
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &unsupported) || errors.As(err, &maxErr) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("decode Content-Encoding %q: %w", enc, err)
 	}
 
@@ -68,14 +93,15 @@ func ReadRequestBodyWithPrealloc(req *http.Request) ([]byte, error) {
 // ReadLenientJSONRequestBodyWithPrealloc reads a request body and normalizes
 // JSON string control bytes before strict validation.
 func ReadLenientJSONRequestBodyWithPrealloc(req *http.Request, maxNormalizedBytes int64) ([]byte, error) {
-	body, err := ReadRequestBodyWithPrealloc(req)
+	body, err := ReadRequestBodyWithLimit(req, maxNormalizedBytes)
 	if err != nil {
 		return nil, err
 	}
 	return NormalizeLenientJSONRequestBody(body, maxNormalizedBytes)
 }
 
-func decompressRequestBody(encoding string, raw []byte) ([]byte, error) {
+func decompressRequestBody(encoding string, raw []byte, maxDecodedBytes int64) ([]byte, error) {
+	var reader io.Reader
 	switch encoding {
 	case "zstd":
 		dec, err := zstd.NewReader(bytes.NewReader(raw))
@@ -83,24 +109,34 @@ func decompressRequestBody(encoding string, raw []byte) ([]byte, error) {
 			return nil, err
 		}
 		defer dec.Close()
-		return io.ReadAll(io.LimitReader(dec, maxDecompressedBodySize))
+		reader = dec
 	case "gzip", "x-gzip":
 		gr, err := gzip.NewReader(bytes.NewReader(raw))
 		if err != nil {
 			return nil, err
 		}
 		defer func() { _ = gr.Close() }()
-		return io.ReadAll(io.LimitReader(gr, maxDecompressedBodySize))
+		reader = gr
 	case "deflate":
 		zr, err := zlib.NewReader(bytes.NewReader(raw))
 		if err != nil {
 			return nil, err
 		}
 		defer func() { _ = zr.Close() }()
-		return io.ReadAll(io.LimitReader(zr, maxDecompressedBodySize))
+		reader = zr
 	default:
-		return nil, errors.New("unsupported Content-Encoding")
+		return nil, &UnsupportedContentEncodingError{Encoding: encoding}
 	}
+
+	// Read one extra byte to detect overflow instead of handing truncated JSON downstream.
+	decoded, err := io.ReadAll(io.LimitReader(reader, maxDecodedBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decoded)) > maxDecodedBytes {
+		return nil, &http.MaxBytesError{Limit: maxDecodedBytes}
+	}
+	return decoded, nil
 }
 
 // NormalizeLenientJSONRequestBody escapes raw control bytes that broken
@@ -0,0 +1,75 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor 游标无法解析
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Cursor keyset 分页游标：上一页最后一条记录的 (created_at, id)。
+// 零值表示游标模式下的第一页。
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+type cursorPayload struct {
+	CreatedAt string `json:"t"`
+	ID        int64  `json:"id"`
+}
+
+// IsZero 是否为第一页（无定位记录）
+func (c Cursor) IsZero() bool {
+	return c.ID == 0 && c.CreatedAt.IsZero()
+}
+
+// Encode 编码为不透明的 URL 安全字符串
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(cursorPayload{
+		CreatedAt: c.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ID:        c.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseCursor 解析 Encode 生成的游标；空字符串返回零值游标（第一页）。
+func ParseCursor(raw string) (Cursor, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Cursor{}, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(decoded, &payload); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil || payload.ID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{CreatedAt: createdAt, ID: payload.ID}, nil
+}
+
+// UsesCursor 是否使用 keyset 游标分页（此时忽略 Page 与 SortBy，按 created_at, id 排序）
+func (p PaginationParams) UsesCursor() bool {
+	return p.Cursor != nil
+}
+
+// CursorResult 构造游标模式的分页结果：fetched 为按 limit+1 查询到的条数，
+// last 为本页最后一条记录，仅在还有下一页时生成 NextCursor。
+func CursorResult(params PaginationParams, fetched int, last Cursor) *PaginationResult {
+	limit := params.Limit()
+	result := &PaginationResult{PageSize: limit, HasMore: fetched > limit}
+	if result.HasMore {
+		result.NextCursor = last.Encode()
+	}
+	return result
+}
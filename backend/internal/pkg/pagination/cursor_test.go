@@ -0,0 +1,55 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := Cursor{CreatedAt: time.Date(2025, 3, 4, 5, 6, 7, 123456000, time.UTC), ID: 987}
+	got, err := ParseCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("ParseCursor: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Fatalf("ParseCursor = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseCursorEmptyIsFirstPage(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseCursor("  ")
+	if err != nil || !got.IsZero() {
+		t.Fatalf("ParseCursor(empty) = %+v, %v; want zero cursor", got, err)
+	}
+}
+
+func TestParseCursorInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{"!!!", "bm90LWpzb24", Cursor{CreatedAt: time.Now()}.Encode()} {
+		if _, err := ParseCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("ParseCursor(%q) err = %v, want ErrInvalidCursor", raw, err)
+		}
+	}
+}
+
+func TestCursorResult(t *testing.T) {
+	t.Parallel()
+
+	params := PaginationParams{PageSize: 2, Cursor: &Cursor{}}
+	last := Cursor{CreatedAt: time.Unix(100, 0).UTC(), ID: 5}
+
+	result := CursorResult(params, 3, last)
+	if !result.HasMore || result.NextCursor != last.Encode() || result.PageSize != 2 {
+		t.Fatalf("CursorResult(has more) = %+v", result)
+	}
+	result = CursorResult(params, 2, last)
+	if result.HasMore || result.NextCursor != "" {
+		t.Fatalf("CursorResult(last page) = %+v", result)
+	}
+}
@@ -14,6 +14,8 @@ type PaginationParams struct {
 	PageSize  int
 	SortBy    string
 	SortOrder string
+	// Cursor 非 nil 时使用 keyset 游标分页，见 UsesCursor
+	Cursor *Cursor
 }
 
 // PaginationResult 分页结果
@@ -22,6 +24,9 @@ type PaginationResult struct {
 	Page     int
 	PageSize int
 	Pages    int
+	// 游标模式：不统计总数，NextCursor 为空表示没有下一页
+	NextCursor string
+	HasMore    bool
 }
 
 // DefaultPagination 默认分页参数
@@ -0,0 +1,94 @@
+// Package requestsign 为网关发往运营方辅助服务（请求镜像接收端等）的出站请求提供 HMAC 签名与校验。
+//
+// 签名串为 "<unix 秒时间戳>.<原始请求体>"，使用 HMAC-SHA256 计算后以十六进制放入
+// X-Sub2API-Signature: v1=<hex>；时间戳放入 X-Sub2API-Timestamp。接收端用同一密钥重算签名，
+// 并拒绝超出容忍窗口的时间戳以防重放。
+package requestsign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderTimestamp 签名时间戳（unix 秒）
+	HeaderTimestamp = "X-Sub2API-Timestamp"
+	// HeaderSignature 签名值，格式 v1=<hex>；轮换密钥期间可含多个以逗号分隔的值
+	HeaderSignature = "X-Sub2API-Signature"
+
+	// SignatureVersion 当前签名算法版本前缀
+	SignatureVersion = "v1"
+
+	// DefaultTolerance 校验时默认允许的时间戳偏差
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrMissingSignature  = errors.New("requestsign: missing signature headers")
+	ErrInvalidTimestamp  = errors.New("requestsign: invalid timestamp")
+	ErrTimestampExpired  = errors.New("requestsign: timestamp outside tolerance")
+	ErrSignatureMismatch = errors.New("requestsign: signature mismatch")
+)
+
+// Compute 计算 timestamp 与 body 的 v1 签名（十六进制）。
+func Compute(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign 为出站请求设置签名头。body 必须与实际发送的请求体完全一致。
+func Sign(req *http.Request, secret string, body []byte, now time.Time) {
+	timestamp := now.Unix()
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, SignatureVersion+"="+Compute(secret, timestamp, body))
+}
+
+// Verify 校验请求头中的签名：时间戳需在 tolerance 内（<=0 使用 DefaultTolerance），
+// 且任一 v1 签名与任一密钥匹配即通过（便于密钥轮换）。
+func Verify(header http.Header, body []byte, now time.Time, tolerance time.Duration, secrets ...string) error {
+	rawTimestamp := strings.TrimSpace(header.Get(HeaderTimestamp))
+	rawSignature := strings.TrimSpace(header.Get(HeaderSignature))
+	if rawTimestamp == "" || rawSignature == "" {
+		return ErrMissingSignature
+	}
+	timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > tolerance || skew < -tolerance {
+		return ErrTimestampExpired
+	}
+
+	for _, part := range strings.Split(rawSignature, ",") {
+		version, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || version != SignatureVersion {
+			continue
+		}
+		got, err := hex.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		for _, secret := range secrets {
+			if secret == "" {
+				continue
+			}
+			want, _ := hex.DecodeString(Compute(secret, timestamp, body))
+			if hmac.Equal(got, want) {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureMismatch
+}
@@ -0,0 +1,43 @@
+package requestsign
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"request_id":"r1"}`)
+	req, err := http.NewRequest(http.MethodPost, "https://mirror.example/ingest", nil)
+	require.NoError(t, err)
+
+	Sign(req, "s3cret", body, now)
+	require.Equal(t, "1700000000", req.Header.Get(HeaderTimestamp))
+	require.Equal(t, "v1="+Compute("s3cret", now.Unix(), body), req.Header.Get(HeaderSignature))
+
+	require.NoError(t, Verify(req.Header, body, now.Add(time.Minute), 0, "s3cret"))
+	// 轮换期间旧密钥仍可校验通过
+	require.NoError(t, Verify(req.Header, body, now, 0, "new-secret", "s3cret"))
+
+	require.ErrorIs(t, Verify(req.Header, []byte(`{"request_id":"r2"}`), now, 0, "s3cret"), ErrSignatureMismatch)
+	require.ErrorIs(t, Verify(req.Header, body, now, 0, "other"), ErrSignatureMismatch)
+	require.ErrorIs(t, Verify(req.Header, body, now.Add(10*time.Minute), 0, "s3cret"), ErrTimestampExpired)
+	require.ErrorIs(t, Verify(http.Header{}, body, now, 0, "s3cret"), ErrMissingSignature)
+}
+
+func TestVerifyAcceptsAnyListedSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("payload")
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, "v0=deadbeef, v1=00ff, v1="+Compute("k", now.Unix(), body))
+
+	require.NoError(t, Verify(header, body, now, time.Minute, "k"))
+
+	header.Set(HeaderTimestamp, "not-a-number")
+	require.ErrorIs(t, Verify(header, body, now, time.Minute, "k"), ErrInvalidTimestamp)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/Wei-Shaw/sub2api/internal/util/logredact"
 	"github.com/gin-gonic/gin"
 )
@@ -166,6 +167,53 @@ func PaginatedWithResult(c *gin.Context, items any, pagination *PaginationResult
 	})
 }
 
+// CursorPaginatedData 游标分页数据格式：不返回总数，next_cursor 为空表示没有下一页
+type CursorPaginatedData struct {
+	Items      any    `json:"items"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// CursorPaginated 返回游标分页数据
+func CursorPaginated(c *gin.Context, items any, result *pagination.PaginationResult) {
+	data := CursorPaginatedData{Items: items}
+	if result != nil {
+		data.PageSize = result.PageSize
+		data.NextCursor = result.NextCursor
+		data.HasMore = result.HasMore
+	}
+	Success(c, data)
+}
+
+// PaginatedByMode 按分页模式返回：游标模式返回 CursorPaginatedData，否则与 Paginated 相同
+func PaginatedByMode(c *gin.Context, items any, params pagination.PaginationParams, result *pagination.PaginationResult) {
+	if params.UsesCursor() {
+		CursorPaginated(c, items, result)
+		return
+	}
+	var total int64
+	if result != nil {
+		total = result.Total
+	}
+	Paginated(c, items, total, params.Page, params.PageSize)
+}
+
+// ParseCursor 解析 cursor 查询参数：未携带 cursor 时返回 nil（沿用页码分页），
+// 携带空值表示游标模式的第一页。解析失败时已写入 400 响应并返回 ok=false。
+func ParseCursor(c *gin.Context) (cursor *pagination.Cursor, ok bool) {
+	raw, present := c.GetQuery("cursor")
+	if !present {
+		return nil, true
+	}
+	parsed, err := pagination.ParseCursor(raw)
+	if err != nil {
+		BadRequest(c, "Invalid cursor")
+		return nil, false
+	}
+	return &parsed, true
+}
+
 // ParsePagination 解析分页参数
 func ParsePagination(c *gin.Context) (page, pageSize int) {
 	page = 1
@@ -8,8 +8,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	errors2 "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
@@ -705,6 +707,49 @@ func TestParsePagination(t *testing.T) {
 	}
 }
 
+func TestParseCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, c := newContextWithQuery("page=2")
+	cursor, ok := ParseCursor(c)
+	require.True(t, ok)
+	require.Nil(t, cursor, "未携带 cursor 时沿用页码分页")
+
+	_, c = newContextWithQuery("cursor=")
+	cursor, ok = ParseCursor(c)
+	require.True(t, ok)
+	require.NotNil(t, cursor)
+	require.True(t, cursor.IsZero(), "空 cursor 表示游标模式第一页")
+
+	encoded := pagination.Cursor{CreatedAt: time.Unix(1700000000, 0).UTC(), ID: 12}.Encode()
+	_, c = newContextWithQuery("cursor=" + encoded)
+	cursor, ok = ParseCursor(c)
+	require.True(t, ok)
+	require.Equal(t, int64(12), cursor.ID)
+
+	w, c := newContextWithQuery("cursor=broken")
+	_, ok = ParseCursor(c)
+	require.False(t, ok)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPaginatedByModeCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w, c := newContextWithQuery("")
+	params := pagination.PaginationParams{PageSize: 2, Cursor: &pagination.Cursor{}}
+	PaginatedByMode(c, []int{1, 2}, params, &pagination.PaginationResult{PageSize: 2, NextCursor: "next", HasMore: true})
+
+	var resp struct {
+		Data CursorPaginatedData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "next", resp.Data.NextCursor)
+	require.True(t, resp.Data.HasMore)
+	require.Equal(t, 2, resp.Data.PageSize)
+	require.NotContains(t, w.Body.String(), `"total"`)
+}
+
 func Test_parseInt(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -5,6 +5,8 @@ package tlsfingerprint
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"fmt"
 	"log/slog"
@@ -30,6 +32,10 @@ type Profile struct {
 	KeyShareGroups      []uint16 // Empty uses [X25519]
 	PSKModes            []uint16 // Empty uses [psk_dhe_ke]
 	Extensions          []uint16 // Extension type IDs in order; empty uses default Node.js 24.x order
+
+	// Client authentication (mTLS). These do not affect the ClientHello fingerprint.
+	ClientCertificates []tls.Certificate // Presented when the server requests a client certificate
+	RootCAs            *x509.CertPool    // nil uses the system roots
 }
 
 // Dialer creates TLS connections with custom fingerprints.
@@ -50,6 +56,9 @@ type HTTPProxyDialer struct {
 type SOCKS5ProxyDialer struct {
 	profile  *Profile
 	proxyURL *url.URL
+	// resolveTarget optionally rewrites the CONNECT target (e.g. local DNS resolution).
+	// The original addr is still used for SNI.
+	resolveTarget func(ctx context.Context, addr string) (string, error)
 }
 
 // Default TLS fingerprint values captured from Claude Code (Node.js 24.x)
@@ -138,6 +147,12 @@ func NewSOCKS5ProxyDialer(profile *Profile, proxyURL *url.URL) *SOCKS5ProxyDiale
 	return &SOCKS5ProxyDialer{profile: profile, proxyURL: proxyURL}
 }
 
+// WithTargetResolver sets a hook that rewrites the SOCKS5 CONNECT target before dialing.
+func (d *SOCKS5ProxyDialer) WithTargetResolver(resolve func(ctx context.Context, addr string) (string, error)) *SOCKS5ProxyDialer {
+	d.resolveTarget = resolve
+	return d
+}
+
 // DialTLSContext establishes a TLS connection through SOCKS5 proxy with the configured fingerprint.
 // Flow: SOCKS5 CONNECT to target -> TLS handshake with utls on the tunnel
 func (d *SOCKS5ProxyDialer) DialTLSContext(ctx context.Context, network, addr string) (net.Conn, error) {
@@ -167,8 +182,14 @@ func (d *SOCKS5ProxyDialer) DialTLSContext(ctx context.Context, network, addr st
 	}
 
 	// Step 2: Establish SOCKS5 tunnel to target
-	slog.Debug("tls_fingerprint_socks5_establishing_tunnel", "target", addr)
-	conn, err := socksDialer.Dial("tcp", addr)
+	target := addr
+	if d.resolveTarget != nil {
+		if target, err = d.resolveTarget(ctx, addr); err != nil {
+			return nil, err
+		}
+	}
+	slog.Debug("tls_fingerprint_socks5_establishing_tunnel", "target", target)
+	conn, err := socksDialer.Dial("tcp", target)
 	if err != nil {
 		slog.Debug("tls_fingerprint_socks5_connect_failed", "error", err)
 		return nil, fmt.Errorf("SOCKS5 connect: %w", err)
@@ -276,7 +297,7 @@ func performTLSHandshake(ctx context.Context, conn net.Conn, profile *Profile, a
 	}
 
 	spec := buildClientHelloSpecFromProfile(profile)
-	tlsConn := utls.UClient(conn, &utls.Config{ServerName: host}, utls.HelloCustom)
+	tlsConn := utls.UClient(conn, buildUTLSConfig(profile, host), utls.HelloCustom)
 
 	if err := tlsConn.ApplyPreset(spec); err != nil {
 		_ = conn.Close()
@@ -298,6 +319,25 @@ func performTLSHandshake(ctx context.Context, conn net.Conn, profile *Profile, a
 	return tlsConn, nil
 }
 
+// buildUTLSConfig builds the utls config carrying the profile's client certificates and CA pool.
+func buildUTLSConfig(profile *Profile, host string) *utls.Config {
+	cfg := &utls.Config{ServerName: host}
+	if profile == nil {
+		return cfg
+	}
+	cfg.RootCAs = profile.RootCAs
+	for _, cert := range profile.ClientCertificates {
+		cfg.Certificates = append(cfg.Certificates, utls.Certificate{
+			Certificate:                 cert.Certificate,
+			PrivateKey:                  cert.PrivateKey,
+			OCSPStaple:                  cert.OCSPStaple,
+			SignedCertificateTimestamps: cert.SignedCertificateTimestamps,
+			Leaf:                        cert.Leaf,
+		})
+	}
+	return cfg
+}
+
 // toUTLSCurves converts uint16 slice to utls.CurveID slice.
 func toUTLSCurves(curves []uint16) []utls.CurveID {
 	result := make([]utls.CurveID, len(curves))
@@ -281,6 +281,8 @@ type UsageLogFilters struct {
 	EndTime           *time.Time
 	// ExactTotal requests exact COUNT(*) for pagination. Default false for fast large-table paging.
 	ExactTotal bool
+	// AfterID 只返回 id 大于该值的记录（增量拉取）；0 表示不限制
+	AfterID int64
 }
 
 // UsageStats represents usage statistics
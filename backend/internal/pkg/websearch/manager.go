@@ -33,7 +33,7 @@ type ProviderConfig struct {
 // Manager selects providers by quota-weighted load balancing and tracks quota via Redis.
 type Manager struct {
 	configs []ProviderConfig
-	redis   *redis.Client
+	redis   redis.UniversalClient
 
 	clientMu    sync.Mutex
 	clientCache map[string]*http.Client
@@ -74,7 +74,7 @@ return val
 
 // NewManager creates a Manager with the given provider configs and Redis client.
 // Provider order is preserved as-is; selectByQuotaWeight handles load balancing.
-func NewManager(configs []ProviderConfig, redisClient *redis.Client) *Manager {
+func NewManager(configs []ProviderConfig, redisClient redis.UniversalClient) *Manager {
 	copied := make([]ProviderConfig, len(configs))
 	copy(copied, configs)
 	return &Manager{
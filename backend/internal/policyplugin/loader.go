@@ -0,0 +1,39 @@
+package policyplugin
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// goPluginSymbol Go 插件必须导出的工厂函数名：func NewPolicy() (policyplugin.Policy, error)
+const goPluginSymbol = "NewPolicy"
+
+// LoadGoPlugin 加载 -buildmode=plugin 编译的策略插件。
+// 插件需与网关使用同一 Go 版本、同一份依赖编译（通常放在本仓库内一起构建），且仅支持启用 cgo 的 Linux/macOS 构建。
+func LoadGoPlugin(path string) (Policy, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open policy plugin %s: %w", path, err)
+	}
+	sym, err := p.Lookup(goPluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("policy plugin %s: %w", path, err)
+	}
+	var factory Factory
+	switch fn := sym.(type) {
+	case func() (Policy, error):
+		factory = fn
+	case *Factory:
+		factory = *fn
+	default:
+		return nil, fmt.Errorf("policy plugin %s: %s has type %T, want func() (policyplugin.Policy, error)", path, goPluginSymbol, sym)
+	}
+	policy, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("policy plugin %s: %w", path, err)
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("policy plugin %s: %s returned nil policy", path, goPluginSymbol)
+	}
+	return policy, nil
+}
@@ -0,0 +1,262 @@
+package policyplugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRejectMessage     = "Request rejected by policy"
+	policyFailureRejectMsg   = "Request policy evaluation failed"
+	defaultPolicyCallTimeout = 200 * time.Millisecond
+)
+
+// RejectedError pre_forward 钩子拒绝请求时由上游调用层返回的错误。
+type RejectedError struct {
+	Rejection Rejection
+}
+
+func (e *RejectedError) Error() string {
+	return "request rejected by policy: " + e.Rejection.Message
+}
+
+// Outcome 一个钩子点上全部策略的合并结果。
+type Outcome struct {
+	// Body 最终请求体；BodyChanged 为 false 时与输入相同
+	Body        []byte
+	BodyChanged bool
+	Reject      *Rejection
+	Annotations map[string]string
+}
+
+// Manager 按配置顺序在各钩子点执行策略。nil Manager 表示未启用。
+type Manager struct {
+	byHook   map[Hook][]Policy
+	timeout  time.Duration
+	failOpen bool
+}
+
+// NewManager 创建策略执行器；timeout 为单个策略单次调用的超时，failOpen 决定策略出错/超时时放行还是拒绝。
+func NewManager(policies []Policy, timeout time.Duration, failOpen bool) *Manager {
+	if timeout <= 0 {
+		timeout = defaultPolicyCallTimeout
+	}
+	m := &Manager{
+		byHook:   make(map[Hook][]Policy),
+		timeout:  timeout,
+		failOpen: failOpen,
+	}
+	for _, p := range policies {
+		seen := make(map[Hook]struct{}, 3)
+		for _, hook := range p.Hooks() {
+			if _, dup := seen[hook]; dup {
+				continue
+			}
+			seen[hook] = struct{}{}
+			m.byHook[hook] = append(m.byHook[hook], p)
+		}
+	}
+	return m
+}
+
+// ProvideManager 按 gateway.policy_plugins 加载内置策略与 Go 插件；未启用时返回 nil。
+func ProvideManager(cfg *config.Config) (*Manager, error) {
+	if cfg == nil || !cfg.Gateway.PolicyPlugins.Enabled {
+		return nil, nil
+	}
+	pc := cfg.Gateway.PolicyPlugins
+	policies := make([]Policy, 0, len(pc.Builtins)+len(pc.Paths))
+	for _, name := range pc.Builtins {
+		factory, ok := lookupBuiltin(name)
+		if !ok {
+			return nil, fmt.Errorf("policy plugin builtin %q is not registered (available: %s)", name, strings.Join(Registered(), ", "))
+		}
+		p, err := factory()
+		if err != nil {
+			return nil, fmt.Errorf("create builtin policy %q: %w", name, err)
+		}
+		policies = append(policies, p)
+	}
+	for _, path := range pc.Paths {
+		p, err := LoadGoPlugin(path)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	for _, p := range policies {
+		logger.LegacyPrintf("policyplugin", "[PolicyPlugin] loaded policy %s hooks=%v", p.Name(), p.Hooks())
+	}
+	return NewManager(policies, time.Duration(pc.TimeoutMs)*time.Millisecond, pc.FailOpen), nil
+}
+
+// Enabled 是否有策略订阅该钩子。
+func (m *Manager) Enabled(hook Hook) bool {
+	return m != nil && len(m.byHook[hook]) > 0
+}
+
+// Run 依次执行订阅 req.Hook 的策略：请求体改写逐个传递，任一策略拒绝即停止。
+func (m *Manager) Run(ctx context.Context, req *Request) *Outcome {
+	out := &Outcome{Body: req.Body}
+	if !m.Enabled(req.Hook) {
+		return out
+	}
+	mutable := req.Hook != HookPostResponse
+	for _, p := range m.byHook[req.Hook] {
+		call := *req
+		call.Body = out.Body
+		call.Header = req.Header.Clone()
+		call.Annotations = maps.Clone(req.Annotations)
+		if len(out.Annotations) > 0 {
+			if call.Annotations == nil {
+				call.Annotations = make(map[string]string, len(out.Annotations))
+			}
+			maps.Copy(call.Annotations, out.Annotations)
+		}
+
+		decision, err := m.call(ctx, p, &call)
+		if err != nil {
+			logger.L().Warn("policy_plugin.handle_failed",
+				zap.String("policy", p.Name()),
+				zap.String("hook", string(req.Hook)),
+				zap.String("request_id", req.RequestID),
+				zap.Error(err),
+			)
+			if !m.failOpen && mutable {
+				out.Reject = &Rejection{StatusCode: http.StatusServiceUnavailable, Message: policyFailureRejectMsg}
+				return out
+			}
+			continue
+		}
+		if decision == nil {
+			continue
+		}
+		if len(decision.Annotations) > 0 {
+			if out.Annotations == nil {
+				out.Annotations = make(map[string]string, len(decision.Annotations))
+			}
+			maps.Copy(out.Annotations, decision.Annotations)
+		}
+		if !mutable {
+			continue
+		}
+		if decision.Body != nil {
+			out.Body = decision.Body
+			out.BodyChanged = true
+		}
+		if decision.Reject != nil {
+			out.Reject = normalizeRejection(decision.Reject)
+			logger.L().Info("policy_plugin.rejected",
+				zap.String("policy", p.Name()),
+				zap.String("hook", string(req.Hook)),
+				zap.String("request_id", req.RequestID),
+				zap.Int("status", out.Reject.StatusCode),
+			)
+			return out
+		}
+	}
+	return out
+}
+
+// call 在独立 goroutine 中执行策略并施加超时与 panic 保护；超时后不再等待（策略应尊重 ctx 及时返回）。
+func (m *Manager) call(ctx context.Context, p Policy, req *Request) (*Decision, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	type result struct {
+		decision *Decision
+		err      error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- result{err: fmt.Errorf("policy panic: %v", r)}
+			}
+		}()
+		d, err := p.Handle(ctx, req)
+		ch <- result{decision: d, err: err}
+	}()
+	select {
+	case r := <-ch:
+		return r.decision, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("policy call: %w", ctx.Err())
+	}
+}
+
+func normalizeRejection(r *Rejection) *Rejection {
+	out := *r
+	if out.StatusCode < 400 || out.StatusCode > 599 {
+		out.StatusCode = http.StatusForbidden
+	}
+	if strings.TrimSpace(out.Message) == "" {
+		out.Message = defaultRejectMessage
+	}
+	return &out
+}
+
+// PreForward 在上游请求发出前执行 pre_forward 钩子，按需改写 req 的请求体。
+// 非网关流量（context 中没有 State，如令牌刷新、用量查询）直接放行。
+func (m *Manager) PreForward(req *http.Request, accountID int64) error {
+	if !m.Enabled(HookPreForward) || req == nil {
+		return nil
+	}
+	state := StateFrom(req.Context())
+	if state == nil {
+		return nil
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read upstream request body: %w", err)
+		}
+	}
+
+	preq := state.Request(HookPreForward)
+	preq.Method = req.Method
+	preq.Header = SanitizedHeader(req.Header)
+	preq.Body = body
+	preq.AccountID = accountID
+	if req.URL != nil {
+		preq.Path = req.URL.Path
+		// 不含 query：部分上游把密钥放在 query 中
+		preq.UpstreamURL = req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	}
+
+	out := m.Run(req.Context(), preq)
+	state.Annotate(out.Annotations)
+	if req.Body != nil && req.Body != http.NoBody {
+		SetRequestBody(req, out.Body)
+	}
+	if out.Reject != nil {
+		return &RejectedError{Rejection: *out.Reject}
+	}
+	return nil
+}
+
+// SetRequestBody 替换请求体并同步 Content-Length / GetBody。
+func SetRequestBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	if req.Header != nil && req.Header.Get("Content-Length") != "" {
+		req.Header.Set("Content-Length", fmt.Sprint(len(body)))
+	}
+}
@@ -0,0 +1,203 @@
+package policyplugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type funcPolicy struct {
+	name   string
+	hooks  []Hook
+	handle func(ctx context.Context, req *Request) (*Decision, error)
+}
+
+func (p *funcPolicy) Name() string  { return p.name }
+func (p *funcPolicy) Hooks() []Hook { return p.hooks }
+func (p *funcPolicy) Handle(ctx context.Context, req *Request) (*Decision, error) {
+	return p.handle(ctx, req)
+}
+
+func newFuncPolicy(name string, hook Hook, handle func(ctx context.Context, req *Request) (*Decision, error)) *funcPolicy {
+	return &funcPolicy{name: name, hooks: []Hook{hook}, handle: handle}
+}
+
+func TestManagerRun_ChainsBodyAndAnnotations(t *testing.T) {
+	m := NewManager([]Policy{
+		newFuncPolicy("a", HookPreParse, func(_ context.Context, req *Request) (*Decision, error) {
+			return &Decision{Body: append(req.Body, 'a'), Annotations: map[string]string{"first": "1"}}, nil
+		}),
+		newFuncPolicy("b", HookPreParse, func(_ context.Context, req *Request) (*Decision, error) {
+			require.Equal(t, "1", req.Annotations["first"])
+			return &Decision{Body: append(req.Body, 'b')}, nil
+		}),
+	}, time.Second, true)
+
+	out := m.Run(context.Background(), &Request{Hook: HookPreParse, Body: []byte("x")})
+	require.True(t, out.BodyChanged)
+	require.Equal(t, "xab", string(out.Body))
+	require.Nil(t, out.Reject)
+	require.Equal(t, map[string]string{"first": "1"}, out.Annotations)
+}
+
+func TestManagerRun_RejectStopsChain(t *testing.T) {
+	called := false
+	m := NewManager([]Policy{
+		newFuncPolicy("deny", HookPreParse, func(context.Context, *Request) (*Decision, error) {
+			return &Decision{Reject: &Rejection{StatusCode: 200}}, nil
+		}),
+		newFuncPolicy("after", HookPreParse, func(context.Context, *Request) (*Decision, error) {
+			called = true
+			return nil, nil
+		}),
+	}, time.Second, true)
+
+	out := m.Run(context.Background(), &Request{Hook: HookPreParse})
+	require.NotNil(t, out.Reject)
+	require.Equal(t, http.StatusForbidden, out.Reject.StatusCode)
+	require.Equal(t, defaultRejectMessage, out.Reject.Message)
+	require.False(t, called)
+}
+
+func TestManagerRun_FailureModes(t *testing.T) {
+	failing := map[string]func(ctx context.Context, req *Request) (*Decision, error){
+		"error": func(context.Context, *Request) (*Decision, error) {
+			return nil, errors.New("boom")
+		},
+		"panic": func(context.Context, *Request) (*Decision, error) {
+			panic("boom")
+		},
+		"timeout": func(ctx context.Context, _ *Request) (*Decision, error) {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			return &Decision{Reject: &Rejection{StatusCode: 403}}, nil
+		},
+	}
+	for name, handle := range failing {
+		t.Run(name, func(t *testing.T) {
+			policies := []Policy{newFuncPolicy(name, HookPreParse, handle)}
+
+			open := NewManager(policies, 20*time.Millisecond, true).Run(context.Background(), &Request{Hook: HookPreParse, Body: []byte("x")})
+			require.Nil(t, open.Reject)
+			require.False(t, open.BodyChanged)
+
+			closed := NewManager(policies, 20*time.Millisecond, false).Run(context.Background(), &Request{Hook: HookPreParse})
+			require.NotNil(t, closed.Reject)
+			require.Equal(t, http.StatusServiceUnavailable, closed.Reject.StatusCode)
+		})
+	}
+}
+
+func TestManagerRun_PostResponseOnlyAnnotates(t *testing.T) {
+	m := NewManager([]Policy{
+		newFuncPolicy("post", HookPostResponse, func(context.Context, *Request) (*Decision, error) {
+			return &Decision{
+				Body:        []byte("ignored"),
+				Reject:      &Rejection{StatusCode: 403},
+				Annotations: map[string]string{"k": "v"},
+			}, nil
+		}),
+	}, time.Second, false)
+
+	out := m.Run(context.Background(), &Request{Hook: HookPostResponse, StatusCode: 200})
+	require.Nil(t, out.Reject)
+	require.False(t, out.BodyChanged)
+	require.Equal(t, "v", out.Annotations["k"])
+}
+
+func TestManagerNilSafe(t *testing.T) {
+	var m *Manager
+	require.False(t, m.Enabled(HookPreParse))
+	out := m.Run(context.Background(), &Request{Hook: HookPreParse, Body: []byte("x")})
+	require.Equal(t, "x", string(out.Body))
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/v1/messages", strings.NewReader("x"))
+	require.NoError(t, err)
+	require.NoError(t, m.PreForward(req, 1))
+}
+
+func TestManagerPreForward(t *testing.T) {
+	var seen *Request
+	m := NewManager([]Policy{
+		newFuncPolicy("fwd", HookPreForward, func(_ context.Context, req *Request) (*Decision, error) {
+			seen = req
+			return &Decision{Body: []byte(`{"rewritten":true}`), Annotations: map[string]string{"route": "a"}}, nil
+		}),
+	}, time.Second, true)
+
+	t.Run("skipped without state", func(t *testing.T) {
+		seen = nil
+		req, err := http.NewRequest(http.MethodPost, "https://example.com/v1/messages", strings.NewReader("orig"))
+		require.NoError(t, err)
+		require.NoError(t, m.PreForward(req, 1))
+		require.Nil(t, seen)
+		body, _ := io.ReadAll(req.Body)
+		require.Equal(t, "orig", string(body))
+	})
+
+	t.Run("rewrites body with state", func(t *testing.T) {
+		seen = nil
+		state := NewState(Request{RequestID: "req-1", APIKeyID: 7})
+		ctx := WithState(context.Background(), state)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://example.com/v1/messages?key=secret", strings.NewReader("orig"))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Content-Type", "application/json")
+
+		require.NoError(t, m.PreForward(req, 42))
+		require.NotNil(t, seen)
+		require.Equal(t, HookPreForward, seen.Hook)
+		require.Equal(t, "req-1", seen.RequestID)
+		require.Equal(t, int64(42), seen.AccountID)
+		require.Equal(t, "https://example.com/v1/messages", seen.UpstreamURL)
+		require.Empty(t, seen.Header.Get("Authorization"))
+		require.Equal(t, "application/json", seen.Header.Get("Content-Type"))
+		require.Equal(t, "orig", string(seen.Body))
+
+		body, _ := io.ReadAll(req.Body)
+		require.Equal(t, `{"rewritten":true}`, string(body))
+		require.Equal(t, int64(len(body)), req.ContentLength)
+		require.Equal(t, "a", state.Annotations()["route"])
+	})
+
+	t.Run("reject returns RejectedError", func(t *testing.T) {
+		deny := NewManager([]Policy{
+			newFuncPolicy("deny", HookPreForward, func(context.Context, *Request) (*Decision, error) {
+				return &Decision{Reject: &Rejection{StatusCode: 451, Message: "blocked"}}, nil
+			}),
+		}, time.Second, true)
+		ctx := WithState(context.Background(), NewState(Request{}))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://example.com/v1/messages", strings.NewReader("x"))
+		require.NoError(t, err)
+
+		err = deny.PreForward(req, 1)
+		var rejected *RejectedError
+		require.ErrorAs(t, err, &rejected)
+		require.Equal(t, 451, rejected.Rejection.StatusCode)
+		require.Equal(t, "blocked", rejected.Rejection.Message)
+	})
+}
+
+func TestSanitizedHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer x")
+	h.Set("X-Api-Key", "k")
+	h.Set("X-Goog-Api-Key", "g")
+	h.Set("Cookie", "c")
+	h.Set("Anthropic-Version", "2023-06-01")
+
+	out := SanitizedHeader(h)
+	require.Empty(t, out.Get("Authorization"))
+	require.Empty(t, out.Get("X-Api-Key"))
+	require.Empty(t, out.Get("X-Goog-Api-Key"))
+	require.Empty(t, out.Get("Cookie"))
+	require.Equal(t, "2023-06-01", out.Get("Anthropic-Version"))
+	require.Equal(t, "Bearer x", h.Get("Authorization"))
+	require.NotNil(t, SanitizedHeader(nil))
+}
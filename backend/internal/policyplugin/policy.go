@@ -0,0 +1,116 @@
+// Package policyplugin 网关自定义请求策略插件。
+//
+// 运维可以在不分叉代码的情况下接入业务规则：策略以内置注册（编译进二进制）或 Go 插件（-buildmode=plugin）
+// 两种方式加载，在固定钩子点被调用，只能通过受限的 Decision 读取/改写请求体、拒绝请求或附加注解。
+package policyplugin
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Hook 策略调用点。
+type Hook string
+
+const (
+	// HookPreParse 网关解析请求体之前（客户端原始请求体），可改写请求体、拒绝或注解。
+	HookPreParse Hook = "pre_parse"
+	// HookPreForward 每次向上游发起请求之前（已完成账号选择与协议转换），可改写发往上游的请求体、拒绝或注解。
+	// 同一客户端请求的重试/故障转移会多次触发。
+	HookPreForward Hook = "pre_forward"
+	// HookPostResponse 响应写出之后，只能附加注解（响应可能已流式发送，不支持改写或拒绝）。
+	HookPostResponse Hook = "post_response"
+)
+
+// Request 传给策略的只读请求视图。
+type Request struct {
+	Hook      Hook
+	RequestID string
+	Method    string
+	Path      string
+	// Header 客户端请求头副本（pre_forward 为发往上游的请求头副本），修改不会生效
+	Header http.Header
+	// Body 当前请求体；不要原地修改，改写请通过 Decision.Body 返回新内容
+	Body []byte
+
+	APIKeyID int64
+	UserID   int64
+	GroupID  int64
+	Platform string
+
+	// AccountID/UpstreamURL 仅 pre_forward 有效
+	AccountID   int64
+	UpstreamURL string
+
+	// StatusCode 仅 post_response 有效
+	StatusCode int
+
+	// Annotations 本请求此前的钩子已写入的注解（副本）
+	Annotations map[string]string
+}
+
+// Rejection 拒绝请求。
+type Rejection struct {
+	// StatusCode 返回给客户端的状态码（4xx/5xx），非法值按 403 处理
+	StatusCode int
+	Message    string
+}
+
+// Decision 策略的处理结果；nil 表示放行且不做任何修改。
+type Decision struct {
+	// Body 非 nil 时替换请求体（post_response 忽略）
+	Body []byte
+	// Reject 非 nil 时拒绝请求并停止执行后续策略（post_response 忽略）
+	Reject *Rejection
+	// Annotations 附加到请求上的注解，随请求结束写入结构化日志
+	Annotations map[string]string
+}
+
+// Policy 自定义策略插件。实现需要并发安全。
+type Policy interface {
+	Name() string
+	Hooks() []Hook
+	Handle(ctx context.Context, req *Request) (*Decision, error)
+}
+
+// Factory 创建策略实例。Go 插件需导出名为 NewPolicy 的 Factory 函数。
+type Factory func() (Policy, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register 注册内置策略，通常在策略包的 init 中调用；启用哪些内置策略由 gateway.policy_plugins.builtins 决定。
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("policyplugin: Register factory is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("policyplugin: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Registered 返回已注册的内置策略名（排序）。
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupBuiltin(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	f, ok := registry[name]
+	return f, ok
+}
@@ -0,0 +1,92 @@
+package policyplugin
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"sync"
+)
+
+type stateContextKey struct{}
+
+// State 单个网关请求的策略上下文：请求元数据与累计注解，通过 context 传递到上游调用层。
+type State struct {
+	base Request
+
+	mu          sync.Mutex
+	annotations map[string]string
+}
+
+// NewState 以请求元数据（RequestID、Key、用户、分组、平台等）创建请求级状态。
+func NewState(base Request) *State {
+	base.Header = nil
+	base.Body = nil
+	base.Annotations = nil
+	return &State{base: base}
+}
+
+// WithState 将请求级状态放入 context；只有携带状态的上游请求才会触发 pre_forward。
+func WithState(ctx context.Context, state *State) context.Context {
+	return context.WithValue(ctx, stateContextKey{}, state)
+}
+
+// StateFrom 读取 context 中的请求级状态，不存在时返回 nil。
+func StateFrom(ctx context.Context) *State {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(stateContextKey{}).(*State)
+	return state
+}
+
+// Request 基于请求元数据构造指定钩子的请求视图。
+func (s *State) Request(hook Hook) *Request {
+	req := s.base
+	req.Hook = hook
+	req.Annotations = s.Annotations()
+	return &req
+}
+
+// Annotate 合并注解，同名键以后写入者为准。
+func (s *State) Annotate(annotations map[string]string) {
+	if s == nil || len(annotations) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.annotations == nil {
+		s.annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(s.annotations, annotations)
+}
+
+// Annotations 返回注解副本。
+func (s *State) Annotations() map[string]string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.annotations)
+}
+
+// sensitiveHeaders 不暴露给策略的凭证类请求头
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Goog-Api-Key",
+	"Cookie",
+}
+
+// SanitizedHeader 复制请求头并去除凭证类头部。
+func SanitizedHeader(h http.Header) http.Header {
+	out := h.Clone()
+	if out == nil {
+		return http.Header{}
+	}
+	for _, name := range sensitiveHeaders {
+		out.Del(name)
+	}
+	return out
+}
@@ -0,0 +1,8 @@
+package policyplugin
+
+import "github.com/google/wire"
+
+// ProviderSet 提供策略插件执行器
+var ProviderSet = wire.NewSet(
+	ProvideManager,
+)
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type accountAttributionRepository struct {
+	db *sql.DB
+}
+
+func NewAccountAttributionRepository(db *sql.DB) service.AccountAttributionRepository {
+	return &accountAttributionRepository{db: db}
+}
+
+func (r *accountAttributionRepository) Summary(ctx context.Context, accountID int64, start, end time.Time) (*service.AccountAttributionSummary, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account attribution repository db is nil")
+	}
+	var s service.AccountAttributionSummary
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(input_tokens), 0),
+			COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(cache_creation_tokens), 0),
+			COALESCE(SUM(cache_read_tokens), 0),
+			COALESCE(SUM(total_cost), 0),
+			COALESCE(SUM(COALESCE(account_stats_cost, total_cost) * COALESCE(account_rate_multiplier, 1)), 0),
+			COALESCE(SUM(actual_cost), 0),
+			COUNT(DISTINCT user_id),
+			COUNT(DISTINCT api_key_id),
+			COUNT(DISTINCT DATE(created_at))
+		FROM usage_logs
+		WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
+	`, accountID, start, end).Scan(
+		&s.Requests,
+		&s.InputTokens,
+		&s.OutputTokens,
+		&s.CacheCreationTokens,
+		&s.CacheReadTokens,
+		&s.StandardCost,
+		&s.AccountCost,
+		&s.UserCost,
+		&s.DistinctUsers,
+		&s.DistinctAPIKeys,
+		&s.ActiveDays,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.TotalTokens = s.InputTokens + s.OutputTokens + s.CacheCreationTokens + s.CacheReadTokens
+	return &s, nil
+}
+
+func (r *accountAttributionRepository) TopUsers(ctx context.Context, accountID int64, start, end time.Time, limit int) ([]service.AccountAttributionEntry, error) {
+	return r.queryTop(ctx, `
+		SELECT
+			ul.user_id,
+			COALESCE(u.email, ''),
+			0,
+			COUNT(*),
+			COALESCE(SUM(ul.input_tokens + ul.output_tokens + ul.cache_creation_tokens + ul.cache_read_tokens), 0),
+			COALESCE(SUM(COALESCE(ul.account_stats_cost, ul.total_cost) * COALESCE(ul.account_rate_multiplier, 1)), 0) AS account_cost,
+			COALESCE(SUM(ul.actual_cost), 0)
+		FROM usage_logs ul
+		LEFT JOIN users u ON u.id = ul.user_id
+		WHERE ul.account_id = $1 AND ul.created_at >= $2 AND ul.created_at < $3
+		GROUP BY ul.user_id, u.email
+		ORDER BY account_cost DESC, ul.user_id ASC
+		LIMIT $4
+	`, accountID, start, end, limit)
+}
+
+func (r *accountAttributionRepository) TopAPIKeys(ctx context.Context, accountID int64, start, end time.Time, limit int) ([]service.AccountAttributionEntry, error) {
+	return r.queryTop(ctx, `
+		SELECT
+			ul.api_key_id,
+			COALESCE(k.name, ''),
+			COALESCE(k.user_id, ul.user_id),
+			COUNT(*),
+			COALESCE(SUM(ul.input_tokens + ul.output_tokens + ul.cache_creation_tokens + ul.cache_read_tokens), 0),
+			COALESCE(SUM(COALESCE(ul.account_stats_cost, ul.total_cost) * COALESCE(ul.account_rate_multiplier, 1)), 0) AS account_cost,
+			COALESCE(SUM(ul.actual_cost), 0)
+		FROM usage_logs ul
+		LEFT JOIN api_keys k ON k.id = ul.api_key_id
+		WHERE ul.account_id = $1 AND ul.created_at >= $2 AND ul.created_at < $3
+		GROUP BY ul.api_key_id, k.name, k.user_id, ul.user_id
+		ORDER BY account_cost DESC, ul.api_key_id ASC
+		LIMIT $4
+	`, accountID, start, end, limit)
+}
+
+func (r *accountAttributionRepository) queryTop(ctx context.Context, query string, args ...any) ([]service.AccountAttributionEntry, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account attribution repository db is nil")
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.AccountAttributionEntry, 0)
+	for rows.Next() {
+		var e service.AccountAttributionEntry
+		if err := rows.Scan(&e.ID, &e.Name, &e.UserID, &e.Requests, &e.TotalTokens, &e.AccountCost, &e.UserCost); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *accountAttributionRepository) PeakConcurrency(ctx context.Context, accountID int64, start, end time.Time) (int, *time.Time, error) {
+	if r == nil || r.db == nil {
+		return 0, nil, errors.New("account attribution repository db is nil")
+	}
+	// created_at 为请求完成时刻；同一时刻先结束后开始（delta 升序），避免首尾相接的请求被算作重叠
+	var (
+		peak   int
+		peakAt sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, `
+		WITH events AS (
+			SELECT created_at - duration_ms * INTERVAL '1 millisecond' AS ts, 1 AS delta
+			FROM usage_logs
+			WHERE account_id = $1 AND created_at >= $2 AND created_at < $3 AND duration_ms > 0
+			UNION ALL
+			SELECT created_at AS ts, -1 AS delta
+			FROM usage_logs
+			WHERE account_id = $1 AND created_at >= $2 AND created_at < $3 AND duration_ms > 0
+		),
+		running AS (
+			SELECT ts, SUM(delta) OVER (ORDER BY ts, delta ROWS UNBOUNDED PRECEDING) AS active
+			FROM events
+		)
+		SELECT active, ts FROM running ORDER BY active DESC, ts ASC LIMIT 1
+	`, accountID, start, end).Scan(&peak, &peakAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if !peakAt.Valid {
+		return peak, nil, nil
+	}
+	at := peakAt.Time
+	return peak, &at, nil
+}
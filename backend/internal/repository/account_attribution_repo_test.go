@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAttributionRepositorySummary(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &accountAttributionRepository{db: db}
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	mock.ExpectQuery("COUNT\\(DISTINCT api_key_id\\)").
+		WithArgs(int64(7), start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"requests", "input", "output", "cache_creation", "cache_read", "standard", "account", "user", "users", "keys", "days"}).
+			AddRow(int64(3), int64(100), int64(50), int64(10), int64(40), 1.5, 1.2, 2.0, int64(2), int64(3), int64(1)))
+
+	summary, err := repo.Summary(context.Background(), 7, start, end)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), summary.Requests)
+	require.Equal(t, int64(200), summary.TotalTokens)
+	require.InDelta(t, 1.2, summary.AccountCost, 1e-9)
+	require.Equal(t, int64(3), summary.DistinctAPIKeys)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountAttributionRepositoryTopAPIKeys(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &accountAttributionRepository{db: db}
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	mock.ExpectQuery("LEFT JOIN api_keys k").
+		WithArgs(int64(7), start, end, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id", "requests", "tokens", "account_cost", "user_cost"}).
+			AddRow(int64(11), "ci", int64(2), int64(9), int64(900), 3.0, 4.0))
+
+	keys, err := repo.TopAPIKeys(context.Background(), 7, start, end, 5)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, "ci", keys[0].Name)
+	require.Equal(t, int64(2), keys[0].UserID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountAttributionRepositoryPeakConcurrency_NoRows(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &accountAttributionRepository{db: db}
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	mock.ExpectQuery("SUM\\(delta\\) OVER").
+		WithArgs(int64(7), start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"active", "ts"}))
+
+	peak, at, err := repo.PeakConcurrency(context.Background(), 7, start, end)
+	require.NoError(t, err)
+	require.Zero(t, peak)
+	require.Nil(t, at)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
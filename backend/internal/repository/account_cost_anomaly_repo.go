@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// accountCostAnomalyRepository 账号成本异常仓储（raw SQL）。
+type accountCostAnomalyRepository struct {
+	db *sql.DB
+}
+
+// NewAccountCostAnomalyRepository 创建账号成本异常仓储。
+func NewAccountCostAnomalyRepository(db *sql.DB) service.AccountCostAnomalyRepository {
+	return &accountCostAnomalyRepository{db: db}
+}
+
+// costAnomalyCostExpr 上游成本口径与看板账号成本一致；模型按账号实际服务的上游模型统计。
+const (
+	costAnomalyCostExpr  = `COALESCE(account_stats_cost, total_cost) * COALESCE(account_rate_multiplier, 1)`
+	costAnomalyModelExpr = `COALESCE(NULLIF(upstream_model, ''), model)`
+)
+
+const accountCostAnomalySelectColumns = `
+  x.id, x.account_id, COALESCE(a.name, ''), x.model,
+  x.baseline_requests, x.baseline_avg_cost, x.baseline_p95_cost,
+  x.recent_requests, x.recent_avg_cost, x.recent_p95_cost,
+  x.deviation_ratio, x.sample_request_ids, x.throttled, x.status, x.review_note,
+  x.reviewed_by, x.detected_at, x.last_detected_at, x.reviewed_at`
+
+// ComputeCostStats 只扫描近期窗口内达到最少请求数的账号，依赖 usage_logs (account_id, created_at) 复合索引。
+func (r *accountCostAnomalyRepository) ComputeCostStats(ctx context.Context, baselineSince, recentSince time.Time, minRecentRequests int) ([]*service.AccountCostStats, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account cost anomaly repository db is nil")
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		WITH costs AS (
+			SELECT account_id, `+costAnomalyModelExpr+` AS model, created_at, `+costAnomalyCostExpr+` AS cost
+			FROM usage_logs
+			WHERE created_at >= $1
+				AND account_id IN (
+					SELECT account_id FROM usage_logs
+					WHERE created_at >= $2
+					GROUP BY account_id
+					HAVING COUNT(*) >= $3
+				)
+		)
+		SELECT c.account_id, COALESCE(a.name, ''), c.model,
+			COUNT(*) FILTER (WHERE c.created_at < $2),
+			COALESCE(AVG(c.cost) FILTER (WHERE c.created_at < $2), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY c.cost) FILTER (WHERE c.created_at < $2), 0),
+			COUNT(*) FILTER (WHERE c.created_at >= $2),
+			COALESCE(AVG(c.cost) FILTER (WHERE c.created_at >= $2), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY c.cost) FILTER (WHERE c.created_at >= $2), 0)
+		FROM costs c
+		LEFT JOIN accounts a ON a.id = c.account_id
+		GROUP BY c.account_id, a.name, c.model
+		HAVING COUNT(*) FILTER (WHERE c.created_at >= $2) >= $3
+	`, baselineSince, recentSince, minRecentRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.AccountCostStats, 0)
+	for rows.Next() {
+		st := &service.AccountCostStats{}
+		if err := rows.Scan(
+			&st.AccountID, &st.AccountName, &st.Model,
+			&st.BaselineRequests, &st.BaselineAvgCost, &st.BaselineP95Cost,
+			&st.RecentRequests, &st.RecentAvgCost, &st.RecentP95Cost,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
+
+func (r *accountCostAnomalyRepository) ListSampleRequestIDs(ctx context.Context, accountID int64, model string, since time.Time, limit int) ([]string, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account cost anomaly repository db is nil")
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT request_id
+		FROM usage_logs
+		WHERE account_id = $1 AND `+costAnomalyModelExpr+` = $2 AND created_at >= $3
+		ORDER BY `+costAnomalyCostExpr+` DESC, id DESC
+		LIMIT $4
+	`, accountID, model, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make([]string, 0, limit)
+	for rows.Next() {
+		var id sql.NullString
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		if id.Valid && id.String != "" {
+			ids = append(ids, id.String)
+		}
+	}
+	return ids, rows.Err()
+}
+
+func (r *accountCostAnomalyRepository) UpsertOpen(ctx context.Context, anomaly *service.AccountCostAnomaly) (*service.AccountCostAnomaly, bool, error) {
+	if r == nil || r.db == nil {
+		return nil, false, errors.New("account cost anomaly repository db is nil")
+	}
+	if anomaly == nil {
+		return nil, false, fmt.Errorf("nil cost anomaly")
+	}
+	samples := anomaly.SampleRequestIDs
+	if samples == nil {
+		samples = []string{}
+	}
+	// 已有 open 记录时只刷新指标；throttled 保持首次检测时的值
+	var (
+		id       int64
+		inserted bool
+	)
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO account_cost_anomalies (
+			account_id, model,
+			baseline_requests, baseline_avg_cost, baseline_p95_cost,
+			recent_requests, recent_avg_cost, recent_p95_cost,
+			deviation_ratio, sample_request_ids, throttled, status, detected_at, last_detected_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'open', $12, $12)
+		ON CONFLICT (account_id, model) WHERE status = 'open' DO UPDATE SET
+			baseline_requests = EXCLUDED.baseline_requests,
+			baseline_avg_cost = EXCLUDED.baseline_avg_cost,
+			baseline_p95_cost = EXCLUDED.baseline_p95_cost,
+			recent_requests = EXCLUDED.recent_requests,
+			recent_avg_cost = EXCLUDED.recent_avg_cost,
+			recent_p95_cost = EXCLUDED.recent_p95_cost,
+			deviation_ratio = EXCLUDED.deviation_ratio,
+			sample_request_ids = EXCLUDED.sample_request_ids,
+			last_detected_at = EXCLUDED.last_detected_at
+		RETURNING id, (xmax = 0)
+	`,
+		anomaly.AccountID, anomaly.Model,
+		anomaly.BaselineRequests, anomaly.BaselineAvgCost, anomaly.BaselineP95Cost,
+		anomaly.RecentRequests, anomaly.RecentAvgCost, anomaly.RecentP95Cost,
+		anomaly.DeviationRatio, pq.Array(samples), anomaly.Throttled, anomaly.LastDetectedAt,
+	).Scan(&id, &inserted)
+	if err != nil {
+		return nil, false, err
+	}
+	saved, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	return saved, inserted, nil
+}
+
+func (r *accountCostAnomalyRepository) List(ctx context.Context, filter *service.AccountCostAnomalyFilter) (*service.AccountCostAnomalyList, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account cost anomaly repository db is nil")
+	}
+	if filter == nil {
+		filter = &service.AccountCostAnomalyFilter{}
+	}
+	page, pageSize := filter.Page, filter.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	conditions := make([]string, 0, 2)
+	args := make([]any, 0, 4)
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, "x.status = $"+itoa(len(args)))
+	}
+	if filter.AccountID > 0 {
+		args = append(args, filter.AccountID)
+		conditions = append(conditions, "x.account_id = $"+itoa(len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM account_cost_anomalies x "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	rows, err := r.db.QueryContext(ctx, "SELECT"+accountCostAnomalySelectColumns+`
+FROM account_cost_anomalies x
+LEFT JOIN accounts a ON a.id = x.account_id
+`+where+`
+ORDER BY x.last_detected_at DESC, x.id DESC
+LIMIT $`+itoa(len(args)-1)+` OFFSET $`+itoa(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.AccountCostAnomaly, 0)
+	for rows.Next() {
+		item, err := scanAccountCostAnomaly(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &service.AccountCostAnomalyList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func (r *accountCostAnomalyRepository) GetByID(ctx context.Context, id int64) (*service.AccountCostAnomaly, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account cost anomaly repository db is nil")
+	}
+	row := r.db.QueryRowContext(ctx, "SELECT"+accountCostAnomalySelectColumns+`
+FROM account_cost_anomalies x
+LEFT JOIN accounts a ON a.id = x.account_id
+WHERE x.id = $1`, id)
+	item, err := scanAccountCostAnomaly(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrAccountCostAnomalyNotFound
+	}
+	return item, err
+}
+
+func (r *accountCostAnomalyRepository) MarkReviewed(ctx context.Context, id int64, reviewerID int64, note string) (*service.AccountCostAnomaly, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account cost anomaly repository db is nil")
+	}
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE account_cost_anomalies
+		SET status = 'reviewed', review_note = $2, reviewed_by = NULLIF($3::bigint, 0), reviewed_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`, id, note, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, service.ErrAccountCostAnomalyNotFound
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *accountCostAnomalyRepository) CountOpenThrottled(ctx context.Context, accountID int64) (int, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("account cost anomaly repository db is nil")
+	}
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM account_cost_anomalies
+		WHERE account_id = $1 AND status = 'open' AND throttled
+	`, accountID).Scan(&count)
+	return count, err
+}
+
+func scanAccountCostAnomaly(row rowScanner) (*service.AccountCostAnomaly, error) {
+	item := &service.AccountCostAnomaly{}
+	var (
+		samples    []string
+		reviewedBy sql.NullInt64
+		reviewedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&item.ID,
+		&item.AccountID,
+		&item.AccountName,
+		&item.Model,
+		&item.BaselineRequests,
+		&item.BaselineAvgCost,
+		&item.BaselineP95Cost,
+		&item.RecentRequests,
+		&item.RecentAvgCost,
+		&item.RecentP95Cost,
+		&item.DeviationRatio,
+		pq.Array(&samples),
+		&item.Throttled,
+		&item.Status,
+		&item.ReviewNote,
+		&reviewedBy,
+		&item.DetectedAt,
+		&item.LastDetectedAt,
+		&reviewedAt,
+	); err != nil {
+		return nil, err
+	}
+	if samples == nil {
+		samples = []string{}
+	}
+	item.SampleRequestIDs = samples
+	if reviewedBy.Valid {
+		v := reviewedBy.Int64
+		item.ReviewedBy = &v
+	}
+	if reviewedAt.Valid {
+		t := reviewedAt.Time
+		item.ReviewedAt = &t
+	}
+	return item, nil
+}
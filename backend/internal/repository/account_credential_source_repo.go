@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type accountCredentialSourceRepository struct {
+	db *sql.DB
+}
+
+func NewAccountCredentialSourceRepository(db *sql.DB) service.AccountCredentialSourceRepository {
+	return &accountCredentialSourceRepository{db: db}
+}
+
+const accountCredentialSourceSelectColumns = `
+	s.account_id, s.provider, s.secret_path, s.field_map, s.last_synced_at,
+	s.last_version, s.last_error, s.created_at, s.updated_at`
+
+func (r *accountCredentialSourceRepository) GetByAccountID(ctx context.Context, accountID int64) (*service.AccountCredentialSource, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account credential source repository db is nil")
+	}
+	row := r.db.QueryRowContext(ctx, "SELECT"+accountCredentialSourceSelectColumns+`
+FROM account_credential_sources s
+JOIN accounts a ON a.id = s.account_id
+WHERE s.account_id = $1 AND a.deleted_at IS NULL`, accountID)
+	item, err := scanAccountCredentialSource(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrCredentialSourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *accountCredentialSourceRepository) Upsert(ctx context.Context, source *service.AccountCredentialSource) (*service.AccountCredentialSource, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account credential source repository db is nil")
+	}
+	if source == nil {
+		return nil, errors.New("account credential source is nil")
+	}
+	fieldMap := source.FieldMap
+	if fieldMap == nil {
+		fieldMap = map[string]string{}
+	}
+	payload, err := json.Marshal(fieldMap)
+	if err != nil {
+		return nil, err
+	}
+
+	// INSERT ... SELECT 保证账号存在且未软删除；不存在时无返回行。
+	// 映射变化后清空同步状态，避免展示旧路径的版本与错误。
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO account_credential_sources AS s (account_id, provider, secret_path, field_map)
+		SELECT a.id, $2, $3, $4::jsonb
+		FROM accounts a
+		WHERE a.id = $1 AND a.deleted_at IS NULL
+		ON CONFLICT (account_id) DO UPDATE SET
+			provider = EXCLUDED.provider,
+			secret_path = EXCLUDED.secret_path,
+			field_map = EXCLUDED.field_map,
+			last_synced_at = CASE
+				WHEN s.provider = EXCLUDED.provider AND s.secret_path = EXCLUDED.secret_path THEN s.last_synced_at
+				ELSE NULL
+			END,
+			last_version = CASE
+				WHEN s.provider = EXCLUDED.provider AND s.secret_path = EXCLUDED.secret_path THEN s.last_version
+				ELSE ''
+			END,
+			last_error = '',
+			updated_at = NOW()
+		RETURNING`+accountCredentialSourceSelectColumns,
+		source.AccountID, source.Provider, source.SecretPath, string(payload))
+	item, err := scanAccountCredentialSource(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *accountCredentialSourceRepository) Delete(ctx context.Context, accountID int64) error {
+	if r == nil || r.db == nil {
+		return errors.New("account credential source repository db is nil")
+	}
+	res, err := r.db.ExecContext(ctx, "DELETE FROM account_credential_sources WHERE account_id = $1", accountID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrCredentialSourceNotFound
+	}
+	return nil
+}
+
+func (r *accountCredentialSourceRepository) List(ctx context.Context) ([]*service.AccountCredentialSource, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account credential source repository db is nil")
+	}
+	rows, err := r.db.QueryContext(ctx, "SELECT"+accountCredentialSourceSelectColumns+`
+FROM account_credential_sources s
+JOIN accounts a ON a.id = s.account_id
+WHERE a.deleted_at IS NULL
+ORDER BY s.account_id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*service.AccountCredentialSource
+	for rows.Next() {
+		item, err := scanAccountCredentialSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *accountCredentialSourceRepository) RecordSyncResult(ctx context.Context, accountID int64, syncedAt time.Time, version, syncErr string) error {
+	if r == nil || r.db == nil {
+		return errors.New("account credential source repository db is nil")
+	}
+	var err error
+	if syncErr == "" {
+		_, err = r.db.ExecContext(ctx, `
+			UPDATE account_credential_sources
+			SET last_synced_at = $2, last_version = $3, last_error = '', updated_at = NOW()
+			WHERE account_id = $1`, accountID, syncedAt.UTC(), truncateString(version, 128))
+	} else {
+		// 失败时保留上次成功的版本与时间，只记录错误
+		_, err = r.db.ExecContext(ctx, `
+			UPDATE account_credential_sources
+			SET last_error = $2, updated_at = NOW()
+			WHERE account_id = $1`, accountID, truncateString(syncErr, 1000))
+	}
+	return err
+}
+
+func scanAccountCredentialSource(row rowScanner) (*service.AccountCredentialSource, error) {
+	item := &service.AccountCredentialSource{}
+	var (
+		fieldMap     []byte
+		lastSyncedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&item.AccountID,
+		&item.Provider,
+		&item.SecretPath,
+		&fieldMap,
+		&lastSyncedAt,
+		&item.LastVersion,
+		&item.LastError,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	item.FieldMap = map[string]string{}
+	if len(fieldMap) > 0 {
+		if err := json.Unmarshal(fieldMap, &item.FieldMap); err != nil {
+			return nil, err
+		}
+	}
+	if lastSyncedAt.Valid {
+		t := lastSyncedAt.Time
+		item.LastSyncedAt = &t
+	}
+	return item, nil
+}
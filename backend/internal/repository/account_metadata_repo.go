@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type accountMetadataRepository struct {
+	db *sql.DB
+}
+
+func NewAccountMetadataRepository(db *sql.DB) service.AccountMetadataRepository {
+	return &accountMetadataRepository{db: db}
+}
+
+const accountMetadataSelectColumns = `
+	m.account_id, m.owner_name, m.owner_contact, m.purchase_date, m.renewal_date, m.monthly_cost,
+	m.cost_currency, m.notes, m.renewal_reminded_for, m.created_at, m.updated_at`
+
+func (r *accountMetadataRepository) GetByAccountID(ctx context.Context, accountID int64) (*service.AccountMetadata, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account metadata repository db is nil")
+	}
+	row := r.db.QueryRowContext(ctx, "SELECT"+accountMetadataSelectColumns+", a.name, a.platform"+`
+FROM account_metadata m
+JOIN accounts a ON a.id = m.account_id
+WHERE m.account_id = $1 AND a.deleted_at IS NULL`, accountID)
+	item, err := scanAccountMetadata(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrAccountMetadataNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *accountMetadataRepository) Upsert(ctx context.Context, metadata *service.AccountMetadata) (*service.AccountMetadata, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account metadata repository db is nil")
+	}
+	if metadata == nil {
+		return nil, errors.New("account metadata is nil")
+	}
+
+	// INSERT ... SELECT 保证账号存在且未软删除；不存在时无返回行。
+	row := r.db.QueryRowContext(ctx, `
+		WITH upserted AS (
+			INSERT INTO account_metadata (
+				account_id, owner_name, owner_contact, purchase_date, renewal_date,
+				monthly_cost, cost_currency, notes
+			)
+			SELECT a.id, $2, $3, $4::date, $5::date, $6, $7, $8
+			FROM accounts a
+			WHERE a.id = $1 AND a.deleted_at IS NULL
+			ON CONFLICT (account_id) DO UPDATE SET
+				owner_name = EXCLUDED.owner_name,
+				owner_contact = EXCLUDED.owner_contact,
+				purchase_date = EXCLUDED.purchase_date,
+				renewal_date = EXCLUDED.renewal_date,
+				monthly_cost = EXCLUDED.monthly_cost,
+				cost_currency = EXCLUDED.cost_currency,
+				notes = EXCLUDED.notes,
+				updated_at = NOW()
+			RETURNING *
+		)
+		SELECT`+accountMetadataSelectColumns+`, a.name, a.platform
+		FROM upserted m
+		JOIN accounts a ON a.id = m.account_id
+	`, metadata.AccountID, metadata.OwnerName, metadata.OwnerContact,
+		accountMetadataDateArg(metadata.PurchaseDate), accountMetadataDateArg(metadata.RenewalDate),
+		metadata.MonthlyCost, metadata.CostCurrency, metadata.Notes)
+	item, err := scanAccountMetadata(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *accountMetadataRepository) Delete(ctx context.Context, accountID int64) error {
+	if r == nil || r.db == nil {
+		return errors.New("account metadata repository db is nil")
+	}
+	res, err := r.db.ExecContext(ctx, "DELETE FROM account_metadata WHERE account_id = $1", accountID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrAccountMetadataNotFound
+	}
+	return nil
+}
+
+func (r *accountMetadataRepository) List(ctx context.Context, filter *service.AccountMetadataFilter) (*service.AccountMetadataList, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account metadata repository db is nil")
+	}
+	if filter == nil {
+		filter = &service.AccountMetadataFilter{}
+	}
+
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	where, args := buildAccountMetadataWhere(filter)
+	from := "FROM account_metadata m\nJOIN accounts a ON a.id = m.account_id\n" + where
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	orderBy := "ORDER BY m.updated_at DESC, m.account_id DESC"
+	if filter.RenewalBefore != nil {
+		orderBy = "ORDER BY m.renewal_date ASC, m.account_id ASC"
+	}
+	offset := (page - 1) * pageSize
+	argsWithLimit := append(args, pageSize, offset)
+	query := "SELECT" + accountMetadataSelectColumns + ", a.name, a.platform\n" + from + "\n" + orderBy + `
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, argsWithLimit...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.AccountMetadata, 0, pageSize)
+	for rows.Next() {
+		item, err := scanAccountMetadata(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &service.AccountMetadataList{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func buildAccountMetadataWhere(filter *service.AccountMetadataFilter) (string, []any) {
+	conds := []string{"a.deleted_at IS NULL"}
+	var args []any
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		args = append(args, "%"+search+"%")
+		p := "$" + itoa(len(args))
+		conds = append(conds, "(m.owner_name ILIKE "+p+" OR m.owner_contact ILIKE "+p+" OR a.name ILIKE "+p+")")
+	}
+	if filter.RenewalBefore != nil {
+		args = append(args, filter.RenewalBefore.Format(service.AccountMetadataDateLayout))
+		conds = append(conds, "m.renewal_date < $"+itoa(len(args))+"::date")
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *accountMetadataRepository) ListRenewalsDue(ctx context.Context, dueBy time.Time, limit int) ([]*service.AccountRenewalDue, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account metadata repository db is nil")
+	}
+	if limit <= 0 {
+		limit = 200
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.account_id, a.name, a.platform, m.owner_name, m.owner_contact,
+			m.renewal_date, m.monthly_cost, m.cost_currency
+		FROM account_metadata m
+		JOIN accounts a ON a.id = m.account_id
+		WHERE a.deleted_at IS NULL
+			AND m.renewal_date IS NOT NULL
+			AND m.renewal_date <= $1::date
+			AND m.renewal_reminded_for IS DISTINCT FROM m.renewal_date
+		ORDER BY m.renewal_date ASC, m.account_id ASC
+		LIMIT $2
+	`, dueBy.Format(service.AccountMetadataDateLayout), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*service.AccountRenewalDue
+	for rows.Next() {
+		var (
+			item        service.AccountRenewalDue
+			monthlyCost sql.NullFloat64
+		)
+		if err := rows.Scan(&item.AccountID, &item.AccountName, &item.AccountPlatform, &item.OwnerName, &item.OwnerContact,
+			&item.RenewalDate, &monthlyCost, &item.CostCurrency); err != nil {
+			return nil, fmt.Errorf("scan account renewal: %w", err)
+		}
+		if monthlyCost.Valid {
+			v := monthlyCost.Float64
+			item.MonthlyCost = &v
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *accountMetadataRepository) MarkRenewalReminded(ctx context.Context, accountID int64, renewalDate time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("account metadata repository db is nil")
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE account_metadata
+		SET renewal_reminded_for = $2::date
+		WHERE account_id = $1
+	`, accountID, renewalDate.Format(service.AccountMetadataDateLayout))
+	return err
+}
+
+func scanAccountMetadata(row rowScanner) (*service.AccountMetadata, error) {
+	var (
+		item               service.AccountMetadata
+		purchaseDate       sql.NullTime
+		renewalDate        sql.NullTime
+		monthlyCost        sql.NullFloat64
+		renewalRemindedFor sql.NullTime
+	)
+	if err := row.Scan(
+		&item.AccountID, &item.OwnerName, &item.OwnerContact, &purchaseDate, &renewalDate, &monthlyCost,
+		&item.CostCurrency, &item.Notes, &renewalRemindedFor, &item.CreatedAt, &item.UpdatedAt,
+		&item.AccountName, &item.AccountPlatform,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan account metadata: %w", err)
+	}
+	if purchaseDate.Valid {
+		v := purchaseDate.Time
+		item.PurchaseDate = &v
+	}
+	if renewalDate.Valid {
+		v := renewalDate.Time
+		item.RenewalDate = &v
+	}
+	if monthlyCost.Valid {
+		v := monthlyCost.Float64
+		item.MonthlyCost = &v
+	}
+	if renewalRemindedFor.Valid {
+		v := renewalRemindedFor.Time
+		item.RenewalRemindedFor = &v
+	}
+	return &item, nil
+}
+
+// accountMetadataDateArg 以 YYYY-MM-DD 传参，避免 DATE 列受会话时区影响。
+func accountMetadataDateArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(service.AccountMetadataDateLayout)
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+var accountMetadataTestColumns = []string{
+	"account_id", "owner_name", "owner_contact", "purchase_date", "renewal_date", "monthly_cost",
+	"cost_currency", "notes", "renewal_reminded_for", "created_at", "updated_at", "name", "platform",
+}
+
+func TestAccountMetadataRepositoryUpsert_PassesDatesAsDayStrings(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &accountMetadataRepository{db: db}
+
+	renewal := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	cost := 20.0
+	now := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("INSERT INTO account_metadata").
+		WithArgs(int64(7), "alice", "alice@example.com", nil, "2026-11-01", &cost, "USD", "shared seat").
+		WillReturnRows(sqlmock.NewRows(accountMetadataTestColumns).
+			AddRow(int64(7), "alice", "alice@example.com", nil, renewal, cost, "USD", "shared seat", nil, now, now, "team-a", "anthropic"))
+
+	got, err := repo.Upsert(context.Background(), &service.AccountMetadata{
+		AccountID:    7,
+		OwnerName:    "alice",
+		OwnerContact: "alice@example.com",
+		RenewalDate:  &renewal,
+		MonthlyCost:  &cost,
+		CostCurrency: "USD",
+		Notes:        "shared seat",
+	})
+	require.NoError(t, err)
+	require.Nil(t, got.PurchaseDate)
+	require.Equal(t, renewal, *got.RenewalDate)
+	require.Equal(t, 20.0, *got.MonthlyCost)
+	require.Equal(t, "team-a", got.AccountName)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountMetadataRepositoryUpsert_MissingAccount(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &accountMetadataRepository{db: db}
+
+	mock.ExpectQuery("INSERT INTO account_metadata").
+		WillReturnRows(sqlmock.NewRows(accountMetadataTestColumns))
+
+	_, err := repo.Upsert(context.Background(), &service.AccountMetadata{AccountID: 404})
+	require.ErrorIs(t, err, service.ErrAccountNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountMetadataRepositoryListRenewalsDue(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &accountMetadataRepository{db: db}
+
+	renewal := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("renewal_reminded_for IS DISTINCT FROM m.renewal_date").
+		WithArgs("2026-10-22", 200).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "name", "platform", "owner_name", "owner_contact", "renewal_date", "monthly_cost", "cost_currency"}).
+			AddRow(int64(1), "team-a", "anthropic", "ops", "", renewal, nil, ""))
+
+	items, err := repo.ListRenewalsDue(context.Background(), time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC), 0)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, int64(1), items[0].AccountID)
+	require.Nil(t, items[0].MonthlyCost)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildAccountMetadataWhere(t *testing.T) {
+	before := time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)
+	where, args := buildAccountMetadataWhere(&service.AccountMetadataFilter{Search: "alice", RenewalBefore: &before})
+	require.Equal(t, "WHERE a.deleted_at IS NULL AND (m.owner_name ILIKE $1 OR m.owner_contact ILIKE $1 OR a.name ILIKE $1) AND m.renewal_date < $2::date", where)
+	require.Equal(t, []any{"%alice%", "2026-10-23"}, args)
+}
@@ -195,11 +195,16 @@ func (r *accountRepository) CreateWithAccountGroups(ctx context.Context, account
 		for i := range groups {
 			groups[i].AccountID = account.ID
 			groupIDs = append(groupIDs, groups[i].GroupID)
-			builders = append(builders, txClient.AccountGroup.Create().
+			builder := txClient.AccountGroup.Create().
 				SetAccountID(account.ID).
 				SetGroupID(groups[i].GroupID).
-				SetPriority(groups[i].Priority),
-			)
+				SetPriority(groups[i].Priority).
+				SetNillablePriorityOverride(groups[i].PriorityOverride).
+				SetNillableConcurrencyCap(groups[i].ConcurrencyCap)
+			if groups[i].Weight > 0 {
+				builder.SetWeight(groups[i].Weight)
+			}
+			builders = append(builders, builder)
 		}
 		if _, err := txClient.AccountGroup.CreateBulk(builders...).Save(ctx); err != nil {
 			return err
@@ -429,7 +434,8 @@ func (r *accountRepository) updateAccount(ctx context.Context, account *service.
 		}
 	}
 
-	updated, err := r.updateLockedAccount(ctx, client, account, explicitProbeEnabled)
+	// 调度路径拿到的账号可能带有分组成员覆盖（优先级/准入上限/权重），落库前还原为账号本身的取值
+	updated, err := r.updateLockedAccount(ctx, client, account.WithoutGroupMembership(), explicitProbeEnabled)
 	if err != nil {
 		return translatePersistenceError(err, service.ErrAccountNotFound, nil)
 	}
@@ -1602,6 +1608,58 @@ func (r *accountRepository) RemoveFromGroup(ctx context.Context, accountID, grou
 	return nil
 }
 
+// UpdateAccountGroupMembership 更新账号在分组中的成员调度覆盖，并刷新该分组的调度快照。
+func (r *accountRepository) UpdateAccountGroupMembership(ctx context.Context, accountID, groupID int64, update service.AccountGroupMembershipUpdate) (*service.AccountGroup, error) {
+	builder := r.client.AccountGroup.Update().
+		Where(
+			dbaccountgroup.AccountIDEQ(accountID),
+			dbaccountgroup.GroupIDEQ(groupID),
+		)
+	if update.ClearPriorityOverride {
+		builder.ClearPriorityOverride()
+	} else if update.PriorityOverride != nil {
+		builder.SetPriorityOverride(*update.PriorityOverride)
+	}
+	if update.Weight != nil {
+		builder.SetWeight(*update.Weight)
+	}
+	if update.ClearConcurrencyCap {
+		builder.ClearConcurrencyCap()
+	} else if update.ConcurrencyCap != nil {
+		builder.SetConcurrencyCap(*update.ConcurrencyCap)
+	}
+	affected, err := builder.Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, service.ErrAccountGroupMembershipNotFound
+	}
+
+	entry, err := r.client.AccountGroup.Query().
+		Where(
+			dbaccountgroup.AccountIDEQ(accountID),
+			dbaccountgroup.GroupIDEQ(groupID),
+		).
+		Only(ctx)
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrAccountGroupMembershipNotFound, nil)
+	}
+	payload := buildSchedulerGroupPayload([]int64{groupID})
+	if err := enqueueSchedulerOutbox(ctx, r.sql, service.SchedulerOutboxEventAccountGroupsChanged, &accountID, nil, payload); err != nil {
+		logger.LegacyPrintf("repository.account", "[SchedulerOutbox] enqueue membership update failed: account=%d group=%d err=%v", accountID, groupID, err)
+	}
+	return &service.AccountGroup{
+		AccountID:        entry.AccountID,
+		GroupID:          entry.GroupID,
+		Priority:         entry.Priority,
+		PriorityOverride: entry.PriorityOverride,
+		Weight:           entry.Weight,
+		ConcurrencyCap:   entry.ConcurrencyCap,
+		CreatedAt:        entry.CreatedAt,
+	}, nil
+}
+
 func (r *accountRepository) GetGroups(ctx context.Context, accountID int64) ([]service.Group, error) {
 	groups, err := r.client.Group.Query().
 		Where(
@@ -1620,10 +1678,19 @@ func (r *accountRepository) GetGroups(ctx context.Context, accountID int64) ([]s
 }
 
 func (r *accountRepository) BindGroups(ctx context.Context, accountID int64, groupIDs []int64) error {
-	existingGroupIDs, err := r.loadAccountGroupIDs(ctx, accountID)
+	existing, err := r.client.AccountGroup.Query().
+		Where(dbaccountgroup.AccountIDEQ(accountID)).
+		All(ctx)
 	if err != nil {
 		return err
 	}
+	// 重新绑定时保留仍在列表中的分组的成员调度覆盖
+	existingGroupIDs := make([]int64, 0, len(existing))
+	existingByGroup := make(map[int64]*dbent.AccountGroup, len(existing))
+	for _, entry := range existing {
+		existingGroupIDs = append(existingGroupIDs, entry.GroupID)
+		existingByGroup[entry.GroupID] = entry
+	}
 	// 使用事务保证删除旧绑定与创建新绑定的原子性
 	tx, err := r.client.Tx(ctx)
 	if err != nil && !errors.Is(err, dbent.ErrTxStarted) {
@@ -1652,11 +1719,17 @@ func (r *accountRepository) BindGroups(ctx context.Context, accountID int64, gro
 
 	builders := make([]*dbent.AccountGroupCreate, 0, len(groupIDs))
 	for i, groupID := range groupIDs {
-		builders = append(builders, txClient.AccountGroup.Create().
+		builder := txClient.AccountGroup.Create().
 			SetAccountID(accountID).
 			SetGroupID(groupID).
-			SetPriority(i+1),
-		)
+			SetPriority(i + 1)
+		if prev := existingByGroup[groupID]; prev != nil {
+			builder.
+				SetNillablePriorityOverride(prev.PriorityOverride).
+				SetWeight(prev.Weight).
+				SetNillableConcurrencyCap(prev.ConcurrencyCap)
+		}
+		builders = append(builders, builder)
 	}
 
 	if _, err := txClient.AccountGroup.CreateBulk(builders...).Save(ctx); err != nil {
@@ -3039,11 +3112,14 @@ func (r *accountRepository) loadAccountGroups(ctx context.Context, accountIDs []
 		for _, ag := range entries {
 			groupSvc := groupMap[ag.GroupID]
 			agSvc := service.AccountGroup{
-				AccountID: ag.AccountID,
-				GroupID:   ag.GroupID,
-				Priority:  ag.Priority,
-				CreatedAt: ag.CreatedAt,
-				Group:     groupSvc,
+				AccountID:        ag.AccountID,
+				GroupID:          ag.GroupID,
+				Priority:         ag.Priority,
+				PriorityOverride: ag.PriorityOverride,
+				Weight:           ag.Weight,
+				ConcurrencyCap:   ag.ConcurrencyCap,
+				CreatedAt:        ag.CreatedAt,
+				Group:            groupSvc,
 			}
 			accountGroupsByAccount[ag.AccountID] = append(accountGroupsByAccount[ag.AccountID], agSvc)
 			groupIDsByAccount[ag.AccountID] = append(groupIDsByAccount[ag.AccountID], ag.GroupID)
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// accountSmokeTestRepository 账号冒烟测试仓储（raw SQL）。
+type accountSmokeTestRepository struct {
+	db *sql.DB
+}
+
+// NewAccountSmokeTestRepository 创建账号冒烟测试仓储。
+func NewAccountSmokeTestRepository(db *sql.DB) service.AccountSmokeTestRepository {
+	return &accountSmokeTestRepository{db: db}
+}
+
+var errAccountSmokeTestRepoNilDB = errors.New("account smoke test repository db is nil")
+
+func (r *accountSmokeTestRepository) CreateResult(ctx context.Context, result *service.AccountSmokeTestResult) error {
+	if r == nil || r.db == nil {
+		return errAccountSmokeTestRepoNilDB
+	}
+	if result == nil {
+		return fmt.Errorf("nil smoke test result")
+	}
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO account_smoke_test_results (account_id, status, latency_ms, error_message, started_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, created_at
+	`, result.AccountID, result.Status, result.LatencyMs, result.ErrorMessage, result.StartedAt).Scan(&result.ID, &result.CreatedAt)
+}
+
+// DailyTrend 按数据库会话时区（与 timezone 配置一致）分天汇总。
+func (r *accountSmokeTestRepository) DailyTrend(ctx context.Context, accountID int64, since time.Time) ([]*service.AccountSmokeTestTrendPoint, error) {
+	if r == nil || r.db == nil {
+		return nil, errAccountSmokeTestRepoNilDB
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT to_char(started_at, 'YYYY-MM-DD') AS day,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success'),
+			COALESCE(AVG(latency_ms), 0),
+			COALESCE(MAX(latency_ms), 0),
+			COALESCE((ARRAY_AGG(error_message ORDER BY started_at DESC) FILTER (WHERE status <> 'success'))[1], '')
+		FROM account_smoke_test_results
+		WHERE account_id = $1 AND started_at >= $2
+		GROUP BY day
+		ORDER BY day ASC
+	`, accountID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.AccountSmokeTestTrendPoint, 0)
+	for rows.Next() {
+		p := &service.AccountSmokeTestTrendPoint{}
+		if err := rows.Scan(&p.Date, &p.Runs, &p.Passed, &p.AvgLatencyMs, &p.MaxLatencyMs, &p.LastError); err != nil {
+			return nil, err
+		}
+		p.Failed = p.Runs - p.Passed
+		if p.Runs > 0 {
+			p.SuccessRate = float64(p.Passed) / float64(p.Runs)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *accountSmokeTestRepository) ComputeWindowStats(ctx context.Context, baselineSince, recentSince time.Time) ([]*service.AccountSmokeTestWindowStats, error) {
+	if r == nil || r.db == nil {
+		return nil, errAccountSmokeTestRepoNilDB
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT x.account_id, COALESCE(a.name, ''),
+			COUNT(*) FILTER (WHERE x.started_at < $2),
+			COUNT(*) FILTER (WHERE x.started_at < $2 AND x.status = 'success'),
+			COUNT(*) FILTER (WHERE x.started_at >= $2),
+			COUNT(*) FILTER (WHERE x.started_at >= $2 AND x.status = 'success')
+		FROM account_smoke_test_results x
+		LEFT JOIN accounts a ON a.id = x.account_id
+		WHERE x.started_at >= $1
+		GROUP BY x.account_id, a.name
+	`, baselineSince, recentSince)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.AccountSmokeTestWindowStats, 0)
+	for rows.Next() {
+		st := &service.AccountSmokeTestWindowStats{}
+		if err := rows.Scan(&st.AccountID, &st.AccountName, &st.BaselineRuns, &st.BaselinePassed, &st.RecentRuns, &st.RecentPassed); err != nil {
+			return nil, err
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
+
+// UpsertFlag 已标记时只刷新指标，flagged_at 保持首次标记时间。
+func (r *accountSmokeTestRepository) UpsertFlag(ctx context.Context, flag *service.AccountSmokeTestFlag) (bool, error) {
+	if r == nil || r.db == nil {
+		return false, errAccountSmokeTestRepoNilDB
+	}
+	if flag == nil {
+		return false, fmt.Errorf("nil smoke test flag")
+	}
+	var inserted bool
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO account_smoke_test_flags (
+			account_id, recent_runs, recent_success_rate, baseline_runs, baseline_success_rate, flagged_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (account_id) DO UPDATE SET
+			recent_runs = EXCLUDED.recent_runs,
+			recent_success_rate = EXCLUDED.recent_success_rate,
+			baseline_runs = EXCLUDED.baseline_runs,
+			baseline_success_rate = EXCLUDED.baseline_success_rate,
+			updated_at = EXCLUDED.updated_at
+		RETURNING (xmax = 0)
+	`, flag.AccountID, flag.RecentRuns, flag.RecentSuccessRate, flag.BaselineRuns, flag.BaselineSuccessRate, flag.UpdatedAt).Scan(&inserted)
+	if err != nil {
+		return false, err
+	}
+	return inserted, nil
+}
+
+func (r *accountSmokeTestRepository) DeleteFlag(ctx context.Context, accountID int64) error {
+	if r == nil || r.db == nil {
+		return errAccountSmokeTestRepoNilDB
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM account_smoke_test_flags WHERE account_id = $1`, accountID)
+	return err
+}
+
+func (r *accountSmokeTestRepository) ListFlags(ctx context.Context) ([]*service.AccountSmokeTestFlag, error) {
+	if r == nil || r.db == nil {
+		return nil, errAccountSmokeTestRepoNilDB
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT f.account_id, COALESCE(a.name, ''), f.recent_runs, f.recent_success_rate,
+			f.baseline_runs, f.baseline_success_rate, f.flagged_at, f.updated_at
+		FROM account_smoke_test_flags f
+		LEFT JOIN accounts a ON a.id = f.account_id
+		ORDER BY f.flagged_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.AccountSmokeTestFlag, 0)
+	for rows.Next() {
+		f := &service.AccountSmokeTestFlag{}
+		if err := rows.Scan(
+			&f.AccountID, &f.AccountName, &f.RecentRuns, &f.RecentSuccessRate,
+			&f.BaselineRuns, &f.BaselineSuccessRate, &f.FlaggedAt, &f.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+func (r *accountSmokeTestRepository) DeleteResultsBefore(ctx context.Context, before time.Time) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, errAccountSmokeTestRepoNilDB
+	}
+	res, err := r.db.ExecContext(ctx, `DELETE FROM account_smoke_test_results WHERE started_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type accountSnapshotRepository struct {
+	db *sql.DB
+}
+
+func NewAccountSnapshotRepository(db *sql.DB) service.AccountSnapshotRepository {
+	return &accountSnapshotRepository{db: db}
+}
+
+func (r *accountSnapshotRepository) LatestHashes(ctx context.Context) (map[int64]string, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account snapshot repository db is nil")
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (account_id) account_id, state_hash
+		FROM account_snapshots
+		ORDER BY account_id, captured_at DESC, id DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	hashes := make(map[int64]string)
+	for rows.Next() {
+		var (
+			accountID int64
+			hash      string
+		)
+		if err := rows.Scan(&accountID, &hash); err != nil {
+			return nil, fmt.Errorf("scan account snapshot hash: %w", err)
+		}
+		hashes[accountID] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+func (r *accountSnapshotRepository) InsertBatch(ctx context.Context, snapshots []*service.AccountSnapshot) error {
+	if r == nil || r.db == nil {
+		return errors.New("account snapshot repository db is nil")
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	accountIDs := make([]int64, 0, len(snapshots))
+	capturedAt := make([]string, 0, len(snapshots))
+	hashes := make([]string, 0, len(snapshots))
+	states := make([]string, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot == nil {
+			continue
+		}
+		raw, err := json.Marshal(snapshot.State)
+		if err != nil {
+			return fmt.Errorf("marshal account snapshot state: %w", err)
+		}
+		accountIDs = append(accountIDs, snapshot.AccountID)
+		capturedAt = append(capturedAt, snapshot.CapturedAt.UTC().Format(time.RFC3339Nano))
+		hashes = append(hashes, snapshot.StateHash)
+		states = append(states, string(raw))
+	}
+
+	// JOIN accounts 跳过采集期间被物理删除的账号，避免外键失败导致整批写入失败。
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO account_snapshots (account_id, captured_at, state_hash, state)
+		SELECT s.account_id, s.captured_at::timestamptz, s.state_hash, s.state::jsonb
+		FROM unnest($1::bigint[], $2::text[], $3::text[], $4::text[]) AS s(account_id, captured_at, state_hash, state)
+		JOIN accounts a ON a.id = s.account_id
+	`, pq.Array(accountIDs), pq.Array(capturedAt), pq.Array(hashes), pq.Array(states))
+	return err
+}
+
+func (r *accountSnapshotRepository) ListByAccount(ctx context.Context, accountID int64, before *time.Time, limit int) ([]*service.AccountSnapshot, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("account snapshot repository db is nil")
+	}
+	if limit <= 0 {
+		limit = service.AccountSnapshotTimelineDefaultLimit
+	}
+
+	var beforeArg any
+	if before != nil {
+		beforeArg = *before
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, account_id, captured_at, state_hash, state
+		FROM account_snapshots
+		WHERE account_id = $1 AND ($2::timestamptz IS NULL OR captured_at < $2::timestamptz)
+		ORDER BY captured_at DESC, id DESC
+		LIMIT $3
+	`, accountID, beforeArg, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.AccountSnapshot, 0, limit)
+	for rows.Next() {
+		var (
+			item  service.AccountSnapshot
+			state []byte
+		)
+		if err := rows.Scan(&item.ID, &item.AccountID, &item.CapturedAt, &item.StateHash, &state); err != nil {
+			return nil, fmt.Errorf("scan account snapshot: %w", err)
+		}
+		if err := json.Unmarshal(state, &item.State); err != nil {
+			return nil, fmt.Errorf("decode account snapshot %d: %w", item.ID, err)
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *accountSnapshotRepository) DeleteBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("account snapshot repository db is nil")
+	}
+	if limit <= 0 {
+		return 0, nil
+	}
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM account_snapshots
+		WHERE id IN (
+			SELECT id FROM account_snapshots
+			WHERE captured_at < $1
+			ORDER BY captured_at ASC
+			LIMIT $2
+		)
+	`, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountSnapshotRepositoryInsertBatch_SingleStatement(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &accountSnapshotRepository{db: db}
+
+	capturedAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO account_snapshots").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.InsertBatch(context.Background(), []*service.AccountSnapshot{
+		{AccountID: 1, CapturedAt: capturedAt, StateHash: "a", State: service.AccountSnapshotState{Status: service.StatusActive}},
+		{AccountID: 2, CapturedAt: capturedAt, StateHash: "b", State: service.AccountSnapshotState{Status: service.StatusError}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountSnapshotRepositoryListByAccount_DecodesState(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &accountSnapshotRepository{db: db}
+
+	capturedAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM account_snapshots").
+		WithArgs(int64(7), nil, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "captured_at", "state_hash", "state"}).
+			AddRow(int64(11), int64(7), capturedAt, "h", []byte(`{"status":"active","plan_type":"pro","concurrency":3,"flags":{"mixed_scheduling":true}}`)))
+
+	items, err := repo.ListByAccount(context.Background(), 7, nil, 3)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, "pro", items[0].State.PlanType)
+	require.Equal(t, 3, items[0].State.Concurrency)
+	require.True(t, items[0].State.Flags["mixed_scheduling"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
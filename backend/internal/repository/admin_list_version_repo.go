@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type adminListVersionRepository struct {
+	db *sql.DB
+}
+
+func NewAdminListVersionRepository(db *sql.DB) service.AdminListVersionRepository {
+	return &adminListVersionRepository{db: db}
+}
+
+func (r *adminListVersionRepository) AccountsFingerprint(ctx context.Context) (string, error) {
+	if r == nil || r.db == nil {
+		return "", errors.New("admin list version repository db is nil")
+	}
+	var (
+		count    int64
+		latest   sql.NullTime
+		bindings int64
+		checksum int64
+	)
+	// account_groups 没有 updated_at，用行数 + 行内容哈希和感知绑定与优先级变化
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM accounts WHERE deleted_at IS NULL),
+			(SELECT MAX(GREATEST(updated_at, COALESCE(deleted_at, updated_at))) FROM accounts),
+			(SELECT COUNT(*) FROM account_groups),
+			(SELECT COALESCE(SUM(hashtext(account_id || ':' || group_id || ':' || priority)::bigint), 0) FROM account_groups)
+	`).Scan(&count, &latest, &bindings, &checksum)
+	if err != nil {
+		return "", err
+	}
+	return joinFingerprint(count, nullTimeUnixNano(latest), bindings, checksum), nil
+}
+
+func (r *adminListVersionRepository) UsageLogsFingerprint(ctx context.Context) (string, error) {
+	if r == nil || r.db == nil {
+		return "", errors.New("admin list version repository db is nil")
+	}
+	var (
+		minID    int64
+		maxID    int64
+		revision int64
+		latest   sql.NullTime
+	)
+	// 原地改写已有日志行（费用回填等）不改变 id 范围，由 usage_log_revision 修订号感知
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COALESCE(MIN(id), 0) FROM usage_logs),
+			(SELECT COALESCE(MAX(id), 0) FROM usage_logs),
+			(SELECT COALESCE(MAX(revision), 0) FROM usage_log_revision),
+			GREATEST(
+				(SELECT MAX(updated_at) FROM usage_cleanup_tasks),
+				(SELECT MAX(updated_at) FROM users),
+				(SELECT MAX(updated_at) FROM api_keys),
+				(SELECT MAX(updated_at) FROM accounts),
+				(SELECT MAX(updated_at) FROM groups)
+			)
+	`).Scan(&minID, &maxID, &revision, &latest)
+	if err != nil {
+		return "", err
+	}
+	return joinFingerprint(minID, maxID, revision, nullTimeUnixNano(latest)), nil
+}
+
+func (r *adminListVersionRepository) AccountChangesSince(ctx context.Context, since time.Time, limit int) ([]int64, []int64, error) {
+	if r == nil || r.db == nil {
+		return nil, nil, errors.New("admin list version repository db is nil")
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, deleted_at IS NOT NULL
+		FROM accounts
+		WHERE updated_at > $1 OR deleted_at > $1
+		ORDER BY updated_at ASC, id ASC
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	changed := make([]int64, 0)
+	deleted := make([]int64, 0)
+	for rows.Next() {
+		var (
+			id        int64
+			isDeleted bool
+		)
+		if err := rows.Scan(&id, &isDeleted); err != nil {
+			return nil, nil, err
+		}
+		if isDeleted {
+			deleted = append(deleted, id)
+		} else {
+			changed = append(changed, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return changed, deleted, nil
+}
+
+func nullTimeUnixNano(t sql.NullTime) int64 {
+	if !t.Valid {
+		return 0
+	}
+	return t.Time.UnixNano()
+}
+
+func joinFingerprint(parts ...int64) string {
+	buf := make([]byte, 0, len(parts)*20)
+	for i, p := range parts {
+		if i > 0 {
+			buf = append(buf, ':')
+		}
+		buf = strconv.AppendInt(buf, p, 10)
+	}
+	return string(buf)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminListVersionRepositoryAccountsFingerprint(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &adminListVersionRepository{db: db}
+
+	latest := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM account_groups").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "latest", "bindings", "checksum"}).
+			AddRow(int64(3), latest, int64(4), int64(-12)))
+
+	fp, err := repo.AccountsFingerprint(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "3:"+strconv.FormatInt(latest.UnixNano(), 10)+":4:-12", fp)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminListVersionRepositoryUsageLogsFingerprint_Empty(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &adminListVersionRepository{db: db}
+
+	mock.ExpectQuery("FROM usage_logs").
+		WillReturnRows(sqlmock.NewRows([]string{"min_id", "max_id", "revision", "latest"}).AddRow(int64(0), int64(0), int64(0), nil))
+
+	fp, err := repo.UsageLogsFingerprint(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "0:0:0:0", fp)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminListVersionRepositoryUsageLogsFingerprint_IncludesRevision(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &adminListVersionRepository{db: db}
+
+	latest := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM usage_log_revision").
+		WillReturnRows(sqlmock.NewRows([]string{"min_id", "max_id", "revision", "latest"}).AddRow(int64(10), int64(20), int64(3), latest))
+
+	fp, err := repo.UsageLogsFingerprint(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "10:20:3:"+strconv.FormatInt(latest.UnixNano(), 10), fp)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminListVersionRepositoryAccountChangesSince(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &adminListVersionRepository{db: db}
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("WHERE updated_at > \\$1 OR deleted_at > \\$1").
+		WithArgs(since, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted"}).
+			AddRow(int64(5), false).
+			AddRow(int64(6), true).
+			AddRow(int64(7), false))
+
+	changed, deleted, err := repo.AccountChangesSince(context.Background(), since, 11)
+	require.NoError(t, err)
+	require.Equal(t, []int64{5, 7}, changed)
+	require.Equal(t, []int64{6}, deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
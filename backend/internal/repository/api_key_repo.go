@@ -53,7 +53,16 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 		SetNillableExpiresAt(key.ExpiresAt).
 		SetRateLimit5h(key.RateLimit5h).
 		SetRateLimit1d(key.RateLimit1d).
-		SetRateLimit7d(key.RateLimit7d)
+		SetRateLimit7d(key.RateLimit7d).
+		SetTranscriptEnabled(key.TranscriptEnabled).
+		SetAnnotationsEnabled(key.AnnotationsEnabled).
+		SetStreamCaptureEnabled(key.StreamCaptureEnabled).
+		SetResponseLanguage(key.ResponseLanguage).
+		SetNillableParentKeyID(key.ParentKeyID).
+		SetMaxPriority(key.MaxPriority).
+		SetStrictValidation(key.StrictValidation).
+		SetMaxRequestCost(key.MaxRequestCost).
+		SetDataResidency(key.DataResidency)
 
 	if len(key.IPWhitelist) > 0 {
 		builder.SetIPWhitelist(key.IPWhitelist)
@@ -61,6 +70,12 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 	if len(key.IPBlacklist) > 0 {
 		builder.SetIPBlacklist(key.IPBlacklist)
 	}
+	if len(key.AllowedModels) > 0 {
+		builder.SetAllowedModels(key.AllowedModels)
+	}
+	if len(key.BetaFeatures) > 0 {
+		builder.SetBetaFeatures(key.BetaFeatures)
+	}
 
 	created, err := builder.Save(ctx)
 	if err == nil {
@@ -142,6 +157,17 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 			apikey.FieldRateLimit5h,
 			apikey.FieldRateLimit1d,
 			apikey.FieldRateLimit7d,
+			apikey.FieldTranscriptEnabled,
+			apikey.FieldAnnotationsEnabled,
+			apikey.FieldStreamCaptureEnabled,
+			apikey.FieldResponseLanguage,
+			apikey.FieldParentKeyID,
+			apikey.FieldAllowedModels,
+			apikey.FieldMaxPriority,
+			apikey.FieldStrictValidation,
+			apikey.FieldMaxRequestCost,
+			apikey.FieldBetaFeatures,
+			apikey.FieldDataResidency,
 		).
 		WithUser(func(q *dbent.UserQuery) {
 			q.Select(
@@ -207,6 +233,9 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldPeakStart,
 				group.FieldPeakEnd,
 				group.FieldPeakRateMultiplier,
+				group.FieldAttributionMode,
+				group.FieldAttributionText,
+				group.FieldMaxRequestCost,
 			)
 		}).
 		Only(ctx)
@@ -216,7 +245,42 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 		}
 		return nil, err
 	}
-	return apiKeyEntityToService(m), nil
+	out := apiKeyEntityToService(m)
+	if out.ParentKeyID != nil {
+		parent, err := r.getParentForAuth(ctx, *out.ParentKeyID)
+		if err != nil {
+			return nil, err
+		}
+		out.Parent = parent
+	}
+	return out, nil
+}
+
+// getParentForAuth 加载子 Key 认证所需的父 Key 状态；父 Key 已删除时返回 nil（认证按停用处理）。
+func (r *apiKeyRepository) getParentForAuth(ctx context.Context, parentKeyID int64) (*service.APIKey, error) {
+	m, err := r.activeQuery().
+		Where(apikey.IDEQ(parentKeyID)).
+		Select(
+			apikey.FieldID,
+			apikey.FieldStatus,
+			apikey.FieldQuota,
+			apikey.FieldQuotaUsed,
+			apikey.FieldExpiresAt,
+		).
+		Only(ctx)
+	if err != nil {
+		if dbent.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &service.APIKey{
+		ID:        m.ID,
+		Status:    m.Status,
+		Quota:     m.Quota,
+		QuotaUsed: m.QuotaUsed,
+		ExpiresAt: m.ExpiresAt,
+	}, nil
 }
 
 func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) error {
@@ -239,6 +303,14 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 		SetUsage5h(key.Usage5h).
 		SetUsage1d(key.Usage1d).
 		SetUsage7d(key.Usage7d).
+		SetTranscriptEnabled(key.TranscriptEnabled).
+		SetAnnotationsEnabled(key.AnnotationsEnabled).
+		SetStreamCaptureEnabled(key.StreamCaptureEnabled).
+		SetResponseLanguage(key.ResponseLanguage).
+		SetMaxPriority(key.MaxPriority).
+		SetStrictValidation(key.StrictValidation).
+		SetMaxRequestCost(key.MaxRequestCost).
+		SetDataResidency(key.DataResidency).
 		SetUpdatedAt(now)
 	if key.GroupID != nil {
 		builder.SetGroupID(*key.GroupID)
@@ -281,6 +353,16 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 	} else {
 		builder.ClearIPBlacklist()
 	}
+	if len(key.AllowedModels) > 0 {
+		builder.SetAllowedModels(key.AllowedModels)
+	} else {
+		builder.ClearAllowedModels()
+	}
+	if len(key.BetaFeatures) > 0 {
+		builder.SetBetaFeatures(key.BetaFeatures)
+	} else {
+		builder.ClearBetaFeatures()
+	}
 
 	affected, err := builder.Save(ctx)
 	if err != nil {
@@ -547,6 +629,22 @@ func latestUsageLogIPsQuery(apiKeyIDs []int64, dialectName string) (string, []an
 		WHERE rn = 1`, strings.Join(placeholders, ", ")), args
 }
 
+// ListByParentKeyID 列出父 Key 下的全部委托子 Key（按创建时间倒序）
+func (r *apiKeyRepository) ListByParentKeyID(ctx context.Context, parentKeyID int64) ([]service.APIKey, error) {
+	keys, err := r.activeQuery().
+		Where(apikey.ParentKeyIDEQ(parentKeyID)).
+		Order(dbent.Desc(apikey.FieldID)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]service.APIKey, 0, len(keys))
+	for i := range keys {
+		out = append(out, *apiKeyEntityToService(keys[i]))
+	}
+	return out, nil
+}
+
 func (r *apiKeyRepository) VerifyOwnership(ctx context.Context, userID int64, apiKeyIDs []int64) ([]int64, error) {
 	if len(apiKeyIDs) == 0 {
 		return []int64{}, nil
@@ -848,6 +946,18 @@ func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 		Window5hStart: m.Window5hStart,
 		Window1dStart: m.Window1dStart,
 		Window7dStart: m.Window7dStart,
+
+		TranscriptEnabled:    m.TranscriptEnabled,
+		AnnotationsEnabled:   m.AnnotationsEnabled,
+		StreamCaptureEnabled: m.StreamCaptureEnabled,
+		ResponseLanguage:     m.ResponseLanguage,
+		ParentKeyID:          m.ParentKeyID,
+		AllowedModels:        m.AllowedModels,
+		MaxPriority:          m.MaxPriority,
+		StrictValidation:     m.StrictValidation,
+		MaxRequestCost:       m.MaxRequestCost,
+		BetaFeatures:         m.BetaFeatures,
+		DataResidency:        m.DataResidency,
 	}
 	if m.Edges.User != nil {
 		out.User = userEntityToService(m.Edges.User)
@@ -952,6 +1062,14 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		MessagesDispatchModelConfig:     g.MessagesDispatchModelConfig,
 		ModelsListConfig:                g.ModelsListConfig,
 		RPMLimit:                        g.RpmLimit,
+		AllowComputerUseTool:            g.AllowComputerUseTool,
+		AllowCodeExecutionTool:          g.AllowCodeExecutionTool,
+		AllowWebSearchTool:              g.AllowWebSearchTool,
+		SystemPromptProfile:             g.SystemPromptProfile,
+		ResponseLanguage:                g.ResponseLanguage,
+		AttributionMode:                 g.AttributionMode,
+		AttributionText:                 g.AttributionText,
+		MaxRequestCost:                  g.MaxRequestCost,
 		PeakRateEnabled:                 g.PeakRateEnabled,
 		PeakStart:                       g.PeakStart,
 		PeakEnd:                         g.PeakEnd,
@@ -82,7 +82,7 @@ return 0
 `)
 
 type batchImageQueue struct {
-	rdb            *redis.Client
+	rdb            redis.UniversalClient
 	readyKey       string
 	delayedKey     string
 	activeKey      string
@@ -92,7 +92,7 @@ type batchImageQueue struct {
 	lockTTL        time.Duration
 }
 
-func NewBatchImageQueue(rdb *redis.Client, cfg *config.Config) service.BatchImageQueue {
+func NewBatchImageQueue(rdb redis.UniversalClient, cfg *config.Config) service.BatchImageQueue {
 	return newBatchImageQueueWithOptions(rdb, batchImageQueueOptionsFromConfig(cfg))
 }
 
@@ -106,14 +106,17 @@ type batchImageQueueOptions struct {
 	LockTTL        time.Duration
 }
 
-func newBatchImageQueueWithOptions(rdb *redis.Client, opts batchImageQueueOptions) *batchImageQueue {
+func newBatchImageQueueWithOptions(rdb redis.UniversalClient, opts batchImageQueueOptions) *batchImageQueue {
 	opts = normalizeBatchImageQueueOptions(opts)
+	// 队列 key 与 inflight 去重键在同一 Lua 脚本 / MULTI 中操作，cluster 模式下加相同 hash tag；
+	// job 锁只单 key 操作，保持分散
+	slot := newRedisSlotTag(rdb, "batch_image")
 	return &batchImageQueue{
 		rdb:            rdb,
-		readyKey:       opts.ReadyKey,
-		delayedKey:     opts.DelayedKey,
-		activeKey:      opts.ActiveKey,
-		inflightPrefix: opts.InflightPrefix,
+		readyKey:       slot.key(opts.ReadyKey),
+		delayedKey:     slot.key(opts.DelayedKey),
+		activeKey:      slot.key(opts.ActiveKey),
+		inflightPrefix: slot.key(opts.InflightPrefix),
 		lockPrefix:     opts.LockPrefix,
 		inflightTTL:    opts.InflightTTL,
 		lockTTL:        opts.LockTTL,
@@ -318,7 +321,7 @@ func (q *batchImageQueue) lockKey(batchID string) string {
 }
 
 type batchImageRedisJobLock struct {
-	rdb   *redis.Client
+	rdb   redis.UniversalClient
 	key   string
 	token string
 }
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// billingAdjustmentCostEpsilon 退款金额与请求实际费用比较时的容差（usage_logs.actual_cost 为 10 位小数）
+const billingAdjustmentCostEpsilon = 1e-10
+
+type billingAdjustmentRepository struct {
+	db *sql.DB
+}
+
+func NewBillingAdjustmentRepository(db *sql.DB) service.BillingAdjustmentRepository {
+	return &billingAdjustmentRepository{db: db}
+}
+
+const billingAdjustmentSelectColumns = `
+	id, created_at, user_id, api_key_id, kind, amount, reason, notes, usage_request_id,
+	balance_before, balance_after, quota_restored, created_by`
+
+func (r *billingAdjustmentRepository) Apply(ctx context.Context, input *service.CreateBillingAdjustmentInput) (_ *service.BillingAdjustment, err error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("billing adjustment repository db is nil")
+	}
+	if input == nil {
+		return nil, errors.New("billing adjustment input is nil")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if tx != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var quotaUsed float64
+	if input.APIKeyID != nil {
+		var ownerID int64
+		err := tx.QueryRowContext(ctx, `
+			SELECT user_id, quota_used
+			FROM api_keys
+			WHERE id = $1 AND deleted_at IS NULL
+			FOR UPDATE
+		`, *input.APIKeyID).Scan(&ownerID, &quotaUsed)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrAPIKeyNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ownerID != input.UserID {
+			return nil, service.ErrBillingAdjustmentAPIKeyMismatch
+		}
+	}
+
+	var usageRequestID *string
+	if input.UsageRequestID != "" {
+		var (
+			actualCost  float64
+			billingType int8
+		)
+		err := tx.QueryRowContext(ctx, `
+			SELECT actual_cost, billing_type
+			FROM usage_logs
+			WHERE request_id = $1 AND api_key_id = $2 AND user_id = $3
+		`, input.UsageRequestID, *input.APIKeyID, input.UserID).Scan(&actualCost, &billingType)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrBillingAdjustmentUsageNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		// 订阅计费的请求未扣余额，退回余额等于凭空发放现金
+		if billingType == service.BillingTypeSubscription {
+			return nil, service.ErrBillingAdjustmentSubscriptionUsage
+		}
+		if input.Amount > actualCost+billingAdjustmentCostEpsilon {
+			return nil, service.ErrBillingAdjustmentExceedsUsageCost
+		}
+		usageRequestID = &input.UsageRequestID
+	}
+
+	adj := &service.BillingAdjustment{
+		UserID:         input.UserID,
+		APIKeyID:       input.APIKeyID,
+		Kind:           input.Kind,
+		Amount:         input.Amount,
+		Reason:         input.Reason,
+		Notes:          input.Notes,
+		UsageRequestID: usageRequestID,
+		CreatedBy:      input.CreatedBy,
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users
+		SET balance = balance + $1,
+			updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING balance - $1, balance
+	`, input.Amount, input.UserID).Scan(&adj.BalanceBefore, &adj.BalanceAfter)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if input.APIKeyID != nil {
+		if err := restoreBillingAdjustmentAPIKeyUsage(ctx, tx, *input.APIKeyID, input.Amount); err != nil {
+			return nil, err
+		}
+		adj.QuotaRestored = min(input.Amount, max(quotaUsed, 0))
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO billing_adjustments (
+			user_id, api_key_id, kind, amount, reason, notes, usage_request_id,
+			balance_before, balance_after, quota_restored, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`, adj.UserID, adj.APIKeyID, adj.Kind, adj.Amount, adj.Reason, adj.Notes, adj.UsageRequestID,
+		adj.BalanceBefore, adj.BalanceAfter, adj.QuotaRestored, adj.CreatedBy).Scan(&adj.ID, &adj.CreatedAt)
+	if err != nil {
+		if isUniqueConstraintViolation(err) {
+			return nil, service.ErrBillingAdjustmentDuplicate
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	tx = nil
+	return adj, nil
+}
+
+// restoreBillingAdjustmentAPIKeyUsage 回退 Key 的累计额度与限速窗口用量（不低于 0），
+// 因额度耗尽而停用的 Key 在回退后低于上限时自动恢复为 active。
+func restoreBillingAdjustmentAPIKeyUsage(ctx context.Context, tx *sql.Tx, apiKeyID int64, amount float64) error {
+	res, err := tx.ExecContext(ctx, `
+		UPDATE api_keys
+		SET quota_used = GREATEST(quota_used - $1, 0),
+			usage_5h = GREATEST(usage_5h - $1, 0),
+			usage_1d = GREATEST(usage_1d - $1, 0),
+			usage_7d = GREATEST(usage_7d - $1, 0),
+			status = CASE
+				WHEN status = $3 AND quota > 0 AND GREATEST(quota_used - $1, 0) < quota
+				THEN $4
+				ELSE status
+			END,
+			updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, amount, apiKeyID, service.StatusAPIKeyQuotaExhausted, service.StatusAPIKeyActive)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *billingAdjustmentRepository) List(ctx context.Context, filter *service.BillingAdjustmentFilter) (*service.BillingAdjustmentList, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("billing adjustment repository db is nil")
+	}
+	if filter == nil {
+		filter = &service.BillingAdjustmentFilter{}
+	}
+
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	where, args := buildBillingAdjustmentsWhere(filter)
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM billing_adjustments "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	argsWithLimit := append(args, pageSize, offset)
+	query := "SELECT" + billingAdjustmentSelectColumns + "\nFROM billing_adjustments " + where + `
+ORDER BY created_at DESC, id DESC
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, argsWithLimit...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.BillingAdjustment, 0, pageSize)
+	for rows.Next() {
+		item, err := scanBillingAdjustment(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &service.BillingAdjustmentList{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func buildBillingAdjustmentsWhere(filter *service.BillingAdjustmentFilter) (string, []any) {
+	var clauses []string
+	var args []any
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		clauses = append(clauses, "user_id = $"+itoa(len(args)))
+	}
+	if filter.APIKeyID != nil {
+		args = append(args, *filter.APIKeyID)
+		clauses = append(clauses, "api_key_id = $"+itoa(len(args)))
+	}
+	if kind := strings.TrimSpace(filter.Kind); kind != "" {
+		args = append(args, kind)
+		clauses = append(clauses, "kind = $"+itoa(len(args)))
+	}
+	if filter.StartTime != nil && !filter.StartTime.IsZero() {
+		args = append(args, *filter.StartTime)
+		clauses = append(clauses, "created_at >= $"+itoa(len(args)))
+	}
+	if filter.EndTime != nil && !filter.EndTime.IsZero() {
+		args = append(args, *filter.EndTime)
+		clauses = append(clauses, "created_at < $"+itoa(len(args)))
+	}
+	if len(clauses) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func scanBillingAdjustment(row rowScanner) (*service.BillingAdjustment, error) {
+	var (
+		item           service.BillingAdjustment
+		apiKeyID       sql.NullInt64
+		usageRequestID sql.NullString
+		createdBy      sql.NullInt64
+	)
+	if err := row.Scan(
+		&item.ID, &item.CreatedAt, &item.UserID, &apiKeyID, &item.Kind, &item.Amount, &item.Reason, &item.Notes, &usageRequestID,
+		&item.BalanceBefore, &item.BalanceAfter, &item.QuotaRestored, &createdBy,
+	); err != nil {
+		return nil, fmt.Errorf("scan billing adjustment: %w", err)
+	}
+	if apiKeyID.Valid {
+		v := apiKeyID.Int64
+		item.APIKeyID = &v
+	}
+	if usageRequestID.Valid {
+		v := usageRequestID.String
+		item.UsageRequestID = &v
+	}
+	if createdBy.Valid {
+		v := createdBy.Int64
+		item.CreatedBy = &v
+	}
+	return &item, nil
+}
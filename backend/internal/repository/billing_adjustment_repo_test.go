@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBillingAdjustmentRepositoryApply_RefundRestoresKeyUsage(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &billingAdjustmentRepository{db: db}
+
+	keyID := int64(5)
+	adminID := int64(1)
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id, quota_used\\s+FROM api_keys").
+		WithArgs(keyID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "quota_used"}).AddRow(int64(9), 0.3))
+	mock.ExpectQuery("SELECT actual_cost, billing_type\\s+FROM usage_logs").
+		WithArgs("req-1", keyID, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"actual_cost", "billing_type"}).AddRow(0.5, service.BillingTypeBalance))
+	mock.ExpectQuery("UPDATE users").
+		WithArgs(0.5, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"before", "after"}).AddRow(1.0, 1.5))
+	mock.ExpectExec("UPDATE api_keys").
+		WithArgs(0.5, keyID, service.StatusAPIKeyQuotaExhausted, service.StatusAPIKeyActive).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO billing_adjustments").
+		WithArgs(int64(9), &keyID, service.BillingAdjustmentKindRefund, 0.5, "failed generation", "", sqlmock.AnyArg(), 1.0, 1.5, 0.3, &adminID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(42), now))
+	mock.ExpectCommit()
+
+	adj, err := repo.Apply(context.Background(), &service.CreateBillingAdjustmentInput{
+		UserID:         9,
+		APIKeyID:       &keyID,
+		Kind:           service.BillingAdjustmentKindRefund,
+		Amount:         0.5,
+		Reason:         "failed generation",
+		UsageRequestID: "req-1",
+		CreatedBy:      &adminID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), adj.ID)
+	require.Equal(t, 1.5, adj.BalanceAfter)
+	require.Equal(t, 0.3, adj.QuotaRestored, "quota_used 不会低于 0")
+	require.Equal(t, "req-1", *adj.UsageRequestID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBillingAdjustmentRepositoryApply_RejectsForeignKey(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &billingAdjustmentRepository{db: db}
+	keyID := int64(5)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id, quota_used\\s+FROM api_keys").
+		WithArgs(keyID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "quota_used"}).AddRow(int64(10), 0.0))
+	mock.ExpectRollback()
+
+	_, err := repo.Apply(context.Background(), &service.CreateBillingAdjustmentInput{
+		UserID: 9, APIKeyID: &keyID, Kind: service.BillingAdjustmentKindCredit, Amount: 1,
+	})
+	require.ErrorIs(t, err, service.ErrBillingAdjustmentAPIKeyMismatch)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBillingAdjustmentRepositoryApply_RefundExceedingCostRejected(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &billingAdjustmentRepository{db: db}
+	keyID := int64(5)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id, quota_used\\s+FROM api_keys").
+		WithArgs(keyID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "quota_used"}).AddRow(int64(9), 0.0))
+	mock.ExpectQuery("SELECT actual_cost, billing_type\\s+FROM usage_logs").
+		WithArgs("req-1", keyID, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"actual_cost", "billing_type"}).AddRow(0.2, service.BillingTypeBalance))
+	mock.ExpectRollback()
+
+	_, err := repo.Apply(context.Background(), &service.CreateBillingAdjustmentInput{
+		UserID: 9, APIKeyID: &keyID, Kind: service.BillingAdjustmentKindRefund, Amount: 0.5, UsageRequestID: "req-1",
+	})
+	require.ErrorIs(t, err, service.ErrBillingAdjustmentExceedsUsageCost)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBillingAdjustmentRepositoryApply_SubscriptionUsageRejected(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &billingAdjustmentRepository{db: db}
+	keyID := int64(5)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id, quota_used\\s+FROM api_keys").
+		WithArgs(keyID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "quota_used"}).AddRow(int64(9), 0.0))
+	mock.ExpectQuery("SELECT actual_cost, billing_type\\s+FROM usage_logs").
+		WithArgs("req-1", keyID, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"actual_cost", "billing_type"}).AddRow(0.5, service.BillingTypeSubscription))
+	mock.ExpectRollback()
+
+	_, err := repo.Apply(context.Background(), &service.CreateBillingAdjustmentInput{
+		UserID: 9, APIKeyID: &keyID, Kind: service.BillingAdjustmentKindRefund, Amount: 0.5, UsageRequestID: "req-1",
+	})
+	require.ErrorIs(t, err, service.ErrBillingAdjustmentSubscriptionUsage)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBillingAdjustmentRepositoryApply_DuplicateRefund(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &billingAdjustmentRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE users").
+		WithArgs(1.0, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"before", "after"}).AddRow(0.0, 1.0))
+	mock.ExpectQuery("INSERT INTO billing_adjustments").
+		WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	_, err := repo.Apply(context.Background(), &service.CreateBillingAdjustmentInput{
+		UserID: 9, Kind: service.BillingAdjustmentKindCredit, Amount: 1,
+	})
+	require.ErrorIs(t, err, service.ErrBillingAdjustmentDuplicate)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBillingAdjustmentRepositoryApply_UserNotFound(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &billingAdjustmentRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE users").
+		WithArgs(1.0, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"before", "after"}))
+	mock.ExpectRollback()
+
+	_, err := repo.Apply(context.Background(), &service.CreateBillingAdjustmentInput{
+		UserID: 9, Kind: service.BillingAdjustmentKindCredit, Amount: 1,
+	})
+	require.True(t, errors.Is(err, service.ErrUserNotFound))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildBillingAdjustmentsWhere(t *testing.T) {
+	uid := int64(9)
+	kid := int64(5)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	where, args := buildBillingAdjustmentsWhere(&service.BillingAdjustmentFilter{})
+	require.Empty(t, where)
+	require.Empty(t, args)
+
+	where, args = buildBillingAdjustmentsWhere(&service.BillingAdjustmentFilter{UserID: &uid, APIKeyID: &kid, Kind: "refund", StartTime: &start})
+	require.Equal(t, "WHERE user_id = $1 AND api_key_id = $2 AND kind = $3 AND created_at >= $4", where)
+	require.Equal(t, []any{uid, kid, "refund", start}, args)
+}
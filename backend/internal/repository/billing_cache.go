@@ -137,11 +137,13 @@ var (
 )
 
 type billingCache struct {
-	rdb *redis.Client
+	rdb redis.UniversalClient
+	// quotaSlot cluster 模式下用户平台额度 hash 与脏集在同一 Lua 脚本中更新，统一加 {upq} hash tag
+	quotaSlot redisSlotTag
 }
 
-func NewBillingCache(rdb *redis.Client) service.BillingCache {
-	return &billingCache{rdb: rdb}
+func NewBillingCache(rdb redis.UniversalClient) service.BillingCache {
+	return &billingCache{rdb: rdb, quotaSlot: newRedisSlotTag(rdb, "upq")}
 }
 
 func (c *billingCache) GetUserBalance(ctx context.Context, userID int64) (float64, error) {
@@ -379,6 +381,10 @@ func userPlatformQuotaCacheKey(userID int64, platform string) string {
 	return fmt.Sprintf("billing:user_platform_quota:%d:%s", userID, platform)
 }
 
+func (c *billingCache) quotaKey(userID int64, platform string) string {
+	return c.quotaSlot.key(userPlatformQuotaCacheKey(userID, platform))
+}
+
 // parseUserPlatformQuotaHash 将 Redis HGETALL 返回的 map[string]string 反序列化为
 // *service.UserPlatformQuotaCacheEntry。空 map（key 不存在）返回 nil。
 // GetUserPlatformQuotaCache 和 BatchGetUserPlatformQuotaCache 共用此函数，确保解析逻辑一致。
@@ -438,7 +444,7 @@ func parseUserPlatformQuotaHash(m map[string]string) *service.UserPlatformQuotaC
 }
 
 func (c *billingCache) GetUserPlatformQuotaCache(ctx context.Context, userID int64, platform string) (*service.UserPlatformQuotaCacheEntry, bool, error) {
-	key := userPlatformQuotaCacheKey(userID, platform)
+	key := c.quotaKey(userID, platform)
 	m, err := c.rdb.HGetAll(ctx, key).Result()
 	if err != nil {
 		return nil, false, err
@@ -455,7 +461,7 @@ func (c *billingCache) SetUserPlatformQuotaCache(ctx context.Context, userID int
 	if entry == nil {
 		return nil
 	}
-	key := userPlatformQuotaCacheKey(userID, platform)
+	key := c.quotaKey(userID, platform)
 	pipe := c.rdb.TxPipeline()
 
 	// 浮点可空字段：nil → 空字符串（读取时 parseFloatPtr 返回 nil，表示无限额）
@@ -492,7 +498,7 @@ func (c *billingCache) SetUserPlatformQuotaCache(ctx context.Context, userID int
 }
 
 func (c *billingCache) DeleteUserPlatformQuotaCache(ctx context.Context, userID int64, platform string) error {
-	return c.rdb.Del(ctx, userPlatformQuotaCacheKey(userID, platform)).Err()
+	return c.rdb.Del(ctx, c.quotaKey(userID, platform)).Err()
 }
 
 // updateUserPlatformQuotaUsageScript 缓存累加：EXISTS + schema_version 双重守卫。
@@ -545,7 +551,7 @@ func (c *billingCache) IncrUserPlatformQuotaUsageCache(ctx context.Context, user
 		member = userPlatformQuotaDirtyMember(userID, platform)
 	}
 	_, err := c.rdb.Eval(ctx, updateUserPlatformQuotaUsageScript,
-		[]string{userPlatformQuotaCacheKey(userID, platform), userPlatformQuotaDirtySetKey()},
+		[]string{c.quotaKey(userID, platform), c.quotaSlot.key(userPlatformQuotaDirtySetKey())},
 		strconv.FormatFloat(cost, 'f', -1, 64),
 		int(ttl.Seconds()),
 		service.UserPlatformQuotaCacheSchemaV1,
@@ -575,7 +581,7 @@ func parseUserPlatformQuotaDirtyMember(m string) (service.UserPlatformQuotaKey,
 // PopDirtyUserPlatformQuotaKeys 从脏集随机弹出最多 n 个 key。
 // 脏集为空时返回 (nil, nil)。
 func (c *billingCache) PopDirtyUserPlatformQuotaKeys(ctx context.Context, n int) ([]service.UserPlatformQuotaKey, error) {
-	members, err := c.rdb.SPopN(ctx, userPlatformQuotaDirtySetKey(), int64(n)).Result()
+	members, err := c.rdb.SPopN(ctx, c.quotaSlot.key(userPlatformQuotaDirtySetKey()), int64(n)).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil, nil
@@ -601,7 +607,7 @@ func (c *billingCache) ReaddDirtyUserPlatformQuotaKeys(ctx context.Context, keys
 	if len(keys) == 0 {
 		return nil
 	}
-	dirtyKey := userPlatformQuotaDirtySetKey()
+	dirtyKey := c.quotaSlot.key(userPlatformQuotaDirtySetKey())
 	members := make([]any, len(keys))
 	for i, k := range keys {
 		members[i] = userPlatformQuotaDirtyMember(k.UserID, k.Platform)
@@ -622,7 +628,7 @@ func (c *billingCache) BatchGetUserPlatformQuotaCache(ctx context.Context, keys
 	pipe := c.rdb.Pipeline()
 	cmds := make([]*redis.MapStringStringCmd, len(keys))
 	for i, k := range keys {
-		cmds[i] = pipe.HGetAll(ctx, userPlatformQuotaCacheKey(k.UserID, k.Platform))
+		cmds[i] = pipe.HGetAll(ctx, c.quotaKey(k.UserID, k.Platform))
 	}
 	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
 		return nil, err
@@ -21,18 +21,18 @@ type BillingCacheSuite struct {
 func (s *BillingCacheSuite) TestUserBalance() {
 	tests := []struct {
 		name string
-		fn   func(ctx context.Context, rdb *redis.Client, cache service.BillingCache)
+		fn   func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache)
 	}{
 		{
 			name: "missing_key_returns_redis_nil",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				_, err := cache.GetUserBalance(ctx, 1)
 				require.ErrorIs(s.T(), err, redis.Nil, "expected redis.Nil for missing balance key")
 			},
 		},
 		{
 			name: "deduct_on_nonexistent_is_noop",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(1)
 				balanceKey := fmt.Sprintf("%s%d", billingBalanceKeyPrefix, userID)
 
@@ -44,7 +44,7 @@ func (s *BillingCacheSuite) TestUserBalance() {
 		},
 		{
 			name: "set_and_get_with_ttl",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(2)
 				balanceKey := fmt.Sprintf("%s%d", billingBalanceKeyPrefix, userID)
 
@@ -61,7 +61,7 @@ func (s *BillingCacheSuite) TestUserBalance() {
 		},
 		{
 			name: "deduct_reduces_balance",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(3)
 
 				require.NoError(s.T(), cache.SetUserBalance(ctx, userID, 10.5), "SetUserBalance")
@@ -74,7 +74,7 @@ func (s *BillingCacheSuite) TestUserBalance() {
 		},
 		{
 			name: "invalidate_removes_key",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(100)
 				balanceKey := fmt.Sprintf("%s%d", billingBalanceKeyPrefix, userID)
 
@@ -96,7 +96,7 @@ func (s *BillingCacheSuite) TestUserBalance() {
 		},
 		{
 			name: "deduct_refreshes_ttl",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(103)
 				balanceKey := fmt.Sprintf("%s%d", billingBalanceKeyPrefix, userID)
 
@@ -133,11 +133,11 @@ func (s *BillingCacheSuite) TestUserBalance() {
 func (s *BillingCacheSuite) TestSubscriptionCache() {
 	tests := []struct {
 		name string
-		fn   func(ctx context.Context, rdb *redis.Client, cache service.BillingCache)
+		fn   func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache)
 	}{
 		{
 			name: "missing_key_returns_redis_nil",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(10)
 				groupID := int64(20)
 
@@ -147,7 +147,7 @@ func (s *BillingCacheSuite) TestSubscriptionCache() {
 		},
 		{
 			name: "update_usage_on_nonexistent_is_noop",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(11)
 				groupID := int64(21)
 				subKey := fmt.Sprintf("%s%d:%d", billingSubKeyPrefix, userID, groupID)
@@ -161,7 +161,7 @@ func (s *BillingCacheSuite) TestSubscriptionCache() {
 		},
 		{
 			name: "set_and_get_with_ttl",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(12)
 				groupID := int64(22)
 				subKey := fmt.Sprintf("%s%d:%d", billingSubKeyPrefix, userID, groupID)
@@ -189,7 +189,7 @@ func (s *BillingCacheSuite) TestSubscriptionCache() {
 		},
 		{
 			name: "update_usage_increments_all_fields",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(13)
 				groupID := int64(23)
 
@@ -214,7 +214,7 @@ func (s *BillingCacheSuite) TestSubscriptionCache() {
 		},
 		{
 			name: "invalidate_removes_key",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(101)
 				groupID := int64(10)
 				subKey := fmt.Sprintf("%s%d:%d", billingSubKeyPrefix, userID, groupID)
@@ -245,7 +245,7 @@ func (s *BillingCacheSuite) TestSubscriptionCache() {
 		},
 		{
 			name: "missing_status_returns_parsing_error",
-			fn: func(ctx context.Context, rdb *redis.Client, cache service.BillingCache) {
+			fn: func(ctx context.Context, rdb redis.UniversalClient, cache service.BillingCache) {
 				userID := int64(102)
 				groupID := int64(11)
 				subKey := fmt.Sprintf("%s%d:%d", billingSubKeyPrefix, userID, groupID)
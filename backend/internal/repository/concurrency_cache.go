@@ -63,7 +63,7 @@ var (
 	// ARGV[1] = maxConcurrency
 	// ARGV[2] = TTL（秒）
 	// ARGV[3] = requestID
-	// 返回 {是否成功, Redis 当前秒}，Go 侧复用同一时间源写活跃索引，省去额外 TIME 往返。
+	// 返回 {是否成功, Redis 当前秒, 回收的过期槽位数}，Go 侧复用同一时间源写活跃索引，省去额外 TIME 往返。
 	acquireScript = redis.NewScript(`
 		-- Redis 3.2-4.x compat: opt into effects replication so redis.call('TIME')
 		-- replicates correctly. No-op on Redis 5.0+ (effects replication is default).
@@ -79,14 +79,14 @@ var (
 		local expireBefore = now - ttl
 
 		-- 清理过期槽位
-		redis.call('ZREMRANGEBYSCORE', key, '-inf', expireBefore)
+		local reclaimed = redis.call('ZREMRANGEBYSCORE', key, '-inf', expireBefore)
 
 		-- 检查是否已存在（支持重试场景刷新时间戳）
 		local exists = redis.call('ZSCORE', key, requestID)
 		if exists ~= false then
 			redis.call('ZADD', key, now, requestID)
 			redis.call('EXPIRE', key, ttl)
-			return {1, now}
+			return {1, now, reclaimed}
 		end
 
 		-- 检查是否达到并发上限
@@ -94,10 +94,10 @@ var (
 		if count < maxConcurrency then
 			redis.call('ZADD', key, now, requestID)
 			redis.call('EXPIRE', key, ttl)
-			return {1, now}
+			return {1, now, reclaimed}
 		end
 
-		return {0, now}
+		return {0, now, reclaimed}
 	`)
 
 	// getCountScript 统计有序集合中的槽位数量并清理过期条目
@@ -252,6 +252,7 @@ var (
 	// cleanupExpiredSlotsScript 清理单个账号/用户有序集合中过期槽位
 	// KEYS[1] = 有序集合键
 	// ARGV[1] = TTL（秒）
+	// 返回回收的过期槽位数
 	cleanupExpiredSlotsScript = redis.NewScript(`
 		-- Redis 3.2-4.x compat: opt into effects replication so redis.call('TIME')
 		-- replicates correctly. No-op on Redis 5.0+ (effects replication is default).
@@ -261,13 +262,13 @@ var (
 		local timeResult = redis.call('TIME')
 		local now = tonumber(timeResult[1])
 		local expireBefore = now - ttl
-		redis.call('ZREMRANGEBYSCORE', key, '-inf', expireBefore)
+		local reclaimed = redis.call('ZREMRANGEBYSCORE', key, '-inf', expireBefore)
 		if redis.call('ZCARD', key) == 0 then
 			redis.call('DEL', key)
 		else
 			redis.call('EXPIRE', key, ttl)
 		end
-		return 1
+		return reclaimed
 	`)
 
 	// startupCleanupSlotScript 清理单个槽位 key 中非当前进程前缀的成员，避免 Redis Cluster CROSSSLOT。
@@ -295,7 +296,7 @@ var (
 )
 
 type concurrencyCache struct {
-	rdb                 *redis.Client
+	rdb                 redis.UniversalClient
 	slotTTLSeconds      int // 槽位过期时间（秒）
 	waitQueueTTLSeconds int // 等待队列过期时间（秒）
 }
@@ -303,16 +304,24 @@ type concurrencyCache struct {
 // NewConcurrencyCache 创建并发控制缓存
 // slotTTLMinutes: 槽位过期时间（分钟），0 或负数使用默认值 15 分钟
 // waitQueueTTLSeconds: 等待队列过期时间（秒），0 或负数使用 slot TTL
-func NewConcurrencyCache(rdb *redis.Client, slotTTLMinutes int, waitQueueTTLSeconds int) service.ConcurrencyCache {
+func NewConcurrencyCache(rdb redis.UniversalClient, slotTTLMinutes int, waitQueueTTLSeconds int) service.ConcurrencyCache {
 	if slotTTLMinutes <= 0 {
 		slotTTLMinutes = defaultSlotTTLMinutes
 	}
+	return newConcurrencyCacheWithSlotTTL(rdb, slotTTLMinutes*60, waitQueueTTLSeconds)
+}
+
+// newConcurrencyCacheWithSlotTTL 以秒为单位指定槽位过期时间（槽位心跳租约的 TTL 按秒配置）。
+func newConcurrencyCacheWithSlotTTL(rdb redis.UniversalClient, slotTTLSeconds int, waitQueueTTLSeconds int) *concurrencyCache {
+	if slotTTLSeconds <= 0 {
+		slotTTLSeconds = defaultSlotTTLMinutes * 60
+	}
 	if waitQueueTTLSeconds <= 0 {
-		waitQueueTTLSeconds = slotTTLMinutes * 60
+		waitQueueTTLSeconds = slotTTLSeconds
 	}
 	return &concurrencyCache{
 		rdb:                 rdb,
-		slotTTLSeconds:      slotTTLMinutes * 60,
+		slotTTLSeconds:      slotTTLSeconds,
 		waitQueueTTLSeconds: waitQueueTTLSeconds,
 	}
 }
@@ -444,12 +453,13 @@ func (c *concurrencyCache) activeIndexTTL(slotCount int, waitCount int) int {
 func (c *concurrencyCache) readActiveLoadForKey(ctx context.Context, id int64, slotKey, waitKey string, now int64) (activeIndexLoad, error) {
 	cutoffTime := now - int64(c.slotTTLSeconds)
 	pipe := c.rdb.Pipeline()
-	pipe.ZRemRangeByScore(ctx, slotKey, "-inf", strconv.FormatInt(cutoffTime, 10))
+	reclaimCmd := pipe.ZRemRangeByScore(ctx, slotKey, "-inf", strconv.FormatInt(cutoffTime, 10))
 	zcardCmd := pipe.ZCard(ctx, slotKey)
 	getCmd := pipe.Get(ctx, waitKey)
 	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
 		return activeIndexLoad{}, fmt.Errorf("pipeline exec: %w", err)
 	}
+	logReclaimedSlotLeases(slotKey, reclaimCmd.Val())
 
 	waitCount := 0
 	if v, err := getCmd.Int(); err == nil && v > 0 {
@@ -489,16 +499,19 @@ func (c *concurrencyCache) readIndexLoads(ctx context.Context, spec slotIndexSpe
 		pipe := c.rdb.Pipeline()
 		type loadCmd struct {
 			activeIndexLoad
-			zcardCmd *redis.IntCmd
-			getCmd   *redis.StringCmd
+			slotKey    string
+			reclaimCmd *redis.IntCmd
+			zcardCmd   *redis.IntCmd
+			getCmd     *redis.StringCmd
 		}
 		cmds := make([]loadCmd, 0, len(chunk))
 		for _, candidate := range chunk {
 			slotKey := spec.slotKey(candidate.id)
 			waitKey := spec.waitKey(candidate.id)
-			pipe.ZRemRangeByScore(ctx, slotKey, "-inf", strconv.FormatInt(cutoffTime, 10))
 			cmds = append(cmds, loadCmd{
 				activeIndexLoad: candidate,
+				slotKey:         slotKey,
+				reclaimCmd:      pipe.ZRemRangeByScore(ctx, slotKey, "-inf", strconv.FormatInt(cutoffTime, 10)),
 				zcardCmd:        pipe.ZCard(ctx, slotKey),
 				getCmd:          pipe.Get(ctx, waitKey),
 			})
@@ -507,6 +520,7 @@ func (c *concurrencyCache) readIndexLoads(ctx context.Context, spec slotIndexSpe
 			return nil, nil, fmt.Errorf("pipeline exec: %w", err)
 		}
 		for _, cmd := range cmds {
+			logReclaimedSlotLeases(cmd.slotKey, cmd.reclaimCmd.Val())
 			waitCount := 0
 			if v, err := cmd.getCmd.Int(); err == nil && v > 0 {
 				waitCount = v
@@ -538,7 +552,7 @@ func (c *concurrencyCache) removeActiveIndexMembers(ctx context.Context, indexKe
 }
 
 // runScriptInt64Pair 执行返回两元素整数数组的 Lua 脚本并解析（如 {result, now}、{removed, remaining}）。
-func runScriptInt64Pair(ctx context.Context, rdb *redis.Client, script *redis.Script, keys []string, args ...any) (int64, int64, error) {
+func runScriptInt64Pair(ctx context.Context, rdb redis.UniversalClient, script *redis.Script, keys []string, args ...any) (int64, int64, error) {
 	raw, err := script.Run(ctx, rdb, keys, args...).Result()
 	if err != nil {
 		return 0, 0, err
@@ -554,20 +568,39 @@ func runScriptInt64Pair(ctx context.Context, rdb *redis.Client, script *redis.Sc
 	return first, second, nil
 }
 
+// acquireSlot 执行 acquireScript，返回是否占槽与 Redis 当前秒，并记录顺带回收的过期槽位。
+func (c *concurrencyCache) acquireSlot(ctx context.Context, key string, maxConcurrency int, requestID string) (bool, int64, error) {
+	// 时间戳在 Lua 脚本内使用 Redis TIME 命令获取，确保多实例时钟一致
+	raw, err := acquireScript.Run(ctx, c.rdb, []string{key}, maxConcurrency, c.slotTTLSeconds, requestID).Result()
+	if err != nil {
+		return false, 0, err
+	}
+	result, err := redisScriptInt64At(raw, 0)
+	if err != nil {
+		return false, 0, fmt.Errorf("parse script value 0: %w", err)
+	}
+	now, err := redisScriptInt64At(raw, 1)
+	if err != nil {
+		return false, 0, fmt.Errorf("parse script value 1: %w", err)
+	}
+	if reclaimed, err := redisScriptInt64At(raw, 2); err == nil {
+		logReclaimedSlotLeases(key, reclaimed)
+	}
+	return result == 1, now, nil
+}
+
 // Account slot operations
 
 func (c *concurrencyCache) AcquireAccountSlot(ctx context.Context, accountID int64, maxConcurrency int, requestID string) (bool, error) {
-	key := accountSlotKey(accountID)
-	// 时间戳在 Lua 脚本内使用 Redis TIME 命令获取，确保多实例时钟一致
-	result, now, err := runScriptInt64Pair(ctx, c.rdb, acquireScript, []string{key}, maxConcurrency, c.slotTTLSeconds, requestID)
+	acquired, now, err := c.acquireSlot(ctx, accountSlotKey(accountID), maxConcurrency, requestID)
 	if err != nil {
 		return false, err
 	}
-	if result == 1 {
+	if acquired {
 		// 成功占槽后标记活跃账号，后台清理即可从索引定位候选账号。
 		c.touchActiveIndexAt(ctx, accountActiveIndexKey, accountID, now+int64(c.slotTTLSeconds))
 	}
-	return result == 1, nil
+	return acquired, nil
 }
 
 func (c *concurrencyCache) ReleaseAccountSlot(ctx context.Context, accountID int64, requestID string) error {
@@ -630,17 +663,15 @@ func (c *concurrencyCache) GetAccountConcurrencyBatch(ctx context.Context, accou
 // User slot operations
 
 func (c *concurrencyCache) AcquireUserSlot(ctx context.Context, userID int64, maxConcurrency int, requestID string) (bool, error) {
-	key := userSlotKey(userID)
-	// 时间戳在 Lua 脚本内使用 Redis TIME 命令获取，确保多实例时钟一致
-	result, now, err := runScriptInt64Pair(ctx, c.rdb, acquireScript, []string{key}, maxConcurrency, c.slotTTLSeconds, requestID)
+	acquired, now, err := c.acquireSlot(ctx, userSlotKey(userID), maxConcurrency, requestID)
 	if err != nil {
 		return false, err
 	}
-	if result == 1 {
+	if acquired {
 		// 成功占槽后标记活跃用户，避免启动清理依赖全量 SCAN。
 		c.touchActiveIndexAt(ctx, userActiveIndexKey, userID, now+int64(c.slotTTLSeconds))
 	}
-	return result == 1, nil
+	return acquired, nil
 }
 
 func (c *concurrencyCache) ReleaseUserSlot(ctx context.Context, userID int64, requestID string) error {
@@ -938,8 +969,9 @@ func (c *concurrencyCache) GetUsersLoadBatch(ctx context.Context, users []servic
 
 func (c *concurrencyCache) CleanupExpiredAccountSlots(ctx context.Context, accountID int64) error {
 	key := accountSlotKey(accountID)
-	_, err := cleanupExpiredSlotsScript.Run(ctx, c.rdb, []string{key}, c.slotTTLSeconds).Result()
+	reclaimed, err := cleanupExpiredSlotsScript.Run(ctx, c.rdb, []string{key}, c.slotTTLSeconds).Int64()
 	if err == nil {
+		logReclaimedSlotLeases(key, reclaimed)
 		// 单账号清理后同步索引，保持后台批量清理的候选集准确。
 		c.refreshAccountActiveIndex(ctx, accountID)
 	}
@@ -1041,15 +1073,43 @@ func (c *concurrencyCache) sweepLegacyWaitKeysOnce(ctx context.Context) error {
 	if exists > 0 {
 		return nil
 	}
+	// cluster 模式下 SCAN 只覆盖单个节点，需逐个主节点扫描；多 key DEL 可能跨 slot，改为逐 key 删除
+	if cluster, ok := c.rdb.(*redis.ClusterClient); ok {
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return sweepLegacyWaitKeys(ctx, node, true)
+		})
+	} else {
+		err = sweepLegacyWaitKeys(ctx, c.rdb, false)
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.rdb.Set(ctx, legacyWaitSweepMarkerKey, "1", 0).Err(); err != nil {
+		return fmt.Errorf("set legacy wait sweep marker: %w", err)
+	}
+	return nil
+}
+
+func sweepLegacyWaitKeys(ctx context.Context, client redis.Cmdable, perKey bool) error {
 	for _, pattern := range []string{accountWaitKeyPrefix + "*", waitQueueKeyPrefix + "*"} {
 		var cursor uint64
 		for {
-			keys, next, err := c.rdb.Scan(ctx, cursor, pattern, 200).Result()
+			keys, next, err := client.Scan(ctx, cursor, pattern, 200).Result()
 			if err != nil {
 				return fmt.Errorf("scan legacy wait keys %s: %w", pattern, err)
 			}
 			if len(keys) > 0 {
-				if err := c.rdb.Del(ctx, keys...).Err(); err != nil {
+				if perKey {
+					_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+						for _, key := range keys {
+							pipe.Del(ctx, key)
+						}
+						return nil
+					})
+				} else {
+					err = client.Del(ctx, keys...).Err()
+				}
+				if err != nil {
 					return fmt.Errorf("delete legacy wait keys: %w", err)
 				}
 			}
@@ -1059,9 +1119,6 @@ func (c *concurrencyCache) sweepLegacyWaitKeysOnce(ctx context.Context) error {
 			}
 		}
 	}
-	if err := c.rdb.Set(ctx, legacyWaitSweepMarkerKey, "1", 0).Err(); err != nil {
-		return fmt.Errorf("set legacy wait sweep marker: %w", err)
-	}
 	return nil
 }
 
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// renewSlotLeaseScript 刷新单个槽位成员的时间戳（心跳续约）。
+// 成员已被回收时返回 0 且不重建，避免越过并发上限。
+// KEYS[1] = 有序集合键
+// ARGV[1] = TTL（秒）
+// ARGV[2] = requestID
+var renewSlotLeaseScript = redis.NewScript(`
+	redis.replicate_commands()
+	local key = KEYS[1]
+	local ttl = tonumber(ARGV[1])
+	local requestID = ARGV[2]
+	local now = tonumber(redis.call('TIME')[1])
+	if redis.call('ZSCORE', key, requestID) == false then
+		return 0
+	end
+	redis.call('ZADD', key, now, requestID)
+	redis.call('EXPIRE', key, ttl)
+	return 1
+`)
+
+func slotLeaseKey(lease service.SlotLease) (string, error) {
+	switch lease.Kind {
+	case service.SlotLeaseKindAccount:
+		return accountSlotKey(lease.ID), nil
+	case service.SlotLeaseKindUser:
+		return userSlotKey(lease.ID), nil
+	case service.SlotLeaseKindAPIKey:
+		return apiKeySlotKey(lease.ID), nil
+	default:
+		return "", fmt.Errorf("unknown slot lease kind %q", lease.Kind)
+	}
+}
+
+// RenewSlotLeases 分块 Pipeline 续约槽位租约，返回与 leases 一一对应的是否仍持有。
+// Pipeline 中使用 EVAL 而非 EVALSHA：脚本未缓存时 Pipeline 内无法回退。
+func (c *concurrencyCache) RenewSlotLeases(ctx context.Context, leases []service.SlotLease) ([]bool, error) {
+	owned := make([]bool, len(leases))
+	for start := 0; start < len(leases); start += activeIndexPipelineChunkSize {
+		end := start + activeIndexPipelineChunkSize
+		if end > len(leases) {
+			end = len(leases)
+		}
+		pipe := c.rdb.Pipeline()
+		cmds := make([]*redis.Cmd, end-start)
+		for i, lease := range leases[start:end] {
+			key, err := slotLeaseKey(lease)
+			if err != nil {
+				return nil, err
+			}
+			cmds[i] = renewSlotLeaseScript.Eval(ctx, pipe, []string{key}, c.slotTTLSeconds, lease.RequestID)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("renew slot leases: %w", err)
+		}
+		for i, cmd := range cmds {
+			v, err := cmd.Int()
+			owned[start+i] = err == nil && v == 1
+		}
+	}
+	return owned, nil
+}
+
+// logReclaimedSlotLeases 记录按 TTL 回收的过期槽位。
+// 启用心跳续约时，过期只会发生在持有实例宕机或长时间无法访问 Redis 的情况下。
+func logReclaimedSlotLeases(key string, reclaimed int64) {
+	if reclaimed <= 0 {
+		return
+	}
+	logger.LegacyPrintf("repository.concurrency", "Reclaimed %d expired slot leases from %s", reclaimed, key)
+}
@@ -0,0 +1,77 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newSlotLeaseTestCache(t *testing.T, ttlSeconds int) (*concurrencyCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Unix(1_700_000_000, 0))
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return newConcurrencyCacheWithSlotTTL(rdb, ttlSeconds, 0), mr
+}
+
+func TestRenewSlotLeasesKeepsHeldSlotsAlive(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newSlotLeaseTestCache(t, 90)
+
+	ok, err := cache.AcquireAccountSlot(ctx, 7, 1, "req-a")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// 每 60 秒续约一次，累计 3 分钟后槽位仍被持有
+	for i := 0; i < 3; i++ {
+		mr.SetTime(time.Unix(1_700_000_000+int64(i+1)*60, 0))
+		owned, err := cache.RenewSlotLeases(ctx, []service.SlotLease{{Kind: service.SlotLeaseKindAccount, ID: 7, RequestID: "req-a"}})
+		require.NoError(t, err)
+		require.Equal(t, []bool{true}, owned)
+	}
+
+	ok, err = cache.AcquireAccountSlot(ctx, 7, 1, "req-b")
+	require.NoError(t, err)
+	require.False(t, ok, "renewed lease must still occupy the only slot")
+}
+
+func TestExpiredSlotLeaseIsReclaimedAndNotRecreated(t *testing.T) {
+	ctx := context.Background()
+	cache, mr := newSlotLeaseTestCache(t, 90)
+
+	ok, err := cache.AcquireUserSlot(ctx, 3, 1, "dead-instance-req")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// 持有实例宕机，超过租约 TTL 未续约
+	mr.SetTime(time.Unix(1_700_000_000+91, 0))
+	ok, err = cache.AcquireUserSlot(ctx, 3, 1, "new-req")
+	require.NoError(t, err)
+	require.True(t, ok, "expired lease should be reclaimed")
+
+	owned, err := cache.RenewSlotLeases(ctx, []service.SlotLease{
+		{Kind: service.SlotLeaseKindUser, ID: 3, RequestID: "dead-instance-req"},
+		{Kind: service.SlotLeaseKindUser, ID: 3, RequestID: "new-req"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []bool{false, true}, owned)
+
+	count, err := cache.GetUserConcurrency(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestRenewSlotLeasesRejectsUnknownKind(t *testing.T) {
+	cache, _ := newSlotLeaseTestCache(t, 90)
+
+	_, err := cache.RenewSlotLeases(context.Background(), []service.SlotLease{{Kind: "group", ID: 1, RequestID: "r"}})
+	require.Error(t, err)
+}
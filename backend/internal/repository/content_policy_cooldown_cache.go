@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis Key 模式（所有条目都带 TTL，无需后台清理；多个网关实例共享同一份状态）
+const (
+	contentPolicyViolationsPrefix      = "content_policy:violations:"       // ZSET member=违规ID, score=违规时间毫秒, PX window
+	contentPolicyAccountCooldownPrefix = "content_policy:account_cooldown:" // STRING 冷却截止毫秒, PX 剩余冷却
+	contentPolicyUserCooldownsPrefix   = "content_policy:user_cooldowns:"   // ZSET member=userID, score=冷却截止毫秒, PX 剩余冷却
+	contentPolicyRiskyUsersKey         = "content_policy:risky_users"       // ZSET member=userID, score=高风险截止毫秒, PX window
+	contentPolicyAccountsKey           = "content_policy:accounts"          // ZSET member=accountID, score=统计可见截止毫秒（违规窗口与冷却取较晚者）
+)
+
+type contentPolicyCooldownCache struct {
+	rdb redis.UniversalClient
+}
+
+func NewContentPolicyCooldownCache(rdb redis.UniversalClient) service.ContentPolicyCooldownCache {
+	return &contentPolicyCooldownCache{rdb: rdb}
+}
+
+func contentPolicyViolationsKey(accountID int64) string {
+	return fmt.Sprintf("%s%d", contentPolicyViolationsPrefix, accountID)
+}
+
+func contentPolicyAccountCooldownKey(accountID int64) string {
+	return fmt.Sprintf("%s%d", contentPolicyAccountCooldownPrefix, accountID)
+}
+
+func contentPolicyUserCooldownsKey(accountID int64) string {
+	return fmt.Sprintf("%s%d", contentPolicyUserCooldownsPrefix, accountID)
+}
+
+func contentPolicyScoreMin(ms int64) string {
+	return "(" + strconv.FormatInt(ms, 10)
+}
+
+// RecordViolation 记录一次违规并返回账号在窗口内的违规次数
+func (c *contentPolicyCooldownCache) RecordViolation(ctx context.Context, v service.ContentPolicyViolation) (int, error) {
+	nowMs := v.At.UnixMilli()
+	cutoffMs := v.At.Add(-v.Window).UnixMilli()
+	cooldownTTL := v.CooldownUntil.Sub(v.At)
+	if cooldownTTL < time.Millisecond {
+		cooldownTTL = time.Millisecond
+	}
+	visibleTTL := max(v.Window, cooldownTTL)
+	violationsKey := contentPolicyViolationsKey(v.AccountID)
+	member := fmt.Sprintf("%d:%d", v.At.UnixNano(), v.UserID)
+
+	var count *redis.IntCmd
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, violationsKey, "-inf", strconv.FormatInt(cutoffMs, 10))
+		pipe.ZAdd(ctx, violationsKey, redis.Z{Score: float64(nowMs), Member: member})
+		pipe.PExpire(ctx, violationsKey, v.Window)
+		count = pipe.ZCard(ctx, violationsKey)
+
+		if v.AccountScope {
+			pipe.Set(ctx, contentPolicyAccountCooldownKey(v.AccountID), v.CooldownUntil.UnixMilli(), cooldownTTL)
+		} else {
+			userKey := contentPolicyUserCooldownsKey(v.AccountID)
+			pipe.ZRemRangeByScore(ctx, userKey, "-inf", strconv.FormatInt(nowMs, 10))
+			pipe.ZAdd(ctx, userKey, redis.Z{Score: float64(v.CooldownUntil.UnixMilli()), Member: v.UserID})
+			pipe.PExpire(ctx, userKey, cooldownTTL)
+		}
+
+		if v.UserID > 0 {
+			pipe.ZRemRangeByScore(ctx, contentPolicyRiskyUsersKey, "-inf", strconv.FormatInt(nowMs, 10))
+			pipe.ZAdd(ctx, contentPolicyRiskyUsersKey, redis.Z{Score: float64(v.At.Add(v.Window).UnixMilli()), Member: v.UserID})
+			pipe.PExpire(ctx, contentPolicyRiskyUsersKey, v.Window)
+		}
+
+		pipe.ZRemRangeByScore(ctx, contentPolicyAccountsKey, "-inf", strconv.FormatInt(nowMs, 10))
+		pipe.ZAdd(ctx, contentPolicyAccountsKey, redis.Z{Score: float64(nowMs + visibleTTL.Milliseconds()), Member: v.AccountID})
+		pipe.PExpire(ctx, contentPolicyAccountsKey, visibleTTL)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(count.Val()), nil
+}
+
+// GetAccountStates 批量读取账号对指定用户的冷却状态与窗口内违规次数，并返回该用户是否为高风险用户
+func (c *contentPolicyCooldownCache) GetAccountStates(ctx context.Context, userID int64, accountIDs []int64, window time.Duration, now time.Time) (map[int64]service.ContentPolicyAccountState, bool, error) {
+	if len(accountIDs) == 0 {
+		return map[int64]service.ContentPolicyAccountState{}, false, nil
+	}
+	nowMs := now.UnixMilli()
+	cutoff := contentPolicyScoreMin(now.Add(-window).UnixMilli())
+	userMember := strconv.FormatInt(userID, 10)
+
+	counts := make([]*redis.IntCmd, len(accountIDs))
+	accountCooldowns := make([]*redis.StringCmd, len(accountIDs))
+	userCooldowns := make([]*redis.FloatCmd, len(accountIDs))
+	var risky *redis.FloatCmd
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, accountID := range accountIDs {
+			counts[i] = pipe.ZCount(ctx, contentPolicyViolationsKey(accountID), cutoff, "+inf")
+			accountCooldowns[i] = pipe.Get(ctx, contentPolicyAccountCooldownKey(accountID))
+			if userID > 0 {
+				userCooldowns[i] = pipe.ZScore(ctx, contentPolicyUserCooldownsKey(accountID), userMember)
+			}
+		}
+		if userID > 0 {
+			risky = pipe.ZScore(ctx, contentPolicyRiskyUsersKey, userMember)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, false, err
+	}
+
+	states := make(map[int64]service.ContentPolicyAccountState, len(accountIDs))
+	for i, accountID := range accountIDs {
+		state := service.ContentPolicyAccountState{Violations: int(counts[i].Val())}
+		if until, err := accountCooldowns[i].Int64(); err == nil && until > nowMs {
+			state.Cooling = true
+		}
+		if userCooldowns[i] != nil {
+			if until, err := userCooldowns[i].Result(); err == nil && int64(until) > nowMs {
+				state.Cooling = true
+			}
+		}
+		states[accountID] = state
+	}
+	isRisky := false
+	if risky != nil {
+		if until, err := risky.Result(); err == nil && int64(until) > nowMs {
+			isRisky = true
+		}
+	}
+	return states, isRisky, nil
+}
+
+// GetStats 返回窗口内的高风险用户数与各账号违规统计（未排序）
+func (c *contentPolicyCooldownCache) GetStats(ctx context.Context, window time.Duration, now time.Time) (int, []service.ContentPolicyAccountStats, error) {
+	nowMs := now.UnixMilli()
+	cutoff := contentPolicyScoreMin(now.Add(-window).UnixMilli())
+
+	members, err := c.rdb.ZRangeByScore(ctx, contentPolicyAccountsKey, &redis.ZRangeBy{Min: contentPolicyScoreMin(nowMs), Max: "+inf"}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, nil, err
+	}
+	accountIDs := make([]int64, 0, len(members))
+	for _, member := range members {
+		if id, err := strconv.ParseInt(member, 10, 64); err == nil {
+			accountIDs = append(accountIDs, id)
+		}
+	}
+
+	var riskyCount *redis.IntCmd
+	counts := make([]*redis.IntCmd, len(accountIDs))
+	latest := make([]*redis.ZSliceCmd, len(accountIDs))
+	accountCooldowns := make([]*redis.StringCmd, len(accountIDs))
+	coolingUsers := make([]*redis.IntCmd, len(accountIDs))
+	_, err = c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		riskyCount = pipe.ZCount(ctx, contentPolicyRiskyUsersKey, contentPolicyScoreMin(nowMs), "+inf")
+		for i, accountID := range accountIDs {
+			counts[i] = pipe.ZCount(ctx, contentPolicyViolationsKey(accountID), cutoff, "+inf")
+			latest[i] = pipe.ZRevRangeWithScores(ctx, contentPolicyViolationsKey(accountID), 0, 0)
+			accountCooldowns[i] = pipe.Get(ctx, contentPolicyAccountCooldownKey(accountID))
+			coolingUsers[i] = pipe.ZCount(ctx, contentPolicyUserCooldownsKey(accountID), contentPolicyScoreMin(nowMs), "+inf")
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, nil, err
+	}
+
+	stats := make([]service.ContentPolicyAccountStats, 0, len(accountIDs))
+	for i, accountID := range accountIDs {
+		entry := service.ContentPolicyAccountStats{
+			AccountID:    accountID,
+			Violations:   int(counts[i].Val()),
+			CoolingUsers: int(coolingUsers[i].Val()),
+		}
+		if last := latest[i].Val(); len(last) > 0 && last[0].Score > float64(now.Add(-window).UnixMilli()) {
+			entry.LastViolationAt = time.UnixMilli(int64(last[0].Score))
+		}
+		if until, err := accountCooldowns[i].Int64(); err == nil && until > nowMs {
+			cooldownUntil := time.UnixMilli(until)
+			entry.CooldownUntil = &cooldownUntil
+		}
+		if entry.Violations == 0 && entry.CooldownUntil == nil && entry.CoolingUsers == 0 {
+			continue
+		}
+		stats = append(stats, entry)
+	}
+	return int(riskyCount.Val()), stats, nil
+}
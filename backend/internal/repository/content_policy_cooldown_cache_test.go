@@ -0,0 +1,99 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentPolicyCooldownCache_RecordAndStates(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewContentPolicyCooldownCache(rdb)
+
+	now := time.Now()
+	record := func(userID, accountID int64, at time.Time, accountScope bool) int {
+		count, err := cache.RecordViolation(ctx, service.ContentPolicyViolation{
+			AccountID:     accountID,
+			UserID:        userID,
+			At:            at,
+			CooldownUntil: at.Add(5 * time.Minute),
+			AccountScope:  accountScope,
+			Window:        time.Hour,
+		})
+		require.NoError(t, err)
+		return count
+	}
+
+	require.Equal(t, 1, record(7, 1, now.Add(-2*time.Hour), false), "outside the window by the next record")
+	require.Equal(t, 1, record(7, 1, now.Add(-time.Minute), false))
+	require.Equal(t, 2, record(8, 1, now, false))
+	record(0, 2, now, true)
+
+	states, risky, err := cache.GetAccountStates(ctx, 7, []int64{1, 2, 3}, time.Hour, now)
+	require.NoError(t, err)
+	require.True(t, risky)
+	require.Equal(t, service.ContentPolicyAccountState{Violations: 2, Cooling: true}, states[1])
+	require.Equal(t, service.ContentPolicyAccountState{Violations: 1, Cooling: true}, states[2])
+	require.Equal(t, service.ContentPolicyAccountState{}, states[3])
+
+	states, risky, err = cache.GetAccountStates(ctx, 9, []int64{1}, time.Hour, now)
+	require.NoError(t, err)
+	require.False(t, risky)
+	require.False(t, states[1].Cooling, "user cooldowns do not apply to other users")
+
+	// 冷却到期后仅保留窗口内计数
+	states, _, err = cache.GetAccountStates(ctx, 7, []int64{1, 2}, time.Hour, now.Add(10*time.Minute))
+	require.NoError(t, err)
+	require.False(t, states[1].Cooling)
+	require.False(t, states[2].Cooling)
+	require.Equal(t, 2, states[1].Violations)
+
+	ttl := mr.TTL(contentPolicyAccountCooldownKey(2))
+	require.Greater(t, ttl, time.Duration(0))
+	require.LessOrEqual(t, ttl, 5*time.Minute)
+}
+
+func TestContentPolicyCooldownCache_Stats(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewContentPolicyCooldownCache(rdb)
+
+	now := time.Now()
+	for _, v := range []service.ContentPolicyViolation{
+		{AccountID: 5, UserID: 7, At: now},
+		{AccountID: 5, UserID: 8, At: now},
+		{AccountID: 6, UserID: 0, At: now, AccountScope: true},
+	} {
+		v.CooldownUntil = v.At.Add(5 * time.Minute)
+		v.Window = time.Hour
+		_, err := cache.RecordViolation(ctx, v)
+		require.NoError(t, err)
+	}
+
+	riskyUsers, accounts, err := cache.GetStats(ctx, time.Hour, now)
+	require.NoError(t, err)
+	require.Equal(t, 2, riskyUsers)
+	require.Len(t, accounts, 2)
+	byID := map[int64]service.ContentPolicyAccountStats{}
+	for _, a := range accounts {
+		byID[a.AccountID] = a
+	}
+	require.Equal(t, 2, byID[5].Violations)
+	require.Equal(t, 2, byID[5].CoolingUsers)
+	require.Nil(t, byID[5].CooldownUntil)
+	require.Equal(t, now.UnixMilli(), byID[5].LastViolationAt.UnixMilli())
+	require.Equal(t, 1, byID[6].Violations)
+	require.NotNil(t, byID[6].CooldownUntil)
+}
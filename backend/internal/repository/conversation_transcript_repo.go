@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// conversationTranscriptRepository 会话留存仓储（raw SQL）。
+// payload 列只保存服务层加密后的密文，本层不感知明文。
+type conversationTranscriptRepository struct {
+	db *sql.DB
+}
+
+// NewConversationTranscriptRepository 创建会话留存仓储。
+func NewConversationTranscriptRepository(db *sql.DB) service.ConversationTranscriptRepository {
+	return &conversationTranscriptRepository{db: db}
+}
+
+const conversationTranscriptSelectColumns = `
+  t.id, t.created_at, t.user_id, t.api_key_id, t.group_id, t.request_id, t.endpoint,
+  t.model, t.stream, t.status_code, t.duration_ms, t.request_truncated, t.response_truncated`
+
+func (r *conversationTranscriptRepository) BatchInsert(ctx context.Context, items []*service.ConversationTranscript) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil conversation transcript repository")
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(
+		"conversation_transcripts",
+		"created_at", "user_id", "api_key_id", "group_id", "request_id", "endpoint", "model",
+		"stream", "status_code", "duration_ms", "request_payload", "response_payload",
+		"request_truncated", "response_truncated",
+	))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var inserted int64
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		createdAt := item.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now().UTC()
+		}
+		if _, err := stmt.ExecContext(ctx,
+			createdAt.UTC(),
+			item.UserID,
+			item.APIKeyID,
+			nullInt64Ptr(item.GroupID),
+			truncateString(item.RequestID, 64),
+			truncateString(item.Endpoint, 128),
+			truncateString(item.Model, 255),
+			item.Stream,
+			item.StatusCode,
+			item.DurationMs,
+			item.RequestPayload,
+			item.ResponsePayload,
+			item.RequestTruncated,
+			item.ResponseTruncated,
+		); err != nil {
+			_ = stmt.Close()
+			_ = tx.Rollback()
+			return inserted, err
+		}
+		inserted++
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		_ = tx.Rollback()
+		return inserted, err
+	}
+	if err := stmt.Close(); err != nil {
+		_ = tx.Rollback()
+		return inserted, err
+	}
+	if err := tx.Commit(); err != nil {
+		return inserted, err
+	}
+	return inserted, nil
+}
+
+func buildConversationTranscriptsWhere(filter *service.ConversationTranscriptFilter) (string, []any) {
+	clauses := []string{"t.user_id = $1"}
+	args := []any{filter.UserID}
+
+	if filter.APIKeyID != nil && *filter.APIKeyID > 0 {
+		args = append(args, *filter.APIKeyID)
+		clauses = append(clauses, "t.api_key_id = $"+itoa(len(args)))
+	}
+	if model := strings.TrimSpace(filter.Model); model != "" {
+		args = append(args, model)
+		clauses = append(clauses, "t.model = $"+itoa(len(args)))
+	}
+	if filter.StartTime != nil && !filter.StartTime.IsZero() {
+		args = append(args, filter.StartTime.UTC())
+		clauses = append(clauses, "t.created_at >= $"+itoa(len(args)))
+	}
+	if filter.EndTime != nil && !filter.EndTime.IsZero() {
+		args = append(args, filter.EndTime.UTC())
+		clauses = append(clauses, "t.created_at < $"+itoa(len(args)))
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func (r *conversationTranscriptRepository) List(ctx context.Context, filter *service.ConversationTranscriptFilter) (*service.ConversationTranscriptList, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil conversation transcript repository")
+	}
+	if filter == nil || filter.UserID <= 0 {
+		return nil, fmt.Errorf("conversation transcript list requires user_id")
+	}
+
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	where, args := buildConversationTranscriptsWhere(filter)
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM conversation_transcripts t "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	argsWithLimit := append(args, pageSize, offset)
+	query := "SELECT" + conversationTranscriptSelectColumns + "\nFROM conversation_transcripts t\n" + where + `
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, argsWithLimit...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.ConversationTranscript, 0, pageSize)
+	for rows.Next() {
+		item, err := scanConversationTranscriptRow(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &service.ConversationTranscriptList{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (r *conversationTranscriptRepository) GetByIDForUser(ctx context.Context, id, userID int64) (*service.ConversationTranscript, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil conversation transcript repository")
+	}
+	query := "SELECT" + conversationTranscriptSelectColumns + `, t.request_payload, t.response_payload
+FROM conversation_transcripts t WHERE t.id = $1 AND t.user_id = $2`
+	row := r.db.QueryRowContext(ctx, query, id, userID)
+
+	var requestPayload, responsePayload string
+	item, err := scanConversationTranscriptRow(func(dest ...any) error {
+		return row.Scan(append(dest, &requestPayload, &responsePayload)...)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, service.ErrConversationTranscriptNotFound
+		}
+		return nil, err
+	}
+	item.RequestPayload = requestPayload
+	item.ResponsePayload = responsePayload
+	return item, nil
+}
+
+func (r *conversationTranscriptRepository) DeleteBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil conversation transcript repository")
+	}
+	if batchSize <= 0 {
+		batchSize = 2000
+	}
+	res, err := r.db.ExecContext(ctx, `
+WITH batch AS (
+  SELECT id FROM conversation_transcripts WHERE created_at < $1 ORDER BY id LIMIT $2
+)
+DELETE FROM conversation_transcripts WHERE id IN (SELECT id FROM batch)`, cutoff.UTC(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanConversationTranscriptRow(scan func(dest ...any) error) (*service.ConversationTranscript, error) {
+	item := &service.ConversationTranscript{}
+	var groupID sql.NullInt64
+	if err := scan(
+		&item.ID,
+		&item.CreatedAt,
+		&item.UserID,
+		&item.APIKeyID,
+		&groupID,
+		&item.RequestID,
+		&item.Endpoint,
+		&item.Model,
+		&item.Stream,
+		&item.StatusCode,
+		&item.DurationMs,
+		&item.RequestTruncated,
+		&item.ResponseTruncated,
+	); err != nil {
+		return nil, err
+	}
+	if groupID.Valid {
+		v := groupID.Int64
+		item.GroupID = &v
+	}
+	return item, nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// credentialSecretMaxBodyBytes 密钥响应体上限，防止异常响应占用内存
+const credentialSecretMaxBodyBytes = 1 << 20
+
+// credentialSecretFetcher 通过 HTTP API 读取外部密钥库：
+// Vault 使用 KV v2 读接口，AWS Secrets Manager 使用 SigV4 签名的 GetSecretValue（无需额外 SDK 模块）。
+type credentialSecretFetcher struct {
+	cfg    config.CredentialSyncConfig
+	client *http.Client
+}
+
+func NewCredentialSecretFetcher(cfg *config.Config) service.CredentialSecretFetcher {
+	f := &credentialSecretFetcher{client: &http.Client{Timeout: 30 * time.Second}}
+	if cfg != nil {
+		f.cfg = cfg.CredentialSync
+	}
+	return f
+}
+
+func (f *credentialSecretFetcher) Fetch(ctx context.Context, provider, path string) (*service.CredentialSecret, error) {
+	switch provider {
+	case service.CredentialSourceProviderVault:
+		if strings.TrimSpace(f.cfg.Vault.Address) == "" {
+			return nil, service.ErrCredentialSourceProviderNotEnabled
+		}
+		return f.fetchVault(ctx, path)
+	case service.CredentialSourceProviderAWS:
+		if strings.TrimSpace(f.cfg.AWS.Region) == "" {
+			return nil, service.ErrCredentialSourceProviderNotEnabled
+		}
+		return f.fetchAWS(ctx, path)
+	default:
+		return nil, service.ErrCredentialSourceInvalidProvider
+	}
+}
+
+func (f *credentialSecretFetcher) fetchVault(ctx context.Context, path string) (*service.CredentialSecret, error) {
+	mount := strings.Trim(strings.TrimSpace(f.cfg.Vault.Mount), "/")
+	if mount == "" {
+		mount = "secret"
+	}
+	endpoint := strings.TrimRight(strings.TrimSpace(f.cfg.Vault.Address), "/") + "/v1/" + mount + "/data/" + escapeSecretPath(path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", f.cfg.Vault.Token)
+	if ns := strings.TrimSpace(f.cfg.Vault.Namespace); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	body, err := f.do(req, "vault")
+	if err != nil {
+		return nil, err
+	}
+	var parsed struct {
+		Data struct {
+			Data     map[string]any `json:"data"`
+			Metadata struct {
+				Version int64 `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("vault: decode response: %w", err)
+	}
+	secret := &service.CredentialSecret{Values: parsed.Data.Data}
+	if parsed.Data.Metadata.Version > 0 {
+		secret.Version = strconv.FormatInt(parsed.Data.Metadata.Version, 10)
+	}
+	return secret, nil
+}
+
+func (f *credentialSecretFetcher) fetchAWS(ctx context.Context, secretID string) (*service.CredentialSecret, error) {
+	region := strings.TrimSpace(f.cfg.AWS.Region)
+	creds, err := f.awsCredentials(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := strings.TrimRight(strings.TrimSpace(f.cfg.AWS.Endpoint), "/")
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+
+	payload, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	sum := sha256.Sum256(payload)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "secretsmanager", region, time.Now()); err != nil {
+		return nil, fmt.Errorf("aws: sign request: %w", err)
+	}
+
+	body, err := f.do(req, "aws")
+	if err != nil {
+		return nil, err
+	}
+	var parsed struct {
+		SecretString *string `json:"SecretString"`
+		VersionID    string  `json:"VersionId"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("aws: decode response: %w", err)
+	}
+	if parsed.SecretString == nil {
+		return nil, fmt.Errorf("aws: secret %s has no SecretString (binary secrets are not supported)", secretID)
+	}
+	return &service.CredentialSecret{
+		Values:  parseSecretString(*parsed.SecretString),
+		Version: parsed.VersionID,
+	}, nil
+}
+
+func (f *credentialSecretFetcher) awsCredentials(ctx context.Context, region string) (aws.Credentials, error) {
+	var provider aws.CredentialsProvider
+	if strings.TrimSpace(f.cfg.AWS.AccessKeyID) != "" {
+		provider = credentials.NewStaticCredentialsProvider(f.cfg.AWS.AccessKeyID, f.cfg.AWS.SecretAccessKey, "")
+	} else {
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+		if err != nil {
+			return aws.Credentials{}, fmt.Errorf("aws: load config: %w", err)
+		}
+		provider = awsCfg.Credentials
+	}
+	creds, err := provider.Retrieve(ctx)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("aws: retrieve credentials: %w", err)
+	}
+	return creds, nil
+}
+
+func (f *credentialSecretFetcher) do(req *http.Request, name string) ([]byte, error) {
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, credentialSecretMaxBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%s: read response: %w", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// 错误响应不含密钥内容，截断后用于排查
+		return nil, fmt.Errorf("%s: unexpected status %d: %s", name, resp.StatusCode, truncateString(strings.TrimSpace(string(body)), 200))
+	}
+	return body, nil
+}
+
+// parseSecretString 解析 AWS SecretString：JSON 对象按字段展开，其它内容作为单字段 "value"。
+func parseSecretString(s string) map[string]any {
+	var values map[string]any
+	if err := json.Unmarshal([]byte(s), &values); err == nil && values != nil {
+		return values
+	}
+	return map[string]any{"value": s}
+}
+
+// escapeSecretPath 逐段转义密钥路径，保留层级分隔符。
+func escapeSecretPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
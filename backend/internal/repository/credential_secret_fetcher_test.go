@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func newCredentialSecretFetcherForTest(sync config.CredentialSyncConfig) service.CredentialSecretFetcher {
+	return NewCredentialSecretFetcher(&config.Config{CredentialSync: sync})
+}
+
+func TestCredentialSecretFetcher_VaultKV2(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/kv/data/llm/claude%201", r.URL.EscapedPath())
+		require.Equal(t, "tok", r.Header.Get("X-Vault-Token"))
+		require.Equal(t, "team-a", r.Header.Get("X-Vault-Namespace"))
+		_, _ = w.Write([]byte(`{"data":{"data":{"access_token":"at","refresh_token":"rt"},"metadata":{"version":7}}}`))
+	}))
+	defer srv.Close()
+
+	fetcher := newCredentialSecretFetcherForTest(config.CredentialSyncConfig{
+		Vault: config.CredentialSyncVaultConfig{Address: srv.URL + "/", Token: "tok", Namespace: "team-a", Mount: "kv"},
+	})
+	secret, err := fetcher.Fetch(context.Background(), service.CredentialSourceProviderVault, "llm/claude 1")
+	require.NoError(t, err)
+	require.Equal(t, "7", secret.Version)
+	require.Equal(t, map[string]any{"access_token": "at", "refresh_token": "rt"}, secret.Values)
+}
+
+func TestCredentialSecretFetcher_VaultErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+	}))
+	defer srv.Close()
+
+	fetcher := newCredentialSecretFetcherForTest(config.CredentialSyncConfig{
+		Vault: config.CredentialSyncVaultConfig{Address: srv.URL, Token: "tok"},
+	})
+	_, err := fetcher.Fetch(context.Background(), service.CredentialSourceProviderVault, "x")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "403")
+	require.Contains(t, err.Error(), "permission denied")
+}
+
+func TestCredentialSecretFetcher_AWSSecretsManager(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		require.Contains(t, r.Header.Get("Authorization"), "/us-east-1/secretsmanager/aws4_request")
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]string
+		require.NoError(t, json.Unmarshal(body, &payload))
+		require.Equal(t, "prod/openai", payload["SecretId"])
+		_, _ = w.Write([]byte(`{"SecretString":"{\"api_key\":\"sk-1\"}","VersionId":"v-2"}`))
+	}))
+	defer srv.Close()
+
+	fetcher := newCredentialSecretFetcherForTest(config.CredentialSyncConfig{
+		AWS: config.CredentialSyncAWSConfig{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: srv.URL},
+	})
+	secret, err := fetcher.Fetch(context.Background(), service.CredentialSourceProviderAWS, "prod/openai")
+	require.NoError(t, err)
+	require.Equal(t, "v-2", secret.Version)
+	require.Equal(t, map[string]any{"api_key": "sk-1"}, secret.Values)
+}
+
+func TestCredentialSecretFetcher_ProviderNotConfigured(t *testing.T) {
+	fetcher := newCredentialSecretFetcherForTest(config.CredentialSyncConfig{})
+	_, err := fetcher.Fetch(context.Background(), service.CredentialSourceProviderVault, "x")
+	require.ErrorIs(t, err, service.ErrCredentialSourceProviderNotEnabled)
+	_, err = fetcher.Fetch(context.Background(), service.CredentialSourceProviderAWS, "x")
+	require.ErrorIs(t, err, service.ErrCredentialSourceProviderNotEnabled)
+}
+
+func TestParseSecretString_PlainValue(t *testing.T) {
+	require.Equal(t, map[string]any{"value": "sk-plain"}, parseSecretString("sk-plain"))
+}
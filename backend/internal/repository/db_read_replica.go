@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/lib/pq"
+)
+
+// dbReplicaLagQuery 查询副本复制延迟（秒）。
+// WAL 已全部回放时延迟为 0（主库空闲时 pg_last_xact_replay_timestamp 会一直变旧，不能直接相减）；
+// 从未回放过任何事务时返回 -1，视为不可用。连到的是主库（未处于恢复模式）时延迟为 0。
+const dbReplicaLagQuery = `
+SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), -1)
+END`
+
+// dbReplicaProbeTimeout 单次延迟探测超时
+const dbReplicaProbeTimeout = 3 * time.Second
+
+// DBReadReplica PostgreSQL 只读副本连接。
+//
+// 后台按 lag_check_interval_seconds 探测复制延迟；延迟超过 max_lag_seconds 或探测失败时
+// Reader 返回 nil，调用方回退主库。未启用 database.read_replica 时 Reader 始终返回 nil。
+type DBReadReplica struct {
+	db       *sql.DB
+	maxLag   time.Duration
+	interval time.Duration
+
+	// healthy 最近一次探测成功且延迟不超过 maxLag
+	healthy atomic.Bool
+	// lagMillis 最近一次探测到的复制延迟（毫秒），-1 表示未知
+	lagMillis atomic.Int64
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// ProvideDBReadReplica 按配置创建只读副本连接并启动延迟探测；未启用时返回空副本。
+func ProvideDBReadReplica(cfg *config.Config) *DBReadReplica {
+	if cfg == nil || !cfg.Database.ReadReplica.Enabled {
+		return &DBReadReplica{}
+	}
+	replicaCfg := cfg.Database.ReadReplica
+	conn := replicaCfg.Connection(cfg.Database)
+	connector, err := pq.NewConnector(conn.DSNWithTimezone(cfg.Timezone))
+	if err != nil {
+		slog.Error("database read replica disabled: invalid connection settings", "error", err)
+		return &DBReadReplica{}
+	}
+	var db *sql.DB
+	if cfg.Server.EnableServerTiming {
+		db = sql.OpenDB(newServerTimingConnector(connector))
+	} else {
+		db = sql.OpenDB(connector)
+	}
+	settings := clampDBPoolSettings(cfg)
+	db.SetMaxOpenConns(replicaCfg.MaxOpenConns)
+	db.SetMaxIdleConns(replicaCfg.MaxIdleConns)
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
+
+	r := newDBReadReplica(db, time.Duration(replicaCfg.MaxLagSeconds)*time.Second, time.Duration(replicaCfg.LagCheckIntervalSeconds)*time.Second)
+	r.start()
+	slog.Info("database read replica enabled", "host", conn.Host, "port", conn.Port, "max_lag", r.maxLag)
+	return r
+}
+
+func newDBReadReplica(db *sql.DB, maxLag, interval time.Duration) *DBReadReplica {
+	r := &DBReadReplica{db: db, maxLag: maxLag, interval: interval, stopCh: make(chan struct{})}
+	r.lagMillis.Store(-1)
+	return r
+}
+
+// Reader 返回重型只读查询应使用的副本连接；副本未启用、不健康或 ctx 要求读主库时返回 nil。
+func (r *DBReadReplica) Reader(ctx context.Context) *sql.DB {
+	if r == nil || r.db == nil || !r.healthy.Load() || service.PrimaryReadRequired(ctx) {
+		return nil
+	}
+	return r.db
+}
+
+// Lag 返回最近一次探测到的复制延迟；ok=false 表示尚未探测成功。
+func (r *DBReadReplica) Lag() (lag time.Duration, ok bool) {
+	if r == nil || r.db == nil {
+		return 0, false
+	}
+	ms := r.lagMillis.Load()
+	if ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+func (r *DBReadReplica) start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.probe()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case <-ticker.C:
+				r.probe()
+			}
+		}
+	}()
+}
+
+// probe 探测一次复制延迟并更新健康状态；状态变化时记录日志。
+func (r *DBReadReplica) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), dbReplicaProbeTimeout)
+	defer cancel()
+
+	var lagSeconds float64
+	err := r.db.QueryRowContext(ctx, dbReplicaLagQuery).Scan(&lagSeconds)
+	healthy := err == nil && lagSeconds >= 0 && time.Duration(lagSeconds*float64(time.Second)) <= r.maxLag
+	if err != nil || lagSeconds < 0 {
+		r.lagMillis.Store(-1)
+	} else {
+		r.lagMillis.Store(int64(math.Round(lagSeconds * 1000)))
+	}
+
+	if previous := r.healthy.Swap(healthy); previous != healthy {
+		if healthy {
+			slog.Info("database read replica healthy, routing heavy reads to replica", "lag_seconds", lagSeconds)
+		} else {
+			slog.Warn("database read replica unavailable, falling back to primary", "lag_seconds", lagSeconds, "max_lag", r.maxLag, "error", err)
+		}
+	}
+}
+
+// Close 停止延迟探测并关闭副本连接。
+func (r *DBReadReplica) Close() error {
+	if r == nil || r.db == nil {
+		return nil
+	}
+	r.stopOnce.Do(func() { close(r.stopCh) })
+	r.wg.Wait()
+	return r.db.Close()
+}
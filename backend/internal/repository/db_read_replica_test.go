@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBReadReplica_LagAwareRouting(t *testing.T) {
+	db, mock := newSQLMock(t)
+	replica := newDBReadReplica(db, 10*time.Second, time.Minute)
+	ctx := context.Background()
+
+	// 尚未探测：不可用
+	require.Nil(t, replica.Reader(ctx))
+
+	mock.ExpectQuery("pg_last_xact_replay_timestamp").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(2.5))
+	replica.probe()
+	require.Same(t, db, replica.Reader(ctx))
+	lag, ok := replica.Lag()
+	require.True(t, ok)
+	require.Equal(t, 2500*time.Millisecond, lag)
+
+	// 按次要求读主库
+	require.Nil(t, replica.Reader(service.WithPrimaryRead(ctx)))
+
+	// 延迟超过阈值回退主库
+	mock.ExpectQuery("pg_last_xact_replay_timestamp").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(30.0))
+	replica.probe()
+	require.Nil(t, replica.Reader(ctx))
+
+	mock.ExpectQuery("pg_last_xact_replay_timestamp").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0.0))
+	replica.probe()
+	require.NotNil(t, replica.Reader(ctx))
+
+	// 探测失败回退主库
+	mock.ExpectQuery("pg_last_xact_replay_timestamp").WillReturnError(errors.New("connection refused"))
+	replica.probe()
+	require.Nil(t, replica.Reader(ctx))
+	_, ok = replica.Lag()
+	require.False(t, ok)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageLogRepositoryReadSQL(t *testing.T) {
+	primary, _ := newSQLMock(t)
+	replicaDB, mock := newSQLMock(t)
+	replica := newDBReadReplica(replicaDB, 10*time.Second, time.Minute)
+	mock.ExpectQuery("pg_last_xact_replay_timestamp").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0.0))
+	replica.probe()
+
+	repo := newUsageLogRepositoryWithSQL(nil, primary)
+	ctx := context.Background()
+	require.Same(t, primary, repo.readSQL(ctx), "未配置副本时走主库")
+
+	repo.replica = replica
+	require.Same(t, replicaDB, repo.readSQL(ctx))
+	require.Same(t, primary, repo.readSQL(service.WithPrimaryRead(ctx)))
+
+	// 未启用副本（空 DBReadReplica）
+	repo.replica = &DBReadReplica{}
+	require.Same(t, primary, repo.readSQL(ctx))
+}
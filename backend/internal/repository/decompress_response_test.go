@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"compress/flate"
 	"compress/gzip"
+	"context"
 	"io"
 	"log/slog"
 	"net/http"
 	"testing"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/andybalholm/brotli"
 	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
@@ -63,6 +66,63 @@ func TestDecompressResponseBodyExistingEncodings(t *testing.T) {
 	}
 }
 
+func TestDecompressResponseBodyStackedEncodings(t *testing.T) {
+	payload := []byte(`{"usage":{"input_tokens":9}}`)
+	// Content-Encoding 按应用顺序列出：先 gzip 后 zstd
+	resp := newEncodedResponse("gzip, zstd", compressZstd(t, compressGzip(t, payload)))
+
+	decompressResponseBody(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, payload, body)
+	require.Empty(t, resp.Header.Get("Content-Encoding"))
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestDecompressResponseBodyXGzipAlias(t *testing.T) {
+	payload := []byte(`{"ok":true}`)
+	resp := newEncodedResponse("x-gzip", compressGzip(t, payload))
+
+	decompressResponseBody(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, payload, body)
+	require.Empty(t, resp.Header.Get("Content-Encoding"))
+}
+
+func TestDecompressResponseBodyWithSkipsDisallowedEncoding(t *testing.T) {
+	compressed := compressZstd(t, []byte(`{"ok":true}`))
+	resp := newEncodedResponse("zstd", compressed)
+
+	decompressResponseBodyWith(resp, allowedUpstreamEncodings([]string{"zstd"}))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, compressed, body)
+	require.Equal(t, "zstd", resp.Header.Get("Content-Encoding"))
+}
+
+func TestUpstreamDecompressionPolicyPerPlatform(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamDecompression.SkipEncodings = []string{"br"}
+	cfg.Gateway.UpstreamDecompression.PlatformSkipEncodings = map[string][]string{
+		"Gemini": {"*"},
+	}
+	policy := newUpstreamDecompressionPolicy(cfg)
+
+	defaults := policy.allowedFor(context.Background())
+	require.True(t, defaults["zstd"])
+	require.False(t, defaults["br"])
+
+	gemini := policy.allowedFor(context.WithValue(context.Background(), ctxkey.Platform, "gemini"))
+	require.NotNil(t, gemini)
+	require.Empty(t, gemini)
+
+	require.Nil(t, newUpstreamDecompressionPolicy(&config.Config{}).allowedFor(context.Background()))
+}
+
 func TestDecompressResponseBodyWithoutEncodingLeavesBodyUntouched(t *testing.T) {
 	originalBody := &responseTestBody{Reader: bytes.NewReader([]byte("plain"))}
 	resp := &http.Response{
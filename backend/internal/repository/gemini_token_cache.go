@@ -3,8 +3,13 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/redis/go-redis/v9"
@@ -13,37 +18,175 @@ import (
 const (
 	oauthTokenKeyPrefix       = "oauth:token:"
 	oauthRefreshLockKeyPrefix = "oauth:refresh_lock:"
+
+	// encryptedTokenValuePrefix 标记加密后的 token 值；无此前缀的值按明文处理，
+	// 因此开启/关闭加密都不需要清空已有缓存。
+	encryptedTokenValuePrefix = "enc:v1:"
+
+	tokenCacheWipeScanCount = 500
 )
 
 type geminiTokenCache struct {
-	rdb *redis.Client
+	rdb redis.UniversalClient
+	// replica 只读从节点客户端（可选），仅用于 GetAccessToken
+	replica redis.UniversalClient
+
+	// keyPrefix 部署/环境命名空间前缀，拼在所有 key 之前
+	keyPrefix string
+	// encryptor 用于解密带 encryptedTokenValuePrefix 的值；encrypt 为 true 时写入也加密
+	encryptor     service.SecretEncryptor
+	encrypt       bool
+	jitterPercent int
 }
 
-func NewGeminiTokenCache(rdb *redis.Client) service.GeminiTokenCache {
+func NewGeminiTokenCache(rdb redis.UniversalClient) service.GeminiTokenCache {
 	return &geminiTokenCache{rdb: rdb}
 }
 
+// ProvideGeminiTokenCache 启用 redis.read_from_replicas 时 access token 读取走从节点；
+// token_cache 配置控制 key 命名空间、值加密与 TTL 抖动。
+func ProvideGeminiTokenCache(rdb redis.UniversalClient, replica *RedisReadReplica, cfg *config.Config, encryptor service.SecretEncryptor) service.GeminiTokenCache {
+	c := &geminiTokenCache{rdb: rdb, encryptor: encryptor}
+	if replica != nil {
+		c.replica = replica.Client
+	}
+	if cfg != nil {
+		c.keyPrefix = cfg.TokenCache.KeyPrefix
+		if c.keyPrefix != "" && !strings.HasSuffix(c.keyPrefix, ":") {
+			c.keyPrefix += ":"
+		}
+		c.encrypt = cfg.TokenCache.Encrypt && encryptor != nil
+		c.jitterPercent = cfg.TokenCache.TTLJitterPercent
+	}
+	return c
+}
+
+func (c *geminiTokenCache) tokenKey(cacheKey string) string {
+	return fmt.Sprintf("%s%s%s", c.keyPrefix, oauthTokenKeyPrefix, cacheKey)
+}
+
+func (c *geminiTokenCache) lockKey(cacheKey string) string {
+	return fmt.Sprintf("%s%s%s", c.keyPrefix, oauthRefreshLockKeyPrefix, cacheKey)
+}
+
 func (c *geminiTokenCache) GetAccessToken(ctx context.Context, cacheKey string) (string, error) {
-	key := fmt.Sprintf("%s%s", oauthTokenKeyPrefix, cacheKey)
-	return c.rdb.Get(ctx, key).Result()
+	key := c.tokenKey(cacheKey)
+	if c.replica != nil {
+		// 从节点未命中（含尚未复制到的新 token）或不可用时回落主节点，避免触发多余的刷新
+		if value, err := c.replica.Get(ctx, key).Result(); err == nil {
+			return c.decodeValue(key, value)
+		}
+	}
+	value, err := c.rdb.Get(ctx, key).Result()
+	if err != nil {
+		return "", err
+	}
+	return c.decodeValue(key, value)
 }
 
 func (c *geminiTokenCache) SetAccessToken(ctx context.Context, cacheKey string, token string, ttl time.Duration) error {
-	key := fmt.Sprintf("%s%s", oauthTokenKeyPrefix, cacheKey)
-	return c.rdb.Set(ctx, key, token, ttl).Err()
+	value := token
+	if c.encrypt {
+		encrypted, err := c.encryptor.Encrypt(token)
+		if err != nil {
+			return fmt.Errorf("encrypt cached token: %w", err)
+		}
+		value = encryptedTokenValuePrefix + encrypted
+	}
+	return c.rdb.Set(ctx, c.tokenKey(cacheKey), value, c.jitterTTL(ttl)).Err()
 }
 
 func (c *geminiTokenCache) DeleteAccessToken(ctx context.Context, cacheKey string) error {
-	key := fmt.Sprintf("%s%s", oauthTokenKeyPrefix, cacheKey)
-	return c.rdb.Del(ctx, key).Err()
+	return c.rdb.Del(ctx, c.tokenKey(cacheKey)).Err()
 }
 
 func (c *geminiTokenCache) AcquireRefreshLock(ctx context.Context, cacheKey string, ttl time.Duration) (bool, error) {
-	key := fmt.Sprintf("%s%s", oauthRefreshLockKeyPrefix, cacheKey)
-	return c.rdb.SetNX(ctx, key, 1, ttl).Result()
+	return c.rdb.SetNX(ctx, c.lockKey(cacheKey), 1, ttl).Result()
 }
 
 func (c *geminiTokenCache) ReleaseRefreshLock(ctx context.Context, cacheKey string) error {
-	key := fmt.Sprintf("%s%s", oauthRefreshLockKeyPrefix, cacheKey)
-	return c.rdb.Del(ctx, key).Err()
+	return c.rdb.Del(ctx, c.lockKey(cacheKey)).Err()
+}
+
+// WipeAccessTokens 删除本命名空间下全部缓存的 access token，返回删除数量。
+// 刷新锁保持不动：正在进行的刷新完成后会按正常流程重新写入。
+func (c *geminiTokenCache) WipeAccessTokens(ctx context.Context) (int64, error) {
+	pattern := c.keyPrefix + oauthTokenKeyPrefix + "*"
+	// cluster 模式下 SCAN 只覆盖单个节点，需逐个主节点扫描；多 key DEL 可能跨 slot，改为逐 key 删除
+	if cluster, ok := c.rdb.(*redis.ClusterClient); ok {
+		var total atomic.Int64
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			n, err := wipeKeysByPattern(ctx, node, pattern, true)
+			total.Add(n)
+			return err
+		})
+		return total.Load(), err
+	}
+	return wipeKeysByPattern(ctx, c.rdb, pattern, false)
+}
+
+func wipeKeysByPattern(ctx context.Context, client redis.Cmdable, pattern string, perKey bool) (int64, error) {
+	var deleted int64
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, tokenCacheWipeScanCount).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("scan token cache keys: %w", err)
+		}
+		if len(keys) > 0 {
+			if perKey {
+				cmds, pipeErr := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+					for _, key := range keys {
+						pipe.Del(ctx, key)
+					}
+					return nil
+				})
+				for _, cmd := range cmds {
+					if del, ok := cmd.(*redis.IntCmd); ok {
+						deleted += del.Val()
+					}
+				}
+				err = pipeErr
+			} else {
+				var n int64
+				n, err = client.Del(ctx, keys...).Result()
+				deleted += n
+			}
+			if err != nil {
+				return deleted, fmt.Errorf("delete token cache keys: %w", err)
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+// decodeValue 解密带加密标记的值；无法解密（如密钥轮换、实例间密钥不一致）时按未命中处理，
+// 调用方会回落到账号凭证并重新写入缓存。
+func (c *geminiTokenCache) decodeValue(key, value string) (string, error) {
+	encrypted, ok := strings.CutPrefix(value, encryptedTokenValuePrefix)
+	if !ok {
+		return value, nil
+	}
+	if c.encryptor == nil {
+		return "", redis.Nil
+	}
+	token, err := c.encryptor.Decrypt(encrypted)
+	if err != nil {
+		logger.LegacyPrintf("repository.token_cache", "Warning: decrypt cached token failed, treat as miss: key=%s err=%v", key, err)
+		return "", redis.Nil
+	}
+	return token, nil
+}
+
+// jitterTTL 将 TTL 随机缩短 0~jitterPercent%。只缩短不延长：缓存 TTL 已按 token 过期时间计算，
+// 延长会导致返回已过期的 token。
+func (c *geminiTokenCache) jitterTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 || c.jitterPercent <= 0 {
+		return ttl
+	}
+	percent := min(c.jitterPercent, 50)
+	return ttl - time.Duration(float64(ttl)*float64(percent)/100*rand.Float64())
 }
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeminiTokenCache_ReadsFromReplicaWithPrimaryFallback(t *testing.T) {
+	primarySrv := miniredis.RunT(t)
+	replicaSrv := miniredis.RunT(t)
+	primary := redis.NewClient(&redis.Options{Addr: primarySrv.Addr()})
+	replica := redis.NewClient(&redis.Options{Addr: replicaSrv.Addr()})
+	t.Cleanup(func() {
+		_ = primary.Close()
+		_ = replica.Close()
+	})
+
+	cache := ProvideGeminiTokenCache(primary, &RedisReadReplica{Client: replica}, nil, nil)
+	ctx := context.Background()
+
+	// 写入只走主节点；从节点尚未复制时回落主节点
+	require.NoError(t, cache.SetAccessToken(ctx, "acc", "fresh", time.Minute))
+	token, err := cache.GetAccessToken(ctx, "acc")
+	require.NoError(t, err)
+	require.Equal(t, "fresh", token)
+
+	// 从节点命中时直接使用从节点的值
+	replicaSrv.Set(oauthTokenKeyPrefix+"acc", "replicated")
+	token, err = cache.GetAccessToken(ctx, "acc")
+	require.NoError(t, err)
+	require.Equal(t, "replicated", token)
+
+	// 从节点不可用时回落主节点
+	replicaSrv.Close()
+	token, err = cache.GetAccessToken(ctx, "acc")
+	require.NoError(t, err)
+	require.Equal(t, "fresh", token)
+
+	// 两边都没有时返回 redis.Nil
+	_, err = cache.GetAccessToken(ctx, "missing")
+	require.ErrorIs(t, err, redis.Nil)
+}
+
+func newTokenCacheTestConfig(encrypt bool, keyPrefix string, jitterPercent int) *config.Config {
+	cfg := &config.Config{}
+	cfg.Totp.EncryptionKey = strings.Repeat("ab", 32)
+	cfg.TokenCache = config.TokenCacheConfig{Encrypt: encrypt, KeyPrefix: keyPrefix, TTLJitterPercent: jitterPercent}
+	return cfg
+}
+
+func TestGeminiTokenCache_EncryptsValuesUnderNamespace(t *testing.T) {
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	cfg := newTokenCacheTestConfig(true, "prod", 0)
+	encryptor, err := NewAESEncryptor(cfg)
+	require.NoError(t, err)
+	cache := ProvideGeminiTokenCache(rdb, nil, cfg, encryptor)
+	ctx := context.Background()
+
+	require.NoError(t, cache.SetAccessToken(ctx, "acc", "secret-token", time.Minute))
+	raw, err := srv.Get("prod:" + oauthTokenKeyPrefix + "acc")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(raw, encryptedTokenValuePrefix))
+	require.NotContains(t, raw, "secret-token")
+	require.False(t, srv.Exists(oauthTokenKeyPrefix+"acc"))
+
+	token, err := cache.GetAccessToken(ctx, "acc")
+	require.NoError(t, err)
+	require.Equal(t, "secret-token", token)
+
+	// 明文旧值仍可读；关闭加密后已加密的值也仍可读
+	srv.Set("prod:"+oauthTokenKeyPrefix+"legacy", "plain-token")
+	token, err = cache.GetAccessToken(ctx, "legacy")
+	require.NoError(t, err)
+	require.Equal(t, "plain-token", token)
+
+	plainCache := ProvideGeminiTokenCache(rdb, nil, newTokenCacheTestConfig(false, "prod", 0), encryptor)
+	token, err = plainCache.GetAccessToken(ctx, "acc")
+	require.NoError(t, err)
+	require.Equal(t, "secret-token", token)
+
+	// 密钥不一致时按未命中处理
+	otherCfg := newTokenCacheTestConfig(true, "prod", 0)
+	otherCfg.Totp.EncryptionKey = strings.Repeat("cd", 32)
+	otherEncryptor, err := NewAESEncryptor(otherCfg)
+	require.NoError(t, err)
+	_, err = ProvideGeminiTokenCache(rdb, nil, otherCfg, otherEncryptor).GetAccessToken(ctx, "acc")
+	require.ErrorIs(t, err, redis.Nil)
+}
+
+func TestGeminiTokenCache_TTLJitterOnlyShortens(t *testing.T) {
+	cache := ProvideGeminiTokenCache(nil, nil, newTokenCacheTestConfig(false, "", 20), nil).(*geminiTokenCache)
+	for i := 0; i < 100; i++ {
+		ttl := cache.jitterTTL(time.Hour)
+		require.LessOrEqual(t, ttl, time.Hour)
+		require.GreaterOrEqual(t, ttl, 48*time.Minute)
+	}
+	require.Equal(t, time.Duration(0), cache.jitterTTL(0))
+}
+
+func TestGeminiTokenCache_WipeAccessTokensKeepsOtherNamespaces(t *testing.T) {
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	cache := ProvideGeminiTokenCache(rdb, nil, newTokenCacheTestConfig(false, "prod:", 0), nil)
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, cache.SetAccessToken(ctx, key, "t-"+key, time.Minute))
+	}
+	ok, err := cache.AcquireRefreshLock(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	srv.Set("staging:"+oauthTokenKeyPrefix+"a", "other-env")
+
+	deleted, err := cache.(service.TokenCacheWiper).WipeAccessTokens(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), deleted)
+
+	_, err = cache.GetAccessToken(ctx, "a")
+	require.ErrorIs(t, err, redis.Nil)
+	require.True(t, srv.Exists("prod:"+oauthRefreshLockKeyPrefix+"a"))
+	require.True(t, srv.Exists("staging:"+oauthTokenKeyPrefix+"a"))
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type groupBudgetRepository struct {
+	db *sql.DB
+}
+
+func NewGroupBudgetRepository(db *sql.DB) service.GroupBudgetRepository {
+	return &groupBudgetRepository{db: db}
+}
+
+func (r *groupBudgetRepository) List(ctx context.Context) ([]*service.GroupBudget, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("group budget repository db is nil")
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT b.group_id, b.enabled, b.monthly_budget_usd, b.fallback_group_id, b.shift_percent,
+			COALESCE(b.alerted_period, ''), b.created_at, b.updated_at,
+			g.name, f.name
+		FROM group_cost_budgets b
+		JOIN groups g ON g.id = b.group_id AND g.deleted_at IS NULL
+		JOIN groups f ON f.id = b.fallback_group_id AND f.deleted_at IS NULL
+		ORDER BY b.group_id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*service.GroupBudget
+	for rows.Next() {
+		item, err := scanGroupBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *groupBudgetRepository) Upsert(ctx context.Context, budget *service.GroupBudget) (*service.GroupBudget, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("group budget repository db is nil")
+	}
+	if budget == nil {
+		return nil, errors.New("group budget is nil")
+	}
+	row := r.db.QueryRowContext(ctx, `
+		WITH upserted AS (
+			INSERT INTO group_cost_budgets (group_id, enabled, monthly_budget_usd, fallback_group_id, shift_percent)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (group_id) DO UPDATE SET
+				enabled = EXCLUDED.enabled,
+				monthly_budget_usd = EXCLUDED.monthly_budget_usd,
+				fallback_group_id = EXCLUDED.fallback_group_id,
+				shift_percent = EXCLUDED.shift_percent,
+				updated_at = NOW()
+			RETURNING *
+		)
+		SELECT b.group_id, b.enabled, b.monthly_budget_usd, b.fallback_group_id, b.shift_percent,
+			COALESCE(b.alerted_period, ''), b.created_at, b.updated_at,
+			g.name, f.name
+		FROM upserted b
+		JOIN groups g ON g.id = b.group_id
+		JOIN groups f ON f.id = b.fallback_group_id
+	`, budget.GroupID, budget.Enabled, budget.MonthlyBudgetUSD, budget.FallbackGroupID, budget.ShiftPercent)
+	return scanGroupBudget(row)
+}
+
+func (r *groupBudgetRepository) Delete(ctx context.Context, groupID int64) error {
+	if r == nil || r.db == nil {
+		return errors.New("group budget repository db is nil")
+	}
+	res, err := r.db.ExecContext(ctx, "DELETE FROM group_cost_budgets WHERE group_id = $1", groupID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrGroupBudgetNotFound
+	}
+	return nil
+}
+
+// SumUpstreamCostSince 上游成本口径与看板账号成本一致；依赖 usage_logs (group_id, created_at) 复合索引。
+func (r *groupBudgetRepository) SumUpstreamCostSince(ctx context.Context, groupIDs []int64, since time.Time) (map[int64]float64, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("group budget repository db is nil")
+	}
+	out := make(map[int64]float64, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT group_id,
+			COALESCE(SUM(COALESCE(account_stats_cost, total_cost) * COALESCE(account_rate_multiplier, 1)), 0)
+		FROM usage_logs
+		WHERE group_id = ANY($1) AND created_at >= $2
+		GROUP BY group_id
+	`, pq.Array(groupIDs), since)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			groupID int64
+			cost    float64
+		)
+		if err := rows.Scan(&groupID, &cost); err != nil {
+			return nil, err
+		}
+		out[groupID] = cost
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *groupBudgetRepository) MarkAlerted(ctx context.Context, groupID int64, period string) (bool, error) {
+	if r == nil || r.db == nil {
+		return false, errors.New("group budget repository db is nil")
+	}
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE group_cost_budgets
+		SET alerted_period = $2
+		WHERE group_id = $1 AND alerted_period IS DISTINCT FROM $2
+	`, groupID, period)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func scanGroupBudget(row rowScanner) (*service.GroupBudget, error) {
+	var item service.GroupBudget
+	if err := row.Scan(
+		&item.GroupID, &item.Enabled, &item.MonthlyBudgetUSD, &item.FallbackGroupID, &item.ShiftPercent,
+		&item.AlertedPeriod, &item.CreatedAt, &item.UpdatedAt,
+		&item.GroupName, &item.FallbackGroupName,
+	); err != nil {
+		return nil, fmt.Errorf("scan group budget: %w", err)
+	}
+	return &item, nil
+}
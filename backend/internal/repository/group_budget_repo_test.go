@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupBudgetRepositorySumUpstreamCostSince(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &groupBudgetRepository{db: db}
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("COALESCE\\(account_stats_cost, total_cost\\) \\* COALESCE\\(account_rate_multiplier, 1\\)").
+		WithArgs(pq.Array([]int64{1, 2}), since).
+		WillReturnRows(sqlmock.NewRows([]string{"group_id", "cost"}).AddRow(int64(1), 12.5))
+
+	costs, err := repo.SumUpstreamCostSince(context.Background(), []int64{1, 2}, since)
+	require.NoError(t, err)
+	require.Equal(t, map[int64]float64{1: 12.5}, costs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupBudgetRepositoryMarkAlerted(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &groupBudgetRepository{db: db}
+
+	mock.ExpectExec("alerted_period IS DISTINCT FROM \\$2").
+		WithArgs(int64(1), "2026-10").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("alerted_period IS DISTINCT FROM \\$2").
+		WithArgs(int64(1), "2026-10").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	marked, err := repo.MarkAlerted(context.Background(), 1, "2026-10")
+	require.NoError(t, err)
+	require.True(t, marked)
+	marked, err = repo.MarkAlerted(context.Background(), 1, "2026-10")
+	require.NoError(t, err)
+	require.False(t, marked)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupBudgetRepositoryDelete_NotFound(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &groupBudgetRepository{db: db}
+
+	mock.ExpectExec("DELETE FROM group_cost_budgets").
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.ErrorIs(t, repo.Delete(context.Background(), 5), service.ErrGroupBudgetNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -96,6 +96,14 @@ func createGroupRecord(ctx context.Context, client *dbent.Client, groupIn *servi
 		SetMessagesDispatchModelConfig(groupIn.MessagesDispatchModelConfig).
 		SetModelsListConfig(groupIn.ModelsListConfig).
 		SetRpmLimit(groupIn.RPMLimit).
+		SetAllowComputerUseTool(groupIn.AllowComputerUseTool).
+		SetAllowCodeExecutionTool(groupIn.AllowCodeExecutionTool).
+		SetAllowWebSearchTool(groupIn.AllowWebSearchTool).
+		SetSystemPromptProfile(groupIn.SystemPromptProfile).
+		SetResponseLanguage(groupIn.ResponseLanguage).
+		SetAttributionMode(groupIn.AttributionMode).
+		SetAttributionText(groupIn.AttributionText).
+		SetMaxRequestCost(groupIn.MaxRequestCost).
 		SetPeakRateEnabled(groupIn.PeakRateEnabled).
 		SetPeakStart(groupIn.PeakStart).
 		SetPeakEnd(groupIn.PeakEnd).
@@ -259,6 +267,14 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetMessagesDispatchModelConfig(groupIn.MessagesDispatchModelConfig).
 		SetModelsListConfig(groupIn.ModelsListConfig).
 		SetRpmLimit(groupIn.RPMLimit).
+		SetAllowComputerUseTool(groupIn.AllowComputerUseTool).
+		SetAllowCodeExecutionTool(groupIn.AllowCodeExecutionTool).
+		SetAllowWebSearchTool(groupIn.AllowWebSearchTool).
+		SetSystemPromptProfile(groupIn.SystemPromptProfile).
+		SetResponseLanguage(groupIn.ResponseLanguage).
+		SetAttributionMode(groupIn.AttributionMode).
+		SetAttributionText(groupIn.AttributionText).
+		SetMaxRequestCost(groupIn.MaxRequestCost).
 		SetPeakRateEnabled(groupIn.PeakRateEnabled).
 		SetPeakStart(groupIn.PeakStart).
 		SetPeakEnd(groupIn.PeakEnd).
@@ -30,6 +30,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/proxyutil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/servertiming"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
 	"golang.org/x/mod/semver"
@@ -149,6 +150,18 @@ type httpUpstreamService struct {
 	clients map[string]*upstreamClientEntry // 客户端缓存池，key 由隔离策略决定
 	// OpenAI 走 HTTP/HTTPS 代理时的 H2->H1 回退状态（key=标准化 proxyKey）
 	openAIHTTP2Fallbacks sync.Map
+	// dns 自定义解析控制（gateway.dns），nil 表示使用默认解析
+	dns *upstreamDNSControls
+	// proxyRouter 多候选代理账号的延迟选路（gateway.proxy_latency_routing），nil 表示不选路
+	proxyRouter *service.ProxyLatencyRouter
+	// policy 自定义策略插件的 pre_forward 钩子（gateway.policy_plugins），nil 表示未启用
+	policy *policyplugin.Manager
+	// timeouts 按平台与请求类型的上游超时档位（运行时设置），nil 表示未启用
+	timeouts upstreamResponseTimeoutResolver
+	// mtls 按账号选择上游客户端证书（gateway.upstream_mtls），nil 表示未启用
+	mtls *service.UpstreamMTLSService
+	// decompression 按平台的响应解码策略（gateway.upstream_decompression），nil 表示解码全部内置编码
+	decompression *upstreamDecompressionPolicy
 }
 
 // NewHTTPUpstream 创建通用 HTTP 上游服务
@@ -163,7 +176,38 @@ func NewHTTPUpstream(cfg *config.Config) service.HTTPUpstream {
 	return &httpUpstreamService{
 		cfg:     cfg,
 		clients: make(map[string]*upstreamClientEntry),
+		dns:     newUpstreamDNSControls(cfg),
+
+		decompression: newUpstreamDecompressionPolicy(cfg),
+	}
+}
+
+// ProvideHTTPUpstream 创建带延迟选路的 HTTP 上游服务（供依赖注入使用）
+func ProvideHTTPUpstream(cfg *config.Config, proxyRouter *service.ProxyLatencyRouter, policy *policyplugin.Manager, mtls *service.UpstreamMTLSService, settingService *service.SettingService) service.HTTPUpstream {
+	s := NewHTTPUpstream(cfg).(*httpUpstreamService)
+	s.proxyRouter = proxyRouter
+	s.policy = policy
+	s.mtls = mtls
+	if settingService != nil {
+		s.timeouts = settingService
+	}
+	return s
+}
+
+// resolveMTLS 解析账号应使用的上游客户端证书，未配置时返回 nil
+func (s *httpUpstreamService) resolveMTLS(req *http.Request, accountID int64) *service.UpstreamMTLSIdentity {
+	if !s.mtls.Enabled() || req == nil {
+		return nil
 	}
+	return s.mtls.Resolve(req.Context(), accountID)
+}
+
+// routeProxy 账号配置了候选代理时，按上游 origin 替换为当前选中的代理
+func (s *httpUpstreamService) routeProxy(req *http.Request, proxyURL string, accountID int64) string {
+	if s.proxyRouter == nil || req == nil {
+		return proxyURL
+	}
+	return s.proxyRouter.Route(accountID, service.ProxyLatencyOrigin(req.URL), proxyURL)
 }
 
 // Do 执行 HTTP 请求
@@ -183,6 +227,10 @@ func NewHTTPUpstream(cfg *config.Config) service.HTTPUpstream {
 //   - 调用方必须关闭 resp.Body，否则会导致 inFlight 计数泄漏
 //   - inFlight > 0 的客户端不会被淘汰，确保活跃请求不被中断
 func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	if err := s.policy.PreForward(req, accountID); err != nil {
+		return nil, err
+	}
+	proxyURL = s.routeProxy(req, proxyURL, accountID)
 	applyGrokCLIProxyHeaders(req)
 	if err := s.validateRequestHost(req); err != nil {
 		return nil, err
@@ -193,7 +241,7 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 	}
 
 	// 获取或创建对应的客户端，并标记请求占用
-	entry, err := s.acquireClientWithProfile(proxyURL, accountID, accountConcurrency, profile)
+	entry, err := s.getClientEntryWithMTLS(proxyURL, accountID, accountConcurrency, profile, s.resolveMTLS(req, accountID), true, true)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +249,8 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 	// 执行请求
 	client := httpClientForUpstreamRequest(entry.client, req)
 	client = httpClientWithGrokAccessDeniedFallback(client)
-	resp, err := servertiming.Do(client, req)
+	req, finish := s.withResponseTimeout(req)
+	resp, err := finish(servertiming.Do(client, req))
 	if err != nil {
 		s.recordOpenAIHTTP2Failure(profile, entry.protocolMode, entry.proxyKey, err)
 		// 请求失败，立即减少计数
@@ -212,7 +261,9 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 	s.recordOpenAIHTTP2Success(profile, entry.protocolMode, entry.proxyKey)
 
 	// 如果上游返回了压缩内容，解压后再交给业务层
-	decompressResponseBody(resp)
+	decompressResponseBodyWith(resp, s.decompression.allowedFor(req.Context()))
+	// 开启上游原始响应留存时，边读边复制解压后的响应体
+	service.UpstreamStreamCaptureFromContext(req.Context()).WrapResponse(req, resp)
 
 	// 包装响应体，在关闭时自动减少计数并更新时间戳
 	// 这确保了流式响应（如 SSE）在完全读取前不会被淘汰
@@ -237,6 +288,10 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 	if req != nil && req.URL != nil && strings.EqualFold(req.URL.Scheme, "http") {
 		return s.Do(req, proxyURL, accountID, accountConcurrency)
 	}
+	if err := s.policy.PreForward(req, accountID); err != nil {
+		return nil, err
+	}
+	proxyURL = s.routeProxy(req, proxyURL, accountID)
 	applyGrokCLIProxyHeaders(req)
 	upstreamProfile := service.HTTPUpstreamProfileDefault
 	if req != nil {
@@ -257,7 +312,7 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 		return nil, err
 	}
 
-	entry, err := s.acquireClientWithTLS(proxyURL, accountID, accountConcurrency, profile, upstreamProfile)
+	entry, err := s.getClientEntryWithTLSAndMTLS(proxyURL, accountID, accountConcurrency, profile, upstreamProfile, s.resolveMTLS(req, accountID), true, true)
 	if err != nil {
 		slog.Debug("tls_fingerprint_acquire_client_failed", "account_id", accountID, "error", err)
 		return nil, err
@@ -265,7 +320,8 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 
 	client := httpClientForUpstreamRequest(entry.client, req)
 	client = httpClientWithGrokAccessDeniedFallback(client)
-	resp, err := servertiming.Do(client, req)
+	req, finish := s.withResponseTimeout(req)
+	resp, err := finish(servertiming.Do(client, req))
 	if err != nil {
 		atomic.AddInt64(&entry.inFlight, -1)
 		atomic.StoreInt64(&entry.lastUsed, time.Now().UnixNano())
@@ -273,7 +329,8 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 		return nil, err
 	}
 
-	decompressResponseBody(resp)
+	decompressResponseBodyWith(resp, s.decompression.allowedFor(req.Context()))
+	service.UpstreamStreamCaptureFromContext(req.Context()).WrapResponse(req, resp)
 
 	resp.Body = wrapTrackedBody(resp.Body, func() {
 		atomic.AddInt64(&entry.inFlight, -1)
@@ -469,6 +526,11 @@ func (s *httpUpstreamService) acquireClientWithTLS(proxyURL string, accountID in
 // getClientEntryWithTLS 获取或创建带 TLS 指纹的客户端条目
 // TLS 指纹客户端使用独立的缓存键，与普通客户端隔离
 func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile, upstreamProfile service.HTTPUpstreamProfile, markInFlight bool, enforceLimit bool) (*upstreamClientEntry, error) {
+	return s.getClientEntryWithTLSAndMTLS(proxyURL, accountID, accountConcurrency, profile, upstreamProfile, nil, markInFlight, enforceLimit)
+}
+
+// getClientEntryWithTLSAndMTLS 同 getClientEntryWithTLS，identity 非 nil 时在指纹握手中出示客户端证书
+func (s *httpUpstreamService) getClientEntryWithTLSAndMTLS(proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile, upstreamProfile service.HTTPUpstreamProfile, identity *service.UpstreamMTLSIdentity, markInFlight bool, enforceLimit bool) (*upstreamClientEntry, error) {
 	isolation := s.getIsolationMode()
 	proxyKey, parsedProxy, err := normalizeProxyURL(proxyURL)
 	if err != nil {
@@ -477,7 +539,7 @@ func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID i
 	settings := s.resolvePoolSettings(isolation, accountConcurrency)
 	settings = s.applyProfilePoolSettings(settings, upstreamProfile)
 	// TLS 指纹客户端使用独立的缓存键，加 "tls:" 前缀
-	cacheKey := "tls:" + buildCacheKey(isolation, proxyKey, accountID, upstreamProtocolModeDefault)
+	cacheKey := "tls:" + buildCacheKey(isolation, proxyKey, accountID, upstreamProtocolModeDefault) + mtlsCacheKeySuffix(identity)
 	poolKey := buildPoolKey(settings, upstreamProtocolModeDefault) + ":tls"
 
 	now := time.Now()
@@ -529,11 +591,15 @@ func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID i
 
 	// 创建带 TLS 指纹的 Transport
 	slog.Debug("tls_fingerprint_creating_new_client", "account_id", accountID, "cache_key", cacheKey, "proxy", proxyKey)
+	profile = withMTLSFingerprintProfile(profile, identity)
 	transport, err := buildUpstreamTransportWithTLSFingerprint(settings, parsedProxy, profile)
 	if err != nil {
 		s.mu.Unlock()
 		return nil, fmt.Errorf("build TLS fingerprint transport: %w", err)
 	}
+	s.dns.applyTLSFingerprintTransport(transport, parsedProxy, profile)
+	// HTTPS 代理/未知代理类型回退到标准 TLS 时同样需要证书
+	applyUpstreamMTLS(transport, identity)
 
 	client := &http.Client{Transport: transport}
 	if s.shouldValidateResolvedIP() {
@@ -625,6 +691,12 @@ func (s *httpUpstreamService) getOrCreateClient(proxyURL string, accountID int64
 // markInFlight=true 时会标记进行中请求，用于请求路径防止被淘汰
 // enforceLimit=true 时会限制客户端数量，超限且无法淘汰时返回错误
 func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, accountConcurrency int, profile service.HTTPUpstreamProfile, markInFlight bool, enforceLimit bool) (*upstreamClientEntry, error) {
+	return s.getClientEntryWithMTLS(proxyURL, accountID, accountConcurrency, profile, nil, markInFlight, enforceLimit)
+}
+
+// getClientEntryWithMTLS 同 getClientEntry，identity 非 nil 时客户端使用对应的 mTLS 证书，
+// 并以证书配置名区分缓存键，避免不同证书的账号共用连接
+func (s *httpUpstreamService) getClientEntryWithMTLS(proxyURL string, accountID int64, accountConcurrency int, profile service.HTTPUpstreamProfile, identity *service.UpstreamMTLSIdentity, markInFlight bool, enforceLimit bool) (*upstreamClientEntry, error) {
 	// 获取隔离模式
 	isolation := s.getIsolationMode()
 	// 标准化代理 URL 并解析
@@ -637,7 +709,7 @@ func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, a
 	settings := s.resolvePoolSettings(isolation, accountConcurrency)
 	settings = s.applyProfilePoolSettings(settings, profile)
 	// 构建缓存键（根据隔离策略不同）
-	cacheKey := buildCacheKey(isolation, proxyKey, accountID, protocolMode)
+	cacheKey := buildCacheKey(isolation, proxyKey, accountID, protocolMode) + mtlsCacheKeySuffix(identity)
 	// 构建连接池配置键（用于检测配置变更）
 	poolKey := buildPoolKey(settings, protocolMode)
 
@@ -687,6 +759,8 @@ func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, a
 		s.mu.Unlock()
 		return nil, fmt.Errorf("build transport: %w", err)
 	}
+	s.dns.applyTransport(transport, parsedProxy)
+	applyUpstreamMTLS(transport, identity)
 	client := &http.Client{Transport: transport}
 	if s.shouldValidateResolvedIP() {
 		client.CheckRedirect = s.redirectChecker
@@ -1292,6 +1366,40 @@ func buildUpstreamTransport(settings poolSettings, proxyURL *url.URL, protocolMo
 	return transport, nil
 }
 
+// mtlsCacheKeySuffix 返回 mTLS 证书对应的缓存键后缀，未使用证书时为空
+func mtlsCacheKeySuffix(identity *service.UpstreamMTLSIdentity) string {
+	if identity == nil {
+		return ""
+	}
+	return "|mtls:" + identity.Name
+}
+
+// applyUpstreamMTLS 为标准 TLS Transport 挂载客户端证书与 CA。
+// 在已有 TLSClientConfig 上修改（HTTP/2 配置会写入 NextProtos），不整体替换。
+func applyUpstreamMTLS(transport *http.Transport, identity *service.UpstreamMTLSIdentity) {
+	if transport == nil || identity == nil {
+		return
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	transport.TLSClientConfig.Certificates = identity.Certificates
+	if identity.RootCAs != nil {
+		transport.TLSClientConfig.RootCAs = identity.RootCAs
+	}
+}
+
+// withMTLSFingerprintProfile 返回附带客户端证书的指纹 Profile 副本（Profile 为全局共享，不能原地修改）
+func withMTLSFingerprintProfile(profile *tlsfingerprint.Profile, identity *service.UpstreamMTLSIdentity) *tlsfingerprint.Profile {
+	if profile == nil || identity == nil {
+		return profile
+	}
+	clone := *profile
+	clone.ClientCertificates = identity.Certificates
+	clone.RootCAs = identity.RootCAs
+	return &clone
+}
+
 // enableOpenAIHTTP2KeepAlive 在 http.Transport 上显式配置 HTTP/2 并启用连接健康探测。
 // Go 默认惰性配置 http2 且 ReadIdleTimeout=0（不发健康 PING），无法检测被代理/NAT
 // 静默掐断的死连接。此处主动设置 ReadIdleTimeout/PingTimeout，让死连接被提前 PING
@@ -1408,52 +1516,113 @@ func wrapTrackedBody(body io.ReadCloser, onClose func()) io.ReadCloser {
 // 当请求显式设置了 accept-encoding 时，Go 的 Transport 不会自动解压，需要手动处理。
 // 解压成功后会删除 Content-Encoding 和 Content-Length header（长度已不准确）。
 func decompressResponseBody(resp *http.Response) {
+	decompressResponseBodyWith(resp, nil)
+}
+
+// decompressResponseBodyWith 仅当 Content-Encoding 的每一层编码都在 allowed 中时解压，allowed 为 nil 表示全部内置编码；
+// 否则原样透传。多层编码（如 "gzip, zstd"）按逆序逐层解码，某层解码失败时保留已解开的部分并把剩余编码写回 header。
+func decompressResponseBodyWith(resp *http.Response, allowed map[string]bool) {
 	if resp == nil || resp.Body == nil {
 		return
 	}
-	ce := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
-	if ce == "" {
+	codings := parseContentEncodings(resp.Header.Get("Content-Encoding"))
+	if len(codings) == 0 {
 		return
 	}
+	for _, ce := range codings {
+		if !isSupportedContentEncoding(ce) || (allowed != nil && !allowed[ce]) {
+			return
+		}
+	}
 
 	originalBody := resp.Body
-	var reader io.Reader
+	var reader io.Reader = resp.Body
+	var layers []io.Closer
+	for i := len(codings) - 1; i >= 0; i-- {
+		decoded, preserved, err := newContentDecoder(codings[i], reader)
+		if err == nil {
+			if closer, ok := decoded.(io.Closer); ok {
+				layers = append(layers, closer)
+			}
+			reader = decoded
+			continue
+		}
+		if preserved != nil {
+			reader = preserved
+		}
+		if i == len(codings)-1 {
+			// 最外层即失败：保持 header 不变；已预读的字节通过 preserved 保留
+			if preserved != nil {
+				resp.Body = &decompressedBody{reader: reader, closer: originalBody}
+			}
+			return
+		}
+		resp.Body = &decompressedBody{reader: reader, closer: originalBody, layers: layers}
+		resp.Header.Set("Content-Encoding", strings.Join(codings[:i+1], ", "))
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		return
+	}
+
+	resp.Body = &decompressedBody{reader: reader, closer: originalBody, layers: layers}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length") // 解压后长度不确定
+	resp.ContentLength = -1
+}
+
+// parseContentEncodings 解析 Content-Encoding 为按应用顺序排列的编码列表（小写，忽略 identity，x-gzip 视为 gzip）。
+func parseContentEncodings(header string) []string {
+	var codings []string
+	for _, part := range strings.Split(header, ",") {
+		ce := strings.ToLower(strings.TrimSpace(part))
+		switch ce {
+		case "", "identity":
+			continue
+		case "x-gzip":
+			ce = "gzip"
+		}
+		codings = append(codings, ce)
+	}
+	return codings
+}
+
+func isSupportedContentEncoding(ce string) bool {
+	switch ce {
+	case "gzip", "br", "deflate", "zstd":
+		return true
+	}
+	return false
+}
+
+// newContentDecoder 构造单层解码 reader。失败时 preserved 非 nil 表示已预读的数据仍可从中完整读出。
+func newContentDecoder(ce string, r io.Reader) (decoded io.Reader, preserved io.Reader, err error) {
 	switch ce {
 	case "gzip":
-		gr, err := gzip.NewReader(resp.Body)
+		gr, err := gzip.NewReader(r)
 		if err != nil {
-			return // 解压失败，保持原样
+			return nil, nil, err // 解压失败，保持原样
 		}
-		reader = gr
+		return gr, nil, nil
 	case "br":
-		reader = brotli.NewReader(resp.Body)
+		return brotli.NewReader(r), nil, nil
 	case "deflate":
-		reader = flate.NewReader(resp.Body)
+		return flate.NewReader(r), nil, nil
 	case "zstd":
-		bufferedBody := bufio.NewReader(resp.Body)
-		resp.Body = &decompressedBody{reader: bufferedBody, closer: originalBody}
-
-		headerBytes, _ := bufferedBody.Peek(zstd.HeaderMaxSize)
+		buffered := bufio.NewReader(r)
+		headerBytes, _ := buffered.Peek(zstd.HeaderMaxSize)
 		var header zstd.Header
 		if err := header.Decode(headerBytes); err != nil {
 			slog.Warn("zstd_decompress_failed", "error", err)
-			return
+			return nil, buffered, err
 		}
-
-		zr, err := zstd.NewReader(bufferedBody)
+		zr, err := zstd.NewReader(buffered)
 		if err != nil {
 			slog.Warn("zstd_decompress_failed", "error", err)
-			return
+			return nil, buffered, err
 		}
-		reader = &zstdResponseReader{ReadCloser: zr.IOReadCloser()}
-	default:
-		return
+		return &zstdResponseReader{ReadCloser: zr.IOReadCloser()}, nil, nil
 	}
-
-	resp.Body = &decompressedBody{reader: reader, closer: originalBody}
-	resp.Header.Del("Content-Encoding")
-	resp.Header.Del("Content-Length") // 解压后长度不确定
-	resp.ContentLength = -1
+	return nil, nil, fmt.Errorf("unsupported content encoding %q", ce)
 }
 
 type zstdResponseReader struct {
@@ -1475,6 +1644,8 @@ func (r *zstdResponseReader) Read(p []byte) (int, error) {
 type decompressedBody struct {
 	reader io.Reader
 	closer io.Closer
+	// layers 多层编码时各层解码器（按解码顺序），关闭时逆序释放
+	layers []io.Closer
 }
 
 func (d *decompressedBody) Read(p []byte) (int, error) {
@@ -1483,7 +1654,11 @@ func (d *decompressedBody) Read(p []byte) (int, error) {
 
 func (d *decompressedBody) Close() error {
 	// 如果 reader 本身也是 Closer（如 gzip.Reader），先关闭它
-	if rc, ok := d.reader.(io.Closer); ok {
+	if len(d.layers) > 0 {
+		for i := len(d.layers) - 1; i >= 0; i-- {
+			_ = d.layers[i].Close()
+		}
+	} else if rc, ok := d.reader.(io.Closer); ok {
 		_ = rc.Close()
 	}
 	return d.closer.Close()
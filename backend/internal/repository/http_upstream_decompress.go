@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// upstreamDecompressionPolicy 按平台决定解码哪些上游 Content-Encoding（gateway.upstream_decompression）。
+// nil 或未配置跳过项时解码全部内置编码。
+type upstreamDecompressionPolicy struct {
+	// defaultAllowed 为 nil 表示全部可解码
+	defaultAllowed map[string]bool
+	platforms      map[string]map[string]bool
+}
+
+func newUpstreamDecompressionPolicy(cfg *config.Config) *upstreamDecompressionPolicy {
+	if cfg == nil {
+		return nil
+	}
+	dc := cfg.Gateway.UpstreamDecompression
+	if len(dc.SkipEncodings) == 0 && len(dc.PlatformSkipEncodings) == 0 {
+		return nil
+	}
+	p := &upstreamDecompressionPolicy{
+		defaultAllowed: allowedUpstreamEncodings(dc.SkipEncodings),
+		platforms:      make(map[string]map[string]bool, len(dc.PlatformSkipEncodings)),
+	}
+	for platform, skip := range dc.PlatformSkipEncodings {
+		p.platforms[strings.ToLower(strings.TrimSpace(platform))] = allowedUpstreamEncodings(skip)
+	}
+	return p
+}
+
+// allowedFor 返回请求所属平台可解码的编码集合，nil 表示全部。
+// 平台取自网关选定账号时写入 context 的 ctxkey.Platform，未知时使用默认策略。
+func (p *upstreamDecompressionPolicy) allowedFor(ctx context.Context) map[string]bool {
+	if p == nil {
+		return nil
+	}
+	if ctx != nil {
+		if platform, _ := ctx.Value(ctxkey.Platform).(string); platform != "" {
+			if allowed, ok := p.platforms[strings.ToLower(platform)]; ok {
+				return allowed
+			}
+		}
+	}
+	return p.defaultAllowed
+}
+
+// allowedUpstreamEncodings 由跳过列表计算可解码集合；不跳过任何编码时返回 nil。
+func allowedUpstreamEncodings(skip []string) map[string]bool {
+	if len(skip) == 0 {
+		return nil
+	}
+	allowed := make(map[string]bool, len(config.UpstreamContentEncodings))
+	for _, encoding := range config.UpstreamContentEncodings {
+		allowed[encoding] = true
+	}
+	for _, encoding := range skip {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding == "*" {
+			return map[string]bool{}
+		}
+		delete(allowed, encoding)
+	}
+	return allowed
+}
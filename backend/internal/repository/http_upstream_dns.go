@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/dnsresolve"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+)
+
+// upstreamDNSControls 上游连接的 DNS 解析控制（gateway.dns）
+//
+// 作用范围:
+//   - 直连: 目标主机经静态映射 / DoH 解析
+//   - HTTP/HTTPS 代理: 仅影响连接代理服务器本身（目标域名由代理解析）
+//   - SOCKS 代理: remote 模式交由代理端解析（仍尊重静态映射）；local 模式本地解析后以 IP 发起 CONNECT
+type upstreamDNSControls struct {
+	resolver    *dnsresolve.Resolver
+	defaultMode string
+	proxyModes  map[string]string
+}
+
+// newUpstreamDNSControls 根据配置构建解析控制；未配置任何自定义项时返回 nil（保持默认行为）
+func newUpstreamDNSControls(cfg *config.Config) *upstreamDNSControls {
+	if cfg == nil {
+		return nil
+	}
+	dnsCfg := cfg.Gateway.DNS
+	static := make(map[string][]string, len(dnsCfg.StaticHosts))
+	for _, entry := range dnsCfg.StaticHosts {
+		for _, ip := range entry.IPs {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				static[entry.Host] = append(static[entry.Host], ip)
+			}
+		}
+	}
+	d := &upstreamDNSControls{
+		resolver: dnsresolve.New(dnsresolve.Options{
+			StaticHosts: static,
+			DoHURL:      dnsCfg.DoHURL,
+			DoHTimeout:  time.Duration(dnsCfg.DoHTimeoutSeconds) * time.Second,
+		}),
+		defaultMode: normalizeDNSResolutionMode(dnsCfg.SOCKSResolution),
+		proxyModes:  make(map[string]string, len(dnsCfg.ProxyResolution)),
+	}
+	hasLocalMode := d.defaultMode == config.DNSResolutionLocal
+	for _, entry := range dnsCfg.ProxyResolution {
+		key := strings.ToLower(strings.TrimSpace(entry.Proxy))
+		if key == "" {
+			continue
+		}
+		mode := normalizeDNSResolutionMode(entry.Mode)
+		d.proxyModes[key] = mode
+		hasLocalMode = hasLocalMode || mode == config.DNSResolutionLocal
+	}
+	if d.resolver == nil && !hasLocalMode {
+		return nil
+	}
+	return d
+}
+
+func normalizeDNSResolutionMode(mode string) string {
+	if strings.EqualFold(strings.TrimSpace(mode), config.DNSResolutionLocal) {
+		return config.DNSResolutionLocal
+	}
+	return config.DNSResolutionRemote
+}
+
+// socksMode 返回代理的解析模式，按 host:port 优先、host 其次匹配
+func (d *upstreamDNSControls) socksMode(proxyURL *url.URL) string {
+	if mode, ok := d.proxyModes[strings.ToLower(proxyURL.Host)]; ok {
+		return mode
+	}
+	if mode, ok := d.proxyModes[strings.ToLower(proxyURL.Hostname())]; ok {
+		return mode
+	}
+	return d.defaultMode
+}
+
+// socksTarget 计算 SOCKS CONNECT 的目标地址
+func (d *upstreamDNSControls) socksTarget(ctx context.Context, addr, mode string) (string, error) {
+	if mode != config.DNSResolutionLocal {
+		return d.resolver.RewriteStatic(addr), nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, nil
+	}
+	ips, err := d.resolver.Resolve(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(ips[0], port), nil
+}
+
+func isSOCKSProxy(proxyURL *url.URL) bool {
+	if proxyURL == nil {
+		return false
+	}
+	scheme := strings.ToLower(proxyURL.Scheme)
+	return scheme == "socks5" || scheme == "socks5h"
+}
+
+// applyTransport 为 buildUpstreamTransport 构建的 Transport 挂载解析控制
+func (d *upstreamDNSControls) applyTransport(transport *http.Transport, proxyURL *url.URL) {
+	if d == nil || transport == nil {
+		return
+	}
+	if isSOCKSProxy(proxyURL) {
+		socksDial := transport.DialContext
+		if socksDial == nil {
+			return
+		}
+		mode := d.socksMode(proxyURL)
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			target, err := d.socksTarget(ctx, addr, mode)
+			if err != nil {
+				return nil, err
+			}
+			return socksDial(ctx, network, target)
+		}
+		return
+	}
+	if d.resolver == nil {
+		return
+	}
+	// 直连时解析目标主机；HTTP/HTTPS 代理时解析的是代理服务器地址
+	transport.DialContext = d.resolver.WrapDial(nil)
+}
+
+// applyTLSFingerprintTransport 为 buildUpstreamTransportWithTLSFingerprint 构建的 Transport 挂载解析控制。
+// HTTP CONNECT 指纹拨号器自行连接代理，暂不接入（目标域名本就由代理解析）。
+func (d *upstreamDNSControls) applyTLSFingerprintTransport(transport *http.Transport, proxyURL *url.URL, profile *tlsfingerprint.Profile) {
+	if d == nil || transport == nil {
+		return
+	}
+	if proxyURL == nil {
+		if d.resolver != nil {
+			transport.DialTLSContext = tlsfingerprint.NewDialer(profile, d.resolver.WrapDial(nil)).DialTLSContext
+		}
+		return
+	}
+	switch strings.ToLower(proxyURL.Scheme) {
+	case "socks5", "socks5h":
+		mode := d.socksMode(proxyURL)
+		transport.DialTLSContext = tlsfingerprint.NewSOCKS5ProxyDialer(profile, proxyURL).
+			WithTargetResolver(func(ctx context.Context, addr string) (string, error) {
+				return d.socksTarget(ctx, addr, mode)
+			}).DialTLSContext
+	case "https":
+		// HTTPS 代理回退为普通 Transport，与 buildUpstreamTransportWithTLSFingerprint 保持一致
+		d.applyTransport(transport, proxyURL)
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newDNSTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Gateway.DNS.SOCKSResolution = config.DNSResolutionRemote
+	cfg.Gateway.DNS.DoHTimeoutSeconds = 5
+	return cfg
+}
+
+func TestNewUpstreamDNSControls_DefaultIsNil(t *testing.T) {
+	require.Nil(t, newUpstreamDNSControls(nil))
+	require.Nil(t, newUpstreamDNSControls(newDNSTestConfig()))
+
+	cfg := newDNSTestConfig()
+	cfg.Gateway.DNS.ProxyResolution = []config.GatewayDNSProxyResolution{{Proxy: "socks.example:1080", Mode: "LOCAL"}}
+	d := newUpstreamDNSControls(cfg)
+	require.NotNil(t, d, "local resolution mode needs controls even without a custom resolver")
+
+	byHostPort, _ := url.Parse("socks5h://socks.example:1080")
+	otherPort, _ := url.Parse("socks5h://socks.example:2080")
+	require.Equal(t, config.DNSResolutionLocal, d.socksMode(byHostPort))
+	require.Equal(t, config.DNSResolutionRemote, d.socksMode(otherPort))
+}
+
+func TestHTTPUpstreamDoUsesStaticHostOverride(t *testing.T) {
+	var gotHost string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(upstream.Close)
+	_, port, err := net.SplitHostPort(upstream.Listener.Addr().String())
+	require.NoError(t, err)
+
+	cfg := newDNSTestConfig()
+	cfg.Gateway.DNS.StaticHosts = []config.GatewayDNSStaticHost{{Host: "pinned.invalid", IPs: []string{"127.0.0.1"}}}
+	client := NewHTTPUpstream(cfg)
+
+	req, err := http.NewRequest(http.MethodGet, "http://pinned.invalid:"+port+"/", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "pinned.invalid:"+port, gotHost, "Host header must keep the original name")
+}
+
+func TestHTTPUpstreamDoSOCKSRemoteModeHonorsStaticHosts(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(upstream.Close)
+	_, port, err := net.SplitHostPort(upstream.Listener.Addr().String())
+	require.NoError(t, err)
+	proxyURL, proxyCalls := startTestSOCKS5Proxy(t)
+
+	cfg := newDNSTestConfig()
+	cfg.Gateway.DNS.StaticHosts = []config.GatewayDNSStaticHost{{Host: "pinned.invalid", IPs: []string{"127.0.0.1"}}}
+	client := NewHTTPUpstream(cfg)
+
+	// 测试代理会直接 net.Dial 收到的目标，未改写的 .invalid 域名必然失败
+	req, err := http.NewRequest(http.MethodGet, "http://pinned.invalid:"+port+"/", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req, proxyURL, 2, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, int64(1), proxyCalls.Load())
+}
+
+func TestHTTPUpstreamDoSurfacesResolutionError(t *testing.T) {
+	cfg := newDNSTestConfig()
+	cfg.Gateway.DNS.SOCKSResolution = config.DNSResolutionLocal
+	cfg.Gateway.DNS.StaticHosts = []config.GatewayDNSStaticHost{{Host: "pinned.invalid", IPs: []string{"127.0.0.1"}}}
+	client := NewHTTPUpstream(cfg)
+	proxyURL, proxyCalls := startTestSOCKS5Proxy(t)
+
+	req, err := http.NewRequest(http.MethodGet, "http://unmapped.invalid/", nil)
+	require.NoError(t, err)
+	_, err = client.Do(req, proxyURL, 3, 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "dns resolve unmapped.invalid")
+	require.Zero(t, proxyCalls.Load(), "local mode must fail before contacting the proxy")
+}
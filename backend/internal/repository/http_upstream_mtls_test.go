@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+type mtlsAccountRepoStub struct {
+	service.AccountRepository
+	accounts map[int64]*service.Account
+}
+
+func (s *mtlsAccountRepoStub) GetByID(_ context.Context, id int64) (*service.Account, error) {
+	return s.accounts[id], nil
+}
+
+// newMTLSTestServer 启动要求客户端证书的 TLS 服务，返回服务、CA 文件与客户端证书/私钥文件
+func newMTLSTestServer(t *testing.T) (*httptest.Server, string, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sub2api-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	clientCert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Client-CN", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return server, caFile, certFile, keyFile
+}
+
+func newMTLSTestUpstream(t *testing.T, caFile, certFile, keyFile string) *httpUpstreamService {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamMTLS.Profiles = map[string]config.GatewayUpstreamMTLSProfile{
+		"corp": {CertFile: certFile, KeyFile: keyFile, CAFile: caFile},
+	}
+	cfg.Gateway.UpstreamMTLS.PlatformProfiles = map[string]string{service.PlatformAnthropic: "corp"}
+	mtls, err := service.NewUpstreamMTLSService(cfg, &mtlsAccountRepoStub{accounts: map[int64]*service.Account{
+		1: {ID: 1, Platform: service.PlatformAnthropic},
+		2: {ID: 2, Platform: service.PlatformAnthropic, Extra: map[string]any{service.UpstreamMTLSAccountExtraKey: service.UpstreamMTLSProfileNone}},
+	}})
+	require.NoError(t, err)
+	return ProvideHTTPUpstream(cfg, nil, nil, mtls, nil).(*httpUpstreamService)
+}
+
+func TestHTTPUpstreamDoPresentsMTLSClientCertificate(t *testing.T) {
+	server, caFile, certFile, keyFile := newMTLSTestServer(t)
+	upstream := newMTLSTestUpstream(t, caFile, certFile, keyFile)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := upstream.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "sub2api-client", resp.Header.Get("X-Client-CN"))
+
+	// 显式关闭 mTLS 的账号不出示证书，握手被服务端拒绝
+	req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = upstream.Do(req, "", 2, 1)
+	require.Error(t, err)
+}
+
+func TestHTTPUpstreamDoWithTLSPresentsMTLSClientCertificate(t *testing.T) {
+	server, caFile, certFile, keyFile := newMTLSTestServer(t)
+	upstream := newMTLSTestUpstream(t, caFile, certFile, keyFile)
+	profile := &tlsfingerprint.Profile{Name: "mtls-test"}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := upstream.DoWithTLS(req, "", 1, 1, profile)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "sub2api-client", resp.Header.Get("X-Client-CN"))
+	require.Nil(t, profile.ClientCertificates, "shared fingerprint profile must not be mutated")
+}
+
+func TestMTLSCacheKeySuffix(t *testing.T) {
+	require.Empty(t, mtlsCacheKeySuffix(nil))
+	require.Equal(t, "|mtls:corp", mtlsCacheKeySuffix(&service.UpstreamMTLSIdentity{Name: "corp"}))
+}
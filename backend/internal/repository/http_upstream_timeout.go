@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// upstreamResponseTimeoutResolver 按请求解析等待上游响应头的超时（平台超时档位），0 表示不额外限制。
+type upstreamResponseTimeoutResolver interface {
+	UpstreamResponseTimeout(ctx context.Context) time.Duration
+}
+
+// withResponseTimeout 按超时档位为请求加上等待响应头的截止时间。
+// 连接池的 ResponseHeaderTimeout 按客户端复用，无法区分平台与请求类型，因此在请求级别用定时取消实现；
+// 收到响应头后截止时间即解除，流式传输不受影响。返回的 finish 须在请求返回后调用。
+func (s *httpUpstreamService) withResponseTimeout(req *http.Request) (*http.Request, func(*http.Response, error) (*http.Response, error)) {
+	passthrough := func(resp *http.Response, err error) (*http.Response, error) { return resp, err }
+	if s == nil || s.timeouts == nil || req == nil {
+		return req, passthrough
+	}
+	timeout := s.timeouts.UpstreamResponseTimeout(req.Context())
+	if timeout <= 0 {
+		return req, passthrough
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(timeout, cancel)
+	return req.WithContext(ctx), func(resp *http.Response, err error) (*http.Response, error) {
+		if !timer.Stop() {
+			// 定时器已触发：无论请求结果如何都按超时处理，避免被误判为客户端断开
+			if resp != nil && resp.Body != nil {
+				_ = resp.Body.Close()
+			}
+			cancel()
+			return nil, fmt.Errorf("upstream response timeout after %s: %w", timeout, context.DeadlineExceeded)
+		}
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		resp.Body = wrapTrackedBody(resp.Body, cancel)
+		return resp, nil
+	}
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fixedUpstreamTimeout time.Duration
+
+func (d fixedUpstreamTimeout) UpstreamResponseTimeout(context.Context) time.Duration {
+	return time.Duration(d)
+}
+
+func TestHTTPUpstreamDoAppliesResponseTimeoutProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		// 响应头之后的传输不受档位限制
+		time.Sleep(150 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	}))
+	t.Cleanup(server.Close)
+
+	upstream := NewHTTPUpstream(nil).(*httpUpstreamService)
+	upstream.timeouts = fixedUpstreamTimeout(100 * time.Millisecond)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/slow", nil)
+	require.NoError(t, err)
+	_, err = upstream.Do(req, "", 1, 1)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	req, err = http.NewRequest(http.MethodGet, server.URL+"/stream", nil)
+	require.NoError(t, err)
+	resp, err := upstream.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "done", string(body))
+	require.NoError(t, resp.Body.Close())
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// modelAliasRepository 模型别名建议仓储（raw SQL）。
+type modelAliasRepository struct {
+	db *sql.DB
+}
+
+// NewModelAliasRepository 创建模型别名建议仓储。
+func NewModelAliasRepository(db *sql.DB) service.ModelAliasRepository {
+	return &modelAliasRepository{db: db}
+}
+
+const modelAliasSelectColumns = `
+  id, platform, requested_model, suggested_model, confidence, status, hit_count,
+  last_account_id, reviewed_by, first_seen_at, last_seen_at, reviewed_at`
+
+func (r *modelAliasRepository) UpsertObservations(ctx context.Context, observations []service.ModelAliasObservation) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil model alias repository")
+	}
+	if len(observations) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 已审核的条目保留审核时的目标模型，只累加命中统计
+	stmt, err := tx.PrepareContext(ctx, `
+INSERT INTO model_alias_suggestions
+  (platform, requested_model, suggested_model, confidence, hit_count, last_account_id, first_seen_at, last_seen_at)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6::bigint, 0), $7, $7)
+ON CONFLICT (platform, requested_model) DO UPDATE SET
+  hit_count = model_alias_suggestions.hit_count + EXCLUDED.hit_count,
+  last_account_id = COALESCE(EXCLUDED.last_account_id, model_alias_suggestions.last_account_id),
+  last_seen_at = GREATEST(model_alias_suggestions.last_seen_at, EXCLUDED.last_seen_at),
+  suggested_model = CASE
+    WHEN model_alias_suggestions.status = 'pending' AND EXCLUDED.confidence >= model_alias_suggestions.confidence
+      THEN EXCLUDED.suggested_model
+    ELSE model_alias_suggestions.suggested_model END,
+  confidence = CASE
+    WHEN model_alias_suggestions.status = 'pending' AND EXCLUDED.confidence >= model_alias_suggestions.confidence
+      THEN EXCLUDED.confidence
+    ELSE model_alias_suggestions.confidence END`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, obs := range observations {
+		if _, err := stmt.ExecContext(ctx,
+			obs.Platform, obs.RequestedModel, obs.SuggestedModel, obs.Confidence,
+			obs.Hits, obs.AccountID, obs.SeenAt,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *modelAliasRepository) List(ctx context.Context, filter *service.ModelAliasSuggestionFilter) (*service.ModelAliasSuggestionList, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil model alias repository")
+	}
+	if filter == nil {
+		filter = &service.ModelAliasSuggestionFilter{}
+	}
+	page, pageSize := filter.Page, filter.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	conditions := make([]string, 0, 3)
+	args := make([]any, 0, 5)
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, "status = $"+itoa(len(args)))
+	}
+	if filter.Platform != "" {
+		args = append(args, filter.Platform)
+		conditions = append(conditions, "platform = $"+itoa(len(args)))
+	}
+	if filter.UnknownOnly {
+		conditions = append(conditions, "suggested_model = ''")
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM model_alias_suggestions "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	rows, err := r.db.QueryContext(ctx, "SELECT"+modelAliasSelectColumns+"\nFROM model_alias_suggestions "+where+`
+ORDER BY hit_count DESC, id DESC
+LIMIT $`+itoa(len(args)-1)+` OFFSET $`+itoa(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	items, err := scanModelAliasRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &service.ModelAliasSuggestionList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func (r *modelAliasRepository) GetByID(ctx context.Context, id int64) (*service.ModelAliasSuggestion, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil model alias repository")
+	}
+	row := r.db.QueryRowContext(ctx, "SELECT"+modelAliasSelectColumns+`
+FROM model_alias_suggestions WHERE id = $1`, id)
+	item, err := scanModelAlias(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrModelAliasNotFound
+	}
+	return item, err
+}
+
+func (r *modelAliasRepository) Review(ctx context.Context, id int64, status, suggestedModel string, reviewerID int64) (*service.ModelAliasSuggestion, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil model alias repository")
+	}
+	row := r.db.QueryRowContext(ctx, `
+UPDATE model_alias_suggestions
+SET status = $2,
+    suggested_model = CASE WHEN $3::text = '' THEN suggested_model ELSE $3::text END,
+    reviewed_by = $4,
+    reviewed_at = NOW()
+WHERE id = $1
+RETURNING`+modelAliasSelectColumns, id, status, suggestedModel, reviewerID)
+	item, err := scanModelAlias(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrModelAliasNotFound
+	}
+	return item, err
+}
+
+func (r *modelAliasRepository) ListApproved(ctx context.Context) ([]*service.ModelAliasSuggestion, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil model alias repository")
+	}
+	rows, err := r.db.QueryContext(ctx, "SELECT"+modelAliasSelectColumns+`
+FROM model_alias_suggestions
+WHERE status = 'approved' AND suggested_model <> ''`)
+	if err != nil {
+		return nil, err
+	}
+	return scanModelAliasRows(rows)
+}
+
+func scanModelAliasRows(rows *sql.Rows) ([]*service.ModelAliasSuggestion, error) {
+	defer func() { _ = rows.Close() }()
+	items := make([]*service.ModelAliasSuggestion, 0)
+	for rows.Next() {
+		item, err := scanModelAlias(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func scanModelAlias(row rowScanner) (*service.ModelAliasSuggestion, error) {
+	item := &service.ModelAliasSuggestion{}
+	var (
+		lastAccountID sql.NullInt64
+		reviewedBy    sql.NullInt64
+		reviewedAt    sql.NullTime
+	)
+	if err := row.Scan(
+		&item.ID,
+		&item.Platform,
+		&item.RequestedModel,
+		&item.SuggestedModel,
+		&item.Confidence,
+		&item.Status,
+		&item.HitCount,
+		&lastAccountID,
+		&reviewedBy,
+		&item.FirstSeenAt,
+		&item.LastSeenAt,
+		&reviewedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastAccountID.Valid {
+		v := lastAccountID.Int64
+		item.LastAccountID = &v
+	}
+	if reviewedBy.Valid {
+		v := reviewedBy.Int64
+		item.ReviewedBy = &v
+	}
+	if reviewedAt.Valid {
+		t := reviewedAt.Time
+		item.ReviewedAt = &t
+	}
+	return item, nil
+}
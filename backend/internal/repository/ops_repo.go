@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/lib/pq"
 )
@@ -222,15 +223,31 @@ func (r *opsRepository) ListErrorLogs(ctx context.Context, filter *service.OpsEr
 	}
 
 	where, args := buildOpsErrorLogsWhere(filter)
-	countSQL := "SELECT COUNT(*) FROM ops_error_logs e " + where
+	orderBy := opsErrorLogsOrderBy(filter)
+	limitClause := `
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
 
 	var total int
-	if err := r.db.QueryRowContext(ctx, countSQL, args...).Scan(&total); err != nil {
-		return nil, err
+	var argsWithLimit []any
+	if filter.Cursor != nil {
+		// 游标模式：不做 COUNT，也不扫描 OFFSET 行；多取一行判断是否还有下一页
+		params := pagination.PaginationParams{PageSize: pageSize, SortOrder: filter.SortOrder, Cursor: filter.Cursor}
+		var conditions []string
+		conditions, args, orderBy = appendKeysetCursorCondition(nil, args, "e.created_at", "e.id", params)
+		if len(conditions) > 0 {
+			where += " AND " + conditions[0]
+		}
+		limitClause = `
+LIMIT $` + itoa(len(args)+1)
+		argsWithLimit = append(args, pageSize+1)
+	} else {
+		countSQL := "SELECT COUNT(*) FROM ops_error_logs e " + where
+		if err := r.db.QueryRowContext(ctx, countSQL, args...).Scan(&total); err != nil {
+			return nil, err
+		}
+		offset := (page - 1) * pageSize
+		argsWithLimit = append(args, pageSize, offset)
 	}
-
-	offset := (page - 1) * pageSize
-	argsWithLimit := append(args, pageSize, offset)
 	selectSQL := `
 SELECT
   e.id,
@@ -275,8 +292,7 @@ LEFT JOIN users u ON e.user_id = u.id
 LEFT JOIN users u2 ON e.resolved_by_user_id = u2.id
 LEFT JOIN api_keys ak ON ak.id = e.api_key_id
 ` + where + `
-ORDER BY ` + opsErrorLogsOrderBy(filter) + `
-LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+ORDER BY ` + orderBy + limitClause
 
 	rows, err := r.db.QueryContext(ctx, selectSQL, argsWithLimit...)
 	if err != nil {
@@ -386,12 +402,22 @@ LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
 		return nil, err
 	}
 
-	return &service.OpsErrorLogList{
+	list := &service.OpsErrorLogList{
 		Errors:   out,
 		Total:    total,
 		Page:     page,
 		PageSize: pageSize,
-	}, nil
+	}
+	if filter.Cursor != nil {
+		list.Page = 0
+		if len(out) > pageSize {
+			list.Errors = out[:pageSize]
+			last := list.Errors[pageSize-1]
+			list.HasMore = true
+			list.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		}
+	}
+	return list, nil
 }
 
 func (r *opsRepository) GetErrorLogByID(ctx context.Context, id int64) (*service.OpsErrorLogDetail, error) {
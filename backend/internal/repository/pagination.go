@@ -1,6 +1,10 @@
 package repository
 
-import "github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+import (
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
 
 func paginationResultFromTotal(total int64, params pagination.PaginationParams) *pagination.PaginationResult {
 	pages := int(total) / params.Limit()
@@ -33,3 +37,17 @@ func paginateSlice[T any](items []T, params pagination.PaginationParams) []T {
 
 	return items[offset:end]
 }
+
+// appendKeysetCursorCondition 追加 keyset 游标条件并返回对应的 ORDER BY 子句。
+// 排序方向取 params.SortOrder（默认 desc），cursor 为零值时只返回排序。
+func appendKeysetCursorCondition(conditions []string, args []any, createdAtCol, idCol string, params pagination.PaginationParams) ([]string, []any, string) {
+	op, dir := "<", "DESC"
+	if params.NormalizedSortOrder(pagination.SortOrderDesc) == pagination.SortOrderAsc {
+		op, dir = ">", "ASC"
+	}
+	if params.Cursor != nil && !params.Cursor.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("(%s, %s) %s ($%d, $%d)", createdAtCol, idCol, op, len(args)+1, len(args)+2))
+		args = append(args, params.Cursor.CreatedAt, params.Cursor.ID)
+	}
+	return conditions, args, fmt.Sprintf("%s %s, %s %s", createdAtCol, dir, idCol, dir)
+}
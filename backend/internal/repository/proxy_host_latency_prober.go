@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// NewProxyHostLatencyProber 创建经代理探测上游 origin 延迟的探测器（用于延迟选路）
+func NewProxyHostLatencyProber(cfg *config.Config) service.ProxyHostLatencyProber {
+	timeout := 5 * time.Second
+	if cfg != nil && cfg.Gateway.ProxyLatencyRouting.ProbeTimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.Gateway.ProxyLatencyRouting.ProbeTimeoutSeconds) * time.Second
+	}
+	return &proxyHostLatencyProber{timeout: timeout}
+}
+
+type proxyHostLatencyProber struct {
+	timeout time.Duration
+}
+
+// ProbeHostLatency 经代理向 origin 根路径发送 HEAD 请求，返回收到响应头的耗时。
+// 任意 HTTP 状态码都视为可达：探测只关心链路延迟，不关心上游是否接受该路径。
+func (p *proxyHostLatencyProber) ProbeHostLatency(ctx context.Context, proxyURL, origin string) (time.Duration, error) {
+	client, err := httpclient.GetClient(httpclient.Options{
+		ProxyURL: proxyURL,
+		Timeout:  p.timeout,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create proxy client: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, origin+"/", nil)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	return latency, nil
+}
@@ -1,10 +1,14 @@
 package repository
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -20,14 +24,53 @@ import (
 // 1. PoolSize: 控制最大并发连接数（默认 128）
 // 2. MinIdleConns: 保持最小空闲连接，减少冷启动延迟（默认 10）
 // 3. DialTimeout/ReadTimeout/WriteTimeout: 精确控制各阶段超时
-func InitRedis(cfg *config.Config) *redis.Client {
-	client := redis.NewClient(buildRedisOptions(cfg))
+//
+// 按 redis.mode 选择拓扑：standalone 直连，sentinel 通过哨兵发现主节点并在故障转移后自动切换，
+// cluster 按 slot 路由并在 MOVED/ASK 或节点故障时自动刷新路由表。
+func InitRedis(cfg *config.Config) redis.UniversalClient {
+	var client redis.UniversalClient
+	switch cfg.Redis.NormalizedMode() {
+	case config.RedisModeSentinel:
+		client = redis.NewFailoverClient(buildRedisFailoverOptions(cfg, false))
+	case config.RedisModeCluster:
+		client = redis.NewClusterClient(buildRedisClusterOptions(cfg, false))
+	default:
+		client = redis.NewClient(buildRedisOptions(cfg))
+	}
 	if cfg.Server.EnableServerTiming {
 		client.AddHook(serverTimingRedisHook{})
 	}
 	return client
 }
 
+// RedisReadReplica 热点只读路径使用的从节点客户端；未启用 read_from_replicas 或 standalone 模式下 Client 为 nil。
+type RedisReadReplica struct {
+	Client redis.UniversalClient
+}
+
+// ProvideRedisReadReplica 按配置创建只读从节点客户端。
+func ProvideRedisReadReplica(cfg *config.Config) *RedisReadReplica {
+	if cfg == nil || !cfg.Redis.ReadFromReplicas {
+		return &RedisReadReplica{}
+	}
+	switch cfg.Redis.NormalizedMode() {
+	case config.RedisModeSentinel:
+		return &RedisReadReplica{Client: redis.NewFailoverClient(buildRedisFailoverOptions(cfg, true))}
+	case config.RedisModeCluster:
+		return &RedisReadReplica{Client: redis.NewClusterClient(buildRedisClusterOptions(cfg, true))}
+	default:
+		return &RedisReadReplica{}
+	}
+}
+
+// Close 关闭从节点客户端。
+func (r *RedisReadReplica) Close() error {
+	if r == nil || r.Client == nil {
+		return nil
+	}
+	return r.Client.Close()
+}
+
 // buildRedisOptions 构建 Redis 连接选项
 // 从配置文件读取连接池和超时参数，支持生产环境调优
 func buildRedisOptions(cfg *config.Config) *redis.Options {
@@ -42,12 +85,155 @@ func buildRedisOptions(cfg *config.Config) *redis.Options {
 		MinIdleConns: cfg.Redis.MinIdleConns,                                     // 最小空闲连接
 	}
 
-	if cfg.Redis.EnableTLS {
-		opts.TLSConfig = &tls.Config{
-			MinVersion: tls.VersionTLS12,
-			ServerName: cfg.Redis.Host,
+	opts.TLSConfig = buildRedisTLSConfig(cfg, cfg.Redis.Host)
+
+	return opts
+}
+
+// buildRedisFailoverOptions 构建 sentinel 模式连接选项；replicaOnly 为 true 时只连接从节点。
+func buildRedisFailoverOptions(cfg *config.Config, replicaOnly bool) *redis.FailoverOptions {
+	return &redis.FailoverOptions{
+		MasterName:       cfg.Redis.MasterName,
+		SentinelAddrs:    cfg.Redis.Addrs,
+		SentinelPassword: cfg.Redis.SentinelPassword,
+		ReplicaOnly:      replicaOnly,
+		Password:         cfg.Redis.Password,
+		DB:               cfg.Redis.DB,
+		DialTimeout:      time.Duration(cfg.Redis.DialTimeoutSeconds) * time.Second,
+		ReadTimeout:      time.Duration(cfg.Redis.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout:     time.Duration(cfg.Redis.WriteTimeoutSeconds) * time.Second,
+		PoolSize:         cfg.Redis.PoolSize,
+		MinIdleConns:     cfg.Redis.MinIdleConns,
+		TLSConfig:        buildRedisTLSConfig(cfg, ""),
+	}
+}
+
+// buildRedisClusterOptions 构建 cluster 模式连接选项；readOnly 为 true 时只读命令随机路由到主从节点。
+// 连接池参数按单节点生效。
+func buildRedisClusterOptions(cfg *config.Config, readOnly bool) *redis.ClusterOptions {
+	return &redis.ClusterOptions{
+		Addrs:         cfg.Redis.Addrs,
+		Password:      cfg.Redis.Password,
+		ReadOnly:      readOnly,
+		RouteRandomly: readOnly,
+		DialTimeout:   time.Duration(cfg.Redis.DialTimeoutSeconds) * time.Second,
+		ReadTimeout:   time.Duration(cfg.Redis.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout:  time.Duration(cfg.Redis.WriteTimeoutSeconds) * time.Second,
+		PoolSize:      cfg.Redis.PoolSize,
+		MinIdleConns:  cfg.Redis.MinIdleConns,
+		TLSConfig:     buildRedisTLSConfig(cfg, ""),
+	}
+}
+
+// buildRedisTLSConfig 按 redis.enable_tls 构建各拓扑共用的 TLS 配置。
+// standalone 以 redis.host 作为 ServerName；多节点拓扑传空串，握手时按实际拨号地址校验证书。
+func buildRedisTLSConfig(cfg *config.Config, serverName string) *tls.Config {
+	if !cfg.Redis.EnableTLS {
+		return nil
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+}
+
+// isRedisCluster 判断客户端是否为 cluster 模式。
+func isRedisCluster(rdb redis.UniversalClient) bool {
+	_, ok := rdb.(*redis.ClusterClient)
+	return ok
+}
+
+// redisSlotTag cluster 模式下需要在同一 Lua 脚本 / MULTI 事务中操作的一组 key 的公共前缀。
+// 多 key 命令要求所有 key 落在同一 slot（否则返回 CROSSSLOT），同组 key 加上相同的 {tag} 即可；
+// standalone / sentinel 模式为空串，保持原 key 不变，升级后已有数据不受影响。
+type redisSlotTag string
+
+func newRedisSlotTag(rdb redis.UniversalClient, tag string) redisSlotTag {
+	if !isRedisCluster(rdb) {
+		return ""
+	}
+	return redisSlotTag("{" + tag + "}")
+}
+
+// key 返回加上 hash tag 后的 key（Redis 只取第一个 {...}，原 key 中的花括号不再参与 slot 计算）。
+func (t redisSlotTag) key(k string) string {
+	return string(t) + k
+}
+
+// redisMGet 与 MGET 返回值语义一致（未命中为 nil）。
+// cluster 模式下 key 通常分布在多个 slot，改为流水线逐个 GET，由 go-redis 按节点拆分发送。
+func redisMGet(ctx context.Context, rdb redis.UniversalClient, keys ...string) ([]any, error) {
+	if !isRedisCluster(rdb) {
+		return rdb.MGet(ctx, keys...).Result()
+	}
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Get(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	out := make([]any, len(keys))
+	for i, cmd := range cmds {
+		val, err := cmd.Result()
+		if errors.Is(err, redis.Nil) {
+			continue
 		}
+		if err != nil {
+			return nil, err
+		}
+		out[i] = val
 	}
+	return out, nil
+}
 
-	return opts
+// redisHealthProbe 实现 service.RedisProbe
+type redisHealthProbe struct {
+	mode    string
+	rdb     redis.UniversalClient
+	replica redis.UniversalClient
+}
+
+// NewRedisHealthProbe 创建 Redis 健康探测。
+func NewRedisHealthProbe(rdb redis.UniversalClient, replica *RedisReadReplica, cfg *config.Config) service.RedisProbe {
+	p := &redisHealthProbe{mode: config.RedisModeStandalone, rdb: rdb}
+	if cfg != nil {
+		p.mode = cfg.Redis.NormalizedMode()
+	}
+	if replica != nil {
+		p.replica = replica.Client
+	}
+	return p
+}
+
+func (p *redisHealthProbe) Mode() string { return p.mode }
+
+// Ping cluster 模式逐个主节点 PING，任一分片不可达即视为失败。
+func (p *redisHealthProbe) Ping(ctx context.Context) error {
+	if cluster, ok := p.rdb.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			if err := node.Ping(ctx).Err(); err != nil {
+				return fmt.Errorf("%s: %w", node.Options().Addr, err)
+			}
+			return nil
+		})
+	}
+	return p.rdb.Ping(ctx).Err()
+}
+
+func (p *redisHealthProbe) PingReplicas(ctx context.Context) error {
+	if p.replica == nil {
+		return nil
+	}
+	return p.replica.Ping(ctx).Err()
+}
+
+// Refresh cluster 模式下主动重新加载 slot 路由，加快故障转移后的收敛；其余模式由客户端自行处理。
+func (p *redisHealthProbe) Refresh(ctx context.Context) {
+	for _, c := range []redis.UniversalClient{p.rdb, p.replica} {
+		if cluster, ok := c.(*redis.ClusterClient); ok {
+			cluster.ReloadState(ctx)
+		}
+	}
 }
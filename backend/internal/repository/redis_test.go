@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 )
 
@@ -45,3 +48,74 @@ func TestBuildRedisOptions(t *testing.T) {
 	require.NotNil(t, optsTLS.TLSConfig)
 	require.Equal(t, "localhost", optsTLS.TLSConfig.ServerName)
 }
+
+func TestBuildRedisTopologyOptions(t *testing.T) {
+	cfg := &config.Config{
+		Redis: config.RedisConfig{
+			Password:            "secret",
+			DialTimeoutSeconds:  5,
+			ReadTimeoutSeconds:  3,
+			WriteTimeoutSeconds: 4,
+			PoolSize:            100,
+			MinIdleConns:        10,
+			EnableTLS:           true,
+			Addrs:               []string{"s1:26379", "s2:26379"},
+			MasterName:          "mymaster",
+			SentinelPassword:    "sentinel-secret",
+		},
+	}
+
+	failover := buildRedisFailoverOptions(cfg, true)
+	require.Equal(t, "mymaster", failover.MasterName)
+	require.Equal(t, []string{"s1:26379", "s2:26379"}, failover.SentinelAddrs)
+	require.Equal(t, "sentinel-secret", failover.SentinelPassword)
+	require.Equal(t, "secret", failover.Password)
+	require.True(t, failover.ReplicaOnly)
+	require.Equal(t, 100, failover.PoolSize)
+	require.NotNil(t, failover.TLSConfig)
+	require.Empty(t, failover.TLSConfig.ServerName)
+	require.Equal(t, buildRedisTLSConfig(cfg, "").MinVersion, failover.TLSConfig.MinVersion)
+
+	cluster := buildRedisClusterOptions(cfg, false)
+	require.Equal(t, []string{"s1:26379", "s2:26379"}, cluster.Addrs)
+	require.False(t, cluster.ReadOnly)
+	require.False(t, cluster.RouteRandomly)
+	require.Equal(t, 4*time.Second, cluster.WriteTimeout)
+
+	readOnly := buildRedisClusterOptions(cfg, true)
+	require.True(t, readOnly.ReadOnly)
+	require.True(t, readOnly.RouteRandomly)
+}
+
+func TestRedisSlotTag_OnlyTagsClusterKeys(t *testing.T) {
+	standalone := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer func() { _ = standalone.Close() }()
+	require.Equal(t, "sched:buckets", newRedisSlotTag(standalone, "sched").key("sched:buckets"))
+
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{"127.0.0.1:0"}})
+	defer func() { _ = cluster.Close() }()
+	require.Equal(t, "{sched}sched:buckets", newRedisSlotTag(cluster, "sched").key("sched:buckets"))
+
+	cache := newSchedulerCacheWithChunkSizes(cluster, 0, 0).(*schedulerCache)
+	bucket := service.SchedulerBucket{GroupID: 1, Platform: service.PlatformAnthropic, Mode: service.SchedulerModeSingle}
+	for _, key := range []string{
+		cache.bucketKey(schedulerEpochPrefix, bucket),
+		cache.snapshotKey(bucket, "3"),
+		cache.snapshotKeyPrefix(bucket),
+		cache.slot.key(schedulerBucketSetKey),
+	} {
+		require.True(t, strings.HasPrefix(key, "{sched}"), key)
+	}
+
+	queue := newBatchImageQueueWithOptions(cluster, batchImageQueueOptions{})
+	require.Equal(t, "{batch_image}"+defaultBatchImageReadyKey, queue.readyKey)
+	require.Equal(t, "{batch_image}"+defaultBatchImageInflightPrefix+"b1", queue.inflightKey("b1"))
+	require.Equal(t, defaultBatchImageLockPrefix+"b1", queue.lockKey("b1"))
+}
+
+func TestProvideRedisReadReplica_StandaloneIsNil(t *testing.T) {
+	cfg := &config.Config{Redis: config.RedisConfig{ReadFromReplicas: true}}
+	replica := ProvideRedisReadReplica(cfg)
+	require.Nil(t, replica.Client)
+	require.NoError(t, replica.Close())
+}
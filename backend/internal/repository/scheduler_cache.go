@@ -199,16 +199,19 @@ return 1
 )
 
 type schedulerCache struct {
-	rdb            *redis.Client
+	rdb            redis.UniversalClient
 	mgetChunkSize  int
 	writeChunkSize int
+	// slot cluster 模式下分桶状态（epoch/retired/ready/active/version/快照与 sched:buckets）
+	// 在同一 Lua 脚本中操作，统一加 {sched} hash tag 落到同一 slot
+	slot redisSlotTag
 }
 
-func NewSchedulerCache(rdb *redis.Client) service.SchedulerCache {
+func NewSchedulerCache(rdb redis.UniversalClient) service.SchedulerCache {
 	return newSchedulerCacheWithChunkSizes(rdb, defaultSchedulerSnapshotMGetChunkSize, defaultSchedulerSnapshotWriteChunkSize)
 }
 
-func newSchedulerCacheWithChunkSizes(rdb *redis.Client, mgetChunkSize, writeChunkSize int) service.SchedulerCache {
+func newSchedulerCacheWithChunkSizes(rdb redis.UniversalClient, mgetChunkSize, writeChunkSize int) service.SchedulerCache {
 	if mgetChunkSize <= 0 {
 		mgetChunkSize = defaultSchedulerSnapshotMGetChunkSize
 	}
@@ -219,11 +222,12 @@ func newSchedulerCacheWithChunkSizes(rdb *redis.Client, mgetChunkSize, writeChun
 		rdb:            rdb,
 		mgetChunkSize:  mgetChunkSize,
 		writeChunkSize: writeChunkSize,
+		slot:           newRedisSlotTag(rdb, "sched"),
 	}
 }
 
 func (c *schedulerCache) GetSnapshot(ctx context.Context, bucket service.SchedulerBucket) ([]*service.Account, bool, error) {
-	readyKey := schedulerBucketKey(schedulerReadyPrefix, bucket)
+	readyKey := c.bucketKey(schedulerReadyPrefix, bucket)
 	readyVal, err := c.rdb.Get(ctx, readyKey).Result()
 	if err == redis.Nil {
 		return nil, false, nil
@@ -235,7 +239,7 @@ func (c *schedulerCache) GetSnapshot(ctx context.Context, bucket service.Schedul
 		return nil, false, nil
 	}
 
-	activeKey := schedulerBucketKey(schedulerActivePrefix, bucket)
+	activeKey := c.bucketKey(schedulerActivePrefix, bucket)
 	activeVal, err := c.rdb.Get(ctx, activeKey).Result()
 	if err == redis.Nil {
 		return nil, false, nil
@@ -244,7 +248,7 @@ func (c *schedulerCache) GetSnapshot(ctx context.Context, bucket service.Schedul
 		return nil, false, err
 	}
 
-	snapshotKey := schedulerSnapshotKey(bucket, activeVal)
+	snapshotKey := c.snapshotKey(bucket, activeVal)
 	ids, err := c.rdb.ZRange(ctx, snapshotKey, 0, -1).Result()
 	if err != nil {
 		return nil, false, err
@@ -281,8 +285,8 @@ func (c *schedulerCache) GetSnapshot(ctx context.Context, bucket service.Schedul
 
 func (c *schedulerCache) CaptureBucketWriteToken(ctx context.Context, bucket service.SchedulerBucket) (service.SchedulerBucketWriteToken, error) {
 	result, err := captureBucketWriteTokenScript.Run(ctx, c.rdb, []string{
-		schedulerBucketKey(schedulerEpochPrefix, bucket),
-		schedulerBucketKey(schedulerRetiredPrefix, bucket),
+		c.bucketKey(schedulerEpochPrefix, bucket),
+		c.bucketKey(schedulerRetiredPrefix, bucket),
 	}).Int64()
 	if err != nil {
 		return service.SchedulerBucketWriteToken{}, err
@@ -294,13 +298,13 @@ func (c *schedulerCache) CaptureBucketWriteToken(ctx context.Context, bucket ser
 }
 
 func (c *schedulerCache) RetireBucket(ctx context.Context, bucket service.SchedulerBucket) error {
-	snapshotKeyPrefix := fmt.Sprintf("%s%d:%s:%s:v", schedulerSnapshotPrefix, bucket.GroupID, bucket.Platform, bucket.Mode)
+	snapshotKeyPrefix := c.snapshotKeyPrefix(bucket)
 	result, err := retireBucketScript.Run(ctx, c.rdb, []string{
-		schedulerBucketKey(schedulerEpochPrefix, bucket),
-		schedulerBucketKey(schedulerRetiredPrefix, bucket),
-		schedulerBucketSetKey,
-		schedulerBucketKey(schedulerReadyPrefix, bucket),
-		schedulerBucketKey(schedulerActivePrefix, bucket),
+		c.bucketKey(schedulerEpochPrefix, bucket),
+		c.bucketKey(schedulerRetiredPrefix, bucket),
+		c.slot.key(schedulerBucketSetKey),
+		c.bucketKey(schedulerReadyPrefix, bucket),
+		c.bucketKey(schedulerActivePrefix, bucket),
 	}, bucket.String(), snapshotKeyPrefix, snapshotGraceTTLSeconds).Int64()
 	if err != nil {
 		return err
@@ -312,13 +316,13 @@ func (c *schedulerCache) RetireBucket(ctx context.Context, bucket service.Schedu
 }
 
 func (c *schedulerCache) ReopenBucket(ctx context.Context, bucket service.SchedulerBucket) (service.SchedulerBucketWriteToken, error) {
-	snapshotKeyPrefix := fmt.Sprintf("%s%d:%s:%s:v", schedulerSnapshotPrefix, bucket.GroupID, bucket.Platform, bucket.Mode)
+	snapshotKeyPrefix := c.snapshotKeyPrefix(bucket)
 	result, err := reopenBucketScript.Run(ctx, c.rdb, []string{
-		schedulerBucketKey(schedulerEpochPrefix, bucket),
-		schedulerBucketKey(schedulerRetiredPrefix, bucket),
-		schedulerBucketSetKey,
-		schedulerBucketKey(schedulerReadyPrefix, bucket),
-		schedulerBucketKey(schedulerActivePrefix, bucket),
+		c.bucketKey(schedulerEpochPrefix, bucket),
+		c.bucketKey(schedulerRetiredPrefix, bucket),
+		c.slot.key(schedulerBucketSetKey),
+		c.bucketKey(schedulerReadyPrefix, bucket),
+		c.bucketKey(schedulerActivePrefix, bucket),
 	}, bucket.String(), snapshotKeyPrefix, snapshotGraceTTLSeconds).Int64()
 	if err != nil {
 		return service.SchedulerBucketWriteToken{}, err
@@ -434,9 +438,9 @@ func (c *schedulerCache) SetSnapshotByAccountIDs(ctx context.Context, bucket ser
 
 func (c *schedulerCache) allocateSnapshotVersion(ctx context.Context, bucket service.SchedulerBucket, token service.SchedulerBucketWriteToken) (string, error) {
 	result, err := allocateSnapshotVersionScript.Run(ctx, c.rdb, []string{
-		schedulerBucketKey(schedulerEpochPrefix, bucket),
-		schedulerBucketKey(schedulerRetiredPrefix, bucket),
-		schedulerBucketKey(schedulerVersionPrefix, bucket),
+		c.bucketKey(schedulerEpochPrefix, bucket),
+		c.bucketKey(schedulerRetiredPrefix, bucket),
+		c.bucketKey(schedulerVersionPrefix, bucket),
 	}, token.Epoch).Int64()
 	if err != nil {
 		return "", err
@@ -505,7 +509,7 @@ func (c *schedulerCache) writeSnapshotMembers(ctx context.Context, bucket servic
 	if len(members) == 0 {
 		return nil
 	}
-	snapshotKey := schedulerSnapshotKey(bucket, version)
+	snapshotKey := c.snapshotKey(bucket, version)
 	pipe := c.rdb.Pipeline()
 	for start := 0; start < len(members); start += c.writeChunkSize {
 		end := start + c.writeChunkSize
@@ -519,22 +523,22 @@ func (c *schedulerCache) writeSnapshotMembers(ctx context.Context, bucket servic
 }
 
 func (c *schedulerCache) activateSnapshotVersion(ctx context.Context, bucket service.SchedulerBucket, token service.SchedulerBucketWriteToken, version string) error {
-	snapshotKey := schedulerSnapshotKey(bucket, version)
+	snapshotKey := c.snapshotKey(bucket, version)
 	// Phase 2: 原子 CAS 切换版本，同时再次校验退休状态与 writer epoch。
 	// Lua 脚本保证：仅当新版本 >= 当前激活版本时才切换 active 指针，
 	// 防止并发写入导致版本回滚。
 	// 旧快照使用 EXPIRE 宽限期而非立即 DEL，避免 reader 竞态。
-	activeKey := schedulerBucketKey(schedulerActivePrefix, bucket)
-	readyKey := schedulerBucketKey(schedulerReadyPrefix, bucket)
-	snapshotKeyPrefix := fmt.Sprintf("%s%d:%s:%s:v", schedulerSnapshotPrefix, bucket.GroupID, bucket.Platform, bucket.Mode)
+	activeKey := c.bucketKey(schedulerActivePrefix, bucket)
+	readyKey := c.bucketKey(schedulerReadyPrefix, bucket)
+	snapshotKeyPrefix := c.snapshotKeyPrefix(bucket)
 
 	keys := []string{
 		activeKey,
 		readyKey,
-		schedulerBucketSetKey,
+		c.slot.key(schedulerBucketSetKey),
 		snapshotKey,
-		schedulerBucketKey(schedulerEpochPrefix, bucket),
-		schedulerBucketKey(schedulerRetiredPrefix, bucket),
+		c.bucketKey(schedulerEpochPrefix, bucket),
+		c.bucketKey(schedulerRetiredPrefix, bucket),
 	}
 	args := []any{version, bucket.String(), snapshotKeyPrefix, snapshotGraceTTLSeconds, token.Epoch}
 
@@ -587,7 +591,12 @@ func (c *schedulerCache) DeleteAccount(ctx context.Context, accountID int64) err
 		return nil
 	}
 	id := strconv.FormatInt(accountID, 10)
-	return c.rdb.Del(ctx, schedulerAccountKey(id), schedulerAccountMetaKey(id)).Err()
+	// 两个 key 不在同一 slot，分开删除以兼容 cluster 模式
+	pipe := c.rdb.Pipeline()
+	pipe.Del(ctx, schedulerAccountKey(id))
+	pipe.Del(ctx, schedulerAccountMetaKey(id))
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 func (c *schedulerCache) UpdateLastUsed(ctx context.Context, updates map[int64]time.Time) error {
@@ -623,7 +632,8 @@ func (c *schedulerCache) UpdateLastUsed(ctx context.Context, updates map[int64]t
 				"account_id", ids[i],
 				"error", err,
 			)
-			pipe.Del(ctx, keys[i], schedulerAccountMetaKey(strconv.FormatInt(ids[i], 10)))
+			pipe.Del(ctx, keys[i])
+			pipe.Del(ctx, schedulerAccountMetaKey(strconv.FormatInt(ids[i], 10)))
 			continue
 		}
 		pipe.Set(ctx, keys[i], updated, 0)
@@ -644,7 +654,7 @@ func (c *schedulerCache) UnlockBucket(ctx context.Context, bucket service.Schedu
 }
 
 func (c *schedulerCache) ListBuckets(ctx context.Context) ([]service.SchedulerBucket, error) {
-	raw, err := c.rdb.SMembers(ctx, schedulerBucketSetKey).Result()
+	raw, err := c.rdb.SMembers(ctx, c.slot.key(schedulerBucketSetKey)).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -678,6 +688,19 @@ func (c *schedulerCache) SetOutboxWatermark(ctx context.Context, id int64) error
 	return c.rdb.Set(ctx, schedulerOutboxWatermarkKey, strconv.FormatInt(id, 10), 0).Err()
 }
 
+func (c *schedulerCache) bucketKey(prefix string, bucket service.SchedulerBucket) string {
+	return c.slot.key(schedulerBucketKey(prefix, bucket))
+}
+
+func (c *schedulerCache) snapshotKey(bucket service.SchedulerBucket, version string) string {
+	return c.slot.key(schedulerSnapshotKey(bucket, version))
+}
+
+// snapshotKeyPrefix 快照 key 去掉版本号的前缀，供 Lua 脚本拼接旧版本快照 key
+func (c *schedulerCache) snapshotKeyPrefix(bucket service.SchedulerBucket) string {
+	return c.slot.key(fmt.Sprintf("%s%d:%s:%s:v", schedulerSnapshotPrefix, bucket.GroupID, bucket.Platform, bucket.Mode))
+}
+
 func schedulerBucketKey(prefix string, bucket service.SchedulerBucket) string {
 	return fmt.Sprintf("%s%d:%s:%s", prefix, bucket.GroupID, bucket.Platform, bucket.Mode)
 }
@@ -789,6 +812,8 @@ func (c *schedulerCache) writeAccountPayloads(ctx context.Context, accounts []se
 }
 
 func marshalSchedulerCacheAccount(account service.Account) ([]byte, []byte, error) {
+	// 快照保存账号本身的取值，分组成员覆盖在读取候选时按请求分组重新应用
+	account = *account.WithoutGroupMembership()
 	fullPayload, err := json.Marshal(account)
 	if err != nil {
 		return nil, nil, fmt.Errorf("marshal account: %w", err)
@@ -815,7 +840,7 @@ func (c *schedulerCache) mgetChunked(ctx context.Context, keys []string) ([]any,
 		if end > len(keys) {
 			end = len(keys)
 		}
-		part, err := c.rdb.MGet(ctx, keys[start:end]...).Result()
+		part, err := redisMGet(ctx, c.rdb, keys[start:end]...)
 		if err != nil {
 			return nil, err
 		}
@@ -849,11 +874,25 @@ func buildSchedulerMetadataAccount(account service.Account) service.Account {
 		SessionWindowStatus:     account.SessionWindowStatus,
 		ParentAccountID:         account.ParentAccountID,
 		QuotaDimension:          account.QuotaDimension,
+		CreatedAt:               account.CreatedAt,
 		AccountGroups:           filterSchedulerAccountGroups(account.AccountGroups),
 		GroupIDs:                filterSchedulerGroupIDs(account.GroupIDs, account.AccountGroups),
 		Credentials:             filterSchedulerCredentials(account.Credentials),
-		Extra:                   filterSchedulerExtra(account.Extra),
+		Extra:                   withSchedulerDataResidency(filterSchedulerExtra(account.Extra), account.DataResidency()),
+	}
+}
+
+// withSchedulerDataResidency 写入账号实际可满足的驻留区域。元数据不含代理，
+// 因此这里保存已结合代理区域标签计算后的结果，而非账号原始标签。
+func withSchedulerDataResidency(extra map[string]any, region string) map[string]any {
+	if region == "" {
+		return extra
+	}
+	if extra == nil {
+		extra = make(map[string]any, 1)
 	}
+	extra[service.AccountDataResidencyExtraKey] = region
+	return extra
 }
 
 func filterSchedulerAccountGroups(accountGroups []service.AccountGroup) []service.AccountGroup {
@@ -867,10 +906,13 @@ func filterSchedulerAccountGroups(accountGroups []service.AccountGroup) []servic
 			continue
 		}
 		filtered = append(filtered, service.AccountGroup{
-			AccountID: ag.AccountID,
-			GroupID:   ag.GroupID,
-			Priority:  ag.Priority,
-			CreatedAt: ag.CreatedAt,
+			AccountID:        ag.AccountID,
+			GroupID:          ag.GroupID,
+			Priority:         ag.Priority,
+			PriorityOverride: ag.PriorityOverride,
+			Weight:           ag.Weight,
+			ConcurrencyCap:   ag.ConcurrencyCap,
+			CreatedAt:        ag.CreatedAt,
 		})
 	}
 	if len(filtered) == 0 {
@@ -963,6 +1005,8 @@ func filterSchedulerExtra(extra map[string]any) map[string]any {
 		service.UpstreamBillingProbeExtraKey,
 		service.GrokMediaEligibleExtraKey,
 		"grok_billing_snapshot",
+		service.AccountWarmupStartedAtExtraKey,
+		service.AccountWarmupDisabledExtraKey,
 	}
 	filtered := make(map[string]any)
 	for _, key := range keys {
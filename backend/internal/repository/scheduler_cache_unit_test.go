@@ -321,6 +321,7 @@ func TestBuildSchedulerMetadataAccount_KeepsGrokMediaEligibility(t *testing.T) {
 }
 
 func TestBuildSchedulerMetadataAccount_KeepsSlimGroupMembership(t *testing.T) {
+	overridePriority, overrideConcurrency := 1, 2
 	account := service.Account{
 		ID:       42,
 		Platform: service.PlatformAnthropic,
@@ -334,10 +335,13 @@ func TestBuildSchedulerMetadataAccount_KeepsSlimGroupMembership(t *testing.T) {
 				Group:     &service.Group{ID: 7, Name: "drop-from-metadata"},
 			},
 			{
-				AccountID: 42,
-				GroupID:   11,
-				Priority:  3,
-				Group:     &service.Group{ID: 11, Name: "drop-from-metadata"},
+				AccountID:        42,
+				GroupID:          11,
+				Priority:         3,
+				PriorityOverride: &overridePriority,
+				Weight:           250,
+				ConcurrencyCap:   &overrideConcurrency,
+				Group:            &service.Group{ID: 11, Name: "drop-from-metadata"},
 			},
 			{
 				AccountID: 42,
@@ -357,6 +361,9 @@ func TestBuildSchedulerMetadataAccount_KeepsSlimGroupMembership(t *testing.T) {
 	require.Nil(t, got.AccountGroups[0].Account)
 	require.Nil(t, got.AccountGroups[0].Group)
 	require.Equal(t, int64(11), got.AccountGroups[1].GroupID)
+	require.Equal(t, &overridePriority, got.AccountGroups[1].PriorityOverride)
+	require.Equal(t, 250, got.AccountGroups[1].Weight)
+	require.Equal(t, &overrideConcurrency, got.AccountGroups[1].ConcurrencyCap)
 	require.Nil(t, got.Groups)
 }
 
@@ -419,6 +426,25 @@ func TestBuildSchedulerMetadataAccount_KeepsModelRateLimits(t *testing.T) {
 	require.Nil(t, got.Extra["unused_large_field"])
 }
 
+func TestBuildSchedulerMetadataAccount_KeepsEffectiveDataResidency(t *testing.T) {
+	tagged := func(proxyRegion string) service.Account {
+		return service.Account{
+			ID:    91,
+			Extra: map[string]any{service.AccountDataResidencyExtraKey: service.DataResidencyEU},
+			Proxy: &service.Proxy{ID: 5, DataResidency: proxyRegion},
+		}
+	}
+
+	got := buildSchedulerMetadataAccount(tagged(service.DataResidencyEU))
+	require.Nil(t, got.Proxy)
+	require.Equal(t, service.DataResidencyEU, got.DataResidency())
+
+	// 代理出口不在账号所标区域时，元数据中不保留区域标签
+	got = buildSchedulerMetadataAccount(tagged(service.DataResidencyUS))
+	require.Empty(t, got.DataResidency())
+	require.Nil(t, got.Extra)
+}
+
 func TestBuildSchedulerMetadataAccount_KeepsSparkShadowRoutingIdentity(t *testing.T) {
 	parentID := int64(100)
 	account := service.Account{
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// settingChangeRequestRepository 设置变更审批队列仓储（raw SQL）。
+type settingChangeRequestRepository struct {
+	db *sql.DB
+}
+
+// NewSettingChangeRequestRepository 创建设置变更审批队列仓储。
+func NewSettingChangeRequestRepository(db *sql.DB) service.SettingChangeRequestRepository {
+	return &settingChangeRequestRepository{db: db}
+}
+
+const settingChangeRequestSelectColumns = `
+  id, kind, status, categories, changed_keys, payload, requested_by,
+  reviewed_by, review_note, created_at, reviewed_at`
+
+func (r *settingChangeRequestRepository) Create(ctx context.Context, req *service.SettingChangeRequest) (*service.SettingChangeRequest, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil setting change request repository")
+	}
+	payload := []byte(req.Payload)
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+	row := r.db.QueryRowContext(ctx, `
+INSERT INTO setting_change_requests (kind, status, categories, changed_keys, payload, requested_by)
+VALUES ($1, $2, $3, $4, $5::jsonb, $6)
+RETURNING`+settingChangeRequestSelectColumns,
+		req.Kind, req.Status, pq.Array(req.Categories), pq.Array(req.ChangedKeys), string(payload), req.RequestedBy)
+	return scanSettingChangeRequest(row)
+}
+
+func (r *settingChangeRequestRepository) GetByID(ctx context.Context, id int64) (*service.SettingChangeRequest, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil setting change request repository")
+	}
+	row := r.db.QueryRowContext(ctx, "SELECT"+settingChangeRequestSelectColumns+`
+FROM setting_change_requests WHERE id = $1`, id)
+	item, err := scanSettingChangeRequest(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrSettingChangeRequestNotFound
+	}
+	return item, err
+}
+
+func (r *settingChangeRequestRepository) List(ctx context.Context, status string, page, pageSize int) (*service.SettingChangeRequestList, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil setting change request repository")
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	where := ""
+	args := []any{}
+	if status != "" {
+		where = "WHERE status = $1"
+		args = append(args, status)
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM setting_change_requests "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	rows, err := r.db.QueryContext(ctx, "SELECT"+settingChangeRequestSelectColumns+"\nFROM setting_change_requests "+where+`
+ORDER BY created_at DESC, id DESC
+LIMIT $`+itoa(len(args)-1)+` OFFSET $`+itoa(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.SettingChangeRequest, 0)
+	for rows.Next() {
+		item, err := scanSettingChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &service.SettingChangeRequestList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func (r *settingChangeRequestRepository) Review(ctx context.Context, id int64, status string, reviewerID int64, note string) (*service.SettingChangeRequest, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil setting change request repository")
+	}
+	// 条件更新保证同一申请只能被审批一次（并发批准时仅一方成功）
+	row := r.db.QueryRowContext(ctx, `
+UPDATE setting_change_requests
+SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+WHERE id = $1 AND status = 'pending'
+RETURNING`+settingChangeRequestSelectColumns, id, status, reviewerID, truncateString(note, 1000))
+	item, err := scanSettingChangeRequest(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := r.GetByID(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, service.ErrSettingChangeRequestNotPending
+	}
+	return item, err
+}
+
+func scanSettingChangeRequest(row rowScanner) (*service.SettingChangeRequest, error) {
+	item := &service.SettingChangeRequest{}
+	var (
+		categories  []string
+		changedKeys []string
+		payload     []byte
+		reviewedBy  sql.NullInt64
+		reviewedAt  sql.NullTime
+	)
+	if err := row.Scan(
+		&item.ID,
+		&item.Kind,
+		&item.Status,
+		pq.Array(&categories),
+		pq.Array(&changedKeys),
+		&payload,
+		&item.RequestedBy,
+		&reviewedBy,
+		&item.ReviewNote,
+		&item.CreatedAt,
+		&reviewedAt,
+	); err != nil {
+		return nil, err
+	}
+	item.Categories = categories
+	item.ChangedKeys = changedKeys
+	item.Payload = payload
+	if reviewedBy.Valid {
+		v := reviewedBy.Int64
+		item.ReviewedBy = &v
+	}
+	if reviewedAt.Valid {
+		t := reviewedAt.Time
+		item.ReviewedAt = &t
+	}
+	return item, nil
+}
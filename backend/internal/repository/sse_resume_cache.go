@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// 格式: sse_resume:{streamID}:meta / sse_resume:{streamID}:events
+// 同一流的两个 key 在同一 MULTI 中更新，使用 hash tag 确保 Redis Cluster 下落入同一 slot
+const sseResumeKeyPrefix = "sse_resume:"
+
+func sseResumeMetaKey(streamID string) string {
+	return sseResumeKeyPrefix + "{" + streamID + "}:meta"
+}
+
+func sseResumeEventsKey(streamID string) string {
+	return sseResumeKeyPrefix + "{" + streamID + "}:events"
+}
+
+type sseResumeCache struct {
+	rdb redis.UniversalClient
+}
+
+func NewSSEResumeCache(rdb redis.UniversalClient) service.SSEResumeCache {
+	return &sseResumeCache{rdb: rdb}
+}
+
+func (c *sseResumeCache) InitStream(ctx context.Context, streamID string, ownerAPIKeyID int64, ttl time.Duration) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.HSet(ctx, sseResumeMetaKey(streamID), "owner", ownerAPIKeyID, "done", 0)
+	pipe.Expire(ctx, sseResumeMetaKey(streamID), ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *sseResumeCache) AppendEvents(ctx context.Context, streamID string, events []service.SSEResumeEvent, maxEvents int, ttl time.Duration) error {
+	if len(events) == 0 {
+		return nil
+	}
+	values := make([]any, 0, len(events))
+	for _, ev := range events {
+		values = append(values, encodeSSEResumeEvent(ev))
+	}
+	eventsKey := sseResumeEventsKey(streamID)
+	pipe := c.rdb.TxPipeline()
+	pipe.RPush(ctx, eventsKey, values...)
+	if maxEvents > 0 {
+		pipe.LTrim(ctx, eventsKey, int64(-maxEvents), -1)
+	}
+	pipe.Expire(ctx, eventsKey, ttl)
+	pipe.Expire(ctx, sseResumeMetaKey(streamID), ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *sseResumeCache) FinishStream(ctx context.Context, streamID string, ttl time.Duration) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.HSet(ctx, sseResumeMetaKey(streamID), "done", 1)
+	pipe.Expire(ctx, sseResumeMetaKey(streamID), ttl)
+	pipe.Expire(ctx, sseResumeEventsKey(streamID), ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *sseResumeCache) DeleteStream(ctx context.Context, streamID string) error {
+	return c.rdb.Del(ctx, sseResumeMetaKey(streamID), sseResumeEventsKey(streamID)).Err()
+}
+
+func (c *sseResumeCache) LoadStream(ctx context.Context, streamID string) (*service.SSEResumeSnapshot, error) {
+	pipe := c.rdb.Pipeline()
+	metaCmd := pipe.HGetAll(ctx, sseResumeMetaKey(streamID))
+	eventsCmd := pipe.LRange(ctx, sseResumeEventsKey(streamID), 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	meta := metaCmd.Val()
+	if len(meta) == 0 {
+		return nil, nil
+	}
+	owner, _ := strconv.ParseInt(meta["owner"], 10, 64)
+	snap := &service.SSEResumeSnapshot{
+		OwnerAPIKeyID: owner,
+		Done:          meta["done"] == "1",
+	}
+	for _, raw := range eventsCmd.Val() {
+		if ev, ok := decodeSSEResumeEvent(raw); ok {
+			snap.Events = append(snap.Events, ev)
+		}
+	}
+	return snap, nil
+}
+
+// encodeSSEResumeEvent 编码为 "<seq>\n<event>"
+func encodeSSEResumeEvent(ev service.SSEResumeEvent) []byte {
+	out := make([]byte, 0, len(ev.Data)+21)
+	out = strconv.AppendInt(out, ev.Seq, 10)
+	out = append(out, '\n')
+	return append(out, ev.Data...)
+}
+
+func decodeSSEResumeEvent(raw string) (service.SSEResumeEvent, bool) {
+	data := []byte(raw)
+	idx := bytes.IndexByte(data, '\n')
+	if idx <= 0 {
+		return service.SSEResumeEvent{}, false
+	}
+	seq, err := strconv.ParseInt(string(data[:idx]), 10, 64)
+	if err != nil {
+		return service.SSEResumeEvent{}, false
+	}
+	return service.SSEResumeEvent{Seq: seq, Data: data[idx+1:]}, true
+}
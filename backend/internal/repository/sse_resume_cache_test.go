@@ -0,0 +1,52 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEResumeCache_AppendTrimFinishLoad(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewSSEResumeCache(rdb)
+
+	snap, err := cache.LoadStream(ctx, "missing")
+	require.NoError(t, err)
+	require.Nil(t, snap)
+
+	require.NoError(t, cache.InitStream(ctx, "s1", 7, time.Minute))
+	require.NoError(t, cache.AppendEvents(ctx, "s1", []service.SSEResumeEvent{
+		{Seq: 1, Data: []byte("id: s1-1\ndata: a\n\n")},
+		{Seq: 2, Data: []byte("id: s1-2\ndata: b\n\n")},
+		{Seq: 3, Data: []byte("id: s1-3\ndata: c\n\n")},
+	}, 2, time.Minute))
+
+	snap, err = cache.LoadStream(ctx, "s1")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), snap.OwnerAPIKeyID)
+	require.False(t, snap.Done)
+	require.Len(t, snap.Events, 2, "buffer must keep only the most recent events")
+	require.Equal(t, int64(2), snap.Events[0].Seq)
+	require.Equal(t, "id: s1-3\ndata: c\n\n", string(snap.Events[1].Data))
+
+	require.NoError(t, cache.FinishStream(ctx, "s1", time.Minute))
+	snap, err = cache.LoadStream(ctx, "s1")
+	require.NoError(t, err)
+	require.True(t, snap.Done)
+	require.True(t, mr.TTL(sseResumeEventsKey("s1")) > 0)
+
+	require.NoError(t, cache.DeleteStream(ctx, "s1"))
+	snap, err = cache.LoadStream(ctx, "s1")
+	require.NoError(t, err)
+	require.Nil(t, snap)
+}
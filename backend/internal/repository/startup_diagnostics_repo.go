@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/Wei-Shaw/sub2api/migrations"
+	"github.com/redis/go-redis/v9"
+)
+
+type startupDiagnosticsRepository struct {
+	db           *sql.DB
+	rdb          redis.UniversalClient
+	migrationsFS fs.FS
+}
+
+func NewStartupDiagnosticsRepository(db *sql.DB, rdb redis.UniversalClient) service.StartupDiagnosticsRepository {
+	return &startupDiagnosticsRepository{db: db, rdb: rdb, migrationsFS: migrations.FS}
+}
+
+func (r *startupDiagnosticsRepository) PingDatabase(ctx context.Context) error {
+	if r.db == nil {
+		return errors.New("nil sql db")
+	}
+	var one int
+	return r.db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+func (r *startupDiagnosticsRepository) PingRedis(ctx context.Context) error {
+	if r.rdb == nil {
+		return errors.New("nil redis client")
+	}
+	return r.rdb.Ping(ctx).Err()
+}
+
+// SchemaStatus 对比内嵌迁移文件与 schema_migrations 记录。
+// 与迁移执行器保持一致：空文件不会被记录，因此不计入期望集合。
+func (r *startupDiagnosticsRepository) SchemaStatus(ctx context.Context) (*service.SchemaMigrationStatus, error) {
+	expected, err := expectedMigrationFiles(r.migrationsFS)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT filename FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("query schema_migrations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	applied := make(map[string]struct{})
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return diffMigrationSets(expected, applied), nil
+}
+
+func expectedMigrationFiles(fsys fs.FS) ([]string, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+	out := make([]string, 0, len(files))
+	for _, name := range files {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			continue
+		}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func diffMigrationSets(expected []string, applied map[string]struct{}) *service.SchemaMigrationStatus {
+	st := &service.SchemaMigrationStatus{Expected: len(expected), Applied: len(applied)}
+	expectedSet := make(map[string]struct{}, len(expected))
+	for _, name := range expected {
+		expectedSet[name] = struct{}{}
+		if _, ok := applied[name]; !ok {
+			st.Pending = append(st.Pending, name)
+		}
+	}
+	if len(expected) > 0 {
+		st.LatestExpected = expected[len(expected)-1]
+	}
+	appliedNames := make([]string, 0, len(applied))
+	for name := range applied {
+		appliedNames = append(appliedNames, name)
+		if _, ok := expectedSet[name]; !ok {
+			st.Unknown = append(st.Unknown, name)
+		}
+	}
+	sort.Strings(appliedNames)
+	sort.Strings(st.Unknown)
+	if len(appliedNames) > 0 {
+		st.LatestApplied = appliedNames[len(appliedNames)-1]
+	}
+	return st
+}
+
+func (r *startupDiagnosticsRepository) ListGroupsWithoutEnabledAccounts(ctx context.Context) ([]service.DiagnosticsGroupRef, error) {
+	// 只看账号的长期可用状态；限流、过载等临时状态不视为"未启用"。
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT g.id, g.name, g.platform
+		FROM groups g
+		WHERE g.deleted_at IS NULL
+			AND g.status = 'active'
+			AND NOT EXISTS (
+				SELECT 1
+				FROM account_groups ag
+				JOIN accounts a ON a.id = ag.account_id
+				WHERE ag.group_id = g.id
+					AND a.deleted_at IS NULL
+					AND a.status = 'active'
+					AND a.schedulable = true
+					AND (a.expires_at IS NULL OR a.expires_at > NOW() OR a.auto_pause_on_expired = FALSE)
+			)
+		ORDER BY g.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []service.DiagnosticsGroupRef
+	for rows.Next() {
+		var g service.DiagnosticsGroupRef
+		if err := rows.Scan(&g.ID, &g.Name, &g.Platform); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
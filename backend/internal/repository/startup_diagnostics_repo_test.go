@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedMigrationFiles_SkipsEmptyAndNonSQL(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_b.sql": {Data: []byte("SELECT 2;")},
+		"001_a.sql": {Data: []byte("SELECT 1;")},
+		"003_c.sql": {Data: []byte("  \n")},
+		"README.md": {Data: []byte("docs")},
+	}
+	files, err := expectedMigrationFiles(fsys)
+	require.NoError(t, err)
+	require.Equal(t, []string{"001_a.sql", "002_b.sql"}, files)
+}
+
+func TestDiffMigrationSets(t *testing.T) {
+	st := diffMigrationSets(
+		[]string{"001_a.sql", "002_b.sql", "003_c.sql"},
+		map[string]struct{}{"001_a.sql": {}, "002_b.sql": {}, "999_future.sql": {}},
+	)
+	require.Equal(t, 3, st.Expected)
+	require.Equal(t, 3, st.Applied)
+	require.Equal(t, "003_c.sql", st.LatestExpected)
+	require.Equal(t, "999_future.sql", st.LatestApplied)
+	require.Equal(t, []string{"003_c.sql"}, st.Pending)
+	require.Equal(t, []string{"999_future.sql"}, st.Unknown)
+}
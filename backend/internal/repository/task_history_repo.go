@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type taskHistoryRepository struct {
+	db *sql.DB
+}
+
+func NewTaskHistoryRepository(db *sql.DB) service.TaskHistoryRepository {
+	return &taskHistoryRepository{db: db}
+}
+
+// ListBatchImageJobsForUser 按用户跨 API Key 分页查询批量生图任务。
+// 依赖 idx_batch_image_jobs_user_history / idx_batch_image_jobs_user_model_history 部分索引。
+func (r *taskHistoryRepository) ListBatchImageJobsForUser(ctx context.Context, filter *service.TaskHistoryFilter) ([]*service.BatchImageJob, int, error) {
+	if r == nil || r.db == nil {
+		return nil, 0, fmt.Errorf("nil task history repository")
+	}
+	if filter == nil || filter.UserID <= 0 {
+		return nil, 0, fmt.Errorf("task history list requires user_id")
+	}
+
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	where, args := buildTaskHistoryWhere(filter)
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM batch_image_jobs "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*service.BatchImageJob{}, 0, nil
+	}
+
+	offset := (page - 1) * pageSize
+	argsWithLimit := append(args, pageSize, offset)
+	query := batchImageJobSelectSQL + " " + where + `
+ORDER BY created_at DESC, id DESC
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, argsWithLimit...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+	jobs, err := scanBatchImageJobs(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+func buildTaskHistoryWhere(filter *service.TaskHistoryFilter) (string, []any) {
+	conds := []string{"user_id = $1", "user_deleted_at IS NULL"}
+	args := []any{filter.UserID}
+	if filter.APIKeyID != nil {
+		args = append(args, *filter.APIKeyID)
+		conds = append(conds, "api_key_id = $"+itoa(len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			args = append(args, status)
+			placeholders = append(placeholders, "$"+itoa(len(args)))
+		}
+		conds = append(conds, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.Model != "" {
+		args = append(args, filter.Model)
+		conds = append(conds, "model = $"+itoa(len(args)))
+	}
+	if filter.StartTime != nil {
+		args = append(args, *filter.StartTime)
+		conds = append(conds, "created_at >= $"+itoa(len(args)))
+	}
+	if filter.EndTime != nil {
+		args = append(args, *filter.EndTime)
+		conds = append(conds, "created_at < $"+itoa(len(args)))
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTaskHistoryWhere_DefaultsToUserAndExcludesDeleted(t *testing.T) {
+	where, args := buildTaskHistoryWhere(&service.TaskHistoryFilter{UserID: 9})
+
+	require.Equal(t, "WHERE user_id = $1 AND user_deleted_at IS NULL", where)
+	require.Equal(t, []any{int64(9)}, args)
+}
+
+func TestBuildTaskHistoryWhere_AllFilters(t *testing.T) {
+	kid := int64(3)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	where, args := buildTaskHistoryWhere(&service.TaskHistoryFilter{
+		UserID:    9,
+		APIKeyID:  &kid,
+		Statuses:  []string{service.BatchImageJobStatusCreated, service.BatchImageJobStatusSubmitted},
+		Model:     "gemini-2.5-flash-image",
+		StartTime: &start,
+		EndTime:   &end,
+	})
+
+	require.Equal(t, "WHERE user_id = $1 AND user_deleted_at IS NULL AND api_key_id = $2 AND status IN ($3, $4) AND model = $5 AND created_at >= $6 AND created_at < $7", where)
+	require.Equal(t, []any{int64(9), kid, "created", "submitted", "gemini-2.5-flash-image", start, end}, args)
+}
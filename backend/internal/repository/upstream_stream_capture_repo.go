@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// upstreamStreamCaptureRepository 上游原始响应留存索引仓储（raw SQL）。
+// 内容本体在对象存储，本表只保存定位信息。
+type upstreamStreamCaptureRepository struct {
+	db *sql.DB
+}
+
+// NewUpstreamStreamCaptureRepository 创建留存索引仓储。
+func NewUpstreamStreamCaptureRepository(db *sql.DB) service.UpstreamStreamCaptureRepository {
+	return &upstreamStreamCaptureRepository{db: db}
+}
+
+const upstreamStreamCaptureSelectColumns = `
+  c.id, c.created_at, c.request_id, c.user_id, c.api_key_id, c.account_id, c.platform,
+  c.endpoint, c.status_code, c.reason, c.attempts, c.object_key, c.size_bytes, c.truncated`
+
+func (r *upstreamStreamCaptureRepository) Insert(ctx context.Context, record *service.UpstreamStreamCaptureRecord) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil upstream stream capture repository")
+	}
+	createdAt := record.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	return r.db.QueryRowContext(ctx, `
+INSERT INTO upstream_stream_captures
+  (created_at, request_id, user_id, api_key_id, account_id, platform, endpoint, status_code, reason, attempts, object_key, size_bytes, truncated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING id`,
+		createdAt.UTC(),
+		truncateString(record.RequestID, 255),
+		record.UserID,
+		record.APIKeyID,
+		nullInt64Ptr(record.AccountID),
+		truncateString(record.Platform, 32),
+		truncateString(record.Endpoint, 128),
+		record.StatusCode,
+		truncateString(record.Reason, 16),
+		record.Attempts,
+		record.ObjectKey,
+		record.SizeBytes,
+		record.Truncated,
+	).Scan(&record.ID)
+}
+
+func (r *upstreamStreamCaptureRepository) GetByID(ctx context.Context, id int64) (*service.UpstreamStreamCaptureRecord, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil upstream stream capture repository")
+	}
+	row := r.db.QueryRowContext(ctx, "SELECT"+upstreamStreamCaptureSelectColumns+"\nFROM upstream_stream_captures c WHERE c.id = $1", id)
+	return scanUpstreamStreamCaptureSingle(row)
+}
+
+func (r *upstreamStreamCaptureRepository) GetLatestByRequestID(ctx context.Context, requestID string) (*service.UpstreamStreamCaptureRecord, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil upstream stream capture repository")
+	}
+	row := r.db.QueryRowContext(ctx, "SELECT"+upstreamStreamCaptureSelectColumns+`
+FROM upstream_stream_captures c WHERE c.request_id = $1
+ORDER BY c.created_at DESC, c.id DESC LIMIT 1`, requestID)
+	return scanUpstreamStreamCaptureSingle(row)
+}
+
+func buildUpstreamStreamCapturesWhere(filter *service.UpstreamStreamCaptureFilter) (string, []any) {
+	var clauses []string
+	var args []any
+	if filter.APIKeyID != nil && *filter.APIKeyID > 0 {
+		args = append(args, *filter.APIKeyID)
+		clauses = append(clauses, "c.api_key_id = $"+itoa(len(args)))
+	}
+	if requestID := strings.TrimSpace(filter.RequestID); requestID != "" {
+		args = append(args, requestID)
+		clauses = append(clauses, "c.request_id = $"+itoa(len(args)))
+	}
+	if reason := strings.TrimSpace(filter.Reason); reason != "" {
+		args = append(args, reason)
+		clauses = append(clauses, "c.reason = $"+itoa(len(args)))
+	}
+	if len(clauses) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func (r *upstreamStreamCaptureRepository) List(ctx context.Context, filter *service.UpstreamStreamCaptureFilter) (*service.UpstreamStreamCaptureList, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil upstream stream capture repository")
+	}
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	where, args := buildUpstreamStreamCapturesWhere(filter)
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM upstream_stream_captures c "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	argsWithLimit := append(args, pageSize, offset)
+	query := "SELECT" + upstreamStreamCaptureSelectColumns + "\nFROM upstream_stream_captures c\n" + where + `
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+
+	items, err := r.queryRecords(ctx, query, argsWithLimit...)
+	if err != nil {
+		return nil, err
+	}
+	return &service.UpstreamStreamCaptureList{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (r *upstreamStreamCaptureRepository) ListBefore(ctx context.Context, cutoff time.Time, limit int) ([]*service.UpstreamStreamCaptureRecord, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil upstream stream capture repository")
+	}
+	if limit <= 0 {
+		limit = 500
+	}
+	return r.queryRecords(ctx, "SELECT"+upstreamStreamCaptureSelectColumns+`
+FROM upstream_stream_captures c WHERE c.created_at < $1 ORDER BY c.id LIMIT $2`, cutoff.UTC(), limit)
+}
+
+func (r *upstreamStreamCaptureRepository) DeleteByIDs(ctx context.Context, ids []int64) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil upstream stream capture repository")
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res, err := r.db.ExecContext(ctx, "DELETE FROM upstream_stream_captures WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *upstreamStreamCaptureRepository) queryRecords(ctx context.Context, query string, args ...any) ([]*service.UpstreamStreamCaptureRecord, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*service.UpstreamStreamCaptureRecord
+	for rows.Next() {
+		item, err := scanUpstreamStreamCaptureRow(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func scanUpstreamStreamCaptureSingle(row *sql.Row) (*service.UpstreamStreamCaptureRecord, error) {
+	item, err := scanUpstreamStreamCaptureRow(row.Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrUpstreamStreamCaptureNotFound
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func scanUpstreamStreamCaptureRow(scan func(dest ...any) error) (*service.UpstreamStreamCaptureRecord, error) {
+	item := &service.UpstreamStreamCaptureRecord{}
+	var accountID sql.NullInt64
+	if err := scan(
+		&item.ID,
+		&item.CreatedAt,
+		&item.RequestID,
+		&item.UserID,
+		&item.APIKeyID,
+		&accountID,
+		&item.Platform,
+		&item.Endpoint,
+		&item.StatusCode,
+		&item.Reason,
+		&item.Attempts,
+		&item.ObjectKey,
+		&item.SizeBytes,
+		&item.Truncated,
+	); err != nil {
+		return nil, err
+	}
+	if accountID.Valid {
+		v := accountID.Int64
+		item.AccountID = &v
+	}
+	return item, nil
+}
@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// usageArchiveRepository 使用记录归档索引与审计恢复区仓储（raw SQL）。
+// 归档内容本体在对象存储，usage_log_archives 只保存定位信息与对账汇总。
+type usageArchiveRepository struct {
+	db *sql.DB
+}
+
+// NewUsageArchiveRepository 创建使用记录归档仓储。
+func NewUsageArchiveRepository(db *sql.DB) service.UsageLogArchiveRepository {
+	return &usageArchiveRepository{db: db}
+}
+
+const usageArchiveSelectColumns = `
+  a.id, a.created_at, a.month, a.object_key, a.row_count, a.size_bytes, a.first_log_id, a.last_log_id,
+  a.range_start, a.range_end, a.input_tokens, a.output_tokens, a.total_cost, a.actual_cost, a.restored_at`
+
+func (r *usageArchiveRepository) OldestUsageLogBefore(ctx context.Context, cutoff time.Time) (time.Time, bool, error) {
+	var oldest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, `SELECT MIN(created_at) FROM usage_logs WHERE created_at < $1`, cutoff.UTC()).Scan(&oldest); err != nil {
+		return time.Time{}, false, err
+	}
+	if !oldest.Valid {
+		return time.Time{}, false, nil
+	}
+	return oldest.Time.UTC(), true, nil
+}
+
+func (r *usageArchiveRepository) ListUsageLogsForArchive(ctx context.Context, start, end time.Time, limit int) ([]service.UsageLogArchiveRow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT u.id, u.created_at, to_jsonb(u)::text
+FROM usage_logs u
+WHERE u.created_at >= $1 AND u.created_at < $2
+ORDER BY u.created_at, u.id
+LIMIT $3`, start.UTC(), end.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []service.UsageLogArchiveRow
+	for rows.Next() {
+		var row service.UsageLogArchiveRow
+		var data string
+		if err := rows.Scan(&row.ID, &row.CreatedAt, &data); err != nil {
+			return nil, err
+		}
+		row.Data = json.RawMessage(data)
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (r *usageArchiveRepository) CommitArchive(ctx context.Context, archive *service.UsageLogArchive, logIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.QueryRowContext(ctx, `
+INSERT INTO usage_log_archives
+  (month, object_key, row_count, size_bytes, first_log_id, last_log_id, range_start, range_end,
+   input_tokens, output_tokens, total_cost, actual_cost)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, created_at`,
+		archive.Month.UTC(),
+		archive.ObjectKey,
+		archive.RowCount,
+		archive.SizeBytes,
+		archive.FirstLogID,
+		archive.LastLogID,
+		archive.RangeStart.UTC(),
+		archive.RangeEnd.UTC(),
+		archive.InputTokens,
+		archive.OutputTokens,
+		archive.TotalCost,
+		archive.ActualCost,
+	).Scan(&archive.ID, &archive.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM usage_logs WHERE id = ANY($1)`, pq.Array(logIDs)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *usageArchiveRepository) GetByID(ctx context.Context, id int64) (*service.UsageLogArchive, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT"+usageArchiveSelectColumns+"\nFROM usage_log_archives a WHERE a.id = $1", id)
+	item, err := scanUsageArchiveRow(row.Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrUsageLogArchiveNotFound
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *usageArchiveRepository) List(ctx context.Context, filter *service.UsageLogArchiveFilter) (*service.UsageLogArchiveList, error) {
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	where := ""
+	var args []any
+	if !filter.Month.IsZero() {
+		args = append(args, filter.Month.UTC().Format("2006-01")+"-01")
+		where = "WHERE a.month = $1::date"
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM usage_log_archives a "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	argsWithLimit := append(args, pageSize, offset)
+	query := "SELECT" + usageArchiveSelectColumns + "\nFROM usage_log_archives a\n" + where + `
+ORDER BY a.month DESC, a.id DESC
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+	items, err := r.queryArchives(ctx, query, argsWithLimit...)
+	if err != nil {
+		return nil, err
+	}
+	return &service.UsageLogArchiveList{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (r *usageArchiveRepository) ListEndedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*service.UsageLogArchive, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return r.queryArchives(ctx, "SELECT"+usageArchiveSelectColumns+`
+FROM usage_log_archives a WHERE a.range_end < $1 ORDER BY a.id LIMIT $2`, cutoff.UTC(), limit)
+}
+
+func (r *usageArchiveRepository) DeleteByIDs(ctx context.Context, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res, err := r.db.ExecContext(ctx, "DELETE FROM usage_log_archives WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *usageArchiveRepository) InsertRestoredRows(ctx context.Context, archiveID int64, rows []json.RawMessage) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	payload, err := json.Marshal(rows)
+	if err != nil {
+		return 0, fmt.Errorf("marshal restored rows: %w", err)
+	}
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO usage_log_archive_rows (archive_id, usage_log_id, created_at, data)
+SELECT $1, (d->>'id')::bigint, (d->>'created_at')::timestamptz, d
+FROM jsonb_array_elements($2::jsonb) AS d
+ON CONFLICT (archive_id, usage_log_id) DO NOTHING`, archiveID, string(payload))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *usageArchiveRepository) ListRestoredRows(ctx context.Context, archiveID int64, page, pageSize int) ([]json.RawMessage, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM usage_log_archive_rows WHERE archive_id = $1`, archiveID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.QueryContext(ctx, `
+SELECT data::text FROM usage_log_archive_rows
+WHERE archive_id = $1
+ORDER BY created_at, usage_log_id
+LIMIT $2 OFFSET $3`, archiveID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]json.RawMessage, 0, pageSize)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, json.RawMessage(data))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *usageArchiveRepository) DeleteRestoredRows(ctx context.Context, archiveID int64) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM usage_log_archive_rows WHERE archive_id = $1`, archiveID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *usageArchiveRepository) SetRestoredAt(ctx context.Context, archiveID int64, restoredAt *time.Time) error {
+	var value any
+	if restoredAt != nil {
+		value = restoredAt.UTC()
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE usage_log_archives SET restored_at = $2 WHERE id = $1`, archiveID, value)
+	return err
+}
+
+func (r *usageArchiveRepository) queryArchives(ctx context.Context, query string, args ...any) ([]*service.UsageLogArchive, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*service.UsageLogArchive
+	for rows.Next() {
+		item, err := scanUsageArchiveRow(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func scanUsageArchiveRow(scan func(dest ...any) error) (*service.UsageLogArchive, error) {
+	item := &service.UsageLogArchive{}
+	var restoredAt sql.NullTime
+	if err := scan(
+		&item.ID,
+		&item.CreatedAt,
+		&item.Month,
+		&item.ObjectKey,
+		&item.RowCount,
+		&item.SizeBytes,
+		&item.FirstLogID,
+		&item.LastLogID,
+		&item.RangeStart,
+		&item.RangeEnd,
+		&item.InputTokens,
+		&item.OutputTokens,
+		&item.TotalCost,
+		&item.ActualCost,
+		&restoredAt,
+	); err != nil {
+		return nil, err
+	}
+	if restoredAt.Valid {
+		v := restoredAt.Time
+		item.RestoredAt = &v
+	}
+	return item, nil
+}
@@ -195,6 +195,14 @@ func (r *usageBillingRepository) applyUsageBillingEffects(ctx context.Context, t
 		result.APIKeyQuotaExhausted = exhausted
 	}
 
+	if cmd.ParentAPIKeyQuotaCost > 0 && cmd.ParentAPIKeyID > 0 {
+		exhausted, err := incrementUsageBillingAPIKeyQuota(ctx, tx, cmd.ParentAPIKeyID, cmd.ParentAPIKeyQuotaCost)
+		if err != nil {
+			return err
+		}
+		result.ParentAPIKeyQuotaExhausted = exhausted
+	}
+
 	if cmd.APIKeyRateLimitCost > 0 {
 		if err := incrementUsageBillingAPIKeyRateLimit(ctx, tx, cmd.APIKeyID, cmd.APIKeyRateLimitCost); err != nil {
 			return err
@@ -43,22 +43,28 @@ func (r *usageCleanupRepository) ListTasks(ctx context.Context, params paginatio
 		return r.listTasksWithEnt(ctx, params)
 	}
 	var total int64
-	if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM usage_cleanup_tasks", nil, &total); err != nil {
-		return nil, nil, err
-	}
-	if total == 0 {
-		return []service.UsageCleanupTask{}, paginationResultFromTotal(0, params), nil
-	}
-
 	query := `
 		SELECT id, status, filters, created_by, deleted_rows, error_message,
 			canceled_by, canceled_at,
 			started_at, finished_at, created_at, updated_at
 		FROM usage_cleanup_tasks
-		ORDER BY created_at DESC, id DESC
-		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.sql.QueryContext(ctx, query, params.Limit(), params.Offset())
+	var args []any
+	if params.UsesCursor() {
+		conditions, cursorArgs, orderBy := appendKeysetCursorCondition(nil, nil, "created_at", "id", params)
+		args = append(cursorArgs, params.Limit()+1)
+		query += buildWhere(conditions) + " ORDER BY " + orderBy + fmt.Sprintf(" LIMIT $%d", len(args))
+	} else {
+		if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM usage_cleanup_tasks", nil, &total); err != nil {
+			return nil, nil, err
+		}
+		if total == 0 {
+			return []service.UsageCleanupTask{}, paginationResultFromTotal(0, params), nil
+		}
+		query += "ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2"
+		args = []any{params.Limit(), params.Offset()}
+	}
+	rows, err := r.sql.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -113,9 +119,24 @@ func (r *usageCleanupRepository) ListTasks(ctx context.Context, params paginatio
 	if err := rows.Err(); err != nil {
 		return nil, nil, err
 	}
+	if params.UsesCursor() {
+		return usageCleanupTasksCursorPage(tasks, params)
+	}
 	return tasks, paginationResultFromTotal(total, params), nil
 }
 
+func usageCleanupTasksCursorPage(tasks []service.UsageCleanupTask, params pagination.PaginationParams) ([]service.UsageCleanupTask, *pagination.PaginationResult, error) {
+	fetched := len(tasks)
+	if fetched > params.Limit() {
+		tasks = tasks[:params.Limit()]
+	}
+	var last pagination.Cursor
+	if len(tasks) > 0 {
+		last = pagination.Cursor{CreatedAt: tasks[len(tasks)-1].CreatedAt, ID: tasks[len(tasks)-1].ID}
+	}
+	return tasks, pagination.CursorResult(params, fetched, last), nil
+}
+
 func (r *usageCleanupRepository) ClaimNextPendingTask(ctx context.Context, staleRunningAfterSeconds int64) (*service.UsageCleanupTask, error) {
 	if staleRunningAfterSeconds <= 0 {
 		staleRunningAfterSeconds = 1800
@@ -424,6 +445,9 @@ func (r *usageCleanupRepository) createTaskWithSQL(ctx context.Context, task *se
 func (r *usageCleanupRepository) listTasksWithEnt(ctx context.Context, params pagination.PaginationParams) ([]service.UsageCleanupTask, *pagination.PaginationResult, error) {
 	client := clientFromContext(ctx, r.client)
 	query := client.UsageCleanupTask.Query()
+	if params.UsesCursor() {
+		return r.listTasksWithEntCursor(ctx, query, params)
+	}
 	total, err := query.Clone().Count(ctx)
 	if err != nil {
 		return nil, nil, err
@@ -450,6 +474,43 @@ func (r *usageCleanupRepository) listTasksWithEnt(ctx context.Context, params pa
 	return tasks, paginationResultFromTotal(int64(total), params), nil
 }
 
+func (r *usageCleanupRepository) listTasksWithEntCursor(ctx context.Context, query *dbent.UsageCleanupTaskQuery, params pagination.PaginationParams) ([]service.UsageCleanupTask, *pagination.PaginationResult, error) {
+	asc := params.NormalizedSortOrder(pagination.SortOrderDesc) == pagination.SortOrderAsc
+	if c := params.Cursor; !c.IsZero() {
+		if asc {
+			query = query.Where(dbusagecleanuptask.Or(
+				dbusagecleanuptask.CreatedAtGT(c.CreatedAt),
+				dbusagecleanuptask.And(dbusagecleanuptask.CreatedAtEQ(c.CreatedAt), dbusagecleanuptask.IDGT(c.ID)),
+			))
+		} else {
+			query = query.Where(dbusagecleanuptask.Or(
+				dbusagecleanuptask.CreatedAtLT(c.CreatedAt),
+				dbusagecleanuptask.And(dbusagecleanuptask.CreatedAtEQ(c.CreatedAt), dbusagecleanuptask.IDLT(c.ID)),
+			))
+		}
+	}
+	order := dbent.Desc
+	if asc {
+		order = dbent.Asc
+	}
+	rows, err := query.
+		Order(order(dbusagecleanuptask.FieldCreatedAt), order(dbusagecleanuptask.FieldID)).
+		Limit(params.Limit() + 1).
+		All(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks := make([]service.UsageCleanupTask, 0, len(rows))
+	for _, row := range rows {
+		task, err := usageCleanupTaskFromEnt(row)
+		if err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return usageCleanupTasksCursorPage(tasks, params)
+}
+
 func (r *usageCleanupRepository) getTaskStatusWithEnt(ctx context.Context, taskID int64) (string, error) {
 	client := clientFromContext(ctx, r.client)
 	task, err := client.UsageCleanupTask.Query().
@@ -512,3 +512,37 @@ func TestBuildUsageCleanupWhereModelEmpty(t *testing.T) {
 	require.Equal(t, "created_at >= $1 AND created_at <= $2", where)
 	require.Equal(t, []any{start, end}, args)
 }
+
+func TestUsageCleanupRepositoryListTasksCursor(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageCleanupRepository{sql: db}
+
+	filtersJSON, err := json.Marshal(service.UsageCleanupFilters{})
+	require.NoError(t, err)
+	base := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{
+		"id", "status", "filters", "created_by", "deleted_rows", "error_message",
+		"canceled_by", "canceled_at",
+		"started_at", "finished_at", "created_at", "updated_at",
+	})
+	for i := int64(0); i < 3; i++ {
+		createdAt := base.Add(-time.Duration(i) * time.Minute)
+		rows.AddRow(9-i, service.UsageCleanupStatusSucceeded, filtersJSON, int64(1), int64(0), nil, nil, nil, nil, nil, createdAt, createdAt)
+	}
+
+	cursor := pagination.Cursor{CreatedAt: base.Add(time.Minute), ID: 10}
+	// 游标模式不做 COUNT，多取一行判断是否有下一页
+	mock.ExpectQuery("FROM usage_cleanup_tasks\\s+WHERE \\(created_at, id\\) < \\(\\$1, \\$2\\) ORDER BY created_at DESC, id DESC LIMIT \\$3").
+		WithArgs(cursor.CreatedAt, cursor.ID, 3).
+		WillReturnRows(rows)
+
+	tasks, result, err := repo.ListTasks(context.Background(), pagination.PaginationParams{PageSize: 2, Cursor: &cursor})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	require.True(t, result.HasMore)
+	next, err := pagination.ParseCursor(result.NextCursor)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), next.ID)
+	require.True(t, next.CreatedAt.Equal(base.Add(-time.Minute)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -140,6 +141,8 @@ type usageLogRepository struct {
 	client *dbent.Client
 	sql    sqlExecutor
 	db     *sql.DB
+	// replica 只读副本（可选），仅用于仪表盘统计、趋势与使用记录列表等重型读取
+	replica *DBReadReplica
 
 	createBatchOnce     sync.Once
 	createBatchCh       chan usageLogCreateRequest
@@ -148,8 +151,10 @@ type usageLogRepository struct {
 	bestEffortRecent    *gocache.Cache
 }
 
-func NewUsageLogRepository(client *dbent.Client, sqlDB *sql.DB) service.UsageLogRepository {
-	return newUsageLogRepositoryWithSQL(client, sqlDB)
+func NewUsageLogRepository(client *dbent.Client, sqlDB *sql.DB, replica *DBReadReplica) service.UsageLogRepository {
+	repo := newUsageLogRepositoryWithSQL(client, sqlDB)
+	repo.replica = replica
+	return repo
 }
 
 func newUsageLogRepositoryWithSQL(client *dbent.Client, sqlq sqlExecutor) *usageLogRepository {
@@ -162,6 +167,17 @@ func newUsageLogRepositoryWithSQL(client *dbent.Client, sqlq sqlExecutor) *usage
 	return repo
 }
 
+// readSQL 返回重型只读查询使用的执行器：副本可用时走副本，否则（含事务内、ctx 要求读主库、副本延迟过高）走主库。
+// 配额窗口统计、按 ID 读取等一致性敏感的查询不要使用。
+func (r *usageLogRepository) readSQL(ctx context.Context) sqlExecutor {
+	if r.db != nil {
+		if db := r.replica.Reader(ctx); db != nil {
+			return db
+		}
+	}
+	return r.sql
+}
+
 func buildWhere(conditions []string) string {
 	if len(conditions) == 0 {
 		return ""
@@ -43,6 +43,8 @@ var usageLogInsertArgTypes = [...]string{
 	"numeric",     // image_output_cost
 	"integer",     // image_input_tokens
 	"numeric",     // image_input_cost
+	"integer",     // reasoning_tokens
+	"numeric",     // reasoning_cost
 	"numeric",     // input_cost
 	"numeric",     // output_cost
 	"numeric",     // cache_creation_cost
@@ -72,6 +74,7 @@ var usageLogInsertArgTypes = [...]string{
 	"text",        // reasoning_effort
 	"text",        // inbound_endpoint
 	"text",        // upstream_endpoint
+	"text",        // beta_features
 	"boolean",     // cache_ttl_overridden
 	"boolean",     // long_context_billing_applied
 	"bigint",      // channel_id
@@ -238,6 +241,8 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			image_output_cost,
 			image_input_tokens,
 			image_input_cost,
+			reasoning_tokens,
+			reasoning_cost,
 			input_cost,
 			output_cost,
 			cache_creation_cost,
@@ -267,6 +272,7 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			reasoning_effort,
 			inbound_endpoint,
 			upstream_endpoint,
+			beta_features,
 			cache_ttl_overridden,
 			long_context_billing_applied,
 			channel_id,
@@ -281,7 +287,7 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			$10, $11, $12, $13,
 			$14, $15, $16, $17,
 			$18, $19, $20, $21, $22, $23,
-			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56
+			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 		RETURNING id, created_at
@@ -692,6 +698,8 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 			image_output_cost,
 			image_input_tokens,
 			image_input_cost,
+			reasoning_tokens,
+			reasoning_cost,
 			input_cost,
 			output_cost,
 			cache_creation_cost,
@@ -721,6 +729,7 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 			reasoning_effort,
 			inbound_endpoint,
 			upstream_endpoint,
+			beta_features,
 			cache_ttl_overridden,
 			long_context_billing_applied,
 			channel_id,
@@ -779,6 +788,8 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				image_output_cost,
 				image_input_tokens,
 				image_input_cost,
+				reasoning_tokens,
+				reasoning_cost,
 				input_cost,
 				output_cost,
 				cache_creation_cost,
@@ -808,6 +819,7 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				reasoning_effort,
 				inbound_endpoint,
 				upstream_endpoint,
+				beta_features,
 				cache_ttl_overridden,
 				long_context_billing_applied,
 				channel_id,
@@ -837,6 +849,8 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				image_output_cost,
 				image_input_tokens,
 				image_input_cost,
+				reasoning_tokens,
+				reasoning_cost,
 				input_cost,
 				output_cost,
 				cache_creation_cost,
@@ -866,6 +880,7 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				reasoning_effort,
 				inbound_endpoint,
 				upstream_endpoint,
+				beta_features,
 				cache_ttl_overridden,
 				long_context_billing_applied,
 				channel_id,
@@ -935,6 +950,8 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			image_output_cost,
 			image_input_tokens,
 			image_input_cost,
+			reasoning_tokens,
+			reasoning_cost,
 			input_cost,
 			output_cost,
 			cache_creation_cost,
@@ -964,6 +981,7 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			reasoning_effort,
 			inbound_endpoint,
 			upstream_endpoint,
+			beta_features,
 			cache_ttl_overridden,
 			long_context_billing_applied,
 			channel_id,
@@ -1019,6 +1037,8 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			image_output_cost,
 			image_input_tokens,
 			image_input_cost,
+			reasoning_tokens,
+			reasoning_cost,
 			input_cost,
 			output_cost,
 			cache_creation_cost,
@@ -1048,6 +1068,7 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			reasoning_effort,
 			inbound_endpoint,
 			upstream_endpoint,
+			beta_features,
 			cache_ttl_overridden,
 			long_context_billing_applied,
 			channel_id,
@@ -1077,6 +1098,8 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			image_output_cost,
 			image_input_tokens,
 			image_input_cost,
+			reasoning_tokens,
+			reasoning_cost,
 			input_cost,
 			output_cost,
 			cache_creation_cost,
@@ -1106,6 +1129,7 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			reasoning_effort,
 			inbound_endpoint,
 			upstream_endpoint,
+			beta_features,
 			cache_ttl_overridden,
 			long_context_billing_applied,
 			channel_id,
@@ -1143,6 +1167,8 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			image_output_cost,
 			image_input_tokens,
 			image_input_cost,
+			reasoning_tokens,
+			reasoning_cost,
 			input_cost,
 			output_cost,
 			cache_creation_cost,
@@ -1172,6 +1198,7 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			reasoning_effort,
 			inbound_endpoint,
 			upstream_endpoint,
+			beta_features,
 			cache_ttl_overridden,
 			long_context_billing_applied,
 			channel_id,
@@ -1186,7 +1213,7 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			$10, $11, $12, $13,
 			$14, $15, $16, $17,
 			$18, $19, $20, $21, $22, $23,
-			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56
+			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 	`, prepared.args...)
@@ -1223,6 +1250,7 @@ func prepareUsageLogInsert(log *service.UsageLog) usageLogInsertPrepared {
 	reasoningEffort := nullString(log.ReasoningEffort)
 	inboundEndpoint := nullString(log.InboundEndpoint)
 	upstreamEndpoint := nullString(log.UpstreamEndpoint)
+	betaFeatures := nullString(log.BetaFeatures)
 	channelID := nullInt64(log.ChannelID)
 	modelMappingChain := nullString(log.ModelMappingChain)
 	billingTier := nullString(log.BillingTier)
@@ -1263,6 +1291,8 @@ func prepareUsageLogInsert(log *service.UsageLog) usageLogInsertPrepared {
 			log.ImageOutputCost,
 			log.ImageInputTokens,
 			log.ImageInputCost,
+			log.ReasoningTokens,
+			log.ReasoningCost,
 			log.InputCost,
 			log.OutputCost,
 			log.CacheCreationCost,
@@ -1292,6 +1322,7 @@ func prepareUsageLogInsert(log *service.UsageLog) usageLogInsertPrepared {
 			reasoningEffort,
 			inboundEndpoint,
 			upstreamEndpoint,
+			betaFeatures,
 			log.CacheTTLOverridden,
 			log.LongContextBillingApplied,
 			channelID,
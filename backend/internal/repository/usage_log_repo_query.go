@@ -19,7 +19,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/service"
 )
 
-const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, requested_model, upstream_model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, image_output_tokens, image_output_cost, image_input_tokens, image_input_cost, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, image_input_size, image_output_size, image_size_source, image_size_breakdown, video_count, video_resolution, video_duration_seconds, service_tier, reasoning_effort, inbound_endpoint, upstream_endpoint, cache_ttl_overridden, long_context_billing_applied, channel_id, model_mapping_chain, billing_tier, billing_mode, account_stats_cost, created_at"
+const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, requested_model, upstream_model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, image_output_tokens, image_output_cost, image_input_tokens, image_input_cost, reasoning_tokens, reasoning_cost, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, image_input_size, image_output_size, image_size_source, image_size_breakdown, video_count, video_resolution, video_duration_seconds, service_tier, reasoning_effort, inbound_endpoint, upstream_endpoint, beta_features, cache_ttl_overridden, long_context_billing_applied, channel_id, model_mapping_chain, billing_tier, billing_mode, account_stats_cost, created_at"
 
 func (r *usageLogRepository) GetByID(ctx context.Context, id int64) (log *service.UsageLog, err error) {
 	query := "SELECT " + usageLogSelectColumns + " FROM usage_logs WHERE id = $1"
@@ -88,10 +88,17 @@ func (r *usageLogRepository) ListByModelAndTimeRange(ctx context.Context, modelN
 }
 
 func (r *usageLogRepository) Delete(ctx context.Context, id int64) error {
-	_, err := r.sql.ExecContext(ctx, "DELETE FROM usage_logs WHERE id = $1", id)
+	if _, err := r.sql.ExecContext(ctx, "DELETE FROM usage_logs WHERE id = $1", id); err != nil {
+		return err
+	}
+	// 删除中间记录不改变 id 范围，递增修订号使管理端列表指纹失效
+	_, err := r.sql.ExecContext(ctx, bumpUsageLogRevisionSQL)
 	return err
 }
 
+// bumpUsageLogRevisionSQL 递增用量日志修订号（见 migrations/213_usage_log_revision.sql）
+const bumpUsageLogRevisionSQL = `UPDATE usage_log_revision SET revision = revision + 1, updated_at = NOW() WHERE id = 1`
+
 // UsageLogFilters represents filters for usage log queries
 type UsageLogFilters = usagestats.UsageLogFilters
 
@@ -131,6 +138,10 @@ func (r *usageLogRepository) ListWithFilters(ctx context.Context, params paginat
 		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)+1))
 		args = append(args, *filters.EndTime)
 	}
+	if filters.AfterID > 0 {
+		conditions = append(conditions, fmt.Sprintf("id > $%d", len(args)+1))
+		args = append(args, filters.AfterID)
+	}
 
 	whereClause := buildWhere(conditions)
 	var (
@@ -138,9 +149,12 @@ func (r *usageLogRepository) ListWithFilters(ctx context.Context, params paginat
 		page *pagination.PaginationResult
 		err  error
 	)
-	if shouldUseFastUsageLogTotal(filters) {
+	switch {
+	case params.UsesCursor():
+		logs, page, err = r.listUsageLogsWithCursor(ctx, conditions, args, params)
+	case shouldUseFastUsageLogTotal(filters):
 		logs, page, err = r.listUsageLogsWithFastPagination(ctx, whereClause, args, params)
-	} else {
+	default:
 		logs, page, err = r.listUsageLogsWithPagination(ctx, whereClause, args, params)
 	}
 	if err != nil {
@@ -164,7 +178,7 @@ func shouldUseFastUsageLogTotal(filters UsageLogFilters) bool {
 func (r *usageLogRepository) listUsageLogsWithPagination(ctx context.Context, whereClause string, args []any, params pagination.PaginationParams) ([]service.UsageLog, *pagination.PaginationResult, error) {
 	countQuery := "SELECT COUNT(*) FROM usage_logs " + whereClause
 	var total int64
-	if err := scanSingleRow(ctx, r.sql, countQuery, args, &total); err != nil {
+	if err := scanSingleRow(ctx, r.readSQL(ctx), countQuery, args, &total); err != nil {
 		return nil, nil, err
 	}
 
@@ -208,6 +222,28 @@ func (r *usageLogRepository) listUsageLogsWithFastPagination(ctx context.Context
 	return logs, paginationResultFromTotal(total, params), nil
 }
 
+// listUsageLogsWithCursor keyset 分页：按 (created_at, id) 定位，不做 COUNT 也不扫描 OFFSET 行。
+func (r *usageLogRepository) listUsageLogsWithCursor(ctx context.Context, conditions []string, args []any, params pagination.PaginationParams) ([]service.UsageLog, *pagination.PaginationResult, error) {
+	conditions, args, orderBy := appendKeysetCursorCondition(conditions, args, "created_at", "id", params)
+	limit := params.Limit()
+	listArgs := append(append([]any{}, args...), limit+1)
+	query := fmt.Sprintf("SELECT %s FROM usage_logs %s ORDER BY %s LIMIT $%d", usageLogSelectColumns, buildWhere(conditions), orderBy, len(listArgs))
+
+	logs, err := r.queryUsageLogs(ctx, query, listArgs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	fetched := len(logs)
+	if fetched > limit {
+		logs = logs[:limit]
+	}
+	var last pagination.Cursor
+	if len(logs) > 0 {
+		last = pagination.Cursor{CreatedAt: logs[len(logs)-1].CreatedAt, ID: logs[len(logs)-1].ID}
+	}
+	return logs, pagination.CursorResult(params, fetched, last), nil
+}
+
 func usageLogOrderBy(params pagination.PaginationParams) string {
 	sortBy := strings.ToLower(strings.TrimSpace(params.SortBy))
 	sortOrder := strings.ToUpper(params.NormalizedSortOrder(pagination.SortOrderDesc))
@@ -229,7 +265,7 @@ func usageLogOrderBy(params pagination.PaginationParams) string {
 }
 
 func (r *usageLogRepository) queryUsageLogs(ctx context.Context, query string, args ...any) (logs []service.UsageLog, err error) {
-	rows, err := r.sql.QueryContext(ctx, query, args...)
+	rows, err := r.readSQL(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -445,6 +481,8 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		imageOutputCost           float64
 		imageInputTokens          int
 		imageInputCost            float64
+		reasoningTokens           int
+		reasoningCost             float64
 		inputCost                 float64
 		outputCost                float64
 		cacheCreationCost         float64
@@ -474,6 +512,7 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		reasoningEffort           sql.NullString
 		inboundEndpoint           sql.NullString
 		upstreamEndpoint          sql.NullString
+		betaFeatures              sql.NullString
 		cacheTTLOverridden        bool
 		longContextBillingApplied bool
 		channelID                 sql.NullInt64
@@ -505,6 +544,8 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		&imageOutputCost,
 		&imageInputTokens,
 		&imageInputCost,
+		&reasoningTokens,
+		&reasoningCost,
 		&inputCost,
 		&outputCost,
 		&cacheCreationCost,
@@ -534,6 +575,7 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		&reasoningEffort,
 		&inboundEndpoint,
 		&upstreamEndpoint,
+		&betaFeatures,
 		&cacheTTLOverridden,
 		&longContextBillingApplied,
 		&channelID,
@@ -563,6 +605,8 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		ImageOutputCost:           imageOutputCost,
 		ImageInputTokens:          imageInputTokens,
 		ImageInputCost:            imageInputCost,
+		ReasoningTokens:           reasoningTokens,
+		ReasoningCost:             reasoningCost,
 		InputCost:                 inputCost,
 		OutputCost:                outputCost,
 		CacheCreationCost:         cacheCreationCost,
@@ -642,6 +686,9 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 	if upstreamEndpoint.Valid {
 		log.UpstreamEndpoint = &upstreamEndpoint.String
 	}
+	if betaFeatures.Valid {
+		log.BetaFeatures = &betaFeatures.String
+	}
 	if upstreamModel.Valid {
 		log.UpstreamModel = &upstreamModel.String
 	}
@@ -60,6 +60,8 @@ func TestUsageLogRepositoryCreateSyncRequestTypeAndLegacyFields(t *testing.T) {
 			log.ImageOutputCost,
 			log.ImageInputTokens,
 			log.ImageInputCost,
+			log.ReasoningTokens,
+			log.ReasoningCost,
 			log.InputCost,
 			log.OutputCost,
 			log.CacheCreationCost,
@@ -89,6 +91,7 @@ func TestUsageLogRepositoryCreateSyncRequestTypeAndLegacyFields(t *testing.T) {
 			sqlmock.AnyArg(), // reasoning_effort
 			sqlmock.AnyArg(), // inbound_endpoint
 			sqlmock.AnyArg(), // upstream_endpoint
+			sqlmock.AnyArg(), // beta_features
 			log.CacheTTLOverridden,
 			log.LongContextBillingApplied,
 			sqlmock.AnyArg(), // channel_id
@@ -149,6 +152,8 @@ func TestUsageLogRepositoryCreate_PersistsServiceTier(t *testing.T) {
 			log.ImageOutputCost,
 			log.ImageInputTokens,
 			log.ImageInputCost,
+			log.ReasoningTokens,
+			log.ReasoningCost,
 			log.InputCost,
 			log.OutputCost,
 			log.CacheCreationCost,
@@ -178,6 +183,7 @@ func TestUsageLogRepositoryCreate_PersistsServiceTier(t *testing.T) {
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
+			sqlmock.AnyArg(), // beta_features
 			log.CacheTTLOverridden,
 			log.LongContextBillingApplied,
 			sqlmock.AnyArg(), // channel_id
@@ -271,11 +277,11 @@ func TestPrepareUsageLogInsert_PersistsImageSizeMetadata(t *testing.T) {
 		CreatedAt:          time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC),
 	})
 
-	require.Equal(t, sql.NullString{String: imageSize, Valid: true}, prepared.args[36])
-	require.Equal(t, sql.NullString{String: inputSize, Valid: true}, prepared.args[37])
-	require.Equal(t, sql.NullString{String: outputSize, Valid: true}, prepared.args[38])
-	require.Equal(t, sql.NullString{String: source, Valid: true}, prepared.args[39])
-	breakdownJSON, ok := prepared.args[40].(string)
+	require.Equal(t, sql.NullString{String: imageSize, Valid: true}, prepared.args[38])
+	require.Equal(t, sql.NullString{String: inputSize, Valid: true}, prepared.args[39])
+	require.Equal(t, sql.NullString{String: outputSize, Valid: true}, prepared.args[40])
+	require.Equal(t, sql.NullString{String: source, Valid: true}, prepared.args[41])
+	breakdownJSON, ok := prepared.args[42].(string)
 	require.True(t, ok)
 	require.JSONEq(t, `{"1K":1,"4K":1}`, breakdownJSON)
 }
@@ -392,6 +398,38 @@ func TestUsageLogRepositoryListWithFiltersRequestedModelSource(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUsageLogRepositoryListWithFiltersCursor(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db}
+
+	cursor := pagination.Cursor{CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), ID: 42}
+	mock.ExpectQuery("SELECT .* FROM usage_logs WHERE user_id = \\$1 AND \\(created_at, id\\) > \\(\\$2, \\$3\\) ORDER BY created_at ASC, id ASC LIMIT \\$4$").
+		WithArgs(int64(7), cursor.CreatedAt, cursor.ID, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	params := pagination.PaginationParams{PageSize: 20, SortBy: "model", SortOrder: "asc", Cursor: &cursor}
+	logs, page, err := repo.ListWithFilters(context.Background(), params, usagestats.UsageLogFilters{UserID: 7})
+	require.NoError(t, err)
+	require.Empty(t, logs)
+	require.False(t, page.HasMore)
+	require.Empty(t, page.NextCursor)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageLogRepositoryListWithFiltersCursorFirstPage(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db}
+
+	mock.ExpectQuery("SELECT .* FROM usage_logs\\s+ORDER BY created_at DESC, id DESC LIMIT \\$1$").
+		WithArgs(51).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	params := pagination.PaginationParams{PageSize: 50, Cursor: &pagination.Cursor{}}
+	_, _, err := repo.ListWithFilters(context.Background(), params, usagestats.UsageLogFilters{})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUsageLogRepositoryGetUsageTrendWithFiltersRequestTypePriority(t *testing.T) {
 	db, mock := newSQLMock(t)
 	repo := &usageLogRepository{sql: db}
@@ -795,6 +833,7 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			0, 0, 0, 0, 0, 0,
 			0, 0.0, // image_output_tokens, image_output_cost
 			0, 0.0, // image_input_tokens, image_input_cost
+			0, 0.0, // reasoning_tokens, reasoning_cost
 			0.0, 0.0, 0.0, 0.0, 0.8, 0.8,
 			1.0,
 			sql.NullFloat64{},
@@ -819,6 +858,7 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			sql.NullString{},
+			sql.NullString{}, // beta_features
 			false,
 			false,
 			sql.NullInt64{},
@@ -864,6 +904,8 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			0.0,               // image_output_cost
 			0,                 // image_input_tokens
 			0.0,               // image_input_cost
+			0,                 // reasoning_tokens
+			0.0,               // reasoning_cost
 			0.1,               // input_cost
 			0.2,               // output_cost
 			0.3,               // cache_creation_cost
@@ -893,6 +935,7 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			sql.NullString{},
+			sql.NullString{}, // beta_features
 			false,
 			false,
 			sql.NullInt64{},   // channel_id
@@ -926,6 +969,7 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			1, 2, 3, 4, 5, 6,
 			0, 0.0, // image_output_tokens, image_output_cost
 			0, 0.0, // image_input_tokens, image_input_cost
+			0, 0.0, // reasoning_tokens, reasoning_cost
 			0.1, 0.2, 0.3, 0.4, 1.0, 0.9,
 			1.0,
 			sql.NullFloat64{},
@@ -950,6 +994,7 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			sql.NullString{},
+			sql.NullString{}, // beta_features
 			false,
 			false,
 			sql.NullInt64{},   // channel_id
@@ -983,6 +1028,7 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			1, 2, 3, 4, 5, 6,
 			0, 0.0, // image_output_tokens, image_output_cost
 			0, 0.0, // image_input_tokens, image_input_cost
+			0, 0.0, // reasoning_tokens, reasoning_cost
 			0.1, 0.2, 0.3, 0.4, 1.0, 0.9,
 			1.0,
 			sql.NullFloat64{},
@@ -1007,6 +1053,7 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			sql.NullString{},
+			sql.NullString{}, // beta_features
 			false,
 			false,
 			sql.NullInt64{},   // channel_id
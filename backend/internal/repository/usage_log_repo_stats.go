@@ -37,7 +37,7 @@ func (r *usageLogRepository) GetUserStatsAggregated(ctx context.Context, userID
 	var stats usagestats.UsageStats
 	if err := scanSingleRow(
 		ctx,
-		r.sql,
+		r.readSQL(ctx),
 		query,
 		[]any{userID, startTime, endTime},
 		&stats.TotalRequests,
@@ -76,7 +76,7 @@ func (r *usageLogRepository) GetAPIKeyStatsAggregated(ctx context.Context, apiKe
 	var stats usagestats.UsageStats
 	if err := scanSingleRow(
 		ctx,
-		r.sql,
+		r.readSQL(ctx),
 		query,
 		[]any{apiKeyID, startTime, endTime},
 		&stats.TotalRequests,
@@ -125,7 +125,7 @@ func (r *usageLogRepository) GetAccountStatsAggregated(ctx context.Context, acco
 	var stats usagestats.UsageStats
 	if err := scanSingleRow(
 		ctx,
-		r.sql,
+		r.readSQL(ctx),
 		query,
 		[]any{accountID, startTime, endTime},
 		&stats.TotalRequests,
@@ -165,7 +165,7 @@ func (r *usageLogRepository) GetModelStatsAggregated(ctx context.Context, modelN
 	var stats usagestats.UsageStats
 	if err := scanSingleRow(
 		ctx,
-		r.sql,
+		r.readSQL(ctx),
 		query,
 		[]any{modelName, startTime, endTime},
 		&stats.TotalRequests,
@@ -205,7 +205,7 @@ func (r *usageLogRepository) GetDailyStatsAggregated(ctx context.Context, userID
 		ORDER BY 1
 	`
 
-	rows, err := r.sql.QueryContext(ctx, query, userID, startTime, endTime, tzName)
+	rows, err := r.readSQL(ctx).QueryContext(ctx, query, userID, startTime, endTime, tzName)
 	if err != nil {
 		return nil, err
 	}
@@ -509,7 +509,7 @@ func (r *usageLogRepository) GetBatchUserUsageStats(ctx context.Context, userIDs
 		GROUP BY ul.user_id, ` + usageLogEffectivePlatformExpr + `
 	`
 	today := timezone.Today()
-	rows, err := r.sql.QueryContext(ctx, query, pq.Array(normalizedUserIDs), startTime, endTime, today)
+	rows, err := r.readSQL(ctx).QueryContext(ctx, query, pq.Array(normalizedUserIDs), startTime, endTime, today)
 	if err != nil {
 		return nil, err
 	}
@@ -581,7 +581,7 @@ func (r *usageLogRepository) GetBatchAPIKeyUsageStats(ctx context.Context, apiKe
 		GROUP BY api_key_id
 	`
 	today := timezone.Today()
-	rows, err := r.sql.QueryContext(ctx, query, pq.Array(normalizedAPIKeyIDs), startTime, endTime, today)
+	rows, err := r.readSQL(ctx).QueryContext(ctx, query, pq.Array(normalizedAPIKeyIDs), startTime, endTime, today)
 	if err != nil {
 		return nil, err
 	}
@@ -638,7 +638,7 @@ func (r *usageLogRepository) GetGlobalStats(ctx context.Context, startTime, endT
 	stats := &UsageStats{}
 	if err := scanSingleRow(
 		ctx,
-		r.sql,
+		r.readSQL(ctx),
 		query,
 		[]any{startTime, endTime},
 		&stats.TotalRequests,
@@ -725,7 +725,7 @@ func (r *usageLogRepository) GetStatsWithFilters(ctx context.Context, filters Us
 	// 汇总查询:失败即致命。
 	runSummary := func(c context.Context) error {
 		return scanSingleRow(
-			c, r.sql, query, args,
+			c, r.readSQL(c), query, args,
 			&stats.TotalRequests,
 			&stats.TotalInputTokens,
 			&stats.TotalOutputTokens,
@@ -771,7 +771,7 @@ func (r *usageLogRepository) GetStatsWithFilters(ctx context.Context, filters Us
 	}
 
 	if r.db != nil {
-		// 生产路径:r.sql 是 *sql.DB 连接池,可并发。4 条查询并行,延迟取最大值。
+		// 生产路径:r.readSQL(ctx) 是 *sql.DB 连接池,可并发。4 条查询并行,延迟取最大值。
 		g, gctx := errgroup.WithContext(ctx)
 		g.Go(func() error { return runSummary(gctx) })
 		g.Go(func() error { runEndpoints(gctx); return nil })
@@ -854,7 +854,7 @@ func (r *usageLogRepository) getEndpointStatsByColumnWithFilters(ctx context.Con
 	query, args = appendUsageLogBillingModeQueryFilter(query, args, billingMode, "")
 	query += " GROUP BY endpoint ORDER BY requests DESC"
 
-	rows, err := r.sql.QueryContext(ctx, query, args...)
+	rows, err := r.readSQL(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -926,7 +926,7 @@ func (r *usageLogRepository) getEndpointPathStatsWithFilters(ctx context.Context
 	query, args = appendUsageLogBillingModeQueryFilter(query, args, billingMode, "")
 	query += " GROUP BY endpoint ORDER BY requests DESC"
 
-	rows, err := r.sql.QueryContext(ctx, query, args...)
+	rows, err := r.readSQL(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -982,7 +982,7 @@ func (r *usageLogRepository) GetAccountUsageStats(ctx context.Context, accountID
 		ORDER BY date ASC
 	`
 
-	rows, err := r.sql.QueryContext(ctx, query, accountID, startTime, endTime)
+	rows, err := r.readSQL(ctx).QueryContext(ctx, query, accountID, startTime, endTime)
 	if err != nil {
 		return nil, err
 	}
@@ -1048,7 +1048,7 @@ func (r *usageLogRepository) GetAccountUsageStats(ctx context.Context, accountID
 
 	avgQuery := "SELECT COALESCE(AVG(duration_ms), 0) as avg_duration_ms FROM usage_logs WHERE account_id = $1 AND created_at >= $2 AND created_at < $3"
 	var avgDuration float64
-	if err := scanSingleRow(ctx, r.sql, avgQuery, []any{accountID, startTime, endTime}, &avgDuration); err != nil {
+	if err := scanSingleRow(ctx, r.readSQL(ctx), avgQuery, []any{accountID, startTime, endTime}, &avgDuration); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	usageRecordDeadLetterStreamKey    = "usage_record:dlq"
+	usageRecordDeadLetterPayloadField = "p"
+)
+
+type usageRecordDeadLetterCache struct {
+	rdb redis.UniversalClient
+}
+
+func NewUsageRecordDeadLetterCache(rdb redis.UniversalClient) service.UsageRecordDeadLetterCache {
+	return &usageRecordDeadLetterCache{rdb: rdb}
+}
+
+func (c *usageRecordDeadLetterCache) Push(ctx context.Context, payload []byte, maxEntries int64) error {
+	args := &redis.XAddArgs{
+		Stream: usageRecordDeadLetterStreamKey,
+		Values: []any{usageRecordDeadLetterPayloadField, payload},
+	}
+	if maxEntries > 0 {
+		args.MaxLen = maxEntries
+		args.Approx = true
+	}
+	return c.rdb.XAdd(ctx, args).Err()
+}
+
+func (c *usageRecordDeadLetterCache) Peek(ctx context.Context, count int64) ([]service.UsageRecordDeadLetterEntry, error) {
+	msgs, err := c.rdb.XRangeN(ctx, usageRecordDeadLetterStreamKey, "-", "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]service.UsageRecordDeadLetterEntry, 0, len(msgs))
+	for _, msg := range msgs {
+		raw, _ := msg.Values[usageRecordDeadLetterPayloadField].(string)
+		entries = append(entries, service.UsageRecordDeadLetterEntry{ID: msg.ID, Payload: []byte(raw)})
+	}
+	return entries, nil
+}
+
+func (c *usageRecordDeadLetterCache) Remove(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return c.rdb.XDel(ctx, usageRecordDeadLetterStreamKey, ids...).Err()
+}
+
+func (c *usageRecordDeadLetterCache) Depth(ctx context.Context) (int64, error) {
+	return c.rdb.XLen(ctx, usageRecordDeadLetterStreamKey).Result()
+}
@@ -0,0 +1,38 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRecordDeadLetterCache_PushPeekRemove(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewUsageRecordDeadLetterCache(rdb)
+
+	depth, err := cache.Depth(ctx)
+	require.NoError(t, err)
+	require.Zero(t, depth)
+
+	require.NoError(t, cache.Push(ctx, []byte(`{"a":1}`), 100))
+	require.NoError(t, cache.Push(ctx, []byte(`{"b":2}`), 100))
+
+	entries, err := cache.Peek(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, `{"a":1}`, string(entries[0].Payload))
+	require.Equal(t, `{"b":2}`, string(entries[1].Payload))
+
+	require.NoError(t, cache.Remove(ctx, entries[0].ID))
+	depth, err = cache.Depth(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), depth)
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// userNotificationRepository 终端用户站内通知仓储（raw SQL）。
+type userNotificationRepository struct {
+	db *sql.DB
+}
+
+// NewUserNotificationRepository 创建站内通知仓储。
+func NewUserNotificationRepository(db *sql.DB) service.UserNotificationRepository {
+	return &userNotificationRepository{db: db}
+}
+
+const userNotificationSelectColumns = `
+  id, user_id, type, level, title, content, metadata, read_at, created_at`
+
+func (r *userNotificationRepository) Create(ctx context.Context, input *service.UserNotificationInput) (bool, error) {
+	if r == nil || r.db == nil {
+		return false, fmt.Errorf("nil user notification repository")
+	}
+	metadata := []byte("{}")
+	if len(input.Metadata) > 0 {
+		raw, err := json.Marshal(input.Metadata)
+		if err != nil {
+			return false, fmt.Errorf("marshal notification metadata: %w", err)
+		}
+		metadata = raw
+	}
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO user_notifications (user_id, type, level, title, content, metadata, dedup_key)
+VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7)
+ON CONFLICT (user_id, dedup_key) WHERE dedup_key <> '' DO NOTHING`,
+		input.UserID, input.Type, input.Level, truncateString(input.Title, 200), input.Content, string(metadata), truncateString(input.DedupKey, 128))
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *userNotificationRepository) List(ctx context.Context, userID int64, unreadOnly bool, page, pageSize int) (*service.UserNotificationList, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil user notification repository")
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	where := "WHERE user_id = $1"
+	if unreadOnly {
+		where += " AND read_at IS NULL"
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_notifications "+where, userID).Scan(&total); err != nil {
+		return nil, err
+	}
+	unread := total
+	if !unreadOnly {
+		var err error
+		if unread, err = r.CountUnread(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT"+userNotificationSelectColumns+"\nFROM user_notifications "+where+`
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3`, userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.UserNotification, 0)
+	for rows.Next() {
+		item, err := scanUserNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &service.UserNotificationList{Items: items, Total: total, UnreadCount: unread, Page: page, PageSize: pageSize}, nil
+}
+
+func (r *userNotificationRepository) CountUnread(ctx context.Context, userID int64) (int, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil user notification repository")
+	}
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM user_notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *userNotificationRepository) MarkRead(ctx context.Context, userID, id int64) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil user notification repository")
+	}
+	// 已读的通知保持原 read_at，不视为错误
+	var marked int64
+	err := r.db.QueryRowContext(ctx, `
+UPDATE user_notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2
+RETURNING id`, id, userID).Scan(&marked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrUserNotificationNotFound
+	}
+	return err
+}
+
+func (r *userNotificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil user notification repository")
+	}
+	res, err := r.db.ExecContext(ctx, `
+UPDATE user_notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *userNotificationRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil user notification repository")
+	}
+	res, err := r.db.ExecContext(ctx, `DELETE FROM user_notifications WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *userNotificationRepository) ListAPIKeysExpiringBetween(ctx context.Context, from, to time.Time) ([]service.ExpiringAPIKey, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil user notification repository")
+	}
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, user_id, name, expires_at
+FROM api_keys
+WHERE deleted_at IS NULL
+  AND status = $1
+  AND expires_at IS NOT NULL
+  AND expires_at > $2
+  AND expires_at <= $3
+ORDER BY expires_at ASC, id ASC`, service.StatusAPIKeyActive, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	keys := make([]service.ExpiringAPIKey, 0)
+	for rows.Next() {
+		var key service.ExpiringAPIKey
+		if err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.ExpiresAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func scanUserNotification(row rowScanner) (*service.UserNotification, error) {
+	item := &service.UserNotification{}
+	var (
+		metadata []byte
+		readAt   sql.NullTime
+	)
+	if err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.Type,
+		&item.Level,
+		&item.Title,
+		&item.Content,
+		&metadata,
+		&readAt,
+		&item.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(metadata) > 0 && string(metadata) != "{}" {
+		item.Metadata = metadata
+	}
+	if readAt.Valid {
+		t := readAt.Time
+		item.ReadAt = &t
+	}
+	return item, nil
+}
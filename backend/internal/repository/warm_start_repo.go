@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type warmStartRepository struct {
+	db *sql.DB
+}
+
+func NewWarmStartRepository(db *sql.DB) service.WarmStartRepository {
+	return &warmStartRepository{db: db}
+}
+
+// ListRecentlyUsedAPIKeys 返回 since 之后使用过的有效 API Key，按最近使用时间倒序。
+func (r *warmStartRepository) ListRecentlyUsedAPIKeys(ctx context.Context, since time.Time, limit int) ([]string, error) {
+	if r.db == nil {
+		return nil, errors.New("nil sql db")
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT key FROM api_keys
+		WHERE deleted_at IS NULL
+			AND status = $1
+			AND last_used_at >= $2
+			AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY last_used_at DESC
+		LIMIT $3`, service.StatusAPIKeyActive, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query recently used api keys: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	keys := make([]string, 0, limit)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
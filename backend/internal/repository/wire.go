@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	entsql "entgo.io/ent/dialect/sql"
 	"github.com/Wei-Shaw/sub2api/ent"
@@ -15,7 +16,7 @@ import (
 
 // ProvideConcurrencyCache 创建并发控制缓存，从配置读取 TTL 参数
 // 性能优化：TTL 可配置，支持长时间运行的 LLM 请求场景
-func ProvideConcurrencyCache(rdb *redis.Client, cfg *config.Config) service.ConcurrencyCache {
+func ProvideConcurrencyCache(rdb redis.UniversalClient, cfg *config.Config) service.ConcurrencyCache {
 	waitTTLSeconds := int(cfg.Gateway.Scheduling.StickySessionWaitTimeout.Seconds())
 	if cfg.Gateway.Scheduling.FallbackWaitTimeout > cfg.Gateway.Scheduling.StickySessionWaitTimeout {
 		waitTTLSeconds = int(cfg.Gateway.Scheduling.FallbackWaitTimeout.Seconds())
@@ -23,6 +24,10 @@ func ProvideConcurrencyCache(rdb *redis.Client, cfg *config.Config) service.Conc
 	if waitTTLSeconds <= 0 {
 		waitTTLSeconds = cfg.Gateway.ConcurrencySlotTTLMinutes * 60
 	}
+	if cfg.Gateway.SlotLease.Enabled {
+		// 心跳租约：持有者定期续约，槽位按较短的租约 TTL 过期，宕机实例的槽位可尽快回收
+		return newConcurrencyCacheWithSlotTTL(rdb, cfg.Gateway.SlotLease.TTLSeconds, waitTTLSeconds)
+	}
 	return NewConcurrencyCache(rdb, cfg.Gateway.ConcurrencySlotTTLMinutes, waitTTLSeconds)
 }
 
@@ -40,7 +45,7 @@ func ProvidePricingRemoteClient(cfg *config.Config) service.PricingRemoteClient
 
 // ProvideSessionLimitCache 创建会话限制缓存
 // 用于 Anthropic OAuth/SetupToken 账号的并发会话数量控制
-func ProvideSessionLimitCache(rdb *redis.Client, cfg *config.Config) service.SessionLimitCache {
+func ProvideSessionLimitCache(rdb redis.UniversalClient, cfg *config.Config) service.SessionLimitCache {
 	defaultIdleTimeoutMinutes := 5 // 默认 5 分钟空闲超时
 	if cfg != nil && cfg.Gateway.SessionIdleTimeoutMinutes > 0 {
 		defaultIdleTimeoutMinutes = cfg.Gateway.SessionIdleTimeoutMinutes
@@ -49,7 +54,7 @@ func ProvideSessionLimitCache(rdb *redis.Client, cfg *config.Config) service.Ses
 }
 
 // ProvideSchedulerCache 创建调度快照缓存，并注入快照分块参数。
-func ProvideSchedulerCache(rdb *redis.Client, cfg *config.Config) service.SchedulerCache {
+func ProvideSchedulerCache(rdb redis.UniversalClient, cfg *config.Config) service.SchedulerCache {
 	mgetChunkSize := defaultSchedulerSnapshotMGetChunkSize
 	writeChunkSize := defaultSchedulerSnapshotWriteChunkSize
 	if cfg != nil {
@@ -63,6 +68,15 @@ func ProvideSchedulerCache(rdb *redis.Client, cfg *config.Config) service.Schedu
 	return newSchedulerCacheWithChunkSizes(rdb, mgetChunkSize, writeChunkSize)
 }
 
+// ProvideSettingRepository 创建系统设置仓储；启用数据库降级模式时包装过期值兜底。
+func ProvideSettingRepository(client *ent.Client, cfg *config.Config) service.SettingRepository {
+	repo := NewSettingRepository(client)
+	if cfg == nil || !cfg.Database.Degraded.Enabled {
+		return repo
+	}
+	return service.NewStaleFallbackSettingRepository(repo, time.Duration(cfg.Database.Degraded.StaleTTLSeconds)*time.Second)
+}
+
 // ProviderSet is the Wire provider set for all repositories
 var ProviderSet = wire.NewSet(
 	NewUserRepository,
@@ -84,9 +98,26 @@ var ProviderSet = wire.NewSet(
 	NewIdempotencyRepository,
 	NewUsageCleanupRepository,
 	NewDashboardAggregationRepository,
-	NewSettingRepository,
+	ProvideSettingRepository,
 	NewOpsRepository,
 	NewAuditLogRepository,
+	NewConversationTranscriptRepository,
+	NewUpstreamStreamCaptureRepository,
+	NewUsageArchiveRepository,
+	NewTaskHistoryRepository,
+	NewBillingAdjustmentRepository,
+	NewAccountMetadataRepository,
+	NewAccountCredentialSourceRepository,
+	NewCredentialSecretFetcher,
+	NewSettingChangeRequestRepository,
+	NewUserNotificationRepository,
+	NewModelAliasRepository,
+	NewAccountCostAnomalyRepository,
+	NewAccountSmokeTestRepository,
+	NewAccountSnapshotRepository,
+	NewGroupBudgetRepository,
+	NewAdminListVersionRepository,
+	NewAccountAttributionRepository,
 	NewUserSubscriptionRepository,
 	NewUserAttributeDefinitionRepository,
 	NewUserAttributeValueRepository,
@@ -106,6 +137,7 @@ var ProviderSet = wire.NewSet(
 	NewBillingCache,
 	NewAPIKeyCache,
 	NewTempUnschedCache,
+	NewContentPolicyCooldownCache,
 	NewTimeoutCounterCache,
 	NewOpenAI403CounterCache,
 	NewInternal500CounterCache,
@@ -119,7 +151,7 @@ var ProviderSet = wire.NewSet(
 	NewIdentityCache,
 	NewRedeemCache,
 	NewUpdateCache,
-	NewGeminiTokenCache,
+	ProvideGeminiTokenCache,
 	NewImageTaskStore,
 	NewBatchImageQueue,
 	NewBatchImageDownloadLimiter,
@@ -128,6 +160,10 @@ var ProviderSet = wire.NewSet(
 	NewSchedulerOutboxRepository,
 	NewAuthCacheInvalidationOutboxRepository,
 	NewProxyLatencyCache,
+	NewSSEResumeCache,
+	NewUsageRecordDeadLetterCache,
+	NewStartupDiagnosticsRepository,
+	NewWarmStartRepository,
 	NewTotpCache,
 	NewRefreshTokenCache,
 	NewErrorPassthroughCache,
@@ -151,7 +187,8 @@ var ProviderSet = wire.NewSet(
 	NewProxyExitInfoProber,
 	NewClaudeUsageFetcher,
 	NewClaudeOAuthClient,
-	NewHTTPUpstream,
+	ProvideHTTPUpstream,
+	NewProxyHostLatencyProber,
 	NewOpenAIOAuthClient,
 	NewGrokOAuthClient,
 	NewGeminiOAuthClient,
@@ -161,6 +198,9 @@ var ProviderSet = wire.NewSet(
 	ProvideEnt,
 	ProvideSQLDB,
 	ProvideRedis,
+	ProvideRedisReadReplica,
+	ProvideDBReadReplica,
+	NewRedisHealthProbe,
 )
 
 // ProvideEnt 为依赖注入提供 Ent 客户端。
@@ -218,7 +258,7 @@ func ProvideSQLDB(client *ent.Client) (*sql.DB, error) {
 //   - 实时统计数据
 //
 // 依赖：config.Config
-// 提供：*redis.Client
-func ProvideRedis(cfg *config.Config) *redis.Client {
+// 提供：redis.UniversalClient
+func ProvideRedis(cfg *config.Config) redis.UniversalClient {
 	return InitRedis(cfg)
 }
@@ -26,7 +26,7 @@ type activeConfigSnapshot struct {
 type ConfigManager struct {
 	db        *sql.DB
 	settings  service.SettingRepository
-	redis     *redis.Client
+	redis     redis.UniversalClient
 	encryptor SecretEncryptor
 	clock     Clock
 
@@ -53,7 +53,7 @@ type ConfigManager struct {
 	wg          sync.WaitGroup
 }
 
-func NewConfigManager(db *sql.DB, settings service.SettingRepository, redisClient *redis.Client, encryptor service.SecretEncryptor) *ConfigManager {
+func NewConfigManager(db *sql.DB, settings service.SettingRepository, redisClient redis.UniversalClient, encryptor service.SecretEncryptor) *ConfigManager {
 	return &ConfigManager{db: db, settings: settings, redis: redisClient, encryptor: encryptor, clock: realClock{}}
 }
 
@@ -236,6 +236,7 @@ func TestAPIContracts(t *testing.T) {
 					"last_used_at": null,
 					"last_used_ip": null,
 					"current_concurrency": 0,
+					"data_residency": "",
 					"quota": 0,
 					"quota_used": 0,
 					"rate_limit_5h": 0,
@@ -248,6 +249,13 @@ func TestAPIContracts(t *testing.T) {
 					"window_1d_start": null,
 					"window_7d_start": null,
 					"expires_at": null,
+					"transcript_enabled": false,
+					"annotations_enabled": false,
+					"stream_capture_enabled": false,
+					"response_language": "",
+					"max_priority": "",
+					"strict_validation": false,
+					"max_request_cost": 0,
 					"created_at": "2025-01-02T03:04:05Z",
 					"updated_at": "2025-01-02T03:04:05Z"
 				}
@@ -287,6 +295,7 @@ func TestAPIContracts(t *testing.T) {
 							"last_used_at": null,
 							"last_used_ip": null,
 							"current_concurrency": 0,
+							"data_residency": "",
 							"quota": 0,
 							"quota_used": 0,
 							"rate_limit_5h": 0,
@@ -299,6 +308,13 @@ func TestAPIContracts(t *testing.T) {
 							"window_1d_start": null,
 							"window_7d_start": null,
 							"expires_at": null,
+							"transcript_enabled": false,
+							"annotations_enabled": false,
+							"stream_capture_enabled": false,
+							"response_language": "",
+							"max_priority": "",
+							"strict_validation": false,
+							"max_request_cost": 0,
 							"created_at": "2025-01-02T03:04:05Z",
 							"updated_at": "2025-01-02T03:04:05Z"
 						}
@@ -382,6 +398,14 @@ func TestAPIContracts(t *testing.T) {
 						"require_oauth_only": false,
 						"require_privacy_set": false,
 						"rpm_limit": 0,
+						"allow_computer_use_tool": false,
+						"allow_code_execution_tool": false,
+						"allow_web_search_tool": false,
+						"system_prompt_profile": "",
+						"response_language": "",
+						"attribution_mode": "",
+						"attribution_text": "",
+						"max_request_cost": 0,
 						"created_at": "2025-01-02T03:04:05Z",
 						"updated_at": "2025-01-02T03:04:05Z"
 					}
@@ -587,8 +611,10 @@ func TestAPIContracts(t *testing.T) {
 							"cache_read_tokens": 2,
 							"cache_creation_5m_tokens": 0,
 							"cache_creation_1h_tokens": 0,
+							"reasoning_tokens": 0,
 							"input_cost": 0,
 							"output_cost": 0,
+							"reasoning_cost": 0,
 							"cache_creation_cost": 0,
 							"cache_read_cost": 0,
 						"total_cost": 0.5,
@@ -1758,6 +1784,10 @@ func (s *stubAccountRepo) CreateWithAccountGroups(ctx context.Context, account *
 	return errors.New("not implemented")
 }
 
+func (s *stubAccountRepo) UpdateAccountGroupMembership(ctx context.Context, accountID, groupID int64, update service.AccountGroupMembershipUpdate) (*service.AccountGroup, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (s *stubAccountRepo) GetByID(ctx context.Context, id int64) (*service.Account, error) {
 	return nil, service.ErrAccountNotFound
 }
@@ -2403,6 +2433,10 @@ func (r *stubApiKeyRepo) ListKeysByGroupID(ctx context.Context, groupID int64) (
 	return nil, errors.New("not implemented")
 }
 
+func (r *stubApiKeyRepo) ListByParentKeyID(ctx context.Context, parentKeyID int64) ([]service.APIKey, error) {
+	return nil, nil
+}
+
 func (r *stubApiKeyRepo) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	return 0, errors.New("not implemented")
 }
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/accesslog"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog 将每个请求写入独立的 Common / Combined 格式访问日志（log.access），
+// 附带 request ID、API Key ID、账号 ID 与上游状态码。未启用时为空操作。
+// 需挂在 RequestLogger 之后以取得 request ID。
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !accesslog.Enabled() {
+			c.Next()
+			return
+		}
+		startTime := time.Now()
+		path := c.Request.URL.Path
+		rawQuery := c.Request.URL.RawQuery
+
+		c.Next()
+
+		// 与结构化访问日志一致，跳过健康检查等高频探针路径
+		if path == "/health" || path == "/healthz" || path == "/setup/status" {
+			return
+		}
+
+		entry := &accesslog.Entry{
+			RemoteAddr: ip.GetClientIP(c),
+			Time:       startTime,
+			Method:     c.Request.Method,
+			Path:       path,
+			RawQuery:   rawQuery,
+			Proto:      c.Request.Proto,
+			Status:     c.Writer.Status(),
+			Bytes:      c.Writer.Size(),
+			Referer:    c.Request.Referer(),
+			UserAgent:  c.Request.UserAgent(),
+			Latency:    time.Since(startTime),
+		}
+		entry.RequestID, _ = c.Request.Context().Value(ctxkey.RequestID).(string)
+		entry.AccountID, _ = c.Request.Context().Value(ctxkey.AccountID).(int64)
+		if apiKey, ok := GetAPIKeyFromContext(c); ok && apiKey != nil {
+			entry.APIKeyID = apiKey.ID
+			entry.User = strconv.FormatInt(apiKey.UserID, 10)
+		} else if subject, ok := GetAuthSubjectFromContext(c); ok && subject.UserID > 0 {
+			entry.User = strconv.FormatInt(subject.UserID, 10)
+		}
+		// 上游错误码由网关服务记录在 ops 上下文；已命中账号且未记录错误的成功响应按透传状态码记录
+		if v, ok := c.Get(service.OpsUpstreamStatusCodeKey); ok {
+			if code, ok := v.(int); ok {
+				entry.UpstreamStatus = code
+			}
+		} else if entry.AccountID > 0 && entry.Status < 400 {
+			entry.UpstreamStatus = entry.Status
+		}
+		accesslog.Log(entry)
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/accesslog"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLog_WritesCombinedLineWithGatewayFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := accesslog.Init(accesslog.Options{Format: accesslog.FormatCombined, FilePath: path}); err != nil {
+		t.Fatalf("init access log: %v", err)
+	}
+	t.Cleanup(accesslog.Close)
+
+	r := gin.New()
+	r.Use(RequestLogger())
+	r.Use(AccessLog())
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: 7, UserID: 3})
+		ctx := context.WithValue(c.Request.Context(), ctxkey.AccountID, int64(101))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+	r.POST("/v1/messages", func(c *gin.Context) {
+		service.SetOpsUpstreamError(c, http.StatusTooManyRequests, "rate limited", "")
+		c.String(http.StatusServiceUnavailable, "busy")
+	})
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader("{}"))
+	req.Header.Set(requestIDHeader, "req-access-1")
+	req.Header.Set("User-Agent", "claude-cli/2.0")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	accesslog.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read access log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 access log line (health probe skipped), got %d: %q", len(lines), string(data))
+	}
+	line := lines[0]
+	for _, want := range []string{
+		` - 3 [`,
+		`"POST /v1/messages HTTP/1.1" 503 4 "-" "claude-cli/2.0"`,
+		`"req-access-1" 7 101 429 `,
+	} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("access log line %q missing %q", line, want)
+		}
+	}
+}
+
+func TestAccessLog_DisabledIsNoop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	accesslog.Close()
+
+	r := gin.New()
+	r.Use(AccessLog())
+	r.GET("/api/test", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status=%d", w.Code)
+	}
+}
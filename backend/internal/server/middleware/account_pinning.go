@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// applyAccountPinning 处理 X-Sub2API-Account-ID 调试头：仅允许管理员用户的 Key 使用，
+// 校验通过后把账号 ID 写入请求 context（调度层据此跳过选择），并记录每一条固定请求。
+// 该头在此处即被移除，不会透传到上游。返回 false 表示已中止请求。
+func applyAccountPinning(c *gin.Context, apiKey *service.APIKey, abort func(status int, code, message string)) bool {
+	raw := strings.TrimSpace(c.GetHeader(service.AccountPinningHeader))
+	if raw == "" {
+		return true
+	}
+	c.Request.Header.Del(service.AccountPinningHeader)
+
+	if apiKey == nil || apiKey.User == nil || !apiKey.User.IsAdmin() {
+		abort(http.StatusForbidden, "ACCOUNT_PINNING_FORBIDDEN", service.AccountPinningHeader+" is only allowed for admin API keys")
+		return false
+	}
+	accountID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || accountID <= 0 {
+		abort(http.StatusBadRequest, "INVALID_ACCOUNT_PIN", service.AccountPinningHeader+" must be a positive account ID")
+		return false
+	}
+
+	ctx := service.WithPinnedAccountID(c.Request.Context(), accountID)
+	c.Request = c.Request.WithContext(ctx)
+	logger.FromContext(ctx).Info("gateway.account_pinned",
+		zap.Int64("pinned_account_id", accountID),
+		zap.Int64("user_id", apiKey.User.ID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.String("method", c.Request.Method),
+		zap.String("path", c.Request.URL.Path),
+	)
+	return true
+}
@@ -0,0 +1,56 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func runAccountPinning(t *testing.T, apiKey *service.APIKey, header string) (*httptest.ResponseRecorder, int64) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	var pinned int64
+	router := gin.New()
+	router.POST("/v1/messages", func(c *gin.Context) {
+		if !applyAccountPinning(c, apiKey, func(status int, code, message string) { AbortWithError(c, status, code, message) }) {
+			return
+		}
+		require.Empty(t, c.GetHeader(service.AccountPinningHeader), "pinning header must not reach upstream forwarding")
+		pinned = service.PinnedAccountIDFromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	if header != "" {
+		req.Header.Set(service.AccountPinningHeader, header)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, pinned
+}
+
+func TestApplyAccountPinning(t *testing.T) {
+	adminKey := &service.APIKey{ID: 1, User: &service.User{ID: 10, Role: service.RoleAdmin}}
+	userKey := &service.APIKey{ID: 2, User: &service.User{ID: 11, Role: service.RoleUser}}
+
+	w, pinned := runAccountPinning(t, userKey, "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Zero(t, pinned)
+
+	w, pinned = runAccountPinning(t, adminKey, "42")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, int64(42), pinned)
+
+	w, _ = runAccountPinning(t, userKey, "42")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "ACCOUNT_PINNING_FORBIDDEN")
+
+	w, _ = runAccountPinning(t, adminKey, "abc")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "INVALID_ACCOUNT_PIN")
+}
@@ -128,6 +128,12 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			AbortWithError(c, 401, "API_KEY_DISABLED", "API key is disabled")
 			return
 		}
+		// 委托子 Key：父 Key 已删除或停用时子 Key 一并失效
+		if !apiKey.CheckParentKeyUsable() {
+			MarkIngressRejected(c, IngressRejectAPIKeyDisabled)
+			AbortWithError(c, 401, "API_KEY_DISABLED", "API key is disabled")
+			return
+		}
 
 		// 检查 IP 限制（白名单/黑名单）
 		// 注意：错误信息故意模糊，避免暴露具体的 IP 限制机制
@@ -165,11 +171,23 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 		}
 		ctx := context.WithValue(c.Request.Context(), ctxkey.UserID, apiKey.User.ID)
 		c.Request = c.Request.WithContext(ctx)
+		applyDataResidency(c, apiKey)
+		if !applyAccountPinning(c, apiKey, func(status int, code, message string) { AbortWithError(c, status, code, message) }) {
+			return
+		}
+		if !applyBetaFeatures(c, apiKey, func(status int, code, message string) { AbortWithError(c, status, code, message) }) {
+			return
+		}
+		if abortIfSubKeyModelNotAllowed(c, apiKey, func(status int, code, message string) { AbortWithError(c, status, code, message) }) {
+			return
+		}
 		billingInfoRequest := c.Request.URL.Path == "/v1/sub2api/billing"
 		// Async image task polling only reads data that already belongs to the
 		// authenticated key and must remain available after the completed
 		// generation consumes the key's remaining balance.
-		skipBilling := c.Request.URL.Path == "/v1/usage" || billingInfoRequest || isAsyncImageTaskRead(c.Request.Method, c.Request.URL.Path)
+		// Sub-key management must stay available so owners can revoke sub-keys
+		// after the parent key's quota is exhausted.
+		skipBilling := c.Request.URL.Path == "/v1/usage" || billingInfoRequest || isSubKeyManagementRequest(c.Request.URL.Path) || isAsyncImageTaskRead(c.Request.Method, c.Request.URL.Path)
 
 		// ── 4. SimpleMode → early return ─────────────────────────────
 
@@ -233,6 +251,15 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 				abortWithAPIKeyQuotaError(c)
 				return
 			}
+			// 委托子 Key 同时受父 Key 的过期时间与额度约束
+			if err := apiKey.CheckParentQuotaAndExpiry(); err != nil {
+				if errors.Is(err, service.ErrAPIKeyExpired) {
+					AbortWithError(c, 403, "API_KEY_EXPIRED", "API key 已过期")
+				} else {
+					abortWithAPIKeyQuotaError(c)
+				}
+				return
+			}
 
 			// 订阅模式：验证订阅限额
 			if subscription != nil {
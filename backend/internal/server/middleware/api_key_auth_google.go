@@ -88,6 +88,11 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 401, "API key is disabled")
 			return
 		}
+		if !apiKey.CheckParentKeyUsable() {
+			MarkIngressRejected(c, IngressRejectAPIKeyDisabled)
+			abortWithGoogleError(c, 401, "API key is disabled")
+			return
+		}
 
 		// 检查 IP 限制（白名单/黑名单）。与主中间件保持一致，避免 Gemini 端点绕过 Key 的 IP ACL。
 		if len(apiKey.IPWhitelist) > 0 || len(apiKey.IPBlacklist) > 0 {
@@ -130,6 +135,16 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 403, "API Key 所属专属分组不再允许当前用户使用")
 			return
 		}
+		applyDataResidency(c, apiKey)
+		if !applyAccountPinning(c, apiKey, func(status int, _, message string) { abortWithGoogleError(c, status, message) }) {
+			return
+		}
+		if !applyBetaFeatures(c, apiKey, func(status int, _, message string) { abortWithGoogleError(c, status, message) }) {
+			return
+		}
+		if abortIfSubKeyModelNotAllowed(c, apiKey, func(status int, _, message string) { abortWithGoogleError(c, status, message) }) {
+			return
+		}
 
 		// 简易模式：跳过余额和订阅检查
 		if cfg.RunMode == config.RunModeSimple {
@@ -164,6 +179,14 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 429, "API key 额度已用完")
 			return
 		}
+		if err := apiKey.CheckParentQuotaAndExpiry(); err != nil {
+			if errors.Is(err, service.ErrAPIKeyExpired) {
+				abortWithGoogleError(c, 403, "API key 已过期")
+			} else {
+				abortWithGoogleError(c, 429, "API key 额度已用完")
+			}
+			return
+		}
 
 		isSubscriptionType := apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
 		if isSubscriptionType && subscriptionService != nil {
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// isSubKeyManagementRequest 委托子 Key 管理端点（/v1/sub2api/keys）
+func isSubKeyManagementRequest(path string) bool {
+	return path == "/v1/sub2api/keys" || strings.HasPrefix(path, "/v1/sub2api/keys/")
+}
+
+// abortIfSubKeyModelNotAllowed 委托子 Key 配置了模型允许列表时，按请求体 / 路径中的模型拦截。
+// 未能解析出模型的请求放行，由各 handler 按原有逻辑校验（如 "model is required"）。
+func abortIfSubKeyModelNotAllowed(c *gin.Context, apiKey *service.APIKey, abort func(status int, code, message string)) bool {
+	if apiKey == nil || len(apiKey.AllowedModels) == 0 {
+		return false
+	}
+	model, err := subKeyRequestedModel(c)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			abort(http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE", "Request body too large")
+		} else {
+			abort(http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body")
+		}
+		return true
+	}
+	if model == "" || apiKey.IsModelAllowed(model) {
+		return false
+	}
+	service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
+	abort(http.StatusForbidden, "MODEL_NOT_ALLOWED", "Model "+model+" is not allowed for this API key")
+	return true
+}
+
+// subKeyRequestedModel 解析请求的模型：Gemini 风格路径（/models/{model}:action）优先，
+// 其次为 JSON 或 multipart 请求体中的 model 字段。读取后的请求体会回填供后续处理使用。
+func subKeyRequestedModel(c *gin.Context) (string, error) {
+	if model := modelFromGeminiPath(c.Request.URL.Path); model != "" {
+		return model, nil
+	}
+	if c.Request.Method != http.MethodPost || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return "", nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	policyplugin.SetRequestBody(c.Request, body)
+
+	mediaType, params, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType == "multipart/form-data" && params["boundary"] != "" {
+		return modelFromMultipart(body, params["boundary"]), nil
+	}
+	return strings.TrimSpace(gjson.GetBytes(body, "model").String()), nil
+}
+
+func modelFromGeminiPath(path string) string {
+	idx := strings.LastIndex(path, "/models/")
+	if idx < 0 {
+		return ""
+	}
+	rest := path[idx+len("/models/"):]
+	colon := strings.Index(rest, ":")
+	if colon <= 0 {
+		return ""
+	}
+	return strings.TrimSpace(rest[:colon])
+}
+
+func modelFromMultipart(body []byte, boundary string) string {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return ""
+		}
+		if part.FormName() != "model" {
+			_ = part.Close()
+			continue
+		}
+		value, err := io.ReadAll(io.LimitReader(part, 256))
+		_ = part.Close()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(value))
+	}
+}
@@ -0,0 +1,183 @@
+//go:build unit
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newSubKeyAuthTestRouter(t *testing.T, apiKey *service.APIKey, cfg *config.Config) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			if key != apiKey.Key {
+				return nil, service.ErrAPIKeyNotFound
+			}
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	}
+	router.POST("/v1/messages", echo)
+	router.POST("/v1/images/edits", echo)
+	router.POST("/v1beta/models/*modelAction", echo)
+	router.GET("/v1/sub2api/keys", echo)
+	return router
+}
+
+func newSubKeyForAuthTest(allowedModels ...string) *service.APIKey {
+	parentKeyID := int64(1)
+	return &service.APIKey{
+		ID:            2,
+		UserID:        7,
+		Key:           "sub-key",
+		Status:        service.StatusActive,
+		ParentKeyID:   &parentKeyID,
+		Parent:        &service.APIKey{ID: 1, Status: service.StatusActive},
+		AllowedModels: allowedModels,
+		User:          &service.User{ID: 7, Role: service.RoleUser, Status: service.StatusActive, Balance: 10, Concurrency: 3},
+	}
+}
+
+func TestAPIKeyAuthSubKeyRejectsDisallowedModel(t *testing.T) {
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	router := newSubKeyAuthTestRouter(t, newSubKeyForAuthTest("claude-sonnet-*"), cfg)
+
+	body := `{"model":"claude-sonnet-4-5","messages":[]}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	req.Header.Set("x-api-key", "sub-key")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, body, w.Body.String(), "request body is restored after the model check")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4-1"}`))
+	req.Header.Set("x-api-key", "sub-key")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "MODEL_NOT_ALLOWED")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", strings.NewReader(`{}`))
+	req.Header.Set("x-api-key", "sub-key")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAPIKeyAuthSubKeyChecksBridgedBody(t *testing.T) {
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	apiKey := newSubKeyForAuthTest("claude-sonnet-*")
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// 模拟 /ws/v1 桥接：桥接挂在认证之前，GET 握手转换为以首条消息为请求体的 POST 请求
+	router.Use(func(c *gin.Context) {
+		body := c.GetHeader("X-Test-Bridged-Body")
+		c.Request.Method = http.MethodPost
+		c.Request.Body = io.NopCloser(strings.NewReader(body))
+		c.Next()
+	})
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+	router.GET("/v1/messages", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(body string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/messages", nil)
+		req.Header.Set("x-api-key", "sub-key")
+		req.Header.Set("X-Test-Bridged-Body", body)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, send(`{"model":"claude-sonnet-4-5"}`))
+	require.Equal(t, http.StatusForbidden, send(`{"model":"claude-opus-4-1"}`))
+}
+
+func TestAPIKeyAuthSubKeyChecksMultipartModel(t *testing.T) {
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	router := newSubKeyAuthTestRouter(t, newSubKeyForAuthTest("gpt-image-1"), cfg)
+
+	send := func(model string) int {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		require.NoError(t, writer.WriteField("model", model))
+		require.NoError(t, writer.WriteField("prompt", "a cat"))
+		require.NoError(t, writer.Close())
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/images/edits", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("x-api-key", "sub-key")
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, send("gpt-image-1"))
+	require.Equal(t, http.StatusForbidden, send("dall-e-3"))
+}
+
+func TestAPIKeyAuthSubKeyFollowsParentState(t *testing.T) {
+	cfg := &config.Config{}
+
+	subKey := newSubKeyForAuthTest()
+	subKey.Parent = nil
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`))
+	req.Header.Set("x-api-key", "sub-key")
+	newSubKeyAuthTestRouter(t, subKey, cfg).ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), "API_KEY_DISABLED")
+
+	subKey = newSubKeyForAuthTest()
+	subKey.Parent.Quota = 10
+	subKey.Parent.QuotaUsed = 10
+	router := newSubKeyAuthTestRouter(t, subKey, cfg)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`))
+	req.Header.Set("x-api-key", "sub-key")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Contains(t, w.Body.String(), "API_KEY_QUOTA_EXHAUSTED")
+}
+
+func TestAPIKeyAuthSubKeyManagementSkipsBilling(t *testing.T) {
+	parent := &service.APIKey{
+		ID:        1,
+		UserID:    7,
+		Key:       "sub-key",
+		Status:    service.StatusAPIKeyQuotaExhausted,
+		Quota:     10,
+		QuotaUsed: 10,
+		User:      &service.User{ID: 7, Role: service.RoleUser, Status: service.StatusActive, Concurrency: 3},
+	}
+	router := newSubKeyAuthTestRouter(t, parent, &config.Config{})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/sub2api/keys", nil)
+	req.Header.Set("x-api-key", "sub-key")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, "owners can manage sub-keys after the parent quota is exhausted")
+}
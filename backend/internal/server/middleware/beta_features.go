@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// applyBetaFeatures 处理 X-Sub2API-Beta 请求头：逐项校验功能名与 Key 授权，
+// 通过后把启用的功能写入请求 context（下游按功能分支，使用记录落库）。
+// 该头在此处即被移除，不会透传到上游。返回 false 表示已中止请求。
+func applyBetaFeatures(c *gin.Context, apiKey *service.APIKey, abort func(status int, code, message string)) bool {
+	raw := strings.TrimSpace(c.GetHeader(service.BetaFeaturesHeader))
+	if raw == "" {
+		return true
+	}
+	c.Request.Header.Del(service.BetaFeaturesHeader)
+
+	features, err := service.ResolveBetaFeatures(raw, apiKey)
+	if err != nil {
+		abort(infraerrors.Code(err), infraerrors.Reason(err), infraerrors.Message(err))
+		return false
+	}
+	c.Request = c.Request.WithContext(service.WithBetaFeatures(c.Request.Context(), features))
+	return true
+}
@@ -0,0 +1,308 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+)
+
+const (
+	compressionEncodingGzip   = "gzip"
+	compressionEncodingBrotli = "br"
+
+	// brotli 5 级在压缩率与 CPU 之间较均衡，适合动态 JSON
+	compressionBrotliLevel = 5
+)
+
+var (
+	gzipWriterPool = sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}}
+	brotliWriterPool = sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, compressionBrotliLevel)
+	}}
+)
+
+type compressionWriteMode int
+
+const (
+	compressionModeBuffering compressionWriteMode = iota
+	compressionModePassthrough
+	compressionModeCompressing
+)
+
+// compressionResponseWriter 先缓冲响应体直到达到阈值再决定是否压缩：
+// 未达阈值按原样输出；处理器主动 Flush（流式）时立即切为直出，保证流式响应不被缓冲。
+type compressionResponseWriter struct {
+	gin.ResponseWriter
+	encoding     string
+	minSize      int
+	contentTypes map[string]struct{}
+
+	mode    compressionWriteMode
+	checked bool
+	status  int
+	buf     []byte
+	wrote   bool
+	encoder io.WriteCloser
+}
+
+func (w *compressionResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressionResponseWriter) WriteHeader(code int) {
+	if w.mode != compressionModeBuffering {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 && !w.wrote {
+		w.status = code
+	}
+}
+
+func (w *compressionResponseWriter) WriteHeaderNow() {
+	if w.mode == compressionModeBuffering {
+		w.passthrough()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressionResponseWriter) Status() int {
+	if w.mode == compressionModeBuffering && w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressionResponseWriter) Written() bool {
+	if w.mode == compressionModeBuffering {
+		return w.wrote
+	}
+	return w.ResponseWriter.Written()
+}
+
+func (w *compressionResponseWriter) Size() int {
+	if w.mode == compressionModeBuffering {
+		if !w.wrote {
+			return -1
+		}
+		return len(w.buf)
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *compressionResponseWriter) Write(b []byte) (int, error) {
+	switch w.mode {
+	case compressionModePassthrough:
+		return w.ResponseWriter.Write(b)
+	case compressionModeCompressing:
+		return w.encoder.Write(b)
+	}
+
+	if !w.checked {
+		w.checked = true
+		if !w.eligible() {
+			w.passthrough()
+			return w.ResponseWriter.Write(b)
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	w.wrote = true
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressionResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressionResponseWriter) Flush() {
+	switch w.mode {
+	case compressionModeBuffering:
+		w.passthrough()
+	case compressionModeCompressing:
+		if f, ok := w.encoder.(interface{ Flush() error }); ok {
+			_ = f.Flush()
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressionResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.mode == compressionModeBuffering {
+		w.mode = compressionModePassthrough
+	}
+	return w.ResponseWriter.Hijack()
+}
+
+// eligible 在首次写入时根据响应头判断是否可以压缩。
+func (w *compressionResponseWriter) eligible() bool {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	_, ok := w.contentTypes[strings.ToLower(mediaType)]
+	return ok
+}
+
+func (w *compressionResponseWriter) passthrough() {
+	w.mode = compressionModePassthrough
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if len(w.buf) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf)
+	}
+	w.buf = nil
+}
+
+func (w *compressionResponseWriter) startCompression() error {
+	w.mode = compressionModeCompressing
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", w.encoding)
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	switch w.encoding {
+	case compressionEncodingBrotli:
+		bw := brotliWriterPool.Get().(*brotli.Writer)
+		bw.Reset(w.ResponseWriter)
+		w.encoder = bw
+	default:
+		gw := gzipWriterPool.Get().(*gzip.Writer)
+		gw.Reset(w.ResponseWriter)
+		w.encoder = gw
+	}
+	buf := w.buf
+	w.buf = nil
+	_, err := w.encoder.Write(buf)
+	return err
+}
+
+// finish 处理结束：未达阈值的响应原样输出，压缩中的响应写出尾部并归还编码器。
+func (w *compressionResponseWriter) finish() {
+	switch w.mode {
+	case compressionModeBuffering:
+		w.passthrough()
+	case compressionModeCompressing:
+		_ = w.encoder.Close()
+		switch enc := w.encoder.(type) {
+		case *gzip.Writer:
+			enc.Reset(io.Discard)
+			gzipWriterPool.Put(enc)
+		case *brotli.Writer:
+			enc.Reset(io.Discard)
+			brotliWriterPool.Put(enc)
+		}
+		w.encoder = nil
+	}
+}
+
+// ResponseCompression 按 Accept-Encoding 协商 br/gzip 压缩非流式响应。
+// SSE（text/event-stream 不在白名单）、WebSocket 升级、HEAD 请求和主动 Flush 的响应原样直出。
+func ResponseCompression(cfg config.ServerCompressionConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	contentTypes := make(map[string]struct{}, len(cfg.ContentTypes))
+	for _, ct := range cfg.ContentTypes {
+		if ct = strings.ToLower(strings.TrimSpace(ct)); ct != "" {
+			contentTypes[ct] = struct{}{}
+		}
+	}
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		encoding := negotiateCompressionEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &compressionResponseWriter{
+			ResponseWriter: original,
+			encoding:       encoding,
+			minSize:        cfg.MinSizeBytes,
+			contentTypes:   contentTypes,
+		}
+		c.Writer = writer
+		c.Next()
+		writer.finish()
+		if c.Writer == writer {
+			c.Writer = original
+		}
+	}
+}
+
+// negotiateCompressionEncoding 从 Accept-Encoding 中选择编码，br 优先于 gzip；q=0 表示拒绝。
+func negotiateCompressionEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+	accepted := map[string]bool{}
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(k), "q") {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q > 0
+			continue
+		}
+		accepted[name] = q > 0
+	}
+	for _, enc := range []string{compressionEncodingBrotli, compressionEncodingGzip} {
+		if ok, listed := accepted[enc]; listed {
+			if ok {
+				return enc
+			}
+			continue
+		}
+		if wildcard {
+			return enc
+		}
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressionTestRouter(register func(r *gin.Engine)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ResponseCompression(config.ServerCompressionConfig{
+		Enabled:      true,
+		MinSizeBytes: 64,
+		ContentTypes: []string{"application/json", "text/plain"},
+	}))
+	register(r)
+	return r
+}
+
+func doCompressionRequest(r *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponseCompression_GzipLargeJSON(t *testing.T) {
+	payload := strings.Repeat("x", 500)
+	r := newCompressionTestRouter(func(r *gin.Engine) {
+		r.GET("/list", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{"data": payload})
+		})
+	})
+
+	w := doCompressionRequest(r, "/list", "gzip, deflate")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	require.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+
+	zr, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data":"`+payload+`"}`, string(body))
+}
+
+func TestResponseCompression_PrefersBrotli(t *testing.T) {
+	payload := strings.Repeat("y", 500)
+	r := newCompressionTestRouter(func(r *gin.Engine) {
+		r.GET("/text", func(c *gin.Context) {
+			c.String(http.StatusOK, payload)
+		})
+	})
+
+	w := doCompressionRequest(r, "/text", "gzip;q=0.8, br")
+	require.Equal(t, "br", w.Header().Get("Content-Encoding"))
+	body, err := io.ReadAll(brotli.NewReader(w.Body))
+	require.NoError(t, err)
+	require.Equal(t, payload, string(body))
+}
+
+func TestResponseCompression_SkipsSmallUnlistedAndUnaccepted(t *testing.T) {
+	r := newCompressionTestRouter(func(r *gin.Engine) {
+		r.GET("/small", func(c *gin.Context) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false})
+		})
+		r.GET("/binary", func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/octet-stream", bytes.Repeat([]byte{1}, 500))
+		})
+		r.GET("/empty", func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+	})
+
+	w := doCompressionRequest(r, "/small", "gzip")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+	require.JSONEq(t, `{"ok":false}`, w.Body.String())
+
+	w = doCompressionRequest(r, "/binary", "gzip")
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+	require.Equal(t, 500, w.Body.Len())
+
+	w = doCompressionRequest(r, "/empty", "gzip")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+
+	w = doCompressionRequest(r, "/binary", "")
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestResponseCompression_FlushSwitchesToPassthrough(t *testing.T) {
+	r := newCompressionTestRouter(func(r *gin.Engine) {
+		r.GET("/stream", func(c *gin.Context) {
+			// 即使 Content-Type 在白名单内，主动 Flush 也视为流式响应，不压缩不缓冲
+			c.Header("Content-Type", "text/plain")
+			_, _ = c.Writer.WriteString("chunk-1\n")
+			c.Writer.Flush()
+			_, _ = c.Writer.WriteString(strings.Repeat("z", 200))
+		})
+		r.GET("/sse", func(c *gin.Context) {
+			c.Header("Content-Type", "text/event-stream")
+			_, _ = c.Writer.WriteString("data: " + strings.Repeat("s", 200) + "\n\n")
+		})
+	})
+
+	w := doCompressionRequest(r, "/stream", "gzip")
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+	require.True(t, w.Flushed)
+	require.Equal(t, "chunk-1\n"+strings.Repeat("z", 200), w.Body.String())
+
+	w = doCompressionRequest(r, "/sse", "gzip")
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+	require.True(t, strings.HasPrefix(w.Body.String(), "data: "))
+}
+
+func TestNegotiateCompressionEncoding(t *testing.T) {
+	require.Equal(t, "br", negotiateCompressionEncoding("gzip, br"))
+	require.Equal(t, "gzip", negotiateCompressionEncoding("gzip, br;q=0"))
+	require.Equal(t, "br", negotiateCompressionEncoding("*"))
+	require.Equal(t, "gzip", negotiateCompressionEncoding("br;q=0, *"))
+	require.Equal(t, "", negotiateCompressionEncoding("identity"))
+	require.Equal(t, "", negotiateCompressionEncoding("gzip;q=0"))
+	require.Equal(t, "", negotiateCompressionEncoding(""))
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// applyDataResidency 把 Key 要求的数据驻留区域写入请求 context，调度层据此只选择满足该区域的账号与代理。
+func applyDataResidency(c *gin.Context, apiKey *service.APIKey) {
+	if apiKey == nil || apiKey.DataResidency == "" {
+		return
+	}
+	c.Request = c.Request.WithContext(service.WithDataResidency(c.Request.Context(), apiKey.DataResidency))
+}
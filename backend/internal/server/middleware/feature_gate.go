@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureGate 功能关闭时按未注册路由处理（404），整个功能面对外不可见。
+// 开关在运行时读取（带缓存），因此无需重启即可关闭/恢复路由。
+func FeatureGate(flags *service.FeatureFlagService, feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if flags.IsEnabled(c.Request.Context(), feature) {
+			c.Next()
+			return
+		}
+		response.NotFound(c, "Not found")
+		c.Abort()
+	}
+}
+
+// GatewayFeatureGate 与 FeatureGate 相同，但按网关协议格式输出 404。
+func GatewayFeatureGate(flags *service.FeatureFlagService, feature string, writeError GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if flags.IsEnabled(c.Request.Context(), feature) {
+			c.Next()
+			return
+		}
+		service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
+		writeError(c, http.StatusNotFound, "Not found")
+		c.Abort()
+	}
+}
+
+// PlatformFeatureGate 在 API Key 分组属于 platform 且功能关闭时拒绝请求（404）。
+// 用于按分组平台自动路由的共享入口（如 /v1/messages），必须放在 API Key 认证之后。
+func PlatformFeatureGate(flags *service.FeatureFlagService, feature, platform string, writeError GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok || apiKey.Group == nil || apiKey.Group.Platform != platform || flags.IsEnabled(c.Request.Context(), feature) {
+			c.Next()
+			return
+		}
+		service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
+		writeError(c, http.StatusNotFound, "The "+platform+" gateway is disabled on this deployment")
+		c.Abort()
+	}
+}
@@ -0,0 +1,76 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newFeatureFlagService(stored string) *service.FeatureFlagService {
+	return service.NewFeatureFlagService(&bmSettingRepo{values: map[string]string{service.SettingKeyFeatureFlags: stored}})
+}
+
+func TestFeatureGate(t *testing.T) {
+	tests := []struct {
+		name       string
+		flags      *service.FeatureFlagService
+		wantStatus int
+	}{
+		{name: "nil_service_allows", wantStatus: http.StatusOK},
+		{name: "enabled_allows", flags: newFeatureFlagService(`{}`), wantStatus: http.StatusOK},
+		{name: "disabled_returns_404", flags: newFeatureFlagService(`{"payments":false}`), wantStatus: http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/payment/config", FeatureGate(tc.flags, service.FeaturePayments), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/payment/config", nil))
+			require.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestPlatformFeatureGate(t *testing.T) {
+	flags := newFeatureFlagService(`{"gemini_gateway":false}`)
+	tests := []struct {
+		name       string
+		platform   string
+		wantStatus int
+	}{
+		{name: "gemini_group_blocked", platform: service.PlatformGemini, wantStatus: http.StatusNotFound},
+		{name: "other_platform_allowed", platform: service.PlatformAnthropic, wantStatus: http.StatusOK},
+		{name: "no_group_allowed", wantStatus: http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				apiKey := &service.APIKey{}
+				if tc.platform != "" {
+					apiKey.Group = &service.Group{Platform: tc.platform}
+				}
+				c.Set(string(ContextKeyAPIKey), apiKey)
+				c.Next()
+			})
+			r.POST("/v1/messages", PlatformFeatureGate(flags, service.FeatureGeminiGateway, service.PlatformGemini, AnthropicErrorWriter), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+			require.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
@@ -1,15 +1,68 @@
 package middleware
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
 	"github.com/gin-gonic/gin"
 )
 
 // RequestBodyLimit 使用 MaxBytesReader 限制请求体大小。
+// 客户端以 Content-Encoding（gzip / deflate / zstd）压缩的请求体在此统一解压，
+// 解压后大小同样受 maxBytes 限制；后续中间件与 handler 只会看到明文请求体。
+// 不支持的编码返回 415。
 func RequestBodyLimit(maxBytes int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		if !decodeRequestBody(c, maxBytes) {
+			return
+		}
 		c.Next()
 	}
 }
+
+// decodeRequestBody 解压带 Content-Encoding 的请求体并替换 c.Request.Body；失败时写出错误并返回 false。
+func decodeRequestBody(c *gin.Context, maxBytes int64) bool {
+	enc := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+	if enc == "" || enc == "identity" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return true
+	}
+
+	body, err := pkghttputil.ReadRequestBodyWithLimit(c.Request, maxBytes)
+	_ = c.Request.Body.Close()
+	if err != nil {
+		var encErr *pkghttputil.UnsupportedContentEncodingError
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &encErr):
+			writeRequestBodyError(c, http.StatusUnsupportedMediaType,
+				fmt.Sprintf("Unsupported Content-Encoding %q, supported encodings are gzip, deflate and zstd", encErr.Encoding))
+		case errors.As(err, &maxErr):
+			writeRequestBodyError(c, http.StatusRequestEntityTooLarge, "Request body too large")
+		default:
+			writeRequestBodyError(c, http.StatusBadRequest, "Failed to decode compressed request body")
+		}
+		c.Abort()
+		return false
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return true
+}
+
+// writeRequestBodyError 按入口协议输出错误：Gemini 原生路径使用 Google 格式，其余使用 Anthropic/OpenAI 兼容格式。
+func writeRequestBodyError(c *gin.Context, status int, message string) {
+	if allowGoogleQueryKey(c.Request.URL.Path) {
+		GoogleErrorWriter(c, status, message)
+		return
+	}
+	c.JSON(status, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": "invalid_request_error", "message": message},
+	})
+}
@@ -0,0 +1,105 @@
+//go:build unit
+
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, payload []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func newRequestBodyLimitRouter(limit int64, seen *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestBodyLimit(limit))
+	handler := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		*seen = string(body) + "|" + c.GetHeader("Content-Encoding")
+		c.Status(http.StatusOK)
+	}
+	r.POST("/v1/messages", handler)
+	r.POST("/v1beta/models/gemini:generateContent", handler)
+	return r
+}
+
+func TestRequestBodyLimit_DecodesGzipBody(t *testing.T) {
+	var seen string
+	r := newRequestBodyLimitRouter(1024, &seen)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader(gzipBytes(t, []byte(`{"model":"claude"}`))))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"model":"claude"}|`, seen, "handler must see the decoded body without Content-Encoding")
+}
+
+func TestRequestBodyLimit_LimitsDecodedSize(t *testing.T) {
+	var seen string
+	r := newRequestBodyLimitRouter(64, &seen)
+
+	// 压缩后远小于上限，解压后超限
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader(gzipBytes(t, bytes.Repeat([]byte("a"), 4096))))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Empty(t, seen)
+}
+
+func TestRequestBodyLimit_RejectsUnsupportedEncoding(t *testing.T) {
+	var seen string
+	r := newRequestBodyLimitRouter(1024, &seen)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader([]byte("x")))
+	req.Header.Set("Content-Encoding", "br")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	require.Contains(t, w.Body.String(), "invalid_request_error")
+	require.Contains(t, w.Body.String(), `\"br\"`)
+
+	req = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini:generateContent", bytes.NewReader([]byte("x")))
+	req.Header.Set("Content-Encoding", "br")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	require.Contains(t, w.Body.String(), `"status":"`)
+	require.Empty(t, seen)
+}
+
+func TestRequestBodyLimit_RejectsCorruptGzip(t *testing.T) {
+	var seen string
+	r := newRequestBodyLimitRouter(1024, &seen)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader([]byte("not gzip")))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
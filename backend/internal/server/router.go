@@ -8,6 +8,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
 	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/server/routes"
 	"github.com/Wei-Shaw/sub2api/internal/service"
@@ -31,9 +32,15 @@ func SetupRouter(
 	apiKeyService *service.APIKeyService,
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
+	requestMirrorService *service.RequestMirrorService,
+	sseResumeService *service.SSEResumeService,
+	activeRequestRegistry *service.ActiveRequestRegistry,
+	streamCaptureService *service.UpstreamStreamCaptureService,
 	settingService *service.SettingService,
+	featureFlags *service.FeatureFlagService,
+	policyManager *policyplugin.Manager,
 	cfg *config.Config,
-	redisClient *redis.Client,
+	redisClient redis.UniversalClient,
 ) *gin.Engine {
 	middleware2.SetIngressRejectRecorder(opsService)
 	// 缓存 iframe 页面的 origin 列表，用于动态注入 CSP frame-src
@@ -59,6 +66,7 @@ func SetupRouter(
 	// 解析模式按请求快照：兼容开关开启时信任原始转发头，关闭时使用 server.trusted_proxies。
 	r.Use(middleware2.SessionBindingContext(cfg))
 	r.Use(middleware2.Logger())
+	r.Use(middleware2.AccessLog())
 	r.Use(middleware2.CORS(cfg.CORS))
 	r.Use(middleware2.SecurityHeaders(cfg.Security.CSP, func() []string {
 		if p := cachedFrameOrigins.Load(); p != nil {
@@ -66,6 +74,7 @@ func SetupRouter(
 		}
 		return nil
 	}))
+	r.Use(middleware2.ResponseCompression(cfg.Server.Compression))
 	r.Use(middleware2.ServerTiming(cfg.Server.EnableServerTiming))
 
 	// Serve embedded frontend with settings injection if available
@@ -88,7 +97,7 @@ func SetupRouter(
 	}
 
 	// 注册路由
-	registerRoutes(r, handlers, jwtAuth, adminAuth, apiKeyAuth, auditLog, stepUpAuth, apiKeyService, subscriptionService, opsService, settingService, cfg, redisClient)
+	registerRoutes(r, handlers, jwtAuth, adminAuth, apiKeyAuth, auditLog, stepUpAuth, apiKeyService, subscriptionService, opsService, requestMirrorService, sseResumeService, activeRequestRegistry, streamCaptureService, settingService, featureFlags, policyManager, cfg, redisClient)
 
 	return r
 }
@@ -105,12 +114,19 @@ func registerRoutes(
 	apiKeyService *service.APIKeyService,
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
+	requestMirrorService *service.RequestMirrorService,
+	sseResumeService *service.SSEResumeService,
+	activeRequestRegistry *service.ActiveRequestRegistry,
+	streamCaptureService *service.UpstreamStreamCaptureService,
 	settingService *service.SettingService,
+	featureFlags *service.FeatureFlagService,
+	policyManager *policyplugin.Manager,
 	cfg *config.Config,
-	redisClient *redis.Client,
+	redisClient redis.UniversalClient,
 ) {
 	// 通用路由（健康检查、状态等）
 	routes.RegisterCommonRoutes(r)
+	routes.RegisterAutoscalingMetricsRoutes(r, h, cfg)
 
 	// API v1
 	v1 := r.Group("/api/v1")
@@ -119,8 +135,8 @@ func registerRoutes(
 	routes.RegisterAuthRoutes(v1, h, jwtAuth, auditLog, redisClient, settingService)
 	routes.RegisterUserRoutes(v1, h, jwtAuth, auditLog, settingService)
 	routes.RegisterAdminRoutes(v1, h, adminAuth, auditLog, stepUpAuth, settingService)
-	routes.RegisterGatewayRoutes(r, h, apiKeyAuth, apiKeyService, subscriptionService, opsService, settingService, cfg)
-	routes.RegisterPaymentRoutes(v1, h.Payment, h.PaymentWebhook, h.Admin.Payment, jwtAuth, adminAuth, auditLog, settingService)
+	routes.RegisterGatewayRoutes(r, h, apiKeyAuth, apiKeyService, subscriptionService, opsService, requestMirrorService, sseResumeService, activeRequestRegistry, streamCaptureService, settingService, featureFlags, policyManager, cfg)
+	routes.RegisterPaymentRoutes(v1, h.Payment, h.PaymentWebhook, h.Admin.Payment, jwtAuth, adminAuth, auditLog, settingService, featureFlags)
 
 	handler.RegisterPageRoutes(v1, cfg.Pricing.DataDir, gin.HandlerFunc(jwtAuth), gin.HandlerFunc(adminAuth), settingService)
 }
@@ -78,6 +78,43 @@ func RegisterAdminRoutes(
 		// 系统管理
 		registerSystemRoutes(admin, h)
 
+		// 启动自检报告
+		registerDiagnosticsRoutes(admin, h)
+
+		// 计费调整（补偿/退款）台账
+		registerBillingAdjustmentRoutes(admin, h)
+
+		// 账号运维元数据
+		registerAccountMetadataRoutes(admin, h)
+
+		// 分组月度成本预算
+		registerGroupBudgetRoutes(admin, h)
+
+		// 在途网关请求查看与强制取消
+		registerActiveRequestRoutes(admin, h)
+
+		// 部署级功能开关
+		registerFeatureFlagRoutes(admin, h)
+
+		// 上游原始响应留存（争议核查）
+		registerStreamCaptureRoutes(admin, h)
+
+		// 模型别名学习（别名建议审核、未知模型报告）
+		registerModelAliasRoutes(admin, h)
+
+		// 账号上游成本异常（列表与审核）
+		registerCostAnomalyRoutes(admin, h)
+
+		// 账号夜间冒烟测试（每日趋势与下滑标记）
+		admin.GET("/accounts/:id/smoke-tests/trend", h.Admin.AccountSmokeTest.Trend)
+		admin.GET("/smoke-tests/flagged", h.Admin.AccountSmokeTest.ListFlagged)
+
+		// 支持包下载（脱敏配置、错误摘要、版本与自检信息）
+		admin.GET("/support-bundle", h.Admin.SupportBundle.Download)
+
+		// 上游 access token 缓存清理（事故响应）
+		admin.POST("/token-cache/wipe", h.Admin.TokenCache.Wipe)
+
 		// 订阅管理
 		registerSubscriptionRoutes(admin, h)
 
@@ -170,6 +207,7 @@ func registerContentModerationRoutes(admin *gin.RouterGroup, h *handler.Handlers
 func registerAdminAPIKeyRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	apiKeys := admin.Group("/api-keys")
 	{
+		apiKeys.POST("/import", h.Admin.APIKey.Import)
 		apiKeys.PUT("/:id", h.Admin.APIKey.UpdateGroup)
 	}
 }
@@ -239,6 +277,7 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/ingress-rejections", h.Admin.Ops.ListIngressRejects)
 		ops.GET("/ingress-rejections/health", h.Admin.Ops.GetIngressRejectHealth)
 		ops.GET("/auth-cache-invalidation/health", h.Admin.Ops.GetAuthCacheInvalidationHealth)
+		ops.GET("/usage-record-dead-letter/health", h.Admin.Ops.GetUsageRecordDeadLetterHealth)
 
 		// Upstream errors (independent upstream failures)
 		ops.GET("/upstream-errors", h.Admin.Ops.ListUpstreamErrors)
@@ -338,7 +377,9 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers, stepUpAu
 	accounts := admin.Group("/accounts")
 	{
 		accounts.GET("", h.Admin.Account.List)
+		accounts.GET("/changes", h.Admin.Account.Changes)
 		accounts.GET("/upstream-billing-probe/settings", h.Admin.Account.GetUpstreamBillingProbeSettings)
+		accounts.GET("/content-policy", h.Admin.Account.GetContentPolicyStats)
 		accounts.PUT("/upstream-billing-probe/settings", h.Admin.Account.UpdateUpstreamBillingProbeSettings)
 		accounts.POST("/upstream-billing-probe/batch", h.Admin.Account.ProbeUpstreamBillingBatch)
 		accounts.GET("/:id", h.Admin.Account.GetByID)
@@ -346,6 +387,7 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers, stepUpAu
 		accounts.POST("/:id/duplicate", h.Admin.Account.Duplicate)
 		accounts.POST("/check-mixed-channel", h.Admin.Account.CheckMixedChannel)
 		accounts.POST("/import/codex-session", h.Admin.Account.ImportCodexSession)
+		accounts.POST("/import/external", h.Admin.Account.ImportExternalAccounts)
 		accounts.POST("/sync/crs", h.Admin.Account.SyncFromCRS)
 		accounts.POST("/sync/crs/preview", h.Admin.Account.PreviewFromCRS)
 		accounts.PUT("/:id", h.Admin.Account.Update)
@@ -358,7 +400,9 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers, stepUpAu
 		accounts.POST("/:id/apply-oauth-credentials", h.Admin.Account.ApplyOAuthCredentials)
 		accounts.POST("/:id/set-privacy", h.Admin.Account.SetPrivacy)
 		accounts.POST("/:id/refresh-tier", h.Admin.Account.RefreshTier)
+		accounts.POST("/:id/inspect-token", h.Admin.Account.InspectToken)
 		accounts.GET("/:id/stats", h.Admin.Account.GetStats)
+		accounts.GET("/:id/attribution", h.Admin.Account.GetAttributionReport)
 		accounts.POST("/:id/clear-error", h.Admin.Account.ClearError)
 		accounts.POST("/:id/revert-proxy-fallback", h.Admin.Account.RevertProxyFallback)
 		accounts.GET("/:id/usage", h.Admin.Account.GetUsage)
@@ -369,6 +413,7 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers, stepUpAu
 		accounts.GET("/:id/temp-unschedulable", h.Admin.Account.GetTempUnschedulable)
 		accounts.DELETE("/:id/temp-unschedulable", h.Admin.Account.ClearTempUnschedulable)
 		accounts.POST("/:id/schedulable", h.Admin.Account.SetSchedulable)
+		accounts.PUT("/:id/groups/:group_id", h.Admin.Account.UpdateGroupMembership)
 		accounts.POST("/models/sync-upstream-preview", h.Admin.Account.SyncUpstreamModelsPreview)
 		accounts.GET("/:id/models", h.Admin.Account.GetAvailableModels)
 		accounts.POST("/:id/models/sync-upstream", h.Admin.Account.SyncUpstreamModels)
@@ -509,6 +554,8 @@ func registerPromoCodeRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 
 func registerSettingsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	adminSettings := admin.Group("/settings")
+	// 独立设置接口按分类校验编辑权限；PUT /settings 在处理器内按实际变更的字段校验并走审批
+	category := h.Admin.Setting.RequireSettingCategory
 	{
 		adminSettings.GET("", h.Admin.Setting.GetSettings)
 		adminSettings.PUT("", h.Admin.Setting.UpdateSettings)
@@ -517,32 +564,47 @@ func registerSettingsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		adminSettings.GET("/email-templates", h.Admin.Setting.ListEmailTemplates)
 		adminSettings.POST("/email-template-preview", h.Admin.Setting.PreviewEmailTemplate)
 		adminSettings.GET("/email-templates/:event/:locale", h.Admin.Setting.GetEmailTemplate)
-		adminSettings.PUT("/email-templates/:event/:locale", h.Admin.Setting.UpdateEmailTemplate)
-		adminSettings.POST("/email-templates/:event/:locale/restore-official", h.Admin.Setting.RestoreOfficialEmailTemplate)
+		adminSettings.PUT("/email-templates/:event/:locale", category(service.SettingCategoryGeneral), h.Admin.Setting.UpdateEmailTemplate)
+		adminSettings.POST("/email-templates/:event/:locale/restore-official", category(service.SettingCategoryGeneral), h.Admin.Setting.RestoreOfficialEmailTemplate)
 		// Admin API Key 管理
 		adminSettings.GET("/admin-api-key", h.Admin.Setting.GetAdminAPIKey)
-		adminSettings.POST("/admin-api-key/regenerate", h.Admin.Setting.RegenerateAdminAPIKey)
-		adminSettings.DELETE("/admin-api-key", h.Admin.Setting.DeleteAdminAPIKey)
+		adminSettings.POST("/admin-api-key/regenerate", category(service.SettingCategorySecurity), h.Admin.Setting.RegenerateAdminAPIKey)
+		adminSettings.DELETE("/admin-api-key", category(service.SettingCategorySecurity), h.Admin.Setting.DeleteAdminAPIKey)
 		// 529过载冷却配置
 		adminSettings.GET("/overload-cooldown", h.Admin.Setting.GetOverloadCooldownSettings)
-		adminSettings.PUT("/overload-cooldown", h.Admin.Setting.UpdateOverloadCooldownSettings)
+		adminSettings.PUT("/overload-cooldown", category(service.SettingCategoryGateway), h.Admin.Setting.UpdateOverloadCooldownSettings)
 		// 429默认回避配置
 		adminSettings.GET("/rate-limit-429-cooldown", h.Admin.Setting.GetRateLimit429CooldownSettings)
-		adminSettings.PUT("/rate-limit-429-cooldown", h.Admin.Setting.UpdateRateLimit429CooldownSettings)
+		adminSettings.PUT("/rate-limit-429-cooldown", category(service.SettingCategoryGateway), h.Admin.Setting.UpdateRateLimit429CooldownSettings)
 		// 流超时处理配置
 		adminSettings.GET("/stream-timeout", h.Admin.Setting.GetStreamTimeoutSettings)
-		adminSettings.PUT("/stream-timeout", h.Admin.Setting.UpdateStreamTimeoutSettings)
+		adminSettings.PUT("/stream-timeout", category(service.SettingCategoryGateway), h.Admin.Setting.UpdateStreamTimeoutSettings)
+		// 各平台上游超时档位
+		adminSettings.GET("/upstream-timeouts", h.Admin.Setting.GetUpstreamTimeoutProfiles)
+		adminSettings.PUT("/upstream-timeouts", category(service.SettingCategoryGateway), h.Admin.Setting.UpdateUpstreamTimeoutProfiles)
 		// 请求整流器配置
 		adminSettings.GET("/rectifier", h.Admin.Setting.GetRectifierSettings)
-		adminSettings.PUT("/rectifier", h.Admin.Setting.UpdateRectifierSettings)
+		adminSettings.PUT("/rectifier", category(service.SettingCategoryGateway), h.Admin.Setting.UpdateRectifierSettings)
 		// Beta 策略配置
 		adminSettings.GET("/beta-policy", h.Admin.Setting.GetBetaPolicySettings)
-		adminSettings.PUT("/beta-policy", h.Admin.Setting.UpdateBetaPolicySettings)
+		adminSettings.PUT("/beta-policy", category(service.SettingCategoryGateway), h.Admin.Setting.UpdateBetaPolicySettings)
+		// 网关配置灰度通道
+		adminSettings.GET("/canary", h.Admin.Setting.GetGatewayCanary)
+		adminSettings.PUT("/canary", category(service.SettingCategoryGateway), h.Admin.Setting.UpdateGatewayCanary)
+		adminSettings.POST("/canary/promote", category(service.SettingCategoryGateway), h.Admin.Setting.PromoteGatewayCanary)
+		adminSettings.POST("/canary/rollback", category(service.SettingCategoryGateway), h.Admin.Setting.RollbackGatewayCanary)
 		// Web Search 模拟配置
 		adminSettings.GET("/web-search-emulation", h.Admin.Setting.GetWebSearchEmulationConfig)
-		adminSettings.PUT("/web-search-emulation", h.Admin.Setting.UpdateWebSearchEmulationConfig)
+		adminSettings.PUT("/web-search-emulation", category(service.SettingCategoryGateway), h.Admin.Setting.UpdateWebSearchEmulationConfig)
 		adminSettings.POST("/web-search-emulation/test", h.Admin.Setting.TestWebSearchEmulation)
-		adminSettings.POST("/web-search-emulation/reset-usage", h.Admin.Setting.ResetWebSearchUsage)
+		adminSettings.POST("/web-search-emulation/reset-usage", category(service.SettingCategoryGateway), h.Admin.Setting.ResetWebSearchUsage)
+		// 设置分类权限与审批队列
+		adminSettings.GET("/governance", h.Admin.Setting.GetSettingsGovernance)
+		adminSettings.PUT("/governance", h.Admin.Setting.UpdateSettingsGovernance)
+		adminSettings.GET("/change-requests", h.Admin.Setting.ListSettingChangeRequests)
+		adminSettings.GET("/change-requests/:id", h.Admin.Setting.GetSettingChangeRequest)
+		adminSettings.POST("/change-requests/:id/approve", h.Admin.Setting.ApproveSettingChangeRequest)
+		adminSettings.POST("/change-requests/:id/reject", h.Admin.Setting.RejectSettingChangeRequest)
 	}
 }
 
@@ -602,6 +664,86 @@ func registerBackupRoutes(admin *gin.RouterGroup, h *handler.Handlers, stepUpAut
 	}
 }
 
+func registerDiagnosticsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	admin.GET("/diagnostics", h.Admin.Diagnostics.Get)
+	admin.POST("/diagnostics/run", h.Admin.Diagnostics.Run)
+}
+
+func registerBillingAdjustmentRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	adjustments := admin.Group("/billing-adjustments")
+	{
+		adjustments.GET("", h.Admin.BillingAdjustment.List)
+		adjustments.POST("", h.Admin.BillingAdjustment.Create)
+	}
+}
+
+func registerAccountMetadataRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	admin.GET("/account-metadata", h.Admin.AccountMetadata.List)
+	accounts := admin.Group("/accounts")
+	{
+		accounts.GET("/:id/metadata", h.Admin.AccountMetadata.Get)
+		accounts.PUT("/:id/metadata", h.Admin.AccountMetadata.Upsert)
+		accounts.DELETE("/:id/metadata", h.Admin.AccountMetadata.Delete)
+		accounts.GET("/:id/snapshots", h.Admin.AccountSnapshot.Timeline)
+		accounts.GET("/:id/credential-source", h.Admin.CredentialSource.Get)
+		accounts.PUT("/:id/credential-source", h.Admin.CredentialSource.Upsert)
+		accounts.DELETE("/:id/credential-source", h.Admin.CredentialSource.Delete)
+		accounts.POST("/:id/credential-source/sync", h.Admin.CredentialSource.Sync)
+	}
+	admin.GET("/credential-sources", h.Admin.CredentialSource.List)
+	admin.POST("/credential-sources/sync", h.Admin.CredentialSource.SyncAll)
+}
+
+func registerGroupBudgetRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	admin.GET("/group-budgets", h.Admin.GroupBudget.List)
+	groups := admin.Group("/groups")
+	{
+		groups.PUT("/:id/budget", h.Admin.GroupBudget.Upsert)
+		groups.DELETE("/:id/budget", h.Admin.GroupBudget.Delete)
+	}
+}
+
+func registerActiveRequestRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	active := admin.Group("/requests/active")
+	{
+		active.GET("", h.Admin.ActiveRequest.List)
+		active.POST("/:id/cancel", h.Admin.ActiveRequest.Cancel)
+	}
+}
+
+func registerStreamCaptureRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	captures := admin.Group("/stream-captures")
+	{
+		captures.GET("", h.Admin.StreamCapture.List)
+		captures.GET("/:id", h.Admin.StreamCapture.Download)
+	}
+}
+
+func registerModelAliasRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	aliases := admin.Group("/model-aliases")
+	{
+		aliases.GET("", h.Admin.ModelAlias.List)
+		aliases.POST("/:id/approve", h.Admin.ModelAlias.Approve)
+		aliases.POST("/:id/reject", h.Admin.ModelAlias.Reject)
+	}
+}
+
+func registerCostAnomalyRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	anomalies := admin.Group("/cost-anomalies")
+	{
+		anomalies.GET("", h.Admin.CostAnomaly.List)
+		anomalies.POST("/:id/review", h.Admin.CostAnomaly.Review)
+	}
+}
+
+func registerFeatureFlagRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	flags := admin.Group("/feature-flags")
+	{
+		flags.GET("", h.Admin.FeatureFlag.List)
+		flags.PUT("", h.Admin.FeatureFlag.Update)
+	}
+}
+
 func registerSystemRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	system := admin.Group("/system")
 	{
@@ -640,12 +782,21 @@ func registerUsageRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	usage := admin.Group("/usage")
 	{
 		usage.GET("", h.Admin.Usage.List)
+		usage.GET("/changes", h.Admin.Usage.Changes)
 		usage.GET("/stats", h.Admin.Usage.Stats)
+		usage.GET("/pricing-snapshots", h.Admin.Usage.PricingSnapshots)
 		usage.GET("/search-users", h.Admin.Usage.SearchUsers)
 		usage.GET("/search-api-keys", h.Admin.Usage.SearchAPIKeys)
 		usage.GET("/cleanup-tasks", h.Admin.Usage.ListCleanupTasks)
 		usage.POST("/cleanup-tasks", h.Admin.Usage.CreateCleanupTask)
 		usage.POST("/cleanup-tasks/:id/cancel", h.Admin.Usage.CancelCleanupTask)
+		usage.GET("/:id/stream-capture", h.Admin.StreamCapture.DownloadByUsage)
+		// 分层归档：归档对象下载与审计恢复
+		usage.GET("/archives", h.Admin.UsageArchive.List)
+		usage.GET("/archives/:id/download", h.Admin.UsageArchive.Download)
+		usage.POST("/archives/:id/restore", h.Admin.UsageArchive.Restore)
+		usage.GET("/archives/:id/rows", h.Admin.UsageArchive.ListRestoredRows)
+		usage.DELETE("/archives/:id/restore", h.Admin.UsageArchive.ReleaseRestore)
 	}
 }
 
@@ -3,6 +3,9 @@ package routes
 import (
 	"net/http"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/handler"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,6 +16,17 @@ func RegisterCommonRoutes(r *gin.Engine) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	// 就绪/降级状态：数据库或 Redis 不可用时仍返回 200（网关使用缓存继续服务），由 status 区分
+	r.GET("/healthz", func(c *gin.Context) {
+		db := service.DefaultDatabaseHealthMonitor().Status()
+		rds := service.DefaultRedisHealthMonitor().Status()
+		status := "ok"
+		if db.Degraded || (rds.Enabled && !rds.Healthy) {
+			status = "degraded"
+		}
+		c.JSON(http.StatusOK, gin.H{"status": status, "database": db, "redis": rds})
+	})
+
 	// Claude Code 遥测日志（忽略，直接返回200）
 	r.POST("/api/event_logging/batch", func(c *gin.Context) {
 		c.Status(http.StatusOK)
@@ -30,3 +44,11 @@ func RegisterCommonRoutes(r *gin.Engine) {
 		})
 	})
 }
+
+// RegisterAutoscalingMetricsRoutes 注册 HPA/KEDA 伸缩信号导出端点（autoscaling_metrics.enabled 开启时）
+func RegisterAutoscalingMetricsRoutes(r *gin.Engine, h *handler.Handlers, cfg *config.Config) {
+	if cfg == nil || !cfg.AutoscalingMetrics.Enabled || h == nil || h.Autoscaling == nil {
+		return
+	}
+	r.GET("/metrics/autoscaling", h.Autoscaling.Metrics)
+}
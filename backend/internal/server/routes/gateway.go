@@ -5,6 +5,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
+	"github.com/Wei-Shaw/sub2api/internal/policyplugin"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
@@ -19,19 +20,50 @@ func RegisterGatewayRoutes(
 	apiKeyService *service.APIKeyService,
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
+	requestMirrorService *service.RequestMirrorService,
+	sseResumeService *service.SSEResumeService,
+	activeRequestRegistry *service.ActiveRequestRegistry,
+	streamCaptureService *service.UpstreamStreamCaptureService,
 	settingService *service.SettingService,
+	featureFlags *service.FeatureFlagService,
+	policyManager *policyplugin.Manager,
 	cfg *config.Config,
 ) {
 	bodyLimit := middleware.RequestBodyLimit(cfg.Gateway.MaxBodySize)
 	textBodyLimit := middleware.RequestBodyLimit(cfg.Gateway.TextMaxBodySize)
 	clientRequestID := middleware.ClientRequestID()
 	opsErrorLogger := handler.OpsErrorLoggerMiddleware(opsService)
+	requestMirror := handler.RequestMirrorMiddleware(requestMirrorService)
+	errorTranslation := handler.ErrorTranslationMiddleware(service.NewErrorTranslator(cfg))
+	sseResume := handler.SSEResumeMiddleware(sseResumeService)
+	activeRequests := handler.ActiveRequestMiddleware(activeRequestRegistry)
+	streamCapture := handler.UpstreamStreamCaptureMiddleware(streamCaptureService)
 	endpointNorm := handler.InboundEndpointMiddleware()
 
 	// 未分组 Key 拦截中间件（按协议格式区分错误响应）
 	requireGroupAnthropic := middleware.RequireGroupAssignment(settingService, middleware.AnthropicErrorWriter)
 	requireGroupGoogle := middleware.RequireGroupAssignment(settingService, middleware.GoogleErrorWriter)
 
+	// 功能开关：Gemini 分组经共享入口（/v1/messages 等）的请求在 gemini_gateway 关闭时拒绝
+	geminiGatewayGate := middleware.PlatformFeatureGate(featureFlags, service.FeatureGeminiGateway, service.PlatformGemini, middleware.AnthropicErrorWriter)
+
+	// 自定义策略插件钩子（需在 API Key 认证之后，以便策略拿到 Key/用户/分组信息）
+	policyHooks := handler.PolicyPluginMiddleware(policyManager, middleware.AnthropicErrorWriter)
+	policyHooksGoogle := handler.PolicyPluginMiddleware(policyManager, middleware.GoogleErrorWriter)
+	// 严格请求校验（API Key 设置），在改写请求体的中间件之前校验客户端原始请求体
+	requestValidation := handler.RequestValidationMiddleware(middleware.AnthropicErrorWriter)
+	requestValidationGoogle := handler.RequestValidationMiddleware(middleware.GoogleErrorWriter)
+	// 客户端 tools 定义的数量 / 大小上限（按分组平台），在严格校验之后检查或截断超长描述
+	toolLimits := handler.ToolLimitsMiddleware(cfg, middleware.AnthropicErrorWriter)
+	toolLimitsGoogle := handler.ToolLimitsMiddleware(cfg, middleware.GoogleErrorWriter)
+	// 单请求费用上限（API Key / 分组设置），按估算费用上界在转发前拒绝
+	requestCostCap := h.Gateway.RequestCostCapMiddleware(middleware.AnthropicErrorWriter)
+	requestCostCapGoogle := h.Gateway.RequestCostCapMiddleware(middleware.GoogleErrorWriter)
+	// 强制回复语言（API Key / 分组设置），在策略插件 pre_parse 之后改写请求体
+	responseLanguage := handler.ResponseLanguageMiddleware(middleware.AnthropicErrorWriter)
+	// 分组归属水印：在成功响应的文本末尾追加页脚或零宽字符水印
+	attributionWatermark := handler.AttributionWatermarkMiddleware()
+
 	isOpenAIResponsesCompatibleGatewayPlatform := func(c *gin.Context) bool {
 		switch getGroupPlatform(c) {
 		case service.PlatformOpenAI, service.PlatformGrok:
@@ -131,18 +163,23 @@ func RegisterGatewayRoutes(
 		service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
 		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"type": "not_found_error", "message": "Videos API is not supported for this platform"}})
 	}
+	// /v1 与 /ws/v1 共用的中间件链（按执行顺序）：认证及其之前的入口中间件、认证之后的分组与请求体中间件
+	gatewayEntry := []gin.HandlerFunc{clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror}
+	gatewayGuards := []gin.HandlerFunc{requireGroupAnthropic, geminiGatewayGate, policyHooks, requestValidation, toolLimits, requestCostCap, responseLanguage, attributionWatermark, activeRequests, streamCapture}
+
 	// API网关（Claude API兼容）
 	gateway := r.Group("/v1")
 	gateway.Use(bodyLimit)
-	gateway.Use(clientRequestID)
-	gateway.Use(opsErrorLogger)
-	gateway.Use(endpointNorm)
-	gateway.Use(gin.HandlerFunc(apiKeyAuth))
+	gateway.Use(gatewayEntry...)
 	gateway.GET("/sub2api/billing", h.Gateway.KeyBillingInfo)
-	gateway.Use(requireGroupAnthropic)
+	// 委托子 Key 管理：以父 Key 认证，子 Key 用量计入父 Key 额度
+	gateway.POST("/sub2api/keys", h.Gateway.CreateSubKey)
+	gateway.GET("/sub2api/keys", h.Gateway.ListSubKeys)
+	gateway.DELETE("/sub2api/keys/:id", h.Gateway.RevokeSubKey)
+	gateway.Use(gatewayGuards...)
 	{
 		// /v1/messages: auto-route based on group platform
-		gateway.POST("/messages", func(c *gin.Context) {
+		gateway.POST("/messages", sseResume, func(c *gin.Context) {
 			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 				h.OpenAIGateway.Messages(c)
 				return
@@ -158,14 +195,14 @@ func RegisterGatewayRoutes(
 		gateway.GET("/models", modelsHandler)
 		gateway.GET("/usage", h.Gateway.Usage)
 		// OpenAI Responses API: auto-route based on group platform
-		gateway.POST("/responses", func(c *gin.Context) {
+		gateway.POST("/responses", sseResume, func(c *gin.Context) {
 			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 				h.OpenAIGateway.Responses(c)
 				return
 			}
 			h.Gateway.Responses(c)
 		})
-		gateway.POST("/responses/*subpath", func(c *gin.Context) {
+		gateway.POST("/responses/*subpath", sseResume, func(c *gin.Context) {
 			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 				h.OpenAIGateway.Responses(c)
 				return
@@ -177,7 +214,7 @@ func RegisterGatewayRoutes(
 			h.OpenAIGateway.ResponsesWebSocket(c)
 		})
 		// OpenAI Chat Completions API: auto-route based on group platform
-		gateway.POST("/chat/completions", func(c *gin.Context) {
+		gateway.POST("/chat/completions", sseResume, func(c *gin.Context) {
 			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 				h.OpenAIGateway.ChatCompletions(c)
 				return
@@ -221,17 +258,25 @@ func RegisterGatewayRoutes(
 
 	// Gemini 原生 API 兼容层（Gemini SDK/CLI 直连）
 	gemini := r.Group("/v1beta")
+	gemini.Use(middleware.GatewayFeatureGate(featureFlags, service.FeatureGeminiGateway, middleware.GoogleErrorWriter))
 	gemini.Use(bodyLimit)
 	gemini.Use(clientRequestID)
 	gemini.Use(opsErrorLogger)
+	gemini.Use(errorTranslation)
 	gemini.Use(endpointNorm)
 	gemini.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
+	gemini.Use(requestMirror)
 	gemini.Use(requireGroupGoogle)
+	gemini.Use(policyHooksGoogle)
+	gemini.Use(requestValidationGoogle)
+	gemini.Use(toolLimitsGoogle)
+	gemini.Use(requestCostCapGoogle)
+	gemini.Use(activeRequests, streamCapture)
 	{
 		gemini.GET("/models", h.Gateway.GeminiV1BetaListModels)
 		gemini.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
 		// Gin treats ":" as a param marker, but Gemini uses "{model}:{action}" in the same segment.
-		gemini.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+		gemini.POST("/models/*modelAction", sseResume, h.Gateway.GeminiV1BetaModels)
 	}
 
 	// OpenAI Responses API（不带v1前缀的别名）— auto-route based on group platform
@@ -242,19 +287,48 @@ func RegisterGatewayRoutes(
 		}
 		h.Gateway.Responses(c)
 	}
-	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.POST("/alpha/search", textBodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.OpenAIGateway.AlphaSearch)
-	r.GET("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+
+	// 流式接口的 WebSocket 桥接：/ws/v1/... 升级后首条消息为请求体，按对应 POST 接口处理。
+	// 桥接中间件挂在最前，之后与 /v1 走完全相同的中间件链，认证、镜像、错误记录等均作用于桥接后的请求与响应；
+	// 首条消息按文本请求体上限限制大小。
+	if cfg.Gateway.SSEWebSocketBridge.Enabled {
+		wsBridge := handler.SSEWebSocketBridgeMiddleware(cfg.Gateway.SSEWebSocketBridge, cfg.Gateway.TextMaxBodySize)
+		wsGateway := r.Group(handler.SSEWebSocketBridgePrefix + "/v1")
+		wsGateway.Use(wsBridge, textBodyLimit)
+		wsGateway.Use(gatewayEntry...)
+		wsGateway.Use(gatewayGuards...)
+		{
+			wsGateway.GET("/messages", func(c *gin.Context) {
+				if isOpenAIResponsesCompatibleGatewayPlatform(c) {
+					h.OpenAIGateway.Messages(c)
+					return
+				}
+				h.Gateway.Messages(c)
+			})
+			wsGateway.GET("/responses", responsesHandler)
+			wsGateway.GET("/chat/completions", func(c *gin.Context) {
+				if isOpenAIResponsesCompatibleGatewayPlatform(c) {
+					h.OpenAIGateway.ChatCompletions(c)
+					return
+				}
+				h.Gateway.ChatCompletions(c)
+			})
+		}
+	}
+
+	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, requestValidation, toolLimits, requestCostCap, responseLanguage, attributionWatermark, activeRequests, streamCapture, sseResume, responsesHandler)
+	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, requestValidation, toolLimits, requestCostCap, responseLanguage, attributionWatermark, activeRequests, streamCapture, sseResume, responsesHandler)
+	r.POST("/alpha/search", textBodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, h.OpenAIGateway.AlphaSearch)
+	r.GET("/responses", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, func(c *gin.Context) {
 		h.OpenAIGateway.ResponsesWebSocket(c)
 	})
-	r.GET("/models", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, modelsHandler)
-	r.POST("/messages/count_tokens", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, countTokensHandler)
+	r.GET("/models", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, modelsHandler)
+	r.POST("/messages/count_tokens", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, countTokensHandler)
 	codexDirect := r.Group("/backend-api/codex")
-	codexDirect.Use(bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic)
+	codexDirect.Use(bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, requestValidation, toolLimits, requestCostCap, responseLanguage, attributionWatermark, activeRequests, streamCapture)
 	{
-		codexDirect.POST("/responses", responsesHandler)
-		codexDirect.POST("/responses/*subpath", responsesHandler)
+		codexDirect.POST("/responses", sseResume, responsesHandler)
+		codexDirect.POST("/responses/*subpath", sseResume, responsesHandler)
 		codexDirect.POST("/alpha/search", textBodyLimit, h.OpenAIGateway.AlphaSearch)
 		codexDirect.GET("/responses", func(c *gin.Context) {
 			h.OpenAIGateway.ResponsesWebSocket(c)
@@ -262,14 +336,14 @@ func RegisterGatewayRoutes(
 		codexDirect.GET("/models", h.OpenAIGateway.CodexModels)
 	}
 	// OpenAI Chat Completions API（不带v1前缀的别名）— auto-route based on group platform
-	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, requestValidation, toolLimits, requestCostCap, responseLanguage, attributionWatermark, activeRequests, streamCapture, sseResume, func(c *gin.Context) {
 		if isOpenAIResponsesCompatibleGatewayPlatform(c) {
 			h.OpenAIGateway.ChatCompletions(c)
 			return
 		}
 		h.Gateway.ChatCompletions(c)
 	})
-	r.POST("/embeddings", textBodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+	r.POST("/embeddings", textBodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, func(c *gin.Context) {
 		if getGroupPlatform(c) != service.PlatformOpenAI {
 			service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
 			c.JSON(http.StatusNotFound, gin.H{
@@ -282,16 +356,16 @@ func RegisterGatewayRoutes(
 		}
 		h.OpenAIGateway.Embeddings(c)
 	})
-	r.POST("/images/generations", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, imagesHandler)
-	r.POST("/images/edits", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, imagesHandler)
-	r.POST("/images/generations/async", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.AsyncImage.Submit)
-	r.POST("/images/edits/async", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.AsyncImage.Submit)
-	r.GET("/images/tasks/:task_id", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.AsyncImage.Get)
-	r.POST("/videos/generations", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, videoGenerationHandler)
-	r.POST("/videos/edits", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, videoEditHandler)
-	r.POST("/videos/extensions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, videoExtensionHandler)
-	r.GET("/videos/:request_id", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, videoStatusHandler)
-	r.GET("/videos/:request_id/content", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, videoContentHandler)
+	r.POST("/images/generations", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, imagesHandler)
+	r.POST("/images/edits", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, imagesHandler)
+	r.POST("/images/generations/async", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, h.AsyncImage.Submit)
+	r.POST("/images/edits/async", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, h.AsyncImage.Submit)
+	r.GET("/images/tasks/:task_id", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, h.AsyncImage.Get)
+	r.POST("/videos/generations", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, videoGenerationHandler)
+	r.POST("/videos/edits", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, videoEditHandler)
+	r.POST("/videos/extensions", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, videoExtensionHandler)
+	r.GET("/videos/:request_id", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, videoStatusHandler)
+	r.GET("/videos/:request_id/content", bodyLimit, clientRequestID, opsErrorLogger, errorTranslation, endpointNorm, gin.HandlerFunc(apiKeyAuth), requestMirror, requireGroupAnthropic, geminiGatewayGate, policyHooks, activeRequests, streamCapture, videoContentHandler)
 
 	// Antigravity 模型列表
 	r.GET("/antigravity/models", gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.Gateway.AntigravityModels)
@@ -301,12 +375,21 @@ func RegisterGatewayRoutes(
 	antigravityV1.Use(bodyLimit)
 	antigravityV1.Use(clientRequestID)
 	antigravityV1.Use(opsErrorLogger)
+	antigravityV1.Use(errorTranslation)
 	antigravityV1.Use(endpointNorm)
 	antigravityV1.Use(middleware.ForcePlatform(service.PlatformAntigravity))
 	antigravityV1.Use(gin.HandlerFunc(apiKeyAuth))
+	antigravityV1.Use(requestMirror)
 	antigravityV1.Use(requireGroupAnthropic)
+	antigravityV1.Use(policyHooks)
+	antigravityV1.Use(requestValidation)
+	antigravityV1.Use(toolLimits)
+	antigravityV1.Use(requestCostCap)
+	antigravityV1.Use(responseLanguage)
+	antigravityV1.Use(attributionWatermark)
+	antigravityV1.Use(activeRequests, streamCapture)
 	{
-		antigravityV1.POST("/messages", h.Gateway.Messages)
+		antigravityV1.POST("/messages", sseResume, h.Gateway.Messages)
 		antigravityV1.POST("/messages/count_tokens", h.Gateway.CountTokens)
 		antigravityV1.GET("/models", h.Gateway.AntigravityModels)
 		antigravityV1.GET("/usage", h.Gateway.Usage)
@@ -316,14 +399,21 @@ func RegisterGatewayRoutes(
 	antigravityV1Beta.Use(bodyLimit)
 	antigravityV1Beta.Use(clientRequestID)
 	antigravityV1Beta.Use(opsErrorLogger)
+	antigravityV1Beta.Use(errorTranslation)
 	antigravityV1Beta.Use(endpointNorm)
 	antigravityV1Beta.Use(middleware.ForcePlatform(service.PlatformAntigravity))
 	antigravityV1Beta.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
+	antigravityV1Beta.Use(requestMirror)
 	antigravityV1Beta.Use(requireGroupGoogle)
+	antigravityV1Beta.Use(policyHooksGoogle)
+	antigravityV1Beta.Use(requestValidationGoogle)
+	antigravityV1Beta.Use(toolLimitsGoogle)
+	antigravityV1Beta.Use(requestCostCapGoogle)
+	antigravityV1Beta.Use(activeRequests, streamCapture)
 	{
 		antigravityV1Beta.GET("/models", h.Gateway.GeminiV1BetaListModels)
 		antigravityV1Beta.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
-		antigravityV1Beta.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+		antigravityV1Beta.POST("/models/*modelAction", sseResume, h.Gateway.GeminiV1BetaModels)
 	}
 
 }
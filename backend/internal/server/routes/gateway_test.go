@@ -47,6 +47,12 @@ func newGatewayRoutesTestRouterWithConfig(cfg *config.Config, platform ...string
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		cfg,
 	)
 
@@ -89,6 +95,28 @@ func TestGatewayRoutesOpenAIAlphaSearchPathsAreRegistered(t *testing.T) {
 	}
 }
 
+func TestGatewayRoutesSSEWebSocketBridgeRegisteredOnlyWhenEnabled(t *testing.T) {
+	collect := func(router *gin.Engine) map[string]bool {
+		registered := make(map[string]bool)
+		for _, route := range router.Routes() {
+			if route.Method == http.MethodGet {
+				registered[route.Path] = true
+			}
+		}
+		return registered
+	}
+
+	disabled := collect(newGatewayRoutesTestRouter())
+	require.False(t, disabled["/ws/v1/messages"])
+
+	cfg := &config.Config{}
+	cfg.Gateway.SSEWebSocketBridge = config.GatewaySSEWebSocketBridgeConfig{Enabled: true, FirstMessageTimeoutSeconds: 30, WriteTimeoutSeconds: 30}
+	enabled := collect(newGatewayRoutesTestRouterWithConfig(cfg))
+	for _, path := range []string{"/ws/v1/messages", "/ws/v1/responses", "/ws/v1/chat/completions"} {
+		require.True(t, enabled[path], "GET %s should be registered", path)
+	}
+}
+
 func TestGatewayRoutesAlphaSearchRejectsNonOpenAIGroup(t *testing.T) {
 	router := newGatewayRoutesTestRouter(service.PlatformGrok)
 	req := httptest.NewRequest(http.MethodPost, "/v1/alpha/search", strings.NewReader(`{"model":"gpt-5.6-sol"}`))
@@ -99,6 +99,22 @@ func RegisterUserRoutes(
 			usage.POST("/dashboard/api-keys-usage", h.Usage.DashboardAPIKeysUsage)
 		}
 
+		// 会话全文留存（仅返回当前用户 Key 的记录）
+		transcripts := authenticated.Group("/transcripts")
+		{
+			transcripts.GET("", h.Transcript.List)
+			transcripts.GET("/:id", h.Transcript.GetByID)
+		}
+
+		// 长耗时生成任务历史（跨 API Key，含状态、费用与结果链接）
+		tasks := authenticated.Group("/tasks")
+		{
+			tasks.GET("", h.TaskHistory.List)
+		}
+
+		// 补偿/退款记录（仅当前用户）
+		authenticated.GET("/adjustments", h.Adjustment.List)
+
 		// 公告（用户可见）
 		announcements := authenticated.Group("/announcements")
 		{
@@ -106,6 +122,15 @@ func RegisterUserRoutes(
 			announcements.POST("/:id/read", h.Announcement.MarkRead)
 		}
 
+		// 站内通知
+		notifications := authenticated.Group("/notifications")
+		{
+			notifications.GET("", h.Notification.List)
+			notifications.GET("/unread-count", h.Notification.UnreadCount)
+			notifications.POST("/read-all", h.Notification.MarkAllRead)
+			notifications.POST("/:id/read", h.Notification.MarkRead)
+		}
+
 		// 卡密兑换
 		redeem := authenticated.Group("/redeem")
 		{
@@ -79,6 +79,9 @@ type Account struct {
 	headerOverrideCacheRawPtr         uintptr
 	headerOverrideCacheRawLen         int
 	headerOverrideCacheRawSig         uint64
+
+	// membership 已应用的分组成员调度覆盖及被覆盖前的原值（非持久化字段），见 withAccountGroupMembership
+	membership *appliedAccountGroupMembership
 }
 
 type OpenAIEndpointCapability string
@@ -1964,6 +1967,35 @@ func (a *Account) GetTLSFingerprintProfileID() int64 {
 	return 0
 }
 
+// GetProxyCandidateIDs 获取参与延迟选路的候选代理 ID 列表（extra.proxy_candidate_ids）
+// 账号自身绑定的代理始终排在首位；去重后少于 2 个时返回 nil，表示不参与选路。
+func (a *Account) GetProxyCandidateIDs() []int64 {
+	if a.Extra == nil {
+		return nil
+	}
+	raw := parseInt64Slice(a.Extra["proxy_candidate_ids"])
+	if len(raw) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(raw)+1)
+	seen := make(map[int64]struct{}, len(raw)+1)
+	if a.ProxyID != nil && *a.ProxyID > 0 {
+		ids = append(ids, *a.ProxyID)
+		seen[*a.ProxyID] = struct{}{}
+	}
+	for _, id := range raw {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) < 2 {
+		return nil
+	}
+	return ids
+}
+
 // GetUserMsgQueueMode 获取用户消息队列模式
 // "serialize" = 串行队列, "throttle" = 软性限速, "" = 未设置（使用全局配置）
 func (a *Account) GetUserMsgQueueMode() string {
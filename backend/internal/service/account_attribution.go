@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	// AccountAttributionDefaultTopN 排行榜默认条数
+	AccountAttributionDefaultTopN = 10
+	// AccountAttributionMaxTopN 排行榜最大条数
+	AccountAttributionMaxTopN = 50
+	// AccountAttributionMaxRange 报告允许的最大时间跨度
+	AccountAttributionMaxRange = 93 * 24 * time.Hour
+
+	// accountAttributionUnderusedRatio 峰值并发低于上限该比例视为利用率偏低
+	accountAttributionUnderusedRatio = 0.2
+)
+
+// 账号使用建议
+const (
+	AccountAttributionSuggestionIdle      = "idle"      // 周期内无请求，可考虑下线
+	AccountAttributionSuggestionUnderused = "underused" // 峰值并发远低于上限，可考虑降级或合并
+	AccountAttributionSuggestionSaturated = "saturated" // 峰值并发触达上限，可考虑升级或扩容
+	AccountAttributionSuggestionNormal    = "normal"
+)
+
+var ErrAccountAttributionInvalidRange = infraerrors.BadRequest("ACCOUNT_ATTRIBUTION_INVALID_RANGE", "invalid report time range")
+
+// AccountAttributionSummary 账号在报告周期内的总体消耗。
+type AccountAttributionSummary struct {
+	Requests            int64 `json:"requests"`
+	InputTokens         int64 `json:"input_tokens"`
+	OutputTokens        int64 `json:"output_tokens"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens"`
+	CacheReadTokens     int64 `json:"cache_read_tokens"`
+	TotalTokens         int64 `json:"total_tokens"`
+	// StandardCost 按标准价计算的等价成本
+	StandardCost float64 `json:"standard_cost"`
+	// AccountCost 账号口径成本（含账号倍率）
+	AccountCost float64 `json:"account_cost"`
+	// UserCost 向用户实际扣费
+	UserCost        float64 `json:"user_cost"`
+	DistinctUsers   int64   `json:"distinct_users"`
+	DistinctAPIKeys int64   `json:"distinct_api_keys"`
+	ActiveDays      int64   `json:"active_days"`
+}
+
+// AccountAttributionEntry 排行榜中的一个用户或 API Key。
+type AccountAttributionEntry struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	UserID      int64   `json:"user_id,omitempty"`
+	Requests    int64   `json:"requests"`
+	TotalTokens int64   `json:"total_tokens"`
+	AccountCost float64 `json:"account_cost"`
+	UserCost    float64 `json:"user_cost"`
+	// Share 占账号口径总成本的比例（0-1）
+	Share float64 `json:"share"`
+}
+
+// AccountAttributionReport 单个上游账号（共享订阅）在一段时间内的消耗归因报告。
+type AccountAttributionReport struct {
+	AccountID        int64                     `json:"account_id"`
+	AccountName      string                    `json:"account_name"`
+	Platform         string                    `json:"platform"`
+	Type             string                    `json:"type"`
+	StartTime        time.Time                 `json:"start_time"`
+	EndTime          time.Time                 `json:"end_time"`
+	Summary          AccountAttributionSummary `json:"summary"`
+	TopUsers         []AccountAttributionEntry `json:"top_users"`
+	TopAPIKeys       []AccountAttributionEntry `json:"top_api_keys"`
+	PeakConcurrency  int                       `json:"peak_concurrency"`
+	PeakAt           *time.Time                `json:"peak_at,omitempty"`
+	ConcurrencyLimit int                       `json:"concurrency_limit"`
+	// PeakUtilization 峰值并发 / 并发上限；上限为 0（不限制）时为 0
+	PeakUtilization float64 `json:"peak_utilization"`
+	Suggestion      string  `json:"suggestion"`
+}
+
+// AccountAttributionRepository 基于 usage_logs 的账号消耗聚合查询。
+type AccountAttributionRepository interface {
+	Summary(ctx context.Context, accountID int64, start, end time.Time) (*AccountAttributionSummary, error)
+	TopUsers(ctx context.Context, accountID int64, start, end time.Time, limit int) ([]AccountAttributionEntry, error)
+	TopAPIKeys(ctx context.Context, accountID int64, start, end time.Time, limit int) ([]AccountAttributionEntry, error)
+	// PeakConcurrency 用每条请求的 [created_at - duration_ms, created_at] 区间扫描出最大重叠数及其时刻。
+	PeakConcurrency(ctx context.Context, accountID int64, start, end time.Time) (int, *time.Time, error)
+}
+
+// AccountAttributionService 生成账号消耗归因报告，帮助判断哪些账号需要升级或下线。
+type AccountAttributionService struct {
+	repo        AccountAttributionRepository
+	accountRepo AccountRepository
+}
+
+func NewAccountAttributionService(repo AccountAttributionRepository, accountRepo AccountRepository) *AccountAttributionService {
+	return &AccountAttributionService{repo: repo, accountRepo: accountRepo}
+}
+
+// GetReport 生成 [start, end) 区间内账号的消耗归因报告。
+func (s *AccountAttributionService) GetReport(ctx context.Context, accountID int64, start, end time.Time, topN int) (*AccountAttributionReport, error) {
+	if !end.After(start) || end.Sub(start) > AccountAttributionMaxRange {
+		return nil, ErrAccountAttributionInvalidRange
+	}
+	if topN <= 0 {
+		topN = AccountAttributionDefaultTopN
+	}
+	topN = min(topN, AccountAttributionMaxTopN)
+
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.repo.Summary(ctx, accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.repo.TopUsers(ctx, accountID, start, end, topN)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.repo.TopAPIKeys(ctx, accountID, start, end, topN)
+	if err != nil {
+		return nil, err
+	}
+	peak, peakAt, err := s.repo.PeakConcurrency(ctx, accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	fillAccountAttributionShare(users, summary.AccountCost)
+	fillAccountAttributionShare(keys, summary.AccountCost)
+
+	report := &AccountAttributionReport{
+		AccountID:        account.ID,
+		AccountName:      account.Name,
+		Platform:         account.Platform,
+		Type:             account.Type,
+		StartTime:        start,
+		EndTime:          end,
+		Summary:          *summary,
+		TopUsers:         users,
+		TopAPIKeys:       keys,
+		PeakConcurrency:  peak,
+		PeakAt:           peakAt,
+		ConcurrencyLimit: account.Concurrency,
+	}
+	if account.Concurrency > 0 {
+		report.PeakUtilization = float64(peak) / float64(account.Concurrency)
+	}
+	report.Suggestion = accountAttributionSuggestion(report)
+	return report, nil
+}
+
+func fillAccountAttributionShare(entries []AccountAttributionEntry, total float64) {
+	if total <= 0 {
+		return
+	}
+	for i := range entries {
+		entries[i].Share = entries[i].AccountCost / total
+	}
+}
+
+func accountAttributionSuggestion(report *AccountAttributionReport) string {
+	switch {
+	case report.Summary.Requests == 0:
+		return AccountAttributionSuggestionIdle
+	case report.ConcurrencyLimit <= 0:
+		return AccountAttributionSuggestionNormal
+	case report.PeakConcurrency >= report.ConcurrencyLimit:
+		return AccountAttributionSuggestionSaturated
+	case report.PeakUtilization < accountAttributionUnderusedRatio:
+		return AccountAttributionSuggestionUnderused
+	default:
+		return AccountAttributionSuggestionNormal
+	}
+}
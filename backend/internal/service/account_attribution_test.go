@@ -0,0 +1,90 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type accountAttributionRepoStub struct {
+	summary  AccountAttributionSummary
+	users    []AccountAttributionEntry
+	keys     []AccountAttributionEntry
+	peak     int
+	gotLimit int
+}
+
+func (s *accountAttributionRepoStub) Summary(context.Context, int64, time.Time, time.Time) (*AccountAttributionSummary, error) {
+	summary := s.summary
+	return &summary, nil
+}
+
+func (s *accountAttributionRepoStub) TopUsers(_ context.Context, _ int64, _, _ time.Time, limit int) ([]AccountAttributionEntry, error) {
+	s.gotLimit = limit
+	return append([]AccountAttributionEntry(nil), s.users...), nil
+}
+
+func (s *accountAttributionRepoStub) TopAPIKeys(context.Context, int64, time.Time, time.Time, int) ([]AccountAttributionEntry, error) {
+	return append([]AccountAttributionEntry(nil), s.keys...), nil
+}
+
+func (s *accountAttributionRepoStub) PeakConcurrency(context.Context, int64, time.Time, time.Time) (int, *time.Time, error) {
+	return s.peak, nil, nil
+}
+
+type accountAttributionAccountRepoStub struct {
+	AccountRepository
+	account *Account
+}
+
+func (s *accountAttributionAccountRepoStub) GetByID(context.Context, int64) (*Account, error) {
+	return s.account, nil
+}
+
+func TestAccountAttributionService_GetReport(t *testing.T) {
+	repo := &accountAttributionRepoStub{
+		summary: AccountAttributionSummary{Requests: 10, AccountCost: 8},
+		users:   []AccountAttributionEntry{{ID: 1, AccountCost: 6}, {ID: 2, AccountCost: 2}},
+		keys:    []AccountAttributionEntry{{ID: 11, AccountCost: 4}},
+		peak:    4,
+	}
+	accountRepo := &accountAttributionAccountRepoStub{account: &Account{ID: 7, Name: "team-a", Platform: PlatformAnthropic, Concurrency: 4}}
+	svc := NewAccountAttributionService(repo, accountRepo)
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	report, err := svc.GetReport(context.Background(), 7, start, end, 100)
+	require.NoError(t, err)
+	require.Equal(t, AccountAttributionMaxTopN, repo.gotLimit)
+	require.Equal(t, "team-a", report.AccountName)
+	require.InDelta(t, 0.75, report.TopUsers[0].Share, 1e-9)
+	require.InDelta(t, 0.5, report.TopAPIKeys[0].Share, 1e-9)
+	require.InDelta(t, 1.0, report.PeakUtilization, 1e-9)
+	require.Equal(t, AccountAttributionSuggestionSaturated, report.Suggestion)
+
+	repo.peak = 0
+	report, err = svc.GetReport(context.Background(), 7, start, end, 0)
+	require.NoError(t, err)
+	require.Equal(t, AccountAttributionDefaultTopN, repo.gotLimit)
+	require.Equal(t, AccountAttributionSuggestionUnderused, report.Suggestion)
+
+	repo.summary = AccountAttributionSummary{}
+	report, err = svc.GetReport(context.Background(), 7, start, end, 0)
+	require.NoError(t, err)
+	require.Equal(t, AccountAttributionSuggestionIdle, report.Suggestion)
+	require.Zero(t, report.TopUsers[0].Share)
+}
+
+func TestAccountAttributionService_GetReportInvalidRange(t *testing.T) {
+	svc := NewAccountAttributionService(&accountAttributionRepoStub{}, &accountAttributionAccountRepoStub{account: &Account{ID: 1}})
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := svc.GetReport(context.Background(), 1, start, start, 0)
+	require.ErrorIs(t, err, ErrAccountAttributionInvalidRange)
+	_, err = svc.GetReport(context.Background(), 1, start, start.Add(AccountAttributionMaxRange+time.Hour), 0)
+	require.ErrorIs(t, err, ErrAccountAttributionInvalidRange)
+}
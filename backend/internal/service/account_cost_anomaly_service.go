@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// 成本异常状态
+const (
+	AccountCostAnomalyStatusOpen     = "open"
+	AccountCostAnomalyStatusReviewed = "reviewed"
+)
+
+const (
+	accountCostAnomalyLeaderLockKey = "account:cost_anomaly:leader"
+	accountCostAnomalyLeaderLockTTL = 5 * time.Minute
+	// accountCostAnomalySampleSize 告警附带的样本请求数（近期窗口内成本最高的请求）
+	accountCostAnomalySampleSize = 5
+	// accountCostAnomalyThrottleReasonPrefix 暂停调度原因前缀；审核时只解除由本服务设置的暂停
+	accountCostAnomalyThrottleReasonPrefix = "cost anomaly pending review"
+)
+
+var ErrAccountCostAnomalyNotFound = infraerrors.NotFound("COST_ANOMALY_NOT_FOUND", "cost anomaly not found")
+
+// AccountCostStats 单个 (账号, 模型) 在基线窗口与近期窗口的单请求成本分布。
+type AccountCostStats struct {
+	AccountID        int64
+	AccountName      string
+	Model            string
+	BaselineRequests int64
+	BaselineAvgCost  float64
+	BaselineP95Cost  float64
+	RecentRequests   int64
+	RecentAvgCost    float64
+	RecentP95Cost    float64
+}
+
+// AccountCostAnomaly 一次成本异常记录。
+type AccountCostAnomaly struct {
+	ID               int64      `json:"id"`
+	AccountID        int64      `json:"account_id"`
+	AccountName      string     `json:"account_name,omitempty"`
+	Model            string     `json:"model"`
+	BaselineRequests int64      `json:"baseline_requests"`
+	BaselineAvgCost  float64    `json:"baseline_avg_cost"`
+	BaselineP95Cost  float64    `json:"baseline_p95_cost"`
+	RecentRequests   int64      `json:"recent_requests"`
+	RecentAvgCost    float64    `json:"recent_avg_cost"`
+	RecentP95Cost    float64    `json:"recent_p95_cost"`
+	DeviationRatio   float64    `json:"deviation_ratio"`
+	SampleRequestIDs []string   `json:"sample_request_ids"`
+	Throttled        bool       `json:"throttled"`
+	Status           string     `json:"status"`
+	ReviewNote       string     `json:"review_note,omitempty"`
+	ReviewedBy       *int64     `json:"reviewed_by,omitempty"`
+	DetectedAt       time.Time  `json:"detected_at"`
+	LastDetectedAt   time.Time  `json:"last_detected_at"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// AccountCostAnomalyFilter 成本异常列表过滤条件。
+type AccountCostAnomalyFilter struct {
+	Status    string
+	AccountID int64
+	Page      int
+	PageSize  int
+}
+
+// AccountCostAnomalyList 成本异常分页结果。
+type AccountCostAnomalyList struct {
+	Items    []*AccountCostAnomaly
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// AccountCostAnomalyRepository 成本统计与异常记录持久化。
+type AccountCostAnomalyRepository interface {
+	// ComputeCostStats 汇总 [baselineSince, recentSince) 与 [recentSince, now) 两个窗口内各 (账号, 模型) 的单请求成本，
+	// 只返回近期窗口请求数不少于 minRecentRequests 的组合。
+	ComputeCostStats(ctx context.Context, baselineSince, recentSince time.Time, minRecentRequests int) ([]*AccountCostStats, error)
+	// ListSampleRequestIDs 返回近期窗口内该 (账号, 模型) 成本最高的请求 ID。
+	ListSampleRequestIDs(ctx context.Context, accountID int64, model string, since time.Time, limit int) ([]string, error)
+	// UpsertOpen 写入异常；已有 open 记录时只刷新指标，created 为 false。
+	UpsertOpen(ctx context.Context, anomaly *AccountCostAnomaly) (saved *AccountCostAnomaly, created bool, err error)
+	List(ctx context.Context, filter *AccountCostAnomalyFilter) (*AccountCostAnomalyList, error)
+	GetByID(ctx context.Context, id int64) (*AccountCostAnomaly, error)
+	// MarkReviewed 将 open 记录标记为已审核；记录不存在或已审核时返回 ErrAccountCostAnomalyNotFound。
+	MarkReviewed(ctx context.Context, id int64, reviewerID int64, note string) (*AccountCostAnomaly, error)
+	// CountOpenThrottled 统计账号其余仍在暂停调度的 open 异常数。
+	CountOpenThrottled(ctx context.Context, accountID int64) (int, error)
+}
+
+// AccountCostAnomalyService 账号上游成本异常检测。
+//
+// 周期性按 (账号, 模型) 对比近期窗口与基线窗口的平均单请求成本；达到 deviation_ratio 倍时
+// 记录异常并写入一次运维告警（附带近期成本最高的样本请求 ID）。开启 throttle 时同时暂停账号调度，
+// 直到管理员审核或超过 throttle_minutes。只比较账号自身的历史，新账号 / 新模型没有基线时不检测。
+type AccountCostAnomalyService struct {
+	repo        AccountCostAnomalyRepository
+	accountRepo AccountRepository
+	opsRepo     OpsRepository
+	cfg         config.CostAnomalyConfig
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+func NewAccountCostAnomalyService(repo AccountCostAnomalyRepository, accountRepo AccountRepository, opsRepo OpsRepository, cfg *config.Config) *AccountCostAnomalyService {
+	s := &AccountCostAnomalyService{
+		repo:        repo,
+		accountRepo: accountRepo,
+		opsRepo:     opsRepo,
+		stopCh:      make(chan struct{}),
+		instanceID:  uuid.NewString(),
+		now:         time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.CostAnomaly
+	}
+	return s
+}
+
+// SetLeaderLock injects the leader-lock cache and DB so that only one instance
+// evaluates anomalies per cycle.
+func (s *AccountCostAnomalyService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+func (s *AccountCostAnomalyService) Start() {
+	if s == nil || s.repo == nil || !s.cfg.Enabled || s.cfg.CheckIntervalMinutes <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(time.Duration(s.cfg.CheckIntervalMinutes) * time.Minute)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *AccountCostAnomalyService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *AccountCostAnomalyService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, accountCostAnomalyLeaderLockKey, s.instanceID, accountCostAnomalyLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	raised, err := s.Evaluate(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] evaluate failed: %v", err)
+	}
+	if raised > 0 {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] raised %d new cost anomalies", raised)
+	}
+}
+
+// Evaluate 执行一轮检测，返回新产生的异常数。
+func (s *AccountCostAnomalyService) Evaluate(ctx context.Context) (int, error) {
+	now := s.now()
+	recentSince := now.Add(-time.Duration(s.cfg.RecentWindowMinutes) * time.Minute)
+	baselineSince := recentSince.AddDate(0, 0, -s.cfg.BaselineDays)
+
+	stats, err := s.repo.ComputeCostStats(ctx, baselineSince, recentSince, s.cfg.MinRecentRequests)
+	if err != nil {
+		return 0, err
+	}
+	raised := 0
+	for _, st := range stats {
+		ratio, anomalous := detectAccountCostAnomaly(st, s.cfg)
+		if !anomalous {
+			continue
+		}
+		samples, err := s.repo.ListSampleRequestIDs(ctx, st.AccountID, st.Model, recentSince, accountCostAnomalySampleSize)
+		if err != nil {
+			logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] list samples failed: account_id=%d model=%s err=%v", st.AccountID, st.Model, err)
+		}
+		anomaly := &AccountCostAnomaly{
+			AccountID:        st.AccountID,
+			AccountName:      st.AccountName,
+			Model:            st.Model,
+			BaselineRequests: st.BaselineRequests,
+			BaselineAvgCost:  st.BaselineAvgCost,
+			BaselineP95Cost:  st.BaselineP95Cost,
+			RecentRequests:   st.RecentRequests,
+			RecentAvgCost:    st.RecentAvgCost,
+			RecentP95Cost:    st.RecentP95Cost,
+			DeviationRatio:   ratio,
+			SampleRequestIDs: samples,
+			Throttled:        s.cfg.ThrottleEnabled,
+			Status:           AccountCostAnomalyStatusOpen,
+			DetectedAt:       now,
+			LastDetectedAt:   now,
+		}
+		saved, created, err := s.repo.UpsertOpen(ctx, anomaly)
+		if err != nil {
+			logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] save anomaly failed: account_id=%d model=%s err=%v", st.AccountID, st.Model, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		if saved.AccountName == "" {
+			saved.AccountName = st.AccountName
+		}
+		raised++
+		if saved.Throttled {
+			s.throttle(ctx, saved, now)
+		}
+		if s.opsRepo != nil {
+			if _, err := s.opsRepo.CreateAlertEvent(ctx, buildAccountCostAnomalyAlertEvent(saved, now)); err != nil {
+				logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] create alert event failed: account_id=%d err=%v", saved.AccountID, err)
+			}
+		}
+	}
+	return raised, nil
+}
+
+// detectAccountCostAnomaly 近期平均单请求成本是否达到基线的 deviation_ratio 倍，返回实际倍数。
+func detectAccountCostAnomaly(st *AccountCostStats, cfg config.CostAnomalyConfig) (float64, bool) {
+	if st == nil || st.BaselineAvgCost <= 0 || st.RecentAvgCost <= 0 {
+		return 0, false
+	}
+	if st.BaselineRequests < int64(cfg.MinBaselineRequests) || st.RecentRequests < int64(cfg.MinRecentRequests) {
+		return 0, false
+	}
+	ratio := st.RecentAvgCost / st.BaselineAvgCost
+	return ratio, ratio >= cfg.DeviationRatio
+}
+
+func (s *AccountCostAnomalyService) throttle(ctx context.Context, anomaly *AccountCostAnomaly, now time.Time) {
+	if s.accountRepo == nil {
+		return
+	}
+	until := now.Add(time.Duration(s.cfg.ThrottleMinutes) * time.Minute)
+	reason := fmt.Sprintf("%s: model %s cost x%.1f", accountCostAnomalyThrottleReasonPrefix, anomaly.Model, anomaly.DeviationRatio)
+	if err := s.accountRepo.SetTempUnschedulable(ctx, anomaly.AccountID, until, reason); err != nil {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] throttle account failed: account_id=%d err=%v", anomaly.AccountID, err)
+		return
+	}
+	logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] account %d throttled until %s pending review", anomaly.AccountID, until.Format(time.RFC3339))
+}
+
+func buildAccountCostAnomalyAlertEvent(anomaly *AccountCostAnomaly, now time.Time) *OpsAlertEvent {
+	name := strings.TrimSpace(anomaly.AccountName)
+	if name == "" {
+		name = fmt.Sprintf("#%d", anomaly.AccountID)
+	}
+	ratio := anomaly.DeviationRatio
+	description := fmt.Sprintf("account %s model %s: recent avg cost %.6f USD/request over %d requests vs baseline %.6f over %d (x%.1f); p95 %.6f vs %.6f",
+		name, anomaly.Model, anomaly.RecentAvgCost, anomaly.RecentRequests, anomaly.BaselineAvgCost, anomaly.BaselineRequests,
+		ratio, anomaly.RecentP95Cost, anomaly.BaselineP95Cost)
+	if len(anomaly.SampleRequestIDs) > 0 {
+		description += "; sample requests: " + strings.Join(anomaly.SampleRequestIDs, ", ")
+	}
+	if anomaly.Throttled {
+		description += "; account scheduling paused pending review"
+	}
+	baseline := anomaly.BaselineAvgCost
+	recent := anomaly.RecentAvgCost
+	return &OpsAlertEvent{
+		Severity:       "P1",
+		Status:         OpsAlertStatusFiring,
+		Title:          fmt.Sprintf("P1: Upstream cost anomaly: %s / %s", name, anomaly.Model),
+		Description:    description,
+		MetricValue:    &recent,
+		ThresholdValue: &baseline,
+		Dimensions: map[string]any{
+			"kind":               "account_cost_anomaly",
+			"anomaly_id":         anomaly.ID,
+			"account_id":         anomaly.AccountID,
+			"model":              anomaly.Model,
+			"deviation_ratio":    ratio,
+			"sample_request_ids": anomaly.SampleRequestIDs,
+			"throttled":          anomaly.Throttled,
+		},
+		FiredAt:   now,
+		CreatedAt: now,
+	}
+}
+
+// List 分页查询成本异常。
+func (s *AccountCostAnomalyService) List(ctx context.Context, filter *AccountCostAnomalyFilter) (*AccountCostAnomalyList, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Review 标记异常已审核；该账号没有其他暂停中的异常时恢复调度。
+func (s *AccountCostAnomalyService) Review(ctx context.Context, id int64, reviewerID int64, note string) (*AccountCostAnomaly, error) {
+	reviewed, err := s.repo.MarkReviewed(ctx, id, reviewerID, strings.TrimSpace(note))
+	if err != nil {
+		return nil, err
+	}
+	if reviewed.Throttled {
+		s.releaseThrottle(ctx, reviewed.AccountID)
+	}
+	return reviewed, nil
+}
+
+func (s *AccountCostAnomalyService) releaseThrottle(ctx context.Context, accountID int64) {
+	if s.accountRepo == nil {
+		return
+	}
+	remaining, err := s.repo.CountOpenThrottled(ctx, accountID)
+	if err != nil || remaining > 0 {
+		return
+	}
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil || account == nil {
+		return
+	}
+	// 暂停已被其他原因（限流、鉴权失败等）覆盖时不解除
+	if !strings.HasPrefix(account.TempUnschedulableReason, accountCostAnomalyThrottleReasonPrefix) {
+		return
+	}
+	if err := s.accountRepo.ClearTempUnschedulable(ctx, accountID); err != nil {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] release throttle failed: account_id=%d err=%v", accountID, err)
+	}
+}
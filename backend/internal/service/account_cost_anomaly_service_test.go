@@ -0,0 +1,226 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type costAnomalyStubKey struct {
+	accountID int64
+	model     string
+}
+
+type costAnomalyRepoStub struct {
+	AccountCostAnomalyRepository
+	stats    []*AccountCostStats
+	samples  []string
+	open     map[costAnomalyStubKey]*AccountCostAnomaly
+	byID     map[int64]*AccountCostAnomaly
+	nextID   int64
+	upserted int
+}
+
+func (s *costAnomalyRepoStub) ComputeCostStats(context.Context, time.Time, time.Time, int) ([]*AccountCostStats, error) {
+	return s.stats, nil
+}
+
+func (s *costAnomalyRepoStub) ListSampleRequestIDs(context.Context, int64, string, time.Time, int) ([]string, error) {
+	return s.samples, nil
+}
+
+func (s *costAnomalyRepoStub) UpsertOpen(_ context.Context, anomaly *AccountCostAnomaly) (*AccountCostAnomaly, bool, error) {
+	if s.open == nil {
+		s.open = map[costAnomalyStubKey]*AccountCostAnomaly{}
+		s.byID = map[int64]*AccountCostAnomaly{}
+	}
+	s.upserted++
+	key := costAnomalyStubKey{anomaly.AccountID, anomaly.Model}
+	if existing, ok := s.open[key]; ok {
+		existing.RecentAvgCost = anomaly.RecentAvgCost
+		existing.LastDetectedAt = anomaly.LastDetectedAt
+		return existing, false, nil
+	}
+	s.nextID++
+	cp := *anomaly
+	cp.ID = s.nextID
+	s.open[key] = &cp
+	s.byID[cp.ID] = &cp
+	return &cp, true, nil
+}
+
+func (s *costAnomalyRepoStub) MarkReviewed(_ context.Context, id int64, reviewerID int64, note string) (*AccountCostAnomaly, error) {
+	item, ok := s.byID[id]
+	if !ok || item.Status != AccountCostAnomalyStatusOpen {
+		return nil, ErrAccountCostAnomalyNotFound
+	}
+	item.Status = AccountCostAnomalyStatusReviewed
+	item.ReviewNote = note
+	item.ReviewedBy = &reviewerID
+	delete(s.open, costAnomalyStubKey{item.AccountID, item.Model})
+	return item, nil
+}
+
+func (s *costAnomalyRepoStub) CountOpenThrottled(_ context.Context, accountID int64) (int, error) {
+	n := 0
+	for _, item := range s.open {
+		if item.AccountID == accountID && item.Throttled {
+			n++
+		}
+	}
+	return n, nil
+}
+
+type costAnomalyAccountRepoStub struct {
+	AccountRepository
+	account *Account
+	cleared int
+}
+
+func (s *costAnomalyAccountRepoStub) SetTempUnschedulable(_ context.Context, _ int64, until time.Time, reason string) error {
+	s.account.TempUnschedulableUntil = &until
+	s.account.TempUnschedulableReason = reason
+	return nil
+}
+
+func (s *costAnomalyAccountRepoStub) ClearTempUnschedulable(context.Context, int64) error {
+	s.account.TempUnschedulableUntil = nil
+	s.account.TempUnschedulableReason = ""
+	s.cleared++
+	return nil
+}
+
+func (s *costAnomalyAccountRepoStub) GetByID(context.Context, int64) (*Account, error) {
+	cp := *s.account
+	return &cp, nil
+}
+
+func testCostAnomalyConfig(throttle bool) *config.Config {
+	return &config.Config{CostAnomaly: config.CostAnomalyConfig{
+		Enabled:              true,
+		CheckIntervalMinutes: 10,
+		RecentWindowMinutes:  60,
+		BaselineDays:         7,
+		MinRecentRequests:    20,
+		MinBaselineRequests:  100,
+		DeviationRatio:       3,
+		ThrottleEnabled:      throttle,
+		ThrottleMinutes:      240,
+	}}
+}
+
+func TestDetectAccountCostAnomaly(t *testing.T) {
+	cfg := testCostAnomalyConfig(false).CostAnomaly
+	base := AccountCostStats{BaselineRequests: 500, BaselineAvgCost: 0.01, RecentRequests: 50, RecentAvgCost: 0.05}
+
+	ratio, ok := detectAccountCostAnomaly(&base, cfg)
+	require.True(t, ok)
+	require.InDelta(t, 5.0, ratio, 1e-9)
+
+	normal := base
+	normal.RecentAvgCost = 0.02
+	_, ok = detectAccountCostAnomaly(&normal, cfg)
+	require.False(t, ok)
+
+	// 基线样本不足（新账号 / 新模型）不检测
+	sparse := base
+	sparse.BaselineRequests = 10
+	_, ok = detectAccountCostAnomaly(&sparse, cfg)
+	require.False(t, ok)
+
+	quiet := base
+	quiet.RecentRequests = 5
+	_, ok = detectAccountCostAnomaly(&quiet, cfg)
+	require.False(t, ok)
+
+	_, ok = detectAccountCostAnomaly(&AccountCostStats{BaselineRequests: 500, RecentRequests: 50, RecentAvgCost: 1}, cfg)
+	require.False(t, ok)
+}
+
+func TestAccountCostAnomalyEvaluate_AlertsOnceAndThrottles(t *testing.T) {
+	repo := &costAnomalyRepoStub{
+		stats: []*AccountCostStats{
+			{AccountID: 1, AccountName: "team-a", Model: "claude-sonnet-4-6", BaselineRequests: 500, BaselineAvgCost: 0.01, RecentRequests: 40, RecentAvgCost: 0.08},
+			{AccountID: 2, Model: "gpt-5", BaselineRequests: 500, BaselineAvgCost: 0.01, RecentRequests: 40, RecentAvgCost: 0.012},
+		},
+		samples: []string{"req-1", "req-2"},
+	}
+	accounts := &costAnomalyAccountRepoStub{account: &Account{ID: 1}}
+	ops := &renewalAlertOpsRepoStub{}
+	svc := NewAccountCostAnomalyService(repo, accounts, ops, testCostAnomalyConfig(true))
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	raised, err := svc.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, raised)
+	require.Len(t, ops.events, 1)
+	event := ops.events[0]
+	require.Equal(t, "account_cost_anomaly", event.Dimensions["kind"])
+	require.Equal(t, int64(1), event.Dimensions["account_id"])
+	require.Equal(t, []string{"req-1", "req-2"}, event.Dimensions["sample_request_ids"])
+	require.Contains(t, event.Title, "team-a")
+	require.Contains(t, event.Description, "req-1, req-2")
+
+	require.NotNil(t, accounts.account.TempUnschedulableUntil)
+	require.Equal(t, now.Add(240*time.Minute), *accounts.account.TempUnschedulableUntil)
+	require.True(t, strings.HasPrefix(accounts.account.TempUnschedulableReason, accountCostAnomalyThrottleReasonPrefix))
+
+	// 仍在异常中：刷新指标但不重复告警
+	raised, err = svc.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, raised)
+	require.Len(t, ops.events, 1)
+	require.Equal(t, 2, repo.upserted)
+
+	reviewed, err := svc.Review(context.Background(), 1, 9, " expected: long-context migration ")
+	require.NoError(t, err)
+	require.Equal(t, AccountCostAnomalyStatusReviewed, reviewed.Status)
+	require.Equal(t, "expected: long-context migration", reviewed.ReviewNote)
+	require.Equal(t, 1, accounts.cleared)
+	require.Nil(t, accounts.account.TempUnschedulableUntil)
+
+	_, err = svc.Review(context.Background(), 1, 9, "")
+	require.ErrorIs(t, err, ErrAccountCostAnomalyNotFound)
+}
+
+func TestAccountCostAnomalyReview_KeepsForeignTempUnschedulable(t *testing.T) {
+	repo := &costAnomalyRepoStub{
+		stats: []*AccountCostStats{
+			{AccountID: 1, Model: "claude-sonnet-4-6", BaselineRequests: 500, BaselineAvgCost: 0.01, RecentRequests: 40, RecentAvgCost: 0.08},
+		},
+	}
+	accounts := &costAnomalyAccountRepoStub{account: &Account{ID: 1}}
+	svc := NewAccountCostAnomalyService(repo, accounts, nil, testCostAnomalyConfig(true))
+
+	_, err := svc.Evaluate(context.Background())
+	require.NoError(t, err)
+
+	// 之后账号因其他原因（如限流）被暂停，审核不应解除
+	accounts.account.TempUnschedulableReason = "rate limited"
+	_, err = svc.Review(context.Background(), 1, 9, "")
+	require.NoError(t, err)
+	require.Zero(t, accounts.cleared)
+}
+
+func TestAccountCostAnomalyEvaluate_NoThrottleWhenDisabled(t *testing.T) {
+	repo := &costAnomalyRepoStub{
+		stats: []*AccountCostStats{
+			{AccountID: 1, Model: "claude-sonnet-4-6", BaselineRequests: 500, BaselineAvgCost: 0.01, RecentRequests: 40, RecentAvgCost: 0.08},
+		},
+	}
+	accounts := &costAnomalyAccountRepoStub{account: &Account{ID: 1}}
+	svc := NewAccountCostAnomalyService(repo, accounts, &renewalAlertOpsRepoStub{}, testCostAnomalyConfig(false))
+
+	raised, err := svc.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, raised)
+	require.Nil(t, accounts.account.TempUnschedulableUntil)
+	require.False(t, repo.byID[1].Throttled)
+}
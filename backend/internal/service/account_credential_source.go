@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 外部密钥库类型
+const (
+	CredentialSourceProviderVault = "vault" // HashiCorp Vault KV v2
+	CredentialSourceProviderAWS   = "aws"   // AWS Secrets Manager
+)
+
+var (
+	ErrCredentialSourceNotFound           = infraerrors.NotFound("CREDENTIAL_SOURCE_NOT_FOUND", "account credential source not found")
+	ErrCredentialSourceInvalidProvider    = infraerrors.BadRequest("CREDENTIAL_SOURCE_INVALID_PROVIDER", "provider must be one of: vault, aws")
+	ErrCredentialSourceInvalidPath        = infraerrors.BadRequest("CREDENTIAL_SOURCE_INVALID_PATH", "secret_path is required")
+	ErrCredentialSourceInvalidFieldMap    = infraerrors.BadRequest("CREDENTIAL_SOURCE_INVALID_FIELD_MAP", "field_map keys and values must be non-empty")
+	ErrCredentialSourceShadowAccount      = infraerrors.BadRequest("CREDENTIAL_SOURCE_SHADOW_ACCOUNT", "shadow accounts inherit credentials from their parent account")
+	ErrCredentialSyncDisabled             = infraerrors.Forbidden("CREDENTIAL_SYNC_DISABLED", "credential sync is disabled")
+	ErrCredentialSourceProviderNotEnabled = infraerrors.BadRequest("CREDENTIAL_SOURCE_PROVIDER_NOT_CONFIGURED", "secret store provider is not configured")
+	ErrCredentialSecretMissingField       = infraerrors.BadRequest("CREDENTIAL_SECRET_MISSING_FIELD", "secret does not contain a mapped field")
+	ErrCredentialSecretEmpty              = infraerrors.BadRequest("CREDENTIAL_SECRET_EMPTY", "secret has no fields")
+)
+
+// AccountCredentialSource 账号凭据的外部来源（与账号一对一）。
+type AccountCredentialSource struct {
+	AccountID  int64  `json:"account_id"`
+	Provider   string `json:"provider"`
+	SecretPath string `json:"secret_path"`
+	// FieldMap 凭据键 -> 密钥字段；为空时把密钥的全部顶层字段写入凭据
+	FieldMap     map[string]string `json:"field_map"`
+	LastSyncedAt *time.Time        `json:"last_synced_at,omitempty"`
+	// LastVersion 最近一次成功同步的密钥版本（Vault 版本号 / AWS VersionId）
+	LastVersion string    `json:"last_version"`
+	LastError   string    `json:"last_error"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UpsertAccountCredentialSourceInput 创建或覆盖账号凭据来源的参数。
+type UpsertAccountCredentialSourceInput struct {
+	AccountID  int64
+	Provider   string
+	SecretPath string
+	FieldMap   map[string]string
+}
+
+// AccountCredentialSyncResult 单个账号的同步结果。
+type AccountCredentialSyncResult struct {
+	AccountID int64 `json:"account_id"`
+	// Changed 凭据是否发生变化并已写回
+	Changed bool     `json:"changed"`
+	Version string   `json:"version"`
+	Keys    []string `json:"keys"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// CredentialSecret 从外部密钥库读取到的密钥内容。
+type CredentialSecret struct {
+	Values  map[string]any
+	Version string
+}
+
+// CredentialSecretFetcher 外部密钥库读取端口；provider 未配置时返回 ErrCredentialSourceProviderNotEnabled。
+type CredentialSecretFetcher interface {
+	Fetch(ctx context.Context, provider, path string) (*CredentialSecret, error)
+}
+
+// AccountCredentialSourceRepository 账号凭据来源持久化端口。
+type AccountCredentialSourceRepository interface {
+	GetByAccountID(ctx context.Context, accountID int64) (*AccountCredentialSource, error)
+	// Upsert 账号不存在（或已软删除）时返回 ErrAccountNotFound。
+	Upsert(ctx context.Context, source *AccountCredentialSource) (*AccountCredentialSource, error)
+	Delete(ctx context.Context, accountID int64) error
+	List(ctx context.Context) ([]*AccountCredentialSource, error)
+	// RecordSyncResult 记录一次同步结果；syncErr 为空表示成功（同时更新版本与同步时间）。
+	RecordSyncResult(ctx context.Context, accountID int64, syncedAt time.Time, version, syncErr string) error
+}
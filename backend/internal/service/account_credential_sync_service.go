@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	accountCredentialSyncLeaderLockKey = "account:credential_sync:leader"
+	accountCredentialSyncLeaderLockTTL = 10 * time.Minute
+)
+
+// AccountCredentialSyncService 从外部密钥库（Vault / AWS Secrets Manager）同步账号凭据。
+//
+// 每个账号最多映射一个密钥路径；同步时按 FieldMap 把密钥字段合并进账号现有凭据，
+// 只有值发生变化才写回并清理 token 缓存，因此定时同步对未轮换的密钥是幂等的。
+type AccountCredentialSyncService struct {
+	repo             AccountCredentialSourceRepository
+	accountRepo      AccountRepository
+	fetcher          CredentialSecretFetcher
+	cacheInvalidator TokenCacheInvalidator
+	cfg              *config.Config
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+func NewAccountCredentialSyncService(
+	repo AccountCredentialSourceRepository,
+	accountRepo AccountRepository,
+	fetcher CredentialSecretFetcher,
+	cacheInvalidator TokenCacheInvalidator,
+	cfg *config.Config,
+) *AccountCredentialSyncService {
+	return &AccountCredentialSyncService{
+		repo:             repo,
+		accountRepo:      accountRepo,
+		fetcher:          fetcher,
+		cacheInvalidator: cacheInvalidator,
+		cfg:              cfg,
+		stopCh:           make(chan struct{}),
+		instanceID:       uuid.NewString(),
+		now:              time.Now,
+	}
+}
+
+// SetLeaderLock injects the leader-lock cache and DB so that only one instance
+// runs the scheduled sync per cycle.
+func (s *AccountCredentialSyncService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Enabled 全局开关是否开启。
+func (s *AccountCredentialSyncService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.CredentialSync.Enabled && s.fetcher != nil
+}
+
+func (s *AccountCredentialSyncService) Start() {
+	if !s.Enabled() || s.repo == nil || s.accountRepo == nil || s.cfg.CredentialSync.IntervalMinutes <= 0 {
+		return
+	}
+	interval := time.Duration(s.cfg.CredentialSync.IntervalMinutes) * time.Minute
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *AccountCredentialSyncService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *AccountCredentialSyncService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, accountCredentialSyncLeaderLockKey, s.instanceID, accountCredentialSyncLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	results, err := s.SyncAll(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.account_credential_sync", "[CredentialSync] list sources failed: %v", err)
+		return
+	}
+	changed, failed := 0, 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		} else if result.Changed {
+			changed++
+		}
+	}
+	if changed > 0 || failed > 0 {
+		logger.LegacyPrintf("service.account_credential_sync", "[CredentialSync] synced %d sources: changed=%d failed=%d", len(results), changed, failed)
+	}
+}
+
+// Get 查询账号的凭据来源。
+func (s *AccountCredentialSyncService) Get(ctx context.Context, accountID int64) (*AccountCredentialSource, error) {
+	return s.repo.GetByAccountID(ctx, accountID)
+}
+
+// List 列出全部凭据来源。
+func (s *AccountCredentialSyncService) List(ctx context.Context) ([]*AccountCredentialSource, error) {
+	return s.repo.List(ctx)
+}
+
+// Upsert 创建或覆盖账号的凭据来源；不会立即同步。
+func (s *AccountCredentialSyncService) Upsert(ctx context.Context, input *UpsertAccountCredentialSourceInput) (*AccountCredentialSource, error) {
+	provider := strings.ToLower(strings.TrimSpace(input.Provider))
+	if provider != CredentialSourceProviderVault && provider != CredentialSourceProviderAWS {
+		return nil, ErrCredentialSourceInvalidProvider
+	}
+	path := strings.Trim(strings.TrimSpace(input.SecretPath), "/")
+	if path == "" {
+		return nil, ErrCredentialSourceInvalidPath
+	}
+	fieldMap := make(map[string]string, len(input.FieldMap))
+	for key, field := range input.FieldMap {
+		key, field = strings.TrimSpace(key), strings.TrimSpace(field)
+		if key == "" || field == "" {
+			return nil, ErrCredentialSourceInvalidFieldMap
+		}
+		fieldMap[key] = field
+	}
+	account, err := s.accountRepo.GetByID(ctx, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsCredentialShadow() {
+		return nil, ErrCredentialSourceShadowAccount
+	}
+	return s.repo.Upsert(ctx, &AccountCredentialSource{
+		AccountID:  input.AccountID,
+		Provider:   provider,
+		SecretPath: path,
+		FieldMap:   fieldMap,
+	})
+}
+
+// Delete 删除账号的凭据来源；账号现有凭据保持不变。
+func (s *AccountCredentialSyncService) Delete(ctx context.Context, accountID int64) error {
+	return s.repo.Delete(ctx, accountID)
+}
+
+// SyncAll 依次同步全部凭据来源；单个账号失败不影响其余账号，失败原因记录在结果与来源的 last_error 中。
+func (s *AccountCredentialSyncService) SyncAll(ctx context.Context) ([]*AccountCredentialSyncResult, error) {
+	if !s.Enabled() {
+		return nil, ErrCredentialSyncDisabled
+	}
+	sources, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*AccountCredentialSyncResult, 0, len(sources))
+	for _, source := range sources {
+		if ctx.Err() != nil {
+			break
+		}
+		result, _ := s.syncSource(ctx, source)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SyncAccount 立即同步单个账号的凭据。
+func (s *AccountCredentialSyncService) SyncAccount(ctx context.Context, accountID int64) (*AccountCredentialSyncResult, error) {
+	if !s.Enabled() {
+		return nil, ErrCredentialSyncDisabled
+	}
+	source, err := s.repo.GetByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return s.syncSource(ctx, source)
+}
+
+func (s *AccountCredentialSyncService) syncSource(ctx context.Context, source *AccountCredentialSource) (*AccountCredentialSyncResult, error) {
+	result := &AccountCredentialSyncResult{AccountID: source.AccountID}
+	syncErr := s.applySource(ctx, source, result)
+	errMsg := ""
+	if syncErr != nil {
+		errMsg = syncErr.Error()
+		result.Error = errMsg
+		logger.LegacyPrintf("service.account_credential_sync", "[CredentialSync] sync failed: account_id=%d provider=%s err=%v", source.AccountID, source.Provider, syncErr)
+	}
+	if err := s.repo.RecordSyncResult(ctx, source.AccountID, s.now().UTC(), result.Version, errMsg); err != nil {
+		logger.LegacyPrintf("service.account_credential_sync", "[CredentialSync] record result failed: account_id=%d err=%v", source.AccountID, err)
+	}
+	return result, syncErr
+}
+
+func (s *AccountCredentialSyncService) applySource(ctx context.Context, source *AccountCredentialSource, result *AccountCredentialSyncResult) error {
+	account, err := s.accountRepo.GetByID(ctx, source.AccountID)
+	if err != nil {
+		return err
+	}
+	if account.IsCredentialShadow() {
+		return ErrCredentialSourceShadowAccount
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.CredentialSync.TimeoutSeconds)*time.Second)
+	defer cancel()
+	secret, err := s.fetcher.Fetch(fetchCtx, source.Provider, source.SecretPath)
+	if err != nil {
+		return err
+	}
+	result.Version = secret.Version
+
+	values, err := mapCredentialSecret(secret.Values, source.FieldMap)
+	if err != nil {
+		return err
+	}
+	result.Keys = make([]string, 0, len(values))
+	for key := range values {
+		result.Keys = append(result.Keys, key)
+	}
+	sort.Strings(result.Keys)
+
+	merged := shallowCopyMap(account.Credentials)
+	if merged == nil {
+		merged = make(map[string]any, len(values))
+	}
+	for key, value := range values {
+		if current, ok := merged[key]; ok && reflect.DeepEqual(current, value) {
+			continue
+		}
+		merged[key] = value
+		result.Changed = true
+	}
+	if !result.Changed {
+		return nil
+	}
+	if err := persistAccountCredentials(ctx, s.accountRepo, account, merged); err != nil {
+		return fmt.Errorf("persist credentials: %w", err)
+	}
+	if s.cacheInvalidator != nil {
+		if err := s.cacheInvalidator.InvalidateToken(ctx, account); err != nil {
+			logger.LegacyPrintf("service.account_credential_sync", "[CredentialSync] invalidate token cache failed: account_id=%d err=%v", account.ID, err)
+		}
+	}
+	return nil
+}
+
+// mapCredentialSecret 按 fieldMap 从密钥中取出凭据值；fieldMap 为空时取全部顶层字段。
+func mapCredentialSecret(secret map[string]any, fieldMap map[string]string) (map[string]any, error) {
+	if len(fieldMap) == 0 {
+		if len(secret) == 0 {
+			return nil, ErrCredentialSecretEmpty
+		}
+		return shallowCopyMap(secret), nil
+	}
+	out := make(map[string]any, len(fieldMap))
+	for key, field := range fieldMap {
+		value, ok := secret[field]
+		if !ok || value == nil {
+			return nil, fmt.Errorf("%w: %s", ErrCredentialSecretMissingField, field)
+		}
+		out[key] = value
+	}
+	return out, nil
+}
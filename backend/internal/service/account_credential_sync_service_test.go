@@ -0,0 +1,198 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type credentialSyncAccountRepoStub struct {
+	AccountRepository
+	account      *Account
+	updateCalls  int
+	updatedCreds map[string]any
+}
+
+func (r *credentialSyncAccountRepoStub) GetByID(ctx context.Context, id int64) (*Account, error) {
+	if r.account == nil || r.account.ID != id {
+		return nil, ErrAccountNotFound
+	}
+	return r.account, nil
+}
+
+func (r *credentialSyncAccountRepoStub) UpdateCredentials(ctx context.Context, id int64, credentials map[string]any) error {
+	r.updateCalls++
+	r.updatedCreds = credentials
+	return nil
+}
+
+type credentialSourceRepoStub struct {
+	AccountCredentialSourceRepository
+	sources     map[int64]*AccountCredentialSource
+	lastVersion string
+	lastError   string
+	recordCalls int
+}
+
+func (r *credentialSourceRepoStub) GetByAccountID(ctx context.Context, accountID int64) (*AccountCredentialSource, error) {
+	source, ok := r.sources[accountID]
+	if !ok {
+		return nil, ErrCredentialSourceNotFound
+	}
+	return source, nil
+}
+
+func (r *credentialSourceRepoStub) Upsert(ctx context.Context, source *AccountCredentialSource) (*AccountCredentialSource, error) {
+	r.sources[source.AccountID] = source
+	return source, nil
+}
+
+func (r *credentialSourceRepoStub) List(ctx context.Context) ([]*AccountCredentialSource, error) {
+	out := make([]*AccountCredentialSource, 0, len(r.sources))
+	for _, source := range r.sources {
+		out = append(out, source)
+	}
+	return out, nil
+}
+
+func (r *credentialSourceRepoStub) RecordSyncResult(ctx context.Context, accountID int64, syncedAt time.Time, version, syncErr string) error {
+	r.recordCalls++
+	r.lastVersion = version
+	r.lastError = syncErr
+	return nil
+}
+
+type credentialSecretFetcherStub struct {
+	secret *CredentialSecret
+	err    error
+	calls  int
+}
+
+func (f *credentialSecretFetcherStub) Fetch(ctx context.Context, provider, path string) (*CredentialSecret, error) {
+	f.calls++
+	return f.secret, f.err
+}
+
+type credentialSyncInvalidatorStub struct {
+	calls int
+}
+
+func (i *credentialSyncInvalidatorStub) InvalidateToken(ctx context.Context, account *Account) error {
+	i.calls++
+	return nil
+}
+
+func newCredentialSyncTestService(account *Account, source *AccountCredentialSource, fetcher *credentialSecretFetcherStub) (*AccountCredentialSyncService, *credentialSyncAccountRepoStub, *credentialSourceRepoStub, *credentialSyncInvalidatorStub) {
+	cfg := &config.Config{}
+	cfg.CredentialSync.Enabled = true
+	cfg.CredentialSync.TimeoutSeconds = 5
+	accountRepo := &credentialSyncAccountRepoStub{account: account}
+	sourceRepo := &credentialSourceRepoStub{sources: map[int64]*AccountCredentialSource{}}
+	if source != nil {
+		sourceRepo.sources[source.AccountID] = source
+	}
+	invalidator := &credentialSyncInvalidatorStub{}
+	return NewAccountCredentialSyncService(sourceRepo, accountRepo, fetcher, invalidator, cfg), accountRepo, sourceRepo, invalidator
+}
+
+func TestAccountCredentialSync_MergesMappedFields(t *testing.T) {
+	account := &Account{ID: 1, Platform: PlatformAnthropic, Type: AccountTypeOAuth, Credentials: map[string]any{
+		"access_token":  "old",
+		"model_mapping": map[string]any{"a": "b"},
+	}}
+	source := &AccountCredentialSource{AccountID: 1, Provider: CredentialSourceProviderVault, SecretPath: "llm/claude-1", FieldMap: map[string]string{"access_token": "token"}}
+	fetcher := &credentialSecretFetcherStub{secret: &CredentialSecret{Values: map[string]any{"token": "new", "unrelated": "x"}, Version: "3"}}
+	svc, accountRepo, sourceRepo, invalidator := newCredentialSyncTestService(account, source, fetcher)
+
+	result, err := svc.SyncAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, result.Changed)
+	require.Equal(t, "3", result.Version)
+	require.Equal(t, []string{"access_token"}, result.Keys)
+
+	require.Equal(t, 1, accountRepo.updateCalls)
+	require.Equal(t, "new", accountRepo.updatedCreds["access_token"])
+	require.Equal(t, map[string]any{"a": "b"}, accountRepo.updatedCreds["model_mapping"], "unmapped credentials are kept")
+	require.NotContains(t, accountRepo.updatedCreds, "unrelated")
+	require.Equal(t, 1, invalidator.calls)
+	require.Equal(t, "3", sourceRepo.lastVersion)
+	require.Empty(t, sourceRepo.lastError)
+}
+
+func TestAccountCredentialSync_UnchangedSecretSkipsWrite(t *testing.T) {
+	account := &Account{ID: 1, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "sk-1"}}
+	source := &AccountCredentialSource{AccountID: 1, Provider: CredentialSourceProviderAWS, SecretPath: "prod/openai"}
+	fetcher := &credentialSecretFetcherStub{secret: &CredentialSecret{Values: map[string]any{"api_key": "sk-1"}, Version: "v1"}}
+	svc, accountRepo, sourceRepo, invalidator := newCredentialSyncTestService(account, source, fetcher)
+
+	result, err := svc.SyncAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.False(t, result.Changed)
+	require.Zero(t, accountRepo.updateCalls)
+	require.Zero(t, invalidator.calls)
+	require.Equal(t, 1, sourceRepo.recordCalls)
+}
+
+func TestAccountCredentialSync_MissingFieldRecordsError(t *testing.T) {
+	account := &Account{ID: 1, Credentials: map[string]any{"api_key": "sk-1"}}
+	source := &AccountCredentialSource{AccountID: 1, Provider: CredentialSourceProviderVault, SecretPath: "p", FieldMap: map[string]string{"api_key": "key"}}
+	fetcher := &credentialSecretFetcherStub{secret: &CredentialSecret{Values: map[string]any{"other": "x"}}}
+	svc, accountRepo, sourceRepo, _ := newCredentialSyncTestService(account, source, fetcher)
+
+	_, err := svc.SyncAccount(context.Background(), 1)
+	require.ErrorIs(t, err, ErrCredentialSecretMissingField)
+	require.Zero(t, accountRepo.updateCalls)
+	require.Contains(t, sourceRepo.lastError, "key")
+}
+
+func TestAccountCredentialSync_SyncAllContinuesAfterFailure(t *testing.T) {
+	account := &Account{ID: 1, Credentials: map[string]any{}}
+	fetcher := &credentialSecretFetcherStub{err: errors.New("vault: unexpected status 403")}
+	svc, _, sourceRepo, _ := newCredentialSyncTestService(account, nil, fetcher)
+	sourceRepo.sources[1] = &AccountCredentialSource{AccountID: 1, Provider: CredentialSourceProviderVault, SecretPath: "a"}
+	sourceRepo.sources[2] = &AccountCredentialSource{AccountID: 2, Provider: CredentialSourceProviderVault, SecretPath: "b"}
+
+	results, err := svc.SyncAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		require.NotEmpty(t, result.Error)
+	}
+	require.Equal(t, 2, sourceRepo.recordCalls)
+}
+
+func TestAccountCredentialSync_DisabledAndValidation(t *testing.T) {
+	account := &Account{ID: 1}
+	svc, _, _, _ := newCredentialSyncTestService(account, nil, &credentialSecretFetcherStub{})
+
+	_, err := svc.Upsert(context.Background(), &UpsertAccountCredentialSourceInput{AccountID: 1, Provider: "gcp", SecretPath: "x"})
+	require.ErrorIs(t, err, ErrCredentialSourceInvalidProvider)
+	_, err = svc.Upsert(context.Background(), &UpsertAccountCredentialSourceInput{AccountID: 1, Provider: "vault", SecretPath: " / "})
+	require.ErrorIs(t, err, ErrCredentialSourceInvalidPath)
+	_, err = svc.Upsert(context.Background(), &UpsertAccountCredentialSourceInput{AccountID: 1, Provider: "vault", SecretPath: "x", FieldMap: map[string]string{"api_key": ""}})
+	require.ErrorIs(t, err, ErrCredentialSourceInvalidFieldMap)
+
+	source, err := svc.Upsert(context.Background(), &UpsertAccountCredentialSourceInput{AccountID: 1, Provider: " Vault ", SecretPath: "/llm/a/"})
+	require.NoError(t, err)
+	require.Equal(t, CredentialSourceProviderVault, source.Provider)
+	require.Equal(t, "llm/a", source.SecretPath)
+
+	svc.cfg.CredentialSync.Enabled = false
+	_, err = svc.SyncAccount(context.Background(), 1)
+	require.ErrorIs(t, err, ErrCredentialSyncDisabled)
+}
+
+func TestMapCredentialSecret_EmptyFieldMapCopiesAll(t *testing.T) {
+	values, err := mapCredentialSecret(map[string]any{"api_key": "sk", "base_url": "https://x"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"api_key": "sk", "base_url": "https://x"}, values)
+
+	_, err = mapCredentialSecret(map[string]any{}, nil)
+	require.ErrorIs(t, err, ErrCredentialSecretEmpty)
+}
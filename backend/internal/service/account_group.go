@@ -1,13 +1,172 @@
 package service
 
-import "time"
+import (
+	"context"
+	"net/http"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 分组成员负载均衡权重（百分比）：100 表示按账号自身负载因子参与均衡，
+// 200 表示在该分组内按两倍负载因子计算负载率，即分得约两倍请求。
+const (
+	DefaultAccountGroupWeight = 100
+	MaxAccountGroupWeight     = 1000
+)
+
+var (
+	ErrAccountGroupMembershipNotFound = infraerrors.NotFound("ACCOUNT_GROUP_MEMBERSHIP_NOT_FOUND", "account is not a member of this group")
+	ErrInvalidAccountGroupMembership  = infraerrors.BadRequest("INVALID_ACCOUNT_GROUP_MEMBERSHIP", "invalid account group membership settings")
+)
 
 type AccountGroup struct {
 	AccountID int64
 	GroupID   int64
 	Priority  int
-	CreatedAt time.Time
+	// PriorityOverride 账号在该分组内的调度优先级，nil 表示沿用账号优先级
+	PriorityOverride *int
+	// Weight 账号在该分组内的负载均衡权重（百分比），0 按 DefaultAccountGroupWeight 处理
+	Weight int
+	// ConcurrencyCap 该分组的准入上限：并发槽位按账号计数、由所有分组共享，账号在途请求
+	// （计入所有分组）达到该值后不再为该分组调度；不为分组预留或隔离并发。
+	// nil 表示不限制；不小于账号并发时不生效
+	ConcurrencyCap *int
+	CreatedAt      time.Time
 
 	Account *Account
 	Group   *Group
 }
+
+// hasSchedulingOverrides 成员关系是否设置了任一调度覆盖。
+func (ag *AccountGroup) hasSchedulingOverrides() bool {
+	return ag.PriorityOverride != nil || ag.ConcurrencyCap != nil ||
+		(ag.Weight > 0 && ag.Weight != DefaultAccountGroupWeight)
+}
+
+// AccountGroupMembershipUpdate 描述成员调度覆盖的更新；Clear* 为 true 时清除对应覆盖。
+type AccountGroupMembershipUpdate struct {
+	PriorityOverride      *int `json:"priority_override"`
+	ClearPriorityOverride bool `json:"clear_priority_override"`
+	Weight                *int `json:"weight"`
+	ConcurrencyCap        *int `json:"concurrency_cap"`
+	ClearConcurrencyCap   bool `json:"clear_concurrency_cap"`
+}
+
+// Validate 校验成员调度覆盖的取值范围。
+func (u *AccountGroupMembershipUpdate) Validate() error {
+	if u.PriorityOverride != nil && *u.PriorityOverride < 0 {
+		return infraerrors.Newf(http.StatusBadRequest, ErrInvalidAccountGroupMembership.Reason, "priority_override must not be negative")
+	}
+	if u.Weight != nil && (*u.Weight < 1 || *u.Weight > MaxAccountGroupWeight) {
+		return infraerrors.Newf(http.StatusBadRequest, ErrInvalidAccountGroupMembership.Reason, "weight must be between 1 and %d", MaxAccountGroupWeight)
+	}
+	if u.ConcurrencyCap != nil && *u.ConcurrencyCap < 1 {
+		return infraerrors.Newf(http.StatusBadRequest, ErrInvalidAccountGroupMembership.Reason, "concurrency_cap must be at least 1")
+	}
+	return nil
+}
+
+// AccountGroupMembershipRepository 更新单个账号分组成员关系的调度覆盖。
+type AccountGroupMembershipRepository interface {
+	// UpdateAccountGroupMembership 更新成员覆盖并写入调度 outbox；成员关系不存在时返回 ErrAccountGroupMembershipNotFound。
+	UpdateAccountGroupMembership(ctx context.Context, accountID, groupID int64, update AccountGroupMembershipUpdate) (*AccountGroup, error)
+}
+
+// accountGroupMembership 返回账号在指定分组中的成员关系。
+func (a *Account) accountGroupMembership(groupID int64) *AccountGroup {
+	for i := range a.AccountGroups {
+		if a.AccountGroups[i].GroupID == groupID {
+			return &a.AccountGroups[i]
+		}
+	}
+	return nil
+}
+
+// appliedAccountGroupMembership 记录账号副本应用覆盖的分组与被覆盖前的原值。
+type appliedAccountGroupMembership struct {
+	groupID     int64
+	priority    int
+	concurrency int
+	loadFactor  *int
+}
+
+// withAccountGroupMembership 返回应用了账号在该分组中成员调度覆盖的副本：
+// 优先级覆盖替换 Priority，准入上限收紧 Concurrency（槽位仍按账号计数，因此是该分组的准入门槛而非独立配额），
+// 权重按比例缩放负载因子。未设置覆盖、已应用过或不属于该分组时原样返回。
+// 副本仅用于调度；写回数据库或调度缓存的边界会经 WithoutGroupMembership 还原原值。
+func (a *Account) withAccountGroupMembership(groupID *int64) *Account {
+	if a == nil || groupID == nil || *groupID <= 0 || a.membership != nil {
+		return a
+	}
+	membership := a.accountGroupMembership(*groupID)
+	if membership == nil || !membership.hasSchedulingOverrides() {
+		return a
+	}
+	out := *a
+	out.membership = &appliedAccountGroupMembership{
+		groupID:     *groupID,
+		priority:    a.Priority,
+		concurrency: a.Concurrency,
+		loadFactor:  a.LoadFactor,
+	}
+	if membership.PriorityOverride != nil {
+		out.Priority = *membership.PriorityOverride
+	}
+	if limit := membership.ConcurrencyCap; limit != nil && *limit > 0 && *limit < out.Concurrency {
+		out.Concurrency = *limit
+	}
+	if weight := membership.Weight; weight > 0 && weight != DefaultAccountGroupWeight {
+		loadFactor := out.EffectiveLoadFactor() * weight / DefaultAccountGroupWeight
+		if loadFactor < 1 {
+			loadFactor = 1
+		}
+		out.LoadFactor = &loadFactor
+	}
+	return &out
+}
+
+// WithoutGroupMembership 还原被成员覆盖的字段，供账号写回数据库或调度缓存时使用，避免分组视角的取值污染账号本身。
+// 未应用成员覆盖时原样返回。
+func (a *Account) WithoutGroupMembership() *Account {
+	if a == nil || a.membership == nil {
+		return a
+	}
+	out := *a
+	out.Priority = a.membership.priority
+	out.Concurrency = a.membership.concurrency
+	out.LoadFactor = a.membership.loadFactor
+	out.membership = nil
+	return &out
+}
+
+// inheritAccountGroupMembership 将 from 上已应用的成员调度覆盖同样应用到重新加载的 account 上，
+// 供选中后从快照/数据库刷新账号的路径保留分组视角的并发与优先级。
+func inheritAccountGroupMembership(account, from *Account) *Account {
+	if account == nil || from == nil || from.membership == nil {
+		return account
+	}
+	groupID := from.membership.groupID
+	return account.withAccountGroupMembership(&groupID)
+}
+
+// applyAccountGroupMembership 对候选账号列表应用其在请求分组中的成员调度覆盖；无覆盖时不复制列表。
+func applyAccountGroupMembership(accounts []Account, groupID *int64) []Account {
+	if groupID == nil || *groupID <= 0 {
+		return accounts
+	}
+	out := accounts
+	copied := false
+	for i := range accounts {
+		applied := accounts[i].withAccountGroupMembership(groupID)
+		if applied == &accounts[i] {
+			continue
+		}
+		if !copied {
+			out = append([]Account(nil), accounts...)
+			copied = true
+		}
+		out[i] = *applied
+	}
+	return out
+}
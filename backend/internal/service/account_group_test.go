@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountWithAccountGroupMembership(t *testing.T) {
+	priority, concurrency := 5, 4
+	account := &Account{ID: 1, Priority: 50, Concurrency: 10, AccountGroups: []AccountGroup{
+		{GroupID: 7, PriorityOverride: &priority, Weight: 200, ConcurrencyCap: &concurrency},
+		{GroupID: 8, Weight: DefaultAccountGroupWeight},
+	}}
+
+	applied := account.withAccountGroupMembership(int64Ptr(7))
+	require.Equal(t, 5, applied.Priority)
+	require.Equal(t, 4, applied.Concurrency)
+	require.Equal(t, 8, applied.EffectiveLoadFactor(), "weight 200 doubles the (overridden) concurrency")
+	require.Same(t, applied, applied.withAccountGroupMembership(int64Ptr(7)), "overrides apply once")
+	require.Equal(t, 50, account.Priority, "original account is untouched")
+
+	require.Same(t, account, account.withAccountGroupMembership(int64Ptr(8)), "no overrides")
+	require.Same(t, account, account.withAccountGroupMembership(int64Ptr(9)), "not a member")
+	require.Same(t, account, account.withAccountGroupMembership(nil))
+
+	restored := applied.WithoutGroupMembership()
+	require.Equal(t, 50, restored.Priority)
+	require.Equal(t, 10, restored.Concurrency)
+	require.Nil(t, restored.LoadFactor)
+	require.Same(t, account, account.WithoutGroupMembership(), "nothing to restore")
+
+	reloaded := inheritAccountGroupMembership(&Account{ID: 1, Priority: 50, Concurrency: 12, AccountGroups: account.AccountGroups}, applied)
+	require.Equal(t, 5, reloaded.Priority)
+	require.Equal(t, 4, reloaded.Concurrency)
+}
+
+func TestAccountGroupMembershipConcurrencyCapOnlyTightens(t *testing.T) {
+	limit := 20
+	account := &Account{Concurrency: 3, AccountGroups: []AccountGroup{{GroupID: 7, ConcurrencyCap: &limit}}}
+	require.Equal(t, 3, account.withAccountGroupMembership(int64Ptr(7)).Concurrency)
+}
+
+func TestAccountGroupMembershipUpdateValidate(t *testing.T) {
+	negative, zero, tooHeavy := -1, 0, MaxAccountGroupWeight+1
+	require.Error(t, (&AccountGroupMembershipUpdate{PriorityOverride: &negative}).Validate())
+	require.Error(t, (&AccountGroupMembershipUpdate{Weight: &zero}).Validate())
+	require.Error(t, (&AccountGroupMembershipUpdate{Weight: &tooHeavy}).Validate())
+	require.Error(t, (&AccountGroupMembershipUpdate{ConcurrencyCap: &zero}).Validate())
+	require.NoError(t, (&AccountGroupMembershipUpdate{PriorityOverride: &zero, ClearConcurrencyCap: true}).Validate())
+}
+
+func TestSelectAccountWithLoadAwareness_UsesGroupMembershipPriority(t *testing.T) {
+	boosted := 0
+	shared := &Account{ID: 1, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 50,
+		AccountGroups: []AccountGroup{{GroupID: 7, PriorityOverride: &boosted}, {GroupID: 8}}}
+	dedicated := &Account{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 10,
+		AccountGroups: []AccountGroup{{GroupID: 7}, {GroupID: 8}}}
+	svc := newAccountPinningTestService(shared, dedicated)
+	ctx := context.WithValue(context.Background(), ctxkey.ForcePlatform, PlatformAnthropic)
+
+	result, err := svc.SelectAccountWithLoadAwareness(ctx, int64Ptr(7), "", "", nil, "", 0)
+	require.NoError(t, err)
+	require.Equal(t, shared.ID, result.Account.ID, "membership priority override wins inside group 7")
+
+	result, err = svc.SelectAccountWithLoadAwareness(ctx, int64Ptr(8), "", "", nil, "", 0)
+	require.NoError(t, err)
+	require.Equal(t, dedicated.ID, result.Account.ID, "group 8 keeps account priorities")
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// AccountMetadataDateLayout 采购/续费日期的接口格式（按天，不含时区）
+const AccountMetadataDateLayout = "2006-01-02"
+
+var (
+	ErrAccountMetadataNotFound     = infraerrors.NotFound("ACCOUNT_METADATA_NOT_FOUND", "account metadata not found")
+	ErrAccountMetadataInvalidDate  = infraerrors.BadRequest("ACCOUNT_METADATA_INVALID_DATE", "dates must use YYYY-MM-DD format")
+	ErrAccountMetadataInvalidCost  = infraerrors.BadRequest("ACCOUNT_METADATA_INVALID_COST", "monthly_cost must be non-negative")
+	ErrAccountMetadataInvalidRange = infraerrors.BadRequest("ACCOUNT_METADATA_INVALID_RANGE", "renewal_date must not be earlier than purchase_date")
+)
+
+// AccountMetadata 账号运维元数据（负责人、采购/续费、成本与备注），与账号一对一。
+type AccountMetadata struct {
+	AccountID    int64      `json:"account_id"`
+	OwnerName    string     `json:"owner_name"`
+	OwnerContact string     `json:"owner_contact"`
+	PurchaseDate *time.Time `json:"purchase_date,omitempty"`
+	// RenewalDate 订阅到期（需续费）日期
+	RenewalDate  *time.Time `json:"renewal_date,omitempty"`
+	MonthlyCost  *float64   `json:"monthly_cost,omitempty"`
+	CostCurrency string     `json:"cost_currency,omitempty"`
+	Notes        string     `json:"notes"`
+	// RenewalRemindedFor 已发出续费提醒对应的 RenewalDate
+	RenewalRemindedFor *time.Time `json:"renewal_reminded_for,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	// 以下字段仅列表查询时填充
+	AccountName     string `json:"account_name,omitempty"`
+	AccountPlatform string `json:"account_platform,omitempty"`
+}
+
+// UpsertAccountMetadataInput 创建或覆盖账号元数据的参数；日期为 YYYY-MM-DD，空串表示清空。
+type UpsertAccountMetadataInput struct {
+	AccountID    int64
+	OwnerName    string
+	OwnerContact string
+	PurchaseDate string
+	RenewalDate  string
+	MonthlyCost  *float64
+	CostCurrency string
+	Notes        string
+}
+
+// AccountMetadataFilter 元数据列表查询条件。
+type AccountMetadataFilter struct {
+	Page     int
+	PageSize int
+
+	// Search 按负责人/联系方式/账号名模糊匹配
+	Search string
+	// RenewalBefore 仅返回续费日期早于该日期（不含）的记录，按续费日期升序
+	RenewalBefore *time.Time
+}
+
+// AccountMetadataList 分页结果。
+type AccountMetadataList struct {
+	Items    []*AccountMetadata
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// AccountRenewalDue 待提醒的续费条目。
+type AccountRenewalDue struct {
+	AccountID       int64
+	AccountName     string
+	AccountPlatform string
+	OwnerName       string
+	OwnerContact    string
+	RenewalDate     time.Time
+	MonthlyCost     *float64
+	CostCurrency    string
+}
+
+// AccountMetadataRepository 账号元数据持久化端口。
+type AccountMetadataRepository interface {
+	GetByAccountID(ctx context.Context, accountID int64) (*AccountMetadata, error)
+	// Upsert 覆盖写入；账号不存在（或已软删除）时返回 ErrAccountNotFound。
+	Upsert(ctx context.Context, metadata *AccountMetadata) (*AccountMetadata, error)
+	Delete(ctx context.Context, accountID int64) error
+	List(ctx context.Context, filter *AccountMetadataFilter) (*AccountMetadataList, error)
+	// ListRenewalsDue 返回续费日期不晚于 dueBy 且尚未针对该日期提醒过的未删除账号。
+	ListRenewalsDue(ctx context.Context, dueBy time.Time, limit int) ([]*AccountRenewalDue, error)
+	MarkRenewalReminded(ctx context.Context, accountID int64, renewalDate time.Time) error
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+)
+
+// AccountMetadataService 账号运维元数据的管理端 CRUD。
+type AccountMetadataService struct {
+	repo AccountMetadataRepository
+}
+
+// NewAccountMetadataService creates a new AccountMetadataService
+func NewAccountMetadataService(repo AccountMetadataRepository) *AccountMetadataService {
+	return &AccountMetadataService{repo: repo}
+}
+
+// Get 查询单个账号的元数据。
+func (s *AccountMetadataService) Get(ctx context.Context, accountID int64) (*AccountMetadata, error) {
+	if accountID <= 0 {
+		return nil, ErrAccountNotFound
+	}
+	return s.repo.GetByAccountID(ctx, accountID)
+}
+
+// Upsert 创建或覆盖账号元数据。
+func (s *AccountMetadataService) Upsert(ctx context.Context, input *UpsertAccountMetadataInput) (*AccountMetadata, error) {
+	if input == nil || input.AccountID <= 0 {
+		return nil, ErrAccountNotFound
+	}
+	purchaseDate, err := parseAccountMetadataDate(input.PurchaseDate)
+	if err != nil {
+		return nil, err
+	}
+	renewalDate, err := parseAccountMetadataDate(input.RenewalDate)
+	if err != nil {
+		return nil, err
+	}
+	if purchaseDate != nil && renewalDate != nil && renewalDate.Before(*purchaseDate) {
+		return nil, ErrAccountMetadataInvalidRange
+	}
+	if input.MonthlyCost != nil {
+		cost := *input.MonthlyCost
+		if cost < 0 || math.IsNaN(cost) || math.IsInf(cost, 0) {
+			return nil, ErrAccountMetadataInvalidCost
+		}
+	}
+
+	return s.repo.Upsert(ctx, &AccountMetadata{
+		AccountID:    input.AccountID,
+		OwnerName:    strings.TrimSpace(input.OwnerName),
+		OwnerContact: strings.TrimSpace(input.OwnerContact),
+		PurchaseDate: purchaseDate,
+		RenewalDate:  renewalDate,
+		MonthlyCost:  input.MonthlyCost,
+		CostCurrency: strings.ToUpper(strings.TrimSpace(input.CostCurrency)),
+		Notes:        strings.TrimSpace(input.Notes),
+	})
+}
+
+// Delete 删除账号元数据。
+func (s *AccountMetadataService) Delete(ctx context.Context, accountID int64) error {
+	if accountID <= 0 {
+		return ErrAccountNotFound
+	}
+	return s.repo.Delete(ctx, accountID)
+}
+
+// List 分页查询账号元数据（含账号名与平台）。
+func (s *AccountMetadataService) List(ctx context.Context, filter *AccountMetadataFilter) (*AccountMetadataList, error) {
+	if filter == nil {
+		filter = &AccountMetadataFilter{}
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	return s.repo.List(ctx, filter)
+}
+
+func parseAccountMetadataDate(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(AccountMetadataDateLayout, raw)
+	if err != nil {
+		return nil, ErrAccountMetadataInvalidDate
+	}
+	return &t, nil
+}
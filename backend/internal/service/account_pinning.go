@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// AccountPinningHeader 管理员 Key 用于指定调试账号的请求头
+const AccountPinningHeader = "X-Sub2API-Account-ID"
+
+// WithPinnedAccountID 在 context 中记录本次请求固定使用的账号
+func WithPinnedAccountID(ctx context.Context, accountID int64) context.Context {
+	if accountID <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxkey.PinnedAccountID, accountID)
+}
+
+// PinnedAccountIDFromContext 读取固定账号 ID，未固定时返回 0
+func PinnedAccountIDFromContext(ctx context.Context) int64 {
+	if ctx == nil {
+		return 0
+	}
+	id, _ := ctx.Value(ctxkey.PinnedAccountID).(int64)
+	return id
+}
+
+// pinnedAccountSelector 由各网关服务实现，复用其槽位获取与结果组装逻辑
+type pinnedAccountSelector interface {
+	tryAcquireAccountSlot(ctx context.Context, accountID int64, maxConcurrency int) (*AcquireResult, error)
+	newSelectionResult(ctx context.Context, account *Account, acquired bool, release func(), waitPlan *AccountWaitPlan) (*AccountSelectionResult, error)
+	schedulingConfig() config.GatewaySchedulingConfig
+}
+
+// loadPinnedAccount 读取并校验固定账号（存在、启用、平台匹配、数据驻留），不获取并发槽位。
+// 固定账号已在本次请求中失败（位于 excludedIDs）时不再切换到其它账号，直接返回错误。
+func loadPinnedAccount(
+	ctx context.Context,
+	accountRepo AccountRepository,
+	accountID int64,
+	excludedIDs map[int64]struct{},
+	allowed func(*Account) bool,
+) (*Account, error) {
+	if _, excluded := excludedIDs[accountID]; excluded {
+		return nil, fmt.Errorf("%w: pinned account %d failed and failover is disabled", ErrNoAvailableAccounts, accountID)
+	}
+	if accountRepo == nil {
+		return nil, fmt.Errorf("%w: pinned account %d not found", ErrNoAvailableAccounts, accountID)
+	}
+	account, err := accountRepo.GetByID(ctx, accountID)
+	if err != nil || account == nil {
+		return nil, fmt.Errorf("%w: pinned account %d not found", ErrNoAvailableAccounts, accountID)
+	}
+	if !account.IsActive() {
+		return nil, fmt.Errorf("%w: pinned account %d is not active", ErrNoAvailableAccounts, accountID)
+	}
+	if allowed != nil && !allowed(account) {
+		return nil, fmt.Errorf("%w: pinned account %d does not serve platform of this key", ErrNoAvailableAccounts, accountID)
+	}
+	if region := DataResidencyFromContext(ctx); !account.SatisfiesDataResidency(region) {
+		return nil, fmt.Errorf("%w: pinned account %d does not satisfy data residency %q", ErrNoAvailableAccounts, accountID, region)
+	}
+	return account, nil
+}
+
+// selectPinnedAccount 跳过调度直接选择固定账号，但仍遵守账号并发槽位（满载时返回粘性会话同款等待计划）。
+func selectPinnedAccount(
+	ctx context.Context,
+	selector pinnedAccountSelector,
+	accountRepo AccountRepository,
+	accountID int64,
+	excludedIDs map[int64]struct{},
+	allowed func(*Account) bool,
+) (*AccountSelectionResult, error) {
+	account, err := loadPinnedAccount(ctx, accountRepo, accountID, excludedIDs, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := selector.tryAcquireAccountSlot(ctx, account.ID, account.Concurrency)
+	if err == nil && result.Acquired {
+		return selector.newSelectionResult(ctx, account, true, result.ReleaseFunc, nil)
+	}
+	cfg := selector.schedulingConfig()
+	return selector.newSelectionResult(ctx, account, false, nil, &AccountWaitPlan{
+		AccountID:      account.ID,
+		MaxConcurrency: account.Concurrency,
+		Timeout:        cfg.StickySessionWaitTimeout,
+		MaxWaiting:     cfg.StickySessionMaxWaiting,
+	})
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccountPinningTestService(accounts ...*Account) *GatewayService {
+	repo := stubOpenAIAccountRepo{}
+	for _, account := range accounts {
+		repo.accounts = append(repo.accounts, *account)
+	}
+	cfg := &config.Config{RunMode: config.RunModeStandard}
+	cfg.Gateway.Scheduling.StickySessionWaitTimeout = time.Second
+	cfg.Gateway.Scheduling.StickySessionMaxWaiting = 3
+	return &GatewayService{accountRepo: repo, cfg: cfg}
+}
+
+func TestSelectAccountWithLoadAwareness_PinnedAccountBypassesSelection(t *testing.T) {
+	preferred := &Account{ID: 1, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 1}
+	pinned := &Account{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: false, Concurrency: 1, Priority: 99}
+	svc := newAccountPinningTestService(preferred, pinned)
+
+	ctx := context.WithValue(context.Background(), ctxkey.ForcePlatform, PlatformAnthropic)
+	ctx = WithPinnedAccountID(ctx, pinned.ID)
+	result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "", "", nil, "", 0)
+	require.NoError(t, err)
+	require.Equal(t, pinned.ID, result.Account.ID, "pinning must bypass priority and schedulable filtering")
+	require.True(t, result.Acquired)
+}
+
+func TestSelectAccountWithLoadAwareness_PinnedAccountErrors(t *testing.T) {
+	openai := &Account{ID: 3, Platform: PlatformOpenAI, Status: StatusActive, Concurrency: 1}
+	disabled := &Account{ID: 4, Platform: PlatformAnthropic, Status: StatusDisabled, Concurrency: 1}
+	active := &Account{ID: 5, Platform: PlatformAnthropic, Status: StatusActive, Concurrency: 1}
+	svc := newAccountPinningTestService(openai, disabled, active)
+	base := context.WithValue(context.Background(), ctxkey.ForcePlatform, PlatformAnthropic)
+
+	for _, tc := range []struct {
+		name     string
+		id       int64
+		excluded map[int64]struct{}
+	}{
+		{name: "missing", id: 404},
+		{name: "wrong platform", id: openai.ID},
+		{name: "inactive", id: disabled.ID},
+		{name: "already failed", id: active.ID, excluded: map[int64]struct{}{active.ID: {}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.SelectAccountWithLoadAwareness(WithPinnedAccountID(base, tc.id), nil, "", "", tc.excluded, "", 0)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrNoAvailableAccounts))
+		})
+	}
+}
+
+func TestSelectAccountForModel_PinnedAccountBypassesSelection(t *testing.T) {
+	preferred := &Account{ID: 1, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 1}
+	pinned := &Account{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: false, Concurrency: 1, Priority: 99}
+	openai := &Account{ID: 3, Platform: PlatformOpenAI, Status: StatusActive, Concurrency: 1}
+	svc := newAccountPinningTestService(preferred, pinned, openai)
+	base := context.WithValue(context.Background(), ctxkey.ForcePlatform, PlatformAnthropic)
+
+	account, err := svc.SelectAccountForModel(WithPinnedAccountID(base, pinned.ID), nil, "", "")
+	require.NoError(t, err)
+	require.Equal(t, pinned.ID, account.ID, "count_tokens must honor the pinned account")
+
+	_, err = svc.SelectAccountForModel(WithPinnedAccountID(base, openai.ID), nil, "", "")
+	require.ErrorIs(t, err, ErrNoAvailableAccounts)
+}
+
+func TestSelectAccountForAIStudioEndpoints_PinnedAccount(t *testing.T) {
+	repo := stubOpenAIAccountRepo{accounts: []Account{
+		{ID: 1, Platform: PlatformGemini, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Credentials: map[string]any{"api_key": "k"}},
+		{ID: 2, Platform: PlatformGemini, Type: AccountTypeOAuth, Status: StatusActive, Schedulable: true},
+		{ID: 3, Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true},
+	}}
+	svc := &GeminiMessagesCompatService{accountRepo: repo}
+
+	account, err := svc.SelectAccountForAIStudioEndpoints(WithPinnedAccountID(context.Background(), 2), nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), account.ID)
+
+	_, err = svc.SelectAccountForAIStudioEndpoints(WithPinnedAccountID(context.Background(), 3), nil)
+	require.ErrorIs(t, err, ErrNoAvailableAccounts)
+}
+
+type pinnedSlotBusySelector struct{ *GatewayService }
+
+func (s pinnedSlotBusySelector) tryAcquireAccountSlot(context.Context, int64, int) (*AcquireResult, error) {
+	return &AcquireResult{Acquired: false}, nil
+}
+
+func TestSelectPinnedAccount_RespectsSlots(t *testing.T) {
+	account := &Account{ID: 6, Platform: PlatformAnthropic, Status: StatusActive, Concurrency: 2}
+	svc := newAccountPinningTestService(account)
+
+	result, err := selectPinnedAccount(context.Background(), pinnedSlotBusySelector{svc}, svc.accountRepo, account.ID, nil, nil)
+	require.NoError(t, err)
+	require.False(t, result.Acquired)
+	require.NotNil(t, result.WaitPlan)
+	require.Equal(t, account.ID, result.WaitPlan.AccountID)
+	require.Equal(t, 2, result.WaitPlan.MaxConcurrency)
+	require.Equal(t, 3, result.WaitPlan.MaxWaiting)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/google/uuid"
+)
+
+const (
+	accountRenewalReminderLeaderLockKey = "account:renewal:reminder:leader"
+	accountRenewalReminderLeaderLockTTL = 5 * time.Minute
+	// accountRenewalReminderBatchSize 单轮最多提醒的账号数，剩余的在下一轮继续
+	accountRenewalReminderBatchSize = 200
+)
+
+// AccountRenewalReminderService 周期扫描账号元数据，在续费日期前 N 天写入运维告警事件。
+//
+// 告警事件不关联告警规则（rule_id 为空），dimensions 携带 account_id 与 kind=account_renewal；
+// 每个续费日期只提醒一次，修改续费日期后会针对新日期重新提醒。
+type AccountRenewalReminderService struct {
+	metadataRepo AccountMetadataRepository
+	opsRepo      OpsRepository
+	reminderDays int
+	interval     time.Duration
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+	wg           sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+func NewAccountRenewalReminderService(metadataRepo AccountMetadataRepository, opsRepo OpsRepository, reminderDays int, interval time.Duration) *AccountRenewalReminderService {
+	return &AccountRenewalReminderService{
+		metadataRepo: metadataRepo,
+		opsRepo:      opsRepo,
+		reminderDays: reminderDays,
+		interval:     interval,
+		stopCh:       make(chan struct{}),
+		instanceID:   uuid.NewString(),
+		now:          timezone.Now,
+	}
+}
+
+// SetLeaderLock injects the leader-lock cache and DB so that only one instance
+// raises renewal alerts per cycle.
+func (s *AccountRenewalReminderService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+func (s *AccountRenewalReminderService) Start() {
+	if s == nil || s.metadataRepo == nil || s.opsRepo == nil || s.reminderDays <= 0 || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *AccountRenewalReminderService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *AccountRenewalReminderService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, accountRenewalReminderLeaderLockKey, s.instanceID, accountRenewalReminderLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	raised, err := s.raiseDueReminders(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.account_renewal_reminder", "[AccountRenewal] raise reminders failed: %v", err)
+	}
+	if raised > 0 {
+		logger.LegacyPrintf("service.account_renewal_reminder", "[AccountRenewal] raised %d renewal reminders", raised)
+	}
+}
+
+func (s *AccountRenewalReminderService) raiseDueReminders(ctx context.Context) (int, error) {
+	now := s.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dueBy := today.AddDate(0, 0, s.reminderDays)
+
+	items, err := s.metadataRepo.ListRenewalsDue(ctx, dueBy, accountRenewalReminderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	raised := 0
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if _, err := s.opsRepo.CreateAlertEvent(ctx, buildAccountRenewalAlertEvent(item, today, now)); err != nil {
+			logger.LegacyPrintf("service.account_renewal_reminder", "[AccountRenewal] create alert event failed: account_id=%d err=%v", item.AccountID, err)
+			continue
+		}
+		if err := s.metadataRepo.MarkRenewalReminded(ctx, item.AccountID, item.RenewalDate); err != nil {
+			logger.LegacyPrintf("service.account_renewal_reminder", "[AccountRenewal] mark reminded failed: account_id=%d err=%v", item.AccountID, err)
+			continue
+		}
+		raised++
+	}
+	return raised, nil
+}
+
+func buildAccountRenewalAlertEvent(item *AccountRenewalDue, today, now time.Time) *OpsAlertEvent {
+	renewal := time.Date(item.RenewalDate.Year(), item.RenewalDate.Month(), item.RenewalDate.Day(), 0, 0, 0, 0, time.UTC)
+	daysRemaining := int(renewal.Sub(today).Hours() / 24)
+
+	// 已过期的续费提升为 P1，其余为 P2
+	severity := "P2"
+	if daysRemaining < 0 {
+		severity = "P1"
+	}
+	name := strings.TrimSpace(item.AccountName)
+	if name == "" {
+		name = fmt.Sprintf("#%d", item.AccountID)
+	}
+
+	desc := fmt.Sprintf("account %s (id=%d platform=%s) renewal date %s (%d days remaining)",
+		name, item.AccountID, item.AccountPlatform, renewal.Format(AccountMetadataDateLayout), daysRemaining)
+	if owner := strings.TrimSpace(strings.Join([]string{item.OwnerName, item.OwnerContact}, " ")); owner != "" {
+		desc += "; owner: " + owner
+	}
+	if item.MonthlyCost != nil {
+		desc += fmt.Sprintf("; monthly cost: %.2f %s", *item.MonthlyCost, item.CostCurrency)
+		desc = strings.TrimSpace(desc)
+	}
+
+	dims := map[string]any{
+		"kind":         "account_renewal",
+		"account_id":   item.AccountID,
+		"renewal_date": renewal.Format(AccountMetadataDateLayout),
+	}
+	if platform := strings.TrimSpace(item.AccountPlatform); platform != "" {
+		dims["platform"] = platform
+	}
+
+	return &OpsAlertEvent{
+		Severity:    severity,
+		Status:      OpsAlertStatusFiring,
+		Title:       fmt.Sprintf("%s: Account renewal due: %s", severity, name),
+		Description: desc,
+		Dimensions:  dims,
+		FiredAt:     now,
+		CreatedAt:   now,
+	}
+}
@@ -0,0 +1,123 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type accountMetadataRepoStub struct {
+	AccountMetadataRepository
+	due      []*AccountRenewalDue
+	dueBy    time.Time
+	reminded map[int64]time.Time
+	upserted *AccountMetadata
+}
+
+func (s *accountMetadataRepoStub) ListRenewalsDue(_ context.Context, dueBy time.Time, _ int) ([]*AccountRenewalDue, error) {
+	s.dueBy = dueBy
+	return s.due, nil
+}
+
+func (s *accountMetadataRepoStub) MarkRenewalReminded(_ context.Context, accountID int64, renewalDate time.Time) error {
+	if s.reminded == nil {
+		s.reminded = map[int64]time.Time{}
+	}
+	s.reminded[accountID] = renewalDate
+	return nil
+}
+
+func (s *accountMetadataRepoStub) Upsert(_ context.Context, metadata *AccountMetadata) (*AccountMetadata, error) {
+	s.upserted = metadata
+	return metadata, nil
+}
+
+type renewalAlertOpsRepoStub struct {
+	OpsRepository
+	events  []*OpsAlertEvent
+	failFor int64
+}
+
+func (s *renewalAlertOpsRepoStub) CreateAlertEvent(_ context.Context, event *OpsAlertEvent) (*OpsAlertEvent, error) {
+	if s.failFor > 0 && event.Dimensions["account_id"] == s.failFor {
+		return nil, errors.New("insert failed")
+	}
+	s.events = append(s.events, event)
+	return event, nil
+}
+
+func TestAccountRenewalReminder_RaisesAlertsAndMarksReminded(t *testing.T) {
+	cost := 20.0
+	repo := &accountMetadataRepoStub{due: []*AccountRenewalDue{
+		{AccountID: 1, AccountName: "team-a", AccountPlatform: PlatformAnthropic, OwnerName: "ops", RenewalDate: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), MonthlyCost: &cost, CostCurrency: "USD"},
+		{AccountID: 2, AccountName: "team-b", AccountPlatform: PlatformOpenAI, RenewalDate: time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)},
+		{AccountID: 3, AccountName: "team-c", RenewalDate: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+	}}
+	ops := &renewalAlertOpsRepoStub{failFor: 3}
+	svc := NewAccountRenewalReminderService(repo, ops, 7, time.Hour)
+	svc.now = func() time.Time { return time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC) }
+
+	raised, err := svc.raiseDueReminders(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, raised)
+	require.Equal(t, time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC), repo.dueBy)
+
+	require.Len(t, ops.events, 2)
+	require.Equal(t, "P2", ops.events[0].Severity)
+	require.Equal(t, OpsAlertStatusFiring, ops.events[0].Status)
+	require.Equal(t, "account_renewal", ops.events[0].Dimensions["kind"])
+	require.Equal(t, "2026-10-18", ops.events[0].Dimensions["renewal_date"])
+	require.Contains(t, ops.events[0].Description, "3 days remaining")
+	require.Contains(t, ops.events[0].Description, "owner: ops")
+	require.Contains(t, ops.events[0].Description, "20.00 USD")
+	require.Equal(t, "P1", ops.events[1].Severity, "已过期的续费提升为 P1")
+
+	require.Len(t, repo.reminded, 2)
+	require.Contains(t, repo.reminded, int64(1))
+	require.Contains(t, repo.reminded, int64(2))
+	require.NotContains(t, repo.reminded, int64(3), "告警写入失败时不标记，下一轮重试")
+}
+
+func TestAccountRenewalReminder_DisabledWhenReminderDaysZero(t *testing.T) {
+	svc := NewAccountRenewalReminderService(&accountMetadataRepoStub{}, &renewalAlertOpsRepoStub{}, 0, time.Hour)
+	svc.Start()
+	svc.Stop()
+}
+
+func TestAccountMetadataService_UpsertValidates(t *testing.T) {
+	repo := &accountMetadataRepoStub{}
+	svc := NewAccountMetadataService(repo)
+
+	_, err := svc.Upsert(context.Background(), &UpsertAccountMetadataInput{AccountID: 1, RenewalDate: "2026/10/01"})
+	require.ErrorIs(t, err, ErrAccountMetadataInvalidDate)
+
+	_, err = svc.Upsert(context.Background(), &UpsertAccountMetadataInput{AccountID: 1, PurchaseDate: "2026-10-01", RenewalDate: "2026-09-01"})
+	require.ErrorIs(t, err, ErrAccountMetadataInvalidRange)
+
+	negative := -1.0
+	_, err = svc.Upsert(context.Background(), &UpsertAccountMetadataInput{AccountID: 1, MonthlyCost: &negative})
+	require.ErrorIs(t, err, ErrAccountMetadataInvalidCost)
+
+	_, err = svc.Upsert(context.Background(), &UpsertAccountMetadataInput{AccountID: 0})
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	cost := 30.0
+	got, err := svc.Upsert(context.Background(), &UpsertAccountMetadataInput{
+		AccountID:    1,
+		OwnerName:    "  alice ",
+		PurchaseDate: "2026-01-01",
+		RenewalDate:  "2026-11-01",
+		MonthlyCost:  &cost,
+		CostCurrency: " usd",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "alice", got.OwnerName)
+	require.Equal(t, "USD", got.CostCurrency)
+	require.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), *got.RenewalDate)
+	require.Same(t, repo.upserted, got)
+}
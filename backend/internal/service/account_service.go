@@ -131,11 +131,12 @@ type AccountDuplicateRepository interface {
 	CreateWithAccountGroups(ctx context.Context, account *Account, groups []AccountGroup) error
 }
 
-// AdminAccountRepository makes the account-duplication write capability an explicit
+// AdminAccountRepository makes the account-duplication and group-membership write capabilities an explicit
 // construction dependency without forcing read-only gateway test doubles to implement it.
 type AdminAccountRepository interface {
 	AccountRepository
 	AccountDuplicateRepository
+	AccountGroupMembershipRepository
 }
 
 // AccountBulkUpdate describes the fields that can be updated in a bulk operation.
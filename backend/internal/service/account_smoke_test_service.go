@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// 冒烟测试结果状态，与 RunTestBackground 的结果状态一致
+const (
+	AccountSmokeTestStatusSuccess = "success"
+	AccountSmokeTestStatusFailed  = "failed"
+)
+
+const (
+	accountSmokeTestLeaderLockKey = "account:smoke_test:leader"
+	accountSmokeTestLeaderLockTTL = 2 * time.Hour
+	// accountSmokeTestRunTimeout 单次运行（全部账号）的总超时
+	accountSmokeTestRunTimeout = 2 * time.Hour
+	// accountSmokeTestMaxErrorLen 结果中保存的错误信息最大长度
+	accountSmokeTestMaxErrorLen = 1000
+	// AccountSmokeTestMaxTrendDays 趋势接口最多返回的天数
+	AccountSmokeTestMaxTrendDays = 365
+)
+
+// AccountSmokeTestResult 一次账号冒烟测试结果。
+type AccountSmokeTestResult struct {
+	ID           int64     `json:"id"`
+	AccountID    int64     `json:"account_id"`
+	Status       string    `json:"status"`
+	LatencyMs    int64     `json:"latency_ms"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AccountSmokeTestTrendPoint 单个账号某一天的冒烟测试汇总（按配置时区分天）。
+type AccountSmokeTestTrendPoint struct {
+	Date         string  `json:"date"`
+	Runs         int     `json:"runs"`
+	Passed       int     `json:"passed"`
+	Failed       int     `json:"failed"`
+	SuccessRate  float64 `json:"success_rate"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs int64   `json:"max_latency_ms"`
+	LastError    string  `json:"last_error,omitempty"`
+}
+
+// AccountSmokeTestWindowStats 单个账号在基线窗口与近期窗口的测试次数与成功次数。
+type AccountSmokeTestWindowStats struct {
+	AccountID      int64
+	AccountName    string
+	BaselineRuns   int
+	BaselinePassed int
+	RecentRuns     int
+	RecentPassed   int
+}
+
+// AccountSmokeTestFlag 成功率明显下滑而被标记的账号。
+type AccountSmokeTestFlag struct {
+	AccountID           int64     `json:"account_id"`
+	AccountName         string    `json:"account_name,omitempty"`
+	RecentRuns          int       `json:"recent_runs"`
+	RecentSuccessRate   float64   `json:"recent_success_rate"`
+	BaselineRuns        int       `json:"baseline_runs"`
+	BaselineSuccessRate float64   `json:"baseline_success_rate"`
+	FlaggedAt           time.Time `json:"flagged_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// AccountSmokeTestRepository 冒烟测试结果与下滑标记持久化。
+type AccountSmokeTestRepository interface {
+	CreateResult(ctx context.Context, result *AccountSmokeTestResult) error
+	// DailyTrend 返回账号自 since 起按天汇总的结果，按日期升序。
+	DailyTrend(ctx context.Context, accountID int64, since time.Time) ([]*AccountSmokeTestTrendPoint, error)
+	// ComputeWindowStats 汇总 [baselineSince, recentSince) 与 [recentSince, now) 两个窗口内各账号的测试结果。
+	ComputeWindowStats(ctx context.Context, baselineSince, recentSince time.Time) ([]*AccountSmokeTestWindowStats, error)
+	// UpsertFlag 写入标记；已标记时只刷新指标，created 为 false。
+	UpsertFlag(ctx context.Context, flag *AccountSmokeTestFlag) (created bool, err error)
+	DeleteFlag(ctx context.Context, accountID int64) error
+	ListFlags(ctx context.Context) ([]*AccountSmokeTestFlag, error)
+	DeleteResultsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// accountSmokeTester 执行单个账号的测试请求（AccountTestService.RunTestBackground）。
+type accountSmokeTester interface {
+	RunTestBackground(ctx context.Context, accountID int64, modelID string) (*ScheduledTestResult, error)
+}
+
+// AccountSmokeTestService 账号夜间冒烟测试。
+//
+// 按计划对每个启用账号走一次账号测试（默认模型、最短提示词），记录成功 / 失败与延迟；
+// 每轮结束后对比账号自身近期窗口与基线窗口的成功率，下降达到 degradation_threshold 时标记账号并写入一次运维告警，
+// 成功率恢复后自动取消标记。标记只用于提前预警，不影响调度。
+type AccountSmokeTestService struct {
+	repo        AccountSmokeTestRepository
+	accountRepo AccountRepository
+	tester      accountSmokeTester
+	opsRepo     OpsRepository
+	cfg         config.AccountSmokeTestConfig
+	timezone    string
+
+	cron      *cron.Cron
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+func NewAccountSmokeTestService(repo AccountSmokeTestRepository, accountRepo AccountRepository, accountTestSvc *AccountTestService, opsRepo OpsRepository, cfg *config.Config) *AccountSmokeTestService {
+	s := &AccountSmokeTestService{
+		repo:        repo,
+		accountRepo: accountRepo,
+		opsRepo:     opsRepo,
+		instanceID:  uuid.NewString(),
+		now:         time.Now,
+	}
+	if accountTestSvc != nil {
+		s.tester = accountTestSvc
+	}
+	if cfg != nil {
+		s.cfg = cfg.AccountSmokeTest
+		s.timezone = cfg.Timezone
+	}
+	return s
+}
+
+// SetLeaderLock injects the leader-lock cache and DB so that only one instance
+// runs the nightly smoke tests.
+func (s *AccountSmokeTestService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+func (s *AccountSmokeTestService) Start() {
+	if s == nil || s.repo == nil || s.accountRepo == nil || s.tester == nil || !s.cfg.Enabled {
+		return
+	}
+	s.startOnce.Do(func() {
+		loc := time.Local
+		if parsed, err := time.LoadLocation(s.timezone); err == nil && parsed != nil {
+			loc = parsed
+		}
+		c := cron.New(cron.WithParser(scheduledTestCronParser), cron.WithLocation(loc))
+		if _, err := c.AddFunc(s.cfg.Schedule, s.runOnce); err != nil {
+			logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] not started (invalid schedule %q): %v", s.cfg.Schedule, err)
+			return
+		}
+		s.cron = c
+		s.cron.Start()
+		logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] started (schedule=%q)", s.cfg.Schedule)
+	})
+}
+
+func (s *AccountSmokeTestService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		if s.cron != nil {
+			ctx := s.cron.Stop()
+			select {
+			case <-ctx.Done():
+			case <-time.After(3 * time.Second):
+				logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] cron stop timed out")
+			}
+		}
+	})
+}
+
+func (s *AccountSmokeTestService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), accountSmokeTestRunTimeout)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, accountSmokeTestLeaderLockKey, s.instanceID, accountSmokeTestLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	tested, failed, err := s.RunAll(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] run failed: %v", err)
+		return
+	}
+	logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] tested %d accounts, %d failed", tested, failed)
+
+	flagged, err := s.Evaluate(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] evaluate failed: %v", err)
+	}
+	if flagged > 0 {
+		logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] flagged %d accounts with degrading success rate", flagged)
+	}
+
+	if s.cfg.RetentionDays > 0 {
+		cutoff := s.now().AddDate(0, 0, -s.cfg.RetentionDays)
+		if _, err := s.repo.DeleteResultsBefore(ctx, cutoff); err != nil {
+			logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] prune results failed: %v", err)
+		}
+	}
+}
+
+// RunAll 对每个启用账号执行一次测试并记录结果，返回测试账号数与失败数。
+func (s *AccountSmokeTestService) RunAll(ctx context.Context) (tested int, failed int, err error) {
+	accounts, err := s.accountRepo.ListActive(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("list active accounts: %w", err)
+	}
+
+	concurrency := s.cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for i := range accounts {
+		if ctx.Err() != nil {
+			break
+		}
+		accountID := accounts[i].ID
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := s.testAccount(ctx, accountID)
+			if err := s.repo.CreateResult(ctx, result); err != nil {
+				logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] save result failed: account_id=%d err=%v", accountID, err)
+			}
+			mu.Lock()
+			tested++
+			if result.Status != AccountSmokeTestStatusSuccess {
+				failed++
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return tested, failed, nil
+}
+
+func (s *AccountSmokeTestService) testAccount(ctx context.Context, accountID int64) *AccountSmokeTestResult {
+	if s.cfg.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.cfg.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+	startedAt := s.now()
+	out := &AccountSmokeTestResult{AccountID: accountID, Status: AccountSmokeTestStatusFailed, StartedAt: startedAt}
+
+	res, err := s.tester.RunTestBackground(ctx, accountID, "")
+	if err != nil {
+		out.ErrorMessage = truncateString(err.Error(), accountSmokeTestMaxErrorLen)
+		out.LatencyMs = s.now().Sub(startedAt).Milliseconds()
+		return out
+	}
+	if res.Status == AccountSmokeTestStatusSuccess {
+		out.Status = AccountSmokeTestStatusSuccess
+	}
+	out.ErrorMessage = truncateString(res.ErrorMessage, accountSmokeTestMaxErrorLen)
+	out.LatencyMs = res.LatencyMs
+	return out
+}
+
+// Evaluate 对比各账号近期与基线成功率，标记下滑账号并取消已恢复账号的标记，返回新标记数。
+func (s *AccountSmokeTestService) Evaluate(ctx context.Context) (int, error) {
+	now := s.now()
+	recentSince := now.AddDate(0, 0, -s.cfg.RecentDays)
+	baselineSince := recentSince.AddDate(0, 0, -s.cfg.BaselineDays)
+
+	stats, err := s.repo.ComputeWindowStats(ctx, baselineSince, recentSince)
+	if err != nil {
+		return 0, err
+	}
+	existing, err := s.repo.ListFlags(ctx)
+	if err != nil {
+		return 0, err
+	}
+	stale := make(map[int64]struct{}, len(existing))
+	for _, f := range existing {
+		stale[f.AccountID] = struct{}{}
+	}
+
+	flagged := 0
+	for _, st := range stats {
+		if !detectAccountSmokeTestDegradation(st, s.cfg) {
+			continue
+		}
+		delete(stale, st.AccountID)
+		flag := &AccountSmokeTestFlag{
+			AccountID:           st.AccountID,
+			AccountName:         st.AccountName,
+			RecentRuns:          st.RecentRuns,
+			RecentSuccessRate:   smokeTestSuccessRate(st.RecentPassed, st.RecentRuns),
+			BaselineRuns:        st.BaselineRuns,
+			BaselineSuccessRate: smokeTestSuccessRate(st.BaselinePassed, st.BaselineRuns),
+			FlaggedAt:           now,
+			UpdatedAt:           now,
+		}
+		created, err := s.repo.UpsertFlag(ctx, flag)
+		if err != nil {
+			logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] save flag failed: account_id=%d err=%v", st.AccountID, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		flagged++
+		if s.opsRepo != nil {
+			if _, err := s.opsRepo.CreateAlertEvent(ctx, buildAccountSmokeTestAlertEvent(flag, now)); err != nil {
+				logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] create alert event failed: account_id=%d err=%v", st.AccountID, err)
+			}
+		}
+	}
+	for accountID := range stale {
+		if err := s.repo.DeleteFlag(ctx, accountID); err != nil {
+			logger.LegacyPrintf("service.account_smoke_test", "[AccountSmokeTest] clear flag failed: account_id=%d err=%v", accountID, err)
+		}
+	}
+	return flagged, nil
+}
+
+// detectAccountSmokeTestDegradation 近期成功率是否比基线低 degradation_threshold 以上；两个窗口都需达到最少测试次数。
+func detectAccountSmokeTestDegradation(st *AccountSmokeTestWindowStats, cfg config.AccountSmokeTestConfig) bool {
+	if st == nil || st.RecentRuns <= 0 || st.BaselineRuns <= 0 {
+		return false
+	}
+	if st.RecentRuns < cfg.MinRuns || st.BaselineRuns < cfg.MinRuns {
+		return false
+	}
+	drop := smokeTestSuccessRate(st.BaselinePassed, st.BaselineRuns) - smokeTestSuccessRate(st.RecentPassed, st.RecentRuns)
+	return drop >= cfg.DegradationThreshold
+}
+
+func smokeTestSuccessRate(passed, runs int) float64 {
+	if runs <= 0 {
+		return 0
+	}
+	return float64(passed) / float64(runs)
+}
+
+func buildAccountSmokeTestAlertEvent(flag *AccountSmokeTestFlag, now time.Time) *OpsAlertEvent {
+	name := strings.TrimSpace(flag.AccountName)
+	if name == "" {
+		name = fmt.Sprintf("#%d", flag.AccountID)
+	}
+	recent := flag.RecentSuccessRate
+	baseline := flag.BaselineSuccessRate
+	return &OpsAlertEvent{
+		Severity: "P2",
+		Status:   OpsAlertStatusFiring,
+		Title:    fmt.Sprintf("P2: Account smoke test success rate degrading: %s", name),
+		Description: fmt.Sprintf("account %s: smoke test success rate %.0f%% over the last %d runs vs %.0f%% over %d baseline runs",
+			name, recent*100, flag.RecentRuns, baseline*100, flag.BaselineRuns),
+		MetricValue:    &recent,
+		ThresholdValue: &baseline,
+		Dimensions: map[string]any{
+			"kind":       "account_smoke_test_degradation",
+			"account_id": flag.AccountID,
+		},
+		FiredAt:   now,
+		CreatedAt: now,
+	}
+}
+
+// Trend 返回账号最近 days 天的每日成功率与延迟。
+func (s *AccountSmokeTestService) Trend(ctx context.Context, accountID int64, days int) ([]*AccountSmokeTestTrendPoint, error) {
+	if days <= 0 {
+		days = 30
+	}
+	if days > AccountSmokeTestMaxTrendDays {
+		days = AccountSmokeTestMaxTrendDays
+	}
+	return s.repo.DailyTrend(ctx, accountID, s.now().AddDate(0, 0, -days))
+}
+
+// ListFlagged 返回当前被标记为成功率下滑的账号。
+func (s *AccountSmokeTestService) ListFlagged(ctx context.Context) ([]*AccountSmokeTestFlag, error) {
+	return s.repo.ListFlags(ctx)
+}
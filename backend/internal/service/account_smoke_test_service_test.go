@@ -0,0 +1,161 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type smokeTestRepoStub struct {
+	AccountSmokeTestRepository
+	mu      sync.Mutex
+	results []*AccountSmokeTestResult
+	stats   []*AccountSmokeTestWindowStats
+	flags   map[int64]*AccountSmokeTestFlag
+}
+
+func (s *smokeTestRepoStub) CreateResult(_ context.Context, result *AccountSmokeTestResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, result)
+	return nil
+}
+
+func (s *smokeTestRepoStub) ComputeWindowStats(context.Context, time.Time, time.Time) ([]*AccountSmokeTestWindowStats, error) {
+	return s.stats, nil
+}
+
+func (s *smokeTestRepoStub) UpsertFlag(_ context.Context, flag *AccountSmokeTestFlag) (bool, error) {
+	if s.flags == nil {
+		s.flags = map[int64]*AccountSmokeTestFlag{}
+	}
+	_, exists := s.flags[flag.AccountID]
+	cp := *flag
+	s.flags[flag.AccountID] = &cp
+	return !exists, nil
+}
+
+func (s *smokeTestRepoStub) DeleteFlag(_ context.Context, accountID int64) error {
+	delete(s.flags, accountID)
+	return nil
+}
+
+func (s *smokeTestRepoStub) ListFlags(context.Context) ([]*AccountSmokeTestFlag, error) {
+	out := make([]*AccountSmokeTestFlag, 0, len(s.flags))
+	for _, f := range s.flags {
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+type smokeTestAccountRepoStub struct {
+	AccountRepository
+	accounts []Account
+}
+
+func (s *smokeTestAccountRepoStub) ListActive(context.Context) ([]Account, error) {
+	return s.accounts, nil
+}
+
+type smokeTesterStub struct {
+	failing map[int64]bool
+}
+
+func (s *smokeTesterStub) RunTestBackground(_ context.Context, accountID int64, _ string) (*ScheduledTestResult, error) {
+	if s.failing[accountID] {
+		return &ScheduledTestResult{Status: "failed", ErrorMessage: "upstream 401", LatencyMs: 20}, nil
+	}
+	if accountID < 0 {
+		return nil, errors.New("account not found")
+	}
+	return &ScheduledTestResult{Status: "success", LatencyMs: 120}, nil
+}
+
+func testAccountSmokeTestConfig() *config.Config {
+	return &config.Config{AccountSmokeTest: config.AccountSmokeTestConfig{
+		Enabled:              true,
+		Schedule:             "0 3 * * *",
+		Concurrency:          2,
+		TimeoutSeconds:       5,
+		RetentionDays:        90,
+		RecentDays:           3,
+		BaselineDays:         14,
+		MinRuns:              3,
+		DegradationThreshold: 0.3,
+	}}
+}
+
+func TestDetectAccountSmokeTestDegradation(t *testing.T) {
+	cfg := testAccountSmokeTestConfig().AccountSmokeTest
+	base := AccountSmokeTestWindowStats{BaselineRuns: 14, BaselinePassed: 14, RecentRuns: 3, RecentPassed: 1}
+	require.True(t, detectAccountSmokeTestDegradation(&base, cfg))
+
+	stable := base
+	stable.RecentPassed = 3
+	require.False(t, detectAccountSmokeTestDegradation(&stable, cfg))
+
+	// 一直失败的账号不是“下滑”
+	broken := base
+	broken.BaselinePassed = 0
+	broken.RecentPassed = 0
+	require.False(t, detectAccountSmokeTestDegradation(&broken, cfg))
+
+	// 样本不足不判断
+	sparse := base
+	sparse.RecentRuns = 2
+	sparse.RecentPassed = 0
+	require.False(t, detectAccountSmokeTestDegradation(&sparse, cfg))
+}
+
+func TestAccountSmokeTestService_RunAllRecordsEveryActiveAccount(t *testing.T) {
+	repo := &smokeTestRepoStub{}
+	svc := NewAccountSmokeTestService(repo, &smokeTestAccountRepoStub{accounts: []Account{{ID: 1}, {ID: 2}, {ID: -3}}}, nil, nil, testAccountSmokeTestConfig())
+	svc.tester = &smokeTesterStub{failing: map[int64]bool{2: true}}
+
+	tested, failed, err := svc.RunAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, tested)
+	require.Equal(t, 2, failed)
+	require.Len(t, repo.results, 3)
+
+	byAccount := map[int64]*AccountSmokeTestResult{}
+	for _, r := range repo.results {
+		byAccount[r.AccountID] = r
+	}
+	require.Equal(t, AccountSmokeTestStatusSuccess, byAccount[1].Status)
+	require.Equal(t, int64(120), byAccount[1].LatencyMs)
+	require.Equal(t, AccountSmokeTestStatusFailed, byAccount[2].Status)
+	require.Equal(t, "upstream 401", byAccount[2].ErrorMessage)
+	require.Equal(t, "account not found", byAccount[-3].ErrorMessage)
+}
+
+func TestAccountSmokeTestService_EvaluateFlagsAndClears(t *testing.T) {
+	repo := &smokeTestRepoStub{
+		stats: []*AccountSmokeTestWindowStats{
+			{AccountID: 1, AccountName: "a", BaselineRuns: 14, BaselinePassed: 14, RecentRuns: 3, RecentPassed: 1},
+			{AccountID: 2, AccountName: "b", BaselineRuns: 14, BaselinePassed: 14, RecentRuns: 3, RecentPassed: 3},
+		},
+		flags: map[int64]*AccountSmokeTestFlag{2: {AccountID: 2}},
+	}
+	svc := NewAccountSmokeTestService(repo, nil, nil, nil, testAccountSmokeTestConfig())
+
+	flagged, err := svc.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, flagged)
+	require.Contains(t, repo.flags, int64(1))
+	require.NotContains(t, repo.flags, int64(2), "recovered account should be unflagged")
+	require.InDelta(t, 1.0/3.0, repo.flags[1].RecentSuccessRate, 1e-9)
+
+	// 已标记的账号再次检测时不重复计数
+	flagged, err = svc.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, flagged)
+	require.Contains(t, repo.flags, int64(1))
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccountSnapshotState 快照记录的账号状态：套餐、资格标记、并发设置、代理/分组与冷却。
+// 冷却时间只在采集时仍未到期时记录，到期后表现为字段被清空。
+type AccountSnapshotState struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	Schedulable  bool   `json:"schedulable"`
+	ErrorMessage string `json:"error_message,omitempty"`
+
+	PlanType          string `json:"plan_type,omitempty"`
+	SubscriptionTier  string `json:"subscription_tier,omitempty"`
+	TierID            string `json:"tier_id,omitempty"`
+	EntitlementStatus string `json:"entitlement_status,omitempty"`
+	PrivacyMode       string `json:"privacy_mode,omitempty"`
+	// Flags extra 中的布尔开关（资格/能力标记，如 openai_compact_supported、mixed_scheduling）
+	Flags map[string]bool `json:"flags,omitempty"`
+
+	Concurrency    int      `json:"concurrency"`
+	Priority       int      `json:"priority"`
+	LoadFactor     *int     `json:"load_factor,omitempty"`
+	RateMultiplier *float64 `json:"rate_multiplier,omitempty"`
+	ProxyID        *int64   `json:"proxy_id,omitempty"`
+	GroupIDs       []int64  `json:"group_ids,omitempty"`
+
+	ExpiresAt               *time.Time           `json:"expires_at,omitempty"`
+	RateLimitResetAt        *time.Time           `json:"rate_limit_reset_at,omitempty"`
+	OverloadUntil           *time.Time           `json:"overload_until,omitempty"`
+	TempUnschedulableUntil  *time.Time           `json:"temp_unschedulable_until,omitempty"`
+	TempUnschedulableReason string               `json:"temp_unschedulable_reason,omitempty"`
+	ModelCooldowns          map[string]time.Time `json:"model_cooldowns,omitempty"`
+}
+
+// AccountSnapshot 一次账号状态快照。
+type AccountSnapshot struct {
+	ID         int64                `json:"id"`
+	AccountID  int64                `json:"account_id"`
+	CapturedAt time.Time            `json:"captured_at"`
+	StateHash  string               `json:"-"`
+	State      AccountSnapshotState `json:"state"`
+}
+
+// AccountSnapshotChange 相邻两次快照之间单个字段的变化；From/To 为 nil 表示字段不存在或已清空。
+type AccountSnapshotChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// AccountSnapshotTimelineEntry 时间线中的一次状态变化。
+type AccountSnapshotTimelineEntry struct {
+	ID         int64     `json:"id"`
+	CapturedAt time.Time `json:"captured_at"`
+	// Initial 为 true 表示保留期内的第一份快照，没有可对比的前序状态
+	Initial bool                    `json:"initial"`
+	Changes []AccountSnapshotChange `json:"changes"`
+	State   AccountSnapshotState    `json:"state"`
+}
+
+// AccountSnapshotTimeline 账号状态变化时间线（按采集时间倒序）。
+type AccountSnapshotTimeline struct {
+	AccountID int64                          `json:"account_id"`
+	Items     []AccountSnapshotTimelineEntry `json:"items"`
+	HasMore   bool                           `json:"has_more"`
+	// NextUntil 翻页游标：作为下一页的 until 参数
+	NextUntil *time.Time `json:"next_until,omitempty"`
+}
+
+// AccountSnapshotRepository 账号快照持久化端口。
+type AccountSnapshotRepository interface {
+	// LatestHashes 返回各账号最近一次快照的状态哈希，没有快照的账号不出现在结果中。
+	LatestHashes(ctx context.Context) (map[int64]string, error)
+	InsertBatch(ctx context.Context, snapshots []*AccountSnapshot) error
+	// ListByAccount 按采集时间倒序返回 captured_at 早于 before（nil 表示不限）的快照，最多 limit 条。
+	ListByAccount(ctx context.Context, accountID int64, before *time.Time, limit int) ([]*AccountSnapshot, error)
+	// DeleteBefore 删除 captured_at 早于 cutoff 的快照，单次最多 limit 条，返回删除条数。
+	DeleteBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// buildAccountSnapshotState 从账号当前状态构造快照内容。
+func buildAccountSnapshotState(account *Account, now time.Time) AccountSnapshotState {
+	state := AccountSnapshotState{
+		Name:                   account.Name,
+		Status:                 account.Status,
+		Schedulable:            account.Schedulable,
+		ErrorMessage:           account.ErrorMessage,
+		PlanType:               strings.TrimSpace(account.GetCredential("plan_type")),
+		SubscriptionTier:       strings.TrimSpace(account.GetCredential("subscription_tier")),
+		TierID:                 strings.TrimSpace(account.GetCredential("tier_id")),
+		EntitlementStatus:      strings.TrimSpace(account.GetCredential("entitlement_status")),
+		Concurrency:            account.Concurrency,
+		Priority:               account.Priority,
+		LoadFactor:             account.LoadFactor,
+		RateMultiplier:         account.RateMultiplier,
+		ProxyID:                account.ProxyID,
+		ExpiresAt:              account.ExpiresAt,
+		RateLimitResetAt:       snapshotFutureTime(account.RateLimitResetAt, now),
+		OverloadUntil:          snapshotFutureTime(account.OverloadUntil, now),
+		TempUnschedulableUntil: snapshotFutureTime(account.TempUnschedulableUntil, now),
+	}
+	if state.TempUnschedulableUntil != nil {
+		state.TempUnschedulableReason = account.TempUnschedulableReason
+	}
+	if mode, ok := account.Extra["privacy_mode"].(string); ok {
+		state.PrivacyMode = strings.TrimSpace(mode)
+	}
+	for key, value := range account.Extra {
+		if flag, ok := value.(bool); ok {
+			if state.Flags == nil {
+				state.Flags = make(map[string]bool)
+			}
+			state.Flags[key] = flag
+		}
+	}
+	if len(account.GroupIDs) > 0 {
+		state.GroupIDs = append([]int64(nil), account.GroupIDs...)
+		sort.Slice(state.GroupIDs, func(i, j int) bool { return state.GroupIDs[i] < state.GroupIDs[j] })
+	}
+	if limits, ok := account.Extra[modelRateLimitsKey].(map[string]any); ok {
+		for key := range limits {
+			if resetAt := snapshotFutureTime(account.modelRateLimitResetAt(key), now); resetAt != nil {
+				if state.ModelCooldowns == nil {
+					state.ModelCooldowns = make(map[string]time.Time)
+				}
+				state.ModelCooldowns[key] = *resetAt
+			}
+		}
+	}
+	return state
+}
+
+func snapshotFutureTime(t *time.Time, now time.Time) *time.Time {
+	if t == nil || !t.After(now) {
+		return nil
+	}
+	v := t.UTC()
+	return &v
+}
+
+// accountSnapshotStateHash 状态的稳定哈希（json 对 map 键排序，结果确定）。
+func accountSnapshotStateHash(state AccountSnapshotState) (string, error) {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// diffAccountSnapshotStates 逐字段比较两份状态，嵌套对象（flags、model_cooldowns）展开为 "flags.<key>"。
+func diffAccountSnapshotStates(from, to AccountSnapshotState) []AccountSnapshotChange {
+	before := flattenAccountSnapshotState(from)
+	after := flattenAccountSnapshotState(to)
+
+	fields := make([]string, 0, len(before)+len(after))
+	for field := range before {
+		fields = append(fields, field)
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := make([]AccountSnapshotChange, 0)
+	for _, field := range fields {
+		if reflect.DeepEqual(before[field], after[field]) {
+			continue
+		}
+		changes = append(changes, AccountSnapshotChange{Field: field, From: before[field], To: after[field]})
+	}
+	return changes
+}
+
+func flattenAccountSnapshotState(state AccountSnapshotState) map[string]any {
+	out := make(map[string]any)
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return out
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return out
+	}
+	for key, value := range doc {
+		if nested, ok := value.(map[string]any); ok {
+			for nestedKey, nestedValue := range nested {
+				out[key+"."+nestedKey] = nestedValue
+			}
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
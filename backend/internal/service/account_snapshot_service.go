@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	accountSnapshotLeaderLockKey = "account:snapshot:leader"
+	accountSnapshotLeaderLockTTL = 5 * time.Minute
+	// accountSnapshotPruneBatchSize 单次清理的快照条数上限，单轮最多清理 accountSnapshotPruneMaxBatches 批
+	accountSnapshotPruneBatchSize  = 5000
+	accountSnapshotPruneMaxBatches = 20
+
+	AccountSnapshotTimelineDefaultLimit = 50
+	AccountSnapshotTimelineMaxLimit     = 200
+)
+
+// AccountSnapshotService 周期采集账号状态快照并提供变更时间线。
+//
+// 每轮对比各账号当前状态与最近一次快照的哈希，只为状态发生变化的账号写入新快照，
+// 因此时间线上相邻两份快照之间的差异即为一次状态变化（精度为采集间隔）。
+type AccountSnapshotService struct {
+	repo        AccountSnapshotRepository
+	accountRepo AccountRepository
+	interval    time.Duration
+	retention   time.Duration
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+func NewAccountSnapshotService(repo AccountSnapshotRepository, accountRepo AccountRepository, interval, retention time.Duration) *AccountSnapshotService {
+	return &AccountSnapshotService{
+		repo:        repo,
+		accountRepo: accountRepo,
+		interval:    interval,
+		retention:   retention,
+		stopCh:      make(chan struct{}),
+		instanceID:  uuid.NewString(),
+		now:         time.Now,
+	}
+}
+
+// SetLeaderLock injects the leader-lock cache and DB so that only one instance
+// captures snapshots per cycle.
+func (s *AccountSnapshotService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+func (s *AccountSnapshotService) Start() {
+	if s == nil || s.repo == nil || s.accountRepo == nil || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *AccountSnapshotService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *AccountSnapshotService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, accountSnapshotLeaderLockKey, s.instanceID, accountSnapshotLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	captured, err := s.captureChanged(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.account_snapshot", "[AccountSnapshot] capture failed: %v", err)
+	}
+	if captured > 0 {
+		logger.LegacyPrintf("service.account_snapshot", "[AccountSnapshot] captured %d changed accounts", captured)
+	}
+	if s.retention > 0 {
+		if pruned, err := s.prune(ctx); err != nil {
+			logger.LegacyPrintf("service.account_snapshot", "[AccountSnapshot] prune failed: %v", err)
+		} else if pruned > 0 {
+			logger.LegacyPrintf("service.account_snapshot", "[AccountSnapshot] pruned %d expired snapshots", pruned)
+		}
+	}
+}
+
+// captureChanged 为状态与最近快照不同（或尚无快照）的账号写入新快照，返回写入条数。
+func (s *AccountSnapshotService) captureChanged(ctx context.Context) (int, error) {
+	accounts, err := s.accountRepo.ListAllWithFilters(ctx, "", "", "", "", 0, "")
+	if err != nil {
+		return 0, err
+	}
+	latest, err := s.repo.LatestHashes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now().UTC()
+	var changed []*AccountSnapshot
+	for i := range accounts {
+		account := &accounts[i]
+		state := buildAccountSnapshotState(account, now)
+		hash, err := accountSnapshotStateHash(state)
+		if err != nil {
+			logger.LegacyPrintf("service.account_snapshot", "[AccountSnapshot] hash state failed: account_id=%d err=%v", account.ID, err)
+			continue
+		}
+		if latest[account.ID] == hash {
+			continue
+		}
+		changed = append(changed, &AccountSnapshot{
+			AccountID:  account.ID,
+			CapturedAt: now,
+			StateHash:  hash,
+			State:      state,
+		})
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	if err := s.repo.InsertBatch(ctx, changed); err != nil {
+		return 0, err
+	}
+	return len(changed), nil
+}
+
+func (s *AccountSnapshotService) prune(ctx context.Context) (int64, error) {
+	cutoff := s.now().Add(-s.retention)
+	var total int64
+	for i := 0; i < accountSnapshotPruneMaxBatches; i++ {
+		deleted, err := s.repo.DeleteBefore(ctx, cutoff, accountSnapshotPruneBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < accountSnapshotPruneBatchSize {
+			break
+		}
+	}
+	return total, nil
+}
+
+// Timeline 返回账号在 (since, until) 区间内的状态变化，按采集时间倒序，每项附带与前一份快照的差异。
+// since/until 为 nil 表示不限；limit 超出范围时使用默认值/上限。
+func (s *AccountSnapshotService) Timeline(ctx context.Context, accountID int64, since, until *time.Time, limit int) (*AccountSnapshotTimeline, error) {
+	if accountID <= 0 {
+		return nil, ErrAccountNotFound
+	}
+	if exists, err := s.accountRepo.ExistsByID(ctx, accountID); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, ErrAccountNotFound
+	}
+	if limit <= 0 {
+		limit = AccountSnapshotTimelineDefaultLimit
+	}
+	if limit > AccountSnapshotTimelineMaxLimit {
+		limit = AccountSnapshotTimelineMaxLimit
+	}
+
+	// 多取一条作为最旧一项的对比基准（即使它早于 since）
+	snapshots, err := s.repo.ListByAccount(ctx, accountID, until, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &AccountSnapshotTimeline{AccountID: accountID, Items: []AccountSnapshotTimelineEntry{}}
+	for i, snapshot := range snapshots {
+		if i == limit || (since != nil && !snapshot.CapturedAt.After(*since)) {
+			break
+		}
+		entry := AccountSnapshotTimelineEntry{
+			ID:         snapshot.ID,
+			CapturedAt: snapshot.CapturedAt,
+			Changes:    []AccountSnapshotChange{},
+			State:      snapshot.State,
+		}
+		if i+1 < len(snapshots) {
+			entry.Changes = diffAccountSnapshotStates(snapshots[i+1].State, snapshot.State)
+		} else {
+			entry.Initial = true
+		}
+		timeline.Items = append(timeline.Items, entry)
+	}
+
+	if n := len(timeline.Items); n == limit && len(snapshots) > limit {
+		oldest := snapshots[limit]
+		if since == nil || oldest.CapturedAt.After(*since) {
+			timeline.HasMore = true
+			cursor := timeline.Items[n-1].CapturedAt
+			timeline.NextUntil = &cursor
+		}
+	}
+	return timeline, nil
+}
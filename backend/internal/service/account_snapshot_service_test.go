@@ -0,0 +1,153 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type accountSnapshotRepoStub struct {
+	latest   map[int64]string
+	inserted []*AccountSnapshot
+	listed   []*AccountSnapshot
+	before   *time.Time
+	limit    int
+}
+
+func (s *accountSnapshotRepoStub) LatestHashes(context.Context) (map[int64]string, error) {
+	return s.latest, nil
+}
+
+func (s *accountSnapshotRepoStub) InsertBatch(_ context.Context, snapshots []*AccountSnapshot) error {
+	s.inserted = append(s.inserted, snapshots...)
+	return nil
+}
+
+func (s *accountSnapshotRepoStub) ListByAccount(_ context.Context, _ int64, before *time.Time, limit int) ([]*AccountSnapshot, error) {
+	s.before, s.limit = before, limit
+	if len(s.listed) > limit {
+		return s.listed[:limit], nil
+	}
+	return s.listed, nil
+}
+
+func (s *accountSnapshotRepoStub) DeleteBefore(context.Context, time.Time, int) (int64, error) {
+	return 0, nil
+}
+
+type accountSnapshotAccountRepoStub struct {
+	AccountRepository
+	accounts []Account
+}
+
+func (s *accountSnapshotAccountRepoStub) ListAllWithFilters(context.Context, string, string, string, string, int64, string) ([]Account, error) {
+	return s.accounts, nil
+}
+
+func (s *accountSnapshotAccountRepoStub) ExistsByID(_ context.Context, id int64) (bool, error) {
+	return id == 1, nil
+}
+
+func TestBuildAccountSnapshotState(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	proxyID := int64(9)
+	account := &Account{
+		Name:                    "team-a",
+		Status:                  StatusActive,
+		Schedulable:             true,
+		Credentials:             map[string]any{"plan_type": "pro", "access_token": "secret"},
+		Extra:                   map[string]any{"privacy_mode": "training_off", "openai_compact_supported": false, "codex_5h_used_percent": 42.0},
+		Concurrency:             3,
+		ProxyID:                 &proxyID,
+		GroupIDs:                []int64{5, 2},
+		RateLimitResetAt:        &past,
+		TempUnschedulableUntil:  &future,
+		TempUnschedulableReason: "401",
+	}
+
+	state := buildAccountSnapshotState(account, now)
+	require.Equal(t, "pro", state.PlanType)
+	require.Equal(t, "training_off", state.PrivacyMode)
+	require.Equal(t, map[string]bool{"openai_compact_supported": false}, state.Flags)
+	require.Equal(t, []int64{2, 5}, state.GroupIDs)
+	require.Nil(t, state.RateLimitResetAt)
+	require.Equal(t, future, *state.TempUnschedulableUntil)
+	require.Equal(t, "401", state.TempUnschedulableReason)
+}
+
+func TestDiffAccountSnapshotStates(t *testing.T) {
+	proxyA, proxyB := int64(1), int64(2)
+	from := AccountSnapshotState{Status: StatusActive, PlanType: "pro", Concurrency: 3, ProxyID: &proxyA, Flags: map[string]bool{"video_enabled": true}}
+	to := AccountSnapshotState{Status: StatusActive, PlanType: "free", Concurrency: 3, ProxyID: &proxyB, Flags: map[string]bool{"video_enabled": false}}
+
+	changes := diffAccountSnapshotStates(from, to)
+	require.Equal(t, []AccountSnapshotChange{
+		{Field: "flags.video_enabled", From: true, To: false},
+		{Field: "plan_type", From: "pro", To: "free"},
+		{Field: "proxy_id", From: float64(1), To: float64(2)},
+	}, changes)
+	require.Empty(t, diffAccountSnapshotStates(to, to))
+}
+
+func TestAccountSnapshotService_CaptureOnlyChangedAccounts(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	unchanged := Account{ID: 1, Status: StatusActive, Concurrency: 3}
+	hash, err := accountSnapshotStateHash(buildAccountSnapshotState(&unchanged, now))
+	require.NoError(t, err)
+
+	repo := &accountSnapshotRepoStub{latest: map[int64]string{1: hash, 2: "stale"}}
+	accounts := &accountSnapshotAccountRepoStub{accounts: []Account{
+		unchanged,
+		{ID: 2, Status: StatusError, ErrorMessage: "token revoked"},
+		{ID: 3, Status: StatusActive},
+	}}
+	svc := NewAccountSnapshotService(repo, accounts, time.Minute, time.Hour)
+	svc.now = func() time.Time { return now }
+
+	captured, err := svc.captureChanged(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, captured)
+	require.Len(t, repo.inserted, 2)
+	require.Equal(t, int64(2), repo.inserted[0].AccountID)
+	require.Equal(t, int64(3), repo.inserted[1].AccountID)
+	require.Equal(t, now, repo.inserted[0].CapturedAt)
+}
+
+func TestAccountSnapshotService_Timeline(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	repo := &accountSnapshotRepoStub{listed: []*AccountSnapshot{
+		{ID: 3, CapturedAt: t0.Add(2 * time.Hour), State: AccountSnapshotState{Status: StatusActive, PlanType: "free"}},
+		{ID: 2, CapturedAt: t0.Add(time.Hour), State: AccountSnapshotState{Status: StatusActive, PlanType: "pro"}},
+		{ID: 1, CapturedAt: t0, State: AccountSnapshotState{Status: StatusError, PlanType: "pro"}},
+	}}
+	svc := NewAccountSnapshotService(repo, &accountSnapshotAccountRepoStub{}, 0, 0)
+
+	timeline, err := svc.Timeline(context.Background(), 1, nil, nil, 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, repo.limit)
+	require.Len(t, timeline.Items, 2)
+	require.Equal(t, []AccountSnapshotChange{{Field: "plan_type", From: "pro", To: "free"}}, timeline.Items[0].Changes)
+	require.Equal(t, []AccountSnapshotChange{{Field: "status", From: StatusError, To: StatusActive}}, timeline.Items[1].Changes)
+	require.True(t, timeline.HasMore)
+	require.Equal(t, t0.Add(time.Hour), *timeline.NextUntil)
+
+	since := t0.Add(30 * time.Minute)
+	timeline, err = svc.Timeline(context.Background(), 1, &since, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, timeline.Items, 2)
+	require.False(t, timeline.Items[1].Initial)
+	require.False(t, timeline.HasMore)
+
+	timeline, err = svc.Timeline(context.Background(), 1, nil, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, timeline.Items, 3)
+	require.True(t, timeline.Items[2].Initial)
+
+	_, err = svc.Timeline(context.Background(), 404, nil, nil, 10)
+	require.ErrorIs(t, err, ErrAccountNotFound)
+}
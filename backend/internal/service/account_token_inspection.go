@@ -0,0 +1,342 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/openai"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/xai"
+)
+
+// 账号令牌检查发现的问题代码
+const (
+	AccountTokenIssueInactive          = "account_inactive"
+	AccountTokenIssueNotSchedulable    = "not_schedulable"
+	AccountTokenIssueAccountExpired    = "account_expired"
+	AccountTokenIssueRateLimited       = "rate_limited"
+	AccountTokenIssueOverloaded        = "overloaded"
+	AccountTokenIssueTempUnschedulable = "temp_unschedulable"
+	AccountTokenIssueQuotaExceeded     = "quota_exceeded"
+	AccountTokenIssueTokenMissing      = "access_token_missing"
+	AccountTokenIssueTokenExpired      = "access_token_expired"
+	AccountTokenIssueRefreshMissing    = "refresh_token_missing"
+	AccountTokenIssueScopeMissing      = "scope_missing"
+	AccountTokenIssueNoGroups          = "no_groups"
+	AccountTokenIssueGroupInactive     = "group_inactive"
+	AccountTokenIssuePlatformMismatch  = "platform_mismatch"
+	AccountTokenIssueOAuthRequired     = "oauth_required"
+	AccountTokenIssuePrivacyNotSet     = "privacy_not_set"
+)
+
+// accountTokenRequiredScopes 各平台 OAuth 账号发起推理请求必需的 scope。
+// 仅在能读到令牌 scope 时校验；OpenAI / Grok 的 OAuth 令牌不以 scope 区分推理权限。
+var accountTokenRequiredScopes = map[string][]string{
+	PlatformAnthropic:   {"user:inference"},
+	PlatformGemini:      {"https://www.googleapis.com/auth/cloud-platform"},
+	PlatformAntigravity: {"https://www.googleapis.com/auth/cloud-platform"},
+}
+
+// accountTokenFields 参与检查的凭据字段；令牌本身不会出现在结果中，只返回指纹。
+var accountTokenFields = []string{"access_token", "id_token", "refresh_token"}
+
+// AccountTokenInspection 账号令牌与权限检查结果，用于排查“账号为什么一直不被调度”。
+type AccountTokenInspection struct {
+	AccountID   int64     `json:"account_id"`
+	Name        string    `json:"name"`
+	Platform    string    `json:"platform"`
+	Type        string    `json:"type"`
+	InspectedAt time.Time `json:"inspected_at"`
+
+	Tokens       []AccountTokenDetail     `json:"tokens"`
+	Scopes       []string                 `json:"scopes"`
+	ExpiresAt    *time.Time               `json:"expires_at,omitempty"`
+	Expired      bool                     `json:"expired"`
+	Entitlements AccountTokenEntitlements `json:"entitlements"`
+
+	// Schedulable 账号本身可被调度（不含分组维度）
+	Schedulable bool                `json:"schedulable"`
+	Issues      []AccountTokenIssue `json:"issues"`
+	Groups      []AccountTokenGroup `json:"groups"`
+}
+
+// AccountTokenDetail 单个凭据字段的解析结果。
+type AccountTokenDetail struct {
+	Field string `json:"field"`
+	// Format jwt 或 opaque
+	Format string `json:"format"`
+	// Fingerprint 令牌 SHA-256 前 12 位，用于比对是否为同一令牌
+	Fingerprint string         `json:"fingerprint"`
+	Claims      map[string]any `json:"claims,omitempty"`
+	IssuedAt    *time.Time     `json:"issued_at,omitempty"`
+	ExpiresAt   *time.Time     `json:"expires_at,omitempty"`
+}
+
+// AccountTokenEntitlements 令牌与凭据中的组织、套餐信息。
+type AccountTokenEntitlements struct {
+	Email             string                     `json:"email,omitempty"`
+	PlanType          string                     `json:"plan_type,omitempty"`
+	SubscriptionTier  string                     `json:"subscription_tier,omitempty"`
+	TierID            string                     `json:"tier_id,omitempty"`
+	EntitlementStatus string                     `json:"entitlement_status,omitempty"`
+	OrganizationID    string                     `json:"organization_id,omitempty"`
+	ChatGPTAccountID  string                     `json:"chatgpt_account_id,omitempty"`
+	ProjectID         string                     `json:"project_id,omitempty"`
+	OAuthType         string                     `json:"oauth_type,omitempty"`
+	PrivacyMode       string                     `json:"privacy_mode,omitempty"`
+	Organizations     []openai.OrganizationClaim `json:"organizations,omitempty"`
+}
+
+// AccountTokenIssue 阻止调度的问题。
+type AccountTokenIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// AccountTokenGroup 账号在某个分组中的资格检查结果。
+type AccountTokenGroup struct {
+	GroupID  int64               `json:"group_id"`
+	Name     string              `json:"name"`
+	Platform string              `json:"platform"`
+	Eligible bool                `json:"eligible"`
+	Issues   []AccountTokenIssue `json:"issues"`
+}
+
+// InspectAccountToken 解析账号存储的令牌（JWT 只解码不验签），汇总 scope、套餐与过期时间，
+// 并对照账号所属分组的平台要求给出不能被调度的原因。account.Groups 需已加载。
+func InspectAccountToken(account *Account, now time.Time) *AccountTokenInspection {
+	out := &AccountTokenInspection{
+		AccountID:   account.ID,
+		Name:        account.Name,
+		Platform:    account.Platform,
+		Type:        account.Type,
+		InspectedAt: now,
+		Tokens:      []AccountTokenDetail{},
+		Scopes:      []string{},
+		Issues:      []AccountTokenIssue{},
+		Groups:      []AccountTokenGroup{},
+	}
+
+	var accessClaims map[string]any
+	for _, field := range accountTokenFields {
+		token := strings.TrimSpace(account.GetCredential(field))
+		if token == "" {
+			continue
+		}
+		detail := inspectAccountTokenValue(field, token)
+		if field == "access_token" {
+			accessClaims = detail.Claims
+		}
+		out.Tokens = append(out.Tokens, detail)
+	}
+
+	out.Scopes = accountTokenScopes(account, accessClaims)
+	out.ExpiresAt = account.GetCredentialAsTime("expires_at")
+	if out.ExpiresAt == nil {
+		out.ExpiresAt = jwtClaimTime(accessClaims, "exp")
+	}
+	out.Expired = out.ExpiresAt != nil && !now.Before(*out.ExpiresAt)
+	out.Entitlements = accountTokenEntitlements(account)
+
+	out.Issues = accountTokenStateIssues(account, now)
+	if account.IsOAuth() || account.Type == AccountTypeServiceAccount {
+		out.Issues = append(out.Issues, accountTokenCredentialIssues(account, out)...)
+	}
+	out.Schedulable = len(out.Issues) == 0
+
+	if len(account.Groups) == 0 {
+		out.Issues = append(out.Issues, AccountTokenIssue{Code: AccountTokenIssueNoGroups, Message: "account does not belong to any group"})
+	}
+	for _, group := range account.Groups {
+		if group == nil {
+			continue
+		}
+		issues := accountTokenGroupIssues(account, group)
+		out.Groups = append(out.Groups, AccountTokenGroup{
+			GroupID:  group.ID,
+			Name:     group.Name,
+			Platform: group.Platform,
+			Eligible: out.Schedulable && len(issues) == 0,
+			Issues:   issues,
+		})
+	}
+	return out
+}
+
+func inspectAccountTokenValue(field, token string) AccountTokenDetail {
+	sum := sha256.Sum256([]byte(token))
+	detail := AccountTokenDetail{Field: field, Format: "opaque", Fingerprint: hex.EncodeToString(sum[:])[:12]}
+	// refresh_token 即使是 JWT 也不解码，避免在结果中暴露可用于换取新令牌的信息
+	if field == "refresh_token" || strings.Count(token, ".") != 2 {
+		return detail
+	}
+	claims := xai.DecodeJWTClaims(token)
+	if claims == nil {
+		return detail
+	}
+	detail.Format = "jwt"
+	detail.Claims = claims
+	detail.IssuedAt = jwtClaimTime(claims, "iat")
+	detail.ExpiresAt = jwtClaimTime(claims, "exp")
+	return detail
+}
+
+// accountTokenScopes 优先使用凭据中保存的 scope，缺失时读取 access token 的 scp / scope 声明。
+func accountTokenScopes(account *Account, accessClaims map[string]any) []string {
+	if scope := strings.TrimSpace(account.GetCredential("scope")); scope != "" {
+		return strings.Fields(scope)
+	}
+	for _, key := range []string{"scp", "scope"} {
+		switch v := accessClaims[key].(type) {
+		case string:
+			if fields := strings.Fields(v); len(fields) > 0 {
+				return fields
+			}
+		case []any:
+			scopes := make([]string, 0, len(v))
+			for _, item := range v {
+				if s, ok := item.(string); ok && s != "" {
+					scopes = append(scopes, s)
+				}
+			}
+			if len(scopes) > 0 {
+				return scopes
+			}
+		}
+	}
+	return []string{}
+}
+
+func accountTokenEntitlements(account *Account) AccountTokenEntitlements {
+	ent := AccountTokenEntitlements{
+		Email:             strings.TrimSpace(account.GetCredential("email")),
+		PlanType:          strings.TrimSpace(account.GetCredential("plan_type")),
+		SubscriptionTier:  strings.TrimSpace(account.GetCredential("subscription_tier")),
+		TierID:            strings.TrimSpace(account.GetCredential("tier_id")),
+		EntitlementStatus: strings.TrimSpace(account.GetCredential("entitlement_status")),
+		ChatGPTAccountID:  strings.TrimSpace(account.GetCredential("chatgpt_account_id")),
+		ProjectID:         strings.TrimSpace(account.GetCredential("project_id")),
+		OAuthType:         account.GeminiOAuthType(),
+		PrivacyMode:       account.getExtraString("privacy_mode"),
+		OrganizationID:    account.getExtraString("org_uuid"),
+	}
+	if ent.OrganizationID == "" {
+		ent.OrganizationID = strings.TrimSpace(account.GetCredential("organization_id"))
+	}
+	if account.Platform == PlatformOpenAI {
+		if idToken := strings.TrimSpace(account.GetCredential("id_token")); idToken != "" {
+			if claims, err := openai.DecodeIDToken(idToken); err == nil {
+				if ent.Email == "" {
+					ent.Email = claims.Email
+				}
+				if auth := claims.OpenAIAuth; auth != nil {
+					if ent.PlanType == "" {
+						ent.PlanType = auth.ChatGPTPlanType
+					}
+					if ent.ChatGPTAccountID == "" {
+						ent.ChatGPTAccountID = auth.ChatGPTAccountID
+					}
+					if ent.OrganizationID == "" {
+						ent.OrganizationID = auth.POID
+					}
+					ent.Organizations = auth.Organizations
+				}
+			}
+		}
+	}
+	return ent
+}
+
+// accountTokenStateIssues 与 Account.IsSchedulable 的判断保持一致，逐条列出原因。
+func accountTokenStateIssues(account *Account, now time.Time) []AccountTokenIssue {
+	issues := []AccountTokenIssue{}
+	add := func(code, format string, args ...any) {
+		issues = append(issues, AccountTokenIssue{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+	if !account.IsActive() {
+		add(AccountTokenIssueInactive, "account status is %q", account.Status)
+	}
+	if !account.Schedulable {
+		add(AccountTokenIssueNotSchedulable, "account scheduling is switched off")
+	}
+	if account.AutoPauseOnExpired && account.ExpiresAt != nil && !now.Before(*account.ExpiresAt) {
+		add(AccountTokenIssueAccountExpired, "account expired at %s", account.ExpiresAt.Format(time.RFC3339))
+	}
+	if account.OverloadUntil != nil && now.Before(*account.OverloadUntil) {
+		add(AccountTokenIssueOverloaded, "overloaded until %s", account.OverloadUntil.Format(time.RFC3339))
+	}
+	if account.RateLimitResetAt != nil && now.Before(*account.RateLimitResetAt) {
+		add(AccountTokenIssueRateLimited, "rate limited until %s", account.RateLimitResetAt.Format(time.RFC3339))
+	}
+	if account.TempUnschedulableUntil != nil && now.Before(*account.TempUnschedulableUntil) {
+		add(AccountTokenIssueTempUnschedulable, "temporarily unschedulable until %s: %s", account.TempUnschedulableUntil.Format(time.RFC3339), account.TempUnschedulableReason)
+	}
+	if account.IsAPIKeyOrBedrock() && account.IsQuotaExceeded() {
+		add(AccountTokenIssueQuotaExceeded, "account quota exceeded")
+	}
+	return issues
+}
+
+func accountTokenCredentialIssues(account *Account, inspection *AccountTokenInspection) []AccountTokenIssue {
+	issues := []AccountTokenIssue{}
+	if account.Type == AccountTypeServiceAccount {
+		return issues
+	}
+	hasAccess := slices.ContainsFunc(inspection.Tokens, func(d AccountTokenDetail) bool { return d.Field == "access_token" })
+	hasRefresh := slices.ContainsFunc(inspection.Tokens, func(d AccountTokenDetail) bool { return d.Field == "refresh_token" })
+	if !hasAccess {
+		issues = append(issues, AccountTokenIssue{Code: AccountTokenIssueTokenMissing, Message: "no access_token stored"})
+	}
+	if inspection.Expired {
+		msg := fmt.Sprintf("access token expired at %s", inspection.ExpiresAt.Format(time.RFC3339))
+		if hasRefresh {
+			msg += " (a refresh should renew it; check refresh errors if this persists)"
+		}
+		issues = append(issues, AccountTokenIssue{Code: AccountTokenIssueTokenExpired, Message: msg})
+		if !hasRefresh && account.Type == AccountTypeOAuth {
+			issues = append(issues, AccountTokenIssue{Code: AccountTokenIssueRefreshMissing, Message: "access token expired and no refresh_token is stored"})
+		}
+	}
+	if len(inspection.Scopes) > 0 {
+		for _, required := range accountTokenRequiredScopes[account.Platform] {
+			if !slices.Contains(inspection.Scopes, required) {
+				issues = append(issues, AccountTokenIssue{Code: AccountTokenIssueScopeMissing, Message: fmt.Sprintf("token is missing required scope %q", required)})
+			}
+		}
+	}
+	return issues
+}
+
+// accountTokenGroupIssues 对照分组的平台要求（平台、仅 OAuth、隐私设置）检查账号资格。
+func accountTokenGroupIssues(account *Account, group *Group) []AccountTokenIssue {
+	issues := []AccountTokenIssue{}
+	if !group.IsActive() {
+		issues = append(issues, AccountTokenIssue{Code: AccountTokenIssueGroupInactive, Message: fmt.Sprintf("group status is %q", group.Status)})
+	}
+	mixed := account.IsMixedSchedulingEnabled() && (group.Platform == PlatformAnthropic || group.Platform == PlatformGemini)
+	if group.Platform != "" && group.Platform != account.Platform && !mixed {
+		issues = append(issues, AccountTokenIssue{
+			Code:    AccountTokenIssuePlatformMismatch,
+			Message: fmt.Sprintf("group platform %q does not match account platform %q", group.Platform, account.Platform),
+		})
+	}
+	if group.RequireOAuthOnly && account.Type == AccountTypeAPIKey {
+		issues = append(issues, AccountTokenIssue{Code: AccountTokenIssueOAuthRequired, Message: "group only allows OAuth accounts"})
+	}
+	if group.RequirePrivacySet && !account.IsPrivacySet() {
+		issues = append(issues, AccountTokenIssue{Code: AccountTokenIssuePrivacyNotSet, Message: "group requires privacy to be set on the account"})
+	}
+	return issues
+}
+
+func jwtClaimTime(claims map[string]any, key string) *time.Time {
+	v, ok := claims[key].(float64)
+	if !ok || v <= 0 {
+		return nil
+	}
+	t := time.Unix(int64(v), 0).UTC()
+	return &t
+}
@@ -0,0 +1,118 @@
+//go:build unit
+
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testInspectionJWT(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func TestInspectAccountToken_OpenAIEntitlementsAndExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	idToken := testInspectionJWT(t, map[string]any{
+		"email": "ops@example.com",
+		"exp":   now.Add(time.Hour).Unix(),
+		"https://api.openai.com/auth": map[string]any{
+			"chatgpt_plan_type":  "team",
+			"chatgpt_account_id": "acct-1",
+			"organizations":      []any{map[string]any{"id": "org-1", "role": "owner", "is_default": true}},
+		},
+	})
+	accessToken := testInspectionJWT(t, map[string]any{"exp": now.Add(-time.Minute).Unix(), "iat": now.Add(-time.Hour).Unix()})
+	account := &Account{
+		ID: 7, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Status: StatusActive, Schedulable: true,
+		Credentials: map[string]any{"access_token": accessToken, "id_token": idToken, "refresh_token": "rt-opaque"},
+		Extra:       map[string]any{"privacy_mode": PrivacyModeTrainingOff},
+		Groups:      []*Group{{ID: 1, Name: "openai-team", Platform: PlatformOpenAI, Status: StatusActive, RequirePrivacySet: true}},
+	}
+
+	out := InspectAccountToken(account, now)
+	require.Len(t, out.Tokens, 3)
+	require.Equal(t, "jwt", out.Tokens[0].Format)
+	require.Equal(t, "opaque", out.Tokens[2].Format)
+	require.Nil(t, out.Tokens[2].Claims)
+	require.Len(t, out.Tokens[0].Fingerprint, 12)
+
+	require.Equal(t, "team", out.Entitlements.PlanType)
+	require.Equal(t, "acct-1", out.Entitlements.ChatGPTAccountID)
+	require.Equal(t, "ops@example.com", out.Entitlements.Email)
+	require.Len(t, out.Entitlements.Organizations, 1)
+
+	// credentials 无 expires_at 时取 access token 的 exp
+	require.True(t, out.Expired)
+	require.False(t, out.Schedulable)
+	require.Equal(t, AccountTokenIssueTokenExpired, out.Issues[0].Code)
+	require.Len(t, out.Groups, 1)
+	require.False(t, out.Groups[0].Eligible)
+	require.Empty(t, out.Groups[0].Issues)
+
+	// JSON 输出中不包含令牌原文
+	raw, err := json.Marshal(out)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), accessToken)
+	require.NotContains(t, string(raw), "rt-opaque")
+}
+
+func TestInspectAccountToken_GroupRequirements(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	account := &Account{
+		ID: 8, Platform: PlatformAnthropic, Type: AccountTypeOAuth, Status: StatusActive, Schedulable: true,
+		Credentials: map[string]any{
+			"access_token": "sk-ant-oat-opaque",
+			"scope":        "user:profile user:sessions:claude_code",
+			"expires_at":   now.Add(time.Hour).Format(time.RFC3339),
+		},
+		Groups: []*Group{
+			{ID: 1, Name: "claude", Platform: PlatformAnthropic, Status: StatusActive, RequireOAuthOnly: true},
+			{ID: 2, Name: "codex", Platform: PlatformOpenAI, Status: StatusActive},
+			{ID: 3, Name: "old", Platform: PlatformAnthropic, Status: "inactive"},
+		},
+	}
+
+	out := InspectAccountToken(account, now)
+	require.False(t, out.Expired)
+	require.Equal(t, []string{"user:profile", "user:sessions:claude_code"}, out.Scopes)
+	require.Len(t, out.Issues, 1)
+	require.Equal(t, AccountTokenIssueScopeMissing, out.Issues[0].Code)
+
+	// 补上 scope 后账号本身可调度，只剩分组维度的问题
+	account.Credentials["scope"] = "user:inference"
+	out = InspectAccountToken(account, now)
+	require.True(t, out.Schedulable)
+	require.True(t, out.Groups[0].Eligible)
+	require.False(t, out.Groups[1].Eligible)
+	require.Equal(t, AccountTokenIssuePlatformMismatch, out.Groups[1].Issues[0].Code)
+	require.Equal(t, AccountTokenIssueGroupInactive, out.Groups[2].Issues[0].Code)
+
+	// API Key 账号进入仅 OAuth 分组
+	apiKeyAccount := &Account{Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Groups: account.Groups[:1]}
+	out = InspectAccountToken(apiKeyAccount, now)
+	require.Equal(t, AccountTokenIssueOAuthRequired, out.Groups[0].Issues[0].Code)
+}
+
+func TestInspectAccountToken_StateAndNoGroups(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	until := now.Add(10 * time.Minute)
+	account := &Account{
+		Platform: PlatformGemini, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: false,
+		RateLimitResetAt: &until, TempUnschedulableUntil: &until, TempUnschedulableReason: "401 unauthorized",
+	}
+	out := InspectAccountToken(account, now)
+	codes := make([]string, 0, len(out.Issues))
+	for _, issue := range out.Issues {
+		codes = append(codes, issue.Code)
+	}
+	require.Equal(t, []string{AccountTokenIssueNotSchedulable, AccountTokenIssueRateLimited, AccountTokenIssueTempUnschedulable, AccountTokenIssueNoGroups}, codes)
+	require.Contains(t, out.Issues[2].Message, "401 unauthorized")
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const (
+	// AccountWarmupStartedAtExtraKey 预热起点（RFC3339）；账号由停用恢复为启用时写入，未设置时以创建时间为起点。
+	AccountWarmupStartedAtExtraKey = "warmup_started_at"
+	// AccountWarmupDisabledExtraKey 为 true 时该账号跳过预热，直接按全量份额调度。
+	AccountWarmupDisabledExtraKey = "warmup_disabled"
+)
+
+// AccountWarmupPolicy 新启用账号的流量预热策略（由 gateway.account_warmup 构造）；nil 表示关闭，方法对 nil 安全。
+type AccountWarmupPolicy struct {
+	window       time.Duration
+	initialShare float64
+}
+
+// NewAccountWarmupPolicy 按配置创建预热策略；未启用或参数无效时返回 nil。
+func NewAccountWarmupPolicy(cfg *config.Config) *AccountWarmupPolicy {
+	if cfg == nil {
+		return nil
+	}
+	warmup := cfg.Gateway.AccountWarmup
+	if !warmup.Enabled || warmup.WindowHours <= 0 || warmup.InitialShare <= 0 {
+		return nil
+	}
+	return &AccountWarmupPolicy{
+		window:       time.Duration(warmup.WindowHours) * time.Hour,
+		initialShare: min(warmup.InitialShare, 1),
+	}
+}
+
+// AccountWarmupStatus 账号预热状态（管理端展示）
+type AccountWarmupStatus struct {
+	// Share 当前可承接的非粘性流量份额（0-1]
+	Share     float64
+	StartedAt time.Time
+	EndsAt    time.Time
+}
+
+// Status 返回账号当前的预热状态；未启用预热、账号已跳过预热或已过窗口时返回 nil。
+func (p *AccountWarmupPolicy) Status(a *Account, now time.Time) *AccountWarmupStatus {
+	if p == nil || a == nil || a.getExtraBool(AccountWarmupDisabledExtraKey) {
+		return nil
+	}
+	start := a.CreatedAt
+	if restarted := a.getExtraTime(AccountWarmupStartedAtExtraKey); restarted.After(start) {
+		start = restarted
+	}
+	if start.IsZero() {
+		return nil
+	}
+	elapsed := now.Sub(start)
+	if elapsed >= p.window {
+		return nil
+	}
+	share := p.initialShare
+	if elapsed > 0 {
+		share += (1 - p.initialShare) * float64(elapsed) / float64(p.window)
+	}
+	return &AccountWarmupStatus{
+		Share:     share,
+		StartedAt: start,
+		EndsAt:    start.Add(p.window),
+	}
+}
+
+// applyRamp 按预热份额对候选账号做随机准入：预热中的账号以 Share 的概率保留在本次候选中。
+// keepAccountID 为本次请求的粘性账号，始终保留。过滤后若没有任何当前可调度的账号则返回原列表，
+// 避免其余账号都已限流时预热反而导致无号可用。
+func (p *AccountWarmupPolicy) applyRamp(accounts []Account, keepAccountID int64) []Account {
+	if p == nil || len(accounts) < 2 {
+		return accounts
+	}
+	now := time.Now()
+	var kept []Account
+	for i := range accounts {
+		if accounts[i].ID != keepAccountID {
+			if status := p.Status(&accounts[i], now); status != nil && rand.Float64() >= status.Share {
+				if kept == nil {
+					kept = make([]Account, 0, len(accounts))
+					kept = append(kept, accounts[:i]...)
+				}
+				continue
+			}
+		}
+		if kept != nil {
+			kept = append(kept, accounts[i])
+		}
+	}
+	if kept == nil {
+		return accounts
+	}
+	for i := range kept {
+		if kept[i].IsSchedulable() {
+			return kept
+		}
+	}
+	return accounts
+}
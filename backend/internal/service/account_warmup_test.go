@@ -0,0 +1,112 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccountWarmupPolicyForTest(t *testing.T, windowHours int, initialShare float64) *AccountWarmupPolicy {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Gateway.AccountWarmup = config.GatewayAccountWarmupConfig{Enabled: true, WindowHours: windowHours, InitialShare: initialShare}
+	policy := NewAccountWarmupPolicy(cfg)
+	require.NotNil(t, policy)
+	return policy
+}
+
+func TestAccountWarmupStatus_LinearRamp(t *testing.T) {
+	policy := newAccountWarmupPolicyForTest(t, 48, 0.1)
+	now := time.Now()
+
+	fresh := &Account{CreatedAt: now}
+	status := policy.Status(fresh, now)
+	require.NotNil(t, status)
+	require.InDelta(t, 0.1, status.Share, 1e-9)
+	require.Equal(t, now.Add(48*time.Hour), status.EndsAt)
+
+	half := &Account{CreatedAt: now.Add(-24 * time.Hour)}
+	require.InDelta(t, 0.55, policy.Status(half, now).Share, 1e-9)
+
+	require.Nil(t, policy.Status(&Account{CreatedAt: now.Add(-49 * time.Hour)}, now))
+	require.Nil(t, policy.Status(&Account{}, now), "zero created_at is not warming")
+	require.Nil(t, policy.Status(&Account{CreatedAt: now, Extra: map[string]any{AccountWarmupDisabledExtraKey: true}}, now))
+}
+
+func TestAccountWarmupStatus_RestartedAtOverridesCreatedAt(t *testing.T) {
+	policy := newAccountWarmupPolicyForTest(t, 10, 0.5)
+	now := time.Now()
+	restarted := now.Add(-5 * time.Hour).UTC().Truncate(time.Second)
+
+	account := &Account{
+		CreatedAt: now.Add(-30 * 24 * time.Hour),
+		Extra:     map[string]any{AccountWarmupStartedAtExtraKey: restarted.Format(time.RFC3339)},
+	}
+	status := policy.Status(account, now)
+	require.NotNil(t, status)
+	require.True(t, status.StartedAt.Equal(restarted))
+	require.InDelta(t, 0.75, status.Share, 0.01)
+}
+
+func TestAccountWarmupStatus_DisabledConfig(t *testing.T) {
+	require.Nil(t, NewAccountWarmupPolicy(nil))
+	require.Nil(t, NewAccountWarmupPolicy(&config.Config{}))
+
+	var disabled *AccountWarmupPolicy
+	require.Nil(t, disabled.Status(&Account{CreatedAt: time.Now()}, time.Now()))
+}
+
+func TestApplyAccountWarmupRamp(t *testing.T) {
+	policy := newAccountWarmupPolicyForTest(t, 48, 0.0001)
+	now := time.Now()
+	mature := Account{ID: 1, Status: StatusActive, Schedulable: true, CreatedAt: now.Add(-72 * time.Hour)}
+	warming := Account{ID: 2, Status: StatusActive, Schedulable: true, CreatedAt: now}
+
+	t.Run("drops warming accounts that lose the roll", func(t *testing.T) {
+		out := policy.applyRamp([]Account{warming, mature}, 0)
+		require.Len(t, out, 1)
+		require.Equal(t, int64(1), out[0].ID)
+	})
+
+	t.Run("keeps sticky account", func(t *testing.T) {
+		out := policy.applyRamp([]Account{warming, mature}, warming.ID)
+		require.Len(t, out, 2)
+	})
+
+	t.Run("falls back when nothing else is schedulable", func(t *testing.T) {
+		resetAt := now.Add(time.Hour)
+		limited := mature
+		limited.RateLimitResetAt = &resetAt
+		out := policy.applyRamp([]Account{warming, limited}, 0)
+		require.Len(t, out, 2)
+
+		onlyWarming := policy.applyRamp([]Account{warming, {ID: 3, Status: StatusActive, Schedulable: true, CreatedAt: now}}, 0)
+		require.Len(t, onlyWarming, 2)
+	})
+}
+
+func TestUpdateAccount_ReenableRestartsWarmup(t *testing.T) {
+	repo := &updateAccountCredsRepoStub{
+		account: &Account{ID: 301, Platform: PlatformAnthropic, Type: AccountTypeOAuth, Status: StatusDisabled},
+	}
+	svc := &adminServiceImpl{accountRepo: repo}
+
+	_, err := svc.UpdateAccount(context.Background(), 301, &UpdateAccountInput{Status: StatusActive})
+	require.NoError(t, err)
+	startedAt, ok := repo.account.Extra[AccountWarmupStartedAtExtraKey].(string)
+	require.True(t, ok)
+	parsed, err := time.Parse(time.RFC3339, startedAt)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), parsed, time.Minute)
+
+	// 已启用账号再次保存不会重置预热起点
+	repo.account.Extra[AccountWarmupStartedAtExtraKey] = "2020-01-01T00:00:00Z"
+	_, err = svc.UpdateAccount(context.Background(), 301, &UpdateAccountInput{Status: StatusActive, Extra: map[string]any{}})
+	require.NoError(t, err)
+	require.Equal(t, "2020-01-01T00:00:00Z", repo.account.Extra[AccountWarmupStartedAtExtraKey])
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+var ErrActiveRequestNotFound = infraerrors.NotFound("ACTIVE_REQUEST_NOT_FOUND", "active request not found")
+
+// 在途请求所处阶段
+const (
+	ActiveRequestStateReceived           = "received"
+	ActiveRequestStateWaitingSlot        = "waiting_slot"
+	ActiveRequestStateUpstreamConnecting = "upstream_connecting"
+	ActiveRequestStateStreaming          = "streaming"
+)
+
+type activeRequestContextKey struct{}
+
+// ActiveRequestInfo 请求进入网关时已知的身份信息。
+type ActiveRequestInfo struct {
+	ClientRequestID string
+	Method          string
+	Path            string
+	APIKeyID        int64
+	APIKeyName      string
+	UserID          int64
+	UserEmail       string
+	GroupID         *int64
+	// Priority 生效的请求优先级（见 ResolveRequestPriority），空按 normal 处理
+	Priority string
+}
+
+// ActiveRequest 一个在途网关请求；账号、模型、状态与已写字节在处理过程中更新（goroutine-safe）。
+type ActiveRequest struct {
+	id            string
+	info          ActiveRequestInfo
+	startedAt     time.Time
+	upstreamGrace time.Duration
+	bytes         atomic.Int64
+	now           func() time.Time
+	slotWaits     *slotWaitSamples
+	admission     *RequestAdmissionController
+	// prioritySlotWaits 请求所属优先级的排队样本环
+	prioritySlotWaits *slotWaitSamples
+
+	mu          sync.Mutex
+	model       string
+	stream      bool
+	accountID   int64
+	platform    string
+	state       string
+	waitDepth   int
+	selections  int
+	slotWait    time.Duration
+	cancels     []context.CancelFunc
+	cancelledAt *time.Time
+	// canaryCohort 灰度分组（见 GatewayCanaryCohortStable/Canary），首次读取受灰度控制的设置时确定
+	canaryCohort string
+}
+
+// ActiveRequestSnapshot 在途请求的只读快照（管理端展示）。
+type ActiveRequestSnapshot struct {
+	ID              string     `json:"id"`
+	ClientRequestID string     `json:"client_request_id,omitempty"`
+	Method          string     `json:"method"`
+	Path            string     `json:"path"`
+	APIKeyID        int64      `json:"api_key_id"`
+	APIKeyName      string     `json:"api_key_name,omitempty"`
+	UserID          int64      `json:"user_id"`
+	UserEmail       string     `json:"user_email,omitempty"`
+	GroupID         *int64     `json:"group_id,omitempty"`
+	Priority        string     `json:"priority"`
+	AccountID       int64      `json:"account_id,omitempty"`
+	Platform        string     `json:"platform,omitempty"`
+	Model           string     `json:"model,omitempty"`
+	Stream          bool       `json:"stream"`
+	State           string     `json:"state"`
+	StartedAt       time.Time  `json:"started_at"`
+	ElapsedMs       int64      `json:"elapsed_ms"`
+	BytesStreamed   int64      `json:"bytes_streamed"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// ActiveRequestRegistry 本实例的在途网关请求登记表，支持列出与强制取消。
+type ActiveRequestRegistry struct {
+	mu            sync.RWMutex
+	items         map[string]*ActiveRequest
+	upstreamGrace time.Duration
+	now           func() time.Time
+	slotWaits     *slotWaitSamples
+	admission     *RequestAdmissionController
+
+	// 按优先级的槽位排队样本与累计请求数，供运维确认高优先级流量确实获得更低的排队延迟
+	slotWaitsByPriority map[string]*slotWaitSamples
+	beganByPriority     map[string]*atomic.Int64
+}
+
+// NewActiveRequestRegistry creates an empty ActiveRequestRegistry.
+// upstreamGrace 为请求结束（客户端断开）后脱钩的上游调用最多继续运行的时间，见 watchClientDisconnect。
+func NewActiveRequestRegistry(upstreamGrace time.Duration) *ActiveRequestRegistry {
+	r := &ActiveRequestRegistry{
+		items:               make(map[string]*ActiveRequest),
+		upstreamGrace:       upstreamGrace,
+		now:                 time.Now,
+		slotWaits:           newSlotWaitSamples(slotWaitSampleCapacity),
+		slotWaitsByPriority: make(map[string]*slotWaitSamples, len(RequestPriorities)),
+		beganByPriority:     make(map[string]*atomic.Int64, len(RequestPriorities)),
+	}
+	for _, priority := range RequestPriorities {
+		r.slotWaitsByPriority[priority] = newSlotWaitSamples(slotWaitSampleCapacity)
+		r.beganByPriority[priority] = &atomic.Int64{}
+	}
+	return r
+}
+
+// Begin 登记一个新请求，返回的 context 可被 Cancel 强制取消；处理结束后必须调用 End。
+func (r *ActiveRequestRegistry) Begin(ctx context.Context, info ActiveRequestInfo) (*ActiveRequest, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	req := &ActiveRequest{
+		id:            uuid.NewString(),
+		info:          info,
+		startedAt:     r.now(),
+		upstreamGrace: r.upstreamGrace,
+		now:           r.now,
+		slotWaits:     r.slotWaits,
+		admission:     r.admission,
+		state:         ActiveRequestStateReceived,
+		cancels:       []context.CancelFunc{cancel},
+	}
+	req.prioritySlotWaits = r.slotWaitsByPriority[req.Priority()]
+	if began := r.beganByPriority[req.Priority()]; began != nil {
+		began.Add(1)
+	}
+	r.mu.Lock()
+	r.items[req.id] = req
+	r.mu.Unlock()
+	return req, context.WithValue(ctx, activeRequestContextKey{}, req)
+}
+
+// End 注销请求并释放其 context。
+func (r *ActiveRequestRegistry) End(req *ActiveRequest) {
+	if req == nil {
+		return
+	}
+	r.mu.Lock()
+	delete(r.items, req.id)
+	r.mu.Unlock()
+	req.mu.Lock()
+	cancels := req.cancels
+	req.cancels = nil
+	req.mu.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
+
+// List 返回全部在途请求，按已耗时降序。
+func (r *ActiveRequestRegistry) List() []ActiveRequestSnapshot {
+	now := r.now()
+	r.mu.RLock()
+	out := make([]ActiveRequestSnapshot, 0, len(r.items))
+	for _, req := range r.items {
+		out = append(out, req.snapshot(now))
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartedAt.Equal(out[j].StartedAt) {
+			return out[i].StartedAt.Before(out[j].StartedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Cancel 强制取消指定请求，取消其 context 使上游调用与流式转发尽快结束。
+func (r *ActiveRequestRegistry) Cancel(id string) (ActiveRequestSnapshot, error) {
+	r.mu.RLock()
+	req, ok := r.items[id]
+	r.mu.RUnlock()
+	if !ok {
+		return ActiveRequestSnapshot{}, ErrActiveRequestNotFound
+	}
+	now := r.now()
+	req.mu.Lock()
+	if req.cancelledAt == nil {
+		req.cancelledAt = &now
+	}
+	cancels := append([]context.CancelFunc(nil), req.cancels...)
+	req.mu.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+	return req.snapshot(now), nil
+}
+
+// Count 当前在途请求数。
+func (r *ActiveRequestRegistry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.items)
+}
+
+// SlotWaitQuantile 返回 since 之后结束的槽位排队等待耗时的 q 分位数（q ∈ [0,1]）与样本数；
+// 只统计确实进入排队的请求，立即获得槽位的请求不计入。
+func (r *ActiveRequestRegistry) SlotWaitQuantile(since time.Time, q float64) (time.Duration, int) {
+	return r.slotWaits.quantile(since, q)
+}
+
+// SlotWaitQuantileByPriority 同 SlotWaitQuantile，仅统计指定优先级的请求。
+func (r *ActiveRequestRegistry) SlotWaitQuantileByPriority(priority string, since time.Time, q float64) (time.Duration, int) {
+	return r.slotWaitsByPriority[priority].quantile(since, q)
+}
+
+// RequestsByPriority 返回本实例自启动以来按优先级登记的请求数。
+func (r *ActiveRequestRegistry) RequestsByPriority() map[string]int64 {
+	out := make(map[string]int64, len(r.beganByPriority))
+	for priority, began := range r.beganByPriority {
+		out[priority] = began.Load()
+	}
+	return out
+}
+
+// SetAdmissionController 挂载槽位等待的准入控制，之后登记的请求在排队前经过准入判断。
+func (r *ActiveRequestRegistry) SetAdmissionController(c *RequestAdmissionController) {
+	r.admission = c
+}
+
+// AdmissionStats 返回准入控制的当前等待数与累计卸载数。
+func (r *ActiveRequestRegistry) AdmissionStats() RequestAdmissionStats {
+	if r == nil {
+		return RequestAdmissionStats{}
+	}
+	return r.admission.Stats()
+}
+
+// ActiveRequestFromContext 取出当前请求的登记项；未登记时返回 nil（方法对 nil 接收者安全）。
+func ActiveRequestFromContext(ctx context.Context) *ActiveRequest {
+	if ctx == nil {
+		return nil
+	}
+	req, _ := ctx.Value(activeRequestContextKey{}).(*ActiveRequest)
+	return req
+}
+
+// SetModel 记录请求模型与是否流式。
+func (a *ActiveRequest) SetModel(model string, stream bool) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.model = model
+	a.stream = stream
+	a.mu.Unlock()
+}
+
+// SetAccount 记录调度到的账号（failover 时覆盖），请求进入上游连接阶段。
+func (a *ActiveRequest) SetAccount(accountID int64, platform string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.accountID = accountID
+	a.selections++
+	if platform != "" {
+		a.platform = platform
+	}
+	if a.state != ActiveRequestStateStreaming {
+		a.state = ActiveRequestStateUpstreamConnecting
+	}
+	a.mu.Unlock()
+}
+
+// BeginWait 标记请求正在排队等待并发槽位，返回的函数在等待结束时调用。
+func (a *ActiveRequest) BeginWait() func() {
+	if a == nil {
+		return func() {}
+	}
+	a.mu.Lock()
+	a.waitDepth++
+	a.mu.Unlock()
+	startedAt := a.now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			endedAt := a.now()
+			a.mu.Lock()
+			a.waitDepth--
+			a.slotWait += endedAt.Sub(startedAt)
+			a.mu.Unlock()
+			a.slotWaits.record(endedAt, endedAt.Sub(startedAt))
+			a.prioritySlotWaits.record(endedAt, endedAt.Sub(startedAt))
+		})
+	}
+}
+
+// Routing 返回最终服务的平台、failover 重试次数（账号选择次数 - 1）与累计槽位排队耗时。
+func (a *ActiveRequest) Routing() (platform string, retries int, queueWait time.Duration) {
+	if a == nil {
+		return "", 0, 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.selections > 1 {
+		retries = a.selections - 1
+	}
+	return a.platform, retries, a.slotWait
+}
+
+// AdmitWait 在进入槽位等待前申请准入；被卸载时返回 *AdmissionShedError，允许时返回的函数在等待结束时调用。
+func (a *ActiveRequest) AdmitWait() (func(), error) {
+	if a == nil {
+		return func() {}, nil
+	}
+	return a.admission.AdmitWithPriority(a.info.GroupID, a.Priority())
+}
+
+// Priority 返回请求的生效优先级；未登记的请求按 normal 处理。
+func (a *ActiveRequest) Priority() string {
+	if a == nil || a.info.Priority == "" {
+		return RequestPriorityNormal
+	}
+	return a.info.Priority
+}
+
+// AddBytes 累计写给客户端的字节数；已选定账号后的首次写出视为进入流式阶段。
+func (a *ActiveRequest) AddBytes(n int) {
+	if a == nil || n <= 0 {
+		return
+	}
+	a.bytes.Add(int64(n))
+	a.mu.Lock()
+	if a.state == ActiveRequestStateUpstreamConnecting && a.waitDepth == 0 {
+		a.state = ActiveRequestStateStreaming
+	}
+	a.mu.Unlock()
+}
+
+// AttachCancel 关联额外的取消函数，供脱离请求 context 的处理（如断线续传）也能被强制取消。
+func (a *ActiveRequest) AttachCancel(cancel context.CancelFunc) {
+	if a == nil || cancel == nil {
+		return
+	}
+	a.mu.Lock()
+	cancelled := a.cancelledAt != nil
+	if !cancelled {
+		a.cancels = append(a.cancels, cancel)
+	}
+	a.mu.Unlock()
+	if cancelled {
+		cancel()
+	}
+}
+
+func (a *ActiveRequest) forceCancelled() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cancelledAt != nil
+}
+
+func (a *ActiveRequest) snapshot(now time.Time) ActiveRequestSnapshot {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	state := a.state
+	if a.waitDepth > 0 {
+		state = ActiveRequestStateWaitingSlot
+	}
+	return ActiveRequestSnapshot{
+		ID:              a.id,
+		ClientRequestID: a.info.ClientRequestID,
+		Method:          a.info.Method,
+		Path:            a.info.Path,
+		APIKeyID:        a.info.APIKeyID,
+		APIKeyName:      a.info.APIKeyName,
+		UserID:          a.info.UserID,
+		UserEmail:       a.info.UserEmail,
+		GroupID:         a.info.GroupID,
+		Priority:        a.Priority(),
+		AccountID:       a.accountID,
+		Platform:        a.platform,
+		Model:           a.model,
+		Stream:          a.stream,
+		State:           state,
+		StartedAt:       a.startedAt,
+		ElapsedMs:       now.Sub(a.startedAt).Milliseconds(),
+		BytesStreamed:   a.bytes.Load(),
+		CancelledAt:     a.cancelledAt,
+	}
+}
@@ -0,0 +1,111 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveRequestRegistry_StateTransitions(t *testing.T) {
+	registry := NewActiveRequestRegistry(0)
+	req, ctx := registry.Begin(context.Background(), ActiveRequestInfo{Method: "POST", Path: "/v1/messages", APIKeyID: 7, UserID: 3})
+	require.Same(t, req, ActiveRequestFromContext(ctx))
+
+	items := registry.List()
+	require.Len(t, items, 1)
+	require.Equal(t, ActiveRequestStateReceived, items[0].State)
+
+	req.SetModel("claude-sonnet-4", true)
+	done := req.BeginWait()
+	require.Equal(t, ActiveRequestStateWaitingSlot, registry.List()[0].State)
+	done()
+	done()
+
+	req.SetAccount(42, PlatformAnthropic)
+	require.Equal(t, ActiveRequestStateUpstreamConnecting, registry.List()[0].State)
+
+	req.AddBytes(128)
+	req.AddBytes(64)
+	item := registry.List()[0]
+	require.Equal(t, ActiveRequestStateStreaming, item.State)
+	require.Equal(t, int64(192), item.BytesStreamed)
+	require.Equal(t, int64(42), item.AccountID)
+	require.Equal(t, "claude-sonnet-4", item.Model)
+	require.True(t, item.Stream)
+
+	registry.End(req)
+	require.Zero(t, registry.Count())
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestActiveRequestRegistry_ListOldestFirst(t *testing.T) {
+	registry := NewActiveRequestRegistry(0)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+	first, _ := registry.Begin(context.Background(), ActiveRequestInfo{Path: "/first"})
+	now = now.Add(time.Second)
+	registry.Begin(context.Background(), ActiveRequestInfo{Path: "/second"})
+
+	items := registry.List()
+	require.Len(t, items, 2)
+	require.Equal(t, first.id, items[0].ID)
+	require.Equal(t, int64(1000), items[0].ElapsedMs)
+}
+
+func TestActiveRequestRegistry_Cancel(t *testing.T) {
+	registry := NewActiveRequestRegistry(0)
+	req, ctx := registry.Begin(context.Background(), ActiveRequestInfo{})
+
+	detached, detachedCancel := context.WithCancel(context.WithoutCancel(ctx))
+	req.AttachCancel(detachedCancel)
+
+	_, err := registry.Cancel("missing")
+	require.ErrorIs(t, err, ErrActiveRequestNotFound)
+
+	item, err := registry.Cancel(req.id)
+	require.NoError(t, err)
+	require.NotNil(t, item.CancelledAt)
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	require.ErrorIs(t, detached.Err(), context.Canceled)
+
+	late, lateCancel := context.WithCancel(context.Background())
+	req.AttachCancel(lateCancel)
+	require.ErrorIs(t, late.Err(), context.Canceled)
+}
+
+func TestActiveRequest_NilSafe(t *testing.T) {
+	req := ActiveRequestFromContext(context.Background())
+	require.Nil(t, req)
+	req.SetModel("m", false)
+	req.SetAccount(1, PlatformOpenAI)
+	req.AddBytes(10)
+	req.AttachCancel(func() {})
+	req.BeginWait()()
+}
+
+func TestActiveRequest_Routing(t *testing.T) {
+	registry := NewActiveRequestRegistry(0)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+	req, _ := registry.Begin(context.Background(), ActiveRequestInfo{})
+
+	done := req.BeginWait()
+	now = now.Add(250 * time.Millisecond)
+	done()
+	req.SetAccount(1, PlatformAnthropic)
+	req.SetAccount(2, PlatformAntigravity)
+
+	platform, retries, queueWait := req.Routing()
+	require.Equal(t, PlatformAntigravity, platform)
+	require.Equal(t, 1, retries)
+	require.Equal(t, 250*time.Millisecond, queueWait)
+
+	platform, retries, queueWait = (*ActiveRequest)(nil).Routing()
+	require.Empty(t, platform)
+	require.Zero(t, retries)
+	require.Zero(t, queueWait)
+}
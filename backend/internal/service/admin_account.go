@@ -171,7 +171,13 @@ func duplicateAccountGroups(source *Account) ([]AccountGroup, []int64) {
 		groups := make([]AccountGroup, 0, len(source.AccountGroups))
 		groupIDs := make([]int64, 0, len(source.AccountGroups))
 		for _, sourceGroup := range source.AccountGroups {
-			groups = append(groups, AccountGroup{GroupID: sourceGroup.GroupID, Priority: sourceGroup.Priority})
+			groups = append(groups, AccountGroup{
+				GroupID:          sourceGroup.GroupID,
+				Priority:         sourceGroup.Priority,
+				PriorityOverride: sourceGroup.PriorityOverride,
+				Weight:           sourceGroup.Weight,
+				ConcurrencyCap:   sourceGroup.ConcurrencyCap,
+			})
 			groupIDs = append(groupIDs, sourceGroup.GroupID)
 		}
 		return groups, groupIDs
@@ -519,6 +525,9 @@ func (s *adminServiceImpl) CreateAccount(ctx context.Context, input *CreateAccou
 	if err != nil {
 		return nil, err
 	}
+	if err := normalizeAccountDataResidencyExtra(accountExtra); err != nil {
+		return nil, err
+	}
 
 	// 绑定分组
 	groupIDs := input.GroupIDs
@@ -610,6 +619,9 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 		if err != nil {
 			return nil, err
 		}
+		if err := normalizeAccountDataResidencyExtra(normalizedExtra); err != nil {
+			return nil, err
+		}
 	}
 	previousProbeIdentity := upstreamBillingProbeIdentity(account)
 	// 安全/身份不变量(影子账号):通用更新路径被 edit/re-auth/refresh/batch 共用,
@@ -640,6 +652,7 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 		}
 	}
 	wasOveragesEnabled := account.IsOveragesEnabled()
+	wasDisabled := account.Status == StatusDisabled
 
 	if input.Name != "" {
 		account.Name = input.Name
@@ -685,6 +698,7 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 			grokBillingExtraKey,
 			UpstreamBillingProbeEnabledExtraKey,
 			UpstreamBillingProbeExtraKey,
+			AccountWarmupStartedAtExtraKey,
 		} {
 			if v, ok := account.Extra[key]; ok {
 				normalizedExtra[key] = v
@@ -785,6 +799,14 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 		}
 	}
 
+	// 由停用恢复为启用时重新开始流量预热
+	if wasDisabled && account.Status == StatusActive {
+		if account.Extra == nil {
+			account.Extra = make(map[string]any)
+		}
+		account.Extra[AccountWarmupStartedAtExtraKey] = time.Now().UTC().Format(time.RFC3339)
+	}
+
 	probeEnabledAppliedAtomically := false
 	if requestedProbeEnabledUpdate != nil && isUpstreamBillingProbeAccount(account) {
 		if updater, ok := s.accountRepo.(accountProbeEnabledAtomicUpdater); ok {
@@ -833,6 +855,9 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 // UpdateAccountExtra 仅对 Extra JSONB 做 key 级合并，避免覆盖其它运行态键
 // （如 model_rate_limits / passive_usage_* 等）。
 func (s *adminServiceImpl) UpdateAccountExtra(ctx context.Context, id int64, updates map[string]any) error {
+	if err := normalizeAccountDataResidencyExtra(updates); err != nil {
+		return err
+	}
 	if _, exists := updates[openAILongContextBillingEnabledKey]; exists {
 		account, err := s.accountRepo.GetByID(ctx, id)
 		if err != nil {
@@ -854,6 +879,9 @@ func (s *adminServiceImpl) BulkUpdateAccounts(ctx context.Context, input *BulkUp
 	// Managed probe state may only enter through the dedicated typed field below.
 	delete(input.Extra, UpstreamBillingProbeEnabledExtraKey)
 	delete(input.Extra, UpstreamBillingProbeExtraKey)
+	if err := normalizeAccountDataResidencyExtra(input.Extra); err != nil {
+		return nil, err
+	}
 
 	if len(input.AccountIDs) == 0 && input.Filters != nil {
 		accountIDs, err := s.resolveBulkUpdateTargetIDs(ctx, input.Filters)
@@ -1206,6 +1234,19 @@ func (s *adminServiceImpl) SetAccountSchedulable(ctx context.Context, id int64,
 	return updated, nil
 }
 
+func (s *adminServiceImpl) UpdateAccountGroupMembership(ctx context.Context, accountID, groupID int64, update *AccountGroupMembershipUpdate) (*AccountGroup, error) {
+	if update == nil {
+		return nil, ErrInvalidAccountGroupMembership
+	}
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	if s.membershipRepo == nil {
+		return nil, errors.New("account group membership repository is not configured")
+	}
+	return s.membershipRepo.UpdateAccountGroupMembership(ctx, accountID, groupID, *update)
+}
+
 func (s *adminServiceImpl) RevertAccountProxyFallback(ctx context.Context, id int64) error {
 	if err := s.accountRepo.RevertProxyFallback(ctx, id); err != nil {
 		return err
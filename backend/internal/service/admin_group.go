@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -224,6 +225,26 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		mcpXMLInject = *input.MCPXMLInject
 	}
 
+	systemPromptProfile, err := NormalizeSystemPromptProfile(input.SystemPromptProfile)
+	if err != nil {
+		return nil, err
+	}
+	responseLanguage, err := NormalizeResponseLanguage(input.ResponseLanguage, false)
+	if err != nil {
+		return nil, err
+	}
+	attributionMode, err := NormalizeAttributionMode(input.AttributionMode)
+	if err != nil {
+		return nil, err
+	}
+	attributionText, err := NormalizeAttributionText(input.AttributionText)
+	if err != nil {
+		return nil, err
+	}
+	if input.MaxRequestCost < 0 {
+		return nil, ErrInvalidMaxRequestCost
+	}
+
 	allowImageGeneration := input.AllowImageGeneration || defaultAllowImageGenerationForPlatform(platform)
 	allowBatchImageGeneration := input.AllowBatchImageGeneration && allowImageGeneration && platform == PlatformGemini
 
@@ -302,6 +323,14 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		MessagesDispatchModelConfig:     normalizeOpenAIMessagesDispatchModelConfig(input.MessagesDispatchModelConfig),
 		ModelsListConfig:                normalizeGroupModelsListConfig(input.ModelsListConfig),
 		RPMLimit:                        input.RPMLimit,
+		AllowComputerUseTool:            input.AllowComputerUseTool,
+		AllowCodeExecutionTool:          input.AllowCodeExecutionTool,
+		AllowWebSearchTool:              input.AllowWebSearchTool,
+		SystemPromptProfile:             systemPromptProfile,
+		ResponseLanguage:                responseLanguage,
+		AttributionMode:                 attributionMode,
+		AttributionText:                 attributionText,
+		MaxRequestCost:                  input.MaxRequestCost,
 	}
 	sanitizeGroupMessagesDispatchFields(group)
 	if err := s.groupRepo.Create(ctx, group); err != nil {
@@ -618,6 +647,49 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if input.RPMLimit != nil {
 		group.RPMLimit = *input.RPMLimit
 	}
+	if input.AllowComputerUseTool != nil {
+		group.AllowComputerUseTool = *input.AllowComputerUseTool
+	}
+	if input.AllowCodeExecutionTool != nil {
+		group.AllowCodeExecutionTool = *input.AllowCodeExecutionTool
+	}
+	if input.AllowWebSearchTool != nil {
+		group.AllowWebSearchTool = *input.AllowWebSearchTool
+	}
+	if input.SystemPromptProfile != nil {
+		profile, err := NormalizeSystemPromptProfile(*input.SystemPromptProfile)
+		if err != nil {
+			return nil, err
+		}
+		group.SystemPromptProfile = profile
+	}
+	if input.ResponseLanguage != nil {
+		language, err := NormalizeResponseLanguage(*input.ResponseLanguage, false)
+		if err != nil {
+			return nil, err
+		}
+		group.ResponseLanguage = language
+	}
+	if input.AttributionMode != nil {
+		mode, err := NormalizeAttributionMode(*input.AttributionMode)
+		if err != nil {
+			return nil, err
+		}
+		group.AttributionMode = mode
+	}
+	if input.AttributionText != nil {
+		text, err := NormalizeAttributionText(*input.AttributionText)
+		if err != nil {
+			return nil, err
+		}
+		group.AttributionText = text
+	}
+	if input.MaxRequestCost != nil {
+		if *input.MaxRequestCost < 0 {
+			return nil, ErrInvalidMaxRequestCost
+		}
+		group.MaxRequestCost = *input.MaxRequestCost
+	}
 	sanitizeGroupMessagesDispatchFields(group)
 
 	if err := s.groupRepo.Update(ctx, group); err != nil {
@@ -937,6 +1009,113 @@ func (s *adminServiceImpl) AdminResetAPIKeyRateLimitUsage(ctx context.Context, k
 	return apiKey, nil
 }
 
+// AdminSetAPIKeyAnnotationsEnabled 开关 API Key 的响应注解头；仅管理员可修改，避免向普通用户暴露上游细节。
+func (s *adminServiceImpl) AdminSetAPIKeyAnnotationsEnabled(ctx context.Context, keyID int64, enabled bool) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.AnnotationsEnabled == enabled {
+		return apiKey, nil
+	}
+	apiKey.AnnotationsEnabled = enabled
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key annotations: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminSetAPIKeyStreamCaptureEnabled 开关 API Key 的上游原始响应全量留存，供争议核查；仅管理员可修改。
+func (s *adminServiceImpl) AdminSetAPIKeyStreamCaptureEnabled(ctx context.Context, keyID int64, enabled bool) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.StreamCaptureEnabled == enabled {
+		return apiKey, nil
+	}
+	apiKey.StreamCaptureEnabled = enabled
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key stream capture: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminSetAPIKeyMaxPriority 设置 API Key 可通过 X-Priority 请求的最高优先级；空表示不信任该请求头。仅管理员可修改。
+func (s *adminServiceImpl) AdminSetAPIKeyMaxPriority(ctx context.Context, keyID int64, maxPriority string) (*APIKey, error) {
+	maxPriority, err := NormalizeRequestPriority(maxPriority)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.MaxPriority == maxPriority {
+		return apiKey, nil
+	}
+	apiKey.MaxPriority = maxPriority
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key max priority: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminSetAPIKeyBetaFeatures 设置 Key 可通过 X-Sub2API-Beta 按请求启用的实验性功能（仅管理员）。
+func (s *adminServiceImpl) AdminSetAPIKeyBetaFeatures(ctx context.Context, keyID int64, features []string) (*APIKey, error) {
+	features, err := NormalizeBetaFeatures(features)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(apiKey.BetaFeatures, features) {
+		return apiKey, nil
+	}
+	apiKey.BetaFeatures = features
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key beta features: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminSetAPIKeyDataResidency 设置 Key 要求的数据驻留区域；空表示不限制（仅管理员）。
+func (s *adminServiceImpl) AdminSetAPIKeyDataResidency(ctx context.Context, keyID int64, region string) (*APIKey, error) {
+	region, err := NormalizeDataResidency(region)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.DataResidency == region {
+		return apiKey, nil
+	}
+	apiKey.DataResidency = region
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key data residency: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
 // ReplaceUserGroup 替换用户的专属分组
 func (s *adminServiceImpl) ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error) {
 	if oldGroupID == newGroupID {
@@ -128,7 +128,15 @@ func cloneGroupForDuplicate(source *Group, operationID string) *Group {
 			Enabled: source.ModelsListConfig.Enabled,
 			Models:  append([]string(nil), source.ModelsListConfig.Models...),
 		},
-		RPMLimit: source.RPMLimit,
+		RPMLimit:               source.RPMLimit,
+		AllowComputerUseTool:   source.AllowComputerUseTool,
+		AllowCodeExecutionTool: source.AllowCodeExecutionTool,
+		AllowWebSearchTool:     source.AllowWebSearchTool,
+		SystemPromptProfile:    source.SystemPromptProfile,
+		ResponseLanguage:       source.ResponseLanguage,
+		AttributionMode:        source.AttributionMode,
+		AttributionText:        source.AttributionText,
+		MaxRequestCost:         source.MaxRequestCost,
 	}
 }
 
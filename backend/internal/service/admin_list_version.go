@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// AdminAccountChangesMaxLimit 单次增量查询最多返回的账号数
+const AdminAccountChangesMaxLimit = 500
+
+var (
+	ErrAdminChangesSinceRequired   = infraerrors.BadRequest("ADMIN_CHANGES_SINCE_REQUIRED", "since is required")
+	ErrAdminListVersionUnavailable = infraerrors.ServiceUnavailable("ADMIN_LIST_VERSION_UNAVAILABLE", "admin list version service is unavailable")
+)
+
+// AdminListKind 支持版本指纹的管理端列表。
+type AdminListKind string
+
+const (
+	AdminListAccounts  AdminListKind = "accounts"
+	AdminListUsageLogs AdminListKind = "usage_logs"
+)
+
+// AdminListVersionRepository 管理端列表的轻量数据版本查询。
+// 指纹只依赖计数与最大更新时间等聚合值，远比完整列表查询便宜，用于提前返回 304。
+type AdminListVersionRepository interface {
+	// AccountsFingerprint 账号表行数、最大 updated_at/deleted_at 与分组绑定校验和。
+	AccountsFingerprint(ctx context.Context) (string, error)
+	// UsageLogsFingerprint 用量日志 min/max id（日志只追加，清理从旧到新删除）、原地改写修订号、
+	// 清理任务与关联实体（用户、Key、账号、分组）的最大更新时间。
+	UsageLogsFingerprint(ctx context.Context) (string, error)
+	// AccountChangesSince 返回 since 之后更新过的未删除账号 ID，以及 since 之后被删除的账号 ID。
+	AccountChangesSince(ctx context.Context, since time.Time, limit int) (changedIDs []int64, deletedIDs []int64, err error)
+}
+
+// AdminAccountChanges 账号增量查询结果。
+type AdminAccountChanges struct {
+	Accounts   []*Account
+	DeletedIDs []int64
+	// HasMore 为 true 表示变更超过 limit，客户端应回退到全量刷新
+	HasMore bool
+	// ServerTime 作为下次增量查询的 since
+	ServerTime time.Time
+}
+
+// AdminListVersionService 为管理端重量级列表生成 ETag，并提供增量查询。
+type AdminListVersionService struct {
+	repo        AdminListVersionRepository
+	accountRepo AccountRepository
+}
+
+func NewAdminListVersionService(repo AdminListVersionRepository, accountRepo AccountRepository) *AdminListVersionService {
+	return &AdminListVersionService{repo: repo, accountRepo: accountRepo}
+}
+
+// ListETag 基于数据指纹与请求参数生成强 ETag；query 需包含影响结果的全部查询参数。
+// 返回空字符串表示无法生成（调用方按无 ETag 处理）。
+func (s *AdminListVersionService) ListETag(ctx context.Context, kind AdminListKind, query url.Values) (string, error) {
+	if s == nil || s.repo == nil {
+		return "", nil
+	}
+	var (
+		fingerprint string
+		err         error
+	)
+	switch kind {
+	case AdminListAccounts:
+		fingerprint, err = s.repo.AccountsFingerprint(ctx)
+	case AdminListUsageLogs:
+		fingerprint, err = s.repo.UsageLogsFingerprint(ctx)
+	default:
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return buildAdminListETag(kind, fingerprint, query), nil
+}
+
+func buildAdminListETag(kind AdminListKind, fingerprint string, query url.Values) string {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	h.Write([]byte{0})
+	h.Write([]byte(fingerprint))
+	h.Write([]byte{0})
+	// url.Values.Encode 按 key 排序，参数顺序不同的同一查询得到相同 ETag
+	h.Write([]byte(query.Encode()))
+	return "\"" + hex.EncodeToString(h.Sum(nil)[:16]) + "\""
+}
+
+// AccountChanges 返回 since 之后变更的账号与被删除的账号 ID。
+func (s *AdminListVersionService) AccountChanges(ctx context.Context, since time.Time, limit int) (*AdminAccountChanges, error) {
+	if s == nil || s.repo == nil || s.accountRepo == nil {
+		return nil, ErrAdminListVersionUnavailable
+	}
+	if since.IsZero() {
+		return nil, ErrAdminChangesSinceRequired
+	}
+	if limit <= 0 || limit > AdminAccountChangesMaxLimit {
+		limit = AdminAccountChangesMaxLimit
+	}
+	// 先取服务器时间再查询，保证查询期间发生的变更会在下一轮被再次返回而不是丢失
+	serverTime := time.Now()
+	changedIDs, deletedIDs, err := s.repo.AccountChangesSince(ctx, since, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	result := &AdminAccountChanges{
+		DeletedIDs: deletedIDs,
+		HasMore:    len(changedIDs)+len(deletedIDs) > limit,
+		ServerTime: serverTime,
+	}
+	if result.DeletedIDs == nil {
+		result.DeletedIDs = []int64{}
+	}
+	accounts, err := s.accountRepo.GetByIDs(ctx, changedIDs)
+	if err != nil {
+		return nil, err
+	}
+	result.Accounts = accounts
+	return result, nil
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type adminListVersionRepoStub struct {
+	accountsFP string
+	usageFP    string
+	changed    []int64
+	deleted    []int64
+	gotLimit   int
+}
+
+func (s *adminListVersionRepoStub) AccountsFingerprint(context.Context) (string, error) {
+	return s.accountsFP, nil
+}
+
+func (s *adminListVersionRepoStub) UsageLogsFingerprint(context.Context) (string, error) {
+	return s.usageFP, nil
+}
+
+func (s *adminListVersionRepoStub) AccountChangesSince(_ context.Context, _ time.Time, limit int) ([]int64, []int64, error) {
+	s.gotLimit = limit
+	return s.changed, s.deleted, nil
+}
+
+type adminListVersionAccountRepoStub struct {
+	AccountRepository
+	gotIDs []int64
+}
+
+func (s *adminListVersionAccountRepoStub) GetByIDs(_ context.Context, ids []int64) ([]*Account, error) {
+	s.gotIDs = ids
+	out := make([]*Account, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, &Account{ID: id})
+	}
+	return out, nil
+}
+
+func TestAdminListVersionService_ListETag(t *testing.T) {
+	repo := &adminListVersionRepoStub{accountsFP: "10:1", usageFP: "1:100:5"}
+	svc := NewAdminListVersionService(repo, nil)
+	ctx := context.Background()
+
+	q1 := url.Values{"page": {"1"}, "model": {"claude"}}
+	q2, err := url.ParseQuery("model=claude&page=1")
+	require.NoError(t, err)
+
+	etag1, err := svc.ListETag(ctx, AdminListUsageLogs, q1)
+	require.NoError(t, err)
+	etag2, err := svc.ListETag(ctx, AdminListUsageLogs, q2)
+	require.NoError(t, err)
+	require.Equal(t, etag1, etag2, "query parameter order must not affect etag")
+	require.Regexp(t, `^"[0-9a-f]{32}"$`, etag1)
+
+	other, err := svc.ListETag(ctx, AdminListUsageLogs, url.Values{"page": {"2"}, "model": {"claude"}})
+	require.NoError(t, err)
+	require.NotEqual(t, etag1, other)
+
+	repo.usageFP = "1:101:5"
+	changed, err := svc.ListETag(ctx, AdminListUsageLogs, q1)
+	require.NoError(t, err)
+	require.NotEqual(t, etag1, changed)
+
+	accounts, err := svc.ListETag(ctx, AdminListAccounts, q1)
+	require.NoError(t, err)
+	require.NotEqual(t, changed, accounts)
+
+	var nilSvc *AdminListVersionService
+	empty, err := nilSvc.ListETag(ctx, AdminListAccounts, q1)
+	require.NoError(t, err)
+	require.Empty(t, empty)
+}
+
+func TestAdminListVersionService_AccountChanges(t *testing.T) {
+	repo := &adminListVersionRepoStub{changed: []int64{1, 2}, deleted: []int64{3}}
+	accountRepo := &adminListVersionAccountRepoStub{}
+	svc := NewAdminListVersionService(repo, accountRepo)
+	ctx := context.Background()
+
+	before := time.Now()
+	changes, err := svc.AccountChanges(ctx, before.Add(-time.Hour), 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, repo.gotLimit)
+	require.Equal(t, []int64{1, 2}, accountRepo.gotIDs)
+	require.Len(t, changes.Accounts, 2)
+	require.Equal(t, []int64{3}, changes.DeletedIDs)
+	require.True(t, changes.HasMore)
+	require.False(t, changes.ServerTime.Before(before))
+
+	changes, err = svc.AccountChanges(ctx, before.Add(-time.Hour), 0)
+	require.NoError(t, err)
+	require.Equal(t, AdminAccountChangesMaxLimit+1, repo.gotLimit)
+	require.False(t, changes.HasMore)
+
+	_, err = svc.AccountChanges(ctx, time.Time{}, 10)
+	require.ErrorIs(t, err, ErrAdminChangesSinceRequired)
+}
@@ -59,6 +59,11 @@ type AdminService interface {
 	// API Key management (admin)
 	AdminUpdateAPIKeyGroupID(ctx context.Context, keyID int64, groupID *int64) (*AdminUpdateAPIKeyGroupIDResult, error)
 	AdminResetAPIKeyRateLimitUsage(ctx context.Context, keyID int64) (*APIKey, error)
+	AdminSetAPIKeyAnnotationsEnabled(ctx context.Context, keyID int64, enabled bool) (*APIKey, error)
+	AdminSetAPIKeyStreamCaptureEnabled(ctx context.Context, keyID int64, enabled bool) (*APIKey, error)
+	AdminSetAPIKeyMaxPriority(ctx context.Context, keyID int64, maxPriority string) (*APIKey, error)
+	AdminSetAPIKeyBetaFeatures(ctx context.Context, keyID int64, features []string) (*APIKey, error)
+	AdminSetAPIKeyDataResidency(ctx context.Context, keyID int64, region string) (*APIKey, error)
 
 	// ReplaceUserGroup 替换用户的专属分组：授予新分组权限、迁移 Key、移除旧分组权限
 	ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error)
@@ -97,6 +102,8 @@ type AdminService interface {
 	// ForceAntigravityPrivacy 强制重新设置 Antigravity OAuth 账号隐私，无论当前状态。
 	ForceAntigravityPrivacy(ctx context.Context, account *Account) string
 	SetAccountSchedulable(ctx context.Context, id int64, schedulable bool) (*Account, error)
+	// UpdateAccountGroupMembership 更新账号在分组中的优先级/权重/并发覆盖；账号不在该分组时返回 ErrAccountGroupMembershipNotFound。
+	UpdateAccountGroupMembership(ctx context.Context, accountID, groupID int64, update *AccountGroupMembershipUpdate) (*AccountGroup, error)
 	BulkUpdateAccounts(ctx context.Context, input *BulkUpdateAccountsInput) (*BulkUpdateAccountsResult, error)
 	CheckMixedChannelRisk(ctx context.Context, currentAccountID int64, currentAccountPlatform string, groupIDs []int64) error
 	// RevertAccountProxyFallback 将账号的 proxy_id 切回 proxy_fallback_origin_id，并清空 origin 字段。
@@ -254,6 +261,19 @@ type CreateGroupInput struct {
 	ModelsListConfig            GroupModelsListConfig
 	// RPMLimit 分组 RPM 上限（0 = 不限制）
 	RPMLimit int
+	// Anthropic beta 工具透传开关（默认关闭）
+	AllowComputerUseTool   bool
+	AllowCodeExecutionTool bool
+	AllowWebSearchTool     bool
+	// system prompt 注入档位（空 = 沿用全局设置）
+	SystemPromptProfile string
+	// 强制回复语言（空 = 不强制）
+	ResponseLanguage string
+	// 归属水印模式与页脚模板（空 = 不添加 / 默认模板）
+	AttributionMode string
+	AttributionText string
+	// 单请求预估费用上限（美元，0 = 不限制）
+	MaxRequestCost float64
 	// 从指定分组复制账号（创建分组后在同一事务内绑定）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -310,6 +330,19 @@ type UpdateGroupInput struct {
 	ModelsListConfig            *GroupModelsListConfig
 	// RPMLimit 分组 RPM 上限（0 = 不限制），nil 表示未提供不改动。
 	RPMLimit *int
+	// Anthropic beta 工具透传开关，nil 表示未提供不改动。
+	AllowComputerUseTool   *bool
+	AllowCodeExecutionTool *bool
+	AllowWebSearchTool     *bool
+	// system prompt 注入档位，nil 表示未提供不改动，空字符串表示恢复沿用全局设置。
+	SystemPromptProfile *string
+	// 强制回复语言，nil 表示未提供不改动，空字符串表示取消强制。
+	ResponseLanguage *string
+	// 归属水印模式与页脚模板，nil 表示未提供不改动
+	AttributionMode *string
+	AttributionText *string
+	// 单请求预估费用上限，nil 表示未提供不改动，0 表示取消
+	MaxRequestCost *float64
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -450,6 +483,7 @@ type CreateProxyInput struct {
 	FallbackMode   string
 	BackupProxyID  *int64
 	ExpiryWarnDays int
+	DataResidency  string
 }
 
 type UpdateProxyInput struct {
@@ -464,6 +498,7 @@ type UpdateProxyInput struct {
 	FallbackMode   string
 	BackupProxyID  *int64
 	ExpiryWarnDays int
+	DataResidency  *string // nil 表示不修改
 }
 
 type GenerateRedeemCodesInput struct {
@@ -602,6 +637,7 @@ type adminServiceImpl struct {
 	groupDuplicateRepo   GroupDuplicateRepository
 	accountRepo          AccountRepository
 	accountDuplicateRepo AccountDuplicateRepository
+	membershipRepo       AccountGroupMembershipRepository
 	proxyRepo            ProxyRepository
 	apiKeyRepo           APIKeyRepository
 	redeemCodeRepo       RedeemCodeRepository
@@ -656,6 +692,7 @@ func NewAdminService(
 		groupDuplicateRepo:   groupRepo,
 		accountRepo:          accountRepo,
 		accountDuplicateRepo: accountRepo,
+		membershipRepo:       accountRepo,
 		proxyRepo:            proxyRepo,
 		apiKeyRepo:           apiKeyRepo,
 		redeemCodeRepo:       redeemCodeRepo,
@@ -182,6 +182,9 @@ func (s *apiKeyRepoStubForGroupUpdate) ListKeysByUserID(context.Context, int64)
 func (s *apiKeyRepoStubForGroupUpdate) ListKeysByGroupID(context.Context, int64) ([]string, error) {
 	panic("unexpected")
 }
+func (s *apiKeyRepoStubForGroupUpdate) ListByParentKeyID(context.Context, int64) ([]APIKey, error) {
+	return nil, nil
+}
 func (s *apiKeyRepoStubForGroupUpdate) IncrementQuotaUsed(context.Context, int64, float64) (float64, error) {
 	panic("unexpected")
 }
@@ -266,6 +266,10 @@ func (s *deleteGroupAPIKeyRepoStub) ListKeysByGroupID(ctx context.Context, group
 	return s.keys, nil
 }
 
+func (s *deleteGroupAPIKeyRepoStub) ListByParentKeyID(ctx context.Context, parentKeyID int64) ([]APIKey, error) {
+	return nil, nil
+}
+
 type proxyRepoStub struct {
 	deleteErr    error
 	countErr     error
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+// agentSessionAffinitySettings Agent 会话账号亲和的运行时配置（由 gateway.agent_session_affinity 构造）；nil 表示关闭。
+type agentSessionAffinitySettings struct {
+	ttl                    time.Duration
+	releaseOnWindowWarning bool
+}
+
+// newAgentSessionAffinitySettings 按配置创建 Agent 会话亲和设置；未启用时返回 nil。
+func newAgentSessionAffinitySettings(cfg *config.Config) *agentSessionAffinitySettings {
+	if cfg == nil || !cfg.Gateway.AgentSessionAffinity.Enabled || cfg.Gateway.AgentSessionAffinity.TTLSeconds <= 0 {
+		return nil
+	}
+	return &agentSessionAffinitySettings{
+		ttl:                    time.Duration(cfg.Gateway.AgentSessionAffinity.TTLSeconds) * time.Second,
+		releaseOnWindowWarning: cfg.Gateway.AgentSessionAffinity.ReleaseOnWindowWarning,
+	}
+}
+
+// IsAgentSessionRequest 启发式判断请求是否属于工具调用型 Agent 会话：
+// 携带非空 tools，且 metadata.user_id 中带有会话 ID（同一会话的多轮请求据此稳定命中同一粘性 key）。
+// 未启用 Agent 会话亲和时始终返回 false。
+func (s *GatewayService) IsAgentSessionRequest(parsed *ParsedRequest) bool {
+	if s == nil || s.agentAffinity == nil || parsed == nil || !parsed.HasTools || parsed.MetadataUserID == "" {
+		return false
+	}
+	uid := ParseMetadataUserID(parsed.MetadataUserID)
+	return uid != nil && uid.SessionID != ""
+}
+
+// stickySessionTTLForContext 返回本次请求粘性绑定应使用的 TTL：Agent 会话使用亲和 TTL，其余为默认 TTL。
+func (s *GatewayService) stickySessionTTLForContext(ctx context.Context) time.Duration {
+	if s.agentAffinity != nil && AgentSessionFromContext(ctx) {
+		return s.agentAffinity.ttl
+	}
+	return stickySessionTTL
+}
+
+// shouldClearStickySession 在通用检查（见包级 shouldClearStickySession）之外，
+// 避开对当前用户处于内容策略冷却中的账号；并对 Agent 会话绑定的账号在 5h 窗口处于
+// allowed_warning / rejected 时提前释放，让长会话在撞上硬限流前迁移到其他账号。
+func (s *GatewayService) shouldClearStickySession(ctx context.Context, account *Account, requestedModel string) bool {
+	if shouldClearStickySession(account, requestedModel) {
+		return true
+	}
+	if account != nil && s.contentPolicy.blocks(ctx, account.ID) {
+		return true
+	}
+	if s.agentAffinity == nil || !s.agentAffinity.releaseOnWindowWarning || account == nil || !AgentSessionFromContext(ctx) {
+		return false
+	}
+	switch account.SessionWindowStatus {
+	case "allowed_warning", "rejected":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+const agentSessionTestMetadataUserID = `{"device_id":"d1","account_uuid":"","session_id":"12345678-1234-1234-1234-123456789abc"}`
+
+func newAgentSessionAffinityServiceForTest(releaseOnWindowWarning bool) *GatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.AgentSessionAffinity = config.GatewayAgentSessionAffinityConfig{
+		Enabled:                true,
+		TTLSeconds:             4 * 3600,
+		ReleaseOnWindowWarning: releaseOnWindowWarning,
+	}
+	return &GatewayService{cfg: cfg, agentAffinity: newAgentSessionAffinitySettings(cfg)}
+}
+
+func TestIsAgentSessionRequest(t *testing.T) {
+	parsed := &ParsedRequest{HasTools: true, MetadataUserID: agentSessionTestMetadataUserID}
+	require.False(t, (&GatewayService{cfg: &config.Config{}}).IsAgentSessionRequest(parsed), "disabled by default")
+
+	svc := newAgentSessionAffinityServiceForTest(true)
+	require.True(t, svc.IsAgentSessionRequest(parsed))
+	require.False(t, svc.IsAgentSessionRequest(&ParsedRequest{MetadataUserID: agentSessionTestMetadataUserID}), "no tools")
+	require.False(t, svc.IsAgentSessionRequest(&ParsedRequest{HasTools: true}), "no metadata session")
+	require.False(t, svc.IsAgentSessionRequest(&ParsedRequest{HasTools: true, MetadataUserID: "not-a-session"}))
+	require.False(t, svc.IsAgentSessionRequest(nil))
+}
+
+func TestParseGatewayRequest_DetectsTools(t *testing.T) {
+	parsed, err := ParseGatewayRequest(NewRequestBodyRef([]byte(`{"model":"claude-sonnet-4-5","tools":[{"name":"bash"}],"messages":[]}`)), PlatformAnthropic)
+	require.NoError(t, err)
+	require.True(t, parsed.HasTools)
+
+	parsed, err = ParseGatewayRequest(NewRequestBodyRef([]byte(`{"model":"claude-sonnet-4-5","tools":[],"messages":[]}`)), PlatformAnthropic)
+	require.NoError(t, err)
+	require.False(t, parsed.HasTools)
+}
+
+func TestStickySessionTTLForContext(t *testing.T) {
+	agentCtx := WithAgentSession(context.Background(), true)
+	require.Equal(t, stickySessionTTL, (&GatewayService{}).stickySessionTTLForContext(agentCtx), "disabled falls back to default TTL")
+
+	svc := newAgentSessionAffinityServiceForTest(true)
+	require.Equal(t, 4*time.Hour, svc.stickySessionTTLForContext(agentCtx))
+	require.Equal(t, stickySessionTTL, svc.stickySessionTTLForContext(context.Background()))
+}
+
+func TestShouldClearStickySession_AgentSessionReleasesOnWindowWarning(t *testing.T) {
+	account := &Account{ID: 1, Status: StatusActive, Schedulable: true, SessionWindowStatus: "allowed_warning"}
+	agentCtx := WithAgentSession(context.Background(), true)
+
+	svc := newAgentSessionAffinityServiceForTest(true)
+	require.True(t, svc.shouldClearStickySession(agentCtx, account, ""))
+	require.False(t, svc.shouldClearStickySession(context.Background(), account, ""), "regular sessions keep the binding")
+	require.False(t, shouldClearStickySession(account, ""), "account-level checks alone keep the binding")
+
+	account.SessionWindowStatus = "allowed"
+	require.False(t, svc.shouldClearStickySession(agentCtx, account, ""))
+
+	// 通用冷却（限流）对 Agent 会话同样释放
+	resetAt := time.Now().Add(time.Minute)
+	account.RateLimitResetAt = &resetAt
+	require.True(t, svc.shouldClearStickySession(agentCtx, account, ""))
+
+	svc = newAgentSessionAffinityServiceForTest(false)
+	account.RateLimitResetAt = nil
+	account.SessionWindowStatus = "rejected"
+	require.False(t, svc.shouldClearStickySession(agentCtx, account, ""), "window release disabled")
+}
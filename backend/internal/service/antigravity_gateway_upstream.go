@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -157,15 +156,17 @@ func (s *AntigravityGatewayService) ForwardUpstream(ctx context.Context, c *gin.
 
 // streamUpstreamResponse 透传上游 SSE 流并提取 Claude usage
 func (s *AntigravityGatewayService) streamUpstreamResponse(c *gin.Context, resp *http.Response, startTime time.Time) *antigravityStreamResult {
+	restoreWriter := guardStreamClient(c, s.settingService.cfg, resp)
+	defer restoreWriter()
 	usage := &ClaudeUsage{}
 	var firstTokenMs *int
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.settingService.cfg != nil && s.settingService.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.settingService.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	scanner, releaseScanBuf := newPooledSSEScanner(resp.Body, maxLineSize)
+	applySSEOversizeLinePolicy(scanner, s.settingService.cfg, maxLineSize)
 
 	type scanEvent struct {
 		line string
@@ -184,6 +185,7 @@ func (s *AntigravityGatewayService) streamUpstreamResponse(c *gin.Context, resp
 	var lastReadAt int64
 	atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
 	go func() {
+		defer releaseScanBuf()
 		defer close(events)
 		for scanner.Scan() {
 			atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
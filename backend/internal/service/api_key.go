@@ -62,12 +62,51 @@ type APIKey struct {
 	Window5hStart *time.Time // Start of current 5h window
 	Window1dStart *time.Time // Start of current 1d window
 	Window7dStart *time.Time // Start of current 7d window
+
+	// TranscriptEnabled 是否留存该 Key 的完整会话（还需全局开关开启）
+	TranscriptEnabled bool
+	// AnnotationsEnabled 是否在响应中输出网关注解头（仅管理员可修改）
+	AnnotationsEnabled bool
+	// StreamCaptureEnabled 是否留存该 Key 全部上游原始响应（仅管理员可修改，还需全局开关开启）
+	StreamCaptureEnabled bool
+	// ResponseLanguage 强制回复语言代码；空表示沿用分组设置，"off" 表示关闭分组的强制设置
+	ResponseLanguage string
+
+	// ParentKeyID 委托子 Key 的父 Key ID（nil 表示普通 Key）；子 Key 用量同时计入父 Key 额度
+	ParentKeyID *int64
+	// AllowedModels 子 Key 可请求的模型（支持末尾 * 通配），空表示不限制
+	AllowedModels []string
+	// MaxPriority X-Priority 请求头允许的最高优先级（low / normal / high）；空表示不信任请求头（仅管理员可修改）
+	MaxPriority string
+	// StrictValidation 转发前按端点 schema 校验请求体，格式错误时直接返回字段级错误
+	StrictValidation bool
+	// MaxRequestCost 单次请求预估费用上限（美元，按倍率折算后），0 表示不限制
+	MaxRequestCost float64
+	// BetaFeatures 可通过 X-Sub2API-Beta 请求头按请求启用的实验性功能（仅管理员可修改）
+	BetaFeatures []string
+	// DataResidency 要求的数据驻留区域（eu / us / asia）：只调度打了该区域标签且代理出口同区域的账号；空表示不限制（仅管理员可修改）
+	DataResidency string
+	// Parent 认证时加载的父 Key 状态（仅 ID/Status/Quota/QuotaUsed/ExpiresAt）
+	Parent *APIKey
 }
 
 func (k *APIKey) IsActive() bool {
 	return k.Status == StatusActive
 }
 
+// IsSubKey 是否为委托子 Key
+func (k *APIKey) IsSubKey() bool {
+	return k.ParentKeyID != nil
+}
+
+// IsModelAllowed 检查模型是否在子 Key 的允许列表内；未设置允许列表时不限制
+func (k *APIKey) IsModelAllowed(model string) bool {
+	if len(k.AllowedModels) == 0 {
+		return true
+	}
+	return matchModelWhitelist(model, k.AllowedModels)
+}
+
 // HasRateLimits returns true if any rate limit window is configured
 func (k *APIKey) HasRateLimits() bool {
 	return k.RateLimit5h > 0 || k.RateLimit1d > 0 || k.RateLimit7d > 0
@@ -26,6 +26,41 @@ type APIKeyAuthSnapshot struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// TranscriptEnabled 会话全文留存开关
+	TranscriptEnabled bool `json:"transcript_enabled"`
+	// AnnotationsEnabled 响应注解头开关
+	AnnotationsEnabled bool `json:"annotations_enabled,omitempty"`
+	// StreamCaptureEnabled 上游原始响应留存开关
+	StreamCaptureEnabled bool `json:"stream_capture_enabled,omitempty"`
+	// ResponseLanguage 强制回复语言（空 = 沿用分组）
+	ResponseLanguage string `json:"response_language,omitempty"`
+
+	// ParentKeyID 委托子 Key 的父 Key ID
+	ParentKeyID *int64 `json:"parent_key_id,omitempty"`
+	// AllowedModels 子 Key 模型允许列表
+	AllowedModels []string `json:"allowed_models,omitempty"`
+	// MaxPriority X-Priority 允许的最高优先级（空 = 忽略请求头）
+	MaxPriority string `json:"max_priority,omitempty"`
+	// StrictValidation 严格请求校验开关
+	StrictValidation bool `json:"strict_validation,omitempty"`
+	// MaxRequestCost 单请求费用上限（0 = 不限制）
+	MaxRequestCost float64 `json:"max_request_cost,omitempty"`
+	// BetaFeatures 可按请求启用的实验性功能
+	BetaFeatures []string `json:"beta_features,omitempty"`
+	// DataResidency 要求的数据驻留区域（空 = 不限制）
+	DataResidency string `json:"data_residency,omitempty"`
+	// Parent 父 Key 状态（仅子 Key；父 Key 已删除时为 nil）
+	Parent *APIKeyAuthParentSnapshot `json:"parent,omitempty"`
+}
+
+// APIKeyAuthParentSnapshot 子 Key 认证所需的父 Key 状态
+type APIKeyAuthParentSnapshot struct {
+	ID        int64      `json:"id"`
+	Status    string     `json:"status"`
+	Quota     float64    `json:"quota"`
+	QuotaUsed float64    `json:"quota_used"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // APIKeyAuthUserSnapshot 用户快照
@@ -101,6 +136,24 @@ type APIKeyAuthGroupSnapshot struct {
 	// RPMLimit 分组级每分钟请求数上限（0 = 不限制）；用于 billing_cache_service.checkRPM 级联判断。
 	RPMLimit int `json:"rpm_limit"`
 
+	// Anthropic beta 工具透传开关；Forward 据此拦截未开启的服务端工具。
+	AllowComputerUseTool   bool `json:"allow_computer_use_tool"`
+	AllowCodeExecutionTool bool `json:"allow_code_execution_tool"`
+	AllowWebSearchTool     bool `json:"allow_web_search_tool"`
+
+	// SystemPromptProfile Claude OAuth system prompt 注入档位；Forward 据此决定 system 改写方式。
+	SystemPromptProfile string `json:"system_prompt_profile,omitempty"`
+
+	// ResponseLanguage 强制回复语言；网关入口据此在请求未指定语言时追加语言指令。
+	ResponseLanguage string `json:"response_language,omitempty"`
+
+	// AttributionMode / AttributionText 文本响应归属水印；网关入口据此在成功响应末尾追加页脚或零宽水印。
+	AttributionMode string `json:"attribution_mode,omitempty"`
+	AttributionText string `json:"attribution_text,omitempty"`
+
+	// MaxRequestCost 单请求费用上限；网关入口据此在转发前拒绝预估费用超限的请求。
+	MaxRequestCost float64 `json:"max_request_cost,omitempty"`
+
 	// 高峰时段倍率：PeakRateEnabled 为 true 且请求时刻处于 [PeakStart, PeakEnd) 时，
 	// token 计费倍率额外乘以 PeakRateMultiplier（详见 Group.PeakMultiplierAt）。
 	// 必须随快照缓存，否则扣费路径拿到的 apiKey.Group 缺字段、高峰倍率失效。
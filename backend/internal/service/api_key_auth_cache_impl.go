@@ -14,13 +14,14 @@ import (
 	"github.com/dgraph-io/ristretto"
 )
 
-const apiKeyAuthSnapshotVersion = 15 // v15: include group web search per-call pricing
+const apiKeyAuthSnapshotVersion = 28 // v28: include data residency requirement
 
 type apiKeyAuthCacheConfig struct {
 	l1Size        int
 	l1TTL         time.Duration
 	l2TTL         time.Duration
 	negativeTTL   time.Duration
+	staleTTL      time.Duration
 	jitterPercent int
 	singleflight  bool
 }
@@ -30,11 +31,16 @@ func newAPIKeyAuthCacheConfig(cfg *config.Config) apiKeyAuthCacheConfig {
 		return apiKeyAuthCacheConfig{}
 	}
 	auth := cfg.APIKeyAuth
+	var staleTTL time.Duration
+	if cfg.Database.Degraded.Enabled {
+		staleTTL = time.Duration(cfg.Database.Degraded.StaleTTLSeconds) * time.Second
+	}
 	return apiKeyAuthCacheConfig{
 		l1Size:        auth.L1Size,
 		l1TTL:         time.Duration(auth.L1TTLSeconds) * time.Second,
 		l2TTL:         time.Duration(auth.L2TTLSeconds) * time.Second,
 		negativeTTL:   time.Duration(auth.NegativeTTLSeconds) * time.Second,
+		staleTTL:      staleTTL,
 		jitterPercent: auth.JitterPercent,
 		singleflight:  auth.Singleflight,
 	}
@@ -100,12 +106,23 @@ func (s *APIKeyService) initAuthCache(cfg *config.Config) {
 			s.authCacheL1 = cache
 		}
 	}
+	if s.authCfg.staleTTL > 0 {
+		staleSize := max(s.authCfg.l1Size, defaultStaleAuthCacheSize)
+		cache, err := ristretto.NewCache(&ristretto.Config{
+			NumCounters: int64(staleSize) * 10,
+			MaxCost:     int64(staleSize),
+			BufferItems: 64,
+		})
+		if err == nil {
+			s.authStaleCache = cache
+		}
+	}
 }
 
 // StartAuthCacheInvalidationSubscriber starts the Pub/Sub subscriber for L1 cache invalidation.
 // This should be called after the service is fully initialized.
 func (s *APIKeyService) StartAuthCacheInvalidationSubscriber(ctx context.Context) {
-	if s.cache == nil || (s.authCacheL1 == nil && s.authNegativeCacheL1 == nil) {
+	if s.cache == nil || (s.authCacheL1 == nil && s.authNegativeCacheL1 == nil && s.authStaleCache == nil) {
 		return
 	}
 	s.authInvalidationStart.Do(func() {
@@ -162,6 +179,9 @@ func (s *APIKeyService) invalidateLocalAuthCache(cacheKey string) {
 	if s.authNegativeCacheL1 != nil {
 		s.authNegativeCacheL1.Del(cacheKey)
 	}
+	if s.authStaleCache != nil {
+		s.authStaleCache.Del(cacheKey)
+	}
 }
 
 type AuthCacheInvalidationSubscriberHealth struct {
@@ -232,6 +252,9 @@ func (s *APIKeyService) setAuthCacheL1(cacheKey string, entry *APIKeyAuthCacheEn
 		}
 		return
 	}
+	if s.authStaleCache != nil {
+		_ = s.authStaleCache.SetWithTTL(cacheKey, entry, 1, s.authCfg.staleTTL)
+	}
 	if s.authCacheL1 == nil {
 		return
 	}
@@ -258,6 +281,9 @@ func (s *APIKeyService) deleteAuthCache(ctx context.Context, cacheKey string) {
 	if s.authNegativeCacheL1 != nil {
 		s.authNegativeCacheL1.Del(cacheKey)
 	}
+	if s.authStaleCache != nil {
+		s.authStaleCache.Del(cacheKey)
+	}
 	if s.cache == nil {
 		return
 	}
@@ -267,8 +293,20 @@ func (s *APIKeyService) deleteAuthCache(ctx context.Context, cacheKey string) {
 }
 
 func (s *APIKeyService) loadAuthCacheEntry(ctx context.Context, key, cacheKey string) (*APIKeyAuthCacheEntry, error) {
+	// 数据库降级期间不再回源（必然超时），直接使用最后一次成功的认证快照。
+	if IsDatabaseDegraded() {
+		if entry, ok := s.getStaleAuthCacheEntry(cacheKey); ok {
+			return entry, nil
+		}
+	}
 	apiKey, err := s.lookupAPIKeyForAuth(ctx, key)
 	if err != nil {
+		// 回源失败（数据库故障而非 Key 不存在）时兜底使用过期快照，探测尚未判定降级前同样生效。
+		if !errors.Is(err, ErrAPIKeyNotFound) && ctx.Err() == nil {
+			if entry, ok := s.getStaleAuthCacheEntry(cacheKey); ok {
+				return entry, nil
+			}
+		}
 		if errors.Is(err, ErrAPIKeyNotFound) {
 			entry := &APIKeyAuthCacheEntry{NotFound: true}
 			if s.authCfg.negativeEnabled() {
@@ -291,6 +329,51 @@ func (s *APIKeyService) loadAuthCacheEntry(ctx context.Context, key, cacheKey st
 	return entry, nil
 }
 
+// WarmAuthCache 预热给定 API Key 的认证缓存：L1/L2 已有当前版本快照时跳过，否则回源数据库。
+// 返回成功预热的数量；ctx 结束时停止并返回 ctx 错误。
+func (s *APIKeyService) WarmAuthCache(ctx context.Context, keys []string) (int, error) {
+	warmed := 0
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return warmed, err
+		}
+		cacheKey := s.authCacheKey(key)
+		if entry, ok := s.getAuthCacheEntry(ctx, cacheKey); ok {
+			if _, used, _ := s.applyAuthCacheEntry(key, entry); used {
+				warmed++
+				continue
+			}
+		}
+		entry, err := s.loadAuthCacheEntry(ctx, key, cacheKey)
+		if err != nil {
+			if ctx.Err() != nil {
+				return warmed, ctx.Err()
+			}
+			continue
+		}
+		if entry != nil && !entry.NotFound {
+			warmed++
+		}
+	}
+	return warmed, nil
+}
+
+func (s *APIKeyService) getStaleAuthCacheEntry(cacheKey string) (*APIKeyAuthCacheEntry, bool) {
+	if s.authStaleCache == nil {
+		return nil, false
+	}
+	val, ok := s.authStaleCache.Get(cacheKey)
+	if !ok {
+		return nil, false
+	}
+	entry, ok := val.(*APIKeyAuthCacheEntry)
+	if !ok || entry.Snapshot == nil {
+		return nil, false
+	}
+	s.authStaleServed.Add(1)
+	return entry, true
+}
+
 func (s *APIKeyService) lookupAPIKeyForAuth(ctx context.Context, key string) (*APIKey, error) {
 	if s == nil || s.apiKeyRepo == nil {
 		return nil, ErrAPIKeyNotFound
@@ -368,6 +451,27 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 		},
 	}
 
+	snapshot.TranscriptEnabled = apiKey.TranscriptEnabled
+	snapshot.AnnotationsEnabled = apiKey.AnnotationsEnabled
+	snapshot.StreamCaptureEnabled = apiKey.StreamCaptureEnabled
+	snapshot.ResponseLanguage = apiKey.ResponseLanguage
+	snapshot.ParentKeyID = apiKey.ParentKeyID
+	snapshot.AllowedModels = apiKey.AllowedModels
+	snapshot.MaxPriority = apiKey.MaxPriority
+	snapshot.StrictValidation = apiKey.StrictValidation
+	snapshot.MaxRequestCost = apiKey.MaxRequestCost
+	snapshot.BetaFeatures = apiKey.BetaFeatures
+	snapshot.DataResidency = apiKey.DataResidency
+	if apiKey.Parent != nil {
+		snapshot.Parent = &APIKeyAuthParentSnapshot{
+			ID:        apiKey.Parent.ID,
+			Status:    apiKey.Parent.Status,
+			Quota:     apiKey.Parent.Quota,
+			QuotaUsed: apiKey.Parent.QuotaUsed,
+			ExpiresAt: apiKey.Parent.ExpiresAt,
+		}
+	}
+
 	// 填充 (user, group) RPM override —— snapshot 构建时查一次 DB，后续请求零 DB 往返。
 	if apiKey.GroupID != nil && *apiKey.GroupID > 0 && s.userGroupRateRepo != nil {
 		override, err := s.userGroupRateRepo.GetRPMOverrideByUserAndGroup(ctx, apiKey.UserID, *apiKey.GroupID)
@@ -413,6 +517,14 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			MessagesDispatchModelConfig:     apiKey.Group.MessagesDispatchModelConfig,
 			ModelsListConfig:                apiKey.Group.ModelsListConfig,
 			RPMLimit:                        apiKey.Group.RPMLimit,
+			AllowComputerUseTool:            apiKey.Group.AllowComputerUseTool,
+			AllowCodeExecutionTool:          apiKey.Group.AllowCodeExecutionTool,
+			AllowWebSearchTool:              apiKey.Group.AllowWebSearchTool,
+			SystemPromptProfile:             apiKey.Group.SystemPromptProfile,
+			ResponseLanguage:                apiKey.Group.ResponseLanguage,
+			AttributionMode:                 apiKey.Group.AttributionMode,
+			AttributionText:                 apiKey.Group.AttributionText,
+			MaxRequestCost:                  apiKey.Group.MaxRequestCost,
 			PeakRateEnabled:                 apiKey.Group.PeakRateEnabled,
 			PeakStart:                       apiKey.Group.PeakStart,
 			PeakEnd:                         apiKey.Group.PeakEnd,
@@ -458,6 +570,26 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			RPMLimit:                   snapshot.User.RPMLimit,
 			UserGroupRPMOverride:       snapshot.User.UserGroupRPMOverride,
 		},
+		TranscriptEnabled:    snapshot.TranscriptEnabled,
+		AnnotationsEnabled:   snapshot.AnnotationsEnabled,
+		StreamCaptureEnabled: snapshot.StreamCaptureEnabled,
+		ResponseLanguage:     snapshot.ResponseLanguage,
+		ParentKeyID:          snapshot.ParentKeyID,
+		AllowedModels:        snapshot.AllowedModels,
+		MaxPriority:          snapshot.MaxPriority,
+		StrictValidation:     snapshot.StrictValidation,
+		MaxRequestCost:       snapshot.MaxRequestCost,
+		BetaFeatures:         snapshot.BetaFeatures,
+		DataResidency:        snapshot.DataResidency,
+	}
+	if snapshot.Parent != nil {
+		apiKey.Parent = &APIKey{
+			ID:        snapshot.Parent.ID,
+			Status:    snapshot.Parent.Status,
+			Quota:     snapshot.Parent.Quota,
+			QuotaUsed: snapshot.Parent.QuotaUsed,
+			ExpiresAt: snapshot.Parent.ExpiresAt,
+		}
 	}
 	if snapshot.Group != nil {
 		apiKey.Group = &Group{
@@ -497,6 +629,14 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			MessagesDispatchModelConfig:     snapshot.Group.MessagesDispatchModelConfig,
 			ModelsListConfig:                snapshot.Group.ModelsListConfig,
 			RPMLimit:                        snapshot.Group.RPMLimit,
+			AllowComputerUseTool:            snapshot.Group.AllowComputerUseTool,
+			AllowCodeExecutionTool:          snapshot.Group.AllowCodeExecutionTool,
+			AllowWebSearchTool:              snapshot.Group.AllowWebSearchTool,
+			SystemPromptProfile:             snapshot.Group.SystemPromptProfile,
+			ResponseLanguage:                snapshot.Group.ResponseLanguage,
+			AttributionMode:                 snapshot.Group.AttributionMode,
+			AttributionText:                 snapshot.Group.AttributionText,
+			MaxRequestCost:                  snapshot.Group.MaxRequestCost,
 			PeakRateEnabled:                 snapshot.Group.PeakRateEnabled,
 			PeakStart:                       snapshot.Group.PeakStart,
 			PeakEnd:                         snapshot.Group.PeakEnd,
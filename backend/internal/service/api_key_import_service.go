@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// APIKeyImportMaxRows 单次批量导入允许的最大行数
+const APIKeyImportMaxRows = 500
+
+var (
+	ErrAPIKeyImportEmpty   = infraerrors.BadRequest("API_KEY_IMPORT_EMPTY", "no rows to import")
+	ErrAPIKeyImportTooMany = infraerrors.BadRequest("API_KEY_IMPORT_TOO_MANY_ROWS", fmt.Sprintf("at most %d rows can be imported at once", APIKeyImportMaxRows))
+	ErrAPIKeyImportBadCSV  = infraerrors.BadRequest("API_KEY_IMPORT_INVALID_CSV", "invalid CSV")
+
+	ErrAPIKeyImportUserRequired = infraerrors.BadRequest("API_KEY_IMPORT_USER_REQUIRED", "user_id or email is required")
+	ErrAPIKeyImportUserMismatch = infraerrors.BadRequest("API_KEY_IMPORT_USER_MISMATCH", "user_id and email refer to different users")
+	ErrAPIKeyImportInvalidQuota = infraerrors.BadRequest("API_KEY_IMPORT_INVALID_QUOTA", "quota must be >= 0")
+	ErrAPIKeyImportInvalidDays  = infraerrors.BadRequest("API_KEY_IMPORT_INVALID_EXPIRY", "expires_in_days must be >= 0")
+)
+
+// apiKeyImportCSVColumns CSV 支持的列（表头大小写不敏感，顺序任意，缺省列视为空）。
+// scopes 列内多个值以分号分隔。
+var apiKeyImportCSVColumns = []string{"user_id", "email", "name", "group_id", "quota", "expires_in_days", "scopes"}
+
+// APIKeyImportRow 批量导入的一行规格。user_id 与 email 至少提供一个，同时提供时必须指向同一用户。
+// Scopes 为授予该 Key 的实验性功能（与管理员单独设置的 beta_features 同一取值范围）。
+type APIKeyImportRow struct {
+	UserID        int64    `json:"user_id"`
+	Email         string   `json:"email"`
+	Name          string   `json:"name"`
+	GroupID       *int64   `json:"group_id"`
+	Quota         float64  `json:"quota"`
+	ExpiresInDays *int     `json:"expires_in_days"`
+	Scopes        []string `json:"scopes"`
+
+	// parseErr CSV 解析阶段的单元格错误，导入时作为该行的校验错误返回
+	parseErr error
+}
+
+// APIKeyImportInput 批量导入入参。
+type APIKeyImportInput struct {
+	Rows []APIKeyImportRow
+	// SendEmail 为 true 时通过通知邮件把新 Key 发送给对应用户
+	SendEmail bool
+}
+
+// APIKeyImportItem 单行导入结果。Key 为明文密钥，仅在本次响应中返回。
+type APIKeyImportItem struct {
+	Row        int        `json:"row"`
+	UserID     int64      `json:"user_id,omitempty"`
+	Email      string     `json:"email,omitempty"`
+	KeyID      int64      `json:"key_id,omitempty"`
+	Key        string     `json:"key,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Emailed    bool       `json:"emailed,omitempty"`
+	EmailError string     `json:"email_error,omitempty"`
+}
+
+// APIKeyImportResult 批量导入汇总。
+type APIKeyImportResult struct {
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Emailed int                `json:"emailed"`
+	Items   []APIKeyImportItem `json:"items"`
+}
+
+// APIKeyImportService 管理员批量创建 API Key。
+//
+// 每行独立校验与创建：某行失败不影响其它行，失败原因逐行返回。
+// 分组绑定沿用用户自建 Key 的权限校验，专属/订阅分组需要用户已有对应权限。
+type APIKeyImportService struct {
+	apiKeyService            *APIKeyService
+	userRepo                 UserRepository
+	notificationEmailService *NotificationEmailService
+}
+
+// NewAPIKeyImportService 创建批量导入服务。
+func NewAPIKeyImportService(apiKeyService *APIKeyService, userRepo UserRepository, notificationEmailService *NotificationEmailService) *APIKeyImportService {
+	return &APIKeyImportService{
+		apiKeyService:            apiKeyService,
+		userRepo:                 userRepo,
+		notificationEmailService: notificationEmailService,
+	}
+}
+
+// ParseAPIKeyImportCSV 解析带表头的 CSV。表头缺失或无法识别返回 ErrAPIKeyImportBadCSV；
+// 单元格格式错误不会中断解析，而是记录在对应行上，由 Import 作为该行的错误返回。
+func ParseAPIKeyImportCSV(r io.Reader) ([]APIKeyImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrAPIKeyImportEmpty
+		}
+		return nil, ErrAPIKeyImportBadCSV.WithCause(err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for _, column := range apiKeyImportCSVColumns {
+			if name == column {
+				index[column] = i
+			}
+		}
+	}
+	_, hasUserID := index["user_id"]
+	_, hasEmail := index["email"]
+	if !hasUserID && !hasEmail {
+		return nil, infraerrors.BadRequest(ErrAPIKeyImportBadCSV.Reason, "CSV header must contain user_id or email")
+	}
+
+	var rows []APIKeyImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrAPIKeyImportBadCSV.WithCause(err)
+		}
+		cell := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		rows = append(rows, parseAPIKeyImportRecord(cell))
+		if len(rows) > APIKeyImportMaxRows {
+			return nil, ErrAPIKeyImportTooMany
+		}
+	}
+	return rows, nil
+}
+
+func parseAPIKeyImportRecord(cell func(column string) string) APIKeyImportRow {
+	row := APIKeyImportRow{
+		Email: cell("email"),
+		Name:  cell("name"),
+	}
+	fail := func(column, value string) APIKeyImportRow {
+		row.parseErr = infraerrors.Newf(http.StatusBadRequest, "API_KEY_IMPORT_INVALID_CELL", "invalid %s: %q", column, value)
+		return row
+	}
+	if v := cell("user_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fail("user_id", v)
+		}
+		row.UserID = id
+	}
+	if v := cell("group_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fail("group_id", v)
+		}
+		row.GroupID = &id
+	}
+	if v := cell("quota"); v != "" {
+		quota, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fail("quota", v)
+		}
+		row.Quota = quota
+	}
+	if v := cell("expires_in_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return fail("expires_in_days", v)
+		}
+		row.ExpiresInDays = &days
+	}
+	if v := cell("scopes"); v != "" {
+		row.Scopes = strings.Split(v, ";")
+	}
+	return row
+}
+
+// Import 逐行创建 API Key 并返回明文密钥与逐行错误。
+// 只有行数为空或超限时返回 error；单行失败体现在结果的 Items 中。
+func (s *APIKeyImportService) Import(ctx context.Context, input APIKeyImportInput) (*APIKeyImportResult, error) {
+	if len(input.Rows) == 0 {
+		return nil, ErrAPIKeyImportEmpty
+	}
+	if len(input.Rows) > APIKeyImportMaxRows {
+		return nil, ErrAPIKeyImportTooMany
+	}
+
+	result := &APIKeyImportResult{
+		Total: len(input.Rows),
+		Items: make([]APIKeyImportItem, 0, len(input.Rows)),
+	}
+	for i, row := range input.Rows {
+		item := APIKeyImportItem{Row: i + 1, UserID: row.UserID, Email: row.Email}
+		user, key, err := s.importRow(ctx, row)
+		if err != nil {
+			item.Reason, item.Error = apiKeyImportErrorDetail(err)
+			result.Failed++
+			result.Items = append(result.Items, item)
+			continue
+		}
+		item.UserID = user.ID
+		item.Email = user.Email
+		item.KeyID = key.ID
+		item.Key = key.Key
+		item.Name = key.Name
+		item.ExpiresAt = key.ExpiresAt
+		result.Created++
+
+		if input.SendEmail {
+			if err := s.sendKeyEmail(ctx, user, key); err != nil {
+				item.EmailError = err.Error()
+				slog.Warn("api key import email failed", "user_id", user.ID, "key_id", key.ID, "error", err)
+			} else {
+				item.Emailed = true
+				result.Emailed++
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+func (s *APIKeyImportService) importRow(ctx context.Context, row APIKeyImportRow) (*User, *APIKey, error) {
+	if row.parseErr != nil {
+		return nil, nil, row.parseErr
+	}
+	if row.Quota < 0 {
+		return nil, nil, ErrAPIKeyImportInvalidQuota
+	}
+	if row.ExpiresInDays != nil && *row.ExpiresInDays < 0 {
+		return nil, nil, ErrAPIKeyImportInvalidDays
+	}
+	scopes, err := NormalizeBetaFeatures(row.Scopes)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.resolveUser(ctx, row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := strings.TrimSpace(row.Name)
+	if name == "" {
+		name = "Imported key"
+	}
+	key, err := s.apiKeyService.Create(ctx, user.ID, CreateAPIKeyRequest{
+		Name:          name,
+		GroupID:       row.GroupID,
+		Quota:         row.Quota,
+		ExpiresInDays: row.ExpiresInDays,
+		BetaFeatures:  scopes,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, key, nil
+}
+
+func (s *APIKeyImportService) resolveUser(ctx context.Context, row APIKeyImportRow) (*User, error) {
+	email := strings.TrimSpace(row.Email)
+	switch {
+	case row.UserID > 0:
+		user, err := s.userRepo.GetByID(ctx, row.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if email != "" && !strings.EqualFold(user.Email, email) {
+			return nil, ErrAPIKeyImportUserMismatch
+		}
+		return user, nil
+	case email != "":
+		return s.userRepo.GetByEmail(ctx, email)
+	default:
+		return nil, ErrAPIKeyImportUserRequired
+	}
+}
+
+func (s *APIKeyImportService) sendKeyEmail(ctx context.Context, user *User, key *APIKey) error {
+	if s.notificationEmailService == nil {
+		return errors.New("notification email service is not configured")
+	}
+	expiry := "-"
+	if key.ExpiresAt != nil {
+		expiry = key.ExpiresAt.UTC().Format("2006-01-02 15:04")
+	}
+	return s.notificationEmailService.Send(ctx, NotificationEmailSendInput{
+		Event:          NotificationEmailEventAPIKeyProvisioned,
+		RecipientEmail: user.Email,
+		RecipientName:  user.Username,
+		UserID:         user.ID,
+		SourceType:     "api_key",
+		SourceID:       strconv.FormatInt(key.ID, 10),
+		Variables: map[string]string{
+			"api_key_name": key.Name,
+			"api_key":      key.Key,
+			"expiry_time":  expiry,
+		},
+	})
+}
+
+// apiKeyImportErrorDetail 把错误转换为逐行返回的 reason / message。
+func apiKeyImportErrorDetail(err error) (string, string) {
+	var appErr *infraerrors.ApplicationError
+	if errors.As(err, &appErr) {
+		return appErr.Reason, appErr.Message
+	}
+	return infraerrors.UnknownReason, err.Error()
+}
@@ -0,0 +1,121 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type importAPIKeyRepoStub struct {
+	quotaBaseAPIKeyRepoStub
+	created []*APIKey
+}
+
+func (s *importAPIKeyRepoStub) Create(_ context.Context, key *APIKey) error {
+	key.ID = int64(len(s.created) + 1)
+	s.created = append(s.created, key)
+	return nil
+}
+
+func newAPIKeyImportServiceForTest(repo *importAPIKeyRepoStub) *APIKeyImportService {
+	alice := &User{ID: 7, Email: "alice@example.com", Username: "alice"}
+	userRepo := &userRepoStub{
+		user:         alice,
+		usersByEmail: map[string]*User{alice.Email: alice},
+	}
+	apiKeySvc := NewAPIKeyService(repo, userRepo, nil, nil, nil, nil, &config.Config{})
+	notifier := NewNotificationEmailService(newNotificationEmailMemorySettingRepo(), nil)
+	return NewAPIKeyImportService(apiKeySvc, userRepo, notifier)
+}
+
+func TestParseAPIKeyImportCSV(t *testing.T) {
+	rows, err := ParseAPIKeyImportCSV(strings.NewReader(
+		"Email,name,quota,expires_in_days,scopes\n" +
+			"alice@example.com,ci,10.5,30,\n" +
+			"\n" +
+			"bob@example.com,bad,abc,,\n"))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, "alice@example.com", rows[0].Email)
+	require.Equal(t, 10.5, rows[0].Quota)
+	require.Equal(t, 30, *rows[0].ExpiresInDays)
+	require.NoError(t, rows[0].parseErr)
+	require.Error(t, rows[1].parseErr)
+
+	_, err = ParseAPIKeyImportCSV(strings.NewReader("name,quota\nci,1\n"))
+	require.ErrorIs(t, err, ErrAPIKeyImportBadCSV)
+
+	_, err = ParseAPIKeyImportCSV(strings.NewReader(""))
+	require.ErrorIs(t, err, ErrAPIKeyImportEmpty)
+}
+
+func TestAPIKeyImportService_ImportReportsPerRowErrors(t *testing.T) {
+	repo := &importAPIKeyRepoStub{}
+	svc := newAPIKeyImportServiceForTest(repo)
+	days := 7
+
+	result, err := svc.Import(context.Background(), APIKeyImportInput{Rows: []APIKeyImportRow{
+		{Email: "alice@example.com", Name: "ci", Quota: 5, ExpiresInDays: &days},
+		{UserID: 7, Email: "someone-else@example.com"},
+		{Email: "missing@example.com"},
+		{},
+		{Email: "alice@example.com", Quota: -1},
+		{UserID: 7, Scopes: []string{"no-such-feature"}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, 6, result.Total)
+	require.Equal(t, 1, result.Created)
+	require.Equal(t, 5, result.Failed)
+
+	created := result.Items[0]
+	require.Equal(t, 1, created.Row)
+	require.Equal(t, int64(7), created.UserID)
+	require.Equal(t, repo.created[0].Key, created.Key)
+	require.NotEmpty(t, created.Key)
+	require.NotNil(t, created.ExpiresAt)
+	require.Equal(t, 5.0, repo.created[0].Quota)
+
+	require.Equal(t, ErrAPIKeyImportUserMismatch.Reason, result.Items[1].Reason)
+	require.Equal(t, ErrUserNotFound.Reason, result.Items[2].Reason)
+	require.Equal(t, ErrAPIKeyImportUserRequired.Reason, result.Items[3].Reason)
+	require.Equal(t, ErrAPIKeyImportInvalidQuota.Reason, result.Items[4].Reason)
+	require.Equal(t, ErrInvalidBetaFeature.Reason, result.Items[5].Reason)
+	for _, item := range result.Items[1:] {
+		require.Empty(t, item.Key)
+		require.NotEmpty(t, item.Error)
+	}
+}
+
+func TestAPIKeyImportService_ImportGrantsScopesAndReportsEmailFailure(t *testing.T) {
+	repo := &importAPIKeyRepoStub{}
+	svc := newAPIKeyImportServiceForTest(repo)
+
+	result, err := svc.Import(context.Background(), APIKeyImportInput{
+		Rows:      []APIKeyImportRow{{UserID: 7, Scopes: []string{BetaFeatures[0], " " + strings.ToUpper(BetaFeatures[0])}}},
+		SendEmail: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Created)
+	require.Equal(t, []string{BetaFeatures[0]}, repo.created[0].BetaFeatures)
+	require.Equal(t, "Imported key", repo.created[0].Name)
+
+	// 未配置 SMTP 时 Key 仍然创建成功，邮件失败逐行返回
+	require.False(t, result.Items[0].Emailed)
+	require.NotEmpty(t, result.Items[0].EmailError)
+	require.Zero(t, result.Emailed)
+}
+
+func TestAPIKeyImportService_RejectsEmptyAndOversizedBatches(t *testing.T) {
+	svc := newAPIKeyImportServiceForTest(&importAPIKeyRepoStub{})
+
+	_, err := svc.Import(context.Background(), APIKeyImportInput{})
+	require.ErrorIs(t, err, ErrAPIKeyImportEmpty)
+
+	_, err = svc.Import(context.Background(), APIKeyImportInput{Rows: make([]APIKeyImportRow, APIKeyImportMaxRows+1)})
+	require.ErrorIs(t, err, ErrAPIKeyImportTooMany)
+}
@@ -46,6 +46,7 @@ const (
 	MaxAPIKeyCredentialBytes     = 128
 	defaultAuthLookupConcurrency = 64
 	defaultNegativeAuthCacheSize = 16384
+	defaultStaleAuthCacheSize    = 65536
 	apiKeyMaxErrorsPerHour       = 20
 	apiKeyLastUsedMinTouch       = 30 * time.Second
 	apiKeySortCurrentConcurrency = "current_concurrency"
@@ -80,6 +81,8 @@ type APIKeyRepository interface {
 	CountByGroupID(ctx context.Context, groupID int64) (int64, error)
 	ListKeysByUserID(ctx context.Context, userID int64) ([]string, error)
 	ListKeysByGroupID(ctx context.Context, groupID int64) ([]string, error)
+	// ListByParentKeyID 列出父 Key 下的委托子 Key
+	ListByParentKeyID(ctx context.Context, parentKeyID int64) ([]APIKey, error)
 
 	// Quota methods
 	IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error)
@@ -193,6 +196,17 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// TranscriptEnabled 是否留存完整会话
+	TranscriptEnabled bool `json:"transcript_enabled"`
+	// ResponseLanguage 强制回复语言（空 = 沿用分组，off = 关闭分组的强制设置）
+	ResponseLanguage string `json:"response_language"`
+	// StrictValidation 严格请求校验开关
+	StrictValidation bool `json:"strict_validation"`
+	// MaxRequestCost 单请求预估费用上限（美元，0 = 不限制）
+	MaxRequestCost float64 `json:"max_request_cost"`
+	// BetaFeatures 授予的实验性功能，仅管理员批量导入时设置（需已规范化）
+	BetaFeatures []string `json:"beta_features"`
 }
 
 // UpdateAPIKeyRequest 更新API Key请求
@@ -214,6 +228,15 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // Reset all usage counters to 0
+
+	// TranscriptEnabled 会话全文留存开关（nil 不修改）
+	TranscriptEnabled *bool `json:"transcript_enabled"`
+	// ResponseLanguage 强制回复语言（nil 不修改）
+	ResponseLanguage *string `json:"response_language"`
+	// StrictValidation 严格请求校验开关（nil 不修改）
+	StrictValidation *bool `json:"strict_validation"`
+	// MaxRequestCost 单请求预估费用上限（nil 不修改，0 取消）
+	MaxRequestCost *float64 `json:"max_request_cost"`
 }
 
 // APIKeyService API Key服务
@@ -234,6 +257,8 @@ type APIKeyService struct {
 	cfg                       *config.Config
 	authCacheL1               *ristretto.Cache
 	authNegativeCacheL1       *ristretto.Cache
+	authStaleCache            *ristretto.Cache // 数据库降级时使用的最后一次成功认证快照
+	authStaleServed           atomic.Uint64
 	authCfg                   apiKeyAuthCacheConfig
 	authGroup                 singleflight.Group
 	authLookupSlots           chan struct{}
@@ -256,6 +281,8 @@ type APIKeyAuthLookupMetrics struct {
 	Rejected uint64 `json:"rejected"`
 	InFlight int64  `json:"in_flight"`
 	Capacity int    `json:"capacity"`
+	// StaleServed 数据库不可用时使用过期认证快照放行的次数
+	StaleServed uint64 `json:"stale_served"`
 }
 
 func (s *APIKeyService) AuthLookupMetrics() APIKeyAuthLookupMetrics {
@@ -263,10 +290,11 @@ func (s *APIKeyService) AuthLookupMetrics() APIKeyAuthLookupMetrics {
 		return APIKeyAuthLookupMetrics{}
 	}
 	return APIKeyAuthLookupMetrics{
-		Total:    s.authLookupTotal.Load(),
-		Rejected: s.authLookupRejected.Load(),
-		InFlight: s.authLookupInFlight.Load(),
-		Capacity: cap(s.authLookupSlots),
+		Total:       s.authLookupTotal.Load(),
+		Rejected:    s.authLookupRejected.Load(),
+		InFlight:    s.authLookupInFlight.Load(),
+		Capacity:    cap(s.authLookupSlots),
+		StaleServed: s.authStaleServed.Load(),
 	}
 }
 
@@ -419,6 +447,10 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
+	if req.MaxRequestCost < 0 {
+		return nil, ErrInvalidMaxRequestCost
+	}
+
 	// 验证分组权限（如果指定了分组）
 	if req.GroupID != nil {
 		group, err := s.groupRepo.GetByID(ctx, *req.GroupID)
@@ -467,6 +499,11 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
+	responseLanguage, err := NormalizeResponseLanguage(req.ResponseLanguage, true)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建API Key记录
 	apiKey := &APIKey{
 		UserID:      userID,
@@ -481,6 +518,12 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		RateLimit5h: req.RateLimit5h,
 		RateLimit1d: req.RateLimit1d,
 		RateLimit7d: req.RateLimit7d,
+
+		TranscriptEnabled: req.TranscriptEnabled,
+		ResponseLanguage:  responseLanguage,
+		StrictValidation:  req.StrictValidation,
+		MaxRequestCost:    req.MaxRequestCost,
+		BetaFeatures:      req.BetaFeatures,
 	}
 
 	// Set expiration time if specified
@@ -797,6 +840,25 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	if req.RateLimit7d != nil {
 		apiKey.RateLimit7d = *req.RateLimit7d
 	}
+	if req.TranscriptEnabled != nil {
+		apiKey.TranscriptEnabled = *req.TranscriptEnabled
+	}
+	if req.ResponseLanguage != nil {
+		language, err := NormalizeResponseLanguage(*req.ResponseLanguage, true)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.ResponseLanguage = language
+	}
+	if req.StrictValidation != nil {
+		apiKey.StrictValidation = *req.StrictValidation
+	}
+	if req.MaxRequestCost != nil {
+		if *req.MaxRequestCost < 0 {
+			return nil, ErrInvalidMaxRequestCost
+		}
+		apiKey.MaxRequestCost = *req.MaxRequestCost
+	}
 	resetRateLimit := req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage
 	if resetRateLimit {
 		apiKey.Usage5h = 0
@@ -812,6 +874,9 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	}
 
 	s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	if !apiKey.IsSubKey() {
+		s.InvalidateSubKeyAuthCache(ctx, apiKey.ID)
+	}
 	s.compileAPIKeyIPRules(apiKey)
 
 	// Invalidate Redis rate limit cache so reset takes effect immediately
@@ -845,10 +910,26 @@ func (s *APIKeyService) Delete(ctx context.Context, id int64, userID int64) erro
 	}
 	s.InvalidateAuthCacheByKey(ctx, key)
 	s.lastUsedTouchL1.Delete(id)
+	s.deleteSubKeys(ctx, id)
 
 	return nil
 }
 
+// deleteSubKeys 删除父 Key 后一并吊销其签发的委托子 Key（尽力而为，失败的子 Key 因父 Key 不存在仍无法认证）
+func (s *APIKeyService) deleteSubKeys(ctx context.Context, parentKeyID int64) {
+	subKeys, err := s.apiKeyRepo.ListByParentKeyID(ctx, parentKeyID)
+	if err != nil {
+		return
+	}
+	for i := range subKeys {
+		if err := s.apiKeyRepo.DeleteWithAudit(ctx, subKeys[i].ID); err != nil {
+			continue
+		}
+		s.InvalidateAuthCacheByKey(ctx, subKeys[i].Key)
+		s.lastUsedTouchL1.Delete(subKeys[i].ID)
+	}
+}
+
 // ValidateKey 验证API Key是否有效（用于认证中间件）
 func (s *APIKeyService) ValidateKey(ctx context.Context, key string) (*APIKey, *User, error) {
 	// 获取API Key
@@ -1027,6 +1108,7 @@ func (s *APIKeyService) UpdateQuotaUsed(ctx context.Context, apiKeyID int64, cos
 		}
 		if state != nil && state.Status == StatusAPIKeyQuotaExhausted && strings.TrimSpace(state.Key) != "" {
 			s.InvalidateAuthCacheByKey(ctx, state.Key)
+			s.InvalidateSubKeyAuthCache(ctx, apiKeyID)
 		}
 		return nil
 	}
@@ -1051,6 +1133,7 @@ func (s *APIKeyService) UpdateQuotaUsed(ctx context.Context, apiKeyID int64, cos
 		}
 		// Invalidate cache so next request sees the new status
 		s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+		s.InvalidateSubKeyAuthCache(ctx, apiKey.ID)
 	}
 
 	return nil
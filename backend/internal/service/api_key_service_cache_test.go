@@ -107,6 +107,10 @@ func (s *authRepoStub) ListKeysByGroupID(ctx context.Context, groupID int64) ([]
 	return s.listKeysByGroupID(ctx, groupID)
 }
 
+func (s *authRepoStub) ListByParentKeyID(ctx context.Context, parentKeyID int64) ([]APIKey, error) {
+	return nil, nil
+}
+
 func (s *authRepoStub) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	panic("unexpected IncrementQuotaUsed call")
 }
@@ -650,3 +654,52 @@ func TestAPIKeyService_GetByKey_SingleflightCollapses(t *testing.T) {
 	}
 	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
 }
+
+func TestAPIKeyService_GetByKey_ServesStaleSnapshotWhenDBFails(t *testing.T) {
+	var dbDown atomic.Bool
+	cache := &authCacheStub{}
+	repo := &authRepoStub{
+		getByKeyForAuth: func(ctx context.Context, key string) (*APIKey, error) {
+			if dbDown.Load() {
+				return nil, errors.New("dial tcp: connection refused")
+			}
+			return &APIKey{
+				ID:     31,
+				UserID: 4,
+				Status: StatusActive,
+				User: &User{
+					ID:          4,
+					Status:      StatusActive,
+					Role:        RoleUser,
+					Balance:     8,
+					Concurrency: 1,
+				},
+			}, nil
+		},
+	}
+	cfg := &config.Config{
+		APIKeyAuth: config.APIKeyAuthCacheConfig{L2TTLSeconds: 60},
+		Database: config.DatabaseConfig{
+			Degraded: config.DatabaseDegradedConfig{Enabled: true, StaleTTLSeconds: 600},
+		},
+	}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, cache, cfg)
+	cache.getAuthCache = func(ctx context.Context, key string) (*APIKeyAuthCacheEntry, error) {
+		return nil, redis.Nil
+	}
+
+	_, err := svc.GetByKey(context.Background(), "k-stale")
+	require.NoError(t, err)
+	svc.authStaleCache.Wait()
+
+	dbDown.Store(true)
+	apiKey, err := svc.GetByKey(context.Background(), "k-stale")
+	require.NoError(t, err)
+	require.Equal(t, int64(31), apiKey.ID)
+	require.Equal(t, uint64(1), svc.AuthLookupMetrics().StaleServed)
+
+	// 显式失效（吊销、改绑分组等）必须同时清除过期快照
+	svc.InvalidateAuthCacheByKey(context.Background(), "k-stale")
+	_, err = svc.GetByKey(context.Background(), "k-stale")
+	require.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	// maxSubKeysPerParent 单个父 Key 可签发的委托子 Key 上限
+	maxSubKeysPerParent = 200
+	// maxSubKeyAllowedModels 子 Key 模型允许列表的条目上限
+	maxSubKeyAllowedModels = 64
+)
+
+var (
+	ErrSubKeyNestingNotAllowed = infraerrors.Forbidden("SUB_KEY_NESTING_NOT_ALLOWED", "delegated sub-keys cannot mint sub-keys")
+	ErrSubKeyLimitReached      = infraerrors.BadRequest("SUB_KEY_LIMIT_REACHED", "maximum number of sub-keys reached for this api key")
+	ErrSubKeyInvalidQuota      = infraerrors.BadRequest("SUB_KEY_INVALID_QUOTA", "sub-key spend cap must not be negative")
+	ErrSubKeyQuotaExceeded     = infraerrors.BadRequest("SUB_KEY_QUOTA_EXCEEDS_PARENT", "sub-key spend cap exceeds the remaining quota of the parent key")
+	ErrSubKeyInvalidExpiry     = infraerrors.BadRequest("SUB_KEY_INVALID_EXPIRY", "sub-key expiry must be in the future and not later than the parent key")
+	ErrSubKeyTooManyModels     = infraerrors.BadRequest("SUB_KEY_TOO_MANY_MODELS", "too many allowed models for sub-key")
+	ErrSubKeyNotFound          = infraerrors.NotFound("SUB_KEY_NOT_FOUND", "sub-key not found")
+	ErrSubKeyModelNotAllowed   = infraerrors.Forbidden("MODEL_NOT_ALLOWED", "model is not allowed for this api key")
+)
+
+// CreateSubKeyRequest 委托子 Key 创建请求
+type CreateSubKeyRequest struct {
+	Name string
+	// Quota 子 Key 自身的花费上限（USD，0 = 仅受父 Key 额度约束）
+	Quota float64
+	// AllowedModels 允许请求的模型（支持末尾 * 通配），空表示不限制
+	AllowedModels []string
+	// ExpiresAt 过期时间（nil = 与父 Key 一致）
+	ExpiresAt *time.Time
+}
+
+// CreateSubKey 为父 Key 签发委托子 Key：子 Key 继承父 Key 的用户与分组，用量同时计入父 Key 额度。
+func (s *APIKeyService) CreateSubKey(ctx context.Context, parent *APIKey, req CreateSubKeyRequest) (*APIKey, error) {
+	if parent == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	if parent.IsSubKey() {
+		return nil, ErrSubKeyNestingNotAllowed
+	}
+	if req.Quota < 0 {
+		return nil, ErrSubKeyInvalidQuota
+	}
+	if remaining := parent.GetQuotaRemaining(); remaining >= 0 && req.Quota > remaining {
+		return nil, ErrSubKeyQuotaExceeded
+	}
+
+	expiresAt := parent.ExpiresAt
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) || (parent.ExpiresAt != nil && req.ExpiresAt.After(*parent.ExpiresAt)) {
+			return nil, ErrSubKeyInvalidExpiry
+		}
+		expiresAt = req.ExpiresAt
+	}
+
+	allowedModels, err := normalizeSubKeyAllowedModels(req.AllowedModels)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.apiKeyRepo.ListByParentKeyID(ctx, parent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list sub-keys: %w", err)
+	}
+	if len(existing) >= maxSubKeysPerParent {
+		return nil, ErrSubKeyLimitReached
+	}
+
+	key, err := s.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	parentKeyID := parent.ID
+	subKey := &APIKey{
+		UserID:        parent.UserID,
+		Key:           key,
+		Name:          html.EscapeString(req.Name),
+		GroupID:       parent.GroupID,
+		Status:        StatusActive,
+		Quota:         req.Quota,
+		ExpiresAt:     expiresAt,
+		ParentKeyID:   &parentKeyID,
+		AllowedModels: allowedModels,
+	}
+	if err := s.apiKeyRepo.Create(ctx, subKey); err != nil {
+		return nil, fmt.Errorf("create sub-key: %w", err)
+	}
+
+	s.InvalidateAuthCacheByKey(ctx, subKey.Key)
+	return subKey, nil
+}
+
+// ListSubKeys 列出父 Key 签发的委托子 Key
+func (s *APIKeyService) ListSubKeys(ctx context.Context, parent *APIKey) ([]APIKey, error) {
+	if parent == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	if parent.IsSubKey() {
+		return nil, ErrSubKeyNestingNotAllowed
+	}
+	keys, err := s.apiKeyRepo.ListByParentKeyID(ctx, parent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list sub-keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeSubKey 吊销（删除）父 Key 签发的委托子 Key
+func (s *APIKeyService) RevokeSubKey(ctx context.Context, parent *APIKey, subKeyID int64) error {
+	if parent == nil {
+		return ErrAPIKeyNotFound
+	}
+	if parent.IsSubKey() {
+		return ErrSubKeyNestingNotAllowed
+	}
+	subKey, err := s.apiKeyRepo.GetByID(ctx, subKeyID)
+	if err != nil {
+		if infraerrors.IsNotFound(err) {
+			return ErrSubKeyNotFound
+		}
+		return fmt.Errorf("get sub-key: %w", err)
+	}
+	if subKey.ParentKeyID == nil || *subKey.ParentKeyID != parent.ID {
+		return ErrSubKeyNotFound
+	}
+	if err := s.apiKeyRepo.DeleteWithAudit(ctx, subKey.ID); err != nil {
+		return fmt.Errorf("delete sub-key: %w", err)
+	}
+	s.InvalidateAuthCacheByKey(ctx, subKey.Key)
+	s.lastUsedTouchL1.Delete(subKey.ID)
+	return nil
+}
+
+// InvalidateSubKeyAuthCache 父 Key 状态变化（停用、额度、过期、删除）后清理其子 Key 的认证缓存，
+// 子 Key 的认证快照内含父 Key 状态。
+func (s *APIKeyService) InvalidateSubKeyAuthCache(ctx context.Context, parentKeyID int64) {
+	if s == nil || s.apiKeyRepo == nil || parentKeyID <= 0 {
+		return
+	}
+	keys, err := s.apiKeyRepo.ListByParentKeyID(ctx, parentKeyID)
+	if err != nil {
+		return
+	}
+	for i := range keys {
+		s.InvalidateAuthCacheByKey(ctx, keys[i].Key)
+	}
+}
+
+// InvalidateParentKeyAuthCache 父 Key 额度在扣费事务中耗尽后，清理父 Key 及其全部子 Key 的认证缓存
+func (s *APIKeyService) InvalidateParentKeyAuthCache(ctx context.Context, parentKeyID int64) {
+	if s == nil || s.apiKeyRepo == nil || parentKeyID <= 0 {
+		return
+	}
+	if key, _, err := s.apiKeyRepo.GetKeyAndOwnerID(ctx, parentKeyID); err == nil {
+		s.InvalidateAuthCacheByKey(ctx, key)
+	}
+	s.InvalidateSubKeyAuthCache(ctx, parentKeyID)
+}
+
+// CheckParentKeyUsable 检查子 Key 的父 Key 是否可用：父 Key 已删除或停用时返回 false。
+// 普通 Key 始终返回 true。
+func (k *APIKey) CheckParentKeyUsable() bool {
+	if !k.IsSubKey() {
+		return true
+	}
+	parent := k.Parent
+	if parent == nil {
+		return false
+	}
+	return parent.IsActive() || parent.Status == StatusAPIKeyExpired || parent.Status == StatusAPIKeyQuotaExhausted
+}
+
+// CheckParentQuotaAndExpiry 检查子 Key 的父 Key 是否已过期或额度用完（计费阶段执行）
+func (k *APIKey) CheckParentQuotaAndExpiry() error {
+	if !k.IsSubKey() || k.Parent == nil {
+		return nil
+	}
+	parent := k.Parent
+	if parent.Status == StatusAPIKeyExpired || parent.IsExpired() {
+		return ErrAPIKeyExpired
+	}
+	if parent.Status == StatusAPIKeyQuotaExhausted || parent.IsQuotaExhausted() {
+		return ErrAPIKeyQuotaExhausted
+	}
+	return nil
+}
+
+func normalizeSubKeyAllowedModels(models []string) ([]string, error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]struct{}, len(models))
+	out := make([]string, 0, len(models))
+	for _, model := range models {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			continue
+		}
+		if _, ok := seen[model]; ok {
+			continue
+		}
+		seen[model] = struct{}{}
+		out = append(out, model)
+	}
+	if len(out) > maxSubKeyAllowedModels {
+		return nil, ErrSubKeyTooManyModels
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
@@ -0,0 +1,223 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type subKeyRepoStub struct {
+	APIKeyRepository
+	keys       map[int64]*APIKey
+	nextID     int64
+	deletedIDs []int64
+}
+
+func newSubKeyRepoStub() *subKeyRepoStub {
+	return &subKeyRepoStub{keys: map[int64]*APIKey{}, nextID: 100}
+}
+
+func (s *subKeyRepoStub) Create(_ context.Context, key *APIKey) error {
+	s.nextID++
+	key.ID = s.nextID
+	clone := *key
+	s.keys[key.ID] = &clone
+	return nil
+}
+
+func (s *subKeyRepoStub) GetByID(_ context.Context, id int64) (*APIKey, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	clone := *key
+	return &clone, nil
+}
+
+func (s *subKeyRepoStub) ListByParentKeyID(_ context.Context, parentKeyID int64) ([]APIKey, error) {
+	var out []APIKey
+	for _, key := range s.keys {
+		if key.ParentKeyID != nil && *key.ParentKeyID == parentKeyID {
+			out = append(out, *key)
+		}
+	}
+	return out, nil
+}
+
+func (s *subKeyRepoStub) DeleteWithAudit(_ context.Context, id int64) error {
+	s.deletedIDs = append(s.deletedIDs, id)
+	delete(s.keys, id)
+	return nil
+}
+
+func newSubKeyTestService(repo APIKeyRepository) *APIKeyService {
+	return NewAPIKeyService(repo, nil, nil, nil, nil, nil, &config.Config{})
+}
+
+func TestCreateSubKey_InheritsParentAndNormalizesModels(t *testing.T) {
+	repo := newSubKeyRepoStub()
+	svc := newSubKeyTestService(repo)
+	groupID := int64(9)
+	parentExpiry := time.Now().Add(48 * time.Hour)
+	parent := &APIKey{ID: 1, UserID: 7, GroupID: &groupID, Status: StatusActive, Quota: 50, QuotaUsed: 10, ExpiresAt: &parentExpiry}
+
+	subKey, err := svc.CreateSubKey(context.Background(), parent, CreateSubKeyRequest{
+		Name:          "customer-a",
+		Quota:         20,
+		AllowedModels: []string{" claude-sonnet-* ", "", "claude-sonnet-*", "gpt-5"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), subKey.UserID)
+	require.Equal(t, &groupID, subKey.GroupID)
+	require.Equal(t, int64(1), *subKey.ParentKeyID)
+	require.Equal(t, 20.0, subKey.Quota)
+	require.Equal(t, []string{"claude-sonnet-*", "gpt-5"}, subKey.AllowedModels)
+	require.Equal(t, &parentExpiry, subKey.ExpiresAt, "expiry defaults to the parent key")
+	require.NotEqual(t, "", subKey.Key)
+
+	keys, err := svc.ListSubKeys(context.Background(), parent)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+}
+
+func TestCreateSubKey_Validation(t *testing.T) {
+	svc := newSubKeyTestService(newSubKeyRepoStub())
+	parentExpiry := time.Now().Add(time.Hour)
+	parent := &APIKey{ID: 1, UserID: 7, Status: StatusActive, Quota: 50, QuotaUsed: 40, ExpiresAt: &parentExpiry}
+	ctx := context.Background()
+
+	_, err := svc.CreateSubKey(ctx, parent, CreateSubKeyRequest{Name: "a", Quota: -1})
+	require.ErrorIs(t, err, ErrSubKeyInvalidQuota)
+
+	_, err = svc.CreateSubKey(ctx, parent, CreateSubKeyRequest{Name: "a", Quota: 11})
+	require.ErrorIs(t, err, ErrSubKeyQuotaExceeded)
+
+	later := parentExpiry.Add(time.Hour)
+	_, err = svc.CreateSubKey(ctx, parent, CreateSubKeyRequest{Name: "a", ExpiresAt: &later})
+	require.ErrorIs(t, err, ErrSubKeyInvalidExpiry)
+
+	past := time.Now().Add(-time.Minute)
+	_, err = svc.CreateSubKey(ctx, parent, CreateSubKeyRequest{Name: "a", ExpiresAt: &past})
+	require.ErrorIs(t, err, ErrSubKeyInvalidExpiry)
+
+	parentKeyID := int64(1)
+	subKey := &APIKey{ID: 2, UserID: 7, Status: StatusActive, ParentKeyID: &parentKeyID}
+	_, err = svc.CreateSubKey(ctx, subKey, CreateSubKeyRequest{Name: "nested"})
+	require.ErrorIs(t, err, ErrSubKeyNestingNotAllowed)
+}
+
+func TestRevokeSubKey_OnlyOwnSubKeys(t *testing.T) {
+	repo := newSubKeyRepoStub()
+	svc := newSubKeyTestService(repo)
+	parent := &APIKey{ID: 1, UserID: 7, Status: StatusActive}
+	other := &APIKey{ID: 2, UserID: 7, Status: StatusActive}
+
+	subKey, err := svc.CreateSubKey(context.Background(), parent, CreateSubKeyRequest{Name: "a"})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, svc.RevokeSubKey(context.Background(), other, subKey.ID), ErrSubKeyNotFound)
+	require.ErrorIs(t, svc.RevokeSubKey(context.Background(), parent, 999), ErrSubKeyNotFound)
+	require.NoError(t, svc.RevokeSubKey(context.Background(), parent, subKey.ID))
+	require.Equal(t, []int64{subKey.ID}, repo.deletedIDs)
+}
+
+func TestAPIKey_ParentChecks(t *testing.T) {
+	parentKeyID := int64(1)
+	subKey := &APIKey{ID: 2, Status: StatusActive, ParentKeyID: &parentKeyID}
+	require.False(t, subKey.CheckParentKeyUsable(), "deleted parent disables the sub-key")
+
+	subKey.Parent = &APIKey{ID: 1, Status: StatusAPIKeyDisabled}
+	require.False(t, subKey.CheckParentKeyUsable())
+
+	subKey.Parent = &APIKey{ID: 1, Status: StatusActive, Quota: 10, QuotaUsed: 10}
+	require.True(t, subKey.CheckParentKeyUsable())
+	require.ErrorIs(t, subKey.CheckParentQuotaAndExpiry(), ErrAPIKeyQuotaExhausted)
+
+	expired := time.Now().Add(-time.Minute)
+	subKey.Parent = &APIKey{ID: 1, Status: StatusActive, ExpiresAt: &expired}
+	require.ErrorIs(t, subKey.CheckParentQuotaAndExpiry(), ErrAPIKeyExpired)
+
+	subKey.Parent = &APIKey{ID: 1, Status: StatusActive, Quota: 10, QuotaUsed: 5}
+	require.NoError(t, subKey.CheckParentQuotaAndExpiry())
+
+	regular := &APIKey{ID: 3, Status: StatusActive}
+	require.True(t, regular.CheckParentKeyUsable())
+	require.NoError(t, regular.CheckParentQuotaAndExpiry())
+}
+
+func TestAPIKey_IsModelAllowed(t *testing.T) {
+	key := &APIKey{}
+	require.True(t, key.IsModelAllowed("anything"))
+
+	key.AllowedModels = []string{"claude-sonnet-*", "gpt-5"}
+	require.True(t, key.IsModelAllowed("claude-sonnet-4-5"))
+	require.True(t, key.IsModelAllowed("gpt-5"))
+	require.False(t, key.IsModelAllowed("gpt-5-pro"))
+	require.False(t, key.IsModelAllowed("claude-opus-4-1"))
+}
+
+func TestBuildUsageBillingCommand_SubKeyBillsParentQuota(t *testing.T) {
+	parentKeyID := int64(1)
+	p := &postUsageBillingParams{
+		Cost:          &CostBreakdown{TotalCost: 2, ActualCost: 3},
+		User:          &User{ID: 7},
+		APIKey:        &APIKey{ID: 2, ParentKeyID: &parentKeyID},
+		Account:       &Account{ID: 5, Type: AccountTypeOAuth},
+		APIKeyService: &APIKeyService{},
+	}
+
+	cmd := buildUsageBillingCommand("req-1", nil, p)
+	require.Equal(t, 3.0, cmd.APIKeyQuotaCost, "sub-keys always track their own spend")
+	require.Equal(t, int64(1), cmd.ParentAPIKeyID)
+	require.Equal(t, 3.0, cmd.ParentAPIKeyQuotaCost)
+
+	p.APIKey = &APIKey{ID: 3}
+	regular := buildUsageBillingCommand("req-1", nil, p)
+	require.Zero(t, regular.APIKeyQuotaCost)
+	require.Zero(t, regular.ParentAPIKeyID)
+	require.NotEqual(t, regular.RequestFingerprint, cmd.RequestFingerprint)
+}
+
+type subKeyCacheInvalidatorStub struct {
+	invalidatedKeys    []string
+	invalidatedParents []int64
+}
+
+func (s *subKeyCacheInvalidatorStub) UpdateQuotaUsed(context.Context, int64, float64) error {
+	return nil
+}
+
+func (s *subKeyCacheInvalidatorStub) UpdateRateLimitUsage(context.Context, int64, float64) error {
+	return nil
+}
+
+func (s *subKeyCacheInvalidatorStub) InvalidateAuthCacheByKey(_ context.Context, key string) {
+	s.invalidatedKeys = append(s.invalidatedKeys, key)
+}
+
+func (s *subKeyCacheInvalidatorStub) InvalidateSubKeyAuthCache(_ context.Context, parentKeyID int64) {
+	s.invalidatedParents = append(s.invalidatedParents, parentKeyID)
+}
+
+func TestApplyUsageBilling_ParentQuotaExhaustedInvalidatesSubKeys(t *testing.T) {
+	invalidator := &subKeyCacheInvalidatorStub{}
+	repo := &openAIRecordUsageBillingRepoStub{result: &UsageBillingApplyResult{Applied: true, APIKeyQuotaExhausted: true}}
+	p := &postUsageBillingParams{
+		Cost:          &CostBreakdown{TotalCost: 1, ActualCost: 1},
+		User:          &User{ID: 7},
+		APIKey:        &APIKey{ID: 1, Key: "parent-key"},
+		Account:       &Account{ID: 5},
+		APIKeyService: invalidator,
+	}
+
+	applied, err := applyUsageBilling(context.Background(), "req-parent", &UsageLog{RequestID: "req-parent"}, p, &billingDeps{deferredService: &DeferredService{}}, repo)
+	require.NoError(t, err)
+	require.True(t, applied)
+	require.Equal(t, []string{"parent-key"}, invalidator.invalidatedKeys)
+	require.Equal(t, []int64{1}, invalidator.invalidatedParents)
+}
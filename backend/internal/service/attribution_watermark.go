@@ -0,0 +1,418 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// 分组归属水印模式
+const (
+	AttributionModeFooter    = "footer"    // 在回复末尾追加可见页脚
+	AttributionModeInvisible = "invisible" // 在回复末尾追加零宽字符编码的来源信息
+)
+
+// DefaultAttributionFooterTemplate footer 模式未配置模板时使用的页脚。
+const DefaultAttributionFooterTemplate = "— Generated via {group} · {model} · {request_id}"
+
+// attributionTextMaxLen 页脚模板的最大字符数，与 groups.attribution_text 列宽一致。
+const attributionTextMaxLen = 500
+
+// attributionStreamMaxEventBytes 流式改写时单个 SSE 事件的缓冲上限，超出后放弃注入并原样透传。
+const attributionStreamMaxEventBytes = 1 << 20
+
+// 零宽水印字符：起止标记与比特 0 / 1
+const (
+	attributionInvisibleMarker = '\u2060'
+	attributionInvisibleZero   = '\u200b'
+	attributionInvisibleOne    = '\u200c'
+)
+
+var (
+	ErrInvalidAttributionMode = infraerrors.BadRequest("INVALID_ATTRIBUTION_MODE", "attribution_mode must be empty, footer or invisible")
+	ErrAttributionTextTooLong = infraerrors.BadRequest("ATTRIBUTION_TEXT_TOO_LONG", "attribution_text must be at most 500 characters")
+)
+
+// NormalizeAttributionMode 规范化归属水印模式，空字符串表示不添加。
+func NormalizeAttributionMode(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "", AttributionModeFooter, AttributionModeInvisible:
+		return mode, nil
+	default:
+		return "", ErrInvalidAttributionMode
+	}
+}
+
+// NormalizeAttributionText 规范化页脚模板，空字符串表示使用默认模板。
+func NormalizeAttributionText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > attributionTextMaxLen {
+		return "", ErrAttributionTextTooLong
+	}
+	return text, nil
+}
+
+// AttributionWatermark 单次请求生效的归属水印。模型名取自响应体，在渲染时传入。
+type AttributionWatermark struct {
+	Mode      string
+	Template  string
+	GroupID   int64
+	GroupName string
+	RequestID string
+	Time      time.Time
+}
+
+// ResolveAttributionWatermark 按 API Key 所在分组的设置返回本次请求的水印；分组未开启时返回 nil。
+func ResolveAttributionWatermark(apiKey *APIKey, requestID string, now time.Time) *AttributionWatermark {
+	if apiKey == nil || apiKey.Group == nil {
+		return nil
+	}
+	group := apiKey.Group
+	switch group.AttributionMode {
+	case AttributionModeFooter, AttributionModeInvisible:
+	default:
+		return nil
+	}
+	return &AttributionWatermark{
+		Mode:      group.AttributionMode,
+		Template:  group.AttributionText,
+		GroupID:   group.ID,
+		GroupName: group.Name,
+		RequestID: requestID,
+		Time:      now.UTC(),
+	}
+}
+
+// Text 渲染追加到回复末尾的文本。
+func (w *AttributionWatermark) Text(model string) string {
+	if w.Mode == AttributionModeInvisible {
+		payload := "g=" + strconv.FormatInt(w.GroupID, 10) +
+			";r=" + w.RequestID +
+			";m=" + model +
+			";t=" + strconv.FormatInt(w.Time.Unix(), 10)
+		return EncodeInvisibleAttribution(payload)
+	}
+	template := w.Template
+	if template == "" {
+		template = DefaultAttributionFooterTemplate
+	}
+	footer := strings.NewReplacer(
+		"{request_id}", w.RequestID,
+		"{model}", model,
+		"{group}", w.GroupName,
+		"{timestamp}", w.Time.Format(time.RFC3339),
+	).Replace(template)
+	return "\n\n" + footer
+}
+
+// EncodeInvisibleAttribution 把 payload 逐比特编码为零宽字符（U+200B = 0，U+200C = 1），
+// 前后以 U+2060 标记，渲染时不可见但复制文本时会随之保留。
+func EncodeInvisibleAttribution(payload string) string {
+	var b strings.Builder
+	b.Grow((len(payload)*8 + 2) * 3)
+	b.WriteRune(attributionInvisibleMarker)
+	for i := 0; i < len(payload); i++ {
+		for bit := 7; bit >= 0; bit-- {
+			if payload[i]>>uint(bit)&1 == 1 {
+				b.WriteRune(attributionInvisibleOne)
+			} else {
+				b.WriteRune(attributionInvisibleZero)
+			}
+		}
+	}
+	b.WriteRune(attributionInvisibleMarker)
+	return b.String()
+}
+
+// DecodeInvisibleAttribution 从文本中提取第一段零宽水印，供溯源核查使用。
+func DecodeInvisibleAttribution(text string) (string, bool) {
+	start := strings.IndexRune(text, attributionInvisibleMarker)
+	if start < 0 {
+		return "", false
+	}
+	rest := text[start+utf8.RuneLen(attributionInvisibleMarker):]
+	end := strings.IndexRune(rest, attributionInvisibleMarker)
+	if end < 0 {
+		return "", false
+	}
+	var out []byte
+	var cur byte
+	bits := 0
+	for _, r := range rest[:end] {
+		switch r {
+		case attributionInvisibleZero:
+			cur <<= 1
+		case attributionInvisibleOne:
+			cur = cur<<1 | 1
+		default:
+			return "", false
+		}
+		bits++
+		if bits == 8 {
+			out = append(out, cur)
+			cur, bits = 0, 0
+		}
+	}
+	if bits != 0 || len(out) == 0 {
+		return "", false
+	}
+	return string(out), true
+}
+
+// ApplyAttributionWatermark 在非流式成功响应体的最后一段文本后追加水印；
+// format 取值同 ResponseLanguageFormatForPath。响应中没有文本内容时不改动。
+func ApplyAttributionWatermark(body []byte, format string, w *AttributionWatermark) ([]byte, bool) {
+	if w == nil || !gjson.ValidBytes(body) {
+		return body, false
+	}
+	mark := w.Text(gjson.GetBytes(body, "model").String())
+	switch format {
+	case ResponseLanguageFormatAnthropic:
+		return appendAttributionToLastText(body, "content", "text", "text", mark)
+	case ResponseLanguageFormatChatCompletions:
+		applied := false
+		for i, choice := range gjson.GetBytes(body, "choices").Array() {
+			content := choice.Get("message.content")
+			if content.Type != gjson.String || content.String() == "" {
+				continue
+			}
+			out, err := sjson.SetBytes(body, "choices."+strconv.Itoa(i)+".message.content", content.String()+mark)
+			if err != nil {
+				return body, false
+			}
+			body, applied = out, true
+		}
+		return body, applied
+	case ResponseLanguageFormatResponses:
+		output := gjson.GetBytes(body, "output").Array()
+		for i := len(output) - 1; i >= 0; i-- {
+			if output[i].Get("type").String() != "message" {
+				continue
+			}
+			return appendAttributionToLastText(body, "output."+strconv.Itoa(i)+".content", "output_text", "text", mark)
+		}
+		return body, false
+	default:
+		return body, false
+	}
+}
+
+// appendAttributionToLastText 在 arrayPath 数组中最后一个 type 为 blockType 的元素的 textField 后追加 mark。
+func appendAttributionToLastText(body []byte, arrayPath, blockType, textField, mark string) ([]byte, bool) {
+	blocks := gjson.GetBytes(body, arrayPath).Array()
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].Get("type").String() != blockType {
+			continue
+		}
+		path := arrayPath + "." + strconv.Itoa(i) + "." + textField
+		out, err := sjson.SetBytes(body, path, blocks[i].Get(textField).String()+mark)
+		if err != nil {
+			return body, false
+		}
+		return out, true
+	}
+	return body, false
+}
+
+// AttributionStreamRewriter 在 SSE 流的文本结束前插入一段携带水印的增量事件。
+// 上游事件按原字节透传，仅在插入点前追加新事件：
+//   - messages：在首个 message_delta 前插入新的 text content block（start / delta / stop）；
+//   - chat/completions：在首个带 finish_reason 的 chunk（或 [DONE]）前插入 delta.content chunk。
+//
+// 仅在流中出现过文本内容时注入，避免给纯工具调用的回复追加文本。
+type AttributionStreamRewriter struct {
+	format    string
+	watermark *AttributionWatermark
+
+	pending     []byte // 尚未遇到空行结束的事件原始字节
+	passthrough bool
+	injected    bool
+
+	sawText   bool
+	model     string
+	nextIndex int64  // messages：下一个可用的 content block 序号
+	chunkID   string // chat/completions：沿用上游 chunk 的 id / created
+	created   int64
+}
+
+// NewAttributionStreamRewriter 返回指定格式的流式改写器；不支持的格式返回 nil（调用方原样透传）。
+func NewAttributionStreamRewriter(format string, w *AttributionWatermark) *AttributionStreamRewriter {
+	if w == nil {
+		return nil
+	}
+	switch format {
+	case ResponseLanguageFormatAnthropic, ResponseLanguageFormatChatCompletions:
+		return &AttributionStreamRewriter{format: format, watermark: w}
+	default:
+		return nil
+	}
+}
+
+// Write 输入一段上游输出，返回可立即写给客户端的字节（完整事件，必要时带有插入的水印事件）。
+func (r *AttributionStreamRewriter) Write(chunk []byte) []byte {
+	if r.passthrough {
+		return chunk
+	}
+	r.pending = append(r.pending, chunk...)
+	var out []byte
+	for {
+		end, sepLen := sseEventBoundary(r.pending)
+		if end < 0 {
+			break
+		}
+		event := r.pending[:end+sepLen]
+		out = append(out, r.rewriteEvent(event[:end])...)
+		out = append(out, event...)
+		r.pending = r.pending[end+sepLen:]
+	}
+	if len(r.pending) > attributionStreamMaxEventBytes {
+		r.passthrough = true
+		out = append(out, r.pending...)
+		r.pending = nil
+	}
+	// 复制剩余半个事件，避免后续 append 覆盖已返回给调用方的切片
+	r.pending = append([]byte(nil), r.pending...)
+	return out
+}
+
+// Close 返回流结束时尚未成帧的残留字节。
+func (r *AttributionStreamRewriter) Close() []byte {
+	rest := r.pending
+	r.pending = nil
+	return rest
+}
+
+// sseEventBoundary 返回第一个事件分隔空行的位置与分隔符长度。
+func sseEventBoundary(buf []byte) (int, int) {
+	lf := bytes.Index(buf, []byte("\n\n"))
+	crlf := bytes.Index(buf, []byte("\r\n\r\n"))
+	switch {
+	case crlf >= 0 && (lf < 0 || crlf < lf):
+		return crlf, 4
+	case lf >= 0:
+		return lf, 2
+	default:
+		return -1, 0
+	}
+}
+
+// rewriteEvent 观察一个事件并返回需要插在它之前的水印事件（无需注入时返回 nil）。
+func (r *AttributionStreamRewriter) rewriteEvent(event []byte) []byte {
+	if r.injected {
+		return nil
+	}
+	var data []byte
+	for _, line := range bytes.Split(event, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if value, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.TrimPrefix(value, []byte(" "))...)
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if r.format == ResponseLanguageFormatAnthropic {
+		return r.observeAnthropic(data)
+	}
+	return r.observeChatCompletions(data)
+}
+
+func (r *AttributionStreamRewriter) observeAnthropic(data []byte) []byte {
+	payload := gjson.ParseBytes(data)
+	switch payload.Get("type").String() {
+	case "message_start":
+		r.model = payload.Get("message.model").String()
+	case "content_block_start":
+		if index := payload.Get("index").Int(); index >= r.nextIndex {
+			r.nextIndex = index + 1
+		}
+		if payload.Get("content_block.type").String() == "text" {
+			r.sawText = true
+		}
+	case "message_delta":
+		if !r.sawText {
+			return nil
+		}
+		r.injected = true
+		index := r.nextIndex
+		var out []byte
+		out = appendSSEEvent(out, "content_block_start", map[string]any{
+			"type":          "content_block_start",
+			"index":         index,
+			"content_block": map[string]any{"type": "text", "text": ""},
+		})
+		out = appendSSEEvent(out, "content_block_delta", map[string]any{
+			"type":  "content_block_delta",
+			"index": index,
+			"delta": map[string]any{"type": "text_delta", "text": r.watermark.Text(r.model)},
+		})
+		out = appendSSEEvent(out, "content_block_stop", map[string]any{
+			"type":  "content_block_stop",
+			"index": index,
+		})
+		return out
+	}
+	return nil
+}
+
+func (r *AttributionStreamRewriter) observeChatCompletions(data []byte) []byte {
+	done := bytes.Equal(bytes.TrimSpace(data), []byte("[DONE]"))
+	if !done {
+		payload := gjson.ParseBytes(data)
+		if model := payload.Get("model").String(); model != "" {
+			r.model = model
+		}
+		if id := payload.Get("id").String(); id != "" {
+			r.chunkID = id
+		}
+		if created := payload.Get("created").Int(); created > 0 {
+			r.created = created
+		}
+		choice := payload.Get("choices.0")
+		if choice.Get("delta.content").String() != "" {
+			r.sawText = true
+		}
+		if finish := choice.Get("finish_reason"); !finish.Exists() || finish.Type == gjson.Null {
+			return nil
+		}
+	}
+	if !r.sawText {
+		return nil
+	}
+	r.injected = true
+	return appendSSEEvent(nil, "", map[string]any{
+		"id":      r.chunkID,
+		"object":  "chat.completion.chunk",
+		"created": r.created,
+		"model":   r.model,
+		"choices": []any{map[string]any{
+			"index":         0,
+			"delta":         map[string]any{"content": r.watermark.Text(r.model)},
+			"finish_reason": nil,
+		}},
+	})
+}
+
+// appendSSEEvent 以 SSE 格式追加一个事件；name 为空时省略 event 行。
+func appendSSEEvent(out []byte, name string, payload map[string]any) []byte {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return out
+	}
+	if name != "" {
+		out = append(out, "event: "...)
+		out = append(out, name...)
+		out = append(out, '\n')
+	}
+	out = append(out, "data: "...)
+	out = append(out, data...)
+	return append(out, '\n', '\n')
+}
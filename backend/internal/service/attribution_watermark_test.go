@@ -0,0 +1,143 @@
+//go:build unit
+
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func testAttributionWatermark(mode string) *AttributionWatermark {
+	return ResolveAttributionWatermark(&APIKey{Group: &Group{
+		ID:              7,
+		Name:            "reseller",
+		AttributionMode: mode,
+	}}, "req-1", time.Unix(1760000000, 0))
+}
+
+func TestNormalizeAttributionSettings(t *testing.T) {
+	mode, err := NormalizeAttributionMode(" Footer ")
+	require.NoError(t, err)
+	require.Equal(t, AttributionModeFooter, mode)
+
+	_, err = NormalizeAttributionMode("xmp")
+	require.ErrorIs(t, err, ErrInvalidAttributionMode)
+
+	_, err = NormalizeAttributionText(strings.Repeat("字", attributionTextMaxLen+1))
+	require.ErrorIs(t, err, ErrAttributionTextTooLong)
+}
+
+func TestResolveAttributionWatermark_DisabledGroup(t *testing.T) {
+	require.Nil(t, ResolveAttributionWatermark(nil, "req-1", time.Now()))
+	require.Nil(t, ResolveAttributionWatermark(&APIKey{Group: &Group{}}, "req-1", time.Now()))
+}
+
+func TestAttributionWatermark_FooterTemplate(t *testing.T) {
+	w := testAttributionWatermark(AttributionModeFooter)
+	require.Equal(t, "\n\n— Generated via reseller · claude-sonnet-4 · req-1", w.Text("claude-sonnet-4"))
+
+	w.Template = "[{group}] {timestamp}"
+	require.Equal(t, "\n\n[reseller] 2025-10-09T08:53:20Z", w.Text("m"))
+}
+
+func TestInvisibleAttribution_RoundTrip(t *testing.T) {
+	w := testAttributionWatermark(AttributionModeInvisible)
+	mark := w.Text("gpt-5")
+	for _, r := range mark {
+		require.Contains(t, []rune{attributionInvisibleMarker, attributionInvisibleZero, attributionInvisibleOne}, r)
+	}
+
+	payload, ok := DecodeInvisibleAttribution("answer" + mark + " trailing")
+	require.True(t, ok)
+	require.Equal(t, "g=7;r=req-1;m=gpt-5;t=1760000000", payload)
+
+	_, ok = DecodeInvisibleAttribution("no watermark")
+	require.False(t, ok)
+}
+
+func TestApplyAttributionWatermark_NonStreaming(t *testing.T) {
+	w := testAttributionWatermark(AttributionModeFooter)
+
+	body := `{"model":"claude","content":[{"type":"text","text":"a"},{"type":"tool_use","id":"t"},{"type":"text","text":"b"}]}`
+	out, ok := ApplyAttributionWatermark([]byte(body), ResponseLanguageFormatAnthropic, w)
+	require.True(t, ok)
+	require.Equal(t, "a", gjson.GetBytes(out, "content.0.text").String())
+	require.Equal(t, "b"+w.Text("claude"), gjson.GetBytes(out, "content.2.text").String())
+
+	body = `{"model":"gpt","choices":[{"message":{"content":"hi"}},{"message":{"content":null,"tool_calls":[]}}]}`
+	out, ok = ApplyAttributionWatermark([]byte(body), ResponseLanguageFormatChatCompletions, w)
+	require.True(t, ok)
+	require.Equal(t, "hi"+w.Text("gpt"), gjson.GetBytes(out, "choices.0.message.content").String())
+	require.Equal(t, gjson.Null, gjson.GetBytes(out, "choices.1.message.content").Type)
+
+	body = `{"model":"gpt","output":[{"type":"message","content":[{"type":"output_text","text":"x"}]},{"type":"reasoning"}]}`
+	out, ok = ApplyAttributionWatermark([]byte(body), ResponseLanguageFormatResponses, w)
+	require.True(t, ok)
+	require.Equal(t, "x"+w.Text("gpt"), gjson.GetBytes(out, "output.0.content.0.text").String())
+
+	body = `{"model":"claude","content":[{"type":"tool_use","id":"t"}]}`
+	_, ok = ApplyAttributionWatermark([]byte(body), ResponseLanguageFormatAnthropic, w)
+	require.False(t, ok)
+}
+
+func feedAttributionRewriter(r *AttributionStreamRewriter, stream string, size int) string {
+	var out []byte
+	for len(stream) > size {
+		out = append(out, r.Write([]byte(stream[:size]))...)
+		stream = stream[size:]
+	}
+	out = append(out, r.Write([]byte(stream))...)
+	return string(append(out, r.Close()...))
+}
+
+func TestAttributionStreamRewriter_Anthropic(t *testing.T) {
+	w := testAttributionWatermark(AttributionModeFooter)
+	stream := "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude\"}}\n\n" +
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\n" +
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n" +
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+
+	for _, size := range []int{1, 7, len(stream)} {
+		got := feedAttributionRewriter(NewAttributionStreamRewriter(ResponseLanguageFormatAnthropic, w), stream, size)
+		events := strings.Split(strings.TrimSuffix(got, "\n\n"), "\n\n")
+		require.Len(t, events, 9, "chunk size %d", size)
+		require.True(t, strings.HasPrefix(events[4], "event: content_block_start\n"))
+		delta := gjson.Parse(strings.TrimPrefix(strings.SplitN(events[5], "\n", 2)[1], "data: "))
+		require.EqualValues(t, 1, delta.Get("index").Int())
+		require.Equal(t, w.Text("claude"), delta.Get("delta.text").String())
+		require.True(t, strings.HasPrefix(events[7], "event: message_delta\n"))
+	}
+}
+
+func TestAttributionStreamRewriter_AnthropicToolOnly(t *testing.T) {
+	w := testAttributionWatermark(AttributionModeFooter)
+	stream := "event: content_block_start\r\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\"}}\r\n\r\n" +
+		"event: message_delta\r\ndata: {\"type\":\"message_delta\"}\r\n\r\n"
+	got := feedAttributionRewriter(NewAttributionStreamRewriter(ResponseLanguageFormatAnthropic, w), stream, 5)
+	require.Equal(t, stream, got)
+}
+
+func TestAttributionStreamRewriter_ChatCompletions(t *testing.T) {
+	w := testAttributionWatermark(AttributionModeInvisible)
+	stream := "data: {\"id\":\"c1\",\"created\":5,\"model\":\"gpt\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"},\"finish_reason\":null}]}\n\n" +
+		"data: {\"id\":\"c1\",\"created\":5,\"model\":\"gpt\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n" +
+		"data: [DONE]\n\n"
+	got := feedAttributionRewriter(NewAttributionStreamRewriter(ResponseLanguageFormatChatCompletions, w), stream, 3)
+	events := strings.Split(strings.TrimSuffix(got, "\n\n"), "\n\n")
+	require.Len(t, events, 4)
+	injected := gjson.Parse(strings.TrimPrefix(events[1], "data: "))
+	require.Equal(t, "c1", injected.Get("id").String())
+	require.Equal(t, "chat.completion.chunk", injected.Get("object").String())
+	payload, ok := DecodeInvisibleAttribution(injected.Get("choices.0.delta.content").String())
+	require.True(t, ok)
+	require.Equal(t, "g=7;r=req-1;m=gpt;t=1760000000", payload)
+	require.Contains(t, events[2], `"finish_reason":"stop"`)
+
+	require.Nil(t, NewAttributionStreamRewriter(ResponseLanguageFormatResponses, w))
+}
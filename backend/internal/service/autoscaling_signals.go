@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// slotWaitSampleCapacity 槽位排队等待耗时样本环的容量，超出后覆盖最旧样本
+const slotWaitSampleCapacity = 4096
+
+// AutoscalingPendingUnassigned 尚未调度到账号（平台未知）的在途请求使用的平台标签
+const AutoscalingPendingUnassigned = "unassigned"
+
+// AutoscalingSignals 面向 HPA/KEDA 的负载信号快照。
+type AutoscalingSignals struct {
+	// 账号槽位占用（跨实例共享）；运维监控关闭或统计失败时 SlotStatsAvailable 为 false
+	SlotStatsAvailable bool
+	SlotsInUse         int64
+	SlotsCapacity      int64
+	SlotsByPlatform    []AutoscalingPlatformSlots
+
+	// 本实例槽位排队等待 p95 与窗口内样本数
+	QueueWaitP95     time.Duration
+	QueueWaitSamples int
+	// 按请求优先级（X-Priority）拆分的排队等待 p95、样本数与累计请求数
+	QueueWaitByPriority []AutoscalingPriorityQueueWait
+
+	// 本实例用量记录 worker 池
+	WorkerPoolRunning  int64
+	WorkerPoolCapacity int
+	WorkerPoolQueued   uint64
+
+	// 本实例在途请求（按平台）
+	PendingByPlatform []AutoscalingPlatformPending
+
+	// 本实例槽位等待准入控制（gateway.admission_control）
+	Admission RequestAdmissionStats
+}
+
+// AutoscalingPlatformSlots 单个平台的槽位占用。
+type AutoscalingPlatformSlots struct {
+	Platform string
+	InUse    int64
+	Capacity int64
+}
+
+// AutoscalingPriorityQueueWait 单个请求优先级的排队等待 p95、窗口内样本数与累计请求数。
+type AutoscalingPriorityQueueWait struct {
+	Priority     string
+	QueueWaitP95 time.Duration
+	Samples      int
+	Requests     int64
+}
+
+// AutoscalingPlatformPending 单个平台的在途请求数与其中排队等待槽位的请求数。
+type AutoscalingPlatformPending struct {
+	Platform    string
+	Pending     int
+	WaitingSlot int
+}
+
+// SlotUtilization 全部平台的槽位占用率，容量为 0 时返回 0。
+func (s *AutoscalingSignals) SlotUtilization() float64 {
+	return utilizationRatio(s.SlotsInUse, s.SlotsCapacity)
+}
+
+// Utilization 该平台的槽位占用率，容量为 0 时返回 0。
+func (p AutoscalingPlatformSlots) Utilization() float64 {
+	return utilizationRatio(p.InUse, p.Capacity)
+}
+
+// WorkerPoolSaturation worker 池饱和度（运行中 worker / 最大并发），池未启用时返回 0。
+func (s *AutoscalingSignals) WorkerPoolSaturation() float64 {
+	return utilizationRatio(s.WorkerPoolRunning, int64(s.WorkerPoolCapacity))
+}
+
+// AutoscalingSignalsService 汇总网关负载信号，供 Kubernetes 按网关负载而非 CPU 伸缩副本。
+type AutoscalingSignalsService struct {
+	opsService      *OpsService
+	workerPool      *UsageRecordWorkerPool
+	activeRequests  *ActiveRequestRegistry
+	queueWaitWindow time.Duration
+	slotStatsTTL    time.Duration
+	now             func() time.Time
+
+	slotMu        sync.Mutex
+	slotCached    *autoscalingSlotStats
+	slotExpiresAt time.Time
+}
+
+type autoscalingSlotStats struct {
+	inUse      int64
+	capacity   int64
+	byPlatform []AutoscalingPlatformSlots
+}
+
+// NewAutoscalingSignalsService creates an AutoscalingSignalsService.
+func NewAutoscalingSignalsService(cfg *config.Config, opsService *OpsService, workerPool *UsageRecordWorkerPool, activeRequests *ActiveRequestRegistry) *AutoscalingSignalsService {
+	svc := &AutoscalingSignalsService{
+		opsService:      opsService,
+		workerPool:      workerPool,
+		activeRequests:  activeRequests,
+		queueWaitWindow: 5 * time.Minute,
+		slotStatsTTL:    10 * time.Second,
+		now:             time.Now,
+	}
+	if cfg != nil {
+		if cfg.AutoscalingMetrics.QueueWaitWindowSeconds > 0 {
+			svc.queueWaitWindow = time.Duration(cfg.AutoscalingMetrics.QueueWaitWindowSeconds) * time.Second
+		}
+		if cfg.AutoscalingMetrics.SlotStatsCacheSeconds >= 0 {
+			svc.slotStatsTTL = time.Duration(cfg.AutoscalingMetrics.SlotStatsCacheSeconds) * time.Second
+		}
+	}
+	return svc
+}
+
+// Collect 采集当前信号。槽位统计失败不影响其它信号。
+func (s *AutoscalingSignalsService) Collect(ctx context.Context) *AutoscalingSignals {
+	out := &AutoscalingSignals{}
+	if slots := s.slotStats(ctx); slots != nil {
+		out.SlotStatsAvailable = true
+		out.SlotsInUse = slots.inUse
+		out.SlotsCapacity = slots.capacity
+		out.SlotsByPlatform = slots.byPlatform
+	}
+
+	stats := s.workerPool.Stats()
+	out.WorkerPoolRunning = stats.RunningWorkers
+	out.WorkerPoolCapacity = stats.MaxConcurrency
+	out.WorkerPoolQueued = stats.WaitingTasks
+
+	if s.activeRequests != nil {
+		since := s.now().Add(-s.queueWaitWindow)
+		out.QueueWaitP95, out.QueueWaitSamples = s.activeRequests.SlotWaitQuantile(since, 0.95)
+		requests := s.activeRequests.RequestsByPriority()
+		for _, priority := range RequestPriorities {
+			item := AutoscalingPriorityQueueWait{Priority: priority, Requests: requests[priority]}
+			item.QueueWaitP95, item.Samples = s.activeRequests.SlotWaitQuantileByPriority(priority, since, 0.95)
+			out.QueueWaitByPriority = append(out.QueueWaitByPriority, item)
+		}
+		out.PendingByPlatform = pendingByPlatform(s.activeRequests.List())
+		out.Admission = s.activeRequests.AdmissionStats()
+	}
+	return out
+}
+
+func (s *AutoscalingSignalsService) slotStats(ctx context.Context) *autoscalingSlotStats {
+	if s.opsService == nil || !s.opsService.IsMonitoringEnabled(ctx) {
+		return nil
+	}
+	s.slotMu.Lock()
+	defer s.slotMu.Unlock()
+	now := s.now()
+	if s.slotCached != nil && now.Before(s.slotExpiresAt) {
+		return s.slotCached
+	}
+	platforms, _, _, _, err := s.opsService.GetConcurrencyStats(ctx, "", nil)
+	if err != nil {
+		logger.LegacyPrintf("service.autoscaling", "[Autoscaling] concurrency stats unavailable: %v", err)
+		return s.slotCached
+	}
+	stats := &autoscalingSlotStats{byPlatform: make([]AutoscalingPlatformSlots, 0, len(platforms))}
+	for name, info := range platforms {
+		if info == nil {
+			continue
+		}
+		stats.inUse += info.CurrentInUse
+		stats.capacity += info.MaxCapacity
+		stats.byPlatform = append(stats.byPlatform, AutoscalingPlatformSlots{Platform: name, InUse: info.CurrentInUse, Capacity: info.MaxCapacity})
+	}
+	sort.Slice(stats.byPlatform, func(i, j int) bool { return stats.byPlatform[i].Platform < stats.byPlatform[j].Platform })
+	s.slotCached = stats
+	s.slotExpiresAt = now.Add(s.slotStatsTTL)
+	return stats
+}
+
+func pendingByPlatform(items []ActiveRequestSnapshot) []AutoscalingPlatformPending {
+	index := make(map[string]int)
+	out := make([]AutoscalingPlatformPending, 0)
+	for _, item := range items {
+		platform := item.Platform
+		if platform == "" {
+			platform = AutoscalingPendingUnassigned
+		}
+		i, ok := index[platform]
+		if !ok {
+			i = len(out)
+			index[platform] = i
+			out = append(out, AutoscalingPlatformPending{Platform: platform})
+		}
+		out[i].Pending++
+		if item.State == ActiveRequestStateWaitingSlot {
+			out[i].WaitingSlot++
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Platform < out[j].Platform })
+	return out
+}
+
+func utilizationRatio(used, capacity int64) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	return float64(used) / float64(capacity)
+}
+
+// slotWaitSamples 固定容量的槽位排队等待耗时样本环（goroutine-safe）。
+type slotWaitSamples struct {
+	mu      sync.Mutex
+	at      []time.Time
+	waits   []time.Duration
+	next    int
+	written int
+}
+
+func newSlotWaitSamples(capacity int) *slotWaitSamples {
+	return &slotWaitSamples{at: make([]time.Time, capacity), waits: make([]time.Duration, capacity)}
+}
+
+func (s *slotWaitSamples) record(at time.Time, wait time.Duration) {
+	if s == nil || len(s.at) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.at[s.next] = at
+	s.waits[s.next] = wait
+	s.next = (s.next + 1) % len(s.at)
+	if s.written < len(s.at) {
+		s.written++
+	}
+	s.mu.Unlock()
+}
+
+// quantile 返回 since 之后记录的样本的 q 分位数（nearest-rank）与样本数。
+func (s *slotWaitSamples) quantile(since time.Time, q float64) (time.Duration, int) {
+	if s == nil {
+		return 0, 0
+	}
+	s.mu.Lock()
+	values := make([]time.Duration, 0, s.written)
+	for i := 0; i < s.written; i++ {
+		if !s.at[i].Before(since) {
+			values = append(values, s.waits[i])
+		}
+	}
+	s.mu.Unlock()
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	rank := int(math.Ceil(q*float64(len(values)))) - 1
+	rank = max(0, min(rank, len(values)-1))
+	return values[rank], len(values)
+}
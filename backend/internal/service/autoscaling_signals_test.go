@@ -0,0 +1,71 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlotWaitSamples_QuantileWithinWindow(t *testing.T) {
+	samples := newSlotWaitSamples(8)
+	base := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	samples.record(base, time.Hour) // 窗口外
+	for i := 1; i <= 20; i++ {
+		samples.record(base.Add(time.Minute), time.Duration(i)*time.Second)
+	}
+
+	p95, n := samples.quantile(base.Add(time.Second), 0.95)
+	require.Equal(t, 8, n, "ring keeps only the newest samples")
+	require.Equal(t, 20*time.Second, p95)
+
+	p50, _ := samples.quantile(base.Add(time.Second), 0.5)
+	require.Equal(t, 16*time.Second, p50)
+
+	_, n = samples.quantile(base.Add(time.Hour), 0.95)
+	require.Zero(t, n)
+}
+
+func TestAutoscalingSignals_CollectFromRegistry(t *testing.T) {
+	registry := NewActiveRequestRegistry(0)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+
+	waited, _ := registry.Begin(context.Background(), ActiveRequestInfo{})
+	done := waited.BeginWait()
+	now = now.Add(3 * time.Second)
+	done()
+	waited.SetAccount(1, PlatformAnthropic)
+
+	queued, _ := registry.Begin(context.Background(), ActiveRequestInfo{})
+	queued.BeginWait()
+	registry.Begin(context.Background(), ActiveRequestInfo{})
+
+	svc := NewAutoscalingSignalsService(&config.Config{AutoscalingMetrics: config.AutoscalingMetricsConfig{QueueWaitWindowSeconds: 60}}, nil, nil, registry)
+	svc.now = registry.now
+
+	signals := svc.Collect(context.Background())
+	require.False(t, signals.SlotStatsAvailable)
+	require.Equal(t, 3*time.Second, signals.QueueWaitP95)
+	require.Equal(t, 1, signals.QueueWaitSamples)
+	require.Zero(t, signals.WorkerPoolSaturation())
+	require.Equal(t, []AutoscalingPlatformPending{
+		{Platform: PlatformAnthropic, Pending: 1},
+		{Platform: AutoscalingPendingUnassigned, Pending: 2, WaitingSlot: 1},
+	}, signals.PendingByPlatform)
+
+	now = now.Add(2 * time.Minute)
+	signals = svc.Collect(context.Background())
+	require.Zero(t, signals.QueueWaitSamples)
+}
+
+func TestAutoscalingSignals_Ratios(t *testing.T) {
+	signals := &AutoscalingSignals{SlotsInUse: 3, SlotsCapacity: 4, WorkerPoolRunning: 2, WorkerPoolCapacity: 8}
+	require.InDelta(t, 0.75, signals.SlotUtilization(), 1e-9)
+	require.InDelta(t, 0.25, signals.WorkerPoolSaturation(), 1e-9)
+	require.Zero(t, AutoscalingPlatformSlots{InUse: 2}.Utilization())
+}
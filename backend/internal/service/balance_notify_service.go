@@ -43,6 +43,7 @@ type BalanceNotifyService struct {
 	settingRepo              SettingRepository
 	accountRepo              AccountQuotaReader
 	notificationEmailService *NotificationEmailService
+	userNotifier             UserNotifier
 }
 
 // NewBalanceNotifyService creates a new BalanceNotifyService.
@@ -58,6 +59,11 @@ func (s *BalanceNotifyService) SetNotificationEmailService(notificationEmailServ
 	s.notificationEmailService = notificationEmailService
 }
 
+// SetUserNotifier 注入站内通知：余额跌破阈值时除邮件外还会生成一条站内通知。
+func (s *BalanceNotifyService) SetUserNotifier(notifier UserNotifier) {
+	s.userNotifier = notifier
+}
+
 // resolveBalanceThreshold returns the effective balance threshold.
 // For percentage type, it computes threshold = totalRecharged * percentage / 100.
 func resolveBalanceThreshold(threshold float64, thresholdType string, totalRecharged float64) float64 {
@@ -69,8 +75,11 @@ func resolveBalanceThreshold(threshold float64, thresholdType string, totalRecha
 
 // CheckBalanceAfterDeduction checks if balance crossed below threshold after deduction.
 // Notification is sent only on first crossing: oldBalance >= threshold && newBalance < threshold.
+// The in-app notification only depends on the global switch; the email additionally
+// requires the user-level toggle.
 func (s *BalanceNotifyService) CheckBalanceAfterDeduction(ctx context.Context, user *User, oldBalance, cost float64) {
-	if !s.canNotifyBalance(user) {
+	sendEmail := s.canNotifyBalance(user)
+	if !sendEmail && !s.canNotifyBalanceInApp(user) {
 		return
 	}
 	effectiveThreshold, rechargeURL, ok := s.resolveUserEffectiveThreshold(ctx, user)
@@ -81,7 +90,12 @@ func (s *BalanceNotifyService) CheckBalanceAfterDeduction(ctx context.Context, u
 	if !crossedDownward(oldBalance, newBalance, effectiveThreshold) {
 		return
 	}
-	s.dispatchBalanceLowEmail(ctx, user, newBalance, effectiveThreshold, rechargeURL)
+	if s.userNotifier != nil {
+		s.userNotifier.NotifyAsync(buildBalanceLowNotification(user, newBalance, effectiveThreshold, rechargeURL))
+	}
+	if sendEmail {
+		s.dispatchBalanceLowEmail(ctx, user, newBalance, effectiveThreshold, rechargeURL)
+	}
 }
 
 // canNotifyBalance checks nil guards and user-level toggle.
@@ -92,6 +106,31 @@ func (s *BalanceNotifyService) canNotifyBalance(user *User) bool {
 	return user.BalanceNotifyEnabled
 }
 
+// canNotifyBalanceInApp checks nil guards for the in-app notification path.
+func (s *BalanceNotifyService) canNotifyBalanceInApp(user *User) bool {
+	return user != nil && s.userNotifier != nil && s.settingRepo != nil
+}
+
+// buildBalanceLowNotification builds the in-app notice for a balance threshold crossing.
+// Each crossing produces a new notice, so no dedup key is set.
+func buildBalanceLowNotification(user *User, newBalance, threshold float64, rechargeURL string) UserNotificationInput {
+	metadata := map[string]any{
+		"balance":   newBalance,
+		"threshold": threshold,
+	}
+	if rechargeURL != "" {
+		metadata["recharge_url"] = rechargeURL
+	}
+	return UserNotificationInput{
+		UserID:   user.ID,
+		Type:     UserNotificationTypeBalanceLow,
+		Level:    UserNotificationLevelWarning,
+		Title:    "Balance is running low",
+		Content:  fmt.Sprintf("Your balance dropped to %.2f, below the alert threshold of %.2f. Top up to keep your API keys working.", newBalance, threshold),
+		Metadata: metadata,
+	}
+}
+
 // resolveUserEffectiveThreshold reads global + user config, returns the effective threshold.
 // Returns ok=false when notifications should be skipped.
 func (s *BalanceNotifyService) resolveUserEffectiveThreshold(ctx context.Context, user *User) (effectiveThreshold float64, rechargeURL string, ok bool) {
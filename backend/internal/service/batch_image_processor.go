@@ -53,6 +53,7 @@ type BatchImageProviderProcessor struct {
 	Indexer          *BatchImageResultIndexer
 	BillingRepo      UsageBillingRepository
 	AuthCache        APIKeyAuthCacheInvalidator
+	Notifier         UserNotifier
 	DefaultRequeue   time.Duration
 }
 
@@ -157,6 +158,7 @@ func (p *BatchImageProviderProcessor) Process(ctx context.Context, batchID strin
 		if err := p.releaseTerminalHold(ctx, job); err != nil {
 			return BatchImageProcessResult{}, err
 		}
+		p.notifyJobFailed(job, code, msg)
 		return BatchImageProcessResult{Terminal: true}, nil
 	case BatchProviderStateCancelled:
 		if err := p.Repo.TransitionBatchImageJobStatus(ctx, job.BatchID, BatchImageJobStatusCancelled, BatchImageTransitionOptions{
@@ -680,3 +682,27 @@ func truncateBatchImageMessage(message string, limit int) string {
 	}
 	return message[:limit]
 }
+
+// notifyJobFailed 为任务所属用户生成一条批量图片任务失败的站内通知。
+func (p *BatchImageProviderProcessor) notifyJobFailed(job *BatchImageJob, code, msg string) {
+	if p.Notifier == nil || job == nil || job.UserID <= 0 {
+		return
+	}
+	content := fmt.Sprintf("Batch image job %s failed (%s).", job.BatchID, code)
+	if msg != "" {
+		content = fmt.Sprintf("Batch image job %s failed (%s): %s", job.BatchID, code, msg)
+	}
+	p.Notifier.NotifyAsync(UserNotificationInput{
+		UserID:  job.UserID,
+		Type:    UserNotificationTypeTaskFailed,
+		Level:   UserNotificationLevelError,
+		Title:   "Batch image job failed",
+		Content: content,
+		Metadata: map[string]any{
+			"task_id":    job.BatchID,
+			"task_kind":  "batch_image",
+			"error_code": code,
+		},
+		DedupKey: "batch_image_failed:" + job.BatchID,
+	})
+}
@@ -30,6 +30,8 @@ func (s *GatewayService) handleBedrockStreamingResponse(
 	startTime time.Time,
 	model string,
 ) (*streamingResult, error) {
+	restoreWriter := guardStreamClient(c, s.cfg, resp)
+	defer restoreWriter()
 	w := c.Writer
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// BetaFeaturesHeader 客户端按请求启用实验性网关功能的请求头，值为逗号分隔的功能名。
+// 只能启用管理员为该 Key 授权（APIKey.BetaFeatures）的功能；生效的功能记入使用记录，便于灰度对比。
+const BetaFeaturesHeader = "X-Sub2API-Beta"
+
+// 可按请求启用的实验性功能
+const (
+	// BetaFeatureStrictValidation 转发前按端点 schema 校验请求体（等同 Key 级 strict_validation）
+	BetaFeatureStrictValidation = "strict-validation"
+	// BetaFeatureAnnotations 在响应中输出网关注解头（等同 Key 级 annotations_enabled）
+	BetaFeatureAnnotations = "annotations"
+)
+
+// BetaFeatures 全部已知的实验性功能。
+var BetaFeatures = []string{BetaFeatureStrictValidation, BetaFeatureAnnotations}
+
+var (
+	ErrInvalidBetaFeature     = infraerrors.BadRequest("INVALID_BETA_FEATURE", "unknown beta feature")
+	ErrBetaFeatureNotEntitled = infraerrors.Forbidden("BETA_FEATURE_NOT_ENTITLED", "beta feature is not enabled for this API key")
+)
+
+type betaFeaturesContextKey struct{}
+
+// NormalizeBetaFeatures 校验并规范化功能名列表（小写、去重、排序）；包含未知功能时返回 ErrInvalidBetaFeature。
+func NormalizeBetaFeatures(features []string) ([]string, error) {
+	out := make([]string, 0, len(features))
+	for _, feature := range features {
+		feature = strings.ToLower(strings.TrimSpace(feature))
+		if feature == "" {
+			continue
+		}
+		if !slices.Contains(BetaFeatures, feature) {
+			return nil, infraerrors.Newf(http.StatusBadRequest, ErrInvalidBetaFeature.Reason, "unknown beta feature %q", feature)
+		}
+		if !slices.Contains(out, feature) {
+			out = append(out, feature)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// ResolveBetaFeatures 解析 X-Sub2API-Beta 请求头，并校验每个功能都已授权给该 Key。
+// 请求头为空时返回 nil；包含未知功能返回 ErrInvalidBetaFeature，包含未授权功能返回 ErrBetaFeatureNotEntitled。
+func ResolveBetaFeatures(header string, apiKey *APIKey) ([]string, error) {
+	features, err := NormalizeBetaFeatures(strings.Split(header, ","))
+	if err != nil || len(features) == 0 {
+		return nil, err
+	}
+	for _, feature := range features {
+		if apiKey == nil || !slices.Contains(apiKey.BetaFeatures, feature) {
+			return nil, infraerrors.Newf(http.StatusForbidden, ErrBetaFeatureNotEntitled.Reason, "beta feature %q is not enabled for this API key", feature)
+		}
+	}
+	return features, nil
+}
+
+// WithBetaFeatures 在 context 中记录本次请求启用的实验性功能。
+func WithBetaFeatures(ctx context.Context, features []string) context.Context {
+	if len(features) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, betaFeaturesContextKey{}, features)
+}
+
+// BetaFeaturesFromContext 读取本次请求启用的实验性功能，未启用时返回 nil。
+func BetaFeaturesFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	features, _ := ctx.Value(betaFeaturesContextKey{}).([]string)
+	return features
+}
+
+// BetaFeatureEnabled 判断本次请求是否启用了指定实验性功能。
+func BetaFeatureEnabled(ctx context.Context, feature string) bool {
+	return slices.Contains(BetaFeaturesFromContext(ctx), feature)
+}
+
+// applyBetaFeaturesToUsageLog 把本次请求启用的实验性功能写入使用记录（逗号分隔）。
+func applyBetaFeaturesToUsageLog(ctx context.Context, usageLog *UsageLog) {
+	if usageLog == nil || usageLog.BetaFeatures != nil {
+		return
+	}
+	if features := BetaFeaturesFromContext(ctx); len(features) > 0 {
+		joined := strings.Join(features, ",")
+		usageLog.BetaFeatures = &joined
+	}
+}
@@ -0,0 +1,55 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeBetaFeatures(t *testing.T) {
+	got, err := NormalizeBetaFeatures([]string{" Annotations ", "strict-validation", "annotations", ""})
+	require.NoError(t, err)
+	require.Equal(t, []string{BetaFeatureAnnotations, BetaFeatureStrictValidation}, got)
+
+	_, err = NormalizeBetaFeatures([]string{"hedging"})
+	require.Error(t, err)
+	require.Equal(t, ErrInvalidBetaFeature.Reason, infraerrors.Reason(err))
+}
+
+func TestResolveBetaFeatures(t *testing.T) {
+	key := &APIKey{BetaFeatures: []string{BetaFeatureAnnotations}}
+
+	got, err := ResolveBetaFeatures("", key)
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	got, err = ResolveBetaFeatures("annotations, ANNOTATIONS", key)
+	require.NoError(t, err)
+	require.Equal(t, []string{BetaFeatureAnnotations}, got)
+
+	_, err = ResolveBetaFeatures("strict-validation", key)
+	require.Equal(t, ErrBetaFeatureNotEntitled.Reason, infraerrors.Reason(err))
+	require.Equal(t, 403, infraerrors.Code(err))
+
+	_, err = ResolveBetaFeatures("unknown", key)
+	require.Equal(t, ErrInvalidBetaFeature.Reason, infraerrors.Reason(err))
+
+	_, err = ResolveBetaFeatures("annotations", nil)
+	require.Equal(t, ErrBetaFeatureNotEntitled.Reason, infraerrors.Reason(err))
+}
+
+func TestApplyBetaFeaturesToUsageLog(t *testing.T) {
+	log := &UsageLog{}
+	applyBetaFeaturesToUsageLog(context.Background(), log)
+	require.Nil(t, log.BetaFeatures)
+
+	ctx := WithBetaFeatures(context.Background(), []string{BetaFeatureAnnotations, BetaFeatureStrictValidation})
+	require.True(t, BetaFeatureEnabled(ctx, BetaFeatureAnnotations))
+	applyBetaFeaturesToUsageLog(ctx, log)
+	require.NotNil(t, log.BetaFeatures)
+	require.Equal(t, "annotations,strict-validation", *log.BetaFeatures)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 计费调整类型
+const (
+	// BillingAdjustmentKindCredit 补偿（与具体请求无关的额度发放）
+	BillingAdjustmentKindCredit = "credit"
+	// BillingAdjustmentKindRefund 退款（通常对应一次已扣费但失败的请求）
+	BillingAdjustmentKindRefund = "refund"
+)
+
+var (
+	ErrBillingAdjustmentInvalidKind       = infraerrors.BadRequest("BILLING_ADJUSTMENT_INVALID_KIND", "adjustment kind must be credit or refund")
+	ErrBillingAdjustmentInvalidAmount     = infraerrors.BadRequest("BILLING_ADJUSTMENT_INVALID_AMOUNT", "adjustment amount must be positive")
+	ErrBillingAdjustmentUsageNeedsAPIKey  = infraerrors.BadRequest("BILLING_ADJUSTMENT_USAGE_NEEDS_API_KEY", "usage_request_id requires api_key_id")
+	ErrBillingAdjustmentAPIKeyMismatch    = infraerrors.BadRequest("BILLING_ADJUSTMENT_API_KEY_MISMATCH", "api key does not belong to user")
+	ErrBillingAdjustmentUsageNotFound     = infraerrors.NotFound("BILLING_ADJUSTMENT_USAGE_NOT_FOUND", "usage record not found for this api key")
+	ErrBillingAdjustmentExceedsUsageCost  = infraerrors.BadRequest("BILLING_ADJUSTMENT_EXCEEDS_USAGE_COST", "refund amount exceeds the billed cost of the request")
+	ErrBillingAdjustmentSubscriptionUsage = infraerrors.BadRequest("BILLING_ADJUSTMENT_SUBSCRIPTION_USAGE", "request was billed to a subscription and cannot be refunded to balance")
+	ErrBillingAdjustmentDuplicate         = infraerrors.Conflict("BILLING_ADJUSTMENT_DUPLICATE", "usage request has already been refunded")
+)
+
+// BillingAdjustment 一条计费调整台账记录（只追加）。
+type BillingAdjustment struct {
+	ID             int64     `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UserID         int64     `json:"user_id"`
+	APIKeyID       *int64    `json:"api_key_id,omitempty"`
+	Kind           string    `json:"kind"`
+	Amount         float64   `json:"amount"`
+	Reason         string    `json:"reason"`
+	Notes          string    `json:"notes,omitempty"`
+	UsageRequestID *string   `json:"usage_request_id,omitempty"`
+	BalanceBefore  float64   `json:"balance_before"`
+	BalanceAfter   float64   `json:"balance_after"`
+	// QuotaRestored 实际回退的 API Key 已用额度（quota_used 不会低于 0，因此可能小于 Amount）
+	QuotaRestored float64 `json:"quota_restored"`
+	CreatedBy     *int64  `json:"created_by,omitempty"`
+}
+
+// CreateBillingAdjustmentInput 创建计费调整的参数。
+type CreateBillingAdjustmentInput struct {
+	UserID   int64
+	APIKeyID *int64
+	Kind     string
+	Amount   float64
+	Reason   string
+	Notes    string
+	// UsageRequestID 关联的请求 ID（usage_logs.request_id），需同时指定 APIKeyID
+	UsageRequestID string
+	CreatedBy      *int64
+}
+
+// BillingAdjustmentFilter 台账列表查询条件。
+type BillingAdjustmentFilter struct {
+	Page     int
+	PageSize int
+
+	UserID    *int64
+	APIKeyID  *int64
+	Kind      string
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// BillingAdjustmentList 分页结果。
+type BillingAdjustmentList struct {
+	Items    []*BillingAdjustment
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// BillingAdjustmentRepository 计费调整持久化端口。
+type BillingAdjustmentRepository interface {
+	// Apply 在同一事务内写入台账、增加用户余额并回退 API Key 额度计数。
+	Apply(ctx context.Context, input *CreateBillingAdjustmentInput) (*BillingAdjustment, error)
+	List(ctx context.Context, filter *BillingAdjustmentFilter) (*BillingAdjustmentList, error)
+}
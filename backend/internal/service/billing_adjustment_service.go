@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// BillingAdjustmentService 管理员补偿/退款与用户可见的调整记录。
+type BillingAdjustmentService struct {
+	repo                 BillingAdjustmentRepository
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+	billingCacheService  *BillingCacheService
+}
+
+// NewBillingAdjustmentService creates a new BillingAdjustmentService
+func NewBillingAdjustmentService(repo BillingAdjustmentRepository, authCacheInvalidator APIKeyAuthCacheInvalidator, billingCacheService *BillingCacheService) *BillingAdjustmentService {
+	return &BillingAdjustmentService{
+		repo:                 repo,
+		authCacheInvalidator: authCacheInvalidator,
+		billingCacheService:  billingCacheService,
+	}
+}
+
+// Create 发放一笔补偿或退款，并失效受影响的余额/额度缓存。
+func (s *BillingAdjustmentService) Create(ctx context.Context, input *CreateBillingAdjustmentInput) (*BillingAdjustment, error) {
+	if input == nil {
+		return nil, ErrBillingAdjustmentInvalidAmount
+	}
+	input.Kind = strings.ToLower(strings.TrimSpace(input.Kind))
+	if input.Kind != BillingAdjustmentKindCredit && input.Kind != BillingAdjustmentKindRefund {
+		return nil, ErrBillingAdjustmentInvalidKind
+	}
+	if input.Amount <= 0 || math.IsNaN(input.Amount) || math.IsInf(input.Amount, 0) {
+		return nil, ErrBillingAdjustmentInvalidAmount
+	}
+	if input.UserID <= 0 {
+		return nil, ErrUserNotFound
+	}
+	if input.APIKeyID != nil && *input.APIKeyID <= 0 {
+		input.APIKeyID = nil
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	input.Notes = strings.TrimSpace(input.Notes)
+	input.UsageRequestID = strings.TrimSpace(input.UsageRequestID)
+	if input.UsageRequestID != "" && input.APIKeyID == nil {
+		return nil, ErrBillingAdjustmentUsageNeedsAPIKey
+	}
+
+	adj, err := s.repo.Apply(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateCaches(ctx, adj)
+	var apiKeyID int64
+	if adj.APIKeyID != nil {
+		apiKeyID = *adj.APIKeyID
+	}
+	logger.LegacyPrintf("service.billing_adjustment", "[BillingAdjustment] %s issued: id=%d user_id=%d api_key_id=%d amount=%.8f balance=%.8f->%.8f quota_restored=%.8f",
+		adj.Kind, adj.ID, adj.UserID, apiKeyID, adj.Amount, adj.BalanceBefore, adj.BalanceAfter, adj.QuotaRestored)
+	return adj, nil
+}
+
+func (s *BillingAdjustmentService) invalidateCaches(ctx context.Context, adj *BillingAdjustment) {
+	if s.authCacheInvalidator != nil {
+		// 认证快照包含余额与 Key 额度用量
+		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, adj.UserID)
+	}
+	if s.billingCacheService == nil {
+		return
+	}
+	go func() {
+		cacheCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.billingCacheService.InvalidateUserBalance(cacheCtx, adj.UserID); err != nil {
+			logger.LegacyPrintf("service.billing_adjustment", "invalidate user balance cache failed: user_id=%d err=%v", adj.UserID, err)
+		}
+		if adj.APIKeyID != nil && adj.QuotaRestored > 0 {
+			if err := s.billingCacheService.InvalidateAPIKeyRateLimit(cacheCtx, *adj.APIKeyID); err != nil {
+				logger.LegacyPrintf("service.billing_adjustment", "invalidate api key rate limit cache failed: api_key_id=%d err=%v", *adj.APIKeyID, err)
+			}
+		}
+	}()
+}
+
+// List 管理端分页查询台账。
+func (s *BillingAdjustmentService) List(ctx context.Context, filter *BillingAdjustmentFilter) (*BillingAdjustmentList, error) {
+	if filter == nil {
+		filter = &BillingAdjustmentFilter{}
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// ListForUser 分页查询当前用户的调整记录；不返回内部备注与操作人。
+func (s *BillingAdjustmentService) ListForUser(ctx context.Context, userID int64, filter *BillingAdjustmentFilter) (*BillingAdjustmentList, error) {
+	if filter == nil {
+		filter = &BillingAdjustmentFilter{}
+	}
+	filter.UserID = &userID
+	list, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list.Items {
+		item.Notes = ""
+		item.CreatedBy = nil
+	}
+	return list, nil
+}
@@ -0,0 +1,96 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type billingAdjustmentRepoStub struct {
+	applied []*CreateBillingAdjustmentInput
+	list    *BillingAdjustmentList
+	filter  *BillingAdjustmentFilter
+}
+
+func (r *billingAdjustmentRepoStub) Apply(_ context.Context, input *CreateBillingAdjustmentInput) (*BillingAdjustment, error) {
+	cp := *input
+	r.applied = append(r.applied, &cp)
+	return &BillingAdjustment{
+		ID:            1,
+		UserID:        input.UserID,
+		APIKeyID:      input.APIKeyID,
+		Kind:          input.Kind,
+		Amount:        input.Amount,
+		BalanceBefore: 1,
+		BalanceAfter:  1 + input.Amount,
+	}, nil
+}
+
+func (r *billingAdjustmentRepoStub) List(_ context.Context, filter *BillingAdjustmentFilter) (*BillingAdjustmentList, error) {
+	r.filter = filter
+	return r.list, nil
+}
+
+func TestBillingAdjustmentService_Create_Validation(t *testing.T) {
+	keyID := int64(3)
+	cases := []struct {
+		name  string
+		input *CreateBillingAdjustmentInput
+		want  error
+	}{
+		{"nil input", nil, ErrBillingAdjustmentInvalidAmount},
+		{"unknown kind", &CreateBillingAdjustmentInput{UserID: 1, Kind: "bonus", Amount: 1}, ErrBillingAdjustmentInvalidKind},
+		{"zero amount", &CreateBillingAdjustmentInput{UserID: 1, Kind: "credit"}, ErrBillingAdjustmentInvalidAmount},
+		{"negative amount", &CreateBillingAdjustmentInput{UserID: 1, Kind: "refund", Amount: -1}, ErrBillingAdjustmentInvalidAmount},
+		{"missing user", &CreateBillingAdjustmentInput{Kind: "credit", Amount: 1}, ErrUserNotFound},
+		{"usage without key", &CreateBillingAdjustmentInput{UserID: 1, Kind: "refund", Amount: 1, UsageRequestID: "req"}, ErrBillingAdjustmentUsageNeedsAPIKey},
+		{"usage with zero key", &CreateBillingAdjustmentInput{UserID: 1, APIKeyID: new(int64), Kind: "refund", Amount: 1, UsageRequestID: "req"}, ErrBillingAdjustmentUsageNeedsAPIKey},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &billingAdjustmentRepoStub{}
+			_, err := NewBillingAdjustmentService(repo, nil, nil).Create(context.Background(), tc.input)
+			require.ErrorIs(t, err, tc.want)
+			require.Empty(t, repo.applied)
+		})
+	}
+
+	repo := &billingAdjustmentRepoStub{}
+	_, err := NewBillingAdjustmentService(repo, nil, nil).Create(context.Background(), &CreateBillingAdjustmentInput{
+		UserID: 1, APIKeyID: &keyID, Kind: " Refund ", Amount: 0.5, Reason: "  failed generation ", UsageRequestID: " req-1 ",
+	})
+	require.NoError(t, err)
+	require.Len(t, repo.applied, 1)
+	require.Equal(t, BillingAdjustmentKindRefund, repo.applied[0].Kind)
+	require.Equal(t, "failed generation", repo.applied[0].Reason)
+	require.Equal(t, "req-1", repo.applied[0].UsageRequestID)
+}
+
+func TestBillingAdjustmentService_Create_InvalidatesAuthCache(t *testing.T) {
+	repo := &billingAdjustmentRepoStub{}
+	invalidator := &authCacheInvalidatorStub{}
+	svc := NewBillingAdjustmentService(repo, invalidator, nil)
+
+	adj, err := svc.Create(context.Background(), &CreateBillingAdjustmentInput{UserID: 7, Kind: BillingAdjustmentKindCredit, Amount: 2})
+	require.NoError(t, err)
+	require.Equal(t, 3.0, adj.BalanceAfter)
+	require.Equal(t, []int64{7}, invalidator.userIDs)
+}
+
+func TestBillingAdjustmentService_ListForUser_ScopesAndHidesInternalFields(t *testing.T) {
+	adminID := int64(1)
+	repo := &billingAdjustmentRepoStub{list: &BillingAdjustmentList{
+		Items: []*BillingAdjustment{{ID: 1, UserID: 7, Notes: "ticket #12", CreatedBy: &adminID}},
+		Total: 1, Page: 1, PageSize: 20,
+	}}
+	otherUser := int64(99)
+
+	out, err := NewBillingAdjustmentService(repo, nil, nil).ListForUser(context.Background(), 7, &BillingAdjustmentFilter{UserID: &otherUser})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), *repo.filter.UserID)
+	require.Empty(t, out.Items[0].Notes)
+	require.Nil(t, out.Items[0].CreatedBy)
+}
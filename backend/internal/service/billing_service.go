@@ -108,6 +108,7 @@ type ModelPricing struct {
 	LongContextOutputMultiplier        float64 // 长上下文整次会话输出倍率
 	ImageOutputPricePerToken           float64 // 图片输出 token 价格 (USD)
 	ImageOutputPriceExplicit           bool    // 是否由渠道定价显式设定（为 true 时即使 == 0 也不回退）
+	ReasoningOutputPricePerToken       float64 // 推理（thinking / reasoning）输出 token 价格 (USD)；为 0 时回退到输出价格
 }
 
 const (
@@ -149,13 +150,16 @@ type UsageTokens struct {
 	CacheCreation5mTokens int
 	CacheCreation1hTokens int
 	ImageOutputTokens     int
+	// ReasoningTokens 为 OutputTokens 中上游单独回报的推理 token（OpenAI reasoning_tokens、Gemini thoughtsTokenCount）
+	ReasoningTokens int
 }
 
 // CostBreakdown 费用明细
 type CostBreakdown struct {
 	InputCost                 float64 // 文本输入费用（不含图片输入，图片输入单独记入 ImageInputCost）
 	ImageInputCost            float64 // 图片输入 token 费用（如 gpt-image-2 图片编辑）
-	OutputCost                float64
+	OutputCost                float64 // 文本输出费用（不含推理 token，推理部分单独记入 ReasoningCost）
+	ReasoningCost             float64 // 推理 token 费用
 	ImageOutputCost           float64
 	CacheCreationCost         float64
 	CacheReadCost             float64
@@ -805,6 +809,7 @@ func (s *BillingService) GetModelPricing(model string) (*ModelPricing, error) {
 				LongContextOutputMultiplier:        litellmPricing.LongContextOutputCostMultiplier,
 				ImageInputPricePerToken:            litellmPricing.InputCostPerImageToken,
 				ImageOutputPricePerToken:           litellmPricing.OutputCostPerImageToken,
+				ReasoningOutputPricePerToken:       litellmPricing.OutputCostPerReasoningToken,
 			}), nil
 		}
 	}
@@ -1030,6 +1035,18 @@ func (s *BillingService) computeTokenBreakdown(
 	if textOutputTokens < 0 {
 		textOutputTokens = 0
 	}
+	// 推理 token 从文本输出中拆出，费用单独记入 ReasoningCost，便于单独计价与分析推理开销。
+	// 未配置推理单价时按文本 output 价（已含 priority / 长上下文调整）计费，总额不变。
+	if reasoningTokens := min(max(tokens.ReasoningTokens, 0), textOutputTokens); reasoningTokens > 0 {
+		reasoningPrice := pricing.ReasoningOutputPricePerToken
+		if reasoningPrice == 0 {
+			reasoningPrice = outputPrice
+		} else if longContextPricingEligible {
+			reasoningPrice *= pricing.LongContextOutputMultiplier
+		}
+		textOutputTokens -= reasoningTokens
+		bd.ReasoningCost = float64(reasoningTokens) * reasoningPrice
+	}
 	bd.OutputCost = float64(textOutputTokens) * outputPrice
 
 	// 图片输出 token 费用（独立费率）
@@ -1050,12 +1067,13 @@ func (s *BillingService) computeTokenBreakdown(
 		bd.InputCost *= tierMultiplier
 		bd.ImageInputCost *= tierMultiplier
 		bd.OutputCost *= tierMultiplier
+		bd.ReasoningCost *= tierMultiplier
 		bd.ImageOutputCost *= tierMultiplier
 		bd.CacheCreationCost *= tierMultiplier
 		bd.CacheReadCost *= tierMultiplier
 	}
 
-	bd.TotalCost = bd.InputCost + bd.ImageInputCost + bd.OutputCost + bd.ImageOutputCost +
+	bd.TotalCost = bd.InputCost + bd.ImageInputCost + bd.OutputCost + bd.ReasoningCost + bd.ImageOutputCost +
 		bd.CacheCreationCost + bd.CacheReadCost
 	bd.ActualCost = bd.TotalCost * rateMultiplier
 	bd.LongContextBillingApplied = baselineCost != nil && bd.ActualCost > baselineCost.ActualCost
@@ -1264,6 +1282,7 @@ func (s *BillingService) CalculateCostWithLongContext(model string, tokens Usage
 		CacheCreation5mTokens: tokens.CacheCreation5mTokens,
 		CacheCreation1hTokens: tokens.CacheCreation1hTokens,
 		ImageOutputTokens:     tokens.ImageOutputTokens,
+		ReasoningTokens:       tokens.ReasoningTokens,
 	}
 	inRangeCost, err := s.CalculateCost(model, inRangeTokens, rateMultiplier)
 	if err != nil {
@@ -1285,6 +1304,7 @@ func (s *BillingService) CalculateCostWithLongContext(model string, tokens Usage
 		InputCost:                 inRangeCost.InputCost + outRangeCost.InputCost,
 		ImageInputCost:            inRangeCost.ImageInputCost + outRangeCost.ImageInputCost,
 		OutputCost:                inRangeCost.OutputCost,
+		ReasoningCost:             inRangeCost.ReasoningCost,
 		ImageOutputCost:           inRangeCost.ImageOutputCost,
 		CacheCreationCost:         inRangeCost.CacheCreationCost,
 		CacheReadCost:             inRangeCost.CacheReadCost + outRangeCost.CacheReadCost,
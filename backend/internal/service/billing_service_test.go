@@ -1600,3 +1600,41 @@ func TestComputeTokenBreakdown_NonExplicitZeroImagePrice_FallsBackToOutput(t *te
 	// textOutputTokens = 200 - 50 = 150
 	require.InDelta(t, 150*15e-6, bd.OutputCost, 1e-12)
 }
+
+func TestCalculateCost_ReasoningTokensSplitFromOutput(t *testing.T) {
+	svc := NewBillingService(&config.Config{}, &PricingService{
+		pricingData: map[string]*LiteLLMModelPricing{
+			"reasoning-default": {
+				InputCostPerToken:  1e-6,
+				OutputCostPerToken: 4e-6,
+			},
+			"reasoning-priced": {
+				InputCostPerToken:           1e-6,
+				OutputCostPerToken:          4e-6,
+				OutputCostPerReasoningToken: 1e-6,
+			},
+		},
+	})
+	tokens := UsageTokens{InputTokens: 100, OutputTokens: 500, ReasoningTokens: 300}
+
+	// 未配置推理单价：按 output 价计费，仅拆分明细，总额不变
+	cost, err := svc.CalculateCost("reasoning-default", tokens, 1.0)
+	require.NoError(t, err)
+	require.InDelta(t, 200*4e-6, cost.OutputCost, 1e-12)
+	require.InDelta(t, 300*4e-6, cost.ReasoningCost, 1e-12)
+	require.InDelta(t, 100*1e-6+500*4e-6, cost.TotalCost, 1e-12)
+
+	// 显式推理单价独立计价
+	cost, err = svc.CalculateCost("reasoning-priced", tokens, 1.0)
+	require.NoError(t, err)
+	require.InDelta(t, 200*4e-6, cost.OutputCost, 1e-12)
+	require.InDelta(t, 300*1e-6, cost.ReasoningCost, 1e-12)
+	require.InDelta(t, 100*1e-6+200*4e-6+300*1e-6, cost.TotalCost, 1e-12)
+
+	// 上游回报的推理 token 超过 output 时按 output 截断
+	tokens.ReasoningTokens = 900
+	cost, err = svc.CalculateCost("reasoning-default", tokens, 1.0)
+	require.NoError(t, err)
+	require.Zero(t, cost.OutputCost)
+	require.InDelta(t, 500*4e-6, cost.ReasoningCost, 1e-12)
+}
@@ -144,6 +144,7 @@ type ChannelService struct {
 	groupRepo            GroupRepository
 	authCacheInvalidator APIKeyAuthCacheInvalidator
 	pricingService       *PricingService // 用于「可用渠道」展示时回落到全局定价；可为 nil（测试场景）
+	modelAliasResolver   ModelAliasResolver
 
 	cache   atomic.Value // *channelCache
 	cacheSF singleflight.Group
@@ -162,6 +163,12 @@ func NewChannelService(repo ChannelRepository, groupRepo GroupRepository, authCa
 	return s
 }
 
+// SetModelAliasResolver 注入已批准的模型别名改写（可选依赖）。
+// 渠道映射未命中时，ResolveChannelMappingAndRestrict 回落到别名改写。
+func (s *ChannelService) SetModelAliasResolver(resolver ModelAliasResolver) {
+	s.modelAliasResolver = resolver
+}
+
 // loadCache 加载或返回缓存的渠道数据
 func (s *ChannelService) loadCache(ctx context.Context) (*channelCache, error) {
 	if cached, ok := s.cache.Load().(*channelCache); ok && cached != nil {
@@ -513,14 +520,20 @@ func (s *ChannelService) IsModelRestricted(ctx context.Context, groupID int64, m
 // 返回映射结果。模型限制检查已移至调度阶段（GatewayService.checkChannelPricingRestriction），
 // restricted 始终返回 false，保留签名兼容性。
 func (s *ChannelService) ResolveChannelMappingAndRestrict(ctx context.Context, groupID *int64, model string) (ChannelMappingResult, bool) {
-	if groupID == nil {
-		return ChannelMappingResult{MappedModel: model}, false
+	result := ChannelMappingResult{MappedModel: model}
+	if groupID != nil {
+		if lk, _ := s.lookupGroupChannel(ctx, *groupID); lk != nil {
+			result = resolveMapping(lk, *groupID, model)
+		}
 	}
-	lk, _ := s.lookupGroupChannel(ctx, *groupID)
-	if lk == nil {
-		return ChannelMappingResult{MappedModel: model}, false
+	// 渠道映射优先；未命中时应用管理员批准的模型别名
+	if !result.Mapped && s.modelAliasResolver != nil {
+		if alias, ok := s.modelAliasResolver.ResolveModelAlias(ctx, model); ok {
+			result.MappedModel = alias
+			result.Mapped = true
+		}
 	}
-	return resolveMapping(lk, *groupID, model), false
+	return result, false
 }
 
 // resolveMapping 基于已查找的渠道信息解析模型映射。
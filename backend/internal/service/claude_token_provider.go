@@ -92,7 +92,9 @@ func (p *ClaudeTokenProvider) GetAccessToken(ctx context.Context, account *Accou
 			refreshFailed = true
 		} else if result.LockHeld {
 			if p.refreshPolicy.OnLockHeld == ProviderLockHeldWaitForCache && p.tokenCache != nil {
-				time.Sleep(claudeLockWaitTime)
+				if err := sleepWithContext(ctx, claudeLockWaitTime); err != nil {
+					return "", err
+				}
 				if token, cacheErr := p.tokenCache.GetAccessToken(ctx, cacheKey); cacheErr == nil && strings.TrimSpace(token) != "" {
 					slog.Debug("claude_token_cache_hit_after_wait", "account_id", account.ID)
 					return token, nil
@@ -110,7 +112,9 @@ func (p *ClaudeTokenProvider) GetAccessToken(ctx context.Context, account *Accou
 		} else if lockErr != nil {
 			slog.Warn("claude_token_lock_failed", "account_id", account.ID, "error", lockErr)
 		} else {
-			time.Sleep(claudeLockWaitTime)
+			if err := sleepWithContext(ctx, claudeLockWaitTime); err != nil {
+				return "", err
+			}
 			if token, err := p.tokenCache.GetAccessToken(ctx, cacheKey); err == nil && strings.TrimSpace(token) != "" {
 				slog.Debug("claude_token_cache_hit_after_wait", "account_id", account.ID)
 				return token, nil
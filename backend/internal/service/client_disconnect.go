@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrClientDisconnected 下游客户端断开（或请求被管理员强制取消）后，脱钩的上游调用被取消时的 cause。
+var ErrClientDisconnected = errors.New("client disconnected")
+
+// watchClientDisconnect 为与请求 context 脱钩的上游 context 挂上断开监视。
+//
+// 流式上游调用使用 WithoutCancel 脱钩，使客户端断开后仍能读完上游以补全 usage 计费；
+// 但无人接收的生成不应无限持续。对登记在途表的请求，请求 context 结束后最多再等待
+// upstreamGrace 即取消上游（grace 为 0 或管理员强制取消时立即取消）。
+// 未登记的请求（如异步任务以 WithoutCancel 派生的 context）保持原有行为。
+func watchClientDisconnect(parent, detached context.Context) context.Context {
+	req := ActiveRequestFromContext(parent)
+	if req == nil || parent.Done() == nil {
+		return detached
+	}
+	ctx, cancel := context.WithCancelCause(detached)
+	context.AfterFunc(parent, func() {
+		if req.upstreamGrace <= 0 || req.forceCancelled() {
+			cancel(ErrClientDisconnected)
+			return
+		}
+		time.AfterFunc(req.upstreamGrace, func() { cancel(ErrClientDisconnected) })
+	})
+	return ctx
+}
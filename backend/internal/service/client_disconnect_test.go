@@ -0,0 +1,122 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetachUpstreamContext_UnregisteredRequestKeepsLegacyBehavior(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	upstreamCtx, release := detachUpstreamContext(parent)
+	release()
+	cancel()
+
+	select {
+	case <-upstreamCtx.Done():
+		t.Fatal("unregistered detached upstream context must not follow the client context")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDetachUpstreamContext_CancelsAfterGrace(t *testing.T) {
+	registry := NewActiveRequestRegistry(80 * time.Millisecond)
+	req, clientCtx := registry.Begin(context.Background(), ActiveRequestInfo{})
+	upstreamCtx, release := detachStreamUpstreamContext(clientCtx, true)
+	release()
+
+	disconnectedAt := time.Now()
+	registry.End(req)
+
+	select {
+	case <-upstreamCtx.Done():
+		t.Fatal("upstream context cancelled before the grace period elapsed")
+	case <-time.After(20 * time.Millisecond):
+	}
+	select {
+	case <-upstreamCtx.Done():
+		require.ErrorIs(t, context.Cause(upstreamCtx), ErrClientDisconnected)
+		require.Less(t, time.Since(disconnectedAt), time.Second)
+	case <-time.After(time.Second):
+		t.Fatal("upstream context still running 1s after client disconnect")
+	}
+}
+
+func TestDetachUpstreamContext_ForceCancelSkipsGrace(t *testing.T) {
+	registry := NewActiveRequestRegistry(time.Hour)
+	req, clientCtx := registry.Begin(context.Background(), ActiveRequestInfo{})
+	upstreamCtx, _ := detachUpstreamContext(clientCtx)
+
+	_, err := registry.Cancel(req.id)
+	require.NoError(t, err)
+
+	select {
+	case <-upstreamCtx.Done():
+		require.ErrorIs(t, context.Cause(upstreamCtx), ErrClientDisconnected)
+	case <-time.After(time.Second):
+		t.Fatal("force-cancelled request must cancel its upstream immediately")
+	}
+}
+
+func TestDetachUpstreamContext_UpstreamCallStopsAfterDisconnect(t *testing.T) {
+	upstreamStopped := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(upstreamStopped)
+		w.Header().Set("Content-Type", "text/event-stream")
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				if _, err := io.WriteString(w, "event: ping\ndata: {}\n\n"); err != nil {
+					return
+				}
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	defer upstream.Close()
+
+	registry := NewActiveRequestRegistry(0)
+	req, clientCtx := registry.Begin(context.Background(), ActiveRequestInfo{})
+	upstreamCtx, _ := detachStreamUpstreamContext(clientCtx, true)
+
+	upstreamReq, err := http.NewRequestWithContext(upstreamCtx, http.MethodPost, upstream.URL, nil)
+	require.NoError(t, err)
+	resp, err := upstream.Client().Do(upstreamReq)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}()
+
+	registry.End(req)
+
+	for _, ch := range []chan struct{}{readDone, upstreamStopped} {
+		select {
+		case <-ch:
+		case <-time.After(2 * time.Second):
+			t.Fatal("upstream stream kept running after client disconnect")
+		}
+	}
+}
+
+func TestSleepGeminiBackoff_ReturnsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	started := time.Now()
+	require.ErrorIs(t, sleepGeminiBackoff(ctx, geminiMaxRetries), context.Canceled)
+	require.Less(t, time.Since(started), 100*time.Millisecond)
+}
@@ -235,6 +235,9 @@ type ConcurrencyService struct {
 	accountLoadCacheMu  sync.RWMutex
 	accountLoadCache    map[string]cachedAccountLoadBatch
 	accountLoadGroup    singleflight.Group
+
+	// leases 槽位心跳续约，nil 表示未启用（gateway.slot_lease）
+	leases *slotLeaseKeeper
 }
 
 type cachedAccountLoadBatch struct {
@@ -357,9 +360,11 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 	}
 
 	if acquired {
+		untrack := s.trackSlotLease(SlotLeaseKindAccount, accountID, requestID)
 		return &AcquireResult{
 			Acquired: true,
 			ReleaseFunc: func() {
+				untrack()
 				bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
 				if err := s.cache.ReleaseAccountSlot(bgCtx, accountID, requestID); err != nil {
@@ -396,9 +401,11 @@ func (s *ConcurrencyService) AcquireUserSlot(ctx context.Context, userID int64,
 	}
 
 	if acquired {
+		untrack := s.trackSlotLease(SlotLeaseKindUser, userID, requestID)
 		return &AcquireResult{
 			Acquired: true,
 			ReleaseFunc: func() {
+				untrack()
 				bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
 				if err := s.cache.ReleaseUserSlot(bgCtx, userID, requestID); err != nil {
@@ -439,7 +446,9 @@ func (s *ConcurrencyService) TrackAPIKeySlot(ctx context.Context, apiKeyID int64
 		return func() {}
 	}
 
+	untrack := s.trackSlotLease(SlotLeaseKindAPIKey, apiKeyID, requestID)
 	return func() {
+		untrack()
 		bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := cache.ReleaseAPIKeySlot(bgCtx, apiKeyID, requestID); err != nil {
@@ -559,6 +568,21 @@ func (s *ConcurrencyService) GetAccountWaitingCount(ctx context.Context, account
 	return s.cache.GetAccountWaitingCount(ctx, accountID)
 }
 
+// GetUserWaitingCount gets current wait queue count for a user.
+func (s *ConcurrencyService) GetUserWaitingCount(ctx context.Context, userID int64) (int, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+	loadMap, err := s.cache.GetUsersLoadBatch(ctx, []UserWithConcurrency{{ID: userID}})
+	if err != nil {
+		return 0, err
+	}
+	if info := loadMap[userID]; info != nil {
+		return info.WaitingCount, nil
+	}
+	return 0, nil
+}
+
 // CalculateMaxWait calculates the maximum wait queue size for a user
 // maxWait = userConcurrency + defaultExtraWaitSlots
 func CalculateMaxWait(userConcurrency int) int {
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 槽位租约类型
+const (
+	SlotLeaseKindAccount = "account"
+	SlotLeaseKindUser    = "user"
+	SlotLeaseKindAPIKey  = "api_key"
+)
+
+const slotLeaseRenewTimeout = 5 * time.Second
+
+// SlotLease 标识一个已占用的并发槽位（有序集合成员）。
+type SlotLease struct {
+	Kind      string
+	ID        int64
+	RequestID string
+}
+
+// SlotLeaseCache 续约已占用的并发槽位。
+// 续约不会重建已丢失的成员：槽位一旦被回收，持有者不能再悄悄越过并发上限。
+type SlotLeaseCache interface {
+	// RenewSlotLeases 刷新租约时间戳，返回与 leases 一一对应的是否仍持有
+	RenewSlotLeases(ctx context.Context, leases []SlotLease) ([]bool, error)
+}
+
+// slotLeaseKeeper 集中为本实例持有的全部槽位发送心跳。
+// 单个后台 goroutine 批量续约，而不是每个请求各起一个定时器；实例宕机后心跳停止，槽位在一个租约 TTL 内自动回收。
+type slotLeaseKeeper struct {
+	cache    SlotLeaseCache
+	interval time.Duration
+
+	mu     sync.Mutex
+	leases map[SlotLease]struct{}
+}
+
+func newSlotLeaseKeeper(cache SlotLeaseCache, interval time.Duration) *slotLeaseKeeper {
+	return &slotLeaseKeeper{
+		cache:    cache,
+		interval: interval,
+		leases:   make(map[SlotLease]struct{}),
+	}
+}
+
+// track 登记一个已占用的槽位，返回的函数在释放槽位前调用。
+func (k *slotLeaseKeeper) track(lease SlotLease) func() {
+	if k == nil {
+		return func() {}
+	}
+	k.mu.Lock()
+	k.leases[lease] = struct{}{}
+	k.mu.Unlock()
+	return func() {
+		k.mu.Lock()
+		delete(k.leases, lease)
+		k.mu.Unlock()
+	}
+}
+
+func (k *slotLeaseKeeper) run() {
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		k.renewOnce()
+	}
+}
+
+// renewOnce 续约当前全部租约；已丢失的租约记录日志并停止续约，Redis 错误留待下个周期重试。
+func (k *slotLeaseKeeper) renewOnce() {
+	k.mu.Lock()
+	leases := make([]SlotLease, 0, len(k.leases))
+	for lease := range k.leases {
+		leases = append(leases, lease)
+	}
+	k.mu.Unlock()
+	if len(leases) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), slotLeaseRenewTimeout)
+	owned, err := k.cache.RenewSlotLeases(ctx, leases)
+	cancel()
+	if err != nil {
+		logger.L().Warn("concurrency_slot_lease_renew_failed",
+			zap.Int("leases", len(leases)),
+			zap.Error(err),
+		)
+		return
+	}
+	for i, lease := range leases {
+		if i < len(owned) && owned[i] {
+			continue
+		}
+		k.mu.Lock()
+		_, stillTracked := k.leases[lease]
+		delete(k.leases, lease)
+		k.mu.Unlock()
+		if !stillTracked {
+			// 续约期间已正常释放
+			continue
+		}
+		logger.L().Warn("concurrency_slot_lease_lost",
+			zap.String("kind", lease.Kind),
+			zap.Int64("id", lease.ID),
+			zap.String("request_id", lease.RequestID),
+		)
+	}
+}
+
+// EnableSlotLeaseHeartbeat 为本实例持有的槽位启动心跳续约（gateway.slot_lease）。
+// 缓存不支持续约时只记录警告，槽位仍按 TTL 过期。
+func (s *ConcurrencyService) EnableSlotLeaseHeartbeat(interval time.Duration) {
+	if s == nil || s.cache == nil || interval <= 0 || s.leases != nil {
+		return
+	}
+	cache, ok := s.cache.(SlotLeaseCache)
+	if !ok {
+		logger.LegacyPrintf("service.concurrency", "Warning: concurrency cache does not support slot lease renewal; heartbeat disabled")
+		return
+	}
+	s.leases = newSlotLeaseKeeper(cache, interval)
+	go s.leases.run()
+}
+
+// trackSlotLease 登记心跳续约；未启用时返回 no-op。
+func (s *ConcurrencyService) trackSlotLease(kind string, id int64, requestID string) func() {
+	return s.leases.track(SlotLease{Kind: kind, ID: id, RequestID: requestID})
+}
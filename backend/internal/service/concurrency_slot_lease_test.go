@@ -0,0 +1,105 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type slotLeaseCacheForTest struct {
+	stubConcurrencyCacheForTest
+	renewCalls [][]SlotLease
+	lost       map[string]bool
+	renewErr   error
+}
+
+var _ SlotLeaseCache = (*slotLeaseCacheForTest)(nil)
+
+func (c *slotLeaseCacheForTest) RenewSlotLeases(_ context.Context, leases []SlotLease) ([]bool, error) {
+	c.renewCalls = append(c.renewCalls, leases)
+	if c.renewErr != nil {
+		return nil, c.renewErr
+	}
+	owned := make([]bool, len(leases))
+	for i, lease := range leases {
+		owned[i] = !c.lost[lease.RequestID]
+	}
+	return owned, nil
+}
+
+func newSlotLeaseTestService(cache *slotLeaseCacheForTest) *ConcurrencyService {
+	svc := NewConcurrencyService(cache)
+	// 间隔足够长，测试中手动触发续约
+	svc.EnableSlotLeaseHeartbeat(time.Hour)
+	return svc
+}
+
+func TestSlotLeaseHeartbeat_RenewsHeldSlotsUntilRelease(t *testing.T) {
+	cache := &slotLeaseCacheForTest{stubConcurrencyCacheForTest: stubConcurrencyCacheForTest{acquireResult: true}}
+	svc := newSlotLeaseTestService(cache)
+
+	account, err := svc.AcquireAccountSlot(context.Background(), 1, 5)
+	require.NoError(t, err)
+	user, err := svc.AcquireUserSlot(context.Background(), 2, 5)
+	require.NoError(t, err)
+	releaseAPIKey := svc.TrackAPIKeySlot(context.Background(), 3)
+
+	svc.leases.renewOnce()
+	require.Len(t, cache.renewCalls, 1)
+	kinds := map[string]int64{}
+	for _, lease := range cache.renewCalls[0] {
+		kinds[lease.Kind] = lease.ID
+	}
+	require.Equal(t, map[string]int64{SlotLeaseKindAccount: 1, SlotLeaseKindUser: 2, SlotLeaseKindAPIKey: 3}, kinds)
+
+	account.ReleaseFunc()
+	user.ReleaseFunc()
+	releaseAPIKey()
+	svc.leases.renewOnce()
+	require.Len(t, cache.renewCalls, 1, "released slots must not be renewed")
+}
+
+func TestSlotLeaseHeartbeat_StopsRenewingLostLease(t *testing.T) {
+	cache := &slotLeaseCacheForTest{stubConcurrencyCacheForTest: stubConcurrencyCacheForTest{acquireResult: true}}
+	svc := newSlotLeaseTestService(cache)
+
+	_, err := svc.AcquireAccountSlot(context.Background(), 1, 5)
+	require.NoError(t, err)
+	svc.leases.renewOnce()
+	require.Len(t, cache.renewCalls, 1)
+	require.Len(t, cache.renewCalls[0], 1)
+	cache.lost = map[string]bool{cache.renewCalls[0][0].RequestID: true}
+
+	svc.leases.renewOnce()
+	require.Empty(t, svc.leases.leases)
+	svc.leases.renewOnce()
+	require.Len(t, cache.renewCalls, 2)
+}
+
+func TestSlotLeaseHeartbeat_KeepsLeasesOnRenewError(t *testing.T) {
+	cache := &slotLeaseCacheForTest{
+		stubConcurrencyCacheForTest: stubConcurrencyCacheForTest{acquireResult: true},
+		renewErr:                    errors.New("redis down"),
+	}
+	svc := newSlotLeaseTestService(cache)
+
+	_, err := svc.AcquireAccountSlot(context.Background(), 1, 5)
+	require.NoError(t, err)
+	svc.leases.renewOnce()
+	require.Len(t, svc.leases.leases, 1)
+}
+
+func TestSlotLeaseHeartbeat_DisabledWithoutRenewSupport(t *testing.T) {
+	svc := NewConcurrencyService(&stubConcurrencyCacheForTest{acquireResult: true})
+	svc.EnableSlotLeaseHeartbeat(time.Second)
+	require.Nil(t, svc.leases)
+
+	result, err := svc.AcquireAccountSlot(context.Background(), 1, 5)
+	require.NoError(t, err)
+	result.ReleaseFunc()
+}
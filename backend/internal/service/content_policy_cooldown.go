@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// contentPolicyErrorMarkers 上游内容策略类错误的特征（匹配 message / error.code / error.type，小写）
+var contentPolicyErrorMarkers = []string{
+	"content_policy",
+	"content policy",
+	"content_filter",
+	"content filtering",
+	"content management policy",
+	"usage policy",
+	"usage policies",
+	"policy_violation",
+	"violates our",
+	"prohibited_content",
+	"safety system",
+	"flagged as potentially",
+}
+
+// isContentPolicyViolation 判断上游错误响应是否为内容策略违规
+func isContentPolicyViolation(statusCode int, body []byte) bool {
+	if statusCode != http.StatusBadRequest && statusCode != http.StatusForbidden {
+		return false
+	}
+	if len(body) == 0 {
+		return false
+	}
+	combined := strings.ToLower(extractUpstreamErrorMessage(body) + " " +
+		gjson.GetBytes(body, "error.code").String() + " " +
+		gjson.GetBytes(body, "error.type").String())
+	for _, marker := range contentPolicyErrorMarkers {
+		if strings.Contains(combined, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentPolicyViolation 一次内容策略违规记录
+type ContentPolicyViolation struct {
+	AccountID int64
+	// UserID 触发违规的用户；<=0 表示无法归属到用户
+	UserID int64
+	At     time.Time
+	// CooldownUntil 冷却截止时间
+	CooldownUntil time.Time
+	// AccountScope 为 true 时冷却作用于账号整体，否则仅作用于 (用户, 账号)
+	AccountScope bool
+	// Window 违规统计窗口（同时作为高风险用户标记的有效期）
+	Window time.Duration
+}
+
+// ContentPolicyAccountState 单个账号对某用户的内容策略冷却状态
+type ContentPolicyAccountState struct {
+	// Violations 窗口内违规次数
+	Violations int
+	// Cooling 账号整体或对该用户处于冷却中
+	Cooling bool
+}
+
+// ContentPolicyCooldownCache 内容策略违规冷却的共享状态（Redis，条目按 TTL 自动过期，多实例共享）
+type ContentPolicyCooldownCache interface {
+	// RecordViolation 记录一次违规并返回账号在窗口内的违规次数
+	RecordViolation(ctx context.Context, v ContentPolicyViolation) (int, error)
+	// GetAccountStates 批量读取账号对指定用户的冷却状态与窗口内违规次数，并返回该用户是否为高风险用户
+	GetAccountStates(ctx context.Context, userID int64, accountIDs []int64, window time.Duration, now time.Time) (map[int64]ContentPolicyAccountState, bool, error)
+	// GetStats 返回窗口内的高风险用户数与各账号违规统计（未排序）
+	GetStats(ctx context.Context, window time.Duration, now time.Time) (int, []ContentPolicyAccountStats, error)
+}
+
+// ContentPolicyCooldownService 按 gateway.content_policy_cooldown 对上游内容策略违规的账号做冷却，
+// 并将反复触发违规的用户分散到违规较少的账号；nil 表示关闭，方法对 nil 安全。
+type ContentPolicyCooldownService struct {
+	cache     ContentPolicyCooldownCache
+	scope     string
+	cooldown  time.Duration
+	window    time.Duration
+	threshold int
+}
+
+// NewContentPolicyCooldownService 按配置创建内容策略冷却服务；未启用、参数无效或缺少缓存时返回 nil。
+func NewContentPolicyCooldownService(cfg *config.Config, cache ContentPolicyCooldownCache) *ContentPolicyCooldownService {
+	if cfg == nil || cache == nil {
+		return nil
+	}
+	cooldown := cfg.Gateway.ContentPolicyCooldown
+	if !cooldown.Enabled || cooldown.CooldownSeconds <= 0 || cooldown.WindowSeconds <= 0 {
+		return nil
+	}
+	scope := cooldown.Scope
+	if scope != config.ContentPolicyCooldownScopeAccount {
+		scope = config.ContentPolicyCooldownScopeUser
+	}
+	return &ContentPolicyCooldownService{
+		cache:     cache,
+		scope:     scope,
+		cooldown:  time.Duration(cooldown.CooldownSeconds) * time.Second,
+		window:    time.Duration(cooldown.WindowSeconds) * time.Second,
+		threshold: cooldown.AccountThreshold,
+	}
+}
+
+// SetContentPolicyCooldownService 设置内容策略违规冷却（可选依赖，用于候选过滤与粘性会话校验）
+func (s *GatewayService) SetContentPolicyCooldownService(svc *ContentPolicyCooldownService) {
+	s.contentPolicy = svc
+}
+
+// SetContentPolicyCooldownService 设置内容策略违规冷却（可选依赖，用于候选过滤与粘性会话校验）
+func (s *OpenAIGatewayService) SetContentPolicyCooldownService(svc *ContentPolicyCooldownService) {
+	s.contentPolicy = svc
+}
+
+// SetContentPolicyCooldownService 设置内容策略违规冷却（可选依赖，用于粘性会话校验）
+func (s *GeminiMessagesCompatService) SetContentPolicyCooldownService(svc *ContentPolicyCooldownService) {
+	s.contentPolicy = svc
+}
+
+// shouldClearStickySession 在通用检查（见包级 shouldClearStickySession）之外，避开对当前用户处于内容策略冷却中的账号。
+func (s *OpenAIGatewayService) shouldClearStickySession(ctx context.Context, account *Account, requestedModel string) bool {
+	if shouldClearStickySession(account, requestedModel) {
+		return true
+	}
+	return account != nil && s.contentPolicy.blocks(ctx, account.ID)
+}
+
+// RecordViolation 若上游错误为内容策略违规，记录到当前请求用户与账号上。
+func (s *ContentPolicyCooldownService) RecordViolation(ctx context.Context, account *Account, statusCode int, body []byte) {
+	if s == nil || account == nil || !isContentPolicyViolation(statusCode, body) {
+		return
+	}
+	userID, _ := ctx.Value(ctxkey.UserID).(int64)
+	now := time.Now()
+	count, err := s.cache.RecordViolation(ctx, ContentPolicyViolation{
+		AccountID:     account.ID,
+		UserID:        userID,
+		At:            now,
+		CooldownUntil: now.Add(s.cooldown),
+		AccountScope:  s.scope == config.ContentPolicyCooldownScopeAccount || userID <= 0,
+		Window:        s.window,
+	})
+	if err != nil {
+		logger.L().Warn("gateway.content_policy_violation_record_failed",
+			zap.Int64("account_id", account.ID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return
+	}
+	logger.L().Warn("gateway.content_policy_violation",
+		zap.Int64("account_id", account.ID),
+		zap.Int64("user_id", userID),
+		zap.Int("status_code", statusCode),
+		zap.String("scope", s.scope),
+		zap.Int("account_violations_in_window", count),
+	)
+}
+
+// blockedAccounts 返回对当前请求应避开的账号：账号对该用户处于冷却中，或该用户为高风险且账号违规次数已达阈值。
+// 读取失败时不做任何过滤。
+func (s *ContentPolicyCooldownService) blockedAccounts(ctx context.Context, accountIDs []int64) map[int64]bool {
+	if s == nil || len(accountIDs) == 0 {
+		return nil
+	}
+	userID, _ := ctx.Value(ctxkey.UserID).(int64)
+	states, risky, err := s.cache.GetAccountStates(ctx, userID, accountIDs, s.window, time.Now())
+	if err != nil {
+		logger.L().Warn("gateway.content_policy_state_read_failed", zap.Int64("user_id", userID), zap.Error(err))
+		return nil
+	}
+	var blocked map[int64]bool
+	for accountID, state := range states {
+		if state.Cooling || (risky && s.threshold > 0 && state.Violations >= s.threshold) {
+			if blocked == nil {
+				blocked = make(map[int64]bool)
+			}
+			blocked[accountID] = true
+		}
+	}
+	return blocked
+}
+
+// blocks 当前请求是否应避开该账号（供粘性会话校验使用）
+func (s *ContentPolicyCooldownService) blocks(ctx context.Context, accountID int64) bool {
+	return s.blockedAccounts(ctx, []int64{accountID})[accountID]
+}
+
+// filter 从候选账号中移除对当前请求处于内容策略冷却中的账号。
+// 过滤后若没有任何当前可调度的账号则返回原列表，冷却只调整分布，不制造无号可用。
+func (s *ContentPolicyCooldownService) filter(ctx context.Context, accounts []Account) []Account {
+	if s == nil || len(accounts) < 2 {
+		return accounts
+	}
+	ids := make([]int64, 0, len(accounts))
+	for i := range accounts {
+		ids = append(ids, accounts[i].ID)
+	}
+	blocked := s.blockedAccounts(ctx, ids)
+	if len(blocked) == 0 {
+		return accounts
+	}
+	kept := make([]Account, 0, len(accounts))
+	for i := range accounts {
+		if !blocked[accounts[i].ID] {
+			kept = append(kept, accounts[i])
+		}
+	}
+	for i := range kept {
+		if kept[i].IsSchedulable() {
+			return kept
+		}
+	}
+	return accounts
+}
+
+// ContentPolicyAccountStats 单个账号的内容策略违规统计（管理端展示）
+type ContentPolicyAccountStats struct {
+	AccountID       int64      `json:"account_id"`
+	Violations      int        `json:"violations"`
+	LastViolationAt time.Time  `json:"last_violation_at"`
+	CooldownUntil   *time.Time `json:"cooldown_until,omitempty"`
+	CoolingUsers    int        `json:"cooling_users"`
+}
+
+// ContentPolicyStats 内容策略违规统计快照
+type ContentPolicyStats struct {
+	Enabled          bool                        `json:"enabled"`
+	Scope            string                      `json:"scope,omitempty"`
+	WindowSeconds    int64                       `json:"window_seconds,omitempty"`
+	AccountThreshold int                         `json:"account_threshold,omitempty"`
+	RiskyUsers       int                         `json:"risky_users"`
+	Accounts         []ContentPolicyAccountStats `json:"accounts"`
+}
+
+// Stats 返回窗口内各账号的违规次数与冷却状态，按违规次数降序。
+func (s *ContentPolicyCooldownService) Stats(ctx context.Context) (ContentPolicyStats, error) {
+	if s == nil {
+		return ContentPolicyStats{Accounts: []ContentPolicyAccountStats{}}, nil
+	}
+	riskyUsers, accounts, err := s.cache.GetStats(ctx, s.window, time.Now())
+	if err != nil {
+		return ContentPolicyStats{}, err
+	}
+	if accounts == nil {
+		accounts = []ContentPolicyAccountStats{}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Violations != accounts[j].Violations {
+			return accounts[i].Violations > accounts[j].Violations
+		}
+		return accounts[i].AccountID < accounts[j].AccountID
+	})
+	return ContentPolicyStats{
+		Enabled:          true,
+		Scope:            s.scope,
+		WindowSeconds:    int64(s.window / time.Second),
+		AccountThreshold: s.threshold,
+		RiskyUsers:       riskyUsers,
+		Accounts:         accounts,
+	}, nil
+}
@@ -0,0 +1,252 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+// contentPolicyCooldownCacheStub 内存版 ContentPolicyCooldownCache，语义与 Redis 实现一致
+type contentPolicyCooldownCacheStub struct {
+	mu         sync.Mutex
+	violations []ContentPolicyViolation
+	err        error
+}
+
+func (c *contentPolicyCooldownCacheStub) RecordViolation(_ context.Context, v ContentPolicyViolation) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.violations = append(c.violations, v)
+	count := 0
+	for _, existing := range c.violations {
+		if existing.AccountID == v.AccountID && existing.At.After(v.At.Add(-v.Window)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (c *contentPolicyCooldownCacheStub) GetAccountStates(_ context.Context, userID int64, accountIDs []int64, window time.Duration, now time.Time) (map[int64]ContentPolicyAccountState, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, false, c.err
+	}
+	states := make(map[int64]ContentPolicyAccountState, len(accountIDs))
+	for _, id := range accountIDs {
+		states[id] = ContentPolicyAccountState{}
+	}
+	risky := false
+	for _, v := range c.violations {
+		if userID > 0 && v.UserID == userID && v.At.Add(v.Window).After(now) {
+			risky = true
+		}
+		state, ok := states[v.AccountID]
+		if !ok {
+			continue
+		}
+		if v.At.After(now.Add(-window)) {
+			state.Violations++
+		}
+		if v.CooldownUntil.After(now) && (v.AccountScope || (userID > 0 && v.UserID == userID)) {
+			state.Cooling = true
+		}
+		states[v.AccountID] = state
+	}
+	return states, risky, nil
+}
+
+func (c *contentPolicyCooldownCacheStub) GetStats(_ context.Context, window time.Duration, now time.Time) (int, []ContentPolicyAccountStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, nil, c.err
+	}
+	riskyUsers := make(map[int64]bool)
+	coolingUsers := make(map[int64]map[int64]bool)
+	byAccount := make(map[int64]*ContentPolicyAccountStats)
+	for _, v := range c.violations {
+		if v.UserID > 0 && v.At.Add(v.Window).After(now) {
+			riskyUsers[v.UserID] = true
+		}
+		e, ok := byAccount[v.AccountID]
+		if !ok {
+			e = &ContentPolicyAccountStats{AccountID: v.AccountID}
+			byAccount[v.AccountID] = e
+		}
+		if v.At.After(now.Add(-window)) {
+			e.Violations++
+			if v.At.After(e.LastViolationAt) {
+				e.LastViolationAt = v.At
+			}
+		}
+		if !v.CooldownUntil.After(now) {
+			continue
+		}
+		if v.AccountScope {
+			until := v.CooldownUntil
+			e.CooldownUntil = &until
+		} else {
+			if coolingUsers[v.AccountID] == nil {
+				coolingUsers[v.AccountID] = make(map[int64]bool)
+			}
+			coolingUsers[v.AccountID][v.UserID] = true
+		}
+	}
+	out := make([]ContentPolicyAccountStats, 0, len(byAccount))
+	for id, e := range byAccount {
+		e.CoolingUsers = len(coolingUsers[id])
+		out = append(out, *e)
+	}
+	return len(riskyUsers), out, nil
+}
+
+func newContentPolicyCooldownServiceForTest(t *testing.T, scope string, threshold int) (*ContentPolicyCooldownService, *contentPolicyCooldownCacheStub) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Gateway.ContentPolicyCooldown = config.GatewayContentPolicyCooldownConfig{
+		Enabled:          true,
+		Scope:            scope,
+		CooldownSeconds:  300,
+		WindowSeconds:    3600,
+		AccountThreshold: threshold,
+	}
+	cache := &contentPolicyCooldownCacheStub{}
+	svc := NewContentPolicyCooldownService(cfg, cache)
+	require.NotNil(t, svc)
+	return svc, cache
+}
+
+func contentPolicyUserCtx(userID int64) context.Context {
+	return context.WithValue(context.Background(), ctxkey.UserID, userID)
+}
+
+func contentPolicyCandidates(ids ...int64) []Account {
+	out := make([]Account, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, Account{ID: id, Status: StatusActive, Schedulable: true})
+	}
+	return out
+}
+
+func accountIDsOf(accounts []Account) []int64 {
+	ids := make([]int64, 0, len(accounts))
+	for _, a := range accounts {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+var contentPolicyErrorBody = []byte(`{"type":"error","error":{"type":"invalid_request_error","message":"Output blocked by content filtering policy"}}`)
+
+func TestIsContentPolicyViolation(t *testing.T) {
+	require.True(t, isContentPolicyViolation(http.StatusBadRequest, []byte(`{"error":{"code":"content_policy_violation","message":"Your request was rejected"}}`)))
+	require.True(t, isContentPolicyViolation(http.StatusForbidden, []byte(`{"error":{"message":"This request violates our usage policies."}}`)))
+	require.False(t, isContentPolicyViolation(http.StatusTooManyRequests, []byte(`{"error":{"code":"content_policy_violation"}}`)))
+	require.False(t, isContentPolicyViolation(http.StatusBadRequest, []byte(`{"error":{"message":"max_tokens: must be positive"}}`)))
+	require.False(t, isContentPolicyViolation(http.StatusBadRequest, nil))
+}
+
+func TestContentPolicyCooldown_UserScope(t *testing.T) {
+	svc, _ := newContentPolicyCooldownServiceForTest(t, config.ContentPolicyCooldownScopeUser, 0)
+	offender, other := contentPolicyUserCtx(7), contentPolicyUserCtx(8)
+
+	svc.RecordViolation(offender, &Account{ID: 1}, http.StatusBadRequest, []byte(`{"error":{"code":"content_policy_violation"}}`))
+
+	require.Equal(t, []int64{2, 3}, accountIDsOf(svc.filter(offender, contentPolicyCandidates(1, 2, 3))))
+	require.Equal(t, []int64{1, 2, 3}, accountIDsOf(svc.filter(other, contentPolicyCandidates(1, 2, 3))))
+	require.True(t, svc.blocks(offender, 1))
+	require.False(t, svc.blocks(other, 1))
+
+	gateway := &GatewayService{contentPolicy: svc}
+	require.True(t, gateway.shouldClearStickySession(offender, &Account{ID: 1, Status: StatusActive, Schedulable: true}, ""))
+	openai := &OpenAIGatewayService{contentPolicy: svc}
+	require.True(t, openai.shouldClearStickySession(offender, &Account{ID: 1, Status: StatusActive, Schedulable: true}, ""))
+	require.False(t, openai.shouldClearStickySession(other, &Account{ID: 1, Status: StatusActive, Schedulable: true}, ""))
+}
+
+func TestContentPolicyCooldown_AccountScope(t *testing.T) {
+	svc, _ := newContentPolicyCooldownServiceForTest(t, config.ContentPolicyCooldownScopeAccount, 0)
+
+	svc.RecordViolation(contentPolicyUserCtx(7), &Account{ID: 1}, http.StatusBadRequest, contentPolicyErrorBody)
+
+	require.Equal(t, []int64{2}, accountIDsOf(svc.filter(contentPolicyUserCtx(8), contentPolicyCandidates(1, 2))))
+	require.Equal(t, []int64{2}, accountIDsOf(svc.filter(context.Background(), contentPolicyCandidates(1, 2))))
+}
+
+func TestContentPolicyCooldown_SpreadsRiskyUsers(t *testing.T) {
+	svc, cache := newContentPolicyCooldownServiceForTest(t, config.ContentPolicyCooldownScopeUser, 2)
+	now := time.Now()
+	record := func(userID, accountID int64, at time.Time) {
+		_, err := cache.RecordViolation(context.Background(), ContentPolicyViolation{
+			AccountID: accountID, UserID: userID, At: at, CooldownUntil: at.Add(5 * time.Minute), Window: time.Hour,
+		})
+		require.NoError(t, err)
+	}
+	// 账号 1 在窗口内被其它用户触发两次违规，用户 9 在账号 3 上违规过一次（已过冷却）
+	record(7, 1, now.Add(-20*time.Minute))
+	record(8, 1, now.Add(-15*time.Minute))
+	record(9, 3, now.Add(-10*time.Minute))
+
+	require.Equal(t, []int64{2, 3}, accountIDsOf(svc.filter(contentPolicyUserCtx(9), contentPolicyCandidates(1, 2, 3))))
+	// 普通用户不受阈值影响
+	require.Equal(t, []int64{1, 2, 3}, accountIDsOf(svc.filter(contentPolicyUserCtx(10), contentPolicyCandidates(1, 2, 3))))
+}
+
+func TestContentPolicyCooldown_FallsBackWhenNothingLeft(t *testing.T) {
+	svc, _ := newContentPolicyCooldownServiceForTest(t, config.ContentPolicyCooldownScopeUser, 0)
+	ctx := contentPolicyUserCtx(7)
+	svc.RecordViolation(ctx, &Account{ID: 1}, http.StatusBadRequest, contentPolicyErrorBody)
+	svc.RecordViolation(ctx, &Account{ID: 2}, http.StatusBadRequest, contentPolicyErrorBody)
+
+	require.Equal(t, []int64{1, 2}, accountIDsOf(svc.filter(ctx, contentPolicyCandidates(1, 2))))
+}
+
+func TestContentPolicyCooldown_DisabledOrCacheErrorsFailOpen(t *testing.T) {
+	require.Nil(t, NewContentPolicyCooldownService(&config.Config{}, &contentPolicyCooldownCacheStub{}))
+
+	var disabled *ContentPolicyCooldownService
+	disabled.RecordViolation(contentPolicyUserCtx(7), &Account{ID: 1}, http.StatusBadRequest, contentPolicyErrorBody)
+	require.Equal(t, []int64{1, 2}, accountIDsOf(disabled.filter(contentPolicyUserCtx(7), contentPolicyCandidates(1, 2))))
+	require.False(t, disabled.blocks(contentPolicyUserCtx(7), 1))
+	stats, err := disabled.Stats(context.Background())
+	require.NoError(t, err)
+	require.False(t, stats.Enabled)
+
+	svc, cache := newContentPolicyCooldownServiceForTest(t, config.ContentPolicyCooldownScopeUser, 0)
+	svc.RecordViolation(contentPolicyUserCtx(7), &Account{ID: 1}, http.StatusBadRequest, contentPolicyErrorBody)
+	cache.err = errors.New("redis down")
+	require.Equal(t, []int64{1, 2}, accountIDsOf(svc.filter(contentPolicyUserCtx(7), contentPolicyCandidates(1, 2))))
+	_, err = svc.Stats(context.Background())
+	require.Error(t, err)
+}
+
+func TestContentPolicyCooldownStats(t *testing.T) {
+	svc, _ := newContentPolicyCooldownServiceForTest(t, config.ContentPolicyCooldownScopeUser, 3)
+	svc.RecordViolation(contentPolicyUserCtx(7), &Account{ID: 5}, http.StatusBadRequest, contentPolicyErrorBody)
+	svc.RecordViolation(contentPolicyUserCtx(8), &Account{ID: 5}, http.StatusBadRequest, contentPolicyErrorBody)
+	svc.RecordViolation(contentPolicyUserCtx(7), &Account{ID: 6}, http.StatusBadRequest, contentPolicyErrorBody)
+	svc.RecordViolation(contentPolicyUserCtx(7), &Account{ID: 6}, http.StatusBadRequest, []byte(`{"error":{"message":"overloaded"}}`))
+
+	stats, err := svc.Stats(context.Background())
+	require.NoError(t, err)
+	require.True(t, stats.Enabled)
+	require.Equal(t, 2, stats.RiskyUsers)
+	require.Len(t, stats.Accounts, 2)
+	require.Equal(t, int64(5), stats.Accounts[0].AccountID)
+	require.Equal(t, 2, stats.Accounts[0].Violations)
+	require.Equal(t, 2, stats.Accounts[0].CoolingUsers)
+	require.Equal(t, 1, stats.Accounts[1].Violations)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// ErrConversationTranscriptNotFound 会话留存记录不存在（或不属于当前用户）。
+var ErrConversationTranscriptNotFound = infraerrors.NotFound("CONVERSATION_TRANSCRIPT_NOT_FOUND", "conversation transcript not found")
+
+// ConversationTranscript 一条会话留存记录：完整请求消息 + 返回给客户端的最终响应。
+// RequestPayload / ResponsePayload 在服务层为明文，仓储层只保存密文。
+type ConversationTranscript struct {
+	ID                int64     `json:"id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UserID            int64     `json:"user_id"`
+	APIKeyID          int64     `json:"api_key_id"`
+	GroupID           *int64    `json:"group_id,omitempty"`
+	RequestID         string    `json:"request_id"`
+	Endpoint          string    `json:"endpoint"`
+	Model             string    `json:"model"`
+	Stream            bool      `json:"stream"`
+	StatusCode        int       `json:"status_code"`
+	DurationMs        int64     `json:"duration_ms"`
+	RequestPayload    string    `json:"request_payload,omitempty"`
+	ResponsePayload   string    `json:"response_payload,omitempty"`
+	RequestTruncated  bool      `json:"request_truncated"`
+	ResponseTruncated bool      `json:"response_truncated"`
+}
+
+// ConversationTranscriptFilter 会话留存列表查询条件（UserID 必填，保证只能查到自己的记录）。
+type ConversationTranscriptFilter struct {
+	Page     int
+	PageSize int
+
+	UserID    int64
+	APIKeyID  *int64
+	Model     string
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// ConversationTranscriptList 分页结果。
+type ConversationTranscriptList struct {
+	Items    []*ConversationTranscript
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// ConversationTranscriptRepository 会话留存持久化端口。
+type ConversationTranscriptRepository interface {
+	BatchInsert(ctx context.Context, items []*ConversationTranscript) (int64, error)
+	// List 列表不返回 payload，降低载荷；详情接口返回完整记录。
+	List(ctx context.Context, filter *ConversationTranscriptFilter) (*ConversationTranscriptList, error)
+	// GetByIDForUser 按 ID 查询且校验归属，不属于该用户时返回 ErrConversationTranscriptNotFound。
+	GetByIDForUser(ctx context.Context, id, userID int64) (*ConversationTranscript, error)
+	// DeleteBefore 按保留期批量删除，返回本批删除行数（幂等，可多实例并发）。
+	DeleteBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	conversationTranscriptQueueCapacity = 1024
+	conversationTranscriptBatchSize     = 50
+	conversationTranscriptFlushInterval = time.Second
+
+	conversationTranscriptRetentionCheckInterval = 6 * time.Hour
+	conversationTranscriptRetentionStartupDelay  = 5 * time.Minute
+	conversationTranscriptRetentionBatchSize     = 2000
+)
+
+// ConversationTranscriptService 会话全文留存服务。
+// 写入端在网关请求结束后非阻塞入队，由后台协程加密后批量落库；
+// 读取端仅向 Key 所属用户提供查询，详情按需解密。
+type ConversationTranscriptService struct {
+	repo      ConversationTranscriptRepository
+	encryptor SecretEncryptor
+	cfg       *config.Config
+
+	queue chan *ConversationTranscript
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	droppedCount uint64
+	writeFailed  uint64
+	writtenCount uint64
+}
+
+func NewConversationTranscriptService(repo ConversationTranscriptRepository, encryptor SecretEncryptor, cfg *config.Config) *ConversationTranscriptService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ConversationTranscriptService{
+		repo:      repo,
+		encryptor: encryptor,
+		cfg:       cfg,
+		queue:     make(chan *ConversationTranscript, conversationTranscriptQueueCapacity),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start 启动异步写入与保留期清理协程；功能未启用时不启动任何协程。
+func (s *ConversationTranscriptService) Start() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	if s.cfg != nil && s.cfg.Gateway.ConversationTranscript.Enabled && !s.cfg.Totp.EncryptionKeyConfigured {
+		// 自动生成的密钥重启后会变化，已落库的密文将无法解密，因此拒绝写入。
+		logger.LegacyPrintf("service.conversation_transcript", "[ConversationTranscript] disabled: totp.encryption_key is not configured")
+	}
+	if !s.Enabled() {
+		return
+	}
+	s.wg.Add(2)
+	go s.runWriter()
+	go s.runRetentionLoop()
+}
+
+// Stop 停止服务并尽量落盘队列中剩余记录。
+func (s *ConversationTranscriptService) Stop() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Enabled 全局开关已开启且加密密钥为手动配置。
+func (s *ConversationTranscriptService) Enabled() bool {
+	if s == nil || s.repo == nil || s.encryptor == nil || s.cfg == nil {
+		return false
+	}
+	return s.cfg.Gateway.ConversationTranscript.Enabled && s.cfg.Totp.EncryptionKeyConfigured
+}
+
+// ShouldCapture 判断该 API Key 的请求是否需要留存。
+func (s *ConversationTranscriptService) ShouldCapture(apiKey *APIKey) bool {
+	return apiKey != nil && apiKey.TranscriptEnabled && s.Enabled()
+}
+
+// MaxRequestBytes / MaxResponseBytes 单条留存的请求/响应明文上限。
+func (s *ConversationTranscriptService) MaxRequestBytes() int {
+	if s == nil || s.cfg == nil {
+		return 0
+	}
+	return s.cfg.Gateway.ConversationTranscript.MaxRequestBytes
+}
+
+func (s *ConversationTranscriptService) MaxResponseBytes() int {
+	if s == nil || s.cfg == nil {
+		return 0
+	}
+	return s.cfg.Gateway.ConversationTranscript.MaxResponseBytes
+}
+
+// Record 非阻塞入队一条留存记录（payload 为明文，加密在写入协程完成）；队列打满时丢弃并计数。
+func (s *ConversationTranscriptService) Record(entry *ConversationTranscript) {
+	if s == nil || entry == nil || !s.Enabled() {
+		return
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	if limit := s.MaxRequestBytes(); limit > 0 && len(entry.RequestPayload) > limit {
+		entry.RequestPayload = truncateUTF8(entry.RequestPayload, limit)
+		entry.RequestTruncated = true
+	}
+	if limit := s.MaxResponseBytes(); limit > 0 && len(entry.ResponsePayload) > limit {
+		entry.ResponsePayload = truncateUTF8(entry.ResponsePayload, limit)
+		entry.ResponseTruncated = true
+	}
+	select {
+	case <-s.ctx.Done():
+		return
+	default:
+	}
+	select {
+	case s.queue <- entry:
+	default:
+		atomic.AddUint64(&s.droppedCount, 1)
+	}
+}
+
+// ListForUser 分页查询当前用户的留存记录（不含 payload）。
+func (s *ConversationTranscriptService) ListForUser(ctx context.Context, userID int64, filter *ConversationTranscriptFilter) (*ConversationTranscriptList, error) {
+	if filter == nil {
+		filter = &ConversationTranscriptFilter{}
+	}
+	filter.UserID = userID
+	return s.repo.List(ctx, filter)
+}
+
+// GetForUser 查询单条留存详情并解密 payload。
+func (s *ConversationTranscriptService) GetForUser(ctx context.Context, userID, id int64) (*ConversationTranscript, error) {
+	item, err := s.repo.GetByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if item.RequestPayload, err = s.decrypt(item.RequestPayload); err != nil {
+		return nil, fmt.Errorf("decrypt transcript request: %w", err)
+	}
+	if item.ResponsePayload, err = s.decrypt(item.ResponsePayload); err != nil {
+		return nil, fmt.Errorf("decrypt transcript response: %w", err)
+	}
+	return item, nil
+}
+
+func (s *ConversationTranscriptService) encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	return s.encryptor.Encrypt(plaintext)
+}
+
+func (s *ConversationTranscriptService) decrypt(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+	if s.encryptor == nil {
+		return "", fmt.Errorf("encryptor not configured")
+	}
+	return s.encryptor.Decrypt(ciphertext)
+}
+
+// sealEntry 将明文 payload 原地替换为密文。
+func (s *ConversationTranscriptService) sealEntry(entry *ConversationTranscript) error {
+	var err error
+	if entry.RequestPayload, err = s.encrypt(entry.RequestPayload); err != nil {
+		return err
+	}
+	if entry.ResponsePayload, err = s.encrypt(entry.ResponsePayload); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ConversationTranscriptService) runWriter() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(conversationTranscriptFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*ConversationTranscript, 0, conversationTranscriptBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		inserted, err := s.repo.BatchInsert(ctx, batch)
+		cancel()
+		if err != nil {
+			atomic.AddUint64(&s.writeFailed, uint64(len(batch)))
+			logger.LegacyPrintf("service.conversation_transcript", "[ConversationTranscript] flush failed: batch=%d err=%v", len(batch), err)
+		} else {
+			atomic.AddUint64(&s.writtenCount, uint64(inserted))
+		}
+		batch = batch[:0]
+	}
+	add := func(item *ConversationTranscript) {
+		if item == nil {
+			return
+		}
+		if err := s.sealEntry(item); err != nil {
+			atomic.AddUint64(&s.writeFailed, 1)
+			logger.LegacyPrintf("service.conversation_transcript", "[ConversationTranscript] encrypt failed: request_id=%s err=%v", item.RequestID, err)
+			return
+		}
+		batch = append(batch, item)
+		if len(batch) >= conversationTranscriptBatchSize {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			// 停机前排空队列。
+			for {
+				select {
+				case item := <-s.queue:
+					add(item)
+				default:
+					flush()
+					return
+				}
+			}
+		case item := <-s.queue:
+			add(item)
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// runRetentionLoop 按保留期定期删除过期留存记录。
+// 删除操作幂等，多实例并发执行无害，因此无需选主。
+func (s *ConversationTranscriptService) runRetentionLoop() {
+	defer s.wg.Done()
+
+	startupTimer := time.NewTimer(conversationTranscriptRetentionStartupDelay)
+	defer startupTimer.Stop()
+	select {
+	case <-s.ctx.Done():
+		return
+	case <-startupTimer.C:
+	}
+
+	ticker := time.NewTicker(conversationTranscriptRetentionCheckInterval)
+	defer ticker.Stop()
+
+	s.runRetentionOnce()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.runRetentionOnce()
+		}
+	}
+}
+
+func (s *ConversationTranscriptService) runRetentionOnce() {
+	if s.cfg == nil {
+		return
+	}
+	days := s.cfg.Gateway.ConversationTranscript.RetentionDays
+	if days <= 0 {
+		return // 0 表示永久保留
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Minute)
+	defer cancel()
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+	for {
+		deleted, err := s.repo.DeleteBefore(ctx, cutoff, conversationTranscriptRetentionBatchSize)
+		if err != nil {
+			logger.LegacyPrintf("service.conversation_transcript", "[ConversationTranscript] retention cleanup failed: %v", err)
+			return
+		}
+		if deleted == 0 {
+			return
+		}
+	}
+}
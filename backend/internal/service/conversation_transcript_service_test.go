@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type transcriptTestEncryptor struct{}
+
+func (transcriptTestEncryptor) Encrypt(plaintext string) (string, error) {
+	return "enc:" + plaintext, nil
+}
+
+func (transcriptTestEncryptor) Decrypt(ciphertext string) (string, error) {
+	rest, ok := strings.CutPrefix(ciphertext, "enc:")
+	if !ok {
+		return "", errors.New("not encrypted")
+	}
+	return rest, nil
+}
+
+type transcriptRepoStub struct {
+	mu       sync.Mutex
+	inserted []*ConversationTranscript
+	stored   *ConversationTranscript
+}
+
+func (r *transcriptRepoStub) BatchInsert(_ context.Context, items []*ConversationTranscript) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inserted = append(r.inserted, items...)
+	return int64(len(items)), nil
+}
+
+func (r *transcriptRepoStub) List(_ context.Context, filter *ConversationTranscriptFilter) (*ConversationTranscriptList, error) {
+	return &ConversationTranscriptList{Page: filter.Page, PageSize: filter.PageSize}, nil
+}
+
+func (r *transcriptRepoStub) GetByIDForUser(_ context.Context, id, userID int64) (*ConversationTranscript, error) {
+	if r.stored == nil || r.stored.ID != id || r.stored.UserID != userID {
+		return nil, ErrConversationTranscriptNotFound
+	}
+	cp := *r.stored
+	return &cp, nil
+}
+
+func (r *transcriptRepoStub) DeleteBefore(context.Context, time.Time, int) (int64, error) {
+	return 0, nil
+}
+
+func (r *transcriptRepoStub) snapshot() []*ConversationTranscript {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*ConversationTranscript(nil), r.inserted...)
+}
+
+func newTranscriptTestService(repo ConversationTranscriptRepository) *ConversationTranscriptService {
+	cfg := &config.Config{}
+	cfg.Totp.EncryptionKeyConfigured = true
+	cfg.Gateway.ConversationTranscript = config.GatewayConversationTranscriptConfig{
+		Enabled:          true,
+		RetentionDays:    30,
+		MaxRequestBytes:  16,
+		MaxResponseBytes: 8,
+	}
+	return NewConversationTranscriptService(repo, transcriptTestEncryptor{}, cfg)
+}
+
+func TestConversationTranscriptService_ShouldCapture(t *testing.T) {
+	svc := newTranscriptTestService(&transcriptRepoStub{})
+	require.True(t, svc.ShouldCapture(&APIKey{TranscriptEnabled: true}))
+	require.False(t, svc.ShouldCapture(&APIKey{}))
+
+	svc.cfg.Totp.EncryptionKeyConfigured = false
+	require.False(t, svc.ShouldCapture(&APIKey{TranscriptEnabled: true}), "auto-generated key must not be used for transcripts")
+
+	var nilSvc *ConversationTranscriptService
+	require.False(t, nilSvc.ShouldCapture(&APIKey{TranscriptEnabled: true}))
+}
+
+func TestConversationTranscriptService_RecordEncryptsAndTruncates(t *testing.T) {
+	repo := &transcriptRepoStub{}
+	svc := newTranscriptTestService(repo)
+	svc.Start()
+
+	svc.Record(&ConversationTranscript{
+		UserID:          1,
+		APIKeyID:        2,
+		RequestPayload:  `{"messages":[{"role":"user","content":"hi"}]}`,
+		ResponsePayload: "short",
+	})
+	svc.Stop()
+
+	items := repo.snapshot()
+	require.Len(t, items, 1)
+	require.Equal(t, `enc:{"messages":[{"r`, items[0].RequestPayload)
+	require.True(t, items[0].RequestTruncated)
+	require.Equal(t, "enc:short", items[0].ResponsePayload)
+	require.False(t, items[0].ResponseTruncated)
+}
+
+func TestConversationTranscriptService_StartSkipsWorkersWhenDisabled(t *testing.T) {
+	repo := &transcriptRepoStub{}
+	svc := newTranscriptTestService(repo)
+	svc.cfg.Gateway.ConversationTranscript.Enabled = false
+	svc.Start()
+
+	// 启动后再打开开关：记录只会入队，没有写入协程消费
+	svc.cfg.Gateway.ConversationTranscript.Enabled = true
+	svc.Record(&ConversationTranscript{UserID: 1, APIKeyID: 2, RequestPayload: "{}"})
+	svc.Stop()
+
+	require.Empty(t, repo.snapshot())
+}
+
+func TestConversationTranscriptService_GetForUserDecryptsAndChecksOwner(t *testing.T) {
+	repo := &transcriptRepoStub{stored: &ConversationTranscript{
+		ID:              7,
+		UserID:          1,
+		RequestPayload:  "enc:req",
+		ResponsePayload: "enc:resp",
+	}}
+	svc := newTranscriptTestService(repo)
+
+	item, err := svc.GetForUser(context.Background(), 1, 7)
+	require.NoError(t, err)
+	require.Equal(t, "req", item.RequestPayload)
+	require.Equal(t, "resp", item.ResponsePayload)
+
+	_, err = svc.GetForUser(context.Background(), 2, 7)
+	require.ErrorIs(t, err, ErrConversationTranscriptNotFound)
+}
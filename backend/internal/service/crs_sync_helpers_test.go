@@ -170,3 +170,16 @@ func TestReconcileCRSUpstreamBillingProbeExtra(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCRSExport(t *testing.T) {
+	parsed, err := parseCRSExport([]byte(`{"success":true,"data":{"claudeAccounts":[{"id":"c1","kind":"claude","name":"main"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, parsed.Data.ClaudeAccounts, 1)
+	require.Equal(t, "c1", parsed.Data.ClaudeAccounts[0].ID)
+
+	_, err = parseCRSExport([]byte(`{"success":false,"message":"forbidden"}`))
+	require.EqualError(t, err, "crs export failed: forbidden")
+
+	_, err = parseCRSExport([]byte(`not json`))
+	require.ErrorContains(t, err, "crs export parse failed")
+}
@@ -265,7 +265,21 @@ func (s *CRSSyncService) SyncFromCRS(ctx context.Context, input SyncFromCRSInput
 	if err != nil {
 		return nil, err
 	}
+	return s.syncCRSExport(ctx, input, exported)
+}
 
+// ImportCRSExport applies a saved CRS export (the JSON returned by CRS's
+// /admin/sync/export-accounts?include_secrets=true) without connecting to CRS.
+// BaseURL/Username/Password in input are ignored.
+func (s *CRSSyncService) ImportCRSExport(ctx context.Context, raw []byte, input SyncFromCRSInput) (*SyncFromCRSResult, error) {
+	exported, err := parseCRSExport(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s.syncCRSExport(ctx, input, exported)
+}
+
+func (s *CRSSyncService) syncCRSExport(ctx context.Context, input SyncFromCRSInput, exported *crsExportResponse) (*SyncFromCRSResult, error) {
 	now := time.Now().UTC().Format(time.RFC3339)
 
 	result := &SyncFromCRSResult{
@@ -1366,7 +1380,10 @@ func crsExportAccounts(ctx context.Context, client *http.Client, baseURL, adminT
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("crs export failed: status=%d body=%s", resp.StatusCode, string(raw))
 	}
+	return parseCRSExport(raw)
+}
 
+func parseCRSExport(raw []byte) (*crsExportResponse, error) {
 	var parsed crsExportResponse
 	if err := json.Unmarshal(raw, &parsed); err != nil {
 		return nil, fmt.Errorf("crs export parse failed: %w", err)
@@ -1510,7 +1527,19 @@ func (s *CRSSyncService) PreviewFromCRS(ctx context.Context, input SyncFromCRSIn
 	if err != nil {
 		return nil, err
 	}
+	return s.previewCRSExport(ctx, exported)
+}
+
+// PreviewCRSExport classifies the accounts of a saved CRS export like PreviewFromCRS.
+func (s *CRSSyncService) PreviewCRSExport(ctx context.Context, raw []byte) (*PreviewFromCRSResult, error) {
+	exported, err := parseCRSExport(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s.previewCRSExport(ctx, exported)
+}
 
+func (s *CRSSyncService) previewCRSExport(ctx context.Context, exported *crsExportResponse) (*PreviewFromCRSResult, error) {
 	// Batch query all existing CRS account IDs
 	existingCRSIDs, err := s.accountRepo.ListCRSAccountIDs(ctx)
 	if err != nil {
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 数据驻留区域。账号（extra.data_residency）与代理（proxies.data_residency）按所在区域打标，
+// API Key 可要求请求只经由指定区域的账号与代理出站；空字符串表示未打标 / 不限制。
+const (
+	DataResidencyEU   = "eu"
+	DataResidencyUS   = "us"
+	DataResidencyAsia = "asia"
+)
+
+// DataResidencyRegions 全部已知的驻留区域。
+var DataResidencyRegions = []string{DataResidencyEU, DataResidencyUS, DataResidencyAsia}
+
+// AccountDataResidencyExtraKey 账号区域标签在 extra 中的键
+const AccountDataResidencyExtraKey = "data_residency"
+
+var ErrInvalidDataResidency = infraerrors.BadRequest("INVALID_DATA_RESIDENCY", "data residency must be one of eu, us, asia")
+
+type dataResidencyContextKey struct{}
+
+// NormalizeDataResidency 规范化区域标签（小写、去空白）；空值合法，未知区域返回 ErrInvalidDataResidency。
+func NormalizeDataResidency(region string) (string, error) {
+	region = strings.ToLower(strings.TrimSpace(region))
+	if region == "" || slices.Contains(DataResidencyRegions, region) {
+		return region, nil
+	}
+	return "", infraerrors.Newf(http.StatusBadRequest, ErrInvalidDataResidency.Reason, "unknown data residency %q (expected eu, us or asia)", region)
+}
+
+// normalizeAccountDataResidencyExtra 校验并规范化账号 extra 中的区域标签（空字符串表示清除标签）。
+func normalizeAccountDataResidencyExtra(extra map[string]any) error {
+	raw, ok := extra[AccountDataResidencyExtraKey]
+	if !ok {
+		return nil
+	}
+	value, isString := raw.(string)
+	if raw != nil && !isString {
+		return infraerrors.Newf(http.StatusBadRequest, ErrInvalidDataResidency.Reason, "extra.%s must be a string", AccountDataResidencyExtraKey)
+	}
+	region, err := NormalizeDataResidency(value)
+	if err != nil {
+		return err
+	}
+	extra[AccountDataResidencyExtraKey] = region
+	return nil
+}
+
+// DataResidency 返回账号实际可满足的驻留区域：账号须打标，且绑定的代理（如有）须打同一区域标签，
+// 否则出口不在该区域，返回空。调度快照的元数据账号不含代理，此时 extra 中已是按同一规则计算后的结果。
+func (a *Account) DataResidency() string {
+	if a == nil || a.Extra == nil {
+		return ""
+	}
+	region, _ := a.Extra[AccountDataResidencyExtraKey].(string)
+	if region == "" {
+		return ""
+	}
+	if a.Proxy != nil && a.Proxy.DataResidency != region {
+		return ""
+	}
+	return region
+}
+
+// SatisfiesDataResidency 判断账号能否服务要求指定区域的请求，region 为空表示不限制。
+func (a *Account) SatisfiesDataResidency(region string) bool {
+	return region == "" || a.DataResidency() == region
+}
+
+// WithDataResidency 在 context 中记录本次请求要求的驻留区域（来自 API Key）。
+func WithDataResidency(ctx context.Context, region string) context.Context {
+	if region == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, dataResidencyContextKey{}, region)
+}
+
+// DataResidencyFromContext 读取本次请求要求的驻留区域，不限制时返回空。
+func DataResidencyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	region, _ := ctx.Value(dataResidencyContextKey{}).(string)
+	return region
+}
+
+// filterAccountsByDataResidency 按请求要求的驻留区域过滤候选账号；未要求时原样返回。
+func filterAccountsByDataResidency(ctx context.Context, accounts []Account) []Account {
+	region := DataResidencyFromContext(ctx)
+	if region == "" || len(accounts) == 0 {
+		return accounts
+	}
+	filtered := make([]Account, 0, len(accounts))
+	for i := range accounts {
+		if accounts[i].SatisfiesDataResidency(region) {
+			filtered = append(filtered, accounts[i])
+		}
+	}
+	return filtered
+}
+
+// checkAccountDataResidency 校验单个账号（粘性会话、固定账号等绕过候选列表的路径）是否满足驻留要求。
+func checkAccountDataResidency(ctx context.Context, account *Account) error {
+	region := DataResidencyFromContext(ctx)
+	if account == nil || account.SatisfiesDataResidency(region) {
+		return nil
+	}
+	return fmt.Errorf("%w: account %d does not satisfy data residency %q", ErrNoAvailableAccounts, account.ID, region)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDataResidency(t *testing.T) {
+	got, err := NormalizeDataResidency(" EU ")
+	require.NoError(t, err)
+	require.Equal(t, DataResidencyEU, got)
+
+	got, err = NormalizeDataResidency("")
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = NormalizeDataResidency("mars")
+	require.Equal(t, ErrInvalidDataResidency.Reason, infraerrors.Reason(err))
+}
+
+func TestNormalizeAccountDataResidencyExtra(t *testing.T) {
+	extra := map[string]any{AccountDataResidencyExtraKey: "Asia"}
+	require.NoError(t, normalizeAccountDataResidencyExtra(extra))
+	require.Equal(t, DataResidencyAsia, extra[AccountDataResidencyExtraKey])
+
+	extra[AccountDataResidencyExtraKey] = nil
+	require.NoError(t, normalizeAccountDataResidencyExtra(extra))
+	require.Equal(t, "", extra[AccountDataResidencyExtraKey])
+
+	require.Error(t, normalizeAccountDataResidencyExtra(map[string]any{AccountDataResidencyExtraKey: 1}))
+	require.Error(t, normalizeAccountDataResidencyExtra(map[string]any{AccountDataResidencyExtraKey: "mars"}))
+}
+
+func TestAccountDataResidencyRequiresMatchingProxy(t *testing.T) {
+	account := &Account{Extra: map[string]any{AccountDataResidencyExtraKey: DataResidencyEU}}
+	require.Equal(t, DataResidencyEU, account.DataResidency())
+	require.True(t, account.SatisfiesDataResidency(DataResidencyEU))
+	require.True(t, account.SatisfiesDataResidency(""))
+	require.False(t, account.SatisfiesDataResidency(DataResidencyUS))
+
+	account.Proxy = &Proxy{DataResidency: DataResidencyEU}
+	require.True(t, account.SatisfiesDataResidency(DataResidencyEU))
+
+	// 代理出口在其它区域（或未打标）时，账号不满足任何驻留要求
+	account.Proxy = &Proxy{DataResidency: DataResidencyUS}
+	require.False(t, account.SatisfiesDataResidency(DataResidencyEU))
+	account.Proxy = &Proxy{}
+	require.False(t, account.SatisfiesDataResidency(DataResidencyEU))
+
+	require.False(t, (&Account{}).SatisfiesDataResidency(DataResidencyEU))
+}
+
+func TestSelectAccountWithLoadAwareness_EnforcesDataResidency(t *testing.T) {
+	us := &Account{ID: 1, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 1,
+		Extra: map[string]any{AccountDataResidencyExtraKey: DataResidencyUS}}
+	eu := &Account{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 50,
+		Extra: map[string]any{AccountDataResidencyExtraKey: DataResidencyEU}}
+	svc := newAccountPinningTestService(us, eu)
+	base := context.WithValue(context.Background(), ctxkey.ForcePlatform, PlatformAnthropic)
+
+	result, err := svc.SelectAccountWithLoadAwareness(WithDataResidency(base, DataResidencyEU), nil, "", "", nil, "", 0)
+	require.NoError(t, err)
+	require.Equal(t, eu.ID, result.Account.ID, "higher-priority account outside the region must be skipped")
+
+	_, err = svc.SelectAccountWithLoadAwareness(WithDataResidency(base, DataResidencyAsia), nil, "", "", nil, "", 0)
+	require.True(t, errors.Is(err, ErrNoAvailableAccounts))
+
+	_, err = svc.SelectAccountWithLoadAwareness(WithPinnedAccountID(WithDataResidency(base, DataResidencyEU), us.ID), nil, "", "", nil, "", 0)
+	require.True(t, errors.Is(err, ErrNoAvailableAccounts))
+	require.Contains(t, err.Error(), "data residency")
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type flakyDatabasePinger struct {
+	down atomic.Bool
+}
+
+func (p *flakyDatabasePinger) PingDatabase(context.Context) error {
+	if p.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func newTestDatabaseHealthMonitor(pinger DatabasePinger) *DatabaseHealthMonitor {
+	cfg := &config.Config{}
+	cfg.Database.Degraded = config.DatabaseDegradedConfig{
+		Enabled:              true,
+		ProbeIntervalSeconds: 1,
+		ProbeTimeoutSeconds:  1,
+		FailureThreshold:     2,
+		RecoveryThreshold:    2,
+		StaleTTLSeconds:      60,
+	}
+	return NewDatabaseHealthMonitor(pinger, cfg)
+}
+
+func TestDatabaseHealthMonitor_EntersAndLeavesDegradedMode(t *testing.T) {
+	pinger := &flakyDatabasePinger{}
+	m := newTestDatabaseHealthMonitor(pinger)
+	ctx := context.Background()
+
+	pinger.down.Store(true)
+	m.probeOnce(ctx)
+	require.False(t, m.Degraded(), "single failure stays below threshold")
+	m.probeOnce(ctx)
+	require.True(t, m.Degraded())
+	st := m.Status()
+	require.NotNil(t, st.DegradedSince)
+	require.Equal(t, "connection refused", st.LastError)
+
+	pinger.down.Store(false)
+	m.probeOnce(ctx)
+	require.True(t, m.Degraded(), "single success stays below recovery threshold")
+	m.probeOnce(ctx)
+	require.False(t, m.Degraded())
+	st = m.Status()
+	require.Nil(t, st.DegradedSince)
+	require.Equal(t, uint64(2), st.Transitions)
+}
+
+func TestIsDatabaseDegraded_NilMonitor(t *testing.T) {
+	SetDefaultDatabaseHealthMonitor(nil)
+	require.False(t, IsDatabaseDegraded())
+	require.False(t, DefaultDatabaseHealthMonitor().Status().Degraded)
+}
+
+type flakySettingRepo struct {
+	SettingRepository
+	down   atomic.Bool
+	calls  atomic.Int32
+	values map[string]string
+}
+
+func (r *flakySettingRepo) Get(_ context.Context, key string) (*Setting, error) {
+	r.calls.Add(1)
+	if r.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	v, ok := r.values[key]
+	if !ok {
+		return nil, ErrSettingNotFound
+	}
+	return &Setting{Key: key, Value: v}, nil
+}
+
+func (r *flakySettingRepo) GetValue(ctx context.Context, key string) (string, error) {
+	s, err := r.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return s.Value, nil
+}
+
+func (r *flakySettingRepo) GetMultiple(_ context.Context, keys []string) (map[string]string, error) {
+	r.calls.Add(1)
+	if r.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	out := make(map[string]string)
+	for _, k := range keys {
+		if v, ok := r.values[k]; ok {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+func (r *flakySettingRepo) GetAll(_ context.Context) (map[string]string, error) {
+	r.calls.Add(1)
+	if r.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	out := make(map[string]string, len(r.values))
+	for k, v := range r.values {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func TestStaleFallbackSettingRepository_ServesLastKnownValuesOnDBError(t *testing.T) {
+	inner := &flakySettingRepo{values: map[string]string{"a": "1", "b": "2"}}
+	repo := NewStaleFallbackSettingRepository(inner, time.Minute)
+	ctx := context.Background()
+
+	v, err := repo.GetValue(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "1", v)
+	_, err = repo.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrSettingNotFound)
+	_, err = repo.GetMultiple(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+
+	inner.down.Store(true)
+	v, err = repo.GetValue(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "1", v)
+	_, err = repo.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrSettingNotFound, "known-missing keys stay missing")
+	values, err := repo.GetMultiple(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "1", "b": "2"}, values)
+
+	_, err = repo.GetValue(ctx, "never-read")
+	require.Error(t, err, "keys without a last-known value surface the DB error")
+	_, err = repo.GetAll(ctx)
+	require.Error(t, err, "GetAll needs a full snapshot")
+}
+
+func TestStaleFallbackSettingRepository_SkipsDBWhileDegraded(t *testing.T) {
+	inner := &flakySettingRepo{values: map[string]string{"a": "1"}}
+	repo := NewStaleFallbackSettingRepository(inner, time.Minute)
+	ctx := context.Background()
+
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "1"}, all)
+
+	pinger := &flakyDatabasePinger{}
+	pinger.down.Store(true)
+	m := newTestDatabaseHealthMonitor(pinger)
+	m.probeOnce(ctx)
+	m.probeOnce(ctx)
+	SetDefaultDatabaseHealthMonitor(m)
+	t.Cleanup(func() { SetDefaultDatabaseHealthMonitor(nil) })
+
+	before := inner.calls.Load()
+	all, err = repo.GetAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "1"}, all)
+	v, err := repo.GetValue(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "1", v)
+	require.Equal(t, before, inner.calls.Load(), "degraded reads must not hit the database")
+}
+
+func TestWriteUsageLogBestEffort_DefersWhileDegraded(t *testing.T) {
+	pinger := &flakyDatabasePinger{}
+	pinger.down.Store(true)
+	m := newTestDatabaseHealthMonitor(pinger)
+	m.probeOnce(context.Background())
+	m.probeOnce(context.Background())
+	SetDefaultDatabaseHealthMonitor(m)
+	t.Cleanup(func() { SetDefaultDatabaseHealthMonitor(nil) })
+
+	cache := &memoryUsageRecordDeadLetterCache{}
+	SetDefaultUsageRecordDeadLetterService(newTestUsageRecordDeadLetterService(cache, nil, nil))
+	t.Cleanup(func() { SetDefaultUsageRecordDeadLetterService(nil) })
+
+	logRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	writeUsageLogBestEffort(context.Background(), logRepo, &UsageLog{RequestID: "req-degraded"}, "service.test")
+	require.Equal(t, 0, logRepo.calls)
+	require.Len(t, cache.entries, 1)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// DatabasePinger 数据库连通性探测
+type DatabasePinger interface {
+	PingDatabase(ctx context.Context) error
+}
+
+// DatabaseHealthStatus 数据库健康状态（/healthz 与 ops 接口使用）
+type DatabaseHealthStatus struct {
+	Enabled             bool       `json:"enabled"`
+	Degraded            bool       `json:"degraded"`
+	DegradedSince       *time.Time `json:"degraded_since,omitempty"`
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	LastProbeAt         *time.Time `json:"last_probe_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	Transitions         uint64     `json:"transitions"`
+}
+
+// DatabaseHealthMonitor 周期性探测数据库，连续失败达到阈值后进入降级模式，连续成功达到阈值后恢复。
+//
+// 降级期间：API Key 认证与系统设置读取优先使用进程内的过期缓存（不再等待数据库超时），
+// 使用量与计费写入直接进入死信队列，由回放 worker 在数据库恢复后补写。
+// 只读取 IsDatabaseDegraded() 的热路径不持有任何锁。
+type DatabaseHealthMonitor struct {
+	pinger DatabasePinger
+	cfg    config.DatabaseDegradedConfig
+
+	degraded            atomic.Bool
+	degradedSince       atomic.Int64 // unix nano
+	consecutiveFailures atomic.Int64
+	consecutiveOK       atomic.Int64
+	lastProbeAt         atomic.Int64 // unix nano
+	lastError           atomic.Value // string
+	transitions         atomic.Uint64
+
+	stopCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewDatabaseHealthMonitor 创建数据库健康监控
+func NewDatabaseHealthMonitor(pinger DatabasePinger, cfg *config.Config) *DatabaseHealthMonitor {
+	m := &DatabaseHealthMonitor{pinger: pinger, stopCh: make(chan struct{})}
+	if cfg != nil {
+		m.cfg = cfg.Database.Degraded
+	}
+	m.lastError.Store("")
+	return m
+}
+
+// Enabled 是否启用降级模式
+func (m *DatabaseHealthMonitor) Enabled() bool {
+	return m != nil && m.cfg.Enabled && m.pinger != nil
+}
+
+// Degraded 当前是否处于降级模式
+func (m *DatabaseHealthMonitor) Degraded() bool {
+	return m != nil && m.degraded.Load()
+}
+
+// StaleTTL 降级期间过期缓存的最长可用时间；0 表示不使用过期缓存
+func (m *DatabaseHealthMonitor) StaleTTL() time.Duration {
+	if !m.Enabled() || m.cfg.StaleTTLSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(m.cfg.StaleTTLSeconds) * time.Second
+}
+
+// Start 启动探测循环
+func (m *DatabaseHealthMonitor) Start() {
+	if !m.Enabled() {
+		return
+	}
+	m.startOnce.Do(func() {
+		m.wg.Add(1)
+		go m.run()
+	})
+}
+
+// Stop 停止探测循环
+func (m *DatabaseHealthMonitor) Stop() {
+	if m == nil {
+		return
+	}
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+		m.wg.Wait()
+	})
+}
+
+func (m *DatabaseHealthMonitor) run() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(time.Duration(m.cfg.ProbeIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.probeOnce(context.Background())
+		}
+	}
+}
+
+func (m *DatabaseHealthMonitor) probeOnce(ctx context.Context) {
+	timeout := time.Duration(m.cfg.ProbeTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	err := m.pinger.PingDatabase(probeCtx)
+	cancel()
+	m.lastProbeAt.Store(time.Now().UnixNano())
+
+	if err != nil {
+		m.lastError.Store(err.Error())
+		m.consecutiveOK.Store(0)
+		failures := m.consecutiveFailures.Add(1)
+		if failures >= int64(max(m.cfg.FailureThreshold, 1)) && m.degraded.CompareAndSwap(false, true) {
+			m.degradedSince.Store(time.Now().UnixNano())
+			m.transitions.Add(1)
+			logger.LegacyPrintf("service.db_health", "[DBHealth] ALERT: database unreachable after %d probes, entering degraded mode: %v", failures, err)
+		}
+		return
+	}
+
+	m.consecutiveFailures.Store(0)
+	ok := m.consecutiveOK.Add(1)
+	if m.degraded.Load() && ok >= int64(max(m.cfg.RecoveryThreshold, 1)) && m.degraded.CompareAndSwap(true, false) {
+		since := time.Unix(0, m.degradedSince.Swap(0))
+		m.lastError.Store("")
+		m.transitions.Add(1)
+		logger.LegacyPrintf("service.db_health", "[DBHealth] database recovered, leaving degraded mode after %s", time.Since(since).Round(time.Second))
+	}
+}
+
+// Status 返回当前健康状态快照
+func (m *DatabaseHealthMonitor) Status() DatabaseHealthStatus {
+	if m == nil {
+		return DatabaseHealthStatus{}
+	}
+	st := DatabaseHealthStatus{
+		Enabled:             m.Enabled(),
+		Degraded:            m.degraded.Load(),
+		ConsecutiveFailures: m.consecutiveFailures.Load(),
+		Transitions:         m.transitions.Load(),
+	}
+	if v, ok := m.lastError.Load().(string); ok {
+		st.LastError = v
+	}
+	if ts := m.lastProbeAt.Load(); ts > 0 {
+		t := time.Unix(0, ts).UTC()
+		st.LastProbeAt = &t
+	}
+	if ts := m.degradedSince.Load(); ts > 0 && st.Degraded {
+		t := time.Unix(0, ts).UTC()
+		st.DegradedSince = &t
+	}
+	return st
+}
+
+var defaultDatabaseHealthMonitor atomic.Pointer[DatabaseHealthMonitor]
+
+// SetDefaultDatabaseHealthMonitor 注册全局数据库健康监控（由 wire 在启动时设置）
+func SetDefaultDatabaseHealthMonitor(m *DatabaseHealthMonitor) {
+	defaultDatabaseHealthMonitor.Store(m)
+}
+
+// DefaultDatabaseHealthMonitor 返回全局数据库健康监控（未注册时为 nil）
+func DefaultDatabaseHealthMonitor() *DatabaseHealthMonitor {
+	return defaultDatabaseHealthMonitor.Load()
+}
+
+// IsDatabaseDegraded 数据库当前是否处于降级模式
+func IsDatabaseDegraded() bool {
+	return defaultDatabaseHealthMonitor.Load().Degraded()
+}
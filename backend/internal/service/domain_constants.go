@@ -401,6 +401,10 @@ const (
 	// SettingKeyStreamTimeoutSettings stores JSON config for stream timeout handling.
 	SettingKeyStreamTimeoutSettings = "stream_timeout_settings"
 
+	// SettingKeyUpstreamTimeoutProfiles stores JSON per-platform upstream response timeouts
+	// (streaming / non-streaming / utility requests).
+	SettingKeyUpstreamTimeoutProfiles = "upstream_timeout_profiles"
+
 	// =========================
 	// Request Rectifier (请求整流器)
 	// =========================
@@ -415,6 +419,10 @@ const (
 	// SettingKeyBetaPolicySettings stores JSON config for beta policy rules.
 	SettingKeyBetaPolicySettings = "beta_policy_settings"
 
+	// SettingKeyGatewayCanarySettings stores JSON config for the gateway canary
+	// channel (candidate forwarding/beta/scheduler settings for a traffic subset).
+	SettingKeyGatewayCanarySettings = "gateway_canary_settings"
+
 	// SettingKeyOpenAIFastPolicySettings stores JSON config for OpenAI
 	// service_tier (fast/flex) policy rules. Mirrors BetaPolicySettings but
 	// targets OpenAI's body-level service_tier field instead of Claude's
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// 支持的错误文案语言
+const (
+	ErrorLocaleEN = "en"
+	ErrorLocaleZH = "zh"
+)
+
+// errorTranslationRule 一条上游错误标准化规则。
+// 命中条件为任一满足：错误类型/代码命中 types、消息包含 keywords（小写匹配）、HTTP 状态码命中 statuses。
+// keepMessage 为 true 时不改写 error.message（客户端依赖原文识别该错误），统一文案只写入 error.localized_message。
+type errorTranslationRule struct {
+	code        string
+	types       []string
+	keywords    []string
+	statuses    []int
+	en          string
+	zh          string
+	keepMessage bool
+}
+
+// errorLocalizedMessagePath 保留原始消息时统一文案的写入位置
+const errorLocalizedMessagePath = "error.localized_message"
+
+// errorTranslationRules 按优先级排列：先按消息关键字识别具体原因，再按类型/状态码兜底。
+var errorTranslationRules = []errorTranslationRule{
+	{
+		code:     "context_length_exceeded",
+		types:    []string{"context_length_exceeded"},
+		keywords: []string{"prompt is too long", "context length", "context window", "maximum context", "exceed context limit", "too many tokens"},
+		en:       "The request exceeds the model's context length, please shorten the input",
+		zh:       "请求超出模型上下文长度限制，请缩短输入内容",
+		// Claude Code 等客户端按 "prompt is too long" 原文触发自动压缩，不能改写
+		keepMessage: true,
+	},
+	{
+		code:     "content_policy_violation",
+		types:    []string{"content_policy_violation", "content_filter", "SAFETY"},
+		keywords: []string{"content policy", "content management policy", "safety system", "flagged"},
+		en:       "The request was rejected by the upstream content policy",
+		zh:       "请求被上游内容安全策略拒绝",
+	},
+	{
+		code:     "model_not_found",
+		types:    []string{"model_not_found"},
+		keywords: []string{"model not found", "does not exist or you do not have access", "unsupported model", "model is not supported"},
+		en:       "The requested model is not available",
+		zh:       "请求的模型不可用",
+	},
+	{
+		code:     "upstream_quota_exhausted",
+		types:    []string{"insufficient_quota", "billing_error"},
+		keywords: []string{"credit balance is too low", "exceeded your current quota", "insufficient balance"},
+		en:       "Upstream quota exhausted, please contact administrator",
+		zh:       "上游额度已用尽，请联系管理员",
+	},
+	{
+		code:     "rate_limited",
+		types:    []string{"rate_limit_error", "rate_limit_exceeded", "RESOURCE_EXHAUSTED"},
+		keywords: []string{"rate limit", "too many requests"},
+		statuses: []int{http.StatusTooManyRequests},
+		en:       "Upstream rate limit exceeded, please retry later",
+		zh:       "上游请求频率超限，请稍后重试",
+	},
+	{
+		code:     "upstream_overloaded",
+		types:    []string{"overloaded_error", "UNAVAILABLE"},
+		keywords: []string{"overloaded", "over capacity"},
+		statuses: []int{529, http.StatusServiceUnavailable},
+		en:       "Upstream service overloaded, please retry later",
+		zh:       "上游服务繁忙，请稍后重试",
+	},
+	{
+		code:     "upstream_auth_failed",
+		types:    []string{"authentication_error", "permission_error", "PERMISSION_DENIED", "UNAUTHENTICATED", "invalid_api_key"},
+		keywords: []string{"upstream authentication failed", "upstream access forbidden"},
+		en:       "Upstream authentication failed, please contact administrator",
+		zh:       "上游认证失败，请联系管理员",
+	},
+	{
+		code:     "upstream_timeout",
+		keywords: []string{"timed out", "timeout", "deadline exceeded"},
+		statuses: []int{http.StatusGatewayTimeout},
+		en:       "Upstream request timed out, please retry later",
+		zh:       "上游请求超时，请稍后重试",
+	},
+	{
+		code:     "upstream_unavailable",
+		types:    []string{"api_error", "server_error", "INTERNAL"},
+		statuses: []int{http.StatusInternalServerError, http.StatusBadGateway},
+		en:       "Upstream service temporarily unavailable",
+		zh:       "上游服务暂时不可用",
+	},
+}
+
+// ErrorTranslator 将上游错误响应中的消息标准化，并按客户端语言输出。
+type ErrorTranslator struct {
+	enabled       bool
+	defaultLocale string
+}
+
+// NewErrorTranslator 创建上游错误翻译器；cfg 为空或未启用时 Enabled 返回 false。
+func NewErrorTranslator(cfg *config.Config) *ErrorTranslator {
+	t := &ErrorTranslator{defaultLocale: ErrorLocaleEN}
+	if cfg == nil {
+		return t
+	}
+	t.enabled = cfg.Gateway.ErrorTranslation.Enabled
+	if cfg.Gateway.ErrorTranslation.DefaultLocale == ErrorLocaleZH {
+		t.defaultLocale = ErrorLocaleZH
+	}
+	return t
+}
+
+// Enabled 是否启用
+func (t *ErrorTranslator) Enabled() bool {
+	return t != nil && t.enabled
+}
+
+// ResolveLocale 根据 Accept-Language 选择文案语言（取第一个受支持的语言标签，忽略 q 权重）
+func (t *ErrorTranslator) ResolveLocale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, ErrorLocaleZH):
+			return ErrorLocaleZH
+		case strings.HasPrefix(tag, ErrorLocaleEN):
+			return ErrorLocaleEN
+		}
+	}
+	return t.defaultLocale
+}
+
+// Translate 改写错误响应体中的 error.message（保持各协议原有结构不变）；
+// 客户端依赖原文的错误保留 error.message，统一文案写入 error.localized_message。
+// 未识别的错误或无法解析的响应体返回 ok=false，调用方应原样输出。
+func (t *ErrorTranslator) Translate(status int, body []byte, locale string) (out []byte, code string, ok bool) {
+	if !gjson.ValidBytes(body) {
+		return nil, "", false
+	}
+	msgRes := gjson.GetBytes(body, "error.message")
+	if !msgRes.Exists() || msgRes.Type != gjson.String {
+		return nil, "", false
+	}
+	rule := matchErrorTranslationRule(status, body, msgRes.String())
+	if rule == nil {
+		return nil, "", false
+	}
+	msg := rule.en
+	if locale == ErrorLocaleZH {
+		msg = rule.zh
+	}
+	path := "error.message"
+	if rule.keepMessage {
+		path = errorLocalizedMessagePath
+	}
+	if msg == gjson.GetBytes(body, path).String() {
+		return nil, "", false
+	}
+	out, err := sjson.SetBytes(body, path, msg)
+	if err != nil {
+		return nil, "", false
+	}
+	return out, rule.code, true
+}
+
+func matchErrorTranslationRule(status int, body []byte, message string) *errorTranslationRule {
+	errTypes := make([]string, 0, 3)
+	for _, path := range []string{"error.type", "error.code", "error.status"} {
+		if v := gjson.GetBytes(body, path); v.Type == gjson.String && v.String() != "" {
+			errTypes = append(errTypes, v.String())
+		}
+	}
+	lowerMsg := strings.ToLower(message)
+	for i := range errorTranslationRules {
+		rule := &errorTranslationRules[i]
+		for _, kw := range rule.keywords {
+			if strings.Contains(lowerMsg, kw) {
+				return rule
+			}
+		}
+	}
+	for i := range errorTranslationRules {
+		rule := &errorTranslationRules[i]
+		for _, et := range errTypes {
+			if slices.Contains(rule.types, et) {
+				return rule
+			}
+		}
+	}
+	for i := range errorTranslationRules {
+		if slices.Contains(errorTranslationRules[i].statuses, status) {
+			return &errorTranslationRules[i]
+		}
+	}
+	return nil
+}
+
+// HasOpsUpstreamError 当前请求是否记录过上游错误（用于区分上游错误与网关本地错误）
+func HasOpsUpstreamError(c *gin.Context) bool {
+	if c == nil {
+		return false
+	}
+	if _, ok := c.Get(OpsUpstreamStatusCodeKey); ok {
+		return true
+	}
+	_, ok := c.Get(OpsUpstreamErrorsKey)
+	return ok
+}
+
+// PreserveOpsUpstreamErrorDetail 在未记录上游错误详情时保存原始错误响应体，供运维错误日志（仅管理员可见）排障。
+func PreserveOpsUpstreamErrorDetail(c *gin.Context, detail string) {
+	if c == nil {
+		return
+	}
+	if _, ok := c.Get(OpsUpstreamErrorDetailKey); ok {
+		return
+	}
+	setOpsUpstreamError(c, 0, "", detail)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newTestErrorTranslator(defaultLocale string) *ErrorTranslator {
+	cfg := &config.Config{}
+	cfg.Gateway.ErrorTranslation = config.GatewayErrorTranslationConfig{Enabled: true, DefaultLocale: defaultLocale}
+	return NewErrorTranslator(cfg)
+}
+
+func TestErrorTranslator_ResolveLocale(t *testing.T) {
+	tr := newTestErrorTranslator(ErrorLocaleEN)
+	require.Equal(t, ErrorLocaleZH, tr.ResolveLocale("zh-CN,zh;q=0.9,en;q=0.8"))
+	require.Equal(t, ErrorLocaleEN, tr.ResolveLocale("en-US"))
+	require.Equal(t, ErrorLocaleEN, tr.ResolveLocale("fr-FR, de"))
+	require.Equal(t, ErrorLocaleZH, newTestErrorTranslator(ErrorLocaleZH).ResolveLocale(""))
+}
+
+func TestErrorTranslator_TranslatesKnownErrorsPreservingShape(t *testing.T) {
+	tr := newTestErrorTranslator(ErrorLocaleEN)
+
+	anthropic := []byte(`{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`)
+	out, code, ok := tr.Translate(http.StatusBadRequest, anthropic, ErrorLocaleZH)
+	require.True(t, ok)
+	require.Equal(t, "context_length_exceeded", code)
+	// 原文保留供客户端识别并触发压缩，统一文案放在单独字段
+	require.Equal(t, "prompt is too long: 210000 tokens > 200000 maximum", gjson.GetBytes(out, "error.message").String())
+	require.Equal(t, "请求超出模型上下文长度限制，请缩短输入内容", gjson.GetBytes(out, "error.localized_message").String())
+	require.Equal(t, "invalid_request_error", gjson.GetBytes(out, "error.type").String())
+	require.Equal(t, "error", gjson.GetBytes(out, "type").String())
+
+	gemini := []byte(`{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED"}}`)
+	out, code, ok = tr.Translate(http.StatusTooManyRequests, gemini, ErrorLocaleEN)
+	require.True(t, ok)
+	require.Equal(t, "rate_limited", code)
+	require.Equal(t, int64(429), gjson.GetBytes(out, "error.code").Int())
+	require.Equal(t, "Upstream rate limit exceeded, please retry later", gjson.GetBytes(out, "error.message").String())
+
+	overloaded := []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	_, code, ok = tr.Translate(529, overloaded, ErrorLocaleEN)
+	require.True(t, ok)
+	require.Equal(t, "upstream_overloaded", code)
+}
+
+func TestErrorTranslator_LeavesUnknownErrorsUntouched(t *testing.T) {
+	tr := newTestErrorTranslator(ErrorLocaleEN)
+
+	_, _, ok := tr.Translate(http.StatusBadRequest, []byte(`{"error":{"type":"invalid_request_error","message":"messages.0.content: field required"}}`), ErrorLocaleZH)
+	require.False(t, ok)
+
+	_, _, ok = tr.Translate(http.StatusBadGateway, []byte(`not json`), ErrorLocaleZH)
+	require.False(t, ok)
+
+	// 已是目标文案时不重复改写
+	_, _, ok = tr.Translate(http.StatusTooManyRequests, []byte(`{"error":{"type":"rate_limit_error","message":"Upstream rate limit exceeded, please retry later"}}`), ErrorLocaleEN)
+	require.False(t, ok)
+}
+
+func TestNewErrorTranslator_DisabledByDefault(t *testing.T) {
+	require.False(t, NewErrorTranslator(nil).Enabled())
+	require.False(t, NewErrorTranslator(&config.Config{}).Enabled())
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 账号切换失败原因分类（FailoverAttempt.ErrorClass）
+const (
+	FailoverErrorClassRateLimited       = "rate_limited"
+	FailoverErrorClassOverloaded        = "overloaded"
+	FailoverErrorClassCredential        = "credential"
+	FailoverErrorClassAuthRejected      = "auth_rejected"
+	FailoverErrorClassUpstreamError     = "upstream_error"
+	FailoverErrorClassRequestRejected   = "request_rejected"
+	FailoverErrorClassNoEligibleAccount = "no_eligible_account"
+)
+
+// OpsUpstreamKindFailoverExhausted 账号切换耗尽时的汇总事件类型；Detail 为全部尝试的 JSON 数组。
+// 汇总事件不代表一次真实的上游调用，判断“最后一次上游错误”时应跳过。
+const OpsUpstreamKindFailoverExhausted = "failover_exhausted"
+
+// opsFailoverExhaustedRecordedKey 保证一次请求只写入一条切换汇总 ops 事件。
+const opsFailoverExhaustedRecordedKey = "ops_failover_exhausted_recorded"
+
+// FailoverAttempt 一次账号尝试的失败摘要，切换耗尽时汇总返回给客户端并写入 ops 事件。
+type FailoverAttempt struct {
+	// AccountID 为 0 表示选号阶段没有可用候选（冷却、限流或不满足条件）
+	AccountID      int64  `json:"account_id"`
+	Platform       string `json:"platform,omitempty"`
+	ErrorClass     string `json:"error_class"`
+	UpstreamStatus int    `json:"upstream_status,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+// NewFailoverAttempt 根据 failover 错误构造一次尝试摘要。
+func NewFailoverAttempt(accountID int64, platform string, failoverErr *UpstreamFailoverError) FailoverAttempt {
+	attempt := FailoverAttempt{
+		AccountID:  accountID,
+		Platform:   platform,
+		ErrorClass: ClassifyFailoverError(failoverErr),
+	}
+	if failoverErr != nil {
+		attempt.Reason = string(failoverErr.Reason)
+		if !failoverErr.IsCredentialFailure() {
+			attempt.UpstreamStatus = failoverErr.StatusCode
+		}
+	}
+	return attempt
+}
+
+// ClassifyFailoverError 将 failover 错误归类为 FailoverErrorClass*。
+func ClassifyFailoverError(failoverErr *UpstreamFailoverError) string {
+	if failoverErr == nil {
+		return FailoverErrorClassUpstreamError
+	}
+	if failoverErr.IsCredentialFailure() {
+		return FailoverErrorClassCredential
+	}
+	switch status := failoverErr.StatusCode; {
+	case status == http.StatusTooManyRequests:
+		return FailoverErrorClassRateLimited
+	case status == 529 || status == http.StatusServiceUnavailable:
+		return FailoverErrorClassOverloaded
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return FailoverErrorClassAuthRejected
+	case status >= 400 && status < 500:
+		return FailoverErrorClassRequestRejected
+	default:
+		return FailoverErrorClassUpstreamError
+	}
+}
+
+// LastOpsUpstreamAttemptEvent 返回最后一条真实上游尝试事件（跳过切换汇总事件）。
+func LastOpsUpstreamAttemptEvent(events []*OpsUpstreamErrorEvent) *OpsUpstreamErrorEvent {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i] != nil && events[i].Kind != OpsUpstreamKindFailoverExhausted {
+			return events[i]
+		}
+	}
+	return nil
+}
+
+// RecordFailoverAttemptsOpsEvent 把本次请求全部账号尝试的失败摘要作为一条 failover_exhausted
+// 事件写入 ops 上游错误列表（ops_error_logs.upstream_errors）；同一请求只记录一次。
+func RecordFailoverAttemptsOpsEvent(c *gin.Context, attempts []FailoverAttempt) {
+	if c == nil || len(attempts) == 0 {
+		return
+	}
+	if c.GetBool(opsFailoverExhaustedRecordedKey) {
+		return
+	}
+	c.Set(opsFailoverExhaustedRecordedKey, true)
+	detail, _ := json.Marshal(attempts)
+	last := attempts[len(attempts)-1]
+	// 不带上游状态码：各次尝试的原始事件已单独记录，避免汇总事件重复参与透传规则匹配。
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+		Platform:  last.Platform,
+		AccountID: last.AccountID,
+		Kind:      OpsUpstreamKindFailoverExhausted,
+		Reason:    last.ErrorClass,
+		Message:   fmt.Sprintf("all %d account attempts failed", len(attempts)),
+		Detail:    string(detail),
+	})
+}
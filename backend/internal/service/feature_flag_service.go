@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// 可按部署关闭的功能面
+const (
+	// FeatureGeminiGateway Gemini 网关：/v1beta 原生接口与 Gemini 分组的 /v1 请求，以及 Gemini 账号的后台 token 刷新
+	FeatureGeminiGateway = "gemini_gateway"
+	// FeaturePayments 支付：用户/公开/回调/管理端支付接口，以及订单过期对账任务
+	FeaturePayments = "payments"
+	// FeatureWebhooks 支付渠道回调入口（/api/v1/payment/webhook/*）
+	FeatureWebhooks = "webhooks"
+)
+
+// SettingKeyFeatureFlags 功能开关（JSON 对象，仅保存被修改过的功能；缺省视为开启）
+const SettingKeyFeatureFlags = "feature_flags"
+
+const (
+	featureFlagsCacheTTL  = 15 * time.Second
+	featureFlagsErrorTTL  = 5 * time.Second
+	featureFlagsDBTimeout = 5 * time.Second
+)
+
+var ErrUnknownFeatureFlag = infraerrors.BadRequest("UNKNOWN_FEATURE_FLAG", "unknown feature flag")
+
+// featureFlagDefinitions 已知功能及说明（按 key 排序输出）
+var featureFlagDefinitions = map[string]string{
+	FeatureGeminiGateway: "Gemini gateway (/v1beta and Gemini groups on /v1) and Gemini token refresh",
+	FeaturePayments:      "Payment APIs, payment admin APIs and the order expiry job",
+	FeatureWebhooks:      "Payment provider webhook endpoints",
+}
+
+// FeatureFlag 单个功能开关的当前状态。
+type FeatureFlag struct {
+	Key         string `json:"key"`
+	Enabled     bool   `json:"enabled"`
+	Description string `json:"description"`
+}
+
+type cachedFeatureFlags struct {
+	disabled  map[string]bool
+	expiresAt int64
+}
+
+// FeatureFlagService 读写部署级功能开关。
+// 开关保存在 settings 表，进程内缓存 featureFlagsCacheTTL，热路径无锁；多实例在一个 TTL 内收敛。
+type FeatureFlagService struct {
+	settingRepo SettingRepository
+
+	cache atomic.Pointer[cachedFeatureFlags]
+	sf    singleflight.Group
+	now   func() time.Time
+}
+
+// NewFeatureFlagService creates a FeatureFlagService.
+func NewFeatureFlagService(settingRepo SettingRepository) *FeatureFlagService {
+	return &FeatureFlagService{settingRepo: settingRepo, now: time.Now}
+}
+
+// IsEnabled 返回功能是否开启。未知功能、未配置或服务未注入时视为开启。
+func (s *FeatureFlagService) IsEnabled(ctx context.Context, feature string) bool {
+	if s == nil || s.settingRepo == nil {
+		return true
+	}
+	return !s.disabledFeatures(ctx)[feature]
+}
+
+// IsPlatformEnabled 返回平台所属的网关功能是否开启；没有对应开关的平台始终开启。
+func (s *FeatureFlagService) IsPlatformEnabled(ctx context.Context, platform string) bool {
+	if platform == PlatformGemini {
+		return s.IsEnabled(ctx, FeatureGeminiGateway)
+	}
+	return true
+}
+
+// List 返回全部已知功能的开关状态。
+func (s *FeatureFlagService) List(ctx context.Context) []FeatureFlag {
+	keys := make([]string, 0, len(featureFlagDefinitions))
+	for key := range featureFlagDefinitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	flags := make([]FeatureFlag, 0, len(keys))
+	for _, key := range keys {
+		flags = append(flags, FeatureFlag{
+			Key:         key,
+			Enabled:     s.IsEnabled(ctx, key),
+			Description: featureFlagDefinitions[key],
+		})
+	}
+	return flags
+}
+
+// Update 修改部分功能开关，未出现在 updates 中的功能保持不变。
+func (s *FeatureFlagService) Update(ctx context.Context, updates map[string]bool) ([]FeatureFlag, error) {
+	for key := range updates {
+		if _, ok := featureFlagDefinitions[key]; !ok {
+			return nil, ErrUnknownFeatureFlag.WithMetadata(map[string]string{"key": key})
+		}
+	}
+
+	disabled, err := s.loadDisabledFeatures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for key, enabled := range updates {
+		if enabled {
+			delete(disabled, key)
+		} else {
+			disabled[key] = true
+		}
+	}
+	stored := make(map[string]bool, len(disabled))
+	for key := range disabled {
+		stored[key] = false
+	}
+	raw, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("marshal feature flags: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyFeatureFlags, string(raw)); err != nil {
+		return nil, fmt.Errorf("save feature flags: %w", err)
+	}
+	s.store(disabled, featureFlagsCacheTTL)
+	return s.List(ctx), nil
+}
+
+func (s *FeatureFlagService) disabledFeatures(ctx context.Context) map[string]bool {
+	if cached := s.cache.Load(); cached != nil && s.now().UnixNano() < cached.expiresAt {
+		return cached.disabled
+	}
+	result, _, _ := s.sf.Do(SettingKeyFeatureFlags, func() (any, error) {
+		if cached := s.cache.Load(); cached != nil && s.now().UnixNano() < cached.expiresAt {
+			return cached.disabled, nil
+		}
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), featureFlagsDBTimeout)
+		defer cancel()
+		disabled, err := s.loadDisabledFeatures(dbCtx)
+		if err != nil {
+			slog.Warn("failed to load feature flags", "error", err)
+			// 读取失败时沿用上次的结果；从未成功读取过则全部开启
+			disabled = map[string]bool{}
+			if cached := s.cache.Load(); cached != nil {
+				disabled = cached.disabled
+			}
+			s.store(disabled, featureFlagsErrorTTL)
+			return disabled, nil
+		}
+		s.store(disabled, featureFlagsCacheTTL)
+		return disabled, nil
+	})
+	disabled, _ := result.(map[string]bool)
+	return disabled
+}
+
+func (s *FeatureFlagService) store(disabled map[string]bool, ttl time.Duration) {
+	s.cache.Store(&cachedFeatureFlags{disabled: disabled, expiresAt: s.now().Add(ttl).UnixNano()})
+}
+
+// loadDisabledFeatures 读取被关闭的功能；设置不存在时返回空集合。
+func (s *FeatureFlagService) loadDisabledFeatures(ctx context.Context) (map[string]bool, error) {
+	disabled := map[string]bool{}
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyFeatureFlags)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return disabled, nil
+		}
+		return nil, err
+	}
+	if strings.TrimSpace(raw) == "" {
+		return disabled, nil
+	}
+	var stored map[string]bool
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+		return nil, fmt.Errorf("parse feature flags: %w", err)
+	}
+	for key, enabled := range stored {
+		if !enabled {
+			disabled[key] = true
+		}
+	}
+	return disabled, nil
+}
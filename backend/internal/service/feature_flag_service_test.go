@@ -0,0 +1,125 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type featureFlagRepoStub struct {
+	values map[string]string
+	getErr error
+	gets   int
+}
+
+func (s *featureFlagRepoStub) Get(ctx context.Context, key string) (*Setting, error) {
+	panic("unexpected Get call")
+}
+
+func (s *featureFlagRepoStub) GetValue(ctx context.Context, key string) (string, error) {
+	s.gets++
+	if s.getErr != nil {
+		return "", s.getErr
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return "", ErrSettingNotFound
+	}
+	return value, nil
+}
+
+func (s *featureFlagRepoStub) Set(ctx context.Context, key, value string) error {
+	if s.values == nil {
+		s.values = map[string]string{}
+	}
+	s.values[key] = value
+	return nil
+}
+
+func (s *featureFlagRepoStub) GetMultiple(ctx context.Context, keys []string) (map[string]string, error) {
+	panic("unexpected GetMultiple call")
+}
+
+func (s *featureFlagRepoStub) SetMultiple(ctx context.Context, settings map[string]string) error {
+	panic("unexpected SetMultiple call")
+}
+
+func (s *featureFlagRepoStub) GetAll(ctx context.Context) (map[string]string, error) {
+	panic("unexpected GetAll call")
+}
+
+func (s *featureFlagRepoStub) Delete(ctx context.Context, key string) error {
+	panic("unexpected Delete call")
+}
+
+func TestFeatureFlagService_DefaultsToEnabled(t *testing.T) {
+	svc := NewFeatureFlagService(&featureFlagRepoStub{})
+
+	require.True(t, svc.IsEnabled(context.Background(), FeaturePayments))
+	require.True(t, svc.IsEnabled(context.Background(), "unknown"))
+	require.True(t, (*FeatureFlagService)(nil).IsEnabled(context.Background(), FeaturePayments))
+
+	flags := svc.List(context.Background())
+	require.Len(t, flags, 3)
+	require.Equal(t, FeatureGeminiGateway, flags[0].Key)
+	for _, flag := range flags {
+		require.True(t, flag.Enabled, flag.Key)
+	}
+}
+
+func TestFeatureFlagService_UpdatePersistsOnlyDisabledFeatures(t *testing.T) {
+	repo := &featureFlagRepoStub{}
+	svc := NewFeatureFlagService(repo)
+	ctx := context.Background()
+
+	_, err := svc.Update(ctx, map[string]bool{FeaturePayments: false, FeatureWebhooks: true})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"payments":false}`, repo.values[SettingKeyFeatureFlags])
+	require.False(t, svc.IsEnabled(ctx, FeaturePayments))
+	require.True(t, svc.IsEnabled(ctx, FeatureWebhooks))
+
+	flags, err := svc.Update(ctx, map[string]bool{FeatureGeminiGateway: false})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"payments":false,"gemini_gateway":false}`, repo.values[SettingKeyFeatureFlags])
+	require.False(t, svc.IsPlatformEnabled(ctx, PlatformGemini))
+	require.True(t, svc.IsPlatformEnabled(ctx, PlatformAnthropic))
+	require.False(t, flags[0].Enabled)
+
+	_, err = svc.Update(ctx, map[string]bool{FeaturePayments: true})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"gemini_gateway":false}`, repo.values[SettingKeyFeatureFlags])
+	require.True(t, svc.IsEnabled(ctx, FeaturePayments))
+}
+
+func TestFeatureFlagService_UpdateRejectsUnknownFeature(t *testing.T) {
+	repo := &featureFlagRepoStub{}
+	svc := NewFeatureFlagService(repo)
+
+	_, err := svc.Update(context.Background(), map[string]bool{"sora_gateway": false})
+	require.Error(t, err)
+	require.Equal(t, "UNKNOWN_FEATURE_FLAG", infraerrors.Reason(err))
+	require.Empty(t, repo.values)
+}
+
+func TestFeatureFlagService_CachesAndKeepsLastValueOnError(t *testing.T) {
+	repo := &featureFlagRepoStub{values: map[string]string{SettingKeyFeatureFlags: `{"webhooks":false}`}}
+	svc := NewFeatureFlagService(repo)
+	now := time.Unix(1_700_000_000, 0)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.False(t, svc.IsEnabled(ctx, FeatureWebhooks))
+	require.False(t, svc.IsEnabled(ctx, FeatureWebhooks))
+	require.Equal(t, 1, repo.gets)
+
+	now = now.Add(featureFlagsCacheTTL + time.Second)
+	repo.getErr = errors.New("db down")
+	require.False(t, svc.IsEnabled(ctx, FeatureWebhooks))
+	require.Equal(t, 2, repo.gets)
+}
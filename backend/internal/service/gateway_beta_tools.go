@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+)
+
+// Anthropic 服务端 beta 工具类型前缀（tools[].type，带版本日期后缀，如 computer_20250124）
+const (
+	betaToolTypeComputerPrefix      = "computer_"
+	betaToolTypeCodeExecutionPrefix = "code_execution_"
+)
+
+// betaToolUse 请求体中的一个服务端 beta 工具
+type betaToolUse struct {
+	toolType  string
+	betaToken string // 该版本工具所需的 anthropic-beta token；无需 beta 时为空
+}
+
+// collectBetaTools 提取请求体 tools 中的 computer use / code execution / web search 服务端工具。
+// 仅按 type 判断：同名的普通自定义工具（无 type 或 type=custom）不受分组开关约束；
+// GA 版 web_search_20250305 不属于 beta 工具，始终放行，升级后存量分组的 web search 请求不受影响。
+func collectBetaTools(body []byte) []betaToolUse {
+	tools := gjson.GetBytes(body, "tools")
+	if !tools.IsArray() {
+		return nil
+	}
+	var out []betaToolUse
+	tools.ForEach(func(_, tool gjson.Result) bool {
+		toolType := tool.Get("type").String()
+		switch {
+		case strings.HasPrefix(toolType, betaToolTypeComputerPrefix):
+			out = append(out, betaToolUse{toolType: toolType, betaToken: datedBetaToken("computer-use-", toolType, betaToolTypeComputerPrefix)})
+		case strings.HasPrefix(toolType, betaToolTypeCodeExecutionPrefix):
+			out = append(out, betaToolUse{toolType: toolType, betaToken: datedBetaToken("code-execution-", toolType, betaToolTypeCodeExecutionPrefix)})
+		case toolType == toolNameWebSearch2025:
+			// GA 版本，不受分组开关约束
+		case strings.HasPrefix(toolType, toolTypeWebSearchPrefix):
+			// 较新的 web search 版本：由客户端自行携带所需 beta header
+			out = append(out, betaToolUse{toolType: toolType})
+		}
+		return true
+	})
+	return out
+}
+
+// datedBetaToken 将工具版本后缀 YYYYMMDD 转为 beta token，如 computer_20250124 → computer-use-2025-01-24。
+// 后缀不是 8 位日期时返回空（交由客户端自行携带 beta header）。
+func datedBetaToken(betaPrefix, toolType, typePrefix string) string {
+	date := strings.TrimPrefix(toolType, typePrefix)
+	if len(date) != 8 {
+		return ""
+	}
+	for _, ch := range date {
+		if ch < '0' || ch > '9' {
+			return ""
+		}
+	}
+	return betaPrefix + date[:4] + "-" + date[4:6] + "-" + date[6:]
+}
+
+// betaToolAllowed 判断分组是否开启了该工具的透传
+func betaToolAllowed(group *Group, toolType string) bool {
+	switch {
+	case strings.HasPrefix(toolType, betaToolTypeComputerPrefix):
+		return group.AllowComputerUseTool
+	case strings.HasPrefix(toolType, betaToolTypeCodeExecutionPrefix):
+		return group.AllowCodeExecutionTool
+	default:
+		return group.AllowWebSearchTool
+	}
+}
+
+// checkGroupBetaTools 校验请求携带的服务端 beta 工具是否已在分组开启透传。
+// 未绑定分组的 Key 不做限制；分组读取失败时放行（与其它分组级策略一致，避免 DB 抖动误拒）。
+func (s *GatewayService) checkGroupBetaTools(ctx context.Context, groupID *int64, body []byte) *BetaBlockedError {
+	tools := collectBetaTools(body)
+	if len(tools) == 0 || groupID == nil {
+		return nil
+	}
+	group, err := s.resolveGroupByID(ctx, *groupID)
+	if err != nil || group == nil {
+		logger.LegacyPrintf("service.gateway", "[BetaTools] resolve group failed, skip check: group_id=%d err=%v", *groupID, err)
+		return nil
+	}
+	for _, tool := range tools {
+		if !betaToolAllowed(group, tool.toolType) {
+			return &BetaBlockedError{Message: "tool type " + tool.toolType + " is not enabled for this group"}
+		}
+	}
+	return nil
+}
+
+// requiredBetaToolTokens 返回请求体中服务端 beta 工具所需的 anthropic-beta token（去重，保持出现顺序）。
+// 调用前 Forward 已按分组开关拦截，能走到这里的工具都已被允许透传。
+func requiredBetaToolTokens(body []byte) []string {
+	var tokens []string
+	for _, tool := range collectBetaTools(body) {
+		if tool.betaToken == "" || slices.Contains(tokens, tool.betaToken) {
+			continue
+		}
+		tokens = append(tokens, tool.betaToken)
+	}
+	return tokens
+}
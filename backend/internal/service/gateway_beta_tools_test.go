@@ -0,0 +1,95 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectBetaTools_ClassifiesServerToolsOnly(t *testing.T) {
+	body := []byte(`{"tools":[
+		{"type":"computer_20250124","name":"computer"},
+		{"type":"code_execution_20250522","name":"code_execution"},
+		{"type":"web_search_20250305","name":"web_search"},
+		{"type":"web_search_20260209","name":"web_search"},
+		{"name":"web_search","input_schema":{"type":"object"}},
+		{"type":"custom","name":"computer"}
+	]}`)
+	tools := collectBetaTools(body)
+	require.Equal(t, []betaToolUse{
+		{toolType: "computer_20250124", betaToken: "computer-use-2025-01-24"},
+		{toolType: "code_execution_20250522", betaToken: "code-execution-2025-05-22"},
+		{toolType: "web_search_20260209"},
+	}, tools)
+}
+
+func TestDatedBetaToken_RejectsNonDateSuffix(t *testing.T) {
+	require.Equal(t, "computer-use-2025-11-24", datedBetaToken("computer-use-", "computer_20251124", betaToolTypeComputerPrefix))
+	require.Empty(t, datedBetaToken("computer-use-", "computer_latest", betaToolTypeComputerPrefix))
+	require.Empty(t, datedBetaToken("computer-use-", "computer_2025", betaToolTypeComputerPrefix))
+}
+
+func TestCheckGroupBetaTools_RequiresGroupOptIn(t *testing.T) {
+	group := &Group{ID: 7, Platform: PlatformAnthropic, Status: StatusActive, Hydrated: true}
+	ctx := context.WithValue(context.Background(), ctxkey.Group, group)
+	groupID := group.ID
+	svc := &GatewayService{}
+	body := []byte(`{"tools":[{"type":"computer_20250124","name":"computer"}]}`)
+
+	blockErr := svc.checkGroupBetaTools(ctx, &groupID, body)
+	require.NotNil(t, blockErr)
+	require.Contains(t, blockErr.Message, "computer_20250124")
+
+	group.AllowComputerUseTool = true
+	require.Nil(t, svc.checkGroupBetaTools(ctx, &groupID, body))
+
+	// GA 版 web search 无需分组开启，升级后存量分组不应被拒
+	gaWebSearch := []byte(`{"tools":[{"type":"web_search_20250305","name":"web_search"}]}`)
+	require.Nil(t, svc.checkGroupBetaTools(ctx, &groupID, gaWebSearch))
+
+	webSearch := []byte(`{"tools":[{"type":"web_search_20260209","name":"web_search"}]}`)
+	require.NotNil(t, svc.checkGroupBetaTools(ctx, &groupID, webSearch))
+	group.AllowWebSearchTool = true
+	require.Nil(t, svc.checkGroupBetaTools(ctx, &groupID, webSearch))
+}
+
+func TestCheckGroupBetaTools_IgnoresRequestsWithoutServerTools(t *testing.T) {
+	svc := &GatewayService{}
+	groupID := int64(7)
+	require.Nil(t, svc.checkGroupBetaTools(context.Background(), &groupID, []byte(`{"tools":[{"name":"get_weather"}]}`)))
+	require.Nil(t, svc.checkGroupBetaTools(context.Background(), nil, []byte(`{"tools":[{"type":"computer_20250124"}]}`)))
+}
+
+func TestBuildUpstreamRequest_OAuthMimic_InjectsBetaToolTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	// mimic 路径忽略客户端 beta，工具所需 token 必须由网关补齐
+	c.Request.Header.Set("anthropic-beta", "computer-use-2025-01-24")
+
+	account := &Account{ID: 501, Platform: PlatformAnthropic, Type: AccountTypeOAuth,
+		Credentials: map[string]any{"access_token": "oauth-tok"},
+		Status:      StatusActive,
+		Schedulable: true,
+	}
+	body := []byte(`{"model":"claude-sonnet-4-5","tools":[{"type":"computer_20250124","name":"computer","display_width_px":1024,"display_height_px":768},{"type":"code_execution_20250522","name":"code_execution"}],"messages":[]}`)
+	svc := &GatewayService{cfg: &config.Config{}}
+	req, _, err := svc.buildUpstreamRequest(
+		context.Background(), c, account, body,
+		"oauth-tok", "oauth", "claude-sonnet-4-5", true, true,
+	)
+	require.NoError(t, err)
+
+	outBeta := getHeaderRaw(req.Header, "anthropic-beta")
+	require.True(t, anthropicBetaTokensContains(outBeta, "computer-use-2025-01-24"))
+	require.True(t, anthropicBetaTokensContains(outBeta, "code-execution-2025-05-22"))
+}
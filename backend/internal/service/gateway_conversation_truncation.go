@@ -0,0 +1,289 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConversationTruncatedMessagesHeader 响应头：服务端截断丢弃的消息数
+const ConversationTruncatedMessagesHeader = "X-Sub2API-Truncated-Messages"
+
+// conversationImageTokenEstimate 图片/文档块的固定 token 估算值（无法按文本长度估算）
+const conversationImageTokenEstimate = 1600
+
+// ConversationTruncationResult 描述一次截断的结果
+type ConversationTruncationResult struct {
+	DroppedMessages int
+	TokensBefore    int
+	TokensAfter     int
+	Limit           int
+}
+
+// conversationUnit 截断的最小单位：单条消息，或 tool_use 消息与其后紧跟的 tool_result 消息
+type conversationUnit struct {
+	start  int
+	tokens int
+	// toolPair 单元由 tool_use 消息与其 tool_result 消息组成
+	toolPair bool
+}
+
+// TruncateConversationIfNeeded 在估算输入 token 超过上限时丢弃最早的消息。
+// 仅处理 Anthropic Messages 协议；system 始终保留，最近 PreserveRecentTurns 轮不会被丢弃，
+// tool_use/tool_result 对作为整体保留或丢弃。未触发截断时返回 nil。
+func (s *GatewayService) TruncateConversationIfNeeded(parsed *ParsedRequest) (*ConversationTruncationResult, error) {
+	if s == nil || s.cfg == nil || parsed == nil || !s.cfg.Gateway.ConversationTruncation.Enabled {
+		return nil, nil
+	}
+	if parsed.protocol != "" && parsed.protocol != domain.PlatformAnthropic {
+		return nil, nil
+	}
+	limit := s.resolveConversationInputLimit(parsed.Model, parsed.MaxTokens)
+	if limit <= 0 {
+		return nil, nil
+	}
+	return truncateConversation(parsed, limit, s.cfg.Gateway.ConversationTruncation.PreserveRecentTurns)
+}
+
+// resolveConversationInputLimit 取配置上限与模型上下文窗口中较小的值；
+// 模型上下文窗口需预留 max_tokens 给输出。
+func (s *GatewayService) resolveConversationInputLimit(model string, maxTokens int) int {
+	cfg := s.cfg.Gateway.ConversationTruncation
+	limit := cfg.MaxInputTokens
+	if modelLimit := matchConversationModelLimit(cfg.ModelMaxInputTokens, model); modelLimit > 0 {
+		limit = modelLimit
+	}
+	if contextWindow := s.modelContextWindow(model); contextWindow > 0 {
+		available := contextWindow
+		if maxTokens > 0 && maxTokens < contextWindow {
+			available = contextWindow - maxTokens
+		}
+		if limit <= 0 || available < limit {
+			limit = available
+		}
+	}
+	return limit
+}
+
+func (s *GatewayService) modelContextWindow(model string) int {
+	if s.billingService == nil || s.billingService.pricingService == nil || model == "" {
+		return 0
+	}
+	pricing := s.billingService.pricingService.GetModelPricing(model)
+	if pricing == nil {
+		return 0
+	}
+	return pricing.MaxInputTokens
+}
+
+func matchConversationModelLimit(limits map[string]int, model string) int {
+	if len(limits) == 0 || model == "" {
+		return 0
+	}
+	// viper 会把 map key 转为小写，这里统一按小写匹配
+	model = strings.ToLower(model)
+	if limit, ok := limits[model]; ok {
+		return limit
+	}
+	bestLen, best := -1, 0
+	for pattern, limit := range limits {
+		pattern = strings.ToLower(pattern)
+		if !strings.HasSuffix(pattern, "*") {
+			continue
+		}
+		prefix := strings.TrimSuffix(pattern, "*")
+		if strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			bestLen, best = len(prefix), limit
+		}
+	}
+	return best
+}
+
+func truncateConversation(parsed *ParsedRequest, limit, preserveTurns int) (*ConversationTruncationResult, error) {
+	messagesRaw := parsed.MessagesRaw()
+	if len(messagesRaw) == 0 {
+		return nil, nil
+	}
+	messages := gjson.ParseBytes(messagesRaw).Array()
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	systemTokens := estimateConversationValueTokens(gjson.ParseBytes(parsed.SystemRaw()))
+	toolsTokens := estimateTokensForText(gjson.GetBytes(parsed.Body.Bytes(), "tools").Raw)
+	total := systemTokens + toolsTokens
+	units := buildConversationUnits(messages)
+	for _, unit := range units {
+		total += unit.tokens
+	}
+	if total <= limit {
+		return nil, nil
+	}
+
+	if preserveTurns < 1 {
+		preserveTurns = 1
+	}
+	droppableUnits := len(units) - preserveTurns
+	if droppableUnits <= 0 {
+		return nil, nil
+	}
+
+	dropUnits := 0
+	remaining := total
+	for dropUnits < droppableUnits && remaining > limit {
+		remaining -= units[dropUnits].tokens
+		dropUnits++
+	}
+	// 丢弃单元区间 [dropFrom, dropTo)
+	dropFrom, dropTo := 0, alignConversationDropToUser(messages, units, dropUnits, droppableUnits)
+	if dropTo == 0 {
+		dropFrom, dropTo = dropConversationToolPairs(messages, units, total, limit, droppableUnits)
+	}
+	if dropTo <= dropFrom {
+		return nil, nil
+	}
+	after := total
+	for i := dropFrom; i < dropTo; i++ {
+		after -= units[i].tokens
+	}
+
+	dropStart, dropEnd := units[dropFrom].start, units[dropTo].start
+	var b strings.Builder
+	b.Grow(len(messagesRaw))
+	b.WriteByte('[')
+	written := 0
+	for i := range messages {
+		if i >= dropStart && i < dropEnd {
+			continue
+		}
+		if written > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(messages[i].Raw)
+		written++
+	}
+	b.WriteByte(']')
+
+	newBody, err := sjson.SetRawBytes(parsed.Body.Bytes(), "messages", []byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("truncate conversation: %w", err)
+	}
+	if err := parsed.ReplaceBody(newBody); err != nil {
+		return nil, fmt.Errorf("truncate conversation: %w", err)
+	}
+	return &ConversationTruncationResult{
+		DroppedMessages: dropEnd - dropStart,
+		TokensBefore:    total,
+		TokensAfter:     after,
+		Limit:           limit,
+	}, nil
+}
+
+// alignConversationDropToUser 调整丢弃边界，保证截断后的首条消息为 user（Anthropic 协议要求）。
+// 优先在可丢弃范围内继续向后寻找 user 开头的单元；找不到时回退到更少的丢弃量。
+func alignConversationDropToUser(messages []gjson.Result, units []conversationUnit, dropUnits, droppableUnits int) int {
+	startsWithUser := func(idx int) bool {
+		return messages[units[idx].start].Get("role").String() == "user"
+	}
+	for d := dropUnits; d <= droppableUnits && d < len(units); d++ {
+		if startsWithUser(d) {
+			return d
+		}
+	}
+	for d := dropUnits - 1; d > 0; d-- {
+		if startsWithUser(d) {
+			return d
+		}
+	}
+	return 0
+}
+
+// dropConversationToolPairs 在无法以 user 单元为新起点时（如首条 user 之后全是 tool_use/tool_result 循环），
+// 保留首个 user 单元，从其后按整对丢弃最早的 tool_use/tool_result，直到不超过上限或到达可丢弃范围末尾。
+// 被保留的下一单元必然以 assistant 开头（否则 alignConversationDropToUser 已能找到 user 边界），角色交替不受影响。
+func dropConversationToolPairs(messages []gjson.Result, units []conversationUnit, total, limit, droppableUnits int) (dropFrom, dropTo int) {
+	if len(units) < 2 || messages[units[0].start].Get("role").String() != "user" {
+		return 0, 0
+	}
+	remaining := total
+	dropTo = 1
+	for dropTo < droppableUnits && units[dropTo].toolPair && remaining > limit {
+		remaining -= units[dropTo].tokens
+		dropTo++
+	}
+	if dropTo == 1 {
+		return 0, 0
+	}
+	return 1, dropTo
+}
+
+// buildConversationUnits 将消息划分为截断单元：携带 tool_result 的 user 消息
+// 与其前一个单元（通常是发起 tool_use 的 assistant 消息）合并，保证成对保留。
+func buildConversationUnits(messages []gjson.Result) []conversationUnit {
+	units := make([]conversationUnit, 0, len(messages))
+	for i, msg := range messages {
+		tokens := estimateConversationValueTokens(msg.Get("content"))
+		if len(units) > 0 && messageHasToolResult(msg) {
+			units[len(units)-1].tokens += tokens
+			units[len(units)-1].toolPair = true
+			continue
+		}
+		units = append(units, conversationUnit{start: i, tokens: tokens})
+	}
+	return units
+}
+
+func messageHasToolResult(msg gjson.Result) bool {
+	if msg.Get("role").String() != "user" {
+		return false
+	}
+	found := false
+	msg.Get("content").ForEach(func(_, block gjson.Result) bool {
+		if block.Get("type").String() == "tool_result" {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// estimateConversationValueTokens 粗略估算 system/content 的 token 数。
+// 文本按字符估算，图片/文档按固定值，其余块按原始 JSON 估算。
+func estimateConversationValueTokens(value gjson.Result) int {
+	switch {
+	case !value.Exists():
+		return 0
+	case value.Type == gjson.String:
+		return estimateTokensForText(value.String())
+	case value.IsArray():
+		total := 0
+		value.ForEach(func(_, block gjson.Result) bool {
+			total += estimateConversationBlockTokens(block)
+			return true
+		})
+		return total
+	default:
+		return estimateTokensForText(value.Raw)
+	}
+}
+
+func estimateConversationBlockTokens(block gjson.Result) int {
+	switch block.Get("type").String() {
+	case "text":
+		return estimateTokensForText(block.Get("text").String())
+	case "thinking":
+		return estimateTokensForText(block.Get("thinking").String())
+	case "image", "document":
+		return conversationImageTokenEstimate
+	case "tool_use":
+		return estimateTokensForText(block.Get("name").String()) + estimateTokensForText(block.Get("input").Raw)
+	case "tool_result":
+		return estimateConversationValueTokens(block.Get("content"))
+	default:
+		return estimateTokensForText(block.Raw)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newConversationTruncationService(maxInputTokens, preserveTurns int) *GatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.ConversationTruncation = config.GatewayConversationTruncationConfig{
+		Enabled:             true,
+		MaxInputTokens:      maxInputTokens,
+		PreserveRecentTurns: preserveTurns,
+	}
+	return &GatewayService{cfg: cfg}
+}
+
+func parseTruncationRequest(t *testing.T, body string) *ParsedRequest {
+	t.Helper()
+	parsed, err := ParseGatewayRequest(NewRequestBodyRef([]byte(body)), domain.PlatformAnthropic)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestTruncateConversation_DisabledIsNoop(t *testing.T) {
+	svc := newConversationTruncationService(10, 1)
+	svc.cfg.Gateway.ConversationTruncation.Enabled = false
+	big := strings.Repeat("a", 400)
+	parsed := parseTruncationRequest(t, `{"model":"claude-x","messages":[{"role":"user","content":"`+big+`"},{"role":"assistant","content":"ok"},{"role":"user","content":"hi"}]}`)
+
+	result, err := svc.TruncateConversationIfNeeded(parsed)
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.Len(t, gjson.GetBytes(parsed.Body.Bytes(), "messages").Array(), 3)
+}
+
+func TestTruncateConversation_DropsOldestAndKeepsSystem(t *testing.T) {
+	svc := newConversationTruncationService(150, 1)
+	big := strings.Repeat("a", 400) // ~100 tokens
+	parsed := parseTruncationRequest(t, `{"model":"claude-x","system":"be nice","messages":[`+
+		`{"role":"user","content":"`+big+`"},`+
+		`{"role":"assistant","content":"`+big+`"},`+
+		`{"role":"user","content":"latest"}]}`)
+
+	result, err := svc.TruncateConversationIfNeeded(parsed)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 2, result.DroppedMessages)
+	require.LessOrEqual(t, result.TokensAfter, 150)
+
+	body := parsed.Body.Bytes()
+	require.Equal(t, "be nice", gjson.GetBytes(body, "system").String())
+	msgs := gjson.GetBytes(body, "messages").Array()
+	require.Len(t, msgs, 1)
+	require.Equal(t, "latest", msgs[0].Get("content").String())
+	require.Equal(t, "latest", gjson.Get(string(parsed.MessagesRaw()), "0.content").String())
+}
+
+func TestTruncateConversation_NeverSplitsToolPairs(t *testing.T) {
+	svc := newConversationTruncationService(250, 3)
+	big := strings.Repeat("b", 400)
+	parsed := parseTruncationRequest(t, `{"model":"claude-x","messages":[`+
+		`{"role":"user","content":"`+big+`"},`+
+		`{"role":"assistant","content":"`+big+`"},`+
+		`{"role":"user","content":"go"},`+
+		`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"read","input":{"path":"x"}}]},`+
+		`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"`+big+`"}]},`+
+		`{"role":"assistant","content":"done"},`+
+		`{"role":"user","content":"next"}]}`)
+
+	result, err := svc.TruncateConversationIfNeeded(parsed)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 2, result.DroppedMessages)
+
+	msgs := gjson.GetBytes(parsed.Body.Bytes(), "messages").Array()
+	require.Len(t, msgs, 5)
+	require.Equal(t, "go", msgs[0].Get("content").String())
+	require.Equal(t, "tool_use", msgs[1].Get("content.0.type").String())
+	require.Equal(t, "tool_result", msgs[2].Get("content.0.type").String())
+}
+
+func TestTruncateConversation_FirstMessageStaysUser(t *testing.T) {
+	svc := newConversationTruncationService(50, 2)
+	big := strings.Repeat("d", 400)
+	parsed := parseTruncationRequest(t, `{"model":"claude-x","messages":[`+
+		`{"role":"user","content":"`+big+`"},`+
+		`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"read","input":{}}]},`+
+		`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]},`+
+		`{"role":"assistant","content":"done"},`+
+		`{"role":"user","content":"next"}]}`)
+
+	// 可丢弃范围内找不到以 user 开头的边界时保留首条 user，仅整对丢弃其后的 tool_use/tool_result，避免产生 assistant 开头的请求
+	result, err := svc.TruncateConversationIfNeeded(parsed)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 2, result.DroppedMessages)
+
+	msgs := gjson.GetBytes(parsed.Body.Bytes(), "messages").Array()
+	require.Len(t, msgs, 3)
+	require.Equal(t, "user", msgs[0].Get("role").String())
+	require.Equal(t, "done", msgs[1].Get("content").String())
+	require.Equal(t, "next", msgs[2].Get("content").String())
+}
+
+func TestTruncateConversation_PureToolLoopDropsOldestPairs(t *testing.T) {
+	svc := newConversationTruncationService(250, 2)
+	big := strings.Repeat("e", 400)
+	pair := func(id string) string {
+		return `{"role":"assistant","content":[{"type":"tool_use","id":"` + id + `","name":"read","input":{}}]},` +
+			`{"role":"user","content":[{"type":"tool_result","tool_use_id":"` + id + `","content":"` + big + `"}]}`
+	}
+	parsed := parseTruncationRequest(t, `{"model":"claude-x","messages":[`+
+		`{"role":"user","content":"fix the bug"},`+
+		pair("t1")+`,`+pair("t2")+`,`+pair("t3")+`,`+pair("t4")+`]}`)
+
+	result, err := svc.TruncateConversationIfNeeded(parsed)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 4, result.DroppedMessages)
+	require.LessOrEqual(t, result.TokensAfter, 250)
+
+	msgs := gjson.GetBytes(parsed.Body.Bytes(), "messages").Array()
+	require.Len(t, msgs, 5)
+	require.Equal(t, "fix the bug", msgs[0].Get("content").String())
+	require.Equal(t, "t3", msgs[1].Get("content.0.id").String())
+	require.Equal(t, "t3", msgs[2].Get("content.0.tool_use_id").String())
+	require.Equal(t, "t4", msgs[4].Get("content.0.tool_use_id").String())
+}
+
+func TestTruncateConversation_PreservesRecentTurns(t *testing.T) {
+	svc := newConversationTruncationService(10, 3)
+	big := strings.Repeat("c", 400)
+	parsed := parseTruncationRequest(t, `{"model":"claude-x","messages":[`+
+		`{"role":"user","content":"`+big+`"},`+
+		`{"role":"assistant","content":"`+big+`"},`+
+		`{"role":"user","content":"`+big+`"}]}`)
+
+	result, err := svc.TruncateConversationIfNeeded(parsed)
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.Len(t, gjson.GetBytes(parsed.Body.Bytes(), "messages").Array(), 3)
+}
+
+func TestMatchConversationModelLimit(t *testing.T) {
+	limits := map[string]int{
+		"claude-*":       100,
+		"claude-haiku-*": 50,
+		"exact-model":    10,
+	}
+	require.Equal(t, 50, matchConversationModelLimit(limits, "Claude-Haiku-4-5"))
+	require.Equal(t, 100, matchConversationModelLimit(limits, "claude-sonnet-4-5"))
+	require.Equal(t, 10, matchConversationModelLimit(limits, "exact-model"))
+	require.Zero(t, matchConversationModelLimit(limits, "gpt-5"))
+}
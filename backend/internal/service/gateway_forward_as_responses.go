@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -235,12 +234,13 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 ) (*ForwardResult, error) {
 	requestID := resp.Header.Get("x-request-id")
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	scanner, releaseScanBuf := newPooledSSEScanner(resp.Body, maxLineSize)
+	defer releaseScanBuf()
+	applySSEOversizeLinePolicy(scanner, s.cfg, maxLineSize)
 
 	// Accumulate the final Anthropic response from streaming events
 	var finalResp *apicompat.AnthropicResponse
@@ -384,18 +384,21 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 	c.Writer.Header().Set("X-Accel-Buffering", "no")
 	c.Writer.WriteHeader(http.StatusOK)
 
+	restoreWriter := guardStreamClient(c, s.cfg, resp)
+	defer restoreWriter()
 	state := apicompat.NewAnthropicEventToResponsesState()
 	state.Model = originalModel
 	var usage ClaudeUsage
 	var firstTokenMs *int
 	firstChunk := true
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	scanner, releaseScanBuf := newPooledSSEScanner(resp.Body, maxLineSize)
+	defer releaseScanBuf()
+	applySSEOversizeLinePolicy(scanner, s.cfg, maxLineSize)
 
 	resultWithUsage := func() *ForwardResult {
 		return &ForwardResult{
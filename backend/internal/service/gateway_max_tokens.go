@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// 响应头：网关对 max_tokens 的调整。Injected 为注入的值，Clamped 为 "原值->钳制后的值"。
+const (
+	MaxTokensInjectedHeader = "X-Sub2API-Max-Tokens-Injected"
+	MaxTokensClampedHeader  = "X-Sub2API-Max-Tokens-Clamped"
+)
+
+// thinkingMinBudgetTokens Anthropic extended thinking 的最小 budget_tokens
+const thinkingMinBudgetTokens = 1024
+
+// MaxTokensAdjustment 描述一次 max_tokens 调整
+type MaxTokensAdjustment struct {
+	Injected bool
+	Clamped  bool
+	Original int // 请求原值（注入时为 0）
+	Applied  int // 调整后的值
+	Maximum  int // 生效的模型上限，0 表示无上限
+}
+
+// ApplyMaxTokensPolicy 按模型为 /v1/messages 请求注入缺省的 max_tokens，并把超过模型上限的值钳制到上限。
+// 钳制后若 thinking.budget_tokens 不再小于 max_tokens，同步下调 budget_tokens。未做调整时返回 nil。
+func (s *GatewayService) ApplyMaxTokensPolicy(parsed *ParsedRequest) (*MaxTokensAdjustment, error) {
+	if s == nil || s.cfg == nil || parsed == nil || !s.cfg.Gateway.MaxTokens.Enabled || parsed.Model == "" {
+		return nil, nil
+	}
+	if parsed.protocol != "" && parsed.protocol != domain.PlatformAnthropic {
+		return nil, nil
+	}
+	cfg := s.cfg.Gateway.MaxTokens
+	maximum := matchConversationModelLimit(cfg.ModelMaxima, parsed.Model)
+	if maximum <= 0 && cfg.CatalogMaximumFallback {
+		maximum = s.modelMaxOutputTokens(parsed.Model)
+	}
+
+	body := parsed.Body.Bytes()
+	adjustment := &MaxTokensAdjustment{Maximum: maximum}
+	if !gjson.GetBytes(body, "max_tokens").Exists() {
+		value := matchConversationModelLimit(cfg.ModelDefaults, parsed.Model)
+		if value <= 0 {
+			return nil, nil
+		}
+		if maximum > 0 && value > maximum {
+			value = maximum
+		}
+		adjustment.Injected, adjustment.Applied = true, value
+	} else if maximum > 0 && parsed.MaxTokens > maximum {
+		adjustment.Clamped, adjustment.Original, adjustment.Applied = true, parsed.MaxTokens, maximum
+	} else {
+		return nil, nil
+	}
+
+	newBody, err := sjson.SetBytes(body, "max_tokens", adjustment.Applied)
+	if err != nil {
+		return nil, fmt.Errorf("apply max_tokens: %w", err)
+	}
+	budget := gjson.GetBytes(newBody, "thinking.budget_tokens")
+	if adjustment.Clamped && budget.Type == gjson.Number && budget.Int() >= int64(adjustment.Applied) && adjustment.Applied > thinkingMinBudgetTokens {
+		if newBody, err = sjson.SetBytes(newBody, "thinking.budget_tokens", adjustment.Applied-1); err != nil {
+			return nil, fmt.Errorf("apply max_tokens: %w", err)
+		}
+	}
+	if err := parsed.ReplaceBody(newBody); err != nil {
+		return nil, fmt.Errorf("apply max_tokens: %w", err)
+	}
+	return adjustment, nil
+}
+
+func (s *GatewayService) modelMaxOutputTokens(model string) int {
+	if s.billingService == nil || s.billingService.pricingService == nil || model == "" {
+		return 0
+	}
+	pricing := s.billingService.pricingService.GetModelPricing(model)
+	if pricing == nil {
+		return 0
+	}
+	return pricing.MaxOutputTokens
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newMaxTokensPolicyService(defaults, maxima map[string]int) *GatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.MaxTokens = config.GatewayMaxTokensConfig{
+		Enabled:       true,
+		ModelDefaults: defaults,
+		ModelMaxima:   maxima,
+	}
+	return &GatewayService{cfg: cfg}
+}
+
+func TestApplyMaxTokensPolicy_DisabledIsNoop(t *testing.T) {
+	svc := newMaxTokensPolicyService(map[string]int{"claude-*": 4096}, nil)
+	svc.cfg.Gateway.MaxTokens.Enabled = false
+	parsed := parseTruncationRequest(t, `{"model":"claude-x","messages":[{"role":"user","content":"hi"}]}`)
+
+	adjustment, err := svc.ApplyMaxTokensPolicy(parsed)
+	require.NoError(t, err)
+	require.Nil(t, adjustment)
+	require.False(t, gjson.GetBytes(parsed.Body.Bytes(), "max_tokens").Exists())
+}
+
+func TestApplyMaxTokensPolicy_InjectsDefault(t *testing.T) {
+	svc := newMaxTokensPolicyService(map[string]int{"claude-*": 4096, "claude-haiku-*": 20000}, map[string]int{"claude-haiku-*": 8192})
+
+	parsed := parseTruncationRequest(t, `{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hi"}]}`)
+	adjustment, err := svc.ApplyMaxTokensPolicy(parsed)
+	require.NoError(t, err)
+	require.Equal(t, &MaxTokensAdjustment{Injected: true, Applied: 4096}, adjustment)
+	require.Equal(t, 4096, parsed.MaxTokens)
+	require.Equal(t, int64(4096), gjson.GetBytes(parsed.Body.Bytes(), "max_tokens").Int())
+
+	// 默认值本身超过上限时按上限注入
+	parsed = parseTruncationRequest(t, `{"model":"claude-haiku-4-5","messages":[{"role":"user","content":"hi"}]}`)
+	adjustment, err = svc.ApplyMaxTokensPolicy(parsed)
+	require.NoError(t, err)
+	require.Equal(t, 8192, adjustment.Applied)
+	require.False(t, adjustment.Clamped)
+}
+
+func TestApplyMaxTokensPolicy_ClampsAndLowersThinkingBudget(t *testing.T) {
+	svc := newMaxTokensPolicyService(nil, map[string]int{"claude-haiku-*": 8192})
+	parsed := parseTruncationRequest(t, `{"model":"claude-haiku-4-5","max_tokens":32000,"thinking":{"type":"enabled","budget_tokens":16000},"messages":[{"role":"user","content":"hi"}]}`)
+
+	adjustment, err := svc.ApplyMaxTokensPolicy(parsed)
+	require.NoError(t, err)
+	require.Equal(t, &MaxTokensAdjustment{Clamped: true, Original: 32000, Applied: 8192, Maximum: 8192}, adjustment)
+	body := parsed.Body.Bytes()
+	require.Equal(t, int64(8192), gjson.GetBytes(body, "max_tokens").Int())
+	require.Equal(t, int64(8191), gjson.GetBytes(body, "thinking.budget_tokens").Int())
+
+	parsed = parseTruncationRequest(t, `{"model":"claude-haiku-4-5","max_tokens":1000,"messages":[{"role":"user","content":"hi"}]}`)
+	adjustment, err = svc.ApplyMaxTokensPolicy(parsed)
+	require.NoError(t, err)
+	require.Nil(t, adjustment)
+}
+
+func TestApplyMaxTokensPolicy_CatalogMaximumFallback(t *testing.T) {
+	svc := newMaxTokensPolicyService(nil, nil)
+	svc.cfg.Gateway.MaxTokens.CatalogMaximumFallback = true
+	svc.billingService = &BillingService{pricingService: &PricingService{pricingData: map[string]*LiteLLMModelPricing{
+		"claude-sonnet-4-5": {MaxOutputTokens: 64000},
+	}}}
+	parsed := parseTruncationRequest(t, `{"model":"claude-sonnet-4-5","max_tokens":128000,"messages":[{"role":"user","content":"hi"}]}`)
+
+	adjustment, err := svc.ApplyMaxTokensPolicy(parsed)
+	require.NoError(t, err)
+	require.NotNil(t, adjustment)
+	require.Equal(t, 64000, adjustment.Applied)
+}
+
+func TestParsePricingData_MaxOutputTokens(t *testing.T) {
+	svc := &PricingService{}
+	data, err := svc.parsePricingData([]byte(`{"claude-sonnet-4-5":{"input_cost_per_token":0.000003,"output_cost_per_token":0.000015,"litellm_provider":"anthropic","mode":"chat","max_input_tokens":200000,"max_output_tokens":64000}}`))
+	require.NoError(t, err)
+	require.Equal(t, 64000, data["claude-sonnet-4-5"].MaxOutputTokens)
+}
@@ -114,6 +114,7 @@ func clearGatewayRequestDerivedState(parsed *ParsedRequest) {
 	parsed.Model = ""
 	parsed.Stream = false
 	parsed.MetadataUserID = ""
+	parsed.HasTools = false
 	parsed.HasSystem = false
 	parsed.ThinkingEnabled = false
 	parsed.OutputEffort = ""
@@ -219,6 +220,9 @@ func parseGatewayRequestCurrentBody(parsed *ParsedRequest, protocol string) erro
 	}
 
 	parsed.MetadataUserID = gjson.Get(jsonStr, "metadata.user_id").String()
+	if tools := gjson.Get(jsonStr, "tools"); tools.IsArray() && len(tools.Array()) > 0 {
+		parsed.HasTools = true
+	}
 
 	thinkingType := gjson.Get(jsonStr, "thinking.type").String()
 	parsed.ThinkingEnabled = thinkingType == "enabled" || thinkingType == "adaptive"
@@ -279,6 +283,7 @@ type ParsedRequest struct {
 	Model           string          // 请求的模型名称
 	Stream          bool            // 是否为流式请求
 	MetadataUserID  string          // metadata.user_id（用于会话亲和）
+	HasTools        bool            // 是否携带非空 tools（用于识别 Agent 会话）
 	HasSystem       bool            // 是否包含 system 字段（包含 null 也视为显式传入）
 	ThinkingEnabled bool            // 是否开启 thinking（部分平台会影响最终模型名）
 	OutputEffort    string          // output_config.effort（Claude API 的推理强度控制）
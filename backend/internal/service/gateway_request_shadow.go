@@ -0,0 +1,310 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// gatewayRequestShadowMismatches 影子校验发现不一致的累计次数（便于测试与排查）。
+var gatewayRequestShadowMismatches atomic.Uint64
+
+// ParseGatewayRequest 解析网关请求体；按 gateway.request_parse_shadow 采样对快速解析结果做影子校验。
+func (s *GatewayService) ParseGatewayRequest(body *RequestBodyRef, protocol string) (*ParsedRequest, error) {
+	if s == nil {
+		return ParseGatewayRequest(body, protocol)
+	}
+	return parseGatewayRequestWithShadow(s.cfg, body, protocol)
+}
+
+// ParseGatewayRequest 同 GatewayService.ParseGatewayRequest，供 OpenAI 网关的 Anthropic 兼容接口使用。
+func (s *OpenAIGatewayService) ParseGatewayRequest(body *RequestBodyRef, protocol string) (*ParsedRequest, error) {
+	if s == nil {
+		return ParseGatewayRequest(body, protocol)
+	}
+	return parseGatewayRequestWithShadow(s.cfg, body, protocol)
+}
+
+func parseGatewayRequestWithShadow(cfg *config.Config, body *RequestBodyRef, protocol string) (*ParsedRequest, error) {
+	parsed := &ParsedRequest{Body: body}
+	err := parseGatewayRequestCurrentBody(parsed, protocol)
+	if shouldShadowValidateGatewayRequest(cfg) {
+		shadowValidateGatewayRequest(parsed, protocol, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+func shouldShadowValidateGatewayRequest(cfg *config.Config) bool {
+	if cfg == nil || !cfg.Gateway.RequestParseShadow.Enabled {
+		return false
+	}
+	rate := math.Min(cfg.Gateway.RequestParseShadow.SampleRate, 1)
+	if rate <= 0 {
+		return false
+	}
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// gatewayRequestShadowResult 参与比对的解析结果；raw range 字段以解码后的 JSON 值比较。
+type gatewayRequestShadowResult struct {
+	Model           string
+	Stream          bool
+	MetadataUserID  string
+	HasSystem       bool
+	ThinkingEnabled bool
+	OutputEffort    string
+	MaxTokens       int
+	System          any
+	HasSystemRange  bool
+	Messages        any
+	HasMessages     bool
+	Input           any
+	HasInput        bool
+}
+
+// gatewayRequestShadowDiff 单个字段的差异；取值只保留摘要，避免把用户内容写入日志。
+type gatewayRequestShadowDiff struct {
+	Field  string `json:"field"`
+	Fast   string `json:"fast"`
+	Legacy string `json:"legacy"`
+}
+
+// shadowValidateGatewayRequest 用 map 解析器重新解析当前请求体并与快速解析结果比对，不一致时记录差异。
+// 仅用于观测，任何情况下都不改变 ParseGatewayRequest 的返回值。
+func shadowValidateGatewayRequest(parsed *ParsedRequest, protocol string, fastErr error) {
+	if parsed == nil || parsed.Body == nil {
+		return
+	}
+	body := parsed.Body.Bytes()
+	legacy, legacyErr := parseGatewayRequestLegacy(body, protocol)
+
+	var diffs []gatewayRequestShadowDiff
+	switch {
+	case fastErr != nil && legacyErr != nil:
+		return
+	case fastErr != nil || legacyErr != nil:
+		diffs = []gatewayRequestShadowDiff{{
+			Field:  "error",
+			Fast:   shadowErrorSummary(fastErr),
+			Legacy: shadowErrorSummary(legacyErr),
+		}}
+	default:
+		fast, err := gatewayRequestShadowFromParsed(parsed)
+		if err != nil {
+			diffs = []gatewayRequestShadowDiff{{Field: "raw_range", Fast: err.Error(), Legacy: "ok"}}
+		} else {
+			diffs = diffGatewayRequestShadow(fast, legacy)
+		}
+	}
+	if len(diffs) == 0 {
+		return
+	}
+	gatewayRequestShadowMismatches.Add(1)
+	logger.L().Warn("gateway.request_parse_shadow_mismatch",
+		zap.String("protocol", protocol),
+		zap.Int("body_len", len(body)),
+		zap.Any("diffs", diffs),
+	)
+}
+
+func shadowErrorSummary(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return "error: " + err.Error()
+}
+
+func gatewayRequestShadowFromParsed(parsed *ParsedRequest) (*gatewayRequestShadowResult, error) {
+	out := &gatewayRequestShadowResult{
+		Model:           parsed.Model,
+		Stream:          parsed.Stream,
+		MetadataUserID:  parsed.MetadataUserID,
+		HasSystem:       parsed.HasSystem,
+		ThinkingEnabled: parsed.ThinkingEnabled,
+		OutputEffort:    parsed.OutputEffort,
+		MaxTokens:       parsed.MaxTokens,
+	}
+	var err error
+	if out.System, out.HasSystemRange, err = decodeShadowRaw(parsed.SystemRaw()); err != nil {
+		return nil, fmt.Errorf("decode system range: %w", err)
+	}
+	if out.Messages, out.HasMessages, err = decodeShadowRaw(parsed.MessagesRaw()); err != nil {
+		return nil, fmt.Errorf("decode messages range: %w", err)
+	}
+	if out.Input, out.HasInput, err = decodeShadowRaw(parsed.InputRaw()); err != nil {
+		return nil, fmt.Errorf("decode input range: %w", err)
+	}
+	return out, nil
+}
+
+func decodeShadowRaw(raw []byte) (any, bool, error) {
+	if len(raw) == 0 {
+		return nil, false, nil
+	}
+	v, err := decodeShadowJSON(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+func decodeShadowJSON(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// parseGatewayRequestLegacy 基于 encoding/json map 的参考解析实现（快速解析上线前的语义），仅供影子校验使用。
+func parseGatewayRequestLegacy(body []byte, protocol string) (*gatewayRequestShadowResult, error) {
+	decoded, err := decodeShadowJSON(body)
+	if err != nil {
+		return nil, err
+	}
+	req, ok := decoded.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("request body is not a json object")
+	}
+
+	out := &gatewayRequestShadowResult{}
+	if raw, exists := req["model"]; exists {
+		model, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid model field type")
+		}
+		if protocol == domain.PlatformAnthropic {
+			model = normalizeClaudeCodeLongContextModel(model)
+		}
+		out.Model = model
+	}
+	if raw, exists := req["stream"]; exists {
+		stream, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid stream field type")
+		}
+		out.Stream = stream
+	}
+	if metadata, ok := req["metadata"].(map[string]any); ok {
+		out.MetadataUserID, _ = metadata["user_id"].(string)
+	}
+	if thinking, ok := req["thinking"].(map[string]any); ok {
+		thinkingType, _ := thinking["type"].(string)
+		out.ThinkingEnabled = thinkingType == "enabled" || thinkingType == "adaptive"
+	}
+	if outputConfig, ok := req["output_config"].(map[string]any); ok {
+		effort, _ := outputConfig["effort"].(string)
+		out.OutputEffort = strings.TrimSpace(effort)
+	}
+	if n, ok := req["max_tokens"].(json.Number); ok {
+		if f, err := n.Float64(); err == nil && f == math.Trunc(f) &&
+			f <= float64(math.MaxInt) && f >= float64(math.MinInt) {
+			out.MaxTokens = int(f)
+		}
+	}
+
+	switch protocol {
+	case domain.PlatformGemini:
+		if si, ok := req["systemInstruction"].(map[string]any); ok {
+			if parts, ok := si["parts"].([]any); ok {
+				out.System, out.HasSystemRange = parts, true
+			}
+		}
+		if contents, ok := req["contents"].([]any); ok {
+			out.Messages, out.HasMessages = contents, true
+		}
+	default:
+		if system, exists := req["system"]; exists {
+			out.HasSystem = true
+			out.System, out.HasSystemRange = system, true
+		}
+		if messages, ok := req["messages"].([]any); ok {
+			out.Messages, out.HasMessages = messages, true
+		}
+		if protocol == "responses" {
+			if input, exists := req["input"]; exists {
+				out.Input, out.HasInput = input, true
+			}
+		}
+	}
+	return out, nil
+}
+
+func diffGatewayRequestShadow(fast, legacy *gatewayRequestShadowResult) []gatewayRequestShadowDiff {
+	var diffs []gatewayRequestShadowDiff
+	add := func(field, fastVal, legacyVal string) {
+		diffs = append(diffs, gatewayRequestShadowDiff{Field: field, Fast: fastVal, Legacy: legacyVal})
+	}
+	if fast.Model != legacy.Model {
+		add("model", fast.Model, legacy.Model)
+	}
+	if fast.Stream != legacy.Stream {
+		add("stream", fmt.Sprint(fast.Stream), fmt.Sprint(legacy.Stream))
+	}
+	if fast.MetadataUserID != legacy.MetadataUserID {
+		add("metadata.user_id", shadowStringSummary(fast.MetadataUserID), shadowStringSummary(legacy.MetadataUserID))
+	}
+	if fast.HasSystem != legacy.HasSystem {
+		add("has_system", fmt.Sprint(fast.HasSystem), fmt.Sprint(legacy.HasSystem))
+	}
+	if fast.ThinkingEnabled != legacy.ThinkingEnabled {
+		add("thinking_enabled", fmt.Sprint(fast.ThinkingEnabled), fmt.Sprint(legacy.ThinkingEnabled))
+	}
+	if fast.OutputEffort != legacy.OutputEffort {
+		add("output_config.effort", fast.OutputEffort, legacy.OutputEffort)
+	}
+	if fast.MaxTokens != legacy.MaxTokens {
+		add("max_tokens", fmt.Sprint(fast.MaxTokens), fmt.Sprint(legacy.MaxTokens))
+	}
+	if fast.HasSystemRange != legacy.HasSystemRange || !reflect.DeepEqual(fast.System, legacy.System) {
+		add("system", shadowValueSummary(fast.System, fast.HasSystemRange), shadowValueSummary(legacy.System, legacy.HasSystemRange))
+	}
+	if fast.HasMessages != legacy.HasMessages || !reflect.DeepEqual(fast.Messages, legacy.Messages) {
+		add("messages", shadowValueSummary(fast.Messages, fast.HasMessages), shadowValueSummary(legacy.Messages, legacy.HasMessages))
+	}
+	if fast.HasInput != legacy.HasInput || !reflect.DeepEqual(fast.Input, legacy.Input) {
+		add("input", shadowValueSummary(fast.Input, fast.HasInput), shadowValueSummary(legacy.Input, legacy.HasInput))
+	}
+	return diffs
+}
+
+func shadowStringSummary(s string) string {
+	if s == "" {
+		return "empty"
+	}
+	return fmt.Sprintf("string(len=%d)", len(s))
+}
+
+// shadowValueSummary 只描述值的类型与规模，不输出内容。
+func shadowValueSummary(v any, present bool) string {
+	if !present {
+		return "absent"
+	}
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return shadowStringSummary(val)
+	case []any:
+		return fmt.Sprintf("array(len=%d)", len(val))
+	case map[string]any:
+		return fmt.Sprintf("object(keys=%d)", len(val))
+	default:
+		return fmt.Sprintf("%T", val)
+	}
+}
@@ -0,0 +1,84 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatewayRequestShadow_AgreesOnRegularBodies(t *testing.T) {
+	bodies := []struct {
+		protocol string
+		body     string
+	}{
+		{domain.PlatformAnthropic, `{"model":"claude-sonnet-4-5[1m]","stream":true,"max_tokens":1e3,"system":null,"metadata":{"user_id":"u1"},"thinking":{"type":"adaptive"},"output_config":{"effort":" high "},"messages":[{"role":"user","content":[{"type":"text","text":"hi"}]}]}`},
+		{domain.PlatformAnthropic, `{"model":"claude","messages":"not-an-array","metadata":null}`},
+		{domain.PlatformGemini, `{"systemInstruction":{"parts":[{"text":"s"}]},"contents":[{"role":"user","parts":[{"text":"x"}]}]}`},
+		{"responses", `{"model":"gpt-5","input":[{"role":"user","content":"x"}],"stream":false}`},
+	}
+	for _, tc := range bodies {
+		parsed, err := ParseGatewayRequest(NewRequestBodyRef([]byte(tc.body)), tc.protocol)
+		require.NoError(t, err)
+		fast, err := gatewayRequestShadowFromParsed(parsed)
+		require.NoError(t, err)
+		legacy, err := parseGatewayRequestLegacy(parsed.Body.Bytes(), tc.protocol)
+		require.NoError(t, err)
+		require.Empty(t, diffGatewayRequestShadow(fast, legacy), tc.body)
+	}
+}
+
+func TestGatewayRequestShadow_ReportsDuplicateKeyDivergence(t *testing.T) {
+	// gjson 取第一个重复键，encoding/json 取最后一个——典型的静默解析差异
+	body := `{"model":"claude-a","model":"claude-b","messages":[]}`
+	parsed, err := ParseGatewayRequest(NewRequestBodyRef([]byte(body)), domain.PlatformAnthropic)
+	require.NoError(t, err)
+	fast, err := gatewayRequestShadowFromParsed(parsed)
+	require.NoError(t, err)
+	legacy, err := parseGatewayRequestLegacy(parsed.Body.Bytes(), domain.PlatformAnthropic)
+	require.NoError(t, err)
+
+	diffs := diffGatewayRequestShadow(fast, legacy)
+	require.Equal(t, []gatewayRequestShadowDiff{{Field: "model", Fast: "claude-a", Legacy: "claude-b"}}, diffs)
+}
+
+func TestGatewayRequestShadow_ReportsNonStringMetadataUserID(t *testing.T) {
+	// gjson 会把数字 user_id 转成字符串，map 解析器只接受字符串
+	body := `{"model":"claude","metadata":{"user_id":123}}`
+	parsed, err := ParseGatewayRequest(NewRequestBodyRef([]byte(body)), domain.PlatformAnthropic)
+	require.NoError(t, err)
+	fast, err := gatewayRequestShadowFromParsed(parsed)
+	require.NoError(t, err)
+	legacy, err := parseGatewayRequestLegacy(parsed.Body.Bytes(), domain.PlatformAnthropic)
+	require.NoError(t, err)
+
+	diffs := diffGatewayRequestShadow(fast, legacy)
+	require.Equal(t, []gatewayRequestShadowDiff{{Field: "metadata.user_id", Fast: "string(len=3)", Legacy: "empty"}}, diffs)
+}
+
+func TestGatewayRequestShadow_SampledFromServiceParse(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.RequestParseShadow = config.GatewayRequestParseShadowConfig{Enabled: true, SampleRate: 1}
+	svc := &GatewayService{cfg: cfg}
+
+	before := gatewayRequestShadowMismatches.Load()
+	_, err := svc.ParseGatewayRequest(NewRequestBodyRef([]byte(`{"model":"claude","messages":[]}`)), domain.PlatformAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, before, gatewayRequestShadowMismatches.Load())
+
+	_, err = svc.ParseGatewayRequest(NewRequestBodyRef([]byte(`{"stream":false,"stream":"yes"}`)), domain.PlatformAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, before+1, gatewayRequestShadowMismatches.Load())
+
+	// 包级 ParseGatewayRequest 不做影子校验
+	_, err = ParseGatewayRequest(NewRequestBodyRef([]byte(`{"stream":false,"stream":"yes"}`)), domain.PlatformAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, before+1, gatewayRequestShadowMismatches.Load())
+
+	cfg.Gateway.RequestParseShadow.Enabled = false
+	require.False(t, shouldShadowValidateGatewayRequest(cfg))
+	require.False(t, shouldShadowValidateGatewayRequest(nil))
+}
@@ -566,6 +566,7 @@ type ClaudeUsage struct {
 	CacheCreation5mTokens    int // 5分钟缓存创建token（来自嵌套 cache_creation 对象）
 	CacheCreation1hTokens    int // 1小时缓存创建token（来自嵌套 cache_creation 对象）
 	ImageOutputTokens        int `json:"image_output_tokens,omitempty"`
+	ReasoningTokens          int // 推理 token（如 Gemini thoughtsTokenCount），已包含在 OutputTokens 中
 }
 
 // ForwardResult 转发结果
@@ -704,6 +705,9 @@ type GatewayService struct {
 	cache                 GatewayCache
 	digestStore           *DigestSessionStore
 	cfg                   *config.Config
+	accountWarmup         *AccountWarmupPolicy
+	agentAffinity         *agentSessionAffinitySettings
+	contentPolicy         *ContentPolicyCooldownService
 	schedulerSnapshot     *SchedulerSnapshotService
 	billingService        *BillingService
 	rateLimitService      *RateLimitService
@@ -729,6 +733,7 @@ type GatewayService struct {
 	debugGatewayBodyFile  atomic.Pointer[os.File] // non-nil when SUB2API_DEBUG_GATEWAY_BODY is set
 	tlsFPProfileService   *TLSFingerprintProfileService
 	balanceNotifyService  *BalanceNotifyService
+	groupBudget           *GroupBudgetService
 	userPlatformQuotaRepo UserPlatformQuotaRepository
 }
 
@@ -810,6 +815,8 @@ func NewGatewayService(
 	if path := strings.TrimSpace(os.Getenv(debugGatewayBodyEnv)); path != "" {
 		svc.initDebugGatewayBodyFile(path)
 	}
+	svc.accountWarmup = NewAccountWarmupPolicy(cfg)
+	svc.agentAffinity = newAgentSessionAffinitySettings(cfg)
 	return svc
 }
 
@@ -880,12 +887,13 @@ func (s *GatewayService) GenerateSessionHash(parsed *ParsedRequest) string {
 	return ""
 }
 
-// BindStickySession sets session -> account binding with standard TTL.
+// BindStickySession sets session -> account binding with standard TTL
+// (or the agent session affinity TTL when the request is marked as an agent session).
 func (s *GatewayService) BindStickySession(ctx context.Context, groupID *int64, sessionHash string, accountID int64) error {
 	if sessionHash == "" || accountID <= 0 || s.cache == nil {
 		return nil
 	}
-	return s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, accountID, stickySessionTTL)
+	return s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, accountID, s.stickySessionTTLForContext(ctx))
 }
 
 // GetCachedSessionAccountID retrieves the account ID bound to a sticky session.
@@ -1502,12 +1510,7 @@ func (s *GatewayService) applyClaudeCodeOAuthMimicryToBody(
 		return body
 	}
 
-	systemPromptInjectionEnabled, systemPrompt, systemPromptBlocks := s.claudeOAuthSystemPromptInjectionSettings(ctx)
-	systemRewritten := false
-	if systemPromptInjectionEnabled {
-		body = rewriteSystemForNonClaudeCodeWithPromptBlocks(body, normalizeSystemParam(systemRaw), systemPrompt, systemPromptBlocks)
-		systemRewritten = true
-	}
+	body, systemRewritten := s.applySystemPromptProfile(ctx, body, systemRaw)
 
 	normalizeOpts := claudeOAuthNormalizeOptions{stripSystemCacheControl: !systemRewritten}
 
@@ -1655,6 +1658,7 @@ func (s *GatewayService) SelectAccountForModelWithExclusions(ctx context.Context
 	if hasForcePlatform && forcePlatform != "" {
 		platform = forcePlatform
 	} else if groupID != nil {
+		groupID = s.groupBudget.ShiftGroupID(groupID, sessionHash)
 		group, resolvedGroupID, err := s.resolveGatewayGroup(ctx, groupID)
 		if err != nil {
 			return nil, err
@@ -1676,6 +1680,18 @@ func (s *GatewayService) SelectAccountForModelWithExclusions(ctx context.Context
 		return nil, fmt.Errorf("%w supporting model: %s (channel pricing restriction)", ErrNoAvailableAccounts, requestedModel)
 	}
 
+	// 管理员 Key 通过 X-Sub2API-Account-ID 固定账号时跳过调度（count_tokens 等无需并发槽位的路径）
+	if pinnedID := PinnedAccountIDFromContext(ctx); pinnedID > 0 {
+		useMixed := (platform == PlatformAnthropic || platform == PlatformGemini) && !hasForcePlatform
+		account, err := loadPinnedAccount(ctx, s.accountRepo, pinnedID, excludedIDs, func(account *Account) bool {
+			return s.isAccountAllowedForPlatform(account, platform, useMixed)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s.hydrateSelectedAccount(ctx, account)
+	}
+
 	// anthropic/gemini 分组支持混合调度（包含启用了 mixed_scheduling 的 antigravity 账户）
 	// 注意：强制平台模式不走混合调度
 	if (platform == PlatformAnthropic || platform == PlatformGemini) && !hasForcePlatform {
@@ -1712,6 +1728,11 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 
 	cfg := s.schedulingConfig()
 
+	// 分组预算预计超支时按比例切到兜底分组（强制平台模式不切流）
+	if forcePlatform, _ := ctx.Value(ctxkey.ForcePlatform).(string); forcePlatform == "" {
+		groupID = s.groupBudget.ShiftGroupID(groupID, sessionHash)
+	}
+
 	// 检查 Claude Code 客户端限制（可能会替换 groupID 为降级分组）
 	group, groupID, err := s.checkClaudeCodeRestriction(ctx, groupID)
 	if err != nil {
@@ -1728,6 +1749,17 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		return nil, fmt.Errorf("%w supporting model: %s (channel pricing restriction)", ErrNoAvailableAccounts, requestedModel)
 	}
 
+	if pinnedID := PinnedAccountIDFromContext(ctx); pinnedID > 0 {
+		platform, hasForcePlatform, err := s.resolvePlatform(ctx, groupID, group)
+		if err != nil {
+			return nil, err
+		}
+		useMixed := (platform == PlatformAnthropic || platform == PlatformGemini) && !hasForcePlatform
+		return selectPinnedAccount(ctx, s, s.accountRepo, pinnedID, excludedIDs, func(account *Account) bool {
+			return s.isAccountAllowedForPlatform(account, platform, useMixed)
+		})
+	}
+
 	var stickyAccountID int64
 	var stickySource string
 	if prefetch := prefetchedStickyAccountIDFromContext(ctx, groupID); prefetch > 0 {
@@ -1824,6 +1856,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	if err != nil {
 		return nil, err
 	}
+	accounts = s.accountWarmup.applyRamp(s.contentPolicy.filter(ctx, accounts), stickyAccountID)
 	if len(accounts) == 0 {
 		return nil, ErrNoAvailableAccounts
 	}
@@ -2071,7 +2104,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 							continue
 						}
 						if sessionHash != "" && s.cache != nil {
-							_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, item.account.ID, stickySessionTTL)
+							_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, item.account.ID, s.stickySessionTTLForContext(ctx))
 						}
 						if s.debugModelRoutingEnabled() {
 							logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed select: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), item.account.ID)
@@ -2110,7 +2143,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			account, ok := accountByID[accountID]
 			if ok {
 				// 检查账户是否需要清理粘性会话绑定
-				clearSticky := shouldClearStickySession(account, requestedModel)
+				clearSticky := s.shouldClearStickySession(ctx, account, requestedModel)
 				if clearSticky {
 					slog.Debug("sticky.layer1_5_no_routing_clear",
 						"account_id", accountID,
@@ -2162,7 +2195,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 								"result", "slot_acquired",
 							)
 							if s.cache != nil {
-								_ = s.cache.RefreshSessionTTL(ctx, derefGroupID(groupID), sessionHash, stickySessionTTL)
+								_ = s.cache.RefreshSessionTTL(ctx, derefGroupID(groupID), sessionHash, s.stickySessionTTLForContext(ctx))
 							}
 							return s.newSelectionResult(ctx, account, true, result.ReleaseFunc, nil)
 						}
@@ -2321,7 +2354,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 					result.ReleaseFunc() // 释放槽位，继续尝试下一个账号
 				} else {
 					if sessionHash != "" && s.cache != nil {
-						_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.account.ID, stickySessionTTL)
+						_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.account.ID, s.stickySessionTTLForContext(ctx))
 					}
 					return s.newSelectionResult(ctx, selected.account, true, result.ReleaseFunc, nil)
 				}
@@ -2369,7 +2402,7 @@ func (s *GatewayService) tryAcquireByLegacyOrder(ctx context.Context, candidates
 				continue
 			}
 			if sessionHash != "" && s.cache != nil {
-				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, stickySessionTTL)
+				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, s.stickySessionTTLForContext(ctx))
 			}
 			selection, err := s.newSelectionResult(ctx, acc, true, result.ReleaseFunc, nil)
 			if err != nil {
@@ -2544,7 +2577,7 @@ func (s *GatewayService) listSchedulableAccounts(ctx context.Context, groupID *i
 				}
 			}
 		}
-		return accounts, useMixed, err
+		return applyAccountGroupMembership(filterAccountsByDataResidency(ctx, accounts), groupID), useMixed, err
 	}
 	useMixed := (platform == PlatformAnthropic || platform == PlatformGemini) && !hasForcePlatform
 	if useMixed {
@@ -2588,7 +2621,7 @@ func (s *GatewayService) listSchedulableAccounts(ctx context.Context, groupID *i
 					"tls_fingerprint", acc.IsTLSFingerprintEnabled())
 			}
 		}
-		return filtered, useMixed, nil
+		return applyAccountGroupMembership(filterAccountsByDataResidency(ctx, filtered), groupID), useMixed, nil
 	}
 
 	var accounts []Account
@@ -2623,7 +2656,7 @@ func (s *GatewayService) listSchedulableAccounts(ctx context.Context, groupID *i
 				"tls_fingerprint", acc.IsTLSFingerprintEnabled())
 		}
 	}
-	return accounts, useMixed, nil
+	return applyAccountGroupMembership(filterAccountsByDataResidency(ctx, accounts), groupID), useMixed, nil
 }
 
 // IsSingleAntigravityAccountGroup 检查指定分组是否只有一个 antigravity 平台的可调度账号。
@@ -2994,10 +3027,22 @@ func (s *GatewayService) checkAndRegisterSession(ctx context.Context, account *A
 }
 
 func (s *GatewayService) getSchedulableAccount(ctx context.Context, accountID int64) (*Account, error) {
+	var (
+		account *Account
+		err     error
+	)
 	if s.schedulerSnapshot != nil {
-		return s.schedulerSnapshot.GetAccount(ctx, accountID)
+		account, err = s.schedulerSnapshot.GetAccount(ctx, accountID)
+	} else {
+		account, err = s.accountRepo.GetByID(ctx, accountID)
 	}
-	return s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+	if err := checkAccountDataResidency(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
 }
 
 func (s *GatewayService) hydrateSelectedAccount(ctx context.Context, account *Account) (*Account, error) {
@@ -3011,7 +3056,7 @@ func (s *GatewayService) hydrateSelectedAccount(ctx context.Context, account *Ac
 	if hydrated == nil {
 		return nil, fmt.Errorf("selected gateway account %d not found during hydration", account.ID)
 	}
-	return hydrated, nil
+	return inheritAccountGroupMembership(hydrated, account), nil
 }
 
 func (s *GatewayService) newSelectionResult(ctx context.Context, account *Account, acquired bool, release func(), waitPlan *AccountWaitPlan) (*AccountSelectionResult, error) {
@@ -3360,7 +3405,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 					account, err := s.getSchedulableAccount(ctx, accountID)
 					// 检查账号分组归属和平台匹配（确保粘性会话不会跨分组或跨平台）
 					if err == nil {
-						clearSticky := shouldClearStickySession(account, requestedModel)
+						clearSticky := s.shouldClearStickySession(ctx, account, requestedModel)
 						if clearSticky {
 							_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 						}
@@ -3385,6 +3430,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 		if err != nil {
 			return nil, fmt.Errorf("query accounts failed: %w", err)
 		}
+		accounts = s.accountWarmup.applyRamp(s.contentPolicy.filter(ctx, accounts), 0)
 		accountsLoaded = true
 
 		// 提前预取窗口费用+RPM 计数，确保 routing 段内的调度检查调用能命中缓存
@@ -3459,7 +3505,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 
 		if selected != nil {
 			if sessionHash != "" && s.cache != nil {
-				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTLForContext(ctx)); err != nil {
 					logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 				}
 			}
@@ -3479,7 +3525,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 				account, err := s.getSchedulableAccount(ctx, accountID)
 				// 检查账号分组归属和平台匹配（确保粘性会话不会跨分组或跨平台）
 				if err == nil {
-					clearSticky := shouldClearStickySession(account, requestedModel)
+					clearSticky := s.shouldClearStickySession(ctx, account, requestedModel)
 					if clearSticky {
 						_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 					}
@@ -3502,6 +3548,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 		if err != nil {
 			return nil, fmt.Errorf("query accounts failed: %w", err)
 		}
+		accounts = s.accountWarmup.applyRamp(s.contentPolicy.filter(ctx, accounts), 0)
 	}
 
 	// 批量预取窗口费用+RPM 计数，避免逐个账号查询（N+1）
@@ -3581,7 +3628,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 
 	// 4. 建立粘性绑定
 	if sessionHash != "" && s.cache != nil {
-		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTLForContext(ctx)); err != nil {
 			logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 		}
 	}
@@ -3618,7 +3665,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 					account, err := s.getSchedulableAccount(ctx, accountID)
 					// 检查账号分组归属和有效性：原生平台直接匹配，antigravity 需要启用混合调度
 					if err == nil {
-						clearSticky := shouldClearStickySession(account, requestedModel)
+						clearSticky := s.shouldClearStickySession(ctx, account, requestedModel)
 						if clearSticky {
 							_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 						}
@@ -3641,6 +3688,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 		if err != nil {
 			return nil, fmt.Errorf("query accounts failed: %w", err)
 		}
+		accounts = s.accountWarmup.applyRamp(s.contentPolicy.filter(ctx, accounts), 0)
 		accountsLoaded = true
 
 		// 提前预取窗口费用+RPM 计数，确保 routing 段内的调度检查调用能命中缓存
@@ -3719,7 +3767,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 
 		if selected != nil {
 			if sessionHash != "" && s.cache != nil {
-				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTLForContext(ctx)); err != nil {
 					logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 				}
 			}
@@ -3739,7 +3787,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 				account, err := s.getSchedulableAccount(ctx, accountID)
 				// 检查账号分组归属和有效性：原生平台直接匹配，antigravity 需要启用混合调度
 				if err == nil {
-					clearSticky := shouldClearStickySession(account, requestedModel)
+					clearSticky := s.shouldClearStickySession(ctx, account, requestedModel)
 					if clearSticky {
 						_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 					}
@@ -3760,6 +3808,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 		if err != nil {
 			return nil, fmt.Errorf("query accounts failed: %w", err)
 		}
+		accounts = s.accountWarmup.applyRamp(s.contentPolicy.filter(ctx, accounts), 0)
 	}
 
 	// 批量预取窗口费用+RPM 计数，避免逐个账号查询（N+1）
@@ -3842,7 +3891,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 
 	// 4. 建立粘性绑定
 	if sessionHash != "" && s.cache != nil {
-		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTLForContext(ctx)); err != nil {
 			logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 		}
 	}
@@ -4967,6 +5016,11 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		return s.handleWebSearchEmulation(ctx, c, account, parsed)
 	}
 
+	// 服务端 beta 工具（computer use / code execution / web search）需分组显式开启才透传
+	if blockErr := s.checkGroupBetaTools(ctx, parsed.GroupID, parsed.Body.Bytes()); blockErr != nil {
+		return nil, blockErr
+	}
+
 	if account != nil && account.IsAnthropicAPIKeyPassthroughEnabled() {
 		passthroughBody := parsed.Body.Bytes()
 		passthroughModel := parsed.Model
@@ -5043,18 +5097,17 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		// Code..." system prompt 但缺少 billing attribution block，导致 Anthropic
 		// 检测到"有 CC prompt 但无 billing block"的不一致而判为 third-party。
 		// Parrot 的 transform_request 从不检查客户端 system 内容，直接覆盖。
-		systemRewritten := false
+		// 注入方式由分组的 system prompt 档位决定，未设置时沿用全局注入开关。
 		systemRaw, _ := parsed.SystemValue()
-		systemPromptInjectionEnabled, systemPrompt, systemPromptBlocks := s.claudeOAuthSystemPromptInjectionSettings(ctx)
-		if systemPromptInjectionEnabled {
-			if err := replaceBody(rewriteSystemForNonClaudeCodeWithPromptBlocks(body, systemRaw, systemPrompt, systemPromptBlocks)); err != nil {
+		rewrittenBody, systemRewritten := s.applySystemPromptProfile(ctx, body, systemRaw)
+		if systemRewritten {
+			if err := replaceBody(rewrittenBody); err != nil {
 				return nil, err
 			}
-			systemRewritten = true
 		}
 
 		// system 被重写时保留 CC prompt 的 cache_control: ephemeral（匹配真实 Claude Code 行为）；
-		// 未重写时（注入开关关闭）剥离客户端 cache_control，与原有行为一致。
+		// 未重写时（no-injection 档位）剥离客户端 cache_control，与原有行为一致。
 		// 两种情况下 enforceCacheControlLimit 都会兜底处理上限。
 		normalizeOpts := claudeOAuthNormalizeOptions{stripSystemCacheControl: !systemRewritten}
 		if s.identityService != nil {
@@ -6082,6 +6135,9 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 	clientDisconnected := false
 	sawTerminalEvent := false
 
+	limitUpstreamStreamBody(c, s.cfg, resp)
+	streamGuard := newStreamClientGuard(c, s.cfg)
+	streamGuard.watchUpstream(resp)
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
@@ -6089,6 +6145,7 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 	}
 	scanBuf := getSSEScannerBuf64K()
 	scanner.Buffer(scanBuf[:0], maxLineSize)
+	applySSEOversizeLinePolicy(scanner, s.cfg, maxLineSize)
 
 	type scanEvent struct {
 		line string
@@ -6192,6 +6249,9 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 				if errors.Is(ev.err, context.Canceled) || errors.Is(ev.err, context.DeadlineExceeded) {
 					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs, clientDisconnect: true}, fmt.Errorf("stream usage incomplete: %w", ev.err)
 				}
+				if errors.Is(ev.err, ErrUpstreamStreamTooLarge) {
+					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, ev.err
+				}
 				if errors.Is(ev.err, bufio.ErrTooLong) {
 					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] SSE line too long: account=%d max_size=%d error=%v", account.ID, maxLineSize, ev.err)
 					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, ev.err
@@ -6200,6 +6260,7 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 			}
 
 			line := ev.line
+			streamGuard.consume(len(line) + 1)
 			if data, ok := extractAnthropicSSEDataLine(line); ok {
 				trimmed := strings.TrimSpace(data)
 				if anthropicStreamEventIsTerminal("", trimmed) {
@@ -6219,15 +6280,19 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 
 			if !clientDisconnected {
 				restored := string(reverseToolNamesIfPresent(c, []byte(line)))
-				if _, err := io.WriteString(w, restored); err != nil {
-					clientDisconnected = true
-					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during streaming, continue draining upstream for usage: account=%d", account.ID)
-				} else if _, err := io.WriteString(w, "\n"); err != nil {
-					clientDisconnected = true
-					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during streaming, continue draining upstream for usage: account=%d", account.ID)
-				} else if line == "" {
+				streamGuard.beginWrite()
+				_, err := io.WriteString(w, restored)
+				if err == nil {
+					_, err = io.WriteString(w, "\n")
+				}
+				if err == nil && line == "" {
 					// 按 SSE 事件边界刷出，减少每行 flush 带来的 syscall 开销。
 					flusher.Flush()
+				}
+				if err = streamGuard.endWrite(err); err != nil {
+					clientDisconnected = true
+					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during streaming, continue draining upstream for usage: account=%d err=%v", account.ID, err)
+				} else if line == "" {
 					lastDataAt = time.Now()
 					resetKeepaliveTimer()
 					inPartialEvent = false
@@ -6262,12 +6327,16 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 				resetKeepaliveTimer()
 				continue
 			}
-			if _, err := fmt.Fprint(w, "event: ping\ndata: {\"type\": \"ping\"}\n\n"); err != nil {
+			streamGuard.beginWrite()
+			_, err := fmt.Fprint(w, "event: ping\ndata: {\"type\": \"ping\"}\n\n")
+			if err == nil {
+				flusher.Flush()
+			}
+			if err = streamGuard.endWrite(err); err != nil {
 				clientDisconnected = true
-				logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during keepalive ping, continue draining upstream for usage: account=%d", account.ID)
+				logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during keepalive ping, continue draining upstream for usage: account=%d err=%v", account.ID, err)
 				continue
 			}
-			flusher.Flush()
 			lastDataAt = time.Now()
 			resetKeepaliveTimer()
 		}
@@ -7044,6 +7113,12 @@ func (s *GatewayService) buildUpstreamRequest(ctx context.Context, c *gin.Contex
 		tokenType, mimicClaudeCode, modelID, clientHeaders, body, effectiveDropSet,
 	)
 
+	// 已开启透传的服务端 beta 工具：补齐所需 beta token（mimic 路径会忽略客户端 beta，需在此补回）
+	if tokens := requiredBetaToolTokens(body); len(tokens) > 0 {
+		finalBetaHeader = mergeAnthropicBetaDropping(tokens, finalBetaHeader, effectiveDropSet)
+		finalBetaShouldSet = true
+	}
+
 	// 账号覆写了 anthropic-beta 时，覆写值即最终上游值（由下方 ApplyHeaderOverrides 写入）：
 	// body 能力净化必须以覆写值为准，否则 header/body 不对称会被上游 400。
 	if beta, ok := account.HeaderOverrideValue("anthropic-beta"); ok {
@@ -8391,6 +8466,10 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 
 	usage := &ClaudeUsage{}
 	var firstTokenMs *int
+	limitUpstreamStreamBody(c, s.cfg, resp)
+	// 慢客户端保护：单次写超时 + 上游积压预算
+	streamGuard := newStreamClientGuard(c, s.cfg)
+	streamGuard.watchUpstream(resp)
 	scanner := bufio.NewScanner(resp.Body)
 	// 设置更大的buffer以处理长行
 	maxLineSize := defaultMaxLineSize
@@ -8399,6 +8478,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 	}
 	scanBuf := getSSEScannerBuf64K()
 	scanner.Buffer(scanBuf[:0], maxLineSize)
+	applySSEOversizeLinePolicy(scanner, s.cfg, maxLineSize)
 
 	type scanEvent struct {
 		line string
@@ -8695,6 +8775,11 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs, clientDisconnect: true}, fmt.Errorf("stream usage incomplete after disconnect: %w", ev.err)
 				}
 				// 客户端未断开，正常的错误处理
+				// 累计字节超出预算属于主动中止，不走 failover
+				if errors.Is(ev.err, ErrUpstreamStreamTooLarge) {
+					sendErrorEvent("response_too_large", "upstream stream exceeded the configured size budget")
+					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, ev.err
+				}
 				if errors.Is(ev.err, bufio.ErrTooLong) {
 					logger.LegacyPrintf("service.gateway", "SSE line too long: account=%d max_size=%d error=%v", account.ID, maxLineSize, ev.err)
 					sendErrorEvent("response_too_large", fmt.Sprintf("upstream SSE line exceeded %d bytes", maxLineSize))
@@ -8726,6 +8811,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 				return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, fmt.Errorf("stream read error: %w", ev.err)
 			}
 			line := ev.line
+			streamGuard.consume(len(line) + 1)
 			trimmed := strings.TrimSpace(line)
 
 			if trimmed == "" {
@@ -8745,12 +8831,16 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 				for _, block := range outputBlocks {
 					if !clientDisconnected {
 						restored := reverseToolNamesIfPresent(c, []byte(block))
-						if _, werr := fmt.Fprint(w, string(restored)); werr != nil {
+						streamGuard.beginWrite()
+						_, werr := fmt.Fprint(w, string(restored))
+						if werr == nil {
+							flusher.Flush()
+						}
+						if werr = streamGuard.endWrite(werr); werr != nil {
 							clientDisconnected = true
-							logger.LegacyPrintf("service.gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
+							logger.LegacyPrintf("service.gateway", "Client disconnected during streaming, continuing to drain upstream for billing: %v", werr)
 							break
 						}
-						flusher.Flush()
 						lastDataAt = time.Now()
 						resetKeepaliveTimer()
 					}
@@ -8799,12 +8889,16 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 					keepaliveBlock = block
 				}
 			}
-			if _, werr := fmt.Fprint(w, keepaliveBlock); werr != nil {
+			streamGuard.beginWrite()
+			_, werr := fmt.Fprint(w, keepaliveBlock)
+			if werr == nil {
+				flusher.Flush()
+			}
+			if werr = streamGuard.endWrite(werr); werr != nil {
 				clientDisconnected = true
-				logger.LegacyPrintf("service.gateway", "Client disconnected during keepalive ping, continuing to drain upstream for billing")
+				logger.LegacyPrintf("service.gateway", "Client disconnected during keepalive ping, continuing to drain upstream for billing: %v", werr)
 				continue
 			}
-			flusher.Flush()
 			lastDataAt = time.Now()
 			resetKeepaliveTimer()
 		}
@@ -9154,6 +9248,14 @@ func (s *GatewayService) ResolveUserGroupRateMultiplier(ctx context.Context, use
 	return s.getUserGroupRateMultiplier(ctx, userID, groupID, groupDefaultMultiplier)
 }
 
+// EstimateRequestCostCeiling 按当前价格估算请求费用上界，供单请求费用上限在转发前拦截（见 BillingService.EstimateRequestCostCeiling）。
+func (s *GatewayService) EstimateRequestCostCeiling(body []byte, format, pathModel string, rateMultiplier float64) (*RequestCostEstimate, error) {
+	if s == nil || s.billingService == nil {
+		return nil, nil
+	}
+	return s.billingService.EstimateRequestCostCeiling(body, format, pathModel, rateMultiplier)
+}
+
 // RecordUsageInput 记录使用量的输入参数。
 // 异步 worker 只接收计费所需快照，不能持有 ParsedRequest/RequestBodyRef 这类大请求体引用。
 type RecordUsageInput struct {
@@ -9184,6 +9286,14 @@ type apiKeyAuthCacheInvalidator interface {
 	InvalidateAuthCacheByKey(ctx context.Context, key string)
 }
 
+type parentAPIKeyAuthCacheInvalidator interface {
+	InvalidateParentKeyAuthCache(ctx context.Context, parentKeyID int64)
+}
+
+type subAPIKeyAuthCacheInvalidator interface {
+	InvalidateSubKeyAuthCache(ctx context.Context, parentKeyID int64)
+}
+
 type usageLogBestEffortWriter interface {
 	CreateBestEffort(ctx context.Context, log *UsageLog) error
 }
@@ -9227,7 +9337,13 @@ func QuotaPlatform(ctx context.Context, apiKey *APIKey) string {
 }
 
 func (p *postUsageBillingParams) shouldDeductAPIKeyQuota() bool {
-	return p.Cost.ActualCost > 0 && p.APIKey.Quota > 0 && p.APIKeyService != nil
+	// 委托子 Key 始终累计自身用量，便于父 Key 持有者查看各子 Key 的花费
+	return p.Cost.ActualCost > 0 && (p.APIKey.Quota > 0 || p.APIKey.IsSubKey()) && p.APIKeyService != nil
+}
+
+// shouldDeductParentAPIKeyQuota 委托子 Key 的用量同时计入父 Key 额度
+func (p *postUsageBillingParams) shouldDeductParentAPIKeyQuota() bool {
+	return p.Cost.ActualCost > 0 && p.APIKey.IsSubKey() && p.APIKeyService != nil
 }
 
 func (p *postUsageBillingParams) shouldUpdateRateLimits() bool {
@@ -9273,6 +9389,12 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 		}
 	}
 
+	if p.shouldDeductParentAPIKeyQuota() {
+		if err := p.APIKeyService.UpdateQuotaUsed(billingCtx, *p.APIKey.ParentKeyID, cost.ActualCost); err != nil {
+			slog.Error("update parent api key quota failed", "api_key_id", p.APIKey.ID, "parent_key_id", *p.APIKey.ParentKeyID, "error", err)
+		}
+	}
+
 	if p.shouldUpdateRateLimits() {
 		if err := p.APIKeyService.UpdateRateLimitUsage(billingCtx, p.APIKey.ID, cost.ActualCost); err != nil {
 			slog.Error("update api key rate limit usage failed", "api_key_id", p.APIKey.ID, "error", err)
@@ -9313,13 +9435,8 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 }
 
 func resolveUsageBillingRequestID(ctx context.Context, upstreamRequestID string) string {
-	if ctx != nil {
-		if clientRequestID, _ := ctx.Value(ctxkey.ClientRequestID).(string); strings.TrimSpace(clientRequestID) != "" {
-			return "client:" + strings.TrimSpace(clientRequestID)
-		}
-		if requestID, _ := ctx.Value(ctxkey.RequestID).(string); strings.TrimSpace(requestID) != "" {
-			return "local:" + strings.TrimSpace(requestID)
-		}
+	if requestID := UsageRequestIDFromContext(ctx); requestID != "" {
+		return requestID
 	}
 	if requestID := strings.TrimSpace(upstreamRequestID); requestID != "" {
 		return requestID
@@ -9388,6 +9505,10 @@ func buildUsageBillingCommand(requestID string, usageLog *UsageLog, p *postUsage
 	if p.shouldDeductAPIKeyQuota() {
 		cmd.APIKeyQuotaCost = p.Cost.ActualCost
 	}
+	if p.shouldDeductParentAPIKeyQuota() {
+		cmd.ParentAPIKeyID = *p.APIKey.ParentKeyID
+		cmd.ParentAPIKeyQuotaCost = p.Cost.ActualCost
+	}
 	if p.shouldUpdateRateLimits() {
 		cmd.APIKeyRateLimitCost = p.Cost.ActualCost
 	}
@@ -9410,11 +9531,16 @@ func applyUsageBilling(ctx context.Context, requestID string, usageLog *UsageLog
 		return true, nil
 	}
 
+	if deferUsageRecordWhileDegraded(usageRecordDeadLetterReasonBilling, cmd, usageLog) {
+		return false, errUsageRecordDeferred
+	}
+
 	billingCtx, cancel := detachedBillingContext(ctx)
 	defer cancel()
 
 	result, err := repo.Apply(billingCtx, cmd)
 	if err != nil {
+		enqueueUsageRecordDeadLetter(usageRecordDeadLetterReasonBilling, cmd, usageLog, err)
 		return false, err
 	}
 
@@ -9427,6 +9553,15 @@ func applyUsageBilling(ctx context.Context, requestID string, usageLog *UsageLog
 		if invalidator, ok := p.APIKeyService.(apiKeyAuthCacheInvalidator); ok && p.APIKey != nil && p.APIKey.Key != "" {
 			invalidator.InvalidateAuthCacheByKey(billingCtx, p.APIKey.Key)
 		}
+		// 父 Key 自身额度耗尽时，子 Key 认证快照中的父 Key 状态同样失效
+		if invalidator, ok := p.APIKeyService.(subAPIKeyAuthCacheInvalidator); ok && p.APIKey != nil && p.APIKey.ParentKeyID == nil {
+			invalidator.InvalidateSubKeyAuthCache(billingCtx, p.APIKey.ID)
+		}
+	}
+	if result.ParentAPIKeyQuotaExhausted && p.APIKey != nil && p.APIKey.ParentKeyID != nil {
+		if invalidator, ok := p.APIKeyService.(parentAPIKeyAuthCacheInvalidator); ok {
+			invalidator.InvalidateParentKeyAuthCache(billingCtx, *p.APIKey.ParentKeyID)
+		}
 	}
 
 	finalizePostUsageBilling(billingCtx, p, deps, result)
@@ -9597,14 +9732,14 @@ func detachStreamUpstreamContext(ctx context.Context, stream bool) (context.Cont
 	if !stream {
 		return ctx, func() {}
 	}
-	return context.WithoutCancel(ctx), func() {}
+	return watchClientDisconnect(ctx, context.WithoutCancel(ctx)), func() {}
 }
 
 func detachUpstreamContext(ctx context.Context) (context.Context, context.CancelFunc) {
 	if ctx == nil {
 		return context.Background(), func() {}
 	}
-	return context.WithoutCancel(ctx), func() {}
+	return watchClientDisconnect(ctx, context.WithoutCancel(ctx)), func() {}
 }
 
 // billingDeps 扣费逻辑依赖的服务（由各 gateway service 提供）
@@ -9636,6 +9771,10 @@ func writeUsageLogBestEffort(ctx context.Context, repo UsageLogRepository, usage
 	if repo == nil || usageLog == nil {
 		return
 	}
+	applyBetaFeaturesToUsageLog(ctx, usageLog)
+	if deferUsageRecordWhileDegraded(usageRecordDeadLetterReasonUsageLog, nil, usageLog) {
+		return
+	}
 	usageCtx, cancel := detachedBillingContext(ctx)
 	defer cancel()
 
@@ -9654,6 +9793,7 @@ func writeUsageLogBestEffort(ctx context.Context, repo UsageLogRepository, usage
 			}
 			if _, syncErr := repo.Create(fallbackCtx, usageLog); syncErr != nil {
 				logger.LegacyPrintf(logKey, "Create usage log sync fallback failed: %v", syncErr)
+				enqueueUsageRecordDeadLetter(usageRecordDeadLetterReasonUsageLog, nil, usageLog, syncErr)
 			}
 		}
 		return
@@ -9661,6 +9801,7 @@ func writeUsageLogBestEffort(ctx context.Context, repo UsageLogRepository, usage
 
 	if _, err := repo.Create(usageCtx, usageLog); err != nil {
 		logger.LegacyPrintf(logKey, "Create usage log failed: %v", err)
+		enqueueUsageRecordDeadLetter(usageRecordDeadLetterReasonUsageLog, nil, usageLog, err)
 	}
 }
 
@@ -9762,6 +9903,8 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 	account := input.Account
 	subscription := input.Subscription
 	ApplyForwardImageBillingResolution(result)
+	// 缓存命中按上游实际返回判断，不受下方强制缓存计费改写影响
+	cacheHit := result.Usage.CacheReadInputTokens > 0
 
 	// 强制缓存计费：将 input_tokens 转为 cache_read_input_tokens
 	// 用于粘性会话切换时的特殊计费处理
@@ -9810,6 +9953,7 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 
 	// 计算费用
 	cost := s.calculateRecordUsageCost(ctx, result, apiKey, billingModel, multiplier, imageMultiplier, opts)
+	RequestAnnotationsFromContext(ctx).SetUsage(cacheHit, cost.ActualCost)
 
 	// 判断计费方式：订阅模式 vs 余额模式
 	isSubscriptionBilling := subscription != nil && apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
@@ -9868,6 +10012,9 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 		Platform:              quotaPlatform,
 	}, s.billingDeps(), s.usageBillingRepo)
 
+	if errors.Is(billingErr, errUsageRecordDeferred) {
+		return nil
+	}
 	if billingErr != nil {
 		return billingErr
 	}
@@ -9969,6 +10116,7 @@ func (s *GatewayService) calculateTokenCost(
 		CacheCreation5mTokens: result.Usage.CacheCreation5mTokens,
 		CacheCreation1hTokens: result.Usage.CacheCreation1hTokens,
 		ImageOutputTokens:     result.Usage.ImageOutputTokens,
+		ReasoningTokens:       result.Usage.ReasoningTokens,
 	}
 
 	var cost *CostBreakdown
@@ -10038,6 +10186,7 @@ func (s *GatewayService) buildRecordUsageLog(
 		CacheCreation5mTokens: result.Usage.CacheCreation5mTokens,
 		CacheCreation1hTokens: result.Usage.CacheCreation1hTokens,
 		ImageOutputTokens:     result.Usage.ImageOutputTokens,
+		ReasoningTokens:       result.Usage.ReasoningTokens,
 		RateMultiplier:        multiplier,
 		AccountRateMultiplier: &accountRateMultiplier,
 		BillingType:           billingType,
@@ -10066,6 +10215,7 @@ func (s *GatewayService) buildRecordUsageLog(
 	if cost != nil {
 		usageLog.InputCost = cost.InputCost
 		usageLog.OutputCost = cost.OutputCost
+		usageLog.ReasoningCost = cost.ReasoningCost
 		usageLog.ImageOutputCost = cost.ImageOutputCost
 		usageLog.CacheCreationCost = cost.CacheCreationCost
 		usageLog.CacheReadCost = cost.CacheReadCost
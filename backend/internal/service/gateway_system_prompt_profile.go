@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// Claude OAuth 账号转发非 Claude Code 客户端请求时的 system prompt 注入档位（Group.SystemPromptProfile）
+const (
+	// SystemPromptProfileClaudeCode system 仅保留 Claude Code 标识块（billing + 身份 + 全局配置的扩展提示词），
+	// 客户端 system 迁移到 messages 开头的 user/assistant 消息对。
+	SystemPromptProfileClaudeCode = "claude-code"
+	// SystemPromptProfileAPIDefault 仅在 system 前追加 Claude Code 标识：客户端未传 system 时注入标识块，
+	// 传了则保留在原位，并在第一个文本块前加同样的标识前缀。
+	SystemPromptProfileAPIDefault = "api-default"
+	// SystemPromptProfileNoInjection 不注入任何内容，客户端 system 原样透传。
+	SystemPromptProfileNoInjection = "no-injection"
+)
+
+var ErrInvalidSystemPromptProfile = infraerrors.BadRequest("INVALID_SYSTEM_PROMPT_PROFILE", "system_prompt_profile must be one of: claude-code, api-default, no-injection")
+
+// NormalizeSystemPromptProfile 规范化分组的注入档位；空字符串表示沿用全局设置。
+func NormalizeSystemPromptProfile(profile string) (string, error) {
+	profile = strings.ToLower(strings.TrimSpace(profile))
+	switch profile {
+	case "", SystemPromptProfileClaudeCode, SystemPromptProfileAPIDefault, SystemPromptProfileNoInjection:
+		return profile, nil
+	default:
+		return "", ErrInvalidSystemPromptProfile
+	}
+}
+
+// resolveSystemPromptProfile 返回本次请求生效的注入档位。
+// 分组显式设置时以分组为准；未设置（或未绑定分组）时按全局注入开关映射为 claude-code / no-injection。
+// 分组只从请求 ctx 读取（认证中间件已写入），不额外查库。
+func (s *GatewayService) resolveSystemPromptProfile(ctx context.Context) (profile, expansionPrompt, blocksConfig string) {
+	enabled, expansionPrompt, blocksConfig := s.claudeOAuthSystemPromptInjectionSettings(ctx)
+	if group, ok := ctx.Value(ctxkey.Group).(*Group); ok && IsGroupContextValid(group) {
+		if normalized, err := NormalizeSystemPromptProfile(group.SystemPromptProfile); err == nil && normalized != "" {
+			return normalized, expansionPrompt, blocksConfig
+		}
+	}
+	if enabled {
+		return SystemPromptProfileClaudeCode, expansionPrompt, blocksConfig
+	}
+	return SystemPromptProfileNoInjection, expansionPrompt, blocksConfig
+}
+
+// applySystemPromptProfile 按档位改写 body 中的 system。
+// 返回值 rewritten 表示 system 已由网关重建（此时保留注入块的 cache_control，否则剥离客户端 cache_control）。
+func (s *GatewayService) applySystemPromptProfile(ctx context.Context, body []byte, system any) ([]byte, bool) {
+	profile, expansionPrompt, blocksConfig := s.resolveSystemPromptProfile(ctx)
+	switch profile {
+	case SystemPromptProfileClaudeCode:
+		return rewriteSystemForNonClaudeCodeWithPromptBlocks(body, normalizeSystemParam(system), expansionPrompt, blocksConfig), true
+	case SystemPromptProfileAPIDefault:
+		return injectClaudeCodePrompt(body, system), true
+	default:
+		return body, false
+	}
+}
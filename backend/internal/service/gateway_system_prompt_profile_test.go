@@ -0,0 +1,85 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func systemPromptProfileCtx(profile string) context.Context {
+	group := &Group{ID: 3, Platform: PlatformAnthropic, Status: StatusActive, Hydrated: true, SystemPromptProfile: profile}
+	return context.WithValue(context.Background(), ctxkey.Group, group)
+}
+
+func TestNormalizeSystemPromptProfile(t *testing.T) {
+	for _, in := range []string{"", "claude-code", " API-Default ", "no-injection"} {
+		got, err := NormalizeSystemPromptProfile(in)
+		require.NoError(t, err, in)
+		require.Equal(t, strings.ToLower(strings.TrimSpace(in)), got)
+	}
+
+	_, err := NormalizeSystemPromptProfile("opencode")
+	require.Error(t, err)
+	require.Equal(t, "INVALID_SYSTEM_PROMPT_PROFILE", infraerrors.Reason(err))
+}
+
+func TestResolveSystemPromptProfile_FallsBackToGlobalSetting(t *testing.T) {
+	svc := &GatewayService{}
+
+	// 无设置服务时全局注入默认开启
+	profile, _, _ := svc.resolveSystemPromptProfile(context.Background())
+	require.Equal(t, SystemPromptProfileClaudeCode, profile)
+
+	profile, _, _ = svc.resolveSystemPromptProfile(systemPromptProfileCtx(""))
+	require.Equal(t, SystemPromptProfileClaudeCode, profile)
+
+	profile, _, _ = svc.resolveSystemPromptProfile(systemPromptProfileCtx(SystemPromptProfileNoInjection))
+	require.Equal(t, SystemPromptProfileNoInjection, profile)
+}
+
+func TestApplySystemPromptProfile_ClaudeCodeRelocatesClientSystem(t *testing.T) {
+	svc := &GatewayService{}
+	body := []byte(`{"system":"You are a pirate.","messages":[{"role":"user","content":"hi"}]}`)
+
+	out, rewritten := svc.applySystemPromptProfile(systemPromptProfileCtx(SystemPromptProfileClaudeCode), body, "You are a pirate.")
+	require.True(t, rewritten)
+	require.NotContains(t, gjson.GetBytes(out, "system").Raw, "pirate")
+	require.Contains(t, gjson.GetBytes(out, "messages.0.content").Raw, "pirate")
+}
+
+func TestApplySystemPromptProfile_APIDefault(t *testing.T) {
+	svc := &GatewayService{}
+	ctx := systemPromptProfileCtx(SystemPromptProfileAPIDefault)
+
+	// 客户端未传 system：只注入 Claude Code 标识块
+	out, rewritten := svc.applySystemPromptProfile(ctx, []byte(`{"messages":[{"role":"user","content":"hi"}]}`), nil)
+	require.True(t, rewritten)
+	system := gjson.GetBytes(out, "system").Array()
+	require.Len(t, system, 1)
+	require.Equal(t, claudeCodeSystemPrompt, system[0].Get("text").String())
+
+	// 客户端传了 system：保留在 system 内并加标识前缀，messages 不变
+	body := []byte(`{"system":[{"type":"text","text":"You are a pirate."}],"messages":[{"role":"user","content":"hi"}]}`)
+	out, rewritten = svc.applySystemPromptProfile(ctx, body, []any{map[string]any{"type": "text", "text": "You are a pirate."}})
+	require.True(t, rewritten)
+	system = gjson.GetBytes(out, "system").Array()
+	require.Len(t, system, 2)
+	require.True(t, strings.HasSuffix(system[1].Get("text").String(), "You are a pirate."))
+	require.Equal(t, int64(1), gjson.GetBytes(out, "messages.#").Int())
+}
+
+func TestApplySystemPromptProfile_NoInjectionPassesThrough(t *testing.T) {
+	svc := &GatewayService{}
+	body := []byte(`{"system":"You are a pirate.","messages":[{"role":"user","content":"hi"}]}`)
+
+	out, rewritten := svc.applySystemPromptProfile(systemPromptProfileCtx(SystemPromptProfileNoInjection), body, "You are a pirate.")
+	require.False(t, rewritten)
+	require.Equal(t, string(body), string(out))
+}
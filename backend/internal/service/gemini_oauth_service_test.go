@@ -747,6 +747,7 @@ func (m *mockGeminiOAuthClient) RefreshToken(ctx context.Context, oauthType, ref
 type mockGeminiCodeAssistClient struct {
 	loadCodeAssistFunc func(ctx context.Context, accessToken, proxyURL string, req *geminicli.LoadCodeAssistRequest) (*geminicli.LoadCodeAssistResponse, error)
 	onboardUserFunc    func(ctx context.Context, accessToken, proxyURL string, req *geminicli.OnboardUserRequest) (*geminicli.OnboardUserResponse, error)
+	retrieveQuotaFunc  func(ctx context.Context, accessToken, proxyURL string, req *geminicli.RetrieveUserQuotaRequest) (*geminicli.RetrieveUserQuotaResponse, error)
 }
 
 func (m *mockGeminiCodeAssistClient) LoadCodeAssist(ctx context.Context, accessToken, proxyURL string, req *geminicli.LoadCodeAssistRequest) (*geminicli.LoadCodeAssistResponse, error) {
@@ -763,6 +764,13 @@ func (m *mockGeminiCodeAssistClient) OnboardUser(ctx context.Context, accessToke
 	panic("OnboardUser not implemented")
 }
 
+func (m *mockGeminiCodeAssistClient) RetrieveUserQuota(ctx context.Context, accessToken, proxyURL string, req *geminicli.RetrieveUserQuotaRequest) (*geminicli.RetrieveUserQuotaResponse, error) {
+	if m.retrieveQuotaFunc != nil {
+		return m.retrieveQuotaFunc(ctx, accessToken, proxyURL, req)
+	}
+	panic("RetrieveUserQuota not implemented")
+}
+
 // =====================
 // mock: ProxyRepository (最小实现)
 // =====================
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
+)
+
+const (
+	geminiQuotaProbeTimeout = 15 * time.Second
+	// geminiQuotaExhaustedReason 配额探测发现耗尽时写入模型限流的原因
+	geminiQuotaExhaustedReason = "gemini_quota_exhausted"
+	// geminiQuotaProbeExtraKey 最近一次探测结果在 account.Extra 中的 key
+	geminiQuotaProbeExtraKey = "gemini_quota_probe"
+)
+
+// GeminiQuotaProber 通过 Code Assist retrieveUserQuota 探测账号真实剩余配额。
+//
+// 配额耗尽的模型桶按模型限流到上游报告的重置时间；项目级桶或全部模型桶耗尽时整个账号限流，
+// 使调度在请求失败前就跳过该账号。探测按账号节流，失败只记日志。
+type GeminiQuotaProber struct {
+	accountRepo AccountRepository
+	proxyRepo   ProxyRepository
+	codeAssist  GeminiCliCodeAssistClient
+	interval    time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	lastProbe map[int64]time.Time
+}
+
+// GeminiQuotaProbeResult 一次配额探测的结论。
+type GeminiQuotaProbeResult struct {
+	Buckets []geminicli.UserQuotaBucket
+	// AccountResetAt 非空表示整个账号配额耗尽
+	AccountResetAt *time.Time
+	// ExhaustedModels 配额耗尽的模型及其重置时间
+	ExhaustedModels map[string]time.Time
+}
+
+// NewGeminiQuotaProber creates a GeminiQuotaProber. interval <= 0 disables background probing.
+func NewGeminiQuotaProber(accountRepo AccountRepository, proxyRepo ProxyRepository, codeAssist GeminiCliCodeAssistClient, interval time.Duration) *GeminiQuotaProber {
+	return &GeminiQuotaProber{
+		accountRepo: accountRepo,
+		proxyRepo:   proxyRepo,
+		codeAssist:  codeAssist,
+		interval:    interval,
+		now:         time.Now,
+		lastProbe:   make(map[int64]time.Time),
+	}
+}
+
+// CanProbe 仅 Code Assist（含 project_id）的 OAuth 账号可探测。
+func (p *GeminiQuotaProber) CanProbe(account *Account) bool {
+	return p != nil && p.codeAssist != nil && account != nil &&
+		account.Platform == PlatformGemini && account.Type == AccountTypeOAuth &&
+		strings.TrimSpace(account.GetCredential("project_id")) != ""
+}
+
+// MaybeProbe 距上次探测超过 interval 时在后台探测一次，不阻塞调用方。
+func (p *GeminiQuotaProber) MaybeProbe(account *Account, accessToken string) {
+	if p == nil || p.interval <= 0 || !p.CanProbe(account) || strings.TrimSpace(accessToken) == "" {
+		return
+	}
+	now := p.now()
+	p.mu.Lock()
+	if last, ok := p.lastProbe[account.ID]; ok && now.Sub(last) < p.interval {
+		p.mu.Unlock()
+		return
+	}
+	p.lastProbe[account.ID] = now
+	p.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), geminiQuotaProbeTimeout)
+		defer cancel()
+		if _, err := p.Probe(ctx, account, accessToken); err != nil {
+			slog.Warn("gemini_quota_probe_failed", "account_id", account.ID, "error", err)
+		}
+	}()
+}
+
+// Probe 同步探测并应用结果（限流耗尽的账号/模型，记录快照到 account.Extra）。
+func (p *GeminiQuotaProber) Probe(ctx context.Context, account *Account, accessToken string) (*GeminiQuotaProbeResult, error) {
+	if !p.CanProbe(account) {
+		return nil, nil
+	}
+	var proxyURL string
+	if account.ProxyID != nil && p.proxyRepo != nil {
+		if proxy, err := p.proxyRepo.GetByID(ctx, *account.ProxyID); err == nil && proxy != nil {
+			proxyURL = proxy.URL()
+		}
+	}
+	resp, err := p.codeAssist.RetrieveUserQuota(ctx, accessToken, proxyURL, &geminicli.RetrieveUserQuotaRequest{
+		Project: strings.TrimSpace(account.GetCredential("project_id")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+	result := evaluateGeminiQuotaBuckets(resp.Buckets, now)
+	p.apply(ctx, account, result, now)
+	return result, nil
+}
+
+func (p *GeminiQuotaProber) apply(ctx context.Context, account *Account, result *GeminiQuotaProbeResult, now time.Time) {
+	if p.accountRepo == nil {
+		return
+	}
+	for model, resetAt := range result.ExhaustedModels {
+		if err := p.accountRepo.SetModelRateLimit(ctx, account.ID, model, resetAt, geminiQuotaExhaustedReason); err != nil {
+			slog.Warn("gemini_quota_probe_set_model_rate_limit_failed", "account_id", account.ID, "model", model, "error", err)
+			continue
+		}
+		slog.Info("gemini_model_quota_exhausted", "account_id", account.ID, "model", model, "reset_at", resetAt)
+	}
+	if result.AccountResetAt != nil {
+		if err := p.accountRepo.SetRateLimited(ctx, account.ID, *result.AccountResetAt); err != nil {
+			slog.Warn("gemini_quota_probe_set_rate_limited_failed", "account_id", account.ID, "error", err)
+		} else {
+			slog.Info("gemini_quota_exhausted", "account_id", account.ID, "reset_at", *result.AccountResetAt)
+		}
+	}
+
+	snapshot := make([]map[string]any, 0, len(result.Buckets))
+	for _, bucket := range result.Buckets {
+		item := map[string]any{"model_id": bucket.ModelID, "token_type": bucket.TokenType, "reset_time": bucket.ResetTime}
+		if bucket.RemainingFraction != nil {
+			item["remaining_fraction"] = *bucket.RemainingFraction
+		}
+		snapshot = append(snapshot, item)
+	}
+	if err := p.accountRepo.UpdateExtra(ctx, account.ID, map[string]any{
+		geminiQuotaProbeExtraKey: map[string]any{"probed_at": now.UTC().Format(time.RFC3339), "buckets": snapshot},
+	}); err != nil {
+		slog.Warn("gemini_quota_probe_update_extra_failed", "account_id", account.ID, "error", err)
+	}
+}
+
+// evaluateGeminiQuotaBuckets 找出已耗尽的请求配额桶。无法识别剩余量或重置时间的桶视为未知，不做判断。
+func evaluateGeminiQuotaBuckets(buckets []geminicli.UserQuotaBucket, now time.Time) *GeminiQuotaProbeResult {
+	result := &GeminiQuotaProbeResult{Buckets: buckets, ExhaustedModels: make(map[string]time.Time)}
+	models := make(map[string]struct{})
+	var earliest *time.Time
+	for _, bucket := range buckets {
+		if tokenType := strings.ToUpper(strings.TrimSpace(bucket.TokenType)); tokenType != "" && tokenType != "REQUESTS" {
+			continue
+		}
+		model := strings.TrimSpace(bucket.ModelID)
+		if model != "" {
+			models[model] = struct{}{}
+		}
+		if bucket.RemainingFraction == nil || *bucket.RemainingFraction > 0 {
+			continue
+		}
+		resetAt, err := time.Parse(time.RFC3339, strings.TrimSpace(bucket.ResetTime))
+		if err != nil || !resetAt.After(now) {
+			continue
+		}
+		if model == "" {
+			if result.AccountResetAt == nil || resetAt.After(*result.AccountResetAt) {
+				result.AccountResetAt = &resetAt
+			}
+			continue
+		}
+		if prev, ok := result.ExhaustedModels[model]; !ok || resetAt.After(prev) {
+			result.ExhaustedModels[model] = resetAt
+		}
+		if earliest == nil || resetAt.Before(*earliest) {
+			earliest = &resetAt
+		}
+	}
+	// 全部模型桶均耗尽：整个账号不可用，至最早恢复的模型重置为止
+	if result.AccountResetAt == nil && len(models) > 0 && len(result.ExhaustedModels) == len(models) {
+		result.AccountResetAt = earliest
+	}
+	return result
+}
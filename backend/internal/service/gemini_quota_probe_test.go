@@ -0,0 +1,99 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
+	"github.com/stretchr/testify/require"
+)
+
+type geminiQuotaProbeRepoSpy struct {
+	AccountRepository
+	rateLimitedUntil *time.Time
+	modelLimits      map[string]time.Time
+	extra            map[string]any
+}
+
+func (s *geminiQuotaProbeRepoSpy) SetRateLimited(_ context.Context, _ int64, resetAt time.Time) error {
+	s.rateLimitedUntil = &resetAt
+	return nil
+}
+
+func (s *geminiQuotaProbeRepoSpy) SetModelRateLimit(_ context.Context, _ int64, scope string, resetAt time.Time, _ ...string) error {
+	if s.modelLimits == nil {
+		s.modelLimits = make(map[string]time.Time)
+	}
+	s.modelLimits[scope] = resetAt
+	return nil
+}
+
+func (s *geminiQuotaProbeRepoSpy) UpdateExtra(_ context.Context, _ int64, updates map[string]any) error {
+	s.extra = updates
+	return nil
+}
+
+func geminiQuotaFraction(v float64) *float64 { return &v }
+
+func TestEvaluateGeminiQuotaBuckets(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	reset := now.Add(6 * time.Hour).Format(time.RFC3339)
+
+	result := evaluateGeminiQuotaBuckets([]geminicli.UserQuotaBucket{
+		{ModelID: "gemini-2.5-pro", TokenType: "REQUESTS", RemainingFraction: geminiQuotaFraction(0), ResetTime: reset},
+		{ModelID: "gemini-2.5-flash", TokenType: "REQUESTS", RemainingFraction: geminiQuotaFraction(0.4), ResetTime: reset},
+		{ModelID: "gemini-2.5-flash", TokenType: "TOKENS", RemainingFraction: geminiQuotaFraction(0), ResetTime: reset},
+		{ModelID: "gemini-2.0-flash", RemainingFraction: geminiQuotaFraction(0), ResetTime: now.Add(-time.Minute).Format(time.RFC3339)},
+		{ModelID: "gemini-unknown", ResetTime: reset},
+	}, now)
+	require.Nil(t, result.AccountResetAt)
+	require.Equal(t, map[string]time.Time{"gemini-2.5-pro": now.Add(6 * time.Hour)}, result.ExhaustedModels)
+
+	result = evaluateGeminiQuotaBuckets([]geminicli.UserQuotaBucket{
+		{ModelID: "gemini-2.5-pro", RemainingFraction: geminiQuotaFraction(0), ResetTime: now.Add(3 * time.Hour).Format(time.RFC3339)},
+		{ModelID: "gemini-2.5-flash", RemainingFraction: geminiQuotaFraction(0), ResetTime: now.Add(time.Hour).Format(time.RFC3339)},
+	}, now)
+	require.NotNil(t, result.AccountResetAt)
+	require.Equal(t, now.Add(time.Hour), *result.AccountResetAt, "all model buckets exhausted: account blocked until the earliest reset")
+}
+
+func TestGeminiQuotaProber_ProbeMarksExhausted(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	repo := &geminiQuotaProbeRepoSpy{}
+	var gotProject string
+	client := &mockGeminiCodeAssistClient{
+		retrieveQuotaFunc: func(_ context.Context, _, _ string, req *geminicli.RetrieveUserQuotaRequest) (*geminicli.RetrieveUserQuotaResponse, error) {
+			gotProject = req.Project
+			return &geminicli.RetrieveUserQuotaResponse{Buckets: []geminicli.UserQuotaBucket{
+				{RemainingFraction: geminiQuotaFraction(0), ResetTime: now.Add(2 * time.Hour).Format(time.RFC3339)},
+			}}, nil
+		},
+	}
+	prober := NewGeminiQuotaProber(repo, nil, client, time.Minute)
+	prober.now = func() time.Time { return now }
+
+	account := &Account{ID: 9, Platform: PlatformGemini, Type: AccountTypeOAuth, Credentials: map[string]any{"project_id": "proj-1"}}
+	result, err := prober.Probe(context.Background(), account, "token")
+	require.NoError(t, err)
+	require.NotNil(t, result.AccountResetAt)
+	require.Equal(t, "proj-1", gotProject)
+	require.NotNil(t, repo.rateLimitedUntil)
+	require.Equal(t, now.Add(2*time.Hour), *repo.rateLimitedUntil)
+	require.Contains(t, repo.extra, geminiQuotaProbeExtraKey)
+}
+
+func TestGeminiQuotaProber_SkipsNonCodeAssist(t *testing.T) {
+	prober := NewGeminiQuotaProber(&geminiQuotaProbeRepoSpy{}, nil, &mockGeminiCodeAssistClient{}, time.Minute)
+
+	require.False(t, prober.CanProbe(&Account{Platform: PlatformGemini, Type: AccountTypeOAuth}))
+	require.False(t, prober.CanProbe(&Account{Platform: PlatformGemini, Type: AccountTypeAPIKey, Credentials: map[string]any{"project_id": "p"}}))
+	result, err := prober.Probe(context.Background(), &Account{Platform: PlatformGemini, Type: AccountTypeOAuth}, "token")
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	var nilProber *GeminiQuotaProber
+	nilProber.MaybeProbe(&Account{}, "token")
+}
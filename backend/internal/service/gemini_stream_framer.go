@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+)
+
+// geminiStreamDoneMarker 个别兼容上游在流末尾发送的 data: [DONE]，原样转发给客户端
+var geminiStreamDoneMarker = []byte("[DONE]")
+
+// geminiStreamFramer 把 Gemini 上游流重新切分为完整的 JSON 对象，每个对象对应一个 SSE 事件。
+//
+// 上游（或中间代理）可能在任意位置切分 JSON：一个对象跨多个网络分块、跨多个 data: 行、
+// 被裸换行截断成不带 data: 前缀的续行，或忽略 alt=sse 直接返回 JSON 数组流。
+// 逐行透传时这些情况会让按行解析的客户端拿到半截 JSON。framer 不依赖分块和行边界，
+// 按括号深度（识别字符串与转义）找出完整对象，校验并压缩成单行后再交给调用方写出。
+type geminiStreamFramer struct {
+	maxObjectSize int
+
+	// sse 上游格式：nil 表示尚未看到首个非空白字节
+	sse *bool
+	// line SSE 模式下未遇到换行的半行
+	line []byte
+
+	// 当前未闭合的 JSON 对象
+	obj      []byte
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+func newGeminiStreamFramer(maxObjectSize int) *geminiStreamFramer {
+	if maxObjectSize <= 0 {
+		maxObjectSize = defaultMaxLineSize
+	}
+	return &geminiStreamFramer{maxObjectSize: maxObjectSize}
+}
+
+// Feed 输入一段上游原始字节，返回其中已完整的 JSON 对象（已压缩为单行）或 geminiStreamDoneMarker。
+// 单个对象超过 maxObjectSize 时返回 bufio.ErrTooLong。
+func (f *geminiStreamFramer) Feed(chunk []byte) ([][]byte, error) {
+	if f.sse == nil {
+		trimmed := bytes.TrimLeft(chunk, " \t\r\n\ufeff")
+		if len(trimmed) == 0 {
+			return nil, nil
+		}
+		sse := trimmed[0] != '{' && trimmed[0] != '['
+		f.sse = &sse
+	}
+	if !*f.sse {
+		return f.scan(chunk, nil)
+	}
+
+	var out [][]byte
+	for len(chunk) > 0 {
+		i := bytes.IndexByte(chunk, '\n')
+		if i < 0 {
+			f.line = append(f.line, chunk...)
+			if len(f.line) > f.maxObjectSize {
+				return out, bufio.ErrTooLong
+			}
+			return out, nil
+		}
+		f.line = append(f.line, chunk[:i]...)
+		chunk = chunk[i+1:]
+		var err error
+		out, err = f.sseLine(bytes.TrimSuffix(f.line, []byte("\r")), out)
+		f.line = f.line[:0]
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// Close 结束输入：处理末尾没有换行的最后一行，返回其中完整的对象，
+// 以及仍未闭合的残留内容（上游被截断，调用方只用于记录日志）。
+func (f *geminiStreamFramer) Close() (frames [][]byte, rest []byte) {
+	if len(f.line) > 0 {
+		frames, _ = f.sseLine(bytes.TrimSuffix(f.line, []byte("\r")), nil)
+	}
+	rest = bytes.TrimSpace(f.obj)
+	f.obj, f.line = nil, nil
+	f.depth, f.inString, f.escaped = 0, false, false
+	return frames, rest
+}
+
+// sseLine 处理一行 SSE：data: 的值送入对象扫描；对象未闭合时，无字段前缀的行视为被裸换行截断的续行。
+func (f *geminiStreamFramer) sseLine(line []byte, out [][]byte) ([][]byte, error) {
+	if value, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+		value = bytes.TrimPrefix(value, []byte(" "))
+		if f.depth == 0 && bytes.Equal(bytes.TrimSpace(value), geminiStreamDoneMarker) {
+			return append(out, geminiStreamDoneMarker), nil
+		}
+		// 同一事件的多行 data 按 SSE 规范以换行拼接，对象中间的换行是合法空白
+		return f.scan(append(value, '\n'), out)
+	}
+	if f.depth > 0 && !isGeminiSSEFieldLine(line) {
+		return f.scan(append(line, '\n'), out)
+	}
+	// 空行（事件分隔）、注释与 event:/id:/retry: 字段不影响对象切分
+	return out, nil
+}
+
+// scan 按括号深度切分对象；对象之外的 JSON 数组分隔符（[ , ]）、空白与无法识别的字节直接丢弃。
+func (f *geminiStreamFramer) scan(data []byte, out [][]byte) ([][]byte, error) {
+	start := -1
+	if f.depth > 0 {
+		start = 0
+	}
+	for i, b := range data {
+		if f.depth == 0 {
+			if b == '{' {
+				start = i
+				f.depth = 1
+			}
+			continue
+		}
+		switch {
+		case f.escaped:
+			f.escaped = false
+		case f.inString:
+			switch b {
+			case '\\':
+				f.escaped = true
+			case '"':
+				f.inString = false
+			}
+		case b == '"':
+			f.inString = true
+		case b == '{' || b == '[':
+			f.depth++
+		case b == '}' || b == ']':
+			f.depth--
+			if f.depth == 0 {
+				f.obj = append(f.obj, data[start:i+1]...)
+				if frame := compactGeminiStreamObject(f.obj); frame != nil {
+					out = append(out, frame)
+				}
+				f.obj = f.obj[:0]
+				start = -1
+			}
+		}
+	}
+	if start >= 0 {
+		f.obj = append(f.obj, data[start:]...)
+		if len(f.obj) > f.maxObjectSize {
+			return out, bufio.ErrTooLong
+		}
+	}
+	return out, nil
+}
+
+// compactGeminiStreamObject 把对象压缩为单行；括号配平但内容不是合法 JSON 时丢弃（返回 nil）。
+func compactGeminiStreamObject(obj []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(obj))
+	if err := json.Compact(&buf, obj); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func isGeminiSSEFieldLine(line []byte) bool {
+	return len(line) == 0 ||
+		line[0] == ':' ||
+		bytes.HasPrefix(line, []byte("event:")) ||
+		bytes.HasPrefix(line, []byte("id:")) ||
+		bytes.HasPrefix(line, []byte("retry:"))
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const geminiFramerChunk1 = `{"candidates":[{"content":{"parts":[{"text":"a } \"quoted\" { [x]"}],"role":"model"}}]}`
+const geminiFramerChunk2 = `{"candidates":[{"content":{"parts":[{"text":"你好\\n"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":5}}`
+
+func feedGeminiFramer(t *testing.T, f *geminiStreamFramer, chunks ...string) []string {
+	t.Helper()
+	var out []string
+	for _, chunk := range chunks {
+		frames, err := f.Feed([]byte(chunk))
+		require.NoError(t, err)
+		for _, frame := range frames {
+			out = append(out, string(frame))
+		}
+	}
+	frames, rest := f.Close()
+	require.Empty(t, rest)
+	for _, frame := range frames {
+		out = append(out, string(frame))
+	}
+	return out
+}
+
+// splitEvery 把 s 切成每段 n 字节（按字节切分，可能切开多字节字符）
+func splitEvery(s string, n int) []string {
+	var out []string
+	for len(s) > n {
+		out = append(out, s[:n])
+		s = s[n:]
+	}
+	return append(out, s)
+}
+
+func TestGeminiStreamFramer_SSEAnyChunkBoundary(t *testing.T) {
+	stream := "data: " + geminiFramerChunk1 + "\r\n\r\ndata: " + geminiFramerChunk2 + "\r\n\r\n"
+	want := []string{geminiFramerChunk1, geminiFramerChunk2}
+
+	for size := 1; size <= len(stream); size++ {
+		got := feedGeminiFramer(t, newGeminiStreamFramer(0), splitEvery(stream, size)...)
+		require.Equal(t, want, got, "chunk size %d", size)
+	}
+	// 在每个位置切成两段
+	for i := 1; i < len(stream); i++ {
+		got := feedGeminiFramer(t, newGeminiStreamFramer(0), stream[:i], stream[i:])
+		require.Equal(t, want, got, "split at %d", i)
+	}
+}
+
+func TestGeminiStreamFramer_MultiLineDataEvent(t *testing.T) {
+	stream := "data: {\"candidates\": [\ndata:   {\"index\": 0}\ndata: ]}\n\n"
+	got := feedGeminiFramer(t, newGeminiStreamFramer(0), stream)
+	require.Equal(t, []string{`{"candidates":[{"index":0}]}`}, got)
+}
+
+func TestGeminiStreamFramer_BareNewlineContinuation(t *testing.T) {
+	// 中间代理把一个 data 行在 JSON 中间插入了裸换行
+	stream := "data: {\"candidates\":[{\"content\":\n{\"parts\":[]}}]}\n\n: keepalive\n\ndata: {\"a\":1}\n\n"
+	got := feedGeminiFramer(t, newGeminiStreamFramer(0), stream)
+	require.Equal(t, []string{`{"candidates":[{"content":{"parts":[]}}]}`, `{"a":1}`}, got)
+}
+
+func TestGeminiStreamFramer_SeveralObjectsInOneEvent(t *testing.T) {
+	got := feedGeminiFramer(t, newGeminiStreamFramer(0), "data: {\"a\":1}{\"b\":2}\n\n")
+	require.Equal(t, []string{`{"a":1}`, `{"b":2}`}, got)
+}
+
+func TestGeminiStreamFramer_JSONArrayStream(t *testing.T) {
+	// 上游忽略 alt=sse 时返回的多行 JSON 数组流
+	stream := "[{\n  \"candidates\": [\n    {\"index\": 0}\n  ]\n}\n,\r\n{\n  \"usageMetadata\": {\"totalTokenCount\": 7}\n}\n]"
+	want := []string{`{"candidates":[{"index":0}]}`, `{"usageMetadata":{"totalTokenCount":7}}`}
+	for size := 1; size <= len(stream); size++ {
+		got := feedGeminiFramer(t, newGeminiStreamFramer(0), splitEvery(stream, size)...)
+		require.Equal(t, want, got, "chunk size %d", size)
+	}
+}
+
+func TestGeminiStreamFramer_LastLineWithoutNewline(t *testing.T) {
+	got := feedGeminiFramer(t, newGeminiStreamFramer(0), "data: {\"a\":1}\n\ndata: {\"b\"", ":2}")
+	require.Equal(t, []string{`{"a":1}`, `{"b":2}`}, got)
+}
+
+func TestGeminiStreamFramer_DoneMarkerAndInvalidObject(t *testing.T) {
+	got := feedGeminiFramer(t, newGeminiStreamFramer(0), "data: {\"a\":tru}\n\ndata: {\"b\":true}\n\ndata: [DONE]\n\n")
+	require.Equal(t, []string{`{"b":true}`, "[DONE]"}, got)
+}
+
+func TestGeminiStreamFramer_TruncatedAndOversize(t *testing.T) {
+	f := newGeminiStreamFramer(0)
+	frames, err := f.Feed([]byte("data: {\"a\":1}\n\ndata: {\"b\":\"unterminated"))
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	frames, rest := f.Close()
+	require.Empty(t, frames)
+	require.Equal(t, `{"b":"unterminated`, string(rest))
+
+	f = newGeminiStreamFramer(16)
+	_, err = f.Feed([]byte(`[{"text":"` + strings.Repeat("x", 32)))
+	require.ErrorIs(t, err, bufio.ErrTooLong)
+}
+
+// trickleReader 每次 Read 最多返回 n 字节，模拟任意的网络分块
+type trickleReader struct {
+	r io.Reader
+	n int
+}
+
+func (t *trickleReader) Read(p []byte) (int, error) {
+	if len(p) > t.n {
+		p = p[:t.n]
+	}
+	return t.r.Read(p)
+}
+
+func TestGeminiHandleNativeStreamingResponse_ReframesEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", nil)
+
+	upstream := "data: {\"response\": " + geminiFramerChunk1 + "}\r\n\r\ndata: {\"response\":\n" + geminiFramerChunk2 + "}\r\n\r\n"
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(&trickleReader{r: strings.NewReader(upstream), n: 7}),
+	}
+
+	svc := &GeminiMessagesCompatService{}
+	result, err := svc.handleNativeStreamingResponse(c, resp, time.Now(), true)
+	require.NoError(t, err)
+	require.NotNil(t, result.firstTokenMs)
+	require.Equal(t, 3, result.usage.InputTokens)
+	require.Equal(t, 5, result.usage.OutputTokens)
+
+	events := strings.Split(strings.TrimSuffix(w.Body.String(), "\n\n"), "\n\n")
+	require.Len(t, events, 2)
+	for _, event := range events {
+		payload, ok := strings.CutPrefix(event, "data: ")
+		require.True(t, ok, event)
+		require.NotContains(t, payload, "\n")
+		require.True(t, json.Valid([]byte(payload)), payload)
+	}
+	require.Equal(t, "data: "+geminiFramerChunk1, events[0])
+}
@@ -15,3 +15,8 @@ type GeminiTokenCache interface {
 	AcquireRefreshLock(ctx context.Context, cacheKey string, ttl time.Duration) (bool, error)
 	ReleaseRefreshLock(ctx context.Context, cacheKey string) error
 }
+
+// TokenCacheWiper 可一次性清空全部缓存 access token 的缓存实现（事故响应：怀疑 token 泄露时使用）。
+type TokenCacheWiper interface {
+	WipeAccessTokens(ctx context.Context) (int64, error)
+}
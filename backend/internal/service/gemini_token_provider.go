@@ -23,6 +23,7 @@ type GeminiTokenProvider struct {
 	refreshAPI         *OAuthRefreshAPI
 	executor           OAuthRefreshExecutor
 	refreshPolicy      ProviderRefreshPolicy
+	quotaProber        *GeminiQuotaProber
 }
 
 func NewGeminiTokenProvider(
@@ -49,6 +50,11 @@ func (p *GeminiTokenProvider) SetRefreshPolicy(policy ProviderRefreshPolicy) {
 	p.refreshPolicy = policy
 }
 
+// SetQuotaProber injects the Code Assist remaining-quota prober.
+func (p *GeminiTokenProvider) SetQuotaProber(prober *GeminiQuotaProber) {
+	p.quotaProber = prober
+}
+
 func (p *GeminiTokenProvider) GetAccessToken(ctx context.Context, account *Account) (string, error) {
 	if account == nil {
 		return "", errors.New("account is nil")
@@ -65,6 +71,7 @@ func (p *GeminiTokenProvider) GetAccessToken(ctx context.Context, account *Accou
 	// 1) Try cache first.
 	if p.tokenCache != nil {
 		if token, err := p.tokenCache.GetAccessToken(ctx, cacheKey); err == nil && strings.TrimSpace(token) != "" {
+			p.quotaProber.MaybeProbe(account, token)
 			return token, nil
 		}
 	}
@@ -168,6 +175,9 @@ func (p *GeminiTokenProvider) GetAccessToken(ctx context.Context, account *Accou
 		}
 	}
 
+	// 4) Background remaining-quota probe (Code Assist only, throttled per account).
+	p.quotaProber.MaybeProbe(account, accessToken)
+
 	return accessToken, nil
 }
 
@@ -91,6 +91,27 @@ type Group struct {
 	// 一旦设置即接管该分组用户的限流（覆盖用户级 rpm_limit），可被 user-group rpm_override 进一步覆盖。
 	RPMLimit int
 
+	// Anthropic beta 工具透传开关（默认关闭）：未开启时携带对应服务端工具的请求被拒绝，
+	// 开启后按工具版本自动补齐所需 anthropic-beta token。GA 版 web_search_20250305 不受 AllowWebSearchTool 约束。
+	AllowComputerUseTool   bool
+	AllowCodeExecutionTool bool
+	AllowWebSearchTool     bool
+
+	// SystemPromptProfile Claude OAuth 账号的 system prompt 注入档位（见 SystemPromptProfile* 常量），
+	// 空表示沿用全局注入设置。
+	SystemPromptProfile string
+
+	// ResponseLanguage 强制回复语言代码（见 ResponseLanguageCodes），请求未指定语言时追加语言指令；空表示不强制。
+	ResponseLanguage string
+
+	// AttributionMode 文本响应归属水印（见 AttributionMode* 常量）；空表示不添加。
+	AttributionMode string
+	// AttributionText footer 模式的页脚模板，空表示使用 DefaultAttributionFooterTemplate。
+	AttributionText string
+
+	// MaxRequestCost 单次请求预估费用上限（美元，按倍率折算后），0 表示不限制；与 Key 的上限同时设置时取较小值。
+	MaxRequestCost float64
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
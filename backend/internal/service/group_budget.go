@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+var (
+	ErrGroupBudgetNotFound         = infraerrors.NotFound("GROUP_BUDGET_NOT_FOUND", "group budget not found")
+	ErrGroupBudgetInvalidAmount    = infraerrors.BadRequest("GROUP_BUDGET_INVALID_AMOUNT", "monthly_budget_usd must be positive")
+	ErrGroupBudgetInvalidPercent   = infraerrors.BadRequest("GROUP_BUDGET_INVALID_PERCENT", "shift_percent must be between 1 and 100")
+	ErrGroupBudgetInvalidFallback  = infraerrors.BadRequest("GROUP_BUDGET_INVALID_FALLBACK", "fallback group must be a different group")
+	ErrGroupBudgetPlatformMismatch = infraerrors.BadRequest("GROUP_BUDGET_PLATFORM_MISMATCH", "fallback group must use the same platform")
+)
+
+// GroupBudget 分组月度上游成本预算配置。
+type GroupBudget struct {
+	GroupID          int64   `json:"group_id"`
+	Enabled          bool    `json:"enabled"`
+	MonthlyBudgetUSD float64 `json:"monthly_budget_usd"`
+	FallbackGroupID  int64   `json:"fallback_group_id"`
+	// ShiftPercent 预计超支时切到兜底分组的流量比例（1-100）
+	ShiftPercent int `json:"shift_percent"`
+	// AlertedPeriod 已告警的周期（YYYY-MM）
+	AlertedPeriod string    `json:"alerted_period,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	GroupName         string `json:"group_name,omitempty"`
+	FallbackGroupName string `json:"fallback_group_name,omitempty"`
+}
+
+// GroupBudgetStatus 预算配置与本周期成本/预测。
+type GroupBudgetStatus struct {
+	*GroupBudget
+	Period          string  `json:"period"`
+	MonthToDateCost float64 `json:"month_to_date_cost"`
+	ProjectedCost   float64 `json:"projected_cost"`
+	// Shifting 为 true 表示当前正在按 ShiftPercent 切流
+	Shifting bool `json:"shifting"`
+}
+
+// GroupBudgetRepository 分组预算持久化端口。
+type GroupBudgetRepository interface {
+	List(ctx context.Context) ([]*GroupBudget, error)
+	Upsert(ctx context.Context, budget *GroupBudget) (*GroupBudget, error)
+	Delete(ctx context.Context, groupID int64) error
+	// SumUpstreamCostSince 按分组汇总 since 之后的上游（账号）成本。
+	SumUpstreamCostSince(ctx context.Context, groupIDs []int64, since time.Time) (map[int64]float64, error)
+	// MarkAlerted 将分组标记为已在 period 告警；已标记过返回 false。
+	MarkAlerted(ctx context.Context, groupID int64, period string) (bool, error)
+}
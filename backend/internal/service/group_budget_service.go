@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/cespare/xxhash/v2"
+)
+
+// groupBudgetMinProjectionWindow 月初样本太少时按至少 1 天的已用时长外推，避免首小时的少量成本被放大成超支
+const groupBudgetMinProjectionWindow = 24 * time.Hour
+
+type groupBudgetShift struct {
+	fallbackGroupID int64
+	percent         int
+}
+
+// GroupBudgetService 分组月度上游成本预算。
+//
+// 周期性按自然月汇总分组的上游成本并线性外推到月末；预计超支时把 ShiftPercent 比例的调度
+// 切到兜底分组（同一会话始终落在同一侧），并写入一次运维告警。新的月份成本清零后自动恢复。
+// 切流只影响账号调度，用户侧仍按原分组计费。
+type GroupBudgetService struct {
+	repo      GroupBudgetRepository
+	groupRepo GroupRepository
+	opsRepo   OpsRepository
+	interval  time.Duration
+
+	shifts atomic.Pointer[map[int64]groupBudgetShift]
+	evalMu sync.Mutex
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+func NewGroupBudgetService(repo GroupBudgetRepository, groupRepo GroupRepository, opsRepo OpsRepository, interval time.Duration) *GroupBudgetService {
+	return &GroupBudgetService{
+		repo:      repo,
+		groupRepo: groupRepo,
+		opsRepo:   opsRepo,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+		now:       timezone.Now,
+	}
+}
+
+func (s *GroupBudgetService) Start() {
+	if s == nil || s.repo == nil || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *GroupBudgetService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *GroupBudgetService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := s.Evaluate(ctx); err != nil {
+		logger.LegacyPrintf("service.group_budget", "[GroupBudget] evaluate failed: %v", err)
+	}
+}
+
+// ShiftGroupID 返回本次调度应使用的分组：预算切流生效且请求落入切流比例时返回兜底分组，否则原样返回。
+// sessionHash 非空时按会话哈希分桶，保证同一会话的粘性调度不在两个分组间来回切换。
+func (s *GroupBudgetService) ShiftGroupID(groupID *int64, sessionHash string) *int64 {
+	if s == nil || groupID == nil {
+		return groupID
+	}
+	shifts := s.shifts.Load()
+	if shifts == nil {
+		return groupID
+	}
+	shift, ok := (*shifts)[*groupID]
+	if !ok || shift.fallbackGroupID <= 0 {
+		return groupID
+	}
+	var bucket int
+	if sessionHash != "" {
+		bucket = int(xxhash.Sum64String(sessionHash) % 100)
+	} else {
+		bucket = rand.IntN(100)
+	}
+	if bucket >= shift.percent {
+		return groupID
+	}
+	fallbackID := shift.fallbackGroupID
+	return &fallbackID
+}
+
+// Evaluate 重新计算所有预算的本月成本与预测，刷新切流状态并对新进入超支的分组告警。
+func (s *GroupBudgetService) Evaluate(ctx context.Context) ([]*GroupBudgetStatus, error) {
+	s.evalMu.Lock()
+	defer s.evalMu.Unlock()
+
+	budgets, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	periodStart := timezone.StartOfMonth(now)
+	periodEnd := periodStart.AddDate(0, 1, 0)
+	period := periodStart.Format("2006-01")
+
+	groupIDs := make([]int64, 0, len(budgets))
+	for _, b := range budgets {
+		groupIDs = append(groupIDs, b.GroupID)
+	}
+	costs := map[int64]float64{}
+	if len(groupIDs) > 0 {
+		if costs, err = s.repo.SumUpstreamCostSince(ctx, groupIDs, periodStart); err != nil {
+			return nil, err
+		}
+	}
+
+	shifts := make(map[int64]groupBudgetShift, len(budgets))
+	statuses := make([]*GroupBudgetStatus, 0, len(budgets))
+	for _, b := range budgets {
+		mtd := costs[b.GroupID]
+		status := &GroupBudgetStatus{
+			GroupBudget:     b,
+			Period:          period,
+			MonthToDateCost: mtd,
+			ProjectedCost:   projectGroupBudgetCost(mtd, periodStart, periodEnd, now),
+		}
+		status.Shifting = b.Enabled && status.ProjectedCost > b.MonthlyBudgetUSD
+		if status.Shifting {
+			shifts[b.GroupID] = groupBudgetShift{fallbackGroupID: b.FallbackGroupID, percent: b.ShiftPercent}
+			s.alertIfNeeded(ctx, status, now)
+		}
+		statuses = append(statuses, status)
+	}
+	s.shifts.Store(&shifts)
+	return statuses, nil
+}
+
+func (s *GroupBudgetService) alertIfNeeded(ctx context.Context, status *GroupBudgetStatus, now time.Time) {
+	if s.opsRepo == nil || status.AlertedPeriod == status.Period {
+		return
+	}
+	marked, err := s.repo.MarkAlerted(ctx, status.GroupID, status.Period)
+	if err != nil {
+		logger.LegacyPrintf("service.group_budget", "[GroupBudget] mark alerted failed: group_id=%d err=%v", status.GroupID, err)
+		return
+	}
+	if !marked {
+		return
+	}
+	status.AlertedPeriod = status.Period
+	if _, err := s.opsRepo.CreateAlertEvent(ctx, buildGroupBudgetAlertEvent(status, now)); err != nil {
+		logger.LegacyPrintf("service.group_budget", "[GroupBudget] create alert event failed: group_id=%d err=%v", status.GroupID, err)
+		return
+	}
+	logger.LegacyPrintf("service.group_budget", "[GroupBudget] group %d projected %.2f over budget %.2f, shifting %d%% to group %d",
+		status.GroupID, status.ProjectedCost, status.MonthlyBudgetUSD, status.ShiftPercent, status.FallbackGroupID)
+}
+
+func buildGroupBudgetAlertEvent(status *GroupBudgetStatus, now time.Time) *OpsAlertEvent {
+	name := strings.TrimSpace(status.GroupName)
+	if name == "" {
+		name = fmt.Sprintf("#%d", status.GroupID)
+	}
+	fallbackName := strings.TrimSpace(status.FallbackGroupName)
+	if fallbackName == "" {
+		fallbackName = fmt.Sprintf("#%d", status.FallbackGroupID)
+	}
+	projected := status.ProjectedCost
+	budget := status.MonthlyBudgetUSD
+	return &OpsAlertEvent{
+		Severity: "P1",
+		Status:   OpsAlertStatusFiring,
+		Title:    fmt.Sprintf("P1: Group budget projected to exceed: %s", name),
+		Description: fmt.Sprintf("group %s projected %.2f USD vs budget %.2f USD for %s (month-to-date %.2f); shifting %d%% of traffic to group %s",
+			name, projected, budget, status.Period, status.MonthToDateCost, status.ShiftPercent, fallbackName),
+		MetricValue:    &projected,
+		ThresholdValue: &budget,
+		Dimensions: map[string]any{
+			"kind":              "group_budget",
+			"group_id":          status.GroupID,
+			"fallback_group_id": status.FallbackGroupID,
+			"period":            status.Period,
+		},
+		FiredAt:   now,
+		CreatedAt: now,
+	}
+}
+
+// projectGroupBudgetCost 按本月已用时长线性外推月末成本。
+func projectGroupBudgetCost(monthToDate float64, periodStart, periodEnd, now time.Time) float64 {
+	if monthToDate <= 0 {
+		return 0
+	}
+	total := periodEnd.Sub(periodStart)
+	elapsed := now.Sub(periodStart)
+	if elapsed < groupBudgetMinProjectionWindow {
+		elapsed = groupBudgetMinProjectionWindow
+	}
+	if elapsed >= total {
+		return monthToDate
+	}
+	return monthToDate * float64(total) / float64(elapsed)
+}
+
+// List 返回所有预算及本周期的成本、预测与切流状态。
+func (s *GroupBudgetService) List(ctx context.Context) ([]*GroupBudgetStatus, error) {
+	return s.Evaluate(ctx)
+}
+
+// Upsert 创建或覆盖分组预算，并立即刷新切流状态。
+func (s *GroupBudgetService) Upsert(ctx context.Context, budget *GroupBudget) (*GroupBudget, error) {
+	if budget == nil || budget.GroupID <= 0 {
+		return nil, ErrGroupNotFound
+	}
+	if budget.MonthlyBudgetUSD <= 0 || math.IsNaN(budget.MonthlyBudgetUSD) || math.IsInf(budget.MonthlyBudgetUSD, 0) {
+		return nil, ErrGroupBudgetInvalidAmount
+	}
+	if budget.ShiftPercent < 1 || budget.ShiftPercent > 100 {
+		return nil, ErrGroupBudgetInvalidPercent
+	}
+	if budget.FallbackGroupID <= 0 || budget.FallbackGroupID == budget.GroupID {
+		return nil, ErrGroupBudgetInvalidFallback
+	}
+	group, err := s.groupRepo.GetByIDLite(ctx, budget.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	fallback, err := s.groupRepo.GetByIDLite(ctx, budget.FallbackGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.Platform != fallback.Platform {
+		return nil, ErrGroupBudgetPlatformMismatch
+	}
+
+	saved, err := s.repo.Upsert(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+	s.refresh(ctx)
+	return saved, nil
+}
+
+// Delete 删除分组预算并立即恢复该分组的正常调度。
+func (s *GroupBudgetService) Delete(ctx context.Context, groupID int64) error {
+	if err := s.repo.Delete(ctx, groupID); err != nil {
+		return err
+	}
+	s.refresh(ctx)
+	return nil
+}
+
+func (s *GroupBudgetService) refresh(ctx context.Context) {
+	if _, err := s.Evaluate(ctx); err != nil {
+		logger.LegacyPrintf("service.group_budget", "[GroupBudget] refresh after update failed: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type groupBudgetRepoStub struct {
+	GroupBudgetRepository
+	budgets []*GroupBudget
+	costs   map[int64]float64
+	since   time.Time
+	alerted map[int64]string
+}
+
+func (s *groupBudgetRepoStub) List(context.Context) ([]*GroupBudget, error) {
+	return s.budgets, nil
+}
+
+func (s *groupBudgetRepoStub) SumUpstreamCostSince(_ context.Context, _ []int64, since time.Time) (map[int64]float64, error) {
+	s.since = since
+	return s.costs, nil
+}
+
+func (s *groupBudgetRepoStub) MarkAlerted(_ context.Context, groupID int64, period string) (bool, error) {
+	if s.alerted == nil {
+		s.alerted = map[int64]string{}
+	}
+	if s.alerted[groupID] == period {
+		return false, nil
+	}
+	s.alerted[groupID] = period
+	return true, nil
+}
+
+type groupBudgetGroupRepoStub struct {
+	GroupRepository
+	groups map[int64]*Group
+}
+
+func (s *groupBudgetGroupRepoStub) GetByIDLite(_ context.Context, id int64) (*Group, error) {
+	if g, ok := s.groups[id]; ok {
+		return g, nil
+	}
+	return nil, ErrGroupNotFound
+}
+
+func TestProjectGroupBudgetCost(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	// 10 天用了 100，31 天预计 310
+	require.InDelta(t, 310.0, projectGroupBudgetCost(100, start, end, start.AddDate(0, 0, 10)), 1e-9)
+	// 月初首小时按 1 天外推，不被放大 744 倍
+	require.InDelta(t, 31.0, projectGroupBudgetCost(1, start, end, start.Add(time.Hour)), 1e-9)
+	require.Zero(t, projectGroupBudgetCost(0, start, end, start.AddDate(0, 0, 10)))
+}
+
+func TestGroupBudgetEvaluate_ShiftsAndAlertsOncePerPeriod(t *testing.T) {
+	repo := &groupBudgetRepoStub{
+		budgets: []*GroupBudget{
+			{GroupID: 1, Enabled: true, MonthlyBudgetUSD: 200, FallbackGroupID: 9, ShiftPercent: 100, GroupName: "premium"},
+			{GroupID: 2, Enabled: true, MonthlyBudgetUSD: 1000, FallbackGroupID: 9, ShiftPercent: 100},
+			{GroupID: 3, Enabled: false, MonthlyBudgetUSD: 10, FallbackGroupID: 9, ShiftPercent: 100},
+		},
+		costs: map[int64]float64{1: 100, 2: 100, 3: 100},
+	}
+	ops := &renewalAlertOpsRepoStub{}
+	svc := NewGroupBudgetService(repo, nil, ops, time.Minute)
+	svc.now = func() time.Time { return time.Date(2026, 10, 11, 0, 0, 0, 0, time.Local) }
+
+	statuses, err := svc.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	require.True(t, statuses[0].Shifting)
+	require.False(t, statuses[1].Shifting)
+	require.False(t, statuses[2].Shifting, "disabled budget must not shift")
+	require.Equal(t, "2026-10", statuses[0].Period)
+	require.Len(t, ops.events, 1)
+	require.Equal(t, "group_budget", ops.events[0].Dimensions["kind"])
+	require.Equal(t, int64(1), ops.events[0].Dimensions["group_id"])
+
+	shifted := svc.ShiftGroupID(int64Ptr(1), "session")
+	require.Equal(t, int64(9), *shifted)
+	require.Equal(t, int64(2), *svc.ShiftGroupID(int64Ptr(2), "session"))
+
+	// 同周期再次评估不重复告警
+	_, err = svc.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.Len(t, ops.events, 1)
+
+	// 新月份成本清零后恢复正常调度
+	svc.now = func() time.Time { return time.Date(2026, 11, 2, 0, 0, 0, 0, time.Local) }
+	repo.costs = map[int64]float64{}
+	_, err = svc.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), *svc.ShiftGroupID(int64Ptr(1), "session"))
+}
+
+func TestGroupBudgetShiftGroupID_PercentIsStickyPerSession(t *testing.T) {
+	svc := NewGroupBudgetService(nil, nil, nil, 0)
+	shifts := map[int64]groupBudgetShift{1: {fallbackGroupID: 9, percent: 30}}
+	svc.shifts.Store(&shifts)
+
+	require.Nil(t, svc.ShiftGroupID(nil, "s"))
+	var nilSvc *GroupBudgetService
+	require.Equal(t, int64(1), *nilSvc.ShiftGroupID(int64Ptr(1), "s"))
+
+	shiftedCount := 0
+	for i := 0; i < 1000; i++ {
+		session := "session-" + time.Duration(i).String()
+		first := *svc.ShiftGroupID(int64Ptr(1), session)
+		for j := 0; j < 3; j++ {
+			require.Equal(t, first, *svc.ShiftGroupID(int64Ptr(1), session))
+		}
+		if first == 9 {
+			shiftedCount++
+		}
+	}
+	require.InDelta(t, 300, shiftedCount, 80)
+}
+
+func TestGroupBudgetUpsert_Validation(t *testing.T) {
+	groups := &groupBudgetGroupRepoStub{groups: map[int64]*Group{
+		1: {ID: 1, Platform: PlatformAnthropic},
+		2: {ID: 2, Platform: PlatformOpenAI},
+		3: {ID: 3, Platform: PlatformAnthropic},
+	}}
+	svc := NewGroupBudgetService(&groupBudgetRepoStub{}, groups, nil, 0)
+	ctx := context.Background()
+
+	_, err := svc.Upsert(ctx, &GroupBudget{GroupID: 1, MonthlyBudgetUSD: 0, FallbackGroupID: 3, ShiftPercent: 50})
+	require.ErrorIs(t, err, ErrGroupBudgetInvalidAmount)
+	_, err = svc.Upsert(ctx, &GroupBudget{GroupID: 1, MonthlyBudgetUSD: 10, FallbackGroupID: 3, ShiftPercent: 101})
+	require.ErrorIs(t, err, ErrGroupBudgetInvalidPercent)
+	_, err = svc.Upsert(ctx, &GroupBudget{GroupID: 1, MonthlyBudgetUSD: 10, FallbackGroupID: 1, ShiftPercent: 50})
+	require.ErrorIs(t, err, ErrGroupBudgetInvalidFallback)
+	_, err = svc.Upsert(ctx, &GroupBudget{GroupID: 1, MonthlyBudgetUSD: 10, FallbackGroupID: 2, ShiftPercent: 50})
+	require.ErrorIs(t, err, ErrGroupBudgetPlatformMismatch)
+	_, err = svc.Upsert(ctx, &GroupBudget{GroupID: 1, MonthlyBudgetUSD: 10, FallbackGroupID: 404, ShiftPercent: 50})
+	require.ErrorIs(t, err, ErrGroupNotFound)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -82,6 +83,7 @@ type ImageTaskService struct {
 	resolve          ImageStorageResolver
 	ttl              time.Duration
 	executionTimeout time.Duration
+	notifier         UserNotifier
 }
 
 func NewImageTaskService(store ImageTaskStore) *ImageTaskService {
@@ -115,6 +117,11 @@ func NewImageTaskServiceWithResolver(store ImageTaskStore, resolve ImageStorageR
 	return s
 }
 
+// SetUserNotifier 注入站内通知：任务失败时通知任务所属用户。
+func (s *ImageTaskService) SetUserNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
 // current 返回当前生效的 uploader 与启用状态。
 // 注入了 resolver 时以 resolver 为准（后台设置可热切换），否则回落到构造时固定的值。
 func (s *ImageTaskService) current() (*ImageResultUploader, bool) {
@@ -232,9 +239,29 @@ func (s *ImageTaskService) finish(ctx context.Context, id, status string, status
 	if err := s.store.Save(ctx, task, s.ttl); err != nil {
 		return ErrImageTaskUnavailable.WithCause(err)
 	}
+	if status == ImageTaskStatusFailed && s.notifier != nil && task.UserID > 0 {
+		s.notifier.NotifyAsync(buildImageTaskFailedNotification(task))
+	}
 	return nil
 }
 
+func buildImageTaskFailedNotification(task *ImageTaskRecord) UserNotificationInput {
+	return UserNotificationInput{
+		UserID:  task.UserID,
+		Type:    UserNotificationTypeTaskFailed,
+		Level:   UserNotificationLevelError,
+		Title:   "Image generation task failed",
+		Content: fmt.Sprintf("Async image task %s failed (HTTP %d). Check the task result for details.", task.ID, task.HTTPStatus),
+		Metadata: map[string]any{
+			"task_id":     task.ID,
+			"task_kind":   "image_generation",
+			"api_key_id":  task.APIKeyID,
+			"http_status": task.HTTPStatus,
+		},
+		DedupKey: "image_task_failed:" + task.ID,
+	}
+}
+
 func imageTaskToPublic(task *ImageTaskRecord) *ImageTask {
 	if task == nil {
 		return nil
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/openai"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/xai"
+)
+
+// 别名匹配置信度：仅大小写 / 分隔符不同 > 仅快照后缀不同 > 仅词序不同 > 编辑距离相近。
+const (
+	modelAliasConfidenceSeparator = 1.0
+	modelAliasConfidenceSnapshot  = 0.95
+	modelAliasConfidenceReordered = 0.9
+	// modelAliasFuzzyWeight 编辑距离相似度的折算系数，保证模糊匹配的置信度始终低于结构性匹配
+	modelAliasFuzzyWeight = 0.85
+	// modelAliasFuzzyMinSimilarity 参与模糊匹配的最低相似度
+	modelAliasFuzzyMinSimilarity = 0.75
+)
+
+// modelSnapshotSuffixPattern 匹配模型名末尾的快照后缀：-20241022 / -2024-08-06 / -latest
+var modelSnapshotSuffixPattern = regexp.MustCompile(`-(\d{8}|\d{4}-\d{2}-\d{2}|latest)$`)
+
+// suggestModelAlias 在候选模型中挑选与被拒绝模型名最接近的一个，返回建议模型与置信度（0-1）。
+// 未找到相近候选时返回空字符串。
+func suggestModelAlias(requested string, candidates []string) (string, float64) {
+	reqNorm := normalizeModelAliasName(requested)
+	if reqNorm == "" {
+		return "", 0
+	}
+	reqBase := stripModelSnapshotSuffix(reqNorm)
+	reqTokens := sortedModelAliasTokens(reqBase)
+
+	best, bestScore := "", 0.0
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" || strings.Contains(candidate, "*") || strings.EqualFold(candidate, strings.TrimSpace(requested)) {
+			continue
+		}
+		candNorm := normalizeModelAliasName(candidate)
+		candBase := stripModelSnapshotSuffix(candNorm)
+
+		var score float64
+		switch {
+		case candNorm == reqNorm:
+			score = modelAliasConfidenceSeparator
+		case candBase == reqBase:
+			score = modelAliasConfidenceSnapshot
+		case sortedModelAliasTokens(candBase) == reqTokens:
+			score = modelAliasConfidenceReordered
+		default:
+			similarity := modelAliasSimilarity(reqBase, candBase)
+			if similarity < modelAliasFuzzyMinSimilarity {
+				continue
+			}
+			score = similarity * modelAliasFuzzyWeight
+		}
+		// 同分时取字典序更大的候选（通常是更新的快照）
+		if score > bestScore || (score == bestScore && candidate > best) {
+			best, bestScore = candidate, score
+		}
+	}
+	return best, bestScore
+}
+
+// normalizeModelAliasName 统一大小写与分隔符："Claude_3.5 Sonnet" → "claude-3-5-sonnet"。
+// 带供应商前缀的名称（anthropic/claude-...）只比较最后一段。
+func normalizeModelAliasName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	name = strings.NewReplacer(".", "-", "_", "-", " ", "-", ":", "-").Replace(name)
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool { return r == '-' }), "-")
+}
+
+func stripModelSnapshotSuffix(normalized string) string {
+	return modelSnapshotSuffixPattern.ReplaceAllString(normalized, "")
+}
+
+func sortedModelAliasTokens(normalized string) string {
+	tokens := strings.Split(normalized, "-")
+	sort.Strings(tokens)
+	return strings.Join(tokens, "-")
+}
+
+// modelAliasSimilarity 基于编辑距离的相似度（0-1）。
+func modelAliasSimilarity(a, b string) float64 {
+	maxLen := len(a)
+	if len(b) > maxLen {
+		maxLen = len(b)
+	}
+	if maxLen == 0 {
+		return 0
+	}
+	return 1 - float64(modelAliasEditDistance(a, b))/float64(maxLen)
+}
+
+func modelAliasEditDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// modelAliasCandidates 返回账号可服务的已知模型名：账号模型映射中的非通配键，加上平台默认模型列表。
+func modelAliasCandidates(account *Account) []string {
+	if account == nil {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	out := make([]string, 0, 32)
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.Contains(name, "*") {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	for requested := range account.GetModelMapping() {
+		add(requested)
+	}
+	switch account.Platform {
+	case domain.PlatformAnthropic:
+		for _, id := range claude.DefaultModelIDs() {
+			add(id)
+		}
+	case domain.PlatformOpenAI:
+		for _, id := range openai.DefaultModelIDs() {
+			add(id)
+		}
+	case domain.PlatformGemini:
+		for _, m := range geminicli.DefaultModels {
+			add(m.ID)
+		}
+	case domain.PlatformGrok:
+		for _, id := range xai.DefaultModelIDs() {
+			add(id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// 别名建议状态
+const (
+	ModelAliasStatusPending  = "pending"
+	ModelAliasStatusApproved = "approved"
+	ModelAliasStatusRejected = "rejected"
+)
+
+const (
+	// modelAliasMaxPendingObservations 两次落库之间最多缓存的不同模型名，防止异常流量撑爆内存
+	modelAliasMaxPendingObservations = 10000
+	// modelAliasMaxModelNameLength 超长模型名不记录（与表字段长度一致）
+	modelAliasMaxModelNameLength = 255
+	modelAliasFlushTimeout       = 30 * time.Second
+	modelAliasDefaultInterval    = time.Minute
+)
+
+var (
+	ErrModelAliasNotFound       = infraerrors.NotFound("MODEL_ALIAS_NOT_FOUND", "model alias suggestion not found")
+	ErrModelAliasTargetRequired = infraerrors.BadRequest("MODEL_ALIAS_TARGET_REQUIRED", "target model is required to approve a suggestion without a match")
+	ErrModelAliasTargetInvalid  = infraerrors.BadRequest("MODEL_ALIAS_TARGET_INVALID", "target model must differ from the requested model")
+)
+
+// ModelAliasSuggestion 上游拒绝的模型名及其别名建议。SuggestedModel 为空表示未知模型。
+type ModelAliasSuggestion struct {
+	ID             int64      `json:"id"`
+	Platform       string     `json:"platform"`
+	RequestedModel string     `json:"requested_model"`
+	SuggestedModel string     `json:"suggested_model"`
+	Confidence     float64    `json:"confidence"`
+	Status         string     `json:"status"`
+	HitCount       int64      `json:"hit_count"`
+	LastAccountID  *int64     `json:"last_account_id,omitempty"`
+	ReviewedBy     *int64     `json:"reviewed_by,omitempty"`
+	FirstSeenAt    time.Time  `json:"first_seen_at"`
+	LastSeenAt     time.Time  `json:"last_seen_at"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// ModelAliasObservation 两次落库之间聚合的一组 model not found 观测。
+type ModelAliasObservation struct {
+	Platform       string
+	RequestedModel string
+	SuggestedModel string
+	Confidence     float64
+	Hits           int64
+	AccountID      int64
+	SeenAt         time.Time
+}
+
+// ModelAliasSuggestionFilter 别名建议列表过滤条件。
+type ModelAliasSuggestionFilter struct {
+	Status   string
+	Platform string
+	// UnknownOnly 仅返回没有别名建议的未知模型
+	UnknownOnly bool
+	Page        int
+	PageSize    int
+}
+
+// ModelAliasSuggestionList 别名建议分页结果。
+type ModelAliasSuggestionList struct {
+	Items    []*ModelAliasSuggestion
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// ModelAliasRepository 别名建议持久化。
+type ModelAliasRepository interface {
+	// UpsertObservations 按 (platform, requested_model) 累加命中次数；
+	// 仍为 pending 的条目在新建议置信度不低于原建议时更新建议。
+	UpsertObservations(ctx context.Context, observations []ModelAliasObservation) error
+	List(ctx context.Context, filter *ModelAliasSuggestionFilter) (*ModelAliasSuggestionList, error)
+	GetByID(ctx context.Context, id int64) (*ModelAliasSuggestion, error)
+	// Review 设置审核状态；suggestedModel 非空时同时覆盖建议模型。
+	Review(ctx context.Context, id int64, status, suggestedModel string, reviewerID int64) (*ModelAliasSuggestion, error)
+	ListApproved(ctx context.Context) ([]*ModelAliasSuggestion, error)
+}
+
+// ModelAliasObserver 接收上游 model not found 观测（由 RateLimitService 调用）。
+type ModelAliasObserver interface {
+	ObserveModelNotFound(account *Account, requestedModel string)
+}
+
+// ModelAliasResolver 返回已批准的模型别名改写（由 ChannelService 在渠道映射之后调用）。
+type ModelAliasResolver interface {
+	ResolveModelAlias(ctx context.Context, model string) (string, bool)
+}
+
+type modelAliasKey struct {
+	platform string
+	model    string
+}
+
+func newModelAliasKey(platform, model string) modelAliasKey {
+	return modelAliasKey{platform: platform, model: strings.ToLower(strings.TrimSpace(model))}
+}
+
+// ModelAliasLearningService 模型别名学习。
+//
+// 上游以 model not found 拒绝请求时，记录被拒绝的模型名与账号已知模型中最接近的一个；
+// 观测先在内存聚合，按间隔批量落库。管理员批准后（且开启 auto_apply），
+// 后续请求在没有渠道映射时被改写为批准的目标模型。
+type ModelAliasLearningService struct {
+	repo     ModelAliasRepository
+	cfg      config.ModelAliasLearningConfig
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[modelAliasKey]*ModelAliasObservation
+
+	// approved (platform, 小写模型名) → 目标模型
+	approved atomic.Pointer[map[modelAliasKey]string]
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+// NewModelAliasLearningService 创建模型别名学习服务。
+func NewModelAliasLearningService(repo ModelAliasRepository, cfg *config.Config) *ModelAliasLearningService {
+	s := &ModelAliasLearningService{
+		repo:     repo,
+		interval: modelAliasDefaultInterval,
+		pending:  make(map[modelAliasKey]*ModelAliasObservation),
+		stopCh:   make(chan struct{}),
+		now:      time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.ModelAliasLearning
+		if cfg.ModelAliasLearning.FlushIntervalSeconds > 0 {
+			s.interval = time.Duration(cfg.ModelAliasLearning.FlushIntervalSeconds) * time.Second
+		}
+	}
+	return s
+}
+
+// ObserveModelNotFound 记录一次上游 model not found。只做内存聚合，可在请求热路径调用。
+func (s *ModelAliasLearningService) ObserveModelNotFound(account *Account, requestedModel string) {
+	if s == nil || !s.cfg.Enabled || account == nil {
+		return
+	}
+	requestedModel = strings.TrimSpace(requestedModel)
+	if requestedModel == "" || len(requestedModel) > modelAliasMaxModelNameLength {
+		return
+	}
+	key := newModelAliasKey(account.Platform, requestedModel)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if obs, ok := s.pending[key]; ok {
+		obs.Hits++
+		obs.AccountID = account.ID
+		obs.SeenAt = now
+		return
+	}
+	if len(s.pending) >= modelAliasMaxPendingObservations {
+		return
+	}
+	suggested, confidence := suggestModelAlias(requestedModel, modelAliasCandidates(account))
+	if confidence < s.cfg.MinConfidence {
+		suggested, confidence = "", 0
+	}
+	s.pending[key] = &ModelAliasObservation{
+		Platform:       account.Platform,
+		RequestedModel: requestedModel,
+		SuggestedModel: suggested,
+		Confidence:     confidence,
+		Hits:           1,
+		AccountID:      account.ID,
+		SeenAt:         now,
+	}
+}
+
+// ResolveModelAlias 返回请求模型在当前分组平台下已批准的别名目标；未开启 auto_apply 时不改写。
+func (s *ModelAliasLearningService) ResolveModelAlias(ctx context.Context, model string) (string, bool) {
+	if s == nil || !s.cfg.AutoApply || ctx == nil {
+		return "", false
+	}
+	approved := s.approved.Load()
+	if approved == nil || len(*approved) == 0 {
+		return "", false
+	}
+	group, ok := ctx.Value(ctxkey.Group).(*Group)
+	if !ok || group == nil {
+		return "", false
+	}
+	target, ok := (*approved)[newModelAliasKey(group.Platform, model)]
+	return target, ok && target != ""
+}
+
+// List 分页查询别名建议与未知模型。
+func (s *ModelAliasLearningService) List(ctx context.Context, filter *ModelAliasSuggestionFilter) (*ModelAliasSuggestionList, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Approve 批准别名建议；targetModel 非空时覆盖自动建议的目标模型（未知模型必须指定）。
+func (s *ModelAliasLearningService) Approve(ctx context.Context, id int64, targetModel string, reviewerID int64) (*ModelAliasSuggestion, error) {
+	item, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	target := strings.TrimSpace(targetModel)
+	if target == "" {
+		target = item.SuggestedModel
+	}
+	if target == "" {
+		return nil, ErrModelAliasTargetRequired
+	}
+	if strings.EqualFold(target, item.RequestedModel) || len(target) > modelAliasMaxModelNameLength {
+		return nil, ErrModelAliasTargetInvalid
+	}
+	reviewed, err := s.repo.Review(ctx, id, ModelAliasStatusApproved, target, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	s.refreshApproved(ctx)
+	return reviewed, nil
+}
+
+// Reject 拒绝（或撤销已批准的）别名建议。
+func (s *ModelAliasLearningService) Reject(ctx context.Context, id int64, reviewerID int64) (*ModelAliasSuggestion, error) {
+	reviewed, err := s.repo.Review(ctx, id, ModelAliasStatusRejected, "", reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	s.refreshApproved(ctx)
+	return reviewed, nil
+}
+
+func (s *ModelAliasLearningService) Start() {
+	if s == nil || s.repo == nil || (!s.cfg.Enabled && !s.cfg.AutoApply) {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				s.flush()
+				return
+			}
+		}
+	}()
+}
+
+func (s *ModelAliasLearningService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *ModelAliasLearningService) runOnce() {
+	s.flush()
+	if s.cfg.AutoApply {
+		ctx, cancel := context.WithTimeout(context.Background(), modelAliasFlushTimeout)
+		defer cancel()
+		s.refreshApproved(ctx)
+	}
+}
+
+// flush 将内存中聚合的观测写入数据库；失败时丢弃本批次（观测只用于统计与建议）。
+func (s *ModelAliasLearningService) flush() {
+	s.mu.Lock()
+	if len(s.pending) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	batch := make([]ModelAliasObservation, 0, len(s.pending))
+	for _, obs := range s.pending {
+		batch = append(batch, *obs)
+	}
+	s.pending = make(map[modelAliasKey]*ModelAliasObservation)
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), modelAliasFlushTimeout)
+	defer cancel()
+	if err := s.repo.UpsertObservations(ctx, batch); err != nil {
+		logger.LegacyPrintf("service.model_alias", "[ModelAlias] flush %d observations failed: %v", len(batch), err)
+	}
+}
+
+func (s *ModelAliasLearningService) refreshApproved(ctx context.Context) {
+	items, err := s.repo.ListApproved(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.model_alias", "[ModelAlias] load approved aliases failed: %v", err)
+		return
+	}
+	approved := make(map[modelAliasKey]string, len(items))
+	for _, item := range items {
+		if item.SuggestedModel == "" {
+			continue
+		}
+		approved[newModelAliasKey(item.Platform, item.RequestedModel)] = item.SuggestedModel
+	}
+	s.approved.Store(&approved)
+}
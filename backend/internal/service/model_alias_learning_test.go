@@ -0,0 +1,207 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+type modelAliasRepoStub struct {
+	upserted []ModelAliasObservation
+	items    map[int64]*ModelAliasSuggestion
+}
+
+func (s *modelAliasRepoStub) UpsertObservations(_ context.Context, observations []ModelAliasObservation) error {
+	s.upserted = append(s.upserted, observations...)
+	return nil
+}
+
+func (s *modelAliasRepoStub) List(context.Context, *ModelAliasSuggestionFilter) (*ModelAliasSuggestionList, error) {
+	return &ModelAliasSuggestionList{}, nil
+}
+
+func (s *modelAliasRepoStub) GetByID(_ context.Context, id int64) (*ModelAliasSuggestion, error) {
+	item, ok := s.items[id]
+	if !ok {
+		return nil, ErrModelAliasNotFound
+	}
+	cp := *item
+	return &cp, nil
+}
+
+func (s *modelAliasRepoStub) Review(_ context.Context, id int64, status, suggestedModel string, reviewerID int64) (*ModelAliasSuggestion, error) {
+	item, ok := s.items[id]
+	if !ok {
+		return nil, ErrModelAliasNotFound
+	}
+	item.Status = status
+	if suggestedModel != "" {
+		item.SuggestedModel = suggestedModel
+	}
+	item.ReviewedBy = &reviewerID
+	cp := *item
+	return &cp, nil
+}
+
+func (s *modelAliasRepoStub) ListApproved(context.Context) ([]*ModelAliasSuggestion, error) {
+	out := make([]*ModelAliasSuggestion, 0)
+	for _, item := range s.items {
+		if item.Status == ModelAliasStatusApproved {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func newTestModelAliasService(repo ModelAliasRepository, autoApply bool) *ModelAliasLearningService {
+	return NewModelAliasLearningService(repo, &config.Config{ModelAliasLearning: config.ModelAliasLearningConfig{
+		Enabled:              true,
+		AutoApply:            autoApply,
+		MinConfidence:        0.8,
+		FlushIntervalSeconds: 60,
+	}})
+}
+
+func TestSuggestModelAlias(t *testing.T) {
+	candidates := []string{"claude-3-5-sonnet-20241022", "claude-3-5-haiku-20241022", "claude-sonnet-4-6", "gpt-image-1"}
+
+	tests := []struct {
+		name          string
+		requested     string
+		wantModel     string
+		minConfidence float64
+	}{
+		{name: "dot separator and missing snapshot", requested: "claude-3.5-sonnet", wantModel: "claude-3-5-sonnet-20241022", minConfidence: modelAliasConfidenceSnapshot},
+		{name: "case and separators only", requested: "Claude_Sonnet.4.6", wantModel: "claude-sonnet-4-6", minConfidence: modelAliasConfidenceSeparator},
+		{name: "provider prefix", requested: "anthropic/claude-sonnet-4-6", wantModel: "claude-sonnet-4-6", minConfidence: modelAliasConfidenceSeparator},
+		{name: "reordered tokens", requested: "claude-sonnet-3-5-latest", wantModel: "claude-3-5-sonnet-20241022", minConfidence: modelAliasConfidenceReordered},
+		{name: "typo", requested: "claude-sonet-4-6", wantModel: "claude-sonnet-4-6", minConfidence: 0.75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, confidence := suggestModelAlias(tt.requested, candidates)
+			require.Equal(t, tt.wantModel, model)
+			require.GreaterOrEqual(t, confidence, tt.minConfidence)
+		})
+	}
+
+	model, confidence := suggestModelAlias("totally-unknown-model", candidates)
+	require.Empty(t, model)
+	require.Zero(t, confidence)
+
+	// 被拒绝的模型名本身不能作为建议
+	model, _ = suggestModelAlias("claude-sonnet-4-6", []string{"claude-sonnet-4-6"})
+	require.Empty(t, model)
+}
+
+func TestModelAliasLearningService_ObserveAggregatesAndFlushes(t *testing.T) {
+	repo := &modelAliasRepoStub{}
+	svc := newTestModelAliasService(repo, false)
+	account := &Account{ID: 7, Platform: PlatformAnthropic}
+
+	svc.ObserveModelNotFound(account, "claude-sonnet.4.6")
+	svc.ObserveModelNotFound(account, "CLAUDE-SONNET.4.6")
+	svc.ObserveModelNotFound(account, "no-such-model-xyz")
+	svc.flush()
+
+	require.Len(t, repo.upserted, 2)
+	byModel := map[string]ModelAliasObservation{}
+	for _, obs := range repo.upserted {
+		byModel[obs.RequestedModel] = obs
+	}
+	known := byModel["claude-sonnet.4.6"]
+	require.Equal(t, int64(2), known.Hits)
+	require.Equal(t, "claude-sonnet-4-6", known.SuggestedModel)
+	require.Equal(t, int64(7), known.AccountID)
+
+	unknown := byModel["no-such-model-xyz"]
+	require.Empty(t, unknown.SuggestedModel)
+	require.Zero(t, unknown.Confidence)
+
+	svc.flush()
+	require.Len(t, repo.upserted, 2, "flush must drain pending observations")
+}
+
+func TestModelAliasLearningService_ObserveDisabled(t *testing.T) {
+	repo := &modelAliasRepoStub{}
+	svc := NewModelAliasLearningService(repo, &config.Config{})
+	svc.ObserveModelNotFound(&Account{ID: 1, Platform: PlatformAnthropic}, "claude-3.5-sonnet")
+	svc.flush()
+	require.Empty(t, repo.upserted)
+}
+
+func TestModelAliasLearningService_ApproveAppliesRewrite(t *testing.T) {
+	repo := &modelAliasRepoStub{items: map[int64]*ModelAliasSuggestion{
+		1: {ID: 1, Platform: PlatformAnthropic, RequestedModel: "claude-3.5-sonnet", SuggestedModel: "claude-3-5-sonnet-20241022", Status: ModelAliasStatusPending},
+		2: {ID: 2, Platform: PlatformAnthropic, RequestedModel: "mystery-model", Status: ModelAliasStatusPending},
+	}}
+	svc := newTestModelAliasService(repo, true)
+	ctx := context.WithValue(context.Background(), ctxkey.Group, &Group{ID: 3, Platform: PlatformAnthropic})
+
+	_, ok := svc.ResolveModelAlias(ctx, "claude-3.5-sonnet")
+	require.False(t, ok, "pending suggestions must not rewrite requests")
+
+	item, err := svc.Approve(context.Background(), 1, "", 9)
+	require.NoError(t, err)
+	require.Equal(t, ModelAliasStatusApproved, item.Status)
+
+	target, ok := svc.ResolveModelAlias(ctx, "Claude-3.5-Sonnet")
+	require.True(t, ok)
+	require.Equal(t, "claude-3-5-sonnet-20241022", target)
+
+	otherPlatform := context.WithValue(context.Background(), ctxkey.Group, &Group{ID: 4, Platform: PlatformOpenAI})
+	_, ok = svc.ResolveModelAlias(otherPlatform, "claude-3.5-sonnet")
+	require.False(t, ok, "aliases are scoped to the platform that rejected the model")
+
+	_, err = svc.Approve(context.Background(), 2, "", 9)
+	require.ErrorIs(t, err, ErrModelAliasTargetRequired)
+	_, err = svc.Approve(context.Background(), 2, "Mystery-Model", 9)
+	require.ErrorIs(t, err, ErrModelAliasTargetInvalid)
+
+	_, err = svc.Reject(context.Background(), 1, 9)
+	require.NoError(t, err)
+	_, ok = svc.ResolveModelAlias(ctx, "claude-3.5-sonnet")
+	require.False(t, ok, "rejecting an approved alias revokes the rewrite")
+}
+
+func TestModelAliasLearningService_ApprovedAliasIgnoredWithoutAutoApply(t *testing.T) {
+	repo := &modelAliasRepoStub{items: map[int64]*ModelAliasSuggestion{
+		1: {ID: 1, Platform: PlatformAnthropic, RequestedModel: "claude-3.5-sonnet", SuggestedModel: "claude-3-5-sonnet-20241022", Status: ModelAliasStatusPending},
+	}}
+	svc := newTestModelAliasService(repo, false)
+	_, err := svc.Approve(context.Background(), 1, "", 9)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), ctxkey.Group, &Group{ID: 3, Platform: PlatformAnthropic})
+	_, ok := svc.ResolveModelAlias(ctx, "claude-3.5-sonnet")
+	require.False(t, ok)
+}
+
+func TestChannelService_ResolveChannelMappingFallsBackToModelAlias(t *testing.T) {
+	repo := &modelAliasRepoStub{items: map[int64]*ModelAliasSuggestion{
+		1: {ID: 1, Platform: PlatformAnthropic, RequestedModel: "claude-3.5-sonnet", SuggestedModel: "claude-3-5-sonnet-20241022", Status: ModelAliasStatusApproved},
+	}}
+	aliases := newTestModelAliasService(repo, true)
+	aliases.refreshApproved(context.Background())
+
+	channelService := NewChannelService(&mockChannelRepository{
+		listAllFn: func(context.Context) ([]Channel, error) { return nil, nil },
+	}, nil, nil, nil)
+	channelService.SetModelAliasResolver(aliases)
+
+	groupID := int64(3)
+	ctx := context.WithValue(context.Background(), ctxkey.Group, &Group{ID: groupID, Platform: PlatformAnthropic})
+	result, _ := channelService.ResolveChannelMappingAndRestrict(ctx, &groupID, "claude-3.5-sonnet")
+	require.True(t, result.Mapped)
+	require.Equal(t, "claude-3-5-sonnet-20241022", result.MappedModel)
+	require.Equal(t, "claude-3.5-sonnet→claude-3-5-sonnet-20241022", result.BuildModelMappingChain("claude-3.5-sonnet", ""))
+
+	result, _ = channelService.ResolveChannelMappingAndRestrict(ctx, &groupID, "claude-sonnet-4-6")
+	require.False(t, result.Mapped)
+	require.Equal(t, "claude-sonnet-4-6", result.MappedModel)
+}
@@ -27,6 +27,7 @@ const (
 	NotificationEmailEventSubscriptionExpiryReminder  = "subscription.expiry_reminder"
 	NotificationEmailEventBalanceLow                  = "balance.low"
 	NotificationEmailEventBalanceRechargeSuccess      = "balance.recharge_success"
+	NotificationEmailEventAPIKeyProvisioned           = "api_key.provisioned"
 	NotificationEmailEventAccountQuotaAlert           = "account.quota_alert"
 	NotificationEmailEventContentModerationViolation  = "content_moderation.violation_notice"
 	NotificationEmailEventContentModerationDisabled   = "content_moderation.account_disabled"
@@ -913,6 +914,8 @@ func notificationEmailSampleVariables(locale string) map[string]string {
 			"recharge_url":        "https://example.com/recharge",
 			"recharge_amount":     "50.00",
 			"order_id":            "1024",
+			"api_key_name":        "生产环境",
+			"api_key":             "sk-preview-0123456789abcdef",
 			"unsubscribe_url":     "https://example.com/unsubscribe",
 			"account_id":          "1001",
 			"account_name":        "openai-main",
@@ -961,6 +964,8 @@ func notificationEmailSampleVariables(locale string) map[string]string {
 		"recharge_url":        "https://example.com/recharge",
 		"recharge_amount":     "50.00",
 		"order_id":            "1024",
+		"api_key_name":        "Production",
+		"api_key":             "sk-preview-0123456789abcdef",
 		"unsubscribe_url":     "https://example.com/unsubscribe",
 		"account_id":          "1001",
 		"account_name":        "openai-main",
@@ -1028,6 +1033,7 @@ var notificationEmailEventOrder = []string{
 	NotificationEmailEventSubscriptionExpiryReminder,
 	NotificationEmailEventBalanceLow,
 	NotificationEmailEventBalanceRechargeSuccess,
+	NotificationEmailEventAPIKeyProvisioned,
 	NotificationEmailEventAccountQuotaAlert,
 	NotificationEmailEventContentModerationViolation,
 	NotificationEmailEventContentModerationDisabled,
@@ -1093,6 +1099,14 @@ var notificationEmailEventDefinitions = map[string]NotificationEmailEventInfo{
 		Optional:     false,
 		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...), "recharge_amount", "current_balance", "order_id"),
 	},
+	NotificationEmailEventAPIKeyProvisioned: {
+		Event:        NotificationEmailEventAPIKeyProvisioned,
+		Label:        "API key provisioned",
+		Description:  "Sent when an administrator bulk-imports API keys with email delivery enabled.",
+		Category:     "api_key",
+		Optional:     false,
+		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...), "api_key_name", "api_key", "expiry_time"),
+	},
 	NotificationEmailEventAccountQuotaAlert: {
 		Event:       NotificationEmailEventAccountQuotaAlert,
 		Label:       "Account quota alert",
@@ -1292,6 +1306,26 @@ var notificationEmailOfficialTemplates = map[string]map[string]notificationEmail
 			<p>订单号：{{order_id}}</p>`),
 		},
 	},
+	NotificationEmailEventAPIKeyProvisioned: {
+		notificationEmailDefaultLocale: {
+			Subject: "[{{site_name}}] Your new API key",
+			HTML: notificationEmailCard("#0891b2", "API key created", `
+<p>Hello {{recipient_name}},</p>
+<p>An administrator has created the API key <strong>{{api_key_name}}</strong> for your account.</p>
+<p style="font-family: monospace; font-size: 15px; word-break: break-all; text-align: center;">{{api_key}}</p>
+<p>Expiry time: <strong>{{expiry_time}}</strong></p>
+<p>Keep this key secret and delete this email after storing it somewhere safe.</p>`),
+		},
+		notificationEmailLocaleChinese: {
+			Subject: "[{{site_name}}] 您的新 API Key",
+			HTML: notificationEmailCard("#0891b2", "API Key 已创建", `
+<p>{{recipient_name}}，您好：</p>
+<p>管理员已为您的账号创建 API Key <strong>{{api_key_name}}</strong>。</p>
+<p style="font-family: monospace; font-size: 15px; word-break: break-all; text-align: center;">{{api_key}}</p>
+<p>到期时间：<strong>{{expiry_time}}</strong></p>
+<p>请妥善保管该密钥，保存后建议删除本邮件。</p>`),
+		},
+	},
 	NotificationEmailEventAccountQuotaAlert: {
 		notificationEmailDefaultLocale: {
 			Subject: "[{{site_name}}] Account quota alert - {{account_name}}",
@@ -482,7 +482,7 @@ func (s *defaultOpenAIAccountScheduler) selectBySessionHash(
 		_ = s.service.deleteStickySessionAccountID(ctx, req.GroupID, sessionHash)
 		return nil, false, nil
 	}
-	if shouldClearStickySession(account, req.RequestedModel) || account.Platform != normalizeOpenAICompatiblePlatform(req.Platform) || !account.IsOpenAICompatible() || !account.IsSchedulable() {
+	if s.service.shouldClearStickySession(ctx, account, req.RequestedModel) || account.Platform != normalizeOpenAICompatiblePlatform(req.Platform) || !account.IsOpenAICompatible() || !account.IsSchedulable() {
 		_ = s.service.deleteStickySessionAccountID(ctx, req.GroupID, sessionHash)
 		return nil, false, nil
 	}
@@ -1279,6 +1279,7 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 	if err != nil {
 		return nil, 0, 0, 0, err
 	}
+	accounts = s.service.accountWarmup.applyRamp(s.service.contentPolicy.filter(ctx, accounts), 0)
 	if len(accounts) == 0 {
 		return nil, 0, 0, 0, noAvailableOpenAISelectionError(req.RequestedModel, false)
 	}
@@ -1687,7 +1688,16 @@ func (s *OpenAIGatewayService) openAIAdvancedSchedulerSettingRepo() SettingRepos
 	return s.rateLimitService.settingService.settingRepo
 }
 
+// openAIAdvancedSchedulerRuntimeSettings 返回本请求生效的调度设置：灰度分组请求可覆盖高级调度总开关。
 func (s *OpenAIGatewayService) openAIAdvancedSchedulerRuntimeSettings(ctx context.Context) openAIAdvancedSchedulerRuntimeSettings {
+	settings := s.openAIAdvancedSchedulerStableSettings(ctx)
+	if candidate := gatewayCanaryCandidate(ctx, s.openAIAdvancedSchedulerSettingRepo()); candidate != nil && candidate.OpenAIAdvancedSchedulerEnabled != nil {
+		settings.enabled = *candidate.OpenAIAdvancedSchedulerEnabled
+	}
+	return settings
+}
+
+func (s *OpenAIGatewayService) openAIAdvancedSchedulerStableSettings(ctx context.Context) openAIAdvancedSchedulerRuntimeSettings {
 	if cached, ok := openAIAdvancedSchedulerSettingCache.Load().(*cachedOpenAIAdvancedSchedulerSetting); ok && cached != nil {
 		if time.Now().UnixNano() < cached.expiresAt {
 			return openAIAdvancedSchedulerRuntimeSettings{
@@ -1975,6 +1985,13 @@ func (s *OpenAIGatewayService) selectAccountWithScheduler(
 	ctx = s.withOpenAIQuotaAutoPauseContext(ctx)
 	platform = normalizeOpenAICompatiblePlatform(platform)
 	decision := OpenAIAccountScheduleDecision{}
+	if pinnedID := PinnedAccountIDFromContext(ctx); pinnedID > 0 {
+		selection, err := selectPinnedAccount(ctx, s, s.accountRepo, pinnedID, excludedIDs, func(account *Account) bool {
+			return account.Platform == platform
+		})
+		return selection, decision, err
+	}
+	groupID = s.groupBudget.ShiftGroupID(groupID, sessionHash)
 	scheduler := s.getOpenAIAccountScheduler(ctx)
 	if scheduler == nil {
 		decision.Layer = openAIAccountScheduleLayerLoadBalance
@@ -32,14 +32,15 @@ import (
 // 调用方，属于有意保留的行为差异，不在此强行统一。
 
 // newUpstreamSSEScanner 构造读取上游 SSE 流的行扫描器，按配置放大单行上限。
-func (s *OpenAIGatewayService) newUpstreamSSEScanner(r io.Reader) *bufio.Scanner {
-	scanner := bufio.NewScanner(r)
+// 初始缓冲取自池，扫描结束后须调用返回的 release 归还。
+func (s *OpenAIGatewayService) newUpstreamSSEScanner(r io.Reader) (*bufio.Scanner, func()) {
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
-	return scanner
+	scanner, release := newPooledSSEScanner(r, maxLineSize)
+	applySSEOversizeLinePolicy(scanner, s.cfg, maxLineSize)
+	return scanner, release
 }
 
 // newStreamHeaderWriter 返回幂等的 SSE 响应头写入闭包：首次调用时透传过滤后的
@@ -246,7 +247,8 @@ func (s *OpenAIGatewayService) scanCCStream(
 ) ccStreamScanState {
 	var st ccStreamScanState
 
-	scanner := s.newUpstreamSSEScanner(resp.Body)
+	scanner, releaseScanBuf := s.newUpstreamSSEScanner(resp.Body)
+	defer releaseScanBuf()
 	for scanner.Scan() {
 		line := scanner.Text()
 		payload, ok := extractOpenAISSEDataLine(line)
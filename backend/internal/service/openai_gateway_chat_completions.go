@@ -518,7 +518,9 @@ func (s *OpenAIGatewayService) handleChatStreamingResponse(
 	var streamFailoverErr *UpstreamFailoverError
 	var streamNonFailoverErr error
 
-	scanner := s.newUpstreamSSEScanner(resp.Body)
+	restoreWriter := guardStreamClient(c, s.cfg, resp)
+	defer restoreWriter()
+	scanner, releaseScanBuf := s.newUpstreamSSEScanner(resp.Body)
 
 	streamInterval := time.Duration(0)
 	if s.cfg != nil && s.cfg.Gateway.StreamDataIntervalTimeout > 0 {
@@ -806,6 +808,7 @@ func (s *OpenAIGatewayService) handleChatStreamingResponse(
 
 	// No keepalive: fast synchronous path
 	if streamInterval <= 0 && keepaliveInterval <= 0 {
+		defer releaseScanBuf()
 		var parser openAICompatSSEFrameParser
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -856,6 +859,7 @@ func (s *OpenAIGatewayService) handleChatStreamingResponse(
 		}
 	}
 	go func() {
+		defer releaseScanBuf()
 		defer close(events)
 		for scanner.Scan() {
 			atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
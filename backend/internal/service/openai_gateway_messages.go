@@ -647,7 +647,7 @@ func (s *OpenAIGatewayService) readOpenAICompatBufferedTerminal(
 		return nil, usage, acc, errors.New("upstream response body is nil")
 	}
 
-	scanner := s.newUpstreamSSEScanner(resp.Body)
+	scanner, releaseScanBuf := s.newUpstreamSSEScanner(resp.Body)
 
 	streamInterval := time.Duration(0)
 	if s.cfg != nil && s.cfg.Gateway.StreamDataIntervalTimeout > 0 {
@@ -693,6 +693,7 @@ func (s *OpenAIGatewayService) readOpenAICompatBufferedTerminal(
 	events := make(chan scanEvent, 16)
 	done := make(chan struct{})
 	go func() {
+		defer releaseScanBuf()
 		defer close(events)
 		for scanner.Scan() {
 			select {
@@ -819,7 +820,9 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 	var streamFailoverErr error
 	var streamNonFailoverErr error
 
-	scanner := s.newUpstreamSSEScanner(resp.Body)
+	restoreWriter := guardStreamClient(c, s.cfg, resp)
+	defer restoreWriter()
+	scanner, releaseScanBuf := s.newUpstreamSSEScanner(resp.Body)
 
 	streamInterval := time.Duration(0)
 	if s.cfg != nil && s.cfg.Gateway.StreamDataIntervalTimeout > 0 {
@@ -1040,6 +1043,7 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 
 	// ── No keepalive: fast synchronous path (no goroutine overhead) ──
 	if streamInterval <= 0 && keepaliveInterval <= 0 {
+		defer releaseScanBuf()
 		var parser openAICompatSSEFrameParser
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -1087,6 +1091,7 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 		}
 	}
 	go func() {
+		defer releaseScanBuf()
 		defer close(events)
 		for scanner.Scan() {
 			atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
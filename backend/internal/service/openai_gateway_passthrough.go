@@ -979,6 +979,8 @@ func (s *OpenAIGatewayService) handleStreamingResponsePassthrough(
 		c.Header("x-request-id", v)
 	}
 
+	restoreWriter := guardStreamClient(c, s.cfg, resp)
+	defer restoreWriter()
 	w := c.Writer
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -1020,6 +1022,7 @@ func (s *OpenAIGatewayService) handleStreamingResponsePassthrough(
 		return true
 	}
 
+	limitUpstreamStreamBody(c, s.cfg, resp)
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
@@ -1027,6 +1030,7 @@ func (s *OpenAIGatewayService) handleStreamingResponsePassthrough(
 	}
 	scanBuf := getSSEScannerBuf64K()
 	scanner.Buffer(scanBuf[:0], maxLineSize)
+	applySSEOversizeLinePolicy(scanner, s.cfg, maxLineSize)
 	defer putSSEScannerBuf64K(scanBuf)
 	documentScanner := newOpenAISSEJSONDocumentScanner(scanner)
 
@@ -1175,6 +1179,9 @@ func (s *OpenAIGatewayService) handleStreamingResponsePassthrough(
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return resultWithUsage(), fmt.Errorf("stream usage incomplete: %w", err)
 		}
+		if errors.Is(err, ErrUpstreamStreamTooLarge) {
+			return resultWithUsage(), err
+		}
 		if errors.Is(err, bufio.ErrTooLong) {
 			logger.LegacyPrintf("service.openai_gateway", "[OpenAI passthrough] SSE line too long: account=%d max_size=%d error=%v", account.ID, maxLineSize, err)
 			return resultWithUsage(), err
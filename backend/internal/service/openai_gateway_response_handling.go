@@ -97,7 +97,10 @@ func (s *OpenAIGatewayService) handleStreamingResponseWithReasoning(ctx context.
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
 	var firstTokenMs *int
-	bufferedWriter := bufio.NewWriterSize(w, 4*1024)
+	// 慢客户端保护：单次写超时 + 上游积压预算；bufio 溢出写入与提交暂存帧均经 guardedWriter
+	streamGuard := newStreamClientGuard(c, s.cfg)
+	guardedWriter := streamGuard.wrapWriter(w)
+	bufferedWriter := bufio.NewWriterSize(guardedWriter, 4*1024)
 	var firstOutputStage *openAIFirstOutputStage
 	if guardFirstOutput {
 		firstOutputStage = newDefaultOpenAIFirstOutputStage()
@@ -121,7 +124,7 @@ func (s *OpenAIGatewayService) handleStreamingResponseWithReasoning(ctx context.
 	}
 	flushBuffered := func() error {
 		if firstOutputStage != nil && firstTokenMs == nil && !firstOutputStage.closed {
-			if err := firstOutputStage.CommitTo(w); err != nil {
+			if err := firstOutputStage.CommitTo(guardedWriter); err != nil {
 				return err
 			}
 		} else {
@@ -129,8 +132,7 @@ func (s *OpenAIGatewayService) handleStreamingResponseWithReasoning(ctx context.
 				return err
 			}
 		}
-		flusher.Flush()
-		return nil
+		return streamGuard.flush(flusher)
 	}
 
 	usage := &OpenAIUsage{}
@@ -138,11 +140,15 @@ func (s *OpenAIGatewayService) handleStreamingResponseWithReasoning(ctx context.
 	responseID := ""
 	var firstOutputScanGuard atomic.Bool
 	firstOutputScanGuard.Store(guardFirstOutput)
+	limitUpstreamStreamBody(c, s.cfg, resp)
+	streamGuard.watchUpstream(resp)
 	scanner := bufio.NewScanner(resp.Body)
 	scanBuf := getSSEScannerBuf64K()
 	scanner.Buffer(scanBuf[:0], maxLineSize)
 	if guardFirstOutput {
 		scanner.Split(openAIFirstOutputDynamicScanLines(&firstOutputScanGuard))
+	} else {
+		applySSEOversizeLinePolicy(scanner, s.cfg, maxLineSize)
 	}
 	documentScanner := newOpenAISSEJSONDocumentScanner(scanner)
 
@@ -370,6 +376,11 @@ func (s *OpenAIGatewayService) handleStreamingResponseWithReasoning(ctx context.
 			}
 			return resultWithUsage(), fmt.Errorf("stream usage incomplete: %w", scanErr), true
 		}
+		// 累计字节超出预算属于主动中止，不走 failover
+		if errors.Is(scanErr, ErrUpstreamStreamTooLarge) {
+			sendErrorEvent("response_too_large")
+			return resultWithUsage(), scanErr, true
+		}
 		if errors.Is(scanErr, bufio.ErrTooLong) {
 			logger.LegacyPrintf("service.openai_gateway", "SSE line too long: account=%d max_size=%d error=%v", account.ID, maxLineSize, scanErr)
 			sendErrorEvent("response_too_large")
@@ -390,6 +401,7 @@ func (s *OpenAIGatewayService) handleStreamingResponseWithReasoning(ctx context.
 		return resultWithUsage(), fmt.Errorf("stream read error: %w", scanErr), true
 	}
 	processSSELine := func(line string, queueDrained bool) {
+		streamGuard.consume(len(line) + 1)
 		if streamEarlyErr != nil {
 			return
 		}
@@ -704,12 +716,16 @@ func (s *OpenAIGatewayService) handleStreamingResponseWithReasoning(ctx context.
 			if guardFirstOutput {
 				// Bypass attempt-local buffered frames. The stable SSE headers may be
 				// committed here, but account headers remain private until semantic output.
-				if _, err := w.Write([]byte(":\n\n")); err != nil {
+				if _, err := guardedWriter.Write([]byte(":\n\n")); err != nil {
 					clientDisconnected = true
 					logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
 					continue
 				}
-				flusher.Flush()
+				if err := streamGuard.flush(flusher); err != nil {
+					clientDisconnected = true
+					logger.LegacyPrintf("service.openai_gateway", "Client disconnected during keepalive flush, continuing to drain upstream for billing")
+					continue
+				}
 				lastDownstreamWriteAt = time.Now()
 				continue
 			}
@@ -1017,6 +1033,11 @@ func openAIUsageFromGJSON(value gjson.Result) (OpenAIUsage, bool) {
 	if imageOutputTokens == 0 {
 		imageOutputTokens = value.Get("completion_tokens_details.image_tokens").Int()
 	}
+	// 推理模型在 output_tokens_details.reasoning_tokens 回报 output_tokens 中的推理部分，单独记录并计价。
+	reasoningTokens := firstPositiveGJSONInt(
+		value.Get("output_tokens_details.reasoning_tokens"),
+		value.Get("completion_tokens_details.reasoning_tokens"),
+	)
 	// 图片输入 token（如 gpt-image-2 的 /v1/images/edits 带图请求），
 	// 上游在 input_tokens_details.image_tokens 单独回传，用于图/文输入分价计费。
 	// 普通文本请求该字段为 0，走原路径行为不变。
@@ -1031,6 +1052,7 @@ func openAIUsageFromGJSON(value gjson.Result) (OpenAIUsage, bool) {
 		CacheCreationInputTokens: cacheCreationTokens,
 		CacheReadInputTokens:     cacheReadTokens,
 		ImageOutputTokens:        int(imageOutputTokens),
+		ReasoningTokens:          reasoningTokens,
 	}, true
 }
 
@@ -908,6 +908,8 @@ func (s *OpenAIGatewayService) handleOpenAIImagesStreamingResponse(
 	c.Status(resp.StatusCode)
 	c.Header("Content-Type", contentType)
 
+	restoreWriter := guardStreamClient(c, s.cfg, resp)
+	defer restoreWriter()
 	flusher, ok := c.Writer.(http.Flusher)
 	if !ok {
 		return OpenAIUsage{}, 0, nil, nil, fmt.Errorf("streaming is not supported by response writer")
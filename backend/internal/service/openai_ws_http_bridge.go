@@ -329,6 +329,7 @@ func (s *OpenAIGatewayService) proxyOpenAIWSHTTPBridgeTurn(
 	}
 	scanBuf := getSSEScannerBuf64K()
 	scanner.Buffer(scanBuf[:0], maxLineSize)
+	applySSEOversizeLinePolicy(scanner, s.cfg, maxLineSize)
 	defer putSSEScannerBuf64K(scanBuf)
 
 	for scanner.Scan() {
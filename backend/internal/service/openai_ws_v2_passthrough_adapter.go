@@ -997,6 +997,7 @@ func (s *OpenAIGatewayService) proxyResponsesWebSocketV2Passthrough(
 						CacheCreationInputTokens: turn.Usage.CacheCreationInputTokens,
 						CacheReadInputTokens:     turn.Usage.CacheReadInputTokens,
 						ImageOutputTokens:        turn.Usage.ImageOutputTokens,
+						ReasoningTokens:          turn.Usage.ReasoningTokens,
 					},
 					Model:                 turn.RequestModel,
 					ServiceTier:           usageMeta.serviceTier.Load(),
@@ -1112,6 +1113,7 @@ func (s *OpenAIGatewayService) proxyResponsesWebSocketV2Passthrough(
 			CacheCreationInputTokens: relayResult.Usage.CacheCreationInputTokens,
 			CacheReadInputTokens:     relayResult.Usage.CacheReadInputTokens,
 			ImageOutputTokens:        relayResult.Usage.ImageOutputTokens,
+			ReasoningTokens:          relayResult.Usage.ReasoningTokens,
 		},
 		Model:                 relayResult.RequestModel,
 		ServiceTier:           usageMeta.serviceTier.Load(),
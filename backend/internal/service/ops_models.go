@@ -3,6 +3,8 @@ package service
 import (
 	"strings"
 	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 )
 
 type OpsSystemLog struct {
@@ -158,6 +160,8 @@ type OpsErrorLogFilter struct {
 
 	Page     int
 	PageSize int
+	// Cursor 非 nil 时使用 keyset 游标分页（忽略 Page/SortBy，按 created_at, id 排序且不统计总数）
+	Cursor *pagination.Cursor
 
 	// SortBy/SortOrder: server-side sorting aligned with the usage-log list.
 	// Repo whitelists columns (created_at/model/status_code); anything else
@@ -178,4 +182,7 @@ type OpsErrorLogList struct {
 	Total    int            `json:"total"`
 	Page     int            `json:"page"`
 	PageSize int            `json:"page_size"`
+	// 游标模式下的下一页游标，空表示没有下一页
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more,omitempty"`
 }
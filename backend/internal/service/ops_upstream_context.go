@@ -5,6 +5,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/dnsresolve"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -224,6 +226,9 @@ type OpsUpstreamErrorEvent struct {
 	Detail  string `json:"detail,omitempty"`
 }
 
+// OpsUpstreamReasonDNSResolutionFailed 上游主机名解析失败
+const OpsUpstreamReasonDNSResolutionFailed = "dns_resolution_failed"
+
 func appendOpsUpstreamError(c *gin.Context, ev OpsUpstreamErrorEvent) {
 	if c == nil {
 		return
@@ -244,6 +249,10 @@ func appendOpsUpstreamError(c *gin.Context, ev OpsUpstreamErrorEvent) {
 	if ev.Message != "" {
 		ev.Message = sanitizeUpstreamErrorMessage(ev.Message)
 	}
+	// DNS 解析失败（自定义解析器或系统解析器）单独标注，便于与上游超时/拒绝连接区分
+	if ev.Kind == "request_error" && ev.Reason == "" && dnsresolve.IsResolveErrorMessage(ev.Message) {
+		ev.Reason = OpsUpstreamReasonDNSResolutionFailed
+	}
 
 	var existing []*OpsUpstreamErrorEvent
 	if v, ok := c.Get(OpsUpstreamErrorsKey); ok {
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
 
@@ -26,3 +28,18 @@ func TestSafeUpstreamURL(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendOpsUpstreamErrorTagsDNSFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{Kind: "request_error", Message: `Post "https://api.anthropic.com/v1/messages": dns resolve api.anthropic.com: doh: rcode 3`})
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{Kind: "request_error", Message: "dial tcp 1.2.3.4:443: connect: connection refused"})
+
+	v, ok := c.Get(OpsUpstreamErrorsKey)
+	require.True(t, ok)
+	events := v.([]*OpsUpstreamErrorEvent)
+	require.Len(t, events, 2)
+	require.Equal(t, OpsUpstreamReasonDNSResolutionFailed, events[0].Reason)
+	require.Empty(t, events[1].Reason)
+}
@@ -32,6 +32,8 @@ type PaymentOrderExpiryService struct {
 	lockCache  LeaderLockCache
 	db         *sql.DB
 	instanceID string
+
+	featureFlags *FeatureFlagService
 }
 
 func NewPaymentOrderExpiryService(paymentSvc *PaymentService, interval time.Duration) *PaymentOrderExpiryService {
@@ -54,6 +56,15 @@ func (s *PaymentOrderExpiryService) SetLeaderLock(lockCache LeaderLockCache, db
 	s.db = db
 }
 
+// SetFeatureFlags injects the feature flags; the sweep is skipped while the
+// payments feature is disabled and resumes on the next tick once re-enabled.
+func (s *PaymentOrderExpiryService) SetFeatureFlags(flags *FeatureFlagService) {
+	if s == nil {
+		return
+	}
+	s.featureFlags = flags
+}
+
 func (s *PaymentOrderExpiryService) Start() {
 	if s == nil || s.paymentSvc == nil || s.interval <= 0 {
 		return
@@ -87,6 +98,9 @@ func (s *PaymentOrderExpiryService) Stop() {
 }
 
 func (s *PaymentOrderExpiryService) runOnce() {
+	if !s.featureFlags.IsEnabled(context.Background(), FeaturePayments) {
+		return
+	}
 	// Multi-instance guard: only the leader reconciles/expires orders per cycle,
 	// avoiding N× upstream payment-provider API calls and update races.
 	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -126,6 +126,11 @@ type LiteLLMModelPricing struct {
 	OutputCostPerImage                  float64 `json:"output_cost_per_image"`       // 图片生成模型每张图片价格
 	OutputCostPerImageToken             float64 `json:"output_cost_per_image_token"` // 图片输出 token 价格
 	InputCostPerImageToken              float64 `json:"input_cost_per_image_token"`  // 图片输入 token 价格（如 gpt-image-2 图片编辑）
+	MaxInputTokens                      int     `json:"max_input_tokens,omitempty"`  // 模型上下文窗口（输入 token 上限）
+	MaxOutputTokens                     int     `json:"max_output_tokens,omitempty"` // 单次输出 token 上限
+
+	// OutputCostPerReasoningToken 推理（thinking / reasoning）token 价格；为 0 时按输出价格计费
+	OutputCostPerReasoningToken float64 `json:"output_cost_per_reasoning_token,omitempty"`
 
 	// TokenPricingAbsent 表示源数据中 input/output token 价格均缺失（仅有图片价）。
 	// 此类条目只可用于图片计费，token 计费必须回退到 fallback 或 fail-closed，
@@ -160,6 +165,9 @@ type LiteLLMRawEntry struct {
 	OutputCostPerImage                  *float64 `json:"output_cost_per_image"`
 	OutputCostPerImageToken             *float64 `json:"output_cost_per_image_token"`
 	InputCostPerImageToken              *float64 `json:"input_cost_per_image_token"`
+	OutputCostPerReasoningToken         *float64 `json:"output_cost_per_reasoning_token"`
+	MaxInputTokens                      *float64 `json:"max_input_tokens"`
+	MaxOutputTokens                     *float64 `json:"max_output_tokens"`
 }
 
 // PricingService 动态价格服务
@@ -260,6 +268,7 @@ func (s *PricingService) checkAndUpdatePricing() error {
 		logger.LegacyPrintf("service.pricing", "[Pricing] Failed to load local file, downloading: %v", err)
 		return s.downloadPricingData()
 	}
+	s.archiveCurrentPricingFile()
 
 	// 如果配置了哈希URL，通过远程哈希检查是否有更新
 	if s.cfg.Pricing.HashURL != "" {
@@ -389,6 +398,8 @@ func (s *PricingService) downloadPricingData() error {
 	if err := os.WriteFile(pricingFile, body, 0644); err != nil {
 		logger.LegacyPrintf("service.pricing", "[Pricing] Failed to save file: %v", err)
 	}
+	// 归档历史价格，供用量按历史价格回溯重算
+	s.archivePricingSnapshot(body, time.Now())
 
 	// 使用远程哈希作为同步锚点，防止重复下载
 	// 当远程哈希不可用时，回退到数据本身的哈希
@@ -494,6 +505,15 @@ func (s *PricingService) parsePricingData(body []byte) (map[string]*LiteLLMModel
 		if entry.InputCostPerImageToken != nil {
 			pricing.InputCostPerImageToken = *entry.InputCostPerImageToken
 		}
+		if entry.OutputCostPerReasoningToken != nil {
+			pricing.OutputCostPerReasoningToken = *entry.OutputCostPerReasoningToken
+		}
+		if entry.MaxInputTokens != nil && *entry.MaxInputTokens > 0 {
+			pricing.MaxInputTokens = int(*entry.MaxInputTokens)
+		}
+		if entry.MaxOutputTokens != nil && *entry.MaxOutputTokens > 0 {
+			pricing.MaxOutputTokens = int(*entry.MaxOutputTokens)
+		}
 
 		result[modelName] = pricing
 	}
@@ -1023,6 +1043,15 @@ func (s *PricingService) GetStatus() map[string]any {
 	}
 }
 
+// LoadSnapshot 从指定价格文件加载价格数据（不下载、不启动定时更新），供离线重算等运维命令使用。
+// filePath 为空时加载数据目录中当前部署的价格文件。
+func (s *PricingService) LoadSnapshot(filePath string) error {
+	if filePath == "" {
+		filePath = s.getPricingFilePath()
+	}
+	return s.loadPricingData(filePath)
+}
+
 // ForceUpdate 强制更新
 func (s *PricingService) ForceUpdate() error {
 	return s.downloadPricingData()
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// pricingSnapshotDirName 历史价格文件归档目录（位于价格数据目录下）
+	pricingSnapshotDirName = "pricing_snapshots"
+	// pricingOverrideDirName 命名价格覆盖集目录，每个 <name>.json 为 LiteLLM 格式的部分价格表
+	pricingOverrideDirName = "pricing_overrides"
+
+	pricingSnapshotFilePrefix  = "model_pricing-"
+	pricingSnapshotTimeLayout  = "20060102T150405Z"
+	pricingSnapshotHashLen     = 8
+	pricingSnapshotMaxArchived = 180
+)
+
+// pricingOverrideNamePattern 覆盖集名称只允许字母数字、下划线与连字符，避免路径穿越
+var pricingOverrideNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// PricingSnapshotInfo 一份已归档的价格快照
+type PricingSnapshotInfo struct {
+	Name        string    `json:"name"`
+	EffectiveAt time.Time `json:"effective_at"`
+	Hash        string    `json:"hash"`
+	path        string
+}
+
+func (s *PricingService) pricingSnapshotDir() string {
+	return filepath.Join(s.cfg.Pricing.DataDir, pricingSnapshotDirName)
+}
+
+func (s *PricingService) pricingOverrideDir() string {
+	return filepath.Join(s.cfg.Pricing.DataDir, pricingOverrideDirName)
+}
+
+// archivePricingSnapshot 将一份价格文件按生效时间归档；内容与最近一份归档相同时跳过。
+// 归档失败只记录日志，不影响价格更新。
+func (s *PricingService) archivePricingSnapshot(body []byte, effectiveAt time.Time) {
+	digest := sha256.Sum256(body)
+	hash := hex.EncodeToString(digest[:])[:pricingSnapshotHashLen]
+
+	snapshots, err := s.ListPricingSnapshots()
+	if err != nil {
+		logger.LegacyPrintf("service.pricing", "[Pricing] List snapshots failed: %v", err)
+		return
+	}
+	if n := len(snapshots); n > 0 && snapshots[n-1].Hash == hash {
+		return
+	}
+
+	dir := s.pricingSnapshotDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.LegacyPrintf("service.pricing", "[Pricing] Failed to create snapshot directory: %v", err)
+		return
+	}
+	name := pricingSnapshotFilePrefix + effectiveAt.UTC().Format(pricingSnapshotTimeLayout) + "-" + hash
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), body, 0644); err != nil {
+		logger.LegacyPrintf("service.pricing", "[Pricing] Failed to archive snapshot: %v", err)
+		return
+	}
+
+	// 超出保留上限时删除最旧的归档
+	for i := 0; i < len(snapshots)+1-pricingSnapshotMaxArchived; i++ {
+		_ = os.Remove(snapshots[i].path)
+	}
+}
+
+// archiveCurrentPricingFile 将数据目录中当前的价格文件（按文件修改时间）补录为一份归档，
+// 使升级前已存在的价格文件也能被按日期回溯。
+func (s *PricingService) archiveCurrentPricingFile() {
+	pricingFile := s.getPricingFilePath()
+	info, err := os.Stat(pricingFile)
+	if err != nil {
+		return
+	}
+	body, err := os.ReadFile(pricingFile)
+	if err != nil {
+		return
+	}
+	s.archivePricingSnapshot(body, info.ModTime())
+}
+
+// ListPricingSnapshots 列出已归档的价格快照，按生效时间升序。
+func (s *PricingService) ListPricingSnapshots() ([]PricingSnapshotInfo, error) {
+	entries, err := os.ReadDir(s.pricingSnapshotDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read snapshot directory: %w", err)
+	}
+	snapshots := make([]PricingSnapshotInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, ok := parsePricingSnapshotFileName(entry.Name())
+		if !ok {
+			continue
+		}
+		info.path = filepath.Join(s.pricingSnapshotDir(), entry.Name())
+		snapshots = append(snapshots, info)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].EffectiveAt.Before(snapshots[j].EffectiveAt)
+	})
+	return snapshots, nil
+}
+
+// parsePricingSnapshotFileName 解析 model_pricing-<时间>-<哈希>.json 格式的归档文件名。
+func parsePricingSnapshotFileName(fileName string) (PricingSnapshotInfo, bool) {
+	name, ok := strings.CutSuffix(fileName, ".json")
+	if !ok {
+		return PricingSnapshotInfo{}, false
+	}
+	rest, ok := strings.CutPrefix(name, pricingSnapshotFilePrefix)
+	if !ok {
+		return PricingSnapshotInfo{}, false
+	}
+	stamp, hash, ok := strings.Cut(rest, "-")
+	if !ok || hash == "" {
+		return PricingSnapshotInfo{}, false
+	}
+	effectiveAt, err := time.Parse(pricingSnapshotTimeLayout, stamp)
+	if err != nil {
+		return PricingSnapshotInfo{}, false
+	}
+	return PricingSnapshotInfo{Name: name, EffectiveAt: effectiveAt, Hash: hash}, true
+}
+
+// ListPricingOverrideSets 列出可用的命名价格覆盖集，按名称排序。
+func (s *PricingService) ListPricingOverrideSets() ([]string, error) {
+	entries, err := os.ReadDir(s.pricingOverrideDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read override directory: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || !pricingOverrideNamePattern.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// pricingDataCopy 返回当前内存价格表的浅拷贝（条目指针共享，调用方不得修改条目本身）。
+func (s *PricingService) pricingDataCopy() map[string]*LiteLLMModelPricing {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data := make(map[string]*LiteLLMModelPricing, len(s.pricingData))
+	for name, pricing := range s.pricingData {
+		data[name] = pricing
+	}
+	return data
+}
+
+// newDetachedPricingService 用给定价格表构造一个独立的价格服务（不下载、不启动定时更新），供重算使用。
+func newDetachedPricingService(s *PricingService, data map[string]*LiteLLMModelPricing, updatedAt time.Time) *PricingService {
+	detached := NewPricingService(s.cfg, nil)
+	detached.pricingData = data
+	detached.lastUpdated = updatedAt
+	return detached
+}
@@ -28,6 +28,8 @@ type Proxy struct {
 	FallbackMode   string
 	BackupProxyID  *int64
 	ExpiryWarnDays int
+	// DataResidency 出口 IP 所在区域（eu/us/asia），空表示未打标
+	DataResidency string
 }
 
 func (p *Proxy) IsActive() bool {
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// proxyLatencyEWMAAlpha 延迟 EWMA 的新样本权重
+	proxyLatencyEWMAAlpha = 0.3
+	// proxyLatencyUnhealthyFailures 连续探测失败达到该次数视为不健康
+	proxyLatencyUnhealthyFailures = 2
+	// proxyLatencyRouteIdleTTL 超过该时长未被使用的 (账号, 上游) 选路记录会被回收，不再探测
+	proxyLatencyRouteIdleTTL = time.Hour
+	// proxyLatencyProbeConcurrency 单轮探测的最大并发
+	proxyLatencyProbeConcurrency = 8
+)
+
+// ProxyHostLatencyProber 通过指定代理探测到上游 origin 的延迟
+type ProxyHostLatencyProber interface {
+	ProbeHostLatency(ctx context.Context, proxyURL, origin string) (time.Duration, error)
+}
+
+type proxyLatencyCandidate struct {
+	id  int64
+	url string
+}
+
+type proxyOriginKey struct {
+	proxyID int64
+	origin  string
+}
+
+type accountOriginKey struct {
+	accountID int64
+	origin    string
+}
+
+type proxyLatencyStat struct {
+	ewmaMs   float64
+	samples  int
+	failures int
+}
+
+func (st *proxyLatencyStat) healthy() bool {
+	return st != nil && st.samples > 0 && st.failures < proxyLatencyUnhealthyFailures
+}
+
+type proxyLatencySelection struct {
+	proxyID  int64
+	since    time.Time
+	lastUsed atomic.Int64
+}
+
+// ProxyLatencyRouter 为配置了多个候选代理的账号，按上游 origin 选择延迟最低的健康代理。
+// 周期性探测 (代理, origin) 延迟并以 EWMA 平滑；切换需满足相对/绝对改善阈值和最短驻留时间（滞后），
+// 当前代理不健康时立即切换。未配置候选代理的账号原样使用传入的代理。
+type ProxyLatencyRouter struct {
+	accountRepo AccountRepository
+	proxyRepo   ProxyRepository
+	prober      ProxyHostLatencyProber
+	cfg         config.GatewayProxyLatencyRoutingConfig
+	enabled     bool
+
+	mu          sync.RWMutex
+	candidates  map[int64][]proxyLatencyCandidate
+	selections  map[accountOriginKey]*proxyLatencySelection
+	stats       map[proxyOriginKey]*proxyLatencyStat
+	lastRefresh time.Time
+
+	now      func() time.Time
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewProxyLatencyRouter 创建延迟选路器；未启用时 Route 直接透传。
+func NewProxyLatencyRouter(accountRepo AccountRepository, proxyRepo ProxyRepository, prober ProxyHostLatencyProber, cfg *config.Config) *ProxyLatencyRouter {
+	r := &ProxyLatencyRouter{
+		accountRepo: accountRepo,
+		proxyRepo:   proxyRepo,
+		prober:      prober,
+		candidates:  make(map[int64][]proxyLatencyCandidate),
+		selections:  make(map[accountOriginKey]*proxyLatencySelection),
+		stats:       make(map[proxyOriginKey]*proxyLatencyStat),
+		now:         time.Now,
+		stopCh:      make(chan struct{}),
+	}
+	if cfg != nil {
+		r.cfg = cfg.Gateway.ProxyLatencyRouting
+		r.enabled = r.cfg.Enabled && accountRepo != nil && proxyRepo != nil && prober != nil
+	}
+	return r
+}
+
+// Start 启动后台探测循环
+func (r *ProxyLatencyRouter) Start() {
+	if r == nil || !r.enabled || r.cfg.ProbeIntervalSeconds <= 0 {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(time.Duration(r.cfg.ProbeIntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		r.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				r.runOnce()
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台探测循环
+func (r *ProxyLatencyRouter) Stop() {
+	if r == nil {
+		return
+	}
+	r.stopOnce.Do(func() { close(r.stopCh) })
+	r.wg.Wait()
+}
+
+// Route 返回账号访问 origin（scheme://host[:port]）应使用的代理 URL。
+// 账号不参与选路或尚无选择时返回传入的 proxyURL。
+func (r *ProxyLatencyRouter) Route(accountID int64, origin, proxyURL string) string {
+	if r == nil || !r.enabled || accountID <= 0 || origin == "" {
+		return proxyURL
+	}
+	key := accountOriginKey{accountID: accountID, origin: origin}
+	now := r.now()
+
+	r.mu.RLock()
+	candidates := r.candidates[accountID]
+	sel := r.selections[key]
+	if sel != nil {
+		sel.lastUsed.Store(now.UnixNano())
+		if u, ok := candidateURL(candidates, sel.proxyID); ok {
+			r.mu.RUnlock()
+			return u
+		}
+	}
+	r.mu.RUnlock()
+	if len(candidates) == 0 {
+		return proxyURL
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	candidates = r.candidates[accountID]
+	sel = r.selections[key]
+	if sel == nil {
+		sel = &proxyLatencySelection{proxyID: r.initialProxyLocked(candidates, origin, proxyURL), since: now}
+		r.selections[key] = sel
+	}
+	sel.lastUsed.Store(now.UnixNano())
+	if u, ok := candidateURL(candidates, sel.proxyID); ok {
+		return u
+	}
+	return proxyURL
+}
+
+// initialProxyLocked 首次访问时的选择：已有探测数据则取最优，否则沿用传入代理（不在候选中时取首个候选）。
+func (r *ProxyLatencyRouter) initialProxyLocked(candidates []proxyLatencyCandidate, origin, proxyURL string) int64 {
+	if best := r.bestLocked(candidates, origin); best > 0 {
+		return best
+	}
+	for _, c := range candidates {
+		if c.url == proxyURL {
+			return c.id
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0].id
+	}
+	return 0
+}
+
+// bestLocked 返回 origin 下延迟最低的健康候选代理 ID，无可用数据时返回 0
+func (r *ProxyLatencyRouter) bestLocked(candidates []proxyLatencyCandidate, origin string) int64 {
+	var bestID int64
+	bestMs := 0.0
+	for _, c := range candidates {
+		st := r.stats[proxyOriginKey{proxyID: c.id, origin: origin}]
+		if !st.healthy() {
+			continue
+		}
+		if bestID == 0 || st.ewmaMs < bestMs {
+			bestID, bestMs = c.id, st.ewmaMs
+		}
+	}
+	return bestID
+}
+
+func candidateURL(candidates []proxyLatencyCandidate, proxyID int64) (string, bool) {
+	for _, c := range candidates {
+		if c.id == proxyID {
+			return c.url, true
+		}
+	}
+	return "", false
+}
+
+func (r *ProxyLatencyRouter) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.cfg.ProbeIntervalSeconds)*time.Second)
+	defer cancel()
+
+	now := r.now()
+	if r.lastRefresh.IsZero() || now.Sub(r.lastRefresh) >= time.Duration(r.cfg.RefreshIntervalSeconds)*time.Second {
+		if err := r.refreshCandidates(ctx); err != nil {
+			logger.LegacyPrintf("service.proxy_latency_router", "[ProxyLatencyRouter] refresh candidates failed: %v", err)
+		} else {
+			r.lastRefresh = now
+		}
+	}
+	r.probe(ctx, r.pruneAndCollectPairs(now))
+	r.reevaluate(r.now())
+}
+
+// refreshCandidates 重新加载参与选路的账号及其可用候选代理
+func (r *ProxyLatencyRouter) refreshCandidates(ctx context.Context) error {
+	accounts, err := r.accountRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	proxies, err := r.proxyRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	proxiesByID := make(map[int64]*Proxy, len(proxies))
+	for i := range proxies {
+		proxiesByID[proxies[i].ID] = &proxies[i]
+	}
+
+	next := make(map[int64][]proxyLatencyCandidate)
+	for i := range accounts {
+		ids := accounts[i].GetProxyCandidateIDs()
+		if len(ids) == 0 {
+			continue
+		}
+		// 打了区域标签的账号只在同区域代理之间选路，避免出口被切换到其它区域
+		region, _ := accounts[i].Extra[AccountDataResidencyExtraKey].(string)
+		list := make([]proxyLatencyCandidate, 0, len(ids))
+		for _, id := range ids {
+			p, ok := proxiesByID[id]
+			if !ok || (region != "" && p.DataResidency != region) {
+				continue
+			}
+			list = append(list, proxyLatencyCandidate{id: id, url: p.URL()})
+		}
+		if len(list) >= 2 {
+			next[accounts[i].ID] = list
+		}
+	}
+
+	r.mu.Lock()
+	r.candidates = next
+	r.mu.Unlock()
+	return nil
+}
+
+// pruneAndCollectPairs 回收闲置选路记录，并返回本轮需要探测的 (代理, origin) 组合
+func (r *ProxyLatencyRouter) pruneAndCollectPairs(now time.Time) map[proxyOriginKey]string {
+	cutoff := now.Add(-proxyLatencyRouteIdleTTL).UnixNano()
+	pairs := make(map[proxyOriginKey]string)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, sel := range r.selections {
+		candidates, ok := r.candidates[key.accountID]
+		if !ok || sel.lastUsed.Load() < cutoff {
+			delete(r.selections, key)
+			continue
+		}
+		for _, c := range candidates {
+			pairs[proxyOriginKey{proxyID: c.id, origin: key.origin}] = c.url
+		}
+	}
+	for key := range r.stats {
+		if _, ok := pairs[key]; !ok {
+			delete(r.stats, key)
+		}
+	}
+	return pairs
+}
+
+func (r *ProxyLatencyRouter) probe(ctx context.Context, pairs map[proxyOriginKey]string) {
+	if len(pairs) == 0 {
+		return
+	}
+	timeout := time.Duration(r.cfg.ProbeTimeoutSeconds) * time.Second
+	sem := make(chan struct{}, proxyLatencyProbeConcurrency)
+	var wg sync.WaitGroup
+	for key, proxyURL := range pairs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key proxyOriginKey, proxyURL string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			latency, err := r.prober.ProbeHostLatency(probeCtx, proxyURL, key.origin)
+			cancel()
+			r.recordProbe(key, latency, err)
+		}(key, proxyURL)
+	}
+	wg.Wait()
+}
+
+func (r *ProxyLatencyRouter) recordProbe(key proxyOriginKey, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.stats[key]
+	if st == nil {
+		st = &proxyLatencyStat{}
+		r.stats[key] = st
+	}
+	if err != nil {
+		st.failures++
+		return
+	}
+	ms := float64(latency) / float64(time.Millisecond)
+	if st.samples == 0 {
+		st.ewmaMs = ms
+	} else {
+		st.ewmaMs = proxyLatencyEWMAAlpha*ms + (1-proxyLatencyEWMAAlpha)*st.ewmaMs
+	}
+	st.samples++
+	st.failures = 0
+}
+
+// reevaluate 按滞后规则重新评估所有选路记录
+func (r *ProxyLatencyRouter) reevaluate(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, sel := range r.selections {
+		candidates := r.candidates[key.accountID]
+		next := r.nextProxyLocked(sel, candidates, key.origin, now)
+		if next == 0 || next == sel.proxyID {
+			continue
+		}
+		logger.LegacyPrintf("service.proxy_latency_router", "[ProxyLatencyRouter] account=%d origin=%s switch proxy %d -> %d",
+			key.accountID, key.origin, sel.proxyID, next)
+		sel.proxyID = next
+		sel.since = now
+	}
+}
+
+// nextProxyLocked 计算选路记录应切换到的代理 ID；返回 0 或当前 ID 表示保持不变。
+func (r *ProxyLatencyRouter) nextProxyLocked(sel *proxyLatencySelection, candidates []proxyLatencyCandidate, origin string, now time.Time) int64 {
+	best := r.bestLocked(candidates, origin)
+	if best == 0 || best == sel.proxyID {
+		return 0
+	}
+	if _, ok := candidateURL(candidates, sel.proxyID); !ok {
+		return best
+	}
+	cur := r.stats[proxyOriginKey{proxyID: sel.proxyID, origin: origin}]
+	if cur == nil || cur.samples == 0 {
+		// 当前代理尚无探测结果时保持，等下一轮数据
+		return 0
+	}
+	if !cur.healthy() {
+		return best
+	}
+	if now.Sub(sel.since) < time.Duration(r.cfg.MinHoldSeconds)*time.Second {
+		return 0
+	}
+	bestMs := r.stats[proxyOriginKey{proxyID: best, origin: origin}].ewmaMs
+	if cur.ewmaMs-bestMs < float64(r.cfg.MinImprovementMs) {
+		return 0
+	}
+	if bestMs > cur.ewmaMs*(1-float64(r.cfg.SwitchThresholdPercent)/100) {
+		return 0
+	}
+	return best
+}
+
+// ProxyLatencyOrigin 从上游 URL 提取选路使用的 origin（scheme://host[:port]）
+func ProxyLatencyOrigin(u *url.URL) string {
+	if u == nil || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
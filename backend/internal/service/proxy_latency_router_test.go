@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProxyHostLatencyProber struct {
+	mu      sync.Mutex
+	latency map[string]time.Duration
+	fail    map[string]bool
+}
+
+func (p *fakeProxyHostLatencyProber) set(proxyURL string, latency time.Duration, fail bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.latency[proxyURL] = latency
+	p.fail[proxyURL] = fail
+}
+
+func (p *fakeProxyHostLatencyProber) ProbeHostLatency(_ context.Context, proxyURL, _ string) (time.Duration, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail[proxyURL] {
+		return 0, errors.New("probe failed")
+	}
+	return p.latency[proxyURL], nil
+}
+
+const (
+	testProxyA = "http://proxy-a:8080"
+	testProxyB = "http://proxy-b:8080"
+)
+
+func newTestProxyLatencyRouter(t *testing.T) (*ProxyLatencyRouter, *fakeProxyHostLatencyProber, *time.Time) {
+	t.Helper()
+	prober := &fakeProxyHostLatencyProber{latency: map[string]time.Duration{}, fail: map[string]bool{}}
+	cfg := &config.Config{}
+	cfg.Gateway.ProxyLatencyRouting = config.GatewayProxyLatencyRoutingConfig{
+		Enabled:                true,
+		ProbeIntervalSeconds:   60,
+		ProbeTimeoutSeconds:    1,
+		RefreshIntervalSeconds: 300,
+		SwitchThresholdPercent: 20,
+		MinImprovementMs:       30,
+		MinHoldSeconds:         300,
+	}
+	r := NewProxyLatencyRouter(nil, nil, prober, cfg)
+	r.enabled = true
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	r.candidates[1] = []proxyLatencyCandidate{{id: 10, url: testProxyA}, {id: 20, url: testProxyB}}
+	return r, prober, &now
+}
+
+func (r *ProxyLatencyRouter) probeAndReevaluateForTest() {
+	now := r.now()
+	r.probe(context.Background(), r.pruneAndCollectPairs(now))
+	r.reevaluate(now)
+}
+
+func TestProxyLatencyRouter_PassThroughWithoutCandidates(t *testing.T) {
+	r, _, _ := newTestProxyLatencyRouter(t)
+	require.Equal(t, "http://own:1", r.Route(2, "https://api.anthropic.com", "http://own:1"))
+	require.Equal(t, testProxyA, r.Route(1, "", testProxyA))
+
+	var nilRouter *ProxyLatencyRouter
+	require.Equal(t, testProxyA, nilRouter.Route(1, "https://api.anthropic.com", testProxyA))
+}
+
+func TestProxyLatencyRouter_HysteresisAndHealth(t *testing.T) {
+	r, prober, now := newTestProxyLatencyRouter(t)
+	origin := "https://api.anthropic.com"
+
+	// 首次访问沿用传入代理
+	require.Equal(t, testProxyA, r.Route(1, origin, testProxyA))
+
+	// B 快但未超过最短驻留时间，不切换
+	prober.set(testProxyA, 200*time.Millisecond, false)
+	prober.set(testProxyB, 100*time.Millisecond, false)
+	r.probeAndReevaluateForTest()
+	require.Equal(t, testProxyA, r.Route(1, origin, testProxyA))
+
+	// 驻留期满后，改善幅度满足阈值，切换到 B
+	*now = now.Add(10 * time.Minute)
+	r.probeAndReevaluateForTest()
+	require.Equal(t, testProxyB, r.Route(1, origin, testProxyA))
+
+	// A 只略快（不足 20%），保持 B
+	*now = now.Add(10 * time.Minute)
+	prober.set(testProxyA, 90*time.Millisecond, false)
+	prober.set(testProxyB, 100*time.Millisecond, false)
+	for i := 0; i < 10; i++ {
+		r.probeAndReevaluateForTest()
+	}
+	require.Equal(t, testProxyB, r.Route(1, origin, testProxyA))
+
+	// B 连续探测失败变为不健康，即使在驻留期内也立即切回 A
+	prober.set(testProxyB, 0, true)
+	r.probeAndReevaluateForTest()
+	require.Equal(t, testProxyB, r.Route(1, origin, testProxyA), "a single failure must not flap")
+	r.probeAndReevaluateForTest()
+	require.Equal(t, testProxyA, r.Route(1, origin, testProxyA))
+}
+
+func TestProxyLatencyRouter_PrunesIdleSelections(t *testing.T) {
+	r, _, now := newTestProxyLatencyRouter(t)
+	r.Route(1, "https://api.anthropic.com", testProxyA)
+	require.Len(t, r.pruneAndCollectPairs(*now), 2)
+
+	require.Empty(t, r.pruneAndCollectPairs(now.Add(2*proxyLatencyRouteIdleTTL)))
+	require.Empty(t, r.selections)
+}
+
+func TestProxyLatencyOrigin(t *testing.T) {
+	u, err := url.Parse("HTTPS://API.Anthropic.com:443/v1/messages?beta=true")
+	require.NoError(t, err)
+	require.Equal(t, "https://api.anthropic.com:443", ProxyLatencyOrigin(u))
+	require.Empty(t, ProxyLatencyOrigin(nil))
+}
+
+func TestAccountGetProxyCandidateIDs(t *testing.T) {
+	own := int64(5)
+	account := &Account{ProxyID: &own, Extra: map[string]any{"proxy_candidate_ids": []any{float64(7), float64(5), float64(7)}}}
+	require.Equal(t, []int64{5, 7}, account.GetProxyCandidateIDs())
+
+	single := &Account{ProxyID: &own, Extra: map[string]any{"proxy_candidate_ids": []any{float64(5)}}}
+	require.Nil(t, single.GetProxyCandidateIDs())
+	require.Nil(t, (&Account{}).GetProxyCandidateIDs())
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// WithPrimaryRead 标记 ctx 上的数据库读取必须走主库。
+// 配置了只读副本时，仪表盘统计与使用记录列表默认读副本；写后需要立即读到结果的调用用它按次关闭副本路由。
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxkey.PrimaryDBRead, true)
+}
+
+// PrimaryReadRequired 返回 ctx 是否要求读取走主库。
+func PrimaryReadRequired(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	required, _ := ctx.Value(ctxkey.PrimaryDBRead).(bool)
+	return required
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	redisHealthProbeTimeout     = 2 * time.Second
+	redisHealthFailureThreshold = 2
+)
+
+// RedisProbe Redis 连通性探测（由 repository 按部署拓扑实现）
+type RedisProbe interface {
+	// Mode 部署拓扑：standalone / sentinel / cluster
+	Mode() string
+	// Ping 探测主节点（cluster 模式为全部主节点）
+	Ping(ctx context.Context) error
+	// PingReplicas 探测只读从节点客户端；未启用从节点读取时返回 nil
+	PingReplicas(ctx context.Context) error
+	// Refresh 探测失败时触发拓扑刷新（cluster 重新加载 slot 路由）
+	Refresh(ctx context.Context)
+}
+
+// RedisHealthStatus Redis 健康状态（/healthz 使用）
+type RedisHealthStatus struct {
+	Enabled             bool       `json:"enabled"`
+	Mode                string     `json:"mode,omitempty"`
+	Healthy             bool       `json:"healthy"`
+	ReplicaHealthy      bool       `json:"replica_healthy"`
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	LastProbeAt         *time.Time `json:"last_probe_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	Transitions         uint64     `json:"transitions"`
+}
+
+// RedisHealthMonitor 周期性 PING Redis，记录主/从节点可用性变化。
+//
+// 故障转移本身由 go-redis 客户端完成（sentinel 重新解析主节点、cluster 按 MOVED 刷新路由），
+// 这里负责可观测性，并在 cluster 模式探测失败时主动刷新 slot 路由以加快收敛。
+type RedisHealthMonitor struct {
+	probe    RedisProbe
+	interval time.Duration
+
+	healthy             atomic.Bool
+	replicaHealthy      atomic.Bool
+	consecutiveFailures atomic.Int64
+	lastProbeAt         atomic.Int64 // unix nano
+	lastError           atomic.Value // string
+	transitions         atomic.Uint64
+
+	stopCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewRedisHealthMonitor 创建 Redis 健康监控
+func NewRedisHealthMonitor(probe RedisProbe, cfg *config.Config) *RedisHealthMonitor {
+	m := &RedisHealthMonitor{probe: probe, stopCh: make(chan struct{})}
+	if cfg != nil && cfg.Redis.HealthCheckIntervalSeconds > 0 {
+		m.interval = time.Duration(cfg.Redis.HealthCheckIntervalSeconds) * time.Second
+	}
+	m.healthy.Store(true)
+	m.replicaHealthy.Store(true)
+	m.lastError.Store("")
+	return m
+}
+
+// Enabled 是否启用周期探测
+func (m *RedisHealthMonitor) Enabled() bool {
+	return m != nil && m.probe != nil && m.interval > 0
+}
+
+// Start 启动探测循环
+func (m *RedisHealthMonitor) Start() {
+	if !m.Enabled() {
+		return
+	}
+	m.startOnce.Do(func() {
+		m.wg.Add(1)
+		go m.run()
+	})
+}
+
+// Stop 停止探测循环
+func (m *RedisHealthMonitor) Stop() {
+	if m == nil {
+		return
+	}
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+		m.wg.Wait()
+	})
+}
+
+func (m *RedisHealthMonitor) run() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.probeOnce(context.Background())
+		}
+	}
+}
+
+func (m *RedisHealthMonitor) probeOnce(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, redisHealthProbeTimeout)
+	defer cancel()
+	err := m.probe.Ping(probeCtx)
+	replicaErr := m.probe.PingReplicas(probeCtx)
+	m.lastProbeAt.Store(time.Now().UnixNano())
+
+	if replicaErr != nil {
+		if m.replicaHealthy.CompareAndSwap(true, false) {
+			logger.LegacyPrintf("service.redis_health", "[RedisHealth] replica reads unavailable (mode=%s), falling back to primary: %v", m.probe.Mode(), replicaErr)
+		}
+	} else if m.replicaHealthy.CompareAndSwap(false, true) {
+		logger.LegacyPrintf("service.redis_health", "[RedisHealth] replica reads recovered (mode=%s)", m.probe.Mode())
+	}
+
+	if err != nil {
+		m.lastError.Store(err.Error())
+		failures := m.consecutiveFailures.Add(1)
+		m.probe.Refresh(ctx)
+		if failures >= redisHealthFailureThreshold && m.healthy.CompareAndSwap(true, false) {
+			m.transitions.Add(1)
+			logger.LegacyPrintf("service.redis_health", "[RedisHealth] ALERT: redis unreachable after %d probes (mode=%s): %v", failures, m.probe.Mode(), err)
+		}
+		return
+	}
+
+	m.consecutiveFailures.Store(0)
+	if m.healthy.CompareAndSwap(false, true) {
+		m.lastError.Store("")
+		m.transitions.Add(1)
+		logger.LegacyPrintf("service.redis_health", "[RedisHealth] redis reachable again (mode=%s)", m.probe.Mode())
+	}
+}
+
+// Status 返回当前健康状态快照
+func (m *RedisHealthMonitor) Status() RedisHealthStatus {
+	if m == nil {
+		return RedisHealthStatus{}
+	}
+	st := RedisHealthStatus{
+		Enabled:             m.Enabled(),
+		Healthy:             m.healthy.Load(),
+		ReplicaHealthy:      m.replicaHealthy.Load(),
+		ConsecutiveFailures: m.consecutiveFailures.Load(),
+		Transitions:         m.transitions.Load(),
+	}
+	if m.probe != nil {
+		st.Mode = m.probe.Mode()
+	}
+	if v, ok := m.lastError.Load().(string); ok {
+		st.LastError = v
+	}
+	if ts := m.lastProbeAt.Load(); ts > 0 {
+		t := time.Unix(0, ts).UTC()
+		st.LastProbeAt = &t
+	}
+	return st
+}
+
+var defaultRedisHealthMonitor atomic.Pointer[RedisHealthMonitor]
+
+// SetDefaultRedisHealthMonitor 注册全局 Redis 健康监控（由 wire 在启动时设置）
+func SetDefaultRedisHealthMonitor(m *RedisHealthMonitor) {
+	defaultRedisHealthMonitor.Store(m)
+}
+
+// DefaultRedisHealthMonitor 返回全局 Redis 健康监控（未注册时为 nil）
+func DefaultRedisHealthMonitor() *RedisHealthMonitor {
+	return defaultRedisHealthMonitor.Load()
+}
@@ -0,0 +1,68 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type redisProbeStub struct {
+	err        error
+	replicaErr error
+	refreshes  int
+}
+
+func (s *redisProbeStub) Mode() string                       { return config.RedisModeCluster }
+func (s *redisProbeStub) Ping(context.Context) error         { return s.err }
+func (s *redisProbeStub) PingReplicas(context.Context) error { return s.replicaErr }
+func (s *redisProbeStub) Refresh(context.Context)            { s.refreshes++ }
+
+func TestRedisHealthMonitor_Transitions(t *testing.T) {
+	probe := &redisProbeStub{}
+	cfg := &config.Config{Redis: config.RedisConfig{HealthCheckIntervalSeconds: 5}}
+	m := NewRedisHealthMonitor(probe, cfg)
+	require.True(t, m.Enabled())
+
+	m.probeOnce(context.Background())
+	st := m.Status()
+	require.True(t, st.Healthy)
+	require.Equal(t, config.RedisModeCluster, st.Mode)
+	require.NotNil(t, st.LastProbeAt)
+
+	// 单次失败不切换状态，但会触发拓扑刷新
+	probe.err = errors.New("connection refused")
+	m.probeOnce(context.Background())
+	require.True(t, m.Status().Healthy)
+	require.Equal(t, 1, probe.refreshes)
+
+	m.probeOnce(context.Background())
+	st = m.Status()
+	require.False(t, st.Healthy)
+	require.Equal(t, int64(2), st.ConsecutiveFailures)
+	require.Equal(t, "connection refused", st.LastError)
+	require.Equal(t, uint64(1), st.Transitions)
+
+	probe.err = nil
+	probe.replicaErr = errors.New("replica down")
+	m.probeOnce(context.Background())
+	st = m.Status()
+	require.True(t, st.Healthy)
+	require.False(t, st.ReplicaHealthy)
+	require.Empty(t, st.LastError)
+	require.Equal(t, uint64(2), st.Transitions)
+}
+
+func TestRedisHealthMonitor_DisabledWithoutInterval(t *testing.T) {
+	m := NewRedisHealthMonitor(&redisProbeStub{}, &config.Config{})
+	require.False(t, m.Enabled())
+	m.Start()
+	m.Stop()
+
+	var nilMonitor *RedisHealthMonitor
+	require.Equal(t, RedisHealthStatus{}, nilMonitor.Status())
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+// 卸载原因
+const (
+	AdmissionShedReasonGlobal   = "global_queue_full"
+	AdmissionShedReasonGroup    = "group_queue_full"
+	AdmissionShedReasonAdaptive = "wait_p95_exceeded"
+)
+
+const (
+	// admissionMaxShedProbability 自适应卸载的最大拒绝比例，保留少量请求以便等待耗时样本能够恢复
+	admissionMaxShedProbability = 0.95
+	// admissionWaitP95RefreshInterval 等待 p95 的重新计算间隔（分位数计算需要排序样本）
+	admissionWaitP95RefreshInterval = time.Second
+)
+
+// AdmissionShedError 请求在进入槽位等待队列前被准入控制拒绝。
+type AdmissionShedError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *AdmissionShedError) Error() string {
+	return fmt.Sprintf("request shed by admission control: %s", e.Reason)
+}
+
+// RequestAdmissionStats 准入控制的当前状态与累计卸载数。
+type RequestAdmissionStats struct {
+	Enabled         bool
+	WaitingTotal    int64
+	ShedProbability float64
+	// Shed 按原因累计的卸载数
+	Shed map[string]int64
+}
+
+// RequestAdmissionController 对等待并发槽位的请求做准入控制（gateway.admission_control）。
+//
+// 等待请求数超过全局/分组上限时直接拒绝；近期槽位等待 p95 超过目标时，按超出比例随机拒绝新的等待请求。
+// 立即拿到槽位的请求不经过准入控制。
+type RequestAdmissionController struct {
+	cfg      config.GatewayAdmissionControlConfig
+	registry *ActiveRequestRegistry
+	now      func() time.Time
+	random   func() float64
+
+	waitingTotal atomic.Int64
+	shedGlobal   atomic.Int64
+	shedGroup    atomic.Int64
+	shedAdaptive atomic.Int64
+
+	mu             sync.Mutex
+	waitingByGroup map[int64]int
+	p95            time.Duration
+	p95Samples     int
+	p95ComputedAt  time.Time
+}
+
+// NewRequestAdmissionController creates a RequestAdmissionController that reads wait samples from registry.
+func NewRequestAdmissionController(cfg config.GatewayAdmissionControlConfig, registry *ActiveRequestRegistry) *RequestAdmissionController {
+	return &RequestAdmissionController{
+		cfg:            cfg,
+		registry:       registry,
+		now:            time.Now,
+		random:         rand.Float64,
+		waitingByGroup: make(map[int64]int),
+	}
+}
+
+// Admit 申请进入槽位等待队列；允许时返回的函数在等待结束时调用，拒绝时返回 *AdmissionShedError。
+func (c *RequestAdmissionController) Admit(groupID *int64) (func(), error) {
+	return c.AdmitWithPriority(groupID, RequestPriorityNormal)
+}
+
+// AdmitWithPriority 同 Admit，自适应卸载按请求优先级调整：high 不参与，low 拒绝比例加倍。
+// 全局/分组等待上限对所有优先级一视同仁。
+func (c *RequestAdmissionController) AdmitWithPriority(groupID *int64, priority string) (func(), error) {
+	if c == nil || !c.cfg.Enabled {
+		return func() {}, nil
+	}
+	if probability := requestPriorityShedProbability(c.shedProbability(), priority); probability > 0 && c.random() < probability {
+		c.shedAdaptive.Add(1)
+		return nil, c.shedError(AdmissionShedReasonAdaptive)
+	}
+
+	if total := c.waitingTotal.Add(1); c.cfg.MaxWaitingGlobal > 0 && total > int64(c.cfg.MaxWaitingGlobal) {
+		c.waitingTotal.Add(-1)
+		c.shedGlobal.Add(1)
+		return nil, c.shedError(AdmissionShedReasonGlobal)
+	}
+	group := int64(0)
+	if groupID != nil {
+		group = *groupID
+	}
+	c.mu.Lock()
+	if c.cfg.MaxWaitingPerGroup > 0 && c.waitingByGroup[group] >= c.cfg.MaxWaitingPerGroup {
+		c.mu.Unlock()
+		c.waitingTotal.Add(-1)
+		c.shedGroup.Add(1)
+		return nil, c.shedError(AdmissionShedReasonGroup)
+	}
+	c.waitingByGroup[group]++
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			if c.waitingByGroup[group] <= 1 {
+				delete(c.waitingByGroup, group)
+			} else {
+				c.waitingByGroup[group]--
+			}
+			c.mu.Unlock()
+			c.waitingTotal.Add(-1)
+		})
+	}, nil
+}
+
+// Stats 返回当前等待数与累计卸载数。
+func (c *RequestAdmissionController) Stats() RequestAdmissionStats {
+	if c == nil {
+		return RequestAdmissionStats{}
+	}
+	return RequestAdmissionStats{
+		Enabled:         c.cfg.Enabled,
+		WaitingTotal:    c.waitingTotal.Load(),
+		ShedProbability: c.shedProbability(),
+		Shed: map[string]int64{
+			AdmissionShedReasonGlobal:   c.shedGlobal.Load(),
+			AdmissionShedReasonGroup:    c.shedGroup.Load(),
+			AdmissionShedReasonAdaptive: c.shedAdaptive.Load(),
+		},
+	}
+}
+
+// shedProbability 近期等待 p95 超过目标时的拒绝比例：(p95 - target) / target，上限 admissionMaxShedProbability。
+func (c *RequestAdmissionController) shedProbability() float64 {
+	if !c.cfg.Enabled || c.cfg.ShedWaitP95Ms <= 0 || c.registry == nil {
+		return 0
+	}
+	now := c.now()
+	c.mu.Lock()
+	if c.p95ComputedAt.IsZero() || now.Sub(c.p95ComputedAt) >= admissionWaitP95RefreshInterval {
+		c.p95, c.p95Samples = c.registry.SlotWaitQuantile(now.Add(-time.Duration(c.cfg.ShedWindowSeconds)*time.Second), 0.95)
+		c.p95ComputedAt = now
+	}
+	p95, samples := c.p95, c.p95Samples
+	c.mu.Unlock()
+
+	target := time.Duration(c.cfg.ShedWaitP95Ms) * time.Millisecond
+	if samples < c.cfg.ShedMinSamples || p95 <= target {
+		return 0
+	}
+	probability := float64(p95-target) / float64(target)
+	if probability > admissionMaxShedProbability {
+		probability = admissionMaxShedProbability
+	}
+	return probability
+}
+
+func (c *RequestAdmissionController) shedError(reason string) error {
+	return &AdmissionShedError{Reason: reason, RetryAfter: time.Duration(c.cfg.RetryAfterSeconds) * time.Second}
+}
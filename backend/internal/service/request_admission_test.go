@@ -0,0 +1,107 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func requireAdmissionShed(t *testing.T, err error, reason string) {
+	t.Helper()
+	var shedErr *AdmissionShedError
+	require.True(t, errors.As(err, &shedErr))
+	require.Equal(t, reason, shedErr.Reason)
+	require.Equal(t, 5*time.Second, shedErr.RetryAfter)
+}
+
+func TestRequestAdmission_WaitingLimits(t *testing.T) {
+	c := NewRequestAdmissionController(config.GatewayAdmissionControlConfig{
+		Enabled: true, MaxWaitingGlobal: 3, MaxWaitingPerGroup: 2, RetryAfterSeconds: 5,
+	}, nil)
+	groupA, groupB := int64(1), int64(2)
+
+	releaseA1, err := c.Admit(&groupA)
+	require.NoError(t, err)
+	_, err = c.Admit(&groupA)
+	require.NoError(t, err)
+	_, err = c.Admit(&groupA)
+	requireAdmissionShed(t, err, AdmissionShedReasonGroup)
+
+	_, err = c.Admit(&groupB)
+	require.NoError(t, err)
+	_, err = c.Admit(nil)
+	requireAdmissionShed(t, err, AdmissionShedReasonGlobal)
+
+	releaseA1()
+	releaseA1() // 重复调用不会重复扣减
+	_, err = c.Admit(&groupA)
+	require.NoError(t, err)
+
+	stats := c.Stats()
+	require.Equal(t, int64(3), stats.WaitingTotal)
+	require.Equal(t, int64(1), stats.Shed[AdmissionShedReasonGroup])
+	require.Equal(t, int64(1), stats.Shed[AdmissionShedReasonGlobal])
+}
+
+func TestRequestAdmission_AdaptiveShedding(t *testing.T) {
+	registry := NewActiveRequestRegistry(0)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+	c := NewRequestAdmissionController(config.GatewayAdmissionControlConfig{
+		Enabled: true, ShedWaitP95Ms: 1000, ShedWindowSeconds: 60, ShedMinSamples: 2, RetryAfterSeconds: 5,
+	}, registry)
+	c.now = registry.now
+	c.random = func() float64 { return 0.4 }
+
+	recordWait := func(d time.Duration) {
+		req, _ := registry.Begin(context.Background(), ActiveRequestInfo{})
+		done := req.BeginWait()
+		now = now.Add(d)
+		done()
+	}
+	recordWait(1500 * time.Millisecond)
+	_, err := c.Admit(nil)
+	require.NoError(t, err, "not enough samples yet")
+
+	recordWait(1500 * time.Millisecond)
+	now = now.Add(admissionWaitP95RefreshInterval)
+	require.InDelta(t, 0.5, c.Stats().ShedProbability, 1e-9)
+	_, err = c.Admit(nil)
+	requireAdmissionShed(t, err, AdmissionShedReasonAdaptive)
+
+	_, err = c.AdmitWithPriority(nil, RequestPriorityHigh)
+	require.NoError(t, err, "high priority is exempt from adaptive shedding")
+
+	c.random = func() float64 { return 0.6 }
+	_, err = c.Admit(nil)
+	require.NoError(t, err)
+	_, err = c.AdmitWithPriority(nil, RequestPriorityLow)
+	requireAdmissionShed(t, err, AdmissionShedReasonAdaptive)
+
+	now = now.Add(2 * time.Minute)
+	require.Zero(t, c.Stats().ShedProbability, "samples outside the window stop shedding")
+}
+
+func TestRequestAdmission_DisabledAndNil(t *testing.T) {
+	release, err := NewRequestAdmissionController(config.GatewayAdmissionControlConfig{MaxWaitingGlobal: 1}, nil).Admit(nil)
+	require.NoError(t, err)
+	release()
+
+	var req *ActiveRequest
+	release, err = req.AdmitWait()
+	require.NoError(t, err)
+	release()
+
+	registry := NewActiveRequestRegistry(0)
+	active, _ := registry.Begin(context.Background(), ActiveRequestInfo{})
+	release, err = active.AdmitWait()
+	require.NoError(t, err)
+	release()
+	require.False(t, registry.AdmissionStats().Enabled)
+}
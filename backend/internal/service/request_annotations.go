@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 网关响应注解（APIKey.AnnotationsEnabled 开启时输出）。
+// 平台、重试次数与排队耗时在首次写出响应时已知，作为响应头下发；
+// 缓存命中与费用估算要等使用量记录算出费用后才知道，作为 HTTP trailer 下发。
+const (
+	RequestAnnotationHeaderPlatform      = "X-Sub2API-Platform"
+	RequestAnnotationHeaderRetryCount    = "X-Sub2API-Retry-Count"
+	RequestAnnotationHeaderQueueWaitMs   = "X-Sub2API-Queue-Wait-Ms"
+	RequestAnnotationHeaderPriority      = "X-Sub2API-Priority"
+	RequestAnnotationTrailerCacheHit     = "X-Sub2API-Cache-Hit"
+	RequestAnnotationTrailerCostEstimate = "X-Sub2API-Cost-Estimate"
+)
+
+type requestAnnotationsContextKey struct{}
+
+// RequestAnnotations 收集只在使用量记录阶段才能得到的注解（缓存命中、费用估算）。
+// 使用量记录在 worker 池异步执行，响应结束时通过 WaitUsage 有限等待结果。
+type RequestAnnotations struct {
+	once     sync.Once
+	done     chan struct{}
+	cacheHit bool
+	costUSD  float64
+}
+
+// NewRequestAnnotations creates an empty RequestAnnotations.
+func NewRequestAnnotations() *RequestAnnotations {
+	return &RequestAnnotations{done: make(chan struct{})}
+}
+
+// WithRequestAnnotations 把注解收集器挂到 context；未开启注解的请求不挂载，记录方法对 nil 接收者安全。
+func WithRequestAnnotations(ctx context.Context, a *RequestAnnotations) context.Context {
+	if a == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestAnnotationsContextKey{}, a)
+}
+
+// RequestAnnotationsFromContext 取出当前请求的注解收集器；未开启时返回 nil。
+func RequestAnnotationsFromContext(ctx context.Context) *RequestAnnotations {
+	if ctx == nil {
+		return nil
+	}
+	a, _ := ctx.Value(requestAnnotationsContextKey{}).(*RequestAnnotations)
+	return a
+}
+
+// SetUsage 记录缓存命中与费用估算（用户实际扣费口径，已含倍率），只取第一次。
+func (a *RequestAnnotations) SetUsage(cacheHit bool, actualCost float64) {
+	if a == nil {
+		return
+	}
+	a.once.Do(func() {
+		a.cacheHit = cacheHit
+		a.costUSD = actualCost
+		close(a.done)
+	})
+}
+
+// WaitUsage 最多等待 timeout 取得使用量注解；超时返回 ok=false。
+func (a *RequestAnnotations) WaitUsage(timeout time.Duration) (cacheHit bool, costUSD float64, ok bool) {
+	if a == nil {
+		return false, 0, false
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-a.done:
+		return a.cacheHit, a.costUSD, true
+	case <-timer.C:
+		return false, 0, false
+	}
+}
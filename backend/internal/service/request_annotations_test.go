@@ -0,0 +1,36 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestAnnotations_SetUsageOnce(t *testing.T) {
+	a := NewRequestAnnotations()
+	ctx := WithRequestAnnotations(context.Background(), a)
+	require.Same(t, a, RequestAnnotationsFromContext(ctx))
+
+	_, _, ok := a.WaitUsage(time.Millisecond)
+	require.False(t, ok)
+
+	RequestAnnotationsFromContext(ctx).SetUsage(true, 0.0125)
+	RequestAnnotationsFromContext(ctx).SetUsage(false, 9)
+	cacheHit, cost, ok := a.WaitUsage(time.Millisecond)
+	require.True(t, ok)
+	require.True(t, cacheHit)
+	require.Equal(t, 0.0125, cost)
+}
+
+func TestRequestAnnotations_NilSafe(t *testing.T) {
+	ctx := WithRequestAnnotations(context.Background(), nil)
+	require.Nil(t, RequestAnnotationsFromContext(ctx))
+
+	RequestAnnotationsFromContext(ctx).SetUsage(true, 1)
+	_, _, ok := RequestAnnotationsFromContext(ctx).WaitUsage(time.Millisecond)
+	require.False(t, ok)
+}
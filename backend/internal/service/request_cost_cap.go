@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+
+	"github.com/tidwall/gjson"
+)
+
+var (
+	ErrInvalidMaxRequestCost = infraerrors.BadRequest("INVALID_MAX_REQUEST_COST", "max_request_cost must be greater than or equal to 0")
+
+	// ErrRequestCostUnbounded 请求未给出输出上限且模型的最大输出未知，无法估算费用上界
+	ErrRequestCostUnbounded = errors.New("request cost ceiling is unbounded")
+)
+
+// requestCostInlineDataMinLen 超过该长度且形似内联媒体（data: URL / base64 字段）的字符串按固定 token 估算，
+// 避免把图片 base64 当作文本放大输入估算。
+const requestCostInlineDataMinLen = 1024
+
+// EffectiveMaxRequestCost 返回 Key 与分组单请求费用上限中较小的非零值；0 表示不限制。
+func EffectiveMaxRequestCost(apiKey *APIKey) float64 {
+	if apiKey == nil {
+		return 0
+	}
+	limit := apiKey.MaxRequestCost
+	if apiKey.Group != nil && apiKey.Group.MaxRequestCost > 0 && (limit <= 0 || apiKey.Group.MaxRequestCost < limit) {
+		limit = apiKey.Group.MaxRequestCost
+	}
+	return math.Max(limit, 0)
+}
+
+// RequestCostEstimate 转发前估算的单请求费用上界
+type RequestCostEstimate struct {
+	Model           string
+	InputTokens     int
+	MaxOutputTokens int
+	// Cost 按倍率折算后的费用上界（美元）
+	Cost float64
+}
+
+// EstimateRequestCostCeiling 按 输入估算 + 输出上限 × 输出单价 估算请求费用上界（含长上下文加价与倍率）。
+// format 为 RequestValidationFormatForPath 的返回值；Gemini 原生请求的模型取自路径，由 pathModel 传入。
+// 模型无价格数据时返回 nil（交由计费环节处理）；未给出输出上限且模型最大输出未知时返回 ErrRequestCostUnbounded。
+func (s *BillingService) EstimateRequestCostCeiling(body []byte, format, pathModel string, rateMultiplier float64) (*RequestCostEstimate, error) {
+	root := gjson.ParseBytes(body)
+	model := strings.TrimSpace(root.Get("model").String())
+	var maxOutput gjson.Result
+	var inputFields []string
+	switch format {
+	case ResponseLanguageFormatAnthropic:
+		maxOutput = root.Get("max_tokens")
+		inputFields = []string{"system", "messages", "tools"}
+	case ResponseLanguageFormatChatCompletions:
+		maxOutput = root.Get("max_completion_tokens")
+		if !maxOutput.Exists() {
+			maxOutput = root.Get("max_tokens")
+		}
+		inputFields = []string{"messages", "tools"}
+	case ResponseLanguageFormatResponses:
+		maxOutput = root.Get("max_output_tokens")
+		inputFields = []string{"instructions", "input", "tools"}
+	case RequestValidationFormatGemini:
+		model = pathModel
+		maxOutput = root.Get("generationConfig.maxOutputTokens")
+		inputFields = []string{"systemInstruction", "contents", "tools"}
+	default:
+		return nil, nil
+	}
+	if model == "" {
+		return nil, nil
+	}
+
+	outputTokens := int(maxOutput.Int())
+	if outputTokens <= 0 {
+		outputTokens = s.modelMaxOutputTokens(model)
+		if outputTokens <= 0 {
+			return nil, ErrRequestCostUnbounded
+		}
+	}
+	inputTokens := 0
+	for _, field := range inputFields {
+		inputTokens += estimateRequestInputTokens(root.Get(field), "")
+	}
+
+	cost, err := s.CalculateCostWithServiceTier(model, UsageTokens{InputTokens: inputTokens, OutputTokens: outputTokens}, rateMultiplier, root.Get("service_tier").String())
+	if err != nil {
+		return nil, nil
+	}
+	return &RequestCostEstimate{
+		Model:           model,
+		InputTokens:     inputTokens,
+		MaxOutputTokens: outputTokens,
+		Cost:            cost.ActualCost,
+	}, nil
+}
+
+// modelMaxOutputTokens 返回价格数据中模型的单次输出上限；未知时返回 0。
+func (s *BillingService) modelMaxOutputTokens(model string) int {
+	if s.pricingService == nil {
+		return 0
+	}
+	if pricing := s.pricingService.GetModelPricing(model); pricing != nil {
+		return pricing.MaxOutputTokens
+	}
+	return 0
+}
+
+// estimateRequestInputTokens 粗略估算请求体中一段内容的输入 token 数：文本按字符估算，
+// 内联媒体按固定值估算，其余值递归累加。
+func estimateRequestInputTokens(value gjson.Result, key string) int {
+	switch {
+	case !value.Exists():
+		return 0
+	case value.Type == gjson.String:
+		text := value.String()
+		if len(text) >= requestCostInlineDataMinLen && isInlineMediaField(key, text) {
+			return conversationImageTokenEstimate
+		}
+		return estimateTokensForText(text)
+	case value.IsArray() || value.IsObject():
+		total := 0
+		value.ForEach(func(k, v gjson.Result) bool {
+			childKey := key
+			if value.IsObject() {
+				childKey = k.String()
+			}
+			total += estimateRequestInputTokens(v, childKey)
+			return true
+		})
+		return total
+	default:
+		return estimateTokensForText(value.Raw)
+	}
+}
+
+func isInlineMediaField(key, text string) bool {
+	if strings.HasPrefix(text, "data:") {
+		return true
+	}
+	switch key {
+	case "data", "image_url", "file_data", "b64_json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+//go:build unit
+
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveMaxRequestCost(t *testing.T) {
+	require.Zero(t, EffectiveMaxRequestCost(nil))
+	require.Zero(t, EffectiveMaxRequestCost(&APIKey{}))
+	require.Equal(t, 2.0, EffectiveMaxRequestCost(&APIKey{MaxRequestCost: 2}))
+	require.Equal(t, 1.0, EffectiveMaxRequestCost(&APIKey{Group: &Group{MaxRequestCost: 1}}))
+	require.Equal(t, 1.0, EffectiveMaxRequestCost(&APIKey{MaxRequestCost: 2, Group: &Group{MaxRequestCost: 1}}))
+	require.Equal(t, 0.5, EffectiveMaxRequestCost(&APIKey{MaxRequestCost: 0.5, Group: &Group{MaxRequestCost: 1}}))
+}
+
+func newRequestCostCapBilling() *BillingService {
+	cfg := &config.Config{}
+	pricing := NewPricingService(cfg, nil)
+	pricing.pricingData = map[string]*LiteLLMModelPricing{
+		"cap-test-model": {InputCostPerToken: 1e-6, OutputCostPerToken: 1e-5, MaxOutputTokens: 1000},
+	}
+	return NewBillingService(cfg, pricing)
+}
+
+func TestEstimateRequestCostCeiling_UsesMaxTokensAndMultiplier(t *testing.T) {
+	billing := newRequestCostCapBilling()
+	body := []byte(`{"model":"cap-test-model","max_completion_tokens":200,"max_tokens":999,"messages":[{"role":"user","content":"hello"}]}`)
+
+	estimate, err := billing.EstimateRequestCostCeiling(body, ResponseLanguageFormatChatCompletions, "", 2)
+	require.NoError(t, err)
+	require.NotNil(t, estimate)
+	require.Equal(t, 200, estimate.MaxOutputTokens)
+	require.Positive(t, estimate.InputTokens)
+	require.InDelta(t, 2*(float64(estimate.InputTokens)*1e-6+200*1e-5), estimate.Cost, 1e-12)
+}
+
+func TestEstimateRequestCostCeiling_FallsBackToModelMaxOutput(t *testing.T) {
+	billing := newRequestCostCapBilling()
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`)
+
+	estimate, err := billing.EstimateRequestCostCeiling(body, RequestValidationFormatGemini, "cap-test-model", 1)
+	require.NoError(t, err)
+	require.Equal(t, 1000, estimate.MaxOutputTokens)
+
+	_, err = billing.EstimateRequestCostCeiling([]byte(`{"model":"claude-sonnet-4","messages":[]}`), ResponseLanguageFormatAnthropic, "", 1)
+	require.ErrorIs(t, err, ErrRequestCostUnbounded)
+}
+
+func TestEstimateRequestInputTokens_InlineMediaUsesFixedEstimate(t *testing.T) {
+	image := "data:image/png;base64," + strings.Repeat("A", 200000)
+	body := []byte(`{"model":"cap-test-model","max_output_tokens":10,"input":[{"role":"user","content":[{"type":"input_image","image_url":"` + image + `"}]}]}`)
+
+	estimate, err := newRequestCostCapBilling().EstimateRequestCostCeiling(body, ResponseLanguageFormatResponses, "", 1)
+	require.NoError(t, err)
+	require.Less(t, estimate.InputTokens, conversationImageTokenEstimate+100)
+}
@@ -18,6 +18,7 @@ type RequestMetadata struct {
 	PrefetchedStickyGroupID    *int64
 	SingleAccountRetry         *bool
 	AccountSwitchCount         *int
+	AgentSession               *bool
 }
 
 var (
@@ -116,6 +117,14 @@ func WithAccountSwitchCount(ctx context.Context, value int, bridgeOldKeys bool)
 	})
 }
 
+// WithAgentSession 标记当前请求是否属于工具调用型 Agent 会话（无旧版 ctxkey 桥接）。
+func WithAgentSession(ctx context.Context, value bool) context.Context {
+	return updateRequestMetadata(ctx, false, func(md *RequestMetadata) {
+		v := value
+		md.AgentSession = &v
+	}, nil)
+}
+
 func IsMaxTokensOneHaikuRequestFromContext(ctx context.Context) (bool, bool) {
 	if md := metadataFromContext(ctx); md != nil && md.IsMaxTokensOneHaikuRequest != nil {
 		return *md.IsMaxTokensOneHaikuRequest, true
@@ -214,3 +223,11 @@ func AccountSwitchCountFromContext(ctx context.Context) (int, bool) {
 	}
 	return 0, false
 }
+
+// AgentSessionFromContext 返回当前请求是否被标记为 Agent 会话。
+func AgentSessionFromContext(ctx context.Context) bool {
+	if md := metadataFromContext(ctx); md != nil && md.AgentSession != nil {
+		return *md.AgentSession
+	}
+	return false
+}
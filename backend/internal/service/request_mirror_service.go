@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/requestsign"
+)
+
+// requestMirrorFailureLogInterval 投递失败日志的最小间隔，避免外部端点故障时刷屏
+const requestMirrorFailureLogInterval = 30 * time.Second
+
+// RequestMirrorRecord 镜像到外部端点的单条记录。
+// 只包含脱敏后的元数据：不含 API Key 明文、请求头与客户端 IP；正文仅在 include_bodies 开启时附带。
+type RequestMirrorRecord struct {
+	RequestID  string    `json:"request_id,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Method     string    `json:"method"`
+	Endpoint   string    `json:"endpoint"`
+	Platform   string    `json:"platform,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Stream     bool      `json:"stream"`
+	StatusCode int       `json:"status_code"`
+	DurationMs int64     `json:"duration_ms"`
+	UserID     int64     `json:"user_id,omitempty"`
+	APIKeyID   int64     `json:"api_key_id,omitempty"`
+	GroupID    *int64    `json:"group_id,omitempty"`
+	AccountID  int64     `json:"account_id,omitempty"`
+
+	RequestBody       string `json:"request_body,omitempty"`
+	RequestTruncated  bool   `json:"request_truncated,omitempty"`
+	ResponseBody      string `json:"response_body,omitempty"`
+	ResponseTruncated bool   `json:"response_truncated,omitempty"`
+}
+
+// RequestMirrorService 将采样后的请求记录异步投递到外部分析端点（如评测 / 护栏服务）。
+// 投递走独立的有界 worker 池（溢出策略固定为 drop），不会与使用量记录争用容量，也不会阻塞请求路径。
+type RequestMirrorService struct {
+	cfg    *config.Config
+	pool   *UsageRecordWorkerPool
+	client *http.Client
+	sample func() float64
+
+	sentCount       atomic.Uint64
+	failedCount     atomic.Uint64
+	lastFailureLogN atomic.Int64
+}
+
+// NewRequestMirrorService 创建镜像服务；未启用时不创建 worker 池。
+func NewRequestMirrorService(cfg *config.Config) *RequestMirrorService {
+	s := &RequestMirrorService{cfg: cfg, sample: rand.Float64}
+	if cfg == nil || !cfg.Gateway.RequestMirror.Enabled {
+		return s
+	}
+	mirrorCfg := cfg.Gateway.RequestMirror
+	timeout := time.Duration(mirrorCfg.TimeoutSeconds) * time.Second
+	s.client = &http.Client{Timeout: timeout}
+	s.pool = NewUsageRecordWorkerPoolWithOptions(UsageRecordWorkerPoolOptions{
+		WorkerCount:      mirrorCfg.WorkerCount,
+		QueueSize:        mirrorCfg.QueueSize,
+		TaskTimeout:      timeout,
+		OverflowPolicy:   config.UsageRecordOverflowPolicyDrop,
+		AutoScaleEnabled: false,
+	})
+	return s
+}
+
+// Enabled 镜像是否启用
+func (s *RequestMirrorService) Enabled() bool {
+	return s != nil && s.pool != nil
+}
+
+// ShouldSample 按 sample_rate 决定本次请求是否镜像
+func (s *RequestMirrorService) ShouldSample() bool {
+	if !s.Enabled() {
+		return false
+	}
+	rate := s.cfg.Gateway.RequestMirror.SampleRate
+	if rate >= 1 {
+		return true
+	}
+	return s.sample() < rate
+}
+
+// IncludeBodies 是否附带正文
+func (s *RequestMirrorService) IncludeBodies() bool {
+	return s.Enabled() && s.cfg.Gateway.RequestMirror.IncludeBodies
+}
+
+// MaxBodyBytes 单个正文的附带上限
+func (s *RequestMirrorService) MaxBodyBytes() int {
+	if !s.Enabled() {
+		return 0
+	}
+	return s.cfg.Gateway.RequestMirror.MaxBodyBytes
+}
+
+// Submit 非阻塞提交一条镜像记录；队列满时丢弃。
+func (s *RequestMirrorService) Submit(record *RequestMirrorRecord) {
+	if !s.Enabled() || record == nil {
+		return
+	}
+	s.pool.Submit(func(ctx context.Context) {
+		if err := s.send(ctx, record); err != nil {
+			s.failedCount.Add(1)
+			s.logFailure(err)
+			return
+		}
+		s.sentCount.Add(1)
+	})
+}
+
+// Stop 停止 worker 池（等待已入队任务完成）
+func (s *RequestMirrorService) Stop() {
+	if s == nil || s.pool == nil {
+		return
+	}
+	s.pool.Stop()
+}
+
+func (s *RequestMirrorService) send(ctx context.Context, record *RequestMirrorRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal mirror record: %w", err)
+	}
+	mirrorCfg := s.cfg.Gateway.RequestMirror
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSpace(mirrorCfg.Endpoint), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build mirror request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := strings.TrimSpace(mirrorCfg.AuthToken); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if secret := mirrorCfg.SigningSecret; secret != "" {
+		requestsign.Sign(req, secret, payload, time.Now())
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("mirror endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *RequestMirrorService) logFailure(err error) {
+	now := time.Now().UnixNano()
+	last := s.lastFailureLogN.Load()
+	if now-last < int64(requestMirrorFailureLogInterval) || !s.lastFailureLogN.CompareAndSwap(last, now) {
+		return
+	}
+	logger.LegacyPrintf("service.request_mirror", "[RequestMirror] delivery failed: sent=%d failed=%d err=%v",
+		s.sentCount.Load(), s.failedCount.Load(), err)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/requestsign"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestMirrorTestConfig(endpoint string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Gateway.RequestMirror = config.GatewayRequestMirrorConfig{
+		Enabled:        true,
+		Endpoint:       endpoint,
+		AuthToken:      "mirror-secret",
+		SampleRate:     0.25,
+		MaxBodyBytes:   64,
+		TimeoutSeconds: 2,
+		WorkerCount:    1,
+		QueueSize:      4,
+	}
+	return cfg
+}
+
+func TestRequestMirrorService_DisabledIsNoop(t *testing.T) {
+	svc := NewRequestMirrorService(&config.Config{})
+	require.False(t, svc.Enabled())
+	require.False(t, svc.ShouldSample())
+	svc.Submit(&RequestMirrorRecord{})
+	svc.Stop()
+
+	var nilSvc *RequestMirrorService
+	require.False(t, nilSvc.ShouldSample())
+	nilSvc.Stop()
+}
+
+func TestRequestMirrorService_ShouldSample(t *testing.T) {
+	svc := NewRequestMirrorService(newRequestMirrorTestConfig("http://127.0.0.1:1/mirror"))
+	defer svc.Stop()
+
+	svc.sample = func() float64 { return 0.1 }
+	require.True(t, svc.ShouldSample())
+	svc.sample = func() float64 { return 0.5 }
+	require.False(t, svc.ShouldSample())
+}
+
+func TestRequestMirrorService_SubmitPostsRecord(t *testing.T) {
+	received := make(chan RequestMirrorRecord, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "Bearer mirror-secret", r.Header.Get("Authorization"))
+		var record RequestMirrorRecord
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+		received <- record
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	svc := NewRequestMirrorService(newRequestMirrorTestConfig(srv.URL))
+	svc.Submit(&RequestMirrorRecord{RequestID: "req-1", Endpoint: "/v1/messages", Model: "claude-sonnet-4-5", StatusCode: 200})
+
+	select {
+	case record := <-received:
+		require.Equal(t, "req-1", record.RequestID)
+		require.Equal(t, "claude-sonnet-4-5", record.Model)
+		require.Empty(t, record.RequestBody)
+	case <-time.After(2 * time.Second):
+		t.Fatal("mirror record was not delivered")
+	}
+	svc.Stop()
+	require.Equal(t, uint64(1), svc.sentCount.Load())
+}
+
+func TestRequestMirrorService_SignsDeliveryWhenSecretSet(t *testing.T) {
+	verified := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified <- requestsign.Verify(r.Header, body, time.Now(), time.Minute, "hmac-secret")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	cfg := newRequestMirrorTestConfig(srv.URL)
+	cfg.Gateway.RequestMirror.SigningSecret = "hmac-secret"
+	svc := NewRequestMirrorService(cfg)
+	svc.Submit(&RequestMirrorRecord{RequestID: "req-signed", StatusCode: 200})
+
+	select {
+	case err := <-verified:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("mirror record was not delivered")
+	}
+	svc.Stop()
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// RequestPriorityHeader 客户端声明请求优先级的请求头（low / normal / high）。
+// 仅对管理员设置了 max_priority 的 Key 生效，且不会超过该上限；其它 Key 一律按 normal 处理。
+const RequestPriorityHeader = "X-Priority"
+
+// 请求优先级
+const (
+	RequestPriorityLow    = "low"
+	RequestPriorityNormal = "normal"
+	RequestPriorityHigh   = "high"
+)
+
+// RequestPriorities 全部优先级，按从低到高排列（指标导出按此顺序）。
+var RequestPriorities = []string{RequestPriorityLow, RequestPriorityNormal, RequestPriorityHigh}
+
+const (
+	// requestPriorityHighRetryFactor high 优先级的 failover 账号切换预算倍数
+	requestPriorityHighRetryFactor = 1.5
+	// requestPriorityLowRetryFactor low 优先级的 failover 账号切换预算倍数（至少保留 1 次）
+	requestPriorityLowRetryFactor = 0.5
+	// requestPriorityLowShedFactor 自适应卸载时 low 优先级的拒绝比例倍数；high 优先级不参与自适应卸载
+	requestPriorityLowShedFactor = 2.0
+)
+
+var ErrInvalidRequestPriority = infraerrors.BadRequest("INVALID_REQUEST_PRIORITY", "priority must be one of low, normal, high")
+
+// NormalizeRequestPriority 校验并规范化优先级；空字符串原样返回（表示未设置）。
+func NormalizeRequestPriority(priority string) (string, error) {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if priority == "" || requestPriorityRank(priority) >= 0 {
+		return priority, nil
+	}
+	return "", ErrInvalidRequestPriority
+}
+
+// ResolveRequestPriority 根据 X-Priority 请求头与 Key 的 max_priority 计算生效优先级。
+// Key 未设置 max_priority（不受信任）、请求头缺失或无法识别时返回 normal；高于上限时降到上限。
+func ResolveRequestPriority(header string, apiKey *APIKey) string {
+	if apiKey == nil || apiKey.MaxPriority == "" {
+		return RequestPriorityNormal
+	}
+	requested := strings.ToLower(strings.TrimSpace(header))
+	rank := requestPriorityRank(requested)
+	if rank < 0 {
+		return RequestPriorityNormal
+	}
+	if maxRank := requestPriorityRank(apiKey.MaxPriority); maxRank >= 0 && rank > maxRank {
+		return apiKey.MaxPriority
+	}
+	return requested
+}
+
+// RequestPriorityRetryBudget 按优先级调整 failover 账号切换次数：high 放宽，low 收紧但至少 1 次。
+func RequestPriorityRetryBudget(base int, priority string) int {
+	if base <= 0 {
+		return base
+	}
+	switch priority {
+	case RequestPriorityHigh:
+		return int(float64(base)*requestPriorityHighRetryFactor + 0.5)
+	case RequestPriorityLow:
+		return max(1, int(float64(base)*requestPriorityLowRetryFactor))
+	}
+	return base
+}
+
+// requestPriorityShedProbability 按优先级调整自适应卸载的拒绝比例。
+func requestPriorityShedProbability(probability float64, priority string) float64 {
+	switch priority {
+	case RequestPriorityHigh:
+		return 0
+	case RequestPriorityLow:
+		return min(admissionMaxShedProbability, probability*requestPriorityLowShedFactor)
+	}
+	return probability
+}
+
+func requestPriorityRank(priority string) int {
+	for i, p := range RequestPriorities {
+		if p == priority {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,89 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRequestPriority(t *testing.T) {
+	trusted := &APIKey{MaxPriority: RequestPriorityHigh}
+	capped := &APIKey{MaxPriority: RequestPriorityNormal}
+
+	cases := []struct {
+		name   string
+		header string
+		key    *APIKey
+		want   string
+	}{
+		{"untrusted key ignores header", "high", &APIKey{}, RequestPriorityNormal},
+		{"nil key", "high", nil, RequestPriorityNormal},
+		{"trusted high", " HIGH ", trusted, RequestPriorityHigh},
+		{"trusted low", "low", trusted, RequestPriorityLow},
+		{"missing header", "", trusted, RequestPriorityNormal},
+		{"unknown value", "urgent", trusted, RequestPriorityNormal},
+		{"clamped to max", "high", capped, RequestPriorityNormal},
+		{"below max kept", "low", capped, RequestPriorityLow},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, ResolveRequestPriority(tc.header, tc.key))
+		})
+	}
+}
+
+func TestNormalizeRequestPriority(t *testing.T) {
+	got, err := NormalizeRequestPriority(" High ")
+	require.NoError(t, err)
+	require.Equal(t, RequestPriorityHigh, got)
+
+	got, err = NormalizeRequestPriority("")
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = NormalizeRequestPriority("critical")
+	require.ErrorIs(t, err, ErrInvalidRequestPriority)
+}
+
+func TestRequestPriorityRetryBudget(t *testing.T) {
+	require.Equal(t, 10, RequestPriorityRetryBudget(10, RequestPriorityNormal))
+	require.Equal(t, 15, RequestPriorityRetryBudget(10, RequestPriorityHigh))
+	require.Equal(t, 5, RequestPriorityRetryBudget(10, RequestPriorityLow))
+	require.Equal(t, 1, RequestPriorityRetryBudget(1, RequestPriorityLow), "low keeps at least one switch")
+	require.Equal(t, 2, RequestPriorityRetryBudget(1, RequestPriorityHigh))
+	require.Zero(t, RequestPriorityRetryBudget(0, RequestPriorityHigh))
+}
+
+func TestActiveRequestRegistry_SlotWaitsByPriority(t *testing.T) {
+	registry := NewActiveRequestRegistry(0)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+
+	recordWait := func(priority string, d time.Duration) {
+		req, _ := registry.Begin(context.Background(), ActiveRequestInfo{Priority: priority})
+		done := req.BeginWait()
+		now = now.Add(d)
+		done()
+		registry.End(req)
+	}
+	recordWait(RequestPriorityHigh, 100*time.Millisecond)
+	recordWait(RequestPriorityLow, 3*time.Second)
+	recordWait("", time.Second)
+
+	since := now.Add(-time.Minute)
+	p95, samples := registry.SlotWaitQuantileByPriority(RequestPriorityHigh, since, 0.95)
+	require.Equal(t, 100*time.Millisecond, p95)
+	require.Equal(t, 1, samples)
+	p95, _ = registry.SlotWaitQuantileByPriority(RequestPriorityNormal, since, 0.95)
+	require.Equal(t, time.Second, p95, "unset priority counts as normal")
+	_, samples = registry.SlotWaitQuantile(since, 0.95)
+	require.Equal(t, 3, samples)
+
+	require.Equal(t, map[string]int64{
+		RequestPriorityLow: 1, RequestPriorityNormal: 1, RequestPriorityHigh: 1,
+	}, registry.RequestsByPriority())
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// RequestValidationFormatGemini Gemini 原生 generateContent / streamGenerateContent 请求体。
+// 其余格式沿用 ResponseLanguageFormat* 常量。
+const RequestValidationFormatGemini = "gemini"
+
+// requestValidationMaxErrors 单次返回的字段错误上限，避免畸形大请求生成超长错误响应。
+const requestValidationMaxErrors = 20
+
+// RequestFieldError 一条字段级校验错误。Path 为 gjson 风格的点分路径（数组下标为数字段）。
+type RequestFieldError struct {
+	Path     string `json:"path"`
+	Expected string `json:"expected,omitempty"`
+	Message  string `json:"message"`
+}
+
+// RequestValidationFormatForPath 按入站路径判断需要校验的请求体格式；不支持的端点返回空字符串。
+func RequestValidationFormatForPath(path string) string {
+	if strings.HasSuffix(path, ":generateContent") || strings.HasSuffix(path, ":streamGenerateContent") {
+		return RequestValidationFormatGemini
+	}
+	return ResponseLanguageFormatForPath(path)
+}
+
+// reqSchema 请求体字段的最小 schema：只描述网关关心的结构与类型，未声明的字段一律放行，
+// 以免上游新增参数时误拒合法请求。
+type reqSchema struct {
+	// types 允许的 JSON 类型：object / array / string / number / integer / boolean / null
+	types    []string
+	required []string
+	props    map[string]*reqSchema
+	items    *reqSchema
+	minItems int
+	enum     []string
+	min      *float64
+	max      *float64
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+var (
+	reqString  = &reqSchema{types: []string{"string"}}
+	reqBool    = &reqSchema{types: []string{"boolean"}}
+	reqObject  = &reqSchema{types: []string{"object"}}
+	reqArray   = &reqSchema{types: []string{"array"}}
+	reqInteger = &reqSchema{types: []string{"integer"}}
+	reqNumber  = &reqSchema{types: []string{"number"}}
+
+	reqStringList = &reqSchema{types: []string{"array"}, items: reqString}
+	reqTypedBlock = &reqSchema{types: []string{"object"}, required: []string{"type"}, props: map[string]*reqSchema{"type": reqString}}
+)
+
+func reqNumberRange(min, max float64) *reqSchema {
+	return &reqSchema{types: []string{"number"}, min: floatPtr(min), max: floatPtr(max)}
+}
+
+func reqIntegerMin(min float64) *reqSchema {
+	return &reqSchema{types: []string{"integer"}, min: floatPtr(min)}
+}
+
+var anthropicMessagesSchema = &reqSchema{
+	types:    []string{"object"},
+	required: []string{"model", "max_tokens", "messages"},
+	props: map[string]*reqSchema{
+		"model":      reqString,
+		"max_tokens": reqIntegerMin(1),
+		"messages": {
+			types:    []string{"array"},
+			minItems: 1,
+			items: &reqSchema{
+				types:    []string{"object"},
+				required: []string{"role", "content"},
+				props: map[string]*reqSchema{
+					"role":    {types: []string{"string"}, enum: []string{"user", "assistant"}},
+					"content": {types: []string{"string", "array"}, items: reqTypedBlock},
+				},
+			},
+		},
+		"system":         {types: []string{"string", "array"}, items: reqTypedBlock},
+		"temperature":    reqNumberRange(0, 1),
+		"top_p":          reqNumberRange(0, 1),
+		"top_k":          reqIntegerMin(0),
+		"stream":         reqBool,
+		"stop_sequences": reqStringList,
+		"metadata":       reqObject,
+		"thinking":       reqTypedBlock,
+		"tool_choice":    reqTypedBlock,
+		"tools": {
+			types: []string{"array"},
+			items: &reqSchema{types: []string{"object"}, required: []string{"name"}, props: map[string]*reqSchema{"name": reqString}},
+		},
+	},
+}
+
+var chatCompletionsSchema = &reqSchema{
+	types:    []string{"object"},
+	required: []string{"model", "messages"},
+	props: map[string]*reqSchema{
+		"model": reqString,
+		"messages": {
+			types:    []string{"array"},
+			minItems: 1,
+			items: &reqSchema{
+				types:    []string{"object"},
+				required: []string{"role"},
+				props: map[string]*reqSchema{
+					"role":    {types: []string{"string"}, enum: []string{"system", "developer", "user", "assistant", "tool", "function"}},
+					"content": {types: []string{"string", "array", "null"}, items: reqTypedBlock},
+				},
+			},
+		},
+		"stream":                reqBool,
+		"stream_options":        reqObject,
+		"temperature":           reqNumberRange(0, 2),
+		"top_p":                 reqNumberRange(0, 1),
+		"n":                     reqIntegerMin(1),
+		"max_tokens":            reqIntegerMin(1),
+		"max_completion_tokens": reqIntegerMin(1),
+		"stop":                  {types: []string{"string", "array", "null"}, items: reqString},
+		"response_format":       reqTypedBlock,
+		"tools": {
+			types: []string{"array"},
+			items: &reqSchema{
+				types:    []string{"object"},
+				required: []string{"type"},
+				props: map[string]*reqSchema{
+					"type":     reqString,
+					"function": {types: []string{"object"}, required: []string{"name"}, props: map[string]*reqSchema{"name": reqString}},
+				},
+			},
+		},
+	},
+}
+
+var responsesSchema = &reqSchema{
+	types:    []string{"object"},
+	required: []string{"model"},
+	props: map[string]*reqSchema{
+		"model":             reqString,
+		"input":             {types: []string{"string", "array"}, items: reqObject},
+		"instructions":      {types: []string{"string", "null"}},
+		"stream":            reqBool,
+		"temperature":       reqNumberRange(0, 2),
+		"top_p":             reqNumberRange(0, 1),
+		"max_output_tokens": reqIntegerMin(1),
+		"reasoning":         reqObject,
+		"text":              reqObject,
+		"tools":             {types: []string{"array"}, items: reqTypedBlock},
+	},
+}
+
+var geminiGenerateContentSchema = &reqSchema{
+	types:    []string{"object"},
+	required: []string{"contents"},
+	props: map[string]*reqSchema{
+		"contents": {
+			types:    []string{"array"},
+			minItems: 1,
+			items: &reqSchema{
+				types:    []string{"object"},
+				required: []string{"parts"},
+				props: map[string]*reqSchema{
+					"role":  {types: []string{"string"}, enum: []string{"user", "model", "function"}},
+					"parts": {types: []string{"array"}, items: reqObject},
+				},
+			},
+		},
+		"systemInstruction": reqObject,
+		"generationConfig": {
+			types: []string{"object"},
+			props: map[string]*reqSchema{
+				"temperature":     reqNumberRange(0, 2),
+				"topP":            reqNumberRange(0, 1),
+				"topK":            reqIntegerMin(0),
+				"candidateCount":  reqIntegerMin(1),
+				"maxOutputTokens": reqIntegerMin(1),
+				"stopSequences":   reqStringList,
+			},
+		},
+		"tools":          {types: []string{"array"}, items: reqObject},
+		"safetySettings": {types: []string{"array"}, items: reqObject},
+	},
+}
+
+func requestSchemaForFormat(format string) *reqSchema {
+	switch format {
+	case ResponseLanguageFormatAnthropic:
+		return anthropicMessagesSchema
+	case ResponseLanguageFormatChatCompletions:
+		return chatCompletionsSchema
+	case ResponseLanguageFormatResponses:
+		return responsesSchema
+	case RequestValidationFormatGemini:
+		return geminiGenerateContentSchema
+	default:
+		return nil
+	}
+}
+
+// ValidateRequestBody 按端点 schema 校验请求体，返回字段级错误（最多 requestValidationMaxErrors 条）；
+// 不支持的格式或校验通过时返回 nil。
+func ValidateRequestBody(body []byte, format string) []RequestFieldError {
+	schema := requestSchemaForFormat(format)
+	if schema == nil {
+		return nil
+	}
+	if !gjson.ValidBytes(body) {
+		return []RequestFieldError{{Path: "", Expected: "object", Message: "request body is not valid JSON"}}
+	}
+	v := &requestValidator{}
+	v.check("", gjson.ParseBytes(body), schema)
+	return v.errs
+}
+
+type requestValidator struct {
+	errs []RequestFieldError
+}
+
+func (v *requestValidator) add(path, expected, message string) {
+	if len(v.errs) >= requestValidationMaxErrors {
+		return
+	}
+	v.errs = append(v.errs, RequestFieldError{Path: path, Expected: expected, Message: message})
+}
+
+func (v *requestValidator) check(path string, value gjson.Result, schema *reqSchema) {
+	if len(v.errs) >= requestValidationMaxErrors {
+		return
+	}
+	actual := jsonTypeOf(value)
+	if !schemaAllowsType(schema.types, actual) {
+		expected := strings.Join(schema.types, " | ")
+		v.add(path, expected, fmt.Sprintf("expected %s, got %s", expected, actual))
+		return
+	}
+	switch actual {
+	case "object":
+		for _, key := range schema.required {
+			if !value.Get(gjsonEscapeKey(key)).Exists() {
+				v.add(joinRequestPath(path, key), strings.Join(schema.props[key].typesOrAny(), " | "), "field is required")
+			}
+		}
+		keys := make([]string, 0, len(schema.props))
+		for key := range schema.props {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if field := value.Get(gjsonEscapeKey(key)); field.Exists() {
+				v.check(joinRequestPath(path, key), field, schema.props[key])
+			}
+		}
+	case "array":
+		items := value.Array()
+		if len(items) < schema.minItems {
+			v.add(path, "array", fmt.Sprintf("must contain at least %d item(s)", schema.minItems))
+		}
+		if schema.items != nil {
+			for i, item := range items {
+				v.check(joinRequestPath(path, strconv.Itoa(i)), item, schema.items)
+			}
+		}
+	case "string":
+		if len(schema.enum) > 0 && !containsString(schema.enum, value.String()) {
+			v.add(path, strings.Join(schema.enum, " | "), fmt.Sprintf("must be one of %s, got %q", strings.Join(schema.enum, ", "), value.String()))
+		}
+	case "number", "integer":
+		n := value.Float()
+		if schema.min != nil && n < *schema.min {
+			v.add(path, strings.Join(schema.types, " | "), fmt.Sprintf("must be >= %s", strconv.FormatFloat(*schema.min, 'f', -1, 64)))
+		}
+		if schema.max != nil && n > *schema.max {
+			v.add(path, strings.Join(schema.types, " | "), fmt.Sprintf("must be <= %s", strconv.FormatFloat(*schema.max, 'f', -1, 64)))
+		}
+	}
+}
+
+func (s *reqSchema) typesOrAny() []string {
+	if s == nil || len(s.types) == 0 {
+		return []string{"any"}
+	}
+	return s.types
+}
+
+// jsonTypeOf 返回值的 JSON 类型；整数值报告为 integer（number schema 同样接受）。
+func jsonTypeOf(value gjson.Result) string {
+	switch value.Type {
+	case gjson.Null:
+		return "null"
+	case gjson.True, gjson.False:
+		return "boolean"
+	case gjson.String:
+		return "string"
+	case gjson.Number:
+		if !strings.ContainsAny(value.Raw, ".eE") {
+			return "integer"
+		}
+		return "number"
+	default:
+		if value.IsArray() {
+			return "array"
+		}
+		return "object"
+	}
+}
+
+func schemaAllowsType(types []string, actual string) bool {
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func joinRequestPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func gjsonEscapeKey(key string) string {
+	replacer := strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`)
+	return replacer.Replace(key)
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
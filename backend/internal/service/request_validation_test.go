@@ -0,0 +1,57 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestValidationFormatForPath(t *testing.T) {
+	require.Equal(t, ResponseLanguageFormatAnthropic, RequestValidationFormatForPath("/v1/messages"))
+	require.Equal(t, ResponseLanguageFormatChatCompletions, RequestValidationFormatForPath("/v1/chat/completions"))
+	require.Equal(t, RequestValidationFormatGemini, RequestValidationFormatForPath("/v1beta/models/gemini-2.5-pro:streamGenerateContent"))
+	require.Empty(t, RequestValidationFormatForPath("/v1beta/models/gemini-2.5-pro:countTokens"))
+}
+
+func TestValidateRequestBody_ValidBodies(t *testing.T) {
+	cases := map[string]string{
+		ResponseLanguageFormatAnthropic:       `{"model":"claude","max_tokens":1024,"messages":[{"role":"user","content":[{"type":"text","text":"hi"}]}],"temperature":0.5,"unknown_field":1}`,
+		ResponseLanguageFormatChatCompletions: `{"model":"gpt","messages":[{"role":"assistant","content":null,"tool_calls":[]}],"stop":"x","temperature":1}`,
+		ResponseLanguageFormatResponses:       `{"model":"gpt","input":"hi","max_output_tokens":10}`,
+		RequestValidationFormatGemini:         `{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"maxOutputTokens":8}}`,
+	}
+	for format, body := range cases {
+		require.Empty(t, ValidateRequestBody([]byte(body), format), format)
+	}
+	require.Nil(t, ValidateRequestBody([]byte(`not json`), ""))
+}
+
+func TestValidateRequestBody_FieldErrors(t *testing.T) {
+	body := `{"model":7,"max_tokens":1.5,"messages":[{"role":"system","content":"x"},{"content":[{"text":"no type"}]}],"temperature":3}`
+	errs := ValidateRequestBody([]byte(body), ResponseLanguageFormatAnthropic)
+	require.Equal(t, []RequestFieldError{
+		{Path: "max_tokens", Expected: "integer", Message: "expected integer, got number"},
+		{Path: "messages.0.role", Expected: "user | assistant", Message: `must be one of user, assistant, got "system"`},
+		{Path: "messages.1.role", Expected: "string", Message: "field is required"},
+		{Path: "messages.1.content.0.type", Expected: "string", Message: "field is required"},
+		{Path: "model", Expected: "string", Message: "expected string, got integer"},
+		{Path: "temperature", Expected: "number", Message: "must be <= 1"},
+	}, errs)
+}
+
+func TestValidateRequestBody_MissingAndInvalid(t *testing.T) {
+	errs := ValidateRequestBody([]byte(`{"messages":[]}`), ResponseLanguageFormatChatCompletions)
+	require.Equal(t, []RequestFieldError{
+		{Path: "model", Expected: "string", Message: "field is required"},
+		{Path: "messages", Expected: "array", Message: "must contain at least 1 item(s)"},
+	}, errs)
+
+	errs = ValidateRequestBody([]byte(`{"contents":[`), RequestValidationFormatGemini)
+	require.Len(t, errs, 1)
+	require.Equal(t, "request body is not valid JSON", errs[0].Message)
+
+	errs = ValidateRequestBody([]byte(`[]`), ResponseLanguageFormatResponses)
+	require.Equal(t, "expected object, got array", errs[0].Message)
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ResponseLanguageOff API Key 专用：对该 Key 关闭所在分组的强制回复语言。
+const ResponseLanguageOff = "off"
+
+// 请求体格式（决定语言指令写入的位置）
+const (
+	ResponseLanguageFormatAnthropic       = "anthropic"        // /v1/messages：system
+	ResponseLanguageFormatChatCompletions = "chat_completions" // /v1/chat/completions：system / developer 消息
+	ResponseLanguageFormatResponses       = "responses"        // /v1/responses：instructions
+)
+
+// responseLanguage 可选的强制回复语言。
+type responseLanguage struct {
+	Code        string
+	Instruction string
+}
+
+// responseLanguages 后台下拉框可选的语言，按展示顺序排列。
+var responseLanguages = []responseLanguage{
+	{Code: "zh", Instruction: "Respond in Simplified Chinese (简体中文)."},
+	{Code: "zh-tw", Instruction: "Respond in Traditional Chinese (繁體中文)."},
+	{Code: "en", Instruction: "Respond in English."},
+	{Code: "ja", Instruction: "Respond in Japanese (日本語)."},
+	{Code: "ko", Instruction: "Respond in Korean (한국어)."},
+	{Code: "fr", Instruction: "Respond in French (Français)."},
+	{Code: "de", Instruction: "Respond in German (Deutsch)."},
+	{Code: "es", Instruction: "Respond in Spanish (Español)."},
+	{Code: "pt", Instruction: "Respond in Portuguese (Português)."},
+	{Code: "ru", Instruction: "Respond in Russian (Русский)."},
+}
+
+var ErrInvalidResponseLanguage = infraerrors.BadRequest("INVALID_RESPONSE_LANGUAGE", "response_language must be empty or one of: "+strings.Join(ResponseLanguageCodes(), ", "))
+
+// ResponseLanguageCodes 返回支持的语言代码。
+func ResponseLanguageCodes() []string {
+	codes := make([]string, 0, len(responseLanguages))
+	for _, lang := range responseLanguages {
+		codes = append(codes, lang.Code)
+	}
+	return codes
+}
+
+func lookupResponseLanguage(code string) (responseLanguage, bool) {
+	for _, lang := range responseLanguages {
+		if lang.Code == code {
+			return lang, true
+		}
+	}
+	return responseLanguage{}, false
+}
+
+// NormalizeResponseLanguage 规范化强制回复语言；allowOff 为 true 时额外接受 "off"（仅 API Key）。
+func NormalizeResponseLanguage(code string, allowOff bool) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "_", "-")
+	if code == "" {
+		return "", nil
+	}
+	if code == ResponseLanguageOff && allowOff {
+		return code, nil
+	}
+	if _, ok := lookupResponseLanguage(code); ok {
+		return code, nil
+	}
+	return "", ErrInvalidResponseLanguage
+}
+
+// ResolveResponseLanguage 返回本次请求生效的语言代码：Key 显式设置优先，"off" 表示关闭，未设置时沿用分组。
+func ResolveResponseLanguage(apiKey *APIKey) string {
+	if apiKey == nil {
+		return ""
+	}
+	switch apiKey.ResponseLanguage {
+	case ResponseLanguageOff:
+		return ""
+	case "":
+		if apiKey.Group != nil {
+			return apiKey.Group.ResponseLanguage
+		}
+		return ""
+	default:
+		return apiKey.ResponseLanguage
+	}
+}
+
+// ResponseLanguageFormatForPath 按入站路径判断请求体格式；不支持的端点返回空字符串。
+func ResponseLanguageFormatForPath(path string) string {
+	path = strings.TrimRight(path, "/")
+	switch {
+	case strings.HasSuffix(path, "/messages"):
+		return ResponseLanguageFormatAnthropic
+	case strings.HasSuffix(path, "/chat/completions"):
+		return ResponseLanguageFormatChatCompletions
+	case strings.HasSuffix(path, "/responses"):
+		return ResponseLanguageFormatResponses
+	default:
+		return ""
+	}
+}
+
+// languageDirectivePatterns 识别客户端已给出的回复语言要求，避免与网关指令冲突或重复注入。
+var languageDirectivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(respond|reply|answer|write|speak|output|communicate|converse)\w*\b[^.\n]{0,40}?\b(in|using)\s+(the\s+)?(simplified\s+|traditional\s+)?(english|chinese|mandarin|cantonese|japanese|korean|french|german|spanish|portuguese|russian|italian|arabic|vietnamese|thai)\b`),
+	regexp.MustCompile(`(?i)\b(respond|reply|answer)\w*\b[^.\n]{0,40}?\b(in|using)\s+the\s+(same\s+language|user'?s\s+language|language\s+of\s+the\s+user)`),
+	regexp.MustCompile(`(用|使用|以|请用|請用)\s*(简体中文|繁体中文|繁體中文|中文|汉语|漢語|英文|英语|英語|日文|日语|日語|韩文|韩语|韓語|法语|德语|西班牙语|葡萄牙语|俄语)\s*(来|來)?\s*(回答|回复|回覆|回應|作答|输出|輸出|交流|对话|對話|回应)`),
+	regexp.MustCompile(`(日本語|英語|中国語|韓国語)で(回答|返答|答え|応答|話)`),
+}
+
+// hasLanguageDirective 判断文本中是否已指定回复语言。
+func hasLanguageDirective(text string) bool {
+	if strings.TrimSpace(text) == "" {
+		return false
+	}
+	for _, lang := range responseLanguages {
+		if strings.Contains(text, lang.Instruction) {
+			return true
+		}
+	}
+	for _, pattern := range languageDirectivePatterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyResponseLanguage 在请求未指定回复语言时追加语言指令。
+// 检查范围为系统级指令（system / developer / instructions）与最后一条用户消息；
+// 返回 applied=false 表示未改写（未启用、格式不支持、客户端已指定语言或请求体无法解析）。
+func ApplyResponseLanguage(body []byte, format, code string) ([]byte, bool) {
+	lang, ok := lookupResponseLanguage(code)
+	if !ok || len(body) == 0 || !gjson.ValidBytes(body) {
+		return body, false
+	}
+	switch format {
+	case ResponseLanguageFormatAnthropic:
+		return applyResponseLanguageAnthropic(body, lang.Instruction)
+	case ResponseLanguageFormatChatCompletions:
+		return applyResponseLanguageChatCompletions(body, lang.Instruction)
+	case ResponseLanguageFormatResponses:
+		return applyResponseLanguageResponses(body, lang.Instruction)
+	default:
+		return body, false
+	}
+}
+
+func applyResponseLanguageAnthropic(body []byte, instruction string) ([]byte, bool) {
+	system := gjson.GetBytes(body, "system")
+	if hasLanguageDirective(contentText(system)) || hasLanguageDirective(lastUserMessageText(body, "messages")) {
+		return body, false
+	}
+	var (
+		out []byte
+		err error
+	)
+	switch {
+	case system.IsArray():
+		out, err = sjson.SetBytes(body, "system.-1", map[string]any{"type": "text", "text": instruction})
+	case system.Type == gjson.String && strings.TrimSpace(system.String()) != "":
+		out, err = sjson.SetBytes(body, "system", system.String()+"\n\n"+instruction)
+	default:
+		out, err = sjson.SetBytes(body, "system", instruction)
+	}
+	if err != nil {
+		return body, false
+	}
+	return out, true
+}
+
+func applyResponseLanguageChatCompletions(body []byte, instruction string) ([]byte, bool) {
+	messages := gjson.GetBytes(body, "messages")
+	if !messages.IsArray() {
+		return body, false
+	}
+	systemIndex := -1
+	for i, msg := range messages.Array() {
+		role := msg.Get("role").String()
+		if role != "system" && role != "developer" {
+			continue
+		}
+		if hasLanguageDirective(contentText(msg.Get("content"))) {
+			return body, false
+		}
+		if systemIndex < 0 {
+			systemIndex = i
+		}
+	}
+	if hasLanguageDirective(lastUserMessageText(body, "messages")) {
+		return body, false
+	}
+
+	var (
+		out []byte
+		err error
+	)
+	if systemIndex >= 0 {
+		path := "messages." + strconv.Itoa(systemIndex) + ".content"
+		content := gjson.GetBytes(body, path)
+		if content.IsArray() {
+			out, err = sjson.SetBytes(body, path+".-1", map[string]any{"type": "text", "text": instruction})
+		} else {
+			out, err = sjson.SetBytes(body, path, strings.TrimRight(content.String(), "\n")+"\n\n"+instruction)
+		}
+	} else {
+		// 无系统消息时在最前面插入一条
+		items := make([]json.RawMessage, 0, len(messages.Array())+1)
+		systemMsg, _ := json.Marshal(map[string]string{"role": "system", "content": instruction})
+		items = append(items, systemMsg)
+		for _, msg := range messages.Array() {
+			items = append(items, json.RawMessage(msg.Raw))
+		}
+		raw, marshalErr := json.Marshal(items)
+		if marshalErr != nil {
+			return body, false
+		}
+		out, err = sjson.SetRawBytes(body, "messages", raw)
+	}
+	if err != nil {
+		return body, false
+	}
+	return out, true
+}
+
+func applyResponseLanguageResponses(body []byte, instruction string) ([]byte, bool) {
+	instructions := gjson.GetBytes(body, "instructions")
+	if hasLanguageDirective(instructions.String()) {
+		return body, false
+	}
+	input := gjson.GetBytes(body, "input")
+	if input.Type == gjson.String {
+		if hasLanguageDirective(input.String()) {
+			return body, false
+		}
+	} else if input.IsArray() {
+		for _, item := range input.Array() {
+			role := item.Get("role").String()
+			if (role == "system" || role == "developer") && hasLanguageDirective(contentText(item.Get("content"))) {
+				return body, false
+			}
+		}
+		if hasLanguageDirective(lastUserMessageText(body, "input")) {
+			return body, false
+		}
+	}
+
+	text := instruction
+	if existing := strings.TrimRight(instructions.String(), "\n"); strings.TrimSpace(existing) != "" {
+		text = existing + "\n\n" + instruction
+	}
+	out, err := sjson.SetBytes(body, "instructions", text)
+	if err != nil {
+		return body, false
+	}
+	return out, true
+}
+
+// contentText 拼接字符串或内容块数组中的文本。
+func contentText(content gjson.Result) string {
+	if content.Type == gjson.String {
+		return content.String()
+	}
+	if !content.IsArray() {
+		return ""
+	}
+	var sb strings.Builder
+	for _, block := range content.Array() {
+		if block.Type == gjson.String {
+			sb.WriteString(block.String())
+		} else {
+			sb.WriteString(block.Get("text").String())
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// lastUserMessageText 返回消息数组中最后一条 user 消息的文本。
+func lastUserMessageText(body []byte, path string) string {
+	items := gjson.GetBytes(body, path).Array()
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].Get("role").String() == "user" {
+			return contentText(items[i].Get("content"))
+		}
+	}
+	return ""
+}
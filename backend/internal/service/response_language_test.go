@@ -0,0 +1,128 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNormalizeResponseLanguage(t *testing.T) {
+	code, err := NormalizeResponseLanguage(" ZH_TW ", false)
+	require.NoError(t, err)
+	require.Equal(t, "zh-tw", code)
+
+	code, err = NormalizeResponseLanguage("", false)
+	require.NoError(t, err)
+	require.Empty(t, code)
+
+	_, err = NormalizeResponseLanguage(ResponseLanguageOff, false)
+	require.ErrorIs(t, err, ErrInvalidResponseLanguage, "groups cannot use off")
+
+	code, err = NormalizeResponseLanguage("OFF", true)
+	require.NoError(t, err)
+	require.Equal(t, ResponseLanguageOff, code)
+
+	_, err = NormalizeResponseLanguage("klingon", true)
+	require.ErrorIs(t, err, ErrInvalidResponseLanguage)
+}
+
+func TestResolveResponseLanguage(t *testing.T) {
+	group := &Group{ResponseLanguage: "zh"}
+	require.Equal(t, "zh", ResolveResponseLanguage(&APIKey{Group: group}))
+	require.Equal(t, "en", ResolveResponseLanguage(&APIKey{ResponseLanguage: "en", Group: group}))
+	require.Empty(t, ResolveResponseLanguage(&APIKey{ResponseLanguage: ResponseLanguageOff, Group: group}))
+	require.Empty(t, ResolveResponseLanguage(&APIKey{}))
+	require.Empty(t, ResolveResponseLanguage(nil))
+}
+
+func TestResponseLanguageFormatForPath(t *testing.T) {
+	require.Equal(t, ResponseLanguageFormatAnthropic, ResponseLanguageFormatForPath("/antigravity/v1/messages"))
+	require.Equal(t, ResponseLanguageFormatChatCompletions, ResponseLanguageFormatForPath("/chat/completions"))
+	require.Equal(t, ResponseLanguageFormatResponses, ResponseLanguageFormatForPath("/backend-api/codex/responses"))
+	require.Empty(t, ResponseLanguageFormatForPath("/v1/messages/count_tokens"))
+	require.Empty(t, ResponseLanguageFormatForPath("/v1/embeddings"))
+}
+
+func TestHasLanguageDirective(t *testing.T) {
+	for _, text := range []string{
+		"You are helpful. Always respond in English.",
+		"Please reply only using Japanese.",
+		"Answer in the same language as the question.",
+		"请用中文回答",
+		"使用英文回复用户",
+		"日本語で回答してください",
+		"Respond in Simplified Chinese (简体中文).",
+	} {
+		require.True(t, hasLanguageDirective(text), text)
+	}
+	for _, text := range []string{
+		"",
+		"You are a helpful assistant.",
+		"Translate the following English text.",
+		"中文是一门语言",
+	} {
+		require.False(t, hasLanguageDirective(text), text)
+	}
+}
+
+func TestApplyResponseLanguage_Anthropic(t *testing.T) {
+	out, applied := ApplyResponseLanguage([]byte(`{"messages":[{"role":"user","content":"hi"}]}`), ResponseLanguageFormatAnthropic, "zh")
+	require.True(t, applied)
+	require.Equal(t, "Respond in Simplified Chinese (简体中文).", gjson.GetBytes(out, "system").String())
+
+	out, applied = ApplyResponseLanguage([]byte(`{"system":"Be brief.","messages":[]}`), ResponseLanguageFormatAnthropic, "en")
+	require.True(t, applied)
+	require.Equal(t, "Be brief.\n\nRespond in English.", gjson.GetBytes(out, "system").String())
+
+	out, applied = ApplyResponseLanguage([]byte(`{"system":[{"type":"text","text":"Be brief.","cache_control":{"type":"ephemeral"}}],"messages":[]}`), ResponseLanguageFormatAnthropic, "en")
+	require.True(t, applied)
+	require.Equal(t, "ephemeral", gjson.GetBytes(out, "system.0.cache_control.type").String(), "existing blocks are untouched")
+	require.Equal(t, "Respond in English.", gjson.GetBytes(out, "system.1.text").String())
+
+	// 再次应用不会重复注入
+	again, applied := ApplyResponseLanguage(out, ResponseLanguageFormatAnthropic, "en")
+	require.False(t, applied)
+	require.Equal(t, string(out), string(again))
+
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"请用英文回答：你好"}]}]}`)
+	out, applied = ApplyResponseLanguage(body, ResponseLanguageFormatAnthropic, "zh")
+	require.False(t, applied, "client already specified a language in the last user message")
+	require.Equal(t, string(body), string(out))
+}
+
+func TestApplyResponseLanguage_ChatCompletions(t *testing.T) {
+	out, applied := ApplyResponseLanguage([]byte(`{"messages":[{"role":"user","content":"hi"}]}`), ResponseLanguageFormatChatCompletions, "ja")
+	require.True(t, applied)
+	require.Equal(t, "system", gjson.GetBytes(out, "messages.0.role").String())
+	require.Equal(t, "Respond in Japanese (日本語).", gjson.GetBytes(out, "messages.0.content").String())
+	require.Equal(t, "hi", gjson.GetBytes(out, "messages.1.content").String())
+
+	out, applied = ApplyResponseLanguage([]byte(`{"messages":[{"role":"developer","content":"Be brief."},{"role":"user","content":"hi"}]}`), ResponseLanguageFormatChatCompletions, "ja")
+	require.True(t, applied)
+	require.Equal(t, "Be brief.\n\nRespond in Japanese (日本語).", gjson.GetBytes(out, "messages.0.content").String())
+	require.Len(t, gjson.GetBytes(out, "messages").Array(), 2)
+
+	_, applied = ApplyResponseLanguage([]byte(`{"messages":[{"role":"system","content":"Always reply in French."},{"role":"user","content":"hi"}]}`), ResponseLanguageFormatChatCompletions, "ja")
+	require.False(t, applied)
+}
+
+func TestApplyResponseLanguage_Responses(t *testing.T) {
+	out, applied := ApplyResponseLanguage([]byte(`{"input":"hi"}`), ResponseLanguageFormatResponses, "de")
+	require.True(t, applied)
+	require.Equal(t, "Respond in German (Deutsch).", gjson.GetBytes(out, "instructions").String())
+
+	out, applied = ApplyResponseLanguage([]byte(`{"instructions":"Be brief.","input":[{"role":"user","content":[{"type":"input_text","text":"hi"}]}]}`), ResponseLanguageFormatResponses, "de")
+	require.True(t, applied)
+	require.Equal(t, "Be brief.\n\nRespond in German (Deutsch).", gjson.GetBytes(out, "instructions").String())
+
+	_, applied = ApplyResponseLanguage([]byte(`{"input":[{"role":"developer","content":"Respond in English."}]}`), ResponseLanguageFormatResponses, "de")
+	require.False(t, applied)
+
+	_, applied = ApplyResponseLanguage([]byte(`not json`), ResponseLanguageFormatResponses, "de")
+	require.False(t, applied)
+	_, applied = ApplyResponseLanguage([]byte(`{"input":"hi"}`), ResponseLanguageFormatResponses, "")
+	require.False(t, applied)
+}
@@ -143,6 +143,10 @@ type SchedulerSnapshotService struct {
 	fullRebuildRequested uint64
 	fullRebuildCompleted uint64
 	fullRebuildLastErr   error
+
+	// initialRebuildDone 在启动重建结束（无论成功与否）后关闭，供启动预热等待。
+	initialRebuildDone     chan struct{}
+	initialRebuildDoneOnce sync.Once
 }
 
 func NewSchedulerSnapshotService(
@@ -164,6 +168,8 @@ func NewSchedulerSnapshotService(
 		cfg:           cfg,
 		stopCh:        make(chan struct{}),
 		fallbackLimit: newFallbackLimiter(maxQPS),
+
+		initialRebuildDone: make(chan struct{}),
 	}
 }
 
@@ -296,7 +302,25 @@ func (s *SchedulerSnapshotService) UpdateAccountInCache(ctx context.Context, acc
 	return s.cache.SetAccount(ctx, account)
 }
 
+// WaitInitialRebuild 等待启动时的全量快照重建结束；未启用快照缓存时立即返回。
+func (s *SchedulerSnapshotService) WaitInitialRebuild(ctx context.Context) error {
+	if s == nil || s.cache == nil || s.initialRebuildDone == nil {
+		return nil
+	}
+	select {
+	case <-s.initialRebuildDone:
+		s.fullRebuildStateMu.Lock()
+		defer s.fullRebuildStateMu.Unlock()
+		return s.fullRebuildLastErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (s *SchedulerSnapshotService) runInitialRebuild() {
+	if s.initialRebuildDone != nil {
+		defer s.initialRebuildDoneOnce.Do(func() { close(s.initialRebuildDone) })
+	}
 	if s.cache == nil {
 		return
 	}
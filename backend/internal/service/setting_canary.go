@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// 网关配置灰度通道
+//
+// 管理员为一部分流量（指定 API Key，或按 Key ID 哈希的百分比）配置“候选”版本的网关设置：
+// 转发改写开关、Beta 策略规则、OpenAI 高级调度开关；其余流量继续使用稳定设置。
+// 两组请求的结果分别统计（进程内，多实例部署时各实例独立），确认无误后 promote 把候选写回
+// 稳定设置，或 rollback 直接丢弃候选。
+
+const (
+	GatewayCanaryCohortStable = "stable"
+	GatewayCanaryCohortCanary = "canary"
+)
+
+const (
+	gatewayCanaryCacheTTL  = 30 * time.Second
+	gatewayCanaryDBTimeout = 2 * time.Second
+)
+
+var (
+	ErrGatewayCanaryInvalidPercentage = infraerrors.BadRequest("GATEWAY_CANARY_INVALID_PERCENTAGE", "percentage must be between 0 and 100")
+	ErrGatewayCanaryInvalidAPIKeyID   = infraerrors.BadRequest("GATEWAY_CANARY_INVALID_API_KEY_ID", "api_key_ids must be positive")
+	ErrGatewayCanaryEmptyCandidate    = infraerrors.BadRequest("GATEWAY_CANARY_EMPTY_CANDIDATE", "canary candidate has no settings")
+	ErrGatewayCanaryNoTraffic         = infraerrors.BadRequest("GATEWAY_CANARY_NO_TRAFFIC", "enabled canary needs api_key_ids or a percentage above 0")
+)
+
+// GatewayCanaryCandidate 候选设置；nil 字段沿用稳定设置
+type GatewayCanaryCandidate struct {
+	EnableFingerprintUnification       *bool               `json:"enable_fingerprint_unification,omitempty"`
+	EnableMetadataPassthrough          *bool               `json:"enable_metadata_passthrough,omitempty"`
+	EnableCCHSigning                   *bool               `json:"enable_cch_signing,omitempty"`
+	EnableAnthropicCacheTTL1hInjection *bool               `json:"enable_anthropic_cache_ttl_1h_injection,omitempty"`
+	RewriteMessageCacheControl         *bool               `json:"rewrite_message_cache_control,omitempty"`
+	EnableClientDatelineNormalization  *bool               `json:"enable_client_dateline_normalization,omitempty"`
+	OpenAIAdvancedSchedulerEnabled     *bool               `json:"openai_advanced_scheduler_enabled,omitempty"`
+	BetaPolicy                         *BetaPolicySettings `json:"beta_policy,omitempty"`
+}
+
+// IsEmpty 候选是否未设置任何字段
+func (c *GatewayCanaryCandidate) IsEmpty() bool {
+	return c == nil || (c.EnableFingerprintUnification == nil &&
+		c.EnableMetadataPassthrough == nil &&
+		c.EnableCCHSigning == nil &&
+		c.EnableAnthropicCacheTTL1hInjection == nil &&
+		c.RewriteMessageCacheControl == nil &&
+		c.EnableClientDatelineNormalization == nil &&
+		c.OpenAIAdvancedSchedulerEnabled == nil &&
+		c.BetaPolicy == nil)
+}
+
+// applyForwarding 把候选的转发开关叠加到稳定设置上
+func (c *GatewayCanaryCandidate) applyForwarding(result *gatewayForwardingSettingsResult) {
+	if c.EnableFingerprintUnification != nil {
+		result.fp = *c.EnableFingerprintUnification
+	}
+	if c.EnableMetadataPassthrough != nil {
+		result.mp = *c.EnableMetadataPassthrough
+	}
+	if c.EnableCCHSigning != nil {
+		result.cch = *c.EnableCCHSigning
+	}
+	if c.EnableAnthropicCacheTTL1hInjection != nil {
+		result.cacheTTL1h = *c.EnableAnthropicCacheTTL1hInjection
+	}
+	if c.RewriteMessageCacheControl != nil {
+		result.rewriteMessageCacheControl = *c.RewriteMessageCacheControl
+	}
+	if c.EnableClientDatelineNormalization != nil {
+		result.clientDatelineNormalization = *c.EnableClientDatelineNormalization
+	}
+}
+
+// stableSettingUpdates 候选中可直接写回的 key/value 设置（Beta 策略单独写入）
+func (c *GatewayCanaryCandidate) stableSettingUpdates() map[string]string {
+	updates := map[string]string{}
+	set := func(key string, v *bool) {
+		if v != nil {
+			updates[key] = strconv.FormatBool(*v)
+		}
+	}
+	set(SettingKeyEnableFingerprintUnification, c.EnableFingerprintUnification)
+	set(SettingKeyEnableMetadataPassthrough, c.EnableMetadataPassthrough)
+	set(SettingKeyEnableCCHSigning, c.EnableCCHSigning)
+	set(SettingKeyEnableAnthropicCacheTTL1hInjection, c.EnableAnthropicCacheTTL1hInjection)
+	set(SettingKeyRewriteMessageCacheControl, c.RewriteMessageCacheControl)
+	set(SettingKeyEnableClientDatelineNormalization, c.EnableClientDatelineNormalization)
+	set(openAIAdvancedSchedulerSettingKey, c.OpenAIAdvancedSchedulerEnabled)
+	return updates
+}
+
+// GatewayCanarySettings 灰度通道配置
+type GatewayCanarySettings struct {
+	Enabled bool `json:"enabled"`
+	// Percentage 按 API Key ID 哈希进入灰度的流量百分比（0-100），同一 Key 始终落在同一分组
+	Percentage int `json:"percentage"`
+	// APIKeyIDs 始终进入灰度的 API Key
+	APIKeyIDs []int64                `json:"api_key_ids"`
+	Candidate GatewayCanaryCandidate `json:"candidate"`
+	UpdatedAt *time.Time             `json:"updated_at,omitempty"`
+}
+
+// CohortFor 返回 API Key 所属的灰度分组；灰度未启用时返回空字符串。
+func (c *GatewayCanarySettings) CohortFor(apiKeyID int64) string {
+	if c == nil || !c.Enabled || apiKeyID <= 0 {
+		return ""
+	}
+	for _, id := range c.APIKeyIDs {
+		if id == apiKeyID {
+			return GatewayCanaryCohortCanary
+		}
+	}
+	if c.Percentage > 0 {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(strconv.FormatInt(apiKeyID, 10)))
+		if int(h.Sum32()%100) < c.Percentage {
+			return GatewayCanaryCohortCanary
+		}
+	}
+	return GatewayCanaryCohortStable
+}
+
+func validateGatewayCanarySettings(settings *GatewayCanarySettings) error {
+	if settings.Percentage < 0 || settings.Percentage > 100 {
+		return ErrGatewayCanaryInvalidPercentage
+	}
+	seen := make(map[int64]struct{}, len(settings.APIKeyIDs))
+	ids := make([]int64, 0, len(settings.APIKeyIDs))
+	for _, id := range settings.APIKeyIDs {
+		if id <= 0 {
+			return ErrGatewayCanaryInvalidAPIKeyID
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	settings.APIKeyIDs = ids
+	if settings.Candidate.BetaPolicy != nil {
+		if err := validateBetaPolicySettings(settings.Candidate.BetaPolicy); err != nil {
+			return infraerrors.BadRequest("GATEWAY_CANARY_INVALID_BETA_POLICY", err.Error())
+		}
+	}
+	if settings.Enabled {
+		if settings.Candidate.IsEmpty() {
+			return ErrGatewayCanaryEmptyCandidate
+		}
+		if settings.Percentage == 0 && len(settings.APIKeyIDs) == 0 {
+			return ErrGatewayCanaryNoTraffic
+		}
+	}
+	return nil
+}
+
+// GatewayCanaryCohortMetrics 单个分组的请求结果统计
+type GatewayCanaryCohortMetrics struct {
+	Requests     int64   `json:"requests"`
+	Errors       int64   `json:"errors"`
+	ErrorRate    float64 `json:"error_rate"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// GatewayCanaryStatus 灰度配置与两组对比指标（本实例自 MetricsSince 起）
+type GatewayCanaryStatus struct {
+	Settings     *GatewayCanarySettings     `json:"settings"`
+	Stable       GatewayCanaryCohortMetrics `json:"stable"`
+	Canary       GatewayCanaryCohortMetrics `json:"canary"`
+	MetricsSince time.Time                  `json:"metrics_since"`
+}
+
+type gatewayCanaryCounters struct {
+	requests  int64
+	errors    int64
+	latencyMs int64
+}
+
+func (c gatewayCanaryCounters) metrics() GatewayCanaryCohortMetrics {
+	m := GatewayCanaryCohortMetrics{Requests: c.requests, Errors: c.errors}
+	if c.requests > 0 {
+		m.ErrorRate = float64(c.errors) / float64(c.requests)
+		m.AvgLatencyMs = float64(c.latencyMs) / float64(c.requests)
+	}
+	return m
+}
+
+// gatewayCanaryMetricsStore 进程内分组统计；灰度配置变更、promote、rollback 时清零
+type gatewayCanaryMetricsStore struct {
+	mu     sync.Mutex
+	since  time.Time
+	stable gatewayCanaryCounters
+	canary gatewayCanaryCounters
+}
+
+var gatewayCanaryMetrics = &gatewayCanaryMetricsStore{since: time.Now()}
+
+func (m *gatewayCanaryMetricsStore) record(cohort string, failed bool, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counters := &m.stable
+	if cohort == GatewayCanaryCohortCanary {
+		counters = &m.canary
+	}
+	counters.requests++
+	if failed {
+		counters.errors++
+	}
+	counters.latencyMs += latency.Milliseconds()
+}
+
+func (m *gatewayCanaryMetricsStore) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.since = time.Now()
+	m.stable = gatewayCanaryCounters{}
+	m.canary = gatewayCanaryCounters{}
+}
+
+func (m *gatewayCanaryMetricsStore) fill(status *GatewayCanaryStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status.Stable = m.stable.metrics()
+	status.Canary = m.canary.metrics()
+	status.MetricsSince = m.since
+}
+
+type cachedGatewayCanarySettings struct {
+	settings  *GatewayCanarySettings
+	expiresAt int64 // unix nano
+}
+
+var gatewayCanaryCache atomic.Value // *cachedGatewayCanarySettings
+var gatewayCanarySF singleflight.Group
+
+func storeGatewayCanaryCache(settings *GatewayCanarySettings) {
+	gatewayCanarySF.Forget("gateway_canary")
+	gatewayCanaryCache.Store(&cachedGatewayCanarySettings{
+		settings:  settings,
+		expiresAt: time.Now().Add(gatewayCanaryCacheTTL).UnixNano(),
+	})
+}
+
+func parseGatewayCanarySettings(raw string) *GatewayCanarySettings {
+	settings := &GatewayCanarySettings{}
+	if raw == "" {
+		return settings
+	}
+	if err := json.Unmarshal([]byte(raw), settings); err != nil {
+		slog.Warn("failed to parse gateway canary settings", "error", err)
+		return &GatewayCanarySettings{}
+	}
+	return settings
+}
+
+// loadGatewayCanarySettings 读取灰度配置（进程内缓存 30s），读取失败按未启用处理。
+func loadGatewayCanarySettings(ctx context.Context, repo SettingRepository) *GatewayCanarySettings {
+	if cached, ok := gatewayCanaryCache.Load().(*cachedGatewayCanarySettings); ok && cached != nil {
+		if time.Now().UnixNano() < cached.expiresAt {
+			return cached.settings
+		}
+	}
+	val, _, _ := gatewayCanarySF.Do("gateway_canary", func() (any, error) {
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gatewayCanaryDBTimeout)
+		defer cancel()
+		raw, err := repo.GetValue(dbCtx, SettingKeyGatewayCanarySettings)
+		if err != nil && !errors.Is(err, ErrSettingNotFound) {
+			slog.Warn("failed to get gateway canary settings", "error", err)
+		}
+		settings := parseGatewayCanarySettings(raw)
+		gatewayCanaryCache.Store(&cachedGatewayCanarySettings{
+			settings:  settings,
+			expiresAt: time.Now().Add(gatewayCanaryCacheTTL).UnixNano(),
+		})
+		return settings, nil
+	})
+	if settings, ok := val.(*GatewayCanarySettings); ok && settings != nil {
+		return settings
+	}
+	return &GatewayCanarySettings{}
+}
+
+// gatewayCanaryCandidate 返回当前网关请求应叠加的候选设置；非灰度分组或非网关请求返回 nil。
+// 分组在请求首次读取受灰度控制的设置时确定，之后整个请求保持一致。
+func gatewayCanaryCandidate(ctx context.Context, repo SettingRepository) *GatewayCanaryCandidate {
+	req := ActiveRequestFromContext(ctx)
+	if req == nil || repo == nil {
+		return nil
+	}
+	cohort := req.CanaryCohort()
+	if cohort == GatewayCanaryCohortStable {
+		return nil
+	}
+	settings := loadGatewayCanarySettings(ctx, repo)
+	if cohort == "" {
+		cohort = req.setCanaryCohort(settings.CohortFor(req.info.APIKeyID))
+	}
+	if cohort != GatewayCanaryCohortCanary || !settings.Enabled {
+		return nil
+	}
+	return &settings.Candidate
+}
+
+// RecordGatewayCanaryOutcome 在请求结束时按分组记录结果（5xx 计为错误）；灰度未启用时不记录。
+func RecordGatewayCanaryOutcome(req *ActiveRequest, status int) {
+	if req == nil {
+		return
+	}
+	cohort := req.CanaryCohort()
+	if cohort == "" {
+		cached, ok := gatewayCanaryCache.Load().(*cachedGatewayCanarySettings)
+		if !ok || cached == nil {
+			return
+		}
+		cohort = req.setCanaryCohort(cached.settings.CohortFor(req.info.APIKeyID))
+		if cohort == "" {
+			return
+		}
+	}
+	gatewayCanaryMetrics.record(cohort, status >= 500, req.now().Sub(req.startedAt))
+}
+
+// CanaryCohort 返回请求已确定的灰度分组，尚未确定时返回空字符串。
+func (a *ActiveRequest) CanaryCohort() string {
+	if a == nil {
+		return ""
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.canaryCohort
+}
+
+// setCanaryCohort 首次确定灰度分组；已确定时保持原值并返回。
+func (a *ActiveRequest) setCanaryCohort(cohort string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.canaryCohort == "" {
+		a.canaryCohort = cohort
+	}
+	return a.canaryCohort
+}
+
+// GetGatewayCanaryStatus 获取灰度配置与两组对比指标
+func (s *SettingService) GetGatewayCanaryStatus(ctx context.Context) (*GatewayCanaryStatus, error) {
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyGatewayCanarySettings)
+	if err != nil && !errors.Is(err, ErrSettingNotFound) {
+		return nil, fmt.Errorf("get gateway canary settings: %w", err)
+	}
+	status := &GatewayCanaryStatus{Settings: parseGatewayCanarySettings(raw)}
+	gatewayCanaryMetrics.fill(status)
+	return status, nil
+}
+
+// SetGatewayCanarySettings 保存灰度配置，立即生效并清零对比指标
+func (s *SettingService) SetGatewayCanarySettings(ctx context.Context, settings *GatewayCanarySettings) (*GatewayCanaryStatus, error) {
+	if settings == nil {
+		return nil, fmt.Errorf("settings cannot be nil")
+	}
+	if err := validateGatewayCanarySettings(settings); err != nil {
+		return nil, err
+	}
+	if err := s.saveGatewayCanarySettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return s.GetGatewayCanaryStatus(ctx)
+}
+
+// PromoteGatewayCanary 把候选设置写回稳定设置并关闭灰度
+func (s *SettingService) PromoteGatewayCanary(ctx context.Context) (*GatewayCanaryStatus, error) {
+	status, err := s.GetGatewayCanaryStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candidate := status.Settings.Candidate
+	if candidate.IsEmpty() {
+		return nil, ErrGatewayCanaryEmptyCandidate
+	}
+	if updates := candidate.stableSettingUpdates(); len(updates) > 0 {
+		if err := s.settingRepo.SetMultiple(ctx, updates); err != nil {
+			return nil, fmt.Errorf("promote gateway canary settings: %w", err)
+		}
+	}
+	if candidate.BetaPolicy != nil {
+		if err := s.SetBetaPolicySettings(ctx, candidate.BetaPolicy); err != nil {
+			return nil, fmt.Errorf("promote gateway canary beta policy: %w", err)
+		}
+	}
+	// 使稳定设置缓存失效，下次读取回源
+	gatewayForwardingSF.Forget("gateway_forwarding")
+	gatewayForwardingCache.Store(&cachedGatewayForwardingSettings{})
+	openAIAdvancedSchedulerSettingSF.Forget(openAIAdvancedSchedulerSettingKey)
+	openAIAdvancedSchedulerSettingCache.Store(&cachedOpenAIAdvancedSchedulerSetting{})
+
+	slog.Info("gateway canary promoted",
+		"canary_requests", status.Canary.Requests,
+		"canary_error_rate", status.Canary.ErrorRate,
+		"stable_error_rate", status.Stable.ErrorRate,
+	)
+	if err := s.saveGatewayCanarySettings(ctx, &GatewayCanarySettings{}); err != nil {
+		return nil, err
+	}
+	return s.GetGatewayCanaryStatus(ctx)
+}
+
+// RollbackGatewayCanary 丢弃候选设置并关闭灰度，全部流量回到稳定设置
+func (s *SettingService) RollbackGatewayCanary(ctx context.Context) (*GatewayCanaryStatus, error) {
+	if err := s.saveGatewayCanarySettings(ctx, &GatewayCanarySettings{}); err != nil {
+		return nil, err
+	}
+	slog.Info("gateway canary rolled back")
+	return s.GetGatewayCanaryStatus(ctx)
+}
+
+func (s *SettingService) saveGatewayCanarySettings(ctx context.Context, settings *GatewayCanarySettings) error {
+	now := time.Now()
+	settings.UpdatedAt = &now
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal gateway canary settings: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyGatewayCanarySettings, string(data)); err != nil {
+		return err
+	}
+	storeGatewayCanaryCache(settings)
+	gatewayCanaryMetrics.reset()
+	return nil
+}
@@ -0,0 +1,156 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/singleflight"
+)
+
+type canarySettingRepoStub struct {
+	values map[string]string
+}
+
+func (r *canarySettingRepoStub) Get(ctx context.Context, key string) (*Setting, error) {
+	if value, ok := r.values[key]; ok {
+		return &Setting{Key: key, Value: value}, nil
+	}
+	return nil, ErrSettingNotFound
+}
+
+func (r *canarySettingRepoStub) GetValue(ctx context.Context, key string) (string, error) {
+	setting, err := r.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return setting.Value, nil
+}
+
+func (r *canarySettingRepoStub) Set(ctx context.Context, key, value string) error {
+	r.values[key] = value
+	return nil
+}
+
+func (r *canarySettingRepoStub) GetMultiple(ctx context.Context, keys []string) (map[string]string, error) {
+	out := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := r.values[key]; ok {
+			out[key] = value
+		}
+	}
+	return out, nil
+}
+
+func (r *canarySettingRepoStub) SetMultiple(ctx context.Context, settings map[string]string) error {
+	for key, value := range settings {
+		r.values[key] = value
+	}
+	return nil
+}
+
+func (r *canarySettingRepoStub) GetAll(ctx context.Context) (map[string]string, error) {
+	return r.values, nil
+}
+
+func (r *canarySettingRepoStub) Delete(ctx context.Context, key string) error {
+	delete(r.values, key)
+	return nil
+}
+
+func resetGatewayCanaryStateForTest() {
+	gatewayCanaryCache = atomic.Value{}
+	gatewayCanarySF = singleflight.Group{}
+	gatewayForwardingCache = atomic.Value{}
+	gatewayForwardingSF = singleflight.Group{}
+	gatewayCanaryMetrics.reset()
+}
+
+func TestGatewayCanarySettings_CohortFor(t *testing.T) {
+	settings := &GatewayCanarySettings{Enabled: true, APIKeyIDs: []int64{7}}
+	require.Equal(t, GatewayCanaryCohortCanary, settings.CohortFor(7))
+	require.Equal(t, GatewayCanaryCohortStable, settings.CohortFor(8))
+
+	settings.Percentage = 100
+	require.Equal(t, GatewayCanaryCohortCanary, settings.CohortFor(8))
+
+	settings.Enabled = false
+	require.Empty(t, settings.CohortFor(7))
+}
+
+func TestSetGatewayCanarySettings_Validation(t *testing.T) {
+	resetGatewayCanaryStateForTest()
+	svc := NewSettingService(&canarySettingRepoStub{values: map[string]string{}}, &config.Config{})
+	enabled := true
+
+	_, err := svc.SetGatewayCanarySettings(context.Background(), &GatewayCanarySettings{Percentage: 101})
+	require.ErrorIs(t, err, ErrGatewayCanaryInvalidPercentage)
+
+	_, err = svc.SetGatewayCanarySettings(context.Background(), &GatewayCanarySettings{Enabled: true, Percentage: 10})
+	require.ErrorIs(t, err, ErrGatewayCanaryEmptyCandidate)
+
+	_, err = svc.SetGatewayCanarySettings(context.Background(), &GatewayCanarySettings{
+		Enabled:   true,
+		Candidate: GatewayCanaryCandidate{EnableMetadataPassthrough: &enabled},
+	})
+	require.ErrorIs(t, err, ErrGatewayCanaryNoTraffic)
+
+	status, err := svc.SetGatewayCanarySettings(context.Background(), &GatewayCanarySettings{
+		Enabled:   true,
+		APIKeyIDs: []int64{3, 1, 3},
+		Candidate: GatewayCanaryCandidate{EnableMetadataPassthrough: &enabled},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 3}, status.Settings.APIKeyIDs)
+}
+
+func TestGatewayCanary_CandidateAppliesToCanaryRequestsAndPromotes(t *testing.T) {
+	resetGatewayCanaryStateForTest()
+	t.Cleanup(resetGatewayCanaryStateForTest)
+	repo := &canarySettingRepoStub{values: map[string]string{
+		SettingKeyEnableMetadataPassthrough: "false",
+	}}
+	svc := NewSettingService(repo, &config.Config{})
+	enabled := true
+	_, err := svc.SetGatewayCanarySettings(context.Background(), &GatewayCanarySettings{
+		Enabled:   true,
+		APIKeyIDs: []int64{5},
+		Candidate: GatewayCanaryCandidate{EnableMetadataPassthrough: &enabled},
+	})
+	require.NoError(t, err)
+
+	registry := NewActiveRequestRegistry(0)
+	canaryReq, canaryCtx := registry.Begin(context.Background(), ActiveRequestInfo{APIKeyID: 5})
+	defer registry.End(canaryReq)
+	stableReq, stableCtx := registry.Begin(context.Background(), ActiveRequestInfo{APIKeyID: 6})
+	defer registry.End(stableReq)
+
+	_, mp, _ := svc.GetGatewayForwardingSettings(canaryCtx)
+	require.True(t, mp)
+	_, mp, _ = svc.GetGatewayForwardingSettings(stableCtx)
+	require.False(t, mp)
+	_, mp, _ = svc.GetGatewayForwardingSettings(context.Background())
+	require.False(t, mp)
+
+	RecordGatewayCanaryOutcome(canaryReq, 502)
+	RecordGatewayCanaryOutcome(stableReq, 200)
+	status, err := svc.GetGatewayCanaryStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status.Canary.Requests)
+	require.Equal(t, 1.0, status.Canary.ErrorRate)
+	require.Equal(t, int64(1), status.Stable.Requests)
+	require.Zero(t, status.Stable.Errors)
+
+	status, err = svc.PromoteGatewayCanary(context.Background())
+	require.NoError(t, err)
+	require.False(t, status.Settings.Enabled)
+	require.True(t, status.Settings.Candidate.IsEmpty())
+	require.Zero(t, status.Canary.Requests)
+	require.Equal(t, "true", repo.values[SettingKeyEnableMetadataPassthrough])
+	_, mp, _ = svc.GetGatewayForwardingSettings(context.Background())
+	require.True(t, mp)
+}
@@ -738,6 +738,9 @@ func (s *SettingService) IsBudgetRectifierEnabled(ctx context.Context) bool {
 
 // GetBetaPolicySettings 获取 Beta 策略配置
 func (s *SettingService) GetBetaPolicySettings(ctx context.Context) (*BetaPolicySettings, error) {
+	if candidate := gatewayCanaryCandidate(ctx, s.settingRepo); candidate != nil && candidate.BetaPolicy != nil {
+		return &BetaPolicySettings{Rules: append([]BetaPolicyRule(nil), candidate.BetaPolicy.Rules...)}, nil
+	}
 	value, err := s.settingRepo.GetValue(ctx, SettingKeyBetaPolicySettings)
 	if err != nil {
 		if errors.Is(err, ErrSettingNotFound) {
@@ -763,6 +766,20 @@ func (s *SettingService) SetBetaPolicySettings(ctx context.Context, settings *Be
 		return fmt.Errorf("settings cannot be nil")
 	}
 
+	if err := validateBetaPolicySettings(settings); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal beta policy settings: %w", err)
+	}
+
+	return s.settingRepo.Set(ctx, SettingKeyBetaPolicySettings, string(data))
+}
+
+// validateBetaPolicySettings 校验 Beta 策略规则并就地规范化 model_whitelist
+func validateBetaPolicySettings(settings *BetaPolicySettings) error {
 	validActions := map[string]bool{
 		BetaPolicyActionPass: true, BetaPolicyActionFilter: true, BetaPolicyActionBlock: true,
 	}
@@ -793,13 +810,7 @@ func (s *SettingService) SetBetaPolicySettings(ctx context.Context, settings *Be
 			return fmt.Errorf("rule[%d]: invalid fallback_action %q", i, rule.FallbackAction)
 		}
 	}
-
-	data, err := json.Marshal(settings)
-	if err != nil {
-		return fmt.Errorf("marshal beta policy settings: %w", err)
-	}
-
-	return s.settingRepo.Set(ctx, SettingKeyBetaPolicySettings, string(data))
+	return nil
 }
 
 // GetOpenAIFastPolicySettings 获取 OpenAI fast 策略配置
@@ -702,35 +702,44 @@ func (s *SettingService) getGatewayForwardingSettingsCached(ctx context.Context)
 	return gatewayForwardingSettingsResult{fp: true, claudeOAuthSystemPromptInjection: true, clientDatelineNormalization: true}
 }
 
+// gatewayForwardingSettings 返回本请求生效的转发设置：灰度分组请求叠加候选值（见 GatewayCanarySettings）。
+func (s *SettingService) gatewayForwardingSettings(ctx context.Context) gatewayForwardingSettingsResult {
+	result := s.getGatewayForwardingSettingsCached(ctx)
+	if candidate := gatewayCanaryCandidate(ctx, s.settingRepo); candidate != nil {
+		candidate.applyForwarding(&result)
+	}
+	return result
+}
+
 // GetGatewayForwardingSettings returns cached gateway forwarding settings.
 // Uses in-process atomic.Value cache with 60s TTL, zero-lock hot path.
 // Returns (fingerprintUnification, metadataPassthrough, cchSigning).
 func (s *SettingService) GetGatewayForwardingSettings(ctx context.Context) (fingerprintUnification, metadataPassthrough, cchSigning bool) {
-	result := s.getGatewayForwardingSettingsCached(ctx)
+	result := s.gatewayForwardingSettings(ctx)
 	return result.fp, result.mp, result.cch
 }
 
 // IsAnthropicCacheTTL1hInjectionEnabled 检查是否对 Anthropic OAuth/SetupToken 请求体注入 1h cache_control ttl。
 func (s *SettingService) IsAnthropicCacheTTL1hInjectionEnabled(ctx context.Context) bool {
-	return s.getGatewayForwardingSettingsCached(ctx).cacheTTL1h
+	return s.gatewayForwardingSettings(ctx).cacheTTL1h
 }
 
 // IsRewriteMessageCacheControlEnabled 检查是否启用 messages cache_control 改写。
 func (s *SettingService) IsRewriteMessageCacheControlEnabled(ctx context.Context) bool {
-	return s.getGatewayForwardingSettingsCached(ctx).rewriteMessageCacheControl
+	return s.gatewayForwardingSettings(ctx).rewriteMessageCacheControl
 }
 
 // IsClientDatelineNormalizationEnabled 检查是否启用 Anthropic OAuth/SetupToken 请求体
 // 的客户端 dateline 归一化。默认开启。
 func (s *SettingService) IsClientDatelineNormalizationEnabled(ctx context.Context) bool {
-	return s.getGatewayForwardingSettingsCached(ctx).clientDatelineNormalization
+	return s.gatewayForwardingSettings(ctx).clientDatelineNormalization
 }
 
 // GetClaudeOAuthSystemPromptInjectionSettings returns the Claude OAuth mimic
 // system block switch, legacy custom expansion prompt, and configurable blocks JSON.
 // Empty values mean use the built-in Claude Code default blocks.
 func (s *SettingService) GetClaudeOAuthSystemPromptInjectionSettings(ctx context.Context) (enabled bool, prompt string, blocks string) {
-	result := s.getGatewayForwardingSettingsCached(ctx)
+	result := s.gatewayForwardingSettings(ctx)
 	return result.claudeOAuthSystemPromptInjection, result.claudeOAuthSystemPrompt, result.claudeOAuthSystemPromptBlocks
 }
 
@@ -841,6 +850,21 @@ func (s *SettingService) WarmOpenAIQuotaAutoPauseSettings(ctx context.Context) O
 	return cached.settings
 }
 
+// WarmGatewayRuntimeSettings 同步加载网关热路径使用的运行时设置缓存，供启动预热调用，
+// 避免发布后的首批请求各自回源数据库。
+func (s *SettingService) WarmGatewayRuntimeSettings(ctx context.Context) {
+	if s == nil || s.settingRepo == nil {
+		return
+	}
+	s.IsBackendModeEnabled(ctx)
+	s.getGatewayForwardingSettingsCached(ctx)
+	s.GetClaudeCodeVersionBounds(ctx)
+	s.GetCyberSessionBlockRuntime(ctx)
+	s.GetAntigravityUserAgentVersion(ctx)
+	s.GetOpenAICodexUserAgent(ctx)
+	s.WarmOpenAIQuotaAutoPauseSettings(ctx)
+}
+
 // refreshOpenAIQuotaAutoPauseSettings reads the latest settings from the DB and stores
 // them into the in-memory cache. On error it stores the prior value (or zero defaults
 // if nothing is cached yet) with the shorter error TTL so the next refresh comes
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// SettingKeySettingsGovernance 设置分类编辑权限与审批策略（JSON）
+const SettingKeySettingsGovernance = "settings_governance"
+
+// 系统设置分类。未归类的键落入 general。
+const (
+	SettingCategoryGateway  = "gateway"  // 网关转发、调度、客户端限制
+	SettingCategoryBilling  = "billing"  // 余额、订阅、返利、支付
+	SettingCategorySecurity = "security" // 注册登录、第三方登录、验证码、step-up 等
+	SettingCategoryGeneral  = "general"  // 站点展示、运维监控等其余设置
+)
+
+// SettingCategories 全部设置分类（稳定顺序，用于展示与校验）。
+var SettingCategories = []string{
+	SettingCategoryGateway,
+	SettingCategoryBilling,
+	SettingCategorySecurity,
+	SettingCategoryGeneral,
+}
+
+// 设置变更申请类型
+const (
+	SettingChangeKindSettings   = "settings"   // PUT /admin/settings 的完整请求体
+	SettingChangeKindGovernance = "governance" // 权限与审批策略本身
+)
+
+// 设置变更申请状态
+const (
+	SettingChangeStatusPending  = "pending"
+	SettingChangeStatusApproved = "approved"
+	SettingChangeStatusRejected = "rejected"
+)
+
+var (
+	ErrSettingCategoryForbidden       = infraerrors.Forbidden("SETTINGS_CATEGORY_FORBIDDEN", "you are not allowed to edit this settings category")
+	ErrSettingsGovernanceInvalid      = infraerrors.BadRequest("SETTINGS_GOVERNANCE_INVALID", "invalid settings governance policy")
+	ErrSettingChangeRequestNotFound   = infraerrors.NotFound("SETTING_CHANGE_REQUEST_NOT_FOUND", "setting change request not found")
+	ErrSettingChangeRequestNotPending = infraerrors.Conflict("SETTING_CHANGE_REQUEST_NOT_PENDING", "setting change request has already been reviewed")
+	ErrSettingChangeRequestSelfReview = infraerrors.Forbidden("SETTING_CHANGE_REQUEST_SELF_APPROVAL", "a different admin must approve this change")
+	ErrSettingChangeRequestStale      = infraerrors.Conflict("SETTING_CHANGE_REQUEST_STALE", "settings changed since the request was submitted; submit a new request")
+)
+
+// settingCategoryExact 精确匹配的分类（优先于前缀规则）。
+var settingCategoryExact = map[string]string{
+	"email_verify_enabled":              SettingCategorySecurity,
+	"invitation_code_enabled":           SettingCategorySecurity,
+	"password_reset_enabled":            SettingCategorySecurity,
+	"totp_enabled":                      SettingCategorySecurity,
+	"session_binding_enabled":           SettingCategorySecurity,
+	"step_up_enabled":                   SettingCategorySecurity,
+	"api_key_acl_trust_forwarded_ip":    SettingCategorySecurity,
+	"forwarded_client_ip_headers":       SettingCategorySecurity,
+	"force_email_on_third_party_signup": SettingCategorySecurity,
+	"risk_control_enabled":              SettingCategorySecurity,
+	"backend_mode_enabled":              SettingCategorySecurity,
+
+	"default_concurrency":                SettingCategoryBilling,
+	"default_balance":                    SettingCategoryBilling,
+	"default_subscriptions":              SettingCategoryBilling,
+	SettingKeyDefaultPlatformQuotas:      SettingCategoryBilling,
+	"promo_code_enabled":                 SettingCategoryBilling,
+	"subscription_expiry_notify_enabled": SettingCategoryBilling,
+	"payment_config":                     SettingCategoryBilling,
+
+	"enable_model_fallback":                   SettingCategoryGateway,
+	"enable_identity_patch":                   SettingCategoryGateway,
+	"identity_patch_prompt":                   SettingCategoryGateway,
+	"allow_ungrouped_key_scheduling":          SettingCategoryGateway,
+	"enable_fingerprint_unification":          SettingCategoryGateway,
+	"enable_metadata_passthrough":             SettingCategoryGateway,
+	"enable_cch_signing":                      SettingCategoryGateway,
+	"enable_anthropic_cache_ttl_1h_injection": SettingCategoryGateway,
+	"rewrite_message_cache_control":           SettingCategoryGateway,
+	"enable_client_dateline_normalization":    SettingCategoryGateway,
+	"antigravity_user_agent_version":          SettingCategoryGateway,
+}
+
+// settingCategoryPrefixes 前缀规则，按顺序匹配。
+var settingCategoryPrefixes = []struct {
+	prefix   string
+	category string
+}{
+	{"registration_", SettingCategorySecurity},
+	{"login_agreement_", SettingCategorySecurity},
+	{"smtp_", SettingCategorySecurity},
+	{"turnstile_", SettingCategorySecurity},
+	{"linuxdo_connect_", SettingCategorySecurity},
+	{"dingtalk_connect_", SettingCategorySecurity},
+	{"wechat_connect_", SettingCategorySecurity},
+	{"oidc_connect_", SettingCategorySecurity},
+	{"github_oauth_", SettingCategorySecurity},
+	{"google_oauth_", SettingCategorySecurity},
+	{"cyber_session_block_", SettingCategorySecurity},
+	{"admin_api_key", SettingCategorySecurity},
+
+	{"auth_source_default_", SettingCategoryBilling},
+	{"affiliate_", SettingCategoryBilling},
+	{"payment_", SettingCategoryBilling},
+	{"purchase_subscription_", SettingCategoryBilling},
+	{"balance_low_notify_", SettingCategoryBilling},
+	{"account_quota_notify_", SettingCategoryBilling},
+
+	{"fallback_model_", SettingCategoryGateway},
+	{"min_claude_code_version", SettingCategoryGateway},
+	{"max_claude_code_version", SettingCategoryGateway},
+	{"min_codex_version", SettingCategoryGateway},
+	{"max_codex_version", SettingCategoryGateway},
+	{"codex_cli_only_", SettingCategoryGateway},
+	{"enable_claude_oauth_", SettingCategoryGateway},
+	{"claude_oauth_", SettingCategoryGateway},
+	{"openai_", SettingCategoryGateway},
+	{"channel_monitor_", SettingCategoryGateway},
+}
+
+// SettingCategoryForKey 返回设置键所属分类；键名与 PUT /admin/settings 的 JSON 字段一致。
+func SettingCategoryForKey(key string) string {
+	key = strings.TrimSpace(key)
+	if category, ok := settingCategoryExact[key]; ok {
+		return category
+	}
+	for _, rule := range settingCategoryPrefixes {
+		if strings.HasPrefix(key, rule.prefix) {
+			return rule.category
+		}
+	}
+	return SettingCategoryGeneral
+}
+
+// SettingCategoriesForKeys 返回一组设置键涉及的分类（去重，按 SettingCategories 顺序）。
+func SettingCategoriesForKeys(keys []string) []string {
+	seen := make(map[string]bool, len(SettingCategories))
+	for _, key := range keys {
+		seen[SettingCategoryForKey(key)] = true
+	}
+	out := make([]string, 0, len(seen))
+	for _, category := range SettingCategories {
+		if seen[category] {
+			out = append(out, category)
+		}
+	}
+	return out
+}
+
+func isSettingCategory(category string) bool {
+	for _, c := range SettingCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// SettingsGovernancePolicy 设置分类编辑权限与审批策略。
+//
+// 系统只有 admin / user 两种角色，因此编辑权限绑定到管理员用户 ID：
+// 分类未出现在 CategoryEditors 中（或列表为空）时所有管理员均可编辑。
+type SettingsGovernancePolicy struct {
+	CategoryEditors map[string][]int64 `json:"category_editors"`
+	// RequireSecurityApproval 开启后 security 分类的变更进入待审批队列，需另一位管理员批准后生效
+	RequireSecurityApproval bool `json:"require_security_approval"`
+}
+
+// CanEdit 判断管理员是否可编辑该分类。
+func (p *SettingsGovernancePolicy) CanEdit(userID int64, category string) bool {
+	if p == nil {
+		return true
+	}
+	editors := p.CategoryEditors[category]
+	if len(editors) == 0 {
+		return true
+	}
+	for _, id := range editors {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// DeniedCategories 返回 categories 中该管理员无权编辑的分类。
+func (p *SettingsGovernancePolicy) DeniedCategories(userID int64, categories []string) []string {
+	var denied []string
+	for _, category := range categories {
+		if !p.CanEdit(userID, category) {
+			denied = append(denied, category)
+		}
+	}
+	return denied
+}
+
+// RequiresApproval 判断一组分类的变更是否需要审批。
+func (p *SettingsGovernancePolicy) RequiresApproval(categories []string) bool {
+	if p == nil || !p.RequireSecurityApproval {
+		return false
+	}
+	for _, category := range categories {
+		if category == SettingCategorySecurity {
+			return true
+		}
+	}
+	return false
+}
+
+// SettingChangeRequest 待审批的设置变更。
+type SettingChangeRequest struct {
+	ID          int64    `json:"id"`
+	Kind        string   `json:"kind"`
+	Status      string   `json:"status"`
+	Categories  []string `json:"categories"`
+	ChangedKeys []string `json:"changed_keys"`
+	// Payload 申请时的请求体，批准时原样重放；可能含密钥，对外展示前需脱敏
+	Payload     json.RawMessage `json:"payload"`
+	RequestedBy int64           `json:"requested_by"`
+	ReviewedBy  *int64          `json:"reviewed_by,omitempty"`
+	ReviewNote  string          `json:"review_note"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ReviewedAt  *time.Time      `json:"reviewed_at,omitempty"`
+}
+
+// SettingChangeRequestList 分页结果。
+type SettingChangeRequestList struct {
+	Items    []*SettingChangeRequest
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// SettingChangeRequestRepository 设置变更申请持久化端口。
+type SettingChangeRequestRepository interface {
+	Create(ctx context.Context, req *SettingChangeRequest) (*SettingChangeRequest, error)
+	GetByID(ctx context.Context, id int64) (*SettingChangeRequest, error)
+	List(ctx context.Context, status string, page, pageSize int) (*SettingChangeRequestList, error)
+	// Review 仅在申请仍为 pending 时更新状态；否则返回 ErrSettingChangeRequestNotPending。
+	Review(ctx context.Context, id int64, status string, reviewerID int64, note string) (*SettingChangeRequest, error)
+}
+
+// SettingGovernanceService 管理设置分类权限、审批策略与待审批队列。
+type SettingGovernanceService struct {
+	settingRepo SettingRepository
+	changeRepo  SettingChangeRequestRepository
+}
+
+// NewSettingGovernanceService 创建设置治理服务。
+func NewSettingGovernanceService(settingRepo SettingRepository, changeRepo SettingChangeRequestRepository) *SettingGovernanceService {
+	return &SettingGovernanceService{settingRepo: settingRepo, changeRepo: changeRepo}
+}
+
+// GetPolicy 读取当前策略；未配置时返回空策略（所有管理员可编辑、无需审批）。
+// 策略损坏时按最严格处理：要求 security 审批，避免解析失败导致门控静默失效。
+func (s *SettingGovernanceService) GetPolicy(ctx context.Context) (*SettingsGovernancePolicy, error) {
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeySettingsGovernance)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return &SettingsGovernancePolicy{CategoryEditors: map[string][]int64{}}, nil
+		}
+		return nil, err
+	}
+	policy := &SettingsGovernancePolicy{}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), policy); err != nil {
+			logger.LegacyPrintf("service.setting_governance", "[SettingGovernance] invalid policy JSON, requiring security approval: %v", err)
+			return &SettingsGovernancePolicy{CategoryEditors: map[string][]int64{}, RequireSecurityApproval: true}, nil
+		}
+	}
+	if policy.CategoryEditors == nil {
+		policy.CategoryEditors = map[string][]int64{}
+	}
+	return policy, nil
+}
+
+// NormalizePolicy 校验并规范化策略：分类必须合法，编辑者去重排序；
+// 开启审批且限定了 security 编辑者时至少需要两人，否则没有人能批准。
+// actorID 必须保留 security 编辑权，避免操作者把自己锁在策略之外。
+func (s *SettingGovernanceService) NormalizePolicy(policy *SettingsGovernancePolicy, actorID int64) (*SettingsGovernancePolicy, error) {
+	if policy == nil {
+		return nil, ErrSettingsGovernanceInvalid
+	}
+	out := &SettingsGovernancePolicy{
+		CategoryEditors:         make(map[string][]int64, len(policy.CategoryEditors)),
+		RequireSecurityApproval: policy.RequireSecurityApproval,
+	}
+	for category, editors := range policy.CategoryEditors {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if !isSettingCategory(category) {
+			return nil, ErrSettingsGovernanceInvalid.WithMetadata(map[string]string{"category": category})
+		}
+		seen := make(map[int64]bool, len(editors))
+		ids := make([]int64, 0, len(editors))
+		for _, id := range editors {
+			if id <= 0 {
+				return nil, ErrSettingsGovernanceInvalid.WithMetadata(map[string]string{"category": category})
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		out.CategoryEditors[category] = ids
+	}
+	if editors := out.CategoryEditors[SettingCategorySecurity]; out.RequireSecurityApproval && len(editors) == 1 {
+		return nil, infraerrors.BadRequest("SETTINGS_GOVERNANCE_INVALID", "security approval requires at least two security editors")
+	}
+	if !out.CanEdit(actorID, SettingCategorySecurity) {
+		return nil, infraerrors.BadRequest("SETTINGS_GOVERNANCE_INVALID", "the policy must keep you as a security editor")
+	}
+	return out, nil
+}
+
+// SavePolicy 持久化已规范化的策略。
+func (s *SettingGovernanceService) SavePolicy(ctx context.Context, policy *SettingsGovernancePolicy) error {
+	raw, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("marshal settings governance policy: %w", err)
+	}
+	return s.settingRepo.Set(ctx, SettingKeySettingsGovernance, string(raw))
+}
+
+// Submit 创建待审批的设置变更申请。
+func (s *SettingGovernanceService) Submit(ctx context.Context, kind string, requesterID int64, changedKeys []string, payload []byte) (*SettingChangeRequest, error) {
+	keys := append([]string(nil), changedKeys...)
+	sort.Strings(keys)
+	categories := SettingCategoriesForKeys(keys)
+	if kind == SettingChangeKindGovernance {
+		categories = []string{SettingCategorySecurity}
+	}
+	return s.changeRepo.Create(ctx, &SettingChangeRequest{
+		Kind:        kind,
+		Status:      SettingChangeStatusPending,
+		Categories:  categories,
+		ChangedKeys: keys,
+		Payload:     payload,
+		RequestedBy: requesterID,
+	})
+}
+
+// Get 查询单个变更申请。
+func (s *SettingGovernanceService) Get(ctx context.Context, id int64) (*SettingChangeRequest, error) {
+	return s.changeRepo.GetByID(ctx, id)
+}
+
+// List 分页查询变更申请；status 为空时返回全部。
+func (s *SettingGovernanceService) List(ctx context.Context, status string, page, pageSize int) (*SettingChangeRequestList, error) {
+	return s.changeRepo.List(ctx, strings.TrimSpace(status), page, pageSize)
+}
+
+// CheckReviewer 校验审批人：申请仍待审批、审批人可编辑涉及的全部分类；
+// approve 时审批人不得是申请人（拒绝则允许申请人自行撤回）。
+func (s *SettingGovernanceService) CheckReviewer(ctx context.Context, req *SettingChangeRequest, reviewerID int64, approve bool) error {
+	if req.Status != SettingChangeStatusPending {
+		return ErrSettingChangeRequestNotPending
+	}
+	if approve && req.RequestedBy == reviewerID {
+		return ErrSettingChangeRequestSelfReview
+	}
+	policy, err := s.GetPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	if denied := policy.DeniedCategories(reviewerID, req.Categories); len(denied) > 0 {
+		return ErrSettingCategoryForbidden.WithMetadata(map[string]string{"categories": strings.Join(denied, ",")})
+	}
+	return nil
+}
+
+// Review 把申请标记为已批准/已拒绝。
+func (s *SettingGovernanceService) Review(ctx context.Context, id int64, approve bool, reviewerID int64, note string) (*SettingChangeRequest, error) {
+	status := SettingChangeStatusRejected
+	if approve {
+		status = SettingChangeStatusApproved
+	}
+	return s.changeRepo.Review(ctx, id, status, reviewerID, strings.TrimSpace(note))
+}
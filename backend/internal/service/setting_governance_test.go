@@ -0,0 +1,136 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type settingGovernanceRepoStub struct {
+	SettingRepository
+	values map[string]string
+}
+
+func (s *settingGovernanceRepoStub) GetValue(ctx context.Context, key string) (string, error) {
+	value, ok := s.values[key]
+	if !ok {
+		return "", ErrSettingNotFound
+	}
+	return value, nil
+}
+
+func (s *settingGovernanceRepoStub) Set(ctx context.Context, key, value string) error {
+	s.values[key] = value
+	return nil
+}
+
+func TestSettingCategoryForKey(t *testing.T) {
+	cases := map[string]string{
+		"step_up_enabled":                        SettingCategorySecurity,
+		"registration_enabled":                   SettingCategorySecurity,
+		"oidc_connect_client_secret":             SettingCategorySecurity,
+		"smtp_password":                          SettingCategorySecurity,
+		"default_balance":                        SettingCategoryBilling,
+		"auth_source_default_email_balance":      SettingCategoryBilling,
+		"payment_config":                         SettingCategoryBilling,
+		SettingKeyDefaultPlatformQuotas:          SettingCategoryBilling,
+		"openai_advanced_scheduler_lb_top_k":     SettingCategoryGateway,
+		SettingKeyOpenAIFastPolicySettings:       SettingCategoryGateway,
+		"fallback_model_openai":                  SettingCategoryGateway,
+		"min_claude_code_version":                SettingCategoryGateway,
+		"site_name":                              SettingCategoryGeneral,
+		"table_default_page_size":                SettingCategoryGeneral,
+		"some_future_setting_not_yet_classified": SettingCategoryGeneral,
+	}
+	for key, want := range cases {
+		require.Equal(t, want, SettingCategoryForKey(key), key)
+	}
+
+	require.Equal(t, []string{SettingCategoryBilling, SettingCategorySecurity, SettingCategoryGeneral},
+		SettingCategoriesForKeys([]string{"site_name", "totp_enabled", "default_balance", "site_logo"}))
+}
+
+func TestSettingsGovernancePolicy_CanEditAndApproval(t *testing.T) {
+	var nilPolicy *SettingsGovernancePolicy
+	require.True(t, nilPolicy.CanEdit(1, SettingCategorySecurity))
+	require.False(t, nilPolicy.RequiresApproval([]string{SettingCategorySecurity}))
+
+	policy := &SettingsGovernancePolicy{
+		CategoryEditors:         map[string][]int64{SettingCategorySecurity: {1, 2}},
+		RequireSecurityApproval: true,
+	}
+	require.True(t, policy.CanEdit(1, SettingCategorySecurity))
+	require.False(t, policy.CanEdit(3, SettingCategorySecurity))
+	require.True(t, policy.CanEdit(3, SettingCategoryGateway), "unrestricted categories are open to all admins")
+	require.Equal(t, []string{SettingCategorySecurity}, policy.DeniedCategories(3, []string{SettingCategoryGateway, SettingCategorySecurity}))
+	require.True(t, policy.RequiresApproval([]string{SettingCategoryGateway, SettingCategorySecurity}))
+	require.False(t, policy.RequiresApproval([]string{SettingCategoryBilling}))
+}
+
+func TestSettingGovernanceService_NormalizePolicy(t *testing.T) {
+	svc := NewSettingGovernanceService(&settingGovernanceRepoStub{values: map[string]string{}}, nil)
+
+	policy, err := svc.NormalizePolicy(&SettingsGovernancePolicy{
+		CategoryEditors: map[string][]int64{" Security ": {2, 1, 2}, "billing": {}},
+	}, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]int64{SettingCategorySecurity: {1, 2}}, policy.CategoryEditors)
+
+	_, err = svc.NormalizePolicy(&SettingsGovernancePolicy{CategoryEditors: map[string][]int64{"sora": {1}}}, 1)
+	require.ErrorIs(t, err, ErrSettingsGovernanceInvalid)
+
+	_, err = svc.NormalizePolicy(&SettingsGovernancePolicy{
+		CategoryEditors:         map[string][]int64{SettingCategorySecurity: {1}},
+		RequireSecurityApproval: true,
+	}, 1)
+	require.Error(t, err, "a single security editor can never get approval")
+
+	_, err = svc.NormalizePolicy(&SettingsGovernancePolicy{
+		CategoryEditors: map[string][]int64{SettingCategorySecurity: {2, 3}},
+	}, 1)
+	require.Error(t, err, "actor must not lock themselves out")
+}
+
+func TestSettingGovernanceService_GetPolicy(t *testing.T) {
+	repo := &settingGovernanceRepoStub{values: map[string]string{}}
+	svc := NewSettingGovernanceService(repo, nil)
+
+	policy, err := svc.GetPolicy(context.Background())
+	require.NoError(t, err)
+	require.False(t, policy.RequireSecurityApproval)
+	require.Empty(t, policy.CategoryEditors)
+
+	require.NoError(t, svc.SavePolicy(context.Background(), &SettingsGovernancePolicy{
+		CategoryEditors:         map[string][]int64{SettingCategorySecurity: {1, 2}},
+		RequireSecurityApproval: true,
+	}))
+	policy, err = svc.GetPolicy(context.Background())
+	require.NoError(t, err)
+	require.True(t, policy.RequireSecurityApproval)
+	require.Equal(t, []int64{1, 2}, policy.CategoryEditors[SettingCategorySecurity])
+
+	// 策略损坏时按最严格处理
+	repo.values[SettingKeySettingsGovernance] = "{broken"
+	policy, err = svc.GetPolicy(context.Background())
+	require.NoError(t, err)
+	require.True(t, policy.RequireSecurityApproval)
+}
+
+func TestSettingGovernanceService_CheckReviewer(t *testing.T) {
+	repo := &settingGovernanceRepoStub{values: map[string]string{
+		SettingKeySettingsGovernance: `{"category_editors":{"security":[1,2]},"require_security_approval":true}`,
+	}}
+	svc := NewSettingGovernanceService(repo, nil)
+	req := &SettingChangeRequest{Status: SettingChangeStatusPending, Categories: []string{SettingCategorySecurity}, RequestedBy: 1}
+
+	require.ErrorIs(t, svc.CheckReviewer(context.Background(), req, 1, true), ErrSettingChangeRequestSelfReview)
+	require.NoError(t, svc.CheckReviewer(context.Background(), req, 1, false), "requester may withdraw")
+	require.ErrorIs(t, svc.CheckReviewer(context.Background(), req, 3, true), ErrSettingCategoryForbidden)
+	require.NoError(t, svc.CheckReviewer(context.Background(), req, 2, true))
+
+	req.Status = SettingChangeStatusApproved
+	require.ErrorIs(t, svc.CheckReviewer(context.Background(), req, 2, true), ErrSettingChangeRequestNotPending)
+}
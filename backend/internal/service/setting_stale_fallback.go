@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// staleFallbackSettingRepository 为系统设置读取提供数据库故障兜底。
+//
+// 每次成功读取都会记录最后已知值（包括"键不存在"）；数据库降级期间直接返回记录值，
+// 未降级但读取失败时也会在 staleTTL 内回退到记录值，避免热路径上的设置读取
+// 因数据库短暂不可用而退化为安全默认值（例如放开本应生效的限制）。
+// 写操作始终直通数据库，成功后同步更新记录。
+type staleFallbackSettingRepository struct {
+	inner    SettingRepository
+	staleTTL time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]staleSettingEntry
+	// allAt 最近一次 GetAll 成功的时间；只有完整快照才能兜底 GetAll
+	allAt time.Time
+}
+
+type staleSettingEntry struct {
+	setting Setting
+	missing bool
+	at      time.Time
+}
+
+// NewStaleFallbackSettingRepository 包装设置仓储；staleTTL <= 0 时原样返回 inner。
+func NewStaleFallbackSettingRepository(inner SettingRepository, staleTTL time.Duration) SettingRepository {
+	if inner == nil || staleTTL <= 0 {
+		return inner
+	}
+	return &staleFallbackSettingRepository{
+		inner:    inner,
+		staleTTL: staleTTL,
+		entries:  make(map[string]staleSettingEntry),
+	}
+}
+
+// shouldFallback 仅对基础设施错误兜底；键不存在与调用方取消不属于数据库故障。
+func (r *staleFallbackSettingRepository) shouldFallback(ctx context.Context, err error) bool {
+	return err != nil && !errors.Is(err, ErrSettingNotFound) && ctx.Err() == nil
+}
+
+func (r *staleFallbackSettingRepository) lookup(key string, now time.Time) (staleSettingEntry, bool) {
+	e, ok := r.entries[key]
+	if !ok || now.Sub(e.at) > r.staleTTL {
+		return staleSettingEntry{}, false
+	}
+	return e, true
+}
+
+func (r *staleFallbackSettingRepository) remember(key string, setting *Setting, now time.Time) {
+	e := staleSettingEntry{at: now, missing: setting == nil}
+	if setting != nil {
+		e.setting = *setting
+	}
+	r.mu.Lock()
+	r.entries[key] = e
+	r.mu.Unlock()
+}
+
+func (r *staleFallbackSettingRepository) staleGet(key string) (staleSettingEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lookup(key, time.Now())
+}
+
+func (e staleSettingEntry) result() (*Setting, error) {
+	if e.missing {
+		return nil, ErrSettingNotFound
+	}
+	s := e.setting
+	return &s, nil
+}
+
+func (r *staleFallbackSettingRepository) Get(ctx context.Context, key string) (*Setting, error) {
+	if IsDatabaseDegraded() {
+		if e, ok := r.staleGet(key); ok {
+			return e.result()
+		}
+	}
+	s, err := r.inner.Get(ctx, key)
+	switch {
+	case err == nil:
+		r.remember(key, s, time.Now())
+	case errors.Is(err, ErrSettingNotFound):
+		r.remember(key, nil, time.Now())
+	case r.shouldFallback(ctx, err):
+		if e, ok := r.staleGet(key); ok {
+			return e.result()
+		}
+	}
+	return s, err
+}
+
+func (r *staleFallbackSettingRepository) GetValue(ctx context.Context, key string) (string, error) {
+	s, err := r.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return s.Value, nil
+}
+
+func (r *staleFallbackSettingRepository) staleGetMultiple(keys []string) (map[string]string, bool) {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]string, len(keys))
+	for _, key := range keys {
+		e, ok := r.lookup(key, now)
+		if !ok {
+			return nil, false
+		}
+		if !e.missing {
+			out[key] = e.setting.Value
+		}
+	}
+	return out, true
+}
+
+func (r *staleFallbackSettingRepository) GetMultiple(ctx context.Context, keys []string) (map[string]string, error) {
+	if IsDatabaseDegraded() && len(keys) > 0 {
+		if out, ok := r.staleGetMultiple(keys); ok {
+			return out, nil
+		}
+	}
+	values, err := r.inner.GetMultiple(ctx, keys)
+	if err != nil {
+		if r.shouldFallback(ctx, err) {
+			if out, ok := r.staleGetMultiple(keys); ok {
+				return out, nil
+			}
+		}
+		return values, err
+	}
+	now := time.Now()
+	r.mu.Lock()
+	for _, key := range keys {
+		if v, ok := values[key]; ok {
+			r.entries[key] = staleSettingEntry{setting: Setting{Key: key, Value: v}, at: now}
+		} else {
+			r.entries[key] = staleSettingEntry{missing: true, at: now}
+		}
+	}
+	r.mu.Unlock()
+	return values, nil
+}
+
+func (r *staleFallbackSettingRepository) staleGetAll() (map[string]string, bool) {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.allAt.IsZero() || now.Sub(r.allAt) > r.staleTTL {
+		return nil, false
+	}
+	out := make(map[string]string, len(r.entries))
+	for key, e := range r.entries {
+		if !e.missing {
+			out[key] = e.setting.Value
+		}
+	}
+	return out, true
+}
+
+func (r *staleFallbackSettingRepository) GetAll(ctx context.Context) (map[string]string, error) {
+	if IsDatabaseDegraded() {
+		if out, ok := r.staleGetAll(); ok {
+			return out, nil
+		}
+	}
+	values, err := r.inner.GetAll(ctx)
+	if err != nil {
+		if r.shouldFallback(ctx, err) {
+			if out, ok := r.staleGetAll(); ok {
+				return out, nil
+			}
+		}
+		return values, err
+	}
+	now := time.Now()
+	r.mu.Lock()
+	// 完整快照：不在结果中的键一律视为不存在
+	for key, e := range r.entries {
+		if _, ok := values[key]; !ok && !e.missing {
+			r.entries[key] = staleSettingEntry{missing: true, at: now}
+		}
+	}
+	for key, v := range values {
+		r.entries[key] = staleSettingEntry{setting: Setting{Key: key, Value: v}, at: now}
+	}
+	r.allAt = now
+	r.mu.Unlock()
+	return values, nil
+}
+
+func (r *staleFallbackSettingRepository) Set(ctx context.Context, key, value string) error {
+	if err := r.inner.Set(ctx, key, value); err != nil {
+		return err
+	}
+	r.remember(key, &Setting{Key: key, Value: value, UpdatedAt: time.Now()}, time.Now())
+	return nil
+}
+
+func (r *staleFallbackSettingRepository) SetMultiple(ctx context.Context, settings map[string]string) error {
+	if err := r.inner.SetMultiple(ctx, settings); err != nil {
+		return err
+	}
+	now := time.Now()
+	r.mu.Lock()
+	for key, v := range settings {
+		r.entries[key] = staleSettingEntry{setting: Setting{Key: key, Value: v, UpdatedAt: now}, at: now}
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *staleFallbackSettingRepository) Delete(ctx context.Context, key string) error {
+	if err := r.inner.Delete(ctx, key); err != nil {
+		return err
+	}
+	r.remember(key, nil, time.Now())
+	return nil
+}
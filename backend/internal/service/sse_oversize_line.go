@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// applySSEOversizeLinePolicy 按 gateway.sse_oversize_line 为上游 SSE scanner 设置超长行处理。
+// error 模式（默认）不做改动，超长行仍以 bufio.ErrTooLong 结束；
+// truncate 模式下超长行按流式 JSON 扫描，仅截断超过 keep_bytes 的字符串字段。
+func applySSEOversizeLinePolicy(scanner *bufio.Scanner, cfg *config.Config, maxLineSize int) {
+	if scanner == nil || cfg == nil || maxLineSize <= 0 {
+		return
+	}
+	oversize := cfg.Gateway.SSEOversizeLine
+	if strings.TrimSpace(oversize.Mode) != config.SSEOversizeLineModeTruncate || oversize.KeepBytes <= 0 {
+		return
+	}
+	splitter := &sseOversizeLineSplitter{maxLineSize: maxLineSize, keepBytes: oversize.KeepBytes}
+	scanner.Split(splitter.split)
+}
+
+// sseOversizeLineSplitter 行为与 bufio.ScanLines 一致；当缓冲区已满仍未遇到换行时，
+// 改为边读边截断地消费该行，截断后的结果仍超过 maxLineSize 才返回 bufio.ErrTooLong。
+type sseOversizeLineSplitter struct {
+	maxLineSize int
+	keepBytes   int
+
+	active    bool
+	out       []byte
+	truncator sseJSONStringTruncator
+}
+
+func (s *sseOversizeLineSplitter) split(data []byte, atEOF bool) (int, []byte, error) {
+	if !s.active {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			return i + 1, dropSSELineCR(data[:i]), nil
+		}
+		if atEOF {
+			if len(data) == 0 {
+				return 0, nil, nil
+			}
+			return len(data), dropSSELineCR(data), nil
+		}
+		if len(data) < s.maxLineSize {
+			return 0, nil, nil
+		}
+		s.active = true
+		s.out = nil
+		s.truncator = sseJSONStringTruncator{keep: s.keepBytes}
+	}
+
+	chunk, advance := data, len(data)
+	end := bytes.IndexByte(data, '\n')
+	if end >= 0 {
+		chunk, advance = data[:end], end+1
+	}
+	s.out = s.truncator.write(s.out, chunk)
+	if len(s.out) > s.maxLineSize {
+		s.active = false
+		return 0, nil, bufio.ErrTooLong
+	}
+	if end < 0 && !atEOF {
+		return advance, nil, nil
+	}
+
+	s.active = false
+	line := dropSSELineCR(s.out)
+	s.out = nil
+	logger.LegacyPrintf("service.gateway", "SSE line exceeded max_size=%d, truncated %d string field(s) dropping %d bytes",
+		s.maxLineSize, s.truncator.fields, s.truncator.dropped)
+	return advance, line, nil
+}
+
+func dropSSELineCR(line []byte) []byte {
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		return line[:len(line)-1]
+	}
+	return line
+}
+
+// sseJSONStringTruncator 逐字节复制 JSON 文本，字符串值超过 keep 字节后丢弃其余内容，
+// 并在闭合引号前追加 "...[truncated N bytes]"。截断点不会落在转义序列或 UTF-8 多字节字符中间，
+// 结构字符原样保留，因此截断后的事件仍是合法 JSON。
+type sseJSONStringTruncator struct {
+	keep int
+
+	inString bool
+	skipping bool
+	escaped  bool
+	hexLeft  int
+	kept     int
+	skipped  int
+
+	fields  int
+	dropped int64
+}
+
+func (t *sseJSONStringTruncator) write(dst, src []byte) []byte {
+	for _, b := range src {
+		switch {
+		case !t.inString:
+			dst = append(dst, b)
+			if b == '"' {
+				t.inString = true
+				t.kept, t.skipped = 0, 0
+			}
+		case t.skipping:
+			t.skipByte(b)
+			if !t.inString {
+				dst = fmt.Appendf(dst, "...[truncated %d bytes]\"", t.skipped)
+				t.fields++
+				t.dropped += int64(t.skipped)
+			}
+		case t.escaped:
+			t.escaped = false
+			if b == 'u' {
+				t.hexLeft = 4
+			}
+			dst = append(dst, b)
+			t.kept++
+		case t.hexLeft > 0:
+			t.hexLeft--
+			dst = append(dst, b)
+			t.kept++
+		case b == '"':
+			dst = append(dst, b)
+			t.inString = false
+		case t.kept >= t.keep && b&0xC0 != 0x80:
+			t.skipping = true
+			t.skipByte(b)
+		default:
+			if b == '\\' {
+				t.escaped = true
+			}
+			dst = append(dst, b)
+			t.kept++
+		}
+	}
+	return dst
+}
+
+// skipByte 在丢弃模式下消费一个字节，遇到未转义的闭合引号时结束当前字符串。
+func (t *sseJSONStringTruncator) skipByte(b byte) {
+	switch {
+	case t.escaped:
+		t.escaped = false
+		t.skipped++
+	case b == '\\':
+		t.escaped = true
+		t.skipped++
+	case b == '"':
+		t.inString = false
+		t.skipping = false
+	default:
+		t.skipped++
+	}
+}
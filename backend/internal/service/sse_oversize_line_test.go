@@ -0,0 +1,80 @@
+//go:build unit
+
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newOversizeTestScanner(body string, mode string, maxLineSize, keepBytes int) *bufio.Scanner {
+	cfg := &config.Config{}
+	cfg.Gateway.SSEOversizeLine = config.GatewaySSEOversizeLineConfig{Mode: mode, KeepBytes: keepBytes}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 16), maxLineSize)
+	applySSEOversizeLinePolicy(scanner, cfg, maxLineSize)
+	return scanner
+}
+
+func scanAllLines(t *testing.T, scanner *bufio.Scanner) ([]string, error) {
+	t.Helper()
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+func TestSSEOversizeLine_ErrorModeKeepsLegacyBehavior(t *testing.T) {
+	body := "event: ping\n" + `data: {"text":"` + strings.Repeat("a", 400) + `"}` + "\n"
+	lines, err := scanAllLines(t, newOversizeTestScanner(body, config.SSEOversizeLineModeError, 256, 32))
+	require.True(t, errors.Is(err, bufio.ErrTooLong))
+	require.Equal(t, []string{"event: ping"}, lines)
+}
+
+func TestSSEOversizeLine_TruncateModeCutsOnlyOversizeString(t *testing.T) {
+	payload := `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"` + strings.Repeat("é", 300) + `\"tail\"` + `"}}`
+	body := "event: content_block_delta\r\ndata: " + payload + "\r\n\r\nevent: message_stop\ndata: {\"type\":\"message_stop\"}\n"
+
+	lines, err := scanAllLines(t, newOversizeTestScanner(body, config.SSEOversizeLineModeTruncate, 256, 33))
+	require.NoError(t, err)
+	require.Len(t, lines, 5)
+	require.Equal(t, "event: content_block_delta", lines[0])
+	require.Equal(t, "", lines[2])
+	require.Equal(t, `data: {"type":"message_stop"}`, lines[4])
+
+	data := strings.TrimPrefix(lines[1], "data: ")
+	var event struct {
+		Type  string `json:"type"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(data), &event))
+	require.Equal(t, "content_block_delta", event.Type)
+	require.Equal(t, "text_delta", event.Delta.Type)
+	require.True(t, strings.HasPrefix(event.Delta.Text, strings.Repeat("é", 17)+"...[truncated "))
+	require.True(t, strings.HasSuffix(event.Delta.Text, " bytes]"))
+}
+
+func TestSSEOversizeLine_TruncateModeFailsWhenStructureStillTooLarge(t *testing.T) {
+	body := "data: [" + strings.Repeat("1,", 300) + "1]\n"
+	_, err := scanAllLines(t, newOversizeTestScanner(body, config.SSEOversizeLineModeTruncate, 256, 32))
+	require.True(t, errors.Is(err, bufio.ErrTooLong))
+}
+
+func TestSSEJSONStringTruncator_DoesNotSplitEscapes(t *testing.T) {
+	tr := sseJSONStringTruncator{keep: 3}
+	out := tr.write(nil, []byte(`{"k":"abécdef\\gh"}`))
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	require.Equal(t, "abé...[truncated 8 bytes]", decoded["k"])
+	require.Equal(t, 1, tr.fields)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// sseResumeRecorderQueueSize 单个流待写入 Redis 的事件队列长度，写满视为缓存不完整
+	sseResumeRecorderQueueSize = 1024
+	// sseResumeRecorderBatchSize 单次批量写入的最大事件数
+	sseResumeRecorderBatchSize = 64
+	// sseResumeCacheOpTimeout 单次 Redis 操作超时
+	sseResumeCacheOpTimeout = 2 * time.Second
+	// sseResumeStreamIDBytes 流 ID 随机字节数
+	sseResumeStreamIDBytes = 12
+)
+
+// SSEResumeEvent 一条已分配 ID 的 SSE 事件（Data 为完整事件文本，含 id 行与结尾空行）
+type SSEResumeEvent struct {
+	Seq  int64
+	Data []byte
+}
+
+// SSEResumeSnapshot 续传缓存中某个流的当前状态
+type SSEResumeSnapshot struct {
+	OwnerAPIKeyID int64
+	Done          bool
+	Events        []SSEResumeEvent
+}
+
+// SSEResumeCache 续传事件缓存
+type SSEResumeCache interface {
+	InitStream(ctx context.Context, streamID string, ownerAPIKeyID int64, ttl time.Duration) error
+	AppendEvents(ctx context.Context, streamID string, events []SSEResumeEvent, maxEvents int, ttl time.Duration) error
+	FinishStream(ctx context.Context, streamID string, ttl time.Duration) error
+	DeleteStream(ctx context.Context, streamID string) error
+	// LoadStream 读取流状态；流不存在或已过期时返回 nil, nil
+	LoadStream(ctx context.Context, streamID string) (*SSEResumeSnapshot, error)
+}
+
+// SSEResumeService 流式响应断线续传：分配事件 ID、异步缓存事件、按 Last-Event-ID 回放。
+type SSEResumeService struct {
+	cache SSEResumeCache
+	cfg   config.GatewaySSEResumeConfig
+}
+
+// NewSSEResumeService 创建续传服务；未启用或缺少缓存时 Enabled 返回 false。
+func NewSSEResumeService(cache SSEResumeCache, cfg *config.Config) *SSEResumeService {
+	s := &SSEResumeService{cache: cache}
+	if cfg != nil {
+		s.cfg = cfg.Gateway.SSEResume
+	}
+	return s
+}
+
+// Enabled 续传是否启用
+func (s *SSEResumeService) Enabled() bool {
+	return s != nil && s.cache != nil && s.cfg.Enabled
+}
+
+// TailIdleTimeout 续传仍在生成的流时，无新事件的最长等待时间
+func (s *SSEResumeService) TailIdleTimeout() time.Duration {
+	return time.Duration(s.cfg.TailIdleTimeoutSeconds) * time.Second
+}
+
+// DisconnectGrace 客户端断开后继续生成（等待续传）的最长时间
+func (s *SSEResumeService) DisconnectGrace() time.Duration {
+	return time.Duration(s.cfg.DisconnectGraceSeconds) * time.Second
+}
+
+func (s *SSEResumeService) ttl() time.Duration {
+	return time.Duration(s.cfg.TTLSeconds) * time.Second
+}
+
+// NewSSEResumeStreamID 生成新的流 ID
+func NewSSEResumeStreamID() string {
+	buf := make([]byte, sseResumeStreamIDBytes)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// FormatSSEResumeEventID 组装事件 ID："<stream_id>-<seq>"
+func FormatSSEResumeEventID(streamID string, seq int64) string {
+	return streamID + "-" + strconv.FormatInt(seq, 10)
+}
+
+// ParseSSEResumeEventID 解析 Last-Event-ID；格式不符时 ok=false
+func ParseSSEResumeEventID(raw string) (streamID string, seq int64, ok bool) {
+	raw = strings.TrimSpace(raw)
+	idx := strings.LastIndexByte(raw, '-')
+	if idx <= 0 || idx == len(raw)-1 {
+		return "", 0, false
+	}
+	streamID = raw[:idx]
+	if len(streamID) != sseResumeStreamIDBytes*2 {
+		return "", 0, false
+	}
+	if _, err := hex.DecodeString(streamID); err != nil {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseInt(raw[idx+1:], 10, 64)
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+	return streamID, seq, true
+}
+
+// LoadResumable 读取属于 apiKeyID 的流在 afterSeq 之后的事件。
+// 流不存在、不属于该 Key，或所需事件已被淘汰（无法无缝续传）时返回 ok=false，调用方应按新请求处理。
+func (s *SSEResumeService) LoadResumable(ctx context.Context, streamID string, apiKeyID int64, afterSeq int64) (events []SSEResumeEvent, done bool, ok bool) {
+	if !s.Enabled() {
+		return nil, false, false
+	}
+	opCtx, cancel := context.WithTimeout(ctx, sseResumeCacheOpTimeout)
+	defer cancel()
+	snap, err := s.cache.LoadStream(opCtx, streamID)
+	if err != nil {
+		logger.LegacyPrintf("service.sse_resume", "[SSEResume] load stream failed: stream=%s err=%v", streamID, err)
+		return nil, false, false
+	}
+	if snap == nil || snap.OwnerAPIKeyID != apiKeyID {
+		return nil, false, false
+	}
+	if len(snap.Events) > 0 && snap.Events[0].Seq > afterSeq+1 {
+		return nil, false, false
+	}
+	for _, ev := range snap.Events {
+		if ev.Seq > afterSeq {
+			events = append(events, ev)
+		}
+	}
+	return events, snap.Done, true
+}
+
+// SSEResumeRecorder 将单个流的事件异步写入续传缓存。
+// 写入队列满或 Redis 写入失败时该流被标记为不完整并在结束时删除，避免客户端续传到有缺口的结果。
+type SSEResumeRecorder struct {
+	svc      *SSEResumeService
+	streamID string
+	ch       chan SSEResumeEvent
+	done     chan struct{}
+	broken   atomic.Bool
+	closed   atomic.Bool
+}
+
+// StartRecording 为新流启动后台写入；服务未启用时返回 nil（nil 接收者的方法均为空操作）。
+func (s *SSEResumeService) StartRecording(streamID string, ownerAPIKeyID int64) *SSEResumeRecorder {
+	if !s.Enabled() {
+		return nil
+	}
+	r := &SSEResumeRecorder{
+		svc:      s,
+		streamID: streamID,
+		ch:       make(chan SSEResumeEvent, sseResumeRecorderQueueSize),
+		done:     make(chan struct{}),
+	}
+	go r.run(ownerAPIKeyID)
+	return r
+}
+
+// Record 非阻塞提交一条事件
+func (r *SSEResumeRecorder) Record(seq int64, data []byte) {
+	if r == nil || r.broken.Load() || r.closed.Load() {
+		return
+	}
+	select {
+	case r.ch <- SSEResumeEvent{Seq: seq, Data: data}:
+	default:
+		r.broken.Store(true)
+	}
+}
+
+// Close 标记流结束；已入队事件在后台写完后再标记完成。
+func (r *SSEResumeRecorder) Close() {
+	if r == nil || !r.closed.CompareAndSwap(false, true) {
+		return
+	}
+	close(r.ch)
+}
+
+func (r *SSEResumeRecorder) run(ownerAPIKeyID int64) {
+	defer close(r.done)
+	cache := r.svc.cache
+	ttl := r.svc.ttl()
+
+	ctx, cancel := context.WithTimeout(context.Background(), sseResumeCacheOpTimeout)
+	err := cache.InitStream(ctx, r.streamID, ownerAPIKeyID, ttl)
+	cancel()
+	if err != nil {
+		r.broken.Store(true)
+	}
+
+	batch := make([]SSEResumeEvent, 0, sseResumeRecorderBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if !r.broken.Load() {
+			ctx, cancel := context.WithTimeout(context.Background(), sseResumeCacheOpTimeout)
+			if err := cache.AppendEvents(ctx, r.streamID, batch, r.svc.cfg.BufferEvents, ttl); err != nil {
+				r.broken.Store(true)
+			}
+			cancel()
+		}
+		batch = batch[:0]
+	}
+	for ev := range r.ch {
+		batch = append(batch, ev)
+	drain:
+		for len(batch) < sseResumeRecorderBatchSize {
+			select {
+			case next, ok := <-r.ch:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+		flush()
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), sseResumeCacheOpTimeout)
+	defer cancel()
+	if r.broken.Load() {
+		logger.LegacyPrintf("service.sse_resume", "[SSEResume] stream buffer incomplete, dropping: stream=%s", r.streamID)
+		_ = cache.DeleteStream(ctx, r.streamID)
+		return
+	}
+	if err := cache.FinishStream(ctx, r.streamID, ttl); err != nil {
+		logger.LegacyPrintf("service.sse_resume", "[SSEResume] finish stream failed: stream=%s err=%v", r.streamID, err)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySSEResumeCache struct {
+	mu        sync.Mutex
+	streams   map[string]*SSEResumeSnapshot
+	appendErr error
+}
+
+func newMemorySSEResumeCache() *memorySSEResumeCache {
+	return &memorySSEResumeCache{streams: map[string]*SSEResumeSnapshot{}}
+}
+
+func (m *memorySSEResumeCache) InitStream(_ context.Context, streamID string, owner int64, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams[streamID] = &SSEResumeSnapshot{OwnerAPIKeyID: owner}
+	return nil
+}
+
+func (m *memorySSEResumeCache) AppendEvents(_ context.Context, streamID string, events []SSEResumeEvent, maxEvents int, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.appendErr != nil {
+		return m.appendErr
+	}
+	snap := m.streams[streamID]
+	snap.Events = append(snap.Events, events...)
+	if len(snap.Events) > maxEvents {
+		snap.Events = snap.Events[len(snap.Events)-maxEvents:]
+	}
+	return nil
+}
+
+func (m *memorySSEResumeCache) FinishStream(_ context.Context, streamID string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams[streamID].Done = true
+	return nil
+}
+
+func (m *memorySSEResumeCache) DeleteStream(_ context.Context, streamID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.streams, streamID)
+	return nil
+}
+
+func (m *memorySSEResumeCache) LoadStream(_ context.Context, streamID string) (*SSEResumeSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap, ok := m.streams[streamID]
+	if !ok {
+		return nil, nil
+	}
+	cp := *snap
+	cp.Events = append([]SSEResumeEvent(nil), snap.Events...)
+	return &cp, nil
+}
+
+func newSSEResumeTestService(cache SSEResumeCache, bufferEvents int) *SSEResumeService {
+	cfg := &config.Config{}
+	cfg.Gateway.SSEResume = config.GatewaySSEResumeConfig{Enabled: true, BufferEvents: bufferEvents, TTLSeconds: 60, TailIdleTimeoutSeconds: 1}
+	return NewSSEResumeService(cache, cfg)
+}
+
+func TestParseSSEResumeEventID(t *testing.T) {
+	streamID := NewSSEResumeStreamID()
+	id, seq, ok := ParseSSEResumeEventID(FormatSSEResumeEventID(streamID, 42))
+	require.True(t, ok)
+	require.Equal(t, streamID, id)
+	require.Equal(t, int64(42), seq)
+
+	for _, raw := range []string{"", "42", "abc-1", streamID + "-", streamID + "-x", "zz" + streamID[2:] + "-1"} {
+		_, _, ok := ParseSSEResumeEventID(raw)
+		require.False(t, ok, raw)
+	}
+}
+
+func TestSSEResumeRecorder_RecordsAndLoadResumable(t *testing.T) {
+	cache := newMemorySSEResumeCache()
+	svc := newSSEResumeTestService(cache, 3)
+
+	rec := svc.StartRecording("s1", 9)
+	for seq := int64(1); seq <= 4; seq++ {
+		rec.Record(seq, []byte("data: x\n\n"))
+	}
+	rec.Close()
+	<-rec.done
+
+	events, done, ok := svc.LoadResumable(context.Background(), "s1", 9, 2)
+	require.True(t, ok)
+	require.True(t, done)
+	require.Len(t, events, 2)
+	require.Equal(t, int64(3), events[0].Seq)
+
+	_, _, ok = svc.LoadResumable(context.Background(), "s1", 10, 2)
+	require.False(t, ok, "another key must not read the stream")
+	_, _, ok = svc.LoadResumable(context.Background(), "s1", 9, 0)
+	require.False(t, ok, "evicted events make the resume lossy")
+}
+
+func TestSSEResumeRecorder_DropsStreamOnWriteFailure(t *testing.T) {
+	cache := newMemorySSEResumeCache()
+	cache.appendErr = errors.New("redis down")
+	svc := newSSEResumeTestService(cache, 10)
+
+	rec := svc.StartRecording("s2", 1)
+	rec.Record(1, []byte("data: x\n\n"))
+	rec.Close()
+	<-rec.done
+
+	_, _, ok := svc.LoadResumable(context.Background(), "s2", 1, 0)
+	require.False(t, ok)
+}
+
+func TestSSEResumeService_DisabledIsNoop(t *testing.T) {
+	svc := NewSSEResumeService(newMemorySSEResumeCache(), &config.Config{})
+	require.False(t, svc.Enabled())
+	rec := svc.StartRecording("s3", 1)
+	require.Nil(t, rec)
+	rec.Record(1, nil)
+	rec.Close()
+}
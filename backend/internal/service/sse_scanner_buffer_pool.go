@@ -1,6 +1,10 @@
 package service
 
-import "sync"
+import (
+	"bufio"
+	"io"
+	"sync"
+)
 
 const sseScannerBuf64KSize = 64 * 1024
 
@@ -27,3 +31,16 @@ func putSSEScannerBuf64K(buf *sseScannerBuf64K) {
 	}
 	sseScannerBuf64KPool.Put(buf)
 }
+
+// newPooledSSEScanner 使用池化的 64KB 初始缓冲构造 SSE 行扫描器。
+// release 必须在最后一次 Scan 之后调用（读取 goroutine 中扫描时在 goroutine 退出时调用），重复调用安全；
+// 归还后扫描器不可再用，读取结果需使用 Text() 拷贝而非 Bytes()。
+func newPooledSSEScanner(r io.Reader, maxLineSize int) (*bufio.Scanner, func()) {
+	scanner := bufio.NewScanner(r)
+	scanBuf := getSSEScannerBuf64K()
+	scanner.Buffer(scanBuf[:0], maxLineSize)
+	var once sync.Once
+	return scanner, func() {
+		once.Do(func() { putSSEScannerBuf64K(scanBuf) })
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"bufio"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sseBenchConcurrentStreams 模拟高 QPS 下同时进行的流数量
+const sseBenchConcurrentStreams = 1000
+
+func sseBenchStreamBody() string {
+	var b strings.Builder
+	for i := 0; i < 20; i++ {
+		b.WriteString("event: content_block_delta\n")
+		b.WriteString(`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"hello world"}}`)
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+func runSSEScanStreams(b *testing.B, body string, newScanner func(r *strings.Reader) (*bufio.Scanner, func())) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		wg.Add(sseBenchConcurrentStreams)
+		for j := 0; j < sseBenchConcurrentStreams; j++ {
+			go func() {
+				defer wg.Done()
+				scanner, release := newScanner(strings.NewReader(body))
+				defer release()
+				for scanner.Scan() {
+					_ = scanner.Text()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// BenchmarkSSEScanner_1kConcurrentStreams 对比每流新分配 64KB 缓冲与池化缓冲的分配量
+func BenchmarkSSEScanner_1kConcurrentStreams(b *testing.B) {
+	body := sseBenchStreamBody()
+
+	b.Run("unpooled", func(b *testing.B) {
+		runSSEScanStreams(b, body, func(r *strings.Reader) (*bufio.Scanner, func()) {
+			scanner := bufio.NewScanner(r)
+			scanner.Buffer(make([]byte, 0, sseScannerBuf64KSize), defaultMaxLineSize)
+			return scanner, func() {}
+		})
+	})
+	b.Run("pooled", func(b *testing.B) {
+		runSSEScanStreams(b, body, func(r *strings.Reader) (*bufio.Scanner, func()) {
+			return newPooledSSEScanner(r, defaultMaxLineSize)
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -17,3 +18,18 @@ func TestSSEScannerBuf64KPool_GetPutDoesNotPanic(t *testing.T) {
 	// 允许传入 nil，确保不会 panic
 	putSSEScannerBuf64K(nil)
 }
+
+func TestNewPooledSSEScanner_ScansLongLinesAndReleasesOnce(t *testing.T) {
+	long := strings.Repeat("x", 2*sseScannerBuf64KSize)
+	scanner, release := newPooledSSEScanner(strings.NewReader("data: a\n"+long+"\n"), 4*sseScannerBuf64KSize)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []string{"data: a", long}, lines, "lines longer than the pooled buffer still grow up to maxLineSize")
+
+	release()
+	release()
+}
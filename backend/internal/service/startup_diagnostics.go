@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 自检项状态
+const (
+	DiagnosticStatusOK   = "ok"
+	DiagnosticStatusWarn = "warn"
+	DiagnosticStatusFail = "fail"
+)
+
+// 自检触发来源
+const (
+	DiagnosticsTriggerStartup = "startup"
+	DiagnosticsTriggerManual  = "manual"
+)
+
+// 自检项名称
+const (
+	diagnosticCheckDatabase = "database"
+	diagnosticCheckSchema   = "schema"
+	diagnosticCheckRedis    = "redis"
+	diagnosticCheckSettings = "settings"
+	diagnosticCheckGroups   = "groups"
+	diagnosticCheckProxies  = "proxies"
+)
+
+// ErrStartupDiagnosticsFailed 关键自检项失败且配置了 fail_fast
+var ErrStartupDiagnosticsFailed = errors.New("startup diagnostics: critical check failed")
+
+// diagnosticsGroupListLimit 报告中列出的无可用账号分组上限，避免分组过多时报告膨胀。
+const diagnosticsGroupListLimit = 50
+
+// DiagnosticCheck 单个自检项结果
+type DiagnosticCheck struct {
+	Name       string         `json:"name"`
+	Status     string         `json:"status"`
+	Critical   bool           `json:"critical"`
+	Message    string         `json:"message,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// DiagnosticsReport 自检报告
+type DiagnosticsReport struct {
+	Status      string            `json:"status"`
+	Trigger     string            `json:"trigger"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	DurationMs  int64             `json:"duration_ms"`
+	Checks      []DiagnosticCheck `json:"checks"`
+}
+
+// HasCriticalFailure 是否存在失败的关键检查项
+func (r *DiagnosticsReport) HasCriticalFailure() bool {
+	if r == nil {
+		return false
+	}
+	for _, c := range r.Checks {
+		if c.Critical && c.Status == DiagnosticStatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaMigrationStatus 迁移文件与 schema_migrations 记录的对比结果
+type SchemaMigrationStatus struct {
+	Expected       int
+	Applied        int
+	LatestExpected string
+	LatestApplied  string
+	// Pending 已随二进制发布但尚未在数据库中记录的迁移
+	Pending []string
+	// Unknown 数据库中存在但当前二进制不认识的迁移（通常意味着数据库被更新版本使用过）
+	Unknown []string
+}
+
+// DiagnosticsGroupRef 分组简要信息
+type DiagnosticsGroupRef struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Platform string `json:"platform"`
+}
+
+// StartupDiagnosticsRepository 自检所需的底层探测能力
+type StartupDiagnosticsRepository interface {
+	PingDatabase(ctx context.Context) error
+	PingRedis(ctx context.Context) error
+	SchemaStatus(ctx context.Context) (*SchemaMigrationStatus, error)
+	// ListGroupsWithoutEnabledAccounts 返回没有任何启用（active + schedulable + 未过期）账号的活跃分组
+	ListGroupsWithoutEnabledAccounts(ctx context.Context) ([]DiagnosticsGroupRef, error)
+}
+
+// StartupDiagnosticsService 启动自检服务
+//
+// 启动时执行一次完整自检并将结构化结果写入日志；最近一次报告保存在内存中，
+// 供 GET /admin/diagnostics 查询，也可由管理员手动触发重新执行。
+// 数据库、schema、Redis、系统设置为关键项，失败时可按配置终止启动；
+// 分组账号与代理抽样仅产生告警，不影响启动。
+type StartupDiagnosticsService struct {
+	repo           StartupDiagnosticsRepository
+	settingService *SettingService
+	proxyRepo      ProxyRepository
+	proxyProber    ProxyExitInfoProber
+	cfg            *config.Config
+
+	runMu sync.Mutex
+	last  atomic.Pointer[DiagnosticsReport]
+}
+
+// NewStartupDiagnosticsService 创建启动自检服务
+func NewStartupDiagnosticsService(
+	repo StartupDiagnosticsRepository,
+	settingService *SettingService,
+	proxyRepo ProxyRepository,
+	proxyProber ProxyExitInfoProber,
+	cfg *config.Config,
+) *StartupDiagnosticsService {
+	return &StartupDiagnosticsService{
+		repo:           repo,
+		settingService: settingService,
+		proxyRepo:      proxyRepo,
+		proxyProber:    proxyProber,
+		cfg:            cfg,
+	}
+}
+
+// Enabled 是否启用启动自检
+func (s *StartupDiagnosticsService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.StartupDiagnostics.Enabled
+}
+
+// FailFast 关键项失败时是否终止启动
+func (s *StartupDiagnosticsService) FailFast() bool {
+	return s.Enabled() && s.cfg.StartupDiagnostics.FailFast
+}
+
+// LastReport 返回最近一次自检报告（未执行过时为 nil）
+func (s *StartupDiagnosticsService) LastReport() *DiagnosticsReport {
+	if s == nil {
+		return nil
+	}
+	return s.last.Load()
+}
+
+// RunAtStartup 启动阶段执行自检；启用 fail_fast 且关键项失败时返回错误，由调用方终止启动。
+func (s *StartupDiagnosticsService) RunAtStartup(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+	report := s.Run(ctx, DiagnosticsTriggerStartup)
+	if s.FailFast() && report.HasCriticalFailure() {
+		return ErrStartupDiagnosticsFailed
+	}
+	return nil
+}
+
+// Run 执行一次完整自检，记录日志并保存为最近一次报告。
+// 同一时刻只允许一次自检执行，并发调用会串行等待。
+func (s *StartupDiagnosticsService) Run(ctx context.Context, trigger string) *DiagnosticsReport {
+	if s == nil {
+		return nil
+	}
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	timeout := 30 * time.Second
+	if s.cfg != nil && s.cfg.StartupDiagnostics.TimeoutSeconds > 0 {
+		timeout = time.Duration(s.cfg.StartupDiagnostics.TimeoutSeconds) * time.Second
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	report := &DiagnosticsReport{
+		Trigger:     trigger,
+		GeneratedAt: start.UTC(),
+	}
+
+	dbCheck := s.timed(diagnosticCheckDatabase, true, func(c *DiagnosticCheck) { s.checkDatabase(runCtx, c) })
+	report.Checks = append(report.Checks, dbCheck)
+	dbOK := dbCheck.Status != DiagnosticStatusFail
+	if dbOK {
+		report.Checks = append(report.Checks, s.timed(diagnosticCheckSchema, true, func(c *DiagnosticCheck) { s.checkSchema(runCtx, c) }))
+	} else {
+		report.Checks = append(report.Checks, skippedDiagnosticCheck(diagnosticCheckSchema, true))
+	}
+	report.Checks = append(report.Checks, s.timed(diagnosticCheckRedis, true, func(c *DiagnosticCheck) { s.checkRedis(runCtx, c) }))
+	if dbOK {
+		report.Checks = append(report.Checks,
+			s.timed(diagnosticCheckSettings, true, func(c *DiagnosticCheck) { s.checkSettings(runCtx, c) }),
+			s.timed(diagnosticCheckGroups, false, func(c *DiagnosticCheck) { s.checkGroups(runCtx, c) }),
+			s.timed(diagnosticCheckProxies, false, func(c *DiagnosticCheck) { s.checkProxies(runCtx, c) }),
+		)
+	} else {
+		report.Checks = append(report.Checks,
+			skippedDiagnosticCheck(diagnosticCheckSettings, true),
+			skippedDiagnosticCheck(diagnosticCheckGroups, false),
+			skippedDiagnosticCheck(diagnosticCheckProxies, false),
+		)
+	}
+
+	report.Status = DiagnosticStatusOK
+	for _, c := range report.Checks {
+		switch {
+		case c.Status == DiagnosticStatusFail && c.Critical:
+			report.Status = DiagnosticStatusFail
+		case c.Status != DiagnosticStatusOK && report.Status == DiagnosticStatusOK:
+			report.Status = DiagnosticStatusWarn
+		}
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	s.last.Store(report)
+	logDiagnosticsReport(report)
+	return report
+}
+
+func (s *StartupDiagnosticsService) timed(name string, critical bool, fn func(c *DiagnosticCheck)) DiagnosticCheck {
+	c := DiagnosticCheck{Name: name, Status: DiagnosticStatusOK, Critical: critical}
+	start := time.Now()
+	fn(&c)
+	c.DurationMs = time.Since(start).Milliseconds()
+	return c
+}
+
+func skippedDiagnosticCheck(name string, critical bool) DiagnosticCheck {
+	status := DiagnosticStatusWarn
+	if critical {
+		status = DiagnosticStatusFail
+	}
+	return DiagnosticCheck{Name: name, Status: status, Critical: critical, Message: "skipped: database unavailable"}
+}
+
+func (s *StartupDiagnosticsService) checkDatabase(ctx context.Context, c *DiagnosticCheck) {
+	if s.repo == nil {
+		c.Status, c.Message = DiagnosticStatusFail, "diagnostics repository not configured"
+		return
+	}
+	if err := s.repo.PingDatabase(ctx); err != nil {
+		c.Status, c.Message = DiagnosticStatusFail, err.Error()
+	}
+}
+
+func (s *StartupDiagnosticsService) checkSchema(ctx context.Context, c *DiagnosticCheck) {
+	st, err := s.repo.SchemaStatus(ctx)
+	if err != nil {
+		c.Status, c.Message = DiagnosticStatusFail, err.Error()
+		return
+	}
+	c.Details = map[string]any{
+		"expected":        st.Expected,
+		"applied":         st.Applied,
+		"latest_expected": st.LatestExpected,
+		"latest_applied":  st.LatestApplied,
+	}
+	if len(st.Pending) > 0 {
+		c.Status = DiagnosticStatusFail
+		c.Message = fmt.Sprintf("%d migration(s) not applied", len(st.Pending))
+		c.Details["pending"] = st.Pending
+	}
+	if len(st.Unknown) > 0 {
+		// 数据库比当前二进制更新：可能是回滚部署，兼容性无法保证但不阻止启动。
+		if c.Status == DiagnosticStatusOK {
+			c.Status = DiagnosticStatusWarn
+			c.Message = fmt.Sprintf("%d migration(s) in database are unknown to this build", len(st.Unknown))
+		}
+		c.Details["unknown"] = st.Unknown
+	}
+}
+
+func (s *StartupDiagnosticsService) checkRedis(ctx context.Context, c *DiagnosticCheck) {
+	if s.repo == nil {
+		c.Status, c.Message = DiagnosticStatusFail, "diagnostics repository not configured"
+		return
+	}
+	if err := s.repo.PingRedis(ctx); err != nil {
+		c.Status, c.Message = DiagnosticStatusFail, err.Error()
+	}
+}
+
+func (s *StartupDiagnosticsService) checkSettings(ctx context.Context, c *DiagnosticCheck) {
+	if s.settingService == nil {
+		c.Status, c.Message = DiagnosticStatusWarn, "setting service not configured"
+		return
+	}
+	settings, err := s.settingService.GetAllSettings(ctx)
+	if err != nil {
+		c.Status, c.Message = DiagnosticStatusFail, err.Error()
+		return
+	}
+	var problems []string
+	if (settings.EmailVerifyEnabled || settings.PasswordResetEnabled) && strings.TrimSpace(settings.SMTPHost) == "" {
+		problems = append(problems, "email verification or password reset is enabled but SMTP host is empty")
+	}
+	if settings.TurnstileEnabled && (strings.TrimSpace(settings.TurnstileSiteKey) == "" || !settings.TurnstileSecretKeyConfigured) {
+		problems = append(problems, "turnstile is enabled but site key or secret key is missing")
+	}
+	if settings.PasswordResetEnabled && strings.TrimSpace(settings.FrontendURL) == "" {
+		problems = append(problems, "password reset is enabled but frontend_url is empty")
+	}
+	if len(problems) > 0 {
+		// 设置可加载但存在不一致，属于配置问题而非启动阻断项。
+		c.Status = DiagnosticStatusWarn
+		c.Message = strings.Join(problems, "; ")
+	}
+}
+
+func (s *StartupDiagnosticsService) checkGroups(ctx context.Context, c *DiagnosticCheck) {
+	groups, err := s.repo.ListGroupsWithoutEnabledAccounts(ctx)
+	if err != nil {
+		c.Status, c.Message = DiagnosticStatusWarn, err.Error()
+		return
+	}
+	if len(groups) == 0 {
+		return
+	}
+	c.Status = DiagnosticStatusWarn
+	c.Message = fmt.Sprintf("%d active group(s) have no enabled account", len(groups))
+	listed := groups
+	if len(listed) > diagnosticsGroupListLimit {
+		listed = listed[:diagnosticsGroupListLimit]
+	}
+	c.Details = map[string]any{"groups": listed, "count": len(groups)}
+}
+
+func (s *StartupDiagnosticsService) checkProxies(ctx context.Context, c *DiagnosticCheck) {
+	sampleSize := 0
+	if s.cfg != nil {
+		sampleSize = s.cfg.StartupDiagnostics.ProxySampleSize
+	}
+	if sampleSize <= 0 || s.proxyRepo == nil || s.proxyProber == nil {
+		c.Message = "proxy probing disabled"
+		return
+	}
+	proxies, err := s.proxyRepo.ListActive(ctx)
+	if err != nil {
+		c.Status, c.Message = DiagnosticStatusWarn, err.Error()
+		return
+	}
+	if len(proxies) == 0 {
+		c.Message = "no active proxies"
+		return
+	}
+	rand.Shuffle(len(proxies), func(i, j int) { proxies[i], proxies[j] = proxies[j], proxies[i] })
+	if len(proxies) > sampleSize {
+		proxies = proxies[:sampleSize]
+	}
+
+	type proxyResult struct {
+		ID        int64  `json:"id"`
+		Name      string `json:"name"`
+		OK        bool   `json:"ok"`
+		LatencyMs int64  `json:"latency_ms,omitempty"`
+		Error     string `json:"error,omitempty"`
+	}
+	results := make([]proxyResult, len(proxies))
+	var wg sync.WaitGroup
+	for i := range proxies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := &proxies[i]
+			results[i] = proxyResult{ID: p.ID, Name: p.Name}
+			_, latency, err := s.proxyProber.ProbeProxy(ctx, p.URL())
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].OK = true
+			results[i].LatencyMs = latency
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if !r.OK {
+			failed++
+		}
+	}
+	c.Details = map[string]any{"sampled": len(results), "failed": failed, "results": results}
+	if failed > 0 {
+		c.Status = DiagnosticStatusWarn
+		c.Message = fmt.Sprintf("%d of %d sampled proxies unreachable", failed, len(results))
+	}
+}
+
+func logDiagnosticsReport(report *DiagnosticsReport) {
+	log := logger.L().With(
+		zap.String("component", "service.startup_diagnostics"),
+		zap.String("trigger", report.Trigger),
+	)
+	for _, c := range report.Checks {
+		fields := []zap.Field{
+			zap.String("check", c.Name),
+			zap.String("status", c.Status),
+			zap.Bool("critical", c.Critical),
+			zap.Int64("duration_ms", c.DurationMs),
+		}
+		if c.Message != "" {
+			fields = append(fields, zap.String("message", c.Message))
+		}
+		if len(c.Details) > 0 {
+			fields = append(fields, zap.Any("details", c.Details))
+		}
+		switch c.Status {
+		case DiagnosticStatusFail:
+			log.Error("diagnostics check failed", fields...)
+		case DiagnosticStatusWarn:
+			log.Warn("diagnostics check warning", fields...)
+		default:
+			log.Info("diagnostics check passed", fields...)
+		}
+	}
+	log.Info("diagnostics completed",
+		zap.String("status", report.Status),
+		zap.Int64("duration_ms", report.DurationMs),
+		zap.Int("checks", len(report.Checks)),
+	)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type startupDiagnosticsRepoStub struct {
+	dbErr    error
+	redisErr error
+	schema   *SchemaMigrationStatus
+	groups   []DiagnosticsGroupRef
+}
+
+func (s *startupDiagnosticsRepoStub) PingDatabase(context.Context) error { return s.dbErr }
+func (s *startupDiagnosticsRepoStub) PingRedis(context.Context) error    { return s.redisErr }
+
+func (s *startupDiagnosticsRepoStub) SchemaStatus(context.Context) (*SchemaMigrationStatus, error) {
+	if s.schema == nil {
+		return &SchemaMigrationStatus{}, nil
+	}
+	return s.schema, nil
+}
+
+func (s *startupDiagnosticsRepoStub) ListGroupsWithoutEnabledAccounts(context.Context) ([]DiagnosticsGroupRef, error) {
+	return s.groups, nil
+}
+
+type diagnosticsProxyRepoStub struct {
+	ProxyRepository
+	proxies []Proxy
+}
+
+func (s *diagnosticsProxyRepoStub) ListActive(context.Context) ([]Proxy, error) {
+	return append([]Proxy(nil), s.proxies...), nil
+}
+
+type diagnosticsProxyProberStub struct {
+	failHost string
+}
+
+func (s *diagnosticsProxyProberStub) ProbeProxy(_ context.Context, proxyURL string) (*ProxyExitInfo, int64, error) {
+	if s.failHost != "" && proxyURL == "http://"+s.failHost+":8080" {
+		return nil, 0, errors.New("connect timeout")
+	}
+	return &ProxyExitInfo{}, 42, nil
+}
+
+func newTestStartupDiagnosticsConfig(failFast bool, sample int) *config.Config {
+	cfg := &config.Config{}
+	cfg.StartupDiagnostics = config.StartupDiagnosticsConfig{
+		Enabled:         true,
+		FailFast:        failFast,
+		ProxySampleSize: sample,
+		TimeoutSeconds:  5,
+	}
+	return cfg
+}
+
+func findDiagnosticCheck(t *testing.T, report *DiagnosticsReport, name string) DiagnosticCheck {
+	t.Helper()
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("check %q not found", name)
+	return DiagnosticCheck{}
+}
+
+func TestStartupDiagnostics_DatabaseDownSkipsDependentChecksAndFailsFast(t *testing.T) {
+	repo := &startupDiagnosticsRepoStub{dbErr: errors.New("connection refused")}
+	svc := NewStartupDiagnosticsService(repo, nil, nil, nil, newTestStartupDiagnosticsConfig(true, 0))
+
+	err := svc.RunAtStartup(context.Background())
+	require.ErrorIs(t, err, ErrStartupDiagnosticsFailed)
+
+	report := svc.LastReport()
+	require.NotNil(t, report)
+	require.Equal(t, DiagnosticStatusFail, report.Status)
+	require.Equal(t, DiagnosticsTriggerStartup, report.Trigger)
+	require.Equal(t, DiagnosticStatusFail, findDiagnosticCheck(t, report, diagnosticCheckSchema).Status)
+	require.Equal(t, DiagnosticStatusOK, findDiagnosticCheck(t, report, diagnosticCheckRedis).Status)
+	require.Contains(t, findDiagnosticCheck(t, report, diagnosticCheckGroups).Message, "skipped")
+}
+
+func TestStartupDiagnostics_CriticalFailureWithoutFailFastDoesNotAbort(t *testing.T) {
+	repo := &startupDiagnosticsRepoStub{redisErr: errors.New("NOAUTH")}
+	svc := NewStartupDiagnosticsService(repo, nil, nil, nil, newTestStartupDiagnosticsConfig(false, 0))
+
+	require.NoError(t, svc.RunAtStartup(context.Background()))
+	require.True(t, svc.LastReport().HasCriticalFailure())
+}
+
+func TestStartupDiagnostics_PendingMigrationsFailSchemaCheck(t *testing.T) {
+	repo := &startupDiagnosticsRepoStub{schema: &SchemaMigrationStatus{
+		Expected: 3, Applied: 2, Pending: []string{"003_c.sql"},
+	}}
+	svc := NewStartupDiagnosticsService(repo, nil, nil, nil, newTestStartupDiagnosticsConfig(false, 0))
+
+	report := svc.Run(context.Background(), DiagnosticsTriggerManual)
+	schema := findDiagnosticCheck(t, report, diagnosticCheckSchema)
+	require.Equal(t, DiagnosticStatusFail, schema.Status)
+	require.Equal(t, []string{"003_c.sql"}, schema.Details["pending"])
+}
+
+func TestStartupDiagnostics_GroupsAndProxiesOnlyWarn(t *testing.T) {
+	repo := &startupDiagnosticsRepoStub{groups: []DiagnosticsGroupRef{{ID: 7, Name: "claude-pro", Platform: PlatformAnthropic}}}
+	proxyRepo := &diagnosticsProxyRepoStub{proxies: []Proxy{
+		{ID: 1, Name: "ok", Protocol: "http", Host: "10.0.0.1", Port: 8080},
+		{ID: 2, Name: "bad", Protocol: "http", Host: "10.0.0.2", Port: 8080},
+	}}
+	prober := &diagnosticsProxyProberStub{failHost: "10.0.0.2"}
+	svc := NewStartupDiagnosticsService(repo, nil, proxyRepo, prober, newTestStartupDiagnosticsConfig(true, 5))
+
+	require.NoError(t, svc.RunAtStartup(context.Background()))
+	report := svc.LastReport()
+	require.Equal(t, DiagnosticStatusWarn, report.Status)
+	require.False(t, report.HasCriticalFailure())
+
+	groups := findDiagnosticCheck(t, report, diagnosticCheckGroups)
+	require.Equal(t, DiagnosticStatusWarn, groups.Status)
+	require.Equal(t, 1, groups.Details["count"])
+
+	proxies := findDiagnosticCheck(t, report, diagnosticCheckProxies)
+	require.Equal(t, DiagnosticStatusWarn, proxies.Status)
+	require.Equal(t, 2, proxies.Details["sampled"])
+	require.Equal(t, 1, proxies.Details["failed"])
+}
+
+func TestStartupDiagnostics_DisabledSkipsRun(t *testing.T) {
+	cfg := newTestStartupDiagnosticsConfig(true, 0)
+	cfg.StartupDiagnostics.Enabled = false
+	svc := NewStartupDiagnosticsService(&startupDiagnosticsRepoStub{dbErr: errors.New("down")}, nil, nil, nil, cfg)
+
+	require.NoError(t, svc.RunAtStartup(context.Background()))
+	require.Nil(t, svc.LastReport())
+}
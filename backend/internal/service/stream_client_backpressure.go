@@ -0,0 +1,281 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// errStreamClientTooSlow 客户端读取过慢，被判定为需要断开。
+var errStreamClientTooSlow = errors.New("stream client too slow")
+
+const (
+	streamClientSlowReasonWriteTimeout = "stream_client_write_timeout"
+	streamClientSlowReasonLagExceeded  = "stream_client_lag_exceeded"
+)
+
+var (
+	streamClientWriteTimeoutTotal atomic.Int64
+	streamClientLagExceededTotal  atomic.Int64
+)
+
+// StreamClientBackpressureStats 返回因慢客户端被断开的流式请求计数。
+func StreamClientBackpressureStats() (writeTimeouts, lagExceeded int64) {
+	return streamClientWriteTimeoutTotal.Load(), streamClientLagExceededTotal.Load()
+}
+
+// streamClientGuard 流式响应的下游背压保护：
+//   - 每次写入客户端前设置写超时，写入（含 Flush）超时视为慢客户端；
+//   - 客户端写入阻塞期间统计上游已读取但尚未下发的字节，超过积压预算时立即使阻塞中的写入失败。
+//
+// 触发后调用方按客户端断开处理：停止写客户端、继续读完上游以补全 usage，连接在 handler 返回时关闭。
+// 所有方法对 nil receiver 安全（未启用时为 nil）。
+type streamClientGuard struct {
+	c            *gin.Context
+	rc           *http.ResponseController
+	writeTimeout time.Duration
+	maxLag       int64
+
+	upstreamRead atomic.Int64 // 上游累计读取字节（读 goroutine 写入）
+	delivered    atomic.Int64 // 已交给写循环处理的上游字节
+	writing      atomic.Bool
+	// autoDeliver 为 true 时（bindWriter 模式）每次写入开始即视为此前读取的上游字节均已交付，
+	// 积压只统计本次写入阻塞期间新读取的字节，调用方无需逐行 consume。
+	autoDeliver bool
+
+	tripOnce   sync.Once
+	tripped    atomic.Bool
+	tripReason string
+}
+
+// newStreamClientGuard 按配置创建背压保护；写超时与积压预算均未配置时返回 nil。
+// 写超时经 http.ResponseController 设置，依赖 c.Writer 上各层包装 Writer 实现 Unwrap 以找到底层连接。
+func newStreamClientGuard(c *gin.Context, cfg *config.Config) *streamClientGuard {
+	if c == nil || cfg == nil {
+		return nil
+	}
+	writeTimeout := time.Duration(cfg.Gateway.StreamClientWriteTimeout) * time.Second
+	maxLag := cfg.Gateway.StreamClientMaxLagBytes
+	if writeTimeout <= 0 && maxLag <= 0 {
+		return nil
+	}
+	return &streamClientGuard{
+		c:            c,
+		rc:           http.NewResponseController(c.Writer),
+		writeTimeout: writeTimeout,
+		maxLag:       maxLag,
+	}
+}
+
+// watchUpstream 为上游响应体挂上字节统计，用于计算客户端积压。
+func (g *streamClientGuard) watchUpstream(resp *http.Response) {
+	if g == nil || g.maxLag <= 0 || resp == nil || resp.Body == nil {
+		return
+	}
+	resp.Body = &streamClientLagReader{ReadCloser: resp.Body, guard: g}
+}
+
+// consume 记录写循环已取走的上游字节（一行 SSE 及其换行符）。
+func (g *streamClientGuard) consume(n int) {
+	if g == nil || g.maxLag <= 0 {
+		return
+	}
+	g.delivered.Add(int64(n))
+}
+
+// beginWrite 在写入客户端前调用，设置本次写入的截止时间。
+func (g *streamClientGuard) beginWrite() {
+	if g == nil || g.tripped.Load() {
+		return
+	}
+	if g.autoDeliver {
+		g.delivered.Store(g.upstreamRead.Load())
+	}
+	g.writing.Store(true)
+	if g.writeTimeout > 0 {
+		_ = g.rc.SetWriteDeadline(time.Now().Add(g.writeTimeout))
+	}
+}
+
+// endWrite 在写入（含 Flush）完成后调用；返回非 nil 表示应按客户端断开处理。
+// 未触发时清除写超时，避免影响未经保护的后续写入（如错误事件、chunked 结束块）；
+// 已触发时保留过期的截止时间，使后续写入快速失败。
+func (g *streamClientGuard) endWrite(err error) error {
+	if g == nil {
+		return err
+	}
+	g.writing.Store(false)
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		g.trip(streamClientSlowReasonWriteTimeout)
+	}
+	if g.tripped.Load() {
+		g.record()
+		if err == nil {
+			err = errStreamClientTooSlow
+		}
+		return err
+	}
+	if g.writeTimeout > 0 {
+		_ = g.rc.SetWriteDeadline(time.Time{})
+	}
+	return err
+}
+
+// flush 在写超时保护下刷出已写入的数据。
+func (g *streamClientGuard) flush(flusher http.Flusher) error {
+	g.beginWrite()
+	flusher.Flush()
+	return g.endWrite(nil)
+}
+
+// wrapWriter 返回每次写入都受保护的 io.Writer，供 bufio 等间接写入路径使用；未启用时原样返回。
+func (g *streamClientGuard) wrapWriter(w io.Writer) io.Writer {
+	if g == nil {
+		return w
+	}
+	return &streamClientGuardWriter{w: w, guard: g}
+}
+
+// bindWriter 将 c.Writer 替换为受保护的 ResponseWriter，供写入分散在多处（fmt.Fprint、c.Writer.Flush、
+// 错误事件等）的流式路径使用：经其的每次 Write / Flush 都在写超时与积压预算下执行。
+// 触发后 Write 返回错误、Flush 静默失败，调用方沿用既有的写失败分支按客户端断开处理。
+// 返回的函数恢复原 Writer；未启用时为空操作。
+func (g *streamClientGuard) bindWriter() (restore func()) {
+	if g == nil {
+		return func() {}
+	}
+	g.autoDeliver = true
+	orig := g.c.Writer
+	wrapped := &streamClientGuardResponseWriter{ResponseWriter: orig, guard: g}
+	g.c.Writer = wrapped
+	return func() {
+		if current, ok := g.c.Writer.(*streamClientGuardResponseWriter); ok && current == wrapped {
+			g.c.Writer = orig
+		}
+	}
+}
+
+// guardStreamClient 为写入分散在多处的流式路径启用背压保护：统计上游读取并以 bindWriter 替换 c.Writer。
+// 须在取用 c.Writer / Flusher 与创建上游 scanner 之前调用；返回的函数恢复原 Writer。
+func guardStreamClient(c *gin.Context, cfg *config.Config, resp *http.Response) (restore func()) {
+	guard := newStreamClientGuard(c, cfg)
+	guard.watchUpstream(resp)
+	return guard.bindWriter()
+}
+
+type streamClientGuardResponseWriter struct {
+	gin.ResponseWriter
+	guard *streamClientGuard
+}
+
+func (w *streamClientGuardResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *streamClientGuardResponseWriter) Write(p []byte) (int, error) {
+	if w.guard.tripped.Load() {
+		return 0, w.guard.endWrite(nil)
+	}
+	w.guard.beginWrite()
+	n, err := w.ResponseWriter.Write(p)
+	return n, w.guard.endWrite(err)
+}
+
+func (w *streamClientGuardResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *streamClientGuardResponseWriter) Flush() {
+	if w.guard.tripped.Load() {
+		return
+	}
+	_ = w.guard.flush(w.ResponseWriter)
+}
+
+type streamClientGuardWriter struct {
+	w     io.Writer
+	guard *streamClientGuard
+}
+
+func (w *streamClientGuardWriter) Write(p []byte) (int, error) {
+	w.guard.beginWrite()
+	n, err := w.w.Write(p)
+	return n, w.guard.endWrite(err)
+}
+
+func (g *streamClientGuard) observeUpstream(n int) {
+	read := g.upstreamRead.Add(int64(n))
+	if !g.writing.Load() {
+		return
+	}
+	if read-g.delivered.Load() > g.maxLag {
+		g.trip(streamClientSlowReasonLagExceeded)
+	}
+}
+
+func (g *streamClientGuard) trip(reason string) {
+	g.tripOnce.Do(func() {
+		g.tripReason = reason
+		g.tripped.Store(true)
+		// 截止时间设为当前时刻，使阻塞中的写入立即返回
+		_ = g.rc.SetWriteDeadline(time.Now())
+	})
+}
+
+const streamClientRecordKey = "stream_client_too_slow_recorded"
+
+func (g *streamClientGuard) record() {
+	if _, exists := g.c.Get(streamClientRecordKey); exists {
+		return
+	}
+	g.c.Set(streamClientRecordKey, true)
+
+	switch g.tripReason {
+	case streamClientSlowReasonLagExceeded:
+		streamClientLagExceededTotal.Add(1)
+	default:
+		streamClientWriteTimeoutTotal.Add(1)
+	}
+	path := ""
+	if g.c.Request != nil && g.c.Request.URL != nil {
+		path = g.c.Request.URL.Path
+	}
+	lag := g.upstreamRead.Load() - g.delivered.Load()
+	logger.L().Warn("gateway.stream_client_too_slow",
+		zap.String("reason", g.tripReason),
+		zap.String("path", path),
+		zap.Duration("write_timeout", g.writeTimeout),
+		zap.Int64("max_lag_bytes", g.maxLag),
+		zap.Int64("lag_bytes", lag),
+	)
+	// 慢客户端由客户端侧导致，记录到 ops 但不计入 SLA
+	markOpsStreamError(g.c, OpsStreamError{
+		ErrType:        "client_error",
+		Code:           g.tripReason,
+		Message:        "client connection closed: stream consumer too slow",
+		IntendedStatus: http.StatusRequestTimeout,
+	})
+}
+
+// streamClientLagReader 统计上游读取字节并在客户端积压超限时触发断开。
+type streamClientLagReader struct {
+	io.ReadCloser
+	guard *streamClientGuard
+}
+
+func (r *streamClientLagReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.guard.observeUpstream(n)
+	}
+	return n, err
+}
@@ -0,0 +1,207 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineWriter 模拟支持写超时的连接：block=true 时写入阻塞到截止时间到达后返回超时错误。
+type deadlineWriter struct {
+	header http.Header
+	block  bool
+
+	mu        sync.Mutex
+	deadline  time.Time
+	deadlines []time.Time
+	buf       bytes.Buffer
+}
+
+func (w *deadlineWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = http.Header{}
+	}
+	return w.header
+}
+
+func (w *deadlineWriter) WriteHeader(int) {}
+
+func (w *deadlineWriter) Write(p []byte) (int, error) {
+	if !w.block {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.buf.Write(p)
+	}
+	for giveUp := time.Now().Add(2 * time.Second); time.Now().Before(giveUp); time.Sleep(2 * time.Millisecond) {
+		w.mu.Lock()
+		deadline := w.deadline
+		w.mu.Unlock()
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return 0, fmt.Errorf("write tcp: %w", os.ErrDeadlineExceeded)
+		}
+	}
+	return 0, io.ErrClosedPipe
+}
+
+func (w *deadlineWriter) Flush() {}
+
+func (w *deadlineWriter) SetWriteDeadline(t time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deadline = t
+	w.deadlines = append(w.deadlines, t)
+	return nil
+}
+
+func newStreamClientGuardForTest(t *testing.T, writer *deadlineWriter, writeTimeoutSeconds int, maxLag int64) (*streamClientGuard, *gin.Context) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(writer)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	cfg := &config.Config{}
+	cfg.Gateway.StreamClientWriteTimeout = writeTimeoutSeconds
+	cfg.Gateway.StreamClientMaxLagBytes = maxLag
+	guard := newStreamClientGuard(c, cfg)
+	require.NotNil(t, guard)
+	return guard, c
+}
+
+func TestStreamClientGuard_DisabledPassesErrorsThrough(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	require.Nil(t, newStreamClientGuard(c, &config.Config{}))
+
+	var guard *streamClientGuard
+	guard.beginWrite()
+	guard.consume(10)
+	require.ErrorIs(t, guard.endWrite(io.ErrClosedPipe), io.ErrClosedPipe)
+	require.NoError(t, guard.endWrite(nil))
+	var buf bytes.Buffer
+	require.Same(t, &buf, guard.wrapWriter(&buf))
+}
+
+func TestStreamClientGuard_ClearsDeadlineAfterSuccessfulWrite(t *testing.T) {
+	writer := &deadlineWriter{}
+	guard, _ := newStreamClientGuardForTest(t, writer, 30, 0)
+
+	_, err := guard.wrapWriter(writer).Write([]byte("data: {}\n\n"))
+	require.NoError(t, err)
+
+	require.Len(t, writer.deadlines, 2)
+	require.False(t, writer.deadlines[0].IsZero(), "write is armed with a deadline")
+	require.True(t, writer.deadlines[1].IsZero(), "deadline is cleared once the write completes")
+}
+
+func TestStreamClientGuard_WriteTimeoutMarksClientTooSlow(t *testing.T) {
+	writer := &deadlineWriter{block: true}
+	guard, c := newStreamClientGuardForTest(t, writer, 1, 0)
+	guard.writeTimeout = 20 * time.Millisecond
+	beforeTimeouts, _ := StreamClientBackpressureStats()
+
+	_, err := guard.wrapWriter(writer).Write([]byte("data: {}\n\n"))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	timeouts, _ := StreamClientBackpressureStats()
+	require.Equal(t, beforeTimeouts+1, timeouts)
+	streamErr, ok := GetOpsStreamError(c)
+	require.True(t, ok)
+	require.Equal(t, streamClientSlowReasonWriteTimeout, streamErr.Code)
+	require.False(t, streamErr.CountTowardsSLA)
+
+	// 触发后不再清除截止时间，后续写入快速失败且只记录一次
+	require.Error(t, guard.endWrite(nil))
+	timeouts, _ = StreamClientBackpressureStats()
+	require.Equal(t, beforeTimeouts+1, timeouts)
+}
+
+func TestStreamClientGuard_LagBudgetAbortsBlockedWrite(t *testing.T) {
+	writer := &deadlineWriter{block: true}
+	guard, c := newStreamClientGuardForTest(t, writer, 0, 100)
+	_, beforeLag := StreamClientBackpressureStats()
+
+	resp := &http.Response{Body: io.NopCloser(bytes.NewReader(bytes.Repeat([]byte("x"), 1024)))}
+	guard.watchUpstream(resp)
+
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := guard.wrapWriter(writer).Write([]byte("data: {}\n\n"))
+		writeErr <- err
+	}()
+	require.Eventually(t, func() bool { return guard.writing.Load() }, time.Second, time.Millisecond)
+
+	// 写入阻塞期间上游继续读取，积压超过预算后阻塞的写入被立即中止
+	_, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	select {
+	case err := <-writeErr:
+		require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	case <-time.After(2 * time.Second):
+		t.Fatal("blocked write was not aborted")
+	}
+	_, lag := StreamClientBackpressureStats()
+	require.Equal(t, beforeLag+1, lag)
+	streamErr, ok := GetOpsStreamError(c)
+	require.True(t, ok)
+	require.Equal(t, streamClientSlowReasonLagExceeded, streamErr.Code)
+}
+
+func TestStreamClientGuard_LagIgnoredWhileClientKeepsUp(t *testing.T) {
+	writer := &deadlineWriter{}
+	guard, _ := newStreamClientGuardForTest(t, writer, 0, 100)
+
+	resp := &http.Response{Body: io.NopCloser(bytes.NewReader(bytes.Repeat([]byte("x"), 1024)))}
+	guard.watchUpstream(resp)
+	_, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// 单个大事件一次性读入不算客户端积压（没有阻塞中的写入）
+	require.False(t, guard.tripped.Load())
+	guard.consume(1024)
+	_, err = guard.wrapWriter(writer).Write([]byte("data: {}\n\n"))
+	require.NoError(t, err)
+}
+
+func TestStreamClientGuard_BindWriterGuardsContextWriter(t *testing.T) {
+	writer := &deadlineWriter{}
+	guard, c := newStreamClientGuardForTest(t, writer, 1, 100)
+	orig := c.Writer
+
+	resp := &http.Response{Body: io.NopCloser(bytes.NewReader(bytes.Repeat([]byte("x"), 1024)))}
+	guard.watchUpstream(resp)
+	restore := guard.bindWriter()
+	require.NotSame(t, orig, c.Writer)
+
+	// 写入前已读取的上游字节视为已交付，不计入积压
+	_, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_, err = fmt.Fprint(c.Writer, "data: {}\n\n")
+	require.NoError(t, err)
+	require.False(t, guard.tripped.Load())
+	require.True(t, writer.deadlines[len(writer.deadlines)-1].IsZero())
+
+	// 写超时后经 c.Writer 的写入快速失败，Flush 不再触及连接
+	writer.block = true
+	guard.writeTimeout = 20 * time.Millisecond
+	_, err = c.Writer.WriteString("data: {}\n\n")
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	deadlines := len(writer.deadlines)
+	c.Writer.Flush()
+	_, err = c.Writer.Write([]byte("data: {}\n\n"))
+	require.ErrorIs(t, err, errStreamClientTooSlow)
+	require.Len(t, writer.deadlines, deadlines)
+
+	restore()
+	require.Same(t, orig, c.Writer)
+}
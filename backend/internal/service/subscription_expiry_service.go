@@ -29,6 +29,7 @@ type SubscriptionExpiryService struct {
 	userSubRepo              UserSubscriptionRepository
 	settingRepo              SettingRepository
 	notificationEmailService *NotificationEmailService
+	userNotifier             UserNotifier
 	interval                 time.Duration
 	stopCh                   chan struct{}
 	stopOnce                 sync.Once
@@ -67,6 +68,11 @@ func (s *SubscriptionExpiryService) SetNotificationEmailService(notificationEmai
 	s.notificationEmailService = notificationEmailService
 }
 
+// SetUserNotifier 注入站内通知：到期提醒除邮件外还会生成站内通知。
+func (s *SubscriptionExpiryService) SetUserNotifier(notifier UserNotifier) {
+	s.userNotifier = notifier
+}
+
 func (s *SubscriptionExpiryService) Start() {
 	if s == nil || s.userSubRepo == nil || s.interval <= 0 {
 		return
@@ -115,7 +121,7 @@ func (s *SubscriptionExpiryService) runOnce() {
 }
 
 func (s *SubscriptionExpiryService) sendExpiryReminders(ctx context.Context) {
-	if s == nil || s.userSubRepo == nil || s.notificationEmailService == nil {
+	if s == nil || s.userSubRepo == nil || (s.notificationEmailService == nil && s.userNotifier == nil) {
 		return
 	}
 	if !s.expiryReminderEnabled(ctx) {
@@ -160,13 +166,19 @@ func (s *SubscriptionExpiryService) expiryReminderEnabled(ctx context.Context) b
 }
 
 func (s *SubscriptionExpiryService) sendExpiryReminderIfDue(ctx context.Context, sub *UserSubscription) {
-	if sub == nil || sub.User == nil || sub.Group == nil || sub.User.Email == "" {
+	if sub == nil || sub.User == nil || sub.Group == nil {
 		return
 	}
 	daysRemaining := sub.DaysRemaining()
 	if daysRemaining != 7 && daysRemaining != 3 && daysRemaining != 1 {
 		return
 	}
+	if s.userNotifier != nil {
+		s.userNotifier.NotifyAsync(buildSubscriptionExpiringNotification(sub, daysRemaining))
+	}
+	if s.notificationEmailService == nil || sub.User.Email == "" {
+		return
+	}
 	if err := s.notificationEmailService.Send(ctx, NotificationEmailSendInput{
 		Event:          NotificationEmailEventSubscriptionExpiryReminder,
 		RecipientEmail: sub.User.Email,
@@ -184,3 +196,21 @@ func (s *SubscriptionExpiryService) sendExpiryReminderIfDue(ctx context.Context,
 		log.Printf("[SubscriptionExpiry] Send expiry reminder failed: subscription=%d user=%d err=%v", sub.ID, sub.UserID, err)
 	}
 }
+
+// buildSubscriptionExpiringNotification 构造订阅到期站内通知。
+// 去重键包含到期时间与提醒档位：每个档位只提醒一次，续期后针对新的到期时间重新提醒。
+func buildSubscriptionExpiringNotification(sub *UserSubscription, daysRemaining int) UserNotificationInput {
+	return UserNotificationInput{
+		UserID:  sub.UserID,
+		Type:    UserNotificationTypeSubscriptionExpiring,
+		Level:   UserNotificationLevelWarning,
+		Title:   "Subscription is about to expire",
+		Content: fmt.Sprintf("Your %q subscription expires at %s (%d day(s) left).", sub.Group.Name, sub.ExpiresAt.Format("2006-01-02 15:04"), daysRemaining),
+		Metadata: map[string]any{
+			"subscription_id": sub.ID,
+			"group_id":        sub.GroupID,
+			"days_remaining":  daysRemaining,
+		},
+		DedupKey: fmt.Sprintf("subscription_expiring:%d:%d:%dd", sub.ID, sub.ExpiresAt.Unix(), daysRemaining),
+	}
+}
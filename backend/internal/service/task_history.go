@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// TaskTypeBatchImage 批量生图任务
+const TaskTypeBatchImage = "batch_image"
+
+var ErrTaskHistoryInvalidStatus = infraerrors.BadRequest("TASK_HISTORY_INVALID_STATUS", "invalid task status filter")
+
+// taskHistoryStatusMapping 对外状态 -> 内部状态；与 PublicBatchImageStatus 互为逆映射。
+var taskHistoryStatusMapping = map[string][]string{
+	"queued":             {BatchImageJobStatusCreated, BatchImageJobStatusUploading, BatchImageJobStatusSubmitted},
+	"running":            {BatchImageJobStatusRunning},
+	"processing_results": {BatchImageJobStatusIndexing},
+	"settling":           {BatchImageJobStatusSettling},
+	"completed":          {BatchImageJobStatusCompleted},
+	"failed":             {BatchImageJobStatusFailed},
+	"cancelled":          {BatchImageJobStatusCancelled},
+	"output_deleted":     {BatchImageJobStatusOutputDeleted},
+}
+
+// TaskHistoryFilter 用户任务历史查询条件。
+type TaskHistoryFilter struct {
+	Page     int
+	PageSize int
+
+	UserID   int64
+	APIKeyID *int64
+	// Status 对外状态（queued/running/completed...），为空表示不过滤
+	Status string
+	// Statuses 由 Status 解析出的内部状态集合，仓储层使用
+	Statuses  []string
+	Model     string
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// TaskHistoryItem 用户任务历史条目。
+type TaskHistoryItem struct {
+	ID           string  `json:"id"`
+	Type         string  `json:"type"`
+	TaskName     string  `json:"task_name,omitempty"`
+	Status       string  `json:"status"`
+	Model        string  `json:"model"`
+	APIKeyID     *int64  `json:"api_key_id,omitempty"`
+	ItemCount    int     `json:"item_count"`
+	SuccessCount int     `json:"success_count"`
+	FailCount    int     `json:"fail_count"`
+	Cost         float64 `json:"cost"`
+	// CostFinal 为 true 表示 Cost 为结算后的实际费用，否则为预估费用
+	CostFinal    bool       `json:"cost_final"`
+	Currency     string     `json:"currency,omitempty"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	ResultURL    string     `json:"result_url,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// TaskHistoryList 分页结果。
+type TaskHistoryList struct {
+	Items    []*TaskHistoryItem
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// TaskHistoryRepository 用户任务历史查询端口（跨 API Key，排除用户已删除记录）。
+type TaskHistoryRepository interface {
+	ListBatchImageJobsForUser(ctx context.Context, filter *TaskHistoryFilter) ([]*BatchImageJob, int, error)
+}
+
+// TaskHistoryService 聚合用户的长耗时生成任务（目前为批量生图）供控制台展示。
+type TaskHistoryService struct {
+	repo TaskHistoryRepository
+}
+
+// NewTaskHistoryService creates a new TaskHistoryService
+func NewTaskHistoryService(repo TaskHistoryRepository) *TaskHistoryService {
+	return &TaskHistoryService{repo: repo}
+}
+
+// ListForUser 分页查询当前用户的任务历史。
+func (s *TaskHistoryService) ListForUser(ctx context.Context, userID int64, filter *TaskHistoryFilter) (*TaskHistoryList, error) {
+	if filter == nil {
+		filter = &TaskHistoryFilter{}
+	}
+	filter.UserID = userID
+	filter.Statuses = nil
+	if status := strings.ToLower(strings.TrimSpace(filter.Status)); status != "" {
+		statuses, ok := taskHistoryStatusMapping[status]
+		if !ok {
+			return nil, ErrTaskHistoryInvalidStatus
+		}
+		filter.Statuses = statuses
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	jobs, total, err := s.repo.ListBatchImageJobsForUser(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*TaskHistoryItem, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, batchImageJobToTaskHistoryItem(job))
+	}
+	return &TaskHistoryList{
+		Items:    items,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}, nil
+}
+
+func batchImageJobToTaskHistoryItem(job *BatchImageJob) *TaskHistoryItem {
+	item := &TaskHistoryItem{
+		ID:           job.BatchID,
+		Type:         TaskTypeBatchImage,
+		TaskName:     batchImagePublicTaskName(job),
+		Status:       PublicBatchImageStatus(job.Status),
+		Model:        job.Model,
+		APIKeyID:     job.APIKeyID,
+		ItemCount:    job.ItemCount,
+		SuccessCount: job.SuccessCount,
+		FailCount:    job.FailCount,
+		Cost:         job.EstimatedCost,
+		Currency:     job.Currency,
+		CreatedAt:    job.CreatedAt,
+		FinishedAt:   job.FinishedAt,
+	}
+	if job.ActualCost != nil && job.SettledAt != nil {
+		item.Cost = *job.ActualCost
+		item.CostFinal = true
+	}
+	if job.LastErrorMessage != nil {
+		item.ErrorMessage = *job.LastErrorMessage
+	}
+	// 结果下载走网关（需使用创建任务的 API Key），输出已清理的任务不再提供链接
+	if job.Status == BatchImageJobStatusCompleted && job.OutputDeletedAt == nil {
+		item.ResultURL = fmt.Sprintf("/v1/images/batches/%s/download", job.BatchID)
+	}
+	return item
+}
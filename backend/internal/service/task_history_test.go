@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type taskHistoryRepoStub struct {
+	jobs   []*BatchImageJob
+	total  int
+	err    error
+	filter *TaskHistoryFilter
+}
+
+func (r *taskHistoryRepoStub) ListBatchImageJobsForUser(_ context.Context, filter *TaskHistoryFilter) ([]*BatchImageJob, int, error) {
+	cp := *filter
+	r.filter = &cp
+	return r.jobs, r.total, r.err
+}
+
+func TestTaskHistoryService_ListForUser_MapsStatusAndPagination(t *testing.T) {
+	repo := &taskHistoryRepoStub{}
+	svc := NewTaskHistoryService(repo)
+
+	out, err := svc.ListForUser(context.Background(), 7, &TaskHistoryFilter{Status: " Queued ", PageSize: 500})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), repo.filter.UserID)
+	require.Equal(t, []string{BatchImageJobStatusCreated, BatchImageJobStatusUploading, BatchImageJobStatusSubmitted}, repo.filter.Statuses)
+	require.Equal(t, 1, out.Page)
+	require.Equal(t, 100, out.PageSize)
+	require.Empty(t, out.Items)
+}
+
+func TestTaskHistoryService_ListForUser_RejectsUnknownStatus(t *testing.T) {
+	repo := &taskHistoryRepoStub{}
+	_, err := NewTaskHistoryService(repo).ListForUser(context.Background(), 7, &TaskHistoryFilter{Status: "settled"})
+	require.ErrorIs(t, err, ErrTaskHistoryInvalidStatus)
+	require.Nil(t, repo.filter)
+}
+
+func TestTaskHistoryService_ListForUser_PropagatesRepoError(t *testing.T) {
+	repo := &taskHistoryRepoStub{err: errors.New("db down")}
+	_, err := NewTaskHistoryService(repo).ListForUser(context.Background(), 7, nil)
+	require.EqualError(t, err, "db down")
+}
+
+func TestTaskHistoryService_ListForUser_BuildsItems(t *testing.T) {
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	settled := created.Add(time.Hour)
+	actual := 1.25
+	msg := "quota exceeded"
+	deletedAt := settled
+	repo := &taskHistoryRepoStub{
+		total: 3,
+		jobs: []*BatchImageJob{
+			{BatchID: "batch_a", Model: "m", Status: BatchImageJobStatusCompleted, EstimatedCost: 2, ActualCost: &actual, SettledAt: &settled, Currency: "USD", CreatedAt: created},
+			{BatchID: "batch_b", Model: "m", Status: BatchImageJobStatusRunning, EstimatedCost: 3, CreatedAt: created},
+			{BatchID: "batch_c", Model: "m", Status: BatchImageJobStatusFailed, EstimatedCost: 1, LastErrorMessage: &msg, OutputDeletedAt: &deletedAt, CreatedAt: created},
+		},
+	}
+
+	out, err := NewTaskHistoryService(repo).ListForUser(context.Background(), 7, &TaskHistoryFilter{Page: 2, PageSize: 3})
+	require.NoError(t, err)
+	require.Equal(t, 3, out.Total)
+	require.Equal(t, 2, out.Page)
+	require.Len(t, out.Items, 3)
+
+	done := out.Items[0]
+	require.Equal(t, TaskTypeBatchImage, done.Type)
+	require.Equal(t, "completed", done.Status)
+	require.Equal(t, 1.25, done.Cost)
+	require.True(t, done.CostFinal)
+	require.Equal(t, "/v1/images/batches/batch_a/download", done.ResultURL)
+
+	running := out.Items[1]
+	require.Equal(t, "running", running.Status)
+	require.Equal(t, 3.0, running.Cost)
+	require.False(t, running.CostFinal)
+	require.Empty(t, running.ResultURL)
+
+	failed := out.Items[2]
+	require.Equal(t, "failed", failed.Status)
+	require.Equal(t, msg, failed.ErrorMessage)
+	require.Empty(t, failed.ResultURL)
+}
@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"strconv"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 )
 
+var ErrTokenCacheWipeUnsupported = infraerrors.New(http.StatusNotImplemented, "TOKEN_CACHE_WIPE_UNSUPPORTED", "token cache does not support wiping")
+
 type TokenCacheInvalidator interface {
 	InvalidateToken(ctx context.Context, account *Account) error
 }
@@ -68,6 +74,24 @@ func (c *CompositeTokenCacheInvalidator) InvalidateToken(ctx context.Context, ac
 	return nil
 }
 
+// InvalidateAll 清空全部缓存的 access token，返回删除的数量。
+// 之后的请求回落到账号凭证中的 token 并重新写入缓存，不会触发上游刷新。
+func (c *CompositeTokenCacheInvalidator) InvalidateAll(ctx context.Context) (int64, error) {
+	if c == nil || c.cache == nil {
+		return 0, nil
+	}
+	wiper, ok := c.cache.(TokenCacheWiper)
+	if !ok {
+		return 0, ErrTokenCacheWipeUnsupported
+	}
+	deleted, err := wiper.WipeAccessTokens(ctx)
+	if err != nil {
+		return deleted, fmt.Errorf("wipe token cache: %w", err)
+	}
+	slog.Warn("token_cache_wiped", "deleted", deleted)
+	return deleted, nil
+}
+
 // CheckTokenVersion 检查 account 的 token 版本是否已过时，并返回最新的 account
 // 用于解决异步刷新任务与请求线程的竞态条件：
 // 如果刷新任务已更新 token 并删除缓存，此时请求线程的旧 account 对象不应写入缓存
@@ -37,6 +37,28 @@ func (s *geminiTokenCacheStub) ReleaseRefreshLock(ctx context.Context, cacheKey
 	return nil
 }
 
+type wipingTokenCacheStub struct {
+	geminiTokenCacheStub
+	wiped int64
+	err   error
+}
+
+func (s *wipingTokenCacheStub) WipeAccessTokens(ctx context.Context) (int64, error) {
+	return s.wiped, s.err
+}
+
+func TestCompositeTokenCacheInvalidator_InvalidateAll(t *testing.T) {
+	deleted, err := NewCompositeTokenCacheInvalidator(&wipingTokenCacheStub{wiped: 5}).InvalidateAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(5), deleted)
+
+	_, err = NewCompositeTokenCacheInvalidator(&wipingTokenCacheStub{err: errors.New("redis down")}).InvalidateAll(context.Background())
+	require.Error(t, err)
+
+	_, err = NewCompositeTokenCacheInvalidator(&geminiTokenCacheStub{}).InvalidateAll(context.Background())
+	require.ErrorIs(t, err, ErrTokenCacheWipeUnsupported)
+}
+
 func TestCompositeTokenCacheInvalidator_Gemini(t *testing.T) {
 	cache := &geminiTokenCacheStub{}
 	invalidator := NewCompositeTokenCacheInvalidator(cache)
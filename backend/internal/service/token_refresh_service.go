@@ -65,6 +65,7 @@ type TokenRefreshService struct {
 	tempUnschedCache TempUnschedCache // 用于清除 Redis 中的临时不可调度缓存
 	refreshAPI       *OAuthRefreshAPI // 统一刷新 API
 	runtimeBlocker   AccountRuntimeBlocker
+	featureFlags     *FeatureFlagService
 
 	// OpenAI privacy: 刷新成功后检查并设置 training opt-out
 	privacyClientFactory PrivacyClientFactory
@@ -152,6 +153,20 @@ func (s *TokenRefreshService) eligiblePlatforms() []string {
 	return platforms
 }
 
+// enabledPlatforms 过滤掉功能开关已关闭的平台（如 gemini_gateway），其账号本周期不刷新。
+func (s *TokenRefreshService) enabledPlatforms(ctx context.Context, platforms []string) []string {
+	if s.featureFlags == nil {
+		return platforms
+	}
+	enabled := make([]string, 0, len(platforms))
+	for _, platform := range platforms {
+		if s.featureFlags.IsPlatformEnabled(ctx, platform) {
+			enabled = append(enabled, platform)
+		}
+	}
+	return enabled
+}
+
 func (s *TokenRefreshService) candidateAfterID() int64 {
 	s.candidateMu.Lock()
 	defer s.candidateMu.Unlock()
@@ -176,6 +191,11 @@ func (s *TokenRefreshService) SetRefreshAPI(api *OAuthRefreshAPI) {
 }
 
 // SetRefreshPolicy 注入后台刷新调用侧策略（用于显式化平台/场景差异行为）。
+// SetFeatureFlags 注入功能开关，关闭的平台跳过后台刷新。
+func (s *TokenRefreshService) SetFeatureFlags(flags *FeatureFlagService) {
+	s.featureFlags = flags
+}
+
 func (s *TokenRefreshService) SetRefreshPolicy(policy BackgroundRefreshPolicy) {
 	s.refreshPolicy = policy
 }
@@ -504,6 +524,10 @@ func (s *TokenRefreshService) processRefreshContext(parent context.Context) {
 		slog.Error("token_refresh.provider_registry_empty")
 		return
 	}
+	platforms = s.enabledPlatforms(ctx, platforms)
+	if len(platforms) == 0 {
+		return
+	}
 
 	refreshWindow := time.Duration(s.cfg.RefreshBeforeExpiryHours * float64(time.Hour))
 	pageSize := s.candidatePageSize()
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ToolsTruncatedHeader 响应头：按策略截断了描述的工具数量
+const ToolsTruncatedHeader = "X-Sub2API-Truncated-Tools"
+
+// 工具上限违规原因
+const (
+	ToolLimitReasonTooManyTools       = "too_many_tools"
+	ToolLimitReasonToolTooLarge       = "tool_too_large"
+	ToolLimitReasonToolsTooLarge      = "tools_too_large"
+	ToolLimitReasonDescriptionTooLong = "description_too_long"
+)
+
+// ToolLimitViolation 指明超限的工具。Index 为工具在请求中的序号（Gemini 按全部 functionDeclarations 展开计数），
+// 整体超限（数量 / 合计大小）时为 -1；Path 为 gjson 风格的点分路径。
+type ToolLimitViolation struct {
+	Index  int    `json:"index"`
+	Name   string `json:"name,omitempty"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Limit  int    `json:"limit"`
+	Actual int    `json:"actual"`
+}
+
+// Message 返回面向客户端的错误描述。
+func (v *ToolLimitViolation) Message() string {
+	tool := fmt.Sprintf("tool #%d", v.Index)
+	if v.Name != "" {
+		tool = fmt.Sprintf("tool #%d (%s)", v.Index, v.Name)
+	}
+	switch v.Reason {
+	case ToolLimitReasonTooManyTools:
+		return fmt.Sprintf("Request defines %d tools, exceeding the upstream limit of %d", v.Actual, v.Limit)
+	case ToolLimitReasonToolsTooLarge:
+		return fmt.Sprintf("Tool definitions total %d bytes, exceeding the upstream limit of %d bytes", v.Actual, v.Limit)
+	case ToolLimitReasonToolTooLarge:
+		return fmt.Sprintf("Definition of %s is %d bytes, exceeding the upstream limit of %d bytes", tool, v.Actual, v.Limit)
+	default:
+		return fmt.Sprintf("Description of %s is %d characters, exceeding the upstream limit of %d", tool, v.Actual, v.Limit)
+	}
+}
+
+// ToolLimitResult 工具上限检查结果。Violation 非空时应拒绝请求；否则 Body 为（可能截断了描述的）请求体。
+type ToolLimitResult struct {
+	Body           []byte
+	TruncatedTools int
+	Violation      *ToolLimitViolation
+}
+
+// toolDefinition 请求体中的一个工具定义
+type toolDefinition struct {
+	path     string
+	descPath string
+}
+
+// ToolLimitFor 返回平台的工具上限；未启用或平台未配置时返回 false。
+func ToolLimitFor(cfg *config.Config, platform string) (config.GatewayToolLimit, bool) {
+	if cfg == nil || !cfg.Gateway.ToolLimits.Enabled || platform == "" {
+		return config.GatewayToolLimit{}, false
+	}
+	limit, ok := cfg.Gateway.ToolLimits.Platforms[platform]
+	if !ok || limit == (config.GatewayToolLimit{}) {
+		return config.GatewayToolLimit{}, false
+	}
+	return limit, true
+}
+
+// ApplyToolLimits 按上限检查请求体中的 tools 定义。format 取 RequestValidationFormatForPath 的返回值；
+// policy 为 truncate 时超长描述被截断到上限（按字符，不拆分 UTF-8 编码），其它超限项一律返回 Violation。
+// 请求体不是合法 JSON 或没有 tools 时原样返回，交由后续环节处理。
+func ApplyToolLimits(body []byte, format string, limit config.GatewayToolLimit, policy string) (*ToolLimitResult, error) {
+	result := &ToolLimitResult{Body: body}
+	if !gjson.ValidBytes(body) {
+		return result, nil
+	}
+	tools := collectToolDefinitions(body, format)
+	if len(tools) == 0 {
+		return result, nil
+	}
+	if limit.MaxTools > 0 && len(tools) > limit.MaxTools {
+		result.Violation = &ToolLimitViolation{
+			Index: -1, Path: "tools", Reason: ToolLimitReasonTooManyTools, Limit: limit.MaxTools, Actual: len(tools),
+		}
+		return result, nil
+	}
+
+	total := 0
+	for i, tool := range tools {
+		value := gjson.GetBytes(result.Body, tool.path)
+		name := toolDefinitionName(value, format)
+		if limit.MaxDescriptionChars > 0 {
+			desc := gjson.GetBytes(result.Body, tool.descPath)
+			if desc.Type == gjson.String {
+				if chars := utf8.RuneCountInString(desc.String()); chars > limit.MaxDescriptionChars {
+					if policy != config.ToolDescriptionPolicyTruncate {
+						result.Violation = &ToolLimitViolation{
+							Index: i, Name: name, Path: tool.descPath, Reason: ToolLimitReasonDescriptionTooLong,
+							Limit: limit.MaxDescriptionChars, Actual: chars,
+						}
+						return result, nil
+					}
+					updated, err := sjson.SetBytes(result.Body, tool.descPath, truncateRunes(desc.String(), limit.MaxDescriptionChars))
+					if err != nil {
+						return nil, fmt.Errorf("truncate tool description: %w", err)
+					}
+					result.Body = updated
+					result.TruncatedTools++
+					value = gjson.GetBytes(result.Body, tool.path)
+				}
+			}
+		}
+		size := len(value.Raw)
+		if limit.MaxToolBytes > 0 && size > limit.MaxToolBytes {
+			result.Violation = &ToolLimitViolation{
+				Index: i, Name: name, Path: tool.path, Reason: ToolLimitReasonToolTooLarge, Limit: limit.MaxToolBytes, Actual: size,
+			}
+			return result, nil
+		}
+		total += size
+	}
+	if limit.MaxTotalBytes > 0 && total > limit.MaxTotalBytes {
+		result.Violation = &ToolLimitViolation{
+			Index: -1, Path: "tools", Reason: ToolLimitReasonToolsTooLarge, Limit: limit.MaxTotalBytes, Actual: total,
+		}
+	}
+	return result, nil
+}
+
+// collectToolDefinitions 按请求格式列出全部工具定义：
+// Anthropic / Responses 为 tools[i]，Chat Completions 为 tools[i]（描述在 function 下），
+// Gemini 展开 tools[i].functionDeclarations[j]，不含函数声明的工具（如 googleSearch）按一个工具计。
+func collectToolDefinitions(body []byte, format string) []toolDefinition {
+	tools := gjson.GetBytes(body, "tools")
+	if !tools.IsArray() {
+		return nil
+	}
+	var out []toolDefinition
+	for i, tool := range tools.Array() {
+		path := "tools." + strconv.Itoa(i)
+		switch format {
+		case ResponseLanguageFormatChatCompletions:
+			out = append(out, toolDefinition{path: path, descPath: path + ".function.description"})
+		case RequestValidationFormatGemini:
+			field := "functionDeclarations"
+			decls := tool.Get(field)
+			if !decls.Exists() {
+				field = "function_declarations"
+				decls = tool.Get(field)
+			}
+			if !decls.IsArray() {
+				out = append(out, toolDefinition{path: path, descPath: path + ".description"})
+				continue
+			}
+			for j := range decls.Array() {
+				declPath := path + "." + field + "." + strconv.Itoa(j)
+				out = append(out, toolDefinition{path: declPath, descPath: declPath + ".description"})
+			}
+		default:
+			out = append(out, toolDefinition{path: path, descPath: path + ".description"})
+		}
+	}
+	return out
+}
+
+func toolDefinitionName(tool gjson.Result, format string) string {
+	if format == ResponseLanguageFormatChatCompletions {
+		if name := tool.Get("function.name").String(); name != "" {
+			return name
+		}
+	}
+	return tool.Get("name").String()
+}
+
+// truncateRunes 截取前 limit 个字符
+func truncateRunes(s string, limit int) string {
+	count := 0
+	for i := range s {
+		if count == limit {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
@@ -0,0 +1,88 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyToolLimits_TooManyTools(t *testing.T) {
+	body := []byte(`{"tools":[{"name":"a"},{"name":"b"},{"name":"c"}]}`)
+	result, err := ApplyToolLimits(body, ResponseLanguageFormatAnthropic, config.GatewayToolLimit{MaxTools: 2}, config.ToolDescriptionPolicyReject)
+	require.NoError(t, err)
+	require.NotNil(t, result.Violation)
+	require.Equal(t, ToolLimitReasonTooManyTools, result.Violation.Reason)
+	require.Equal(t, 2, result.Violation.Limit)
+	require.Equal(t, 3, result.Violation.Actual)
+}
+
+func TestApplyToolLimits_RejectsLongDescriptionWithToolIdentity(t *testing.T) {
+	body := []byte(`{"tools":[{"type":"function","function":{"name":"ok","description":"short"}},{"type":"function","function":{"name":"lookup","description":"0123456789"}}]}`)
+	result, err := ApplyToolLimits(body, ResponseLanguageFormatChatCompletions, config.GatewayToolLimit{MaxDescriptionChars: 5}, config.ToolDescriptionPolicyReject)
+	require.NoError(t, err)
+	require.Equal(t, &ToolLimitViolation{
+		Index: 1, Name: "lookup", Path: "tools.1.function.description",
+		Reason: ToolLimitReasonDescriptionTooLong, Limit: 5, Actual: 10,
+	}, result.Violation)
+	require.Contains(t, result.Violation.Message(), "tool #1 (lookup)")
+}
+
+func TestApplyToolLimits_TruncatesDescriptionByRune(t *testing.T) {
+	body := []byte(`{"tools":[{"name":"search","description":"查询天气与空气质量","input_schema":{"type":"object"}}]}`)
+	result, err := ApplyToolLimits(body, ResponseLanguageFormatAnthropic, config.GatewayToolLimit{MaxDescriptionChars: 4}, config.ToolDescriptionPolicyTruncate)
+	require.NoError(t, err)
+	require.Nil(t, result.Violation)
+	require.Equal(t, 1, result.TruncatedTools)
+	require.Equal(t, "查询天气", gjson.GetBytes(result.Body, "tools.0.description").String())
+	require.Equal(t, "object", gjson.GetBytes(result.Body, "tools.0.input_schema.type").String())
+}
+
+func TestApplyToolLimits_GeminiFunctionDeclarations(t *testing.T) {
+	body := []byte(`{"contents":[],"tools":[{"googleSearch":{}},{"functionDeclarations":[{"name":"a","description":"x"},{"name":"big","description":"y","parameters":{"type":"object","properties":{"q":{"type":"string"}}}}]}]}`)
+
+	result, err := ApplyToolLimits(body, RequestValidationFormatGemini, config.GatewayToolLimit{MaxTools: 2}, config.ToolDescriptionPolicyReject)
+	require.NoError(t, err)
+	require.NotNil(t, result.Violation)
+	require.Equal(t, 3, result.Violation.Actual)
+
+	result, err = ApplyToolLimits(body, RequestValidationFormatGemini, config.GatewayToolLimit{MaxToolBytes: 40}, config.ToolDescriptionPolicyReject)
+	require.NoError(t, err)
+	require.NotNil(t, result.Violation)
+	require.Equal(t, ToolLimitReasonToolTooLarge, result.Violation.Reason)
+	require.Equal(t, 2, result.Violation.Index)
+	require.Equal(t, "big", result.Violation.Name)
+	require.Equal(t, "tools.1.functionDeclarations.1", result.Violation.Path)
+}
+
+func TestApplyToolLimits_TotalBytesAndPassThrough(t *testing.T) {
+	body := []byte(`{"tools":[{"type":"function","name":"a","description":"aaaa"},{"type":"function","name":"b","description":"bbbb"}]}`)
+
+	result, err := ApplyToolLimits(body, ResponseLanguageFormatResponses, config.GatewayToolLimit{MaxTotalBytes: 60}, config.ToolDescriptionPolicyReject)
+	require.NoError(t, err)
+	require.NotNil(t, result.Violation)
+	require.Equal(t, ToolLimitReasonToolsTooLarge, result.Violation.Reason)
+	require.Equal(t, -1, result.Violation.Index)
+
+	result, err = ApplyToolLimits(body, ResponseLanguageFormatResponses, config.GatewayToolLimit{MaxTools: 5, MaxToolBytes: 100}, config.ToolDescriptionPolicyReject)
+	require.NoError(t, err)
+	require.Nil(t, result.Violation)
+	require.Equal(t, body, result.Body)
+}
+
+func TestToolLimitFor(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.ToolLimits.Platforms = map[string]config.GatewayToolLimit{PlatformOpenAI: {MaxTools: 128}}
+	_, ok := ToolLimitFor(cfg, PlatformOpenAI)
+	require.False(t, ok, "disabled")
+
+	cfg.Gateway.ToolLimits.Enabled = true
+	limit, ok := ToolLimitFor(cfg, PlatformOpenAI)
+	require.True(t, ok)
+	require.Equal(t, 128, limit.MaxTools)
+	_, ok = ToolLimitFor(cfg, PlatformAnthropic)
+	require.False(t, ok)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const (
+	// UpstreamMTLSAccountExtraKey 账号级证书配置名（覆盖平台默认值）
+	UpstreamMTLSAccountExtraKey = "upstream_mtls_profile"
+	// UpstreamMTLSProfileNone 账号显式不使用客户端证书
+	UpstreamMTLSProfileNone = "none"
+
+	upstreamMTLSAccountCacheTTL = time.Minute
+)
+
+// UpstreamMTLSIdentity 一组已加载的客户端证书，供上游 Transport 使用。
+type UpstreamMTLSIdentity struct {
+	// Name 证书配置名，同时用于区分连接池缓存键
+	Name         string
+	Certificates []tls.Certificate
+	// RootCAs 为 nil 时使用系统根证书
+	RootCAs *x509.CertPool
+}
+
+// UpstreamMTLSService 按账号解析上游 mTLS 客户端证书（gateway.upstream_mtls）。
+//
+// 证书与私钥在启动时从文件加载并只保存在内存中，不写入数据库、不经接口回显；
+// 账号只通过 extra.upstream_mtls_profile 引用配置名。
+type UpstreamMTLSService struct {
+	accountRepo AccountRepository
+	identities  map[string]*UpstreamMTLSIdentity
+	platforms   map[string]string
+	now         func() time.Time
+
+	mu    sync.Mutex
+	cache map[int64]upstreamMTLSCacheEntry
+}
+
+type upstreamMTLSCacheEntry struct {
+	profile   string
+	expiresAt time.Time
+}
+
+// NewUpstreamMTLSService 加载全部证书配置；任一证书无法加载时返回错误，避免带着错误配置启动。
+func NewUpstreamMTLSService(cfg *config.Config, accountRepo AccountRepository) (*UpstreamMTLSService, error) {
+	s := &UpstreamMTLSService{
+		accountRepo: accountRepo,
+		identities:  make(map[string]*UpstreamMTLSIdentity),
+		platforms:   make(map[string]string),
+		now:         time.Now,
+		cache:       make(map[int64]upstreamMTLSCacheEntry),
+	}
+	if cfg == nil {
+		return s, nil
+	}
+	for name, profile := range cfg.Gateway.UpstreamMTLS.Profiles {
+		name = normalizeUpstreamMTLSProfileName(name)
+		identity, err := loadUpstreamMTLSIdentity(name, profile)
+		if err != nil {
+			return nil, err
+		}
+		s.identities[name] = identity
+	}
+	for platform, name := range cfg.Gateway.UpstreamMTLS.PlatformProfiles {
+		s.platforms[strings.ToLower(strings.TrimSpace(platform))] = normalizeUpstreamMTLSProfileName(name)
+	}
+	if len(s.identities) > 0 {
+		slog.Info("upstream_mtls_profiles_loaded", "profiles", len(s.identities), "platform_defaults", len(s.platforms))
+	}
+	return s, nil
+}
+
+// Enabled 是否配置了任何证书
+func (s *UpstreamMTLSService) Enabled() bool {
+	return s != nil && len(s.identities) > 0
+}
+
+// Resolve 返回账号应使用的客户端证书；未配置或账号显式关闭时返回 nil。
+// 账号信息短暂缓存，避免每个请求都查库。
+func (s *UpstreamMTLSService) Resolve(ctx context.Context, accountID int64) *UpstreamMTLSIdentity {
+	if !s.Enabled() || accountID <= 0 {
+		return nil
+	}
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.cache[accountID]
+	s.mu.Unlock()
+	if !ok || now.After(entry.expiresAt) {
+		entry = upstreamMTLSCacheEntry{profile: s.lookupProfile(ctx, accountID), expiresAt: now.Add(upstreamMTLSAccountCacheTTL)}
+		s.mu.Lock()
+		s.cache[accountID] = entry
+		s.mu.Unlock()
+	}
+	if entry.profile == "" {
+		return nil
+	}
+	return s.identities[entry.profile]
+}
+
+// ProfileForAccount 按账号 extra 覆盖 > 平台默认的顺序选择证书配置名，空串表示不使用。
+func (s *UpstreamMTLSService) ProfileForAccount(account *Account) string {
+	if !s.Enabled() || account == nil {
+		return ""
+	}
+	if raw, ok := account.Extra[UpstreamMTLSAccountExtraKey].(string); ok && strings.TrimSpace(raw) != "" {
+		name := normalizeUpstreamMTLSProfileName(raw)
+		if name == UpstreamMTLSProfileNone {
+			return ""
+		}
+		if _, exists := s.identities[name]; exists {
+			return name
+		}
+		slog.Warn("upstream_mtls_unknown_account_profile", "account_id", account.ID, "profile", name)
+		return ""
+	}
+	return s.platforms[strings.ToLower(account.Platform)]
+}
+
+func (s *UpstreamMTLSService) lookupProfile(ctx context.Context, accountID int64) string {
+	if s.accountRepo == nil {
+		return ""
+	}
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil || account == nil {
+		return ""
+	}
+	return s.ProfileForAccount(account)
+}
+
+func normalizeUpstreamMTLSProfileName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func loadUpstreamMTLSIdentity(name string, profile config.GatewayUpstreamMTLSProfile) (*UpstreamMTLSIdentity, error) {
+	certFile := strings.TrimSpace(profile.CertFile)
+	keyFile := strings.TrimSpace(profile.KeyFile)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load upstream mtls profile %q: %w", name, err)
+	}
+	if info, err := os.Stat(keyFile); err == nil && info.Mode().Perm()&0o077 != 0 {
+		slog.Warn("upstream_mtls_key_file_permissive", "profile", name, "mode", info.Mode().Perm().String())
+	}
+	identity := &UpstreamMTLSIdentity{Name: name, Certificates: []tls.Certificate{cert}}
+	if caFile := strings.TrimSpace(profile.CAFile); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read upstream mtls profile %q ca_file: %w", name, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("upstream mtls profile %q ca_file contains no certificates", name)
+		}
+		identity.RootCAs = pool
+	}
+	return identity, nil
+}
@@ -0,0 +1,121 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type upstreamMTLSAccountRepoStub struct {
+	AccountRepository
+	accounts map[int64]*Account
+	calls    int
+}
+
+func (s *upstreamMTLSAccountRepoStub) GetByID(_ context.Context, id int64) (*Account, error) {
+	s.calls++
+	return s.accounts[id], nil
+}
+
+// writeUpstreamMTLSTestPair 生成自签名客户端证书，返回证书与私钥文件路径
+func writeUpstreamMTLSTestPair(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sub2api-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestUpstreamMTLSService_ResolvesAccountOverPlatform(t *testing.T) {
+	certFile, keyFile := writeUpstreamMTLSTestPair(t)
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamMTLS.Profiles = map[string]config.GatewayUpstreamMTLSProfile{
+		"corp":  {CertFile: certFile, KeyFile: keyFile, CAFile: certFile},
+		"other": {CertFile: certFile, KeyFile: keyFile},
+	}
+	cfg.Gateway.UpstreamMTLS.PlatformProfiles = map[string]string{PlatformAnthropic: "Corp"}
+	repo := &upstreamMTLSAccountRepoStub{accounts: map[int64]*Account{
+		1: {ID: 1, Platform: PlatformAnthropic},
+		2: {ID: 2, Platform: PlatformAnthropic, Extra: map[string]any{UpstreamMTLSAccountExtraKey: "OTHER"}},
+		3: {ID: 3, Platform: PlatformAnthropic, Extra: map[string]any{UpstreamMTLSAccountExtraKey: UpstreamMTLSProfileNone}},
+		4: {ID: 4, Platform: PlatformOpenAI},
+		5: {ID: 5, Platform: PlatformAnthropic, Extra: map[string]any{UpstreamMTLSAccountExtraKey: "missing"}},
+	}}
+
+	svc, err := NewUpstreamMTLSService(cfg, repo)
+	require.NoError(t, err)
+	require.True(t, svc.Enabled())
+
+	identity := svc.Resolve(context.Background(), 1)
+	require.NotNil(t, identity)
+	require.Equal(t, "corp", identity.Name)
+	require.Len(t, identity.Certificates, 1)
+	require.NotNil(t, identity.RootCAs)
+
+	require.Equal(t, "other", svc.Resolve(context.Background(), 2).Name)
+	require.Nil(t, svc.Resolve(context.Background(), 3), "account opted out")
+	require.Nil(t, svc.Resolve(context.Background(), 4), "platform without default")
+	require.Nil(t, svc.Resolve(context.Background(), 5), "unknown override does not fall back")
+
+	calls := repo.calls
+	svc.Resolve(context.Background(), 1)
+	require.Equal(t, calls, repo.calls, "account lookup is cached")
+	now := time.Now()
+	svc.now = func() time.Time { return now.Add(2 * upstreamMTLSAccountCacheTTL) }
+	svc.Resolve(context.Background(), 1)
+	require.Equal(t, calls+1, repo.calls)
+}
+
+func TestUpstreamMTLSService_LoadErrors(t *testing.T) {
+	certFile, keyFile := writeUpstreamMTLSTestPair(t)
+
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamMTLS.Profiles = map[string]config.GatewayUpstreamMTLSProfile{
+		"corp": {CertFile: certFile, KeyFile: filepath.Join(t.TempDir(), "absent.key")},
+	}
+	_, err := NewUpstreamMTLSService(cfg, nil)
+	require.ErrorContains(t, err, `load upstream mtls profile "corp"`)
+
+	badCA := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(badCA, []byte("not a pem"), 0o600))
+	cfg.Gateway.UpstreamMTLS.Profiles = map[string]config.GatewayUpstreamMTLSProfile{
+		"corp": {CertFile: certFile, KeyFile: keyFile, CAFile: badCA},
+	}
+	_, err = NewUpstreamMTLSService(cfg, nil)
+	require.ErrorContains(t, err, "ca_file contains no certificates")
+
+	svc, err := NewUpstreamMTLSService(&config.Config{}, nil)
+	require.NoError(t, err)
+	require.False(t, svc.Enabled())
+	require.Nil(t, svc.Resolve(context.Background(), 1))
+}
@@ -5,13 +5,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
 
 var ErrUpstreamResponseBodyTooLarge = errors.New("upstream response body too large")
 
+// ErrUpstreamStreamTooLarge 流式响应累计字节超过上限。
+var ErrUpstreamStreamTooLarge = errors.New("upstream stream exceeded size budget")
+
 // defaultUpstreamResponseReadMaxBytes 源自 config.DefaultUpstreamResponseReadMaxBytes，
 // 仅在 cfg 为 nil 时作为兜底（测试或极端场景）。
 const defaultUpstreamResponseReadMaxBytes = config.DefaultUpstreamResponseReadMaxBytes
@@ -23,6 +29,48 @@ func resolveUpstreamResponseReadLimit(cfg *config.Config) int64 {
 	return defaultUpstreamResponseReadMaxBytes
 }
 
+// resolveUpstreamResponseReadLimitForContext 优先使用按入站路由配置的上限，未配置时回退全局上限。
+func resolveUpstreamResponseReadLimitForContext(cfg *config.Config, c *gin.Context) int64 {
+	if cfg != nil {
+		if limit := matchEndpointByteLimit(cfg.Gateway.UpstreamResponseEndpointMaxBytes, c); limit > 0 {
+			return limit
+		}
+	}
+	return resolveUpstreamResponseReadLimit(cfg)
+}
+
+// resolveUpstreamStreamLimit 返回流式响应累计字节上限，0 表示不限制。
+func resolveUpstreamStreamLimit(cfg *config.Config, c *gin.Context) int64 {
+	if cfg == nil {
+		return 0
+	}
+	if limit, ok := lookupEndpointByteLimit(cfg.Gateway.UpstreamStreamEndpointMaxBytes, c); ok {
+		return limit
+	}
+	return cfg.Gateway.UpstreamStreamMaxBytes
+}
+
+func matchEndpointByteLimit(limits map[string]int64, c *gin.Context) int64 {
+	limit, _ := lookupEndpointByteLimit(limits, c)
+	return limit
+}
+
+// lookupEndpointByteLimit 按路由路径匹配（viper 会把 map key 转为小写，这里统一按小写匹配）。
+func lookupEndpointByteLimit(limits map[string]int64, c *gin.Context) (int64, bool) {
+	if len(limits) == 0 || c == nil {
+		return 0, false
+	}
+	path := c.FullPath()
+	if path == "" && c.Request != nil && c.Request.URL != nil {
+		path = c.Request.URL.Path
+	}
+	if path == "" {
+		return 0, false
+	}
+	limit, ok := limits[strings.ToLower(path)]
+	return limit, ok
+}
+
 func readUpstreamResponseBodyLimited(reader io.Reader, maxBytes int64) ([]byte, error) {
 	if reader == nil {
 		return nil, errors.New("response body is nil")
@@ -47,7 +95,7 @@ type TooLargeWriter func(c *gin.Context)
 // ReadUpstreamResponseBody 读取上游非流式响应体。
 // 超限时自动记录 ops error 并调用 onTooLarge 向客户端写错误。
 func ReadUpstreamResponseBody(reader io.Reader, cfg *config.Config, c *gin.Context, onTooLarge TooLargeWriter) ([]byte, error) {
-	maxBytes := resolveUpstreamResponseReadLimit(cfg)
+	maxBytes := resolveUpstreamResponseReadLimitForContext(cfg, c)
 	body, err := readUpstreamResponseBodyLimited(reader, maxBytes)
 	if err != nil {
 		if errors.Is(err, ErrUpstreamResponseBodyTooLarge) {
@@ -61,6 +109,56 @@ func ReadUpstreamResponseBody(reader io.Reader, cfg *config.Config, c *gin.Conte
 	return body, nil
 }
 
+// upstreamStreamBudgetReader 统计流式响应累计读取字节，超过预算后返回 ErrUpstreamStreamTooLarge。
+// 读取在独立 goroutine 中进行，ops 错误只记录一次。
+type upstreamStreamBudgetReader struct {
+	io.ReadCloser
+	c        *gin.Context
+	limit    int64
+	read     int64
+	tripOnce sync.Once
+}
+
+func (r *upstreamStreamBudgetReader) Read(p []byte) (int, error) {
+	if r.read > r.limit {
+		return 0, fmt.Errorf("%w: limit=%d", ErrUpstreamStreamTooLarge, r.limit)
+	}
+	if remaining := r.limit - r.read; int64(len(p)) > remaining+1 {
+		// 多读 1 字节用于判断是否真正超限（恰好等于上限的流不应被中止）
+		p = p[:remaining+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if r.read > r.limit {
+		r.trip()
+		return n - int(r.read-r.limit), fmt.Errorf("%w: limit=%d", ErrUpstreamStreamTooLarge, r.limit)
+	}
+	return n, err
+}
+
+func (r *upstreamStreamBudgetReader) trip() {
+	r.tripOnce.Do(func() {
+		setOpsUpstreamError(r.c, http.StatusBadGateway, "upstream stream too large", fmt.Sprintf("limit=%d", r.limit))
+		path := ""
+		if r.c != nil {
+			path = r.c.FullPath()
+		}
+		logger.LegacyPrintf("service.gateway", "[StreamBudget] upstream stream aborted: path=%s limit=%d", path, r.limit)
+	})
+}
+
+// limitUpstreamStreamBody 按配置为流式响应体挂上累计字节预算；未配置上限时原样返回。
+func limitUpstreamStreamBody(c *gin.Context, cfg *config.Config, resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	limit := resolveUpstreamStreamLimit(cfg, c)
+	if limit <= 0 {
+		return
+	}
+	resp.Body = &upstreamStreamBudgetReader{ReadCloser: resp.Body, c: c, limit: limit}
+}
+
 // anthropicTooLargeError 以 Anthropic Messages API 格式写入超限错误。
 func anthropicTooLargeError(c *gin.Context) {
 	c.JSON(http.StatusBadGateway, gin.H{
@@ -3,6 +3,10 @@ package service
 import (
 	"bytes"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"testing/iotest"
 
@@ -78,3 +82,71 @@ func TestReadUpstreamResponseBody(t *testing.T) {
 		require.False(t, called)
 	})
 }
+
+func newUpstreamLimitTestContext(path string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, path, nil)
+	return c
+}
+
+func TestResolveUpstreamResponseReadLimitForContext(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamResponseReadMaxBytes = 1000
+	cfg.Gateway.UpstreamResponseEndpointMaxBytes = map[string]int64{"/v1/messages/count_tokens": 10}
+
+	require.Equal(t, int64(10), resolveUpstreamResponseReadLimitForContext(cfg, newUpstreamLimitTestContext("/v1/messages/count_tokens")))
+	require.Equal(t, int64(1000), resolveUpstreamResponseReadLimitForContext(cfg, newUpstreamLimitTestContext("/v1/messages")))
+	require.Equal(t, int64(1000), resolveUpstreamResponseReadLimitForContext(cfg, nil))
+}
+
+func TestLimitUpstreamStreamBody(t *testing.T) {
+	newResp := func(body string) *http.Response {
+		return &http.Response{Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		resp := newResp("data: hello\n\n")
+		limitUpstreamStreamBody(newUpstreamLimitTestContext("/v1/messages"), &config.Config{}, resp)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "data: hello\n\n", string(body))
+	})
+
+	t.Run("exactly at limit is allowed", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Gateway.UpstreamStreamMaxBytes = 5
+		resp := newResp("12345")
+		limitUpstreamStreamBody(newUpstreamLimitTestContext("/v1/messages"), cfg, resp)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "12345", string(body))
+	})
+
+	t.Run("exceeding limit aborts and records ops error", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Gateway.UpstreamStreamMaxBytes = 100
+		cfg.Gateway.UpstreamStreamEndpointMaxBytes = map[string]int64{"/v1/messages": 4}
+		c := newUpstreamLimitTestContext("/v1/messages")
+		resp := newResp("123456789")
+		limitUpstreamStreamBody(c, cfg, resp)
+
+		body, err := io.ReadAll(resp.Body)
+		require.True(t, errors.Is(err, ErrUpstreamStreamTooLarge))
+		require.Equal(t, "1234", string(body))
+		status, ok := c.Get(OpsUpstreamStatusCodeKey)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadGateway, status)
+	})
+
+	t.Run("endpoint override of zero disables the global limit", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Gateway.UpstreamStreamMaxBytes = 2
+		cfg.Gateway.UpstreamStreamEndpointMaxBytes = map[string]int64{"/v1/responses": 0}
+		resp := newResp("123456789")
+		limitUpstreamStreamBody(newUpstreamLimitTestContext("/v1/responses"), cfg, resp)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "123456789", string(body))
+	})
+}
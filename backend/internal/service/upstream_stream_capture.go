@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// ErrUpstreamStreamCaptureNotFound 留存记录不存在（未命中留存条件或已过保留期）。
+var ErrUpstreamStreamCaptureNotFound = infraerrors.NotFound("UPSTREAM_STREAM_CAPTURE_NOT_FOUND", "upstream stream capture not found")
+
+// 留存原因
+const (
+	UpstreamStreamCaptureReasonFlagged = "flagged" // API Key 被管理员标记为全量留存
+	UpstreamStreamCaptureReasonError   = "error"   // 请求失败（客户端状态码 >= 400、上游报错或读流中断）
+)
+
+// UpstreamStreamCapture 单个网关请求的上游原始响应缓冲（含所有 failover 尝试）。
+// 由中间件挂到请求 context，HTTP 上游客户端在拿到响应后经 WrapResponse 边读边复制；超过上限后只标记截断。
+type UpstreamStreamCapture struct {
+	limit int
+
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	truncated bool
+	attempts  int
+	failed    bool
+}
+
+type upstreamStreamCaptureContextKey struct{}
+
+// NewUpstreamStreamCapture creates a capture buffer bounded to limit bytes.
+func NewUpstreamStreamCapture(limit int) *UpstreamStreamCapture {
+	return &UpstreamStreamCapture{limit: limit}
+}
+
+// WithUpstreamStreamCapture 把留存缓冲挂到 context；nil 时原样返回。
+func WithUpstreamStreamCapture(ctx context.Context, c *UpstreamStreamCapture) context.Context {
+	if c == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, upstreamStreamCaptureContextKey{}, c)
+}
+
+// UpstreamStreamCaptureFromContext 取出当前请求的留存缓冲；未开启留存时返回 nil（方法对 nil 接收者安全）。
+func UpstreamStreamCaptureFromContext(ctx context.Context) *UpstreamStreamCapture {
+	if ctx == nil {
+		return nil
+	}
+	c, _ := ctx.Value(upstreamStreamCaptureContextKey{}).(*UpstreamStreamCapture)
+	return c
+}
+
+// WrapResponse 记录一次上游尝试的分隔行，并把响应体替换为边读边复制的 reader。
+// 分隔行只含 host + path，不含 query，避免 Gemini 等上游把密钥放在 URL 参数中被落盘。
+func (c *UpstreamStreamCapture) WrapResponse(req *http.Request, resp *http.Response) {
+	if c == nil || resp == nil {
+		return
+	}
+	target := ""
+	if req != nil && req.URL != nil {
+		target = req.Method + " " + req.URL.Host + req.URL.Path
+	}
+	c.mu.Lock()
+	c.attempts++
+	if resp.StatusCode >= http.StatusBadRequest {
+		c.failed = true
+	}
+	header := fmt.Sprintf("### attempt %d %s status=%d at=%s\n", c.attempts, target, resp.StatusCode, time.Now().UTC().Format(time.RFC3339Nano))
+	c.appendLocked([]byte(header))
+	c.mu.Unlock()
+	if resp.Body != nil {
+		resp.Body = &upstreamStreamCaptureBody{ReadCloser: resp.Body, capture: c}
+	}
+}
+
+// Failed 是否有上游尝试返回错误状态码或读流异常中断。
+func (c *UpstreamStreamCapture) Failed() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failed
+}
+
+// Snapshot 返回已留存内容的副本、是否截断与尝试次数。
+func (c *UpstreamStreamCapture) Snapshot() (data []byte, truncated bool, attempts int) {
+	if c == nil {
+		return nil, false, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return bytes.Clone(c.buf.Bytes()), c.truncated, c.attempts
+}
+
+func (c *UpstreamStreamCapture) append(b []byte, readErr error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.appendLocked(b)
+	if readErr != nil && !errors.Is(readErr, io.EOF) {
+		c.failed = true
+		c.appendLocked([]byte("\n### read error: " + readErr.Error() + "\n"))
+	}
+}
+
+func (c *UpstreamStreamCapture) appendLocked(b []byte) {
+	if c.truncated || len(b) == 0 {
+		return
+	}
+	if remaining := c.limit - c.buf.Len(); c.limit > 0 && len(b) > remaining {
+		_, _ = c.buf.Write(b[:remaining])
+		c.truncated = true
+		return
+	}
+	_, _ = c.buf.Write(b)
+}
+
+type upstreamStreamCaptureBody struct {
+	io.ReadCloser
+	capture *UpstreamStreamCapture
+}
+
+func (b *upstreamStreamCaptureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.capture.append(p[:n], err)
+	return n, err
+}
+
+// UpstreamStreamCaptureRecord 一条已上传的留存索引。
+type UpstreamStreamCaptureRecord struct {
+	ID         int64     `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	RequestID  string    `json:"request_id"`
+	UserID     int64     `json:"user_id"`
+	APIKeyID   int64     `json:"api_key_id"`
+	AccountID  *int64    `json:"account_id,omitempty"`
+	Platform   string    `json:"platform"`
+	Endpoint   string    `json:"endpoint"`
+	StatusCode int       `json:"status_code"`
+	Reason     string    `json:"reason"`
+	Attempts   int       `json:"attempts"`
+	ObjectKey  string    `json:"-"`
+	SizeBytes  int64     `json:"size_bytes"`
+	Truncated  bool      `json:"truncated"`
+}
+
+// UpstreamStreamCaptureFilter 留存列表查询条件。
+type UpstreamStreamCaptureFilter struct {
+	Page     int
+	PageSize int
+
+	APIKeyID  *int64
+	RequestID string
+	Reason    string
+}
+
+// UpstreamStreamCaptureList 分页结果。
+type UpstreamStreamCaptureList struct {
+	Items    []*UpstreamStreamCaptureRecord `json:"items"`
+	Total    int                            `json:"total"`
+	Page     int                            `json:"page"`
+	PageSize int                            `json:"page_size"`
+}
+
+// UpstreamStreamCaptureRepository 留存索引持久化端口。
+type UpstreamStreamCaptureRepository interface {
+	Insert(ctx context.Context, record *UpstreamStreamCaptureRecord) error
+	GetByID(ctx context.Context, id int64) (*UpstreamStreamCaptureRecord, error)
+	// GetLatestByRequestID 同一 request_id 存在多条时返回最新一条。
+	GetLatestByRequestID(ctx context.Context, requestID string) (*UpstreamStreamCaptureRecord, error)
+	List(ctx context.Context, filter *UpstreamStreamCaptureFilter) (*UpstreamStreamCaptureList, error)
+	// ListBefore 返回 cutoff 之前创建的最多 limit 条记录，供保留期清理先删对象再删索引。
+	ListBefore(ctx context.Context, cutoff time.Time, limit int) ([]*UpstreamStreamCaptureRecord, error)
+	DeleteByIDs(ctx context.Context, ids []int64) (int64, error)
+}
+
+// UsageRequestIDFromContext 返回与 usage_logs.request_id 一致的请求标识（client:/local: 前缀）；
+// ctx 中两者都没有时返回空串。
+func UsageRequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if clientRequestID, _ := ctx.Value(ctxkey.ClientRequestID).(string); strings.TrimSpace(clientRequestID) != "" {
+		return "client:" + strings.TrimSpace(clientRequestID)
+	}
+	if requestID, _ := ctx.Value(ctxkey.RequestID).(string); strings.TrimSpace(requestID) != "" {
+		return "local:" + strings.TrimSpace(requestID)
+	}
+	return ""
+}
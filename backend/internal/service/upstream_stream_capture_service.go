@@ -0,0 +1,325 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	upstreamStreamCaptureQueueCapacity = 256
+	upstreamStreamCaptureUploadTimeout = 30 * time.Second
+
+	upstreamStreamCaptureRetentionCheckInterval = 6 * time.Hour
+	upstreamStreamCaptureRetentionStartupDelay  = 5 * time.Minute
+	upstreamStreamCaptureRetentionBatchSize     = 500
+)
+
+// pendingUpstreamStreamCapture 待上传的留存（内容与索引元数据）。
+type pendingUpstreamStreamCapture struct {
+	record *UpstreamStreamCaptureRecord
+	data   []byte
+}
+
+// UpstreamStreamCaptureService 上游原始响应留存服务。
+// 写入端在网关请求结束后非阻塞入队，由后台协程上传到对象存储（复用备份的 S3 配置）并写入索引；
+// 读取端仅向管理员提供按记录 / 按使用记录 request_id 的下载。
+type UpstreamStreamCaptureService struct {
+	repo   UpstreamStreamCaptureRepository
+	backup *BackupService
+	cfg    *config.Config
+
+	queue chan *pendingUpstreamStreamCapture
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	droppedCount  uint64
+	uploadFailed  uint64
+	uploadedCount uint64
+}
+
+func NewUpstreamStreamCaptureService(repo UpstreamStreamCaptureRepository, backup *BackupService, cfg *config.Config) *UpstreamStreamCaptureService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &UpstreamStreamCaptureService{
+		repo:   repo,
+		backup: backup,
+		cfg:    cfg,
+		queue:  make(chan *pendingUpstreamStreamCapture, upstreamStreamCaptureQueueCapacity),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start 启动异步上传与保留期清理协程。
+func (s *UpstreamStreamCaptureService) Start() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	s.wg.Add(2)
+	go s.runUploader()
+	go s.runRetentionLoop()
+}
+
+// Stop 停止服务并尽量上传队列中剩余的留存。
+func (s *UpstreamStreamCaptureService) Stop() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Enabled 全局开关是否开启。
+func (s *UpstreamStreamCaptureService) Enabled() bool {
+	if s == nil || s.repo == nil || s.backup == nil || s.cfg == nil {
+		return false
+	}
+	return s.cfg.Gateway.UpstreamStreamCapture.Enabled
+}
+
+// Begin 为该 API Key 的请求创建留存缓冲；不可能命中留存条件时返回 nil，请求零开销。
+func (s *UpstreamStreamCaptureService) Begin(apiKey *APIKey) *UpstreamStreamCapture {
+	if apiKey == nil || !s.Enabled() {
+		return nil
+	}
+	if !apiKey.StreamCaptureEnabled && !s.cfg.Gateway.UpstreamStreamCapture.CaptureOnError {
+		return nil
+	}
+	return NewUpstreamStreamCapture(s.cfg.Gateway.UpstreamStreamCapture.MaxBytes)
+}
+
+// Submit 请求结束时判断是否留存：标记的 Key 全量留存，其余仅在失败时留存；命中后非阻塞入队。
+// record 由调用方填写请求元数据，Reason / 内容相关字段在此补全。
+func (s *UpstreamStreamCaptureService) Submit(capture *UpstreamStreamCapture, apiKey *APIKey, record *UpstreamStreamCaptureRecord) {
+	if capture == nil || apiKey == nil || record == nil || !s.Enabled() {
+		return
+	}
+	switch {
+	case apiKey.StreamCaptureEnabled:
+		record.Reason = UpstreamStreamCaptureReasonFlagged
+	case record.StatusCode >= http.StatusBadRequest || capture.Failed():
+		record.Reason = UpstreamStreamCaptureReasonError
+	default:
+		return
+	}
+	data, truncated, attempts := capture.Snapshot()
+	if attempts == 0 {
+		return // 未到达上游（如鉴权、限流拒绝），没有可留存的内容
+	}
+	record.UserID = apiKey.UserID
+	record.APIKeyID = apiKey.ID
+	record.Attempts = attempts
+	record.Truncated = truncated
+	record.SizeBytes = int64(len(data))
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now().UTC()
+	}
+	select {
+	case <-s.ctx.Done():
+		return
+	default:
+	}
+	select {
+	case s.queue <- &pendingUpstreamStreamCapture{record: record, data: data}:
+	default:
+		atomic.AddUint64(&s.droppedCount, 1)
+	}
+}
+
+// List 分页查询留存索引。
+func (s *UpstreamStreamCaptureService) List(ctx context.Context, filter *UpstreamStreamCaptureFilter) (*UpstreamStreamCaptureList, error) {
+	if filter == nil {
+		filter = &UpstreamStreamCaptureFilter{}
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Open 按留存 ID 打开内容，调用方负责关闭返回的 reader。
+func (s *UpstreamStreamCaptureService) Open(ctx context.Context, id int64) (*UpstreamStreamCaptureRecord, io.ReadCloser, error) {
+	record, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.open(ctx, record)
+}
+
+// OpenByRequestID 按 usage_logs.request_id 打开最近一次留存，用于从使用记录跳转核查。
+func (s *UpstreamStreamCaptureService) OpenByRequestID(ctx context.Context, requestID string) (*UpstreamStreamCaptureRecord, io.ReadCloser, error) {
+	requestID = strings.TrimSpace(requestID)
+	if requestID == "" {
+		return nil, nil, ErrUpstreamStreamCaptureNotFound
+	}
+	record, err := s.repo.GetLatestByRequestID(ctx, requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.open(ctx, record)
+}
+
+func (s *UpstreamStreamCaptureService) open(ctx context.Context, record *UpstreamStreamCaptureRecord) (*UpstreamStreamCaptureRecord, io.ReadCloser, error) {
+	store, err := s.store(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := store.Download(ctx, record.ObjectKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("download upstream stream capture: %w", err)
+	}
+	return record, body, nil
+}
+
+func (s *UpstreamStreamCaptureService) store(ctx context.Context) (BackupObjectStore, error) {
+	if s.backup == nil {
+		return nil, ErrBackupS3NotConfigured
+	}
+	cfg, err := s.backup.loadS3Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil || !cfg.IsConfigured() {
+		return nil, ErrBackupS3NotConfigured
+	}
+	return s.backup.getOrCreateStore(ctx, cfg)
+}
+
+func (s *UpstreamStreamCaptureService) objectKey(createdAt time.Time) string {
+	prefix := "stream-captures"
+	if s.cfg != nil {
+		if p := strings.Trim(strings.TrimSpace(s.cfg.Gateway.UpstreamStreamCapture.Prefix), "/"); p != "" {
+			prefix = p
+		}
+	}
+	return fmt.Sprintf("%s/%s/%s.sse", prefix, createdAt.UTC().Format("2006/01/02"), uuid.NewString())
+}
+
+func (s *UpstreamStreamCaptureService) upload(item *pendingUpstreamStreamCapture) {
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamStreamCaptureUploadTimeout)
+	defer cancel()
+
+	store, err := s.store(ctx)
+	if err != nil {
+		atomic.AddUint64(&s.uploadFailed, 1)
+		logger.LegacyPrintf("service.upstream_stream_capture", "[UpstreamStreamCapture] object store unavailable: request_id=%s err=%v", item.record.RequestID, err)
+		return
+	}
+	item.record.ObjectKey = s.objectKey(item.record.CreatedAt)
+	if _, err := store.Upload(ctx, item.record.ObjectKey, bytes.NewReader(item.data), "text/event-stream"); err != nil {
+		atomic.AddUint64(&s.uploadFailed, 1)
+		logger.LegacyPrintf("service.upstream_stream_capture", "[UpstreamStreamCapture] upload failed: request_id=%s err=%v", item.record.RequestID, err)
+		return
+	}
+	if err := s.repo.Insert(ctx, item.record); err != nil {
+		atomic.AddUint64(&s.uploadFailed, 1)
+		logger.LegacyPrintf("service.upstream_stream_capture", "[UpstreamStreamCapture] index insert failed: request_id=%s err=%v", item.record.RequestID, err)
+		_ = store.Delete(ctx, item.record.ObjectKey)
+		return
+	}
+	atomic.AddUint64(&s.uploadedCount, 1)
+}
+
+func (s *UpstreamStreamCaptureService) runUploader() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			// 停机前排空队列。
+			for {
+				select {
+				case item := <-s.queue:
+					s.upload(item)
+				default:
+					return
+				}
+			}
+		case item := <-s.queue:
+			s.upload(item)
+		}
+	}
+}
+
+// runRetentionLoop 按保留期定期删除过期留存（先删对象再删索引）。
+// 删除操作幂等，多实例并发执行无害，因此无需选主。
+func (s *UpstreamStreamCaptureService) runRetentionLoop() {
+	defer s.wg.Done()
+
+	startupTimer := time.NewTimer(upstreamStreamCaptureRetentionStartupDelay)
+	defer startupTimer.Stop()
+	select {
+	case <-s.ctx.Done():
+		return
+	case <-startupTimer.C:
+	}
+
+	ticker := time.NewTicker(upstreamStreamCaptureRetentionCheckInterval)
+	defer ticker.Stop()
+
+	s.runRetentionOnce(s.ctx)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.runRetentionOnce(s.ctx)
+		}
+	}
+}
+
+func (s *UpstreamStreamCaptureService) runRetentionOnce(parent context.Context) {
+	if s.cfg == nil {
+		return
+	}
+	days := s.cfg.Gateway.UpstreamStreamCapture.RetentionDays
+	if days <= 0 {
+		return // 0 表示永久保留
+	}
+	ctx, cancel := context.WithTimeout(parent, 10*time.Minute)
+	defer cancel()
+
+	store, err := s.store(ctx)
+	if err != nil {
+		if !errors.Is(err, ErrBackupS3NotConfigured) {
+			logger.LegacyPrintf("service.upstream_stream_capture", "[UpstreamStreamCapture] retention skipped: %v", err)
+		}
+		return
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+	for {
+		records, err := s.repo.ListBefore(ctx, cutoff, upstreamStreamCaptureRetentionBatchSize)
+		if err != nil {
+			logger.LegacyPrintf("service.upstream_stream_capture", "[UpstreamStreamCapture] retention list failed: %v", err)
+			return
+		}
+		if len(records) == 0 {
+			return
+		}
+		ids := make([]int64, 0, len(records))
+		for _, record := range records {
+			if err := store.Delete(ctx, record.ObjectKey); err != nil {
+				// 对象删除失败时保留索引，下个周期重试
+				logger.LegacyPrintf("service.upstream_stream_capture", "[UpstreamStreamCapture] retention delete object failed: key=%s err=%v", record.ObjectKey, err)
+				continue
+			}
+			ids = append(ids, record.ID)
+		}
+		if len(ids) == 0 {
+			return
+		}
+		if _, err := s.repo.DeleteByIDs(ctx, ids); err != nil {
+			logger.LegacyPrintf("service.upstream_stream_capture", "[UpstreamStreamCapture] retention delete index failed: %v", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,177 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+type upstreamStreamCaptureRepoStub struct {
+	UpstreamStreamCaptureRepository
+}
+
+type errAfterReader struct {
+	data string
+	err  error
+	done bool
+}
+
+func (r *errAfterReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, r.err
+	}
+	r.done = true
+	return copy(p, r.data), nil
+}
+
+func newCaptureTestResponse(status int, body io.Reader) (*http.Request, *http.Response) {
+	req, _ := http.NewRequest(http.MethodPost, "https://upstream.example/v1/messages?key=secret", nil)
+	return req, &http.Response{StatusCode: status, Body: io.NopCloser(body)}
+}
+
+func TestUpstreamStreamCapture_TeesBodyWithoutQuery(t *testing.T) {
+	capture := NewUpstreamStreamCapture(1024)
+	req, resp := newCaptureTestResponse(http.StatusOK, strings.NewReader("data: hello\n\n"))
+	capture.WrapResponse(req, resp)
+
+	got, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "data: hello\n\n", string(got))
+
+	data, truncated, attempts := capture.Snapshot()
+	require.False(t, truncated)
+	require.Equal(t, 1, attempts)
+	require.Contains(t, string(data), "### attempt 1 POST upstream.example/v1/messages status=200")
+	require.NotContains(t, string(data), "secret")
+	require.True(t, strings.HasSuffix(string(data), "data: hello\n\n"))
+	require.False(t, capture.Failed())
+}
+
+func TestUpstreamStreamCapture_TruncatesAtLimit(t *testing.T) {
+	capture := NewUpstreamStreamCapture(64)
+	req, resp := newCaptureTestResponse(http.StatusOK, strings.NewReader(strings.Repeat("x", 200)))
+	capture.WrapResponse(req, resp)
+
+	got, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Len(t, got, 200, "downstream must still see the full body")
+
+	data, truncated, _ := capture.Snapshot()
+	require.True(t, truncated)
+	require.Len(t, data, 64)
+}
+
+func TestUpstreamStreamCapture_MarksFailure(t *testing.T) {
+	capture := NewUpstreamStreamCapture(1024)
+	req, resp := newCaptureTestResponse(http.StatusTooManyRequests, strings.NewReader(`{"error":"rate"}`))
+	capture.WrapResponse(req, resp)
+	require.True(t, capture.Failed())
+
+	capture = NewUpstreamStreamCapture(1024)
+	req, resp = newCaptureTestResponse(http.StatusOK, &errAfterReader{data: "data: partial", err: errors.New("stream reset")})
+	capture.WrapResponse(req, resp)
+	_, err := io.ReadAll(resp.Body)
+	require.Error(t, err)
+	require.True(t, capture.Failed())
+	data, _, _ := capture.Snapshot()
+	require.Contains(t, string(data), "### read error: stream reset")
+}
+
+func TestUpstreamStreamCapture_NilSafe(t *testing.T) {
+	var capture *UpstreamStreamCapture
+	req, resp := newCaptureTestResponse(http.StatusOK, strings.NewReader("ok"))
+	capture.WrapResponse(req, resp)
+	require.False(t, capture.Failed())
+	require.Nil(t, UpstreamStreamCaptureFromContext(context.Background()))
+}
+
+func newUpstreamStreamCaptureTestService(captureOnError bool) *UpstreamStreamCaptureService {
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamStreamCapture = config.GatewayUpstreamStreamCaptureConfig{
+		Enabled:        true,
+		CaptureOnError: captureOnError,
+		MaxBytes:       1024,
+	}
+	return NewUpstreamStreamCaptureService(&upstreamStreamCaptureRepoStub{}, &BackupService{}, cfg)
+}
+
+func TestUpstreamStreamCaptureService_BeginAndSubmit(t *testing.T) {
+	svc := newUpstreamStreamCaptureTestService(false)
+	require.Nil(t, svc.Begin(&APIKey{ID: 1}), "unflagged key without capture_on_error has nothing to capture")
+
+	flagged := &APIKey{ID: 2, UserID: 7, StreamCaptureEnabled: true}
+	capture := svc.Begin(flagged)
+	require.NotNil(t, capture)
+
+	// 未到达上游时不留存
+	svc.Submit(capture, flagged, &UpstreamStreamCaptureRecord{StatusCode: http.StatusOK})
+	require.Len(t, svc.queue, 0)
+
+	req, resp := newCaptureTestResponse(http.StatusOK, strings.NewReader("data: ok\n\n"))
+	capture.WrapResponse(req, resp)
+	_, _ = io.ReadAll(resp.Body)
+	svc.Submit(capture, flagged, &UpstreamStreamCaptureRecord{RequestID: "client:abc", StatusCode: http.StatusOK})
+	require.Len(t, svc.queue, 1)
+	item := <-svc.queue
+	require.Equal(t, UpstreamStreamCaptureReasonFlagged, item.record.Reason)
+	require.Equal(t, int64(7), item.record.UserID)
+	require.Equal(t, int64(2), item.record.APIKeyID)
+	require.Equal(t, 1, item.record.Attempts)
+	require.Equal(t, int64(len(item.data)), item.record.SizeBytes)
+}
+
+func TestUpstreamStreamCaptureService_CaptureOnErrorOnly(t *testing.T) {
+	svc := newUpstreamStreamCaptureTestService(true)
+	apiKey := &APIKey{ID: 3}
+
+	capture := svc.Begin(apiKey)
+	require.NotNil(t, capture)
+	req, resp := newCaptureTestResponse(http.StatusOK, strings.NewReader("data: ok\n\n"))
+	capture.WrapResponse(req, resp)
+	_, _ = io.ReadAll(resp.Body)
+	svc.Submit(capture, apiKey, &UpstreamStreamCaptureRecord{StatusCode: http.StatusOK})
+	require.Len(t, svc.queue, 0, "successful requests from unflagged keys are not captured")
+
+	svc.Submit(capture, apiKey, &UpstreamStreamCaptureRecord{StatusCode: http.StatusBadGateway})
+	require.Len(t, svc.queue, 1)
+	item := <-svc.queue
+	require.Equal(t, UpstreamStreamCaptureReasonError, item.record.Reason)
+}
+
+func TestUpstreamStreamCaptureService_DisabledIsNoop(t *testing.T) {
+	svc := newUpstreamStreamCaptureTestService(true)
+	svc.cfg.Gateway.UpstreamStreamCapture.Enabled = false
+	require.False(t, svc.Enabled())
+	require.Nil(t, svc.Begin(&APIKey{ID: 1, StreamCaptureEnabled: true}))
+
+	var nilSvc *UpstreamStreamCaptureService
+	require.False(t, nilSvc.Enabled())
+}
+
+func TestUsageRequestIDFromContext(t *testing.T) {
+	require.Equal(t, "", UsageRequestIDFromContext(context.Background()))
+
+	ctx := context.WithValue(context.Background(), ctxkey.RequestID, "req-1")
+	require.Equal(t, "local:req-1", UsageRequestIDFromContext(ctx))
+
+	ctx = context.WithValue(ctx, ctxkey.ClientRequestID, " cli-1 ")
+	require.Equal(t, "client:cli-1", UsageRequestIDFromContext(ctx))
+}
+
+func TestUpstreamStreamCaptureService_ObjectKeyLayout(t *testing.T) {
+	svc := newUpstreamStreamCaptureTestService(false)
+	svc.cfg.Gateway.UpstreamStreamCapture.Prefix = "/captures/"
+	key := svc.objectKey(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC))
+	require.True(t, strings.HasPrefix(key, "captures/2026/03/04/"), key)
+	require.True(t, strings.HasSuffix(key, ".sse"), key)
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"golang.org/x/sync/singleflight"
+)
+
+// 上游请求类型，决定按哪一档超时等待上游响应头。
+const (
+	UpstreamRequestKindStreaming    = "streaming"
+	UpstreamRequestKindNonStreaming = "non_streaming"
+	// UpstreamRequestKindUtility count_tokens 等非生成类调用
+	UpstreamRequestKindUtility = "utility"
+)
+
+// maxUpstreamTimeoutSeconds 单档超时上限（1 小时）
+const maxUpstreamTimeoutSeconds = 3600
+
+const (
+	upstreamTimeoutProfilesCacheTTL  = 60 * time.Second
+	upstreamTimeoutProfilesDBTimeout = 5 * time.Second
+)
+
+// UpstreamTimeoutProfile 单个平台的上游超时档位（秒），0 表示沿用连接池默认的响应头超时。
+// 超时计到收到上游响应头为止：流式请求即首包时间，非流式请求基本等于整次生成耗时；
+// 之后的流式传输不受此限制（由流数据间隔超时控制）。
+type UpstreamTimeoutProfile struct {
+	StreamingSeconds    int `json:"streaming_seconds"`
+	NonStreamingSeconds int `json:"non_streaming_seconds"`
+	UtilitySeconds      int `json:"utility_seconds"`
+}
+
+// UpstreamTimeoutProfileSettings 各平台的上游超时档位，键为平台（anthropic / openai / gemini / antigravity / grok）。
+type UpstreamTimeoutProfileSettings struct {
+	Profiles map[string]UpstreamTimeoutProfile `json:"profiles"`
+}
+
+// Timeout 返回平台在指定请求类型下的超时；未配置时返回 0。
+func (s *UpstreamTimeoutProfileSettings) Timeout(platform, kind string) time.Duration {
+	if s == nil {
+		return 0
+	}
+	profile, ok := s.Profiles[strings.ToLower(strings.TrimSpace(platform))]
+	if !ok {
+		return 0
+	}
+	var seconds int
+	switch kind {
+	case UpstreamRequestKindStreaming:
+		seconds = profile.StreamingSeconds
+	case UpstreamRequestKindNonStreaming:
+		seconds = profile.NonStreamingSeconds
+	default:
+		seconds = profile.UtilitySeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func validateUpstreamTimeoutProfileSettings(settings *UpstreamTimeoutProfileSettings) error {
+	for platform, profile := range settings.Profiles {
+		if !isKnownUpstreamTimeoutPlatform(platform) {
+			return fmt.Errorf("unknown platform %q", platform)
+		}
+		for name, seconds := range map[string]int{
+			"streaming_seconds":     profile.StreamingSeconds,
+			"non_streaming_seconds": profile.NonStreamingSeconds,
+			"utility_seconds":       profile.UtilitySeconds,
+		} {
+			if seconds < 0 || seconds > maxUpstreamTimeoutSeconds {
+				return fmt.Errorf("%s.%s must be between 0-%d", platform, name, maxUpstreamTimeoutSeconds)
+			}
+		}
+	}
+	return nil
+}
+
+func isKnownUpstreamTimeoutPlatform(platform string) bool {
+	switch platform {
+	case PlatformAnthropic, PlatformOpenAI, PlatformGemini, PlatformAntigravity, PlatformGrok:
+		return true
+	default:
+		return false
+	}
+}
+
+type upstreamRequestKindContextKey struct{}
+
+// WithUpstreamRequestKind 在 context 中记录本次网关请求的上游请求类型。
+func WithUpstreamRequestKind(ctx context.Context, kind string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, upstreamRequestKindContextKey{}, kind)
+}
+
+// UpstreamRequestKindFromContext 读取上游请求类型，未标记时返回空。
+func UpstreamRequestKindFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	kind, _ := ctx.Value(upstreamRequestKindContextKey{}).(string)
+	return kind
+}
+
+type cachedUpstreamTimeoutProfiles struct {
+	settings  *UpstreamTimeoutProfileSettings
+	expiresAt int64 // unix nano
+}
+
+var upstreamTimeoutProfilesCache atomic.Value // *cachedUpstreamTimeoutProfiles
+var upstreamTimeoutProfilesSF singleflight.Group
+
+func storeUpstreamTimeoutProfilesCache(settings *UpstreamTimeoutProfileSettings) {
+	upstreamTimeoutProfilesSF.Forget(SettingKeyUpstreamTimeoutProfiles)
+	upstreamTimeoutProfilesCache.Store(&cachedUpstreamTimeoutProfiles{
+		settings:  settings,
+		expiresAt: time.Now().Add(upstreamTimeoutProfilesCacheTTL).UnixNano(),
+	})
+}
+
+// GetUpstreamTimeoutProfileSettings 获取各平台上游超时档位
+func (s *SettingService) GetUpstreamTimeoutProfileSettings(ctx context.Context) (*UpstreamTimeoutProfileSettings, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyUpstreamTimeoutProfiles)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return &UpstreamTimeoutProfileSettings{Profiles: map[string]UpstreamTimeoutProfile{}}, nil
+		}
+		return nil, fmt.Errorf("get upstream timeout profiles: %w", err)
+	}
+	return parseUpstreamTimeoutProfileSettings(value), nil
+}
+
+// SetUpstreamTimeoutProfileSettings 设置各平台上游超时档位，保存后立即对新请求生效
+func (s *SettingService) SetUpstreamTimeoutProfileSettings(ctx context.Context, settings *UpstreamTimeoutProfileSettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+	normalized := make(map[string]UpstreamTimeoutProfile, len(settings.Profiles))
+	for platform, profile := range settings.Profiles {
+		normalized[strings.ToLower(strings.TrimSpace(platform))] = profile
+	}
+	settings.Profiles = normalized
+	if err := validateUpstreamTimeoutProfileSettings(settings); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal upstream timeout profiles: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyUpstreamTimeoutProfiles, string(data)); err != nil {
+		return err
+	}
+	storeUpstreamTimeoutProfilesCache(settings)
+	return nil
+}
+
+// UpstreamResponseTimeout 返回当前上游请求应使用的响应头超时；
+// 平台取自选定账号时写入 context 的 ctxkey.Platform，未命中任何档位时返回 0（沿用连接池默认值）。
+func (s *SettingService) UpstreamResponseTimeout(ctx context.Context) time.Duration {
+	if s == nil || ctx == nil {
+		return 0
+	}
+	platform, _ := ctx.Value(ctxkey.Platform).(string)
+	if platform == "" {
+		return 0
+	}
+	return s.loadUpstreamTimeoutProfiles(ctx).Timeout(platform, UpstreamRequestKindFromContext(ctx))
+}
+
+func parseUpstreamTimeoutProfileSettings(raw string) *UpstreamTimeoutProfileSettings {
+	settings := &UpstreamTimeoutProfileSettings{}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), settings); err != nil {
+			slog.Warn("failed to parse upstream timeout profiles", "error", err)
+			settings = &UpstreamTimeoutProfileSettings{}
+		}
+	}
+	if settings.Profiles == nil {
+		settings.Profiles = map[string]UpstreamTimeoutProfile{}
+	}
+	return settings
+}
+
+// loadUpstreamTimeoutProfiles 读取超时档位（进程内缓存 60s），读取失败按未配置处理。
+func (s *SettingService) loadUpstreamTimeoutProfiles(ctx context.Context) *UpstreamTimeoutProfileSettings {
+	if cached, ok := upstreamTimeoutProfilesCache.Load().(*cachedUpstreamTimeoutProfiles); ok && cached != nil {
+		if time.Now().UnixNano() < cached.expiresAt {
+			return cached.settings
+		}
+	}
+	val, _, _ := upstreamTimeoutProfilesSF.Do(SettingKeyUpstreamTimeoutProfiles, func() (any, error) {
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), upstreamTimeoutProfilesDBTimeout)
+		defer cancel()
+		raw, err := s.settingRepo.GetValue(dbCtx, SettingKeyUpstreamTimeoutProfiles)
+		if err != nil && !errors.Is(err, ErrSettingNotFound) {
+			slog.Warn("failed to get upstream timeout profiles", "error", err)
+		}
+		settings := parseUpstreamTimeoutProfileSettings(raw)
+		upstreamTimeoutProfilesCache.Store(&cachedUpstreamTimeoutProfiles{
+			settings:  settings,
+			expiresAt: time.Now().Add(upstreamTimeoutProfilesCacheTTL).UnixNano(),
+		})
+		return settings, nil
+	})
+	if settings, ok := val.(*UpstreamTimeoutProfileSettings); ok && settings != nil {
+		return settings
+	}
+	return &UpstreamTimeoutProfileSettings{}
+}
@@ -0,0 +1,59 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/singleflight"
+)
+
+func resetUpstreamTimeoutProfilesCacheForTest() {
+	upstreamTimeoutProfilesCache = atomic.Value{}
+	upstreamTimeoutProfilesSF = singleflight.Group{}
+}
+
+func TestUpstreamTimeoutProfileSettings_Timeout(t *testing.T) {
+	settings := &UpstreamTimeoutProfileSettings{Profiles: map[string]UpstreamTimeoutProfile{
+		PlatformGemini: {StreamingSeconds: 30, NonStreamingSeconds: 120, UtilitySeconds: 10},
+	}}
+
+	require.Equal(t, 30*time.Second, settings.Timeout(PlatformGemini, UpstreamRequestKindStreaming))
+	require.Equal(t, 120*time.Second, settings.Timeout(PlatformGemini, UpstreamRequestKindNonStreaming))
+	require.Equal(t, 10*time.Second, settings.Timeout(PlatformGemini, UpstreamRequestKindUtility))
+	require.Equal(t, 10*time.Second, settings.Timeout(PlatformGemini, ""), "unmarked requests use the utility profile")
+	require.Zero(t, settings.Timeout(PlatformAnthropic, UpstreamRequestKindStreaming))
+}
+
+func TestSetUpstreamTimeoutProfileSettings_ValidatesAndAppliesImmediately(t *testing.T) {
+	resetUpstreamTimeoutProfilesCacheForTest()
+	t.Cleanup(resetUpstreamTimeoutProfilesCacheForTest)
+	svc := NewSettingService(&canarySettingRepoStub{values: map[string]string{}}, &config.Config{})
+	ctx := context.Background()
+
+	require.Error(t, svc.SetUpstreamTimeoutProfileSettings(ctx, &UpstreamTimeoutProfileSettings{
+		Profiles: map[string]UpstreamTimeoutProfile{"unknown": {StreamingSeconds: 10}},
+	}))
+	require.Error(t, svc.SetUpstreamTimeoutProfileSettings(ctx, &UpstreamTimeoutProfileSettings{
+		Profiles: map[string]UpstreamTimeoutProfile{PlatformOpenAI: {NonStreamingSeconds: -1}},
+	}))
+
+	reqCtx := WithUpstreamRequestKind(context.WithValue(ctx, ctxkey.Platform, PlatformAnthropic), UpstreamRequestKindNonStreaming)
+	require.Zero(t, svc.UpstreamResponseTimeout(reqCtx))
+
+	require.NoError(t, svc.SetUpstreamTimeoutProfileSettings(ctx, &UpstreamTimeoutProfileSettings{
+		Profiles: map[string]UpstreamTimeoutProfile{" Anthropic ": {NonStreamingSeconds: 60}},
+	}))
+	require.Equal(t, 60*time.Second, svc.UpstreamResponseTimeout(reqCtx))
+	require.Zero(t, svc.UpstreamResponseTimeout(ctx), "requests without a selected platform are not limited")
+
+	stored, err := svc.GetUpstreamTimeoutProfileSettings(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 60, stored.Profiles[PlatformAnthropic].NonStreamingSeconds)
+}
@@ -0,0 +1,492 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	usageArchiveLeaderLockKey = "usage:archive:leader"
+	usageArchiveLeaderLockTTL = 2 * time.Hour
+
+	usageArchiveCheckInterval = 6 * time.Hour
+	usageArchiveStartupDelay  = 10 * time.Minute
+	usageArchiveRunTimeout    = 90 * time.Minute
+
+	usageArchiveRetentionBatchSize = 100
+	usageArchiveRestoreBatchSize   = 1000
+	usageArchiveMaxLineBytes       = 16 << 20
+)
+
+var (
+	ErrUsageLogArchiveNotFound = infraerrors.NotFound("USAGE_LOG_ARCHIVE_NOT_FOUND", "usage log archive not found")
+	ErrUsageLogArchiveNotReady = infraerrors.ServiceUnavailable("USAGE_LOG_ARCHIVE_UNAVAILABLE", "usage archive storage is not configured")
+)
+
+// UsageLogArchive 一个归档对象的索引：同一自然月（UTC）内的一批使用记录及其对账汇总。
+type UsageLogArchive struct {
+	ID           int64      `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Month        time.Time  `json:"month"`
+	ObjectKey    string     `json:"-"`
+	RowCount     int        `json:"row_count"`
+	SizeBytes    int64      `json:"size_bytes"`
+	FirstLogID   int64      `json:"first_log_id"`
+	LastLogID    int64      `json:"last_log_id"`
+	RangeStart   time.Time  `json:"range_start"`
+	RangeEnd     time.Time  `json:"range_end"`
+	InputTokens  int64      `json:"input_tokens"`
+	OutputTokens int64      `json:"output_tokens"`
+	TotalCost    float64    `json:"total_cost"`
+	ActualCost   float64    `json:"actual_cost"`
+	RestoredAt   *time.Time `json:"restored_at,omitempty"`
+}
+
+// UsageLogArchiveRow 一条待归档的使用记录，Data 为 usage_logs 整行的 JSON（列名为键）。
+type UsageLogArchiveRow struct {
+	ID        int64
+	CreatedAt time.Time
+	Data      json.RawMessage
+}
+
+// UsageLogArchiveFilter 归档列表查询条件。
+type UsageLogArchiveFilter struct {
+	Page     int
+	PageSize int
+
+	// Month 非零时只返回该自然月的归档
+	Month time.Time
+}
+
+// UsageLogArchiveList 归档分页结果。
+type UsageLogArchiveList struct {
+	Items    []*UsageLogArchive
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// UsageLogArchiveRepository 使用记录归档的数据访问。
+type UsageLogArchiveRepository interface {
+	// OldestUsageLogBefore 返回 cutoff 之前最早一条使用记录的创建时间；没有时 ok 为 false。
+	OldestUsageLogBefore(ctx context.Context, cutoff time.Time) (oldest time.Time, ok bool, err error)
+	// ListUsageLogsForArchive 返回 [start, end) 内按 (created_at, id) 排序的最多 limit 条使用记录。
+	ListUsageLogsForArchive(ctx context.Context, start, end time.Time, limit int) ([]UsageLogArchiveRow, error)
+	// CommitArchive 在同一事务内写入归档索引并删除已归档的原始记录。
+	CommitArchive(ctx context.Context, archive *UsageLogArchive, logIDs []int64) error
+
+	GetByID(ctx context.Context, id int64) (*UsageLogArchive, error)
+	List(ctx context.Context, filter *UsageLogArchiveFilter) (*UsageLogArchiveList, error)
+	// ListEndedBefore 返回记录时间范围在 cutoff 之前结束的最多 limit 个归档，供保留期清理先删对象再删索引。
+	ListEndedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*UsageLogArchive, error)
+	DeleteByIDs(ctx context.Context, ids []int64) (int64, error)
+
+	// InsertRestoredRows 将归档记录写入审计恢复区（重复写入幂等），返回新写入的行数。
+	InsertRestoredRows(ctx context.Context, archiveID int64, rows []json.RawMessage) (int64, error)
+	// ListRestoredRows 分页读取审计恢复区中某个归档的记录。
+	ListRestoredRows(ctx context.Context, archiveID int64, page, pageSize int) ([]json.RawMessage, int, error)
+	// DeleteRestoredRows 清空某个归档在审计恢复区的记录。
+	DeleteRestoredRows(ctx context.Context, archiveID int64) (int64, error)
+	SetRestoredAt(ctx context.Context, archiveID int64, restoredAt *time.Time) error
+}
+
+// UsageArchiveService 使用记录分层归档：原始记录保留 archive_after_days 天，之后按自然月导出为
+// gzip 压缩的 JSONL 写入对象存储（复用备份的 S3 配置）并删除原始行；归档对象按 archive_retention_days 清理。
+// 审计时可把归档恢复到 usage_log_archive_rows（JSONB），不回写 usage_logs，避免被保留期清理再次删除。
+type UsageArchiveService struct {
+	repo   UsageLogArchiveRepository
+	backup *BackupService
+	cfg    *config.Config
+
+	// storeFn 测试可替换；默认从备份配置获取对象存储
+	storeFn func(ctx context.Context) (BackupObjectStore, error)
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+func NewUsageArchiveService(repo UsageLogArchiveRepository, backup *BackupService, cfg *config.Config) *UsageArchiveService {
+	s := &UsageArchiveService{
+		repo:       repo,
+		backup:     backup,
+		cfg:        cfg,
+		stopCh:     make(chan struct{}),
+		instanceID: uuid.NewString(),
+		now:        time.Now,
+	}
+	s.storeFn = s.backupStore
+	return s
+}
+
+// SetLeaderLock 注入 leader 锁，多实例部署时每轮只有一个实例执行归档。
+func (s *UsageArchiveService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Enabled 全局开关是否开启。
+func (s *UsageArchiveService) Enabled() bool {
+	return s != nil && s.repo != nil && s.cfg != nil && s.cfg.UsageArchive.Enabled
+}
+
+// Start 启动定时归档协程。
+func (s *UsageArchiveService) Start() {
+	if !s.Enabled() {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		startupTimer := time.NewTimer(usageArchiveStartupDelay)
+		defer startupTimer.Stop()
+		select {
+		case <-s.stopCh:
+			return
+		case <-startupTimer.C:
+		}
+
+		ticker := time.NewTicker(usageArchiveCheckInterval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *UsageArchiveService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *UsageArchiveService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), usageArchiveRunTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, usageArchiveLeaderLockKey, s.instanceID, usageArchiveLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	archived, err := s.ArchiveOnce(ctx)
+	if err != nil {
+		if errors.Is(err, ErrUsageLogArchiveNotReady) {
+			logger.LegacyPrintf("service.usage_archive", "[UsageArchive] skipped: backup S3 storage is not configured")
+			return
+		}
+		logger.LegacyPrintf("service.usage_archive", "[UsageArchive] archive failed after %d batches: %v", archived, err)
+	} else if archived > 0 {
+		logger.LegacyPrintf("service.usage_archive", "[UsageArchive] archived %d batches", archived)
+	}
+	if err := s.CleanupExpiredArchives(ctx); err != nil && !errors.Is(err, ErrUsageLogArchiveNotReady) {
+		logger.LegacyPrintf("service.usage_archive", "[UsageArchive] retention cleanup failed: %v", err)
+	}
+}
+
+// ArchiveOnce 归档早于 archive_after_days 的原始记录，最多写入 max_batches_per_run 个对象，返回写入的对象数。
+// 每个对象只包含同一自然月的记录；对象上传成功后才在事务内写索引并删除原始行，提交失败时删除已上传的对象。
+func (s *UsageArchiveService) ArchiveOnce(ctx context.Context) (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+	store, err := s.storeFn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cfg := s.cfg.UsageArchive
+	cutoff := s.now().UTC().AddDate(0, 0, -cfg.ArchiveAfterDays)
+
+	archived := 0
+	for archived < cfg.MaxBatchesPerRun {
+		if err := ctx.Err(); err != nil {
+			return archived, err
+		}
+		oldest, ok, err := s.repo.OldestUsageLogBefore(ctx, cutoff)
+		if err != nil {
+			return archived, fmt.Errorf("find oldest usage log: %w", err)
+		}
+		if !ok {
+			return archived, nil
+		}
+		month := time.Date(oldest.UTC().Year(), oldest.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+		end := month.AddDate(0, 1, 0)
+		if end.After(cutoff) {
+			end = cutoff
+		}
+		rows, err := s.repo.ListUsageLogsForArchive(ctx, month, end, cfg.BatchSize)
+		if err != nil {
+			return archived, fmt.Errorf("list usage logs for archive: %w", err)
+		}
+		if len(rows) == 0 {
+			return archived, nil
+		}
+
+		archive, data, err := buildUsageLogArchive(month, rows)
+		if err != nil {
+			return archived, err
+		}
+		archive.ObjectKey = s.objectKey(archive)
+		size, err := store.Upload(ctx, archive.ObjectKey, bytes.NewReader(data), "application/gzip")
+		if err != nil {
+			return archived, fmt.Errorf("upload usage archive: %w", err)
+		}
+		archive.SizeBytes = size
+		ids := make([]int64, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+		}
+		if err := s.repo.CommitArchive(ctx, archive, ids); err != nil {
+			_ = store.Delete(context.WithoutCancel(ctx), archive.ObjectKey)
+			return archived, fmt.Errorf("commit usage archive: %w", err)
+		}
+		archived++
+	}
+	return archived, nil
+}
+
+// CleanupExpiredArchives 删除记录创建超过 archive_retention_days 天的归档（先删对象再删索引）。
+func (s *UsageArchiveService) CleanupExpiredArchives(ctx context.Context) error {
+	if !s.Enabled() || s.cfg.UsageArchive.ArchiveRetentionDays <= 0 {
+		return nil
+	}
+	store, err := s.storeFn(ctx)
+	if err != nil {
+		return err
+	}
+	cutoff := s.now().UTC().AddDate(0, 0, -s.cfg.UsageArchive.ArchiveRetentionDays)
+	for {
+		archives, err := s.repo.ListEndedBefore(ctx, cutoff, usageArchiveRetentionBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(archives) == 0 {
+			return nil
+		}
+		ids := make([]int64, 0, len(archives))
+		for _, archive := range archives {
+			if err := store.Delete(ctx, archive.ObjectKey); err != nil {
+				// 对象删除失败时保留索引，下个周期重试
+				logger.LegacyPrintf("service.usage_archive", "[UsageArchive] retention delete object failed: key=%s err=%v", archive.ObjectKey, err)
+				continue
+			}
+			ids = append(ids, archive.ID)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if _, err := s.repo.DeleteByIDs(ctx, ids); err != nil {
+			return err
+		}
+	}
+}
+
+// List 分页查询归档索引。
+func (s *UsageArchiveService) List(ctx context.Context, filter *UsageLogArchiveFilter) (*UsageLogArchiveList, error) {
+	if filter == nil {
+		filter = &UsageLogArchiveFilter{}
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Open 打开归档对象（gzip 压缩的 JSONL），调用方负责关闭返回的 reader。
+func (s *UsageArchiveService) Open(ctx context.Context, id int64) (*UsageLogArchive, io.ReadCloser, error) {
+	archive, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := s.storeFn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := store.Download(ctx, archive.ObjectKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("download usage archive: %w", err)
+	}
+	return archive, body, nil
+}
+
+// Restore 将归档中的记录恢复到审计恢复区，返回恢复后的归档索引与新写入的行数；重复恢复幂等。
+func (s *UsageArchiveService) Restore(ctx context.Context, id int64) (*UsageLogArchive, int64, error) {
+	archive, body, err := s.Open(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = body.Close() }()
+
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("open usage archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	var restored int64
+	flush := func(batch []json.RawMessage) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := s.repo.InsertRestoredRows(ctx, archive.ID, batch)
+		restored += n
+		return err
+	}
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), usageArchiveMaxLineBytes)
+	batch := make([]json.RawMessage, 0, usageArchiveRestoreBatchSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		batch = append(batch, json.RawMessage(bytes.Clone(line)))
+		if len(batch) == usageArchiveRestoreBatchSize {
+			if err := flush(batch); err != nil {
+				return nil, restored, fmt.Errorf("restore usage archive rows: %w", err)
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, restored, fmt.Errorf("read usage archive: %w", err)
+	}
+	if err := flush(batch); err != nil {
+		return nil, restored, fmt.Errorf("restore usage archive rows: %w", err)
+	}
+	now := s.now().UTC()
+	if err := s.repo.SetRestoredAt(ctx, archive.ID, &now); err != nil {
+		return nil, restored, err
+	}
+	archive.RestoredAt = &now
+	return archive, restored, nil
+}
+
+// ListRestoredRows 分页读取已恢复的归档记录。
+func (s *UsageArchiveService) ListRestoredRows(ctx context.Context, id int64, page, pageSize int) ([]json.RawMessage, int, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListRestoredRows(ctx, id, page, pageSize)
+}
+
+// ReleaseRestore 审计结束后清空归档在恢复区的记录，返回删除的行数。
+func (s *UsageArchiveService) ReleaseRestore(ctx context.Context, id int64) (int64, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return 0, err
+	}
+	deleted, err := s.repo.DeleteRestoredRows(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.repo.SetRestoredAt(ctx, id, nil); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+func (s *UsageArchiveService) backupStore(ctx context.Context) (BackupObjectStore, error) {
+	if s.backup == nil {
+		return nil, ErrUsageLogArchiveNotReady
+	}
+	cfg, err := s.backup.loadS3Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil || !cfg.IsConfigured() {
+		return nil, ErrUsageLogArchiveNotReady
+	}
+	return s.backup.getOrCreateStore(ctx, cfg)
+}
+
+func (s *UsageArchiveService) objectKey(archive *UsageLogArchive) string {
+	prefix := "usage-archives"
+	if p := strings.Trim(strings.TrimSpace(s.cfg.UsageArchive.Prefix), "/"); p != "" {
+		prefix = p
+	}
+	return fmt.Sprintf("%s/%s/%d-%d-%s.jsonl.gz", prefix, archive.Month.Format("2006-01"), archive.FirstLogID, archive.LastLogID, uuid.NewString()[:8])
+}
+
+// buildUsageLogArchive 把一批记录编码为 gzip 压缩的 JSONL，并统计对账汇总（token 与费用）。
+func buildUsageLogArchive(month time.Time, rows []UsageLogArchiveRow) (*UsageLogArchive, []byte, error) {
+	archive := &UsageLogArchive{
+		Month:      month,
+		RowCount:   len(rows),
+		FirstLogID: rows[0].ID,
+		LastLogID:  rows[0].ID,
+		RangeStart: rows[0].CreatedAt,
+		RangeEnd:   rows[0].CreatedAt,
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, row := range rows {
+		if _, err := gz.Write(row.Data); err != nil {
+			return nil, nil, fmt.Errorf("compress usage archive: %w", err)
+		}
+		if _, err := gz.Write([]byte{'\n'}); err != nil {
+			return nil, nil, fmt.Errorf("compress usage archive: %w", err)
+		}
+		if row.ID < archive.FirstLogID {
+			archive.FirstLogID = row.ID
+		}
+		if row.ID > archive.LastLogID {
+			archive.LastLogID = row.ID
+		}
+		if row.CreatedAt.Before(archive.RangeStart) {
+			archive.RangeStart = row.CreatedAt
+		}
+		if row.CreatedAt.After(archive.RangeEnd) {
+			archive.RangeEnd = row.CreatedAt
+		}
+		fields := gjson.GetManyBytes(row.Data, "input_tokens", "output_tokens", "total_cost", "actual_cost")
+		archive.InputTokens += fields[0].Int()
+		archive.OutputTokens += fields[1].Int()
+		archive.TotalCost += fields[2].Float()
+		archive.ActualCost += fields[3].Float()
+	}
+	if err := gz.Close(); err != nil {
+		return nil, nil, fmt.Errorf("compress usage archive: %w", err)
+	}
+	return archive, buf.Bytes(), nil
+}
@@ -0,0 +1,286 @@
+//go:build unit
+
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// usageArchiveRepoStub 在内存中模拟 usage_logs、归档索引与审计恢复区。
+type usageArchiveRepoStub struct {
+	logs      map[int64]UsageLogArchiveRow
+	archives  map[int64]*UsageLogArchive
+	restored  map[int64]map[int64]json.RawMessage
+	nextID    int64
+	commitErr error
+}
+
+func newUsageArchiveRepoStub() *usageArchiveRepoStub {
+	return &usageArchiveRepoStub{
+		logs:     map[int64]UsageLogArchiveRow{},
+		archives: map[int64]*UsageLogArchive{},
+		restored: map[int64]map[int64]json.RawMessage{},
+	}
+}
+
+func (r *usageArchiveRepoStub) addLog(id int64, createdAt time.Time, inputTokens int, cost float64) {
+	data := fmt.Sprintf(`{"id":%d,"created_at":%q,"input_tokens":%d,"output_tokens":1,"total_cost":%g,"actual_cost":%g}`,
+		id, createdAt.Format(time.RFC3339Nano), inputTokens, cost, cost)
+	r.logs[id] = UsageLogArchiveRow{ID: id, CreatedAt: createdAt, Data: json.RawMessage(data)}
+}
+
+func (r *usageArchiveRepoStub) sortedLogs() []UsageLogArchiveRow {
+	out := make([]UsageLogArchiveRow, 0, len(r.logs))
+	for _, row := range r.logs {
+		out = append(out, row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func (r *usageArchiveRepoStub) OldestUsageLogBefore(_ context.Context, cutoff time.Time) (time.Time, bool, error) {
+	for _, row := range r.sortedLogs() {
+		if row.CreatedAt.Before(cutoff) {
+			return row.CreatedAt, true, nil
+		}
+		break
+	}
+	return time.Time{}, false, nil
+}
+
+func (r *usageArchiveRepoStub) ListUsageLogsForArchive(_ context.Context, start, end time.Time, limit int) ([]UsageLogArchiveRow, error) {
+	var out []UsageLogArchiveRow
+	for _, row := range r.sortedLogs() {
+		if !row.CreatedAt.Before(start) && row.CreatedAt.Before(end) && len(out) < limit {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (r *usageArchiveRepoStub) CommitArchive(_ context.Context, archive *UsageLogArchive, logIDs []int64) error {
+	if r.commitErr != nil {
+		return r.commitErr
+	}
+	r.nextID++
+	archive.ID = r.nextID
+	copied := *archive
+	r.archives[archive.ID] = &copied
+	for _, id := range logIDs {
+		delete(r.logs, id)
+	}
+	return nil
+}
+
+func (r *usageArchiveRepoStub) GetByID(_ context.Context, id int64) (*UsageLogArchive, error) {
+	archive, ok := r.archives[id]
+	if !ok {
+		return nil, ErrUsageLogArchiveNotFound
+	}
+	copied := *archive
+	return &copied, nil
+}
+
+func (r *usageArchiveRepoStub) List(context.Context, *UsageLogArchiveFilter) (*UsageLogArchiveList, error) {
+	return &UsageLogArchiveList{}, nil
+}
+
+func (r *usageArchiveRepoStub) ListEndedBefore(_ context.Context, cutoff time.Time, limit int) ([]*UsageLogArchive, error) {
+	var out []*UsageLogArchive
+	for _, archive := range r.archives {
+		if archive.RangeEnd.Before(cutoff) && len(out) < limit {
+			out = append(out, archive)
+		}
+	}
+	return out, nil
+}
+
+func (r *usageArchiveRepoStub) DeleteByIDs(_ context.Context, ids []int64) (int64, error) {
+	for _, id := range ids {
+		delete(r.archives, id)
+	}
+	return int64(len(ids)), nil
+}
+
+func (r *usageArchiveRepoStub) InsertRestoredRows(_ context.Context, archiveID int64, rows []json.RawMessage) (int64, error) {
+	if r.restored[archiveID] == nil {
+		r.restored[archiveID] = map[int64]json.RawMessage{}
+	}
+	var inserted int64
+	for _, row := range rows {
+		id := gjson.GetBytes(row, "id").Int()
+		if _, ok := r.restored[archiveID][id]; !ok {
+			r.restored[archiveID][id] = row
+			inserted++
+		}
+	}
+	return inserted, nil
+}
+
+func (r *usageArchiveRepoStub) ListRestoredRows(_ context.Context, archiveID int64, _, _ int) ([]json.RawMessage, int, error) {
+	var out []json.RawMessage
+	for _, row := range r.restored[archiveID] {
+		out = append(out, row)
+	}
+	return out, len(out), nil
+}
+
+func (r *usageArchiveRepoStub) DeleteRestoredRows(_ context.Context, archiveID int64) (int64, error) {
+	n := int64(len(r.restored[archiveID]))
+	delete(r.restored, archiveID)
+	return n, nil
+}
+
+func (r *usageArchiveRepoStub) SetRestoredAt(_ context.Context, archiveID int64, restoredAt *time.Time) error {
+	if archive, ok := r.archives[archiveID]; ok {
+		archive.RestoredAt = restoredAt
+	}
+	return nil
+}
+
+func newUsageArchiveTestService(repo *usageArchiveRepoStub, store *mockObjectStore, now time.Time) *UsageArchiveService {
+	cfg := &config.Config{UsageArchive: config.UsageArchiveConfig{
+		Enabled:              true,
+		ArchiveAfterDays:     30,
+		ArchiveRetentionDays: 365,
+		BatchSize:            2,
+		MaxBatchesPerRun:     10,
+		Prefix:               "archives",
+	}}
+	svc := NewUsageArchiveService(repo, nil, cfg)
+	svc.storeFn = func(context.Context) (BackupObjectStore, error) { return store, nil }
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func readUsageArchiveObject(t *testing.T, store *mockObjectStore, key string) []string {
+	t.Helper()
+	gz, err := gzip.NewReader(strings.NewReader(string(store.objects[key])))
+	require.NoError(t, err)
+	data, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestUsageArchiveService_ArchivesByMonthAndDeletesOriginals(t *testing.T) {
+	now := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	repo := newUsageArchiveRepoStub()
+	repo.addLog(1, time.Date(2026, 3, 30, 10, 0, 0, 0, time.UTC), 10, 0.5)
+	repo.addLog(2, time.Date(2026, 4, 2, 10, 0, 0, 0, time.UTC), 20, 1)
+	repo.addLog(3, time.Date(2026, 4, 3, 10, 0, 0, 0, time.UTC), 30, 1)
+	repo.addLog(4, time.Date(2026, 4, 4, 10, 0, 0, 0, time.UTC), 40, 1)
+	repo.addLog(5, time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC), 50, 1) // 未到归档期
+	store := newMockObjectStore()
+	svc := newUsageArchiveTestService(repo, store, now)
+
+	archived, err := svc.ArchiveOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, archived, "3 月 1 个对象，4 月按 batch_size=2 拆成 2 个对象")
+	require.Len(t, repo.logs, 1)
+	require.Contains(t, repo.logs, int64(5))
+
+	march := repo.archives[1]
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), march.Month)
+	require.Equal(t, 1, march.RowCount)
+	require.Equal(t, int64(10), march.InputTokens)
+	require.InDelta(t, 0.5, march.TotalCost, 1e-9)
+	require.True(t, strings.HasPrefix(march.ObjectKey, "archives/2026-03/1-1-"))
+	require.Equal(t, int64(len(store.objects[march.ObjectKey])), march.SizeBytes)
+
+	april := repo.archives[2]
+	require.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), april.Month)
+	require.Equal(t, int64(2), april.FirstLogID)
+	require.Equal(t, int64(3), april.LastLogID)
+	lines := readUsageArchiveObject(t, store, april.ObjectKey)
+	require.Len(t, lines, 2)
+	require.Equal(t, int64(2), gjson.Get(lines[0], "id").Int())
+}
+
+func TestUsageArchiveService_CommitFailureRemovesUploadedObject(t *testing.T) {
+	now := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	repo := newUsageArchiveRepoStub()
+	repo.addLog(1, time.Date(2026, 3, 30, 10, 0, 0, 0, time.UTC), 10, 0.5)
+	repo.commitErr = fmt.Errorf("tx failed")
+	store := newMockObjectStore()
+	svc := newUsageArchiveTestService(repo, store, now)
+
+	archived, err := svc.ArchiveOnce(context.Background())
+	require.Error(t, err)
+	require.Zero(t, archived)
+	require.Empty(t, store.objects)
+	require.Len(t, repo.logs, 1)
+}
+
+func TestUsageArchiveService_RestoreAndRelease(t *testing.T) {
+	now := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	repo := newUsageArchiveRepoStub()
+	repo.addLog(1, time.Date(2026, 3, 30, 10, 0, 0, 0, time.UTC), 10, 0.5)
+	repo.addLog(2, time.Date(2026, 3, 31, 10, 0, 0, 0, time.UTC), 20, 0.5)
+	store := newMockObjectStore()
+	svc := newUsageArchiveTestService(repo, store, now)
+	_, err := svc.ArchiveOnce(context.Background())
+	require.NoError(t, err)
+
+	archive, restored, err := svc.Restore(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), restored)
+	require.NotNil(t, archive.RestoredAt)
+
+	// 重复恢复幂等
+	_, restored, err = svc.Restore(context.Background(), 1)
+	require.NoError(t, err)
+	require.Zero(t, restored)
+
+	rows, total, err := svc.ListRestoredRows(context.Background(), 1, 1, 20)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Len(t, rows, 2)
+
+	deleted, err := svc.ReleaseRestore(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+	require.Nil(t, repo.archives[1].RestoredAt)
+
+	_, _, err = svc.Restore(context.Background(), 99)
+	require.ErrorIs(t, err, ErrUsageLogArchiveNotFound)
+}
+
+func TestUsageArchiveService_CleanupExpiredArchives(t *testing.T) {
+	now := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	repo := newUsageArchiveRepoStub()
+	store := newMockObjectStore()
+	store.objects["old"] = []byte("x")
+	store.objects["recent"] = []byte("y")
+	repo.archives[1] = &UsageLogArchive{ID: 1, ObjectKey: "old", RangeEnd: now.AddDate(-2, 0, 0)}
+	repo.archives[2] = &UsageLogArchive{ID: 2, ObjectKey: "recent", RangeEnd: now.AddDate(0, -3, 0)}
+	svc := newUsageArchiveTestService(repo, store, now)
+
+	require.NoError(t, svc.CleanupExpiredArchives(context.Background()))
+	require.NotContains(t, repo.archives, int64(1))
+	require.Contains(t, repo.archives, int64(2))
+	require.NotContains(t, store.objects, "old")
+	require.Contains(t, store.objects, "recent")
+}
+
+func TestUsageArchiveService_StorageNotConfigured(t *testing.T) {
+	svc := NewUsageArchiveService(newUsageArchiveRepoStub(), nil, &config.Config{UsageArchive: config.UsageArchiveConfig{Enabled: true, ArchiveAfterDays: 30, BatchSize: 10, MaxBatchesPerRun: 1}})
+	_, err := svc.ArchiveOnce(context.Background())
+	require.ErrorIs(t, err, ErrUsageLogArchiveNotReady)
+}
@@ -39,6 +39,10 @@ type UsageBillingCommand struct {
 	APIKeyQuotaCost     float64
 	APIKeyRateLimitCost float64
 	AccountQuotaCost    float64
+
+	// ParentAPIKeyID 委托子 Key 的父 Key；ParentAPIKeyQuotaCost 同步计入父 Key 的额度
+	ParentAPIKeyID        int64
+	ParentAPIKeyQuotaCost float64
 }
 
 func (c *UsageBillingCommand) Normalize() {
@@ -78,6 +82,9 @@ func buildUsageBillingFingerprint(c *UsageBillingCommand) string {
 		c.APIKeyRateLimitCost,
 		c.AccountQuotaCost,
 	)
+	if c.ParentAPIKeyID > 0 {
+		raw += fmt.Sprintf("|parent:%d:%0.10f", c.ParentAPIKeyID, c.ParentAPIKeyQuotaCost)
+	}
 	if payloadHash := strings.TrimSpace(c.RequestPayloadHash); payloadHash != "" {
 		raw += "|" + payloadHash
 	}
@@ -117,6 +124,9 @@ type UsageBillingApplyResult struct {
 	NewBalance           *float64           // post-deduction balance (nil = no balance deduction)
 	BalanceOverdrafted   bool               // true when the sufficient-balance guard missed and debt was still recorded
 	QuotaState           *AccountQuotaState // post-increment quota state (nil = no quota increment)
+
+	// ParentAPIKeyQuotaExhausted 本次扣费使委托子 Key 的父 Key 额度耗尽
+	ParentAPIKeyQuotaExhausted bool
 }
 
 // BatchImageBalanceHoldCommand describes an idempotent balance hold operation.
@@ -127,6 +127,8 @@ type UsageLog struct {
 	InboundEndpoint *string
 	// UpstreamEndpoint is the normalized upstream endpoint path, e.g. /v1/responses.
 	UpstreamEndpoint *string
+	// BetaFeatures lists the experimental features enabled via X-Sub2API-Beta, comma separated.
+	BetaFeatures *string
 
 	GroupID        *int64
 	SubscriptionID *int64
@@ -143,6 +145,10 @@ type UsageLog struct {
 	ImageInputCost    float64
 	ImageOutputTokens int
 	ImageOutputCost   float64
+	// ReasoningTokens 为 OutputTokens 中上游单独回报的推理（thinking）部分，
+	// ReasoningCost 从 OutputCost 中拆出，TotalCost 口径不变。
+	ReasoningTokens int
+	ReasoningCost   float64
 
 	InputCost                 float64
 	OutputCost                float64
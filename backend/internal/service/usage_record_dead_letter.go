@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	usageRecordDeadLetterReasonBilling  = "billing_failed"
+	usageRecordDeadLetterReasonUsageLog = "usage_log_failed"
+
+	usageRecordDeadLetterPushTimeout   = 3 * time.Second
+	usageRecordDeadLetterReplayTimeout = 30 * time.Second
+	usageRecordDeadLetterHealthTimeout = 3 * time.Second
+)
+
+// UsageRecordDeadLetterEntry 死信队列中的一条记录
+type UsageRecordDeadLetterEntry struct {
+	ID      string
+	Payload []byte
+}
+
+// UsageRecordDeadLetterCache 死信队列存储，按写入顺序读取
+type UsageRecordDeadLetterCache interface {
+	Push(ctx context.Context, payload []byte, maxEntries int64) error
+	Peek(ctx context.Context, count int64) ([]UsageRecordDeadLetterEntry, error)
+	Remove(ctx context.Context, ids ...string) error
+	Depth(ctx context.Context) (int64, error)
+}
+
+// usageRecordDeadLetter 写库失败的使用量记录载荷。
+// Command 非空表示计费尚未落库，回放时先按 request_id 幂等扣费，再写使用日志。
+type usageRecordDeadLetter struct {
+	Reason    string               `json:"reason"`
+	Command   *UsageBillingCommand `json:"command,omitempty"`
+	UsageLog  *UsageLog            `json:"usage_log"`
+	Attempts  int                  `json:"attempts"`
+	FailedAt  time.Time            `json:"failed_at"`
+	LastError string               `json:"last_error,omitempty"`
+}
+
+// UsageRecordDeadLetterStats 死信队列运行时统计
+type UsageRecordDeadLetterStats struct {
+	Enabled       bool       `json:"enabled"`
+	Depth         int64      `json:"depth"`
+	Enqueued      uint64     `json:"enqueued"`
+	EnqueueFailed uint64     `json:"enqueue_failed"`
+	Replayed      uint64     `json:"replayed"`
+	ReplayFailed  uint64     `json:"replay_failed"`
+	Discarded     uint64     `json:"discarded"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastReplayAt  *time.Time `json:"last_replay_at,omitempty"`
+}
+
+// UsageRecordDeadLetterService 使用量记录死信队列。
+// 计费/使用日志写库失败时保存载荷，恢复 worker 在数据库健康后回放。
+// 回放只重做数据库侧的扣费与日志写入，并失效相关计费缓存；Redis 侧的用户平台配额计数不会补记。
+type UsageRecordDeadLetterService struct {
+	cache        UsageRecordDeadLetterCache
+	billingRepo  UsageBillingRepository
+	usageLogRepo UsageLogRepository
+	billingCache *BillingCacheService
+	db           *sql.DB
+	cfg          config.GatewayUsageRecordDeadLetterConfig
+
+	enqueued      atomic.Uint64
+	enqueueFailed atomic.Uint64
+	replayed      atomic.Uint64
+	replayFailed  atomic.Uint64
+	discarded     atomic.Uint64
+	depth         atomic.Int64
+	lastReplay    atomic.Int64
+	lastError     atomic.Value
+
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+var defaultUsageRecordDeadLetter atomic.Pointer[UsageRecordDeadLetterService]
+
+// SetDefaultUsageRecordDeadLetterService 设置全局死信队列（供计费/日志写入路径使用）
+func SetDefaultUsageRecordDeadLetterService(svc *UsageRecordDeadLetterService) {
+	defaultUsageRecordDeadLetter.Store(svc)
+}
+
+// DefaultUsageRecordDeadLetterService 返回全局死信队列，未设置时为 nil
+func DefaultUsageRecordDeadLetterService() *UsageRecordDeadLetterService {
+	return defaultUsageRecordDeadLetter.Load()
+}
+
+// NewUsageRecordDeadLetterService 创建使用量记录死信队列
+func NewUsageRecordDeadLetterService(
+	cache UsageRecordDeadLetterCache,
+	billingRepo UsageBillingRepository,
+	usageLogRepo UsageLogRepository,
+	billingCache *BillingCacheService,
+	db *sql.DB,
+	cfg *config.Config,
+) *UsageRecordDeadLetterService {
+	s := &UsageRecordDeadLetterService{
+		cache:        cache,
+		billingRepo:  billingRepo,
+		usageLogRepo: usageLogRepo,
+		billingCache: billingCache,
+		db:           db,
+		stopCh:       make(chan struct{}),
+	}
+	if cfg != nil {
+		s.cfg = cfg.Gateway.UsageRecord.DeadLetter
+	}
+	return s
+}
+
+// Enabled 是否启用
+func (s *UsageRecordDeadLetterService) Enabled() bool {
+	return s != nil && s.cache != nil && s.cfg.Enabled
+}
+
+// Start 启动回放 worker
+func (s *UsageRecordDeadLetterService) Start() {
+	if !s.Enabled() || s.cfg.ReplayIntervalSeconds <= 0 {
+		return
+	}
+	s.startOnce.Do(func() {
+		s.wg.Add(1)
+		go s.run()
+	})
+}
+
+// Stop 停止回放 worker
+func (s *UsageRecordDeadLetterService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		s.wg.Wait()
+	})
+}
+
+func (s *UsageRecordDeadLetterService) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(time.Duration(s.cfg.ReplayIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), usageRecordDeadLetterReplayTimeout)
+			s.replayOnce(ctx)
+			cancel()
+		}
+	}
+}
+
+// Enqueue 保存一条写库失败的使用量记录；返回是否成功写入死信队列。
+func (s *UsageRecordDeadLetterService) Enqueue(reason string, cmd *UsageBillingCommand, usageLog *UsageLog, cause error) bool {
+	if !s.Enabled() || usageLog == nil {
+		return false
+	}
+	entry := usageRecordDeadLetter{
+		Reason:   reason,
+		Command:  cmd,
+		UsageLog: detachUsageLogForDeadLetter(usageLog),
+		FailedAt: time.Now().UTC(),
+	}
+	if cause != nil {
+		entry.LastError = cause.Error()
+	}
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		s.enqueueFailed.Add(1)
+		logger.LegacyPrintf("service.usage_record_dlq", "ALERT: marshal dead letter failed request_id=%s: %v", usageLog.RequestID, err)
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), usageRecordDeadLetterPushTimeout)
+	defer cancel()
+	if err := s.cache.Push(ctx, payload, int64(s.cfg.MaxEntries)); err != nil {
+		s.enqueueFailed.Add(1)
+		s.lastError.Store(err.Error())
+		logger.LegacyPrintf("service.usage_record_dlq", "ALERT: push dead letter failed request_id=%s reason=%s: %v", usageLog.RequestID, reason, err)
+		return false
+	}
+	s.enqueued.Add(1)
+	logger.LegacyPrintf("service.usage_record_dlq", "Usage record dead-lettered request_id=%s reason=%s: %v", usageLog.RequestID, reason, cause)
+	return true
+}
+
+// Stats 返回死信队列统计（depth 实时查询，失败时返回上次观测值）
+func (s *UsageRecordDeadLetterService) Stats(ctx context.Context) UsageRecordDeadLetterStats {
+	if s == nil {
+		return UsageRecordDeadLetterStats{}
+	}
+	stats := UsageRecordDeadLetterStats{
+		Enabled:       s.Enabled(),
+		Enqueued:      s.enqueued.Load(),
+		EnqueueFailed: s.enqueueFailed.Load(),
+		Replayed:      s.replayed.Load(),
+		ReplayFailed:  s.replayFailed.Load(),
+		Discarded:     s.discarded.Load(),
+	}
+	if s.cache != nil {
+		if depth, err := s.cache.Depth(ctx); err == nil {
+			s.depth.Store(depth)
+		}
+	}
+	stats.Depth = s.depth.Load()
+	if v, ok := s.lastError.Load().(string); ok {
+		stats.LastError = v
+	}
+	if nanos := s.lastReplay.Load(); nanos > 0 {
+		t := time.Unix(0, nanos).UTC()
+		stats.LastReplayAt = &t
+	}
+	return stats
+}
+
+// replayOnce 回放一批死信；数据库不健康时跳过，回放中途数据库故障则提前结束本轮。
+func (s *UsageRecordDeadLetterService) replayOnce(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+	depth, err := s.cache.Depth(ctx)
+	if err != nil {
+		s.lastError.Store(err.Error())
+		return
+	}
+	s.depth.Store(depth)
+	if depth == 0 || !s.dbHealthy(ctx) {
+		return
+	}
+	entries, err := s.cache.Peek(ctx, int64(s.cfg.ReplayBatchSize))
+	if err != nil {
+		s.lastError.Store(err.Error())
+		return
+	}
+	s.lastReplay.Store(time.Now().UnixNano())
+
+	for _, raw := range entries {
+		var entry usageRecordDeadLetter
+		if err := json.Unmarshal(raw.Payload, &entry); err != nil || entry.UsageLog == nil {
+			s.discard(ctx, raw.ID, "", "undecodable payload")
+			continue
+		}
+		replayErr := s.replayEntry(ctx, &entry)
+		if replayErr == nil {
+			if err := s.cache.Remove(ctx, raw.ID); err != nil {
+				s.lastError.Store(err.Error())
+				return
+			}
+			s.replayed.Add(1)
+			continue
+		}
+
+		s.replayFailed.Add(1)
+		s.lastError.Store(replayErr.Error())
+		if isPermanentUsageBillingError(replayErr) {
+			s.discard(ctx, raw.ID, entry.UsageLog.RequestID, replayErr.Error())
+			continue
+		}
+		if !s.dbHealthy(ctx) {
+			return
+		}
+		// 数据库健康但仍失败：计入重试次数并移到队尾，避免单条毒数据阻塞队列。
+		entry.Attempts++
+		entry.LastError = replayErr.Error()
+		if entry.Attempts >= s.cfg.MaxAttempts {
+			s.discard(ctx, raw.ID, entry.UsageLog.RequestID, replayErr.Error())
+			continue
+		}
+		payload, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		if err := s.cache.Push(ctx, payload, int64(s.cfg.MaxEntries)); err != nil {
+			s.lastError.Store(err.Error())
+			return
+		}
+		if err := s.cache.Remove(ctx, raw.ID); err != nil {
+			s.lastError.Store(err.Error())
+			return
+		}
+	}
+	if depth, err := s.cache.Depth(ctx); err == nil {
+		s.depth.Store(depth)
+	}
+}
+
+func (s *UsageRecordDeadLetterService) replayEntry(ctx context.Context, entry *usageRecordDeadLetter) error {
+	if entry.Command != nil && s.billingRepo != nil {
+		result, err := s.billingRepo.Apply(ctx, entry.Command)
+		if err != nil {
+			return err
+		}
+		if result != nil && result.Applied {
+			s.invalidateBillingCaches(ctx, entry.Command, entry.UsageLog)
+		}
+		// 扣费已落库（或已被去重），后续重试只需补写使用日志
+		entry.Command = nil
+	}
+	if s.usageLogRepo == nil {
+		return nil
+	}
+	_, err := s.usageLogRepo.Create(ctx, entry.UsageLog)
+	return err
+}
+
+func (s *UsageRecordDeadLetterService) invalidateBillingCaches(ctx context.Context, cmd *UsageBillingCommand, usageLog *UsageLog) {
+	if s.billingCache == nil {
+		return
+	}
+	if cmd.BalanceCost > 0 {
+		_ = s.billingCache.InvalidateUserBalance(ctx, cmd.UserID)
+	}
+	if cmd.SubscriptionCost > 0 && usageLog.GroupID != nil {
+		_ = s.billingCache.InvalidateSubscription(ctx, cmd.UserID, *usageLog.GroupID)
+	}
+	if cmd.APIKeyRateLimitCost > 0 {
+		_ = s.billingCache.InvalidateAPIKeyRateLimit(ctx, cmd.APIKeyID)
+	}
+}
+
+func (s *UsageRecordDeadLetterService) discard(ctx context.Context, id, requestID, reason string) {
+	if err := s.cache.Remove(ctx, id); err != nil {
+		s.lastError.Store(err.Error())
+		return
+	}
+	s.discarded.Add(1)
+	logger.LegacyPrintf("service.usage_record_dlq", "ALERT: dead letter discarded id=%s request_id=%s: %s", id, requestID, reason)
+}
+
+func (s *UsageRecordDeadLetterService) dbHealthy(ctx context.Context) bool {
+	if IsDatabaseDegraded() {
+		return false
+	}
+	if s.db == nil {
+		return true
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, usageRecordDeadLetterHealthTimeout)
+	defer cancel()
+	var one int
+	return s.db.QueryRowContext(pingCtx, "SELECT 1").Scan(&one) == nil
+}
+
+// detachUsageLogForDeadLetter 复制使用日志并去掉关联实体，仅保留需要落库的字段
+func detachUsageLogForDeadLetter(usageLog *UsageLog) *UsageLog {
+	clone := *usageLog
+	clone.User = nil
+	clone.APIKey = nil
+	clone.Account = nil
+	clone.Group = nil
+	clone.Subscription = nil
+	return &clone
+}
+
+// isPermanentUsageBillingError 业务性失败（回放也不会成功），不进入死信队列
+func isPermanentUsageBillingError(err error) bool {
+	return errors.Is(err, ErrUsageBillingRequestConflict) ||
+		errors.Is(err, ErrUsageBillingRequestIDRequired) ||
+		errors.Is(err, ErrUserNotFound) ||
+		errors.Is(err, ErrSubscriptionNotFound)
+}
+
+// errUsageRecordDeferred 数据库降级期间计费已转入死信队列；调用方应跳过后续写库（使用日志随计费一并回放）。
+var errUsageRecordDeferred = errors.New("usage record deferred: database degraded")
+
+// enqueueUsageRecordDeadLetter 将写库失败的使用量记录交给全局死信队列；业务性错误不入队。
+func enqueueUsageRecordDeadLetter(reason string, cmd *UsageBillingCommand, usageLog *UsageLog, cause error) {
+	if cause == nil || isPermanentUsageBillingError(cause) {
+		return
+	}
+	DefaultUsageRecordDeadLetterService().Enqueue(reason, cmd, usageLog, cause)
+}
+
+// deferUsageRecordWhileDegraded 数据库降级期间直接将使用量记录写入死信队列，
+// 避免请求 worker 阻塞在必然超时的写库调用上。返回 false 表示未降级或入队失败，调用方照常写库。
+func deferUsageRecordWhileDegraded(reason string, cmd *UsageBillingCommand, usageLog *UsageLog) bool {
+	if !IsDatabaseDegraded() {
+		return false
+	}
+	return DefaultUsageRecordDeadLetterService().Enqueue(reason, cmd, usageLog, errUsageRecordDeferred)
+}
+
+// GetUsageRecordDeadLetterHealth 返回使用量记录死信队列深度与回放统计
+func (s *OpsService) GetUsageRecordDeadLetterHealth(ctx context.Context) UsageRecordDeadLetterStats {
+	if s == nil || s.usageRecordDeadLetter == nil {
+		return UsageRecordDeadLetterStats{}
+	}
+	return s.usageRecordDeadLetter.Stats(ctx)
+}
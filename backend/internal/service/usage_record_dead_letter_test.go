@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryUsageRecordDeadLetterCache struct {
+	seq     int
+	entries []UsageRecordDeadLetterEntry
+}
+
+func (c *memoryUsageRecordDeadLetterCache) Push(_ context.Context, payload []byte, _ int64) error {
+	c.seq++
+	c.entries = append(c.entries, UsageRecordDeadLetterEntry{ID: strconv.Itoa(c.seq), Payload: append([]byte(nil), payload...)})
+	return nil
+}
+
+func (c *memoryUsageRecordDeadLetterCache) Peek(_ context.Context, count int64) ([]UsageRecordDeadLetterEntry, error) {
+	n := min(int(count), len(c.entries))
+	return append([]UsageRecordDeadLetterEntry(nil), c.entries[:n]...), nil
+}
+
+func (c *memoryUsageRecordDeadLetterCache) Remove(_ context.Context, ids ...string) error {
+	kept := c.entries[:0]
+	for _, e := range c.entries {
+		removed := false
+		for _, id := range ids {
+			if e.ID == id {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, e)
+		}
+	}
+	c.entries = kept
+	return nil
+}
+
+func (c *memoryUsageRecordDeadLetterCache) Depth(context.Context) (int64, error) {
+	return int64(len(c.entries)), nil
+}
+
+func newTestUsageRecordDeadLetterService(cache UsageRecordDeadLetterCache, billingRepo UsageBillingRepository, logRepo UsageLogRepository) *UsageRecordDeadLetterService {
+	cfg := &config.Config{}
+	cfg.Gateway.UsageRecord.DeadLetter = config.GatewayUsageRecordDeadLetterConfig{
+		Enabled:               true,
+		MaxEntries:            100,
+		ReplayIntervalSeconds: 30,
+		ReplayBatchSize:       10,
+		MaxAttempts:           2,
+	}
+	return NewUsageRecordDeadLetterService(cache, billingRepo, logRepo, nil, nil, cfg)
+}
+
+func TestUsageRecordDeadLetter_ReplaysBillingThenUsageLog(t *testing.T) {
+	cache := &memoryUsageRecordDeadLetterCache{}
+	billingRepo := &openAIRecordUsageBillingRepoStub{result: &UsageBillingApplyResult{Applied: true}}
+	logRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc := newTestUsageRecordDeadLetterService(cache, billingRepo, logRepo)
+
+	usageLog := &UsageLog{RequestID: "req-1", APIKeyID: 3, UserID: 9, Model: "claude-sonnet-4-5", InputTokens: 10, User: &User{ID: 9}}
+	cmd := &UsageBillingCommand{RequestID: "req-1", APIKeyID: 3, UserID: 9, BalanceCost: 0.5}
+	require.True(t, svc.Enqueue(usageRecordDeadLetterReasonBilling, cmd, usageLog, errors.New("connection refused")))
+	require.Equal(t, int64(1), svc.Stats(context.Background()).Depth)
+
+	svc.replayOnce(context.Background())
+
+	require.Equal(t, 1, billingRepo.calls)
+	require.Equal(t, "req-1", billingRepo.lastCmd.RequestID)
+	require.Equal(t, 0.5, billingRepo.lastCmd.BalanceCost)
+	require.Equal(t, 1, logRepo.calls)
+	require.Equal(t, 10, logRepo.lastLog.InputTokens)
+	require.Nil(t, logRepo.lastLog.User, "associated entities must not be serialized")
+
+	stats := svc.Stats(context.Background())
+	require.Equal(t, int64(0), stats.Depth)
+	require.Equal(t, uint64(1), stats.Replayed)
+}
+
+func TestUsageRecordDeadLetter_RequeuesThenDiscardsAfterMaxAttempts(t *testing.T) {
+	cache := &memoryUsageRecordDeadLetterCache{}
+	logRepo := &openAIRecordUsageLogRepoStub{err: errors.New("value too long")}
+	svc := newTestUsageRecordDeadLetterService(cache, nil, logRepo)
+
+	require.True(t, svc.Enqueue(usageRecordDeadLetterReasonUsageLog, nil, &UsageLog{RequestID: "req-2"}, errors.New("timeout")))
+
+	svc.replayOnce(context.Background())
+	require.Len(t, cache.entries, 1, "failed entry is moved to the tail for retry")
+	require.Equal(t, "2", cache.entries[0].ID)
+
+	svc.replayOnce(context.Background())
+	require.Empty(t, cache.entries)
+	stats := svc.Stats(context.Background())
+	require.Equal(t, uint64(1), stats.Discarded)
+	require.Equal(t, uint64(2), stats.ReplayFailed)
+}
+
+func TestEnqueueUsageRecordDeadLetter_SkipsPermanentErrors(t *testing.T) {
+	cache := &memoryUsageRecordDeadLetterCache{}
+	svc := newTestUsageRecordDeadLetterService(cache, nil, nil)
+	SetDefaultUsageRecordDeadLetterService(svc)
+	t.Cleanup(func() { SetDefaultUsageRecordDeadLetterService(nil) })
+
+	usageLog := &UsageLog{RequestID: "req-3"}
+	enqueueUsageRecordDeadLetter(usageRecordDeadLetterReasonBilling, &UsageBillingCommand{RequestID: "req-3"}, usageLog, ErrUsageBillingRequestConflict)
+	require.Empty(t, cache.entries)
+
+	enqueueUsageRecordDeadLetter(usageRecordDeadLetterReasonBilling, &UsageBillingCommand{RequestID: "req-3"}, usageLog, errors.New("dial tcp: connection refused"))
+	require.Len(t, cache.entries, 1)
+}
+
+func TestApplyUsageBilling_DeadLettersInfrastructureFailure(t *testing.T) {
+	cache := &memoryUsageRecordDeadLetterCache{}
+	SetDefaultUsageRecordDeadLetterService(newTestUsageRecordDeadLetterService(cache, nil, nil))
+	t.Cleanup(func() { SetDefaultUsageRecordDeadLetterService(nil) })
+
+	repo := &openAIRecordUsageBillingRepoStub{err: errors.New("database is down")}
+	usageLog := &UsageLog{RequestID: "req-4", APIKeyID: 1, UserID: 2}
+	_, err := applyUsageBilling(context.Background(), "req-4", usageLog, &postUsageBillingParams{
+		Cost:    &CostBreakdown{TotalCost: 1, ActualCost: 1},
+		User:    &User{ID: 2},
+		APIKey:  &APIKey{ID: 1},
+		Account: &Account{ID: 5},
+	}, &billingDeps{}, repo)
+	require.Error(t, err)
+	require.Len(t, cache.entries, 1)
+}
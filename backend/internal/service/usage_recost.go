@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+)
+
+const (
+	// PricingSnapshotCurrent 按当前生效的价格表重算
+	PricingSnapshotCurrent = "current"
+	// PricingSnapshotOverridePrefix 按 "override:<name>" 指定命名覆盖集（叠加在当前价格表之上）
+	PricingSnapshotOverridePrefix = "override:"
+
+	// usageRecostStatsMaxRows 统计重算单次最多扫描的用量记录数，超出时结果标记为 truncated
+	usageRecostStatsMaxRows = 50000
+	usageRecostStatsBatch   = 1000
+	// usageRecostCacheSize 缓存的已加载快照数，避免每次请求重复解析价格文件
+	usageRecostCacheSize = 4
+
+	// 未重算原因
+	UsageRecostSkipChannelPricing = "channel_pricing"
+	UsageRecostSkipUnpriced       = "unpriced"
+)
+
+var (
+	ErrInvalidPricingSnapshot  = infraerrors.BadRequest("INVALID_PRICING_SNAPSHOT", "pricing_snapshot must be current, a date (YYYY-MM-DD or RFC3339) or override:<name>")
+	ErrPricingSnapshotNotFound = infraerrors.NotFound("PRICING_SNAPSHOT_NOT_FOUND", "no stored pricing snapshot matches pricing_snapshot")
+)
+
+// UsageRecost 单条用量在指定价格快照下的重算结果。
+// 未重算（渠道定价、非 token 计费、快照中无该模型价格）时 SkipReason 非空，重算费用沿用原费用。
+type UsageRecost struct {
+	Repriced        bool    `json:"repriced"`
+	SkipReason      string  `json:"skip_reason,omitempty"`
+	TotalCost       float64 `json:"total_cost"`
+	ActualCost      float64 `json:"actual_cost"`
+	ActualCostDelta float64 `json:"actual_cost_delta"`
+}
+
+// UsageRecostSummary 一组用量在指定价格快照下的重算汇总，同时给出原费用与重算费用。
+type UsageRecostSummary struct {
+	PricingSnapshot      string           `json:"pricing_snapshot"`
+	ResolvedSnapshot     string           `json:"resolved_snapshot"`
+	Rows                 int64            `json:"rows"`
+	Repriced             int64            `json:"repriced"`
+	Skipped              map[string]int64 `json:"skipped"`
+	Truncated            bool             `json:"truncated"`
+	OriginalTotalCost    float64          `json:"original_total_cost"`
+	OriginalActualCost   float64          `json:"original_actual_cost"`
+	RecomputedTotalCost  float64          `json:"recomputed_total_cost"`
+	RecomputedActualCost float64          `json:"recomputed_actual_cost"`
+	ActualCostDelta      float64          `json:"actual_cost_delta"`
+}
+
+// PricingSnapshotCatalog 可用于重算的价格快照与覆盖集
+type PricingSnapshotCatalog struct {
+	Snapshots    []PricingSnapshotInfo `json:"snapshots"`
+	OverrideSets []string              `json:"override_sets"`
+}
+
+// UsageRecoster 绑定到某个价格快照的重算器
+type UsageRecoster struct {
+	// Resolved 实际使用的快照："current"、归档快照名或 "override:<name>"
+	Resolved string
+	billing  *BillingService
+}
+
+// UsageRecostService 按历史价格快照、当前价格或命名覆盖集重算用量费用（只读，不修改用量记录）。
+type UsageRecostService struct {
+	cfg            *config.Config
+	pricingService *PricingService
+	billingService *BillingService
+	usageService   *UsageService
+
+	mu    sync.Mutex
+	cache map[string]*BillingService
+}
+
+// NewUsageRecostService 创建用量重算服务
+func NewUsageRecostService(cfg *config.Config, pricingService *PricingService, billingService *BillingService, usageService *UsageService) *UsageRecostService {
+	return &UsageRecostService{
+		cfg:            cfg,
+		pricingService: pricingService,
+		billingService: billingService,
+		usageService:   usageService,
+		cache:          make(map[string]*BillingService),
+	}
+}
+
+// Catalog 列出已归档的价格快照与命名覆盖集
+func (s *UsageRecostService) Catalog() (*PricingSnapshotCatalog, error) {
+	snapshots, err := s.pricingService.ListPricingSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.pricingService.ListPricingOverrideSets()
+	if err != nil {
+		return nil, err
+	}
+	return &PricingSnapshotCatalog{Snapshots: snapshots, OverrideSets: overrides}, nil
+}
+
+// Resolve 解析 pricing_snapshot 参数：
+//   - "current"：当前生效价格
+//   - "YYYY-MM-DD"（UTC 当日结束时）或 RFC3339 时间：该时刻生效的归档快照
+//   - "override:<name>"：当前价格叠加命名覆盖集（按模型整条替换）
+func (s *UsageRecostService) Resolve(spec string) (*UsageRecoster, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return nil, ErrInvalidPricingSnapshot
+	case strings.EqualFold(spec, PricingSnapshotCurrent):
+		return &UsageRecoster{Resolved: PricingSnapshotCurrent, billing: s.billingService}, nil
+	case strings.HasPrefix(spec, PricingSnapshotOverridePrefix):
+		return s.resolveOverride(strings.TrimPrefix(spec, PricingSnapshotOverridePrefix))
+	default:
+		asOf, err := parsePricingSnapshotAsOf(spec)
+		if err != nil {
+			return nil, ErrInvalidPricingSnapshot
+		}
+		return s.resolveAsOf(asOf)
+	}
+}
+
+// parsePricingSnapshotAsOf 解析回溯时间点；纯日期按 UTC 当日结束计，返回的时间为开区间上界。
+func parsePricingSnapshotAsOf(spec string) (time.Time, error) {
+	if day, err := time.Parse("2006-01-02", spec); err == nil {
+		return day.AddDate(0, 0, 1), nil
+	}
+	t, err := time.Parse(time.RFC3339, spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(time.Nanosecond), nil
+}
+
+func (s *UsageRecostService) resolveAsOf(asOf time.Time) (*UsageRecoster, error) {
+	snapshots, err := s.pricingService.ListPricingSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	var picked *PricingSnapshotInfo
+	for i := range snapshots {
+		if !snapshots[i].EffectiveAt.Before(asOf) {
+			break
+		}
+		picked = &snapshots[i]
+	}
+	if picked == nil {
+		return nil, ErrPricingSnapshotNotFound
+	}
+	billing, err := s.cachedBilling("snapshot:"+picked.Name, func() (*PricingService, error) {
+		pricing := NewPricingService(s.cfg, nil)
+		if err := pricing.LoadSnapshot(picked.path); err != nil {
+			return nil, err
+		}
+		return pricing, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &UsageRecoster{Resolved: picked.Name, billing: billing}, nil
+}
+
+func (s *UsageRecostService) resolveOverride(name string) (*UsageRecoster, error) {
+	if !pricingOverrideNamePattern.MatchString(name) {
+		return nil, ErrInvalidPricingSnapshot
+	}
+	path := filepath.Join(s.pricingService.pricingOverrideDir(), name+".json")
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrPricingSnapshotNotFound
+		}
+		return nil, fmt.Errorf("stat pricing override: %w", err)
+	}
+
+	// 覆盖集叠加在当前价格之上：缓存键包含覆盖文件与当前价格表的版本
+	s.pricingService.mu.RLock()
+	baseVersion := s.pricingService.localHash
+	s.pricingService.mu.RUnlock()
+	key := fmt.Sprintf("override:%s:%d:%s", name, info.ModTime().UnixNano(), baseVersion)
+	billing, err := s.cachedBilling(key, func() (*PricingService, error) {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read pricing override: %w", err)
+		}
+		overrides, err := s.pricingService.parsePricingData(body)
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_PRICING_OVERRIDE", fmt.Sprintf("pricing override %s is invalid: %v", name, err))
+		}
+		data := s.pricingService.pricingDataCopy()
+		for model, pricing := range overrides {
+			data[strings.ToLower(model)] = pricing
+		}
+		return newDetachedPricingService(s.pricingService, data, info.ModTime()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &UsageRecoster{Resolved: PricingSnapshotOverridePrefix + name, billing: billing}, nil
+}
+
+func (s *UsageRecostService) cachedBilling(key string, load func() (*PricingService, error)) (*BillingService, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if billing, ok := s.cache[key]; ok {
+		return billing, nil
+	}
+	pricing, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if len(s.cache) >= usageRecostCacheSize {
+		clear(s.cache)
+	}
+	billing := NewBillingService(s.cfg, pricing)
+	s.cache[key] = billing
+	return billing, nil
+}
+
+// Recost 按快照重算单条用量。与离线重算工具一致，仅重算按 token 计费且未走渠道定价的记录。
+func (r *UsageRecoster) Recost(log *UsageLog) UsageRecost {
+	original := UsageRecost{TotalCost: log.TotalCost, ActualCost: log.ActualCost}
+	if log.ChannelID != nil {
+		original.SkipReason = UsageRecostSkipChannelPricing
+		return original
+	}
+	if log.BillingMode != nil {
+		if mode := strings.TrimSpace(*log.BillingMode); mode != "" && mode != string(BillingModeToken) {
+			original.SkipReason = "billing_mode_" + mode
+			return original
+		}
+	}
+	serviceTier := ""
+	if log.ServiceTier != nil {
+		serviceTier = *log.ServiceTier
+	}
+	tokens := UsageTokens{
+		InputTokens:           log.InputTokens,
+		ImageInputTokens:      log.ImageInputTokens,
+		OutputTokens:          log.OutputTokens,
+		CacheCreationTokens:   log.CacheCreationTokens,
+		CacheReadTokens:       log.CacheReadTokens,
+		CacheCreation5mTokens: log.CacheCreation5mTokens,
+		CacheCreation1hTokens: log.CacheCreation1hTokens,
+		ImageOutputTokens:     log.ImageOutputTokens,
+		ReasoningTokens:       log.ReasoningTokens,
+	}
+	cost, err := r.billing.CalculateCostWithServiceTier(log.Model, tokens, log.RateMultiplier, serviceTier)
+	if err != nil {
+		original.SkipReason = UsageRecostSkipUnpriced
+		return original
+	}
+	return UsageRecost{
+		Repriced:        true,
+		TotalCost:       cost.TotalCost,
+		ActualCost:      cost.ActualCost,
+		ActualCostDelta: cost.ActualCost - log.ActualCost,
+	}
+}
+
+// Summarize 按过滤条件扫描用量记录并汇总重算结果，最多扫描 usageRecostStatsMaxRows 条。
+func (s *UsageRecostService) Summarize(ctx context.Context, spec string, filters usagestats.UsageLogFilters) (*UsageRecostSummary, error) {
+	recoster, err := s.Resolve(spec)
+	if err != nil {
+		return nil, err
+	}
+	summary := &UsageRecostSummary{
+		PricingSnapshot:  strings.TrimSpace(spec),
+		ResolvedSnapshot: recoster.Resolved,
+		Skipped:          make(map[string]int64),
+	}
+	filters.ExactTotal = false
+	cursor := &pagination.Cursor{}
+	for {
+		params := pagination.PaginationParams{PageSize: usageRecostStatsBatch, Cursor: cursor}
+		logs, result, err := s.usageService.ListWithFilters(ctx, params, filters)
+		if err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			if summary.Rows >= usageRecostStatsMaxRows {
+				summary.Truncated = true
+				break
+			}
+			summary.add(&logs[i], recoster.Recost(&logs[i]))
+		}
+		if summary.Truncated || result == nil || !result.HasMore || result.NextCursor == "" {
+			break
+		}
+		next, err := pagination.ParseCursor(result.NextCursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &next
+	}
+	summary.ActualCostDelta = summary.RecomputedActualCost - summary.OriginalActualCost
+	return summary, nil
+}
+
+func (s *UsageRecostSummary) add(log *UsageLog, recost UsageRecost) {
+	s.Rows++
+	s.OriginalTotalCost += log.TotalCost
+	s.OriginalActualCost += log.ActualCost
+	s.RecomputedTotalCost += recost.TotalCost
+	s.RecomputedActualCost += recost.ActualCost
+	if recost.Repriced {
+		s.Repriced++
+	} else {
+		s.Skipped[recost.SkipReason]++
+	}
+}
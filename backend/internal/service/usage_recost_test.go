@@ -0,0 +1,135 @@
+//go:build unit
+
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newUsageRecostTestService(t *testing.T) (*UsageRecostService, *PricingService) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Pricing.DataDir = t.TempDir()
+	pricing := NewPricingService(cfg, nil)
+	pricing.pricingData = map[string]*LiteLLMModelPricing{
+		"recost-test-model": {InputCostPerToken: 3e-6, OutputCostPerToken: 1e-5},
+	}
+	billing := NewBillingService(cfg, pricing)
+	return NewUsageRecostService(cfg, pricing, billing, nil), pricing
+}
+
+func recostPricingBody(inputCost float64) []byte {
+	return []byte(`{"recost-test-model": {"input_cost_per_token": ` + strconv.FormatFloat(inputCost, 'g', -1, 64) + `, "output_cost_per_token": 0.00001}}`)
+}
+
+func TestArchivePricingSnapshot_DedupesAndSorts(t *testing.T) {
+	_, pricing := newUsageRecostTestService(t)
+	t1 := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	t2 := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	pricing.archivePricingSnapshot(recostPricingBody(1e-6), t1)
+	pricing.archivePricingSnapshot(recostPricingBody(1e-6), t1.Add(time.Hour)) // 内容相同，跳过
+	pricing.archivePricingSnapshot(recostPricingBody(2e-6), t2)
+
+	snapshots, err := pricing.ListPricingSnapshots()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	require.True(t, snapshots[0].EffectiveAt.Equal(t1))
+	require.True(t, snapshots[1].EffectiveAt.Equal(t2))
+	require.NotEqual(t, snapshots[0].Hash, snapshots[1].Hash)
+}
+
+func TestUsageRecostService_ResolveAsOfDate(t *testing.T) {
+	svc, pricing := newUsageRecostTestService(t)
+	pricing.archivePricingSnapshot(recostPricingBody(1e-6), time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC))
+	pricing.archivePricingSnapshot(recostPricingBody(2e-6), time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC))
+
+	log := &UsageLog{Model: "recost-test-model", InputTokens: 1000, RateMultiplier: 1, TotalCost: 0.5, ActualCost: 0.5}
+
+	recoster, err := svc.Resolve("2026-04-15")
+	require.NoError(t, err)
+	got := recoster.Recost(log)
+	require.True(t, got.Repriced)
+	require.InDelta(t, 0.001, got.TotalCost, 1e-12)
+	require.InDelta(t, 0.001-0.5, got.ActualCostDelta, 1e-12)
+
+	// 纯日期按当日结束计：当天生效的快照也会被选中
+	recoster, err = svc.Resolve("2026-06-01")
+	require.NoError(t, err)
+	require.InDelta(t, 0.002, recoster.Recost(log).TotalCost, 1e-12)
+
+	recoster, err = svc.Resolve("2026-06-01T07:59:59Z")
+	require.NoError(t, err)
+	require.InDelta(t, 0.001, recoster.Recost(log).TotalCost, 1e-12)
+
+	_, err = svc.Resolve("2026-01-01")
+	require.ErrorIs(t, err, ErrPricingSnapshotNotFound)
+
+	_, err = svc.Resolve("last-week")
+	require.ErrorIs(t, err, ErrInvalidPricingSnapshot)
+}
+
+func TestUsageRecostService_ResolveCurrentAndOverride(t *testing.T) {
+	svc, pricing := newUsageRecostTestService(t)
+	log := &UsageLog{Model: "recost-test-model", InputTokens: 1000, RateMultiplier: 2, TotalCost: 0.003, ActualCost: 0.006}
+
+	recoster, err := svc.Resolve("current")
+	require.NoError(t, err)
+	got := recoster.Recost(log)
+	require.True(t, got.Repriced)
+	require.InDelta(t, 0.003, got.TotalCost, 1e-12)
+	require.InDelta(t, 0.006, got.ActualCost, 1e-12)
+	require.InDelta(t, 0, got.ActualCostDelta, 1e-12)
+
+	dir := pricing.pricingOverrideDir()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "negotiated.json"), []byte(`{"Recost-Test-Model": {"input_cost_per_token": 0.000001, "output_cost_per_token": 0.000005}}`), 0644))
+
+	recoster, err = svc.Resolve("override:negotiated")
+	require.NoError(t, err)
+	require.Equal(t, "override:negotiated", recoster.Resolved)
+	got = recoster.Recost(log)
+	require.InDelta(t, 0.001, got.TotalCost, 1e-12)
+	require.InDelta(t, 0.002-0.006, got.ActualCostDelta, 1e-12)
+
+	catalog, err := svc.Catalog()
+	require.NoError(t, err)
+	require.Equal(t, []string{"negotiated"}, catalog.OverrideSets)
+
+	_, err = svc.Resolve("override:missing")
+	require.ErrorIs(t, err, ErrPricingSnapshotNotFound)
+	_, err = svc.Resolve("override:../etc")
+	require.ErrorIs(t, err, ErrInvalidPricingSnapshot)
+}
+
+func TestUsageRecoster_SkipsNonTokenRows(t *testing.T) {
+	svc, _ := newUsageRecostTestService(t)
+	recoster, err := svc.Resolve("current")
+	require.NoError(t, err)
+
+	channelID := int64(7)
+	got := recoster.Recost(&UsageLog{Model: "recost-test-model", InputTokens: 1000, ChannelID: &channelID, TotalCost: 1, ActualCost: 1.5})
+	require.False(t, got.Repriced)
+	require.Equal(t, UsageRecostSkipChannelPricing, got.SkipReason)
+	require.InDelta(t, 1.5, got.ActualCost, 1e-12)
+
+	mode := "image"
+	got = recoster.Recost(&UsageLog{Model: "recost-test-model", BillingMode: &mode, TotalCost: 0.04, ActualCost: 0.04})
+	require.Equal(t, "billing_mode_image", got.SkipReason)
+
+	summary := &UsageRecostSummary{Skipped: map[string]int64{}}
+	summary.add(&UsageLog{TotalCost: 0.04, ActualCost: 0.04}, got)
+	summary.add(&UsageLog{TotalCost: 1, ActualCost: 1}, UsageRecost{Repriced: true, TotalCost: 2, ActualCost: 2})
+	require.Equal(t, int64(2), summary.Rows)
+	require.Equal(t, int64(1), summary.Repriced)
+	require.Equal(t, int64(1), summary.Skipped["billing_mode_image"])
+	require.InDelta(t, 2.04, summary.RecomputedActualCost, 1e-12)
+	require.InDelta(t, 1.04, summary.OriginalActualCost, 1e-12)
+}
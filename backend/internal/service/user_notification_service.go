@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// 站内通知类型
+const (
+	UserNotificationTypeBalanceLow           = "balance_low"
+	UserNotificationTypeSubscriptionExpiring = "subscription_expiring"
+	UserNotificationTypeAPIKeyExpiring       = "api_key_expiring"
+	UserNotificationTypeTaskFailed           = "task_failed"
+)
+
+// 站内通知级别
+const (
+	UserNotificationLevelInfo    = "info"
+	UserNotificationLevelWarning = "warning"
+	UserNotificationLevelError   = "error"
+)
+
+const (
+	userNotificationLeaderLockKey = "user:notification:maintenance:leader"
+	userNotificationLeaderLockTTL = 5 * time.Minute
+	// userNotificationAsyncTimeout 异步写入单条通知的超时，避免阻塞计费 / 任务热路径
+	userNotificationAsyncTimeout = 5 * time.Second
+)
+
+var ErrUserNotificationNotFound = infraerrors.New(http.StatusNotFound, "USER_NOTIFICATION_NOT_FOUND", "notification not found")
+
+// UserNotification 终端用户站内通知。
+type UserNotification struct {
+	ID        int64           `json:"id"`
+	UserID    int64           `json:"user_id"`
+	Type      string          `json:"type"`
+	Level     string          `json:"level"`
+	Title     string          `json:"title"`
+	Content   string          `json:"content"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// UserNotificationInput 生成一条站内通知的入参。
+// DedupKey 非空时同一用户内唯一，重复写入会被忽略（同一事件只提醒一次）。
+type UserNotificationInput struct {
+	UserID   int64
+	Type     string
+	Level    string
+	Title    string
+	Content  string
+	Metadata map[string]any
+	DedupKey string
+}
+
+// UserNotificationList 站内通知分页结果。
+type UserNotificationList struct {
+	Items       []*UserNotification
+	Total       int
+	UnreadCount int
+	Page        int
+	PageSize    int
+}
+
+// ExpiringAPIKey 即将到期的 API Key（仅包含生成通知所需字段）。
+type ExpiringAPIKey struct {
+	ID        int64
+	UserID    int64
+	Name      string
+	ExpiresAt time.Time
+}
+
+// UserNotificationRepository 站内通知持久化。
+type UserNotificationRepository interface {
+	// Create 写入通知；命中 (user_id, dedup_key) 去重时返回 created=false。
+	Create(ctx context.Context, input *UserNotificationInput) (created bool, err error)
+	List(ctx context.Context, userID int64, unreadOnly bool, page, pageSize int) (*UserNotificationList, error)
+	CountUnread(ctx context.Context, userID int64) (int, error)
+	MarkRead(ctx context.Context, userID, id int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	ListAPIKeysExpiringBetween(ctx context.Context, from, to time.Time) ([]ExpiringAPIKey, error)
+}
+
+// UserNotifier 由告警来源（余额、订阅、异步任务）调用以生成站内通知。
+// 实现必须是非阻塞且容错的：通知失败不能影响调用方的主流程。
+type UserNotifier interface {
+	NotifyAsync(input UserNotificationInput)
+}
+
+// UserNotificationService 终端用户站内通知服务。
+//
+// 通知由已有告警事件驱动生成（余额跌破阈值、订阅到期提醒、异步任务失败），
+// API Key 到期提醒与过期通知清理由 leader 实例周期执行。
+type UserNotificationService struct {
+	repo     UserNotificationRepository
+	cfg      config.UserNotificationConfig
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+// NewUserNotificationService 创建站内通知服务。
+func NewUserNotificationService(repo UserNotificationRepository, cfg *config.Config) *UserNotificationService {
+	s := &UserNotificationService{
+		repo:       repo,
+		stopCh:     make(chan struct{}),
+		instanceID: uuid.NewString(),
+		now:        time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.UserNotification
+		s.interval = time.Duration(cfg.UserNotification.ScanIntervalMinutes) * time.Minute
+	}
+	return s
+}
+
+// SetLeaderLock injects the leader-lock cache and DB so that only one instance
+// scans expiring API keys and prunes old notifications per cycle.
+func (s *UserNotificationService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Enabled 是否生成新的站内通知。
+func (s *UserNotificationService) Enabled() bool {
+	return s != nil && s.repo != nil && s.cfg.Enabled
+}
+
+// Notify 同步写入一条通知；未启用时静默忽略。
+func (s *UserNotificationService) Notify(ctx context.Context, input UserNotificationInput) error {
+	if !s.Enabled() {
+		return nil
+	}
+	if input.UserID <= 0 || strings.TrimSpace(input.Type) == "" || strings.TrimSpace(input.Title) == "" {
+		return fmt.Errorf("invalid user notification: user_id=%d type=%q", input.UserID, input.Type)
+	}
+	if input.Level == "" {
+		input.Level = UserNotificationLevelInfo
+	}
+	_, err := s.repo.Create(ctx, &input)
+	return err
+}
+
+// NotifyAsync 在后台写入通知，供计费、任务等热路径调用。
+func (s *UserNotificationService) NotifyAsync(input UserNotificationInput) {
+	if !s.Enabled() {
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.LegacyPrintf("service.user_notification", "[UserNotification] panic while notifying: %v", r)
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), userNotificationAsyncTimeout)
+		defer cancel()
+		if err := s.Notify(ctx, input); err != nil {
+			logger.LegacyPrintf("service.user_notification", "[UserNotification] create failed: user_id=%d type=%s err=%v", input.UserID, input.Type, err)
+		}
+	}()
+}
+
+// List 分页查询用户通知，同时返回未读数。
+func (s *UserNotificationService) List(ctx context.Context, userID int64, unreadOnly bool, page, pageSize int) (*UserNotificationList, error) {
+	return s.repo.List(ctx, userID, unreadOnly, page, pageSize)
+}
+
+// UnreadCount 返回用户未读通知数。
+func (s *UserNotificationService) UnreadCount(ctx context.Context, userID int64) (int, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+// MarkRead 标记单条通知为已读（仅限本人的通知）。
+func (s *UserNotificationService) MarkRead(ctx context.Context, userID, id int64) error {
+	return s.repo.MarkRead(ctx, userID, id)
+}
+
+// MarkAllRead 标记用户全部通知为已读，返回本次标记的条数。
+func (s *UserNotificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return s.repo.MarkAllRead(ctx, userID)
+}
+
+func (s *UserNotificationService) Start() {
+	if !s.Enabled() || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *UserNotificationService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *UserNotificationService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, userNotificationLeaderLockKey, s.instanceID, userNotificationLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	created, err := s.notifyExpiringAPIKeys(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.user_notification", "[UserNotification] scan expiring api keys failed: %v", err)
+	}
+	if created > 0 {
+		logger.LegacyPrintf("service.user_notification", "[UserNotification] created %d api key expiry notices", created)
+	}
+
+	if s.cfg.RetentionDays > 0 {
+		cutoff := s.now().AddDate(0, 0, -s.cfg.RetentionDays)
+		deleted, err := s.repo.DeleteBefore(ctx, cutoff)
+		if err != nil {
+			logger.LegacyPrintf("service.user_notification", "[UserNotification] prune failed: %v", err)
+		} else if deleted > 0 {
+			logger.LegacyPrintf("service.user_notification", "[UserNotification] pruned %d notifications older than %s", deleted, cutoff.Format(time.RFC3339))
+		}
+	}
+}
+
+// notifyExpiringAPIKeys 为提醒窗口内即将到期的 API Key 生成通知。
+// 去重键包含到期时间，因此延长有效期后会针对新的到期时间重新提醒。
+func (s *UserNotificationService) notifyExpiringAPIKeys(ctx context.Context) (int, error) {
+	if s.cfg.APIKeyExpiryNoticeHours <= 0 {
+		return 0, nil
+	}
+	now := s.now()
+	keys, err := s.repo.ListAPIKeysExpiringBetween(ctx, now, now.Add(time.Duration(s.cfg.APIKeyExpiryNoticeHours)*time.Hour))
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for _, key := range keys {
+		ok, err := s.repo.Create(ctx, buildAPIKeyExpiringNotification(key, now))
+		if err != nil {
+			logger.LegacyPrintf("service.user_notification", "[UserNotification] create api key expiry notice failed: api_key_id=%d err=%v", key.ID, err)
+			continue
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
+
+func buildAPIKeyExpiringNotification(key ExpiringAPIKey, now time.Time) *UserNotificationInput {
+	hours := int(key.ExpiresAt.Sub(now).Hours())
+	if hours < 1 {
+		hours = 1
+	}
+	return &UserNotificationInput{
+		UserID:  key.UserID,
+		Type:    UserNotificationTypeAPIKeyExpiring,
+		Level:   UserNotificationLevelWarning,
+		Title:   "API Key is about to expire",
+		Content: fmt.Sprintf("API key %q expires at %s (in about %d hours). Extend or replace it to avoid interruptions.", key.Name, key.ExpiresAt.UTC().Format("2006-01-02 15:04 UTC"), hours),
+		Metadata: map[string]any{
+			"api_key_id": key.ID,
+			"expires_at": key.ExpiresAt.UTC().Format(time.RFC3339),
+		},
+		DedupKey: fmt.Sprintf("api_key_expiring:%d:%d", key.ID, key.ExpiresAt.Unix()),
+	}
+}
@@ -0,0 +1,192 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type userNotificationRepoStub struct {
+	mu           sync.Mutex
+	created      []UserNotificationInput
+	dedup        map[string]bool
+	expiringKeys []ExpiringAPIKey
+	deleteCutoff time.Time
+}
+
+func (r *userNotificationRepoStub) Create(_ context.Context, input *UserNotificationInput) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if input.DedupKey != "" {
+		if r.dedup == nil {
+			r.dedup = map[string]bool{}
+		}
+		if r.dedup[input.DedupKey] {
+			return false, nil
+		}
+		r.dedup[input.DedupKey] = true
+	}
+	r.created = append(r.created, *input)
+	return true, nil
+}
+
+func (r *userNotificationRepoStub) List(context.Context, int64, bool, int, int) (*UserNotificationList, error) {
+	return &UserNotificationList{}, nil
+}
+
+func (r *userNotificationRepoStub) CountUnread(context.Context, int64) (int, error) {
+	return 0, nil
+}
+
+func (r *userNotificationRepoStub) MarkRead(context.Context, int64, int64) error {
+	return nil
+}
+
+func (r *userNotificationRepoStub) MarkAllRead(context.Context, int64) (int64, error) {
+	return 0, nil
+}
+
+func (r *userNotificationRepoStub) DeleteBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	r.deleteCutoff = cutoff
+	return 0, nil
+}
+
+func (r *userNotificationRepoStub) ListAPIKeysExpiringBetween(context.Context, time.Time, time.Time) ([]ExpiringAPIKey, error) {
+	return r.expiringKeys, nil
+}
+
+// userNotifierRecorder 同步记录通知，便于断言告警来源的调用。
+type userNotifierRecorder struct {
+	mu    sync.Mutex
+	items []UserNotificationInput
+}
+
+func (r *userNotifierRecorder) NotifyAsync(input UserNotificationInput) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = append(r.items, input)
+}
+
+func newUserNotificationServiceForTest(repo *userNotificationRepoStub, enabled bool) *UserNotificationService {
+	return NewUserNotificationService(repo, &config.Config{UserNotification: config.UserNotificationConfig{
+		Enabled:                 enabled,
+		RetentionDays:           30,
+		APIKeyExpiryNoticeHours: 72,
+		ScanIntervalMinutes:     60,
+	}})
+}
+
+func TestUserNotificationService_NotifyDisabledIsNoop(t *testing.T) {
+	repo := &userNotificationRepoStub{}
+	svc := newUserNotificationServiceForTest(repo, false)
+
+	require.NoError(t, svc.Notify(context.Background(), UserNotificationInput{UserID: 1, Type: UserNotificationTypeBalanceLow, Title: "x"}))
+	require.Empty(t, repo.created)
+
+	var nilSvc *UserNotificationService
+	nilSvc.NotifyAsync(UserNotificationInput{UserID: 1})
+}
+
+func TestUserNotificationService_NotifyValidatesAndDefaultsLevel(t *testing.T) {
+	repo := &userNotificationRepoStub{}
+	svc := newUserNotificationServiceForTest(repo, true)
+
+	require.Error(t, svc.Notify(context.Background(), UserNotificationInput{UserID: 0, Type: UserNotificationTypeBalanceLow, Title: "x"}))
+	require.Error(t, svc.Notify(context.Background(), UserNotificationInput{UserID: 1, Type: UserNotificationTypeBalanceLow}))
+
+	require.NoError(t, svc.Notify(context.Background(), UserNotificationInput{UserID: 1, Type: UserNotificationTypeBalanceLow, Title: "x"}))
+	require.Len(t, repo.created, 1)
+	require.Equal(t, UserNotificationLevelInfo, repo.created[0].Level)
+}
+
+func TestUserNotificationService_RunOnceNotifiesExpiringKeysOnceAndPrunes(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(48 * time.Hour)
+	repo := &userNotificationRepoStub{expiringKeys: []ExpiringAPIKey{{ID: 12, UserID: 3, Name: "prod", ExpiresAt: expiresAt}}}
+	svc := newUserNotificationServiceForTest(repo, true)
+	svc.now = func() time.Time { return now }
+
+	svc.runOnce()
+	svc.runOnce()
+
+	require.Len(t, repo.created, 1, "the same expiry must only be announced once")
+	notice := repo.created[0]
+	require.Equal(t, int64(3), notice.UserID)
+	require.Equal(t, UserNotificationTypeAPIKeyExpiring, notice.Type)
+	require.Equal(t, "api_key_expiring:12:1772539200", notice.DedupKey)
+	require.Contains(t, notice.Content, "in about 48 hours")
+	require.Equal(t, now.AddDate(0, 0, -30), repo.deleteCutoff)
+
+	// 延长有效期后针对新的到期时间重新提醒
+	repo.expiringKeys[0].ExpiresAt = expiresAt.Add(24 * time.Hour)
+	svc.runOnce()
+	require.Len(t, repo.created, 2)
+}
+
+func TestCheckBalanceAfterDeduction_InAppNotificationIndependentOfEmailToggle(t *testing.T) {
+	repo := newMockSettingRepo()
+	repo.data[SettingKeyBalanceLowNotifyEnabled] = "true"
+	repo.data[SettingKeyBalanceLowNotifyThreshold] = "10"
+	recorder := &userNotifierRecorder{}
+	s := NewBalanceNotifyService(nil, repo, nil)
+	s.SetUserNotifier(recorder)
+	u := &User{ID: 5, BalanceNotifyEnabled: false}
+
+	s.CheckBalanceAfterDeduction(context.Background(), u, 100, 5)
+	require.Empty(t, recorder.items, "no crossing, no notice")
+
+	s.CheckBalanceAfterDeduction(context.Background(), u, 12, 4)
+	require.Len(t, recorder.items, 1)
+	require.Equal(t, int64(5), recorder.items[0].UserID)
+	require.Equal(t, UserNotificationTypeBalanceLow, recorder.items[0].Type)
+	require.Equal(t, 8.0, recorder.items[0].Metadata["balance"])
+
+	repo.data[SettingKeyBalanceLowNotifyEnabled] = "false"
+	s.CheckBalanceAfterDeduction(context.Background(), u, 12, 4)
+	require.Len(t, recorder.items, 1, "global switch also gates the in-app notice")
+}
+
+func TestSubscriptionExpiryReminder_NotifiesInAppWithoutEmailService(t *testing.T) {
+	recorder := &userNotifierRecorder{}
+	svc := NewSubscriptionExpiryService(nil, time.Minute)
+	svc.SetUserNotifier(recorder)
+	sub := &UserSubscription{
+		ID:        21,
+		UserID:    4,
+		GroupID:   2,
+		ExpiresAt: time.Now().Add(3*24*time.Hour - time.Minute),
+		User:      &User{ID: 4},
+		Group:     &Group{ID: 2, Name: "Pro"},
+	}
+
+	svc.sendExpiryReminderIfDue(context.Background(), sub)
+
+	require.Len(t, recorder.items, 1)
+	require.Equal(t, UserNotificationTypeSubscriptionExpiring, recorder.items[0].Type)
+	require.Contains(t, recorder.items[0].DedupKey, "subscription_expiring:21:")
+	require.Equal(t, sub.DaysRemaining(), recorder.items[0].Metadata["days_remaining"])
+}
+
+func TestImageTaskFail_NotifiesTaskOwner(t *testing.T) {
+	store := &imageTaskMemoryStore{}
+	recorder := &userNotifierRecorder{}
+	svc := NewImageTaskServiceWithOptions(store, time.Hour, time.Minute)
+	svc.SetUserNotifier(recorder)
+	task, err := svc.Create(context.Background(), ImageTaskOwner{UserID: 7, APIKeyID: 9})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Fail(context.Background(), task.ID, http.StatusBadGateway, json.RawMessage(`{"error":{"message":"upstream"}}`)))
+
+	require.Len(t, recorder.items, 1)
+	require.Equal(t, int64(7), recorder.items[0].UserID)
+	require.Equal(t, UserNotificationTypeTaskFailed, recorder.items[0].Type)
+	require.Equal(t, "image_task_failed:"+task.ID, recorder.items[0].DedupKey)
+}
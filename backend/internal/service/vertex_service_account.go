@@ -169,7 +169,9 @@ func getVertexServiceAccountAccessToken(ctx context.Context, cache GeminiTokenCa
 		} else if lockErr != nil {
 			slog.Warn("vertex_service_account_token_lock_failed", "account_id", account.ID, "error", lockErr)
 		} else {
-			time.Sleep(vertexLockWaitTime)
+			if err := sleepWithContext(ctx, vertexLockWaitTime); err != nil {
+				return "", err
+			}
 			if token, err := cache.GetAccessToken(ctx, cacheKey); err == nil && strings.TrimSpace(token) != "" {
 				return token, nil
 			}
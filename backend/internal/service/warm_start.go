@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// WarmStartRepository 启动预热所需的数据查询
+type WarmStartRepository interface {
+	// ListRecentlyUsedAPIKeys 返回 since 之后使用过的有效 API Key，按最近使用时间倒序，最多 limit 个
+	ListRecentlyUsedAPIKeys(ctx context.Context, since time.Time, limit int) ([]string, error)
+}
+
+// WarmStartResult 一次启动预热的结果
+type WarmStartResult struct {
+	APIKeysWarmed     int
+	SettingsWarmed    bool
+	SchedulerWarmed   bool
+	DurationMs        int64
+	TimedOut          bool
+	FailedStepReasons map[string]string
+}
+
+// WarmStartService 启动预热服务
+//
+// 在 HTTP 服务开始监听前同步预热热点数据：网关运行时设置缓存、最近使用过的 API Key 认证缓存，
+// 并等待调度账号快照的启动重建完成，降低发布后第一分钟的延迟与错误尖峰。
+// 定价表在服务构造时已同步加载，这里不再重复。预热失败只记录日志，不阻止启动。
+type WarmStartService struct {
+	repo              WarmStartRepository
+	apiKeyService     *APIKeyService
+	settingService    *SettingService
+	schedulerSnapshot *SchedulerSnapshotService
+	cfg               *config.Config
+}
+
+// NewWarmStartService 创建启动预热服务
+func NewWarmStartService(
+	repo WarmStartRepository,
+	apiKeyService *APIKeyService,
+	settingService *SettingService,
+	schedulerSnapshot *SchedulerSnapshotService,
+	cfg *config.Config,
+) *WarmStartService {
+	return &WarmStartService{
+		repo:              repo,
+		apiKeyService:     apiKeyService,
+		settingService:    settingService,
+		schedulerSnapshot: schedulerSnapshot,
+		cfg:               cfg,
+	}
+}
+
+// Enabled 是否启用启动预热
+func (s *WarmStartService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.WarmStart.Enabled
+}
+
+// Run 执行一次启动预热并记录日志，总耗时受 warm_start.timeout_seconds 限制。
+func (s *WarmStartService) Run(ctx context.Context) *WarmStartResult {
+	if !s.Enabled() {
+		return nil
+	}
+	warmCfg := s.cfg.WarmStart
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(warmCfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	result := &WarmStartResult{FailedStepReasons: map[string]string{}}
+
+	if s.settingService != nil {
+		s.settingService.WarmGatewayRuntimeSettings(runCtx)
+		result.SettingsWarmed = runCtx.Err() == nil
+	}
+
+	if s.apiKeyService != nil && s.repo != nil && warmCfg.APIKeyLimit > 0 {
+		since := start.Add(-time.Duration(warmCfg.APIKeyLookbackHours) * time.Hour)
+		keys, err := s.repo.ListRecentlyUsedAPIKeys(runCtx, since, warmCfg.APIKeyLimit)
+		if err != nil {
+			result.FailedStepReasons["api_keys"] = err.Error()
+		} else {
+			warmed, err := s.apiKeyService.WarmAuthCache(runCtx, keys)
+			result.APIKeysWarmed = warmed
+			if err != nil {
+				result.FailedStepReasons["api_keys"] = err.Error()
+			}
+		}
+	}
+
+	if s.schedulerSnapshot != nil {
+		if err := s.schedulerSnapshot.WaitInitialRebuild(runCtx); err != nil {
+			result.FailedStepReasons["scheduler_snapshot"] = err.Error()
+		} else {
+			result.SchedulerWarmed = true
+		}
+	}
+
+	result.TimedOut = runCtx.Err() != nil
+	result.DurationMs = time.Since(start).Milliseconds()
+	s.logResult(result)
+	return result
+}
+
+func (s *WarmStartService) logResult(result *WarmStartResult) {
+	fields := []zap.Field{
+		zap.String("component", "service.warm_start"),
+		zap.Int("api_keys_warmed", result.APIKeysWarmed),
+		zap.Bool("settings_warmed", result.SettingsWarmed),
+		zap.Bool("scheduler_warmed", result.SchedulerWarmed),
+		zap.Int64("duration_ms", result.DurationMs),
+		zap.Bool("timed_out", result.TimedOut),
+	}
+	if len(result.FailedStepReasons) > 0 {
+		fields = append(fields, zap.Any("failures", result.FailedStepReasons))
+		logger.L().Warn("warm start completed with failures", fields...)
+		return
+	}
+	logger.L().Info("warm start completed", fields...)
+}
@@ -0,0 +1,94 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+type warmStartRepoStub struct {
+	keys  []string
+	since time.Time
+	limit int
+}
+
+func (s *warmStartRepoStub) ListRecentlyUsedAPIKeys(ctx context.Context, since time.Time, limit int) ([]string, error) {
+	s.since = since
+	s.limit = limit
+	return s.keys, nil
+}
+
+func TestWarmStartService_WarmsRecentlyUsedAPIKeys(t *testing.T) {
+	cache := &authCacheStub{
+		getAuthCache: func(ctx context.Context, key string) (*APIKeyAuthCacheEntry, error) {
+			return nil, redis.Nil
+		},
+	}
+	repo := &authRepoStub{
+		getByKeyForAuth: func(ctx context.Context, key string) (*APIKey, error) {
+			if key == "gone" {
+				return nil, ErrAPIKeyNotFound
+			}
+			return &APIKey{
+				ID:     5,
+				UserID: 7,
+				Status: StatusActive,
+				User:   &User{ID: 7, Status: StatusActive, Role: RoleUser, Concurrency: 1},
+			}, nil
+		},
+	}
+	cfg := &config.Config{
+		APIKeyAuth: config.APIKeyAuthCacheConfig{L2TTLSeconds: 60},
+		WarmStart: config.WarmStartConfig{
+			Enabled:             true,
+			TimeoutSeconds:      5,
+			APIKeyLimit:         10,
+			APIKeyLookbackHours: 24,
+		},
+	}
+	apiKeyService := NewAPIKeyService(repo, nil, nil, nil, nil, cache, cfg)
+	warmRepo := &warmStartRepoStub{keys: []string{"k1", "gone", "k3"}}
+	svc := NewWarmStartService(warmRepo, apiKeyService, nil, nil, cfg)
+
+	result := svc.Run(context.Background())
+	require.NotNil(t, result)
+	require.Equal(t, 2, result.APIKeysWarmed)
+	require.Empty(t, result.FailedStepReasons)
+	require.Equal(t, 10, warmRepo.limit)
+	require.WithinDuration(t, time.Now().Add(-24*time.Hour), warmRepo.since, time.Minute)
+	require.Len(t, cache.setAuthKeys, 2)
+
+	// 已在缓存中的 Key 不再回源
+	cache.getAuthCache = func(ctx context.Context, key string) (*APIKeyAuthCacheEntry, error) {
+		return &APIKeyAuthCacheEntry{Snapshot: &APIKeyAuthSnapshot{
+			Version:  apiKeyAuthSnapshotVersion,
+			APIKeyID: 5,
+			UserID:   7,
+			Status:   StatusActive,
+			User:     APIKeyAuthUserSnapshot{ID: 7, Status: StatusActive, Role: RoleUser, Concurrency: 1},
+		}}, nil
+	}
+	repo.getByKeyForAuth = func(ctx context.Context, key string) (*APIKey, error) {
+		t.Fatalf("unexpected repo call for %s", key)
+		return nil, nil
+	}
+	warmed, err := apiKeyService.WarmAuthCache(context.Background(), []string{"k1"})
+	require.NoError(t, err)
+	require.Equal(t, 1, warmed)
+}
+
+func TestWarmStartService_Disabled(t *testing.T) {
+	svc := NewWarmStartService(&warmStartRepoStub{}, nil, nil, nil, &config.Config{})
+	require.Nil(t, svc.Run(context.Background()))
+}
+
+func TestSchedulerSnapshotService_WaitInitialRebuildWithoutCache(t *testing.T) {
+	svc := NewSchedulerSnapshotService(nil, nil, nil, nil, &config.Config{})
+	require.NoError(t, svc.WaitInitialRebuild(context.Background()))
+}
@@ -83,6 +83,7 @@ func ProvideTokenRefreshService(
 	proxyRepo ProxyRepository,
 	refreshAPI *OAuthRefreshAPI,
 	runtimeBlocker AccountRuntimeBlocker,
+	featureFlags *FeatureFlagService,
 ) *TokenRefreshService {
 	svc := NewTokenRefreshService(accountRepo, oauthService, openaiOAuthService, geminiOAuthService, antigravityOAuthService, cacheInvalidator, schedulerCache, cfg, tempUnschedCache, grokOAuthService)
 	// 注入 OpenAI privacy opt-out 依赖
@@ -92,6 +93,7 @@ func ProvideTokenRefreshService(
 	// 调用侧显式注入后台刷新策略，避免策略漂移
 	svc.SetRefreshPolicy(DefaultBackgroundRefreshPolicy())
 	svc.SetAccountRuntimeBlocker(runtimeBlocker)
+	svc.SetFeatureFlags(featureFlags)
 	svc.Start()
 	return svc
 }
@@ -212,11 +214,16 @@ func ProvideGeminiTokenProvider(
 	tokenCache GeminiTokenCache,
 	geminiOAuthService *GeminiOAuthService,
 	refreshAPI *OAuthRefreshAPI,
+	cfg *config.Config,
 ) *GeminiTokenProvider {
 	p := NewGeminiTokenProvider(accountRepo, tokenCache, geminiOAuthService)
 	executor := NewGeminiTokenRefresher(geminiOAuthService)
 	p.SetRefreshAPI(refreshAPI, executor)
 	p.SetRefreshPolicy(GeminiProviderRefreshPolicy())
+	if geminiOAuthService != nil {
+		interval := time.Duration(cfg.Gemini.Quota.ProbeIntervalMinutes) * time.Minute
+		p.SetQuotaProber(NewGeminiQuotaProber(accountRepo, geminiOAuthService.proxyRepo, geminiOAuthService.codeAssist, interval))
+	}
 	return p
 }
 
@@ -274,6 +281,109 @@ func ProvideAccountExpiryService(accountRepo AccountRepository) *AccountExpirySe
 	return svc
 }
 
+// ProvideAccountRenewalReminderService creates and starts AccountRenewalReminderService.
+func ProvideAccountRenewalReminderService(metadataRepo AccountMetadataRepository, opsRepo OpsRepository, lockCache LeaderLockCache, db *sql.DB, cfg *config.Config) *AccountRenewalReminderService {
+	svc := NewAccountRenewalReminderService(metadataRepo, opsRepo, cfg.AccountRenewal.ReminderDays, time.Duration(cfg.AccountRenewal.CheckIntervalMinutes)*time.Minute)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideUserNotificationService creates UserNotificationService and starts the
+// API key expiry scan / retention pruning loop.
+func ProvideUserNotificationService(repo UserNotificationRepository, lockCache LeaderLockCache, db *sql.DB, cfg *config.Config) *UserNotificationService {
+	svc := NewUserNotificationService(repo, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideModelAliasLearningService creates ModelAliasLearningService, wires it
+// into rate-limit handling (observations) and channel mapping (approved
+// rewrites), and starts the flush loop.
+func ProvideModelAliasLearningService(repo ModelAliasRepository, rateLimitService *RateLimitService, channelService *ChannelService, cfg *config.Config) *ModelAliasLearningService {
+	svc := NewModelAliasLearningService(repo, cfg)
+	rateLimitService.SetModelAliasObserver(svc)
+	channelService.SetModelAliasResolver(svc)
+	svc.Start()
+	return svc
+}
+
+// ProvideContentPolicyCooldownService creates ContentPolicyCooldownService from
+// gateway.content_policy_cooldown and wires it into violation recording
+// (rate-limit handling) and account selection (gateway schedulers).
+func ProvideContentPolicyCooldownService(
+	cfg *config.Config,
+	cache ContentPolicyCooldownCache,
+	rateLimitService *RateLimitService,
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+	geminiCompatService *GeminiMessagesCompatService,
+) *ContentPolicyCooldownService {
+	svc := NewContentPolicyCooldownService(cfg, cache)
+	rateLimitService.SetContentPolicyCooldownService(svc)
+	gatewayService.SetContentPolicyCooldownService(svc)
+	openAIGatewayService.SetContentPolicyCooldownService(svc)
+	geminiCompatService.SetContentPolicyCooldownService(svc)
+	return svc
+}
+
+// ProvideAccountCostAnomalyService creates and starts AccountCostAnomalyService.
+func ProvideAccountCostAnomalyService(repo AccountCostAnomalyRepository, accountRepo AccountRepository, opsRepo OpsRepository, lockCache LeaderLockCache, db *sql.DB, cfg *config.Config) *AccountCostAnomalyService {
+	svc := NewAccountCostAnomalyService(repo, accountRepo, opsRepo, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideAccountSmokeTestService creates and starts AccountSmokeTestService.
+func ProvideAccountSmokeTestService(repo AccountSmokeTestRepository, accountRepo AccountRepository, accountTestSvc *AccountTestService, opsRepo OpsRepository, lockCache LeaderLockCache, db *sql.DB, cfg *config.Config) *AccountSmokeTestService {
+	svc := NewAccountSmokeTestService(repo, accountRepo, accountTestSvc, opsRepo, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideActiveRequestRegistry creates the in-flight gateway request registry.
+func ProvideActiveRequestRegistry(cfg *config.Config) *ActiveRequestRegistry {
+	registry := NewActiveRequestRegistry(time.Duration(cfg.Gateway.ClientDisconnectUpstreamGraceSeconds) * time.Second)
+	registry.SetAdmissionController(NewRequestAdmissionController(cfg.Gateway.AdmissionControl, registry))
+	return registry
+}
+
+// ProvideAccountSnapshotService creates and starts AccountSnapshotService.
+func ProvideAccountSnapshotService(repo AccountSnapshotRepository, accountRepo AccountRepository, lockCache LeaderLockCache, db *sql.DB, cfg *config.Config) *AccountSnapshotService {
+	svc := NewAccountSnapshotService(repo, accountRepo, time.Duration(cfg.AccountSnapshot.IntervalMinutes)*time.Minute, time.Duration(cfg.AccountSnapshot.RetentionDays)*24*time.Hour)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideAccountCredentialSyncService creates and starts AccountCredentialSyncService.
+func ProvideAccountCredentialSyncService(
+	repo AccountCredentialSourceRepository,
+	accountRepo AccountRepository,
+	fetcher CredentialSecretFetcher,
+	cacheInvalidator TokenCacheInvalidator,
+	lockCache LeaderLockCache,
+	db *sql.DB,
+	cfg *config.Config,
+) *AccountCredentialSyncService {
+	svc := NewAccountCredentialSyncService(repo, accountRepo, fetcher, cacheInvalidator, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideGroupBudgetService creates and starts GroupBudgetService, and attaches it to the gateway schedulers.
+func ProvideGroupBudgetService(repo GroupBudgetRepository, groupRepo GroupRepository, opsRepo OpsRepository, gatewayService *GatewayService, openAIGatewayService *OpenAIGatewayService) *GroupBudgetService {
+	svc := NewGroupBudgetService(repo, groupRepo, opsRepo, 5*time.Minute)
+	gatewayService.groupBudget = svc
+	openAIGatewayService.groupBudget = svc
+	svc.Start()
+	return svc
+}
+
 // ProvideProxyExpiryService creates and starts ProxyExpiryService.
 func ProvideProxyExpiryService(proxyRepo ProxyRepository) *ProxyExpiryService {
 	svc := NewProxyExpiryService(proxyRepo, time.Minute)
@@ -281,11 +391,19 @@ func ProvideProxyExpiryService(proxyRepo ProxyRepository) *ProxyExpiryService {
 	return svc
 }
 
+// ProvideProxyLatencyRouter creates and starts ProxyLatencyRouter.
+func ProvideProxyLatencyRouter(accountRepo AccountRepository, proxyRepo ProxyRepository, prober ProxyHostLatencyProber, cfg *config.Config) *ProxyLatencyRouter {
+	svc := NewProxyLatencyRouter(accountRepo, proxyRepo, prober, cfg)
+	svc.Start()
+	return svc
+}
+
 // ProvideSubscriptionExpiryService creates and starts SubscriptionExpiryService.
-func ProvideSubscriptionExpiryService(userSubRepo UserSubscriptionRepository, settingRepo SettingRepository, notificationEmailService *NotificationEmailService, lockCache LeaderLockCache, db *sql.DB) *SubscriptionExpiryService {
+func ProvideSubscriptionExpiryService(userSubRepo UserSubscriptionRepository, settingRepo SettingRepository, notificationEmailService *NotificationEmailService, userNotificationService *UserNotificationService, lockCache LeaderLockCache, db *sql.DB) *SubscriptionExpiryService {
 	svc := NewSubscriptionExpiryService(userSubRepo, time.Minute)
 	svc.SetSettingRepository(settingRepo)
 	svc.SetNotificationEmailService(notificationEmailService)
+	svc.SetUserNotifier(userNotificationService)
 	svc.SetLeaderLock(lockCache, db)
 	svc.Start()
 	return svc
@@ -317,6 +435,9 @@ func ProvideConcurrencyService(cache ConcurrencyCache, accountRepo AccountReposi
 	if cfg != nil {
 		svc.SetAccountLoadBatchCacheTTL(time.Duration(cfg.Gateway.Scheduling.LoadBatchCacheTTLMS) * time.Millisecond)
 		svc.StartSlotCleanupWorker(accountRepo, cfg.Gateway.Scheduling.SlotCleanupInterval)
+		if cfg.Gateway.SlotLease.Enabled {
+			svc.EnableSlotLeaseHeartbeat(time.Duration(cfg.Gateway.SlotLease.HeartbeatIntervalSeconds) * time.Second)
+		}
 	}
 	return svc
 }
@@ -370,7 +491,7 @@ func ProvideOpsMetricsCollector(
 	accountRepo AccountRepository,
 	concurrencyService *ConcurrencyService,
 	db *sql.DB,
-	redisClient *redis.Client,
+	redisClient redis.UniversalClient,
 	cfg *config.Config,
 ) *OpsMetricsCollector {
 	collector := NewOpsMetricsCollector(opsRepo, settingRepo, accountRepo, concurrencyService, db, redisClient, cfg)
@@ -383,7 +504,7 @@ func ProvideOpsAggregationService(
 	opsRepo OpsRepository,
 	settingRepo SettingRepository,
 	db *sql.DB,
-	redisClient *redis.Client,
+	redisClient redis.UniversalClient,
 	cfg *config.Config,
 ) *OpsAggregationService {
 	svc := NewOpsAggregationService(opsRepo, settingRepo, db, redisClient, cfg)
@@ -396,7 +517,7 @@ func ProvideOpsAlertEvaluatorService(
 	opsService *OpsService,
 	opsRepo OpsRepository,
 	emailService *EmailService,
-	redisClient *redis.Client,
+	redisClient redis.UniversalClient,
 	cfg *config.Config,
 	proxyRepo ProxyRepository,
 ) *OpsAlertEvaluatorService {
@@ -413,7 +534,7 @@ func ProvideOpsAlertEvaluatorService(
 func ProvideOpsCleanupService(
 	opsRepo OpsRepository,
 	db *sql.DB,
-	redisClient *redis.Client,
+	redisClient redis.UniversalClient,
 	cfg *config.Config,
 	channelMonitorSvc *ChannelMonitorService,
 	settingRepo SettingRepository,
@@ -442,6 +563,31 @@ func ProvideAuditLogService(repo AuditLogRepository, settingService *SettingServ
 	return svc
 }
 
+// ProvideConversationTranscriptService 创建会话全文留存服务并启动异步写入与保留期清理协程。
+// 停止逻辑挂在 cmd/server 的 provideCleanup。
+func ProvideConversationTranscriptService(repo ConversationTranscriptRepository, encryptor SecretEncryptor, cfg *config.Config) *ConversationTranscriptService {
+	svc := NewConversationTranscriptService(repo, encryptor, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideUpstreamStreamCaptureService 创建上游原始响应留存服务并启动异步上传与保留期清理协程。
+// 停止逻辑挂在 cmd/server 的 provideCleanup。
+func ProvideUpstreamStreamCaptureService(repo UpstreamStreamCaptureRepository, backup *BackupService, cfg *config.Config) *UpstreamStreamCaptureService {
+	svc := NewUpstreamStreamCaptureService(repo, backup, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideUsageArchiveService 创建使用记录归档服务并启动定时归档协程。
+// 停止逻辑挂在 cmd/server 的 provideCleanup。
+func ProvideUsageArchiveService(repo UsageLogArchiveRepository, backup *BackupService, lockCache LeaderLockCache, db *sql.DB, cfg *config.Config) *UsageArchiveService {
+	svc := NewUsageArchiveService(repo, backup, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
 func buildIdempotencyConfig(cfg *config.Config) IdempotencyConfig {
 	idempotencyCfg := DefaultIdempotencyConfig()
 	if cfg != nil {
@@ -481,6 +627,37 @@ func ProvideIdempotencyCleanupService(repo IdempotencyRepository, cfg *config.Co
 	return svc
 }
 
+// ProvideDatabaseHealthMonitor 创建数据库健康监控，注册为全局实例并启动探测。
+func ProvideDatabaseHealthMonitor(pinger StartupDiagnosticsRepository, cfg *config.Config) *DatabaseHealthMonitor {
+	m := NewDatabaseHealthMonitor(pinger, cfg)
+	SetDefaultDatabaseHealthMonitor(m)
+	m.Start()
+	return m
+}
+
+// ProvideRedisHealthMonitor 创建 Redis 健康监控，注册为全局实例并启动探测。
+func ProvideRedisHealthMonitor(probe RedisProbe, cfg *config.Config) *RedisHealthMonitor {
+	m := NewRedisHealthMonitor(probe, cfg)
+	SetDefaultRedisHealthMonitor(m)
+	m.Start()
+	return m
+}
+
+// ProvideUsageRecordDeadLetterService 创建使用量记录死信队列，注册为全局实例并启动回放 worker。
+func ProvideUsageRecordDeadLetterService(
+	cache UsageRecordDeadLetterCache,
+	billingRepo UsageBillingRepository,
+	usageLogRepo UsageLogRepository,
+	billingCache *BillingCacheService,
+	db *sql.DB,
+	cfg *config.Config,
+) *UsageRecordDeadLetterService {
+	svc := NewUsageRecordDeadLetterService(cache, billingRepo, usageLogRepo, billingCache, db, cfg)
+	SetDefaultUsageRecordDeadLetterService(svc)
+	svc.Start()
+	return svc
+}
+
 // ProvideScheduledTestService creates ScheduledTestService.
 func ProvideScheduledTestService(
 	planRepo ScheduledTestPlanRepository,
@@ -507,7 +684,7 @@ func ProvideOpsScheduledReportService(
 	opsService *OpsService,
 	userService *UserService,
 	emailService *EmailService,
-	redisClient *redis.Client,
+	redisClient redis.UniversalClient,
 	cfg *config.Config,
 ) *OpsScheduledReportService {
 	svc := NewOpsScheduledReportService(opsService, userService, emailService, redisClient, cfg)
@@ -547,8 +724,10 @@ func ProvideImageStorageSettingService(
 // 对象存储是异步图片任务的启用前提：仅当开关打开且凭证齐全时功能才可用，否则整体禁用
 // （handler 返回 404，不创建任务、不写 Redis），从而避免大 base64 结果撑爆 Redis。
 // 启用状态由 settings 服务在运行时解析，因此后台改开关后无需重启即可生效。
-func ProvideImageTaskService(store ImageTaskStore, settings *ImageStorageSettingService) *ImageTaskService {
-	return NewImageTaskServiceWithResolver(store, settings.Resolver(), defaultImageTaskTTL, defaultImageTaskExecutionTimeout)
+func ProvideImageTaskService(store ImageTaskStore, settings *ImageStorageSettingService, userNotificationService *UserNotificationService) *ImageTaskService {
+	svc := NewImageTaskServiceWithResolver(store, settings.Resolver(), defaultImageTaskTTL, defaultImageTaskExecutionTimeout)
+	svc.SetUserNotifier(userNotificationService)
+	return svc
 }
 
 // ProvideBackupService creates and starts BackupService
@@ -583,6 +762,7 @@ func ProvideOpsService(
 	settingService *SettingService,
 	authCacheInvalidationWorker *AuthCacheInvalidationWorker,
 	apiKeyService *APIKeyService,
+	usageRecordDeadLetter *UsageRecordDeadLetterService,
 ) *OpsService {
 	svc := NewOpsService(
 		opsRepo,
@@ -605,6 +785,7 @@ func ProvideOpsService(
 	}
 	svc.authCacheInvalidationWorker = authCacheInvalidationWorker
 	svc.apiKeyService = apiKeyService
+	svc.usageRecordDeadLetter = usageRecordDeadLetter
 	svc.StartRuntimeSettingsRefresh(context.Background())
 	return svc
 }
@@ -725,13 +906,25 @@ var ProviderSet = wire.NewSet(
 	ProvideAccountUsageService,
 	ProvideAccountTestService,
 	ProvideUpstreamBillingProbeService,
+	NewAdminListVersionService,
+	NewUsageRecostService,
+	NewAccountAttributionService,
+	NewAccountWarmupPolicy,
+	ProvideContentPolicyCooldownService,
 	ProvideSettingService,
+	NewFeatureFlagService,
 	NewDataManagementService,
 	ProvideBackupService,
 	ProvideOpsSystemLogSink,
 	ProvideOpsService,
 	ProvideOpsIngressRejectAggregator,
 	ProvideAuditLogService,
+	ProvideConversationTranscriptService,
+	ProvideUpstreamStreamCaptureService,
+	ProvideUsageArchiveService,
+	NewTaskHistoryService,
+	NewBillingAdjustmentService,
+	NewAccountMetadataService,
 	ProvideOpsMetricsCollector,
 	ProvideOpsAggregationService,
 	ProvideOpsAlertEvaluatorService,
@@ -739,6 +932,7 @@ var ProviderSet = wire.NewSet(
 	ProvideOpsScheduledReportService,
 	NewEmailService,
 	NewNotificationEmailService,
+	NewAPIKeyImportService,
 	ProvideEmailQueueService,
 	NewTurnstileService,
 	NewSubscriptionService,
@@ -746,6 +940,16 @@ var ProviderSet = wire.NewSet(
 	ProvideConcurrencyService,
 	ProvideUserMessageQueueService,
 	NewUsageRecordWorkerPool,
+	ProvideUsageRecordDeadLetterService,
+	ProvideDatabaseHealthMonitor,
+	ProvideRedisHealthMonitor,
+	NewStartupDiagnosticsService,
+	NewWarmStartService,
+	NewSupportBundleService,
+	NewRequestMirrorService,
+	NewSSEResumeService,
+	ProvideActiveRequestRegistry,
+	NewAutoscalingSignalsService,
 	ProvideSchedulerSnapshotService,
 	NewIdentityService,
 	NewCRSSyncService,
@@ -753,7 +957,18 @@ var ProviderSet = wire.NewSet(
 	ProvideTokenRefreshService,
 	wire.Bind(new(GrokOAuthReconciler), new(*TokenRefreshService)),
 	ProvideAccountExpiryService,
+	ProvideAccountRenewalReminderService,
+	ProvideAccountSnapshotService,
+	ProvideAccountCredentialSyncService,
+	NewSettingGovernanceService,
+	ProvideUserNotificationService,
+	ProvideModelAliasLearningService,
+	ProvideAccountCostAnomalyService,
+	ProvideAccountSmokeTestService,
+	ProvideGroupBudgetService,
 	ProvideProxyExpiryService,
+	ProvideProxyLatencyRouter,
+	NewUpstreamMTLSService,
 	ProvideSubscriptionExpiryService,
 	ProvideTimingWheelService,
 	ProvideDashboardAggregationService,
@@ -801,9 +1016,10 @@ func ProvidePaymentConfigService(entClient *dbent.Client, settingRepo SettingRep
 }
 
 // ProvideBalanceNotifyService creates BalanceNotifyService
-func ProvideBalanceNotifyService(emailService *EmailService, settingRepo SettingRepository, accountRepo AccountRepository, notificationEmailService *NotificationEmailService) *BalanceNotifyService {
+func ProvideBalanceNotifyService(emailService *EmailService, settingRepo SettingRepository, accountRepo AccountRepository, notificationEmailService *NotificationEmailService, userNotificationService *UserNotificationService) *BalanceNotifyService {
 	svc := NewBalanceNotifyService(emailService, settingRepo, accountRepo)
 	svc.SetNotificationEmailService(notificationEmailService)
+	svc.SetUserNotifier(userNotificationService)
 	return svc
 }
 
@@ -815,9 +1031,10 @@ func ProvidePaymentService(entClient *dbent.Client, registry *payment.Registry,
 }
 
 // ProvidePaymentOrderExpiryService creates and starts PaymentOrderExpiryService.
-func ProvidePaymentOrderExpiryService(paymentSvc *PaymentService, lockCache LeaderLockCache, db *sql.DB) *PaymentOrderExpiryService {
+func ProvidePaymentOrderExpiryService(paymentSvc *PaymentService, lockCache LeaderLockCache, db *sql.DB, featureFlags *FeatureFlagService) *PaymentOrderExpiryService {
 	svc := NewPaymentOrderExpiryService(paymentSvc, 60*time.Second)
 	svc.SetLeaderLock(lockCache, db)
+	svc.SetFeatureFlags(featureFlags)
 	svc.Start()
 	return svc
 }
@@ -0,0 +1,33 @@
+-- 会话全文留存（按 API Key 单独开启）
+-- 与脱敏后的审计日志不同，这里保存完整的请求消息与最终响应，用于合规与排障。
+-- 设计约束：
+--   1. request_payload / response_payload 为 AES-GCM 密文（base64），明文不落库
+--   2. 仅 Key 所属用户可查询；按 gateway.conversation_transcript.retention_days 定期清理
+--   3. 删除 API Key 时不级联删除，留存记录随保留期自然过期
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS transcript_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS conversation_transcripts (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    user_id BIGINT NOT NULL,
+    api_key_id BIGINT NOT NULL,
+    group_id BIGINT,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    endpoint VARCHAR(128) NOT NULL DEFAULT '',
+    model VARCHAR(255) NOT NULL DEFAULT '',
+    stream BOOLEAN NOT NULL DEFAULT FALSE,
+    status_code INT NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    request_payload TEXT NOT NULL DEFAULT '',
+    response_payload TEXT NOT NULL DEFAULT '',
+    request_truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    response_truncated BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_transcripts_user_created
+    ON conversation_transcripts (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_conversation_transcripts_api_key_created
+    ON conversation_transcripts (api_key_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_conversation_transcripts_created_at
+    ON conversation_transcripts (created_at);
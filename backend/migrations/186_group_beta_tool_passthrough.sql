@@ -0,0 +1,11 @@
+-- 分组级 Anthropic beta 工具透传开关（computer use / code execution / web search 工具）。
+-- 默认关闭：运营需按分组显式开启，未开启时携带对应工具的请求直接返回 400。
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS allow_computer_use_tool BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS allow_code_execution_tool BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS allow_web_search_tool BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN groups.allow_computer_use_tool IS '是否允许透传 computer use 工具（computer_*）';
+COMMENT ON COLUMN groups.allow_code_execution_tool IS '是否允许透传 code execution 工具（code_execution_*）';
+COMMENT ON COLUMN groups.allow_web_search_tool IS '是否允许透传服务端 web search 工具（web_search_*）';
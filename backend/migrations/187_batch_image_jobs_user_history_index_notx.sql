@@ -0,0 +1,8 @@
+-- 用户任务历史（GET /api/v1/tasks）按用户跨 API Key 分页，排除用户已删除记录。
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_batch_image_jobs_user_history
+  ON batch_image_jobs (user_id, created_at DESC, id DESC)
+  WHERE user_deleted_at IS NULL;
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_batch_image_jobs_user_model_history
+  ON batch_image_jobs (user_id, model, created_at DESC)
+  WHERE user_deleted_at IS NULL;
@@ -0,0 +1,32 @@
+-- 计费调整台账：管理员对用户/API Key 发放的补偿（credit）与退款（refund）
+-- 设计约束：
+--   1. 只追加不修改；每条记录在同一事务内同步变更 users.balance 与 api_keys 额度计数
+--   2. 指定 API Key 时回退 quota_used 与限速窗口用量，使额度/预算计算看到调整后的结果
+--   3. 关联具体请求的退款按 (api_key_id, usage_request_id) 唯一，防止同一请求重复退款
+--   4. created_by 记录操作管理员，与审计日志互为补充
+CREATE TABLE IF NOT EXISTS billing_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id BIGINT,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('credit', 'refund')),
+    amount DECIMAL(20, 10) NOT NULL CHECK (amount > 0),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    usage_request_id VARCHAR(64),
+    balance_before DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    balance_after DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    quota_restored DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    created_by BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_billing_adjustments_user_created
+    ON billing_adjustments (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_billing_adjustments_api_key_created
+    ON billing_adjustments (api_key_id, created_at DESC)
+    WHERE api_key_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_billing_adjustments_created_at
+    ON billing_adjustments (created_at DESC, id DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_billing_adjustments_usage_request_unique
+    ON billing_adjustments (api_key_id, usage_request_id)
+    WHERE usage_request_id IS NOT NULL;
@@ -0,0 +1,22 @@
+-- 账号运维元数据：负责人/联系方式、采购与续费日期、月成本、运维备注
+-- 设计约束：
+--   1. 与 accounts 一对一，账号物理删除时级联清理；账号软删除后不再参与续费提醒
+--   2. renewal_date 为订阅到期（需续费）日期，续费提醒任务在到期前 N 天发出告警
+--   3. renewal_reminded_for 记录已提醒的 renewal_date，修改续费日期后会重新提醒
+CREATE TABLE IF NOT EXISTS account_metadata (
+    account_id BIGINT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    owner_name VARCHAR(100) NOT NULL DEFAULT '',
+    owner_contact VARCHAR(255) NOT NULL DEFAULT '',
+    purchase_date DATE,
+    renewal_date DATE,
+    monthly_cost DECIMAL(20, 8),
+    cost_currency VARCHAR(8) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    renewal_reminded_for DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_metadata_renewal_date
+    ON account_metadata (renewal_date)
+    WHERE renewal_date IS NOT NULL;
@@ -0,0 +1,16 @@
+-- 分组月度上游成本预算：预计超支时按比例把调度切到更便宜的同平台兜底分组
+-- 设计约束：
+--   1. 上游成本口径与看板账号成本一致：COALESCE(account_stats_cost, total_cost) * COALESCE(account_rate_multiplier, 1)
+--   2. 周期为自然月（按系统时区），新周期自动恢复正常调度
+--   3. alerted_period（YYYY-MM）记录已告警的周期，多实例下通过条件更新保证每周期只告警一次
+CREATE TABLE IF NOT EXISTS group_cost_budgets (
+    group_id BIGINT PRIMARY KEY REFERENCES groups(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    monthly_budget_usd DECIMAL(20, 8) NOT NULL CHECK (monthly_budget_usd > 0),
+    fallback_group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    shift_percent INT NOT NULL DEFAULT 100 CHECK (shift_percent BETWEEN 1 AND 100),
+    alerted_period VARCHAR(7),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (fallback_group_id <> group_id)
+);
@@ -0,0 +1,3 @@
+-- Keyset (cursor) pagination on usage logs orders and seeks by (created_at, id).
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_usage_logs_created_at_id
+    ON usage_logs (created_at DESC, id DESC);
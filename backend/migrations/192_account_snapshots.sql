@@ -0,0 +1,17 @@
+-- 账号状态快照：周期记录套餐、资格标记、并发、代理、分组与冷却状态，用于回溯账号状态变化
+-- 设计约束：
+--   1. 只追加不修改；状态哈希与该账号最近一次快照相同时不写入，表中每行都代表一次状态变化
+--   2. state 为 JSON 状态文档，差异在读取时按相邻快照计算
+--   3. 账号物理删除时级联清理；超过保留期的快照由采集任务按 captured_at 批量清理
+CREATE TABLE IF NOT EXISTS account_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    state_hash VARCHAR(64) NOT NULL,
+    state JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_snapshots_account_captured
+    ON account_snapshots (account_id, captured_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_account_snapshots_captured_at
+    ON account_snapshots (captured_at);
@@ -0,0 +1,7 @@
+-- 分组级 Claude OAuth system prompt 注入档位。
+-- 空字符串表示沿用全局设置（enable_claude_oauth_system_prompt_injection）。
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS system_prompt_profile VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.system_prompt_profile IS 'system prompt 注入档位：claude-code / api-default / no-injection，空表示沿用全局设置';
@@ -0,0 +1,6 @@
+-- 响应注解头（按 API Key 由管理员开启）
+-- 开启后网关在响应中附带服务平台、重试次数、排队耗时、缓存命中与费用估算，供受信任的集成方排障与对账。
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS annotations_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN api_keys.annotations_enabled IS '是否在响应中输出网关注解头（管理员开启）';
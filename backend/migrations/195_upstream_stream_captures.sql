@@ -0,0 +1,32 @@
+-- 上游原始响应留存（争议核查）
+-- 管理员标记的 API Key 全量留存；开启 capture_on_error 时其余请求仅在失败时留存。
+-- 设计约束：
+--   1. 响应内容存放在对象存储（复用备份的 S3 配置），本表只保存索引
+--   2. request_id 与 usage_logs.request_id 口径一致，可从使用记录直接定位
+--   3. 按 gateway.upstream_stream_capture.retention_days 定期清理索引与对象
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS stream_capture_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS upstream_stream_captures (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    user_id BIGINT NOT NULL,
+    api_key_id BIGINT NOT NULL,
+    account_id BIGINT,
+    platform VARCHAR(32) NOT NULL DEFAULT '',
+    endpoint VARCHAR(128) NOT NULL DEFAULT '',
+    status_code INT NOT NULL DEFAULT 0,
+    reason VARCHAR(16) NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    object_key VARCHAR(512) NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_upstream_stream_captures_request_id
+    ON upstream_stream_captures (request_id);
+CREATE INDEX IF NOT EXISTS idx_upstream_stream_captures_api_key_created
+    ON upstream_stream_captures (api_key_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_upstream_stream_captures_created_at
+    ON upstream_stream_captures (created_at);
@@ -0,0 +1,16 @@
+-- 账号凭据外部来源：把账号映射到外部密钥库（HashiCorp Vault KV v2 / AWS Secrets Manager）中的密钥路径
+-- 设计约束：
+--   1. 与 accounts 一对一，账号物理删除时级联清理
+--   2. field_map 为 {凭据键: 密钥字段} 映射；为空对象时把密钥的全部顶层字段写入凭据
+--   3. last_version 记录最近一次成功同步的密钥版本，last_error 记录最近一次同步失败原因（成功后清空）
+CREATE TABLE IF NOT EXISTS account_credential_sources (
+    account_id BIGINT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    provider VARCHAR(16) NOT NULL,
+    secret_path VARCHAR(512) NOT NULL,
+    field_map JSONB NOT NULL DEFAULT '{}'::jsonb,
+    last_synced_at TIMESTAMPTZ,
+    last_version VARCHAR(128) NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
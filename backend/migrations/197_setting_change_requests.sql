@@ -0,0 +1,20 @@
+-- 系统设置变更审批队列：开启 security 分类审批后，涉及该分类的设置变更先写入此表，
+-- 由另一位管理员批准后按 payload 重放生效。
+--   kind:    settings（PUT /admin/settings 请求体）/ governance（权限与审批策略）
+--   status:  pending / approved / rejected
+CREATE TABLE IF NOT EXISTS setting_change_requests (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    categories TEXT[] NOT NULL DEFAULT '{}',
+    changed_keys TEXT[] NOT NULL DEFAULT '{}',
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    requested_by BIGINT NOT NULL,
+    reviewed_by BIGINT,
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_setting_change_requests_status_created
+    ON setting_change_requests (status, created_at DESC);
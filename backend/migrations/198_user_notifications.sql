@@ -0,0 +1,29 @@
+-- 终端用户站内通知：系统根据告警事件（余额不足、订阅 / API Key 即将到期、异步任务失败）为用户生成的消息。
+--   dedup_key: 同一用户内的去重键（如 api_key_expiring:12:1767225600），为空表示不去重
+--   read_at:   为空表示未读
+CREATE TABLE IF NOT EXISTS user_notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    level VARCHAR(16) NOT NULL DEFAULT 'info',
+    title VARCHAR(200) NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    dedup_key VARCHAR(128) NOT NULL DEFAULT '',
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_notifications_user_created
+    ON user_notifications (user_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_user_notifications_user_unread
+    ON user_notifications (user_id)
+    WHERE read_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_user_notifications_user_dedup
+    ON user_notifications (user_id, dedup_key)
+    WHERE dedup_key <> '';
+
+CREATE INDEX IF NOT EXISTS idx_user_notifications_created_at
+    ON user_notifications (created_at);
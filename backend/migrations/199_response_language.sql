@@ -0,0 +1,12 @@
+-- 强制回复语言：请求未指定回复语言时，网关在 system / instructions 末尾追加语言指令。
+-- groups.response_language:   空字符串表示不强制
+-- api_keys.response_language: 空字符串表示沿用分组设置，'off' 表示对该 Key 关闭分组的强制设置
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS response_language VARCHAR(16) NOT NULL DEFAULT '';
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS response_language VARCHAR(16) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.response_language IS '强制回复语言代码（如 zh / en），空表示不强制';
+COMMENT ON COLUMN api_keys.response_language IS '强制回复语言代码；空表示沿用分组设置，off 表示关闭分组的强制设置';
@@ -0,0 +1,14 @@
+-- 委托子 Key：API Key 持有者可通过公开 API 为其下游用户签发子 Key。
+-- api_keys.parent_key_id:  父 Key ID；子 Key 的用量同时计入父 Key 的额度
+-- api_keys.allowed_models: 子 Key 可请求的模型（支持末尾 * 通配），空表示不限制
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS parent_key_id BIGINT;
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS allowed_models JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_parent_key_id ON api_keys(parent_key_id);
+
+COMMENT ON COLUMN api_keys.parent_key_id IS '委托子 Key 的父 Key ID；子 Key 用量同时计入父 Key 额度';
+COMMENT ON COLUMN api_keys.allowed_models IS '委托子 Key 允许请求的模型（支持末尾 * 通配），空表示不限制';
@@ -0,0 +1,24 @@
+-- 模型别名学习：上游以 model not found 拒绝的模型名，以及与之最接近的已知模型（别名建议）。
+--   suggested_model: 建议改写的目标模型；空表示未找到相近模型（未知模型）
+--   status:          pending / approved / rejected；仅 approved 且开启 auto_apply 时改写请求
+--   hit_count:       被上游拒绝的累计次数
+CREATE TABLE IF NOT EXISTS model_alias_suggestions (
+    id BIGSERIAL PRIMARY KEY,
+    platform VARCHAR(32) NOT NULL,
+    requested_model VARCHAR(255) NOT NULL,
+    suggested_model VARCHAR(255) NOT NULL DEFAULT '',
+    confidence DOUBLE PRECISION NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    hit_count BIGINT NOT NULL DEFAULT 0,
+    last_account_id BIGINT,
+    reviewed_by BIGINT,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_model_alias_suggestions_platform_model
+    ON model_alias_suggestions (platform, requested_model);
+
+CREATE INDEX IF NOT EXISTS idx_model_alias_suggestions_status_last_seen
+    ON model_alias_suggestions (status, last_seen_at DESC);
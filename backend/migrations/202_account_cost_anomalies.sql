@@ -0,0 +1,32 @@
+-- 账号上游成本异常：按 (账号, 模型) 对比近期与基线的单请求成本，偏离过大时记录并告警。
+-- 设计约束：
+--   1. 成本口径与看板账号成本一致：COALESCE(account_stats_cost, total_cost) * COALESCE(account_rate_multiplier, 1)
+--   2. 同一 (账号, 模型) 同时只有一条 open 记录；再次检测到时刷新指标而不重复告警
+--   3. throttled 表示检测时已暂停账号调度，审核（status = reviewed）时解除
+CREATE TABLE IF NOT EXISTS account_cost_anomalies (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    model VARCHAR(255) NOT NULL,
+    baseline_requests BIGINT NOT NULL DEFAULT 0,
+    baseline_avg_cost DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    baseline_p95_cost DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    recent_requests BIGINT NOT NULL DEFAULT 0,
+    recent_avg_cost DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    recent_p95_cost DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    deviation_ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+    sample_request_ids TEXT[] NOT NULL DEFAULT '{}',
+    throttled BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_by BIGINT,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_cost_anomalies_open
+    ON account_cost_anomalies (account_id, model)
+    WHERE status = 'open';
+
+CREATE INDEX IF NOT EXISTS idx_account_cost_anomalies_status_detected
+    ON account_cost_anomalies (status, last_detected_at DESC);
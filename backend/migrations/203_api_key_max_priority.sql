@@ -0,0 +1,7 @@
+-- 请求优先级：受信任的 Key 可通过 X-Priority 请求头（low / normal / high）影响排队位置与重试预算。
+-- api_keys.max_priority: 该 Key 允许的最高优先级；空字符串表示忽略请求头（按 normal 处理）
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS max_priority VARCHAR(8) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN api_keys.max_priority IS 'X-Priority 请求头允许的最高优先级（low / normal / high），空表示忽略请求头';
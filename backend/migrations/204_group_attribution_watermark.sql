@@ -0,0 +1,12 @@
+-- 生成内容归属水印：按分组在文本响应末尾追加可见页脚或零宽字符编码的来源信息，满足转售方的溯源要求。
+-- groups.attribution_mode: 空字符串表示不添加；footer 追加可见页脚；invisible 追加零宽字符编码的分组 / 请求 ID / 时间戳
+-- groups.attribution_text: footer 模式的页脚模板，空字符串使用默认模板
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS attribution_mode VARCHAR(16) NOT NULL DEFAULT '';
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS attribution_text VARCHAR(500) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.attribution_mode IS '文本响应归属水印：footer / invisible，空表示不添加';
+COMMENT ON COLUMN groups.attribution_text IS 'footer 模式的页脚模板，支持 {request_id} {model} {group} {timestamp} 占位符';
@@ -0,0 +1,7 @@
+-- 严格请求校验：开启后网关在转发前按端点 schema 校验请求体，格式错误时直接返回字段级错误，
+-- 避免把畸形请求转发给上游后只拿到含糊的 400。
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS strict_validation BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN api_keys.strict_validation IS '转发前按端点 schema 校验请求体，格式错误时返回字段级错误';
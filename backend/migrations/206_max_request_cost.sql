@@ -0,0 +1,12 @@
+-- 单请求费用上限：转发前按 max_tokens × 输出单价 + 输入估算得出费用上界，超过上限的请求直接拒绝，
+-- 防止单个失控的长上下文 / 高价模型请求耗尽小额用户的预算。
+-- api_keys.max_request_cost / groups.max_request_cost: 美元（按倍率折算后），0 表示不限制；两者同时设置时取较小值
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS max_request_cost DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS max_request_cost DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN api_keys.max_request_cost IS '单次请求预估费用上限（美元），0 表示不限制';
+COMMENT ON COLUMN groups.max_request_cost IS '单次请求预估费用上限（美元），0 表示不限制';
@@ -0,0 +1,27 @@
+-- 账号夜间冒烟测试：每次运行为每个启用账号记录一条结果，用于成功率 / 延迟趋势。
+-- account_smoke_test_flags 保存成功率明显下滑的账号（每账号最多一条），恢复后删除。
+CREATE TABLE IF NOT EXISTS account_smoke_test_results (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    error_message TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_smoke_test_results_account_started
+    ON account_smoke_test_results (account_id, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_account_smoke_test_results_started
+    ON account_smoke_test_results (started_at);
+
+CREATE TABLE IF NOT EXISTS account_smoke_test_flags (
+    account_id BIGINT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    recent_runs INT NOT NULL DEFAULT 0,
+    recent_success_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    baseline_runs INT NOT NULL DEFAULT 0,
+    baseline_success_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    flagged_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,6 @@
+-- 208_usage_log_reasoning_tokens.sql
+-- usage_logs 单独记录推理（thinking / reasoning）token 数与费用，便于单独计价与分析推理开销。
+-- reasoning_tokens 是 output_tokens 的子集（output_tokens 口径不变），
+-- reasoning_cost 从 output_cost 中拆出，total_cost 口径不变。
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS reasoning_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS reasoning_cost DECIMAL(20, 10) NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- 按请求启用实验性网关功能：客户端通过 X-Sub2API-Beta 请求头声明，仅限管理员为该 Key 授权的功能。
+-- api_keys.beta_features:   Key 可启用的实验性功能名列表，空表示不可启用任何功能
+-- usage_logs.beta_features: 本次请求实际启用的功能（逗号分隔），便于灰度对比
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS beta_features JSONB;
+
+ALTER TABLE usage_logs
+    ADD COLUMN IF NOT EXISTS beta_features TEXT;
+
+COMMENT ON COLUMN api_keys.beta_features IS '可通过 X-Sub2API-Beta 请求头按请求启用的实验性功能（仅管理员可修改）';
+COMMENT ON COLUMN usage_logs.beta_features IS '本次请求通过 X-Sub2API-Beta 启用的实验性功能，逗号分隔';
@@ -0,0 +1,13 @@
+-- 数据驻留：账号与代理按所在区域打标（eu/us/asia），API Key 可要求请求只经由指定区域的账号与代理出站。
+-- proxies.data_residency:  代理出口 IP 所在区域，空表示未打标
+-- api_keys.data_residency: Key 要求的驻留区域，空表示不限制（仅管理员可修改）
+-- 账号的区域标签存放在 accounts.extra.data_residency 中，无需新增列。
+
+ALTER TABLE proxies
+    ADD COLUMN IF NOT EXISTS data_residency VARCHAR(8) NOT NULL DEFAULT '';
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS data_residency VARCHAR(8) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN proxies.data_residency IS '代理出口 IP 所在区域（eu/us/asia），空表示未打标';
+COMMENT ON COLUMN api_keys.data_residency IS '要求的数据驻留区域（eu/us/asia），空表示不限制（仅管理员可修改）';
@@ -0,0 +1,14 @@
+-- 账号分组成员的调度覆盖：同一账号可在多个分组中以不同优先级、权重与并发份额提供服务。
+-- priority_override:    账号在该分组内的调度优先级，NULL 表示沿用 accounts.priority
+-- weight:               账号在该分组内的负载均衡权重（百分比），100 表示按账号自身负载因子
+-- concurrency_override: 该分组请求可占用的账号并发上限，NULL 表示沿用 accounts.concurrency
+-- 已有成员关系取默认值（无覆盖、权重 100），调度行为保持不变。
+
+ALTER TABLE account_groups
+    ADD COLUMN IF NOT EXISTS priority_override INT,
+    ADD COLUMN IF NOT EXISTS weight INT NOT NULL DEFAULT 100,
+    ADD COLUMN IF NOT EXISTS concurrency_override INT;
+
+COMMENT ON COLUMN account_groups.priority_override IS '账号在该分组内的调度优先级，NULL 表示沿用账号优先级';
+COMMENT ON COLUMN account_groups.weight IS '账号在该分组内的负载均衡权重（百分比），100 表示按账号自身负载因子';
+COMMENT ON COLUMN account_groups.concurrency_override IS '该分组请求可占用的账号并发上限，NULL 表示沿用账号并发';
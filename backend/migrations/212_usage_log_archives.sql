@@ -0,0 +1,44 @@
+-- 使用记录分层归档
+-- usage_logs 原始记录保留 usage_archive.archive_after_days 天，之后按自然月导出为 gzip 压缩的 JSONL
+-- 存放到对象存储（复用备份的 S3 配置）并删除原始行；仪表盘预聚合表保留汇总口径。
+-- 设计约束：
+--   1. 每个归档对象只包含同一自然月（UTC）的记录，本表保存索引与对账汇总
+--   2. 对象上传成功后，索引写入与原始行删除在同一事务内完成
+--   3. 审计时可将归档恢复到 usage_log_archive_rows（JSONB），不回写 usage_logs，避免与保留期清理互相干扰
+CREATE TABLE IF NOT EXISTS usage_log_archives (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    month DATE NOT NULL,
+    object_key VARCHAR(512) NOT NULL,
+    row_count INT NOT NULL DEFAULT 0,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    first_log_id BIGINT NOT NULL DEFAULT 0,
+    last_log_id BIGINT NOT NULL DEFAULT 0,
+    range_start TIMESTAMPTZ NOT NULL,
+    range_end TIMESTAMPTZ NOT NULL,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    total_cost DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    actual_cost DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    restored_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_log_archives_month
+    ON usage_log_archives (month);
+CREATE INDEX IF NOT EXISTS idx_usage_log_archives_created_at
+    ON usage_log_archives (created_at);
+
+-- 审计恢复区：按归档恢复的原始记录（JSONB 保留归档时的全部列）
+CREATE TABLE IF NOT EXISTS usage_log_archive_rows (
+    archive_id BIGINT NOT NULL REFERENCES usage_log_archives(id) ON DELETE CASCADE,
+    usage_log_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    data JSONB NOT NULL,
+    PRIMARY KEY (archive_id, usage_log_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_log_archive_rows_created_at
+    ON usage_log_archive_rows (created_at);
+
+COMMENT ON TABLE usage_log_archives IS '使用记录归档索引（对象存储中的按月 gzip JSONL）';
+COMMENT ON TABLE usage_log_archive_rows IS '审计恢复的归档使用记录（JSONB），释放恢复后删除';
@@ -0,0 +1,13 @@
+-- 用量日志数据修订号：管理端用量列表指纹（ETag）的变更标记。
+-- usage_logs 以追加为主，指纹依赖 min/max id；原地改写已有行（如 usage-backfill 重算费用、按 ID 删除单条记录）
+-- 不会改变 id 范围，需同时递增本表修订号，避免客户端拿到过期的 304。
+CREATE TABLE IF NOT EXISTS usage_log_revision (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    revision BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO usage_log_revision (id, revision) VALUES (1, 0)
+ON CONFLICT (id) DO NOTHING;
+
+COMMENT ON TABLE usage_log_revision IS '用量日志数据修订号（单行），原地改写 usage_logs 时递增';
@@ -0,0 +1,5 @@
+-- 管理端列表指纹按 MAX(updated_at) 取关联实体的最新变更时间，补齐索引避免每次 If-None-Match 校验全表扫描。
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_updated_at ON users (updated_at);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_api_keys_updated_at ON api_keys (updated_at);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_accounts_updated_at ON accounts (updated_at);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_groups_updated_at ON groups (updated_at);
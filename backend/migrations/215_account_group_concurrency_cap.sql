@@ -0,0 +1,20 @@
+-- 账号分组成员的 concurrency_override 实际是该分组的准入上限：并发槽位按账号计数、由所有分组共享，
+-- 账号在途请求达到该值后不再为该分组调度，并不为分组预留或隔离并发。按语义更名为 concurrency_cap。
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM information_schema.columns
+        WHERE table_schema = 'public'
+          AND table_name = 'account_groups'
+          AND column_name = 'concurrency_override'
+    ) THEN
+        ALTER TABLE account_groups RENAME COLUMN concurrency_override TO concurrency_cap;
+    END IF;
+END $$;
+
+ALTER TABLE account_groups
+    ADD COLUMN IF NOT EXISTS concurrency_cap INT;
+
+COMMENT ON COLUMN account_groups.concurrency_cap IS '该分组的准入上限：账号在途请求（计入所有分组）达到该值后不再为该分组调度，NULL 表示不限制';
@@ -0,0 +1,17 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchImageJobsUserHistoryIndexMigration(t *testing.T) {
+	content, err := FS.ReadFile("187_batch_image_jobs_user_history_index_notx.sql")
+	require.NoError(t, err)
+
+	sql := strings.Join(strings.Fields(string(content)), " ")
+	require.Contains(t, sql, "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_batch_image_jobs_user_history ON batch_image_jobs (user_id, created_at DESC, id DESC) WHERE user_deleted_at IS NULL")
+	require.Contains(t, sql, "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_batch_image_jobs_user_model_history ON batch_image_jobs (user_id, model, created_at DESC) WHERE user_deleted_at IS NULL")
+}
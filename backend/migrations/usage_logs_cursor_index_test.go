@@ -0,0 +1,17 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageLogsCursorIndexMigration(t *testing.T) {
+	content, err := FS.ReadFile("191_usage_logs_created_at_id_index_notx.sql")
+	require.NoError(t, err)
+
+	sql := strings.Join(strings.Fields(string(content)), " ")
+	require.Contains(t, sql, "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_usage_logs_created_at_id")
+	require.Contains(t, sql, "ON usage_logs (created_at DESC, id DESC)")
+}
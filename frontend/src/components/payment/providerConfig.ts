@@ -81,6 +81,12 @@ export const PAYMENT_CURRENCY_OPTIONS: TypeOption[] = [
   { value: 'NZD', label: 'NZD' },
 ]
 
+/** Stripe checkout modes: embedded Payment Element or hosted Checkout Session redirect. */
+export const STRIPE_CHECKOUT_MODE_OPTIONS: TypeOption[] = [
+  { value: 'payment_intent', label: 'Payment Element' },
+  { value: 'checkout_session', label: 'Checkout Session' },
+]
+
 // 与后端当前集成的 stripe-go v85.0.0 的 stripe.APIVersion 保持一致。
 export const STRIPE_SDK_API_VERSION = '2026-03-25.dahlia'
 
@@ -151,6 +157,7 @@ export const PROVIDER_CONFIG_FIELDS: Record<string, ConfigFieldDef[]> = {
     { key: 'publishableKey', label: '', sensitive: false },
     { key: 'webhookSecret', label: '', sensitive: true },
     { key: 'currency', label: '', sensitive: false, defaultValue: 'CNY', hintKey: 'admin.settings.payment.field_paymentCurrencyHint', options: PAYMENT_CURRENCY_OPTIONS },
+    { key: 'checkoutMode', label: '', sensitive: false, optional: true, defaultValue: 'payment_intent', hintKey: 'admin.settings.payment.field_checkoutModeHint', options: STRIPE_CHECKOUT_MODE_OPTIONS },
   ],
   airwallex: [
     { key: 'clientId', label: '', sensitive: false },
@@ -639,6 +639,8 @@ export default {
         field_accountId: 'Airwallex Account ID',
         field_airwallexApiBaseHint: 'Must match the API key environment: use https://api-demo.airwallex.com/api/v1 for sandbox/demo keys, and https://api.airwallex.com/api/v1 for production keys. Mixed environments return credentials_invalid / Access Denied.',
         field_paymentCurrencyHint: 'Default is CNY. Stripe and Airwallex can choose HKD, USD, or another listed currency supported by the account; WeChat Pay, Alipay, and EasyPay remain CNY.',
+        field_checkoutMode: 'Checkout mode',
+        field_checkoutModeHint: 'Payment Element collects payment on this site via Stripe.js. Checkout Session redirects users to a Stripe-hosted checkout page; the webhook must also subscribe to checkout.session.completed, checkout.session.async_payment_succeeded and checkout.session.async_payment_failed.',
         field_accountIdHint: 'Leave this empty unless you use multiple accounts, an organization-level key, or connected-account payments. A single-account scoped API key uses the selected account by default.',
         field_cid: 'Channel ID',
         field_cidAlipay: 'Alipay Channel ID',